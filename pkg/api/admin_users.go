@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/ua-parser/uap-go/uaparser"
 )
 
 func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) response.Response {
@@ -47,6 +48,9 @@ func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext, form dtos.AdminCreat
 	}
 
 	metrics.MApiAdminUserCreate.Inc()
+	auditUserChange(c, models.AuditActionCreate, user.Id, nil, &userAuditState{
+		Login: user.Login, Email: user.Email, Name: user.Name, IsGrafanaAdmin: user.IsAdmin,
+	})
 
 	result := models.UserIdDTO{
 		Message: "User created",
@@ -89,6 +93,7 @@ func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPass
 // PUT /api/admin/users/:id/permissions
 func (hs *HTTPServer) AdminUpdateUserPermissions(c *models.ReqContext, form dtos.AdminUpdateUserPermissionsForm) response.Response {
 	userID := c.ParamsInt64(":id")
+	before := getUserAuditState(c, userID)
 
 	err := updateUserPermissions(hs.SQLStore, userID, form.IsGrafanaAdmin)
 	if err != nil {
@@ -98,12 +103,14 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *models.ReqContext, form dtos
 
 		return response.Error(500, "Failed to update user permissions", err)
 	}
+	auditUserChange(c, models.AuditActionUpdate, userID, before, getUserAuditState(c, userID))
 
 	return response.Success("User permissions updated")
 }
 
 func AdminDeleteUser(c *models.ReqContext) response.Response {
 	userID := c.ParamsInt64(":id")
+	before := getUserAuditState(c, userID)
 
 	cmd := models.DeleteUserCommand{UserId: userID}
 
@@ -113,6 +120,7 @@ func AdminDeleteUser(c *models.ReqContext) response.Response {
 		}
 		return response.Error(500, "Failed to delete user", err)
 	}
+	auditUserChange(c, models.AuditActionDelete, userID, before, nil)
 
 	return response.Success("User deleted")
 }
@@ -127,6 +135,7 @@ func (hs *HTTPServer) AdminDisableUser(c *models.ReqContext) response.Response {
 		return response.Error(500, "Could not disable external user", nil)
 	}
 
+	before := getUserAuditState(c, userID)
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: true}
 	if err := bus.Dispatch(&disableCmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
@@ -134,6 +143,7 @@ func (hs *HTTPServer) AdminDisableUser(c *models.ReqContext) response.Response {
 		}
 		return response.Error(500, "Failed to disable user", err)
 	}
+	auditUserChange(c, models.AuditActionUpdate, userID, before, getUserAuditState(c, userID))
 
 	err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), userID)
 	if err != nil {
@@ -153,6 +163,7 @@ func AdminEnableUser(c *models.ReqContext) response.Response {
 		return response.Error(500, "Could not enable external user", nil)
 	}
 
+	before := getUserAuditState(c, userID)
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: false}
 	if err := bus.Dispatch(&disableCmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
@@ -160,6 +171,7 @@ func AdminEnableUser(c *models.ReqContext) response.Response {
 		}
 		return response.Error(500, "Failed to enable user", err)
 	}
+	auditUserChange(c, models.AuditActionUpdate, userID, before, getUserAuditState(c, userID))
 
 	return response.Success("User enabled")
 }
@@ -187,6 +199,138 @@ func (hs *HTTPServer) AdminRevokeUserAuthToken(c *models.ReqContext, cmd models.
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// AdminSearchUserSessions returns a page of the active sessions of all the users, of a user with userId or of the
+// members of an org with orgId, the most recently rotated first.
+// GET /api/admin/sessions
+func (hs *HTTPServer) AdminSearchUserSessions(c *models.ReqContext) response.Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	result, err := hs.AuthTokenService.SearchUserTokens(c.Req.Context(), &models.SearchUserTokensQuery{
+		UserId: c.QueryInt64("userId"),
+		OrgId:  c.QueryInt64("orgId"),
+		Page:   page,
+		Limit:  perPage,
+	})
+	if err != nil {
+		return response.Error(500, "Failed to search user sessions", err)
+	}
+
+	parser := uaparser.NewFromSaved()
+	users := map[int64]*models.User{}
+	sessions := make([]*dtos.UserSession, 0, len(result.Tokens))
+	for _, token := range result.Tokens {
+		user, ok := users[token.UserId]
+		if !ok {
+			query := models.GetUserByIdQuery{Id: token.UserId}
+			if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil && !errors.Is(err, models.ErrUserNotFound) {
+				return response.Error(500, "Failed to get user", err)
+			}
+			user = query.Result
+			users[token.UserId] = user
+		}
+
+		session := &dtos.UserSession{
+			UserToken: *hs.userTokenDTO(c, parser, token),
+			UserId:    token.UserId,
+		}
+		// The tokens of the deleted users are listed until they expire.
+		if user != nil {
+			session.Login = user.Login
+			session.Email = user.Email
+		}
+		sessions = append(sessions, session)
+	}
+
+	return response.JSON(200, dtos.SearchUserSessionsResult{
+		TotalCount: result.TotalCount,
+		Sessions:   sessions,
+		Page:       page,
+		PerPage:    perPage,
+	})
+}
+
+// AdminRevokeUserSessions revokes the sessions, of any users.
+// POST /api/admin/sessions/revoke
+func (hs *HTTPServer) AdminRevokeUserSessions(c *models.ReqContext, cmd models.RevokeAuthTokensCmd) response.Response {
+	if len(cmd.AuthTokenIds) == 0 {
+		return response.Error(400, "No user auth tokens to revoke", nil)
+	}
+
+	for _, id := range cmd.AuthTokenIds {
+		if c.UserToken != nil && c.UserToken.Id == id {
+			return response.Error(400, "Cannot revoke active user auth token", nil)
+		}
+	}
+
+	count, err := hs.AuthTokenService.RevokeTokens(c.Req.Context(), cmd.AuthTokenIds)
+	if err != nil {
+		return response.Error(500, "Failed to revoke user auth tokens", err)
+	}
+
+	return response.JSON(200, util.DynMap{
+		"message": "User auth tokens revoked",
+		"count":   count,
+	})
+}
+
+// AdminForceReauthentication revokes all the sessions of the users and of the members of the org, so that they have
+// to log in again. The signed in admin keeps its sessions when it's a member of the org.
+// POST /api/admin/sessions/reauthenticate
+func (hs *HTTPServer) AdminForceReauthentication(c *models.ReqContext, cmd models.ForceReauthenticationCmd) response.Response {
+	if len(cmd.UserIds) == 0 && cmd.OrgId == 0 {
+		return response.Error(400, "No users to re-authenticate", nil)
+	}
+
+	userIDs := make([]int64, 0, len(cmd.UserIds))
+	seen := map[int64]bool{}
+	for _, id := range cmd.UserIds {
+		if id == c.UserId {
+			return response.Error(400, "You cannot force yourself to re-authenticate", nil)
+		}
+		if !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	if cmd.OrgId != 0 {
+		orgQuery := models.GetOrgByIdQuery{Id: cmd.OrgId}
+		if err := bus.DispatchCtx(c.Req.Context(), &orgQuery); err != nil {
+			if errors.Is(err, models.ErrOrgNotFound) {
+				return response.Error(404, "Organization not found", err)
+			}
+			return response.Error(500, "Failed to get organization", err)
+		}
+
+		query := models.GetOrgUsersQuery{OrgId: cmd.OrgId}
+		if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+			return response.Error(500, "Failed to get organization users", err)
+		}
+		for _, orgUser := range query.Result {
+			if orgUser.UserId != c.UserId && !seen[orgUser.UserId] {
+				seen[orgUser.UserId] = true
+				userIDs = append(userIDs, orgUser.UserId)
+			}
+		}
+	}
+
+	if err := hs.AuthTokenService.BatchRevokeAllUserTokens(c.Req.Context(), userIDs); err != nil {
+		return response.Error(500, "Failed to revoke user auth tokens", err)
+	}
+
+	return response.JSON(200, util.DynMap{
+		"message":    "Users have to log in again",
+		"usersCount": len(userIDs),
+	})
+}
+
 // updateUserPermissions updates the user's permissions.
 //
 // Stubbable by tests.
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -503,3 +504,168 @@ func (s fakeLoginService) CreateUser(cmd models.CreateUserCommand) (*models.User
 
 	return nil, errors.New("unexpected cmd")
 }
+
+func TestAdminSessionsAPIEndpoint(t *testing.T) {
+	t.Run("When searching the sessions", func(t *testing.T) {
+		adminSessionsScenario(t, "Should return the sessions with their users", "GET", "/api/admin/sessions",
+			func(hs *HTTPServer, c *models.ReqContext) response.Response {
+				return hs.AdminSearchUserSessions(c)
+			},
+			func(sc *scenarioContext) {
+				bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+					if query.Id != 10 {
+						return models.ErrUserNotFound
+					}
+					query.Result = &models.User{Id: 10, Login: "jdoe", Email: "jdoe@example.com"}
+					return nil
+				})
+
+				var query *models.SearchUserTokensQuery
+				sc.userAuthTokenService.SearchUserTokensProvider = func(ctx context.Context, q *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+					query = q
+					return &models.SearchUserTokensResult{
+						TotalCount: 12,
+						Tokens: []*models.UserToken{
+							{Id: 1, UserId: 10, ClientIp: "127.0.0.1", CreatedAt: time.Now().Unix(), RotatedAt: time.Now().Unix()},
+							{Id: 2, UserId: 11, ClientIp: "127.0.0.2", CreatedAt: time.Now().Unix(), RotatedAt: time.Now().Unix()},
+						},
+					}, nil
+				}
+
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"orgId": "2", "page": "2", "perpage": "10"}).exec()
+				require.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, &models.SearchUserTokensQuery{OrgId: 2, Page: 2, Limit: 10}, query)
+
+				result := sc.ToJSON()
+				assert.Equal(t, int64(12), result.Get("totalCount").MustInt64())
+				assert.Equal(t, 2, result.Get("page").MustInt())
+				sessions := result.Get("sessions")
+				assert.Len(t, sessions.MustArray(), 2)
+				assert.Equal(t, int64(1), sessions.GetIndex(0).Get("id").MustInt64())
+				assert.Equal(t, "127.0.0.1", sessions.GetIndex(0).Get("clientIp").MustString())
+				assert.Equal(t, "jdoe", sessions.GetIndex(0).Get("login").MustString())
+				assert.Equal(t, int64(11), sessions.GetIndex(1).Get("userId").MustInt64())
+				assert.Empty(t, sessions.GetIndex(1).Get("login").MustString())
+			})
+	})
+
+	t.Run("When revoking sessions", func(t *testing.T) {
+		revoke := func(cmd models.RevokeAuthTokensCmd) func(hs *HTTPServer, c *models.ReqContext) response.Response {
+			return func(hs *HTTPServer, c *models.ReqContext) response.Response {
+				c.UserToken = &models.UserToken{Id: 3}
+				return hs.AdminRevokeUserSessions(c, cmd)
+			}
+		}
+
+		adminSessionsScenario(t, "Should revoke the sessions", "POST", "/api/admin/sessions/revoke",
+			revoke(models.RevokeAuthTokensCmd{AuthTokenIds: []int64{1, 2}}), func(sc *scenarioContext) {
+				var revoked []int64
+				sc.userAuthTokenService.RevokeTokensProvider = func(ctx context.Context, ids []int64) (int64, error) {
+					revoked = ids
+					return 1, nil
+				}
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				require.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, []int64{1, 2}, revoked)
+				assert.Equal(t, int64(1), sc.ToJSON().Get("count").MustInt64())
+			})
+
+		adminSessionsScenario(t, "Should not revoke the active session", "POST", "/api/admin/sessions/revoke",
+			revoke(models.RevokeAuthTokensCmd{AuthTokenIds: []int64{1, 3}}), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+
+		adminSessionsScenario(t, "Should require sessions", "POST", "/api/admin/sessions/revoke",
+			revoke(models.RevokeAuthTokensCmd{}), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+	})
+
+	t.Run("When forcing users to re-authenticate", func(t *testing.T) {
+		reauthenticate := func(cmd models.ForceReauthenticationCmd) func(hs *HTTPServer, c *models.ReqContext) response.Response {
+			return func(hs *HTTPServer, c *models.ReqContext) response.Response {
+				return hs.AdminForceReauthentication(c, cmd)
+			}
+		}
+
+		adminSessionsScenario(t, "Should revoke the sessions of the users and org members", "POST", "/api/admin/sessions/reauthenticate",
+			reauthenticate(models.ForceReauthenticationCmd{UserIds: []int64{20, 21}, OrgId: 2}),
+			func(sc *scenarioContext) {
+				bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetOrgByIdQuery) error {
+					query.Result = &models.Org{Id: query.Id}
+					return nil
+				})
+				bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetOrgUsersQuery) error {
+					query.Result = []*models.OrgUserDTO{{UserId: testUserID}, {UserId: 21}, {UserId: 22}}
+					return nil
+				})
+
+				var revoked []int64
+				sc.userAuthTokenService.BatchRevokedTokenProvider = func(ctx context.Context, userIds []int64) error {
+					revoked = userIds
+					return nil
+				}
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				require.Equal(t, 200, sc.resp.Code)
+				// The admin keeps its sessions.
+				assert.Equal(t, []int64{20, 21, 22}, revoked)
+				assert.Equal(t, 3, sc.ToJSON().Get("usersCount").MustInt())
+			})
+
+		adminSessionsScenario(t, "Should return not found for a non-existing org", "POST", "/api/admin/sessions/reauthenticate",
+			reauthenticate(models.ForceReauthenticationCmd{OrgId: 2}),
+			func(sc *scenarioContext) {
+				bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetOrgByIdQuery) error {
+					return models.ErrOrgNotFound
+				})
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 404, sc.resp.Code)
+			})
+
+		adminSessionsScenario(t, "Should not force the admin to re-authenticate", "POST", "/api/admin/sessions/reauthenticate",
+			reauthenticate(models.ForceReauthenticationCmd{UserIds: []int64{testUserID}}),
+			func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+	})
+}
+
+func adminSessionsScenario(t *testing.T, desc string, method string, url string,
+	handler func(hs *HTTPServer, c *models.ReqContext) response.Response, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		fakeAuthTokenService := auth.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			Bus:              bus.GetBus(),
+			Cfg:              setting.NewCfg(),
+			AuthTokenService: fakeAuthTokenService,
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.IsGrafanaAdmin = true
+
+			return handler(&hs, c)
+		})
+
+		if method == "GET" {
+			sc.m.Get(url, sc.defaultHandler)
+		} else {
+			sc.m.Post(url, sc.defaultHandler)
+		}
+
+		fn(sc)
+	})
+}
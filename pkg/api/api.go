@@ -33,15 +33,23 @@ func (hs *HTTPServer) registerRoutes() {
 	redirectFromLegacyPanelEditURL := middleware.RedirectFromLegacyPanelEditURL(hs.Cfg)
 	authorize := acmiddleware.Middleware(hs.AccessControl)
 	quota := middleware.Quota(hs.QuotaService)
+	rateLimit := middleware.RateLimiting(hs.Cfg, hs.RemoteCacheService)
+	idempotent := middleware.Idempotency(hs.Cfg, hs.RemoteCacheService)
 	bind := binding.Bind
 
 	r := hs.RouteRegister
 
 	// not logged in views
 	r.Get("/logout", hs.Logout)
-	r.Post("/login", quota("session"), bind(dtos.LoginCommand{}), routing.Wrap(hs.LoginPost))
+	r.Post("/login", rateLimit("auth"), quota("session"), bind(dtos.LoginCommand{}), routing.Wrap(hs.LoginPost))
 	r.Get("/login/:name", quota("session"), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
+	r.Get("/login/saml", quota("session"), hs.SAMLLogin)
+	r.Get("/logout/saml", hs.SAMLLogout)
+	r.Get("/saml/metadata", routing.Wrap(hs.SAMLMetadata))
+	r.Post("/saml/acs", quota("session"), hs.SAMLAssertionConsumer)
+	r.Get("/saml/slo", hs.SAMLSingleLogout)
+	r.Post("/saml/slo", hs.SAMLSingleLogout)
 	r.Get("/invite/:code", hs.Index)
 
 	// authed views
@@ -105,8 +113,8 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/verify", hs.Index)
 	r.Get("/signup", hs.Index)
 	r.Get("/api/user/signup/options", routing.Wrap(GetSignUpOptions))
-	r.Post("/api/user/signup", quota("user"), bind(dtos.SignUpForm{}), routing.Wrap(SignUp))
-	r.Post("/api/user/signup/step2", bind(dtos.SignUpStep2Form{}), routing.Wrap(hs.SignUpStep2))
+	r.Post("/api/user/signup", rateLimit("auth"), quota("user"), bind(dtos.SignUpForm{}), routing.Wrap(SignUp))
+	r.Post("/api/user/signup/step2", rateLimit("auth"), bind(dtos.SignUpStep2Form{}), routing.Wrap(hs.SignUpStep2))
 
 	// invited
 	r.Get("/api/user/invite/:code", routing.Wrap(GetInviteInfoByCode))
@@ -116,8 +124,8 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/user/password/send-reset-email", reqNotSignedIn, hs.Index)
 	r.Get("/user/password/reset", hs.Index)
 
-	r.Post("/api/user/password/send-reset-email", bind(dtos.SendResetPasswordEmailForm{}), routing.Wrap(SendResetPasswordEmail))
-	r.Post("/api/user/password/reset", bind(dtos.ResetUserPasswordForm{}), routing.Wrap(ResetPassword))
+	r.Post("/api/user/password/send-reset-email", rateLimit("auth"), bind(dtos.SendResetPasswordEmailForm{}), routing.Wrap(SendResetPasswordEmail))
+	r.Post("/api/user/password/reset", rateLimit("auth"), bind(dtos.ResetUserPasswordForm{}), routing.Wrap(ResetPassword))
 
 	// dashboard snapshots
 	r.Get("/dashboard/snapshot/*", reqNoAuth, hs.Index)
@@ -126,6 +134,9 @@ func (hs *HTTPServer) registerRoutes() {
 	// api renew session based on cookie
 	r.Get("/api/login/ping", quota("session"), routing.Wrap(hs.LoginAPIPing))
 
+	// OpenAPI specification of the HTTP API
+	r.Get("/api/openapi.json", routing.Wrap(GetOpenAPISpec))
+
 	// expose plugin file system assets
 	r.Get("/public/plugins/:pluginId/*", hs.GetPluginAssets)
 
@@ -191,7 +202,7 @@ func (hs *HTTPServer) registerRoutes() {
 		// org information available to all users.
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
 			orgRoute.Get("/", routing.Wrap(GetOrgCurrent))
-			orgRoute.Get("/quotas", routing.Wrap(GetOrgQuotas))
+			orgRoute.Get("/quotas", routing.Wrap(hs.GetOrgQuotas))
 		})
 
 		// current org
@@ -225,6 +236,7 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// search all orgs
 		apiRoute.Get("/orgs", reqGrafanaAdmin, routing.Wrap(SearchOrgs))
+		apiRoute.Get("/orgs/search", reqGrafanaAdmin, routing.Wrap(SearchOrgsWithPaging))
 
 		// orgs (admin routes)
 		apiRoute.Group("/orgs/:orgId", func(orgsRoute routing.RouteRegister) {
@@ -237,7 +249,7 @@ func (hs *HTTPServer) registerRoutes() {
 			orgsRoute.Post("/users", authorize(reqGrafanaAdmin, accesscontrol.ActionOrgUsersAdd, accesscontrol.ScopeUsersAll), bind(models.AddOrgUserCommand{}), routing.Wrap(AddOrgUser))
 			orgsRoute.Patch("/users/:userId", authorize(reqGrafanaAdmin, accesscontrol.ActionOrgUsersRoleUpdate, usersScope), bind(models.UpdateOrgUserCommand{}), routing.Wrap(UpdateOrgUser))
 			orgsRoute.Delete("/users/:userId", authorize(reqGrafanaAdmin, accesscontrol.ActionOrgUsersRemove, usersScope), routing.Wrap(RemoveOrgUser))
-			orgsRoute.Get("/quotas", reqGrafanaAdmin, routing.Wrap(GetOrgQuotas))
+			orgsRoute.Get("/quotas", reqGrafanaAdmin, routing.Wrap(hs.GetOrgQuotas))
 			orgsRoute.Put("/quotas/:target", reqGrafanaAdmin, bind(models.UpdateOrgQuotaCmd{}), routing.Wrap(UpdateOrgQuota))
 		})
 
@@ -253,23 +265,83 @@ func (hs *HTTPServer) registerRoutes() {
 			keysRoute.Delete("/:id", routing.Wrap(DeleteAPIKey))
 		}, reqOrgAdmin)
 
+		// service accounts
+		apiRoute.Group("/serviceaccounts", func(serviceAccountsRoute routing.RouteRegister) {
+			serviceAccountsRoute.Get("/", routing.Wrap(hs.GetServiceAccounts))
+			serviceAccountsRoute.Post("/", bind(models.CreateServiceAccountCommand{}), routing.Wrap(hs.CreateServiceAccount))
+			serviceAccountsRoute.Get("/:serviceAccountId", routing.Wrap(hs.GetServiceAccount))
+			serviceAccountsRoute.Patch("/:serviceAccountId", bind(models.UpdateServiceAccountCommand{}), routing.Wrap(hs.UpdateServiceAccount))
+			serviceAccountsRoute.Delete("/:serviceAccountId", routing.Wrap(hs.DeleteServiceAccount))
+			serviceAccountsRoute.Get("/:serviceAccountId/tokens", routing.Wrap(hs.GetServiceAccountTokens))
+			serviceAccountsRoute.Post("/:serviceAccountId/tokens", bind(models.AddServiceAccountTokenCommand{}), routing.Wrap(hs.AddServiceAccountToken))
+			serviceAccountsRoute.Post("/:serviceAccountId/tokens/:tokenId/rotate", bind(models.RotateServiceAccountTokenCommand{}), routing.Wrap(hs.RotateServiceAccountToken))
+			serviceAccountsRoute.Delete("/:serviceAccountId/tokens/:tokenId", routing.Wrap(hs.DeleteServiceAccountToken))
+		}, reqOrgAdmin)
+
+		// Audit log
+		if hs.Cfg.Audit.Enabled {
+			apiRoute.Get("/audit", reqOrgAdmin, routing.Wrap(hs.SearchAuditEntries))
+		}
+
+		// Webhooks
+		if hs.Cfg.Webhooks.Enabled {
+			apiRoute.Group("/webhooks", func(webhooksRoute routing.RouteRegister) {
+				webhooksRoute.Get("/", routing.Wrap(hs.GetWebhooks))
+				webhooksRoute.Post("/", bind(models.CreateWebhookCommand{}), routing.Wrap(hs.CreateWebhook))
+				webhooksRoute.Get("/:webhookId", routing.Wrap(hs.GetWebhook))
+				webhooksRoute.Patch("/:webhookId", bind(models.UpdateWebhookCommand{}), routing.Wrap(hs.UpdateWebhook))
+				webhooksRoute.Delete("/:webhookId", routing.Wrap(hs.DeleteWebhook))
+				webhooksRoute.Get("/:webhookId/deliveries", routing.Wrap(hs.GetWebhookDeliveries))
+				webhooksRoute.Post("/:webhookId/deliveries/:deliveryId/redeliver", routing.Wrap(hs.RedeliverWebhookDelivery))
+			}, reqOrgAdmin)
+		}
+
+		// SCIM 2.0 provisioning of the users and teams of the org
+		if hs.Cfg.SCIM.Enabled {
+			apiRoute.Group("/scim/v2", func(scimRoute routing.RouteRegister) {
+				scimRoute.Get("/ServiceProviderConfig", routing.Wrap(hs.GetSCIMServiceProviderConfig))
+				scimRoute.Get("/ResourceTypes", routing.Wrap(hs.GetSCIMResourceTypes))
+				scimRoute.Get("/Users", routing.Wrap(hs.GetSCIMUsers))
+				scimRoute.Post("/Users", routing.Wrap(hs.CreateSCIMUser))
+				scimRoute.Get("/Users/:id", routing.Wrap(hs.GetSCIMUser))
+				scimRoute.Put("/Users/:id", routing.Wrap(hs.ReplaceSCIMUser))
+				scimRoute.Patch("/Users/:id", routing.Wrap(hs.PatchSCIMUser))
+				scimRoute.Delete("/Users/:id", routing.Wrap(hs.DeleteSCIMUser))
+				scimRoute.Get("/Groups", routing.Wrap(hs.GetSCIMGroups))
+				scimRoute.Post("/Groups", routing.Wrap(hs.CreateSCIMGroup))
+				scimRoute.Get("/Groups/:id", routing.Wrap(hs.GetSCIMGroup))
+				scimRoute.Put("/Groups/:id", routing.Wrap(hs.ReplaceSCIMGroup))
+				scimRoute.Patch("/Groups/:id", routing.Wrap(hs.PatchSCIMGroup))
+				scimRoute.Delete("/Groups/:id", routing.Wrap(hs.DeleteSCIMGroup))
+			}, reqOrgAdmin)
+		}
+
 		// Preferences
 		apiRoute.Group("/preferences", func(prefRoute routing.RouteRegister) {
 			prefRoute.Post("/set-home-dash", bind(models.SavePreferencesCommand{}), routing.Wrap(SetHomeDashboard))
 		})
 
-		// Data sources
+		// Data sources, which only org admins may read and change unless the data source permissions are enforced
+		reqDataSourcesList, reqDataSourceRead, reqDataSourceWrite, reqDataSourceAdmin := reqOrgAdmin, reqOrgAdmin, reqOrgAdmin, reqOrgAdmin
+		if hs.Cfg.DataSourcePermissionsEnabled {
+			reqDataSourcesList = reqSignedIn
+			reqDataSourceRead = hs.reqDataSourcePermission(models.DsPermissionRead)
+			reqDataSourceWrite = hs.reqDataSourcePermission(models.DsPermissionWrite)
+			reqDataSourceAdmin = hs.reqDataSourcePermission(models.DsPermissionAdmin)
+		}
 		apiRoute.Group("/datasources", func(datasourceRoute routing.RouteRegister) {
-			datasourceRoute.Get("/", routing.Wrap(hs.GetDataSources))
-			datasourceRoute.Post("/", quota("data_source"), bind(models.AddDataSourceCommand{}), routing.Wrap(AddDataSource))
-			datasourceRoute.Put("/:id", bind(models.UpdateDataSourceCommand{}), routing.Wrap(hs.UpdateDataSource))
-			datasourceRoute.Delete("/:id", routing.Wrap(hs.DeleteDataSourceById))
-			datasourceRoute.Delete("/uid/:uid", routing.Wrap(hs.DeleteDataSourceByUID))
-			datasourceRoute.Delete("/name/:name", routing.Wrap(hs.DeleteDataSourceByName))
-			datasourceRoute.Get("/:id", routing.Wrap(GetDataSourceById))
-			datasourceRoute.Get("/uid/:uid", routing.Wrap(GetDataSourceByUID))
-			datasourceRoute.Get("/name/:name", routing.Wrap(GetDataSourceByName))
-		}, reqOrgAdmin)
+			datasourceRoute.Get("/", reqDataSourcesList, routing.Wrap(hs.GetDataSources))
+			datasourceRoute.Post("/", reqOrgAdmin, quota("data_source"), idempotent, bind(models.AddDataSourceCommand{}), routing.Wrap(AddDataSource))
+			datasourceRoute.Put("/:id", reqDataSourceWrite, bind(models.UpdateDataSourceCommand{}), routing.Wrap(hs.UpdateDataSource))
+			datasourceRoute.Delete("/:id", reqDataSourceAdmin, routing.Wrap(hs.DeleteDataSourceById))
+			datasourceRoute.Delete("/uid/:uid", reqDataSourceAdmin, routing.Wrap(hs.DeleteDataSourceByUID))
+			datasourceRoute.Delete("/name/:name", reqDataSourceAdmin, routing.Wrap(hs.DeleteDataSourceByName))
+			datasourceRoute.Get("/:id", reqDataSourceRead, routing.Wrap(hs.GetDataSourceById))
+			datasourceRoute.Get("/uid/:uid", reqDataSourceRead, routing.Wrap(hs.GetDataSourceByUID))
+			datasourceRoute.Get("/name/:name", reqDataSourceRead, routing.Wrap(hs.GetDataSourceByName))
+			datasourceRoute.Get("/uid/:uid/permissions", reqDataSourceAdmin, routing.Wrap(hs.GetDataSourcePermissions))
+			datasourceRoute.Post("/uid/:uid/permissions", reqDataSourceAdmin, bind(models.UpdateDataSourcePermissionsCommand{}), routing.Wrap(hs.UpdateDataSourcePermissions))
+		})
 
 		apiRoute.Get("/datasources/id/:name", routing.Wrap(GetDataSourceIdByName), reqSignedIn)
 
@@ -324,13 +396,18 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Get("/uid/:uid", routing.Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/uid/:uid", routing.Wrap(hs.DeleteDashboardByUID))
 
+			if hs.Cfg.PublicDashboards.Enabled {
+				dashboardRoute.Get("/uid/:uid/public-config", routing.Wrap(hs.GetPublicDashboardConfig))
+				dashboardRoute.Post("/uid/:uid/public-config", bind(models.SavePublicDashboardCommand{}), routing.Wrap(hs.SavePublicDashboardConfig))
+			}
+
 			dashboardRoute.Post("/calculate-diff", bind(dtos.CalculateDiffOptions{}), routing.Wrap(CalculateDashboardDiff))
 			dashboardRoute.Post("/trim", bind(models.TrimDashboardCommand{}), routing.Wrap(hs.TrimDashboard))
 
 			dashboardRoute.Post("/db", bind(models.SaveDashboardCommand{}), routing.Wrap(hs.PostDashboard))
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", GetDashboardTags)
-			dashboardRoute.Post("/import", bind(dtos.ImportDashboardCommand{}), routing.Wrap(hs.ImportDashboard))
+			dashboardRoute.Post("/import", idempotent, bind(dtos.ImportDashboardCommand{}), routing.Wrap(hs.ImportDashboard))
 
 			dashboardRoute.Group("/id/:dashboardId", func(dashIdRoute routing.RouteRegister) {
 				dashIdRoute.Get("/versions", routing.Wrap(GetDashboardVersions))
@@ -365,12 +442,12 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/search/", routing.Wrap(Search))
 
 		// metrics
-		apiRoute.Post("/tsdb/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
+		apiRoute.Post("/tsdb/query", rateLimit("query"), bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
 		apiRoute.Get("/tsdb/testdata/gensql", reqGrafanaAdmin, routing.Wrap(GenerateSQLTestData))
 		apiRoute.Get("/tsdb/testdata/random-walk", routing.Wrap(hs.GetTestDataRandomWalk))
 
 		// DataSource w/ expressions
-		apiRoute.Post("/ds/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
+		apiRoute.Post("/ds/query", rateLimit("query"), bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
 
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), routing.Wrap(hs.AlertTest))
@@ -444,8 +521,14 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/notifications/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadNotifications))
 		adminRoute.Post("/ldap/reload", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPConfigReload), routing.Wrap(hs.ReloadLDAPCfg))
 		adminRoute.Post("/ldap/sync/:id", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPUsersSync), routing.Wrap(hs.PostSyncUserWithLDAP))
+		adminRoute.Get("/ldap/sync-runs", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPStatusRead), routing.Wrap(hs.GetLDAPSyncRuns))
+		adminRoute.Get("/ldap/sync-runs/:runId", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPStatusRead), routing.Wrap(hs.GetLDAPSyncRun))
+		adminRoute.Post("/ldap/sync-runs", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPUsersSync), routing.Wrap(hs.PostLDAPSyncRun))
 		adminRoute.Get("/ldap/:username", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPUsersRead), routing.Wrap(hs.GetUserFromLDAP))
 		adminRoute.Get("/ldap/status", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPStatusRead), routing.Wrap(hs.GetLDAPStatus))
+		adminRoute.Get("/sessions", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenList, accesscontrol.ScopeGlobalUsersAll), routing.Wrap(hs.AdminSearchUserSessions))
+		adminRoute.Post("/sessions/revoke", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenUpdate, accesscontrol.ScopeGlobalUsersAll), bind(models.RevokeAuthTokensCmd{}), routing.Wrap(hs.AdminRevokeUserSessions))
+		adminRoute.Post("/sessions/reauthenticate", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersLogout, accesscontrol.ScopeGlobalUsersAll), bind(models.ForceReauthenticationCmd{}), routing.Wrap(hs.AdminForceReauthentication))
 	})
 
 	// Administering users
@@ -466,7 +549,7 @@ func (hs *HTTPServer) registerRoutes() {
 	})
 
 	// rendering
-	r.Get("/render/*", reqSignedIn, hs.RenderToPng)
+	r.Get("/render/*", reqSignedIn, rateLimit("render"), hs.RenderToPng)
 
 	// grafana.net proxy
 	r.Any("/api/gnet/*", reqSignedIn, ProxyGnetRequest)
@@ -482,6 +565,13 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/api/snapshots-delete/:deleteKey", reqSnapshotPublicModeOrSignedIn, routing.Wrap(DeleteDashboardSnapshotByDeleteKey))
 	r.Delete("/api/snapshots/:key", reqEditorRole, routing.Wrap(DeleteDashboardSnapshot))
 
+	// Public dashboards
+	if hs.Cfg.PublicDashboards.Enabled {
+		r.Get("/api/public/dashboards/:accessToken", routing.Wrap(hs.GetPublicDashboard))
+		r.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", bind(dtos.PublicDashboardQuery{}),
+			routing.Wrap(hs.QueryPublicDashboardPanel))
+	}
+
 	// Frontend logs
 	sourceMapStore := frontendlogging.NewSourceMapStore(hs.Cfg, hs.PluginManager, frontendlogging.ReadSourceMapFromFS)
 	r.Post("/log", middleware.RateLimit(hs.Cfg.Sentry.EndpointRPS, hs.Cfg.Sentry.EndpointBurst, time.Now),
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// auditChange records the change of a resource made by the request in the audit log, when it's enabled. Before is
+// nil for the created resources, and after for the deleted ones. The failures are logged, and don't fail the request
+// which already made the change.
+func auditChange(c *models.ReqContext, action models.AuditAction, resourceType models.AuditResourceType, resourceID, resourceName string, before, after interface{}) {
+	recordAuditEntry(c, &models.RecordAuditEntryCommand{
+		OrgId:        c.OrgId,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceId:   resourceID,
+		ResourceName: resourceName,
+		Before:       before,
+		After:        after,
+	})
+}
+
+func recordAuditEntry(c *models.ReqContext, cmd *models.RecordAuditEntryCommand) {
+	cmd.UserId = c.UserId
+	cmd.UserLogin = c.Login
+	if err := bus.DispatchCtx(c.Req.Context(), cmd); err != nil && !errors.Is(err, bus.ErrHandlerNotFound) {
+		plog.Error("Failed to record audit entry", "action", cmd.Action, "resourceType", cmd.ResourceType,
+			"resourceId", cmd.ResourceId, "error", err)
+	}
+}
+
+// userAuditState is the state of a user recorded in the audit log.
+type userAuditState struct {
+	Login          string `json:"login"`
+	Email          string `json:"email"`
+	Name           string `json:"name"`
+	IsGrafanaAdmin bool   `json:"isGrafanaAdmin"`
+	IsDisabled     bool   `json:"isDisabled"`
+}
+
+// getUserAuditState returns the state of the user, or nil when it can't be read.
+func getUserAuditState(c *models.ReqContext, userID int64) *userAuditState {
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return nil
+	}
+	return &userAuditState{
+		Login:          query.Result.Login,
+		Email:          query.Result.Email,
+		Name:           query.Result.Name,
+		IsGrafanaAdmin: query.Result.IsAdmin,
+		IsDisabled:     query.Result.IsDisabled,
+	}
+}
+
+func auditUserChange(c *models.ReqContext, action models.AuditAction, userID int64, before, after *userAuditState) {
+	cmd := &models.RecordAuditEntryCommand{
+		OrgId:        c.OrgId,
+		Action:       action,
+		ResourceType: models.AuditResourceUser,
+		ResourceId:   strconv.FormatInt(userID, 10),
+	}
+	if before != nil {
+		cmd.ResourceName, cmd.Before = before.Login, before
+	}
+	if after != nil {
+		cmd.ResourceName, cmd.After = after.Login, after
+	}
+	recordAuditEntry(c, cmd)
+}
+
+// pluginSettingsAuditState is the state of the settings of an app plugin recorded in the audit log, without their
+// secure JSON data.
+type pluginSettingsAuditState struct {
+	Enabled  bool                   `json:"enabled"`
+	Pinned   bool                   `json:"pinned"`
+	JsonData map[string]interface{} `json:"jsonData"`
+}
+
+// orgUserAuditState is the membership of a user in an org recorded in the audit log.
+type orgUserAuditState struct {
+	Login string          `json:"login"`
+	Role  models.RoleType `json:"role"`
+}
+
+// getOrgUserAuditState returns the membership of the user in the org, or nil when it can't be read.
+func getOrgUserAuditState(c *models.ReqContext, orgID, userID int64) *orgUserAuditState {
+	query := models.GetSignedInUserQuery{UserId: userID, OrgId: orgID}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil || query.Result.OrgRole == "" {
+		return nil
+	}
+	return &orgUserAuditState{Login: query.Result.Login, Role: query.Result.OrgRole}
+}
+
+// auditOrgUserChange records the change of the membership of a user in the audit log of the org, which may not be
+// the current org of the request.
+func auditOrgUserChange(c *models.ReqContext, action models.AuditAction, orgID, userID int64, before, after *orgUserAuditState) {
+	cmd := &models.RecordAuditEntryCommand{
+		OrgId:        orgID,
+		Action:       action,
+		ResourceType: models.AuditResourceOrgUser,
+		ResourceId:   strconv.FormatInt(userID, 10),
+	}
+	if before != nil {
+		cmd.ResourceName, cmd.Before = before.Login, before
+	}
+	if after != nil {
+		cmd.ResourceName, cmd.After = after.Login, after
+	}
+	recordAuditEntry(c, cmd)
+}
+
+// SearchAuditEntries returns a page of the audit log of the org, the most recent changes first.
+// GET /api/audit
+func (hs *HTTPServer) SearchAuditEntries(c *models.ReqContext) response.Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	query := models.SearchAuditEntriesQuery{
+		OrgId:        c.OrgId,
+		UserId:       c.QueryInt64("userId"),
+		Action:       models.AuditAction(c.Query("action")),
+		ResourceType: models.AuditResourceType(c.Query("resourceType")),
+		ResourceId:   c.Query("resourceId"),
+		Page:         page,
+		PerPage:      perPage,
+	}
+	for param, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if c.Query(param) == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, c.Query(param))
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid "+param+" time, expected RFC 3339 format", err)
+		}
+		*t = parsed
+	}
+
+	result, err := hs.SQLStore.SearchAuditEntries(c.Req.Context(), &query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search audit log", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
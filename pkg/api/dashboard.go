@@ -95,6 +95,11 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		return dashboardGuardianResponse(err)
 	}
 
+	etag := versionETag(dash.Id, dash.Version)
+	if rsp := notModified(c, etag, dash.Updated); rsp != nil {
+		return rsp
+	}
+
 	canEdit, _ := guardian.CanEdit()
 	canSave, _ := guardian.CanSave()
 	canAdmin, _ := guardian.CanAdmin()
@@ -185,7 +190,7 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 	}
 
 	c.TimeRequest(metrics.MApiDashboardGet)
-	return response.JSON(200, dto)
+	return withCacheValidators(response.JSON(200, dto), etag, dash.Updated)
 }
 
 func getUserLogin(ctx context.Context, userID int64) string {
@@ -242,6 +247,10 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		return dashboardGuardianResponse(err)
 	}
 
+	if rsp := preconditionFailed(c, versionETag(dash.Id, dash.Version)); rsp != nil {
+		return rsp
+	}
+
 	// disconnect all library elements for this dashboard
 	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c, dash.Id)
 	if err != nil {
@@ -260,6 +269,7 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 
 		return response.Error(500, "Failed to delete dashboard", err)
 	}
+	auditChange(c, models.AuditActionDelete, models.AuditResourceDashboard, dash.Uid, dash.Title, dash.Data, nil)
 
 	if hs.Live != nil {
 		err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.OrgId, c.ToUserDisplayDTO(), dash.Uid)
@@ -303,6 +313,24 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		}
 	}
 
+	// the previous version of the dashboard is needed for the If-Match header and the audit log
+	var previous *models.Dashboard
+	ifMatch := c.Req.Header.Get("If-Match") != ""
+	if (ifMatch || hs.Cfg.Audit.Enabled) && (dash.Id != 0 || dash.Uid != "") {
+		previous, _ = getDashboardHelper(c.Req.Context(), c.OrgId, dash.Id, dash.Uid)
+	}
+	if ifMatch {
+		if previous == nil {
+			return versionMismatchResponse()
+		}
+		if rsp := preconditionFailed(c, versionETag(previous.Id, previous.Version)); rsp != nil {
+			return rsp
+		}
+		// the dashboard is saved with the version of the If-Match header, so that its changes since are detected
+		dash.Version = previous.Version
+		cmd.Overwrite = false
+	}
+
 	svc := dashboards.NewProvisioningService(hs.SQLStore)
 	provisioningData, err := svc.GetProvisionedDashboardDataByDashboardID(dash.Id)
 	if err != nil {
@@ -373,15 +401,21 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return response.Error(500, "Error while connecting library panels", err)
 	}
 
+	if previous != nil {
+		auditChange(c, models.AuditActionUpdate, models.AuditResourceDashboard, dashboard.Uid, dashboard.Title, previous.Data, dashboard.Data)
+	} else {
+		auditChange(c, models.AuditActionCreate, models.AuditResourceDashboard, dashboard.Uid, dashboard.Title, nil, dashboard.Data)
+	}
+
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(200, util.DynMap{
+	return withCacheValidators(response.JSON(200, util.DynMap{
 		"status":  "success",
 		"slug":    dashboard.Slug,
 		"version": dashboard.Version,
 		"id":      dashboard.Id,
 		"uid":     dashboard.Uid,
 		"url":     dashboard.GetUrl(),
-	})
+	}), versionETag(dashboard.Id, dashboard.Version), dashboard.Updated)
 }
 
 func (hs *HTTPServer) dashboardSaveErrorToApiResponse(err error) response.Response {
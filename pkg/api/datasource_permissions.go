@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GetDataSourcePermissions returns the permissions of a data source.
+// GET /api/datasources/uid/:uid/permissions
+func (hs *HTTPServer) GetDataSourcePermissions(c *models.ReqContext) response.Response {
+	ds, err := getRawDataSourceByUID(c.Params(":uid"), c.OrgId)
+	if err != nil {
+		return dataSourcePermissionsErrorResponse(err, "Failed to get data source")
+	}
+
+	permissions, err := hs.DataSourcePermissions.GetPermissions(c.Req.Context(), ds)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source permissions", err)
+	}
+
+	return response.JSON(http.StatusOK, permissions)
+}
+
+// UpdateDataSourcePermissions replaces the permissions of a data source.
+// POST /api/datasources/uid/:uid/permissions
+func (hs *HTTPServer) UpdateDataSourcePermissions(c *models.ReqContext, cmd models.UpdateDataSourcePermissionsCommand) response.Response {
+	ds, err := getRawDataSourceByUID(c.Params(":uid"), c.OrgId)
+	if err != nil {
+		return dataSourcePermissionsErrorResponse(err, "Failed to get data source")
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.DataSourceId = ds.Id
+	if err := hs.DataSourcePermissions.UpdatePermissions(c.Req.Context(), &cmd); err != nil {
+		return dataSourcePermissionsErrorResponse(err, "Failed to update data source permissions")
+	}
+
+	return response.Success("Data source permissions updated")
+}
+
+// reqDataSourcePermission returns a middleware which requires the permission on the data source of the request,
+// identified by the uid, id or name parameter of its route. The requests for unknown data sources are left to the
+// route, which responds with a not found error.
+func (hs *HTTPServer) reqDataSourcePermission(permission models.DsPermissionType) func(c *models.ReqContext) {
+	return func(c *models.ReqContext) {
+		if c.OrgRole == models.ROLE_ADMIN {
+			return
+		}
+
+		query := models.GetDataSourceQuery{OrgId: c.OrgId, Uid: c.Params(":uid"), Name: c.Params(":name")}
+		if query.Uid == "" && query.Name == "" {
+			query.Id = c.ParamsInt64(":id")
+		}
+		if err := bus.Dispatch(&query); err != nil {
+			if errors.Is(err, models.ErrDataSourceNotFound) || errors.Is(err, models.ErrDataSourceIdentifierNotSet) {
+				return
+			}
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to get data source", err)
+			return
+		}
+
+		ok, err := hs.DataSourcePermissions.HasPermission(c.Req.Context(), c.SignedInUser, query.Result, permission)
+		if err != nil {
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to check data source permissions", err)
+			return
+		}
+		if !ok {
+			c.JsonApiErr(http.StatusForbidden, "Access denied to datasource", models.ErrDataSourceAccessDenied)
+		}
+	}
+}
+
+func dataSourcePermissionsErrorResponse(err error, message string) response.Response {
+	if errors.Is(err, models.ErrDataSourceNotFound) {
+		return response.Error(http.StatusNotFound, "Data source not found", nil)
+	}
+	if errors.Is(err, models.ErrDataSourcePermissionInvalid) ||
+		errors.Is(err, models.ErrDataSourcePermissionInfoMissing) ||
+		errors.Is(err, models.ErrDataSourcePermissionAdminRole) ||
+		errors.Is(err, models.ErrDataSourcePermissionDuplicate) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDataSourcePermissions gives the read permission on the data sources whose name starts with "shared", and no
+// other permission, to the users below org admin.
+type fakeDataSourcePermissions struct {
+	datasources.PermissionsService
+}
+
+func (f *fakeDataSourcePermissions) HasPermission(_ context.Context, user *models.SignedInUser, ds *models.DataSource, permission models.DsPermissionType) (bool, error) {
+	if user.HasRole(models.ROLE_ADMIN) {
+		return true, nil
+	}
+	return strings.HasPrefix(ds.Name, "shared") && permission <= models.DsPermissionRead, nil
+}
+
+func TestDataSourcePermissions(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataSourcePermissionsEnabled = true
+	hs := &HTTPServer{
+		Bus:                   bus.GetBus(),
+		Cfg:                   cfg,
+		PluginManager:         &fakePluginManager{},
+		DataSourcePermissions: &fakeDataSourcePermissions{},
+	}
+
+	loggedInUserScenario(t, "When listing the data sources with permissions enforced", "/api/datasources/", func(sc *scenarioContext) {
+		bus.AddHandler("test", func(query *models.GetDataSourcesQuery) error {
+			query.Result = []*models.DataSource{{Name: "shared-loki", Password: "secret"}, {Name: "private-loki"}}
+			return nil
+		})
+
+		sc.handlerFunc = hs.GetDataSources
+		sc.fakeReq("GET", "/api/datasources").exec()
+
+		var respJSON []map[string]interface{}
+		require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&respJSON))
+		require.Len(t, respJSON, 1)
+		require.Equal(t, "shared-loki", respJSON[0]["name"])
+		require.Equal(t, "", respJSON[0]["password"])
+	})
+
+	t.Run("The passwords of data sources are only returned to their admins", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			query.Result = &models.DataSource{Id: 1, Uid: "shared", Name: "shared-loki", Password: "secret", BasicAuthPassword: "basic-secret"}
+			return nil
+		})
+
+		for _, tc := range []struct {
+			role     models.RoleType
+			password string
+		}{
+			{role: models.ROLE_VIEWER, password: ""},
+			{role: models.ROLE_ADMIN, password: "secret"},
+		} {
+			sc := setupScenarioContext(t, "/api/datasources/uid/shared")
+			role := tc.role
+			sc.m.Get("/api/datasources/uid/:uid", func(c *models.ReqContext) {
+				c.SignedInUser = &models.SignedInUser{UserId: testUserID, OrgId: testOrgID, OrgRole: role}
+			}, routing.Wrap(hs.GetDataSourceByUID))
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+
+			var respJSON map[string]interface{}
+			require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&respJSON))
+			require.Equal(t, tc.password, respJSON["password"], tc.role)
+			if tc.password == "" {
+				require.Equal(t, "", respJSON["basicAuthPassword"], tc.role)
+			}
+		}
+	})
+
+	t.Run("Routes of data sources require their permission", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			switch query.Uid {
+			case "shared":
+				query.Result = &models.DataSource{Id: 1, Uid: "shared", Name: "shared-loki"}
+			case "private":
+				query.Result = &models.DataSource{Id: 2, Uid: "private", Name: "private-loki"}
+			default:
+				return models.ErrDataSourceNotFound
+			}
+			return nil
+		})
+
+		for _, tc := range []struct {
+			uid        string
+			permission models.DsPermissionType
+			role       models.RoleType
+			status     int
+		}{
+			{uid: "shared", permission: models.DsPermissionRead, role: models.ROLE_VIEWER, status: http.StatusOK},
+			{uid: "shared", permission: models.DsPermissionWrite, role: models.ROLE_EDITOR, status: http.StatusForbidden},
+			{uid: "private", permission: models.DsPermissionRead, role: models.ROLE_EDITOR, status: http.StatusForbidden},
+			{uid: "private", permission: models.DsPermissionAdmin, role: models.ROLE_ADMIN, status: http.StatusOK},
+			{uid: "unknown", permission: models.DsPermissionRead, role: models.ROLE_VIEWER, status: http.StatusOK},
+		} {
+			sc := setupScenarioContext(t, "/api/datasources/uid/"+tc.uid)
+			role := tc.role
+			sc.m.Get("/api/datasources/uid/:uid", func(c *models.ReqContext) {
+				c.SignedInUser = &models.SignedInUser{UserId: testUserID, OrgId: testOrgID, OrgRole: role}
+			}, hs.reqDataSourcePermission(tc.permission), routing.Wrap(func(c *models.ReqContext) response.Response {
+				return response.Success("ok")
+			}))
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, tc.status, sc.resp.Code, "%s permission of %s on %s", tc.permission, tc.role, tc.uid)
+		}
+	})
+}
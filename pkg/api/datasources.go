@@ -29,6 +29,25 @@ func (hs *HTTPServer) GetDataSources(c *models.ReqContext) response.Response {
 
 	result := make(dtos.DataSourceList, 0)
 	for _, ds := range query.Result {
+		password := ds.Password
+		if hs.Cfg.DataSourcePermissionsEnabled {
+			ok, err := hs.DataSourcePermissions.HasPermission(c.Req.Context(), c.SignedInUser, ds, models.DsPermissionRead)
+			if err != nil {
+				return response.Error(500, "Failed to check data source permissions", err)
+			}
+			if !ok {
+				continue
+			}
+			// the legacy password is stored in plaintext, so it's only listed for the admins of the data source
+			isAdmin, err := hs.DataSourcePermissions.HasPermission(c.Req.Context(), c.SignedInUser, ds, models.DsPermissionAdmin)
+			if err != nil {
+				return response.Error(500, "Failed to check data source permissions", err)
+			}
+			if !isAdmin {
+				password = ""
+			}
+		}
+
 		dsItem := dtos.DataSourceListItemDTO{
 			OrgId:     ds.OrgId,
 			Id:        ds.Id,
@@ -38,7 +57,7 @@ func (hs *HTTPServer) GetDataSources(c *models.ReqContext) response.Response {
 			Type:      ds.Type,
 			TypeName:  ds.Type,
 			Access:    ds.Access,
-			Password:  ds.Password,
+			Password:  password,
 			Database:  ds.Database,
 			User:      ds.User,
 			BasicAuth: ds.BasicAuth,
@@ -62,7 +81,7 @@ func (hs *HTTPServer) GetDataSources(c *models.ReqContext) response.Response {
 	return response.JSON(200, &result)
 }
 
-func GetDataSourceById(c *models.ReqContext) response.Response {
+func (hs *HTTPServer) GetDataSourceById(c *models.ReqContext) response.Response {
 	query := models.GetDataSourceQuery{
 		Id:    c.ParamsInt64(":id"),
 		OrgId: c.OrgId,
@@ -78,10 +97,7 @@ func GetDataSourceById(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to query datasources", err)
 	}
 
-	ds := query.Result
-	dtos := convertModelToDtos(ds)
-
-	return response.JSON(200, &dtos)
+	return hs.dataSourceResponse(c, query.Result)
 }
 
 func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Response {
@@ -103,6 +119,10 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if rsp := preconditionFailed(c, versionETag(ds.Id, ds.Version)); rsp != nil {
+		return rsp
+	}
+
 	cmd := &models.DeleteDataSourceCommand{ID: id, OrgID: c.OrgId}
 
 	err = bus.Dispatch(cmd)
@@ -110,13 +130,14 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 		return response.Error(500, "Failed to delete datasource", err)
 	}
 
+	auditChange(c, models.AuditActionDelete, models.AuditResourceDataSource, ds.Uid, ds.Name, convertModelToDtos(ds), nil)
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
 
 	return response.Success("Data source deleted")
 }
 
 // GET /api/datasources/uid/:uid
-func GetDataSourceByUID(c *models.ReqContext) response.Response {
+func (hs *HTTPServer) GetDataSourceByUID(c *models.ReqContext) response.Response {
 	ds, err := getRawDataSourceByUID(c.Params(":uid"), c.OrgId)
 
 	if err != nil {
@@ -126,8 +147,7 @@ func GetDataSourceByUID(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to query datasources", err)
 	}
 
-	dtos := convertModelToDtos(ds)
-	return response.JSON(200, &dtos)
+	return hs.dataSourceResponse(c, ds)
 }
 
 // DELETE /api/datasources/uid/:uid
@@ -150,6 +170,10 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if rsp := preconditionFailed(c, versionETag(ds.Id, ds.Version)); rsp != nil {
+		return rsp
+	}
+
 	cmd := &models.DeleteDataSourceCommand{UID: uid, OrgID: c.OrgId}
 
 	err = bus.Dispatch(cmd)
@@ -157,6 +181,7 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 		return response.Error(500, "Failed to delete datasource", err)
 	}
 
+	auditChange(c, models.AuditActionDelete, models.AuditResourceDataSource, ds.Uid, ds.Name, convertModelToDtos(ds), nil)
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
 
 	return response.Success("Data source deleted")
@@ -181,12 +206,17 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if rsp := preconditionFailed(c, versionETag(getCmd.Result.Id, getCmd.Result.Version)); rsp != nil {
+		return rsp
+	}
+
 	cmd := &models.DeleteDataSourceCommand{Name: name, OrgID: c.OrgId}
 	err := bus.Dispatch(cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
 
+	auditChange(c, models.AuditActionDelete, models.AuditResourceDataSource, getCmd.Result.Uid, getCmd.Result.Name, convertModelToDtos(getCmd.Result), nil)
 	hs.Live.HandleDatasourceDelete(c.OrgId, getCmd.Result.Uid)
 
 	return response.JSON(200, util.DynMap{
@@ -223,6 +253,7 @@ func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) respon
 	}
 
 	ds := convertModelToDtos(cmd.Result)
+	auditChange(c, models.AuditActionCreate, models.AuditResourceDataSource, ds.UID, ds.Name, nil, ds)
 	return response.JSON(200, util.DynMap{
 		"message":    "Datasource added",
 		"id":         cmd.Result.Id,
@@ -244,9 +275,31 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 		return response.Error(500, "Failed to update datasource", err)
 	}
 
+	// the previous version of the data source is needed for the If-Match header and the audit log
+	var previous *models.DataSource
+	ifMatch := c.Req.Header.Get("If-Match") != ""
+	if ifMatch || hs.Cfg.Audit.Enabled {
+		if previous, err = getRawDataSourceById(cmd.Id, cmd.OrgId); err != nil && !errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(500, "Failed to query datasource", err)
+		}
+	}
+	if ifMatch {
+		if previous == nil {
+			return versionMismatchResponse()
+		}
+		if rsp := preconditionFailed(c, versionETag(previous.Id, previous.Version)); rsp != nil {
+			return rsp
+		}
+		// the data source is saved with the version of the If-Match header, so that its changes since are detected
+		cmd.Version = previous.Version
+	}
+
 	err = bus.Dispatch(&cmd)
 	if err != nil {
 		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
+			if ifMatch {
+				return versionMismatchResponse()
+			}
 			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
 		}
 		return response.Error(500, "Failed to update datasource", err)
@@ -265,15 +318,18 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 	}
 
 	datasourceDTO := convertModelToDtos(query.Result)
+	if previous != nil {
+		auditChange(c, models.AuditActionUpdate, models.AuditResourceDataSource, datasourceDTO.UID, datasourceDTO.Name, convertModelToDtos(previous), datasourceDTO)
+	}
 
 	hs.Live.HandleDatasourceUpdate(c.OrgId, datasourceDTO.UID)
 
-	return response.JSON(200, util.DynMap{
+	return withCacheValidators(response.JSON(200, util.DynMap{
 		"message":    "Datasource updated",
 		"id":         cmd.Id,
 		"name":       cmd.Name,
 		"datasource": datasourceDTO,
-	})
+	}), versionETag(query.Result.Id, query.Result.Version), query.Result.Updated)
 }
 
 func fillWithSecureJSONData(cmd *models.UpdateDataSourceCommand) error {
@@ -327,7 +383,7 @@ func getRawDataSourceByUID(uid string, orgID int64) (*models.DataSource, error)
 }
 
 // Get /api/datasources/name/:name
-func GetDataSourceByName(c *models.ReqContext) response.Response {
+func (hs *HTTPServer) GetDataSourceByName(c *models.ReqContext) response.Response {
 	query := models.GetDataSourceQuery{Name: c.Params(":name"), OrgId: c.OrgId}
 
 	if err := bus.Dispatch(&query); err != nil {
@@ -337,8 +393,29 @@ func GetDataSourceByName(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to query datasources", err)
 	}
 
-	dtos := convertModelToDtos(query.Result)
-	return response.JSON(200, &dtos)
+	return hs.dataSourceResponse(c, query.Result)
+}
+
+// dataSourceResponse returns the data source with its ETag, or 304 to a conditional request for the version the
+// client has. The legacy passwords are stored in plaintext, so they are only returned to the admins of the data
+// source.
+func (hs *HTTPServer) dataSourceResponse(c *models.ReqContext, ds *models.DataSource) response.Response {
+	etag := versionETag(ds.Id, ds.Version)
+	if rsp := notModified(c, etag, ds.Updated); rsp != nil {
+		return rsp
+	}
+
+	isAdmin, err := hs.DataSourcePermissions.HasPermission(c.Req.Context(), c.SignedInUser, ds, models.DsPermissionAdmin)
+	if err != nil {
+		return response.Error(500, "Failed to check data source permissions", err)
+	}
+
+	dtos := convertModelToDtos(ds)
+	if !isAdmin {
+		dtos.Password = ""
+		dtos.BasicAuthPassword = ""
+	}
+	return withCacheValidators(response.JSON(200, &dtos), etag, ds.Updated)
 }
 
 // Get /api/datasources/id/:name
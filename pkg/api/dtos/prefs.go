@@ -1,13 +1,15 @@
 package dtos
 
 type Prefs struct {
-	Theme           string `json:"theme"`
-	HomeDashboardID int64  `json:"homeDashboardId"`
-	Timezone        string `json:"timezone"`
+	Theme                string `json:"theme"`
+	HomeDashboardID      int64  `json:"homeDashboardId"`
+	Timezone             string `json:"timezone"`
+	QueryCachingDisabled bool   `json:"queryCachingDisabled"`
 }
 
 type UpdatePrefsCmd struct {
-	Theme           string `json:"theme"`
-	HomeDashboardID int64  `json:"homeDashboardId"`
-	Timezone        string `json:"timezone"`
+	Theme                string `json:"theme"`
+	HomeDashboardID      int64  `json:"homeDashboardId"`
+	Timezone             string `json:"timezone"`
+	QueryCachingDisabled bool   `json:"queryCachingDisabled"`
 }
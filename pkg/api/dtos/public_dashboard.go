@@ -0,0 +1,16 @@
+package dtos
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type PublicDashboard struct {
+	Dashboard  *simplejson.Json                  `json:"dashboard"`
+	TimeRanges []models.PublicDashboardTimeRange `json:"timeRanges"`
+}
+
+type PublicDashboardQuery struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
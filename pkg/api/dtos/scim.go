@@ -0,0 +1,93 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The schemas of the SCIM 2.0 resources and messages, from RFC 7643 and RFC 7644.
+const (
+	SCIMUserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMGroupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMPatchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMResourceTypeSchema          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// SCIMUser is a SCIM user, which is a user of the org.
+type SCIMUser struct {
+	Schemas     []string        `json:"schemas"`
+	Id          string          `json:"id,omitempty"`
+	ExternalId  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *SCIMName       `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Emails      []SCIMEmail     `json:"emails,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Groups      []SCIMReference `json:"groups,omitempty"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMReference is a member of a group, or a group of a user.
+type SCIMReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMGroup is a SCIM group, which is a team of the org.
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	Id          string          `json:"id,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMReference `json:"members,omitempty"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type SCIMListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is an operation of a patch request. Op is add, remove or replace, in any case.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
@@ -13,4 +13,20 @@ type UserToken struct {
 	BrowserVersion         string    `json:"browserVersion"`
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
+	ExpiresAt              time.Time `json:"expiresAt"`
+}
+
+// UserSession is a user token listed with its user.
+type UserSession struct {
+	UserToken
+	UserId int64  `json:"userId"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+}
+
+type SearchUserSessionsResult struct {
+	TotalCount int64          `json:"totalCount"`
+	Sessions   []*UserSession `json:"sessions"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"perPage"`
 }
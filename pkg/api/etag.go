@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// versionETag returns the ETag of a version of a dashboard, folder or data source. The ETags are weak, as the
+// responses also have data which changes without a new version, like the permissions of the user.
+func versionETag(id int64, version int) string {
+	return fmt.Sprintf(`W/"%d-%d"`, id, version)
+}
+
+// etagMatches reports whether the ETag is in the ETags of an If-Match or If-None-Match header. The opaque tags are
+// compared, so that the weak ETags of the versions can be used in If-Match headers.
+func etagMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified returns the 304 response to a conditional GET request of a resource which hasn't changed since the
+// client got it, or nil. If-Modified-Since is ignored when the request has an If-None-Match header.
+func notModified(c *models.ReqContext, etag string, updated time.Time) response.Response {
+	if ifNoneMatch := c.Req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if !etagMatches(ifNoneMatch, etag) {
+			return nil
+		}
+	} else {
+		since, err := http.ParseTime(c.Req.Header.Get("If-Modified-Since"))
+		if err != nil || updated.Truncate(time.Second).After(since) {
+			return nil
+		}
+	}
+
+	rsp := response.CreateNormalResponse(http.Header{}, nil, http.StatusNotModified)
+	return withCacheValidators(rsp, etag, updated)
+}
+
+// withCacheValidators sets the ETag and Last-Modified headers of the resource of the response.
+func withCacheValidators(rsp *response.NormalResponse, etag string, updated time.Time) *response.NormalResponse {
+	rsp.SetHeader("ETag", etag)
+	if !updated.IsZero() {
+		rsp.SetHeader("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+	return rsp
+}
+
+// preconditionFailed returns the 412 response to a request with an If-Match header which doesn't match the current
+// version of the resource, or nil.
+func preconditionFailed(c *models.ReqContext, etag string) response.Response {
+	ifMatch := c.Req.Header.Get("If-Match")
+	if ifMatch == "" || etagMatches(ifMatch, etag) {
+		return nil
+	}
+	return versionMismatchResponse()
+}
+
+func versionMismatchResponse() response.Response {
+	return response.JSON(http.StatusPreconditionFailed, util.DynMap{
+		"status":  "version-mismatch",
+		"message": "The resource has been changed since the version of the If-Match header",
+	})
+}
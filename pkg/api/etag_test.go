@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := versionETag(1, 3)
+	require.Equal(t, `W/"1-3"`, etag)
+
+	assert.True(t, etagMatches(`W/"1-3"`, etag))
+	assert.True(t, etagMatches(`"1-3"`, etag))
+	assert.True(t, etagMatches(`"1-2", W/"1-3"`, etag))
+	assert.True(t, etagMatches(`*`, etag))
+	assert.False(t, etagMatches(`W/"1-2"`, etag))
+	assert.False(t, etagMatches(`W/"2-3"`, etag))
+}
+
+func TestDataSourceConditionalRequests(t *testing.T) {
+	cfg := setting.NewCfg()
+	hs := &HTTPServer{Cfg: cfg, DataSourcePermissions: &datasources.PermissionsServiceImpl{Cfg: cfg}}
+	updated := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	stubDataSource := func() {
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			query.Result = &models.DataSource{Id: 1, Uid: "ds", OrgId: testOrgID, Name: "ds", Version: 3, Updated: updated}
+			return nil
+		})
+	}
+
+	loggedInUserScenario(t, "When calling GET without validators on", "/api/datasources/uid/ds", func(sc *scenarioContext) {
+		stubDataSource()
+		sc.handlerFunc = hs.GetDataSourceByUID
+		sc.fakeReq("GET", sc.url).exec()
+
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, `W/"1-3"`, sc.resp.Header().Get("ETag"))
+		assert.Equal(t, updated.Format(http.TimeFormat), sc.resp.Header().Get("Last-Modified"))
+	})
+
+	loggedInUserScenario(t, "When calling GET with the current ETag on", "/api/datasources/uid/ds", func(sc *scenarioContext) {
+		stubDataSource()
+		sc.handlerFunc = hs.GetDataSourceByUID
+		sc.fakeReq("GET", sc.url)
+		sc.req.Header.Set("If-None-Match", `W/"1-3"`)
+		sc.exec()
+
+		assert.Equal(t, 304, sc.resp.Code)
+		assert.Empty(t, sc.resp.Body.String())
+	})
+
+	loggedInUserScenario(t, "When calling GET with an old ETag on", "/api/datasources/uid/ds", func(sc *scenarioContext) {
+		stubDataSource()
+		sc.handlerFunc = hs.GetDataSourceByUID
+		sc.fakeReq("GET", sc.url)
+		sc.req.Header.Set("If-None-Match", `W/"1-2"`)
+		// If-Modified-Since is ignored with If-None-Match
+		sc.req.Header.Set("If-Modified-Since", updated.Format(http.TimeFormat))
+		sc.exec()
+
+		assert.Equal(t, 200, sc.resp.Code)
+	})
+
+	loggedInUserScenario(t, "When calling GET with If-Modified-Since on", "/api/datasources/uid/ds", func(sc *scenarioContext) {
+		stubDataSource()
+		sc.handlerFunc = hs.GetDataSourceByUID
+		sc.fakeReq("GET", sc.url)
+		sc.req.Header.Set("If-Modified-Since", updated.Format(http.TimeFormat))
+		sc.exec()
+		assert.Equal(t, 304, sc.resp.Code)
+
+		sc.fakeReq("GET", sc.url)
+		sc.req.Header.Set("If-Modified-Since", updated.Add(-time.Second).Format(http.TimeFormat))
+		sc.exec()
+		assert.Equal(t, 200, sc.resp.Code)
+	})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE with an old ETag on", "DELETE", "/api/datasources/uid/ds",
+		"/api/datasources/uid/:uid", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			stubDataSource()
+			bus.AddHandler("test", func(cmd *models.DeleteDataSourceCommand) error {
+				t.Fatal("the data source should not be deleted")
+				return nil
+			})
+			hs := &HTTPServer{Bus: bus.GetBus(), Cfg: setting.NewCfg()}
+			sc.handlerFunc = hs.DeleteDataSourceByUID
+			sc.fakeReq("DELETE", sc.url)
+			sc.req.Header.Set("If-Match", `W/"1-2"`)
+			sc.exec()
+
+			assert.Equal(t, 412, sc.resp.Code)
+			assert.Contains(t, sc.resp.Body.String(), "version-mismatch")
+		})
+}
@@ -42,8 +42,13 @@ func (hs *HTTPServer) GetFolderByUID(c *models.ReqContext) response.Response {
 		return ToFolderErrorResponse(err)
 	}
 
+	etag := versionETag(folder.Id, folder.Version)
+	if rsp := notModified(c, etag, folder.Updated); rsp != nil {
+		return rsp
+	}
+
 	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, folder))
+	return withCacheValidators(response.JSON(200, toFolderDto(c.Req.Context(), g, folder)), etag, folder.Updated)
 }
 
 func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
@@ -53,8 +58,13 @@ func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
 		return ToFolderErrorResponse(err)
 	}
 
+	etag := versionETag(folder.Id, folder.Version)
+	if rsp := notModified(c, etag, folder.Updated); rsp != nil {
+		return rsp
+	}
+
 	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, folder))
+	return withCacheValidators(response.JSON(200, toFolderDto(c.Req.Context(), g, folder)), etag, folder.Updated)
 }
 
 func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolderCommand) response.Response {
@@ -77,17 +87,41 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolder
 
 func (hs *HTTPServer) UpdateFolder(c *models.ReqContext, cmd models.UpdateFolderCommand) response.Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	if c.Req.Header.Get("If-Match") != "" {
+		folder, err := s.GetFolderByUID(c.Params(":uid"))
+		if err != nil {
+			return ToFolderErrorResponse(err)
+		}
+		if rsp := preconditionFailed(c, versionETag(folder.Id, folder.Version)); rsp != nil {
+			return rsp
+		}
+		// the folder is saved with the version of the If-Match header, so that its changes since are detected
+		cmd.Version = folder.Version
+		cmd.Overwrite = false
+	}
+
 	err := s.UpdateFolder(c.Params(":uid"), &cmd)
 	if err != nil {
 		return ToFolderErrorResponse(err)
 	}
 
 	g := guardian.New(cmd.Result.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, cmd.Result))
+	return withCacheValidators(response.JSON(200, toFolderDto(c.Req.Context(), g, cmd.Result)),
+		versionETag(cmd.Result.Id, cmd.Result.Version), cmd.Result.Updated)
 }
 
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	if c.Req.Header.Get("If-Match") != "" {
+		folder, err := s.GetFolderByUID(c.Params(":uid"))
+		if err != nil {
+			return ToFolderErrorResponse(err)
+		}
+		if rsp := preconditionFailed(c, versionETag(folder.Id, folder.Version)); rsp != nil {
+			return rsp
+		}
+	}
+
 	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c, c.Params(":uid"))
 	if err != nil {
 		if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -167,6 +170,90 @@ func TestHealthAPI_DatabaseHealthCached(t *testing.T) {
 	require.True(t, healthy.(bool))
 }
 
+func TestReadyAPI(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t)
+	m.Get("/api/ready", hs.apiReadyHandler)
+	ready := func() (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ready", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := ready()
+	require.Equal(t, 503, code)
+	require.JSONEq(t, `{"status": "starting"}`, body)
+
+	hs.ready = true
+	code, body = ready()
+	require.Equal(t, 200, code)
+	require.JSONEq(t, `{"status": "ready"}`, body)
+
+	hs.Drain(context.Background())
+	code, body = ready()
+	require.Equal(t, 503, code)
+	require.JSONEq(t, `{"status": "shutting down"}`, body)
+}
+
+func TestHTTPServer_Drain(t *testing.T) {
+	serve := func(t *testing.T, handler http.HandlerFunc) (*HTTPServer, string) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		hs := &HTTPServer{log: log.New("test"), Cfg: setting.NewCfg(), httpSrv: &http.Server{Handler: handler}}
+		hs.Cfg.ShutdownGracePeriod = time.Second
+		go func() {
+			_ = hs.httpSrv.Serve(listener)
+		}()
+		return hs, "http://" + listener.Addr().String()
+	}
+
+	t.Run("Requests in flight complete", func(t *testing.T) {
+		started := make(chan struct{})
+		hs, url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		result := make(chan error)
+		go func() {
+			resp, err := http.Get(url)
+			if err == nil {
+				err = resp.Body.Close()
+			}
+			result <- err
+		}()
+		<-started
+		hs.Drain(context.Background())
+		require.NoError(t, <-result)
+
+		_, err := http.Get(url)
+		require.Error(t, err)
+	})
+
+	t.Run("Requests in flight are closed after the grace period", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+		hs, url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		})
+		hs.Cfg.ShutdownGracePeriod = 50 * time.Millisecond
+
+		result := make(chan error)
+		go func() {
+			resp, err := http.Get(url)
+			if err == nil {
+				err = resp.Body.Close()
+			}
+			result <- err
+		}()
+		<-started
+		hs.Drain(context.Background())
+		require.Error(t, <-result)
+	})
+}
+
 func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*macaron.Macaron, *HTTPServer) {
 	t.Helper()
 
@@ -12,7 +12,9 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/grafana/grafana/pkg/services/ldapsync"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 
@@ -42,8 +44,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/saml"
 	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/shorturls"
@@ -72,40 +76,49 @@ type HTTPServer struct {
 	httpSrv     *http.Server
 	middlewares []macaron.Handler
 
-	PluginContextProvider  *plugincontext.Provider                 `inject:""`
-	RouteRegister          routing.RouteRegister                   `inject:""`
-	Bus                    bus.Bus                                 `inject:""`
-	RenderService          rendering.Service                       `inject:""`
-	Cfg                    *setting.Cfg                            `inject:""`
-	SettingsProvider       setting.Provider                        `inject:""`
-	HooksService           *hooks.HooksService                     `inject:""`
-	CacheService           *localcache.CacheService                `inject:""`
-	DatasourceCache        datasources.CacheService                `inject:""`
-	AuthTokenService       models.UserTokenService                 `inject:""`
-	QuotaService           *quota.QuotaService                     `inject:""`
-	RemoteCacheService     *remotecache.RemoteCache                `inject:""`
-	ProvisioningService    provisioning.ProvisioningService        `inject:""`
-	Login                  login.Service                           `inject:""`
-	License                models.Licensing                        `inject:""`
-	AccessControl          accesscontrol.AccessControl             `inject:""`
-	BackendPluginManager   backendplugin.Manager                   `inject:""`
-	DataProxy              *datasourceproxy.DatasourceProxyService `inject:""`
-	PluginRequestValidator models.PluginRequestValidator           `inject:""`
-	PluginManager          plugins.Manager                         `inject:""`
-	SearchService          *search.SearchService                   `inject:""`
-	ShortURLService        shorturls.Service                       `inject:""`
-	Live                   *live.GrafanaLive                       `inject:""`
-	LivePushGateway        *pushhttp.Gateway                       `inject:""`
-	ContextHandler         *contexthandler.ContextHandler          `inject:""`
-	SQLStore               *sqlstore.SQLStore                      `inject:""`
-	DataService            *tsdb.Service                           `inject:""`
-	PluginDashboardService *plugindashboards.Service               `inject:""`
-	AlertEngine            *alerting.AlertEngine                   `inject:""`
-	LoadSchemaService      *schemaloader.SchemaLoaderService       `inject:""`
-	Alertmanager           *notifier.Alertmanager                  `inject:""`
-	LibraryPanelService    librarypanels.Service                   `inject:""`
-	LibraryElementService  libraryelements.Service                 `inject:""`
-	Listener               net.Listener
+	// lifecycleMtx guards httpSrv against Drain, and the readiness of the server.
+	lifecycleMtx sync.Mutex
+	ready        bool
+	draining     bool
+
+	PluginContextProvider   *plugincontext.Provider                 `inject:""`
+	RouteRegister           routing.RouteRegister                   `inject:""`
+	Bus                     bus.Bus                                 `inject:""`
+	RenderService           rendering.Service                       `inject:""`
+	Cfg                     *setting.Cfg                            `inject:""`
+	SettingsProvider        setting.Provider                        `inject:""`
+	HooksService            *hooks.HooksService                     `inject:""`
+	CacheService            *localcache.CacheService                `inject:""`
+	DatasourceCache         datasources.CacheService                `inject:""`
+	AuthTokenService        models.UserTokenService                 `inject:""`
+	QuotaService            *quota.QuotaService                     `inject:""`
+	RemoteCacheService      *remotecache.RemoteCache                `inject:""`
+	ProvisioningService     provisioning.ProvisioningService        `inject:""`
+	Login                   login.Service                           `inject:""`
+	License                 models.Licensing                        `inject:""`
+	AccessControl           accesscontrol.AccessControl             `inject:""`
+	BackendPluginManager    backendplugin.Manager                   `inject:""`
+	DataProxy               *datasourceproxy.DatasourceProxyService `inject:""`
+	PluginRequestValidator  models.PluginRequestValidator           `inject:""`
+	PluginManager           plugins.Manager                         `inject:""`
+	SearchService           *search.SearchService                   `inject:""`
+	ShortURLService         shorturls.Service                       `inject:""`
+	PublicDashboardsService publicdashboards.Service                `inject:""`
+	DataSourcePermissions   datasources.PermissionsService          `inject:""`
+	Live                    *live.GrafanaLive                       `inject:""`
+	LivePushGateway         *pushhttp.Gateway                       `inject:""`
+	ContextHandler          *contexthandler.ContextHandler          `inject:""`
+	SQLStore                *sqlstore.SQLStore                      `inject:""`
+	DataService             *tsdb.Service                           `inject:""`
+	PluginDashboardService  *plugindashboards.Service               `inject:""`
+	AlertEngine             *alerting.AlertEngine                   `inject:""`
+	LoadSchemaService       *schemaloader.SchemaLoaderService       `inject:""`
+	Alertmanager            *notifier.Alertmanager                  `inject:""`
+	LibraryPanelService     librarypanels.Service                   `inject:""`
+	LibraryElementService   libraryelements.Service                 `inject:""`
+	LDAPSyncService         *ldapsync.LDAPSyncService               `inject:""`
+	SAMLService             *saml.SAMLService                       `inject:""`
+	Listener                net.Listener
 }
 
 func (hs *HTTPServer) Init() error {
@@ -128,11 +141,13 @@ func (hs *HTTPServer) Run(ctx context.Context) error {
 
 	// Remove any square brackets enclosing IPv6 addresses, a format we support for backwards compatibility
 	host := strings.TrimSuffix(strings.TrimPrefix(hs.Cfg.HTTPAddr, "["), "]")
+	hs.lifecycleMtx.Lock()
 	hs.httpSrv = &http.Server{
 		Addr:        net.JoinHostPort(host, hs.Cfg.HTTPPort),
 		Handler:     hs.macaron,
 		ReadTimeout: hs.Cfg.ReadTimeout,
 	}
+	hs.lifecycleMtx.Unlock()
 	switch hs.Cfg.Protocol {
 	case setting.HTTP2Scheme:
 		if err := hs.configureHttp2(); err != nil {
@@ -153,6 +168,10 @@ func (hs *HTTPServer) Run(ctx context.Context) error {
 	hs.log.Info("HTTP Server Listen", "address", listener.Addr().String(), "protocol",
 		hs.Cfg.Protocol, "subUrl", hs.Cfg.AppSubURL, "socket", hs.Cfg.SocketPath)
 
+	hs.lifecycleMtx.Lock()
+	hs.ready = !hs.draining
+	hs.lifecycleMtx.Unlock()
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 
@@ -192,6 +211,38 @@ func (hs *HTTPServer) Run(ctx context.Context) error {
 	return nil
 }
 
+// Drain shuts the server down gracefully. It makes /api/ready fail, waits for the shutdown delay so that load
+// balancers stop sending requests to it, and then stops accepting requests and waits for the ones in flight to
+// complete for up to the shutdown grace period, after which their connections are closed.
+func (hs *HTTPServer) Drain(ctx context.Context) {
+	hs.lifecycleMtx.Lock()
+	hs.ready = false
+	hs.draining = true
+	srv := hs.httpSrv
+	hs.lifecycleMtx.Unlock()
+	if srv == nil {
+		return
+	}
+
+	if hs.Cfg.ShutdownDelay > 0 {
+		hs.log.Info("Waiting before draining requests", "delay", hs.Cfg.ShutdownDelay)
+		select {
+		case <-time.After(hs.Cfg.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	hs.log.Info("Draining requests", "gracePeriod", hs.Cfg.ShutdownGracePeriod)
+	ctx, cancel := context.WithTimeout(ctx, hs.Cfg.ShutdownGracePeriod)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		hs.log.Warn("Requests didn't complete within the grace period, closing their connections", "error", err)
+		if err := srv.Close(); err != nil {
+			hs.log.Error("Failed to close server", "error", err)
+		}
+	}
+}
+
 func (hs *HTTPServer) getListener() (net.Listener, error) {
 	if hs.Listener != nil {
 		return hs.Listener, nil
@@ -361,6 +412,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	// and should not be redirected or rejected.
 	m.Use(hs.healthzHandler)
 	m.Use(hs.apiHealthHandler)
+	m.Use(hs.apiReadyHandler)
 	m.Use(hs.metricsEndpoint)
 
 	m.Use(hs.ContextHandler.Middleware)
@@ -373,6 +425,14 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 
 	m.Use(middleware.HandleNoCacheHeader)
 	m.Use(middleware.AddCSPHeader(hs.Cfg, hs.log))
+	m.Use(middleware.APIKeyScopes())
+
+	if hs.Cfg.LoadShedding.Enabled {
+		m.Use(middleware.LoadShedding(hs.Cfg))
+	}
+	if hs.Cfg.OAuthTokenRefreshBeforeExpiry > 0 {
+		m.Use(middleware.OAuthTokenRefresh(hs.Cfg))
+	}
 
 	for _, mw := range hs.middlewares {
 		m.Use(mw)
@@ -448,6 +508,44 @@ func (hs *HTTPServer) apiHealthHandler(ctx *macaron.Context) {
 	}
 }
 
+// apiReadyHandler will return ok while the server accepts requests, and http
+// status code 503 once it's shutting down, for readiness probes. Unlike
+// /api/health, it doesn't check the database.
+func (hs *HTTPServer) apiReadyHandler(ctx *macaron.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/api/ready" {
+		return
+	}
+
+	hs.lifecycleMtx.Lock()
+	ready, draining := hs.ready, hs.draining
+	hs.lifecycleMtx.Unlock()
+
+	data := simplejson.New()
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	switch {
+	case ready:
+		data.Set("status", "ready")
+		ctx.Resp.WriteHeader(200)
+	case draining:
+		data.Set("status", "shutting down")
+		ctx.Resp.WriteHeader(503)
+	default:
+		data.Set("status", "starting")
+		ctx.Resp.WriteHeader(503)
+	}
+
+	dataBytes, err := data.EncodePretty()
+	if err != nil {
+		hs.log.Error("Failed to encode data", "err", err)
+		return
+	}
+
+	if _, err := ctx.Resp.Write(dataBytes); err != nil {
+		hs.log.Error("Failed to write to response", "err", err)
+	}
+}
+
 func (hs *HTTPServer) mapStatic(m *macaron.Macaron, rootDir string, dir string, prefix string) {
 	headers := func(c *macaron.Context) {
 		c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ldap"
+)
+
+// GetLDAPSyncRuns returns the last runs of the LDAP sync, without their report.
+func (hs *HTTPServer) GetLDAPSyncRuns(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	runs, err := hs.SQLStore.GetLDAPSyncRuns(c.Req.Context(), limit)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get LDAP sync runs", err)
+	}
+
+	dtos := make([]*models.LDAPSyncRunDTO, 0, len(runs))
+	for _, run := range runs {
+		dtos = append(dtos, run.ToDTO(false))
+	}
+	return response.JSON(http.StatusOK, dtos)
+}
+
+// GetLDAPSyncRun returns the run of the LDAP sync with the report of the users it disabled, enabled or failed to sync.
+func (hs *HTTPServer) GetLDAPSyncRun(c *models.ReqContext) response.Response {
+	run, err := hs.SQLStore.GetLDAPSyncRun(c.Req.Context(), c.ParamsInt64(":runId"))
+	if err != nil {
+		if errors.Is(err, models.ErrLDAPSyncRunNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get LDAP sync run", err)
+	}
+
+	return response.JSON(http.StatusOK, run.ToDTO(true))
+}
+
+// PostLDAPSyncRun starts a sync of all the LDAP users, and returns its run while it's running.
+func (hs *HTTPServer) PostLDAPSyncRun(c *models.ReqContext) response.Response {
+	if !ldap.IsEnabled() {
+		return response.Error(http.StatusBadRequest, "LDAP is not enabled", nil)
+	}
+
+	run, err := hs.LDAPSyncService.StartSync(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, models.ErrLDAPSyncAlreadyActive) {
+			return response.Error(http.StatusConflict, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to start LDAP sync", err)
+	}
+
+	return response.JSON(http.StatusAccepted, run.ToDTO(false))
+}
@@ -283,13 +283,21 @@ func (hs *HTTPServer) Logout(c *models.ReqContext) {
 		return
 	}
 
+	hs.endSession(c)
+	hs.redirectAfterLogout(c)
+}
+
+// endSession revokes the session of the user and deletes its cookie.
+func (hs *HTTPServer) endSession(c *models.ReqContext) {
 	err := hs.AuthTokenService.RevokeToken(c.Req.Context(), c.UserToken, false)
 	if err != nil && !errors.Is(err, models.ErrUserTokenNotFound) {
 		hs.log.Error("failed to revoke auth token", "error", err)
 	}
 
 	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+}
 
+func (hs *HTTPServer) redirectAfterLogout(c *models.ReqContext) {
 	if setting.SignoutRedirectUrl != "" {
 		c.Redirect(setting.SignoutRedirectUrl)
 	} else {
@@ -342,14 +350,6 @@ func (hs *HTTPServer) RedirectResponseWithError(ctx *models.ReqContext, err erro
 	return response.Redirect(hs.Cfg.AppSubURL + "/login")
 }
 
-func (hs *HTTPServer) samlEnabled() bool {
-	return hs.SettingsProvider.KeyValue("auth.saml", "enabled").MustBool(false) && hs.License.HasValidLicense()
-}
-
-func (hs *HTTPServer) samlSingleLogoutEnabled() bool {
-	return hs.SettingsProvider.KeyValue("auth.saml", "single_logout").MustBool(false) && hs.samlEnabled()
-}
-
 func getLoginExternalError(err error) string {
 	var createTokenErr *models.CreateTokenErr
 	if errors.As(err, &createTokenErr) {
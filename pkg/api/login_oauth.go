@@ -207,14 +207,16 @@ func buildExternalUserInfo(token *oauth2.Token, userInfo *social.BasicUserInfo,
 	oauthLogger.Debug("Building external user info from OAuth user info")
 
 	extUser := &models.ExternalUserInfo{
-		AuthModule: fmt.Sprintf("oauth_%s", name),
-		OAuthToken: token,
-		AuthId:     userInfo.Id,
-		Name:       userInfo.Name,
-		Login:      userInfo.Login,
-		Email:      userInfo.Email,
-		OrgRoles:   map[int64]models.RoleType{},
-		Groups:     userInfo.Groups,
+		AuthModule:     fmt.Sprintf("oauth_%s", name),
+		OAuthToken:     token,
+		AuthId:         userInfo.Id,
+		Name:           userInfo.Name,
+		Login:          userInfo.Login,
+		Email:          userInfo.Email,
+		OrgRoles:       map[int64]models.RoleType{},
+		Groups:         userInfo.Groups,
+		IsGrafanaAdmin: userInfo.IsGrafanaAdmin,
+		Teams:          userInfo.Teams,
 	}
 
 	if userInfo.Role != "" {
@@ -235,6 +237,15 @@ func buildExternalUserInfo(token *oauth2.Token, userInfo *social.BasicUserInfo,
 		}
 	}
 
+	for orgName, role := range userInfo.OrgRoles {
+		query := &models.GetOrgByNameQuery{Name: orgName}
+		if err := bus.Dispatch(query); err != nil {
+			oauthLogger.Warn("Ignoring the role of the user in an org which can't be found", "org", orgName, "error", err)
+			continue
+		}
+		extUser.OrgRoles[query.Result.Id] = models.RoleType(role)
+	}
+
 	return extUser
 }
 
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/login"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/saml"
+)
+
+// SAMLMetadata returns the SP metadata, which registers Grafana in the IdP.
+func (hs *HTTPServer) SAMLMetadata(c *models.ReqContext) response.Response {
+	if !hs.samlEnabled() {
+		return response.Error(http.StatusNotFound, "SAML is not enabled", nil)
+	}
+
+	metadata, err := hs.SAMLService.Metadata()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get SAML metadata", err)
+	}
+	return response.Respond(http.StatusOK, metadata).SetHeader("Content-Type", "application/samlmetadata+xml")
+}
+
+// SAMLLogin starts the login of the user in the IdP.
+func (hs *HTTPServer) SAMLLogin(c *models.ReqContext) {
+	if !hs.samlEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML is not enabled", nil)
+		return
+	}
+
+	msg, err := hs.SAMLService.NewLoginRequest(c.Resp)
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to create SAML login request", err)
+		return
+	}
+	hs.writeSAMLMessage(c, msg)
+}
+
+// SAMLAssertionConsumer logs the user in with the response of the IdP, to a login request of Grafana or to an
+// IdP-initiated login.
+func (hs *HTTPServer) SAMLAssertionConsumer(c *models.ReqContext) {
+	loginInfo := models.LoginInfo{AuthModule: "saml"}
+	if !hs.samlEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML is not enabled", nil)
+		return
+	}
+
+	extUser, err := hs.SAMLService.ParseLoginResponse(c.Resp, c.Req.Request)
+	if err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+	loginInfo.ExternalUser = *extUser
+
+	cmd := &models.UpsertUserCommand{
+		ReqContext:    c,
+		ExternalUser:  extUser,
+		SignupAllowed: hs.Cfg.SAML.AllowSignUp,
+	}
+	if err := bus.Dispatch(cmd); err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+	// Do not expose disabled status,
+	// just show incorrect user credentials error (see #17947)
+	if cmd.Result.IsDisabled {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, login.ErrInvalidCredentials)
+		return
+	}
+	loginInfo.User = cmd.Result
+
+	if err := hs.loginUserWithUser(cmd.Result, c); err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+
+	loginInfo.HTTPStatus = http.StatusOK
+	hs.HooksService.RunLoginHook(&loginInfo, c)
+	metrics.MApiLoginSAML.Inc()
+
+	c.Redirect(hs.Cfg.AppSubURL + "/")
+}
+
+// SAMLLogout ends the session of the user, and then its session in the IdP when it's logged in with SAML.
+func (hs *HTTPServer) SAMLLogout(c *models.ReqContext) {
+	if !hs.samlSingleLogoutEnabled() || c.UserId == 0 {
+		hs.endSession(c)
+		hs.redirectAfterLogout(c)
+		return
+	}
+
+	query := &models.GetAuthInfoQuery{UserId: c.UserId, AuthModule: models.AuthModuleSAML}
+	if err := bus.Dispatch(query); err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			hs.log.Error("Failed to get SAML auth info", "userId", c.UserId, "error", err)
+		}
+		hs.endSession(c)
+		hs.redirectAfterLogout(c)
+		return
+	}
+
+	msg, err := hs.SAMLService.NewLogoutRequest(query.Result.AuthId)
+	hs.endSession(c)
+	if err != nil {
+		if !errors.Is(err, saml.ErrNoSLOLocation) {
+			hs.log.Error("Failed to create SAML logout request", "userId", c.UserId, "error", err)
+		}
+		hs.redirectAfterLogout(c)
+		return
+	}
+	hs.writeSAMLMessage(c, msg)
+}
+
+// SAMLSingleLogout handles the logout requests of the IdP, which end all the sessions of the user, and the responses
+// of the IdP to the logout requests of Grafana.
+func (hs *HTTPServer) SAMLSingleLogout(c *models.ReqContext) {
+	if !hs.samlSingleLogoutEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML single logout is not enabled", nil)
+		return
+	}
+
+	if !saml.IsLogoutRequest(c.Req.Request) {
+		if err := hs.SAMLService.ParseLogoutResponse(c.Req.Request); err != nil {
+			hs.log.Warn("Invalid SAML logout response", "error", err)
+		}
+		hs.redirectAfterLogout(c)
+		return
+	}
+
+	req, err := hs.SAMLService.ParseLogoutRequest(c.Req.Request)
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusBadRequest, "Invalid SAML logout request", err)
+		return
+	}
+
+	query := &models.GetAuthInfoQuery{AuthModule: models.AuthModuleSAML, AuthId: req.NameID}
+	err = bus.Dispatch(query)
+	switch {
+	case err == nil:
+		// The user can be logged in with several browsers, the IdP session ended for all of them.
+		if err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), query.Result.UserId); err != nil {
+			c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to revoke user sessions", err)
+			return
+		}
+		hs.log.Info("Logged out SAML user on IdP request", "userId", query.Result.UserId)
+	case errors.Is(err, models.ErrUserNotFound):
+		hs.log.Debug("Ignoring SAML logout request of an unknown user")
+	default:
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to get SAML auth info", err)
+		return
+	}
+	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+
+	msg, err := hs.SAMLService.NewLogoutResponse(req.ID, req.RelayState)
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to create SAML logout response", err)
+		return
+	}
+	hs.writeSAMLMessage(c, msg)
+}
+
+// writeSAMLMessage sends the message to the IdP through the browser of the user.
+func (hs *HTTPServer) writeSAMLMessage(c *models.ReqContext, msg *saml.Message) {
+	if msg.RedirectURL != "" {
+		c.Redirect(msg.RedirectURL)
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Resp.WriteHeader(http.StatusOK)
+	if _, err := c.Resp.Write(msg.PostForm); err != nil {
+		hs.log.Error("Failed to write SAML message", "error", err)
+	}
+}
+
+func (hs *HTTPServer) samlEnabled() bool {
+	return hs.SAMLService != nil && hs.SAMLService.IsEnabled()
+}
+
+func (hs *HTTPServer) samlSingleLogoutEnabled() bool {
+	return hs.SAMLService != nil && hs.SAMLService.IsSingleLogoutEnabled()
+}
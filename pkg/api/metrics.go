@@ -3,12 +3,15 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -24,14 +27,6 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		return response.Error(http.StatusBadRequest, "No queries found in query", nil)
 	}
 
-	timeRange := plugins.NewDataTimeRange(reqDTO.From, reqDTO.To)
-	request := plugins.DataQuery{
-		TimeRange: &timeRange,
-		Debug:     reqDTO.Debug,
-		User:      c.SignedInUser,
-		Queries:   make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
-	}
-
 	// Loop to see if we have an expression.
 	for _, query := range reqDTO.Queries {
 		if query.Get("datasource").MustString("") == expr.DatasourceName {
@@ -39,8 +34,12 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		}
 	}
 
-	var ds *models.DataSource
-	for i, query := range reqDTO.Queries {
+	// The queries are split by data source, so the queries of the mixed data source are sent to each of their
+	// data sources.
+	timeRange := plugins.NewDataTimeRange(reqDTO.From, reqDTO.To)
+	requests := make([]dataSourceRequest, 0, 1)
+	requestIndexes := map[int64]int{}
+	for _, query := range reqDTO.Queries {
 		hs.log.Debug("Processing metrics query", "query", query)
 
 		datasourceID, err := query.Get("datasourceId").Int64()
@@ -49,57 +48,140 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 			return response.Error(http.StatusBadRequest, "Query missing data source ID", nil)
 		}
 
-		// For mixed datasource case, each data source is sent in a single request.
-		// So only the datasource from the first query is needed. As all requests
-		// should be the same data source.
-		if i == 0 {
-			ds, err = hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+		index, ok := requestIndexes[datasourceID]
+		if !ok {
+			ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
 			if err != nil {
 				return hs.handleGetDataSourceError(err, datasourceID)
 			}
+			if err := hs.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+				return response.Error(http.StatusForbidden, "Access denied", err)
+			}
+
+			index = len(requests)
+			requestIndexes[datasourceID] = index
+			requests = append(requests, dataSourceRequest{
+				ds: ds,
+				request: plugins.DataQuery{
+					TimeRange: &timeRange,
+					Debug:     reqDTO.Debug,
+					User:      c.SignedInUser,
+				},
+			})
 		}
 
-		request.Queries = append(request.Queries, plugins.DataSubQuery{
+		requests[index].request.Queries = append(requests[index].request.Queries, plugins.DataSubQuery{
 			RefID:         query.Get("refId").MustString("A"),
 			MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
 			IntervalMS:    query.Get("intervalMs").MustInt64(1000),
 			QueryType:     query.Get("queryType").MustString(""),
 			Model:         query,
-			DataSource:    ds,
+			DataSource:    requests[index].ds,
 		})
 	}
 
-	err := hs.PluginRequestValidator.Validate(ds.Url, nil)
-	if err != nil {
-		return response.Error(http.StatusForbidden, "Access denied", err)
+	qdr := queryDataSources(tsdb.WithQueryCaching(c.Req.Context()), requests, hs.DataService.HandleRequest)
+	return toMacronResponse(qdr)
+}
+
+// mixedQueryConcurrency is the max number of data sources queried concurrently by a request mixing data sources.
+const mixedQueryConcurrency = 4
+
+// dataSourceRequest holds the queries of a request to one of its data sources.
+type dataSourceRequest struct {
+	ds *models.DataSource
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	request plugins.DataQuery
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+type dataRequestHandler func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error)
+
+// queryDataSources runs the requests to the data sources concurrently and merges their responses. When a data
+// source fails, its error is the response of each of its queries, and the queries of the other data sources still
+// return their data.
+func queryDataSources(ctx context.Context, requests []dataSourceRequest, handle dataRequestHandler) *backend.QueryDataResponse {
+	responses := make([]*backend.QueryDataResponse, len(requests))
+	var g errgroup.Group
+	g.SetLimit(mixedQueryConcurrency)
+	for i, req := range requests {
+		i, req := i, req
+		g.Go(func() error {
+			responses[i] = queryDataSource(ctx, req, handle)
+			return nil
+		})
 	}
+	_ = g.Wait()
 
-	resp, err := hs.DataService.HandleRequest(c.Req.Context(), ds, request)
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Metric request error", err)
+	qdr := backend.NewQueryDataResponse()
+	for _, resp := range responses {
+		for refID, res := range resp.Responses {
+			qdr.Responses[refID] = res
+		}
 	}
+	return qdr
+}
 
-	// This is insanity... but ¯\_(ツ)_/¯, the current query path looks like:
-	//  encodeJson( decodeBase64( encodeBase64( decodeArrow( encodeArrow(frame)) ) )
-	// this will soon change to a more direct route
-	qdr, err := resp.ToBackendDataResponse()
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, "error converting results", err)
+func queryDataSource(ctx context.Context, req dataSourceRequest, handle dataRequestHandler) *backend.QueryDataResponse {
+	resp, err := handle(ctx, req.ds, req.request)
+	if err == nil {
+		// This is insanity... but ¯\_(ツ)_/¯, the current query path looks like:
+		//  encodeJson( decodeBase64( encodeBase64( decodeArrow( encodeArrow(frame)) ) )
+		// this will soon change to a more direct route
+		qdr, convErr := resp.ToBackendDataResponse()
+		if convErr == nil {
+			return qdr
+		}
+		err = fmt.Errorf("error converting results: %w", convErr)
 	}
-	return toMacronResponse(qdr)
+
+	plog.Error("Data source query failed", "datasource", req.ds.Name, "error", err)
+	qdr := backend.NewQueryDataResponse()
+	for _, q := range req.request.Queries {
+		qdr.Responses[q.RefID] = backend.DataResponse{Error: err}
+	}
+	return qdr
 }
 
+// toMacronResponse returns the responses of the queries with their errors. The status is 200 when all the queries
+// succeed, 207 (Multi-Status) when some of them fail, and the status of their errors when all of them fail.
 func toMacronResponse(qdr *backend.QueryDataResponse) response.Response {
-	statusCode := http.StatusOK
+	failed := 0
+	statusCode := 0
 	for _, res := range qdr.Responses {
-		if res.Error != nil {
+		if res.Error == nil {
+			continue
+		}
+		failed++
+		if status := queryErrorStatus(res.Error); statusCode == 0 || status == statusCode {
+			statusCode = status
+		} else {
 			statusCode = http.StatusBadRequest
 		}
 	}
 
+	switch {
+	case failed == 0:
+		statusCode = http.StatusOK
+	case failed < len(qdr.Responses):
+		statusCode = http.StatusMultiStatus
+	}
 	return response.JSONStreaming(statusCode, qdr)
 }
 
+// queryErrorStatus returns the status of the error of a query: 503 when its data source is overloaded, 504 when
+// its data source didn't respond in time, 400 otherwise.
+func queryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, tsdb.ErrDataSourceOverloaded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, tsdb.ErrDataSourceQueryTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.MetricRequest) response.Response {
 	timeRange := plugins.NewDataTimeRange(reqDTO.From, reqDTO.To)
@@ -159,6 +241,18 @@ func (hs *HTTPServer) handleGetDataSourceError(err error, datasourceID int64) *r
 	return response.Error(500, "Unable to load data source metadata", err)
 }
 
+// handleDataRequestError returns the response of a failed data source request. The requests rejected by the limits
+// of the data source can be retried later.
+func handleDataRequestError(err error) *response.NormalResponse {
+	if errors.Is(err, tsdb.ErrDataSourceOverloaded) {
+		return response.Error(http.StatusServiceUnavailable, "Data source overloaded", err).SetHeader("Retry-After", "1")
+	}
+	if errors.Is(err, tsdb.ErrDataSourceQueryTimeout) {
+		return response.Error(http.StatusGatewayTimeout, "Data source query timeout", err)
+	}
+	return response.Error(http.StatusInternalServerError, "Metric request error", err)
+}
+
 // QueryMetrics returns query metrics
 // POST /api/tsdb/query
 func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricRequest) response.Response {
@@ -200,7 +294,7 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 
 	resp, err := hs.DataService.HandleRequest(c.Req.Context(), ds, request)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Metric request error", err)
+		return handleDataRequestError(err)
 	}
 
 	statusCode := http.StatusOK
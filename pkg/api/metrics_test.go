@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryDataSources(t *testing.T) {
+	newRequest := func(id int64, refIDs ...string) dataSourceRequest {
+		req := dataSourceRequest{ds: &models.DataSource{Id: id}}
+		for _, refID := range refIDs {
+			req.request.Queries = append(req.request.Queries, plugins.DataSubQuery{RefID: refID})
+		}
+		return req
+	}
+
+	t.Run("Should merge the responses of the data sources", func(t *testing.T) {
+		var mu sync.Mutex
+		queried := map[int64][]string{}
+		//nolint: staticcheck // plugins.DataPlugin deprecated
+		handle := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{}}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, q := range query.Queries {
+				queried[ds.Id] = append(queried[ds.Id], q.RefID)
+				resp.Results[q.RefID] = plugins.DataQueryResult{
+					RefID:      q.RefID,
+					Dataframes: plugins.NewDecodedDataFrames(data.Frames{data.NewFrame(q.RefID)}),
+				}
+			}
+			return resp, nil
+		}
+
+		qdr := queryDataSources(context.Background(), []dataSourceRequest{
+			newRequest(1, "A", "C"),
+			newRequest(2, "B"),
+		}, handle)
+		require.Equal(t, map[int64][]string{1: {"A", "C"}, 2: {"B"}}, queried)
+		require.Len(t, qdr.Responses, 3)
+		for _, refID := range []string{"A", "B", "C"} {
+			require.Equal(t, refID, qdr.Responses[refID].Frames[0].Name)
+		}
+	})
+
+	t.Run("Should return the error of a failed data source for each of its queries", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		//nolint: staticcheck // plugins.DataPlugin deprecated
+		handle := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			if ds.Id == 2 {
+				return plugins.DataResponse{}, errFailed
+			}
+			return plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+				"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{data.NewFrame("A")})},
+			}}, nil
+		}
+
+		qdr := queryDataSources(context.Background(), []dataSourceRequest{
+			newRequest(1, "A"),
+			newRequest(2, "B", "C"),
+		}, handle)
+		require.NoError(t, qdr.Responses["A"].Error)
+		require.Len(t, qdr.Responses["A"].Frames, 1)
+		require.Equal(t, errFailed, qdr.Responses["B"].Error)
+		require.Equal(t, errFailed, qdr.Responses["C"].Error)
+	})
+}
+
+func TestToMacronResponse(t *testing.T) {
+	newResponse := func(errs ...error) *backend.QueryDataResponse {
+		qdr := backend.NewQueryDataResponse()
+		for i, err := range errs {
+			qdr.Responses[string(rune('A'+i))] = backend.DataResponse{Error: err}
+		}
+		return qdr
+	}
+	overloaded := fmt.Errorf("%w: busy", tsdb.ErrDataSourceOverloaded)
+	timeout := fmt.Errorf("%w: slow", tsdb.ErrDataSourceQueryTimeout)
+
+	testCases := []struct {
+		desc   string
+		qdr    *backend.QueryDataResponse
+		status int
+	}{
+		{desc: "all queries succeed", qdr: newResponse(nil, nil), status: http.StatusOK},
+		{desc: "some queries fail", qdr: newResponse(nil, errors.New("bad query")), status: http.StatusMultiStatus},
+		{desc: "all queries fail", qdr: newResponse(errors.New("bad query")), status: http.StatusBadRequest},
+		{desc: "all data sources are overloaded", qdr: newResponse(overloaded, overloaded), status: http.StatusServiceUnavailable},
+		{desc: "all data sources time out", qdr: newResponse(timeout), status: http.StatusGatewayTimeout},
+		{desc: "all queries fail differently", qdr: newResponse(overloaded, timeout), status: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.status, toMacronResponse(tc.qdr).Status())
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/openapi"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GetOpenAPISpec returns the OpenAPI 3 specification of the HTTP API, for generating clients.
+// GET /api/openapi.json
+func GetOpenAPISpec(c *models.ReqContext) response.Response {
+	return response.Respond(http.StatusOK, openapi.Spec()).SetHeader("Content-Type", "application/json")
+}
@@ -0,0 +1,111 @@
+package main
+
+// The subset of the OpenAPI 3 document model which is generated. Paths and schemas are maps of interface{} so that
+// the converted parts of the alerting API specification can be merged in as they are.
+
+type document struct {
+	OpenAPI    string                            `json:"openapi"`
+	Info       info                              `json:"info"`
+	Paths      map[string]map[string]interface{} `json:"paths"`
+	Components components                        `json:"components"`
+	Security   []map[string][]string             `json:"security"`
+}
+
+type info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type components struct {
+	Schemas         map[string]interface{} `json:"schemas"`
+	SecuritySchemes map[string]interface{} `json:"securitySchemes"`
+}
+
+type operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+	// Security is only set to an empty list for the routes which don't require authentication.
+	Security     *[]map[string][]string `json:"security,omitempty"`
+	RequiredRole string                 `json:"x-grafana-required-role,omitempty"`
+	Action       string                 `json:"x-grafana-action,omitempty"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+// schema is a JSON schema. The empty schema allows any value.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+}
+
+const (
+	schemaRefPrefix     = "#/components/schemas/"
+	errorResponseSchema = "ErrorResponse"
+	successResponseName = "SuccessResponse"
+)
+
+func newDocument() *document {
+	return &document{
+		OpenAPI: "3.0.3",
+		Info: info{
+			Title:       "Grafana HTTP API",
+			Description: "Generated from the routes of the HTTP API by `make openapi3`, don't edit it by hand.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]map[string]interface{}{},
+		Components: components{
+			Schemas: map[string]interface{}{
+				errorResponseSchema: &schema{
+					Type: "object",
+					Properties: map[string]*schema{
+						"message": {Type: "string"},
+						"error":   {Type: "string"},
+					},
+				},
+				successResponseName: &schema{
+					Type:       "object",
+					Properties: map[string]*schema{"message": {Type: "string"}},
+				},
+			},
+			SecuritySchemes: map[string]interface{}{
+				"basic":  map[string]string{"type": "http", "scheme": "basic"},
+				"bearer": map[string]string{"type": "http", "scheme": "bearer"},
+			},
+		},
+		Security: []map[string][]string{{"bearer": {}}, {"basic": {}}},
+	}
+}
+
+func refSchema(name string) *schema {
+	return &schema{Ref: schemaRefPrefix + name}
+}
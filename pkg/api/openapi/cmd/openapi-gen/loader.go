@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"runtime"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadPackage parses and type checks the package and its dependencies from source. go/packages only lists them,
+// since the version of golang.org/x/tools in go.mod can't read the export data of newer Go versions.
+func loadPackage(path string) ([]*ast.File, *types.Info, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps,
+	}
+	pkgs, err := packages.Load(cfg, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, nil, fmt.Errorf("failed to load %s", path)
+	}
+
+	l := &loader{
+		root:   path,
+		fset:   token.NewFileSet(),
+		sizes:  types.SizesFor("gc", runtime.GOARCH),
+		loaded: map[*packages.Package]*types.Package{},
+		files:  map[*packages.Package][]*ast.File{},
+		info: &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+		},
+	}
+	if _, err := l.load(pkgs[0]); err != nil {
+		return nil, nil, err
+	}
+	return l.files[pkgs[0]], l.info, nil
+}
+
+type loader struct {
+	root   string
+	fset   *token.FileSet
+	sizes  types.Sizes
+	loaded map[*packages.Package]*types.Package
+	files  map[*packages.Package][]*ast.File
+	// info is only recorded for the root package.
+	info *types.Info
+}
+
+func (l *loader) load(pkg *packages.Package) (*types.Package, error) {
+	if pkg.PkgPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if loaded, ok := l.loaded[pkg]; ok {
+		return loaded, nil
+	}
+	for _, imported := range pkg.Imports {
+		if _, err := l.load(imported); err != nil {
+			return nil, err
+		}
+	}
+
+	var mode parser.Mode
+	if pkg.PkgPath == l.root {
+		mode = parser.ParseComments
+	}
+	files := make([]*ast.File, 0, len(pkg.CompiledGoFiles))
+	for _, filename := range pkg.CompiledGoFiles {
+		file, err := parser.ParseFile(l.fset, filename, nil, mode)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	var info *types.Info
+	if pkg.PkgPath == l.root {
+		info = l.info
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			imported, ok := pkg.Imports[path]
+			if !ok {
+				return nil, fmt.Errorf("%s isn't imported by %s", path, pkg.PkgPath)
+			}
+			return l.loaded[imported], nil
+		}),
+		Sizes: l.sizes,
+		// The function bodies of the dependencies aren't needed for their types.
+		IgnoreFuncBodies: info == nil,
+		Error:            func(error) {},
+	}
+	checked, err := conf.Check(pkg.PkgPath, l.fset, files, info)
+	if err != nil && info != nil {
+		return nil, fmt.Errorf("failed to type check %s: %w", pkg.PkgPath, err)
+	}
+	l.loaded[pkg] = checked
+	l.files[pkg] = files
+	return checked, nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
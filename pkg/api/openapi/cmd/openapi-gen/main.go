@@ -0,0 +1,53 @@
+// The openapi-gen command generates the OpenAPI 3 specification of the HTTP API from the routes registered by
+// pkg/api and the models of their requests and responses, and merges in the specification of the alerting API
+// generated by pkg/services/ngalert/api/tooling.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+)
+
+const apiPackage = "github.com/grafana/grafana/pkg/api"
+
+func main() {
+	var output, alerting string
+	flag.StringVar(&output, "of", "pkg/api/openapi/openapi.json", "output file")
+	flag.StringVar(&alerting, "alerting", "pkg/services/ngalert/api/tooling/post.json",
+		"Swagger 2 specification of the alerting API to merge, if any")
+	flag.Parse()
+
+	files, info, err := loadPackage(apiPackage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	doc := newDocument()
+	if alerting != "" {
+		//nolint
+		b, err := ioutil.ReadFile(alerting)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := mergeSwagger(doc, b); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// The routes are added after the alerting API, so that their schemas are renamed if their names are taken.
+	g := newGenerator(files, info, doc)
+	if err := g.addRoutes(); err != nil {
+		log.Fatal(err)
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	//nolint
+	if err := ioutil.WriteFile(output, append(out, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d paths to %s", len(doc.Paths), output)
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	routingPackage       = "github.com/grafana/grafana/pkg/api/routing"
+	responsePackage      = "github.com/grafana/grafana/pkg/api/response"
+	middlewarePackage    = "github.com/grafana/grafana/pkg/middleware"
+	acMiddlewarePackage  = "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	bindingPackage       = "github.com/go-macaron/binding"
+	registerRoutesMethod = "registerRoutes"
+)
+
+// middlewareRoles are the roles required by the middleware of the middleware package.
+var middlewareRoles = map[string]string{
+	"ReqSignedIn":                  "Viewer",
+	"ReqSignedInNoAnonymous":       "Viewer",
+	"SnapshotPublicModeOrSignedIn": "Viewer",
+	"ReqEditorRole":                "Editor",
+	"ReqOrgAdmin":                  "Admin",
+	"AdminOrFeatureEnabled":        "Admin",
+	"ReqGrafanaAdmin":              "Grafana Admin",
+}
+
+var roleRanks = map[string]int{"Viewer": 1, "Editor": 2, "Admin": 3, "Grafana Admin": 4}
+
+var routeMethods = map[string][]string{
+	"Get":    {"get"},
+	"Post":   {"post"},
+	"Put":    {"put"},
+	"Patch":  {"patch"},
+	"Delete": {"delete"},
+	"Any":    {"get", "post", "put", "patch", "delete"},
+}
+
+var routeCommentPattern = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE|HEAD) /`)
+
+// generator adds the operations of the routes registered by HTTPServer.registerRoutes to the document.
+type generator struct {
+	info    *types.Info
+	doc     *document
+	schemas *schemas
+	// funcs are the declarations of the functions of the package.
+	funcs map[types.Object]*ast.FuncDecl
+	// definitions are the expressions the local variables of registerRoutes are defined with, like bind for
+	// binding.Bind.
+	definitions  map[types.Object]ast.Expr
+	operationIDs map[string]bool
+}
+
+func newGenerator(files []*ast.File, info *types.Info, doc *document) *generator {
+	g := &generator{
+		info:         info,
+		doc:          doc,
+		schemas:      newSchemas(doc.Components.Schemas),
+		funcs:        map[types.Object]*ast.FuncDecl{},
+		definitions:  map[types.Object]ast.Expr{},
+		operationIDs: map[string]bool{},
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				g.funcs[info.Defs[fn.Name]] = fn
+			}
+		}
+	}
+	return g
+}
+
+// route is a route being registered, with the middleware of the groups it's registered in.
+type route struct {
+	methods  []string
+	pattern  string
+	handlers []ast.Expr
+}
+
+func (g *generator) addRoutes() error {
+	var registerRoutes *ast.FuncDecl
+	for _, fn := range g.funcs {
+		if fn.Name.Name == registerRoutesMethod && fn.Recv != nil {
+			registerRoutes = fn
+		}
+	}
+	if registerRoutes == nil {
+		return fmt.Errorf("%s not found", registerRoutesMethod)
+	}
+
+	ast.Inspect(registerRoutes.Body, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE && len(assign.Lhs) == len(assign.Rhs) {
+			for i, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && g.info.Defs[ident] != nil {
+					g.definitions[g.info.Defs[ident]] = assign.Rhs[i]
+				}
+			}
+		}
+		return true
+	})
+
+	var routes []route
+	g.walkRoutes(registerRoutes.Body, "", nil, &routes)
+	for _, r := range routes {
+		path, params := openAPIPath(r.pattern)
+		if path != "/api" && !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+		for _, method := range r.methods {
+			if _, exists := g.doc.Paths[path][method]; exists {
+				continue
+			}
+			if g.doc.Paths[path] == nil {
+				g.doc.Paths[path] = map[string]interface{}{}
+			}
+			g.doc.Paths[path][method] = g.operation(method, path, params, r.handlers)
+		}
+	}
+	return nil
+}
+
+// walkRoutes collects the routes registered in the node, descending into the groups.
+func (g *generator) walkRoutes(node ast.Node, prefix string, middleware []ast.Expr, routes *[]route) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !isNamed(g.info.TypeOf(sel.X), routingPackage, "RouteRegister") || len(call.Args) < 2 {
+			return true
+		}
+		pattern, ok := g.constantString(call.Args[0])
+		if !ok {
+			return false
+		}
+
+		if sel.Sel.Name == "Group" {
+			groupMiddleware := append(append([]ast.Expr{}, middleware...), call.Args[2:]...)
+			if fn, ok := call.Args[1].(*ast.FuncLit); ok {
+				g.walkRoutes(fn.Body, prefix+pattern, groupMiddleware, routes)
+			}
+			return false
+		}
+		if methods, ok := routeMethods[sel.Sel.Name]; ok {
+			*routes = append(*routes, route{
+				methods:  methods,
+				pattern:  prefix + pattern,
+				handlers: append(append([]ast.Expr{}, middleware...), call.Args[1:]...),
+			})
+		}
+		return false
+	})
+}
+
+func (g *generator) operation(method, path string, params []string, handlers []ast.Expr) *operation {
+	op := &operation{Responses: map[string]*response{}}
+	if tag := pathTag(path); tag != "" {
+		op.Tags = []string{tag}
+	}
+	for _, name := range params {
+		op.Parameters = append(op.Parameters, &parameter{Name: name, In: "path", Required: true, Schema: &schema{Type: "string"}})
+	}
+
+	var model types.Type
+	for _, handler := range handlers[:len(handlers)-1] {
+		if m := g.middleware(handler, op); m != nil {
+			model = m
+		}
+	}
+	if model != nil {
+		if method == "get" || method == "delete" {
+			op.Parameters = append(op.Parameters, g.queryParameters(model)...)
+		} else {
+			op.RequestBody = &requestBody{
+				Required: true,
+				Content:  map[string]*mediaType{"application/json": {Schema: g.schemas.schemaOf(model)}},
+			}
+		}
+	}
+
+	handler := handlers[len(handlers)-1]
+	if call, ok := handler.(*ast.CallExpr); ok && isFunc(g.objectOf(call.Fun), routingPackage, "Wrap") && len(call.Args) == 1 {
+		handler = call.Args[0]
+	}
+	if obj := g.objectOf(handler); obj != nil {
+		op.OperationID = g.operationID(obj.Name(), method)
+		if decl, ok := g.funcs[obj]; ok {
+			op.Summary, op.Description = docSummary(decl.Doc)
+			g.addResponses(op, decl.Body, map[*ast.FuncDecl]bool{decl: true})
+		}
+	} else if lit, ok := handler.(*ast.FuncLit); ok {
+		g.addResponses(op, lit.Body, map[*ast.FuncDecl]bool{})
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &response{Description: http.StatusText(http.StatusOK)}
+	}
+	if op.RequiredRole == "" {
+		op.Security = &[]map[string][]string{}
+	} else {
+		addErrorResponse(op, http.StatusUnauthorized)
+		if op.RequiredRole != "Viewer" || op.Action != "" {
+			addErrorResponse(op, http.StatusForbidden)
+		}
+	}
+	return op
+}
+
+// middleware adds the role and the access control action required by the middleware to the operation, and returns
+// the model of the request if the middleware binds it.
+func (g *generator) middleware(expr ast.Expr, op *operation) types.Type {
+	obj := g.objectOf(expr)
+	if obj == nil || obj.Pkg() == nil {
+		return nil
+	}
+	call, _ := g.resolve(expr).(*ast.CallExpr)
+
+	switch obj.Pkg().Path() {
+	case middlewarePackage:
+		if role, ok := middlewareRoles[obj.Name()]; ok && roleRanks[role] > roleRanks[op.RequiredRole] {
+			op.RequiredRole = role
+		}
+	case acMiddlewarePackage:
+		// authorize(fallback, action, scope) is the call of the middleware returned by acmiddleware.Middleware.
+		if outer, ok := expr.(*ast.CallExpr); ok && len(outer.Args) >= 2 {
+			g.middleware(outer.Args[0], op)
+			if action, ok := g.constantString(outer.Args[1]); ok {
+				op.Action = action
+			}
+		}
+	case bindingPackage:
+		if obj.Name() == "Bind" && call != nil && len(call.Args) == 1 {
+			return g.info.TypeOf(call.Args[0])
+		}
+	}
+	return nil
+}
+
+// queryParameters returns the query parameters of a model which is bound from the query string.
+func (g *generator) queryParameters(model types.Type) []*parameter {
+	st, ok := model.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var params []*parameter
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i))
+		name := tag.Get("form")
+		if name == "" {
+			name, _ = parseJSONTag(tag.Get("json"))
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+		sch := g.schemas.schemaOf(field.Type())
+		if sch.Ref != "" || sch.Type == "object" || sch.Type == "" {
+			continue
+		}
+		params = append(params, &parameter{Name: name, In: "query", Schema: sch})
+	}
+	return params
+}
+
+// addResponses adds the responses created in the body to the operation, including the ones of the functions of the
+// package it calls which return responses. The responses of the body take precedence.
+func (g *generator) addResponses(op *operation, body *ast.BlockStmt, visited map[*ast.FuncDecl]bool) {
+	if body == nil {
+		return
+	}
+	var helpers []*ast.FuncDecl
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn, ok := g.objectOf(call.Fun).(*types.Func)
+		if !ok {
+			return true
+		}
+		if fn.Pkg() != nil && fn.Pkg().Path() == responsePackage {
+			g.addResponse(op, fn.Name(), call.Args)
+			return true
+		}
+		if decl, ok := g.funcs[fn]; ok && !visited[decl] && returnsResponse(fn) {
+			visited[decl] = true
+			helpers = append(helpers, decl)
+		}
+		return true
+	})
+	for _, decl := range helpers {
+		g.addResponses(op, decl.Body, visited)
+	}
+}
+
+func (g *generator) addResponse(op *operation, name string, args []ast.Expr) {
+	switch name {
+	case "Success":
+		setResponse(op, http.StatusOK, refSchema(successResponseName))
+	case "Error":
+		if status, ok := g.constantInt(args[0]); ok {
+			setResponse(op, status, refSchema(errorResponseSchema))
+		}
+	case "Empty":
+		if status, ok := g.constantInt(args[0]); ok {
+			setResponse(op, status, nil)
+		}
+	case "JSON", "JSONStreaming", "Respond":
+		status, ok := g.constantInt(args[0])
+		if !ok {
+			return
+		}
+		t := g.info.TypeOf(args[1])
+		if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+			setResponse(op, status, nil)
+			return
+		}
+		// Respond writes bytes and strings as they are.
+		if name == "Respond" && isRaw(t) {
+			setResponse(op, status, &schema{})
+			return
+		}
+		setResponse(op, status, g.schemas.schemaOf(t))
+	}
+}
+
+func setResponse(op *operation, status int, sch *schema) {
+	code := strconv.Itoa(status)
+	if _, exists := op.Responses[code]; exists {
+		return
+	}
+	description := http.StatusText(status)
+	if description == "" {
+		description = code
+	}
+	resp := &response{Description: description}
+	if sch != nil {
+		resp.Content = map[string]*mediaType{"application/json": {Schema: sch}}
+	}
+	op.Responses[code] = resp
+}
+
+func addErrorResponse(op *operation, status int) {
+	setResponse(op, status, refSchema(errorResponseSchema))
+}
+
+func (g *generator) operationID(name, method string) string {
+	id := name
+	if g.operationIDs[id] {
+		id = name + strings.Title(method)
+	}
+	for i := 2; g.operationIDs[id]; i++ {
+		id = name + strings.Title(method) + strconv.Itoa(i)
+	}
+	g.operationIDs[id] = true
+	return id
+}
+
+// resolve returns the expression a local variable of registerRoutes is defined with, or the expression.
+func (g *generator) resolve(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.Ident:
+			if def, ok := g.definitions[g.info.Uses[e]]; ok {
+				expr = def
+				continue
+			}
+		}
+		return expr
+	}
+}
+
+// objectOf returns the object an expression refers to, following the local variables of registerRoutes. The object
+// of a call is the object of the called function.
+func (g *generator) objectOf(expr ast.Expr) types.Object {
+	switch e := g.resolve(expr).(type) {
+	case *ast.Ident:
+		return g.info.Uses[e]
+	case *ast.SelectorExpr:
+		return g.info.Uses[e.Sel]
+	case *ast.CallExpr:
+		return g.objectOf(e.Fun)
+	}
+	return nil
+}
+
+func (g *generator) constantString(expr ast.Expr) (string, bool) {
+	if tv, ok := g.info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value), true
+	}
+	return "", false
+}
+
+func (g *generator) constantInt(expr ast.Expr) (int, bool) {
+	if tv, ok := g.info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.Int {
+		v, ok := constant.Int64Val(tv.Value)
+		return int(v), ok
+	}
+	return 0, false
+}
+
+// openAPIPath converts a route pattern like /api/dashboards/uid/:uid to a path like /api/dashboards/uid/{uid}, and
+// returns it with the names of its parameters.
+func openAPIPath(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+	var params []string
+	for i, segment := range segments {
+		var name string
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = strings.TrimSuffix(segment[1:], "?")
+			if j := strings.Index(name, "("); j >= 0 {
+				name = name[:j]
+			}
+		case segment == "*":
+			name = "path"
+		default:
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+	path := strings.Join(segments, "/")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path, params
+}
+
+// pathTag returns the tag of the operations of a path, which is the first segment after /api.
+func pathTag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	if segments[0] == "" || strings.HasPrefix(segments[0], "{") {
+		return ""
+	}
+	return strings.TrimSuffix(segments[0], filepath.Ext(segments[0]))
+}
+
+// docSummary returns the first sentence of a doc comment, and the comment if it's longer, without the lines naming
+// the routes of the handler.
+func docSummary(doc *ast.CommentGroup) (string, string) {
+	if doc == nil {
+		return "", ""
+	}
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !routeCommentPattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	text := strings.Join(lines, " ")
+	summary := text
+	if i := strings.Index(text, ". "); i >= 0 {
+		summary = text[:i+1]
+	}
+	if summary == text {
+		return summary, ""
+	}
+	return summary, text
+}
+
+func returnsResponse(fn *types.Func) bool {
+	results := fn.Type().(*types.Signature).Results()
+	for i := 0; i < results.Len(); i++ {
+		if isNamed(results.At(i).Type(), responsePackage, "Response") {
+			return true
+		}
+	}
+	return false
+}
+
+func isRaw(t types.Type) bool {
+	if slice, ok := t.Underlying().(*types.Slice); ok {
+		basic, ok := slice.Elem().(*types.Basic)
+		return ok && basic.Kind() == types.Byte
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+func isNamed(t types.Type, pkgPath, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkgPath && named.Obj().Name() == name
+}
+
+func isFunc(obj types.Object, pkgPath, name string) bool {
+	fn, ok := obj.(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkgPath && fn.Name() == name
+}
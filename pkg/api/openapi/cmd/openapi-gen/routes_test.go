@@ -0,0 +1,54 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		params  []string
+	}{
+		{pattern: "/api/dashboards/", path: "/api/dashboards"},
+		{pattern: "/api/dashboards/uid/:uid", path: "/api/dashboards/uid/{uid}", params: []string{"uid"}},
+		{pattern: "/api/orgs/:orgId/users/:userId", path: "/api/orgs/{orgId}/users/{userId}", params: []string{"orgId", "userId"}},
+		{pattern: "/api/datasources/proxy/:id/*", path: "/api/datasources/proxy/{id}/{path}", params: []string{"id", "path"}},
+	}
+	for _, tt := range tests {
+		path, params := openAPIPath(tt.pattern)
+		require.Equal(t, tt.path, path)
+		require.Equal(t, tt.params, params)
+	}
+}
+
+func TestPathTag(t *testing.T) {
+	require.Equal(t, "dashboards", pathTag("/api/dashboards/uid/{uid}"))
+	require.Equal(t, "openapi", pathTag("/api/openapi.json"))
+	require.Equal(t, "", pathTag("/api/{path}"))
+}
+
+func TestDocSummary(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "handlers.go", `package api
+
+// GET /api/webhooks
+func GetWebhooks() {}
+
+// RedeliverWebhookDelivery makes a delivery pending again. It's retried right away.
+// POST /api/webhooks/:webhookId/deliveries/:deliveryId/redeliver
+func RedeliverWebhookDelivery() {}
+`, parser.ParseComments)
+	require.NoError(t, err)
+
+	summary, description := docSummary(file.Comments[0])
+	require.Empty(t, summary)
+	require.Empty(t, description)
+
+	summary, description = docSummary(file.Comments[1])
+	require.Equal(t, "RedeliverWebhookDelivery makes a delivery pending again.", summary)
+	require.Equal(t, "RedeliverWebhookDelivery makes a delivery pending again. It's retried right away.", description)
+}
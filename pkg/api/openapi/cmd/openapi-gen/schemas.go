@@ -0,0 +1,141 @@
+package main
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// schemas generates the JSON schemas of Go types, as they're encoded by encoding/json. Named struct types are added
+// to the components of the document and referenced.
+type schemas struct {
+	components map[string]interface{}
+	names      map[*types.TypeName]string
+}
+
+func newSchemas(components map[string]interface{}) *schemas {
+	return &schemas{components: components, names: map[*types.TypeName]string{}}
+}
+
+func (s *schemas) schemaOf(t types.Type) *schema {
+	switch t := t.(type) {
+	case *types.Named:
+		return s.namedSchema(t)
+	case *types.Pointer:
+		return s.schemaOf(t.Elem())
+	case *types.Basic:
+		return basicSchema(t)
+	case *types.Slice:
+		if basic, ok := t.Elem().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case *types.Array:
+		return &schema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case *types.Map:
+		return &schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case *types.Struct:
+		sch := &schema{Type: "object", Properties: map[string]*schema{}}
+		s.addFields(sch, t)
+		return sch
+	default:
+		return &schema{}
+	}
+}
+
+func (s *schemas) namedSchema(t *types.Named) *schema {
+	obj := t.Obj()
+	if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+		return &schema{Type: "string", Format: "date-time"}
+	}
+	// The encoding of the types which encode themselves is unknown.
+	if methods := types.NewMethodSet(types.NewPointer(t)); methods.Lookup(nil, "MarshalJSON") != nil {
+		return &schema{}
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return s.schemaOf(t.Underlying())
+	}
+
+	if name, ok := s.names[obj]; ok {
+		return refSchema(name)
+	}
+	name := obj.Name()
+	if _, exists := s.components[name]; exists && obj.Pkg() != nil {
+		name = obj.Pkg().Name() + "." + name
+	}
+	s.names[obj] = name
+	// Adds the schema before its fields so that recursive types reference it.
+	sch := &schema{Type: "object", Properties: map[string]*schema{}}
+	s.components[name] = sch
+	s.addFields(sch, st)
+	return refSchema(name)
+}
+
+// addFields adds the properties of the struct fields to the schema. The fields of the embedded structs without a
+// JSON name are added as if they were fields of the struct, like encoding/json does.
+func (s *schemas) addFields(sch *schema, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		name, _ := parseJSONTag(tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+		if field.Embedded() && name == "" {
+			if embedded, ok := embeddedStruct(field.Type()); ok {
+				s.addFields(sch, embedded)
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+		sch.Properties[name] = s.schemaOf(field.Type())
+		if strings.Contains(tag.Get("binding"), "Required") {
+			sch.Required = append(sch.Required, name)
+		}
+	}
+}
+
+func embeddedStruct(t types.Type) (*types.Struct, bool) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		if methods := types.NewMethodSet(types.NewPointer(named)); methods.Lookup(nil, "MarshalJSON") != nil {
+			return nil, false
+		}
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	return st, ok
+}
+
+func parseJSONTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func basicSchema(t *types.Basic) *schema {
+	switch {
+	case t.Info()&types.IsBoolean != 0:
+		return &schema{Type: "boolean"}
+	case t.Kind() == types.Int32 || t.Kind() == types.Uint32:
+		return &schema{Type: "integer", Format: "int32"}
+	case t.Info()&types.IsInteger != 0:
+		return &schema{Type: "integer", Format: "int64"}
+	case t.Kind() == types.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case t.Info()&types.IsFloat != 0:
+		return &schema{Type: "number", Format: "double"}
+	case t.Info()&types.IsString != 0:
+		return &schema{Type: "string"}
+	default:
+		return &schema{}
+	}
+}
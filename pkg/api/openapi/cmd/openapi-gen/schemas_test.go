@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaOf(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "models.go", `package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Meta struct {
+	Created time.Time `+"`json:\"created\"`"+`
+}
+
+type Dashboard struct {
+	Meta
+	Id       int64           `+"`json:\"id\"`"+`
+	Title    string          `+"`json:\"title\" binding:\"Required\"`"+`
+	Tags     []string
+	Data     json.RawMessage `+"`json:\"data\"`"+`
+	Parent   *Dashboard      `+"`json:\"parent,omitempty\"`"+`
+	Ignored  bool            `+"`json:\"-\"`"+`
+	internal string
+}
+`, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("models", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	components := map[string]interface{}{}
+	s := newSchemas(components)
+	require.Equal(t, refSchema("Dashboard"), s.schemaOf(types.NewPointer(pkg.Scope().Lookup("Dashboard").Type())))
+
+	b, err := json.Marshal(components)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"Dashboard": {
+			"type": "object",
+			"properties": {
+				"created": {"type": "string", "format": "date-time"},
+				"id": {"type": "integer", "format": "int64"},
+				"title": {"type": "string"},
+				"Tags": {"type": "array", "items": {"type": "string"}},
+				"data": {},
+				"parent": {"$ref": "#/components/schemas/Dashboard"}
+			},
+			"required": ["title"]
+		}
+	}`, string(b))
+}
+
+func TestSchemaOfRenamesTakenNames(t *testing.T) {
+	pkg := types.NewPackage("github.com/grafana/grafana/pkg/api/dtos", "dtos")
+	st := types.NewStruct([]*types.Var{types.NewField(token.NoPos, pkg, "Name", types.Typ[types.String], false)}, nil)
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Alert", nil), st, nil)
+
+	components := map[string]interface{}{"Alert": &schema{}}
+	s := newSchemas(components)
+	require.Equal(t, refSchema("dtos.Alert"), s.schemaOf(named))
+	require.Contains(t, components, "dtos.Alert")
+}
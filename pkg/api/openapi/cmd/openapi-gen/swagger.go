@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parameterSchemaKeys are the keys of the Swagger 2 parameters which are moved to their schema in OpenAPI 3.
+var parameterSchemaKeys = []string{"type", "format", "items", "enum", "default"}
+
+// mergeSwagger converts the paths and definitions of a Swagger 2 specification to OpenAPI 3, and adds them to the
+// document.
+func mergeSwagger(doc *document, b []byte) error {
+	var spec struct {
+		Paths       map[string]map[string]interface{} `json:"paths"`
+		Definitions map[string]interface{}            `json:"definitions"`
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return err
+	}
+
+	for name, definition := range spec.Definitions {
+		if _, exists := doc.Components.Schemas[name]; exists {
+			return fmt.Errorf("schema %s is already defined", name)
+		}
+		doc.Components.Schemas[name] = convertRefs(definition)
+	}
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			op, ok := op.(map[string]interface{})
+			if _, isMethod := routeMethods[strings.Title(method)]; !ok || !isMethod {
+				continue
+			}
+			if _, exists := doc.Paths[path][method]; exists {
+				return fmt.Errorf("operation %s %s is already defined", method, path)
+			}
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = map[string]interface{}{}
+			}
+			doc.Paths[path][method] = convertOperation(op)
+		}
+	}
+	return nil
+}
+
+func convertOperation(op map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range op {
+		switch key {
+		case "parameters", "responses", "consumes", "produces", "schemes", "security":
+		default:
+			result[key] = value
+		}
+	}
+
+	var parameters []interface{}
+	params, _ := op["parameters"].([]interface{})
+	for _, p := range params {
+		param, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if param["in"] == "body" {
+			result["requestBody"] = map[string]interface{}{
+				"required": param["required"] == true,
+				"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": convertRefs(param["schema"])}},
+			}
+			continue
+		}
+		converted := map[string]interface{}{}
+		sch := map[string]interface{}{}
+		for key, value := range param {
+			switch {
+			case contains(parameterSchemaKeys, key):
+				sch[key] = convertRefs(value)
+			case key == "name" || key == "in" || key == "required" || key == "description":
+				converted[key] = value
+			}
+		}
+		converted["schema"] = sch
+		parameters = append(parameters, converted)
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	responses := map[string]interface{}{}
+	resps, _ := op["responses"].(map[string]interface{})
+	for code, r := range resps {
+		resp, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		converted := map[string]interface{}{"description": resp["description"]}
+		if sch, ok := resp["schema"]; ok {
+			converted["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": convertRefs(sch)}}
+		}
+		responses[code] = converted
+	}
+	result["responses"] = responses
+	return result
+}
+
+// convertRefs returns the value with the references to the Swagger 2 definitions replaced with references to the
+// OpenAPI 3 schema components.
+func convertRefs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				result[key] = schemaRefPrefix + strings.TrimPrefix(ref, "#/definitions/")
+				continue
+			}
+			result[key] = convertRefs(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			result = append(result, convertRefs(value))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
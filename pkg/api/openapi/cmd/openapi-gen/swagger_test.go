@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSwagger(t *testing.T) {
+	doc := newDocument()
+	err := mergeSwagger(doc, []byte(`{
+		"swagger": "2.0",
+		"paths": {
+			"/api/ruler/{Recipient}/api/v1/rules": {
+				"post": {
+					"operationId": "RoutePostRules",
+					"consumes": ["application/json"],
+					"parameters": [
+						{"in": "path", "name": "Recipient", "required": true, "type": "string", "x-go-name": "Recipient"},
+						{"in": "body", "name": "Body", "required": true, "schema": {"$ref": "#/definitions/RuleGroup"}}
+					],
+					"responses": {
+						"202": {"description": "Ack", "schema": {"$ref": "#/definitions/Ack"}}
+					}
+				}
+			}
+		},
+		"definitions": {
+			"Ack": {"type": "object"},
+			"RuleGroup": {"type": "object", "properties": {"rules": {"type": "array", "items": {"$ref": "#/definitions/Rule"}}}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	b, err := json.Marshal(doc.Paths)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"/api/ruler/{Recipient}/api/v1/rules": {
+			"post": {
+				"operationId": "RoutePostRules",
+				"parameters": [{"in": "path", "name": "Recipient", "required": true, "schema": {"type": "string"}}],
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/RuleGroup"}}}
+				},
+				"responses": {
+					"202": {
+						"description": "Ack",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Ack"}}}
+					}
+				}
+			}
+		}
+	}`, string(b))
+	require.Equal(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/Rule"}},
+		doc.Components.Schemas["RuleGroup"].(map[string]interface{})["properties"].(map[string]interface{})["rules"])
+
+	require.Error(t, mergeSwagger(doc, []byte(`{"definitions": {"Ack": {}}}`)))
+}
@@ -0,0 +1,16 @@
+// Package openapi contains the OpenAPI 3 specification of the HTTP API, which is generated from its routes by
+// `make openapi3`.
+package openapi
+
+import (
+	// Embeds the specification.
+	_ "embed"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec returns the OpenAPI 3 specification of the HTTP API as JSON.
+func Spec() []byte {
+	return spec
+}
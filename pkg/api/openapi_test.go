@@ -0,0 +1,24 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	resp := GetOpenAPISpec(&models.ReqContext{})
+	require.Equal(t, http.StatusOK, resp.Status())
+
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body(), &spec))
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+	require.Contains(t, spec.Paths, "/api/openapi.json")
+	require.Contains(t, spec.Paths, "/api/dashboards/uid/{uid}")
+}
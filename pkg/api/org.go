@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -100,15 +101,21 @@ func CreateOrg(c *models.ReqContext, cmd models.CreateOrgCommand) response.Respo
 
 // PUT /api/org
 func UpdateOrgCurrent(c *models.ReqContext, form dtos.UpdateOrgForm) response.Response {
-	return updateOrgHelper(form, c.OrgId)
+	return updateOrgHelper(c, form, c.OrgId)
 }
 
 // PUT /api/orgs/:orgId
 func UpdateOrg(c *models.ReqContext, form dtos.UpdateOrgForm) response.Response {
-	return updateOrgHelper(form, c.ParamsInt64(":orgId"))
+	return updateOrgHelper(c, form, c.ParamsInt64(":orgId"))
 }
 
-func updateOrgHelper(form dtos.UpdateOrgForm, orgID int64) response.Response {
+func updateOrgHelper(c *models.ReqContext, form dtos.UpdateOrgForm, orgID int64) response.Response {
+	var before interface{}
+	orgQuery := models.GetOrgByIdQuery{Id: orgID}
+	if err := bus.Dispatch(&orgQuery); err == nil {
+		before = dtos.UpdateOrgForm{Name: orgQuery.Result.Name}
+	}
+
 	cmd := models.UpdateOrgCommand{Name: form.Name, OrgId: orgID}
 	if err := bus.Dispatch(&cmd); err != nil {
 		if errors.Is(err, models.ErrOrgNameTaken) {
@@ -116,6 +123,15 @@ func updateOrgHelper(form dtos.UpdateOrgForm, orgID int64) response.Response {
 		}
 		return response.Error(500, "Failed to update organization", err)
 	}
+	recordAuditEntry(c, &models.RecordAuditEntryCommand{
+		OrgId:        orgID,
+		Action:       models.AuditActionUpdate,
+		ResourceType: models.AuditResourceOrg,
+		ResourceId:   strconv.FormatInt(orgID, 10),
+		ResourceName: form.Name,
+		Before:       before,
+		After:        form,
+	})
 
 	return response.Success("Organization updated")
 }
@@ -161,7 +177,33 @@ func DeleteOrgByID(c *models.ReqContext) response.Response {
 	return response.Success("Organization deleted")
 }
 
+// GET /api/orgs
 func SearchOrgs(c *models.ReqContext) response.Response {
+	query, rsp := searchOrgs(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(200, query.Result)
+}
+
+// GET /api/orgs/search
+func SearchOrgsWithPaging(c *models.ReqContext) response.Response {
+	query, rsp := searchOrgs(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(200, models.SearchOrgQueryResult{
+		TotalCount: query.TotalCount,
+		Orgs:       query.Result,
+		Page:       query.Page,
+		PerPage:    query.Limit,
+		NextCursor: query.NextCursor,
+	})
+}
+
+func searchOrgs(c *models.ReqContext) (*models.SearchOrgsQuery, response.Response) {
 	perPage := c.QueryInt("perpage")
 	if perPage <= 0 {
 		perPage = 1000
@@ -169,16 +211,23 @@ func SearchOrgs(c *models.ReqContext) response.Response {
 
 	page := c.QueryInt("page")
 
-	query := models.SearchOrgsQuery{
-		Query: c.Query("query"),
-		Name:  c.Query("name"),
-		Page:  page,
-		Limit: perPage,
+	sort, err := models.ParseSearchSort(c.Query("sort"))
+	if err != nil {
+		return nil, searchErrorResponse(err, "Failed to search orgs")
 	}
 
-	if err := bus.Dispatch(&query); err != nil {
-		return response.Error(500, "Failed to search orgs", err)
+	query := &models.SearchOrgsQuery{
+		Query:  c.Query("query"),
+		Name:   c.Query("name"),
+		Page:   page,
+		Limit:  perPage,
+		Sort:   sort,
+		Cursor: c.Query("cursor"),
 	}
 
-	return response.JSON(200, query.Result)
+	if err := bus.Dispatch(query); err != nil {
+		return nil, searchErrorResponse(err, "Failed to search orgs")
+	}
+
+	return query, nil
 }
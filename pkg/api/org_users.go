@@ -13,16 +13,16 @@ import (
 // POST /api/org/users
 func AddOrgUserToCurrentOrg(c *models.ReqContext, cmd models.AddOrgUserCommand) response.Response {
 	cmd.OrgId = c.OrgId
-	return addOrgUserHelper(cmd)
+	return addOrgUserHelper(c, cmd)
 }
 
 // POST /api/orgs/:orgId/users
 func AddOrgUser(c *models.ReqContext, cmd models.AddOrgUserCommand) response.Response {
 	cmd.OrgId = c.ParamsInt64(":orgId")
-	return addOrgUserHelper(cmd)
+	return addOrgUserHelper(c, cmd)
 }
 
-func addOrgUserHelper(cmd models.AddOrgUserCommand) response.Response {
+func addOrgUserHelper(c *models.ReqContext, cmd models.AddOrgUserCommand) response.Response {
 	if !cmd.Role.IsValid() {
 		return response.Error(400, "Invalid role specified", nil)
 	}
@@ -46,6 +46,7 @@ func addOrgUserHelper(cmd models.AddOrgUserCommand) response.Response {
 		}
 		return response.Error(500, "Could not add user to organization", err)
 	}
+	auditOrgUserChange(c, models.AuditActionCreate, cmd.OrgId, cmd.UserId, nil, &orgUserAuditState{Login: userToAdd.Login, Role: cmd.Role})
 
 	return response.JSON(200, util.DynMap{
 		"message": "User added to organization",
@@ -202,33 +203,35 @@ func (hs *HTTPServer) SearchOrgUsersWithPaging(c *models.ReqContext) response.Re
 func UpdateOrgUserForCurrentOrg(c *models.ReqContext, cmd models.UpdateOrgUserCommand) response.Response {
 	cmd.OrgId = c.OrgId
 	cmd.UserId = c.ParamsInt64(":userId")
-	return updateOrgUserHelper(cmd)
+	return updateOrgUserHelper(c, cmd)
 }
 
 // PATCH /api/orgs/:orgId/users/:userId
 func UpdateOrgUser(c *models.ReqContext, cmd models.UpdateOrgUserCommand) response.Response {
 	cmd.OrgId = c.ParamsInt64(":orgId")
 	cmd.UserId = c.ParamsInt64(":userId")
-	return updateOrgUserHelper(cmd)
+	return updateOrgUserHelper(c, cmd)
 }
 
-func updateOrgUserHelper(cmd models.UpdateOrgUserCommand) response.Response {
+func updateOrgUserHelper(c *models.ReqContext, cmd models.UpdateOrgUserCommand) response.Response {
 	if !cmd.Role.IsValid() {
 		return response.Error(400, "Invalid role specified", nil)
 	}
+	before := getOrgUserAuditState(c, cmd.OrgId, cmd.UserId)
 	if err := bus.Dispatch(&cmd); err != nil {
 		if errors.Is(err, models.ErrLastOrgAdmin) {
 			return response.Error(400, "Cannot change role so that there is no organization admin left", nil)
 		}
 		return response.Error(500, "Failed update org user", err)
 	}
+	auditOrgUserChange(c, models.AuditActionUpdate, cmd.OrgId, cmd.UserId, before, getOrgUserAuditState(c, cmd.OrgId, cmd.UserId))
 
 	return response.Success("Organization user updated")
 }
 
 // DELETE /api/org/users/:userId
 func RemoveOrgUserForCurrentOrg(c *models.ReqContext) response.Response {
-	return removeOrgUserHelper(&models.RemoveOrgUserCommand{
+	return removeOrgUserHelper(c, &models.RemoveOrgUserCommand{
 		UserId:                   c.ParamsInt64(":userId"),
 		OrgId:                    c.OrgId,
 		ShouldDeleteOrphanedUser: true,
@@ -237,19 +240,21 @@ func RemoveOrgUserForCurrentOrg(c *models.ReqContext) response.Response {
 
 // DELETE /api/orgs/:orgId/users/:userId
 func RemoveOrgUser(c *models.ReqContext) response.Response {
-	return removeOrgUserHelper(&models.RemoveOrgUserCommand{
+	return removeOrgUserHelper(c, &models.RemoveOrgUserCommand{
 		UserId: c.ParamsInt64(":userId"),
 		OrgId:  c.ParamsInt64(":orgId"),
 	})
 }
 
-func removeOrgUserHelper(cmd *models.RemoveOrgUserCommand) response.Response {
+func removeOrgUserHelper(c *models.ReqContext, cmd *models.RemoveOrgUserCommand) response.Response {
+	before := getOrgUserAuditState(c, cmd.OrgId, cmd.UserId)
 	if err := bus.Dispatch(cmd); err != nil {
 		if errors.Is(err, models.ErrLastOrgAdmin) {
 			return response.Error(400, "Cannot remove last organization admin", nil)
 		}
 		return response.Error(500, "Failed to remove user from organization", err)
 	}
+	auditOrgUserChange(c, models.AuditActionDelete, cmd.OrgId, cmd.UserId, before, nil)
 
 	if cmd.UserWasDeleted {
 		return response.Success("User deleted")
@@ -180,6 +180,12 @@ func (proxy *DataSourceProxy) director(req *http.Request) {
 
 	reqQueryVals := req.URL.Query()
 
+	if oauthtoken.IsOAuthPassThruEnabled(proxy.ds) {
+		// the OAuth identity of the user is only forwarded from its token, never from the headers of the client
+		req.Header.Del("Authorization")
+		req.Header.Del("X-ID-Token")
+	}
+
 	switch proxy.ds.Type {
 	case models.DS_INFLUXDB_08:
 		req.URL.RawPath = util.JoinURLFragments(proxy.targetUrl.Path, "db/"+proxy.ds.Database+"/"+proxy.proxyPath)
@@ -240,6 +246,10 @@ func (proxy *DataSourceProxy) director(req *http.Request) {
 	if oauthtoken.IsOAuthPassThruEnabled(proxy.ds) {
 		if token := oauthtoken.GetCurrentOAuthToken(proxy.ctx.Req.Context(), proxy.ctx.SignedInUser); token != nil {
 			req.Header.Set("Authorization", fmt.Sprintf("%s %s", token.Type(), token.AccessToken))
+
+			if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+				req.Header.Set("X-ID-Token", idToken)
+			}
 		}
 	}
 }
@@ -476,6 +476,39 @@ func TestDataSourceProxy_routeRule(t *testing.T) {
 		assert.Equal(t, "Bearer testtoken", req.Header.Get("Authorization"))
 	})
 
+	t.Run("When proxying a datasource that has OAuth token pass-through enabled for a user without token", func(t *testing.T) {
+		bus.AddHandler("test", func(query *models.GetAuthInfoQuery) error {
+			return models.ErrUserNotFound
+		})
+
+		ds := &models.DataSource{
+			Type: "custom-datasource",
+			Url:  "http://host/root/",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"oauthPassThru": true,
+			}),
+		}
+		req, err := http.NewRequest("GET", "http://localhost/asd", nil)
+		require.NoError(t, err)
+		ctx := &models.ReqContext{
+			SignedInUser: &models.SignedInUser{UserId: 1},
+			Context: &macaron.Context{
+				Req: macaron.Request{Request: req},
+			},
+		}
+		proxy, err := NewDataSourceProxy(ds, &plugins.DataSourcePlugin{}, ctx, "/path/to/folder/", &setting.Cfg{}, httpClientProvider)
+		require.NoError(t, err)
+		req, err = http.NewRequest(http.MethodGet, "http://grafana.com/sub", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer client")
+		req.Header.Set("X-ID-Token", "client")
+
+		proxy.director(req)
+
+		assert.Empty(t, req.Header.Get("Authorization"))
+		assert.Empty(t, req.Header.Get("X-ID-Token"))
+	})
+
 	t.Run("When SendUserHeader config is enabled", func(t *testing.T) {
 		req := getDatasourceProxiedRequest(
 			t,
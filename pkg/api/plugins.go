@@ -156,9 +156,25 @@ func (hs *HTTPServer) UpdatePluginSetting(c *models.ReqContext, cmd models.Updat
 
 	cmd.OrgId = c.OrgId
 	cmd.PluginId = pluginID
+
+	var before interface{}
+	settingQuery := models.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: c.OrgId}
+	if err := bus.Dispatch(&settingQuery); err == nil {
+		before = pluginSettingsAuditState{
+			Enabled:  settingQuery.Result.Enabled,
+			Pinned:   settingQuery.Result.Pinned,
+			JsonData: settingQuery.Result.JsonData,
+		}
+	}
+
 	if err := bus.Dispatch(&cmd); err != nil {
 		return response.Error(500, "Failed to update plugin setting", err)
 	}
+	auditChange(c, models.AuditActionUpdate, models.AuditResourcePluginSettings, pluginID, pluginID, before, pluginSettingsAuditState{
+		Enabled:  cmd.Enabled,
+		Pinned:   cmd.Pinned,
+		JsonData: cmd.JsonData,
+	})
 
 	return response.Success("Plugin settings updated")
 }
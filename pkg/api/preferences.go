@@ -1,6 +1,8 @@
 package api
 
 import (
+	"strconv"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
@@ -32,9 +34,10 @@ func getPreferencesFor(orgID, userID, teamID int64) response.Response {
 	}
 
 	dto := dtos.Prefs{
-		Theme:           prefsQuery.Result.Theme,
-		HomeDashboardID: prefsQuery.Result.HomeDashboardId,
-		Timezone:        prefsQuery.Result.Timezone,
+		Theme:                prefsQuery.Result.Theme,
+		HomeDashboardID:      prefsQuery.Result.HomeDashboardId,
+		Timezone:             prefsQuery.Result.Timezone,
+		QueryCachingDisabled: prefsQuery.Result.QueryCachingDisabled,
 	}
 
 	return response.JSON(200, &dto)
@@ -47,12 +50,13 @@ func UpdateUserPreferences(c *models.ReqContext, dtoCmd dtos.UpdatePrefsCmd) res
 
 func updatePreferencesFor(orgID, userID, teamId int64, dtoCmd *dtos.UpdatePrefsCmd) response.Response {
 	saveCmd := models.SavePreferencesCommand{
-		UserId:          userID,
-		OrgId:           orgID,
-		TeamId:          teamId,
-		Theme:           dtoCmd.Theme,
-		Timezone:        dtoCmd.Timezone,
-		HomeDashboardId: dtoCmd.HomeDashboardID,
+		UserId:               userID,
+		OrgId:                orgID,
+		TeamId:               teamId,
+		Theme:                dtoCmd.Theme,
+		Timezone:             dtoCmd.Timezone,
+		HomeDashboardId:      dtoCmd.HomeDashboardID,
+		QueryCachingDisabled: dtoCmd.QueryCachingDisabled,
 	}
 
 	if err := bus.Dispatch(&saveCmd); err != nil {
@@ -69,5 +73,20 @@ func GetOrgPreferences(c *models.ReqContext) response.Response {
 
 // PUT /api/org/preferences
 func UpdateOrgPreferences(c *models.ReqContext, dtoCmd dtos.UpdatePrefsCmd) response.Response {
-	return updatePreferencesFor(c.OrgId, 0, 0, &dtoCmd)
+	var before interface{}
+	prefsQuery := models.GetPreferencesQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&prefsQuery); err == nil {
+		before = dtos.UpdatePrefsCmd{
+			Theme:                prefsQuery.Result.Theme,
+			HomeDashboardID:      prefsQuery.Result.HomeDashboardId,
+			Timezone:             prefsQuery.Result.Timezone,
+			QueryCachingDisabled: prefsQuery.Result.QueryCachingDisabled,
+		}
+	}
+
+	rsp := updatePreferencesFor(c.OrgId, 0, 0, &dtoCmd)
+	if rsp.Status() == 200 {
+		auditChange(c, models.AuditActionUpdate, models.AuditResourcePreferences, strconv.FormatInt(c.OrgId, 10), "", before, dtoCmd)
+	}
+	return rsp
 }
@@ -0,0 +1,351 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+const (
+	// mixedDataSourceName is the data source of the panels whose queries have their own data sources.
+	mixedDataSourceName = "-- Mixed --"
+	// publicPanelMaxDataPoints is the max data points of the queries of the panels of the public dashboards that
+	// don't set theirs.
+	publicPanelMaxDataPoints = 1000
+)
+
+// GetPublicDashboardConfig returns the public dashboard of a dashboard, disabled when it was never published.
+// GET /api/dashboards/uid/:uid/public-config
+func (hs *HTTPServer) GetPublicDashboardConfig(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	publicDashboard, err := hs.PublicDashboardsService.GetPublicDashboardConfig(c.Req.Context(), c.OrgId, dash.Uid)
+	if errors.Is(err, models.ErrPublicDashboardNotFound) {
+		publicDashboard = &models.PublicDashboard{OrgId: c.OrgId, DashboardUid: dash.Uid, TimeRanges: []models.PublicDashboardTimeRange{}}
+	} else if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get public dashboard", err)
+	}
+
+	return response.JSON(http.StatusOK, publicDashboard)
+}
+
+// SavePublicDashboardConfig publishes or unpublishes a dashboard. The queries of the public dashboard run with the
+// data source permissions of the user saving it, so only the admins of the dashboard may publish it.
+// POST /api/dashboards/uid/:uid/public-config
+func (hs *HTTPServer) SavePublicDashboardConfig(c *models.ReqContext, cmd models.SavePublicDashboardCommand) response.Response {
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canAdmin, err := guardian.CanAdmin(); err != nil || !canAdmin {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd.DashboardUid = dash.Uid
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+	publicDashboard, err := hs.PublicDashboardsService.SavePublicDashboardConfig(c.Req.Context(), &cmd)
+	if errors.Is(err, models.ErrPublicDashboardInvalidTimeRange) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save public dashboard", err)
+	}
+
+	return response.JSON(http.StatusOK, publicDashboard)
+}
+
+// GetPublicDashboard returns the dashboard published with the access token, without the queries of its panels.
+// GET /api/public/dashboards/:accessToken
+func (hs *HTTPServer) GetPublicDashboard(c *models.ReqContext) response.Response {
+	publicDashboard, dash, rsp := hs.getPublicDashboard(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	data, err := publicDashboardJSON(dash.Data)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to load public dashboard", err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.PublicDashboard{Dashboard: data, TimeRanges: publicDashboard.TimeRanges})
+}
+
+// QueryPublicDashboardPanel runs the saved queries of a panel of a public dashboard with the data source permissions
+// of the user who published it, within the time range of the dashboard or one of the allowed time ranges.
+// POST /api/public/dashboards/:accessToken/panels/:panelId/query
+func (hs *HTTPServer) QueryPublicDashboardPanel(c *models.ReqContext, reqDTO dtos.PublicDashboardQuery) response.Response {
+	panelID, err := strconv.ParseInt(c.Params(":panelId"), 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid panel ID", err)
+	}
+
+	publicDashboard, dash, rsp := hs.getPublicDashboard(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	timeRange, err := publicDashboardTimeRange(publicDashboard, dash.Data, reqDTO)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
+	panel := findDashboardPanel(dash.Data, panelID)
+	if panel == nil {
+		return response.Error(http.StatusNotFound, models.ErrPublicDashboardPanelNotFound.Error(), nil)
+	}
+
+	userQuery := models.GetSignedInUserQuery{UserId: publicDashboard.CreatedBy, OrgId: publicDashboard.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &userQuery); err != nil {
+		return response.Error(http.StatusForbidden, "The user who published the dashboard can't query it", err)
+	}
+	owner := userQuery.Result
+
+	guardian := guardian.New(dash.Id, owner.OrgId, owner)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	// the cached results are only served while the publisher can view the dashboard, and the version of the
+	// dashboard is part of the key so that the results of edited panels aren't served
+	cacheKey := fmt.Sprintf("%s:%d:%d:%s:%s", publicDashboard.AccessToken, dash.Version, panelID, timeRange.From, timeRange.To)
+	if qdr, ok := hs.PublicDashboardsService.GetQueryResult(cacheKey); ok {
+		return toMacronResponse(qdr)
+	}
+
+	requests, rsp := hs.publicPanelRequests(c.Req.Context(), owner, panel, timeRange)
+	if rsp != nil {
+		return rsp
+	}
+
+	qdr := queryDataSources(c.Req.Context(), requests, hs.DataService.HandleRequest)
+	hs.PublicDashboardsService.SetQueryResult(cacheKey, qdr)
+	return toMacronResponse(qdr)
+}
+
+// getPublicDashboard returns the public dashboard of the access token of the request and its dashboard, or rejects
+// the requests over the rate limit of the public dashboard. The access token is looked up first, so that only the
+// published dashboards have a rate limiter.
+func (hs *HTTPServer) getPublicDashboard(c *models.ReqContext) (*models.PublicDashboard, *models.Dashboard, response.Response) {
+	publicDashboard, err := hs.PublicDashboardsService.GetPublicDashboardByAccessToken(c.Req.Context(), c.Params(":accessToken"))
+	if errors.Is(err, models.ErrPublicDashboardNotFound) {
+		return nil, nil, response.Error(http.StatusNotFound, "Public dashboard not found", nil)
+	}
+	if err != nil {
+		return nil, nil, response.Error(http.StatusInternalServerError, "Failed to get public dashboard", err)
+	}
+	if !hs.PublicDashboardsService.AllowRequest(publicDashboard.AccessToken) {
+		return nil, nil, response.Error(http.StatusTooManyRequests, "Too many requests", nil)
+	}
+
+	dash, rsp := getDashboardHelper(c.Req.Context(), publicDashboard.OrgId, 0, publicDashboard.DashboardUid)
+	if rsp != nil {
+		return nil, nil, rsp
+	}
+	return publicDashboard, dash, nil
+}
+
+// publicPanelRequests returns the requests to the data sources of the queries of a panel. The queries run as the
+// user, so that they fail when the user isn't allowed to query their data sources.
+func (hs *HTTPServer) publicPanelRequests(ctx context.Context, user *models.SignedInUser, panel *simplejson.Json,
+	timeRange plugins.DataTimeRange) ([]dataSourceRequest, response.Response) {
+	panelDataSource := panel.Get("datasource").MustString("")
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(publicPanelMaxDataPoints)
+	if maxDataPoints <= 0 {
+		maxDataPoints = publicPanelMaxDataPoints
+	}
+	intervalMS := (timeRange.GetToAsMsEpoch() - timeRange.GetFromAsMsEpoch()) / maxDataPoints
+	if intervalMS < 1 {
+		intervalMS = 1
+	}
+
+	requests := make([]dataSourceRequest, 0, 1)
+	requestIndexes := map[string]int{}
+	targets := panel.Get("targets")
+	for i := range targets.MustArray() {
+		query := targets.GetIndex(i)
+		if query.Get("hide").MustBool(false) {
+			continue
+		}
+
+		dsName := panelDataSource
+		if dsName == mixedDataSourceName {
+			dsName = query.Get("datasource").MustString("")
+		}
+		if dsName == expr.DatasourceName {
+			return nil, response.Error(http.StatusBadRequest, "Expressions are not supported on public dashboards", nil)
+		}
+
+		index, ok := requestIndexes[dsName]
+		if !ok {
+			ds, err := hs.getPublicPanelDataSource(ctx, user, dsName)
+			if err != nil {
+				return nil, publicPanelDataSourceError(err)
+			}
+			if err := hs.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+				return nil, response.Error(http.StatusForbidden, "Access denied", err)
+			}
+
+			index = len(requests)
+			requestIndexes[dsName] = index
+			requests = append(requests, dataSourceRequest{
+				ds: ds,
+				request: plugins.DataQuery{
+					TimeRange: &timeRange,
+					User:      user,
+				},
+			})
+		}
+
+		requests[index].request.Queries = append(requests[index].request.Queries, plugins.DataSubQuery{
+			RefID:         query.Get("refId").MustString("A"),
+			MaxDataPoints: maxDataPoints,
+			IntervalMS:    intervalMS,
+			QueryType:     query.Get("queryType").MustString(""),
+			Model:         query,
+			DataSource:    requests[index].ds,
+		})
+	}
+
+	if len(requests) == 0 {
+		return nil, response.Error(http.StatusBadRequest, "The panel has no queries", nil)
+	}
+	return requests, nil
+}
+
+// getPublicPanelDataSource returns the data source of a panel by name, or the default data source of the org of the
+// user when the panel doesn't have one, checking that the user may query it.
+func (hs *HTTPServer) getPublicPanelDataSource(ctx context.Context, user *models.SignedInUser, name string) (*models.DataSource, error) {
+	var dsID int64
+	if name == "" || name == "default" {
+		query := models.GetDefaultDataSourceQuery{OrgId: user.OrgId}
+		if err := bus.DispatchCtx(ctx, &query); err != nil {
+			return nil, err
+		}
+		dsID = query.Result.Id
+	} else {
+		query := models.GetDataSourceQuery{Name: name, OrgId: user.OrgId}
+		if err := bus.DispatchCtx(ctx, &query); err != nil {
+			return nil, err
+		}
+		dsID = query.Result.Id
+	}
+
+	return hs.DatasourceCache.GetDatasource(dsID, user, false)
+}
+
+func publicPanelDataSourceError(err error) response.Response {
+	if errors.Is(err, models.ErrDataSourceAccessDenied) {
+		return response.Error(http.StatusForbidden, "Access denied to data source", err)
+	}
+	if errors.Is(err, models.ErrDataSourceNotFound) {
+		return response.Error(http.StatusBadRequest, "Data source not found", err)
+	}
+	return response.Error(http.StatusInternalServerError, "Unable to load data source metadata", err)
+}
+
+// publicDashboardTimeRange returns the time range of a query of a public dashboard: the time range of the dashboard
+// when the query doesn't have one, or the time range of the query when it is the time range of the dashboard or one
+// of the allowed time ranges.
+func publicDashboardTimeRange(publicDashboard *models.PublicDashboard, dashboard *simplejson.Json,
+	reqDTO dtos.PublicDashboardQuery) (plugins.DataTimeRange, error) {
+	dashboardRange := models.PublicDashboardTimeRange{
+		From: dashboard.GetPath("time", "from").MustString("now-6h"),
+		To:   dashboard.GetPath("time", "to").MustString("now"),
+	}
+	if reqDTO.From == "" && reqDTO.To == "" {
+		return plugins.NewDataTimeRange(dashboardRange.From, dashboardRange.To), nil
+	}
+
+	requested := models.PublicDashboardTimeRange{From: reqDTO.From, To: reqDTO.To}
+	allowed := append([]models.PublicDashboardTimeRange{dashboardRange}, publicDashboard.TimeRanges...)
+	for _, tr := range allowed {
+		if tr == requested {
+			return plugins.NewDataTimeRange(tr.From, tr.To), nil
+		}
+	}
+	return plugins.DataTimeRange{}, models.ErrPublicDashboardTimeRangeNotAllowed
+}
+
+// publicDashboardJSON returns a copy of the dashboard without the queries of its panels, which run on the server.
+func publicDashboardJSON(dashboard *simplejson.Json) (*simplejson.Json, error) {
+	raw, err := dashboard.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	public, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, panel := range dashboardPanels(public) {
+		targets, ok := panel.CheckGet("targets")
+		if !ok {
+			continue
+		}
+		refIDs := make([]interface{}, 0, len(targets.MustArray()))
+		for i := range targets.MustArray() {
+			target := targets.GetIndex(i)
+			refIDs = append(refIDs, map[string]interface{}{
+				"refId": target.Get("refId").MustString(""),
+				"hide":  target.Get("hide").MustBool(false),
+			})
+		}
+		panel.Set("targets", refIDs)
+	}
+	return public, nil
+}
+
+// findDashboardPanel returns the panel of the dashboard with the ID, or nil.
+func findDashboardPanel(dashboard *simplejson.Json, panelID int64) *simplejson.Json {
+	for _, panel := range dashboardPanels(dashboard) {
+		if id, err := panel.Get("id").Int64(); err == nil && id == panelID {
+			return panel
+		}
+	}
+	return nil
+}
+
+// dashboardPanels returns the panels of the dashboard, including the panels of its collapsed rows and the panels of
+// the rows of the dashboards saved before the rows became panels.
+func dashboardPanels(dashboard *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	collect := func(list *simplejson.Json) {
+		for i := range list.MustArray() {
+			panel := list.GetIndex(i)
+			panels = append(panels, panel)
+			nested := panel.Get("panels")
+			for j := range nested.MustArray() {
+				panels = append(panels, nested.GetIndex(j))
+			}
+		}
+	}
+
+	collect(dashboard.Get("panels"))
+	rows := dashboard.Get("rows")
+	for i := range rows.MustArray() {
+		collect(rows.GetIndex(i).Get("panels"))
+	}
+	return panels
+}
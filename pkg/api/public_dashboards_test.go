@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublicDashboardsService publishes the dashboard "dash" with the access token "token", and allows the given
+// number of requests to it. It has a cached query result for every key, and records the keys looked up.
+type fakePublicDashboardsService struct {
+	publicdashboards.Service
+	allowed   int
+	limiters  map[string]int
+	cacheKeys []string
+}
+
+func (s *fakePublicDashboardsService) GetPublicDashboardByAccessToken(_ context.Context, accessToken string) (*models.PublicDashboard, error) {
+	if accessToken != "token" {
+		return nil, models.ErrPublicDashboardNotFound
+	}
+	return &models.PublicDashboard{OrgId: testOrgID, DashboardUid: "dash", AccessToken: accessToken, IsEnabled: true, CreatedBy: 1}, nil
+}
+
+func (s *fakePublicDashboardsService) GetQueryResult(key string) (*backend.QueryDataResponse, bool) {
+	s.cacheKeys = append(s.cacheKeys, key)
+	return backend.NewQueryDataResponse(), true
+}
+
+func (s *fakePublicDashboardsService) AllowRequest(accessToken string) bool {
+	s.limiters[accessToken]++
+	return s.limiters[accessToken] <= s.allowed
+}
+
+func TestGetPublicDashboard(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+		query.Result = &models.Dashboard{Id: 1, Uid: "dash", OrgId: testOrgID, Data: simplejson.NewFromAny(map[string]interface{}{"panels": []interface{}{}})}
+		return nil
+	})
+
+	service := &fakePublicDashboardsService{allowed: 1, limiters: map[string]int{}}
+	hs := &HTTPServer{PublicDashboardsService: service}
+	get := func(accessToken string) int {
+		sc := setupScenarioContext(t, "/api/public/dashboards/"+accessToken)
+		sc.m.Get("/api/public/dashboards/:accessToken", routing.Wrap(hs.GetPublicDashboard))
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		return sc.resp.Code
+	}
+
+	t.Run("Unknown access tokens don't have a rate limiter", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, get("unknown"))
+		require.Equal(t, http.StatusNotFound, get("other"))
+		require.Empty(t, service.limiters)
+	})
+
+	t.Run("Requests over the rate limit of a public dashboard are rejected", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, get("token"))
+		require.Equal(t, http.StatusTooManyRequests, get("token"))
+		require.Equal(t, map[string]int{"token": 2}, service.limiters)
+	})
+}
+
+func TestQueryPublicDashboardPanel(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	version := 1
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+		query.Result = &models.Dashboard{Id: 1, Uid: "dash", OrgId: testOrgID, Version: version, Data: simplejson.NewFromAny(map[string]interface{}{
+			"time":   map[string]interface{}{"from": "now-1h", "to": "now"},
+			"panels": []interface{}{map[string]interface{}{"id": 1, "targets": []interface{}{}}},
+		})}
+		return nil
+	})
+	publisherDeleted := false
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+		if publisherDeleted {
+			return models.ErrUserNotFound
+		}
+		query.Result = &models.SignedInUser{UserId: query.UserId, OrgId: query.OrgId, OrgRole: models.ROLE_ADMIN}
+		return nil
+	})
+
+	service := &fakePublicDashboardsService{allowed: 100, limiters: map[string]int{}}
+	hs := &HTTPServer{PublicDashboardsService: service}
+	query := func() int {
+		sc := setupScenarioContext(t, "/api/public/dashboards/token/panels/1/query")
+		sc.m.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", routing.Wrap(func(c *models.ReqContext) response.Response {
+			return hs.QueryPublicDashboardPanel(c, dtos.PublicDashboardQuery{})
+		}))
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		return sc.resp.Code
+	}
+
+	t.Run("The cached results are kept per version of the dashboard", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, query())
+		version = 2
+		require.Equal(t, http.StatusOK, query())
+		require.Equal(t, []string{"token:1:1:now-1h:now", "token:2:1:now-1h:now"}, service.cacheKeys)
+	})
+
+	t.Run("The cached results aren't served once the publisher is deleted", func(t *testing.T) {
+		service.cacheKeys = nil
+		publisherDeleted = true
+		require.Equal(t, http.StatusForbidden, query())
+		require.Empty(t, service.cacheKeys)
+	})
+}
+
+func TestPublicDashboardTimeRange(t *testing.T) {
+	dashboard := simplejson.NewFromAny(map[string]interface{}{
+		"time": map[string]interface{}{"from": "now-1h", "to": "now"},
+	})
+	publicDashboard := &models.PublicDashboard{
+		TimeRanges: []models.PublicDashboardTimeRange{{From: "now-7d", To: "now"}},
+	}
+
+	t.Run("Defaults to the time range of the dashboard", func(t *testing.T) {
+		tr, err := publicDashboardTimeRange(publicDashboard, dashboard, dtos.PublicDashboardQuery{})
+		require.NoError(t, err)
+		require.Equal(t, "now-1h", tr.From)
+		require.Equal(t, "now", tr.To)
+	})
+
+	t.Run("Allows the time range of the dashboard and the allowed time ranges", func(t *testing.T) {
+		tr, err := publicDashboardTimeRange(publicDashboard, dashboard, dtos.PublicDashboardQuery{From: "now-7d", To: "now"})
+		require.NoError(t, err)
+		require.Equal(t, "now-7d", tr.From)
+
+		_, err = publicDashboardTimeRange(publicDashboard, dashboard, dtos.PublicDashboardQuery{From: "now-1h", To: "now"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Rejects the other time ranges", func(t *testing.T) {
+		_, err := publicDashboardTimeRange(publicDashboard, dashboard, dtos.PublicDashboardQuery{From: "now-30d", To: "now"})
+		require.Equal(t, models.ErrPublicDashboardTimeRangeNotAllowed, err)
+	})
+}
+
+func TestPublicDashboardPanels(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "datasource": "prom", "targets": [{"refId": "A", "expr": "up"}]},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "targets": [{"refId": "B", "expr": "rate(errors[5m])", "hide": true}]}
+			]}
+		],
+		"rows": [{"panels": [{"id": 4}]}]
+	}`))
+	require.NoError(t, err)
+
+	t.Run("Finds the panels of the collapsed rows and of the legacy rows", func(t *testing.T) {
+		require.Equal(t, "prom", findDashboardPanel(dashboard, 1).Get("datasource").MustString())
+		require.NotNil(t, findDashboardPanel(dashboard, 3))
+		require.NotNil(t, findDashboardPanel(dashboard, 4))
+		require.Nil(t, findDashboardPanel(dashboard, 5))
+	})
+
+	t.Run("Removes the queries from the public dashboard", func(t *testing.T) {
+		public, err := publicDashboardJSON(dashboard)
+		require.NoError(t, err)
+
+		require.Equal(t, []interface{}{map[string]interface{}{"refId": "A", "hide": false}},
+			findDashboardPanel(public, 1).Get("targets").MustArray())
+		require.Equal(t, []interface{}{map[string]interface{}{"refId": "B", "hide": true}},
+			findDashboardPanel(public, 3).Get("targets").MustArray())
+		_, ok := findDashboardPanel(public, 4).CheckGet("targets")
+		require.False(t, ok)
+
+		// The dashboard itself keeps its queries.
+		require.Equal(t, "up", findDashboardPanel(dashboard, 1).Get("targets").GetIndex(0).Get("expr").MustString())
+	})
+}
@@ -7,11 +7,11 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func GetOrgQuotas(c *models.ReqContext) response.Response {
+func (hs *HTTPServer) GetOrgQuotas(c *models.ReqContext) response.Response {
 	if !setting.Quota.Enabled {
 		return response.Error(404, "Quotas not enabled", nil)
 	}
-	query := models.GetOrgQuotasQuery{OrgId: c.ParamsInt64(":orgId")}
+	query := models.GetOrgQuotasQuery{OrgId: c.ParamsInt64(":orgId"), IsNgAlertEnabled: hs.Cfg.IsNgAlertEnabled()}
 
 	if err := bus.Dispatch(&query); err != nil {
 		return response.Error(500, "Failed to get org quotas", err)
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// The default and maximum number of resources of a page of a SCIM list.
+const (
+	defaultSCIMPageSize = 100
+	maxSCIMPageSize     = 1000
+)
+
+// scimFilterPattern matches the only filters which the identity providers use to look up the users and groups, an
+// attribute equal to a value.
+var scimFilterPattern = regexp.MustCompile(`^\s*([A-Za-z][\w.]*)\s+(?i:eq)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// scimBadRequestError is an invalid request, with the scimType of the error response of RFC 7644.
+type scimBadRequestError struct {
+	scimType string
+	detail   string
+}
+
+func (e scimBadRequestError) Error() string {
+	return e.detail
+}
+
+func scimBadRequest(scimType, format string, args ...interface{}) scimBadRequestError {
+	return scimBadRequestError{scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+func scimJSON(status int, body interface{}) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", "application/scim+json")
+}
+
+func scimError(status int, scimType, detail string) *response.NormalResponse {
+	return scimJSON(status, dtos.SCIMError{
+		Schemas:  []string{dtos.SCIMErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// scimErrorResponse returns the error response of the invalid requests, or a 500 one for the other errors.
+func scimErrorResponse(err error, message string) response.Response {
+	if badRequest, ok := err.(scimBadRequestError); ok {
+		return scimError(http.StatusBadRequest, badRequest.scimType, badRequest.detail)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+func decodeSCIMBody(c *models.ReqContext, v interface{}) error {
+	if err := json.NewDecoder(c.Req.Request.Body).Decode(v); err != nil {
+		return scimBadRequest("invalidSyntax", "Invalid request body: %s", err)
+	}
+	return nil
+}
+
+// parseSCIMFilter returns the attribute and the value of a filter, which is empty when the filter is.
+func parseSCIMFilter(filter string) (string, string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", scimBadRequest("invalidFilter", "Unsupported filter %q, only the eq operator is supported", filter)
+	}
+	value, err := strconv.Unquote(`"` + match[2] + `"`)
+	if err != nil {
+		return "", "", scimBadRequest("invalidFilter", "Invalid filter value %q", match[2])
+	}
+	return match[1], value, nil
+}
+
+// scimPage returns the 1-based index of the first resource of the page of a list, and the number of resources of
+// the page.
+func scimPage(c *models.ReqContext) (int, int) {
+	startIndex := c.QueryInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := defaultSCIMPageSize
+	if c.Query("count") != "" {
+		count = c.QueryInt("count")
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > maxSCIMPageSize {
+		count = maxSCIMPageSize
+	}
+	return startIndex, count
+}
+
+// scimPageBounds returns the bounds of the page of a list of total resources.
+func scimPageBounds(total, startIndex, count int) (int, int) {
+	start := startIndex - 1
+	if start > total {
+		start = total
+	}
+	end := start + count
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func scimListResponse(resources []interface{}, total, startIndex int) response.Response {
+	return scimJSON(http.StatusOK, dtos.SCIMListResponse{
+		Schemas:      []string{dtos.SCIMListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (hs *HTTPServer) scimLocation(resourceType string, id int64) string {
+	return fmt.Sprintf("%sapi/scim/v2/%s/%d", hs.Cfg.AppURL, resourceType, id)
+}
+
+// GET /api/scim/v2/ServiceProviderConfig
+func (hs *HTTPServer) GetSCIMServiceProviderConfig(c *models.ReqContext) response.Response {
+	supported := func(supported bool) map[string]interface{} {
+		return map[string]interface{}{"supported": supported}
+	}
+	return scimJSON(http.StatusOK, map[string]interface{}{
+		"schemas":          []string{dtos.SCIMServiceProviderConfigSchema},
+		"documentationUri": "https://grafana.com/docs/grafana/latest/http_api/scim/",
+		"patch":            supported(true),
+		"bulk":             map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]interface{}{"supported": true, "maxResults": maxSCIMPageSize},
+		"changePassword":   supported(false),
+		"sort":             supported(false),
+		"etag":             supported(false),
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "API key",
+			"description": "An API key or a service account token with the Admin role",
+		}},
+		"meta": dtos.SCIMMeta{ResourceType: "ServiceProviderConfig", Location: hs.Cfg.AppURL + "api/scim/v2/ServiceProviderConfig"},
+	})
+}
+
+// GET /api/scim/v2/ResourceTypes
+func (hs *HTTPServer) GetSCIMResourceTypes(c *models.ReqContext) response.Response {
+	resourceType := func(name, endpoint, schema string) interface{} {
+		return map[string]interface{}{
+			"schemas":  []string{dtos.SCIMResourceTypeSchema},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+			"meta":     dtos.SCIMMeta{ResourceType: "ResourceType", Location: hs.Cfg.AppURL + "api/scim/v2/ResourceTypes/" + name},
+		}
+	}
+	resources := []interface{}{
+		resourceType("User", "/Users", dtos.SCIMUserSchema),
+		resourceType("Group", "/Groups", dtos.SCIMGroupSchema),
+	}
+	return scimListResponse(resources, len(resources), 1)
+}
+
+// scimPatchOp returns the operation of a patch, in lower case.
+func scimPatchOp(op dtos.SCIMPatchOperation) (string, error) {
+	name := strings.ToLower(op.Op)
+	switch name {
+	case "add", "remove", "replace":
+		return name, nil
+	default:
+		return "", scimBadRequest("invalidSyntax", "Unsupported patch operation %q", op.Op)
+	}
+}
+
+func scimString(path string, value json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", scimBadRequest("invalidValue", "Invalid value of %s, expected a string", path)
+	}
+	return s, nil
+}
+
+// scimBool returns a boolean value, which some identity providers send as a "True" or "False" string.
+func scimBool(path string, value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, scimBadRequest("invalidValue", "Invalid value of %s, expected a boolean", path)
+}
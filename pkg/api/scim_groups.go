@@ -0,0 +1,380 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var errSCIMGroupNotFound = errors.New("team not found")
+
+// scimMemberValuePath matches the paths of a member of a group, such as members[value eq "12"].
+var scimMemberValuePath = regexp.MustCompile(`^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+// GET /api/scim/v2/Groups
+func (hs *HTTPServer) GetSCIMGroups(c *models.ReqContext) response.Response {
+	attr, value, err := parseSCIMFilter(c.Query("filter"))
+	if err != nil {
+		return scimErrorResponse(err, "")
+	}
+	startIndex, count := scimPage(c)
+
+	query := models.SearchTeamsQuery{OrgId: c.OrgId, SignedInUser: c.SignedInUser, HiddenUsers: hs.Cfg.HiddenUsers}
+	switch strings.ToLower(attr) {
+	case "", "id":
+	case "displayname":
+		query.Name = value
+	default:
+		return scimError(http.StatusBadRequest, "invalidFilter", "Unsupported filter attribute "+attr)
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list teams", err)
+	}
+	teams := query.Result.Teams
+	if strings.EqualFold(attr, "id") {
+		teams = nil
+		for _, team := range query.Result.Teams {
+			if strconv.FormatInt(team.Id, 10) == value {
+				teams = append(teams, team)
+			}
+		}
+	}
+
+	// The identity providers exclude the members when they only look up the groups.
+	withMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	start, end := scimPageBounds(len(teams), startIndex, count)
+	resources := make([]interface{}, 0, end-start)
+	for _, team := range teams[start:end] {
+		group, err := hs.toSCIMGroup(c, team, withMembers)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to list teams", err)
+		}
+		resources = append(resources, group)
+	}
+	return scimListResponse(resources, len(teams), startIndex)
+}
+
+// GET /api/scim/v2/Groups/:id
+func (hs *HTTPServer) GetSCIMGroup(c *models.ReqContext) response.Response {
+	team, err := hs.getSCIMTeam(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimGroupErrorResponse(err, "Failed to get team")
+	}
+	return hs.scimGroupResponse(c, http.StatusOK, team.Id)
+}
+
+// CreateSCIMGroup creates a team with the members of the group, which are synced from the identity provider.
+// POST /api/scim/v2/Groups
+func (hs *HTTPServer) CreateSCIMGroup(c *models.ReqContext) response.Response {
+	var group dtos.SCIMGroup
+	if err := decodeSCIMBody(c, &group); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	if group.DisplayName == "" {
+		return scimError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	members, err := hs.scimGroupMemberIDs(c, group.Members)
+	if err != nil {
+		return scimGroupErrorResponse(err, "Failed to create team")
+	}
+
+	team, err := createTeam(hs.SQLStore, group.DisplayName, "", c.OrgId)
+	if err != nil {
+		return scimGroupErrorResponse(err, "Failed to create team")
+	}
+	if err := hs.setSCIMTeamMembers(c, team.Id, nil, members); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to add team members", err)
+	}
+	return hs.scimGroupResponse(c, http.StatusCreated, team.Id)
+}
+
+// ReplaceSCIMGroup renames the team, and replaces its members with the ones of the group.
+// PUT /api/scim/v2/Groups/:id
+func (hs *HTTPServer) ReplaceSCIMGroup(c *models.ReqContext) response.Response {
+	team, err := hs.getSCIMTeam(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimGroupErrorResponse(err, "Failed to get team")
+	}
+	var group dtos.SCIMGroup
+	if err := decodeSCIMBody(c, &group); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	if err := hs.updateSCIMGroup(c, team, &group); err != nil {
+		return scimGroupErrorResponse(err, "Failed to update team")
+	}
+	return hs.scimGroupResponse(c, http.StatusOK, team.Id)
+}
+
+// PATCH /api/scim/v2/Groups/:id
+func (hs *HTTPServer) PatchSCIMGroup(c *models.ReqContext) response.Response {
+	team, err := hs.getSCIMTeam(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimGroupErrorResponse(err, "Failed to get team")
+	}
+	var patch dtos.SCIMPatchRequest
+	if err := decodeSCIMBody(c, &patch); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	group, err := hs.toSCIMGroup(c, team, true)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get team", err)
+	}
+	if err := applySCIMGroupPatch(group, patch.Operations); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	if err := hs.updateSCIMGroup(c, team, group); err != nil {
+		return scimGroupErrorResponse(err, "Failed to update team")
+	}
+	return hs.scimGroupResponse(c, http.StatusOK, team.Id)
+}
+
+// DELETE /api/scim/v2/Groups/:id
+func (hs *HTTPServer) DeleteSCIMGroup(c *models.ReqContext) response.Response {
+	if err := bus.Dispatch(&models.DeleteTeamCommand{OrgId: c.OrgId, Id: c.ParamsInt64(":id")}); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return scimGroupErrorResponse(errSCIMGroupNotFound, "")
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete team", err)
+	}
+	return response.Respond(http.StatusNoContent, []byte{})
+}
+
+func (hs *HTTPServer) getSCIMTeam(c *models.ReqContext, teamID int64) (*models.TeamDTO, error) {
+	query := models.GetTeamByIdQuery{OrgId: c.OrgId, Id: teamID, SignedInUser: c.SignedInUser, HiddenUsers: hs.Cfg.HiddenUsers}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return nil, errSCIMGroupNotFound
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (hs *HTTPServer) scimGroupResponse(c *models.ReqContext, status int, teamID int64) response.Response {
+	team, err := hs.getSCIMTeam(c, teamID)
+	if err != nil {
+		return scimGroupErrorResponse(err, "Failed to get team")
+	}
+	group, err := hs.toSCIMGroup(c, team, true)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get team", err)
+	}
+	rsp := scimJSON(status, group)
+	if status == http.StatusCreated {
+		rsp.SetHeader("Location", group.Meta.Location)
+	}
+	return rsp
+}
+
+// toSCIMGroup returns the SCIM group of a team, with its members unless withMembers is false.
+func (hs *HTTPServer) toSCIMGroup(c *models.ReqContext, team *models.TeamDTO, withMembers bool) (*dtos.SCIMGroup, error) {
+	group := &dtos.SCIMGroup{
+		Schemas:     []string{dtos.SCIMGroupSchema},
+		Id:          strconv.FormatInt(team.Id, 10),
+		DisplayName: team.Name,
+		Meta:        &dtos.SCIMMeta{ResourceType: "Group", Location: hs.scimLocation("Groups", team.Id)},
+	}
+	if !withMembers {
+		return group, nil
+	}
+
+	query := models.GetTeamMembersQuery{OrgId: c.OrgId, TeamId: team.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+	for _, member := range query.Result {
+		group.Members = append(group.Members, dtos.SCIMReference{
+			Value:   strconv.FormatInt(member.UserId, 10),
+			Display: member.Login,
+			Ref:     hs.scimLocation("Users", member.UserId),
+		})
+	}
+	return group, nil
+}
+
+// updateSCIMGroup renames the team, and adds and removes its members to match the ones of the group.
+func (hs *HTTPServer) updateSCIMGroup(c *models.ReqContext, team *models.TeamDTO, group *dtos.SCIMGroup) error {
+	if group.DisplayName == "" {
+		return scimBadRequest("invalidValue", "displayName is required")
+	}
+	members, err := hs.scimGroupMemberIDs(c, group.Members)
+	if err != nil {
+		return err
+	}
+
+	if group.DisplayName != team.Name {
+		cmd := models.UpdateTeamCommand{Id: team.Id, OrgId: c.OrgId, Name: group.DisplayName, Email: team.Email}
+		if err := bus.Dispatch(&cmd); err != nil {
+			return err
+		}
+	}
+
+	query := models.GetTeamMembersQuery{OrgId: c.OrgId, TeamId: team.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return err
+	}
+	current := make([]int64, 0, len(query.Result))
+	for _, member := range query.Result {
+		current = append(current, member.UserId)
+	}
+	return hs.setSCIMTeamMembers(c, team.Id, current, members)
+}
+
+// setSCIMTeamMembers adds the members which aren't current members of the team, and removes the current members
+// which aren't members anymore. The added members are external, like the members synced from the LDAP and OAuth
+// groups.
+func (hs *HTTPServer) setSCIMTeamMembers(c *models.ReqContext, teamID int64, current, members []int64) error {
+	isCurrent := make(map[int64]bool, len(current))
+	for _, userID := range current {
+		isCurrent[userID] = true
+	}
+	isMember := make(map[int64]bool, len(members))
+	for _, userID := range members {
+		isMember[userID] = true
+		if isCurrent[userID] {
+			continue
+		}
+		if err := addTeamMember(hs.SQLStore, userID, c.OrgId, teamID, true, 0); err != nil &&
+			!errors.Is(err, models.ErrTeamMemberAlreadyAdded) {
+			return err
+		}
+	}
+	for _, userID := range current {
+		if isMember[userID] {
+			continue
+		}
+		cmd := models.RemoveTeamMemberCommand{OrgId: c.OrgId, TeamId: teamID, UserId: userID}
+		if err := bus.Dispatch(&cmd); err != nil && !errors.Is(err, models.ErrTeamMemberNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// scimGroupMemberIDs returns the ids of the members of a group, which must be users of the org.
+func (hs *HTTPServer) scimGroupMemberIDs(c *models.ReqContext, members []dtos.SCIMReference) ([]int64, error) {
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.ParseInt(member.Value, 10, 64)
+		if err != nil {
+			return nil, scimBadRequest("invalidValue", "Member %q isn't a user of the organization", member.Value)
+		}
+		if _, err := hs.getSCIMUser(c, userID); err != nil {
+			if errors.Is(err, errSCIMUserNotFound) {
+				return nil, scimBadRequest("invalidValue", "Member %q isn't a user of the organization", member.Value)
+			}
+			return nil, err
+		}
+		ids = append(ids, userID)
+	}
+	return ids, nil
+}
+
+func scimGroupErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, errSCIMGroupNotFound):
+		return scimError(http.StatusNotFound, "", "Group not found")
+	case errors.Is(err, models.ErrTeamNameTaken):
+		return scimError(http.StatusConflict, "uniqueness", "A team with the same displayName already exists")
+	default:
+		return scimErrorResponse(err, message)
+	}
+}
+
+// applySCIMGroupPatch applies the operations of a patch request to a SCIM group. The members of a group can be
+// added, removed by value with a members[value eq "id"] path or with a list of members, or replaced. The other
+// attributes are ignored.
+func applySCIMGroupPatch(group *dtos.SCIMGroup, operations []dtos.SCIMPatchOperation) error {
+	for _, operation := range operations {
+		op, err := scimPatchOp(operation)
+		if err != nil {
+			return err
+		}
+		if operation.Path != "" {
+			if err := applySCIMGroupPatchPath(group, op, operation.Path, operation.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if op == "remove" {
+			return scimBadRequest("noTarget", "The remove operation needs a path")
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &values); err != nil {
+			return scimBadRequest("invalidValue", "The value of an operation without a path must be an object")
+		}
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if err := applySCIMGroupPatchPath(group, op, path, values[path]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func applySCIMGroupPatchPath(group *dtos.SCIMGroup, op, path string, value json.RawMessage) error {
+	attr := strings.TrimPrefix(strings.ToLower(path), strings.ToLower(dtos.SCIMGroupSchema)+":")
+
+	switch {
+	case attr == "displayname":
+		if op == "remove" {
+			return scimBadRequest("mutability", "%s can't be removed", path)
+		}
+		name, err := scimString(path, value)
+		if err != nil {
+			return err
+		}
+		group.DisplayName = name
+	case attr == "members":
+		var members []dtos.SCIMReference
+		if len(value) > 0 {
+			if err := json.Unmarshal(value, &members); err != nil {
+				return scimBadRequest("invalidValue", "Invalid value of %s, expected a list of members", path)
+			}
+		}
+		switch op {
+		case "add":
+			group.Members = append(group.Members, members...)
+		case "replace":
+			group.Members = members
+		case "remove":
+			if len(value) == 0 {
+				group.Members = nil
+			}
+			for _, member := range members {
+				group.Members = removeSCIMMember(group.Members, member.Value)
+			}
+		}
+	case scimMemberValuePath.MatchString(attr):
+		if op != "remove" {
+			return scimBadRequest("invalidPath", "Only the remove operation is supported on %s", path)
+		}
+		group.Members = removeSCIMMember(group.Members, scimMemberValuePath.FindStringSubmatch(attr)[1])
+	}
+	return nil
+}
+
+func removeSCIMMember(members []dtos.SCIMReference, value string) []dtos.SCIMReference {
+	result := members[:0]
+	for _, member := range members {
+		if member.Value != value {
+			result = append(result, member)
+		}
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	t.Run("Parses the eq filters", func(t *testing.T) {
+		attr, value, err := parseSCIMFilter(`userName eq "jane@example.com"`)
+		require.NoError(t, err)
+		require.Equal(t, "userName", attr)
+		require.Equal(t, "jane@example.com", value)
+
+		attr, value, err = parseSCIMFilter(`emails.value EQ "say \"hi\""`)
+		require.NoError(t, err)
+		require.Equal(t, "emails.value", attr)
+		require.Equal(t, `say "hi"`, value)
+	})
+
+	t.Run("Allows no filter", func(t *testing.T) {
+		attr, _, err := parseSCIMFilter(" ")
+		require.NoError(t, err)
+		require.Empty(t, attr)
+	})
+
+	t.Run("Rejects the other operators", func(t *testing.T) {
+		_, _, err := parseSCIMFilter(`userName sw "jane"`)
+		require.Equal(t, "invalidFilter", err.(scimBadRequestError).scimType)
+
+		_, _, err = parseSCIMFilter(`userName eq "jane" and active eq true`)
+		require.Error(t, err)
+	})
+}
+
+func TestSCIMPageBounds(t *testing.T) {
+	start, end := scimPageBounds(5, 1, 2)
+	require.Equal(t, []int{0, 2}, []int{start, end})
+
+	start, end = scimPageBounds(5, 4, 10)
+	require.Equal(t, []int{3, 5}, []int{start, end})
+
+	start, end = scimPageBounds(5, 10, 2)
+	require.Equal(t, []int{5, 5}, []int{start, end})
+}
+
+func TestSCIMUserFromUser(t *testing.T) {
+	user := scimUserFromUser(&models.User{Id: 3, Login: "jane", Email: "jane@example.com", Name: "Jane van Doe", IsDisabled: true},
+		&models.UserAuth{AuthModule: models.AuthModuleSCIM, AuthId: "00u1"})
+
+	require.Equal(t, "3", user.Id)
+	require.Equal(t, "00u1", user.ExternalId)
+	require.Equal(t, dtos.SCIMName{Formatted: "Jane van Doe", GivenName: "Jane", FamilyName: "van Doe"}, *user.Name)
+	require.Equal(t, []dtos.SCIMEmail{{Value: "jane@example.com", Type: "work", Primary: true}}, user.Emails)
+	require.False(t, *user.Active)
+}
+
+func TestSCIMUserNameAndEmail(t *testing.T) {
+	require.Equal(t, "Jane", scimUserName(&dtos.SCIMUser{DisplayName: "Jane", Name: &dtos.SCIMName{Formatted: "Jane Doe"}}))
+	require.Equal(t, "Jane Doe", scimUserName(&dtos.SCIMUser{Name: &dtos.SCIMName{GivenName: "Jane", FamilyName: "Doe"}}))
+	require.Empty(t, scimUserName(&dtos.SCIMUser{}))
+
+	require.Equal(t, "work@example.com", scimUserEmail(&dtos.SCIMUser{Emails: []dtos.SCIMEmail{
+		{Value: "home@example.com"}, {Value: "work@example.com", Primary: true},
+	}}))
+	require.Equal(t, "home@example.com", scimUserEmail(&dtos.SCIMUser{Emails: []dtos.SCIMEmail{{Value: "home@example.com"}}}))
+}
+
+func TestApplySCIMUserPatch(t *testing.T) {
+	newUser := func() *dtos.SCIMUser {
+		return scimUserFromUser(&models.User{Id: 3, Login: "jane", Email: "jane@example.com", Name: "Jane Doe"}, nil)
+	}
+	patch := func(t *testing.T, user *dtos.SCIMUser, operations string) error {
+		var request dtos.SCIMPatchRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"Operations": `+operations+`}`), &request))
+		return applySCIMUserPatch(user, request.Operations)
+	}
+
+	t.Run("Deactivates the user with a path or a value object", func(t *testing.T) {
+		user := newUser()
+		require.NoError(t, patch(t, user, `[{"op": "replace", "path": "active", "value": false}]`))
+		require.False(t, *user.Active)
+
+		user = newUser()
+		require.NoError(t, patch(t, user, `[{"op": "Replace", "value": {"active": "False"}}]`))
+		require.False(t, *user.Active)
+	})
+
+	t.Run("Updates the attributes of the user", func(t *testing.T) {
+		user := newUser()
+		require.NoError(t, patch(t, user, `[
+			{"op": "replace", "path": "userName", "value": "jane.doe"},
+			{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "jane.doe@example.com"},
+			{"op": "add", "path": "externalId", "value": "00u1"},
+			{"op": "replace", "path": "name.familyName", "value": "Smith"},
+			{"op": "add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "R&D"}
+		]`))
+
+		require.Equal(t, "jane.doe", user.UserName)
+		require.Equal(t, "jane.doe@example.com", scimUserEmail(user))
+		require.Equal(t, "00u1", user.ExternalId)
+		require.Equal(t, "Jane Smith", scimUserName(user))
+	})
+
+	t.Run("Rejects the invalid operations", func(t *testing.T) {
+		err := patch(t, newUser(), `[{"op": "move", "path": "active", "value": true}]`)
+		require.Equal(t, "invalidSyntax", err.(scimBadRequestError).scimType)
+
+		err = patch(t, newUser(), `[{"op": "remove", "path": "userName"}]`)
+		require.Equal(t, "mutability", err.(scimBadRequestError).scimType)
+
+		err = patch(t, newUser(), `[{"op": "replace", "path": "active", "value": "maybe"}]`)
+		require.Equal(t, "invalidValue", err.(scimBadRequestError).scimType)
+	})
+}
+
+func TestApplySCIMGroupPatch(t *testing.T) {
+	newGroup := func() *dtos.SCIMGroup {
+		return &dtos.SCIMGroup{DisplayName: "Ops", Members: []dtos.SCIMReference{{Value: "1"}, {Value: "2"}}}
+	}
+	patch := func(t *testing.T, group *dtos.SCIMGroup, operations string) error {
+		var request dtos.SCIMPatchRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"Operations": `+operations+`}`), &request))
+		return applySCIMGroupPatch(group, request.Operations)
+	}
+	memberValues := func(group *dtos.SCIMGroup) []string {
+		values := []string{}
+		for _, member := range group.Members {
+			values = append(values, member.Value)
+		}
+		return values
+	}
+
+	t.Run("Adds and removes members", func(t *testing.T) {
+		group := newGroup()
+		require.NoError(t, patch(t, group, `[
+			{"op": "add", "path": "members", "value": [{"value": "3"}]},
+			{"op": "remove", "path": "members[value eq \"1\"]"},
+			{"op": "Remove", "path": "members", "value": [{"value": "2"}]}
+		]`))
+		require.Equal(t, []string{"3"}, memberValues(group))
+	})
+
+	t.Run("Replaces the members and the name", func(t *testing.T) {
+		group := newGroup()
+		require.NoError(t, patch(t, group, `[
+			{"op": "replace", "path": "members", "value": [{"value": "4"}]},
+			{"op": "replace", "value": {"id": "7", "displayName": "SRE"}}
+		]`))
+		require.Equal(t, []string{"4"}, memberValues(group))
+		require.Equal(t, "SRE", group.DisplayName)
+
+		group = newGroup()
+		require.NoError(t, patch(t, group, `[{"op": "remove", "path": "members"}]`))
+		require.Empty(t, group.Members)
+	})
+
+	t.Run("Rejects adding a member by filter", func(t *testing.T) {
+		err := patch(t, newGroup(), `[{"op": "add", "path": "members[value eq \"3\"]", "value": {}}]`)
+		require.Equal(t, "invalidPath", err.(scimBadRequestError).scimType)
+	})
+}
@@ -0,0 +1,568 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	errSCIMUserNotFound      = errors.New("user not found")
+	errSCIMUserTaken         = errors.New("user with the same login or email already exists")
+	errSCIMUserNotManageable = errors.New("user is a Grafana admin or a member of other orgs")
+)
+
+// scimEmailValuePath matches the paths of the value of the email of a user, such as emails[type eq "work"].value.
+var scimEmailValuePath = regexp.MustCompile(`^emails(\[[^\]]*\])?\.value$`)
+
+// scimUserFilters are the filters of the users by attribute, in lower case. The externalId filter is looked up as
+// an id filter.
+var scimUserFilters = map[string]func(user *models.OrgUserDTO, value string) bool{
+	"id": func(user *models.OrgUserDTO, value string) bool {
+		return strconv.FormatInt(user.UserId, 10) == value
+	},
+	"username": func(user *models.OrgUserDTO, value string) bool {
+		return strings.EqualFold(user.Login, value)
+	},
+	"emails": func(user *models.OrgUserDTO, value string) bool {
+		return strings.EqualFold(user.Email, value)
+	},
+	"emails.value": func(user *models.OrgUserDTO, value string) bool {
+		return strings.EqualFold(user.Email, value)
+	},
+}
+
+// scimUserRecord is a user of the org, with the SCIM auth info holding its external id.
+type scimUserRecord struct {
+	user     *models.User
+	authInfo *models.UserAuth
+	// manageable is whether the attributes of the user can be changed, which isn't the case of the Grafana admins
+	// and of the users of other orgs, since the org admins can't change them.
+	manageable bool
+}
+
+// GET /api/scim/v2/Users
+func (hs *HTTPServer) GetSCIMUsers(c *models.ReqContext) response.Response {
+	attr, value, err := parseSCIMFilter(c.Query("filter"))
+	if err != nil {
+		return scimErrorResponse(err, "")
+	}
+	attr = strings.ToLower(attr)
+	startIndex, count := scimPage(c)
+
+	if attr == "externalid" {
+		query := models.GetAuthInfoQuery{AuthModule: models.AuthModuleSCIM, AuthId: value}
+		if err := bus.Dispatch(&query); err != nil {
+			if errors.Is(err, models.ErrUserNotFound) {
+				return scimListResponse([]interface{}{}, 0, startIndex)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to list users", err)
+		}
+		attr, value = "id", strconv.FormatInt(query.Result.UserId, 10)
+	}
+	filter, ok := scimUserFilters[attr]
+	if attr != "" && !ok {
+		return scimError(http.StatusBadRequest, "invalidFilter", "Unsupported filter attribute "+attr)
+	}
+
+	query := models.GetOrgUsersQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list users", err)
+	}
+	var userIDs []int64
+	for _, orgUser := range query.Result {
+		if filter == nil || filter(orgUser, value) {
+			userIDs = append(userIDs, orgUser.UserId)
+		}
+	}
+
+	start, end := scimPageBounds(len(userIDs), startIndex, count)
+	resources := make([]interface{}, 0, end-start)
+	for _, userID := range userIDs[start:end] {
+		record, err := hs.getSCIMUser(c, userID)
+		if err != nil {
+			return scimUserErrorResponse(err, "Failed to list users")
+		}
+		user, err := hs.toSCIMUser(c, record)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to list users", err)
+		}
+		resources = append(resources, user)
+	}
+	return scimListResponse(resources, len(userIDs), startIndex)
+}
+
+// GET /api/scim/v2/Users/:id
+func (hs *HTTPServer) GetSCIMUser(c *models.ReqContext) response.Response {
+	record, err := hs.getSCIMUser(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimUserErrorResponse(err, "Failed to get user")
+	}
+	return hs.scimUserResponse(c, http.StatusOK, record.user.Id)
+}
+
+// CreateSCIMUser creates a user, and adds it to the org with the role of the SCIM settings.
+// POST /api/scim/v2/Users
+func (hs *HTTPServer) CreateSCIMUser(c *models.ReqContext) response.Response {
+	var scimUser dtos.SCIMUser
+	if err := decodeSCIMBody(c, &scimUser); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	if scimUser.UserName == "" {
+		return scimError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+
+	user, err := hs.Login.CreateUser(models.CreateUserCommand{
+		Login:        scimUser.UserName,
+		Email:        scimUserEmail(&scimUser),
+		Name:         scimUserName(&scimUser),
+		IsDisabled:   scimUser.Active != nil && !*scimUser.Active,
+		SkipOrgSetup: true,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrUserAlreadyExists) {
+			return scimError(http.StatusConflict, "uniqueness", "A user with the same userName or email already exists")
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to create user", err)
+	}
+	auditUserChange(c, models.AuditActionCreate, user.Id, nil, getUserAuditState(c, user.Id))
+
+	addCmd := models.AddOrgUserCommand{OrgId: c.OrgId, UserId: user.Id, Role: models.RoleType(hs.Cfg.SCIM.OrgRole)}
+	if err := bus.Dispatch(&addCmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to add user to organization", err)
+	}
+	auditOrgUserChange(c, models.AuditActionCreate, c.OrgId, user.Id, nil, getOrgUserAuditState(c, c.OrgId, user.Id))
+
+	if scimUser.ExternalId != "" {
+		authCmd := models.SetAuthInfoCommand{AuthModule: models.AuthModuleSCIM, AuthId: scimUser.ExternalId, UserId: user.Id}
+		if err := bus.Dispatch(&authCmd); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to set external id of user", err)
+		}
+	}
+
+	return hs.scimUserResponse(c, http.StatusCreated, user.Id)
+}
+
+// ReplaceSCIMUser updates the user with the attributes of the request, and deactivates or reactivates it.
+// PUT /api/scim/v2/Users/:id
+func (hs *HTTPServer) ReplaceSCIMUser(c *models.ReqContext) response.Response {
+	record, err := hs.getSCIMUser(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimUserErrorResponse(err, "Failed to get user")
+	}
+	var scimUser dtos.SCIMUser
+	if err := decodeSCIMBody(c, &scimUser); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	if err := hs.updateSCIMUser(c, record, &scimUser); err != nil {
+		return scimUserErrorResponse(err, "Failed to update user")
+	}
+	return hs.scimUserResponse(c, http.StatusOK, record.user.Id)
+}
+
+// PATCH /api/scim/v2/Users/:id
+func (hs *HTTPServer) PatchSCIMUser(c *models.ReqContext) response.Response {
+	record, err := hs.getSCIMUser(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimUserErrorResponse(err, "Failed to get user")
+	}
+	var patch dtos.SCIMPatchRequest
+	if err := decodeSCIMBody(c, &patch); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	scimUser := scimUserFromUser(record.user, record.authInfo)
+	if err := applySCIMUserPatch(scimUser, patch.Operations); err != nil {
+		return scimErrorResponse(err, "")
+	}
+	if err := hs.updateSCIMUser(c, record, scimUser); err != nil {
+		return scimUserErrorResponse(err, "Failed to update user")
+	}
+	return hs.scimUserResponse(c, http.StatusOK, record.user.Id)
+}
+
+// DeleteSCIMUser removes the user from the org, and deletes it when it isn't a member of other orgs.
+// DELETE /api/scim/v2/Users/:id
+func (hs *HTTPServer) DeleteSCIMUser(c *models.ReqContext) response.Response {
+	record, err := hs.getSCIMUser(c, c.ParamsInt64(":id"))
+	if err != nil {
+		return scimUserErrorResponse(err, "Failed to get user")
+	}
+
+	userBefore := getUserAuditState(c, record.user.Id)
+	orgUserBefore := getOrgUserAuditState(c, c.OrgId, record.user.Id)
+	cmd := models.RemoveOrgUserCommand{UserId: record.user.Id, OrgId: c.OrgId, ShouldDeleteOrphanedUser: true}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrLastOrgAdmin) {
+			return scimError(http.StatusBadRequest, "", "Cannot remove last organization admin")
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to remove user from organization", err)
+	}
+	auditOrgUserChange(c, models.AuditActionDelete, c.OrgId, record.user.Id, orgUserBefore, nil)
+	if cmd.UserWasDeleted {
+		auditUserChange(c, models.AuditActionDelete, record.user.Id, userBefore, nil)
+	}
+
+	return response.Respond(http.StatusNoContent, []byte{})
+}
+
+// getSCIMUser returns the user of the org with the id, except the service accounts.
+func (hs *HTTPServer) getSCIMUser(c *models.ReqContext, userID int64) (*scimUserRecord, error) {
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, errSCIMUserNotFound
+		}
+		return nil, err
+	}
+	if query.Result.IsServiceAccount {
+		return nil, errSCIMUserNotFound
+	}
+
+	orgsQuery := models.GetUserOrgListQuery{UserId: userID}
+	if err := bus.Dispatch(&orgsQuery); err != nil {
+		return nil, err
+	}
+	member := false
+	for _, org := range orgsQuery.Result {
+		member = member || org.OrgId == c.OrgId
+	}
+	if !member {
+		return nil, errSCIMUserNotFound
+	}
+
+	record := &scimUserRecord{user: query.Result, manageable: !query.Result.IsAdmin && len(orgsQuery.Result) == 1}
+	authQuery := models.GetAuthInfoQuery{UserId: userID, AuthModule: models.AuthModuleSCIM}
+	if err := bus.Dispatch(&authQuery); err == nil {
+		record.authInfo = authQuery.Result
+	} else if !errors.Is(err, models.ErrUserNotFound) {
+		return nil, err
+	}
+	return record, nil
+}
+
+// scimUserResponse returns the user, after reading it again to get the changes made by the request.
+func (hs *HTTPServer) scimUserResponse(c *models.ReqContext, status int, userID int64) response.Response {
+	record, err := hs.getSCIMUser(c, userID)
+	if err != nil {
+		return scimUserErrorResponse(err, "Failed to get user")
+	}
+	user, err := hs.toSCIMUser(c, record)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	rsp := scimJSON(status, user)
+	if status == http.StatusCreated {
+		rsp.SetHeader("Location", user.Meta.Location)
+	}
+	return rsp
+}
+
+// toSCIMUser returns the SCIM user of a user, with the teams of the org it's a member of as its groups.
+func (hs *HTTPServer) toSCIMUser(c *models.ReqContext, record *scimUserRecord) (*dtos.SCIMUser, error) {
+	query := models.GetTeamsByUserQuery{OrgId: c.OrgId, UserId: record.user.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	user := scimUserFromUser(record.user, record.authInfo)
+	for _, team := range query.Result {
+		user.Groups = append(user.Groups, dtos.SCIMReference{
+			Value:   strconv.FormatInt(team.Id, 10),
+			Display: team.Name,
+			Ref:     hs.scimLocation("Groups", team.Id),
+		})
+	}
+	user.Meta.Location = hs.scimLocation("Users", record.user.Id)
+	return user, nil
+}
+
+// updateSCIMUser updates the login, email, name and external id of the user, and deactivates or reactivates it.
+// The deactivated users are logged out.
+func (hs *HTTPServer) updateSCIMUser(c *models.ReqContext, record *scimUserRecord, scimUser *dtos.SCIMUser) error {
+	user := record.user
+	if scimUser.UserName == "" {
+		return scimBadRequest("invalidValue", "userName is required")
+	}
+	login, email, name := scimUser.UserName, scimUserEmail(scimUser), scimUserName(scimUser)
+	// The email and the name of the users can't be removed.
+	if email == "" {
+		email = user.Email
+	}
+	if name == "" {
+		name = user.Name
+	}
+	disabled := user.IsDisabled
+	if scimUser.Active != nil {
+		disabled = !*scimUser.Active
+	}
+	externalID := ""
+	if record.authInfo != nil {
+		externalID = record.authInfo.AuthId
+	}
+
+	changed := login != user.Login || email != user.Email || name != user.Name
+	if !changed && disabled == user.IsDisabled && scimUser.ExternalId == externalID {
+		return nil
+	}
+	if !record.manageable {
+		return errSCIMUserNotManageable
+	}
+
+	before := getUserAuditState(c, user.Id)
+	if changed {
+		if err := checkSCIMUserTaken(c, user.Id, login, email); err != nil {
+			return err
+		}
+		cmd := models.UpdateUserCommand{UserId: user.Id, Login: login, Email: email, Name: name}
+		if err := bus.Dispatch(&cmd); err != nil {
+			return err
+		}
+	}
+	if disabled != user.IsDisabled {
+		cmd := models.DisableUserCommand{UserId: user.Id, IsDisabled: disabled}
+		if err := bus.Dispatch(&cmd); err != nil {
+			return err
+		}
+		if disabled {
+			if err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), user.Id); err != nil {
+				return err
+			}
+		}
+	}
+	if changed || disabled != user.IsDisabled {
+		auditUserChange(c, models.AuditActionUpdate, user.Id, before, getUserAuditState(c, user.Id))
+	}
+
+	switch {
+	case scimUser.ExternalId == externalID:
+		return nil
+	case scimUser.ExternalId == "":
+		return bus.Dispatch(&models.DeleteAuthInfoCommand{UserAuth: record.authInfo})
+	case record.authInfo == nil:
+		return bus.Dispatch(&models.SetAuthInfoCommand{
+			AuthModule: models.AuthModuleSCIM, AuthId: scimUser.ExternalId, UserId: user.Id,
+		})
+	default:
+		return bus.Dispatch(&models.UpdateAuthInfoCommand{
+			AuthModule: models.AuthModuleSCIM, AuthId: scimUser.ExternalId, UserId: user.Id,
+		})
+	}
+}
+
+// checkSCIMUserTaken returns errSCIMUserTaken when another user has the login or the email.
+func checkSCIMUserTaken(c *models.ReqContext, userID int64, login, email string) error {
+	loginQuery := models.GetUserByLoginQuery{LoginOrEmail: login}
+	if err := bus.Dispatch(&loginQuery); err == nil && loginQuery.Result.Id != userID {
+		return errSCIMUserTaken
+	} else if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		return err
+	}
+	emailQuery := models.GetUserByEmailQuery{Email: email}
+	if err := bus.Dispatch(&emailQuery); err == nil && emailQuery.Result.Id != userID {
+		return errSCIMUserTaken
+	} else if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		return err
+	}
+	return nil
+}
+
+func scimUserErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, errSCIMUserNotFound):
+		return scimError(http.StatusNotFound, "", "User not found")
+	case errors.Is(err, errSCIMUserTaken):
+		return scimError(http.StatusConflict, "uniqueness", "A user with the same userName or email already exists")
+	case errors.Is(err, errSCIMUserNotManageable):
+		return scimError(http.StatusForbidden, "",
+			"The user is a Grafana server admin or a member of other organizations, and can't be changed")
+	default:
+		return scimErrorResponse(err, message)
+	}
+}
+
+// scimUserFromUser returns the SCIM user of a user, without its groups. The given and family names are the first
+// word of the name and the rest of it.
+func scimUserFromUser(user *models.User, authInfo *models.UserAuth) *dtos.SCIMUser {
+	active := !user.IsDisabled
+	created, updated := user.Created, user.Updated
+	scimUser := &dtos.SCIMUser{
+		Schemas:     []string{dtos.SCIMUserSchema},
+		Id:          strconv.FormatInt(user.Id, 10),
+		UserName:    user.Login,
+		Name:        &dtos.SCIMName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Active:      &active,
+		Meta:        &dtos.SCIMMeta{ResourceType: "User", Created: &created, LastModified: &updated},
+	}
+	if names := strings.SplitN(strings.TrimSpace(user.Name), " ", 2); names[0] != "" {
+		scimUser.Name.GivenName = names[0]
+		if len(names) == 2 {
+			scimUser.Name.FamilyName = strings.TrimSpace(names[1])
+		}
+	}
+	if user.Email != "" {
+		scimUser.Emails = []dtos.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	if authInfo != nil {
+		scimUser.ExternalId = authInfo.AuthId
+	}
+	return scimUser
+}
+
+// scimUserName returns the name of a SCIM user, which is its display name, or else its formatted name, or else its
+// given and family names.
+func scimUserName(user *dtos.SCIMUser) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	if user.Name == nil {
+		return ""
+	}
+	if user.Name.Formatted != "" {
+		return user.Name.Formatted
+	}
+	return strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName)
+}
+
+// scimUserEmail returns the primary email of a SCIM user, or else its first one.
+func scimUserEmail(user *dtos.SCIMUser) string {
+	for _, email := range user.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(user.Emails) > 0 {
+		return user.Emails[0].Value
+	}
+	return ""
+}
+
+// applySCIMUserPatch applies the operations of a patch request to a SCIM user. The operations without a path set
+// the attributes of their value. The attributes which Grafana doesn't store, such as the ones of the enterprise
+// extension, are ignored like in the created and replaced users.
+func applySCIMUserPatch(user *dtos.SCIMUser, operations []dtos.SCIMPatchOperation) error {
+	if user.Name == nil {
+		user.Name = &dtos.SCIMName{}
+	}
+	for _, operation := range operations {
+		op, err := scimPatchOp(operation)
+		if err != nil {
+			return err
+		}
+		if operation.Path != "" {
+			if err := applySCIMUserPatchPath(user, op, operation.Path, operation.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if op == "remove" {
+			return scimBadRequest("noTarget", "The remove operation needs a path")
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &values); err != nil {
+			return scimBadRequest("invalidValue", "The value of an operation without a path must be an object")
+		}
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if err := applySCIMUserPatchPath(user, op, path, values[path]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func applySCIMUserPatchPath(user *dtos.SCIMUser, op, path string, value json.RawMessage) error {
+	remove := op == "remove"
+	attr := strings.TrimPrefix(strings.ToLower(path), strings.ToLower(dtos.SCIMUserSchema)+":")
+	stringValue := func() (string, error) {
+		if remove {
+			return "", nil
+		}
+		return scimString(path, value)
+	}
+
+	switch {
+	case attr == "active" || attr == "username" || attr == "emails" || scimEmailValuePath.MatchString(attr):
+		if remove {
+			return scimBadRequest("mutability", "%s can't be removed", path)
+		}
+	}
+
+	switch {
+	case attr == "active":
+		active, err := scimBool(path, value)
+		if err != nil {
+			return err
+		}
+		user.Active = &active
+	case attr == "username":
+		userName, err := scimString(path, value)
+		if err != nil {
+			return err
+		}
+		user.UserName = userName
+	case attr == "externalid":
+		externalID, err := stringValue()
+		if err != nil {
+			return err
+		}
+		user.ExternalId = externalID
+	case attr == "displayname" || attr == "name.formatted":
+		name, err := stringValue()
+		if err != nil {
+			return err
+		}
+		user.DisplayName, user.Name.Formatted = name, name
+	case attr == "name.givenname" || attr == "name.familyname":
+		name, err := stringValue()
+		if err != nil {
+			return err
+		}
+		if attr == "name.givenname" {
+			user.Name.GivenName = name
+		} else {
+			user.Name.FamilyName = name
+		}
+		user.DisplayName = strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName)
+		user.Name.Formatted = user.DisplayName
+	case attr == "name":
+		var name dtos.SCIMName
+		if !remove {
+			if err := json.Unmarshal(value, &name); err != nil {
+				return scimBadRequest("invalidValue", "Invalid value of %s", path)
+			}
+		}
+		if name.Formatted == "" {
+			name.Formatted = strings.TrimSpace(name.GivenName + " " + name.FamilyName)
+		}
+		user.Name, user.DisplayName = &name, name.Formatted
+	case attr == "emails":
+		var emails []dtos.SCIMEmail
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return scimBadRequest("invalidValue", "Invalid value of %s", path)
+		}
+		user.Emails = emails
+	case scimEmailValuePath.MatchString(attr):
+		email, err := scimString(path, value)
+		if err != nil {
+			return err
+		}
+		user.Emails = []dtos.SCIMEmail{{Value: email, Type: "work", Primary: true}}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/serviceaccounts
+func (hs *HTTPServer) GetServiceAccounts(c *models.ReqContext) response.Response {
+	serviceAccounts, err := hs.SQLStore.GetServiceAccounts(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(500, "Failed to list service accounts", err)
+	}
+
+	return response.JSON(200, serviceAccounts)
+}
+
+// POST /api/serviceaccounts
+func (hs *HTTPServer) CreateServiceAccount(c *models.ReqContext, cmd models.CreateServiceAccountCommand) response.Response {
+	if !cmd.Role.IsValid() {
+		return response.Error(400, "Invalid role specified", nil)
+	}
+	cmd.OrgId = c.OrgId
+
+	serviceAccount, err := hs.SQLStore.CreateServiceAccount(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, models.ErrServiceAccountAlreadyExists) {
+			return response.Error(409, err.Error(), nil)
+		}
+		return response.Error(500, "Failed to create service account", err)
+	}
+
+	return response.JSON(200, toServiceAccountDTO(serviceAccount))
+}
+
+// GET /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) GetServiceAccount(c *models.ReqContext) response.Response {
+	serviceAccount, rsp := hs.getServiceAccount(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(200, toServiceAccountDTO(serviceAccount))
+}
+
+// PATCH /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) UpdateServiceAccount(c *models.ReqContext, cmd models.UpdateServiceAccountCommand) response.Response {
+	if cmd.Role != nil && !cmd.Role.IsValid() {
+		return response.Error(400, "Invalid role specified", nil)
+	}
+	if cmd.Name != nil && *cmd.Name == "" {
+		return response.Error(400, "Name can't be empty", nil)
+	}
+	cmd.Id = c.ParamsInt64(":serviceAccountId")
+	cmd.OrgId = c.OrgId
+
+	serviceAccount, err := hs.SQLStore.UpdateServiceAccount(c.Req.Context(), &cmd)
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to update service account")
+	}
+
+	return response.JSON(200, toServiceAccountDTO(serviceAccount))
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) DeleteServiceAccount(c *models.ReqContext) response.Response {
+	if err := hs.SQLStore.DeleteServiceAccount(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId")); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to delete service account")
+	}
+
+	return response.Success("Service account deleted")
+}
+
+// GET /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) GetServiceAccountTokens(c *models.ReqContext) response.Response {
+	serviceAccount, rsp := hs.getServiceAccount(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	tokens, err := hs.SQLStore.GetServiceAccountTokens(c.Req.Context(), c.OrgId, serviceAccount.Id)
+	if err != nil {
+		return response.Error(500, "Failed to list service account tokens", err)
+	}
+
+	now := time.Now()
+	result := make([]*models.ServiceAccountTokenDTO, len(tokens))
+	for i, t := range tokens {
+		var expiration *time.Time = nil
+		if t.Expires != nil {
+			v := time.Unix(*t.Expires, 0)
+			expiration = &v
+		}
+		result[i] = &models.ServiceAccountTokenDTO{
+			Id:         t.Id,
+			Name:       t.Name,
+			Created:    t.Created,
+			Expiration: expiration,
+			HasExpired: expiration != nil && !expiration.After(now),
+			LastUsedAt: t.LastUsedAt,
+		}
+	}
+
+	return response.JSON(200, result)
+}
+
+// POST /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) AddServiceAccountToken(c *models.ReqContext, cmd models.AddServiceAccountTokenCommand) response.Response {
+	serviceAccount, rsp := hs.getServiceAccount(c)
+	if rsp != nil {
+		return rsp
+	}
+	if rsp := hs.validateAPIKeySecondsToLive(cmd.SecondsToLive); rsp != nil {
+		return rsp
+	}
+
+	newKeyInfo, err := apikeygen.New(c.OrgId, cmd.Name)
+	if err != nil {
+		return response.Error(500, "Generating service account token failed", err)
+	}
+
+	addCmd := models.AddApiKeyCommand{
+		Name:             cmd.Name,
+		Role:             serviceAccount.Role,
+		OrgId:            c.OrgId,
+		Key:              newKeyInfo.HashedKey,
+		SecondsToLive:    cmd.SecondsToLive,
+		ServiceAccountId: &serviceAccount.Id,
+	}
+	if err := bus.Dispatch(&addCmd); err != nil {
+		if errors.Is(err, models.ErrInvalidApiKeyExpiration) {
+			return response.Error(400, err.Error(), nil)
+		}
+		if errors.Is(err, models.ErrDuplicateApiKey) {
+			return response.Error(409, err.Error(), nil)
+		}
+		return response.Error(500, "Failed to add service account token", err)
+	}
+
+	return response.JSON(200, &dtos.NewApiKeyResult{
+		ID:   addCmd.Result.Id,
+		Name: addCmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// POST /api/serviceaccounts/:serviceAccountId/tokens/:tokenId/rotate
+func (hs *HTTPServer) RotateServiceAccountToken(c *models.ReqContext, cmd models.RotateServiceAccountTokenCommand) response.Response {
+	token, rsp := hs.getServiceAccountToken(c)
+	if rsp != nil {
+		return rsp
+	}
+	if rsp := hs.validateAPIKeySecondsToLive(cmd.SecondsToLive); rsp != nil {
+		return rsp
+	}
+
+	newKeyInfo, err := apikeygen.New(c.OrgId, token.Name)
+	if err != nil {
+		return response.Error(500, "Generating service account token failed", err)
+	}
+
+	rotateCmd := models.RotateApiKeyCommand{
+		Id:            token.Id,
+		OrgId:         c.OrgId,
+		Key:           newKeyInfo.HashedKey,
+		SecondsToLive: cmd.SecondsToLive,
+	}
+	if err := bus.DispatchCtx(c.Req.Context(), &rotateCmd); err != nil {
+		if errors.Is(err, models.ErrInvalidApiKeyExpiration) {
+			return response.Error(400, err.Error(), nil)
+		}
+		return response.Error(500, "Failed to rotate service account token", err)
+	}
+
+	return response.JSON(200, &dtos.NewApiKeyResult{
+		ID:   rotateCmd.Result.Id,
+		Name: rotateCmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId/tokens/:tokenId
+func (hs *HTTPServer) DeleteServiceAccountToken(c *models.ReqContext) response.Response {
+	token, rsp := hs.getServiceAccountToken(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &models.DeleteApiKeyCommand{Id: token.Id, OrgId: c.OrgId}); err != nil {
+		return response.Error(500, "Failed to delete service account token", err)
+	}
+
+	return response.Success("Service account token deleted")
+}
+
+func (hs *HTTPServer) getServiceAccount(c *models.ReqContext) (*models.ServiceAccount, response.Response) {
+	serviceAccount, err := hs.SQLStore.GetServiceAccount(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId"))
+	if err != nil {
+		return nil, serviceAccountErrorResponse(err, "Failed to get service account")
+	}
+	return serviceAccount, nil
+}
+
+// getServiceAccountToken returns the token of the request, which must be a token of the service account of the
+// request.
+func (hs *HTTPServer) getServiceAccountToken(c *models.ReqContext) (*models.ApiKey, response.Response) {
+	serviceAccount, rsp := hs.getServiceAccount(c)
+	if rsp != nil {
+		return nil, rsp
+	}
+
+	query := models.GetApiKeyByIdQuery{ApiKeyId: c.ParamsInt64(":tokenId")}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrInvalidApiKey) {
+			return nil, response.Error(404, "Service account token not found", nil)
+		}
+		return nil, response.Error(500, "Failed to get service account token", err)
+	}
+	token := query.Result
+	if token.OrgId != c.OrgId || token.ServiceAccountId == nil || *token.ServiceAccountId != serviceAccount.Id {
+		return nil, response.Error(404, "Service account token not found", nil)
+	}
+	return token, nil
+}
+
+func serviceAccountErrorResponse(err error, message string) response.Response {
+	if errors.Is(err, models.ErrServiceAccountNotFound) {
+		return response.Error(404, err.Error(), nil)
+	}
+	if errors.Is(err, models.ErrServiceAccountAlreadyExists) {
+		return response.Error(409, err.Error(), nil)
+	}
+	return response.Error(500, message, err)
+}
+
+func toServiceAccountDTO(serviceAccount *models.ServiceAccount) *models.ServiceAccountDTO {
+	return &models.ServiceAccountDTO{
+		Id:         serviceAccount.Id,
+		OrgId:      serviceAccount.OrgId,
+		Name:       serviceAccount.Name,
+		Login:      serviceAccount.Login,
+		Role:       serviceAccount.Role,
+		IsDisabled: serviceAccount.IsDisabled,
+		Created:    serviceAccount.Created,
+		Updated:    serviceAccount.Updated,
+	}
+}
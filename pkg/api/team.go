@@ -100,6 +100,11 @@ func (hs *HTTPServer) SearchTeams(c *models.ReqContext) response.Response {
 		userIdFilter = c.SignedInUser.UserId
 	}
 
+	sort, err := models.ParseSearchSort(c.Query("sort"))
+	if err != nil {
+		return searchErrorResponse(err, "Failed to search Teams")
+	}
+
 	query := models.SearchTeamsQuery{
 		OrgId:        c.OrgId,
 		Query:        c.Query("query"),
@@ -109,10 +114,12 @@ func (hs *HTTPServer) SearchTeams(c *models.ReqContext) response.Response {
 		Limit:        perPage,
 		SignedInUser: c.SignedInUser,
 		HiddenUsers:  hs.Cfg.HiddenUsers,
+		Sort:         sort,
+		Cursor:       c.Query("cursor"),
 	}
 
 	if err := bus.Dispatch(&query); err != nil {
-		return response.Error(500, "Failed to search Teams", err)
+		return searchErrorResponse(err, "Failed to search Teams")
 	}
 
 	for _, team := range query.Result.Teams {
@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -79,13 +80,13 @@ func UpdateSignedInUser(c *models.ReqContext, cmd models.UpdateUserCommand) resp
 		}
 	}
 	cmd.UserId = c.UserId
-	return handleUpdateUser(cmd)
+	return handleUpdateUser(c, cmd)
 }
 
 // POST /api/users/:id
 func UpdateUser(c *models.ReqContext, cmd models.UpdateUserCommand) response.Response {
 	cmd.UserId = c.ParamsInt64(":id")
-	return handleUpdateUser(cmd)
+	return handleUpdateUser(c, cmd)
 }
 
 // POST /api/users/:id/using/:orgId
@@ -106,7 +107,7 @@ func UpdateUserActiveOrg(c *models.ReqContext) response.Response {
 	return response.Success("Active organization changed")
 }
 
-func handleUpdateUser(cmd models.UpdateUserCommand) response.Response {
+func handleUpdateUser(c *models.ReqContext, cmd models.UpdateUserCommand) response.Response {
 	if len(cmd.Login) == 0 {
 		cmd.Login = cmd.Email
 		if len(cmd.Login) == 0 {
@@ -114,9 +115,11 @@ func handleUpdateUser(cmd models.UpdateUserCommand) response.Response {
 		}
 	}
 
+	before := getUserAuditState(c, cmd.UserId)
 	if err := bus.Dispatch(&cmd); err != nil {
 		return response.Error(500, "Failed to update user", err)
 	}
+	auditUserChange(c, models.AuditActionUpdate, cmd.UserId, before, getUserAuditState(c, cmd.UserId))
 
 	return response.Success("User updated")
 }
@@ -260,9 +263,9 @@ func redirectToChangePassword(c *models.ReqContext) {
 
 // GET /api/users
 func SearchUsers(c *models.ReqContext) response.Response {
-	query, err := searchUser(c)
-	if err != nil {
-		return response.Error(500, "Failed to fetch users", err)
+	query, rsp := searchUser(c)
+	if rsp != nil {
+		return rsp
 	}
 
 	return response.JSON(200, query.Result.Users)
@@ -270,15 +273,15 @@ func SearchUsers(c *models.ReqContext) response.Response {
 
 // GET /api/users/search
 func SearchUsersWithPaging(c *models.ReqContext) response.Response {
-	query, err := searchUser(c)
-	if err != nil {
-		return response.Error(500, "Failed to fetch users", err)
+	query, rsp := searchUser(c)
+	if rsp != nil {
+		return rsp
 	}
 
 	return response.JSON(200, query.Result)
 }
 
-func searchUser(c *models.ReqContext) (*models.SearchUsersQuery, error) {
+func searchUser(c *models.ReqContext) (*models.SearchUsersQuery, response.Response) {
 	perPage := c.QueryInt("perpage")
 	if perPage <= 0 {
 		perPage = 1000
@@ -291,9 +294,40 @@ func searchUser(c *models.ReqContext) (*models.SearchUsersQuery, error) {
 
 	searchQuery := c.Query("query")
 
-	query := &models.SearchUsersQuery{Query: searchQuery, Page: page, Limit: perPage}
+	sort, err := models.ParseSearchSort(c.Query("sort"))
+	if err != nil {
+		return nil, searchErrorResponse(err, "Failed to fetch users")
+	}
+
+	query := &models.SearchUsersQuery{
+		Query:  searchQuery,
+		Page:   page,
+		Limit:  perPage,
+		Login:  c.Query("login"),
+		Email:  c.Query("email"),
+		Sort:   sort,
+		Cursor: c.Query("cursor"),
+	}
+	if role := c.Query("role"); role != "" {
+		query.Role = models.RoleType(role)
+		if !query.Role.IsValid() {
+			return nil, response.Error(400, "Invalid role specified", nil)
+		}
+		query.RoleOrgId = c.OrgId
+	}
+	for param, t := range map[string]*time.Time{"lastSeenAfter": &query.LastSeenAfter, "lastSeenBefore": &query.LastSeenBefore} {
+		if c.Query(param) == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, c.Query(param))
+		if err != nil {
+			return nil, response.Error(400, "Invalid "+param+" time, expected RFC 3339 format", err)
+		}
+		*t = parsed
+	}
+
 	if err := bus.Dispatch(query); err != nil {
-		return nil, err
+		return nil, searchErrorResponse(err, "Failed to fetch users")
 	}
 
 	for _, user := range query.Result.Users {
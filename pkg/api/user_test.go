@@ -207,4 +207,47 @@ func TestUserAPIEndpoint_userLoggedIn(t *testing.T) {
 		assert.Equal(t, 10, sentLimit)
 		assert.Equal(t, 2, sendPage)
 	})
+	loggedInUserScenario(t, "When calling GET with filters, sort and cursor on", "/api/users/search", func(sc *scenarioContext) {
+		var sentQuery *models.SearchUsersQuery
+		bus.AddHandler("test", func(query *models.SearchUsersQuery) error {
+			query.Result = mockResult
+			query.Result.NextCursor = "next"
+			sentQuery = query
+			return nil
+		})
+
+		sc.handlerFunc = SearchUsersWithPaging
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{
+			"login":          "admin",
+			"role":           "Editor",
+			"lastSeenBefore": "2021-06-14T10:28:16Z",
+			"sort":           "lastSeenAt-desc",
+			"cursor":         "abc",
+		}).exec()
+
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.Equal(t, "admin", sentQuery.Login)
+		assert.Equal(t, models.ROLE_EDITOR, sentQuery.Role)
+		assert.Equal(t, int64(testOrgID), sentQuery.RoleOrgId)
+		assert.Equal(t, time.Date(2021, 6, 14, 10, 28, 16, 0, time.UTC), sentQuery.LastSeenBefore.UTC())
+		assert.Equal(t, models.SearchSort{Field: "lastSeenAt", Descending: true}, sentQuery.Sort)
+		assert.Equal(t, "abc", sentQuery.Cursor)
+
+		respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "next", respJSON.Get("nextCursor").MustString())
+	})
+
+	loggedInUserScenario(t, "When calling GET with an invalid sort or cursor on", "/api/users/search", func(sc *scenarioContext) {
+		bus.AddHandler("test", func(query *models.SearchUsersQuery) error {
+			return models.ErrInvalidSearchCursor
+		})
+
+		sc.handlerFunc = SearchUsersWithPaging
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"sort": "login-up"}).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"cursor": "invalid"}).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+	})
 }
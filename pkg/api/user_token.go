@@ -58,53 +58,10 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 		return response.Error(500, "Failed to get user auth tokens", err)
 	}
 
+	parser := uaparser.NewFromSaved()
 	result := []*dtos.UserToken{}
 	for _, token := range tokens {
-		isActive := false
-		if c.UserToken != nil && c.UserToken.Id == token.Id {
-			isActive = true
-		}
-
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
-
-		osVersion := ""
-		if client.Os.Major != "" {
-			osVersion = client.Os.Major
-
-			if client.Os.Minor != "" {
-				osVersion = osVersion + "." + client.Os.Minor
-			}
-		}
-
-		browserVersion := ""
-		if client.UserAgent.Major != "" {
-			browserVersion = client.UserAgent.Major
-
-			if client.UserAgent.Minor != "" {
-				browserVersion = browserVersion + "." + client.UserAgent.Minor
-			}
-		}
-
-		createdAt := time.Unix(token.CreatedAt, 0)
-		seenAt := time.Unix(token.SeenAt, 0)
-
-		if token.SeenAt == 0 {
-			seenAt = createdAt
-		}
-
-		result = append(result, &dtos.UserToken{
-			Id:                     token.Id,
-			IsActive:               isActive,
-			ClientIp:               token.ClientIp,
-			Device:                 client.Device.ToString(),
-			OperatingSystem:        client.Os.Family,
-			OperatingSystemVersion: osVersion,
-			Browser:                client.UserAgent.Family,
-			BrowserVersion:         browserVersion,
-			CreatedAt:              createdAt,
-			SeenAt:                 seenAt,
-		})
+		result = append(result, hs.userTokenDTO(c, parser, token))
 	}
 
 	return response.JSON(200, result)
@@ -144,3 +101,58 @@ func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID i
 		"message": "User auth token revoked",
 	})
 }
+
+// userTokenDTO returns the token with the device information parsed from its user agent.
+func (hs *HTTPServer) userTokenDTO(c *models.ReqContext, parser *uaparser.Parser, token *models.UserToken) *dtos.UserToken {
+	isActive := false
+	if c.UserToken != nil && c.UserToken.Id == token.Id {
+		isActive = true
+	}
+
+	client := parser.Parse(token.UserAgent)
+
+	osVersion := ""
+	if client.Os.Major != "" {
+		osVersion = client.Os.Major
+
+		if client.Os.Minor != "" {
+			osVersion = osVersion + "." + client.Os.Minor
+		}
+	}
+
+	browserVersion := ""
+	if client.UserAgent.Major != "" {
+		browserVersion = client.UserAgent.Major
+
+		if client.UserAgent.Minor != "" {
+			browserVersion = browserVersion + "." + client.UserAgent.Minor
+		}
+	}
+
+	createdAt := time.Unix(token.CreatedAt, 0)
+	seenAt := time.Unix(token.SeenAt, 0)
+
+	if token.SeenAt == 0 {
+		seenAt = createdAt
+	}
+
+	// The token expires at the end of its lifetime, or when it isn't rotated for the inactive lifetime.
+	expiresAt := createdAt.Add(hs.Cfg.LoginMaxLifetime)
+	if inactiveExpiresAt := time.Unix(token.RotatedAt, 0).Add(hs.Cfg.LoginMaxInactiveLifetime); inactiveExpiresAt.Before(expiresAt) {
+		expiresAt = inactiveExpiresAt
+	}
+
+	return &dtos.UserToken{
+		Id:                     token.Id,
+		IsActive:               isActive,
+		ClientIp:               token.ClientIp,
+		Device:                 client.Device.ToString(),
+		OperatingSystem:        client.Os.Family,
+		OperatingSystemVersion: osVersion,
+		Browser:                client.UserAgent.Family,
+		BrowserVersion:         browserVersion,
+		CreatedAt:              createdAt,
+		SeenAt:                 seenAt,
+		ExpiresAt:              expiresAt,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -122,6 +123,7 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 					UserAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/72.0.3626.119 Safari/537.36",
 					CreatedAt: time.Now().Unix(),
 					SeenAt:    time.Now().Unix(),
+					RotatedAt: time.Now().Unix(),
 				},
 				{
 					Id:        2,
@@ -146,6 +148,7 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "127.0.0.1", resultOne.Get("clientIp").MustString())
 			assert.Equal(t, time.Unix(tokens[0].CreatedAt, 0).Format(time.RFC3339), resultOne.Get("createdAt").MustString())
 			assert.Equal(t, time.Unix(tokens[0].SeenAt, 0).Format(time.RFC3339), resultOne.Get("seenAt").MustString())
+			assert.Equal(t, time.Unix(tokens[0].RotatedAt, 0).Add(7*24*time.Hour).Format(time.RFC3339), resultOne.Get("expiresAt").MustString())
 
 			assert.Equal(t, "Other", resultOne.Get("device").MustString())
 			assert.Equal(t, "Chrome", resultOne.Get("browser").MustString())
@@ -287,8 +290,12 @@ func getUserAuthTokensInternalScenario(t *testing.T, desc string, token *models.
 
 		fakeAuthTokenService := auth.NewFakeUserAuthTokenService()
 
+		cfg := setting.NewCfg()
+		cfg.LoginMaxInactiveLifetime = 7 * 24 * time.Hour
+		cfg.LoginMaxLifetime = 30 * 24 * time.Hour
 		hs := HTTPServer{
 			Bus:              bus.GetBus(),
+			Cfg:              cfg,
 			AuthTokenService: fakeAuthTokenService,
 		}
 
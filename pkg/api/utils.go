@@ -1,9 +1,25 @@
 package api
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
 
 func jsonMap(data []byte) (map[string]string, error) {
 	jsonMap := make(map[string]string)
 	err := json.Unmarshal(data, &jsonMap)
 	return jsonMap, err
 }
+
+// searchErrorResponse returns the response to an error of a search of users, orgs or teams, which is a bad request
+// for an invalid sort or cursor.
+func searchErrorResponse(err error, message string) response.Response {
+	if errors.Is(err, models.ErrInvalidSearchSort) || errors.Is(err, models.ErrInvalidSearchCursor) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
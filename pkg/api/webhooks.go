@@ -0,0 +1,174 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/webhooks
+func (hs *HTTPServer) GetWebhooks(c *models.ReqContext) response.Response {
+	webhooks, err := hs.SQLStore.GetWebhooks(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list webhooks", err)
+	}
+
+	result := make([]*models.WebhookDTO, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, webhook.ToDTO())
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// CreateWebhook creates a webhook, and returns it along with its secret, which is generated when it isn't set.
+// POST /api/webhooks
+func (hs *HTTPServer) CreateWebhook(c *models.ReqContext, cmd models.CreateWebhookCommand) response.Response {
+	if rsp := validateWebhookURL(cmd.Url); rsp != nil {
+		return rsp
+	}
+	if rsp := validateWebhookEvents(cmd.Events); rsp != nil {
+		return rsp
+	}
+	if cmd.Secret == "" {
+		secret, err := util.GetRandomString(32)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to generate webhook secret", err)
+		}
+		cmd.Secret = secret
+	}
+	cmd.OrgId = c.OrgId
+
+	webhook, err := hs.SQLStore.CreateWebhook(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to create webhook", err)
+	}
+
+	return response.JSON(http.StatusOK, &models.CreatedWebhookDTO{WebhookDTO: *webhook.ToDTO(), Secret: cmd.Secret})
+}
+
+// GET /api/webhooks/:webhookId
+func (hs *HTTPServer) GetWebhook(c *models.ReqContext) response.Response {
+	webhook, err := hs.SQLStore.GetWebhook(c.Req.Context(), c.OrgId, c.ParamsInt64(":webhookId"))
+	if err != nil {
+		return webhookErrorResponse(err, "Failed to get webhook")
+	}
+
+	return response.JSON(http.StatusOK, webhook.ToDTO())
+}
+
+// PATCH /api/webhooks/:webhookId
+func (hs *HTTPServer) UpdateWebhook(c *models.ReqContext, cmd models.UpdateWebhookCommand) response.Response {
+	if cmd.Name != nil && *cmd.Name == "" {
+		return response.Error(http.StatusBadRequest, "Name can't be empty", nil)
+	}
+	if cmd.Url != nil {
+		if rsp := validateWebhookURL(*cmd.Url); rsp != nil {
+			return rsp
+		}
+	}
+	if cmd.Events != nil {
+		if rsp := validateWebhookEvents(cmd.Events); rsp != nil {
+			return rsp
+		}
+	}
+	cmd.Id = c.ParamsInt64(":webhookId")
+	cmd.OrgId = c.OrgId
+
+	webhook, err := hs.SQLStore.UpdateWebhook(c.Req.Context(), &cmd)
+	if err != nil {
+		return webhookErrorResponse(err, "Failed to update webhook")
+	}
+
+	return response.JSON(http.StatusOK, webhook.ToDTO())
+}
+
+// DELETE /api/webhooks/:webhookId
+func (hs *HTTPServer) DeleteWebhook(c *models.ReqContext) response.Response {
+	if err := hs.SQLStore.DeleteWebhook(c.Req.Context(), c.OrgId, c.ParamsInt64(":webhookId")); err != nil {
+		return webhookErrorResponse(err, "Failed to delete webhook")
+	}
+
+	return response.Success("Webhook deleted")
+}
+
+// GetWebhookDeliveries returns a page of the delivery log of the webhook, the most recent deliveries first.
+// GET /api/webhooks/:webhookId/deliveries
+func (hs *HTTPServer) GetWebhookDeliveries(c *models.ReqContext) response.Response {
+	webhook, err := hs.SQLStore.GetWebhook(c.Req.Context(), c.OrgId, c.ParamsInt64(":webhookId"))
+	if err != nil {
+		return webhookErrorResponse(err, "Failed to get webhook")
+	}
+
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+	query := models.SearchWebhookDeliveriesQuery{
+		OrgId:     c.OrgId,
+		WebhookId: webhook.Id,
+		State:     models.WebhookDeliveryState(c.Query("state")),
+		EventType: models.WebhookEventType(c.Query("eventType")),
+		Page:      page,
+		PerPage:   perPage,
+	}
+	if query.State != "" && !query.State.IsValid() {
+		return response.Error(http.StatusBadRequest, "Invalid state, expected pending, succeeded or dead_letter", nil)
+	}
+
+	result, err := hs.SQLStore.SearchWebhookDeliveries(c.Req.Context(), &query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search webhook deliveries", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// RedeliverWebhookDelivery makes a delivery pending again with its attempts reset, which is how the dead-lettered
+// deliveries are retried.
+// POST /api/webhooks/:webhookId/deliveries/:deliveryId/redeliver
+func (hs *HTTPServer) RedeliverWebhookDelivery(c *models.ReqContext) response.Response {
+	delivery, err := hs.SQLStore.RedeliverWebhookDelivery(c.Req.Context(), c.OrgId, c.ParamsInt64(":webhookId"),
+		c.ParamsInt64(":deliveryId"))
+	if err != nil {
+		return webhookErrorResponse(err, "Failed to redeliver webhook delivery")
+	}
+
+	return response.JSON(http.StatusOK, delivery.ToDTO())
+}
+
+// validateWebhookURL returns an error response unless the URL is an absolute HTTP or HTTPS URL.
+func validateWebhookURL(webhookURL string) response.Response {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return response.Error(http.StatusBadRequest, "Invalid URL, expected an absolute HTTP or HTTPS URL", nil)
+	}
+	return nil
+}
+
+// validateWebhookEvents returns an error response unless there are events, and they're valid event types.
+func validateWebhookEvents(events []models.WebhookEventType) response.Response {
+	if len(events) == 0 {
+		return response.Error(http.StatusBadRequest, "At least one event is required", nil)
+	}
+	for _, eventType := range events {
+		if !eventType.IsValid() {
+			return response.Error(http.StatusBadRequest, "Invalid event "+string(eventType), nil)
+		}
+	}
+	return nil
+}
+
+func webhookErrorResponse(err error, message string) response.Response {
+	if errors.Is(err, models.ErrWebhookNotFound) || errors.Is(err, models.ErrWebhookDeliveryNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -206,7 +206,7 @@ func listenToSystemSignals(ctx context.Context, s *server.Server) {
 				fmt.Fprintf(os.Stderr, "Failed to reload loggers: %s\n", err)
 			}
 		case sig := <-signalChan:
-			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			ctx, cancel := context.WithTimeout(ctx, s.ShutdownTimeout())
 			defer cancel()
 			if err := s.Shutdown(ctx, fmt.Sprintf("System signal: %s", sig)); err != nil {
 				fmt.Fprintf(os.Stderr, "Timed out waiting for server to shut down\n")
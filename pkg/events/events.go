@@ -46,3 +46,42 @@ type UserUpdated struct {
 	Login     string    `json:"login"`
 	Email     string    `json:"email"`
 }
+
+type OrgUserAdded struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgId     int64     `json:"orgId"`
+	UserId    int64     `json:"userId"`
+	Login     string    `json:"login"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+}
+
+type DashboardSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgId     int64     `json:"orgId"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	Title     string    `json:"title"`
+	FolderId  int64     `json:"folderId"`
+	Version   int       `json:"version"`
+	UserId    int64     `json:"userId"`
+}
+
+type DataSourceCreated struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgId     int64     `json:"orgId"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+}
+
+type AlertRuleGroupChanged struct {
+	Timestamp    time.Time `json:"timestamp"`
+	OrgId        int64     `json:"orgId"`
+	NamespaceUid string    `json:"namespaceUid"`
+	RuleGroup    string    `json:"ruleGroup"`
+	Action       string    `json:"action"`
+	RuleUids     []string  `json:"ruleUids"`
+	UserId       int64     `json:"userId"`
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
@@ -226,6 +227,147 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// PreviousStates reports whether the alert instance of the given labels was firing, pending or alerting, in the
+// previous evaluation of the alert rule.
+type PreviousStates func(labels data.Labels) bool
+
+type previousStatesKey struct{}
+
+// WithPreviousStates returns a context with the previous states of the alert instances, for the threshold commands.
+func WithPreviousStates(ctx context.Context, previous PreviousStates) context.Context {
+	return context.WithValue(ctx, previousStatesKey{}, previous)
+}
+
+func previousStatesFromContext(ctx context.Context) PreviousStates {
+	previous, ok := ctx.Value(previousStatesKey{}).(PreviousStates)
+	if !ok || previous == nil {
+		return func(data.Labels) bool { return false }
+	}
+	return previous
+}
+
+// ThresholdCommand is an expression command comparing numbers to a threshold with hysteresis: the number of an
+// alert instance that wasn't firing is compared to the enter level, the number of an alert instance that was firing
+// is compared to the exit level, so an instance doesn't flap around a single threshold. The result is 1 when the
+// number is above ("gt") or below ("lt") the level, 0 otherwise.
+type ThresholdCommand struct {
+	ReferenceVar string
+	Evaluator    string
+	EnterLevel   float64
+	ExitLevel    float64
+	refID        string
+}
+
+// NewThresholdCommand creates a new ThresholdCommand. It will return an error if the evaluator is unknown or if
+// the exit level is beyond the enter level.
+func NewThresholdCommand(refID, referenceVar, evaluator string, enterLevel, exitLevel float64) (*ThresholdCommand, error) {
+	switch evaluator {
+	case "gt":
+		if exitLevel > enterLevel {
+			return nil, fmt.Errorf("the exit level %v must not be above the enter level %v for refId %v", exitLevel, enterLevel, refID)
+		}
+	case "lt":
+		if exitLevel < enterLevel {
+			return nil, fmt.Errorf("the exit level %v must not be below the enter level %v for refId %v", exitLevel, enterLevel, refID)
+		}
+	default:
+		return nil, fmt.Errorf("threshold evaluator %q not implemented for refId %v", evaluator, refID)
+	}
+	return &ThresholdCommand{
+		ReferenceVar: referenceVar,
+		Evaluator:    evaluator,
+		EnterLevel:   enterLevel,
+		ExitLevel:    exitLevel,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to threshold for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	rawEvaluator, ok := rn.Query["evaluator"]
+	if !ok {
+		return nil, fmt.Errorf("no evaluator specified for refId %v", rn.RefID)
+	}
+	evaluator, ok := rawEvaluator.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold evaluator to be a string, got %T for refId %v", rawEvaluator, rn.RefID)
+	}
+
+	enterLevel, err := unmarshalLevel(rn, "enterLevel")
+	if err != nil {
+		return nil, err
+	}
+	exitLevel := enterLevel
+	if _, ok := rn.Query["exitLevel"]; ok {
+		if exitLevel, err = unmarshalLevel(rn, "exitLevel"); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewThresholdCommand(rn.RefID, referenceVar, evaluator, enterLevel, exitLevel)
+}
+
+func unmarshalLevel(rn *rawNode, key string) (float64, error) {
+	rawLevel, ok := rn.Query[key]
+	if !ok {
+		return 0, fmt.Errorf("no %v specified for refId %v", key, rn.RefID)
+	}
+	level, ok := rawLevel.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected threshold %v to be a number, got %T for refId %v", key, rawLevel, rn.RefID)
+	}
+	return level, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	previous := previousStatesFromContext(ctx)
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		number, ok := val.(mathexp.Number)
+		if !ok {
+			return newRes, fmt.Errorf("can only threshold type number, got type %v", val.Type())
+		}
+		labels := number.GetLabels()
+		result := mathexp.NewNumber(tc.refID, labels)
+		f := number.GetFloat64Value()
+		if f == nil {
+			result.SetValue(nil)
+			newRes.Values = append(newRes.Values, result)
+			continue
+		}
+
+		level := tc.EnterLevel
+		if previous(labels) {
+			level = tc.ExitLevel
+		}
+		var firing float64
+		if (tc.Evaluator == "gt" && *f > level) || (tc.Evaluator == "lt" && *f < level) {
+			firing = 1
+		}
+		result.SetValue(&firing)
+		newRes.Values = append(newRes.Values, result)
+	}
+	return newRes, nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -240,6 +382,8 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for a threshold expression with hysteresis.
+	TypeThreshold
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +396,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
 	default:
 		return "unknown"
 	}
@@ -268,6 +414,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,71 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdCommand(t *testing.T) {
+	number := func(host string, f float64) mathexp.Number {
+		n := mathexp.NewNumber("A", data.Labels{"host": host})
+		n.SetValue(&f)
+		return n
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+		number("above", 90),
+		number("between", 75),
+		number("below", 60),
+	}}}
+	firing := func(res mathexp.Results) map[string]float64 {
+		m := map[string]float64{}
+		for _, v := range res.Values {
+			m[v.GetLabels()["host"]] = *v.(mathexp.Number).GetFloat64Value()
+		}
+		return m
+	}
+
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: map[string]interface{}{
+		"expression": "$A", "evaluator": "gt", "enterLevel": 80.0, "exitLevel": 70.0,
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"A"}, cmd.NeedsVars())
+
+	t.Run("Should compare to the enter level without previous states", func(t *testing.T) {
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{"above": 1, "between": 0, "below": 0}, firing(res))
+	})
+
+	t.Run("Should compare the firing instances to the exit level", func(t *testing.T) {
+		ctx := WithPreviousStates(context.Background(), func(labels data.Labels) bool {
+			return labels["host"] != "above"
+		})
+		res, err := cmd.Execute(ctx, vars)
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{"above": 1, "between": 1, "below": 0}, firing(res))
+	})
+
+	t.Run("Should compare below the levels", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("B", "A", "lt", 70, 80)
+		require.NoError(t, err)
+		ctx := WithPreviousStates(context.Background(), func(labels data.Labels) bool {
+			return labels["host"] == "between"
+		})
+		res, err := cmd.Execute(ctx, vars)
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{"above": 0, "between": 1, "below": 1}, firing(res))
+	})
+
+	t.Run("Should reject an exit level beyond the enter level", func(t *testing.T) {
+		_, err := NewThresholdCommand("B", "A", "gt", 70, 80)
+		require.Error(t, err)
+		_, err = NewThresholdCommand("B", "A", "lt", 80, 70)
+		require.Error(t, err)
+		_, err = NewThresholdCommand("B", "A", "eq", 80, 80)
+		require.Error(t, err)
+	})
+}
@@ -242,6 +242,102 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchUnion creates Union objects based on the vector matching of a binary operation, like the vector matching
+// of Prometheus. The items are matched on the given labels, or on all their labels but the given ones, and the
+// matching must be one-to-one unless it is many-to-one (group_left) or one-to-many (group_right). The labels of a
+// one-to-one Union are the labels the items are matched on, those of a many-to-one or one-to-many Union are the
+// labels of the item of the "many" side with the included labels of the item of the "one" side.
+func matchUnion(aResults, bResults Results, matching *parse.VectorMatching) ([]*Union, error) {
+	for _, v := range append(append([]Value{}, aResults.Values...), bResults.Values...) {
+		if v.Type() == parse.TypeScalar {
+			return nil, fmt.Errorf("vector matching is only allowed between numbers and series, got a scalar")
+		}
+	}
+
+	signature := func(l data.Labels) string {
+		matched := data.Labels{}
+		for k, v := range l {
+			if labelListContains(matching.Labels, k) == matching.On {
+				matched[k] = v
+			}
+		}
+		return matched.String()
+	}
+
+	many, one := aResults, bResults
+	if matching.Card == parse.CardOneToMany {
+		many, one = bResults, aResults
+	}
+
+	// The items of the "one" side, and of both sides for one-to-one matching, must be unique by signature.
+	oneBySig := map[string]Value{}
+	for _, v := range one.Values {
+		sig := signature(v.GetLabels())
+		if _, ok := oneBySig[sig]; ok {
+			return nil, fmt.Errorf("multiple matches for labels %v: the matching labels must identify a single item on the \"one\" side", sig)
+		}
+		oneBySig[sig] = v
+	}
+	if matching.Card == parse.CardOneToOne {
+		seen := map[string]bool{}
+		for _, v := range many.Values {
+			sig := signature(v.GetLabels())
+			if seen[sig] {
+				return nil, fmt.Errorf("multiple matches for labels %v: use group_left or group_right for many-to-one matching", sig)
+			}
+			seen[sig] = true
+		}
+	}
+
+	unions := []*Union{}
+	for _, m := range many.Values {
+		sig := signature(m.GetLabels())
+		o, ok := oneBySig[sig]
+		if !ok {
+			continue
+		}
+
+		var labels data.Labels
+		if matching.Card == parse.CardOneToOne {
+			labels = data.Labels{}
+			for k, v := range m.GetLabels() {
+				if labelListContains(matching.Labels, k) == matching.On {
+					labels[k] = v
+				}
+			}
+		} else {
+			labels = m.GetLabels().Copy()
+			if labels == nil {
+				labels = data.Labels{}
+			}
+			oLabels := o.GetLabels()
+			for _, k := range matching.Include {
+				if v, ok := oLabels[k]; ok {
+					labels[k] = v
+				} else {
+					delete(labels, k)
+				}
+			}
+		}
+
+		u := &Union{Labels: labels, A: m, B: o}
+		if matching.Card == parse.CardOneToMany {
+			u.A, u.B = o, m
+		}
+		unions = append(unions, u)
+	}
+	return unions, nil
+}
+
+func labelListContains(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -252,7 +348,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = matchUnion(ar, br, node.Matching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
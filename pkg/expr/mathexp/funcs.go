@@ -1,8 +1,10 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
 
@@ -17,6 +19,31 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             log,
 	},
+	"rate": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             rate,
+	},
+	"moving_avg": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             movingAvg,
+	},
+	"moving_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             movingMax,
+	},
+	"moving_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             movingMin,
+	},
+	"delta": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             delta,
+	},
 	"nan": {
 		Return: parse.TypeScalar,
 		F:      nan,
@@ -57,6 +84,139 @@ func log(e *State, varSet Results) (Results, error) {
 	return newRes, nil
 }
 
+// rate returns the per-second rate of change of each series between consecutive points. A decrease of the value is
+// a counter reset, and the rate is then the value of the point over the time since the previous point.
+func rate(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		series, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only calculate the rate of type series, got type %v", res.Type())
+		}
+
+		size := series.Len() - 1
+		if size < 0 {
+			size = 0
+		}
+		newSeries := NewSeries(e.RefID, series.GetLabels(), size)
+		for i := 1; i < series.Len(); i++ {
+			prevTime, prev := series.GetPoint(i - 1)
+			t, f := series.GetPoint(i)
+			var r *float64
+			if elapsed := t.Sub(prevTime).Seconds(); prev != nil && f != nil && elapsed > 0 {
+				delta := *f - *prev
+				if delta < 0 {
+					delta = *f
+				}
+				v := delta / elapsed
+				r = &v
+			}
+			if err := newSeries.SetPoint(i-1, t, r); err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// movingAvg returns the average of the values of each series within the window ending at each point.
+func movingAvg(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64, current *float64) *float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		avg := sum / float64(len(vals))
+		return &avg
+	})
+}
+
+// movingMax returns the maximum of the values of each series within the window ending at each point.
+func movingMax(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64, current *float64) *float64 {
+		max := vals[0]
+		for _, v := range vals[1:] {
+			max = math.Max(max, v)
+		}
+		return &max
+	})
+}
+
+// movingMin returns the minimum of the values of each series within the window ending at each point.
+func movingMin(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64, current *float64) *float64 {
+		min := vals[0]
+		for _, v := range vals[1:] {
+			min = math.Min(min, v)
+		}
+		return &min
+	})
+}
+
+// delta returns the difference between the value of each point of each series and the oldest value within the
+// window ending at the point.
+func delta(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64, current *float64) *float64 {
+		if current == nil {
+			return nil
+		}
+		d := *current - vals[0]
+		return &d
+	})
+}
+
+// perWindow applies windowF to the values within the window ending at each point of each series, from the oldest
+// to the newest, where the window includes the points after the time of the point minus the window duration. The
+// null values are skipped, and the result is null when the window has no values. The series are expected to be
+// sorted by time.
+func perWindow(e *State, varSet Results, rawWindow string, windowF func(vals []float64, current *float64) *float64) (Results, error) {
+	newRes := Results{}
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return newRes, fmt.Errorf("failed to parse window duration %q: %w", rawWindow, err)
+	}
+	if window <= 0 {
+		return newRes, fmt.Errorf("the window duration %q must be positive", rawWindow)
+	}
+
+	for _, res := range varSet.Values {
+		series, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only apply a window function to type series, got type %v", res.Type())
+		}
+		newSeries := NewSeries(e.RefID, series.GetLabels(), series.Len())
+		start := 0
+		for i := 0; i < series.Len(); i++ {
+			t, f := series.GetPoint(i)
+			for !series.GetTime(start).After(t.Add(-window)) {
+				start++
+			}
+			vals := windowValues(series, start, i)
+			var value *float64
+			if len(vals) > 0 {
+				value = windowF(vals, f)
+			}
+			if err := newSeries.SetPoint(i, t, value); err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// windowValues returns the non-null values of the series between the two indexes, inclusive.
+func windowValues(series Series, from, to int) []float64 {
+	vals := make([]float64, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		if f := series.GetValue(i); f != nil {
+			vals = append(vals, *f)
+		}
+	}
+	return vals
+}
+
 // nan returns a scalar nan value
 func nan(e *State) Results {
 	aNaN := math.NaN()
@@ -68,6 +68,219 @@ func TestFunc(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "rate on series",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(30, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(0.5),
+					}, tp{
+						time.Unix(30, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name:      "rate on number - should error",
+			expr:      "rate($A)",
+			vars:      Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "moving_avg on series",
+			expr: `moving_avg($A, "20s")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(20, 0), nil,
+						}, tp{
+							time.Unix(30, 0), float64Pointer(3),
+						}, tp{
+							time.Unix(40, 0), float64Pointer(9),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(40, 0), float64Pointer(6),
+					}),
+				},
+			},
+		},
+		{
+			name: "moving_max on series",
+			expr: `moving_max($A, "20s")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(20, 0), nil,
+						}, tp{
+							time.Unix(30, 0), float64Pointer(3),
+						}, tp{
+							time.Unix(40, 0), float64Pointer(9),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(40, 0), float64Pointer(9),
+					}),
+				},
+			},
+		},
+		{
+			name: "moving_min on series",
+			expr: `moving_min($A, "20s")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(20, 0), nil,
+						}, tp{
+							time.Unix(30, 0), float64Pointer(3),
+						}, tp{
+							time.Unix(40, 0), float64Pointer(9),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(40, 0), float64Pointer(3),
+					}),
+				},
+			},
+		},
+		{
+			name: "delta on series",
+			expr: `delta($A, "30s")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(20, 0), nil,
+						}, tp{
+							time.Unix(30, 0), float64Pointer(3),
+						}, tp{
+							time.Unix(40, 0), float64Pointer(9),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(4),
+					}, tp{
+						time.Unix(20, 0), nil,
+					}, tp{
+						time.Unix(30, 0), float64Pointer(-2),
+					}, tp{
+						time.Unix(40, 0), float64Pointer(6),
+					}),
+				},
+			},
+		},
+		{
+			name:      "moving_avg with an invalid window - should error",
+			expr:      `moving_avg($A, "soon")`,
+			vars:      Vars{"A": Results{[]Value{makeSeries("", nil)}}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name:     "moving_avg without a window - should error",
+			expr:     `moving_avg($A)`,
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
 		{
 			name:     "abs on string - should error",
 			expr:     `abs("hi")`,
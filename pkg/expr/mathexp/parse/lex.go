@@ -180,7 +180,7 @@ Loop:
 		case isNumber(r):
 			l.backup()
 			return lexNumber
-		case unicode.IsLetter(r):
+		case unicode.IsLetter(r) || r == '_':
 			return lexFunc
 		case r == '(':
 			l.emit(itemLeftParen)
@@ -276,7 +276,7 @@ func lexSymbol(l *lexer) stateFn {
 func lexFunc(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case unicode.IsLetter(r):
+		case isVarchar(r):
 			// absorb
 		default:
 			l.backup()
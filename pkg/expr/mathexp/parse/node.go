@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -298,14 +299,19 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is the vector matching of the operands, or nil to join them on their labels.
+	Matching *VectorMatching
 }
 
-func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
-	return &BinaryNode{NodeType: NodeBinary, Pos: operator.pos, Args: [2]Node{arg1, arg2}, Operator: operator, OpStr: operator.val}
+func newBinary(operator item, arg1, arg2 Node, matching *VectorMatching) *BinaryNode {
+	return &BinaryNode{NodeType: NodeBinary, Pos: operator.pos, Args: [2]Node{arg1, arg2}, Operator: operator, OpStr: operator.val, Matching: matching}
 }
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
@@ -329,6 +335,51 @@ func (b *BinaryNode) Return() ReturnType {
 	return t0
 }
 
+// VectorMatchCardinality is the cardinality of the matching of the items of the operands of a binary operation.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne matches each item of an operand to at most one item of the other.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne matches many items of the left operand to one item of the right operand.
+	CardManyToOne
+	// CardOneToMany matches one item of the left operand to many items of the right operand.
+	CardOneToMany
+)
+
+// VectorMatching describes how the items of the operands of a binary operation are matched by their labels, like
+// the vector matching of Prometheus.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// On is true when the items are matched on the Labels, false when they are matched on all their labels but
+	// the Labels.
+	On     bool
+	Labels []string
+	// Include are the labels of the "one" side added to the result of a many-to-one or one-to-many matching.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.Labels, ", ") + ")"
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left"
+	case CardOneToMany:
+		s += " group_right"
+	default:
+		return s
+	}
+	if len(m.Include) > 0 {
+		s += "(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
+}
+
 // UnaryNode holds one argument and an operator.
 type UnaryNode struct {
 	NodeType
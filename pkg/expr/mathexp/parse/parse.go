@@ -175,6 +175,10 @@ F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+
+Each binary operator may be followed by a vector matching clause:
+matching -> ("on" | "ignoring") labels [("group_left" | "group_right") [labels]]
+labels -> "(" [name {"," name}] ")"
 */
 
 // expr:
@@ -185,7 +189,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +202,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +215,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +228,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +241,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +254,71 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary parses the operator, its vector matching clause and its right operand into a BinaryNode.
+func (t *Tree) binary(left Node, right func() Node) Node {
+	operator := t.next()
+	matching := t.matching()
+	return newBinary(operator, left, right(), matching)
+}
+
+// matching parses the vector matching clause of a binary operator, if any.
+func (t *Tree) matching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		Card:   CardOneToOne,
+		On:     token.val == "on",
+		Labels: t.labels(),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labels()
+	}
+	return m
+}
+
+// labels parses a parenthesized list of label names.
+func (t *Tree) labels() []string {
+	t.expect(itemLeftParen, "label list")
+	labels := []string{}
+	for {
+		token := t.next()
+		switch {
+		case token.typ == itemRightParen && len(labels) == 0:
+			return labels
+		case token.typ != itemFunc:
+			t.unexpected(token, "label list")
+		}
+		labels = append(labels, token.val)
+		switch token = t.next(); token.typ {
+		case itemComma:
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, "label list")
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -329,6 +391,10 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -69,6 +72,91 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if v := fv.GetValue(i); v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	avg := Avg(fv)
+	if math.IsNaN(*avg) {
+		return avg
+	}
+	var squares float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *avg
+		squares += d * d
+	}
+	f := math.Sqrt(squares / float64(fv.Len()))
+	return &f
+}
+
+// Percentile returns the p-th percentile of the values, interpolated between the closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		vals = append(vals, *v)
+	}
+	sort.Float64s(vals)
+
+	rank := p / 100 * float64(len(vals)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+	return &f
+}
+
+// reducer returns the reduction function of the given name. The percentiles are named after their rank, such as
+// "p95" or "p99.9".
+func reducer(rFunc string) (func(*Float64Field) *float64, error) {
+	switch rFunc {
+	case "sum":
+		return Sum, nil
+	case "mean":
+		return Avg, nil
+	case "min":
+		return Min, nil
+	case "max":
+		return Max, nil
+	case "count":
+		return Count, nil
+	case "count_non_null":
+		return CountNonNull, nil
+	case "stddev":
+		return StdDev, nil
+	}
+
+	if strings.HasPrefix(rFunc, "p") {
+		p, err := strconv.ParseFloat(strings.TrimPrefix(rFunc, "p"), 64)
+		if err == nil && p >= 0 && p <= 100 {
+			return func(fv *Float64Field) *float64 {
+				return Percentile(fv, p)
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("reduction %v not implemented", rFunc)
+}
+
 // Reduce turns the Series into a Number based on the given reduction function
 func (s Series) Reduce(refID, rFunc string) (Number, error) {
 	var l data.Labels
@@ -76,24 +164,13 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		l = s.GetLabels().Copy()
 	}
 	number := NewNumber(refID, l)
-	var f *float64
+	reduce, err := reducer(rFunc)
+	if err != nil {
+		return number, err
+	}
 	fVec := s.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
-	switch rFunc {
-	case "sum":
-		f = Sum(&floatField)
-	case "mean":
-		f = Avg(&floatField)
-	case "min":
-		f = Min(&floatField)
-	case "max":
-		f = Max(&floatField)
-	case "count":
-		f = Count(&floatField)
-	default:
-		return number, fmt.Errorf("reduction %v not implemented", rFunc)
-	}
-	number.SetValue(f)
+	number.SetValue(reduce(&floatField))
 
 	return number, nil
 }
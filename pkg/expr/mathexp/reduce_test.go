@@ -214,6 +214,92 @@ func TestSeriesReduce(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(0.5)),
+				},
+			},
+		},
+		{
+			name:        "stddev series with a nil value",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "p50 series",
+			red:         "p50",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1.5)),
+				},
+			},
+		},
+		{
+			name:        "p100 series",
+			red:         "p100",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:        "p50 empty series",
+			red:         "p50",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "p101 reduction will error",
+			red:         "p101",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 	}
 
 	for _, tt := range tests {
@@ -7,7 +7,10 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
-// Resample turns the Series into a Number based on the given reduction function
+// Resample turns the Series into a Series with a point at each interval of the time range. The downsampler is the
+// reduction function of the values within an interval, the upsampler fills the intervals without values: with the
+// previous value ("pad" or "previous"), the next value ("backfilling"), null ("fillna" or "null") or the value
+// interpolated between the previous and the next values ("linear").
 func (s Series) Resample(refID string, interval time.Duration, downsampler string, upsampler string, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
@@ -16,6 +19,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
@@ -32,12 +36,13 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
 		if len(vals) == 0 { // upsampling
 			switch upsampler {
-			case "pad":
+			case "pad", "previous":
 				if lastSeen != nil {
 					value = lastSeen
 				} else {
@@ -49,28 +54,27 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 				} else {
 					_, value = s.GetPoint(sIdx)
 				}
-			case "fillna":
+			case "fillna", "null":
 				value = nil
+			case "linear":
+				if lastSeen != nil && sIdx < s.Len() {
+					nextTime, next := s.GetPoint(sIdx)
+					if next != nil {
+						f := *lastSeen + (*next-*lastSeen)*float64(t.Sub(lastSeenTime))/float64(nextTime.Sub(lastSeenTime))
+						value = &f
+					}
+				}
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
 		} else { // downsampling
-			fVec := data.NewField("", s.GetLabels(), vals)
-			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case "sum":
-				tmp = Sum(&ff)
-			case "mean":
-				tmp = Avg(&ff)
-			case "min":
-				tmp = Min(&ff)
-			case "max":
-				tmp = Max(&ff)
-			default:
+			reduce, err := reducer(downsampler)
+			if err != nil {
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
-			value = tmp
+			fVec := data.NewField("", s.GetLabels(), vals)
+			ff := Float64Field(*fVec)
+			value = reduce(&ff)
 		}
 		if err := resampled.SetPoint(idx, t, value); err != nil {
 			return resampled, err
@@ -245,6 +245,58 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(10, 0), nil,
 			}),
 		},
+		{
+			name:        "resample series: upsampling (mean / linear )",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(11, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(7),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(10, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: downsampling (p50 / null )",
+			interval:    time.Second * 5,
+			downsampler: "p50",
+			upsampler:   "null",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(9),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(10, 0), nil,
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -242,3 +242,110 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorMatching(t *testing.T) {
+	errorCounts := Results{Values: Values{
+		makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(2)),
+		makeNumber("", data.Labels{"host": "a", "code": "503"}, float64Pointer(4)),
+		makeNumber("", data.Labels{"host": "b", "code": "500"}, float64Pointer(5)),
+	}}
+	requests := Results{Values: Values{
+		makeNumber("", data.Labels{"host": "a", "version": "1"}, float64Pointer(10)),
+		makeNumber("", data.Labels{"host": "b", "version": "2"}, float64Pointer(100)),
+		makeNumber("", data.Labels{"host": "c", "version": "2"}, float64Pointer(1000)),
+	}}
+	totalErrors := Results{Values: Values{
+		makeNumber("", data.Labels{"host": "a"}, float64Pointer(6)),
+		makeNumber("", data.Labels{"host": "b"}, float64Pointer(5)),
+	}}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "one-to-one on labels",
+			expr:      "$A / on(host) $B",
+			vars:      Vars{"A": totalErrors, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0.6)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0.05)),
+			}},
+		},
+		{
+			name:      "one-to-one ignoring labels",
+			expr:      "$B / ignoring(version) $A",
+			vars:      Vars{"A": totalErrors, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(10.0/6)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(20)),
+			}},
+		},
+		{
+			name:      "many-to-one with group_left and included labels",
+			expr:      "$A / on(host) group_left(version) $B",
+			vars:      Vars{"A": errorCounts, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "code": "500", "version": "1"}, float64Pointer(0.2)),
+				makeNumber("", data.Labels{"host": "a", "code": "503", "version": "1"}, float64Pointer(0.4)),
+				makeNumber("", data.Labels{"host": "b", "code": "500", "version": "2"}, float64Pointer(0.05)),
+			}},
+		},
+		{
+			name:      "one-to-many with group_right",
+			expr:      "$B * on(host) group_right $A",
+			vars:      Vars{"A": errorCounts, "B": totalErrors},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(12)),
+				makeNumber("", data.Labels{"host": "a", "code": "503"}, float64Pointer(24)),
+				makeNumber("", data.Labels{"host": "b", "code": "500"}, float64Pointer(25)),
+			}},
+		},
+		{
+			name:      "one-to-one with many matches should error",
+			expr:      "$A / on(host) $B",
+			vars:      Vars{"A": errorCounts, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+		},
+		{
+			name:      "matching with a scalar should error",
+			expr:      "$A / on(host) 2",
+			vars:      Vars{"A": errorCounts},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+		},
+		{
+			name:     "group_left without matching labels should not parse",
+			expr:     "$A / group_left $B",
+			vars:     Vars{"A": errorCounts, "B": requests},
+			newErrIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars)
+			tt.execErrIs(t, err)
+			if err == nil {
+				assert.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
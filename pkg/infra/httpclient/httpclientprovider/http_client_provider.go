@@ -21,6 +21,7 @@ func New(cfg *setting.Cfg) httpclient.Provider {
 	middlewares := []sdkhttpclient.Middleware{
 		TracingMiddleware(logger),
 		DataSourceMetricsMiddleware(),
+		RequestStatsMiddleware(),
 		SetUserAgentMiddleware(userAgent),
 		sdkhttpclient.BasicAuthenticationMiddleware(),
 		sdkhttpclient.CustomHeadersMiddleware(),
@@ -0,0 +1,49 @@
+package httpclientprovider
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+)
+
+const RequestStatsMiddlewareName = "request-stats"
+
+// RequestStatsMiddleware records the outgoing requests in the request stats of their context, if any.
+func RequestStatsMiddleware() sdkhttpclient.Middleware {
+	return sdkhttpclient.NamedMiddlewareFunc(RequestStatsMiddlewareName, func(opts sdkhttpclient.Options, next http.RoundTripper) http.RoundTripper {
+		return sdkhttpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			stats := httpclient.RequestStatsFromContext(req.Context())
+			if stats == nil {
+				return next.RoundTrip(req)
+			}
+
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			failed := err != nil || res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+			var requestBytes int64
+			if req.ContentLength > 0 {
+				requestBytes = req.ContentLength
+			}
+			stats.AddRequest(requestBytes, time.Since(start), failed)
+
+			if res != nil && res.Body != nil {
+				res.Body = &countingReadCloser{ReadCloser: res.Body, stats: stats}
+			}
+			return res, err
+		})
+	})
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	stats *httpclient.RequestStats
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.stats.AddResponseBytes(int64(n))
+	return n, err
+}
@@ -0,0 +1,53 @@
+package httpclientprovider
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestStatsMiddleware(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	finalRoundTripper := sdkhttpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		return &http.Response{StatusCode: status, Request: req, Body: ioutil.NopCloser(strings.NewReader("response"))}, nil
+	})
+	mw := RequestStatsMiddleware()
+	rt := mw.CreateMiddleware(sdkhttpclient.Options{}, finalRoundTripper)
+	require.Equal(t, RequestStatsMiddlewareName, mw.(sdkhttpclient.MiddlewareName).MiddlewareName())
+
+	send := func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://", strings.NewReader("query"))
+		require.NoError(t, err)
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		_, err = ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	t.Run("Should record the requests in the stats of their context", func(t *testing.T) {
+		ctx, stats := httpclient.WithRequestStats(context.Background())
+		send(ctx)
+		send(ctx)
+
+		snapshot := stats.Snapshot()
+		require.Equal(t, 2, snapshot.Requests)
+		require.Equal(t, 1, snapshot.Failed)
+		require.Equal(t, int64(10), snapshot.RequestBytes)
+		require.Equal(t, int64(16), snapshot.ResponseBytes)
+	})
+
+	t.Run("Should ignore the requests without stats", func(t *testing.T) {
+		statuses = []int{http.StatusOK}
+		send(context.Background())
+		require.Nil(t, httpclient.RequestStatsFromContext(context.Background()))
+	})
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type requestStatsKey struct{}
+
+// RequestStats are the stats of the outgoing HTTP requests sent with a context, collected by the request stats
+// middleware of the HTTP clients.
+type RequestStats struct {
+	mu            sync.Mutex
+	requests      int
+	failed        int
+	requestBytes  int64
+	responseBytes int64
+	latency       time.Duration
+}
+
+// WithRequestStats returns a context collecting the stats of the outgoing HTTP requests sent with it.
+func WithRequestStats(ctx context.Context) (context.Context, *RequestStats) {
+	stats := &RequestStats{}
+	return context.WithValue(ctx, requestStatsKey{}, stats), stats
+}
+
+// RequestStatsFromContext returns the stats collected by the context, or nil if it doesn't collect stats.
+func RequestStatsFromContext(ctx context.Context) *RequestStats {
+	stats, ok := ctx.Value(requestStatsKey{}).(*RequestStats)
+	if !ok {
+		return nil
+	}
+	return stats
+}
+
+// AddRequest records a request, its size in bytes and the latency until its response headers.
+func (s *RequestStats) AddRequest(requestBytes int64, latency time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	if failed {
+		s.failed++
+	}
+	s.requestBytes += requestBytes
+	s.latency += latency
+}
+
+// AddResponseBytes records bytes read from a response body.
+func (s *RequestStats) AddResponseBytes(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responseBytes += n
+}
+
+// RequestStatsSnapshot is a copy of the stats collected so far.
+type RequestStatsSnapshot struct {
+	// Requests is the number of requests, including the failed and retried ones.
+	Requests int
+	// Failed is the number of requests without response or with a response status 5xx or 429.
+	Failed        int
+	RequestBytes  int64
+	ResponseBytes int64
+	// Latency is the sum of the latencies of the requests.
+	Latency time.Duration
+}
+
+func (s *RequestStats) Snapshot() RequestStatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return RequestStatsSnapshot{
+		Requests:      s.requests,
+		Failed:        s.failed,
+		RequestBytes:  s.requestBytes,
+		ResponseBytes: s.responseBytes,
+		Latency:       s.latency,
+	}
+}
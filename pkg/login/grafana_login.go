@@ -29,6 +29,11 @@ var loginUsingGrafanaDB = func(query *models.LoginUserQuery) error {
 
 	user := userQuery.Result
 
+	// The service accounts authenticate with their tokens.
+	if user.IsServiceAccount {
+		return ErrInvalidCredentials
+	}
+
 	if user.IsDisabled {
 		return ErrUserDisabled
 	}
@@ -75,28 +75,92 @@ func (s *SocialBase) httpGet(client *http.Client, url string) (response httpGetR
 }
 
 func (s *SocialBase) searchJSONForAttr(attributePath string, data []byte) (string, error) {
+	val, err := s.searchJSON(attributePath, data)
+	if err != nil {
+		return "", err
+	}
+
+	strVal, ok := val.(string)
+	if ok {
+		return strVal, nil
+	}
+
+	return "", nil
+}
+
+// searchJSONForBool returns whether the attribute path evaluates to true, or nil when it evaluates to null.
+func (s *SocialBase) searchJSONForBool(attributePath string, data []byte) (*bool, error) {
+	val, err := s.searchJSON(attributePath, data)
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	boolVal, ok := val.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%q evaluates to a %T instead of a boolean", attributePath, val)
+	}
+	return &boolVal, nil
+}
+
+// searchJSONForStrings returns the strings of the array the attribute path evaluates to. The other values of the array
+// are ignored, and the result is nil when the path evaluates to null.
+func (s *SocialBase) searchJSONForStrings(attributePath string, data []byte) ([]string, error) {
+	val, err := s.searchJSON(attributePath, data)
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	arrayVal, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q evaluates to a %T instead of an array", attributePath, val)
+	}
+	strVals := make([]string, 0, len(arrayVal))
+	for _, v := range arrayVal {
+		if strVal, ok := v.(string); ok && strVal != "" {
+			strVals = append(strVals, strVal)
+		}
+	}
+	return strVals, nil
+}
+
+// searchJSONForStringMap returns the string values of the object the attribute path evaluates to. The other values of
+// the object, like the null ones, are ignored, and the result is nil when the path evaluates to null.
+func (s *SocialBase) searchJSONForStringMap(attributePath string, data []byte) (map[string]string, error) {
+	val, err := s.searchJSON(attributePath, data)
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	objectVal, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q evaluates to a %T instead of an object", attributePath, val)
+	}
+	strVals := make(map[string]string, len(objectVal))
+	for k, v := range objectVal {
+		if strVal, ok := v.(string); ok && strVal != "" {
+			strVals[k] = strVal
+		}
+	}
+	return strVals, nil
+}
+
+func (s *SocialBase) searchJSON(attributePath string, data []byte) (interface{}, error) {
 	if attributePath == "" {
-		return "", errors.New("no attribute path specified")
+		return nil, errors.New("no attribute path specified")
 	}
 
 	if len(data) == 0 {
-		return "", errors.New("empty user info JSON response provided")
+		return nil, errors.New("empty user info JSON response provided")
 	}
 
 	var buf interface{}
 	if err := json.Unmarshal(data, &buf); err != nil {
-		return "", errutil.Wrap("failed to unmarshal user info JSON response", err)
+		return nil, errutil.Wrap("failed to unmarshal user info JSON response", err)
 	}
 
 	val, err := jmespath.Search(attributePath, buf)
 	if err != nil {
-		return "", errutil.Wrapf(err, "failed to search user info JSON response with provided path: %q", attributePath)
+		return nil, errutil.Wrapf(err, "failed to search user info JSON response with provided path: %q", attributePath)
 	}
-
-	strVal, ok := val.(string)
-	if ok {
-		return strVal, nil
-	}
-
-	return "", nil
+	return val, nil
 }
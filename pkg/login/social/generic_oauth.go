@@ -29,6 +29,10 @@ type SocialGenericOAuth struct {
 	roleAttributeStrict  bool
 	idTokenAttributeName string
 	teamIds              []int
+
+	orgRolesAttributePath     string
+	grafanaAdminAttributePath string
+	teamsAttributePath        string
 }
 
 func (s *SocialGenericOAuth) Type() int {
@@ -151,6 +155,36 @@ func (s *SocialGenericOAuth) UserInfo(client *http.Client, token *oauth2.Token)
 				userInfo.Role = role
 			}
 		}
+
+		if userInfo.OrgRoles == nil && s.orgRolesAttributePath != "" {
+			orgRoles, err := s.extractOrgRoles(data)
+			if err != nil {
+				s.log.Error("Failed to extract org roles", "error", err)
+			} else if orgRoles != nil {
+				s.log.Debug("Setting user info org roles from extracted org roles", "orgRoles", orgRoles)
+				userInfo.OrgRoles = orgRoles
+			}
+		}
+
+		if userInfo.IsGrafanaAdmin == nil && s.grafanaAdminAttributePath != "" {
+			isGrafanaAdmin, err := s.searchJSONForBool(s.grafanaAdminAttributePath, data.rawJSON)
+			if err != nil {
+				s.log.Error("Failed to search JSON for Grafana Admin attribute", "error", err)
+			} else if isGrafanaAdmin != nil {
+				s.log.Debug("Setting user info Grafana Admin from extracted attribute", "isGrafanaAdmin", *isGrafanaAdmin)
+				userInfo.IsGrafanaAdmin = isGrafanaAdmin
+			}
+		}
+
+		if userInfo.Teams == nil && s.teamsAttributePath != "" {
+			teams, err := s.searchJSONForStrings(s.teamsAttributePath, data.rawJSON)
+			if err != nil {
+				s.log.Error("Failed to search JSON for teams attribute", "error", err)
+			} else if teams != nil {
+				s.log.Debug("Setting user info teams from extracted teams", "teams", teams)
+				userInfo.Teams = teams
+			}
+		}
 	}
 
 	if userInfo.Email == "" {
@@ -167,7 +201,7 @@ func (s *SocialGenericOAuth) UserInfo(client *http.Client, token *oauth2.Token)
 		userInfo.Login = userInfo.Email
 	}
 
-	if s.roleAttributeStrict && !models.RoleType(userInfo.Role).IsValid() {
+	if s.roleAttributeStrict && len(userInfo.OrgRoles) == 0 && !models.RoleType(userInfo.Role).IsValid() {
 		return nil, errors.New("invalid role")
 	}
 
@@ -347,6 +381,22 @@ func (s *SocialGenericOAuth) extractRole(data *UserInfoJson) (string, error) {
 	return role, nil
 }
 
+// extractOrgRoles returns the roles of the user by org name. The mapping of the expression must only have valid roles, so
+// that a typo doesn't remove the user from an org.
+func (s *SocialGenericOAuth) extractOrgRoles(data *UserInfoJson) (map[string]string, error) {
+	orgRoles, err := s.searchJSONForStringMap(s.orgRolesAttributePath, data.rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	for org, role := range orgRoles {
+		if !models.RoleType(role).IsValid() {
+			return nil, fmt.Errorf("invalid role %q for org %q", role, org)
+		}
+	}
+	return orgRoles, nil
+}
+
 func (s *SocialGenericOAuth) FetchPrivateEmail(client *http.Client) (string, error) {
 	type Record struct {
 		Email       string `json:"email"`
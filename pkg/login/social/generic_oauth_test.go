@@ -541,6 +541,81 @@ func TestUserInfoSearchesForName(t *testing.T) {
 	})
 }
 
+func TestUserInfoSearchesForOrgRolesAndTeams(t *testing.T) {
+	t.Run("Given a generic OAuth provider", func(t *testing.T) {
+		provider := SocialGenericOAuth{
+			SocialBase: &SocialBase{
+				log: newLogger("generic_oauth_test", log15.LvlDebug),
+			},
+			orgRolesAttributePath:     `{"Main Org.": contains(groups[*], 'admins') && 'Admin' || 'Viewer', "Ops": contains(groups[*], 'ops') && 'Editor' || null}`,
+			grafanaAdminAttributePath: `contains(groups[*], 'admins')`,
+			teamsAttributePath:        `groups[?starts_with(@, 'team-')]`,
+		}
+
+		tests := []struct {
+			Name                   string
+			ResponseBody           interface{}
+			ExpectedOrgRoles       map[string]string
+			ExpectedIsGrafanaAdmin bool
+			ExpectedTeams          []string
+		}{
+			{
+				Name: "Given an admin, maps its roles and teams",
+				ResponseBody: map[string]interface{}{
+					"email":  "john.doe@example.com",
+					"groups": []string{"admins", "ops", "team-backend"},
+				},
+				ExpectedOrgRoles:       map[string]string{"Main Org.": "Admin", "Ops": "Editor"},
+				ExpectedIsGrafanaAdmin: true,
+				ExpectedTeams:          []string{"team-backend"},
+			},
+			{
+				Name: "Given a user of no group, maps its roles and teams",
+				ResponseBody: map[string]interface{}{
+					"email":  "john.doe@example.com",
+					"groups": []string{},
+				},
+				ExpectedOrgRoles: map[string]string{"Main Org.": "Viewer"},
+				ExpectedTeams:    []string{},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.Name, func(t *testing.T) {
+				body, err := json.Marshal(test.ResponseBody)
+				require.NoError(t, err)
+				ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+					w.Header().Set("Content-Type", "application/json")
+					_, err = w.Write(body)
+					require.NoError(t, err)
+				}))
+				provider.apiUrl = ts.URL
+
+				actualResult, err := provider.UserInfo(ts.Client(), &oauth2.Token{})
+				require.NoError(t, err)
+				require.Equal(t, test.ExpectedOrgRoles, actualResult.OrgRoles)
+				require.NotNil(t, actualResult.IsGrafanaAdmin)
+				require.Equal(t, test.ExpectedIsGrafanaAdmin, *actualResult.IsGrafanaAdmin)
+				require.Equal(t, test.ExpectedTeams, actualResult.Teams)
+			})
+		}
+	})
+
+	t.Run("Given an org role mapping with an invalid role, ignores the mapping", func(t *testing.T) {
+		provider := SocialGenericOAuth{
+			SocialBase: &SocialBase{
+				log: newLogger("generic_oauth_test", log15.LvlDebug),
+			},
+			orgRolesAttributePath: `{"Main Org.": 'Owner'}`,
+		}
+
+		orgRoles, err := provider.extractOrgRoles(&UserInfoJson{rawJSON: []byte(`{}`)})
+		require.EqualError(t, err, `invalid role "Owner" for org "Main Org."`)
+		require.Nil(t, orgRoles)
+	})
+}
+
 func TestPayloadCompression(t *testing.T) {
 	provider := SocialGenericOAuth{
 		SocialBase: &SocialBase{
@@ -29,6 +29,13 @@ type BasicUserInfo struct {
 	Company string
 	Role    string
 	Groups  []string
+
+	// OrgRoles are the roles of the user by org name, and replace the Role in the orgs they have.
+	OrgRoles map[string]string
+	// IsGrafanaAdmin is nil when the Grafana Admin permission of the user isn't synced.
+	IsGrafanaAdmin *bool
+	// Teams are the names of the teams of the user in its orgs, and are nil when its teams aren't synced.
+	Teams []string
 }
 
 type SocialConnector interface {
@@ -197,6 +204,10 @@ func NewOAuthService(cfg *setting.Cfg) {
 				idTokenAttributeName: sec.Key("id_token_attribute_name").String(),
 				teamIds:              sec.Key("team_ids").Ints(","),
 				allowedOrganizations: util.SplitString(sec.Key("allowed_organizations").String()),
+
+				orgRolesAttributePath:     sec.Key("org_roles_attribute_path").String(),
+				grafanaAdminAttributePath: sec.Key("grafana_admin_attribute_path").String(),
+				teamsAttributePath:        sec.Key("teams_attribute_path").String(),
 			}
 		}
 
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// The expression data source of pkg/expr, which the queries of the API keys restricted to data sources can use.
+const (
+	expressionDatasourceName = "__expr__"
+	expressionDatasourceUID  = "-100"
+)
+
+// apiKeyScopeRoute is a route of the requests which need a scope. The path segments starting with a colon match a
+// single segment, :uid being the uid of the resource and :id the id of a data source, and a trailing * matches the
+// rest of the path.
+type apiKeyScopeRoute struct {
+	// method is the method of the requests, empty for all of them.
+	method   string
+	path     string
+	resource string
+	action   string
+	// body returns the resources of the requests in their body.
+	body func(body []byte) []bodyResource
+}
+
+// bodyResource is a resource of the body of a request, referenced by its uid, its id or both.
+type bodyResource struct {
+	uid string
+	id  int64
+}
+
+var errResourceMismatch = errors.New("the uid and the id of a resource don't match")
+
+// apiKeyScopeRoutes are the routes which the scopes allow, by order of precedence. The other requests of the API
+// keys with scopes are rejected.
+var apiKeyScopeRoutes = []apiKeyScopeRoute{
+	{method: "GET", path: "/api/search", resource: "dashboards", action: "read"},
+	{method: "GET", path: "/api/dashboards/uid/:uid", resource: "dashboards", action: "read"},
+	{method: "GET", path: "/api/dashboards/uid/:uid/*", resource: "dashboards", action: "read"},
+	{method: "GET", path: "/api/dashboards/*", resource: "dashboards", action: "read"},
+	{method: "POST", path: "/api/dashboards/calculate-diff", resource: "dashboards", action: "read"},
+	{method: "POST", path: "/api/dashboards/trim", resource: "dashboards", action: "read"},
+	{method: "POST", path: "/api/dashboards/db", resource: "dashboards", action: "write", body: dashboardBodyUIDs},
+	{method: "POST", path: "/api/dashboards/import", resource: "dashboards", action: "write", body: dashboardBodyUIDs},
+	{path: "/api/dashboards/uid/:uid", resource: "dashboards", action: "write"},
+	{path: "/api/dashboards/uid/:uid/*", resource: "dashboards", action: "write"},
+	{path: "/api/dashboards/id/*", resource: "dashboards", action: "write"},
+
+	{method: "GET", path: "/api/folders", resource: "folders", action: "read"},
+	{method: "GET", path: "/api/folders/id/*", resource: "folders", action: "read"},
+	{method: "GET", path: "/api/folders/:uid", resource: "folders", action: "read"},
+	{method: "GET", path: "/api/folders/:uid/*", resource: "folders", action: "read"},
+	{method: "POST", path: "/api/folders", resource: "folders", action: "write"},
+	{path: "/api/folders/:uid", resource: "folders", action: "write"},
+	{path: "/api/folders/:uid/*", resource: "folders", action: "write"},
+
+	{method: "POST", path: "/api/ds/query", resource: "ds", action: "query", body: queryBodyDatasources},
+	{method: "POST", path: "/api/tsdb/query", resource: "ds", action: "query", body: queryBodyDatasources},
+	{path: "/api/datasources/proxy/:id", resource: "ds", action: "query"},
+	{path: "/api/datasources/proxy/:id/*", resource: "ds", action: "query"},
+	{path: "/api/datasources/:id/resources", resource: "ds", action: "query"},
+	{path: "/api/datasources/:id/resources/*", resource: "ds", action: "query"},
+	{path: "/api/datasources/:id/health", resource: "ds", action: "query"},
+	{method: "GET", path: "/api/datasources", resource: "ds", action: "read"},
+	{method: "GET", path: "/api/datasources/uid/:uid", resource: "ds", action: "read"},
+	{method: "GET", path: "/api/datasources/uid/:uid/*", resource: "ds", action: "read"},
+	{method: "GET", path: "/api/datasources/name/*", resource: "ds", action: "read"},
+	{method: "GET", path: "/api/datasources/id/*", resource: "ds", action: "read"},
+	{method: "GET", path: "/api/datasources/:id", resource: "ds", action: "read"},
+	{method: "POST", path: "/api/datasources", resource: "ds", action: "write"},
+	{path: "/api/datasources/uid/:uid", resource: "ds", action: "write"},
+	{path: "/api/datasources/uid/:uid/*", resource: "ds", action: "write"},
+	{path: "/api/datasources/name/*", resource: "ds", action: "write"},
+	{path: "/api/datasources/:id", resource: "ds", action: "write"},
+
+	{method: "GET", path: "/api/annotations", resource: "annotations", action: "read"},
+	{method: "GET", path: "/api/annotations/*", resource: "annotations", action: "read"},
+	{path: "/api/annotations", resource: "annotations", action: "write"},
+	{path: "/api/annotations/*", resource: "annotations", action: "write"},
+
+	{method: "GET", path: "/api/ruler/*", resource: "alert.rules", action: "read"},
+	{method: "GET", path: "/api/prometheus/grafana/api/v1/rules", resource: "alert.rules", action: "read"},
+	{method: "GET", path: "/api/v1/provisioning/alert-rules/*", resource: "alert.rules", action: "read"},
+	{path: "/api/ruler/*", resource: "alert.rules", action: "write"},
+	{path: "/api/v1/provisioning/alert-rules", resource: "alert.rules", action: "write"},
+	{path: "/api/v1/provisioning/alert-rules/*", resource: "alert.rules", action: "write"},
+}
+
+// APIKeyScopes rejects the requests of the API keys with scopes which aren't allowed by one of their scopes. The
+// API keys without scopes are only restricted by their role. It needs to be after the context handler.
+func APIKeyScopes() macaron.Handler {
+	return func(c *models.ReqContext) {
+		if c.SignedInUser == nil || c.ApiKeyId == 0 || len(c.ApiKeyScopes) == 0 {
+			return
+		}
+
+		scopes := make([]models.ApiKeyScope, 0, len(c.ApiKeyScopes))
+		for _, s := range c.ApiKeyScopes {
+			scope, err := models.ParseApiKeyScope(s)
+			if err != nil {
+				c.Logger.Warn("Ignoring invalid API key scope", "apiKeyId", c.ApiKeyId, "error", err)
+				continue
+			}
+			scopes = append(scopes, scope)
+		}
+
+		route, params, ok := matchApiKeyScopeRoute(c.Req.Method, c.Req.URL.Path)
+		if !ok {
+			c.JsonApiErr(403, "API key scopes don't allow this request", nil)
+			return
+		}
+		uids, err := requestResourceUIDs(c, route, params)
+		if err != nil {
+			if errors.Is(err, errResourceMismatch) {
+				c.JsonApiErr(400, "Data source uid and id of a query don't match", err)
+				return
+			}
+			c.JsonApiErr(500, "Failed to check the resources of the request", err)
+			return
+		}
+		if !apiKeyScopesAllow(scopes, route.resource, route.action, uids) {
+			c.JsonApiErr(403, "API key scopes don't allow this request, it needs "+route.resource+":"+route.action, nil)
+			return
+		}
+	}
+}
+
+// apiKeyScopesAllow returns whether the scopes allow the action on all the resources with the uids, or on all the
+// resources of the org when the request has no uids.
+func apiKeyScopesAllow(scopes []models.ApiKeyScope, resource, action string, uids []string) bool {
+	allowed := map[string]bool{}
+	for _, scope := range scopes {
+		if scope.Resource != resource || scope.Action != action {
+			continue
+		}
+		if scope.UID == "" {
+			return true
+		}
+		allowed[scope.UID] = true
+	}
+	if len(uids) == 0 {
+		return false
+	}
+	for _, uid := range uids {
+		if !allowed[uid] {
+			return false
+		}
+	}
+	return true
+}
+
+func matchApiKeyScopeRoute(method, path string) (apiKeyScopeRoute, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range apiKeyScopeRoutes {
+		if route.method != "" && route.method != method {
+			continue
+		}
+		if params, ok := matchRoutePath(strings.Split(strings.Trim(route.path, "/"), "/"), segments); ok {
+			return route, params, true
+		}
+	}
+	return apiKeyScopeRoute{}, nil, false
+}
+
+func matchRoutePath(pattern, segments []string) (map[string]string, bool) {
+	params := map[string]string{}
+	for i, p := range pattern {
+		if p == "*" && i == len(pattern)-1 {
+			return params, len(segments) > i
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(p, ":"):
+			params[p] = segments[i]
+		case p != segments[i]:
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segments)
+}
+
+// requestResourceUIDs returns the uids of the resources of the requests, from its path and body. The ids of the data
+// sources are looked up, and the ones which don't exist are left out. The resources of the body referenced by both
+// their uid and their id must be the same, since the handlers use either of them.
+func requestResourceUIDs(c *models.ReqContext, route apiKeyScopeRoute, params map[string]string) ([]string, error) {
+	var resources []bodyResource
+	if uid, ok := params[":uid"]; ok {
+		resources = append(resources, bodyResource{uid: uid})
+	}
+	if id, err := strconv.ParseInt(params[":id"], 10, 64); err == nil {
+		resources = append(resources, bodyResource{id: id})
+	}
+	if route.body != nil && c.Req.Request.Body != nil {
+		body, err := ioutil.ReadAll(c.Req.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		c.Req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resources = append(resources, route.body(body)...)
+	}
+
+	var uids []string
+	for _, r := range resources {
+		if r.uid != "" {
+			uids = append(uids, r.uid)
+		}
+		if r.id == 0 || route.resource != "ds" {
+			continue
+		}
+		query := models.GetDataSourceQuery{Id: r.id, OrgId: c.OrgId}
+		if err := bus.Dispatch(&query); err != nil {
+			if errors.Is(err, models.ErrDataSourceNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if r.uid != "" && r.uid != query.Result.Uid {
+			return nil, errResourceMismatch
+		}
+		uids = append(uids, query.Result.Uid)
+	}
+	return uids, nil
+}
+
+// dashboardBodyUIDs returns the uid of the dashboard of a save or import command.
+func dashboardBodyUIDs(body []byte) []bodyResource {
+	var cmd struct {
+		Dashboard struct {
+			UID string `json:"uid"`
+		} `json:"dashboard"`
+	}
+	if err := json.Unmarshal(body, &cmd); err != nil || cmd.Dashboard.UID == "" {
+		return nil
+	}
+	return []bodyResource{{uid: cmd.Dashboard.UID}}
+}
+
+// queryBodyDatasources returns the data sources of the queries, except the expressions. The id of a query is always
+// returned, since the query handlers pick its data source by id.
+func queryBodyDatasources(body []byte) []bodyResource {
+	var req struct {
+		Queries []struct {
+			DatasourceID int64           `json:"datasourceId"`
+			Datasource   json.RawMessage `json:"datasource"`
+		} `json:"queries"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+
+	var resources []bodyResource
+	for _, q := range req.Queries {
+		var name string
+		var ref struct {
+			UID string `json:"uid"`
+		}
+		if json.Unmarshal(q.Datasource, &name) == nil && name == expressionDatasourceName {
+			continue
+		}
+		resource := bodyResource{}
+		if json.Unmarshal(q.Datasource, &ref) == nil && ref.UID != expressionDatasourceUID {
+			resource.uid = ref.UID
+		}
+		if q.DatasourceID > 0 {
+			resource.id = q.DatasourceID
+		}
+		if resource.uid != "" || resource.id != 0 {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
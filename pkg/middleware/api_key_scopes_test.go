@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestAPIKeyScopesMiddleware(t *testing.T) {
+	bus.ClearBusHandlers()
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		uids := map[int64]string{1: "xyz", 2: "other"}
+		if uids[query.Id] == "" || query.OrgId != 1 {
+			return models.ErrDataSourceNotFound
+		}
+		query.Result = &models.DataSource{Id: query.Id, Uid: uids[query.Id], OrgId: 1}
+		return nil
+	})
+
+	doReq := func(t *testing.T, user *models.SignedInUser, method, path, body string) int {
+		m := macaron.New()
+		m.Use(macaron.Renderer(macaron.RenderOptions{
+			Directory: "",
+			Delims:    macaron.Delims{Left: "[[", Right: "]]"},
+		}))
+		m.Use(func(c *macaron.Context) {
+			c.Map(&models.ReqContext{Context: c, IsSignedIn: true, SignedInUser: user, Logger: log.New("test")})
+		})
+		m.Use(APIKeyScopes())
+		m.Any("/*", func(c *models.ReqContext) {
+			c.JSON(200, map[string]interface{}{"message": "OK"})
+		})
+
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		require.NoError(t, err)
+		m.ServeHTTP(resp, req)
+		return resp.Code
+	}
+	apiKey := func(scopes ...string) *models.SignedInUser {
+		return &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR, ApiKeyId: 1, ApiKeyScopes: scopes}
+	}
+
+	t.Run("API keys and users without scopes aren't restricted", func(t *testing.T) {
+		assert.Equal(t, 200, doReq(t, apiKey(), "DELETE", "/api/datasources/uid/xyz", ""))
+		assert.Equal(t, 200, doReq(t, &models.SignedInUser{OrgId: 1, UserId: 1}, "GET", "/api/users", ""))
+	})
+
+	t.Run("Requests are allowed by the scope of their route", func(t *testing.T) {
+		key := apiKey("dashboards:read", "folders:write")
+		assert.Equal(t, 200, doReq(t, key, "GET", "/api/search", ""))
+		assert.Equal(t, 200, doReq(t, key, "GET", "/api/dashboards/uid/abc", ""))
+		assert.Equal(t, 200, doReq(t, key, "POST", "/api/folders/", `{"title":"CI"}`))
+		assert.Equal(t, 403, doReq(t, key, "POST", "/api/dashboards/db", `{"dashboard":{"uid":"abc"}}`))
+		assert.Equal(t, 403, doReq(t, key, "GET", "/api/folders", ""))
+		assert.Equal(t, 403, doReq(t, key, "GET", "/api/datasources", ""))
+	})
+
+	t.Run("Requests without a scope route are rejected", func(t *testing.T) {
+		key := apiKey("dashboards:read", "dashboards:write")
+		assert.Equal(t, 403, doReq(t, key, "GET", "/api/users", ""))
+		assert.Equal(t, 403, doReq(t, key, "POST", "/api/auth/keys", `{"name":"escalated","role":"Admin"}`))
+	})
+
+	t.Run("Scopes restricted by uid only allow the requests of the resource", func(t *testing.T) {
+		key := apiKey("dashboards:write:uid:abc", "ds:query:uid:xyz")
+		assert.Equal(t, 200, doReq(t, key, "POST", "/api/dashboards/db", `{"dashboard":{"uid":"abc"}}`))
+		assert.Equal(t, 403, doReq(t, key, "POST", "/api/dashboards/db", `{"dashboard":{"uid":"other"}}`))
+		assert.Equal(t, 403, doReq(t, key, "POST", "/api/dashboards/db", `{"dashboard":{"title":"new"}}`))
+		assert.Equal(t, 200, doReq(t, key, "DELETE", "/api/dashboards/uid/abc", ""))
+		assert.Equal(t, 403, doReq(t, key, "DELETE", "/api/dashboards/uid/other", ""))
+
+		assert.Equal(t, 200, doReq(t, key, "GET", "/api/datasources/proxy/1/api/v1/query", ""))
+		assert.Equal(t, 403, doReq(t, key, "GET", "/api/datasources/proxy/2/api/v1/query", ""))
+		assert.Equal(t, 200, doReq(t, key, "POST", "/api/ds/query",
+			`{"queries":[{"refId":"A","datasourceId":1},{"refId":"B","datasource":{"uid":"xyz"}},{"refId":"C","datasource":{"uid":"-100"}}]}`))
+		assert.Equal(t, 403, doReq(t, key, "POST", "/api/ds/query",
+			`{"queries":[{"refId":"A","datasourceId":1},{"refId":"B","datasource":{"uid":"other"}}]}`))
+		assert.Equal(t, 403, doReq(t, key, "GET", "/api/datasources/uid/xyz", ""))
+	})
+
+	t.Run("Queries are checked by their data source id", func(t *testing.T) {
+		key := apiKey("ds:query:uid:xyz")
+		assert.Equal(t, 200, doReq(t, key, "POST", "/api/ds/query",
+			`{"queries":[{"refId":"A","datasource":{"uid":"xyz"},"datasourceId":1}]}`))
+		assert.Equal(t, 400, doReq(t, key, "POST", "/api/ds/query",
+			`{"queries":[{"refId":"A","datasource":{"uid":"xyz"},"datasourceId":2}]}`))
+		assert.Equal(t, 400, doReq(t, key, "POST", "/api/tsdb/query",
+			`{"queries":[{"refId":"A","datasource":{"uid":"xyz"},"datasourceId":2}]}`))
+		assert.Equal(t, 403, doReq(t, key, "POST", "/api/ds/query",
+			`{"queries":[{"refId":"A","datasource":{"uid":"-100"},"datasourceId":2}]}`))
+	})
+}
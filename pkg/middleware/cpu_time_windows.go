@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and kernel CPU time used by the process.
+func processCPUTime() (time.Duration, error) {
+	handle, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0, err
+	}
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0, err
+	}
+	// the file times are in 100 nanoseconds intervals
+	ticks := (int64(kernel.HighDateTime)<<32 | int64(kernel.LowDateTime)) + (int64(user.HighDateTime)<<32 | int64(user.LowDateTime))
+	return time.Duration(ticks * 100), nil
+}
@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyPendingTimeout = 5 * time.Minute
+)
+
+// idempotentResponse is the response of the request with an idempotency key, or a marker that the request is being
+// handled when Pending.
+type idempotentResponse struct {
+	// Fingerprint is the hash of the method, path and body of the request.
+	Fingerprint string
+	Pending     bool
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyKeys keeps the responses of the POST requests with an Idempotency-Key header in the remote cache, so
+// that their retries get the response of the first request instead of being handled again, like the imports of the
+// automation retrying after a timeout. The responses are encrypted with the secret key, as they can hold secrets.
+type IdempotencyKeys struct {
+	cache     remotecache.CacheStorage
+	settings  setting.IdempotencySettings
+	secretKey string
+	// locks serialize the checks of the keys in the instance, by hash of their key
+	locks [64]sync.Mutex
+}
+
+func NewIdempotencyKeys(cache remotecache.CacheStorage, settings setting.IdempotencySettings, secretKey string) *IdempotencyKeys {
+	return &IdempotencyKeys{cache: cache, settings: settings, secretKey: secretKey}
+}
+
+// Idempotency returns the handler of the idempotency keys of a route, which does nothing unless they're enabled.
+// It needs to be before the binding of the request body, which it reads to check the retries.
+func Idempotency(cfg *setting.Cfg, cache remotecache.CacheStorage) macaron.Handler {
+	if !cfg.Idempotency.Enabled {
+		return func(c *models.ReqContext) {}
+	}
+	return NewIdempotencyKeys(cache, cfg.Idempotency, setting.SecretKey).Middleware
+}
+
+// Middleware replays the response of the request with the same idempotency key, or else handles the request and
+// keeps its response unless it's a server error. The requests are handled normally when the remote cache fails.
+func (k *IdempotencyKeys) Middleware(c *models.ReqContext) {
+	idempotencyKey := c.Req.Header.Get(IdempotencyKeyHeader)
+	if c.Req.Method != http.MethodPost || idempotencyKey == "" || !c.IsSignedIn {
+		return
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		c.JsonApiErr(400, fmt.Sprintf("%s header is longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength), nil)
+		return
+	}
+
+	fingerprint, err := requestFingerprint(c)
+	if err != nil {
+		c.JsonApiErr(400, "Failed to read request body", err)
+		return
+	}
+	key := idempotencyCacheKey(c, idempotencyKey)
+	cached, err := k.reserve(key, fingerprint)
+	if err != nil {
+		c.Logger.Warn("Failed to check idempotency key, handling request", "key", key, "error", err)
+		return
+	}
+	if cached != nil {
+		switch {
+		case cached.Fingerprint != fingerprint:
+			c.JsonApiErr(422, fmt.Sprintf("%s was already used by a different request", IdempotencyKeyHeader), nil)
+		case cached.Pending:
+			c.Resp.Header().Set("Retry-After", "1")
+			c.JsonApiErr(409, fmt.Sprintf("A request with the same %s is being handled", IdempotencyKeyHeader), nil)
+		default:
+			replayResponse(c, cached)
+		}
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Resp, limit: k.settings.MaxResponseSize}
+	c.Resp = macaron.NewResponseWriter(c.Req.Method, recorder)
+	c.MapTo(c.Resp, (*http.ResponseWriter)(nil))
+	if c.Render != nil {
+		c.Render.SetResponseWriter(c.Resp)
+	}
+
+	c.Next()
+
+	status := c.Resp.Status()
+	if status == 0 || status >= 500 || recorder.overflow {
+		if err := k.cache.Delete(key); err != nil {
+			c.Logger.Warn("Failed to delete idempotency key", "key", key, "error", err)
+		}
+		return
+	}
+	header := c.Resp.Header().Clone()
+	header.Del("Set-Cookie")
+	response := &idempotentResponse{Fingerprint: fingerprint, Status: status, Header: header, Body: recorder.body.Bytes()}
+	if err := k.set(key, response, k.settings.KeyTTL); err != nil {
+		c.Logger.Warn("Failed to keep idempotent response", "key", key, "error", err)
+	}
+}
+
+// reserve marks the key as pending, and returns nil when it wasn't used yet, or else what it's used by.
+func (k *IdempotencyKeys) reserve(key string, fingerprint string) (*idempotentResponse, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	lock := &k.locks[h.Sum32()%uint32(len(k.locks))]
+	lock.Lock()
+	defer lock.Unlock()
+
+	cached, err := k.get(key)
+	if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		return cached, nil
+	}
+	pending := &idempotentResponse{Fingerprint: fingerprint, Pending: true}
+	return nil, k.set(key, pending, idempotencyPendingTimeout)
+}
+
+func (k *IdempotencyKeys) get(key string) (*idempotentResponse, error) {
+	value, err := k.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, ok := value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected idempotent response type %T", value)
+	}
+	payload, err := util.Decrypt(encrypted, k.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	var response idempotentResponse
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (k *IdempotencyKeys) set(key string, response *idempotentResponse, ttl time.Duration) error {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	encrypted, err := util.Encrypt(payload, k.secretKey)
+	if err != nil {
+		return err
+	}
+	return k.cache.Set(key, encrypted, ttl)
+}
+
+func replayResponse(c *models.ReqContext, response *idempotentResponse) {
+	for name, values := range response.Header {
+		c.Resp.Header()[name] = values
+	}
+	c.Resp.Header().Set(IdempotentReplayedHeader, "true")
+	c.Resp.WriteHeader(response.Status)
+	if _, err := c.Resp.Write(response.Body); err != nil {
+		c.Logger.Warn("Failed to write idempotent response", "error", err)
+	}
+}
+
+// requestFingerprint returns the hash of the method, path and body of the request, and puts the body back for the
+// handlers.
+func requestFingerprint(c *models.ReqContext) (string, error) {
+	var body []byte
+	if c.Req.Request.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(c.Req.Request.Body); err != nil {
+			return "", err
+		}
+		c.Req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s %s\n", c.Req.Method, c.Req.URL.RequestURI())
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// idempotencyCacheKey returns the key of the response in the cache, scoped to the org and the user or API key of
+// the request.
+func idempotencyCacheKey(c *models.ReqContext, idempotencyKey string) string {
+	h := sha256.Sum256([]byte(idempotencyKey))
+	identity := fmt.Sprintf("user:%d", c.UserId)
+	if c.ApiKeyId > 0 {
+		identity = fmt.Sprintf("apikey:%d", c.ApiKeyId)
+	}
+	return fmt.Sprintf("idempotency:org:%d:%s:%s", c.OrgId, identity, hex.EncodeToString(h[:]))
+}
+
+// responseRecorder records the body of the response as it's written, until it's over the limit.
+type responseRecorder struct {
+	http.ResponseWriter
+	limit    int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(b) > r.limit {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
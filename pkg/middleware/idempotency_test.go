@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type request struct {
+		path   string
+		body   string
+		key    string
+		userID int64
+	}
+
+	keys := func(cache remotecache.CacheStorage) *IdempotencyKeys {
+		return NewIdempotencyKeys(cache, setting.IdempotencySettings{Enabled: true, KeyTTL: time.Hour, MaxResponseSize: 1024}, "secret")
+	}
+
+	setup := func(t *testing.T, cache remotecache.CacheStorage, status int) (func(request) *httptest.ResponseRecorder, *int) {
+		handled := 0
+		idempotent := keys(cache).Middleware
+
+		m := macaron.New()
+		m.Use(macaron.Renderer(macaron.RenderOptions{
+			Directory: "",
+			Delims:    macaron.Delims{Left: "[[", Right: "]]"},
+		}))
+		m.Use(func(c *macaron.Context) {
+			userID := int64(1)
+			if id := c.Req.Header.Get("X-User-Id"); id == "2" {
+				userID = 2
+			}
+			c.Map(&models.ReqContext{
+				Context:      c,
+				IsSignedIn:   true,
+				SignedInUser: &models.SignedInUser{UserId: userID, OrgId: 1},
+				Logger:       log.New("test"),
+			})
+		})
+		m.Post("/api/datasources", idempotent, func(c *models.ReqContext) {
+			handled++
+			c.JSON(status, map[string]interface{}{"id": handled})
+		})
+		m.Post("/api/dashboards/import", idempotent, func(c *models.ReqContext) {
+			handled++
+			c.JSON(status, map[string]interface{}{"id": handled})
+		})
+		m.Post("/api/auth/keys", func(c *models.ReqContext) {
+			handled++
+			c.JSON(status, map[string]interface{}{"id": handled})
+		})
+
+		return func(r request) *httptest.ResponseRecorder {
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", r.path, strings.NewReader(r.body))
+			require.NoError(t, err)
+			if r.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, r.key)
+			}
+			if r.userID == 2 {
+				req.Header.Set("X-User-Id", "2")
+			}
+			m.ServeHTTP(resp, req)
+			return resp
+		}, &handled
+	}
+
+	t.Run("Retries get the response of the first request", func(t *testing.T) {
+		doReq, handled := setup(t, remotecache.NewFakeStore(t), 200)
+		req := request{path: "/api/datasources", body: `{"name":"test"}`, key: "abc"}
+
+		first := doReq(req)
+		assert.Equal(t, 200, first.Code)
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+		retry := doReq(req)
+		assert.Equal(t, 200, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, "application/json; charset=UTF-8", retry.Header().Get("Content-Type"))
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, 1, *handled)
+	})
+
+	t.Run("Requests without a key or by other users are handled", func(t *testing.T) {
+		doReq, handled := setup(t, remotecache.NewFakeStore(t), 200)
+
+		doReq(request{path: "/api/datasources", body: `{}`})
+		doReq(request{path: "/api/datasources", body: `{}`})
+		doReq(request{path: "/api/datasources", body: `{}`, key: "abc"})
+		doReq(request{path: "/api/datasources", body: `{}`, key: "abc", userID: 2})
+		assert.Equal(t, 4, *handled)
+	})
+
+	t.Run("Keys reused by a different request are rejected", func(t *testing.T) {
+		doReq, handled := setup(t, remotecache.NewFakeStore(t), 200)
+
+		assert.Equal(t, 200, doReq(request{path: "/api/datasources", body: `{"name":"a"}`, key: "abc"}).Code)
+		assert.Equal(t, 422, doReq(request{path: "/api/datasources", body: `{"name":"b"}`, key: "abc"}).Code)
+		assert.Equal(t, 422, doReq(request{path: "/api/dashboards/import", body: `{"name":"a"}`, key: "abc"}).Code)
+		assert.Equal(t, 1, *handled)
+	})
+
+	t.Run("Retries of requests being handled are rejected", func(t *testing.T) {
+		cache := remotecache.NewFakeStore(t)
+		doReq, handled := setup(t, cache, 200)
+		req := request{path: "/api/datasources", body: `{}`, key: "abc"}
+
+		// a pending request of another instance with the same fingerprint
+		r, err := http.NewRequest("POST", req.path, strings.NewReader(req.body))
+		require.NoError(t, err)
+		c := &models.ReqContext{Context: &macaron.Context{Req: macaron.Request{Request: r}}, SignedInUser: &models.SignedInUser{UserId: 1, OrgId: 1}}
+		fingerprint, err := requestFingerprint(c)
+		require.NoError(t, err)
+		require.NoError(t, keys(cache).set(idempotencyCacheKey(c, req.key), &idempotentResponse{Fingerprint: fingerprint, Pending: true}, time.Minute))
+
+		resp := doReq(req)
+		assert.Equal(t, 409, resp.Code)
+		assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+		assert.Equal(t, 0, *handled)
+	})
+
+	t.Run("Responses are kept encrypted", func(t *testing.T) {
+		cache := remotecache.NewFakeStore(t)
+		doReq, _ := setup(t, cache, 200)
+		req := request{path: "/api/datasources", body: `{}`, key: "abc"}
+		require.Equal(t, 200, doReq(req).Code)
+
+		r, err := http.NewRequest("POST", req.path, strings.NewReader(req.body))
+		require.NoError(t, err)
+		c := &models.ReqContext{Context: &macaron.Context{Req: macaron.Request{Request: r}}, SignedInUser: &models.SignedInUser{UserId: 1, OrgId: 1}}
+		value, err := cache.Get(idempotencyCacheKey(c, req.key))
+		require.NoError(t, err)
+		encrypted, ok := value.([]byte)
+		require.True(t, ok)
+		assert.NotContains(t, string(encrypted), `"id":1`)
+
+		_, err = NewIdempotencyKeys(cache, setting.IdempotencySettings{}, "other secret").get(idempotencyCacheKey(c, req.key))
+		assert.Error(t, err)
+	})
+
+	t.Run("Routes without the handler ignore the key", func(t *testing.T) {
+		doReq, handled := setup(t, remotecache.NewFakeStore(t), 200)
+		req := request{path: "/api/auth/keys", body: `{}`, key: "abc"}
+
+		assert.Empty(t, doReq(req).Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, doReq(req).Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, 2, *handled)
+	})
+
+	t.Run("Server errors aren't kept", func(t *testing.T) {
+		doReq, handled := setup(t, remotecache.NewFakeStore(t), 500)
+		req := request{path: "/api/datasources", body: `{}`, key: "abc"}
+
+		assert.Equal(t, 500, doReq(req).Code)
+		assert.Equal(t, 500, doReq(req).Code)
+		assert.Equal(t, 2, *handled)
+	})
+
+	t.Run("Requests are handled when the cache fails", func(t *testing.T) {
+		doReq, handled := setup(t, failingCacheStorage{}, 200)
+		req := request{path: "/api/datasources", body: `{}`, key: "abc"}
+
+		assert.Equal(t, 200, doReq(req).Code)
+		assert.Equal(t, 200, doReq(req).Code)
+		assert.Equal(t, 2, *handled)
+	})
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	loadSheddingRejectedTotal *prometheus.CounterVec
+	loadSheddingQueuedTotal   *prometheus.CounterVec
+	loadSheddingQueued        prometheus.Gauge
+)
+
+func init() {
+	loadSheddingRejectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "load_shedding_rejected_requests_total",
+			Help:      "Number of low priority requests rejected while the instance was overloaded.",
+		},
+		[]string{"class"},
+	)
+	loadSheddingQueuedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "load_shedding_queued_requests_total",
+			Help:      "Number of low priority requests queued while the instance was overloaded.",
+		},
+		[]string{"class"},
+	)
+	loadSheddingQueued = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Name:      "load_shedding_queued_requests",
+			Help:      "A gauge of the low priority requests currently queued.",
+		},
+	)
+
+	prometheus.MustRegister(loadSheddingRejectedTotal, loadSheddingQueuedTotal, loadSheddingQueued)
+}
+
+// loadSheddingPollInterval is how often the queued requests check the load, as the CPU usage drops without a
+// request being released.
+const loadSheddingPollInterval = 100 * time.Millisecond
+
+// alertingPathPrefixes are the paths of the requests evaluating and testing alert rules and notifications.
+var alertingPathPrefixes = []string{
+	"/api/v1/eval",
+	"/api/v1/rule/test",
+	"/api/alerts/test",
+	"/api/alert-notifications/test",
+}
+
+// LoadShedder counts the requests in flight, and queues or rejects the low priority requests while the instance is
+// overloaded so that the other requests, like the ones of the UI, stay responsive.
+type LoadShedder struct {
+	settings setting.LoadSheddingSettings
+	// cpuUsage returns the percentage of the CPU time of all cores used by the process.
+	cpuUsage func() float64
+
+	inFlight int64
+	queued   int64
+
+	mtx sync.Mutex
+	// released is closed, and replaced, whenever a request is done, to wake the queued requests up.
+	released chan struct{}
+}
+
+// NewLoadShedder creates a load shedder with the settings, sampling the CPU usage of the process.
+func NewLoadShedder(settings setting.LoadSheddingSettings) *LoadShedder {
+	return newLoadShedder(settings, newCPUSampler(time.Second).usage)
+}
+
+func newLoadShedder(settings setting.LoadSheddingSettings, cpuUsage func() float64) *LoadShedder {
+	return &LoadShedder{settings: settings, cpuUsage: cpuUsage, released: make(chan struct{})}
+}
+
+// LoadShedding returns the load shedding handler of the settings. It needs to be after the context handler, which
+// authenticates the requests.
+func LoadShedding(cfg *setting.Cfg) macaron.Handler {
+	return NewLoadShedder(cfg.LoadShedding).Middleware
+}
+
+// Middleware lets the requests of the high priority classes through, and queues the low priority requests while the
+// instance is overloaded. The queued requests are rejected with 503 and the Retry-After header when the queue is
+// full or their wait times out.
+func (l *LoadShedder) Middleware(c *models.ReqContext) {
+	class := requestClass(c)
+	switch {
+	case !l.settings.IsLowPriority(class):
+		atomic.AddInt64(&l.inFlight, 1)
+	case !l.admit() && !l.wait(c, class):
+		return
+	}
+	defer l.release()
+
+	c.Next()
+}
+
+// admit counts the request in flight unless the instance is overloaded.
+func (l *LoadShedder) admit() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.overloaded() {
+		return false
+	}
+	atomic.AddInt64(&l.inFlight, 1)
+	return true
+}
+
+// wait queues the request until it's admitted, and returns false when it's rejected or canceled instead.
+func (l *LoadShedder) wait(c *models.ReqContext, class setting.RequestClass) bool {
+	if atomic.AddInt64(&l.queued, 1) > int64(l.settings.MaxQueuedRequests) {
+		atomic.AddInt64(&l.queued, -1)
+		l.reject(c, class)
+		return false
+	}
+	defer atomic.AddInt64(&l.queued, -1)
+	loadSheddingQueuedTotal.WithLabelValues(string(class)).Inc()
+	loadSheddingQueued.Inc()
+	defer loadSheddingQueued.Dec()
+
+	timeout := time.NewTimer(l.settings.QueueTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(loadSheddingPollInterval)
+	defer ticker.Stop()
+	for {
+		l.mtx.Lock()
+		released := l.released
+		l.mtx.Unlock()
+
+		select {
+		case <-released:
+		case <-ticker.C:
+		case <-timeout.C:
+			l.reject(c, class)
+			return false
+		case <-c.Req.Context().Done():
+			return false
+		}
+		if l.admit() {
+			return true
+		}
+	}
+}
+
+func (l *LoadShedder) release() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	atomic.AddInt64(&l.inFlight, -1)
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+func (l *LoadShedder) overloaded() bool {
+	if max := l.settings.MaxConcurrentRequests; max > 0 && atomic.LoadInt64(&l.inFlight) >= int64(max) {
+		return true
+	}
+	return l.settings.CPUThreshold > 0 && l.cpuUsage() >= l.settings.CPUThreshold
+}
+
+func (l *LoadShedder) reject(c *models.ReqContext, class setting.RequestClass) {
+	loadSheddingRejectedTotal.WithLabelValues(string(class)).Inc()
+	retryAfter := int(math.Ceil(l.settings.QueueTimeout.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Resp.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	c.JsonApiErr(503, "Server is overloaded, try again later", nil)
+}
+
+// requestClass classifies the request by its path and authentication. The requests authenticated without a session,
+// like the ones of the API keys, service accounts and basic auth, are automation.
+func requestClass(c *models.ReqContext) setting.RequestClass {
+	path := c.Req.URL.Path
+	if c.IsRenderCall || strings.HasPrefix(path, "/render/") {
+		return setting.RequestClassRendering
+	}
+	for _, prefix := range alertingPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return setting.RequestClassAlerting
+		}
+	}
+	if c.SignedInUser != nil && c.ApiKeyId > 0 {
+		return setting.RequestClassAPI
+	}
+	if c.UserToken == nil && c.Req.Header.Get("Authorization") != "" {
+		return setting.RequestClassAPI
+	}
+	return setting.RequestClassInteractive
+}
+
+// cpuSampler measures the CPU usage of the process between samples, taken at most once per interval.
+type cpuSampler struct {
+	interval time.Duration
+
+	mtx     sync.Mutex
+	sampled time.Time
+	cpuTime time.Duration
+	value   float64
+}
+
+func newCPUSampler(interval time.Duration) *cpuSampler {
+	s := &cpuSampler{interval: interval, sampled: time.Now()}
+	s.cpuTime, _ = processCPUTime()
+	return s
+}
+
+// usage returns the percentage of the CPU time of all cores used by the process since the previous sample, or zero
+// when the CPU time can't be read.
+func (s *cpuSampler) usage() float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(s.sampled)
+	if elapsed < s.interval {
+		return s.value
+	}
+	cpuTime, err := processCPUTime()
+	if err != nil {
+		return 0
+	}
+	s.value = float64(cpuTime-s.cpuTime) / (float64(elapsed) * float64(runtime.NumCPU())) * 100
+	s.sampled = now
+	s.cpuTime = cpuTime
+	return s.value
+}
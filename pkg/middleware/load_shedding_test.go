@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestRequestClass(t *testing.T) {
+	tests := []struct {
+		desc     string
+		path     string
+		header   string
+		apiKeyID int64
+		token    bool
+		render   bool
+		expected setting.RequestClass
+	}{
+		{desc: "session", path: "/api/dashboards/uid/abc", token: true, expected: setting.RequestClassInteractive},
+		{desc: "anonymous", path: "/api/search", expected: setting.RequestClassInteractive},
+		{desc: "API key", path: "/api/dashboards/db", header: "Bearer key", apiKeyID: 1, expected: setting.RequestClassAPI},
+		{desc: "basic auth", path: "/api/dashboards/db", header: "Basic dXNlcjpwYXNz", expected: setting.RequestClassAPI},
+		{desc: "rule evaluation", path: "/api/v1/eval", token: true, expected: setting.RequestClassAlerting},
+		{desc: "legacy alert test", path: "/api/alerts/test", header: "Bearer key", apiKeyID: 1, expected: setting.RequestClassAlerting},
+		{desc: "rendering", path: "/render/d-solo/abc", token: true, expected: setting.RequestClassRendering},
+		{desc: "image renderer", path: "/d-solo/abc", render: true, expected: setting.RequestClassRendering},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.path, nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			c := &models.ReqContext{
+				Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+				SignedInUser: &models.SignedInUser{ApiKeyId: tc.apiKeyID},
+				IsRenderCall: tc.render,
+			}
+			if tc.token {
+				c.UserToken = &models.UserToken{}
+			}
+			assert.Equal(t, tc.expected, requestClass(c))
+		})
+	}
+}
+
+func TestLoadSheddingMiddleware(t *testing.T) {
+	const basicAuth = "Basic dXNlcjpwYXNz"
+
+	setup := func(t *testing.T, settings setting.LoadSheddingSettings, cpuUsage float64) (func(path, authorization string) *httptest.ResponseRecorder, *LoadShedder, chan struct{}) {
+		shedder := newLoadShedder(settings, func() float64 { return cpuUsage })
+		block := make(chan struct{})
+
+		m := macaron.New()
+		m.Use(macaron.Renderer(macaron.RenderOptions{
+			Directory: "",
+			Delims:    macaron.Delims{Left: "[[", Right: "]]"},
+		}))
+		m.Use(func(c *macaron.Context) {
+			c.Map(&models.ReqContext{Context: c, SignedInUser: &models.SignedInUser{}, Logger: log.New("test")})
+		})
+		m.Use(shedder.Middleware)
+		m.Get("/api/slow", func(c *models.ReqContext) {
+			<-block
+			c.JSON(200, map[string]interface{}{"message": "OK"})
+		})
+		m.Get("/api/search", func(c *models.ReqContext) {
+			c.JSON(200, map[string]interface{}{"message": "OK"})
+		})
+
+		return func(path, authorization string) *httptest.ResponseRecorder {
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", path, nil)
+			require.NoError(t, err)
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			m.ServeHTTP(resp, req)
+			return resp
+		}, shedder, block
+	}
+
+	settings := setting.LoadSheddingSettings{
+		Enabled:               true,
+		MaxConcurrentRequests: 10,
+		CPUThreshold:          90,
+		LowPriority:           []setting.RequestClass{setting.RequestClassAPI},
+		MaxQueuedRequests:     10,
+		QueueTimeout:          200 * time.Millisecond,
+	}
+
+	t.Run("Requests are let through when the instance isn't overloaded", func(t *testing.T) {
+		doReq, _, _ := setup(t, settings, 10)
+		assert.Equal(t, 200, doReq("/api/search", "").Code)
+		assert.Equal(t, 200, doReq("/api/search", basicAuth).Code)
+	})
+
+	t.Run("Low priority requests time out in the queue while the CPU usage is over the threshold", func(t *testing.T) {
+		doReq, _, _ := setup(t, settings, 95)
+		assert.Equal(t, 200, doReq("/api/search", "").Code)
+
+		start := time.Now()
+		resp := doReq("/api/search", basicAuth)
+		assert.Equal(t, 503, resp.Code)
+		assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(settings.QueueTimeout))
+	})
+
+	t.Run("Low priority requests are rejected right away when the queue is full", func(t *testing.T) {
+		full := settings
+		full.MaxQueuedRequests = 0
+		full.QueueTimeout = time.Minute
+		doReq, _, _ := setup(t, full, 95)
+
+		resp := doReq("/api/search", basicAuth)
+		assert.Equal(t, 503, resp.Code)
+		assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+	})
+
+	t.Run("Queued requests are let through once the requests in flight are done", func(t *testing.T) {
+		limited := settings
+		limited.MaxConcurrentRequests = 1
+		limited.QueueTimeout = 10 * time.Second
+		doReq, shedder, block := setup(t, limited, 0)
+		serve := func(path, authorization string) chan int {
+			done := make(chan int, 1)
+			go func() { done <- doReq(path, authorization).Code }()
+			return done
+		}
+
+		slow := serve("/api/slow", "")
+		require.Eventually(t, func() bool { return atomic.LoadInt64(&shedder.inFlight) == 1 }, time.Second, time.Millisecond)
+
+		// interactive requests are never queued
+		assert.Equal(t, 200, doReq("/api/search", "").Code)
+
+		queued := serve("/api/search", basicAuth)
+		require.Eventually(t, func() bool { return atomic.LoadInt64(&shedder.queued) == 1 }, time.Second, time.Millisecond)
+		select {
+		case <-queued:
+			t.Fatal("expected the low priority request to be queued")
+		case <-time.After(2 * loadSheddingPollInterval):
+		}
+
+		close(block)
+		assert.Equal(t, 200, <-slow)
+		assert.Equal(t, 200, <-queued)
+	})
+}
@@ -163,6 +163,58 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, models.ROLE_EDITOR, sc.context.OrgRole)
 	})
 
+	middlewareScenario(t, "Valid service account token", func(t *testing.T, sc *scenarioContext) {
+		const orgID int64 = 12
+		const serviceAccountID int64 = 42
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			saID := serviceAccountID
+			query.Result = &models.ApiKey{Id: 3, OrgId: orgID, Role: models.ROLE_VIEWER, Key: keyhash, ServiceAccountId: &saID}
+			return nil
+		})
+		var lastUsed int64
+		bus.AddHandler("test", func(cmd *models.UpdateApiKeyLastUsedCommand) error {
+			lastUsed = cmd.Id
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: query.UserId, OrgId: query.OrgId, OrgRole: models.ROLE_EDITOR, IsServiceAccount: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+		assert.True(t, sc.context.IsSignedIn)
+		assert.Equal(t, serviceAccountID, sc.context.UserId)
+		assert.Equal(t, orgID, sc.context.OrgId)
+		assert.Equal(t, models.ROLE_EDITOR, sc.context.OrgRole)
+		assert.Equal(t, int64(3), sc.context.ApiKeyId)
+		assert.Equal(t, int64(3), lastUsed)
+	})
+
+	middlewareScenario(t, "Valid token of a disabled service account", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			saID := int64(42)
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_VIEWER, Key: keyhash, ServiceAccountId: &saID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: query.UserId, OrgId: query.OrgId, IsDisabled: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "Service account disabled", sc.respJson["message"])
+	})
+
 	middlewareScenario(t, "Valid API key, but does not match DB hash", func(t *testing.T, sc *scenarioContext) {
 		const keyhash = "Something_not_matching"
 
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// oauthTokenCheckInterval is how often the users without an expiring OAuth token are checked again, since they
+	// may log in with OAuth.
+	oauthTokenCheckInterval = 5 * time.Minute
+	// oauthTokenRetryInterval is how long after a failed refresh it's retried, and the minimum time between the
+	// refreshes of the tokens which expire sooner than the refresh margin.
+	oauthTokenRetryInterval = 30 * time.Second
+)
+
+// refreshOAuthToken refreshes the OAuth token of a user.
+//
+// Stubbable by tests.
+var refreshOAuthToken = oauthtoken.RefreshOAuthToken
+
+// OAuthTokenRefresh refreshes the OAuth access tokens of the users signed in with a session when they're about to
+// expire, so that the data sources forwarding the OAuth identity of the users get valid tokens. The time of the next
+// check of each user is kept in memory, to only read their token when it's about to expire, and the concurrent
+// requests of a user share the same refresh, since the refresh tokens may only be used once.
+func OAuthTokenRefresh(cfg *setting.Cfg) macaron.Handler {
+	checks := localcache.New(oauthTokenCheckInterval, 2*oauthTokenCheckInterval)
+	var refreshes singleflight.Group
+
+	return func(c *models.ReqContext) {
+		if !c.IsSignedIn || c.UserToken == nil || c.SignedInUser == nil {
+			return
+		}
+		key := strconv.FormatInt(c.UserId, 10)
+		if _, ok := checks.Get(key); ok {
+			return
+		}
+
+		user := c.SignedInUser
+		_, _, _ = refreshes.Do(key, func() (interface{}, error) {
+			// The refresh isn't canceled with the request which started it, since the other requests wait for it.
+			token, err := refreshOAuthToken(context.Background(), user, cfg.OAuthTokenRefreshBeforeExpiry)
+			if err != nil {
+				c.Logger.Warn("Failed to refresh OAuth token", "userId", user.UserId, "error", err)
+			}
+			checks.Set(key, true, nextOAuthTokenCheck(token, err, cfg.OAuthTokenRefreshBeforeExpiry))
+			return nil, nil
+		})
+	}
+}
+
+// nextOAuthTokenCheck returns how long until the token of a user needs to be checked again.
+func nextOAuthTokenCheck(token *oauth2.Token, err error, refreshBefore time.Duration) time.Duration {
+	switch {
+	case err != nil:
+		return oauthTokenRetryInterval
+	case token == nil || token.Expiry.IsZero() || token.RefreshToken == "":
+		return oauthTokenCheckInterval
+	}
+	next := time.Until(token.Expiry) - refreshBefore
+	if next < oauthTokenRetryInterval {
+		return oauthTokenRetryInterval
+	}
+	return next
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"gopkg.in/macaron.v1"
+)
+
+func TestOAuthTokenRefreshMiddleware(t *testing.T) {
+	setup := func(t *testing.T, token *oauth2.Token, err error) (func(withSession bool), *[]time.Duration) {
+		var refreshed []time.Duration
+		origRefreshOAuthToken := refreshOAuthToken
+		t.Cleanup(func() {
+			refreshOAuthToken = origRefreshOAuthToken
+		})
+		refreshOAuthToken = func(ctx context.Context, user *models.SignedInUser, refreshBefore time.Duration) (*oauth2.Token, error) {
+			refreshed = append(refreshed, refreshBefore)
+			return token, err
+		}
+
+		m := macaron.New()
+		m.Use(func(c *macaron.Context) {
+			reqContext := &models.ReqContext{
+				Context:      c,
+				IsSignedIn:   true,
+				SignedInUser: &models.SignedInUser{UserId: 1, OrgId: 1},
+				Logger:       log.New("test"),
+			}
+			if c.Req.Header.Get("X-Session") != "" {
+				reqContext.UserToken = &models.UserToken{UserId: 1}
+			}
+			c.Map(reqContext)
+		})
+		m.Use(OAuthTokenRefresh(&setting.Cfg{OAuthTokenRefreshBeforeExpiry: time.Minute}))
+		m.Get("/api/datasources", func(c *models.ReqContext) {
+			c.Resp.WriteHeader(200)
+		})
+
+		return func(withSession bool) {
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/api/datasources", nil)
+			require.NoError(t, err)
+			if withSession {
+				req.Header.Set("X-Session", "1")
+			}
+			m.ServeHTTP(resp, req)
+			require.Equal(t, 200, resp.Code)
+		}, &refreshed
+	}
+
+	t.Run("Refreshes the token of the session users once until the next check", func(t *testing.T) {
+		doReq, refreshed := setup(t, &oauth2.Token{RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}, nil)
+
+		doReq(true)
+		doReq(true)
+		assert.Equal(t, []time.Duration{time.Minute}, *refreshed)
+	})
+
+	t.Run("Ignores the users without a session", func(t *testing.T) {
+		doReq, refreshed := setup(t, nil, nil)
+
+		doReq(false)
+		assert.Empty(t, *refreshed)
+	})
+
+	t.Run("Doesn't fail the requests when the refresh fails", func(t *testing.T) {
+		doReq, refreshed := setup(t, nil, errors.New("invalid_grant"))
+
+		doReq(true)
+		assert.Len(t, *refreshed, 1)
+	})
+}
+
+func TestNextOAuthTokenCheck(t *testing.T) {
+	assert.Equal(t, oauthTokenRetryInterval, nextOAuthTokenCheck(nil, errors.New("invalid_grant"), time.Minute))
+	assert.Equal(t, oauthTokenCheckInterval, nextOAuthTokenCheck(nil, nil, time.Minute))
+	assert.Equal(t, oauthTokenCheckInterval, nextOAuthTokenCheck(&oauth2.Token{Expiry: time.Now().Add(time.Hour)}, nil, time.Minute))
+	assert.Equal(t, oauthTokenRetryInterval, nextOAuthTokenCheck(&oauth2.Token{RefreshToken: "refresh", Expiry: time.Now().Add(time.Minute)}, nil, time.Minute))
+
+	next := nextOAuthTokenCheck(&oauth2.Token{RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}, nil, time.Minute)
+	assert.InDelta(t, float64(59*time.Minute), float64(next), float64(time.Second))
+}
@@ -1,12 +1,21 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
 	"gopkg.in/macaron.v1"
 
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type getTimeFn func() time.Time
@@ -23,3 +32,144 @@ func RateLimit(rps, burst int, getTime getTimeFn) macaron.Handler {
 		}
 	}
 }
+
+func init() {
+	remotecache.Register(&rateLimitBucket{})
+}
+
+// rateLimitBucket is the token bucket of the requests of a user, org or IP address.
+type rateLimitBucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// RateLimiter limits the rate of the requests of each user, org or IP address, with token buckets stored in the
+// remote cache so that the limits are shared by the instances of a high availability setup. The buckets are
+// updated without a distributed lock, so the concurrent requests to different instances can go a little over the
+// limits.
+type RateLimiter struct {
+	// TrustForwardedHeaders counts the requests by the client IP address of the X-Real-IP and X-Forwarded-For
+	// headers, which can only be trusted behind a reverse proxy setting them.
+	TrustForwardedHeaders bool
+
+	cache   remotecache.CacheStorage
+	getTime getTimeFn
+	// locks serialize the updates of the buckets in the instance, by hash of their key
+	locks [64]sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter storing its buckets in the cache. getTime should return the current time.
+// For non-testing purposes use time.Now
+func NewRateLimiter(cache remotecache.CacheStorage, getTime getTimeFn) *RateLimiter {
+	return &RateLimiter{cache: cache, getTime: getTime}
+}
+
+// RateLimiting returns a function that returns the handler limiting the rate of the "auth", "query" or "render"
+// requests by the rate limiting settings, with the buckets in the cache.
+func RateLimiting(cfg *setting.Cfg, cache remotecache.CacheStorage) func(string) macaron.Handler {
+	limiter := NewRateLimiter(cache, time.Now)
+	limiter.TrustForwardedHeaders = cfg.RateLimiting.TrustForwardedHeaders
+	return func(name string) macaron.Handler {
+		if !cfg.RateLimiting.Enabled {
+			return func(c *models.ReqContext) {}
+		}
+
+		switch name {
+		case "auth":
+			return limiter.Limit(name, cfg.RateLimiting.Auth)
+		case "query":
+			return limiter.Limit(name, cfg.RateLimiting.Query)
+		case "render":
+			return limiter.Limit(name, cfg.RateLimiting.Render)
+		default:
+			panic(fmt.Sprintf("unknown rate limit %q", name))
+		}
+	}
+}
+
+// Limit returns a handler rejecting the requests over the limit with 429 and the Retry-After header, counting the
+// requests in the buckets of the name. The requests are allowed when the remote cache fails.
+func (l *RateLimiter) Limit(name string, limit setting.RateLimit) macaron.Handler {
+	if limit.RequestsPerMinute <= 0 {
+		return func(c *models.ReqContext) {}
+	}
+
+	return func(c *models.ReqContext) {
+		key := l.key(c, name, limit.By)
+		retryAfter, err := l.take(key, limit)
+		if err != nil {
+			c.Logger.Warn("Failed to check rate limit, allowing request", "key", key, "error", err)
+			return
+		}
+		if retryAfter > 0 {
+			c.Resp.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JsonApiErr(429, "Rate limit reached", nil)
+		}
+	}
+}
+
+// take takes a token from the bucket of the key, and returns zero when it had one, or else how long until it has
+// one.
+func (l *RateLimiter) take(key string, limit setting.RateLimit) (time.Duration, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	lock := &l.locks[h.Sum32()%uint32(len(l.locks))]
+	lock.Lock()
+	defer lock.Unlock()
+
+	now := l.getTime()
+	tokensPerSecond := float64(limit.RequestsPerMinute) / 60
+	bucket := &rateLimitBucket{Tokens: float64(limit.Burst), Updated: now}
+	cached, err := l.cache.Get(key)
+	if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return 0, err
+	}
+	if cachedBucket, ok := cached.(*rateLimitBucket); ok && err == nil {
+		bucket = cachedBucket
+		if elapsed := now.Sub(bucket.Updated).Seconds(); elapsed > 0 {
+			bucket.Tokens = math.Min(float64(limit.Burst), bucket.Tokens+elapsed*tokensPerSecond)
+		}
+		bucket.Updated = now
+	}
+
+	var retryAfter time.Duration
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+	} else {
+		retryAfter = time.Duration((1 - bucket.Tokens) / tokensPerSecond * float64(time.Second))
+	}
+
+	// the bucket expires once it's full again, as a missing bucket is a full one
+	expire := time.Duration((float64(limit.Burst) - bucket.Tokens) / tokensPerSecond * float64(time.Second))
+	if expire < time.Second {
+		expire = time.Second
+	}
+	if err := l.cache.Set(key, bucket, expire); err != nil {
+		return 0, err
+	}
+	return retryAfter, nil
+}
+
+// key returns the key of the bucket of the request. The requests of the API keys are counted by key when limited by
+// user, and the anonymous requests by IP address.
+func (l *RateLimiter) key(c *models.ReqContext, name string, by setting.RateLimitKey) string {
+	switch {
+	case by == setting.RateLimitByUser && c.IsSignedIn && c.UserId > 0:
+		return fmt.Sprintf("ratelimit:%s:user:%d", name, c.UserId)
+	case by == setting.RateLimitByUser && c.IsSignedIn && c.ApiKeyId > 0:
+		return fmt.Sprintf("ratelimit:%s:apikey:%d", name, c.ApiKeyId)
+	case by == setting.RateLimitByOrg && c.IsSignedIn && c.OrgId > 0:
+		return fmt.Sprintf("ratelimit:%s:org:%d", name, c.OrgId)
+	}
+
+	// the forwarded headers are set by the client without a reverse proxy, so that it could get a new bucket on
+	// every request
+	addr := c.Req.RemoteAddr
+	if l.TrustForwardedHeaders {
+		addr = c.RemoteAddr()
+	}
+	if ip, err := network.GetIPFromAddress(addr); err == nil {
+		addr = ip.String()
+	}
+	return fmt.Sprintf("ratelimit:%s:ip:%s", name, addr)
+}
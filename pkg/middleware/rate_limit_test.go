@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -89,3 +91,97 @@ func TestRateLimitMiddleware(t *testing.T) {
 		}
 	})
 }
+
+type failingCacheStorage struct{}
+
+func (failingCacheStorage) Get(key string) (interface{}, error) {
+	return nil, errors.New("cache is down")
+}
+
+func (failingCacheStorage) Set(key string, value interface{}, expire time.Duration) error {
+	return errors.New("cache is down")
+}
+
+func (failingCacheStorage) Delete(key string) error {
+	return errors.New("cache is down")
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	setup := func(t *testing.T, cache remotecache.CacheStorage, limit setting.RateLimit, trustForwardedHeaders bool) (func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder, advanceTimeFunc) {
+		currentTime := time.Now()
+		limiter := NewRateLimiter(cache, func() time.Time { return currentTime })
+		limiter.TrustForwardedHeaders = trustForwardedHeaders
+
+		m := macaron.New()
+		m.Use(macaron.Renderer(macaron.RenderOptions{
+			Directory: "",
+			Delims:    macaron.Delims{Left: "[[", Right: "]]"},
+		}))
+		m.Use(getContextHandler(t, setting.NewCfg()).Middleware)
+		m.Post("/login", limiter.Limit("auth", limit), func(c *models.ReqContext) {
+			c.JSON(200, map[string]interface{}{"message": "OK"})
+		})
+
+		return func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+				resp := httptest.NewRecorder()
+				req, err := http.NewRequest("POST", "/login", nil)
+				require.NoError(t, err)
+				req.RemoteAddr = remoteAddr
+				if forwardedFor != "" {
+					req.Header.Set("X-Forwarded-For", forwardedFor)
+				}
+				m.ServeHTTP(resp, req)
+				return resp
+			}, func(deltaTime time.Duration) {
+				currentTime = currentTime.Add(deltaTime)
+			}
+	}
+
+	t.Run("Requests over the limit are rejected with Retry-After", func(t *testing.T) {
+		doReq, advanceTime := setup(t, remotecache.NewFakeStore(t), setting.RateLimit{RequestsPerMinute: 6, Burst: 2, By: setting.RateLimitByIP}, false)
+
+		assert.Equal(t, 200, doReq("10.0.0.1:1234", "").Code)
+		assert.Equal(t, 200, doReq("10.0.0.1:1234", "").Code)
+		resp := doReq("10.0.0.1:1234", "")
+		assert.Equal(t, 429, resp.Code)
+		assert.Equal(t, "10", resp.Header().Get("Retry-After"))
+
+		// the requests of other IP addresses have their own bucket
+		assert.Equal(t, 200, doReq("10.0.0.2:1234", "").Code)
+
+		advanceTime(5 * time.Second)
+		resp = doReq("10.0.0.1:4321", "")
+		assert.Equal(t, 429, resp.Code)
+		assert.Equal(t, "5", resp.Header().Get("Retry-After"))
+
+		advanceTime(5 * time.Second)
+		assert.Equal(t, 200, doReq("10.0.0.1:4321", "").Code)
+		assert.Equal(t, 429, doReq("10.0.0.1:4321", "").Code)
+	})
+
+	t.Run("Forwarded headers are ignored unless trusted", func(t *testing.T) {
+		limit := setting.RateLimit{RequestsPerMinute: 6, Burst: 1, By: setting.RateLimitByIP}
+		doReq, _ := setup(t, remotecache.NewFakeStore(t), limit, false)
+		assert.Equal(t, 200, doReq("10.0.0.1:1234", "192.168.0.1").Code)
+		assert.Equal(t, 429, doReq("10.0.0.1:1234", "192.168.0.2").Code)
+
+		doReq, _ = setup(t, remotecache.NewFakeStore(t), limit, true)
+		assert.Equal(t, 200, doReq("10.0.0.1:1234", "192.168.0.1").Code)
+		assert.Equal(t, 200, doReq("10.0.0.1:1234", "192.168.0.2").Code)
+		assert.Equal(t, 429, doReq("10.0.0.2:1234", "192.168.0.1").Code)
+	})
+
+	t.Run("Disabled limits allow all requests", func(t *testing.T) {
+		doReq, _ := setup(t, remotecache.NewFakeStore(t), setting.RateLimit{RequestsPerMinute: 0, Burst: 1, By: setting.RateLimitByIP}, false)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, 200, doReq("10.0.0.1:1234", "").Code)
+		}
+	})
+
+	t.Run("Requests are allowed when the cache fails", func(t *testing.T) {
+		doReq, _ := setup(t, failingCacheStorage{}, setting.RateLimit{RequestsPerMinute: 1, Burst: 1, By: setting.RateLimitByIP}, false)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, 200, doReq("10.0.0.1:1234", "").Code)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -10,6 +12,7 @@ var (
 	ErrInvalidApiKey           = errors.New("invalid API key")
 	ErrInvalidApiKeyExpiration = errors.New("negative value for SecondsToLive")
 	ErrDuplicateApiKey         = errors.New("API key, organization ID and name must be unique")
+	ErrInvalidApiKeyScope      = errors.New("invalid API key scope")
 )
 
 type ApiKey struct {
@@ -21,6 +24,13 @@ type ApiKey struct {
 	Created time.Time
 	Updated time.Time
 	Expires *int64
+	// ServiceAccountId is the service account of the tokens of the service accounts, and nil for the API keys of
+	// the orgs.
+	ServiceAccountId *int64
+	LastUsedAt       *time.Time
+	// Scopes restrict the requests of the API key to the ones they allow, nil to allow all the requests of its
+	// role.
+	Scopes []string `xorm:"json"`
 }
 
 // ---------------------
@@ -31,10 +41,27 @@ type AddApiKeyCommand struct {
 	OrgId         int64    `json:"-"`
 	Key           string   `json:"-"`
 	SecondsToLive int64    `json:"secondsToLive"`
+	Scopes        []string `json:"scopes"`
+	// ServiceAccountId is the service account of the token to add, nil to add an API key of the org.
+	ServiceAccountId *int64 `json:"-"`
 
 	Result *ApiKey `json:"-"`
 }
 
+// RotateApiKeyCommand replaces the key of an API key, which expires SecondsToLive after the rotation.
+type RotateApiKeyCommand struct {
+	Id            int64
+	OrgId         int64
+	Key           string
+	SecondsToLive int64
+
+	Result *ApiKey
+}
+
+type UpdateApiKeyLastUsedCommand struct {
+	Id int64
+}
+
 type DeleteApiKeyCommand struct {
 	Id    int64 `json:"id"`
 	OrgId int64 `json:"-"`
@@ -68,4 +95,63 @@ type ApiKeyDTO struct {
 	Name       string     `json:"name"`
 	Role       RoleType   `json:"role"`
 	Expiration *time.Time `json:"expiration,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+}
+
+// apiKeyScopeActions are the actions of the resources of the API key scopes.
+var apiKeyScopeActions = map[string][]string{
+	"dashboards":  {"read", "write"},
+	"folders":     {"read", "write"},
+	"ds":          {"read", "write", "query"},
+	"annotations": {"read", "write"},
+	"alert.rules": {"read", "write"},
+}
+
+// ApiKeyScope is a permission of an API key, like dashboards:read, or ds:query:uid:xyz which only allows to query
+// the data source with the uid xyz. Only the scopes of the dashboards, folders and data sources can be restricted
+// by uid.
+type ApiKeyScope struct {
+	Resource string
+	Action   string
+	// UID restricts the scope to the resource with the uid, empty for all the resources of the org.
+	UID string
+}
+
+// ParseApiKeyScope parses a scope in the format resource:action, or resource:action:uid:value.
+func ParseApiKeyScope(s string) (ApiKeyScope, error) {
+	parts := strings.SplitN(s, ":", 4)
+	if len(parts) != 2 && len(parts) != 4 {
+		return ApiKeyScope{}, fmt.Errorf("%w %q: expected resource:action or resource:action:uid:value", ErrInvalidApiKeyScope, s)
+	}
+
+	scope := ApiKeyScope{Resource: parts[0], Action: parts[1]}
+	actions, ok := apiKeyScopeActions[scope.Resource]
+	if !ok {
+		return ApiKeyScope{}, fmt.Errorf("%w %q: unknown resource %q", ErrInvalidApiKeyScope, s, scope.Resource)
+	}
+	valid := false
+	for _, action := range actions {
+		valid = valid || action == scope.Action
+	}
+	if !valid {
+		return ApiKeyScope{}, fmt.Errorf("%w %q: the actions of %s are %s", ErrInvalidApiKeyScope, s, scope.Resource, strings.Join(actions, ", "))
+	}
+
+	if len(parts) == 4 {
+		if parts[2] != "uid" || parts[3] == "" {
+			return ApiKeyScope{}, fmt.Errorf("%w %q: expected resource:action:uid:value", ErrInvalidApiKeyScope, s)
+		}
+		if scope.Resource != "dashboards" && scope.Resource != "folders" && scope.Resource != "ds" {
+			return ApiKeyScope{}, fmt.Errorf("%w %q: %s can't be restricted by uid", ErrInvalidApiKeyScope, s, scope.Resource)
+		}
+		scope.UID = parts[3]
+	}
+	return scope, nil
+}
+
+func (s ApiKeyScope) String() string {
+	if s.UID != "" {
+		return fmt.Sprintf("%s:%s:uid:%s", s.Resource, s.Action, s.UID)
+	}
+	return s.Resource + ":" + s.Action
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseApiKeyScope(t *testing.T) {
+	t.Run("Can parse valid scopes", func(t *testing.T) {
+		scope, err := ParseApiKeyScope("dashboards:read")
+		require.NoError(t, err)
+		assert.Equal(t, ApiKeyScope{Resource: "dashboards", Action: "read"}, scope)
+
+		scope, err = ParseApiKeyScope("ds:query:uid:xyz")
+		require.NoError(t, err)
+		assert.Equal(t, ApiKeyScope{Resource: "ds", Action: "query", UID: "xyz"}, scope)
+		assert.Equal(t, "ds:query:uid:xyz", scope.String())
+
+		scope, err = ParseApiKeyScope("alert.rules:write")
+		require.NoError(t, err)
+		assert.Equal(t, "alert.rules:write", scope.String())
+	})
+
+	t.Run("Rejects invalid scopes", func(t *testing.T) {
+		for _, s := range []string{"", "dashboards", "users:read", "dashboards:query", "ds:query:id:1", "ds:query:uid:", "annotations:read:uid:abc"} {
+			_, err := ParseApiKeyScope(s)
+			assert.True(t, errors.Is(err, ErrInvalidApiKeyScope), s)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+type AuditResourceType string
+
+const (
+	AuditResourceDashboard      AuditResourceType = "dashboard"
+	AuditResourceDataSource     AuditResourceType = "datasource"
+	AuditResourceUser           AuditResourceType = "user"
+	AuditResourceOrgUser        AuditResourceType = "org_user"
+	AuditResourceAlertRuleGroup AuditResourceType = "alert_rule_group"
+	AuditResourceOrg            AuditResourceType = "org"
+	AuditResourcePreferences    AuditResourceType = "preferences"
+	AuditResourcePluginSettings AuditResourceType = "plugin_settings"
+)
+
+// AuditEntry is a change of a resource made by a user. The states of the resource before and after the change, and
+// their diff in the jsondiffpatch delta format, are JSON encoded.
+type AuditEntry struct {
+	Id           int64
+	OrgId        int64
+	UserId       int64
+	UserLogin    string
+	Action       AuditAction
+	ResourceType AuditResourceType
+	ResourceId   string
+	ResourceName string
+	Before       string `xorm:"data_before"`
+	After        string `xorm:"data_after"`
+	Diff         string
+	Created      time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+// RecordAuditEntryCommand records a change made by a user in the audit log. Before is nil for the created resources,
+// and After for the deleted ones.
+type RecordAuditEntryCommand struct {
+	OrgId        int64
+	UserId       int64
+	UserLogin    string
+	Action       AuditAction
+	ResourceType AuditResourceType
+	ResourceId   string
+	ResourceName string
+	Before       interface{}
+	After        interface{}
+}
+
+// ---------------------
+// QUERIES
+
+type SearchAuditEntriesQuery struct {
+	OrgId        int64
+	UserId       int64
+	Action       AuditAction
+	ResourceType AuditResourceType
+	ResourceId   string
+	From         time.Time
+	To           time.Time
+	Page         int
+	PerPage      int
+}
+
+// ------------------------
+// DTO & Projections
+
+type AuditEntryDTO struct {
+	Id           int64             `json:"id"`
+	OrgId        int64             `json:"orgId"`
+	UserId       int64             `json:"userId"`
+	UserLogin    string            `json:"userLogin"`
+	Action       AuditAction       `json:"action"`
+	ResourceType AuditResourceType `json:"resourceType"`
+	ResourceId   string            `json:"resourceId"`
+	ResourceName string            `json:"resourceName"`
+	Before       json.RawMessage   `json:"before,omitempty"`
+	After        json.RawMessage   `json:"after,omitempty"`
+	Diff         json.RawMessage   `json:"diff,omitempty"`
+	Created      time.Time         `json:"created"`
+}
+
+type SearchAuditEntriesResult struct {
+	TotalCount int64            `json:"totalCount"`
+	Entries    []*AuditEntryDTO `json:"entries"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"perPage"`
+}
+
+func (e *AuditEntry) ToDTO() *AuditEntryDTO {
+	rawJSON := func(s string) json.RawMessage {
+		if s == "" {
+			return nil
+		}
+		return json.RawMessage(s)
+	}
+	return &AuditEntryDTO{
+		Id:           e.Id,
+		OrgId:        e.OrgId,
+		UserId:       e.UserId,
+		UserLogin:    e.UserLogin,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceId:   e.ResourceId,
+		ResourceName: e.ResourceName,
+		Before:       rawJSON(e.Before),
+		After:        rawJSON(e.After),
+		Diff:         rawJSON(e.Diff),
+		Created:      e.Created,
+	}
+}
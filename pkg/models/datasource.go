@@ -186,11 +186,17 @@ type DsPermissionType int
 const (
 	DsPermissionNoAccess DsPermissionType = iota
 	DsPermissionQuery
+	DsPermissionRead
+	DsPermissionWrite
+	DsPermissionAdmin
 )
 
 func (p DsPermissionType) String() string {
 	names := map[int]string{
 		int(DsPermissionQuery):    "Query",
+		int(DsPermissionRead):     "Read",
+		int(DsPermissionWrite):    "Write",
+		int(DsPermissionAdmin):    "Admin",
 		int(DsPermissionNoAccess): "No Access",
 	}
 	return names[int(p)]
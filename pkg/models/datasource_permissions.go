@@ -0,0 +1,112 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDataSourcePermissionInvalid         = errors.New("data source permission must be one of query, read, write or admin")
+	ErrDataSourcePermissionInfoMissing     = errors.New("one of user id, team id or role must be set for a data source permission")
+	ErrDataSourcePermissionAdminRole       = errors.New("permissions can't be set for the admin role, admins have admin permission on every data source")
+	ErrDataSourcePermissionDuplicate       = errors.New("a user, team or role can only be given one data source permission")
+	ErrDataSourcePermissionDataSourceEmpty = errors.New("data source id must be greater than zero for a data source permission")
+)
+
+// DataSourcePermission gives a user, a team, or every user with a role in the org a permission on a data source.
+// Each level of permission includes the ones below it: query < read < write < admin.
+type DataSourcePermission struct {
+	Id           int64
+	OrgId        int64
+	DataSourceId int64
+
+	UserId     int64
+	TeamId     int64
+	Role       *RoleType // pointer to be nullable
+	Permission DsPermissionType
+
+	Created time.Time
+	Updated time.Time
+}
+
+// AppliesTo returns whether the permission is given to the user, either directly, through one of their teams, or
+// through their role in the org.
+func (p *DataSourcePermission) AppliesTo(user *SignedInUser) bool {
+	switch {
+	case p.UserId != 0:
+		return p.UserId == user.UserId
+	case p.TeamId != 0:
+		for _, teamID := range user.Teams {
+			if teamID == p.TeamId {
+				return true
+			}
+		}
+		return false
+	case p.Role != nil:
+		return *p.Role == user.OrgRole
+	}
+	return false
+}
+
+type DataSourcePermissionDTO struct {
+	Id           int64 `json:"id"`
+	DataSourceId int64 `json:"datasourceId"`
+
+	UserId         int64            `json:"userId,omitempty"`
+	UserLogin      string           `json:"userLogin,omitempty"`
+	UserEmail      string           `json:"userEmail,omitempty"`
+	TeamId         int64            `json:"teamId,omitempty"`
+	Team           string           `json:"team,omitempty"`
+	Role           *RoleType        `json:"role,omitempty"`
+	Permission     DsPermissionType `json:"permission"`
+	PermissionName string           `json:"permissionName"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// ---------------------
+// COMMANDS
+
+type DataSourcePermissionItem struct {
+	UserId     int64            `json:"userId"`
+	TeamId     int64            `json:"teamId"`
+	Role       *RoleType        `json:"role"`
+	Permission DsPermissionType `json:"permission"`
+}
+
+// Validate returns an error if the item doesn't give exactly one user, team or non-admin role a valid permission.
+func (item *DataSourcePermissionItem) Validate() error {
+	if item.Permission < DsPermissionQuery || item.Permission > DsPermissionAdmin {
+		return ErrDataSourcePermissionInvalid
+	}
+
+	set := 0
+	if item.UserId != 0 {
+		set++
+	}
+	if item.TeamId != 0 {
+		set++
+	}
+	if item.Role != nil {
+		if !item.Role.IsValid() {
+			return ErrDataSourcePermissionInfoMissing
+		}
+		if *item.Role == ROLE_ADMIN {
+			return ErrDataSourcePermissionAdminRole
+		}
+		set++
+	}
+	if set != 1 {
+		return ErrDataSourcePermissionInfoMissing
+	}
+	return nil
+}
+
+// UpdateDataSourcePermissionsCommand replaces the permissions of a data source with the items.
+type UpdateDataSourcePermissionsCommand struct {
+	Items []DataSourcePermissionItem `json:"items"`
+
+	OrgId        int64 `json:"-"`
+	DataSourceId int64 `json:"-"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrLDAPSyncRunNotFound   = errors.New("LDAP sync run not found")
+	ErrLDAPSyncAlreadyActive = errors.New("an LDAP sync is already running")
+)
+
+type LDAPSyncState string
+
+const (
+	LDAPSyncRunning   LDAPSyncState = "running"
+	LDAPSyncSucceeded LDAPSyncState = "succeeded"
+	// LDAPSyncFailed is the state of the runs which couldn't search the users in LDAP. The runs which failed to sync
+	// some of the users succeed, with the errors of these users in their report.
+	LDAPSyncFailed LDAPSyncState = "failed"
+)
+
+type LDAPSyncTrigger string
+
+const (
+	LDAPSyncTriggerSchedule LDAPSyncTrigger = "schedule"
+	LDAPSyncTriggerManual   LDAPSyncTrigger = "manual"
+)
+
+type LDAPSyncUserAction string
+
+const (
+	LDAPSyncUserDisabled LDAPSyncUserAction = "disabled"
+	LDAPSyncUserEnabled  LDAPSyncUserAction = "enabled"
+	LDAPSyncUserFailed   LDAPSyncUserAction = "failed"
+)
+
+// LDAPSyncRun is a run of the background sync of the LDAP users, with the report of the users it disabled, enabled
+// or failed to sync. The other users are counted as synced.
+type LDAPSyncRun struct {
+	Id          int64
+	TriggeredBy LDAPSyncTrigger
+	State       LDAPSyncState
+	Error       string
+	Started     time.Time
+	// Finished is the time the run finished, it's the time it started while it's running.
+	Finished time.Time
+
+	Users    int
+	Synced   int
+	Disabled int
+	Enabled  int
+	Failed   int
+	// Report is the JSON array of the LDAPSyncUserResult of the run.
+	Report string
+}
+
+func (r LDAPSyncRun) TableName() string {
+	return "ldap_sync_run"
+}
+
+// LDAPSyncUserResult is the result of the sync of a user, when it isn't simply synced.
+type LDAPSyncUserResult struct {
+	UserId int64              `json:"userId"`
+	Login  string             `json:"login"`
+	Action LDAPSyncUserAction `json:"action"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// ------------------------
+// DTO & Projections
+
+type LDAPSyncRunDTO struct {
+	Id          int64           `json:"id"`
+	TriggeredBy LDAPSyncTrigger `json:"triggeredBy"`
+	State       LDAPSyncState   `json:"state"`
+	Error       string          `json:"error,omitempty"`
+	Started     time.Time       `json:"started"`
+	Finished    *time.Time      `json:"finished,omitempty"`
+
+	Users    int `json:"users"`
+	Synced   int `json:"synced"`
+	Disabled int `json:"disabled"`
+	Enabled  int `json:"enabled"`
+	Failed   int `json:"failed"`
+
+	Report []LDAPSyncUserResult `json:"report,omitempty"`
+}
+
+// ToDTO returns the DTO of the run, with its report when withReport is true.
+func (r *LDAPSyncRun) ToDTO(withReport bool) *LDAPSyncRunDTO {
+	dto := &LDAPSyncRunDTO{
+		Id:          r.Id,
+		TriggeredBy: r.TriggeredBy,
+		State:       r.State,
+		Error:       r.Error,
+		Started:     r.Started,
+		Users:       r.Users,
+		Synced:      r.Synced,
+		Disabled:    r.Disabled,
+		Enabled:     r.Enabled,
+		Failed:      r.Failed,
+	}
+	if r.State != LDAPSyncRunning {
+		finished := r.Finished
+		dto.Finished = &finished
+	}
+	if withReport {
+		dto.Report = []LDAPSyncUserResult{}
+		if r.Report != "" {
+			// The report is written by the sync, a report which can't be read is left empty.
+			_ = json.Unmarshal([]byte(r.Report), &dto.Report)
+		}
+	}
+	return dto
+}
@@ -39,6 +39,16 @@ type SendWebhookSync struct {
 	ContentType string
 }
 
+// HTTPStatusError is returned when a notification is rejected by the receiving service with a non 2xx status code.
+type HTTPStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return e.Message
+}
+
 type SendResetPasswordEmailCommand struct {
 	User *User
 }
@@ -69,7 +69,23 @@ type SearchOrgsQuery struct {
 	Page  int
 	Ids   []int64
 
+	// Sort is by id or name. Cursor is the NextCursor of the previous page, which replaces the Page.
+	Sort   SearchSort
+	Cursor string
+
 	Result []*OrgDTO
+	// TotalCount is the number of orgs matching the query, and NextCursor the cursor of the next page, empty for
+	// the last page.
+	TotalCount int64
+	NextCursor string
+}
+
+type SearchOrgQueryResult struct {
+	TotalCount int64     `json:"totalCount"`
+	Orgs       []*OrgDTO `json:"orgs"`
+	Page       int       `json:"page"`
+	PerPage    int       `json:"perPage"`
+	NextCursor string    `json:"nextCursor,omitempty"`
 }
 
 type OrgDTO struct {
@@ -13,8 +13,10 @@ type Preferences struct {
 	HomeDashboardId int64
 	Timezone        string
 	Theme           string
-	Created         time.Time
-	Updated         time.Time
+	// QueryCachingDisabled turns the query caching off for the org, it is only read from the org preferences.
+	QueryCachingDisabled bool
+	Created              time.Time
+	Updated              time.Time
 }
 
 // ---------------------
@@ -42,7 +44,8 @@ type SavePreferencesCommand struct {
 	OrgId  int64
 	TeamId int64
 
-	HomeDashboardId int64  `json:"homeDashboardId"`
-	Timezone        string `json:"timezone"`
-	Theme           string `json:"theme"`
+	HomeDashboardId      int64  `json:"homeDashboardId"`
+	Timezone             string `json:"timezone"`
+	Theme                string `json:"theme"`
+	QueryCachingDisabled bool   `json:"queryCachingDisabled"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPublicDashboardNotFound            = errors.New("public dashboard not found")
+	ErrPublicDashboardPanelNotFound       = errors.New("panel not found in the public dashboard")
+	ErrPublicDashboardTimeRangeNotAllowed = errors.New("the time range is not allowed on the public dashboard")
+	ErrPublicDashboardInvalidTimeRange    = errors.New("the time ranges of a public dashboard must have a from and a to")
+)
+
+// PublicDashboard is the sharing configuration of a dashboard published at an unauthenticated URL. The queries of
+// the dashboard run with the data source permissions of the user who published it, within the dashboard time range
+// or one of the allowed time ranges.
+type PublicDashboard struct {
+	Id           int64                      `json:"id"`
+	OrgId        int64                      `json:"orgId"`
+	DashboardUid string                     `json:"dashboardUid"`
+	AccessToken  string                     `json:"accessToken"`
+	IsEnabled    bool                       `json:"isEnabled"`
+	TimeRanges   []PublicDashboardTimeRange `json:"timeRanges"`
+	CreatedBy    int64                      `json:"createdBy"`
+	Created      time.Time                  `json:"created"`
+	Updated      time.Time                  `json:"updated"`
+}
+
+// PublicDashboardTimeRange is a time range the viewers of a public dashboard may select, such as from now-24h to
+// now.
+type PublicDashboardTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SavePublicDashboardCommand enables or disables the public dashboard of a dashboard, which is created with a new
+// access token the first time.
+type SavePublicDashboardCommand struct {
+	IsEnabled  bool                       `json:"isEnabled"`
+	TimeRanges []PublicDashboardTimeRange `json:"timeRanges"`
+
+	DashboardUid string `json:"-"`
+	OrgId        int64  `json:"-"`
+	UserId       int64  `json:"-"`
+}
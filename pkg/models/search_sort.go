@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidSearchSort   = errors.New("invalid sort, expected a sortable field with an optional -asc or -desc suffix")
+	ErrInvalidSearchCursor = errors.New("invalid cursor, it must be the cursor of a previous page with the same sort")
+)
+
+// SearchSort is the order of the results of a search of users, orgs or teams. The results are also ordered by
+// id, after the field, so that the order is stable for the cursors.
+type SearchSort struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSearchSort parses a sort like "login", "login-asc" or "lastSeenAt-desc". The sortable fields depend on the
+// search, an empty string is the default sort of the search.
+func ParseSearchSort(s string) (SearchSort, error) {
+	field := s
+	descending := false
+	if i := strings.LastIndex(s, "-"); i >= 0 {
+		field = s[:i]
+		switch s[i+1:] {
+		case "asc":
+		case "desc":
+			descending = true
+		default:
+			return SearchSort{}, ErrInvalidSearchSort
+		}
+	}
+	if s != "" && field == "" {
+		return SearchSort{}, ErrInvalidSearchSort
+	}
+	return SearchSort{Field: field, Descending: descending}, nil
+}
+
+func (s SearchSort) String() string {
+	if s.Descending {
+		return s.Field + "-desc"
+	}
+	return s.Field + "-asc"
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrServiceAccountNotFound      = errors.New("service account not found")
+	ErrServiceAccountAlreadyExists = errors.New("service account with the same name already exists")
+)
+
+// ServiceAccount is a user of an automation, which belongs to one org and authenticates with its tokens. The
+// requests authenticated with the tokens of a service account are attributed to it, and have its role in the org.
+type ServiceAccount struct {
+	Id         int64
+	OrgId      int64
+	Name       string
+	Login      string
+	Role       RoleType
+	IsDisabled bool
+	Created    time.Time
+	Updated    time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateServiceAccountCommand struct {
+	Name  string   `json:"name" binding:"Required"`
+	Role  RoleType `json:"role" binding:"Required"`
+	OrgId int64    `json:"-"`
+}
+
+// UpdateServiceAccountCommand updates the fields of a service account which are set.
+type UpdateServiceAccountCommand struct {
+	Name       *string   `json:"name"`
+	Role       *RoleType `json:"role"`
+	IsDisabled *bool     `json:"isDisabled"`
+
+	Id    int64 `json:"-"`
+	OrgId int64 `json:"-"`
+}
+
+type AddServiceAccountTokenCommand struct {
+	Name          string `json:"name" binding:"Required"`
+	SecondsToLive int64  `json:"secondsToLive"`
+}
+
+type RotateServiceAccountTokenCommand struct {
+	SecondsToLive int64 `json:"secondsToLive"`
+}
+
+// ------------------------
+// DTO & Projections
+
+type ServiceAccountDTO struct {
+	Id         int64     `json:"id"`
+	OrgId      int64     `json:"orgId"`
+	Name       string    `json:"name"`
+	Login      string    `json:"login"`
+	Role       RoleType  `json:"role"`
+	IsDisabled bool      `json:"isDisabled"`
+	Tokens     int64     `json:"tokens"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+}
+
+type ServiceAccountTokenDTO struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Created    time.Time  `json:"created"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	HasExpired bool       `json:"hasExpired"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
@@ -73,6 +73,10 @@ type SearchTeamsQuery struct {
 	SignedInUser *SignedInUser
 	HiddenUsers  map[string]struct{}
 
+	// Sort is by name or email. Cursor is the NextCursor of the previous page, which replaces the Page.
+	Sort   SearchSort
+	Cursor string
+
 	Result SearchTeamQueryResult
 }
 
@@ -91,6 +95,7 @@ type SearchTeamQueryResult struct {
 	Teams      []*TeamDTO `json:"teams"`
 	Page       int        `json:"page"`
 	PerPage    int        `json:"perPage"`
+	NextCursor string     `json:"nextCursor,omitempty"`
 }
 
 type IsAdminOfTeamsQuery struct {
@@ -32,6 +32,8 @@ type User struct {
 	Theme         string
 	HelpFlags1    HelpFlags1
 	IsDisabled    bool
+	// IsServiceAccount is set on the service accounts, which authenticate with their tokens and can't log in.
+	IsServiceAccount bool
 
 	IsAdmin bool
 	OrgId   int64
@@ -144,6 +146,20 @@ type SearchUsersQuery struct {
 	Limit      int
 	AuthModule string
 
+	// Login and Email filter the users with the exact login or email.
+	Login          string
+	Email          string
+	LastSeenAfter  time.Time
+	LastSeenBefore time.Time
+	// Role filters the users with the role in the org RoleOrgId.
+	Role      RoleType
+	RoleOrgId int64
+
+	// Sort is by login, email, name or lastSeenAt. Cursor is the NextCursor of the previous page, which replaces
+	// the Page.
+	Sort   SearchSort
+	Cursor string
+
 	IsDisabled *bool
 
 	Result SearchUserQueryResult
@@ -154,6 +170,7 @@ type SearchUserQueryResult struct {
 	Users      []*UserSearchHitDTO `json:"users"`
 	Page       int                 `json:"page"`
 	PerPage    int                 `json:"perPage"`
+	NextCursor string              `json:"nextCursor,omitempty"`
 }
 
 type GetUserOrgListQuery struct {
@@ -165,20 +182,23 @@ type GetUserOrgListQuery struct {
 // DTO & Projections
 
 type SignedInUser struct {
-	UserId         int64
-	OrgId          int64
-	OrgName        string
-	OrgRole        RoleType
-	Login          string
-	Name           string
-	Email          string
-	ApiKeyId       int64
-	OrgCount       int
-	IsGrafanaAdmin bool
-	IsAnonymous    bool
-	HelpFlags1     HelpFlags1
-	LastSeenAt     time.Time
-	Teams          []int64
+	UserId           int64
+	OrgId            int64
+	OrgName          string
+	OrgRole          RoleType
+	Login            string
+	Name             string
+	Email            string
+	ApiKeyId         int64
+	ApiKeyScopes     []string
+	OrgCount         int
+	IsGrafanaAdmin   bool
+	IsAnonymous      bool
+	IsDisabled       bool
+	IsServiceAccount bool
+	HelpFlags1       HelpFlags1
+	LastSeenAt       time.Time
+	Teams            []int64
 }
 
 func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
@@ -9,6 +9,11 @@ import (
 
 const (
 	AuthModuleLDAP = "ldap"
+	// AuthModuleSCIM is the auth module of the users provisioned with SCIM, with their external id as auth id.
+	AuthModuleSCIM = "scim"
+	// AuthModuleSAML is the auth module of the users logged in with SAML, with the NameID of their assertion as auth
+	// id.
+	AuthModuleSAML = "auth.saml"
 )
 
 type UserAuth struct {
@@ -21,6 +26,7 @@ type UserAuth struct {
 	OAuthRefreshToken string
 	OAuthTokenType    string
 	OAuthExpiry       time.Time
+	OAuthIdToken      string
 }
 
 type ExternalUserInfo struct {
@@ -35,6 +41,7 @@ type ExternalUserInfo struct {
 	OrgRoles       map[int64]RoleType
 	IsGrafanaAdmin *bool // This is a pointer to know if we should sync this or not (nil = ignore sync)
 	IsDisabled     bool
+	Teams          []string // The names of the teams of the user in its orgs (nil = ignore sync)
 }
 
 type LoginInfo struct {
@@ -61,6 +61,31 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+// RevokeAuthTokensCmd revokes several user auth tokens, of any users.
+type RevokeAuthTokensCmd struct {
+	AuthTokenIds []int64 `json:"authTokenIds"`
+}
+
+// ForceReauthenticationCmd revokes all the auth tokens of the users and of the members of the org, so that they have
+// to log in again.
+type ForceReauthenticationCmd struct {
+	UserIds []int64 `json:"userIds"`
+	OrgId   int64   `json:"orgId"`
+}
+
+// SearchUserTokensQuery lists the active user auth tokens, of the user and of the members of the org when they're set.
+type SearchUserTokensQuery struct {
+	UserId int64
+	OrgId  int64
+	Page   int
+	Limit  int
+}
+
+type SearchUserTokensResult struct {
+	TotalCount int64
+	Tokens     []*UserToken
+}
+
 // UserTokenService are used for generating and validating user tokens
 type UserTokenService interface {
 	CreateToken(ctx context.Context, user *User, clientIP net.IP, userAgent string) (*UserToken, error)
@@ -72,4 +97,7 @@ type UserTokenService interface {
 	GetUserToken(ctx context.Context, userId, userTokenId int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userId int64) ([]*UserToken, error)
 	GetUserRevokedTokens(ctx context.Context, userId int64) ([]*UserToken, error)
+	SearchUserTokens(ctx context.Context, query *SearchUserTokensQuery) (*SearchUserTokensResult, error)
+	RevokeTokens(ctx context.Context, userTokenIds []int64) (int64, error)
+	BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error
 }
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventDashboardSaved    WebhookEventType = "dashboard.saved"
+	WebhookEventDataSourceCreated WebhookEventType = "datasource.created"
+	WebhookEventUserAdded         WebhookEventType = "user.added"
+	WebhookEventAlertRuleChanged  WebhookEventType = "alert_rule.changed"
+)
+
+// WebhookEventTypes are the types of the events the webhooks can subscribe to.
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventDashboardSaved,
+	WebhookEventDataSourceCreated,
+	WebhookEventUserAdded,
+	WebhookEventAlertRuleChanged,
+}
+
+func (t WebhookEventType) IsValid() bool {
+	for _, eventType := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryState string
+
+const (
+	// WebhookDeliveryPending is the state of the deliveries waiting for their first or next attempt.
+	WebhookDeliveryPending WebhookDeliveryState = "pending"
+	// WebhookDeliverySucceeded is the state of the deliveries the receiver answered with a 2xx status.
+	WebhookDeliverySucceeded WebhookDeliveryState = "succeeded"
+	// WebhookDeliveryDeadLetter is the state of the deliveries which failed all their attempts, they're only
+	// attempted again when they're redelivered.
+	WebhookDeliveryDeadLetter WebhookDeliveryState = "dead_letter"
+)
+
+func (s WebhookDeliveryState) IsValid() bool {
+	return s == WebhookDeliveryPending || s == WebhookDeliverySucceeded || s == WebhookDeliveryDeadLetter
+}
+
+// Webhook is a subscription of an org to events, which are posted to its URL signed with its secret. The secret is
+// the "secret" key of the secure settings.
+type Webhook struct {
+	Id             int64
+	OrgId          int64
+	Name           string
+	Url            string
+	Events         []WebhookEventType
+	SecureSettings securejsondata.SecureJsonData
+	IsEnabled      bool
+	Created        time.Time
+	Updated        time.Time
+}
+
+// Subscribes returns whether the webhook is enabled and subscribed to the events of the type.
+func (w *Webhook) Subscribes(eventType WebhookEventType) bool {
+	if !w.IsEnabled {
+		return false
+	}
+	for _, t := range w.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the delivery of an event to a webhook, along with the result of its last attempt.
+type WebhookDelivery struct {
+	Id             int64
+	OrgId          int64
+	WebhookId      int64
+	Uid            string
+	EventType      WebhookEventType
+	Payload        string
+	State          WebhookDeliveryState
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	LastDurationMs int64
+	Created        time.Time
+	Updated        time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+// CreateWebhookCommand creates a webhook, with a generated secret when none is set.
+type CreateWebhookCommand struct {
+	Name      string             `json:"name" binding:"Required"`
+	Url       string             `json:"url" binding:"Required"`
+	Events    []WebhookEventType `json:"events" binding:"Required"`
+	Secret    string             `json:"secret"`
+	IsEnabled *bool              `json:"isEnabled"`
+
+	OrgId int64 `json:"-"`
+}
+
+// UpdateWebhookCommand updates the fields of a webhook which are set.
+type UpdateWebhookCommand struct {
+	Name      *string            `json:"name"`
+	Url       *string            `json:"url"`
+	Events    []WebhookEventType `json:"events"`
+	Secret    *string            `json:"secret"`
+	IsEnabled *bool              `json:"isEnabled"`
+
+	Id    int64 `json:"-"`
+	OrgId int64 `json:"-"`
+}
+
+// ---------------------
+// QUERIES
+
+type SearchWebhookDeliveriesQuery struct {
+	OrgId     int64
+	WebhookId int64
+	State     WebhookDeliveryState
+	EventType WebhookEventType
+	Page      int
+	PerPage   int
+}
+
+// ------------------------
+// DTO & Projections
+
+type WebhookDTO struct {
+	Id        int64              `json:"id"`
+	OrgId     int64              `json:"orgId"`
+	Name      string             `json:"name"`
+	Url       string             `json:"url"`
+	Events    []WebhookEventType `json:"events"`
+	IsEnabled bool               `json:"isEnabled"`
+	Created   time.Time          `json:"created"`
+	Updated   time.Time          `json:"updated"`
+}
+
+// CreatedWebhookDTO is a created webhook along with its secret, which isn't returned again.
+type CreatedWebhookDTO struct {
+	WebhookDTO
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryDTO struct {
+	Id             int64                `json:"id"`
+	WebhookId      int64                `json:"webhookId"`
+	Uid            string               `json:"uid"`
+	EventType      WebhookEventType     `json:"eventType"`
+	Payload        json.RawMessage      `json:"payload"`
+	State          WebhookDeliveryState `json:"state"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  *time.Time           `json:"nextAttemptAt,omitempty"`
+	LastStatusCode int                  `json:"lastStatusCode,omitempty"`
+	LastError      string               `json:"lastError,omitempty"`
+	LastDurationMs int64                `json:"lastDurationMs,omitempty"`
+	Created        time.Time            `json:"created"`
+	Updated        time.Time            `json:"updated"`
+}
+
+type SearchWebhookDeliveriesResult struct {
+	TotalCount int64                 `json:"totalCount"`
+	Deliveries []*WebhookDeliveryDTO `json:"deliveries"`
+	Page       int                   `json:"page"`
+	PerPage    int                   `json:"perPage"`
+}
+
+func (w *Webhook) ToDTO() *WebhookDTO {
+	return &WebhookDTO{
+		Id:        w.Id,
+		OrgId:     w.OrgId,
+		Name:      w.Name,
+		Url:       w.Url,
+		Events:    w.Events,
+		IsEnabled: w.IsEnabled,
+		Created:   w.Created,
+		Updated:   w.Updated,
+	}
+}
+
+func (d *WebhookDelivery) ToDTO() *WebhookDeliveryDTO {
+	dto := &WebhookDeliveryDTO{
+		Id:             d.Id,
+		WebhookId:      d.WebhookId,
+		Uid:            d.Uid,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		State:          d.State,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		LastDurationMs: d.LastDurationMs,
+		Created:        d.Created,
+		Updated:        d.Updated,
+	}
+	if d.State == WebhookDeliveryPending {
+		nextAttemptAt := d.NextAttemptAt
+		dto.NextAttemptAt = &nextAttemptAt
+	}
+	return dto
+}
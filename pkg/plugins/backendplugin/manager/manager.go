@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/instrumentation"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
@@ -321,6 +322,30 @@ type keepCookiesJSONModel struct {
 	KeepCookies []string `json:"keepCookies"`
 }
 
+type oauthPassThruJSONModel struct {
+	OAuthPassThru bool `json:"oauthPassThru"`
+}
+
+// applyOAuthPassThru sets the OAuth token of the user in the headers of the resource calls of the data sources
+// which forward the OAuth identity of the users, like their queries.
+func applyOAuthPassThru(req *http.Request, jsonData []byte, user *models.SignedInUser) {
+	model := oauthPassThruJSONModel{}
+	if err := json.Unmarshal(jsonData, &model); err != nil || !model.OAuthPassThru {
+		return
+	}
+
+	// the OAuth identity of the user is only forwarded from its token, never from the headers of the client
+	req.Header.Del("Authorization")
+	req.Header.Del("X-ID-Token")
+	if token := oauthtoken.GetCurrentOAuthToken(req.Context(), user); token != nil {
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", token.Type(), token.AccessToken))
+
+		if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+			req.Header.Set("X-ID-Token", idToken)
+		}
+	}
+}
+
 func (m *manager) callResourceInternal(w http.ResponseWriter, req *http.Request, pCtx backend.PluginContext) error {
 	p, registered := m.Get(pCtx.PluginID)
 	if !registered {
@@ -405,6 +430,9 @@ func (m *manager) CallResource(pCtx backend.PluginContext, reqCtx *models.ReqCon
 		return
 	}
 	clonedReq.URL = urlPath
+	if dis := pCtx.DataSourceInstanceSettings; dis != nil {
+		applyOAuthPassThru(clonedReq, dis.JSONData, reqCtx.SignedInUser)
+	}
 	err = m.callResourceInternal(reqCtx.Resp, clonedReq, pCtx)
 	if err != nil {
 		handleCallResourceError(err, reqCtx)
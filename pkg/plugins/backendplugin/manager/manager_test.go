@@ -513,3 +513,27 @@ type testPluginRequestValidator struct{}
 func (t *testPluginRequestValidator) Validate(string, *http.Request) error {
 	return nil
 }
+
+func TestApplyOAuthPassThru(t *testing.T) {
+	newRequest := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "/api/plugins/test/resources", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer client")
+		req.Header.Set("X-ID-Token", "client")
+		return req
+	}
+
+	t.Run("Keeps the headers when OAuth pass-through is disabled", func(t *testing.T) {
+		req := newRequest(t)
+		applyOAuthPassThru(req, []byte(`{}`), nil)
+		require.Equal(t, "Bearer client", req.Header.Get("Authorization"))
+		require.Equal(t, "client", req.Header.Get("X-ID-Token"))
+	})
+
+	t.Run("Removes the headers of the client when the user has no OAuth token", func(t *testing.T) {
+		req := newRequest(t)
+		applyOAuthPassThru(req, []byte(`{"oauthPassThru":true}`), nil)
+		require.Empty(t, req.Header.Get("Authorization"))
+		require.Empty(t, req.Header.Get("X-ID-Token"))
+	})
+}
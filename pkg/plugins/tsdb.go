@@ -223,6 +223,7 @@ func (r DataResponse) ToBackendDataResponse() (*backend.QueryDataResponse, error
 				return nil, err
 			}
 			frame.RefID = refID
+			frame.Meta = seriesFrameMeta(res.Meta)
 			pRes.Frames = append(pRes.Frames, frame)
 		}
 
@@ -231,6 +232,39 @@ func (r DataResponse) ToBackendDataResponse() (*backend.QueryDataResponse, error
 	return qdr, nil
 }
 
+const (
+	metaKeyExecutedQueryString = "executedQueryString"
+	metaKeyStats               = "stats"
+)
+
+// SetQueryStats sets the query stats in the meta of a legacy result, for the frames of its series.
+func SetQueryStats(meta *simplejson.Json, stats []data.QueryStat) *simplejson.Json {
+	if meta == nil {
+		meta = simplejson.New()
+	}
+	meta.Set(metaKeyStats, stats)
+	return meta
+}
+
+// seriesFrameMeta returns the frame meta of the series of a legacy result, with its executed query and stats.
+func seriesFrameMeta(meta *simplejson.Json) *data.FrameMeta {
+	if meta == nil {
+		return nil
+	}
+
+	frameMeta := &data.FrameMeta{ExecutedQueryString: meta.Get(metaKeyExecutedQueryString).MustString()}
+	// The stats are set by SetQueryStats or decoded from a cached result.
+	if stats, ok := meta.CheckGet(metaKeyStats); ok {
+		if b, err := stats.MarshalJSON(); err == nil {
+			_ = json.Unmarshal(b, &frameMeta.Stats)
+		}
+	}
+	if frameMeta.ExecutedQueryString == "" && frameMeta.Stats == nil {
+		return nil
+	}
+	return frameMeta
+}
+
 // Deprecated: use the plugin SDK
 type DataPlugin interface {
 	DataQuery(ctx context.Context, ds *models.DataSource, query DataQuery) (DataResponse, error)
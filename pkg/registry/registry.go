@@ -129,6 +129,14 @@ type BackgroundService interface {
 	Run(ctx context.Context) error
 }
 
+// ShutdownHook should be implemented by background services that need to
+// stop gracefully. Shutdown is called when Grafana shuts down, after the HTTP
+// server has drained the requests in flight and before the context passed to
+// Run is canceled, so the service can finish its work in progress.
+type ShutdownHook interface {
+	Shutdown(ctx context.Context) error
+}
+
 // DatabaseMigrator allows the caller to add migrations to
 // the migrator passed as argument
 type DatabaseMigrator interface {
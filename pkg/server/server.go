@@ -33,9 +33,12 @@ import (
 	_ "github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/registry"
 	_ "github.com/grafana/grafana/pkg/services/alerting"
+	_ "github.com/grafana/grafana/pkg/services/audit"
 	_ "github.com/grafana/grafana/pkg/services/auth"
 	_ "github.com/grafana/grafana/pkg/services/auth/jwt"
 	_ "github.com/grafana/grafana/pkg/services/cleanup"
+	_ "github.com/grafana/grafana/pkg/services/grpcadmin"
+	_ "github.com/grafana/grafana/pkg/services/ldapsync"
 	_ "github.com/grafana/grafana/pkg/services/librarypanels"
 	_ "github.com/grafana/grafana/pkg/services/login/loginservice"
 	_ "github.com/grafana/grafana/pkg/services/ngalert"
@@ -44,6 +47,7 @@ import (
 	_ "github.com/grafana/grafana/pkg/services/rendering"
 	_ "github.com/grafana/grafana/pkg/services/search"
 	_ "github.com/grafana/grafana/pkg/services/sqlstore"
+	_ "github.com/grafana/grafana/pkg/services/webhooks"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -73,6 +77,9 @@ func (r *globalServiceRegistry) GetServices() []*registry.Descriptor {
 	return registry.GetServices()
 }
 
+// servicesStopTimeout is how long the background services are given to stop once their context is canceled.
+const servicesStopTimeout = 30 * time.Second
+
 // New returns a new instance of Server.
 func New(cfg Config) (*Server, error) {
 	s := newServer(cfg)
@@ -220,13 +227,19 @@ func (s *Server) Run() error {
 	return s.childRoutines.Wait()
 }
 
-// Shutdown initiates Grafana graceful shutdown. This shuts down all
-// running background services. Since Run blocks Shutdown supposed to
-// be run from a separate goroutine.
+// Shutdown initiates Grafana graceful shutdown. This drains the requests
+// in flight, calls the shutdown hooks of the background services, and then
+// shuts down all running background services. Since Run blocks Shutdown
+// supposed to be run from a separate goroutine.
 func (s *Server) Shutdown(ctx context.Context, reason string) error {
 	var err error
 	s.shutdownOnce.Do(func() {
 		s.log.Info("Shutdown started", "reason", reason)
+		// Drain the requests before stopping the services they depend on.
+		if s.HTTPServer != nil {
+			s.HTTPServer.Drain(ctx)
+		}
+		s.runShutdownHooks(ctx)
 		// Call cancel func to stop services.
 		s.shutdownFn()
 		// Wait for server to shut down
@@ -242,6 +255,34 @@ func (s *Server) Shutdown(ctx context.Context, reason string) error {
 	return err
 }
 
+// ShutdownTimeout returns how long a graceful shutdown may take: the shutdown delay and the grace period of the
+// HTTP server, and then the time given to the background services to stop.
+func (s *Server) ShutdownTimeout() time.Duration {
+	return s.cfg.ShutdownDelay + s.cfg.ShutdownGracePeriod + servicesStopTimeout
+}
+
+// runShutdownHooks calls the shutdown hooks of the running background services concurrently, and waits for them.
+func (s *Server) runShutdownHooks(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, svc := range s.serviceRegistry.GetServices() {
+		hook, ok := svc.Instance.(registry.ShutdownHook)
+		if !ok || s.serviceRegistry.IsDisabled(svc.Instance) {
+			continue
+		}
+
+		name := svc.Name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.log.Debug("Shutting down "+name)
+			if err := hook.Shutdown(ctx); err != nil {
+				s.log.Error("Failed to shut down "+name, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 // ExitCode returns an exit code for a given error.
 func (s *Server) ExitCode(runError error) int {
 	if runError != nil {
@@ -124,3 +124,47 @@ func TestServer_Shutdown(t *testing.T) {
 	err = <-ch
 	require.NoError(t, err)
 }
+
+type testShutdownHookService struct {
+	*testService
+	// stoppedBeforeHook is whether the context passed to Run was done when the hook was called.
+	stoppedBeforeHook bool
+	ctx               context.Context
+}
+
+func (s *testShutdownHookService) Run(ctx context.Context) error {
+	s.ctx = ctx
+	return s.testService.Run(ctx)
+}
+
+func (s *testShutdownHookService) Shutdown(_ context.Context) error {
+	s.stoppedBeforeHook = s.ctx.Err() != nil
+	return nil
+}
+
+func TestServer_Shutdown_Hooks(t *testing.T) {
+	s := testServer()
+	service := &testShutdownHookService{testService: newTestService(nil, nil)}
+	s.serviceRegistry = &testServiceRegistry{
+		services: []*registry.Descriptor{
+			{
+				Name:         "TestService",
+				Instance:     service,
+				InitPriority: registry.High,
+			},
+		},
+	}
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		<-service.started
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		ch <- s.Shutdown(ctx, "test interrupt")
+	}()
+	require.NoError(t, s.Run())
+	require.NoError(t, <-ch)
+	require.False(t, service.stoppedBeforeHook)
+	require.Error(t, service.ctx.Err())
+}
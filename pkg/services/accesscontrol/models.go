@@ -86,6 +86,13 @@ const (
 	// Settings actions
 	ActionSettingsRead = "settings:read"
 
+	// Alerting actions
+	ActionAlertingRuleRead           = "alert.rules:read"
+	ActionAlertingRuleWrite          = "alert.rules:write"
+	ActionAlertingNotificationsWrite = "alert.notifications:write"
+	ActionAlertingProvisioningRead   = "alert.provisioning:read"
+	ActionAlertingProvisioningWrite  = "alert.provisioning:write"
+
 	// Global Scopes
 	ScopeGlobalUsersAll = "global:users:*"
 
@@ -98,6 +105,10 @@ const (
 
 	// Services Scopes
 	ScopeServicesAll = "service:*"
+
+	// Folders scopes, the scope of a folder is the prefix followed by its UID
+	ScopeFoldersPrefix = "folders:uid:"
+	ScopeFoldersAll    = ScopeFoldersPrefix + "*"
 )
 
 const RoleGrafanaAdmin = "Grafana Admin"
@@ -98,6 +98,46 @@ func TestEvaluatingPermissions(t *testing.T) {
 			},
 			evalResult: false,
 		},
+		{
+			desc: "should allow viewers to read the alert rules of a folder",
+			user: userTestCase{
+				name:           "testuser",
+				orgRole:        models.ROLE_VIEWER,
+				isGrafanaAdmin: false,
+			},
+			endpoints: []endpointTestCase{
+				{permission: accesscontrol.ActionAlertingRuleRead, scope: []string{accesscontrol.ScopeFoldersPrefix + "folder"}},
+			},
+			evalResult: true,
+		},
+		{
+			desc: "should restrict viewers from changing alert rules and provisioning",
+			user: userTestCase{
+				name:           "testuser",
+				orgRole:        models.ROLE_VIEWER,
+				isGrafanaAdmin: false,
+			},
+			endpoints: []endpointTestCase{
+				{permission: accesscontrol.ActionAlertingRuleWrite, scope: []string{accesscontrol.ScopeFoldersPrefix + "folder"}},
+				{permission: accesscontrol.ActionAlertingNotificationsWrite},
+				{permission: accesscontrol.ActionAlertingProvisioningRead},
+			},
+			evalResult: false,
+		},
+		{
+			desc: "should allow admins to change alert rules and provisioning",
+			user: userTestCase{
+				name:           "testuser",
+				orgRole:        models.ROLE_ADMIN,
+				isGrafanaAdmin: false,
+			},
+			endpoints: []endpointTestCase{
+				{permission: accesscontrol.ActionAlertingRuleWrite, scope: []string{accesscontrol.ScopeFoldersPrefix + "folder"}},
+				{permission: accesscontrol.ActionAlertingNotificationsWrite},
+				{permission: accesscontrol.ActionAlertingProvisioningWrite},
+			},
+			evalResult: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -159,6 +159,58 @@ var provisioningAdminRole = RoleDTO{
 	},
 }
 
+var alertingRulesReaderRole = RoleDTO{
+	Name:    alertingRulesReader,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionAlertingRuleRead,
+			Scope:  ScopeFoldersAll,
+		},
+	},
+}
+
+var alertingRulesWriterRole = RoleDTO{
+	Name:    alertingRulesWriter,
+	Version: 1,
+	Permissions: ConcatPermissions(alertingRulesReaderRole.Permissions, []Permission{
+		{
+			Action: ActionAlertingRuleWrite,
+			Scope:  ScopeFoldersAll,
+		},
+	}),
+}
+
+var alertingNotificationsWriterRole = RoleDTO{
+	Name:    alertingNotificationsWriter,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionAlertingNotificationsWrite,
+		},
+	},
+}
+
+var alertingProvisioningReaderRole = RoleDTO{
+	Name:    alertingProvisioningReader,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionAlertingProvisioningRead,
+		},
+	},
+}
+
+var alertingProvisioningWriterRole = RoleDTO{
+	Name:    alertingProvisioningWriter,
+	Version: 1,
+	Permissions: ConcatPermissions(alertingProvisioningReaderRole.Permissions, []Permission{
+		{
+			Action: ActionAlertingProvisioningWrite,
+		},
+	}),
+}
+
 // FixedRoles provides a map of permission sets/roles which can be
 // assigned to a set of users. When adding a new resource protected by
 // Grafana access control the default permissions should be added to a
@@ -180,6 +232,12 @@ var FixedRoles = map[string]RoleDTO{
 	ldapAdminEdit: ldapAdminEditRole,
 
 	provisioningAdmin: provisioningAdminRole,
+
+	alertingRulesReader:         alertingRulesReaderRole,
+	alertingRulesWriter:         alertingRulesWriterRole,
+	alertingNotificationsWriter: alertingNotificationsWriterRole,
+	alertingProvisioningReader:  alertingProvisioningReaderRole,
+	alertingProvisioningWriter:  alertingProvisioningWriterRole,
 }
 
 const (
@@ -197,6 +255,12 @@ const (
 	ldapAdminRead = "fixed:ldap:admin:read"
 
 	provisioningAdmin = "fixed:provisioning:admin"
+
+	alertingRulesReader         = "fixed:alerting:rules:reader"
+	alertingRulesWriter         = "fixed:alerting:rules:writer"
+	alertingNotificationsWriter = "fixed:alerting:notifications:writer"
+	alertingProvisioningReader  = "fixed:alerting:provisioning:reader"
+	alertingProvisioningWriter  = "fixed:alerting:provisioning:writer"
 )
 
 // FixedRoleGrants specifies which built-in roles are assigned
@@ -217,6 +281,14 @@ var FixedRoleGrants = map[string][]string{
 		usersOrgEdit,
 		usersOrgRead,
 	},
+	string(models.ROLE_EDITOR): {
+		alertingNotificationsWriter,
+		alertingProvisioningWriter,
+		alertingRulesWriter,
+	},
+	string(models.ROLE_VIEWER): {
+		alertingRulesReader,
+	},
 }
 
 func ConcatPermissions(permissions ...[]Permission) []Permission {
@@ -0,0 +1,237 @@
+// Package audit records the changes made by the users in the audit log.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	diff "github.com/yudai/gojsondiff"
+	deltaFormatter "github.com/yudai/gojsondiff/formatter"
+)
+
+const (
+	// streamBufferSize is the max number of entries waiting to be streamed, the entries over it are only stored
+	// in the database.
+	streamBufferSize = 1000
+	redactedValue    = "[redacted]"
+)
+
+// secretKeyRegexp matches the keys of the secret values, which are redacted from the audit log.
+var secretKeyRegexp = regexp.MustCompile(`(?i)password|secret|token|securejson|api_?key`)
+
+type AuditService struct {
+	Cfg      *setting.Cfg       `inject:""`
+	SQLStore *sqlstore.SQLStore `inject:""`
+
+	log     log.Logger
+	file    *os.File
+	loki    *lokiClient
+	entries chan *models.AuditEntryDTO
+}
+
+func init() {
+	registry.RegisterService(&AuditService{})
+}
+
+func (s *AuditService) Init() error {
+	s.log = log.New("audit")
+	if !s.Cfg.Audit.Enabled {
+		return nil
+	}
+
+	if s.Cfg.Audit.FilePath != "" {
+		file, err := os.OpenFile(s.Cfg.Audit.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return err
+		}
+		s.file = file
+	}
+	if s.Cfg.Audit.LokiURL != "" {
+		s.loki = newLokiClient(s.Cfg.Audit.LokiURL)
+	}
+	s.entries = make(chan *models.AuditEntryDTO, streamBufferSize)
+
+	bus.AddHandlerCtx("audit", s.recordEntry)
+	return nil
+}
+
+func (s *AuditService) IsDisabled() bool {
+	return !s.Cfg.Audit.Enabled
+}
+
+// Run streams the entries of the audit log to the file and to Loki.
+func (s *AuditService) Run(ctx context.Context) error {
+	if s.file != nil {
+		defer func() {
+			if err := s.file.Close(); err != nil {
+				s.log.Warn("Failed to close audit log file", "error", err)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case entry := <-s.entries:
+			s.writeToFile(entry)
+			if s.loki != nil {
+				s.loki.add(entry)
+			}
+		case <-ticker.C:
+			if s.loki != nil {
+				s.loki.push(ctx, s.log)
+			}
+		case <-ctx.Done():
+			if s.loki != nil {
+				s.loki.push(context.Background(), s.log)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *AuditService) recordEntry(ctx context.Context, cmd *models.RecordAuditEntryCommand) error {
+	before, err := redactedJSON(cmd.Before)
+	if err != nil {
+		return err
+	}
+	after, err := redactedJSON(cmd.After)
+	if err != nil {
+		return err
+	}
+	delta, err := jsonDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := &models.AuditEntry{
+		OrgId:        cmd.OrgId,
+		UserId:       cmd.UserId,
+		UserLogin:    cmd.UserLogin,
+		Action:       cmd.Action,
+		ResourceType: cmd.ResourceType,
+		ResourceId:   cmd.ResourceId,
+		ResourceName: cmd.ResourceName,
+		Before:       before,
+		After:        after,
+		Diff:         delta,
+		Created:      time.Now(),
+	}
+	if err := s.SQLStore.AddAuditEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	if s.file != nil || s.loki != nil {
+		select {
+		case s.entries <- entry.ToDTO():
+		default:
+			s.log.Warn("Audit log stream buffer is full, the entry is only stored in the database", "id", entry.Id)
+		}
+	}
+	return nil
+}
+
+func (s *AuditService) writeToFile(entry *models.AuditEntryDTO) {
+	if s.file == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		s.log.Error("Failed to encode audit entry", "id", entry.Id, "error", err)
+		return
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		s.log.Error("Failed to write audit entry to file", "id", entry.Id, "error", err)
+	}
+}
+
+// redactedJSON returns the JSON encoding of the state of a resource without its secret values, or an empty string
+// for nil.
+func redactedJSON(state interface{}) (string, error) {
+	if state == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+	if value == nil {
+		return "", nil
+	}
+
+	data, err = json.Marshal(redact(value))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if secretKeyRegexp.MatchString(key) {
+				if item != nil && item != "" {
+					v[key] = redactedValue
+				}
+				continue
+			}
+			v[key] = redact(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+// jsonDiff returns the jsondiffpatch delta of the JSON objects, or an empty string when they're equal.
+func jsonDiff(before, after string) (string, error) {
+	left, err := jsonObject(before)
+	if err != nil {
+		return "", err
+	}
+	right, err := jsonObject(after)
+	if err != nil {
+		return "", err
+	}
+
+	d := diff.New().CompareObjects(left, right)
+	if !d.Modified() {
+		return "", nil
+	}
+	return deltaFormatter.NewDeltaFormatter().Format(d)
+}
+
+func jsonObject(data string) (map[string]interface{}, error) {
+	object := map[string]interface{}{}
+	if data == "" {
+		return object, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return nil, err
+	}
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, nil
+	}
+	// the states which aren't objects are compared as the value of an object
+	object["value"] = value
+	return object, nil
+}
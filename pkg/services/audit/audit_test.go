@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditService(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.Audit.Enabled = true
+	cfg.Audit.FilePath = filepath.Join(t.TempDir(), "audit.log")
+	service := &AuditService{Cfg: cfg, SQLStore: sqlStore}
+	require.NoError(t, service.Init())
+	ctx := context.Background()
+
+	cmd := models.RecordAuditEntryCommand{
+		OrgId: 1, UserId: 2, UserLogin: "editor",
+		Action: models.AuditActionUpdate, ResourceType: models.AuditResourceDataSource, ResourceId: "abc", ResourceName: "prometheus",
+		Before: map[string]interface{}{"url": "http://prometheus:9090", "basicAuthPassword": "pwd", "jsonData": map[string]interface{}{"apiKey": ""}},
+		After:  map[string]interface{}{"url": "http://prometheus:9091", "basicAuthPassword": "changed", "jsonData": map[string]interface{}{"apiKey": "key"}},
+	}
+	require.NoError(t, service.recordEntry(ctx, &cmd))
+
+	result, err := sqlStore.SearchAuditEntries(ctx, &models.SearchAuditEntriesQuery{OrgId: 1, Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	entry := result.Entries[0]
+
+	t.Run("Secrets are redacted", func(t *testing.T) {
+		require.JSONEq(t, `{"url": "http://prometheus:9090", "basicAuthPassword": "[redacted]", "jsonData": {"apiKey": ""}}`, string(entry.Before))
+		require.JSONEq(t, `{"url": "http://prometheus:9091", "basicAuthPassword": "[redacted]", "jsonData": {"apiKey": "[redacted]"}}`, string(entry.After))
+	})
+
+	t.Run("Diff has the changes", func(t *testing.T) {
+		require.JSONEq(t, `{"url": ["http://prometheus:9090", "http://prometheus:9091"], "jsonData": {"apiKey": ["", "[redacted]"]}}`, string(entry.Diff))
+	})
+
+	t.Run("Entries are written to the file", func(t *testing.T) {
+		service.writeToFile(<-service.entries)
+		require.NoError(t, service.file.Close())
+
+		data, err := ioutil.ReadFile(cfg.Audit.FilePath)
+		require.NoError(t, err)
+		var written models.AuditEntryDTO
+		require.NoError(t, json.Unmarshal(data, &written))
+		require.Equal(t, entry.Id, written.Id)
+		require.Equal(t, "editor", written.UserLogin)
+	})
+}
+
+func TestJSONDiff(t *testing.T) {
+	t.Run("Created resources have no before state", func(t *testing.T) {
+		delta, err := jsonDiff("", `{"title": "new"}`)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title": ["new"]}`, delta)
+	})
+
+	t.Run("Unchanged resources have no diff", func(t *testing.T) {
+		delta, err := jsonDiff(`{"title": "same"}`, `{"title": "same"}`)
+		require.NoError(t, err)
+		require.Empty(t, delta)
+	})
+}
+
+func TestLokiClient(t *testing.T) {
+	var requests []lokiPushRequest
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request lokiPushRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newLokiClient(server.URL)
+	client.add(&models.AuditEntryDTO{Id: 1, Action: models.AuditActionCreate, ResourceType: models.AuditResourceDashboard})
+	client.add(&models.AuditEntryDTO{Id: 2, Action: models.AuditActionCreate, ResourceType: models.AuditResourceDashboard})
+	client.add(&models.AuditEntryDTO{Id: 3, Action: models.AuditActionDelete, ResourceType: models.AuditResourceUser})
+
+	t.Run("Failed pushes are retried", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		client.push(context.Background(), log.New("test"))
+		require.Len(t, requests, 1)
+		require.Len(t, client.batch, 3)
+	})
+
+	t.Run("Entries are pushed in a stream for each action and resource type", func(t *testing.T) {
+		status = http.StatusNoContent
+		client.push(context.Background(), log.New("test"))
+		require.Len(t, requests, 2)
+		require.Empty(t, client.batch)
+
+		streams := requests[1].Streams
+		require.Len(t, streams, 2)
+		require.Equal(t, map[string]string{"job": "grafana_audit", "action": "create", "resource_type": "dashboard"}, streams[0].Stream)
+		require.Len(t, streams[0].Values, 2)
+		require.Equal(t, map[string]string{"job": "grafana_audit", "action": "delete", "resource_type": "user"}, streams[1].Stream)
+		require.Len(t, streams[1].Values, 1)
+	})
+}
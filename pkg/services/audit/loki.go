@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// maxLokiBatchSize is the max number of entries in a push to Loki, the entries over it are dropped when Loki
+// doesn't keep up.
+const maxLokiBatchSize = 10000
+
+// lokiClient pushes batches of the entries of the audit log to Loki, with a stream for each action and resource
+// type.
+type lokiClient struct {
+	url    string
+	client *http.Client
+	batch  []*models.AuditEntryDTO
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+func newLokiClient(url string) *lokiClient {
+	return &lokiClient{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *lokiClient) add(entry *models.AuditEntryDTO) {
+	if len(c.batch) >= maxLokiBatchSize {
+		c.batch = c.batch[1:]
+	}
+	c.batch = append(c.batch, entry)
+}
+
+// push sends the batch to Loki, and keeps it to retry on failure.
+func (c *lokiClient) push(ctx context.Context, logger log.Logger) {
+	if len(c.batch) == 0 {
+		return
+	}
+
+	if err := c.send(ctx, c.batch); err != nil {
+		logger.Warn("Failed to push audit entries to Loki", "entries", len(c.batch), "error", err)
+		return
+	}
+	c.batch = nil
+}
+
+func (c *lokiClient) send(ctx context.Context, entries []*models.AuditEntryDTO) error {
+	streams := map[string]*lokiStream{}
+	request := lokiPushRequest{}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		key := string(entry.Action) + "/" + string(entry.ResourceType)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: map[string]string{
+				"job":           "grafana_audit",
+				"action":        string(entry.Action),
+				"resource_type": string(entry.ResourceType),
+			}}
+			streams[key] = stream
+			request.Streams = append(request.Streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.Created.UnixNano(), 10), string(line)})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
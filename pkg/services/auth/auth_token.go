@@ -97,6 +97,12 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *models.Use
 		return nil, err
 	}
 
+	if s.Cfg.LoginMaxConcurrentSessions > 0 {
+		if err := s.revokeExcessTokens(ctx, user.Id); err != nil {
+			return nil, err
+		}
+	}
+
 	userAuthToken.UnhashedToken = token
 
 	s.log.Debug("user auth token created", "tokenId", userAuthToken.Id, "userId", userAuthToken.UserId, "clientIP", userAuthToken.ClientIp, "userAgent", userAuthToken.UserAgent, "authToken", userAuthToken.AuthToken)
@@ -129,9 +135,11 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 	}
 
 	if model.RevokedAt > 0 {
+		// The tokens are only soft revoked when the user exceeds login_maximum_concurrent_sessions.
 		return nil, &models.TokenRevokedError{
-			UserID:  model.UserId,
-			TokenID: model.Id,
+			UserID:                model.UserId,
+			TokenID:               model.Id,
+			MaxConcurrentSessions: s.Cfg.LoginMaxConcurrentSessions,
 		}
 	}
 
@@ -374,6 +382,58 @@ func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, use
 	})
 }
 
+func (s *UserAuthTokenService) RevokeTokens(ctx context.Context, userTokenIds []int64) (int64, error) {
+	if len(userTokenIds) == 0 {
+		return 0, nil
+	}
+
+	var affected int64
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var err error
+		affected, err = dbSession.In("id", userTokenIds).Delete(&userAuthToken{})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Debug("user auth tokens revoked", "tokensCount", len(userTokenIds), "count", affected)
+
+	return affected, nil
+}
+
+// revokeExcessTokens soft revokes the oldest active tokens of the user beyond login_maximum_concurrent_sessions, so
+// that the clients using them can tell the user why the session ended.
+func (s *UserAuthTokenService) revokeExcessTokens(ctx context.Context, userId int64) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var tokenIds []int64
+		err := dbSession.Table("user_auth_token").Cols("id").
+			Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
+				userId,
+				s.createdAfterParam(),
+				s.rotatedAfterParam()).
+			Desc("created_at", "id").
+			Find(&tokenIds)
+		if err != nil {
+			return err
+		}
+
+		if int64(len(tokenIds)) <= s.Cfg.LoginMaxConcurrentSessions {
+			return nil
+		}
+
+		excessIds := tokenIds[s.Cfg.LoginMaxConcurrentSessions:]
+		affected, err := dbSession.In("id", excessIds).Cols("revoked_at").Update(&userAuthToken{RevokedAt: getTime().Unix()})
+		if err != nil {
+			return err
+		}
+
+		s.log.Debug("user auth tokens above the concurrent sessions limit revoked", "userId", userId, "count", affected)
+
+		return nil
+	})
+}
+
 func (s *UserAuthTokenService) GetUserToken(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error) {
 	var result models.UserToken
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
@@ -443,6 +503,55 @@ func (s *UserAuthTokenService) GetUserRevokedTokens(ctx context.Context, userId
 	return result, err
 }
 
+func (s *UserAuthTokenService) SearchUserTokens(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+	result := &models.SearchUserTokensResult{Tokens: []*models.UserToken{}}
+
+	whereConditions := []string{"created_at > ?", "rotated_at > ?", "revoked_at = 0"}
+	whereParams := []interface{}{s.createdAfterParam(), s.rotatedAfterParam()}
+
+	if query.UserId > 0 {
+		whereConditions = append(whereConditions, "user_id = ?")
+		whereParams = append(whereParams, query.UserId)
+	}
+
+	if query.OrgId > 0 {
+		whereConditions = append(whereConditions, "user_id IN (SELECT user_id FROM org_user WHERE org_id = ?)")
+		whereParams = append(whereParams, query.OrgId)
+	}
+
+	where := strings.Join(whereConditions, " AND ")
+
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var err error
+		result.TotalCount, err = dbSession.Where(where, whereParams...).Count(&userAuthToken{})
+		if err != nil {
+			return err
+		}
+
+		sess := dbSession.Where(where, whereParams...).Desc("rotated_at", "id")
+		if query.Limit > 0 {
+			sess.Limit(query.Limit, query.Limit*(query.Page-1))
+		}
+
+		var tokens []*userAuthToken
+		if err := sess.Find(&tokens); err != nil {
+			return err
+		}
+
+		for _, token := range tokens {
+			var userToken models.UserToken
+			if err := token.toUserToken(&userToken); err != nil {
+				return err
+			}
+			result.Tokens = append(result.Tokens, &userToken)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
 func (s *UserAuthTokenService) createdAfterParam() int64 {
 	return getTime().Add(-s.Cfg.LoginMaxLifetime).Unix()
 }
@@ -130,6 +130,53 @@ func TestUserAuthToken(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(model2, ShouldBeNil)
 				})
+
+				Convey("Can revoke user tokens by id", func() {
+					count, err := userAuthTokenService.RevokeTokens(context.Background(), []int64{userToken.Id, 999})
+					So(err, ShouldBeNil)
+					So(count, ShouldEqual, 1)
+
+					model, err := ctx.getAuthTokenByID(userToken.Id)
+					So(err, ShouldBeNil)
+					So(model, ShouldBeNil)
+
+					model2, err := ctx.getAuthTokenByID(userToken2.Id)
+					So(err, ShouldBeNil)
+					So(model2, ShouldNotBeNil)
+				})
+
+				Convey("Can search user tokens", func() {
+					result, err := userAuthTokenService.SearchUserTokens(context.Background(),
+						&models.SearchUserTokensQuery{UserId: userID, Page: 1, Limit: 1})
+					So(err, ShouldBeNil)
+					So(result.TotalCount, ShouldEqual, 2)
+					So(result.Tokens, ShouldHaveLength, 1)
+					So(result.Tokens[0].Id, ShouldEqual, userToken2.Id)
+
+					result, err = userAuthTokenService.SearchUserTokens(context.Background(),
+						&models.SearchUserTokensQuery{UserId: userID + 1})
+					So(err, ShouldBeNil)
+					So(result.TotalCount, ShouldEqual, 0)
+					So(result.Tokens, ShouldBeEmpty)
+				})
+
+				Convey("Can search user tokens of org members", func() {
+					_, err := ctx.sqlstore.NewSession(context.Background()).Insert(&models.OrgUser{
+						OrgId: 2, UserId: userID, Role: models.ROLE_VIEWER, Created: t, Updated: t,
+					})
+					So(err, ShouldBeNil)
+
+					result, err := userAuthTokenService.SearchUserTokens(context.Background(),
+						&models.SearchUserTokensQuery{OrgId: 2})
+					So(err, ShouldBeNil)
+					So(result.TotalCount, ShouldEqual, 2)
+					So(result.Tokens, ShouldHaveLength, 2)
+
+					result, err = userAuthTokenService.SearchUserTokens(context.Background(),
+						&models.SearchUserTokensQuery{OrgId: 3})
+					So(err, ShouldBeNil)
+					So(result.TotalCount, ShouldEqual, 0)
+				})
 			})
 
 			Convey("When revoking users tokens in a batch", func() {
@@ -155,6 +202,30 @@ func TestUserAuthToken(t *testing.T) {
 			})
 		})
 
+		Convey("limits the concurrent sessions", func() {
+			userAuthTokenService.Cfg.LoginMaxConcurrentSessions = 2
+
+			var tokens []*models.UserToken
+			for i := 0; i < 3; i++ {
+				token, err := userAuthTokenService.CreateToken(context.Background(), user,
+					net.ParseIP("192.168.10.11"), "some user agent")
+				So(err, ShouldBeNil)
+				tokens = append(tokens, token)
+			}
+
+			_, err := userAuthTokenService.LookupToken(context.Background(), tokens[0].UnhashedToken)
+			So(err, ShouldResemble, &models.TokenRevokedError{
+				UserID:                userID,
+				TokenID:               tokens[0].Id,
+				MaxConcurrentSessions: 2,
+			})
+
+			for _, token := range tokens[1:] {
+				_, err := userAuthTokenService.LookupToken(context.Background(), token.UnhashedToken)
+				So(err, ShouldBeNil)
+			}
+		})
+
 		Convey("expires correctly", func() {
 			userToken, err := userAuthTokenService.CreateToken(context.Background(), user,
 				net.ParseIP("192.168.10.11"), "some user agent")
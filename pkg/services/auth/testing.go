@@ -18,6 +18,8 @@ type FakeUserAuthTokenService struct {
 	GetUserTokensProvider        func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	GetUserRevokedTokensProvider func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	BatchRevokedTokenProvider    func(ctx context.Context, userIds []int64) error
+	SearchUserTokensProvider     func(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error)
+	RevokeTokensProvider         func(ctx context.Context, userTokenIds []int64) (int64, error)
 }
 
 func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
@@ -55,6 +57,12 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		GetUserTokensProvider: func(ctx context.Context, userId int64) ([]*models.UserToken, error) {
 			return nil, nil
 		},
+		SearchUserTokensProvider: func(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+			return &models.SearchUserTokensResult{Tokens: []*models.UserToken{}}, nil
+		},
+		RevokeTokensProvider: func(ctx context.Context, userTokenIds []int64) (int64, error) {
+			return int64(len(userTokenIds)), nil
+		},
 	}
 }
 
@@ -104,3 +112,11 @@ func (s *FakeUserAuthTokenService) GetUserRevokedTokens(ctx context.Context, use
 func (s *FakeUserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.BatchRevokedTokenProvider(ctx, userIds)
 }
+
+func (s *FakeUserAuthTokenService) SearchUserTokens(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+	return s.SearchUserTokensProvider(ctx, query)
+}
+
+func (s *FakeUserAuthTokenService) RevokeTokens(ctx context.Context, userTokenIds []int64) (int64, error) {
+	return s.RevokeTokensProvider(ctx, userTokenIds)
+}
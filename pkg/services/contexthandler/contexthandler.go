@@ -4,6 +4,7 @@ package contexthandler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -33,8 +34,17 @@ const (
 	InvalidAPIKey           = "invalid API key"
 )
 
+var (
+	ErrInvalidAPIKey          = errors.New(InvalidAPIKey)
+	ErrExpiredAPIKey          = errors.New("expired API key")
+	ErrServiceAccountDisabled = errors.New("service account disabled")
+)
+
 const ServiceName = "ContextHandler"
 
+// apiKeyLastUsedInterval is how often the last use of an API key is updated.
+const apiKeyLastUsedInterval = time.Minute
+
 func init() {
 	registry.Register(&registry.Descriptor{
 		Name:         ServiceName,
@@ -170,18 +180,40 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 	span, _ := opentracing.StartSpanFromContext(reqContext.Req.Context(), "initContextWithAPIKey")
 	defer span.Finish()
 
+	user, err := h.AuthenticateAPIKey(keyString)
+	switch {
+	case errors.Is(err, ErrInvalidAPIKey):
+		reqContext.JsonApiErr(401, InvalidAPIKey, err)
+		return true
+	case errors.Is(err, ErrExpiredAPIKey):
+		reqContext.JsonApiErr(401, "Expired API key", nil)
+		return true
+	case errors.Is(err, ErrServiceAccountDisabled):
+		reqContext.JsonApiErr(401, "Service account disabled", nil)
+		return true
+	case err != nil:
+		reqContext.JsonApiErr(500, "Validating API key failed", err)
+		return true
+	}
+
+	reqContext.IsSignedIn = true
+	reqContext.SignedInUser = user
+	return true
+}
+
+// AuthenticateAPIKey returns the signed in user of an API key. The tokens of the service accounts sign in the
+// service account with its current role in the org of the token.
+func (h *ContextHandler) AuthenticateAPIKey(keyString string) (*models.SignedInUser, error) {
 	// base64 decode key
 	decoded, err := apikeygen.Decode(keyString)
 	if err != nil {
-		reqContext.JsonApiErr(401, InvalidAPIKey, err)
-		return true
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKey, err)
 	}
 
 	// fetch key
 	keyQuery := models.GetApiKeyByNameQuery{KeyName: decoded.Name, OrgId: decoded.OrgId}
 	if err := bus.Dispatch(&keyQuery); err != nil {
-		reqContext.JsonApiErr(401, InvalidAPIKey, err)
-		return true
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKey, err)
 	}
 
 	apikey := keyQuery.Result
@@ -189,12 +221,10 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 	// validate api key
 	isValid, err := apikeygen.IsValid(decoded, apikey.Key)
 	if err != nil {
-		reqContext.JsonApiErr(500, "Validating API key failed", err)
-		return true
+		return nil, err
 	}
 	if !isValid {
-		reqContext.JsonApiErr(401, InvalidAPIKey, err)
-		return true
+		return nil, ErrInvalidAPIKey
 	}
 
 	// check for expiration
@@ -203,16 +233,29 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 		getTime = time.Now
 	}
 	if apikey.Expires != nil && *apikey.Expires <= getTime().Unix() {
-		reqContext.JsonApiErr(401, "Expired API key", err)
-		return true
+		return nil, ErrExpiredAPIKey
 	}
 
-	reqContext.IsSignedIn = true
-	reqContext.SignedInUser = &models.SignedInUser{}
-	reqContext.OrgRole = apikey.Role
-	reqContext.ApiKeyId = apikey.Id
-	reqContext.OrgId = apikey.OrgId
-	return true
+	if apikey.LastUsedAt == nil || getTime().Sub(*apikey.LastUsedAt) > apiKeyLastUsedInterval {
+		if err := bus.Dispatch(&models.UpdateApiKeyLastUsedCommand{Id: apikey.Id}); err != nil {
+			log.New("context").Warn("Failed to update the last use of the API key", "id", apikey.Id, "error", err)
+		}
+	}
+
+	if apikey.ServiceAccountId == nil {
+		return &models.SignedInUser{OrgRole: apikey.Role, ApiKeyId: apikey.Id, ApiKeyScopes: apikey.Scopes, OrgId: apikey.OrgId}, nil
+	}
+
+	query := models.GetSignedInUserQuery{UserId: *apikey.ServiceAccountId, OrgId: apikey.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKey, err)
+	}
+	if query.Result.IsDisabled {
+		return nil, ErrServiceAccountDisabled
+	}
+	query.Result.ApiKeyId = apikey.Id
+	query.Result.ApiKeyScopes = apikey.Scopes
+	return query.Result, nil
 }
 
 func (h *ContextHandler) initContextWithBasicAuth(reqContext *models.ReqContext, orgID int64) bool {
@@ -1,6 +1,7 @@
 package datasources
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
+// CacheService returns the data sources the users are allowed to query, with
+// models.ErrDataSourceAccessDenied for the other ones.
 type CacheService interface {
 	GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error)
 	GetDatasourceByUID(datasourceUID string, user *models.SignedInUser, skipCache bool) (*models.DataSource, error)
@@ -18,6 +21,7 @@ type CacheService interface {
 type CacheServiceImpl struct {
 	CacheService *localcache.CacheService `inject:""`
 	SQLStore     *sqlstore.SQLStore       `inject:""`
+	Permissions  PermissionsService       `inject:""`
 }
 
 func init() {
@@ -43,7 +47,7 @@ func (dc *CacheServiceImpl) GetDatasource(
 		if cached, found := dc.CacheService.Get(cacheKey); found {
 			ds := cached.(*models.DataSource)
 			if ds.OrgId == user.OrgId {
-				return ds, nil
+				return dc.checkQueryPermission(user, ds)
 			}
 		}
 	}
@@ -58,7 +62,7 @@ func (dc *CacheServiceImpl) GetDatasource(
 		dc.CacheService.Set(uidKey(ds.OrgId, ds.Uid), ds, time.Second*5)
 	}
 	dc.CacheService.Set(cacheKey, ds, time.Second*5)
-	return ds, nil
+	return dc.checkQueryPermission(user, ds)
 }
 
 func (dc *CacheServiceImpl) GetDatasourceByUID(
@@ -78,7 +82,7 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 		if cached, found := dc.CacheService.Get(uidCacheKey); found {
 			ds := cached.(*models.DataSource)
 			if ds.OrgId == user.OrgId {
-				return ds, nil
+				return dc.checkQueryPermission(user, ds)
 			}
 		}
 	}
@@ -91,6 +95,17 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 
 	dc.CacheService.Set(uidCacheKey, ds, time.Second*5)
 	dc.CacheService.Set(idKey(ds.Id), ds, time.Second*5)
+	return dc.checkQueryPermission(user, ds)
+}
+
+func (dc *CacheServiceImpl) checkQueryPermission(user *models.SignedInUser, ds *models.DataSource) (*models.DataSource, error) {
+	ok, err := dc.Permissions.HasPermission(context.Background(), user, ds, models.DsPermissionQuery)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, models.ErrDataSourceAccessDenied
+	}
 	return ds, nil
 }
 
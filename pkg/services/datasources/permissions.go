@@ -0,0 +1,116 @@
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// PermissionsService evaluates the query, read, write and admin permissions of the users on the data sources.
+type PermissionsService interface {
+	// IsEnabled returns whether the data source permissions are enforced.
+	IsEnabled() bool
+	// HasPermission returns whether the user has the permission, or a higher one, on the data source. It's always
+	// true when the permissions aren't enforced.
+	HasPermission(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, permission models.DsPermissionType) (bool, error)
+	GetPermissions(ctx context.Context, ds *models.DataSource) ([]*models.DataSourcePermissionDTO, error)
+	UpdatePermissions(ctx context.Context, cmd *models.UpdateDataSourcePermissionsCommand) error
+}
+
+type PermissionsServiceImpl struct {
+	Cfg          *setting.Cfg             `inject:""`
+	CacheService *localcache.CacheService `inject:""`
+	SQLStore     *sqlstore.SQLStore       `inject:""`
+}
+
+func init() {
+	registry.RegisterService(&PermissionsServiceImpl{})
+}
+
+func (s *PermissionsServiceImpl) Init() error {
+	if s.IsEnabled() {
+		bus.AddHandler("datasources", s.filterDatasourcesByQueryPermission)
+	}
+	return nil
+}
+
+func (s *PermissionsServiceImpl) IsEnabled() bool {
+	return s.Cfg.DataSourcePermissionsEnabled
+}
+
+func (s *PermissionsServiceImpl) HasPermission(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, permission models.DsPermissionType) (bool, error) {
+	if !s.IsEnabled() || user.HasRole(models.ROLE_ADMIN) {
+		return true, nil
+	}
+
+	permissions, err := s.getPermissions(ctx, ds)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permissions {
+		if p.Permission >= permission && p.AppliesTo(user) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *PermissionsServiceImpl) GetPermissions(ctx context.Context, ds *models.DataSource) ([]*models.DataSourcePermissionDTO, error) {
+	return s.SQLStore.GetDataSourcePermissionsInfo(ctx, ds.OrgId, ds.Id)
+}
+
+func (s *PermissionsServiceImpl) UpdatePermissions(ctx context.Context, cmd *models.UpdateDataSourcePermissionsCommand) error {
+	if err := s.SQLStore.UpdateDataSourcePermissions(ctx, cmd); err != nil {
+		return err
+	}
+	s.CacheService.Delete(permissionsKey(cmd.DataSourceId))
+	return nil
+}
+
+// getPermissions returns the permissions of the data source, which are cached for a few seconds as they're
+// evaluated for every query of the data source.
+func (s *PermissionsServiceImpl) getPermissions(ctx context.Context, ds *models.DataSource) ([]*models.DataSourcePermission, error) {
+	cacheKey := permissionsKey(ds.Id)
+	if cached, found := s.CacheService.Get(cacheKey); found {
+		return cached.([]*models.DataSourcePermission), nil
+	}
+
+	permissions, err := s.SQLStore.GetDataSourcePermissions(ctx, ds.OrgId, ds.Id)
+	if err != nil {
+		return nil, err
+	}
+	s.CacheService.Set(cacheKey, permissions, time.Second*5)
+	return permissions, nil
+}
+
+// filterDatasourcesByQueryPermission handles the filtering of the data sources shown to a user, for example in the
+// frontend settings, and of the data sources of the alert rules they save.
+func (s *PermissionsServiceImpl) filterDatasourcesByQueryPermission(query *models.DatasourcesPermissionFilterQuery) error {
+	if query.User == nil {
+		query.Result = query.Datasources
+		return nil
+	}
+
+	query.Result = make([]*models.DataSource, 0, len(query.Datasources))
+	for _, ds := range query.Datasources {
+		ok, err := s.HasPermission(context.Background(), query.User, ds, models.DsPermissionQuery)
+		if err != nil {
+			return err
+		}
+		if ok {
+			query.Result = append(query.Result, ds)
+		}
+	}
+	return nil
+}
+
+func permissionsKey(dataSourceID int64) string {
+	return fmt.Sprintf("ds-permissions-%d", dataSourceID)
+}
@@ -0,0 +1,85 @@
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionsService(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.DataSourcePermissionsEnabled = true
+	service := &PermissionsServiceImpl{Cfg: cfg, CacheService: localcache.New(0, 0), SQLStore: sqlStore}
+	ctx := context.Background()
+
+	addCmd := models.AddDataSourceCommand{OrgId: 1, Name: "prometheus", Type: "prometheus", Access: models.DS_ACCESS_PROXY}
+	require.NoError(t, sqlstore.AddDataSource(&addCmd))
+	ds := addCmd.Result
+
+	viewer := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_VIEWER, Teams: []int64{7}}
+	admin := &models.SignedInUser{UserId: 3, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+	t.Run("Viewers and editors can query new data sources", func(t *testing.T) {
+		ok, err := service.HasPermission(ctx, viewer, ds, models.DsPermissionQuery)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = service.HasPermission(ctx, viewer, ds, models.DsPermissionRead)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Org admins have admin permission", func(t *testing.T) {
+		ok, err := service.HasPermission(ctx, admin, ds, models.DsPermissionAdmin)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Permissions of the teams of a user apply to them", func(t *testing.T) {
+		require.NoError(t, service.UpdatePermissions(ctx, &models.UpdateDataSourcePermissionsCommand{
+			OrgId: 1, DataSourceId: ds.Id, Items: []models.DataSourcePermissionItem{{TeamId: 7, Permission: models.DsPermissionWrite}},
+		}))
+
+		ok, err := service.HasPermission(ctx, viewer, ds, models.DsPermissionWrite)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = service.HasPermission(ctx, viewer, ds, models.DsPermissionAdmin)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		editor := &models.SignedInUser{UserId: 4, OrgId: 1, OrgRole: models.ROLE_EDITOR}
+		ok, err = service.HasPermission(ctx, editor, ds, models.DsPermissionQuery)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Data sources are filtered by the query permission", func(t *testing.T) {
+		require.NoError(t, service.UpdatePermissions(ctx, &models.UpdateDataSourcePermissionsCommand{
+			OrgId: 1, DataSourceId: ds.Id, Items: []models.DataSourcePermissionItem{{UserId: 3, Permission: models.DsPermissionQuery}},
+		}))
+
+		query := models.DatasourcesPermissionFilterQuery{User: viewer, Datasources: []*models.DataSource{ds}}
+		require.NoError(t, service.filterDatasourcesByQueryPermission(&query))
+		require.Empty(t, query.Result)
+
+		query = models.DatasourcesPermissionFilterQuery{User: admin, Datasources: []*models.DataSource{ds}}
+		require.NoError(t, service.filterDatasourcesByQueryPermission(&query))
+		require.Len(t, query.Result, 1)
+	})
+
+	t.Run("Permissions aren't enforced when disabled", func(t *testing.T) {
+		cfg.DataSourcePermissionsEnabled = false
+		defer func() { cfg.DataSourcePermissionsEnabled = true }()
+
+		ok, err := service.HasPermission(ctx, viewer, ds, models.DsPermissionAdmin)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
@@ -0,0 +1,148 @@
+package grpcadmin
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	authorizationMetadata = "authorization"
+	orgIDMetadata         = "x-grafana-org-id"
+)
+
+type signedInUserKey struct{}
+
+// authInterceptor authenticates the requests, and passes the signed in user to the handlers in their context.
+func (s *GRPCAdminService) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		logger.Debug("Failed to authenticate request", "method", info.FullMethod, "error", err)
+		return nil, err
+	}
+
+	resp, err := handler(context.WithValue(ctx, signedInUserKey{}, user), req)
+	if status.Code(err) == codes.Internal {
+		logger.Error("Request failed", "method", info.FullMethod, "userId", user.UserId, "orgId", user.OrgId, "error", err)
+	}
+	return resp, err
+}
+
+// authenticate returns the signed in user of the API key, service account token or basic auth credentials of the
+// authorization metadata of the request, like the authentication of the HTTP API.
+func (s *GRPCAdminService) authenticate(ctx context.Context) (*models.SignedInUser, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := firstMetadataValue(md, authorizationMetadata)
+	if header == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	if parts := strings.SplitN(header, " ", 2); len(parts) == 2 && parts[0] == "Bearer" {
+		return s.authenticateAPIKey(parts[1])
+	}
+
+	username, password, err := util.DecodeBasicAuthHeader(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+	if username == "api_key" {
+		return s.authenticateAPIKey(password)
+	}
+	if !s.Cfg.BasicAuthEnabled {
+		return nil, status.Error(codes.Unauthenticated, "basic auth is disabled")
+	}
+
+	authQuery := models.LoginUserQuery{Username: username, Password: password, Cfg: s.Cfg}
+	if err := bus.Dispatch(&authQuery); err != nil {
+		logger.Debug("Failed to authorize the user", "username", username, "error", err)
+		return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidUsernamePassword)
+	}
+
+	var orgID int64
+	if value := firstMetadataValue(md, orgIDMetadata); value != "" {
+		if orgID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s metadata", orgIDMetadata)
+		}
+	}
+	query := models.GetSignedInUserQuery{UserId: authQuery.User.Id, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidUsernamePassword)
+	}
+	return query.Result, nil
+}
+
+func (s *GRPCAdminService) authenticateAPIKey(key string) (*models.SignedInUser, error) {
+	user, err := s.ContextHandler.AuthenticateAPIKey(key)
+	switch {
+	case errors.Is(err, contexthandler.ErrInvalidAPIKey):
+		return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidAPIKey)
+	case errors.Is(err, contexthandler.ErrExpiredAPIKey), errors.Is(err, contexthandler.ErrServiceAccountDisabled):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		logger.Error("Failed to validate API key", "error", err)
+		return nil, status.Error(codes.Internal, "failed to validate API key")
+	}
+	// The scopes only describe HTTP API requests, so the scoped API keys have no access to the gRPC admin API.
+	if len(user.ApiKeyScopes) > 0 {
+		return nil, status.Error(codes.PermissionDenied, "scoped API keys can't access the gRPC admin API")
+	}
+	return user, nil
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// signedInUser returns the signed in user of the request.
+func signedInUser(ctx context.Context) *models.SignedInUser {
+	user, _ := ctx.Value(signedInUserKey{}).(*models.SignedInUser)
+	if user == nil {
+		return &models.SignedInUser{}
+	}
+	return user
+}
+
+// requireGrafanaAdmin returns the signed in user of the request when it's a Grafana server admin, like the admin
+// routes of the HTTP API.
+func requireGrafanaAdmin(ctx context.Context) (*models.SignedInUser, error) {
+	user := signedInUser(ctx)
+	if !user.IsGrafanaAdmin {
+		return nil, status.Error(codes.PermissionDenied, "requires a Grafana server admin")
+	}
+	return user, nil
+}
+
+// requireOrgRole returns the signed in user of the request when it has the role, or a higher one, in the org of
+// the request.
+func requireOrgRole(ctx context.Context, role models.RoleType) (*models.SignedInUser, error) {
+	user := signedInUser(ctx)
+	if user.OrgId <= 0 || !user.HasRole(role) {
+		return nil, status.Errorf(codes.PermissionDenied, "requires the %s role in the org", role)
+	}
+	return user, nil
+}
+
+// recordAuditEntry records a change in the audit log, when it's enabled.
+func recordAuditEntry(ctx context.Context, cmd *models.RecordAuditEntryCommand) {
+	user := signedInUser(ctx)
+	cmd.UserId = user.UserId
+	cmd.UserLogin = user.Login
+	if err := bus.DispatchCtx(ctx, cmd); err != nil && !errors.Is(err, bus.ErrHandlerNotFound) {
+		logger.Error("Failed to record audit entry", "action", cmd.Action, "resourceType", cmd.ResourceType,
+			"resourceId", cmd.ResourceId, "error", err)
+	}
+}
@@ -0,0 +1,251 @@
+package grpcadmin
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/grpcadmin/adminv1"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+type dashboardServer struct {
+	adminv1.UnimplementedDashboardServiceServer
+	service *GRPCAdminService
+}
+
+func (s *dashboardServer) GetDashboard(ctx context.Context, req *adminv1.GetDashboardRequest) (*adminv1.Dashboard, error) {
+	user, err := requireOrgRole(ctx, models.ROLE_VIEWER)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := getDashboard(user.OrgId, req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	if canView, err := guardian.New(dash.Id, user.OrgId, user).CanView(); err != nil || !canView {
+		return nil, guardianError(err)
+	}
+	return toDashboard(dash)
+}
+
+func (s *dashboardServer) SearchDashboards(ctx context.Context, req *adminv1.SearchDashboardsRequest) (*adminv1.SearchDashboardsResponse, error) {
+	user, err := requireOrgRole(ctx, models.ROLE_VIEWER)
+	if err != nil {
+		return nil, err
+	}
+
+	query := search.Query{
+		Title:        req.Query,
+		Tags:         req.Tags,
+		FolderIds:    req.FolderIds,
+		OrgId:        user.OrgId,
+		SignedInUser: user,
+		Limit:        int64(req.Limit),
+		Page:         int64(req.Page),
+		Type:         string(search.DashHitDB),
+		Permission:   models.PERMISSION_VIEW,
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search dashboards: %s", err)
+	}
+
+	resp := &adminv1.SearchDashboardsResponse{}
+	for _, hit := range query.Result {
+		resp.Dashboards = append(resp.Dashboards, &adminv1.DashboardHit{
+			Id:          hit.ID,
+			Uid:         hit.UID,
+			Title:       hit.Title,
+			Url:         hit.URL,
+			Tags:        hit.Tags,
+			FolderId:    hit.FolderID,
+			FolderUid:   hit.FolderUID,
+			FolderTitle: hit.FolderTitle,
+		})
+	}
+	return resp, nil
+}
+
+// SaveDashboard creates or updates a dashboard, with the permission and version checks of the dashboard service.
+func (s *dashboardServer) SaveDashboard(ctx context.Context, req *adminv1.SaveDashboardRequest) (*adminv1.Dashboard, error) {
+	user, err := requireOrgRole(ctx, models.ROLE_VIEWER)
+	if err != nil {
+		return nil, err
+	}
+	data, err := simplejson.NewJson(req.Json)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid dashboard json: %s", err)
+	}
+
+	cmd := models.SaveDashboardCommand{Dashboard: data, OrgId: user.OrgId, UserId: user.UserId, Overwrite: req.Overwrite}
+	if req.FolderUid != "" {
+		folder, err := dashboards.NewFolderService(user.OrgId, user, s.service.SQLStore).GetFolderByUID(req.FolderUid)
+		if err != nil {
+			if errors.Is(err, models.ErrFolderNotFound) {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			return nil, dashboardError(err, "failed to get folder")
+		}
+		cmd.FolderId = folder.Id
+	}
+	dash := cmd.GetDashboardModel()
+
+	var previous *models.Dashboard
+	if dash.Id != 0 || dash.Uid != "" {
+		query := models.GetDashboardQuery{Id: dash.Id, Uid: dash.Uid, OrgId: user.OrgId}
+		if err := bus.Dispatch(&query); err == nil {
+			previous = query.Result
+		}
+	}
+
+	provisioningData, err := dashboards.NewProvisioningService(s.service.SQLStore).GetProvisionedDashboardDataByDashboardID(dash.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check if dashboard is provisioned: %s", err)
+	}
+	allowUIUpdate := true
+	if provisioningData != nil {
+		allowUIUpdate = s.service.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
+	}
+
+	saved, err := dashboards.NewService(s.service.SQLStore).SaveDashboard(&dashboards.SaveDashboardDTO{
+		Dashboard: dash,
+		Message:   req.Message,
+		OrgId:     user.OrgId,
+		User:      user,
+		Overwrite: req.Overwrite,
+	}, allowUIUpdate)
+	if err != nil {
+		return nil, dashboardError(err, "failed to save dashboard")
+	}
+
+	cmdAudit := &models.RecordAuditEntryCommand{
+		OrgId:        user.OrgId,
+		Action:       models.AuditActionCreate,
+		ResourceType: models.AuditResourceDashboard,
+		ResourceId:   saved.Uid,
+		ResourceName: saved.Title,
+		After:        saved.Data,
+	}
+	if previous != nil {
+		cmdAudit.Action = models.AuditActionUpdate
+		cmdAudit.Before = previous.Data
+	}
+	recordAuditEntry(ctx, cmdAudit)
+
+	return toDashboard(saved)
+}
+
+func (s *dashboardServer) DeleteDashboard(ctx context.Context, req *adminv1.DeleteDashboardRequest) (*adminv1.DeleteDashboardResponse, error) {
+	user, err := requireOrgRole(ctx, models.ROLE_VIEWER)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := getDashboard(user.OrgId, req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	if canSave, err := guardian.New(dash.Id, user.OrgId, user).CanSave(); err != nil || !canSave {
+		return nil, guardianError(err)
+	}
+
+	if err := dashboards.NewService(s.service.SQLStore).DeleteDashboard(dash.Id, user.OrgId); err != nil {
+		return nil, dashboardError(err, "failed to delete dashboard")
+	}
+	recordAuditEntry(ctx, &models.RecordAuditEntryCommand{
+		OrgId:        user.OrgId,
+		Action:       models.AuditActionDelete,
+		ResourceType: models.AuditResourceDashboard,
+		ResourceId:   dash.Uid,
+		ResourceName: dash.Title,
+		Before:       dash.Data,
+	})
+	return &adminv1.DeleteDashboardResponse{}, nil
+}
+
+func getDashboard(orgID int64, uid string) (*models.Dashboard, error) {
+	if uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+	query := models.GetDashboardQuery{Uid: uid, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, dashboardError(err, "failed to get dashboard")
+	}
+	if query.Result.IsFolder {
+		return nil, status.Error(codes.NotFound, models.ErrDashboardNotFound.Error())
+	}
+	return query.Result, nil
+}
+
+func toDashboard(dash *models.Dashboard) (*adminv1.Dashboard, error) {
+	data, err := dash.Data.MarshalJSON()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode dashboard: %s", err)
+	}
+	result := &adminv1.Dashboard{
+		Id:       dash.Id,
+		Uid:      dash.Uid,
+		Title:    dash.Title,
+		FolderId: dash.FolderId,
+		Version:  int32(dash.Version),
+		Json:     data,
+		Created:  timestamppb.New(dash.Created),
+		Updated:  timestamppb.New(dash.Updated),
+		Url:      dash.GetUrl(),
+	}
+	if dash.FolderId > 0 {
+		query := models.GetDashboardQuery{Id: dash.FolderId, OrgId: dash.OrgId}
+		if err := bus.Dispatch(&query); err != nil {
+			return nil, dashboardError(err, "failed to get folder")
+		}
+		result.FolderUid = query.Result.Uid
+	}
+	return result, nil
+}
+
+func guardianError(err error) error {
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check dashboard permissions: %s", err)
+	}
+	return status.Error(codes.PermissionDenied, models.ErrDashboardUpdateAccessDenied.Error())
+}
+
+// dashboardError returns the gRPC error of an error of the dashboard service, by the status code of its HTTP
+// error.
+func dashboardError(err error, message string) error {
+	var dashboardErr models.DashboardErr
+	if errors.As(err, &dashboardErr) {
+		switch {
+		case dashboardErr.Status == "version-mismatch":
+			return status.Error(codes.Aborted, dashboardErr.Error())
+		case dashboardErr.StatusCode == 400:
+			return status.Error(codes.InvalidArgument, dashboardErr.Error())
+		case dashboardErr.StatusCode == 403:
+			return status.Error(codes.PermissionDenied, dashboardErr.Error())
+		case dashboardErr.StatusCode == 404:
+			return status.Error(codes.NotFound, dashboardErr.Error())
+		case dashboardErr.StatusCode == 412:
+			return status.Error(codes.FailedPrecondition, dashboardErr.Error())
+		}
+	}
+
+	var pluginErr models.UpdatePluginDashboardError
+	switch {
+	case errors.Is(err, models.ErrDashboardNotFound), errors.Is(err, models.ErrFolderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrFolderAccessDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &pluginErr):
+		return status.Errorf(codes.FailedPrecondition, "the dashboard belongs to plugin %s", pluginErr.PluginId)
+	}
+	return status.Errorf(codes.Internal, "%s: %s", message, err)
+}
@@ -0,0 +1,290 @@
+package grpcadmin
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/api/datasource"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/grpcadmin/adminv1"
+)
+
+type dataSourceServer struct {
+	adminv1.UnimplementedDataSourceServiceServer
+	service *GRPCAdminService
+}
+
+func (s *dataSourceServer) GetDataSource(ctx context.Context, req *adminv1.GetDataSourceRequest) (*adminv1.DataSource, error) {
+	ds, err := s.getDataSource(ctx, req.Ref, models.DsPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	return toDataSource(ds), nil
+}
+
+func (s *dataSourceServer) ListDataSources(ctx context.Context, req *adminv1.ListDataSourcesRequest) (*adminv1.ListDataSourcesResponse, error) {
+	enforced := s.service.DataSourcePermissions.IsEnabled()
+	role := models.ROLE_ADMIN
+	if enforced {
+		role = models.ROLE_VIEWER
+	}
+	user, err := requireOrgRole(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetDataSourcesQuery{OrgId: user.OrgId, DataSourceLimit: s.service.Cfg.DataSourceLimit, User: user}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, dataSourceError(err, "failed to list data sources")
+	}
+
+	resp := &adminv1.ListDataSourcesResponse{}
+	for _, ds := range query.Result {
+		if enforced {
+			ok, err := s.service.DataSourcePermissions.HasPermission(ctx, user, ds, models.DsPermissionRead)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to check data source permissions: %s", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		resp.DataSources = append(resp.DataSources, toDataSource(ds))
+	}
+	return resp, nil
+}
+
+func (s *dataSourceServer) CreateDataSource(ctx context.Context, req *adminv1.CreateDataSourceRequest) (*adminv1.DataSource, error) {
+	user, err := requireOrgRole(ctx, models.ROLE_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	spec := req.Spec
+	if spec == nil || spec.Name == "" || spec.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and type are required")
+	}
+	jsonData, err := dataSourceJSONData(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.AddDataSourceCommand{
+		OrgId:           user.OrgId,
+		Uid:             spec.Uid,
+		Name:            spec.Name,
+		Type:            spec.Type,
+		Access:          models.DsAccess(spec.Access),
+		Url:             spec.Url,
+		Database:        spec.Database,
+		User:            spec.User,
+		BasicAuth:       spec.BasicAuth,
+		BasicAuthUser:   spec.BasicAuthUser,
+		WithCredentials: spec.WithCredentials,
+		IsDefault:       spec.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  spec.SecureJsonData,
+	}
+	if cmd.Access == "" {
+		cmd.Access = models.DS_ACCESS_PROXY
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return nil, dataSourceError(err, "failed to create data source")
+	}
+
+	created := toDataSource(cmd.Result)
+	auditDataSourceChange(ctx, models.AuditActionCreate, created, nil, created)
+	return created, nil
+}
+
+func (s *dataSourceServer) UpdateDataSource(ctx context.Context, req *adminv1.UpdateDataSourceRequest) (*adminv1.DataSource, error) {
+	previous, err := s.getDataSource(ctx, &adminv1.DataSourceRef{Id: req.Id}, models.DsPermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+	if previous.ReadOnly {
+		return nil, status.Error(codes.FailedPrecondition, models.ErrDatasourceIsReadOnly.Error())
+	}
+	spec := req.Spec
+	if spec == nil || spec.Name == "" || spec.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and type are required")
+	}
+	jsonData, err := dataSourceJSONData(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.UpdateDataSourceCommand{
+		OrgId:           previous.OrgId,
+		Id:              previous.Id,
+		Uid:             spec.Uid,
+		Name:            spec.Name,
+		Type:            spec.Type,
+		Access:          models.DsAccess(spec.Access),
+		Url:             spec.Url,
+		Database:        spec.Database,
+		User:            spec.User,
+		BasicAuth:       spec.BasicAuth,
+		BasicAuthUser:   spec.BasicAuthUser,
+		WithCredentials: spec.WithCredentials,
+		IsDefault:       spec.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  spec.SecureJsonData,
+		Version:         int(req.Version),
+	}
+	if cmd.Access == "" {
+		cmd.Access = previous.Access
+	}
+	if cmd.Uid == "" {
+		cmd.Uid = previous.Uid
+	}
+	// the secure settings which aren't in the request are kept
+	if len(cmd.SecureJsonData) > 0 {
+		for k, v := range previous.SecureJsonData.Decrypt() {
+			if _, ok := cmd.SecureJsonData[k]; !ok {
+				cmd.SecureJsonData[k] = v
+			}
+		}
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return nil, dataSourceError(err, "failed to update data source")
+	}
+
+	query := models.GetDataSourceQuery{Id: previous.Id, OrgId: previous.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, dataSourceError(err, "failed to get data source")
+	}
+	updated := toDataSource(query.Result)
+	auditDataSourceChange(ctx, models.AuditActionUpdate, updated, toDataSource(previous), updated)
+	return updated, nil
+}
+
+func (s *dataSourceServer) DeleteDataSource(ctx context.Context, req *adminv1.DeleteDataSourceRequest) (*adminv1.DeleteDataSourceResponse, error) {
+	ds, err := s.getDataSource(ctx, req.Ref, models.DsPermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if ds.ReadOnly {
+		return nil, status.Error(codes.FailedPrecondition, "cannot delete read-only data source")
+	}
+
+	if err := bus.Dispatch(&models.DeleteDataSourceCommand{ID: ds.Id, OrgID: ds.OrgId}); err != nil {
+		return nil, dataSourceError(err, "failed to delete data source")
+	}
+	deleted := toDataSource(ds)
+	auditDataSourceChange(ctx, models.AuditActionDelete, deleted, deleted, nil)
+	return &adminv1.DeleteDataSourceResponse{}, nil
+}
+
+// getDataSource returns the data source of the org of the request when the user has the permission on it, which
+// requires an org admin unless the data source permissions are enforced.
+func (s *dataSourceServer) getDataSource(ctx context.Context, ref *adminv1.DataSourceRef, permission models.DsPermissionType) (*models.DataSource, error) {
+	enforced := s.service.DataSourcePermissions.IsEnabled()
+	role := models.ROLE_ADMIN
+	if enforced {
+		role = models.ROLE_VIEWER
+	}
+	user, err := requireOrgRole(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if ref == nil || (ref.Uid == "" && ref.Id == 0 && ref.Name == "") {
+		return nil, status.Error(codes.InvalidArgument, "uid, id or name of the data source is required")
+	}
+
+	query := models.GetDataSourceQuery{Uid: ref.Uid, Id: ref.Id, Name: ref.Name, OrgId: user.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, dataSourceError(err, "failed to get data source")
+	}
+
+	if enforced {
+		ok, err := s.service.DataSourcePermissions.HasPermission(ctx, user, query.Result, permission)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to check data source permissions: %s", err)
+		}
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, models.ErrDataSourceAccessDenied.Error())
+		}
+	}
+	return query.Result, nil
+}
+
+func dataSourceJSONData(spec *adminv1.DataSourceSpec) (*simplejson.Json, error) {
+	if _, err := datasource.ValidateURL(spec.Type, spec.Url); spec.Url != "" && err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid URL %q", spec.Url)
+	}
+	if len(spec.JsonData) == 0 {
+		return simplejson.New(), nil
+	}
+	jsonData, err := simplejson.NewJson(spec.JsonData)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid json_data: %s", err)
+	}
+	return jsonData, nil
+}
+
+func toDataSource(ds *models.DataSource) *adminv1.DataSource {
+	result := &adminv1.DataSource{
+		Id:              ds.Id,
+		Uid:             ds.Uid,
+		OrgId:           ds.OrgId,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          string(ds.Access),
+		Url:             ds.Url,
+		Database:        ds.Database,
+		User:            ds.User,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		Version:         int32(ds.Version),
+		ReadOnly:        ds.ReadOnly,
+	}
+	if ds.JsonData != nil {
+		result.JsonData, _ = ds.JsonData.MarshalJSON()
+	}
+	for k, v := range ds.SecureJsonData {
+		if len(v) > 0 {
+			result.SecureJsonFields = append(result.SecureJsonFields, k)
+		}
+	}
+	sort.Strings(result.SecureJsonFields)
+	return result
+}
+
+func auditDataSourceChange(ctx context.Context, action models.AuditAction, ds *adminv1.DataSource, before, after *adminv1.DataSource) {
+	cmd := &models.RecordAuditEntryCommand{
+		OrgId:        ds.OrgId,
+		Action:       action,
+		ResourceType: models.AuditResourceDataSource,
+		ResourceId:   ds.Uid,
+		ResourceName: ds.Name,
+	}
+	if before != nil {
+		cmd.Before = before
+	}
+	if after != nil {
+		cmd.After = after
+	}
+	recordAuditEntry(ctx, cmd)
+}
+
+func dataSourceError(err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrDataSourceNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrDataSourceNameExists), errors.Is(err, models.ErrDataSourceUidExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, models.ErrDataSourceUpdatingOldVersion):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, models.ErrDataSourceIdentifierNotSet):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Errorf(codes.Internal, "%s: %s", message, err)
+}
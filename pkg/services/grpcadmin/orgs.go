@@ -0,0 +1,155 @@
+package grpcadmin
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/grpcadmin/adminv1"
+)
+
+type orgServer struct {
+	adminv1.UnimplementedOrgServiceServer
+	service *GRPCAdminService
+}
+
+func (s *orgServer) GetOrg(ctx context.Context, req *adminv1.GetOrgRequest) (*adminv1.Org, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.Id != 0:
+		return getOrg(req.Id)
+	case req.Name != "":
+		query := models.GetOrgByNameQuery{Name: req.Name}
+		if err := bus.Dispatch(&query); err != nil {
+			return nil, orgError(err, "failed to get org")
+		}
+		return &adminv1.Org{Id: query.Result.Id, Name: query.Result.Name}, nil
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or name is required")
+	}
+}
+
+func (s *orgServer) ListOrgs(ctx context.Context, req *adminv1.ListOrgsRequest) (*adminv1.ListOrgsResponse, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	sort, err := models.ParseSearchSort(req.Sort)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query := models.SearchOrgsQuery{Query: req.Query, Limit: int(req.Limit), Sort: sort, Cursor: req.Cursor}
+	if query.Limit <= 0 {
+		query.Limit = 1000
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, searchError(err, "failed to list orgs")
+	}
+
+	resp := &adminv1.ListOrgsResponse{TotalCount: query.TotalCount, NextCursor: query.NextCursor}
+	for _, org := range query.Result {
+		resp.Orgs = append(resp.Orgs, &adminv1.Org{Id: org.Id, Name: org.Name})
+	}
+	return resp, nil
+}
+
+func (s *orgServer) CreateOrg(ctx context.Context, req *adminv1.CreateOrgRequest) (*adminv1.Org, error) {
+	user, err := requireGrafanaAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	cmd := models.CreateOrgCommand{Name: req.Name, UserId: user.UserId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return nil, orgError(err, "failed to create org")
+	}
+
+	metrics.MApiOrgCreate.Inc()
+	org := &adminv1.Org{Id: cmd.Result.Id, Name: cmd.Result.Name}
+	auditOrgChange(ctx, models.AuditActionCreate, org, nil, org)
+	return org, nil
+}
+
+func (s *orgServer) UpdateOrg(ctx context.Context, req *adminv1.UpdateOrgRequest) (*adminv1.Org, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	before, err := getOrg(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bus.Dispatch(&models.UpdateOrgCommand{OrgId: req.Id, Name: req.Name}); err != nil {
+		return nil, orgError(err, "failed to update org")
+	}
+
+	org := &adminv1.Org{Id: req.Id, Name: req.Name}
+	auditOrgChange(ctx, models.AuditActionUpdate, org, before, org)
+	return org, nil
+}
+
+func (s *orgServer) DeleteOrg(ctx context.Context, req *adminv1.DeleteOrgRequest) (*adminv1.DeleteOrgResponse, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	before, err := getOrg(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bus.Dispatch(&models.DeleteOrgCommand{Id: req.Id}); err != nil {
+		return nil, orgError(err, "failed to delete org")
+	}
+	auditOrgChange(ctx, models.AuditActionDelete, before, before, nil)
+	return &adminv1.DeleteOrgResponse{}, nil
+}
+
+func getOrg(id int64) (*adminv1.Org, error) {
+	query := models.GetOrgByIdQuery{Id: id}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, orgError(err, "failed to get org")
+	}
+	return &adminv1.Org{Id: query.Result.Id, Name: query.Result.Name}, nil
+}
+
+func auditOrgChange(ctx context.Context, action models.AuditAction, org *adminv1.Org, before, after *adminv1.Org) {
+	cmd := &models.RecordAuditEntryCommand{
+		OrgId:        org.Id,
+		Action:       action,
+		ResourceType: models.AuditResourceOrg,
+		ResourceId:   strconv.FormatInt(org.Id, 10),
+		ResourceName: org.Name,
+	}
+	if before != nil {
+		cmd.Before = before
+	}
+	if after != nil {
+		cmd.After = after
+	}
+	recordAuditEntry(ctx, cmd)
+}
+
+func orgError(err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrOrgNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrOrgNameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return status.Errorf(codes.Internal, "%s: %s", message, err)
+}
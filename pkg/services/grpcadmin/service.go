@@ -0,0 +1,87 @@
+// Package grpcadmin serves the gRPC admin API, which manages the users, orgs, data sources and dashboards with the
+// same service layer and access control as the HTTP API.
+package grpcadmin
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/grpcadmin/adminv1"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type GRPCAdminService struct {
+	Cfg                   *setting.Cfg                     `inject:""`
+	SQLStore              *sqlstore.SQLStore               `inject:""`
+	Login                 login.Service                    `inject:""`
+	ContextHandler        *contexthandler.ContextHandler   `inject:""`
+	DataSourcePermissions datasources.PermissionsService   `inject:""`
+	ProvisioningService   provisioning.ProvisioningService `inject:""`
+
+	server *grpc.Server
+}
+
+var logger = log.New("grpc.admin")
+
+func init() {
+	registry.RegisterService(&GRPCAdminService{})
+}
+
+func (s *GRPCAdminService) Init() error {
+	if !s.Cfg.GRPCAdmin.Enabled {
+		return nil
+	}
+
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(s.authInterceptor)}
+	if s.Cfg.GRPCAdmin.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.Cfg.GRPCAdmin.CertFile, s.Cfg.GRPCAdmin.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the TLS certificate of the gRPC admin API: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s.server = grpc.NewServer(opts...)
+	adminv1.RegisterUserServiceServer(s.server, &userServer{service: s})
+	adminv1.RegisterOrgServiceServer(s.server, &orgServer{service: s})
+	adminv1.RegisterDataSourceServiceServer(s.server, &dataSourceServer{service: s})
+	adminv1.RegisterDashboardServiceServer(s.server, &dashboardServer{service: s})
+	return nil
+}
+
+func (s *GRPCAdminService) IsDisabled() bool {
+	return !s.Cfg.GRPCAdmin.Enabled
+}
+
+// Run serves the gRPC admin API until the context is done, and then lets the pending requests finish.
+func (s *GRPCAdminService) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Cfg.GRPCAdmin.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Cfg.GRPCAdmin.Address, err)
+	}
+	logger.Info("gRPC admin API listening", "address", listener.Addr().String())
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		s.server.GracefulStop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,144 @@
+package grpcadmin
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/grpcadmin/adminv1"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestGRPCAdminService(t *testing.T) {
+	bus.ClearBusHandlers()
+	t.Cleanup(bus.ClearBusHandlers)
+
+	cfg := setting.NewCfg()
+	cfg.GRPCAdmin.Enabled = true
+	cfg.BasicAuthEnabled = true
+	s := &GRPCAdminService{
+		Cfg:                   cfg,
+		ContextHandler:        &contexthandler.ContextHandler{Cfg: cfg},
+		DataSourcePermissions: &datasources.PermissionsServiceImpl{Cfg: cfg},
+	}
+	require.NoError(t, s.Init())
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	t.Cleanup(s.server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	users := adminv1.NewUserServiceClient(conn)
+	dataSources := adminv1.NewDataSourceServiceClient(conn)
+
+	adminKey, err := apikeygen.New(1, "admin")
+	require.NoError(t, err)
+	viewerKey, err := apikeygen.New(1, "viewer")
+	require.NoError(t, err)
+	scopedKey, err := apikeygen.New(1, "scoped")
+	require.NoError(t, err)
+	bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+		switch query.KeyName {
+		case "admin":
+			query.Result = &models.ApiKey{Id: 1, OrgId: 1, Name: "admin", Role: models.ROLE_ADMIN, Key: adminKey.HashedKey}
+		case "viewer":
+			query.Result = &models.ApiKey{Id: 2, OrgId: 1, Name: "viewer", Role: models.ROLE_VIEWER, Key: viewerKey.HashedKey}
+		case "scoped":
+			query.Result = &models.ApiKey{Id: 3, OrgId: 1, Name: "scoped", Role: models.ROLE_ADMIN, Key: scopedKey.HashedKey,
+				Scopes: []string{"ds:query"}}
+		default:
+			return models.ErrInvalidApiKey
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.UpdateApiKeyLastUsedCommand) error {
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.LoginUserQuery) error {
+		if query.Username != "admin" || query.Password != "secret" {
+			return models.ErrUserNotFound
+		}
+		query.User = &models.User{Id: 1, Login: "admin", IsAdmin: true}
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+		query.Result = &models.SignedInUser{UserId: query.UserId, OrgId: 1, OrgRole: models.ROLE_ADMIN, Login: "admin", IsGrafanaAdmin: true}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetUserByIdQuery) error {
+		if query.Id != 2 {
+			return models.ErrUserNotFound
+		}
+		query.Result = &models.User{Id: 2, Login: "user", Email: "user@test.com"}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetDataSourcesQuery) error {
+		query.Result = []*models.DataSource{{Id: 1, Uid: "ds", OrgId: query.OrgId, Name: "ds", Type: "prometheus"}}
+		return nil
+	})
+
+	withAuthorization := func(authorization string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), authorizationMetadata, authorization)
+	}
+
+	t.Run("Requests without credentials are rejected", func(t *testing.T) {
+		_, err := dataSources.ListDataSources(context.Background(), &adminv1.ListDataSourcesRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = dataSources.ListDataSources(withAuthorization("Bearer invalid"), &adminv1.ListDataSourcesRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("API keys have the access of their role", func(t *testing.T) {
+		resp, err := dataSources.ListDataSources(withAuthorization("Bearer "+adminKey.ClientSecret), &adminv1.ListDataSourcesRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.DataSources, 1)
+		assert.Equal(t, "ds", resp.DataSources[0].Uid)
+		assert.Equal(t, int64(1), resp.DataSources[0].OrgId)
+
+		_, err = dataSources.ListDataSources(withAuthorization("Bearer "+viewerKey.ClientSecret), &adminv1.ListDataSourcesRequest{})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = users.GetUser(withAuthorization("Bearer "+adminKey.ClientSecret), &adminv1.GetUserRequest{Id: 2})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Scoped API keys are rejected", func(t *testing.T) {
+		_, err := dataSources.ListDataSources(withAuthorization("Bearer "+scopedKey.ClientSecret), &adminv1.ListDataSourcesRequest{})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Grafana admins can manage the users with basic auth", func(t *testing.T) {
+		basicAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:secret"))
+		user, err := users.GetUser(withAuthorization(basicAuth), &adminv1.GetUserRequest{Id: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "user", user.Login)
+
+		_, err = users.GetUser(withAuthorization(basicAuth), &adminv1.GetUserRequest{Id: 3})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		wrongPassword := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:wrong"))
+		_, err = users.GetUser(withAuthorization(wrongPassword), &adminv1.GetUserRequest{Id: 2})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
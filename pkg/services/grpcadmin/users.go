@@ -0,0 +1,205 @@
+package grpcadmin
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/grpcadmin/adminv1"
+)
+
+type userServer struct {
+	adminv1.UnimplementedUserServiceServer
+	service *GRPCAdminService
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *adminv1.GetUserRequest) (*adminv1.User, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Id == 0 && req.LoginOrEmail == "" {
+		return nil, status.Error(codes.InvalidArgument, "id or login_or_email is required")
+	}
+	if req.Id != 0 {
+		return getUser(req.Id)
+	}
+	query := models.GetUserByLoginQuery{LoginOrEmail: req.LoginOrEmail}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, userError(err, "failed to get user")
+	}
+	return toUser(query.Result), nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *adminv1.ListUsersRequest) (*adminv1.ListUsersResponse, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	sort, err := models.ParseSearchSort(req.Sort)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query := models.SearchUsersQuery{Query: req.Query, Limit: int(req.Limit), Page: 1, Sort: sort, Cursor: req.Cursor}
+	if query.Limit <= 0 {
+		query.Limit = 1000
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, searchError(err, "failed to list users")
+	}
+
+	resp := &adminv1.ListUsersResponse{TotalCount: query.Result.TotalCount, NextCursor: query.Result.NextCursor}
+	for _, hit := range query.Result.Users {
+		resp.Users = append(resp.Users, &adminv1.User{
+			Id:             hit.Id,
+			Login:          hit.Login,
+			Email:          hit.Email,
+			Name:           hit.Name,
+			IsGrafanaAdmin: hit.IsAdmin,
+			IsDisabled:     hit.IsDisabled,
+			LastSeenAt:     timestamppb.New(hit.LastSeenAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *userServer) CreateUser(ctx context.Context, req *adminv1.CreateUserRequest) (*adminv1.User, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	cmd := models.CreateUserCommand{
+		Login:    req.Login,
+		Email:    req.Email,
+		Name:     req.Name,
+		Password: req.Password,
+		OrgId:    req.OrgId,
+	}
+	if cmd.Login == "" {
+		cmd.Login = cmd.Email
+		if cmd.Login == "" {
+			return nil, status.Error(codes.InvalidArgument, "login or email is required")
+		}
+	}
+	if len(cmd.Password) < 4 {
+		return nil, status.Error(codes.InvalidArgument, "password is missing or too short")
+	}
+
+	user, err := s.service.Login.CreateUser(cmd)
+	if err != nil {
+		if errors.Is(err, models.ErrOrgNotFound) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, userError(err, "failed to create user")
+	}
+
+	metrics.MApiAdminUserCreate.Inc()
+	created := toUser(user)
+	auditUserChange(ctx, models.AuditActionCreate, created, nil, created)
+	return created, nil
+}
+
+func (s *userServer) UpdateUser(ctx context.Context, req *adminv1.UpdateUserRequest) (*adminv1.User, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	before, err := getUser(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.UpdateUserCommand{UserId: req.Id, Login: req.Login, Email: req.Email, Name: req.Name}
+	if cmd.Login == "" {
+		cmd.Login = cmd.Email
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return nil, userError(err, "failed to update user")
+	}
+
+	after, err := getUser(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	auditUserChange(ctx, models.AuditActionUpdate, after, before, after)
+	return after, nil
+}
+
+func (s *userServer) DeleteUser(ctx context.Context, req *adminv1.DeleteUserRequest) (*adminv1.DeleteUserResponse, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	before, err := getUser(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bus.Dispatch(&models.DeleteUserCommand{UserId: req.Id}); err != nil {
+		return nil, userError(err, "failed to delete user")
+	}
+	auditUserChange(ctx, models.AuditActionDelete, before, before, nil)
+	return &adminv1.DeleteUserResponse{}, nil
+}
+
+func getUser(id int64) (*adminv1.User, error) {
+	query := models.GetUserByIdQuery{Id: id}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, userError(err, "failed to get user")
+	}
+	return toUser(query.Result), nil
+}
+
+func toUser(user *models.User) *adminv1.User {
+	return &adminv1.User{
+		Id:             user.Id,
+		Login:          user.Login,
+		Email:          user.Email,
+		Name:           user.Name,
+		IsGrafanaAdmin: user.IsAdmin,
+		IsDisabled:     user.IsDisabled,
+		LastSeenAt:     timestamppb.New(user.LastSeenAt),
+	}
+}
+
+func auditUserChange(ctx context.Context, action models.AuditAction, user *adminv1.User, before, after *adminv1.User) {
+	cmd := &models.RecordAuditEntryCommand{
+		Action:       action,
+		ResourceType: models.AuditResourceUser,
+		ResourceId:   strconv.FormatInt(user.Id, 10),
+		ResourceName: user.Login,
+	}
+	// the typed nil pointers would be recorded as null states
+	if before != nil {
+		cmd.Before = before
+	}
+	if after != nil {
+		cmd.After = after
+	}
+	recordAuditEntry(ctx, cmd)
+}
+
+func userError(err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrUserAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, models.ErrLastGrafanaAdmin):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Errorf(codes.Internal, "%s: %s", message, err)
+}
+
+func searchError(err error, message string) error {
+	if errors.Is(err, models.ErrInvalidSearchSort) || errors.Is(err, models.ErrInvalidSearchCursor) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Errorf(codes.Internal, "%s: %s", message, err)
+}
@@ -432,6 +432,20 @@ func (server *Server) buildGrafanaUser(user *ldap.Entry) (*models.ExternalUserIn
 		}
 	}
 
+	// The teams are only synced when some mappings have teams, so that the teams added by the team sync aren't
+	// removed.
+	for _, group := range server.Config.Groups {
+		if group.Teams == nil {
+			continue
+		}
+		if extUser.Teams == nil {
+			extUser.Teams = []string{}
+		}
+		if isMemberOf(memberOf, group.GroupDN) {
+			extUser.Teams = append(extUser.Teams, group.Teams...)
+		}
+	}
+
 	// If there are group org mappings configured, but no matching mappings,
 	// the user will not be able to login and will be disabled
 	if len(server.Config.Groups) > 0 && len(extUser.OrgRoles) == 0 {
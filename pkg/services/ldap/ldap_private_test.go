@@ -144,6 +144,37 @@ func TestLDAPPrivateMethods(t *testing.T) {
 			So(len(result), ShouldEqual, 1)
 			So(result[0].IsDisabled, ShouldBeTrue)
 		})
+
+		Convey("a user should get the teams of its matching groups", func() {
+			server := &Server{
+				Config: &ServerConfig{
+					Attr: AttributeMap{
+						MemberOf: "memberof",
+					},
+					Groups: []*GroupToOrgRole{
+						{GroupDN: "admins", OrgId: 1, OrgRole: models.ROLE_ADMIN, Teams: []string{"Ops"}},
+						{GroupDN: "*", OrgId: 1, OrgRole: models.ROLE_VIEWER, Teams: []string{"Everyone"}},
+						{GroupDN: "devs", OrgId: 1, OrgRole: models.ROLE_EDITOR, Teams: []string{"Dev"}},
+					},
+				},
+				Connection: &MockConnection{},
+				log:        log.New("test-logger"),
+			}
+
+			entry := ldap.Entry{
+				DN: "dn",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "memberof", Values: []string{"admins"}},
+				},
+			}
+			users := []*ldap.Entry{&entry}
+
+			result, err := server.serializeUsers(users)
+
+			So(err, ShouldBeNil)
+			So(result[0].OrgRoles[1], ShouldEqual, models.ROLE_ADMIN)
+			So(result[0].Teams, ShouldResemble, []string{"Ops", "Everyone"})
+		})
 	})
 
 	Convey("validateGrafanaUser()", t, func() {
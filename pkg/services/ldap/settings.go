@@ -61,6 +61,9 @@ type GroupToOrgRole struct {
 	IsGrafanaAdmin *bool `toml:"grafana_admin"`
 
 	OrgRole models.RoleType `toml:"org_role"`
+
+	// Teams are the names of the teams the members of the group are added to in their orgs.
+	Teams []string `toml:"teams"`
 }
 
 // logger for all LDAP stuff
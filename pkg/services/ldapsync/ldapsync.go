@@ -0,0 +1,290 @@
+// Package ldapsync syncs the LDAP users in the background, so that the changes of their groups and the users removed
+// from LDAP apply without waiting for them to log in again.
+package ldapsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// keptRuns is the number of runs kept with their report, the older ones are deleted.
+	keptRuns = 100
+	// searchPageSize is the number of Grafana users read at once.
+	searchPageSize = 500
+)
+
+// Stubbable by tests.
+var (
+	getLDAPConfig = multildap.GetConfig
+	newLDAP       = multildap.New
+)
+
+// LDAPSyncService syncs the roles, teams and status of the LDAP users on the sync_cron schedule, and runs the syncs
+// requested by the admins.
+type LDAPSyncService struct {
+	Cfg               *setting.Cfg                  `inject:""`
+	SQLStore          *sqlstore.SQLStore            `inject:""`
+	ServerLockService *serverlock.ServerLockService `inject:""`
+	AuthTokenService  models.UserTokenService       `inject:""`
+
+	log      log.Logger
+	schedule cron.Schedule
+	// running is 1 while a sync runs in this instance.
+	running int32
+}
+
+func init() {
+	registry.RegisterService(&LDAPSyncService{})
+}
+
+func (s *LDAPSyncService) Init() error {
+	s.log = log.New("ldap.sync")
+	if !s.Cfg.LDAPEnabled || !s.Cfg.LDAPActiveSyncEnabled {
+		return nil
+	}
+
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	schedule, err := parser.Parse(s.Cfg.LDAPSyncCron)
+	if err != nil {
+		return fmt.Errorf("invalid LDAP sync_cron %q: %w", s.Cfg.LDAPSyncCron, err)
+	}
+	s.schedule = schedule
+	return nil
+}
+
+// IsDisabled returns whether the scheduled sync is disabled. The syncs requested by the admins run when LDAP is
+// enabled.
+func (s *LDAPSyncService) IsDisabled() bool {
+	return s.schedule == nil
+}
+
+func (s *LDAPSyncService) Run(ctx context.Context) error {
+	for {
+		now := time.Now()
+		next := s.schedule.Next(now)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		// All the instances wake up on the schedule, the first one to get the lock runs the sync and the others skip
+		// it.
+		interval := s.schedule.Next(next).Sub(next) / 2
+		err := s.ServerLockService.LockAndExecute(ctx, "ldap sync", interval, func() {
+			if _, err := s.Sync(ctx, models.LDAPSyncTriggerSchedule); err != nil {
+				s.log.Error("Failed to start LDAP sync", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to lock and execute LDAP sync", "error", err)
+		}
+	}
+}
+
+// Sync runs a sync of the LDAP users, and returns its run once it's finished.
+func (s *LDAPSyncService) Sync(ctx context.Context, trigger models.LDAPSyncTrigger) (*models.LDAPSyncRun, error) {
+	run, err := s.startRun(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	s.finishRun(ctx, run)
+	return run, nil
+}
+
+// StartSync starts a sync of the LDAP users in the background, and returns its run while it's running.
+func (s *LDAPSyncService) StartSync(ctx context.Context) (*models.LDAPSyncRun, error) {
+	run, err := s.startRun(ctx, models.LDAPSyncTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	// The sync isn't canceled with the request which started it.
+	go s.finishRun(context.Background(), run)
+	return &started, nil
+}
+
+func (s *LDAPSyncService) startRun(ctx context.Context, trigger models.LDAPSyncTrigger) (*models.LDAPSyncRun, error) {
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return nil, models.ErrLDAPSyncAlreadyActive
+	}
+
+	now := time.Now()
+	run := &models.LDAPSyncRun{TriggeredBy: trigger, State: models.LDAPSyncRunning, Started: now, Finished: now}
+	if err := s.SQLStore.CreateLDAPSyncRun(ctx, run); err != nil {
+		atomic.StoreInt32(&s.running, 0)
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *LDAPSyncService) finishRun(ctx context.Context, run *models.LDAPSyncRun) {
+	defer atomic.StoreInt32(&s.running, 0)
+
+	s.log.Info("Syncing LDAP users", "runId", run.Id, "triggeredBy", run.TriggeredBy)
+	results, err := s.syncUsers(ctx, run)
+	run.State = models.LDAPSyncSucceeded
+	if err != nil {
+		s.log.Error("LDAP sync failed", "runId", run.Id, "error", err)
+		run.State = models.LDAPSyncFailed
+		run.Error = err.Error()
+	}
+	report, err := json.Marshal(results)
+	if err != nil {
+		s.log.Error("Failed to encode LDAP sync report", "runId", run.Id, "error", err)
+	}
+	run.Report = string(report)
+	run.Finished = time.Now()
+
+	// The run is saved when the sync is canceled by the shutdown, so that it isn't left running.
+	if err := s.SQLStore.UpdateLDAPSyncRun(context.Background(), run); err != nil {
+		s.log.Error("Failed to save LDAP sync run", "runId", run.Id, "error", err)
+	}
+	if err := s.SQLStore.DeleteOldLDAPSyncRuns(context.Background(), keptRuns); err != nil {
+		s.log.Error("Failed to delete old LDAP sync runs", "error", err)
+	}
+	s.log.Info("Synced LDAP users", "runId", run.Id, "state", run.State, "users", run.Users,
+		"disabled", run.Disabled, "enabled", run.Enabled, "failed", run.Failed, "duration", run.Finished.Sub(run.Started))
+}
+
+// syncUsers syncs the Grafana users which logged in with LDAP with their LDAP users, and counts them in the run.
+// It doesn't sync any user when a server is unavailable, since its users would be disabled.
+func (s *LDAPSyncService) syncUsers(ctx context.Context, run *models.LDAPSyncRun) ([]models.LDAPSyncUserResult, error) {
+	results := []models.LDAPSyncUserResult{}
+
+	config, err := getLDAPConfig(s.Cfg)
+	if err != nil {
+		return results, fmt.Errorf("failed to get LDAP config: %w", err)
+	}
+	if config == nil {
+		return results, errors.New("LDAP is not enabled")
+	}
+	ldap := newLDAP(config.Servers)
+
+	statuses, err := ldap.Ping()
+	if err != nil {
+		return results, fmt.Errorf("failed to ping LDAP servers: %w", err)
+	}
+	for _, status := range statuses {
+		if !status.Available {
+			return results, fmt.Errorf("LDAP server %s:%d is unavailable: %v", status.Host, status.Port, status.Error)
+		}
+	}
+
+	users, err := getLDAPUsers()
+	if err != nil {
+		return results, fmt.Errorf("failed to get LDAP users: %w", err)
+	}
+	if len(users) == 0 {
+		return results, nil
+	}
+	logins := make([]string, 0, len(users))
+	for _, user := range users {
+		logins = append(logins, user.Login)
+	}
+	extUsers, err := ldap.Users(logins)
+	if err != nil {
+		return results, fmt.Errorf("failed to search users in LDAP: %w", err)
+	}
+	extUsersByLogin := make(map[string]*models.ExternalUserInfo, len(extUsers))
+	for _, extUser := range extUsers {
+		extUsersByLogin[strings.ToLower(extUser.Login)] = extUser
+	}
+
+	for _, user := range users {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		run.Users++
+		action, err := s.syncUser(ctx, user, extUsersByLogin[strings.ToLower(user.Login)])
+		switch {
+		case err != nil:
+			s.log.Warn("Failed to sync LDAP user", "runId", run.Id, "userId", user.Id, "error", err)
+			run.Failed++
+			results = append(results, models.LDAPSyncUserResult{UserId: user.Id, Login: user.Login, Action: models.LDAPSyncUserFailed, Error: err.Error()})
+			continue
+		case action == models.LDAPSyncUserDisabled:
+			run.Disabled++
+		case action == models.LDAPSyncUserEnabled:
+			run.Enabled++
+		default:
+			run.Synced++
+			continue
+		}
+		results = append(results, models.LDAPSyncUserResult{UserId: user.Id, Login: user.Login, Action: action})
+	}
+
+	return results, nil
+}
+
+// syncUser syncs the Grafana user with its LDAP user, or disables it when it isn't in LDAP anymore or doesn't match
+// any group mapping. It returns whether it disabled or enabled the user, or an empty action when it only synced it.
+func (s *LDAPSyncService) syncUser(ctx context.Context, user *models.UserSearchHitDTO,
+	extUser *models.ExternalUserInfo) (models.LDAPSyncUserAction, error) {
+	if extUser == nil || extUser.IsDisabled {
+		if user.IsDisabled {
+			return "", nil
+		}
+		if user.Login == s.Cfg.AdminUser {
+			return "", fmt.Errorf("refusing to disable the Grafana admin %q", user.Login)
+		}
+		if err := bus.Dispatch(&models.DisableUserCommand{UserId: user.Id, IsDisabled: true}); err != nil {
+			return "", err
+		}
+		if err := s.AuthTokenService.RevokeAllUserTokens(ctx, user.Id); err != nil {
+			return "", err
+		}
+		return models.LDAPSyncUserDisabled, nil
+	}
+
+	extUser.UserId = user.Id
+	upsertCmd := &models.UpsertUserCommand{
+		ExternalUser:  extUser,
+		SignupAllowed: s.Cfg.LDAPAllowSignup,
+	}
+	if err := bus.Dispatch(upsertCmd); err != nil {
+		return "", err
+	}
+	if user.IsDisabled {
+		return models.LDAPSyncUserEnabled, nil
+	}
+	return "", nil
+}
+
+// getLDAPUsers returns the Grafana users which last logged in with LDAP.
+func getLDAPUsers() ([]*models.UserSearchHitDTO, error) {
+	var users []*models.UserSearchHitDTO
+	query := &models.SearchUsersQuery{AuthModule: models.AuthModuleLDAP, Limit: searchPageSize}
+	for {
+		if err := bus.Dispatch(query); err != nil {
+			return nil, err
+		}
+		users = append(users, query.Result.Users...)
+		if query.Result.NextCursor == "" {
+			return users, nil
+		}
+		query.Cursor = query.Result.NextCursor
+	}
+}
@@ -0,0 +1,134 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLDAP struct {
+	multildap.IMultiLDAP
+	statuses []*multildap.ServerStatus
+	users    []*models.ExternalUserInfo
+}
+
+func (f *fakeLDAP) Ping() ([]*multildap.ServerStatus, error) {
+	return f.statuses, nil
+}
+
+func (f *fakeLDAP) Users(logins []string) ([]*models.ExternalUserInfo, error) {
+	return f.users, nil
+}
+
+func TestLDAPSyncService(t *testing.T) {
+	setup := func(t *testing.T, fake *fakeLDAP, users []*models.UserSearchHitDTO) (*LDAPSyncService, map[int64]bool, *[]*models.ExternalUserInfo) {
+		origGetLDAPConfig, origNewLDAP := getLDAPConfig, newLDAP
+		t.Cleanup(func() {
+			getLDAPConfig, newLDAP = origGetLDAPConfig, origNewLDAP
+		})
+		getLDAPConfig = func(*setting.Cfg) (*ldap.Config, error) {
+			return &ldap.Config{Servers: []*ldap.ServerConfig{{Host: "ldap.example.com"}}}, nil
+		}
+		newLDAP = func([]*ldap.ServerConfig) multildap.IMultiLDAP {
+			return fake
+		}
+
+		sqlStore := sqlstore.InitTestDB(t)
+		disabled := map[int64]bool{}
+		var upserted []*models.ExternalUserInfo
+		bus.ClearBusHandlers()
+		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandler("test", func(query *models.SearchUsersQuery) error {
+			require.Equal(t, models.AuthModuleLDAP, query.AuthModule)
+			query.Result = models.SearchUserQueryResult{Users: users}
+			return nil
+		})
+		bus.AddHandler("test", func(cmd *models.DisableUserCommand) error {
+			disabled[cmd.UserId] = cmd.IsDisabled
+			return nil
+		})
+		bus.AddHandler("test", func(cmd *models.UpsertUserCommand) error {
+			if cmd.ExternalUser.Login == "broken" {
+				return errors.New("upsert failed")
+			}
+			upserted = append(upserted, cmd.ExternalUser)
+			return nil
+		})
+
+		s := &LDAPSyncService{
+			Cfg:              &setting.Cfg{AdminUser: "admin", LDAPEnabled: true},
+			SQLStore:         sqlStore,
+			AuthTokenService: auth.NewFakeUserAuthTokenService(),
+			log:              log.New("test"),
+		}
+		return s, disabled, &upserted
+	}
+
+	t.Run("Syncs, disables and enables the users and reports them", func(t *testing.T) {
+		fake := &fakeLDAP{
+			statuses: []*multildap.ServerStatus{{Host: "ldap.example.com", Available: true}},
+			users: []*models.ExternalUserInfo{
+				{Login: "Jane"},
+				{Login: "bob"},
+				{Login: "nogroup", IsDisabled: true},
+				{Login: "broken"},
+			},
+		}
+		s, disabled, upserted := setup(t, fake, []*models.UserSearchHitDTO{
+			{Id: 1, Login: "jane"},
+			{Id: 2, Login: "bob", IsDisabled: true},
+			{Id: 3, Login: "gone"},
+			{Id: 4, Login: "nogroup"},
+			{Id: 5, Login: "admin"},
+			{Id: 6, Login: "broken"},
+		})
+
+		run, err := s.Sync(context.Background(), models.LDAPSyncTriggerSchedule)
+		require.NoError(t, err)
+		assert.Equal(t, models.LDAPSyncSucceeded, run.State)
+		assert.Equal(t, []int{6, 1, 2, 1, 2}, []int{run.Users, run.Synced, run.Disabled, run.Enabled, run.Failed})
+		assert.Equal(t, map[int64]bool{3: true, 4: true}, disabled)
+		require.Len(t, *upserted, 2)
+		assert.Equal(t, int64(1), (*upserted)[0].UserId)
+
+		saved, err := s.SQLStore.GetLDAPSyncRun(context.Background(), run.Id)
+		require.NoError(t, err)
+		report := saved.ToDTO(true).Report
+		require.Len(t, report, 5)
+		assert.Equal(t, models.LDAPSyncUserResult{UserId: 2, Login: "bob", Action: models.LDAPSyncUserEnabled}, report[0])
+		assert.Equal(t, models.LDAPSyncUserFailed, report[3].Action)
+		assert.Contains(t, report[3].Error, "Grafana admin")
+	})
+
+	t.Run("Doesn't sync the users when a server is unavailable", func(t *testing.T) {
+		fake := &fakeLDAP{
+			statuses: []*multildap.ServerStatus{{Host: "ldap.example.com", Port: 389, Error: errors.New("timeout")}},
+		}
+		s, disabled, _ := setup(t, fake, []*models.UserSearchHitDTO{{Id: 3, Login: "gone"}})
+
+		run, err := s.Sync(context.Background(), models.LDAPSyncTriggerManual)
+		require.NoError(t, err)
+		assert.Equal(t, models.LDAPSyncFailed, run.State)
+		assert.Equal(t, "LDAP server ldap.example.com:389 is unavailable: timeout", run.Error)
+		assert.Empty(t, disabled)
+	})
+
+	t.Run("Runs one sync at a time", func(t *testing.T) {
+		s, _, _ := setup(t, &fakeLDAP{}, nil)
+		s.running = 1
+
+		_, err := s.StartSync(context.Background())
+		require.ErrorIs(t, err, models.ErrLDAPSyncAlreadyActive)
+	})
+}
@@ -133,6 +133,14 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 	return nil
 }
 
+// Shutdown disconnects the clients with the shutdown reason, so that they reconnect to another instance.
+func (g *GrafanaLive) Shutdown(ctx context.Context) error {
+	if g.node == nil {
+		return nil
+	}
+	return g.node.Shutdown(ctx)
+}
+
 var clientConcurrency = 8
 
 // Init initializes Live service.
@@ -119,6 +119,10 @@ func (ls *Implementation) UpsertUser(cmd *models.UpsertUserCommand) error {
 		}
 	}
 
+	if err := ls.syncTeams(cmd.Result, extUser); err != nil {
+		return err
+	}
+
 	if ls.TeamSync != nil {
 		err := ls.TeamSync(cmd.Result, extUser)
 		if err != nil {
@@ -129,6 +133,76 @@ func (ls *Implementation) UpsertUser(cmd *models.UpsertUserCommand) error {
 	return nil
 }
 
+// syncTeams adds the user to the teams of its orgs named by the external user, and removes it from the teams it was
+// added to by a previous sync which aren't named anymore. The teams which don't exist are ignored, and the memberships
+// added by the team admins are kept.
+func (ls *Implementation) syncTeams(user *models.User, extUser *models.ExternalUserInfo) error {
+	if extUser.Teams == nil {
+		return nil
+	}
+
+	logger.Debug("Syncing teams", "id", user.Id, "extTeams", extUser.Teams)
+	extTeams := map[string]bool{}
+	for _, name := range extUser.Teams {
+		extTeams[name] = true
+	}
+
+	orgsQuery := &models.GetUserOrgListQuery{UserId: user.Id}
+	if err := bus.Dispatch(orgsQuery); err != nil {
+		return err
+	}
+
+	for _, org := range orgsQuery.Result {
+		teamsQuery := &models.GetTeamsByUserQuery{OrgId: org.OrgId, UserId: user.Id}
+		if err := bus.Dispatch(teamsQuery); err != nil {
+			return err
+		}
+		teamNames := map[int64]string{}
+		for _, team := range teamsQuery.Result {
+			teamNames[team.Id] = team.Name
+		}
+
+		membersQuery := &models.GetTeamMembersQuery{OrgId: org.OrgId, UserId: user.Id, External: true}
+		if err := bus.Dispatch(membersQuery); err != nil {
+			return err
+		}
+		for _, member := range membersQuery.Result {
+			if extTeams[teamNames[member.TeamId]] {
+				continue
+			}
+			cmd := &models.RemoveTeamMemberCommand{OrgId: org.OrgId, UserId: user.Id, TeamId: member.TeamId}
+			if err := bus.Dispatch(cmd); err != nil && !errors.Is(err, models.ErrTeamMemberNotFound) {
+				return err
+			}
+		}
+
+		isMember := map[string]bool{}
+		for _, name := range teamNames {
+			isMember[name] = true
+		}
+		for name := range extTeams {
+			if isMember[name] {
+				continue
+			}
+			searchQuery := &models.SearchTeamsQuery{OrgId: org.OrgId, Name: name, Limit: 1, Page: 1}
+			if err := bus.Dispatch(searchQuery); err != nil {
+				return err
+			}
+			if len(searchQuery.Result.Teams) == 0 {
+				logger.Debug("Not syncing team which doesn't exist", "orgId", org.OrgId, "team", name)
+				continue
+			}
+			teamID := searchQuery.Result.Teams[0].Id
+			if err := ls.SQLStore.AddTeamMember(user.Id, org.OrgId, teamID, true, 0); err != nil &&
+				!errors.Is(err, models.ErrTeamMemberAlreadyAdded) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // SetTeamSyncFunc sets the function received through args as the team sync function.
 func (ls *Implementation) SetTeamSyncFunc(teamSyncFunc login.TeamSyncFunc) {
 	ls.TeamSync = teamSyncFunc
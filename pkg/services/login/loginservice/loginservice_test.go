@@ -1,12 +1,14 @@
 package loginservice
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	log "github.com/inconshreveable/log15"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -130,6 +132,57 @@ func Test_teamSync(t *testing.T) {
 	})
 }
 
+func Test_syncTeams(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	login := Implementation{SQLStore: sqlStore, Bus: bus.New()}
+
+	bus.ClearBusHandlers()
+	t.Cleanup(func() { bus.ClearBusHandlers() })
+	bus.AddHandler("test", sqlstore.GetUserOrgList)
+	bus.AddHandler("test", sqlstore.GetTeamsByUser)
+	bus.AddHandler("test", sqlstore.GetTeamMembers)
+	bus.AddHandler("test", sqlstore.SearchTeams)
+	bus.AddHandler("test", sqlstore.RemoveTeamMember)
+
+	user, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "test_user"})
+	require.NoError(t, err)
+	teamIDs := map[string]int64{}
+	for _, name := range []string{"Ops", "Dev", "Managed"} {
+		team, err := sqlStore.CreateTeam(name, "", user.OrgId)
+		require.NoError(t, err)
+		teamIDs[name] = team.Id
+	}
+	require.NoError(t, sqlStore.AddTeamMember(user.Id, user.OrgId, teamIDs["Managed"], false, 0))
+
+	teamsOfUser := func(t *testing.T) []string {
+		query := &models.GetTeamsByUserQuery{OrgId: user.OrgId, UserId: user.Id}
+		require.NoError(t, sqlstore.GetTeamsByUser(query))
+		names := []string{}
+		for _, team := range query.Result {
+			names = append(names, team.Name)
+		}
+		return names
+	}
+
+	t.Run("Adds the user to the named teams which exist", func(t *testing.T) {
+		err := login.syncTeams(user, &models.ExternalUserInfo{Teams: []string{"Ops", "Dev", "Unknown"}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Ops", "Dev", "Managed"}, teamsOfUser(t))
+	})
+
+	t.Run("Removes the user from the synced teams which aren't named anymore", func(t *testing.T) {
+		err := login.syncTeams(user, &models.ExternalUserInfo{Teams: []string{"Dev"}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Dev", "Managed"}, teamsOfUser(t))
+	})
+
+	t.Run("Doesn't sync the teams when they're nil", func(t *testing.T) {
+		err := login.syncTeams(user, &models.ExternalUserInfo{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Dev", "Managed"}, teamsOfUser(t))
+	})
+}
+
 func createSimpleUser() models.User {
 	user := models.User{
 		Id: 1,
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -10,9 +11,12 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -26,32 +30,46 @@ type Alertmanager interface {
 	// Configuration
 	SaveAndApplyConfig(config *apimodels.PostableUserConfig) error
 	GetStatus() apimodels.GettableStatus
+	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*apimodels.TestReceiversResult, error)
+	TestTemplates(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*apimodels.TestTemplatesResults, error)
+	TestRoutes(c apimodels.TestRoutesConfigBodyParams) (*apimodels.TestRoutesResult, error)
 
 	// Silences
 	CreateSilence(ps *apimodels.PostableSilence) (string, error)
 	DeleteSilence(silenceID string) error
 	GetSilence(silenceID string) (apimodels.GettableSilence, error)
 	ListSilences(filter []string) (apimodels.GettableSilences, error)
+	PreviewSilence(ps *apimodels.PostableSilence) (apimodels.GettableAlerts, error)
 
 	// Alerts
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
 	GetAlertGroups(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.AlertGroups, error)
+
+	// Notifications
+	ResendNotification(ctx context.Context, id int64) error
+	AcknowledgeEscalation(id int64, login string) error
 }
 
 // API handlers.
 type API struct {
-	Cfg             *setting.Cfg
-	DatasourceCache datasources.CacheService
-	RouteRegister   routing.RouteRegister
-	DataService     *tsdb.Service
-	QuotaService    *quota.QuotaService
-	Schedule        schedule.ScheduleService
-	RuleStore       store.RuleStore
-	InstanceStore   store.InstanceStore
-	AlertingStore   store.AlertingStore
-	DataProxy       *datasourceproxy.DatasourceProxyService
-	Alertmanager    Alertmanager
-	StateManager    *state.Manager
+	Cfg               *setting.Cfg
+	DatasourceCache   datasources.CacheService
+	RouteRegister     routing.RouteRegister
+	DataService       *tsdb.Service
+	QuotaService      *quota.QuotaService
+	Schedule          schedule.ScheduleService
+	RuleStore         store.RuleStore
+	InstanceStore     store.InstanceStore
+	AlertingStore     store.AlertingStore
+	ProvisioningStore store.ProvisioningStore
+	HistoryStore      store.StateHistoryStore
+	HeartbeatStore    store.AlertRuleHeartbeatStore
+	AdminConfigStore  store.AdminConfigurationStore
+	DataProxy         *datasourceproxy.DatasourceProxyService
+	Alertmanager      Alertmanager
+	StateManager      *state.Manager
+	AccessControl     accesscontrol.AccessControl
+	RemoteCache       remotecache.CacheStorage
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -61,23 +79,26 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 		DataProxy: api.DataProxy,
 	}
 
+	configStore := provisioning.NewConfigStore(api.AlertingStore, api.Alertmanager)
+	muteTimings := provisioning.NewMuteTimingService(configStore, api.ProvisioningStore, logger)
+
 	// Register endpoints for proxing to Alertmanager-compatible backends.
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		AlertmanagerSrv{store: api.AlertingStore, am: api.Alertmanager, log: logger},
+		AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvisioningStore, muteTimings: muteTimings, am: api.Alertmanager, ac: api.AccessControl, log: logger},
 	), m)
 	// Register endpoints for proxing to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore},
+		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, ac: api.AccessControl},
 	), m)
 	// Register endpoints for proxing to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, provenanceStore: api.ProvisioningStore, ac: api.AccessControl, log: logger},
 	), m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
@@ -86,4 +107,52 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 		DatasourceCache: api.DatasourceCache,
 		log:             logger,
 	}, m)
+
+	api.RegisterHistoryApiEndpoints(HistorySrv{
+		log:             logger,
+		store:           api.RuleStore,
+		historyStore:    api.HistoryStore,
+		provenanceStore: api.ProvisioningStore,
+		manager:         api.StateManager,
+		ac:              api.AccessControl,
+	}, m)
+
+	api.RegisterRulesApiEndpoints(RulesSrv{
+		log:   logger,
+		store: api.RuleStore,
+		ac:    api.AccessControl,
+	}, m)
+
+	api.RegisterCompatApiEndpoints(CompatSrv{
+		log:             logger,
+		manager:         api.StateManager,
+		store:           api.RuleStore,
+		DatasourceCache: api.DatasourceCache,
+		ac:              api.AccessControl,
+	}, m)
+
+	api.RegisterHeartbeatApiEndpoints(HeartbeatSrv{
+		log:            logger,
+		store:          api.RuleStore,
+		heartbeatStore: api.HeartbeatStore,
+		ac:             api.AccessControl,
+	}, m)
+
+	api.RegisterConfigurationApiEndpoints(AdminSrv{
+		store:     api.AdminConfigStore,
+		scheduler: api.Schedule,
+		log:       logger,
+	}, m)
+
+	api.RegisterProvisioningApiEndpoints(ProvisioningSrv{
+		log:             logger,
+		store:           api.RuleStore,
+		DatasourceCache: api.DatasourceCache,
+		QuotaService:    api.QuotaService,
+		manager:         api.StateManager,
+		alertRules:      provisioning.NewAlertRuleService(api.RuleStore, api.ProvisioningStore, logger),
+		contactPoints:   provisioning.NewContactPointService(configStore, api.ProvisioningStore, logger),
+		policies:        provisioning.NewNotificationPolicyService(configStore, api.ProvisioningStore, logger),
+		muteTimings:     muteTimings,
+	}, m)
 }
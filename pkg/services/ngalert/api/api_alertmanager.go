@@ -1,24 +1,43 @@
 package api
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+var errUnknownReceiver = errors.New("unknown receiver")
+
+const (
+	defaultNotificationLogLimit int64 = 100
+	maxNotificationLogLimit     int64 = 1000
+)
+
 type AlertmanagerSrv struct {
-	am    Alertmanager
-	store store.AlertingStore
-	log   log.Logger
+	am              Alertmanager
+	store           store.AlertingStore
+	provenanceStore store.ProvisioningStore
+	muteTimings     *provisioning.MuteTimingService
+	ac              accesscontrol.AccessControl
+	log             log.Logger
 }
 
 func (srv AlertmanagerSrv) RouteGetAMStatus(c *models.ReqContext) response.Response {
@@ -26,8 +45,8 @@ func (srv AlertmanagerSrv) RouteGetAMStatus(c *models.ReqContext) response.Respo
 }
 
 func (srv AlertmanagerSrv) RouteCreateSilence(c *models.ReqContext, postableSilence apimodels.PostableSilence) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
 	}
 	silenceID, err := srv.am.CreateSilence(&postableSilence)
 	if err != nil {
@@ -50,8 +69,8 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 }
 
 func (srv AlertmanagerSrv) RouteDeleteSilence(c *models.ReqContext) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
 	}
 	silenceID := c.Params(":SilenceId")
 	if err := srv.am.DeleteSilence(silenceID); err != nil {
@@ -182,9 +201,86 @@ func (srv AlertmanagerSrv) RouteGetSilences(c *models.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, gettableSilences)
 }
 
+func (srv AlertmanagerSrv) RoutePreviewSilence(c *models.ReqContext, postableSilence apimodels.PostableSilence) response.Response {
+	alerts, err := srv.am.PreviewSilence(&postableSilence)
+	if err != nil {
+		if errors.Is(err, notifier.ErrPreviewSilenceBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to preview silence")
+	}
+
+	return response.JSON(http.StatusOK, alerts)
+}
+
+func (srv AlertmanagerSrv) RouteGetNotificationLog(c *models.ReqContext) response.Response {
+	query := ngmodels.ListNotificationAttemptsQuery{
+		Receiver:        c.Query("receiver"),
+		IntegrationType: c.Query("integration"),
+		OnlyFailed:      c.QueryBool("failed"),
+		Limit:           c.QueryInt64("limit"),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultNotificationLogLimit
+	}
+	if query.Limit > maxNotificationLogLimit {
+		query.Limit = maxNotificationLogLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return ErrResp(http.StatusBadRequest, errors.New("'to' must not be before 'from'"), "")
+	}
+
+	if err := srv.store.ListNotificationAttempts(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification log")
+	}
+
+	result := apimodels.NotificationLog{
+		Notifications: make([]apimodels.NotificationAttempt, 0, len(query.Result)),
+	}
+	for _, attempt := range query.Result {
+		result.Notifications = append(result.Notifications, apimodels.NotificationAttempt{
+			ID:               attempt.ID,
+			Receiver:         attempt.Receiver,
+			IntegrationType:  attempt.IntegrationType,
+			IntegrationIndex: attempt.IntegrationIndex,
+			IntegrationUID:   attempt.IntegrationUID,
+			GroupKey:         attempt.GroupKey,
+			AlertsCount:      len(attempt.Alerts),
+			Success:          attempt.Succeeded(),
+			StatusCode:       attempt.StatusCode,
+			Error:            attempt.Error,
+			Suppressed:       attempt.Suppressed,
+			DurationMs:       attempt.DurationMs,
+			Timestamp:        time.Unix(attempt.CreatedAt, 0),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteResendNotification(c *models.ReqContext) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+
+	id := c.ParamsInt64(":NotificationId")
+	if err := srv.am.ResendNotification(c.Req.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotificationAttemptNotFound) || errors.Is(err, notifier.ErrNotificationIntegrationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusBadGateway, err, "failed to resend notification")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "notification resent"})
+}
+
 func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body apimodels.PostableUserConfig) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
 	}
 
 	// Get the last known working configuration
@@ -196,9 +292,11 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		}
 	}
 
+	var currentConfig *apimodels.PostableUserConfig
 	currentReceiverMap := make(map[string]*apimodels.PostableGrafanaReceiver)
 	if query.Result != nil {
-		currentConfig, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+		var err error
+		currentConfig, err = notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
 		if err != nil {
 			return ErrResp(http.StatusInternalServerError, err, "failed to load lastest configuration")
 		}
@@ -206,35 +304,19 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 	}
 
 	// Copy the previously known secure settings
-	for i, r := range body.AlertmanagerConfig.Receivers {
-		for j, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
-			if gr.UID == "" { // new receiver
-				continue
-			}
-
-			cgmr, ok := currentReceiverMap[gr.UID]
-			if !ok {
-				// it tries to update a receiver that didn't previously exist
-				return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown receiver: %s", gr.UID), "")
-			}
+	if err := copyStoredSecureSettings(body.AlertmanagerConfig.Receivers, currentReceiverMap); err != nil {
+		if errors.Is(err, errUnknownReceiver) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
 
-			// frontend sends only the secure settings that have to be updated
-			// therefore we have to copy from the last configuration only those secure settings not included in the request
-			for key := range cgmr.SecureSettings {
-				_, ok := body.AlertmanagerConfig.Receivers[i].PostableGrafanaReceivers.GrafanaManagedReceivers[j].SecureSettings[key]
-				if !ok {
-					decryptedValue, err := cgmr.GetDecryptedSecret(key)
-					if err != nil {
-						return ErrResp(http.StatusInternalServerError, err, "failed to decrypt stored secure setting: %s", key)
-					}
-
-					if body.AlertmanagerConfig.Receivers[i].PostableGrafanaReceivers.GrafanaManagedReceivers[j].SecureSettings == nil {
-						body.AlertmanagerConfig.Receivers[i].PostableGrafanaReceivers.GrafanaManagedReceivers[j].SecureSettings = make(map[string]string, len(cgmr.SecureSettings))
-					}
-
-					body.AlertmanagerConfig.Receivers[i].PostableGrafanaReceivers.GrafanaManagedReceivers[j].SecureSettings[key] = decryptedValue
-				}
+	if currentConfig != nil {
+		if err := srv.checkProvisionedChanges(c.OrgId, currentConfig, &body); err != nil {
+			if errors.Is(err, ngmodels.ErrProvenanceChangeNotAllowed) {
+				return ErrResp(http.StatusConflict, err, "")
 			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to check provisioned resources")
 		}
 	}
 
@@ -250,7 +332,652 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
 }
 
+func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body apimodels.TestReceiversConfigBodyParams) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+
+	if len(body.Receivers) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("no receivers to test"), "")
+	}
+	for _, r := range body.Receivers {
+		if r.Type() != apimodels.GrafanaReceiverType {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("receiver %s has no Grafana managed receiver configs", r.Name), "")
+		}
+	}
+
+	// Integrations of saved receivers can be tested without sending their secure settings again
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{}
+	currentReceiverMap := make(map[string]*apimodels.PostableGrafanaReceiver)
+	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
+		if !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+		}
+	} else {
+		currentConfig, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to load lastest configuration")
+		}
+		currentReceiverMap = currentConfig.GetGrafanaReceiverMap()
+	}
+	if err := copyStoredSecureSettings(body.Receivers, currentReceiverMap); err != nil {
+		if errors.Is(err, errUnknownReceiver) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	// The integrations expect encrypted secure settings
+	for _, r := range body.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			for k, v := range gr.SecureSettings {
+				encryptedData, err := util.Encrypt([]byte(v), setting.SecretKey)
+				if err != nil {
+					return ErrResp(http.StatusInternalServerError, err, "failed to encrypt secure settings")
+				}
+				gr.SecureSettings[k] = base64.StdEncoding.EncodeToString(encryptedData)
+			}
+		}
+	}
+
+	result, err := srv.am.TestReceivers(c.Req.Context(), body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrInvalidReceiver) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test receivers")
+	}
+
+	status := http.StatusOK
+	for _, r := range result.Receivers {
+		for _, configResult := range r.Configs {
+			if configResult.Status != notifier.TestReceiverStatusOK {
+				status = http.StatusMultiStatus
+			}
+		}
+	}
+	return response.JSON(status, result)
+}
+
+func (srv AlertmanagerSrv) RoutePostTestTemplates(c *models.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
+	result, err := srv.am.TestTemplates(c.Req.Context(), body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrInvalidTemplateName) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if errors.Is(err, store.ErrNotificationAttemptNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test templates")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RoutePostTestRoutes(c *models.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	result, err := srv.am.TestRoutes(body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrInvalidTestRouteLabels) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test routes")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetMuteTimeIntervals(c *models.ReqContext) response.Response {
+	muteTimings, err := srv.muteTimings.GetMuteTimings(c.OrgId)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get mute time intervals")
+	}
+	result := make(apimodels.MuteTimeIntervals, 0, len(muteTimings))
+	for _, mt := range muteTimings {
+		result = append(result, mt.MuteTimeInterval)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetMuteTimeInterval(c *models.ReqContext) response.Response {
+	muteTiming, err := srv.muteTimings.GetMuteTiming(c.OrgId, c.Params(":MuteTimeIntervalName"))
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get mute time interval")
+	}
+	return response.JSON(http.StatusOK, muteTiming.MuteTimeInterval)
+}
+
+func (srv AlertmanagerSrv) RoutePostMuteTimeInterval(c *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	muteTiming, err := srv.muteTimings.CreateMuteTiming(c.OrgId, apimodels.ProvisionedMuteTimeInterval{MuteTimeInterval: body}, ngmodels.ProvenanceNone)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to create mute time interval")
+	}
+	return response.JSON(http.StatusCreated, muteTiming.MuteTimeInterval)
+}
+
+func (srv AlertmanagerSrv) RoutePutMuteTimeInterval(c *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	body.Name = c.Params(":MuteTimeIntervalName")
+	muteTiming, err := srv.muteTimings.UpdateMuteTiming(c.OrgId, apimodels.ProvisionedMuteTimeInterval{MuteTimeInterval: body}, ngmodels.ProvenanceNone)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to update mute time interval")
+	}
+	return response.JSON(http.StatusOK, muteTiming.MuteTimeInterval)
+}
+
+func (srv AlertmanagerSrv) RouteDeleteMuteTimeInterval(c *models.ReqContext) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	if err := srv.muteTimings.DeleteMuteTiming(c.OrgId, c.Params(":MuteTimeIntervalName"), ngmodels.ProvenanceNone); err != nil {
+		return toProvisioningErrorResponse(err, "failed to delete mute time interval")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (srv AlertmanagerSrv) RouteGetEscalationPolicies(c *models.ReqContext) response.Response {
+	policies, err := srv.store.ListEscalationPolicies()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get escalation policies")
+	}
+	result := make(apimodels.EscalationPolicies, 0, len(policies))
+	for _, p := range policies {
+		result = append(result, toEscalationPolicy(p))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetEscalationPolicy(c *models.ReqContext) response.Response {
+	query := ngmodels.GetEscalationPolicyQuery{UID: c.Params(":EscalationPolicyUID")}
+	if err := srv.store.GetEscalationPolicy(&query); err != nil {
+		if errors.Is(err, ngmodels.ErrEscalationPolicyNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get escalation policy")
+	}
+	return response.JSON(http.StatusOK, toEscalationPolicy(query.Result))
+}
+
+func (srv AlertmanagerSrv) RoutePostEscalationPolicy(c *models.ReqContext, body apimodels.EscalationPolicy) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	policy := fromEscalationPolicy(body)
+	if resp := srv.validateEscalationPolicy(policy); resp != nil {
+		return resp
+	}
+	if err := srv.store.CreateEscalationPolicy(policy); err != nil {
+		if errors.Is(err, ngmodels.ErrEscalationPolicyExists) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create escalation policy")
+	}
+	return response.JSON(http.StatusCreated, toEscalationPolicy(policy))
+}
+
+func (srv AlertmanagerSrv) RoutePutEscalationPolicy(c *models.ReqContext, body apimodels.EscalationPolicy) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	body.UID = c.Params(":EscalationPolicyUID")
+	policy := fromEscalationPolicy(body)
+	if resp := srv.validateEscalationPolicy(policy); resp != nil {
+		return resp
+	}
+	if err := srv.store.UpdateEscalationPolicy(policy); err != nil {
+		if errors.Is(err, ngmodels.ErrEscalationPolicyNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to update escalation policy")
+	}
+	return response.JSON(http.StatusOK, toEscalationPolicy(policy))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteEscalationPolicy(c *models.ReqContext) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	uid := c.Params(":EscalationPolicyUID")
+
+	cfg, err := srv.getLatestConfig()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+	}
+	if cfg != nil {
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			for _, r := range receiver.GrafanaManagedReceivers {
+				if r.Type == "escalation" && r.Settings.Get("policyUid").MustString() == uid {
+					return ErrResp(http.StatusConflict, fmt.Errorf("escalation policy is referenced by contact point %s", receiver.Name), "")
+				}
+			}
+		}
+	}
+
+	if err := srv.store.DeleteEscalationPolicy(uid); err != nil {
+		if errors.Is(err, ngmodels.ErrEscalationPolicyNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete escalation policy")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (srv AlertmanagerSrv) RouteGetEscalations(c *models.ReqContext) response.Response {
+	query := ngmodels.ListEscalationsQuery{}
+	if err := srv.store.ListEscalations(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get escalations")
+	}
+
+	result := apimodels.Escalations{
+		Escalations: make([]apimodels.Escalation, 0, len(query.Result)),
+	}
+	for _, e := range query.Result {
+		if e.Resolved {
+			continue
+		}
+		escalation := apimodels.Escalation{
+			ID:             e.ID,
+			PolicyUID:      e.PolicyUID,
+			GroupKey:       e.GroupKey,
+			Step:           e.Step + 1,
+			AlertsCount:    len(e.Alerts),
+			AcknowledgedBy: e.AcknowledgedBy,
+			StartedAt:      time.Unix(e.CreatedAt, 0),
+		}
+		if e.NextStepAt != 0 {
+			next := time.Unix(e.NextStepAt, 0)
+			escalation.NextStepAt = &next
+		}
+		if e.Acknowledged() {
+			acknowledged := time.Unix(e.AcknowledgedAt, 0)
+			escalation.AcknowledgedAt = &acknowledged
+		}
+		result.Escalations = append(result.Escalations, escalation)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteAcknowledgeEscalation(c *models.ReqContext) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	if err := srv.am.AcknowledgeEscalation(c.ParamsInt64(":EscalationId"), c.SignedInUser.Login); err != nil {
+		if errors.Is(err, ngmodels.ErrEscalationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to acknowledge escalation")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "escalation acknowledged"})
+}
+
+func (srv AlertmanagerSrv) RouteGetSilenceExpiryNotification(c *models.ReqContext) response.Response {
+	query := ngmodels.GetSilenceExpiryNotificationQuery{SilenceID: c.Params(":SilenceId")}
+	if err := srv.store.GetSilenceExpiryNotification(&query); err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceExpiryNotificationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get silence expiry notification")
+	}
+	return response.JSON(http.StatusOK, toSilenceExpiryNotification(query.Result))
+}
+
+func (srv AlertmanagerSrv) RoutePutSilenceExpiryNotification(c *models.ReqContext, body apimodels.SilenceExpiryNotification) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	n := &ngmodels.SilenceExpiryNotification{
+		SilenceID:    c.Params(":SilenceId"),
+		Receiver:     body.Receiver,
+		NotifyBefore: time.Duration(body.NotifyBefore),
+	}
+	if err := n.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	if _, err := srv.am.GetSilence(n.SilenceID); err != nil {
+		if errors.Is(err, notifier.ErrSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get silence")
+	}
+
+	cfg, err := srv.getLatestConfig()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+	}
+	if cfg != nil && !hasReceiver(cfg, n.Receiver) {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: %s", errUnknownReceiver, n.Receiver), "")
+	}
+
+	if err := srv.store.SaveSilenceExpiryNotification(n); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save silence expiry notification")
+	}
+	return response.JSON(http.StatusOK, toSilenceExpiryNotification(n))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteSilenceExpiryNotification(c *models.ReqContext) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	if err := srv.store.DeleteSilenceExpiryNotification(c.Params(":SilenceId")); err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceExpiryNotificationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete silence expiry notification")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func toSilenceExpiryNotification(n *ngmodels.SilenceExpiryNotification) apimodels.SilenceExpiryNotification {
+	result := apimodels.SilenceExpiryNotification{
+		SilenceID:    n.SilenceID,
+		Receiver:     n.Receiver,
+		NotifyBefore: model.Duration(n.NotifyBefore),
+	}
+	if n.NotifiedAt != 0 {
+		notified := time.Unix(n.NotifiedAt, 0)
+		result.NotifiedAt = &notified
+	}
+	return result
+}
+
+func (srv AlertmanagerSrv) RouteGetMaintenance(c *models.ReqContext) response.Response {
+	query := ngmodels.GetMaintenanceQuery{OrgID: c.OrgId}
+	if err := srv.store.GetMaintenance(&query); err != nil {
+		if errors.Is(err, ngmodels.ErrMaintenanceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get maintenance")
+	}
+	return response.JSON(http.StatusOK, toMaintenance(query.Result, time.Now()))
+}
+
+func (srv AlertmanagerSrv) RoutePutMaintenance(c *models.ReqContext, body apimodels.Maintenance) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	now := time.Now()
+	m := &ngmodels.Maintenance{
+		OrgID:     c.OrgId,
+		Matchers:  body.Matchers,
+		Comment:   body.Comment,
+		CreatedBy: c.Login,
+		CreatedAt: now.Unix(),
+	}
+	if body.EndsAt != nil {
+		if !body.EndsAt.After(now) {
+			return ErrResp(http.StatusBadRequest, errors.New("end of the maintenance must be in the future"), "")
+		}
+		m.EndsAt = body.EndsAt.Unix()
+	}
+	if err := m.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	if err := srv.store.SaveMaintenance(m); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save maintenance")
+	}
+	return response.JSON(http.StatusOK, toMaintenance(m, now))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteMaintenance(c *models.ReqContext) response.Response {
+	if resp := authorizeNotifications(srv.ac, c); resp != nil {
+		return resp
+	}
+	if err := srv.store.DeleteMaintenance(c.OrgId); err != nil {
+		if errors.Is(err, ngmodels.ErrMaintenanceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete maintenance")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func toMaintenance(m *ngmodels.Maintenance, now time.Time) apimodels.Maintenance {
+	createdAt := time.Unix(m.CreatedAt, 0)
+	result := apimodels.Maintenance{
+		Matchers:  m.Matchers,
+		Comment:   m.Comment,
+		CreatedBy: m.CreatedBy,
+		CreatedAt: &createdAt,
+		Active:    m.IsActive(now),
+	}
+	if m.EndsAt != 0 {
+		endsAt := time.Unix(m.EndsAt, 0)
+		result.EndsAt = &endsAt
+	}
+	return result
+}
+
+// hasReceiver returns true if the configuration has a contact point with this name.
+func hasReceiver(cfg *apimodels.PostableUserConfig, name string) bool {
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEscalationPolicy returns an error response if the escalation policy is invalid or if the contact point
+// of one of its steps doesn't exist.
+func (srv AlertmanagerSrv) validateEscalationPolicy(policy *ngmodels.EscalationPolicy) response.Response {
+	if err := policy.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	cfg, err := srv.getLatestConfig()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+	}
+	if cfg == nil {
+		return nil
+	}
+	receivers := make(map[string]struct{}, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	for _, step := range policy.Steps {
+		if _, ok := receivers[step.Receiver]; !ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: %s", errUnknownReceiver, step.Receiver), "")
+		}
+	}
+	return nil
+}
+
+// getLatestConfig returns the latest Alertmanager configuration, nil if there's none.
+func (srv AlertmanagerSrv) getLatestConfig() (*apimodels.PostableUserConfig, error) {
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{}
+	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+}
+
+func toEscalationPolicy(p *ngmodels.EscalationPolicy) apimodels.EscalationPolicy {
+	result := apimodels.EscalationPolicy{
+		UID:   p.UID,
+		Name:  p.Name,
+		Steps: make([]apimodels.EscalationStep, 0, len(p.Steps)),
+	}
+	for _, s := range p.Steps {
+		result.Steps = append(result.Steps, apimodels.EscalationStep{Receiver: s.Receiver, Wait: model.Duration(s.Wait)})
+	}
+	return result
+}
+
+func fromEscalationPolicy(p apimodels.EscalationPolicy) *ngmodels.EscalationPolicy {
+	result := &ngmodels.EscalationPolicy{
+		UID:   p.UID,
+		Name:  p.Name,
+		Steps: make([]ngmodels.EscalationStep, 0, len(p.Steps)),
+	}
+	for _, s := range p.Steps {
+		result.Steps = append(result.Steps, ngmodels.EscalationStep{Receiver: s.Receiver, Wait: time.Duration(s.Wait)})
+	}
+	return result
+}
+
 func (srv AlertmanagerSrv) RoutePostAMAlerts(c *models.ReqContext, body apimodels.PostableAlerts) response.Response {
 	// not implemented
 	return NotImplementedResp
 }
+
+// copyStoredSecureSettings copies the stored secure settings of the known Grafana managed receivers that are
+// missing from the posted receivers, the frontend only sends the secure settings that have to be updated.
+// It returns errUnknownReceiver if a posted receiver has a UID that is not in stored.
+func copyStoredSecureSettings(receivers []*apimodels.PostableApiReceiver, stored map[string]*apimodels.PostableGrafanaReceiver) error {
+	for _, r := range receivers {
+		for _, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
+			if gr.UID == "" { // new receiver
+				continue
+			}
+
+			storedReceiver, ok := stored[gr.UID]
+			if !ok {
+				// it tries to update a receiver that didn't previously exist
+				return fmt.Errorf("%w: %s", errUnknownReceiver, gr.UID)
+			}
+
+			for key := range storedReceiver.SecureSettings {
+				if _, ok := gr.SecureSettings[key]; ok {
+					continue
+				}
+				decryptedValue, err := storedReceiver.GetDecryptedSecret(key)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt stored secure setting: %s: %w", key, err)
+				}
+				if gr.SecureSettings == nil {
+					gr.SecureSettings = make(map[string]string, len(storedReceiver.SecureSettings))
+				}
+				gr.SecureSettings[key] = decryptedValue
+			}
+		}
+	}
+	return nil
+}
+
+// checkProvisionedChanges returns ngmodels.ErrProvenanceChangeNotAllowed if the posted configuration changes or removes
+// provisioned contact points, notification policies or mute timings. Secure settings of the posted configuration
+// must already be completed with the stored ones.
+func (srv AlertmanagerSrv) checkProvisionedChanges(orgID int64, current *apimodels.PostableUserConfig, posted *apimodels.PostableUserConfig) error {
+	contactPointProvenances, err := srv.provenanceStore.GetProvenances(orgID, (&apimodels.EmbeddedContactPoint{}).ResourceType())
+	if err != nil {
+		return err
+	}
+	postedContactPoints := contactPointsByUID(posted)
+	for uid, currentContactPoint := range contactPointsByUID(current) {
+		if provenance := contactPointProvenances[uid]; provenance.CanUpdate(ngmodels.ProvenanceNone) {
+			continue
+		}
+
+		postedContactPoint, ok := postedContactPoints[uid]
+		if !ok {
+			return fmt.Errorf("%w: contact point %s", ngmodels.ErrProvenanceChangeNotAllowed, currentContactPoint.Name)
+		}
+		for key := range currentContactPoint.SecureSettings {
+			value, err := currentContactPoint.integration.GetDecryptedSecret(key)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt stored secure setting %s: %w", key, err)
+			}
+			currentContactPoint.SecureSettings[key] = value
+		}
+		if changed, err := isChanged(currentContactPoint, postedContactPoint); err != nil || changed {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: contact point %s", ngmodels.ErrProvenanceChangeNotAllowed, currentContactPoint.Name)
+		}
+	}
+
+	tree := &apimodels.NotificationPolicyTree{}
+	provenance, err := srv.provenanceStore.GetProvenance(orgID, tree)
+	if err != nil {
+		return err
+	}
+	if !provenance.CanUpdate(ngmodels.ProvenanceNone) {
+		if changed, err := isChanged(current.AlertmanagerConfig.Route, posted.AlertmanagerConfig.Route); err != nil || changed {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: notification policies", ngmodels.ErrProvenanceChangeNotAllowed)
+		}
+	}
+
+	muteTimingProvenances, err := srv.provenanceStore.GetProvenances(orgID, (&apimodels.ProvisionedMuteTimeInterval{}).ResourceType())
+	if err != nil {
+		return err
+	}
+	postedMuteTimings := make(map[string]apimodels.MuteTimeInterval, len(posted.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range posted.AlertmanagerConfig.MuteTimeIntervals {
+		postedMuteTimings[mt.Name] = mt
+	}
+	for _, mt := range current.AlertmanagerConfig.MuteTimeIntervals {
+		if provenance := muteTimingProvenances[mt.Name]; provenance.CanUpdate(ngmodels.ProvenanceNone) {
+			continue
+		}
+
+		postedMuteTiming, ok := postedMuteTimings[mt.Name]
+		if !ok {
+			return fmt.Errorf("%w: mute timing %s", ngmodels.ErrProvenanceChangeNotAllowed, mt.Name)
+		}
+		if changed, err := isChanged(mt, postedMuteTiming); err != nil || changed {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: mute timing %s", ngmodels.ErrProvenanceChangeNotAllowed, mt.Name)
+		}
+	}
+
+	return nil
+}
+
+// comparableContactPoint is a Grafana managed integration and the name of its receiver.
+type comparableContactPoint struct {
+	Name                  string            `json:"name"`
+	Type                  string            `json:"type"`
+	DisableResolveMessage bool              `json:"disableResolveMessage"`
+	Settings              interface{}       `json:"settings"`
+	SecureSettings        map[string]string `json:"secureSettings"`
+
+	integration *apimodels.PostableGrafanaReceiver
+}
+
+func contactPointsByUID(cfg *apimodels.PostableUserConfig) map[string]comparableContactPoint {
+	contactPoints := make(map[string]comparableContactPoint)
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			secureSettings := make(map[string]string, len(gr.SecureSettings))
+			for key, value := range gr.SecureSettings {
+				secureSettings[key] = value
+			}
+			contactPoints[gr.UID] = comparableContactPoint{
+				Name:                  r.Name,
+				Type:                  gr.Type,
+				DisableResolveMessage: gr.DisableResolveMessage,
+				Settings:              gr.Settings,
+				SecureSettings:        secureSettings,
+				integration:           gr,
+			}
+		}
+	}
+	return contactPoints
+}
+
+// isChanged reports whether the JSON representations of a and b differ.
+func isChanged(a, b interface{}) (bool, error) {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(aJSON, bJSON), nil
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// CompatSrv serves the Grafana managed rules and their alerts in the schema of the Prometheus API, so that
+// the tools reading the rules and alerts of Prometheus can read them too.
+type CompatSrv struct {
+	log             log.Logger
+	manager         *state.Manager
+	store           store.RuleStore
+	DatasourceCache datasources.CacheService
+	ac              accesscontrol.AccessControl
+}
+
+// readableRule is a rule of a folder the user can read.
+type readableRule struct {
+	*ngmodels.AlertRule
+	folder *models.Folder
+}
+
+// readableRules returns the rules of the org of the user in the folders they can read.
+func (srv CompatSrv) readableRules(c *models.ReqContext) ([]readableRule, error) {
+	q := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return nil, fmt.Errorf("failed to get alert rules: %w", err)
+	}
+
+	rules := make([]readableRule, 0, len(q.Result))
+	// folders is nil for the folders the user can't read
+	folders := make(map[string]*models.Folder)
+	for _, rule := range q.Result {
+		folder, ok := folders[rule.NamespaceUID]
+		if !ok {
+			f, err := srv.store.GetNamespaceByUID(rule.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser)
+			if err != nil && !errors.Is(err, models.ErrFolderAccessDenied) {
+				return nil, fmt.Errorf("failed to get folder %s: %w", rule.NamespaceUID, err)
+			}
+			if err == nil && canAccessRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, rule.NamespaceUID) {
+				folder = f
+			}
+			folders[rule.NamespaceUID] = folder
+		}
+		if folder != nil {
+			rules = append(rules, readableRule{AlertRule: rule, folder: folder})
+		}
+	}
+	return rules, nil
+}
+
+func compatErrorResponse(status int, errorType apiv1.ErrorType, err error) response.Response {
+	return response.JSON(status, apimodels.DiscoveryBase{
+		Status:    "error",
+		ErrorType: errorType,
+		Error:     err.Error(),
+	})
+}
+
+func (srv CompatSrv) RouteGetCompatAlerts(c *models.ReqContext) response.Response {
+	rules, err := srv.readableRules(c)
+	if err != nil {
+		return compatErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, err)
+	}
+
+	result := apimodels.CompatAlertsResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "success"},
+		Data:          apimodels.CompatAlertDiscovery{Alerts: []*apimodels.CompatAlert{}},
+	}
+	for _, rule := range rules {
+		for _, s := range srv.manager.GetStatesForRuleUID(c.SignedInUser.OrgId, rule.UID) {
+			if alert := toCompatAlert(s); alert != nil {
+				result.Data.Alerts = append(result.Data.Alerts, alert)
+			}
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv CompatSrv) RouteGetCompatRules(c *models.ReqContext) response.Response {
+	ruleType := c.Query("type")
+	if ruleType != "" && ruleType != "alert" && ruleType != "record" {
+		return compatErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("unsupported type %q", ruleType))
+	}
+	ruleNames := queryValues(c, "rule_name[]")
+	ruleGroups := queryValues(c, "rule_group[]")
+	files := queryValues(c, "file[]")
+
+	rules, err := srv.readableRules(c)
+	if err != nil {
+		return compatErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, err)
+	}
+
+	datasourceTypes := make(map[string]string)
+	datasourceType := func(uid string) (string, error) {
+		if t, ok := datasourceTypes[uid]; ok {
+			return t, nil
+		}
+		ds, err := srv.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, c.SkipCache)
+		if err != nil {
+			return "", fmt.Errorf("failed to get data source %s: %w", uid, err)
+		}
+		datasourceTypes[uid] = ds.Type
+		return ds.Type, nil
+	}
+
+	groups := make(map[string]*apimodels.CompatRuleGroup)
+	keys := make([]string, 0)
+	for _, rule := range rules {
+		if !matchesFilter(ruleNames, rule.Title) || !matchesFilter(ruleGroups, rule.RuleGroup) || !matchesFilter(files, rule.folder.Title) {
+			continue
+		}
+		if (ruleType == "alert" && rule.IsRecording()) || (ruleType == "record" && !rule.IsRecording()) {
+			continue
+		}
+
+		key := rule.folder.Title + "\x00" + rule.RuleGroup
+		group, ok := groups[key]
+		if !ok {
+			group = &apimodels.CompatRuleGroup{
+				Name:     rule.RuleGroup,
+				File:     rule.folder.Title,
+				Rules:    []interface{}{},
+				Interval: float64(rule.IntervalSeconds),
+			}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+
+		states := srv.manager.GetStatesForRuleUID(c.SignedInUser.OrgId, rule.UID)
+		compatRule, evaluationTime, lastEvaluation := toCompatRule(rule.AlertRule, states, datasourceType)
+		group.Rules = append(group.Rules, compatRule)
+		group.EvaluationTime += evaluationTime
+		if lastEvaluation.After(group.LastEvaluation) {
+			group.LastEvaluation = lastEvaluation
+		}
+	}
+
+	sort.Strings(keys)
+	result := apimodels.CompatRulesResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "success"},
+		Data:          apimodels.CompatRuleDiscovery{RuleGroups: make([]apimodels.CompatRuleGroup, 0, len(keys))},
+	}
+	for _, key := range keys {
+		result.Data.RuleGroups = append(result.Data.RuleGroups, *groups[key])
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// queryValues returns the non-empty values of the query parameter.
+func queryValues(c *models.ReqContext, name string) []string {
+	values := make([]string, 0)
+	for _, v := range c.QueryStrings(name) {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// matchesFilter returns true if there's no filter or the value is one of its values.
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toCompatRule returns the alerting or recording rule of the Prometheus API of the rule and the states of
+// its alert instances, with the time its evaluation took and the time it was last evaluated at.
+func toCompatRule(rule *ngmodels.AlertRule, states []*state.State, datasourceType func(uid string) (string, error)) (interface{}, float64, time.Time) {
+	query, err := toPrometheusExpr(rule, datasourceType)
+	if err != nil {
+		if b, err := json.Marshal(rule.Data); err == nil {
+			query = string(b)
+		}
+	}
+
+	health := "unknown"
+	var (
+		lastError      string
+		evaluationTime float64
+		lastEvaluation time.Time
+	)
+	for _, s := range states {
+		if s.LastEvaluationTime.After(lastEvaluation) {
+			lastEvaluation = s.LastEvaluationTime
+		}
+		if d := s.EvaluationDuration.Seconds(); d > evaluationTime {
+			evaluationTime = d
+		}
+		if health == "unknown" {
+			health = "ok"
+		}
+		if s.Error != nil {
+			health = "err"
+			lastError = s.Error.Error()
+		} else if s.State == eval.Error {
+			health = "err"
+		}
+	}
+
+	if rule.IsRecording() {
+		return apimodels.CompatRecordingRule{
+			Name:           rule.Title,
+			Query:          query,
+			Labels:         rule.Labels,
+			Health:         health,
+			LastError:      lastError,
+			EvaluationTime: evaluationTime,
+			LastEvaluation: lastEvaluation,
+			Type:           apiv1.RuleTypeRecording,
+		}, evaluationTime, lastEvaluation
+	}
+
+	alertingRule := apimodels.CompatAlertingRule{
+		State:          "inactive",
+		Name:           rule.Title,
+		Query:          query,
+		Duration:       rule.For.Seconds(),
+		Labels:         rule.Labels,
+		Annotations:    rule.Annotations,
+		Alerts:         []*apimodels.CompatAlert{},
+		Health:         health,
+		LastError:      lastError,
+		EvaluationTime: evaluationTime,
+		LastEvaluation: lastEvaluation,
+		Type:           apiv1.RuleTypeAlerting,
+	}
+	if alertingRule.Labels == nil {
+		alertingRule.Labels = map[string]string{}
+	}
+	if alertingRule.Annotations == nil {
+		alertingRule.Annotations = map[string]string{}
+	}
+	for _, s := range states {
+		alert := toCompatAlert(s)
+		if alert == nil {
+			continue
+		}
+		if alert.State == "firing" || alertingRule.State == "inactive" {
+			alertingRule.State = alert.State
+		}
+		alertingRule.Alerts = append(alertingRule.Alerts, alert)
+	}
+	return alertingRule, evaluationTime, lastEvaluation
+}
+
+// toCompatAlert returns the alert of the Prometheus API of the state of an alert instance, or nil if it's
+// neither pending nor firing. The labels used internally by Grafana are left out.
+func toCompatAlert(s *state.State) *apimodels.CompatAlert {
+	var alertState string
+	switch s.State {
+	case eval.Alerting:
+		alertState = "firing"
+	case eval.Pending:
+		alertState = "pending"
+	default:
+		return nil
+	}
+
+	labels := make(map[string]string, len(s.Labels))
+	for k, v := range s.Labels {
+		if !strings.HasPrefix(k, "__") {
+			labels[k] = v
+		}
+	}
+	annotations := make(map[string]string, len(s.Annotations))
+	for k, v := range s.Annotations {
+		annotations[k] = v
+	}
+	value := ""
+	if len(s.Results) > 0 {
+		value = s.Results[len(s.Results)-1].EvaluationString
+	}
+	activeAt := s.StartsAt
+	return &apimodels.CompatAlert{
+		Labels:      labels,
+		Annotations: annotations,
+		State:       alertState,
+		ActiveAt:    &activeAt,
+		Value:       value,
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestToCompatRule(t *testing.T) {
+	datasourceType := func(uid string) (string, error) {
+		if uid == "prom" {
+			return models.DS_PROMETHEUS, nil
+		}
+		return "", errors.New("data source not found")
+	}
+	rule := &ngmodels.AlertRule{
+		Title:     "HighLatency",
+		Condition: "C",
+		Data: []ngmodels.AlertQuery{
+			{RefID: "A", DatasourceUID: "prom", Model: []byte(`{"expr": "latency_seconds"}`)},
+			{RefID: "B", DatasourceUID: "-100", Model: []byte(`{"type": "reduce", "expression": "A", "reducer": "last"}`)},
+			{RefID: "C", DatasourceUID: "-100", Model: []byte(`{"type": "math", "expression": "$B >= 0.5"}`)},
+		},
+		For:    time.Minute,
+		Labels: map[string]string{"severity": "critical"},
+	}
+	now := time.Now()
+
+	t.Run("a rule which was never evaluated is inactive with an unknown health", func(t *testing.T) {
+		r, evaluationTime, lastEvaluation := toCompatRule(rule, nil, datasourceType)
+		alertingRule, ok := r.(apimodels.CompatAlertingRule)
+		require.True(t, ok)
+		require.Equal(t, "(latency_seconds) >= 0.5", alertingRule.Query)
+		require.Equal(t, "inactive", alertingRule.State)
+		require.Equal(t, "unknown", alertingRule.Health)
+		require.Equal(t, float64(60), alertingRule.Duration)
+		require.Equal(t, apiv1.RuleTypeAlerting, alertingRule.Type)
+		require.Empty(t, alertingRule.Alerts)
+		require.Zero(t, evaluationTime)
+		require.True(t, lastEvaluation.IsZero())
+	})
+
+	t.Run("only the pending and firing alerts are listed", func(t *testing.T) {
+		states := []*state.State{
+			{State: eval.Normal, LastEvaluationTime: now, Labels: data.Labels{"instance": "a"}},
+			{State: eval.Pending, LastEvaluationTime: now, StartsAt: now, EvaluationDuration: time.Second, Labels: data.Labels{"instance": "b", ngmodels.RuleUIDLabel: "uid"}},
+			{State: eval.Alerting, LastEvaluationTime: now, StartsAt: now, Labels: data.Labels{"instance": "c"}, Results: []state.Evaluation{{EvaluationString: "old"}, {EvaluationString: "new"}}},
+		}
+		r, evaluationTime, lastEvaluation := toCompatRule(rule, states, datasourceType)
+		alertingRule := r.(apimodels.CompatAlertingRule)
+		require.Equal(t, "firing", alertingRule.State)
+		require.Equal(t, "ok", alertingRule.Health)
+		require.Equal(t, float64(1), evaluationTime)
+		require.Equal(t, now, lastEvaluation)
+		require.Len(t, alertingRule.Alerts, 2)
+		require.Equal(t, "pending", alertingRule.Alerts[0].State)
+		require.Equal(t, map[string]string{"instance": "b"}, map[string]string(alertingRule.Alerts[0].Labels))
+		require.Equal(t, "firing", alertingRule.Alerts[1].State)
+		require.Equal(t, "new", alertingRule.Alerts[1].Value)
+	})
+
+	t.Run("the errors of the evaluations are returned", func(t *testing.T) {
+		states := []*state.State{{State: eval.Error, LastEvaluationTime: now, Error: errors.New("query failed")}}
+		r, _, _ := toCompatRule(rule, states, datasourceType)
+		alertingRule := r.(apimodels.CompatAlertingRule)
+		require.Equal(t, "err", alertingRule.Health)
+		require.Equal(t, "query failed", alertingRule.LastError)
+	})
+
+	t.Run("recording rules have the fields of the Prometheus recording rules", func(t *testing.T) {
+		recording := &ngmodels.AlertRule{Title: "requests:rate5m", Condition: "A", Record: "requests:rate5m", Data: rule.Data[:1]}
+		r, _, _ := toCompatRule(recording, nil, datasourceType)
+		recordingRule, ok := r.(apimodels.CompatRecordingRule)
+		require.True(t, ok)
+		require.Equal(t, apiv1.RuleTypeRecording, recordingRule.Type)
+		require.Contains(t, recordingRule.Query, "latency_seconds")
+	})
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// AdminSrv implements the API of the ngalert administration configuration of the organisations.
+type AdminSrv struct {
+	scheduler schedule.ScheduleService
+	store     store.AdminConfigurationStore
+	log       log.Logger
+}
+
+func (srv AdminSrv) RouteGetAlertmanagers(c *models.ReqContext) response.Response {
+	statuses := srv.scheduler.AlertmanagersFor(c.OrgId)
+	resp := apimodels.GettableAlertmanagers{
+		Status: "success",
+		Data:   make([]apimodels.ExternalAlertmanager, 0, len(statuses)),
+	}
+	for _, s := range statuses {
+		am := apimodels.ExternalAlertmanager{
+			URL:         s.URL,
+			Healthy:     s.Healthy,
+			LastError:   s.LastError,
+			QueueLength: s.QueueLength,
+		}
+		if !s.LastHealthCheck.IsZero() {
+			lastHealthCheck := s.LastHealthCheck
+			am.LastHealthCheck = &lastHealthCheck
+		}
+		if !s.LastSent.IsZero() {
+			lastSent := s.LastSent
+			am.LastSent = &lastSent
+		}
+		resp.Data = append(resp.Data, am)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Response {
+	query := ngmodels.GetOrgAdminConfiguration{OrgID: c.OrgId}
+	if err := srv.store.GetAdminConfiguration(&query); err != nil {
+		if errors.Is(err, store.ErrNoAdminConfiguration) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+
+		msg := "failed to fetch admin configuration from the database"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+
+	resp := apimodels.GettableNGalertConfig{
+		Alertmanagers:       query.Result.Alertmanagers,
+		AlertmanagersChoice: string(query.Result.SendAlertsTo),
+		Enrichment:          toEnrichmentConfig(query.Result.Enrichment),
+	}
+	if resp.Alertmanagers == nil {
+		resp.Alertmanagers = []string{}
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+func (srv AdminSrv) RoutePostNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
+	cfg := &ngmodels.AdminConfiguration{
+		OrgID:         c.OrgId,
+		Alertmanagers: body.Alertmanagers,
+		SendAlertsTo:  ngmodels.AlertmanagersChoice(body.AlertmanagersChoice),
+		Enrichment:    fromEnrichmentConfig(body.Enrichment),
+	}
+	if cfg.Alertmanagers == nil {
+		cfg.Alertmanagers = []string{}
+	}
+	if cfg.SendAlertsTo == "" {
+		cfg.SendAlertsTo = ngmodels.InternalAlertmanager
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	cmd := ngmodels.UpdateAdminConfigurationCmd{AdminConfiguration: cfg}
+	if err := srv.store.UpdateAdminConfiguration(&cmd); err != nil {
+		msg := "failed to save the admin configuration to the database"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusBadRequest, err, msg)
+	}
+
+	// The configuration is applied by the next sync as well, applying it now makes it effective right away.
+	if err := srv.scheduler.SyncAndApplyConfigFromDatabase(); err != nil {
+		srv.log.Warn("failed to apply the admin configuration", "err", err)
+	}
+
+	return response.JSON(http.StatusCreated, util.DynMap{"message": "admin configuration updated"})
+}
+
+func (srv AdminSrv) RouteDeleteNGalertConfig(c *models.ReqContext) response.Response {
+	if err := srv.store.DeleteAdminConfiguration(c.OrgId); err != nil {
+		srv.log.Error("unable to delete configuration", "err", err)
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	if err := srv.scheduler.SyncAndApplyConfigFromDatabase(); err != nil {
+		srv.log.Warn("failed to apply the admin configuration", "err", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{"message": "admin configuration deleted"})
+}
+
+func toEnrichmentConfig(cfg *ngmodels.EnrichmentConfig) *apimodels.EnrichmentConfig {
+	if cfg == nil {
+		return nil
+	}
+	result := &apimodels.EnrichmentConfig{
+		Labels:      cfg.Labels,
+		Annotations: cfg.Annotations,
+	}
+	if len(cfg.Folders) > 0 {
+		result.Folders = make(map[string]apimodels.FolderEnrichment, len(cfg.Folders))
+		for uid, f := range cfg.Folders {
+			result.Folders[uid] = apimodels.FolderEnrichment{Labels: f.Labels, Annotations: f.Annotations}
+		}
+	}
+	for _, r := range cfg.Rewrites {
+		result.Rewrites = append(result.Rewrites, apimodels.LabelRewrite{
+			Action:      string(r.Action),
+			SourceLabel: r.SourceLabel,
+			Regex:       r.Regex,
+			TargetLabel: r.TargetLabel,
+			Replacement: r.Replacement,
+		})
+	}
+	if cfg.Webhook != nil {
+		result.Webhook = &apimodels.EnrichmentWebhook{URL: cfg.Webhook.URL, Timeout: model.Duration(cfg.Webhook.Timeout)}
+	}
+	return result
+}
+
+func fromEnrichmentConfig(cfg *apimodels.EnrichmentConfig) *ngmodels.EnrichmentConfig {
+	if cfg == nil {
+		return nil
+	}
+	result := &ngmodels.EnrichmentConfig{
+		Labels:      cfg.Labels,
+		Annotations: cfg.Annotations,
+	}
+	if len(cfg.Folders) > 0 {
+		result.Folders = make(map[string]ngmodels.FolderEnrichment, len(cfg.Folders))
+		for uid, f := range cfg.Folders {
+			result.Folders[uid] = ngmodels.FolderEnrichment{Labels: f.Labels, Annotations: f.Annotations}
+		}
+	}
+	for _, r := range cfg.Rewrites {
+		result.Rewrites = append(result.Rewrites, ngmodels.LabelRewrite{
+			Action:      ngmodels.LabelRewriteAction(r.Action),
+			SourceLabel: r.SourceLabel,
+			Regex:       r.Regex,
+			TargetLabel: r.TargetLabel,
+			Replacement: r.Replacement,
+		})
+	}
+	if cfg.Webhook != nil {
+		result.Webhook = &ngmodels.EnrichmentWebhook{URL: cfg.Webhook.URL, Timeout: time.Duration(cfg.Webhook.Timeout)}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type HeartbeatSrv struct {
+	log            log.Logger
+	store          store.RuleStore
+	heartbeatStore store.AlertRuleHeartbeatStore
+	ac             accesscontrol.AccessControl
+}
+
+func (srv HeartbeatSrv) RoutePostRuleHeartbeat(c *models.ReqContext) response.Response {
+	q := ngmodels.GetAlertRuleByUIDQuery{OrgID: c.SignedInUser.OrgId, UID: c.Params(":UID")}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	rule := q.Result
+	if _, err := srv.store.GetNamespaceByUID(rule.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, rule.NamespaceUID); resp != nil {
+		return resp
+	}
+	if resp := checkCanSaveFolder(c, srv.store, rule.NamespaceUID, srv.log); resp != nil {
+		return resp
+	}
+	if !rule.IsHeartbeat() {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("alert rule %s is not a heartbeat rule", rule.UID), "")
+	}
+
+	cmd := ngmodels.SaveAlertRuleHeartbeatCommand{OrgID: rule.OrgID, RuleUID: rule.UID, ReceivedAt: time.Now()}
+	if err := srv.heartbeatStore.SaveAlertRuleHeartbeat(&cmd); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save heartbeat")
+	}
+	return response.JSON(http.StatusOK, apimodels.RuleHeartbeat{RuleUID: rule.UID, ReceivedAt: cmd.ReceivedAt})
+}
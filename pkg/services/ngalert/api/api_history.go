@@ -0,0 +1,336 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	defaultStateHistoryLimit int64 = 100
+	maxStateHistoryLimit     int64 = 1000
+
+	defaultRuleVersionsLimit = 100
+	maxRuleVersionsLimit     = 1000
+)
+
+type HistorySrv struct {
+	log             log.Logger
+	store           store.RuleStore
+	historyStore    store.StateHistoryStore
+	provenanceStore store.ProvisioningStore
+	manager         *state.Manager
+	ac              accesscontrol.AccessControl
+}
+
+// getRule returns the alert rule of the request, or an error response if it doesn't exist
+// or the user can't read its folder and its rules.
+func (srv HistorySrv) getRule(c *models.ReqContext) (*ngmodels.AlertRule, response.Response) {
+	q := ngmodels.GetAlertRuleByUIDQuery{OrgID: c.SignedInUser.OrgId, UID: c.Params(":UID")}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	if _, err := srv.store.GetNamespaceByUID(q.Result.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser); err != nil {
+		return nil, toNamespaceErrorResponse(err)
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, q.Result.NamespaceUID); resp != nil {
+		return nil, resp
+	}
+	return q.Result, nil
+}
+
+func (srv HistorySrv) RouteGetRuleStateHistory(c *models.ReqContext) response.Response {
+	rule, resp := srv.getRule(c)
+	if resp != nil {
+		return resp
+	}
+
+	historyQuery := ngmodels.ListAlertStateHistoryQuery{
+		RuleOrgID: c.SignedInUser.OrgId,
+		RuleUID:   rule.UID,
+		State:     ngmodels.InstanceStateType(c.Query("state")),
+		Limit:     c.QueryInt64("limit"),
+	}
+	if historyQuery.State != "" && !historyQuery.State.IsValid() {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid state '%s'", historyQuery.State), "")
+	}
+	if historyQuery.Limit <= 0 {
+		historyQuery.Limit = defaultStateHistoryLimit
+	}
+	if historyQuery.Limit > maxStateHistoryLimit {
+		historyQuery.Limit = maxStateHistoryLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		historyQuery.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		historyQuery.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if !historyQuery.From.IsZero() && !historyQuery.To.IsZero() && historyQuery.To.Before(historyQuery.From) {
+		return ErrResp(http.StatusBadRequest, errors.New("'to' must not be before 'from'"), "")
+	}
+
+	if err := srv.historyStore.ListAlertStateHistory(&historyQuery); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert state history")
+	}
+
+	result := apimodels.RuleStateHistory{
+		RuleUID:     rule.UID,
+		Transitions: make([]apimodels.StateTransition, 0, len(historyQuery.Result)),
+	}
+	for _, entry := range historyQuery.Result {
+		result.Transitions = append(result.Transitions, apimodels.StateTransition{
+			Labels:           entry.Labels,
+			PreviousState:    string(entry.PreviousState),
+			State:            string(entry.CurrentState),
+			Resolved:         entry.IsResolved(),
+			EvaluationString: entry.EvaluationString,
+			Error:            entry.Error,
+			Timestamp:        entry.StateChangedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv HistorySrv) RouteGetRuleVersions(c *models.ReqContext) response.Response {
+	rule, resp := srv.getRule(c)
+	if resp != nil {
+		return resp
+	}
+
+	q := ngmodels.ListAlertRuleVersionsQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: rule.UID,
+		Limit:   c.QueryInt("limit"),
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultRuleVersionsLimit
+	}
+	if q.Limit > maxRuleVersionsLimit {
+		q.Limit = maxRuleVersionsLimit
+	}
+	if err := srv.store.GetAlertRuleVersions(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule versions")
+	}
+
+	result := apimodels.RuleVersions{
+		RuleUID:  rule.UID,
+		Versions: make([]apimodels.RuleVersion, 0, len(q.Result)),
+	}
+	for _, v := range q.Result {
+		result.Versions = append(result.Versions, toRuleVersion(v))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv HistorySrv) RouteGetRuleVersion(c *models.ReqContext) response.Response {
+	rule, resp := srv.getRule(c)
+	if resp != nil {
+		return resp
+	}
+
+	version, resp := srv.getRuleVersion(c, rule, c.ParamsInt64(":Version"))
+	if resp != nil {
+		return resp
+	}
+	return response.JSON(http.StatusOK, toRuleVersion(version))
+}
+
+func (srv HistorySrv) RouteGetRuleVersionsDiff(c *models.ReqContext) response.Response {
+	rule, resp := srv.getRule(c)
+	if resp != nil {
+		return resp
+	}
+
+	base, resp := srv.getRuleVersion(c, rule, c.QueryInt64("base"))
+	if resp != nil {
+		return resp
+	}
+	newVersion, resp := srv.getRuleVersion(c, rule, c.QueryInt64("new"))
+	if resp != nil {
+		return resp
+	}
+
+	return response.JSON(http.StatusOK, apimodels.RuleVersionsDiff{
+		RuleUID: rule.UID,
+		Base:    base.Version,
+		New:     newVersion.Version,
+		Changes: diffRuleVersions(base, newVersion),
+	})
+}
+
+func (srv HistorySrv) RoutePostRuleVersionRestore(c *models.ReqContext, body apimodels.RuleVersionRestore) response.Response {
+	rule, resp := srv.getRule(c)
+	if resp != nil {
+		return resp
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, rule.NamespaceUID); resp != nil {
+		return resp
+	}
+	if resp := checkCanSaveFolder(c, srv.store, rule.NamespaceUID, srv.log); resp != nil {
+		return resp
+	}
+	provenance, err := srv.provenanceStore.GetProvenance(c.SignedInUser.OrgId, rule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rule")
+	}
+	if !provenance.CanUpdate(ngmodels.ProvenanceNone) {
+		return ErrResp(http.StatusConflict, ngmodels.ErrProvenanceChangeNotAllowed, "failed to restore provisioned alert rule %s", rule.Title)
+	}
+	if body.Version <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("version must be positive"), "")
+	}
+
+	cmd := ngmodels.RestoreAlertRuleVersionCommand{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: rule.UID,
+		Version: body.Version,
+	}
+	if err := srv.store.RestoreAlertRuleVersion(&cmd); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleNotFound), errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound):
+			return ErrResp(http.StatusNotFound, err, "")
+		case errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation):
+			return ErrResp(http.StatusConflict, err, "failed to restore alert rule")
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+			return ErrResp(http.StatusBadRequest, err, "failed to restore alert rule")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to restore alert rule")
+	}
+	srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, rule.UID)
+
+	version, resp := srv.getRuleVersion(c, rule, cmd.Result.Version)
+	if resp != nil {
+		return resp
+	}
+	return response.JSON(http.StatusOK, toRuleVersion(version))
+}
+
+func (srv HistorySrv) getRuleVersion(c *models.ReqContext, rule *ngmodels.AlertRule, version int64) (*ngmodels.AlertRuleVersion, response.Response) {
+	if version <= 0 {
+		return nil, ErrResp(http.StatusBadRequest, errors.New("version must be positive"), "")
+	}
+	q := ngmodels.GetAlertRuleVersionQuery{OrgID: c.SignedInUser.OrgId, RuleUID: rule.UID, Version: version}
+	if err := srv.store.GetAlertRuleVersion(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "version %d", version)
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rule version")
+	}
+	return q.Result, nil
+}
+
+func toRuleVersion(v *ngmodels.AlertRuleVersion) apimodels.RuleVersion {
+	return apimodels.RuleVersion{
+		Version:                  v.Version,
+		ParentVersion:            v.ParentVersion,
+		RestoredFrom:             v.RestoredFrom,
+		Created:                  v.Created,
+		NamespaceUID:             v.RuleNamespaceUID,
+		RuleGroup:                v.RuleGroup,
+		Title:                    v.Title,
+		Condition:                v.Condition,
+		Data:                     v.Data,
+		IntervalSeconds:          v.IntervalSeconds,
+		NoDataState:              apimodels.NoDataState(v.NoDataState),
+		ExecErrState:             apimodels.ExecutionErrorState(v.ExecErrState),
+		EvaluationTimeoutSeconds: v.EvaluationTimeoutSeconds,
+		Record:                   v.Record,
+		HeartbeatTimeoutSeconds:  v.HeartbeatTimeoutSeconds,
+		CorrelationKey:           v.CorrelationKey,
+		MaxAlertInstances:        v.MaxAlertInstances,
+		For:                      model.Duration(v.For),
+		Annotations:              v.Annotations,
+		Labels:                   v.Labels,
+	}
+}
+
+// diffRuleVersions returns the changes of the definition of an alert rule from the base to the new version.
+// The annotations, labels and queries are compared one by one.
+func diffRuleVersions(base, newVersion *ngmodels.AlertRuleVersion) []apimodels.RuleVersionChange {
+	changes := make([]apimodels.RuleVersionChange, 0)
+	diff := func(field string, b, n interface{}) {
+		if !reflect.DeepEqual(b, n) {
+			changes = append(changes, apimodels.RuleVersionChange{Field: field, Base: b, New: n})
+		}
+	}
+
+	diff("namespaceUID", base.RuleNamespaceUID, newVersion.RuleNamespaceUID)
+	diff("ruleGroup", base.RuleGroup, newVersion.RuleGroup)
+	diff("title", base.Title, newVersion.Title)
+	diff("condition", base.Condition, newVersion.Condition)
+
+	baseQueries := make(map[string]interface{}, len(base.Data))
+	for _, q := range base.Data {
+		baseQueries[q.RefID] = q
+	}
+	newQueries := make(map[string]interface{}, len(newVersion.Data))
+	for _, q := range newVersion.Data {
+		newQueries[q.RefID] = q
+	}
+	for _, refID := range unionKeys(baseQueries, newQueries) {
+		diff("data."+refID, baseQueries[refID], newQueries[refID])
+	}
+
+	diff("intervalSeconds", base.IntervalSeconds, newVersion.IntervalSeconds)
+	diff("noDataState", base.NoDataState, newVersion.NoDataState)
+	diff("execErrState", base.ExecErrState, newVersion.ExecErrState)
+	diff("evaluationTimeoutSeconds", base.EvaluationTimeoutSeconds, newVersion.EvaluationTimeoutSeconds)
+	diff("record", base.Record, newVersion.Record)
+	diff("heartbeatTimeoutSeconds", base.HeartbeatTimeoutSeconds, newVersion.HeartbeatTimeoutSeconds)
+	diff("correlationKey", base.CorrelationKey, newVersion.CorrelationKey)
+	diff("maxAlertInstances", base.MaxAlertInstances, newVersion.MaxAlertInstances)
+	diff("for", model.Duration(base.For), model.Duration(newVersion.For))
+
+	diffStringMaps(&changes, "annotations", base.Annotations, newVersion.Annotations)
+	diffStringMaps(&changes, "labels", base.Labels, newVersion.Labels)
+	return changes
+}
+
+func diffStringMaps(changes *[]apimodels.RuleVersionChange, field string, base, newMap map[string]string) {
+	b := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		b[k] = v
+	}
+	n := make(map[string]interface{}, len(newMap))
+	for k, v := range newMap {
+		n[k] = v
+	}
+	for _, k := range unionKeys(b, n) {
+		if !reflect.DeepEqual(b[k], n[k]) {
+			*changes = append(*changes, apimodels.RuleVersionChange{Field: field + "." + k, Base: b[k], New: n[k]})
+		}
+	}
+}
+
+// unionKeys returns the sorted keys of both maps.
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
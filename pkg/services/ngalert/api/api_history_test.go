@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDiffRuleVersions(t *testing.T) {
+	base := &ngmodels.AlertRuleVersion{
+		Version:     1,
+		Title:       "rule",
+		Condition:   "A",
+		Data:        []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prom", Model: []byte(`{"expr": "up"}`)}},
+		For:         time.Minute,
+		Annotations: map[string]string{"summary": "down"},
+		Labels:      map[string]string{"team": "a"},
+	}
+
+	t.Run("identical versions have no changes", func(t *testing.T) {
+		require.Empty(t, diffRuleVersions(base, base))
+	})
+
+	t.Run("changes are listed field by field", func(t *testing.T) {
+		newVersion := *base
+		newVersion.Version = 2
+		newVersion.Title = "renamed"
+		newVersion.Data = []ngmodels.AlertQuery{
+			base.Data[0],
+			{RefID: "B", DatasourceUID: "-100", Model: []byte(`{"type": "math", "expression": "$A > 1"}`)},
+		}
+		newVersion.Condition = "B"
+		newVersion.For = 5 * time.Minute
+		newVersion.Annotations = map[string]string{"summary": "down", "description": "the target is down"}
+		newVersion.Labels = map[string]string{"severity": "critical"}
+
+		require.Equal(t, []apimodels.RuleVersionChange{
+			{Field: "title", Base: "rule", New: "renamed"},
+			{Field: "condition", Base: "A", New: "B"},
+			{Field: "data.B", New: newVersion.Data[1]},
+			{Field: "for", Base: model.Duration(time.Minute), New: model.Duration(5 * time.Minute)},
+			{Field: "annotations.description", New: "the target is down"},
+			{Field: "labels.severity", New: "critical"},
+			{Field: "labels.team", Base: "a"},
+		}, diffRuleVersions(base, &newVersion))
+	})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
@@ -23,6 +25,7 @@ type PrometheusSrv struct {
 	log     log.Logger
 	manager *state.Manager
 	store   store.RuleStore
+	ac      accesscontrol.AccessControl
 }
 
 func (srv PrometheusSrv) RouteGetAlertStatuses(c *models.ReqContext) response.Response {
@@ -76,6 +79,19 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			continue
 		}
 		groupId, namespaceUID, namespace := r[0], r[1], r[2]
+		if _, err := srv.store.GetNamespaceByUID(namespaceUID, c.SignedInUser.OrgId, c.SignedInUser); err != nil {
+			if errors.Is(err, models.ErrFolderAccessDenied) {
+				// the rule groups of the folders the user can't read are not listed
+				continue
+			}
+			ruleResponse.DiscoveryBase.Status = "error"
+			ruleResponse.DiscoveryBase.Error = fmt.Sprintf("failure getting folder %s: %s", namespace, err.Error())
+			ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrServer
+			return response.JSON(http.StatusInternalServerError, ruleResponse)
+		}
+		if !canAccessRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, namespaceUID) {
+			continue
+		}
 		alertRuleQuery := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespaceUID, RuleGroup: groupId}
 		if err := srv.store.GetRuleGroupAlertRules(&alertRuleQuery); err != nil {
 			ruleResponse.DiscoveryBase.Status = "error"
@@ -116,6 +132,10 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				Type:           apiv1.RuleTypeAlerting,
 				LastEvaluation: time.Time{},
 			}
+			if rule.IsRecording() {
+				alertingRule.State = ""
+				newRule.Type = apiv1.RuleTypeRecording
+			}
 
 			for _, alertState := range srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID) {
 				activeAt := alertState.StartsAt
@@ -160,6 +180,10 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				alertingRule.Alerts = append(alertingRule.Alerts, alert)
 			}
 
+			if rule.IsPaused {
+				newRule.Health = "paused"
+			}
+
 			alertingRule.Rule = newRule
 			newGroup.Rules = append(newGroup.Rules, alertingRule)
 			newGroup.Interval = float64(rule.IntervalSeconds)
@@ -0,0 +1,363 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type ProvisioningSrv struct {
+	log             log.Logger
+	store           store.RuleStore
+	DatasourceCache datasources.CacheService
+	QuotaService    *quota.QuotaService
+	manager         *state.Manager
+	alertRules      *provisioning.AlertRuleService
+	contactPoints   *provisioning.ContactPointService
+	policies        *provisioning.NotificationPolicyService
+	muteTimings     *provisioning.MuteTimingService
+}
+
+func (srv ProvisioningSrv) RouteGetAlertRule(c *models.ReqContext) response.Response {
+	rule, provenance, err := srv.alertRules.GetAlertRule(c.OrgId, c.Params(":UID"))
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get alert rule")
+	}
+	if _, err := srv.store.GetNamespaceByUID(rule.NamespaceUID, c.OrgId, c.SignedInUser); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, toProvisionedAlertRule(rule, provenance))
+}
+
+func (srv ProvisioningSrv) RoutePostAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	limitReached, err := srv.QuotaService.QuotaReached(c, "alert_rule")
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get quota")
+	}
+	if limitReached {
+		return ErrResp(http.StatusForbidden, errors.New("quota reached"), "")
+	}
+
+	if resp := srv.validateAlertRule(c, body); resp != nil {
+		return resp
+	}
+
+	body.OrgID = c.OrgId
+	rule, err := srv.alertRules.CreateAlertRule(fromProvisionedAlertRule(body), ngmodels.ProvenanceAPI)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to create alert rule")
+	}
+	return response.JSON(http.StatusCreated, toProvisionedAlertRule(rule, ngmodels.ProvenanceAPI))
+}
+
+func (srv ProvisioningSrv) RoutePutAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	existing, _, err := srv.alertRules.GetAlertRule(c.OrgId, c.Params(":UID"))
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to update alert rule")
+	}
+
+	body.UID = existing.UID
+	body.OrgID = c.OrgId
+	if body.FolderUID == "" {
+		body.FolderUID = existing.NamespaceUID
+	}
+	if body.RuleGroup == "" {
+		body.RuleGroup = existing.RuleGroup
+	}
+	if resp := srv.validateAlertRule(c, body); resp != nil {
+		return resp
+	}
+
+	rule, err := srv.alertRules.UpdateAlertRule(fromProvisionedAlertRule(body), ngmodels.ProvenanceAPI)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to update alert rule")
+	}
+	srv.manager.RemoveByRuleUID(c.OrgId, rule.UID)
+
+	return response.JSON(http.StatusOK, toProvisionedAlertRule(rule, ngmodels.ProvenanceAPI))
+}
+
+func (srv ProvisioningSrv) RouteDeleteAlertRule(c *models.ReqContext) response.Response {
+	rule, _, err := srv.alertRules.GetAlertRule(c.OrgId, c.Params(":UID"))
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to delete alert rule")
+	}
+	if resp := checkCanSaveFolder(c, srv.store, rule.NamespaceUID, srv.log); resp != nil {
+		return resp
+	}
+
+	if err := srv.alertRules.DeleteAlertRule(c.OrgId, rule.UID, ngmodels.ProvenanceAPI); err != nil {
+		return toProvisioningErrorResponse(err, "failed to delete alert rule")
+	}
+	srv.manager.RemoveByRuleUID(c.OrgId, rule.UID)
+
+	return response.Empty(http.StatusNoContent)
+}
+
+func (srv ProvisioningSrv) RouteGetContactpoints(c *models.ReqContext) response.Response {
+	contactPoints, err := srv.contactPoints.GetContactPoints(c.OrgId)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get contact points")
+	}
+	return response.JSON(http.StatusOK, apimodels.ContactPoints(contactPoints))
+}
+
+func (srv ProvisioningSrv) RoutePostContactpoints(c *models.ReqContext, body apimodels.EmbeddedContactPoint) response.Response {
+	contactPoint, err := srv.contactPoints.CreateContactPoint(c.OrgId, body, ngmodels.ProvenanceAPI)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to create contact point")
+	}
+	return response.JSON(http.StatusCreated, contactPoint)
+}
+
+func (srv ProvisioningSrv) RoutePutContactpoint(c *models.ReqContext, body apimodels.EmbeddedContactPoint) response.Response {
+	body.UID = c.Params(":UID")
+	if err := srv.contactPoints.UpdateContactPoint(c.OrgId, body, ngmodels.ProvenanceAPI); err != nil {
+		return toProvisioningErrorResponse(err, "failed to update contact point")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "contact point updated"})
+}
+
+func (srv ProvisioningSrv) RouteDeleteContactpoints(c *models.ReqContext) response.Response {
+	if err := srv.contactPoints.DeleteContactPoint(c.OrgId, c.Params(":UID"), ngmodels.ProvenanceAPI); err != nil {
+		return toProvisioningErrorResponse(err, "failed to delete contact point")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (srv ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Response {
+	tree, err := srv.policies.GetPolicyTree(c.OrgId)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get notification policies")
+	}
+	return response.JSON(http.StatusOK, tree)
+}
+
+func (srv ProvisioningSrv) RoutePutPolicyTree(c *models.ReqContext, body apimodels.NotificationPolicyTree) response.Response {
+	if err := srv.policies.UpdatePolicyTree(c.OrgId, body, ngmodels.ProvenanceAPI); err != nil {
+		return toProvisioningErrorResponse(err, "failed to update notification policies")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"})
+}
+
+func (srv ProvisioningSrv) RouteGetMuteTimings(c *models.ReqContext) response.Response {
+	muteTimings, err := srv.muteTimings.GetMuteTimings(c.OrgId)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get mute timings")
+	}
+	return response.JSON(http.StatusOK, apimodels.MuteTimings(muteTimings))
+}
+
+func (srv ProvisioningSrv) RouteGetMuteTiming(c *models.ReqContext) response.Response {
+	muteTiming, err := srv.muteTimings.GetMuteTiming(c.OrgId, c.Params(":name"))
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get mute timing")
+	}
+	return response.JSON(http.StatusOK, muteTiming)
+}
+
+func (srv ProvisioningSrv) RoutePostMuteTiming(c *models.ReqContext, body apimodels.ProvisionedMuteTimeInterval) response.Response {
+	muteTiming, err := srv.muteTimings.CreateMuteTiming(c.OrgId, body, ngmodels.ProvenanceAPI)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to create mute timing")
+	}
+	return response.JSON(http.StatusCreated, muteTiming)
+}
+
+func (srv ProvisioningSrv) RoutePutMuteTiming(c *models.ReqContext, body apimodels.ProvisionedMuteTimeInterval) response.Response {
+	body.Name = c.Params(":name")
+	muteTiming, err := srv.muteTimings.UpdateMuteTiming(c.OrgId, body, ngmodels.ProvenanceAPI)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to update mute timing")
+	}
+	return response.JSON(http.StatusOK, muteTiming)
+}
+
+func (srv ProvisioningSrv) RouteDeleteMuteTiming(c *models.ReqContext) response.Response {
+	if err := srv.muteTimings.DeleteMuteTiming(c.OrgId, c.Params(":name"), ngmodels.ProvenanceAPI); err != nil {
+		return toProvisioningErrorResponse(err, "failed to delete mute timing")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+// RouteGetProvisioningExport exports all provisionable resources the user has access to.
+// The export is in JSON unless the format query parameter is yaml.
+func (srv ProvisioningSrv) RouteGetProvisioningExport(c *models.ReqContext) response.Response {
+	export := apimodels.ProvisioningExport{}
+
+	rules, provenances, err := srv.alertRules.GetAlertRules(c.OrgId)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get alert rules")
+	}
+	export.AlertRules = make([]apimodels.ProvisionedAlertRule, 0, len(rules))
+	for _, r := range rules {
+		if _, err := srv.store.GetNamespaceByUID(r.NamespaceUID, c.OrgId, c.SignedInUser); err != nil {
+			if errors.Is(err, models.ErrFolderAccessDenied) {
+				// do not fail if the user does not have access to a specific folder
+				continue
+			}
+			return toNamespaceErrorResponse(err)
+		}
+		export.AlertRules = append(export.AlertRules, toProvisionedAlertRule(*r, provenances[r.UID]))
+	}
+
+	if export.ContactPoints, err = srv.contactPoints.GetContactPoints(c.OrgId); err != nil {
+		return toProvisioningErrorResponse(err, "failed to get contact points")
+	}
+
+	tree, err := srv.policies.GetPolicyTree(c.OrgId)
+	if err != nil {
+		return toProvisioningErrorResponse(err, "failed to get notification policies")
+	}
+	export.Policies = &tree
+
+	if export.MuteTimings, err = srv.muteTimings.GetMuteTimings(c.OrgId); err != nil {
+		return toProvisioningErrorResponse(err, "failed to get mute timings")
+	}
+
+	if c.Query("format") != "yaml" {
+		return response.JSON(http.StatusOK, export)
+	}
+
+	// the definitions only have JSON struct tags, the export is converted to a generic
+	// structure first to get the same field names in YAML
+	b, err := json.Marshal(export)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal export")
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal export")
+	}
+	yml, err := yaml.Marshal(generic)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal export")
+	}
+	return response.Respond(http.StatusOK, yml).SetHeader("Content-Type", "application/yaml")
+}
+
+// validateAlertRule validates the alert rule and checks that the user can save rules in its folder.
+func (srv ProvisioningSrv) validateAlertRule(c *models.ReqContext, rule apimodels.ProvisionedAlertRule) response.Response {
+	if rule.RuleGroup == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+	}
+	if resp := checkCanSaveFolder(c, srv.store, rule.FolderUID, srv.log); resp != nil {
+		return resp
+	}
+
+	cond := ngmodels.Condition{
+		Condition: rule.Condition,
+		OrgID:     c.OrgId,
+		Data:      rule.Data,
+	}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(validationErrorStatus(err), err, "failed to validate alert rule %s", rule.Title)
+	}
+	return nil
+}
+
+// checkCanSaveFolder returns an error response if the user can't save the rules of the folder.
+func checkCanSaveFolder(c *models.ReqContext, ruleStore store.RuleStore, folderUID string, logger log.Logger) response.Response {
+	folder, err := ruleStore.GetNamespaceByUID(folderUID, c.OrgId, c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := g.CanSave(); err != nil || !canSave {
+		if err != nil {
+			logger.Error("checking can save permission has failed", "userId", c.UserId, "folder", folderUID, "error", err)
+		}
+		return toNamespaceErrorResponse(ngmodels.ErrCannotEditNamespace)
+	}
+	return nil
+}
+
+func toProvisioningErrorResponse(err error, msg string) response.Response {
+	switch {
+	case errors.Is(err, ngmodels.ErrAlertRuleNotFound),
+		errors.Is(err, provisioning.ErrContactPointNotFound),
+		errors.Is(err, provisioning.ErrMuteTimingNotFound):
+		return ErrResp(http.StatusNotFound, err, msg)
+	case errors.Is(err, ngmodels.ErrProvenanceChangeNotAllowed),
+		errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation),
+		errors.Is(err, provisioning.ErrContactPointExists),
+		errors.Is(err, provisioning.ErrContactPointReferenced),
+		errors.Is(err, provisioning.ErrMuteTimingExists),
+		errors.Is(err, provisioning.ErrMuteTimingReferenced):
+		return ErrResp(http.StatusConflict, err, msg)
+	case errors.Is(err, provisioning.ErrValidation),
+		errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+		return ErrResp(http.StatusBadRequest, err, msg)
+	}
+	return ErrResp(http.StatusInternalServerError, err, msg)
+}
+
+func toProvisionedAlertRule(r ngmodels.AlertRule, provenance ngmodels.Provenance) apimodels.ProvisionedAlertRule {
+	return apimodels.ProvisionedAlertRule{
+		ID:                r.ID,
+		UID:               r.UID,
+		OrgID:             r.OrgID,
+		FolderUID:         r.NamespaceUID,
+		RuleGroup:         r.RuleGroup,
+		Title:             r.Title,
+		Condition:         r.Condition,
+		Data:              r.Data,
+		IntervalSeconds:   r.IntervalSeconds,
+		Updated:           r.Updated,
+		NoDataState:       r.NoDataState,
+		ExecErrState:      r.ExecErrState,
+		EvaluationTimeout: model.Duration(time.Duration(r.EvaluationTimeoutSeconds) * time.Second),
+		For:               model.Duration(r.For),
+		Annotations:       r.Annotations,
+		Labels:            r.Labels,
+		IsPaused:          r.IsPaused,
+		Record:            r.Record,
+		HeartbeatTimeout:  model.Duration(time.Duration(r.HeartbeatTimeoutSeconds) * time.Second),
+		CorrelationKey:    r.CorrelationKey,
+		MaxAlertInstances: r.MaxAlertInstances,
+		Provenance:        provenance,
+	}
+}
+
+func fromProvisionedAlertRule(r apimodels.ProvisionedAlertRule) ngmodels.AlertRule {
+	return ngmodels.AlertRule{
+		ID:                       r.ID,
+		UID:                      r.UID,
+		OrgID:                    r.OrgID,
+		NamespaceUID:             r.FolderUID,
+		RuleGroup:                r.RuleGroup,
+		Title:                    r.Title,
+		Condition:                r.Condition,
+		Data:                     r.Data,
+		IntervalSeconds:          r.IntervalSeconds,
+		NoDataState:              r.NoDataState,
+		ExecErrState:             r.ExecErrState,
+		EvaluationTimeoutSeconds: int64(time.Duration(r.EvaluationTimeout).Seconds()),
+		For:                      time.Duration(r.For),
+		Annotations:              r.Annotations,
+		Labels:                   r.Labels,
+		IsPaused:                 r.IsPaused,
+		Record:                   r.Record,
+		HeartbeatTimeoutSeconds:  int64(time.Duration(r.HeartbeatTimeout).Seconds()),
+		CorrelationKey:           r.CorrelationKey,
+		MaxAlertInstances:        r.MaxAlertInstances,
+	}
+}
@@ -1,10 +1,16 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"sort"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -12,19 +18,24 @@ import (
 
 	coreapi "github.com/grafana/grafana/pkg/api"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
 )
 
 type RulerSrv struct {
 	store           store.RuleStore
+	provenanceStore store.ProvisioningStore
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	manager         *state.Manager
+	ac              accesscontrol.AccessControl
 	log             log.Logger
 }
 
@@ -34,12 +45,34 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 	if err != nil {
 		return toNamespaceErrorResponse(err)
 	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, namespace.Uid); resp != nil {
+		return resp
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+	if resp := srv.checkProvisionedRulesRemoval(c.SignedInUser.OrgId, q.Result); resp != nil {
+		return resp
+	}
 
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
 	}
 
+	ruleGroups := make(map[string][]*ngmodels.AlertRule)
+	for _, r := range q.Result {
+		ruleGroups[r.RuleGroup] = append(ruleGroups[r.RuleGroup], r)
+	}
+	for ruleGroup, rules := range ruleGroups {
+		srv.recordRuleGroupChange(c, models.AuditActionDelete, namespace, ruleGroup, rules, nil)
+	}
+
 	for _, uid := range uids {
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
@@ -53,7 +86,23 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 	if err != nil {
 		return toNamespaceErrorResponse(err)
 	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, namespace.Uid); resp != nil {
+		return resp
+	}
 	ruleGroup := c.Params(":Groupname")
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if resp := srv.checkProvisionedRulesRemoval(c.SignedInUser.OrgId, q.Result); resp != nil {
+		return resp
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -62,6 +111,7 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
 	}
+	srv.recordRuleGroupChange(c, models.AuditActionDelete, namespace, ruleGroup, q.Result, nil)
 
 	for _, uid := range uids {
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
@@ -70,12 +120,122 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group deleted"})
 }
 
+func (srv RulerSrv) RoutePauseRuleGroup(c *models.ReqContext) response.Response {
+	return srv.setRuleGroupPaused(c, true)
+}
+
+func (srv RulerSrv) RouteResumeRuleGroup(c *models.ReqContext) response.Response {
+	return srv.setRuleGroupPaused(c, false)
+}
+
+// setRuleGroupPaused pauses or resumes the rules of the group, the state of paused rules is kept
+// so that they continue from it once they are resumed.
+func (srv RulerSrv) setRuleGroupPaused(c *models.ReqContext, paused bool) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, namespace.Uid); resp != nil {
+		return resp
+	}
+
+	cmd := ngmodels.SetRuleGroupPausedCommand{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    c.Params(":Groupname"),
+		IsPaused:     paused,
+	}
+	before := srv.getRuleGroupRules(cmd.OrgID, cmd.NamespaceUID, cmd.RuleGroup)
+	if err := srv.store.SetRuleGroupPaused(&cmd); err != nil {
+		if errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+	}
+	srv.recordRuleGroupChange(c, models.AuditActionUpdate, namespace, cmd.RuleGroup, before,
+		srv.getRuleGroupRules(cmd.OrgID, cmd.NamespaceUID, cmd.RuleGroup))
+
+	if paused {
+		return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group paused"})
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group resumed"})
+}
+
+// RoutePatchRuleGroupConfig renames, moves, reorders and/or changes the interval of the rules of the group
+// in a single transaction. The rule group is moved to a namespace the user can also save to.
+func (srv RulerSrv) RoutePatchRuleGroupConfig(c *models.ReqContext, patch apimodels.PatchableRuleGroupConfig) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, namespace.Uid); resp != nil {
+		return resp
+	}
+
+	cmd := ngmodels.PatchRuleGroupCommand{
+		OrgID:           c.SignedInUser.OrgId,
+		NamespaceUID:    namespace.Uid,
+		RuleGroup:       c.Params(":Groupname"),
+		NewRuleGroup:    patch.Name,
+		IntervalSeconds: int64(time.Duration(patch.Interval).Seconds()),
+		RuleUIDs:        patch.RuleUIDs,
+	}
+	if patch.Namespace != "" && patch.Namespace != namespace.Title {
+		target, err := srv.store.GetNamespaceByTitle(patch.Namespace, c.SignedInUser.OrgId, c.SignedInUser, true)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, target.Uid); resp != nil {
+			return resp
+		}
+		cmd.NewNamespaceUID = target.Uid
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        cmd.OrgID,
+		NamespaceUID: cmd.NamespaceUID,
+		RuleGroup:    cmd.RuleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if resp := srv.checkProvisionedRulesUpdate(cmd.OrgID, q.Result); resp != nil {
+		return resp
+	}
+
+	if err := srv.store.PatchRuleGroup(&cmd); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound):
+			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+		case errors.Is(err, ngmodels.ErrRuleGroupAlreadyExists), errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation):
+			return ErrResp(http.StatusConflict, err, "failed to update rule group")
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+	}
+	srv.recordRuleGroupChange(c, models.AuditActionUpdate, namespace, cmd.RuleGroup, q.Result, cmd.Result)
+
+	// the state of the alerts is labeled with the namespace of their rule
+	if cmd.NewNamespaceUID != "" {
+		for _, r := range cmd.Result {
+			srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, r.UID)
+		}
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+}
+
 func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.Response {
 	namespaceTitle := c.Params(":Namespace")
 	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
 	if err != nil {
 		return toNamespaceErrorResponse(err)
 	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, namespace.Uid); resp != nil {
+		return resp
+	}
 
 	q := ngmodels.ListNamespaceAlertRulesQuery{
 		OrgID:        c.SignedInUser.OrgId,
@@ -117,6 +277,9 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	if err != nil {
 		return toNamespaceErrorResponse(err)
 	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, namespace.Uid); resp != nil {
+		return resp
+	}
 
 	ruleGroup := c.Params(":Groupname")
 	q := ngmodels.ListRuleGroupAlertRulesQuery{
@@ -164,6 +327,9 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 			}
 			return toNamespaceErrorResponse(err)
 		}
+		if !canAccessRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, folder.Uid) {
+			continue
+		}
 		namespace := folder.Title
 		_, ok := configs[namespace]
 		if !ok {
@@ -209,6 +375,9 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 	if err != nil {
 		return toNamespaceErrorResponse(err)
 	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, namespace.Uid); resp != nil {
+		return resp
+	}
 
 	// quotas are checked in advanced
 	// that is acceptable under the assumption that there will be only one alert rule under the rule group
@@ -224,12 +393,173 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 
 	// TODO validate UID uniqueness in the payload
 
+	if resp := srv.validateRuleGroup(c, namespace, ruleGroupConfig); resp != nil {
+		return resp
+	}
+	if resp := srv.checkFolderQuota(c, namespace, ruleGroupConfig); resp != nil {
+		return resp
+	}
+	if resp := srv.saveRuleGroup(c, namespace, ruleGroupConfig); resp != nil {
+		return resp
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+}
+
+// RouteGetNamespaceRulesExport exports the rule groups of the namespace as a Prometheus rule file.
+func (srv RulerSrv) RouteGetNamespaceRulesExport(c *models.ReqContext) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, namespace.Uid); resp != nil {
+		return resp
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	datasourceTypes := make(map[string]string)
+	datasourceType := func(uid string) (string, error) {
+		if t, ok := datasourceTypes[uid]; ok {
+			return t, nil
+		}
+		ds, err := srv.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, c.SkipCache)
+		if err != nil {
+			return "", fmt.Errorf("failed to get data source %s: %w", uid, err)
+		}
+		datasourceTypes[uid] = ds.Type
+		return ds.Type, nil
+	}
+
+	groupNames := make([]string, 0)
+	groups := make(map[string][]*ngmodels.AlertRule)
+	for _, r := range q.Result {
+		if _, ok := groups[r.RuleGroup]; !ok {
+			groupNames = append(groupNames, r.RuleGroup)
+		}
+		groups[r.RuleGroup] = append(groups[r.RuleGroup], r)
+	}
+	sort.Strings(groupNames)
+
+	file := apimodels.PrometheusRuleFile{Groups: make([]apimodels.PrometheusRuleGroup, 0, len(groupNames))}
+	for _, name := range groupNames {
+		group, err := toPrometheusRuleGroup(name, groups[name], datasourceType)
+		if err != nil {
+			if errors.Is(err, errUnsupportedPromRule) {
+				return ErrResp(http.StatusBadRequest, err, "failed to export rule group %s", name)
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to export rule group %s", name)
+		}
+		file.Groups = append(file.Groups, group)
+	}
+
+	yml, err := yaml.Marshal(file)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal export")
+	}
+	return response.Respond(http.StatusOK, yml).SetHeader("Content-Type", "application/yaml")
+}
+
+// RoutePostNamespaceRulesImport imports the rule groups of a Prometheus rule file, in YAML or JSON,
+// into the namespace. The expressions of the rules are queried from the data source of the datasourceUid query parameter.
+func (srv RulerSrv) RoutePostNamespaceRulesImport(c *models.ReqContext) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if resp := authorizeRules(srv.ac, c, accesscontrol.ActionAlertingRuleWrite, namespace.Uid); resp != nil {
+		return resp
+	}
+
+	ds, err := srv.DatasourceCache.GetDatasourceByUID(c.Query("datasourceUid"), c.SignedInUser, c.SkipCache)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to get data source")
+	}
+	if err := checkDatasourceQueryAccess(c.SignedInUser, ds); err != nil {
+		return ErrResp(validationErrorStatus(err), err, "failed to get data source")
+	}
+	if !promRuleDatasourceTypes[ds.Type] {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("data source %s is not a Prometheus or Loki data source", ds.Name), "")
+	}
+
+	body, err := ioutil.ReadAll(c.Req.Request.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read rule file")
+	}
+	// JSON is valid YAML
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(body, &file); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse rule file")
+	}
+	if len(file.Groups) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("no rule groups to import"), "")
+	}
+
+	limitReached, err := srv.QuotaService.QuotaReached(c, "alert_rule")
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get quota")
+	}
+	if limitReached {
+		return ErrResp(http.StatusForbidden, errors.New("quota reached"), "")
+	}
+
+	// all the groups are validated before any of them is saved
+	ruleGroupConfigs := make([]apimodels.PostableRuleGroupConfig, 0, len(file.Groups))
+	names := make(map[string]struct{}, len(file.Groups))
+	for _, group := range file.Groups {
+		if _, ok := names[group.Name]; ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("rule group %s is defined more than once", group.Name), "")
+		}
+		names[group.Name] = struct{}{}
+
+		q := ngmodels.ListRuleGroupAlertRulesQuery{
+			OrgID:        c.SignedInUser.OrgId,
+			NamespaceUID: namespace.Uid,
+			RuleGroup:    group.Name,
+		}
+		if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+		}
+		existingUIDs := make(map[string]string, len(q.Result))
+		for _, r := range q.Result {
+			existingUIDs[r.Title] = r.UID
+		}
+
+		ruleGroupConfig, err := fromPrometheusRuleGroup(group, ds, existingUIDs)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to import rule group %s", group.Name)
+		}
+		if resp := srv.validateRuleGroup(c, namespace, ruleGroupConfig); resp != nil {
+			return resp
+		}
+		ruleGroupConfigs = append(ruleGroupConfigs, ruleGroupConfig)
+	}
+	if resp := srv.checkFolderQuota(c, namespace, ruleGroupConfigs...); resp != nil {
+		return resp
+	}
+
+	for _, ruleGroupConfig := range ruleGroupConfigs {
+		if resp := srv.saveRuleGroup(c, namespace, ruleGroupConfig); resp != nil {
+			return resp
+		}
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule groups imported successfully"})
+}
+
+// validateRuleGroup returns an error response if the rule group is invalid or changes provisioned rules.
+func (srv RulerSrv) validateRuleGroup(c *models.ReqContext, namespace *models.Folder, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
 	//TODO: Should this belong in alerting-api?
 	if ruleGroupConfig.Name == "" {
 		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
 	}
 
-	var alertRuleUIDs []string
 	for _, r := range ruleGroupConfig.Rules {
 		cond := ngmodels.Condition{
 			Condition: r.GrafanaManagedAlert.Condition,
@@ -237,11 +567,49 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 			Data:      r.GrafanaManagedAlert.Data,
 		}
 		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
-			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %s", r.GrafanaManagedAlert.Title)
+			return ErrResp(validationErrorStatus(err), err, "failed to validate alert rule %s", r.GrafanaManagedAlert.Title)
+		}
+	}
+
+	return srv.checkProvisionedRuleGroupChanges(c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig)
+}
+
+// checkFolderQuota returns an error response if the folder would have more alert rules than its quota once
+// the rule groups are saved. The folders which are already over their quota can still have their rules updated
+// as long as they don't get more rules.
+func (srv RulerSrv) checkFolderQuota(c *models.ReqContext, namespace *models.Folder, ruleGroupConfigs ...apimodels.PostableRuleGroupConfig) response.Response {
+	quota := srv.QuotaService.Cfg.Quota
+	if !quota.Enabled || quota.Folder == nil || quota.Folder.AlertRule < 0 {
+		return nil
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespace.Uid}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	// the rules of the saved rule groups are replaced
+	replaced := make(map[string]struct{}, len(ruleGroupConfigs))
+	var count int64
+	for _, ruleGroupConfig := range ruleGroupConfigs {
+		replaced[ruleGroupConfig.Name] = struct{}{}
+		count += int64(len(ruleGroupConfig.Rules))
+	}
+	for _, r := range q.Result {
+		if _, ok := replaced[r.RuleGroup]; !ok {
+			count++
 		}
-		alertRuleUIDs = append(alertRuleUIDs, r.GrafanaManagedAlert.UID)
 	}
 
+	if count > quota.Folder.AlertRule && count > int64(len(q.Result)) {
+		return ErrResp(http.StatusForbidden, fmt.Errorf("folder quota reached: the folder can't have more than %d alert rules", quota.Folder.AlertRule), "")
+	}
+	return nil
+}
+
+// saveRuleGroup creates, updates and deletes the rules of the rule group and resets the state of its rules.
+func (srv RulerSrv) saveRuleGroup(c *models.ReqContext, namespace *models.Folder, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
+	before := srv.getRuleGroupRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig.Name)
 	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
 		OrgID:           c.SignedInUser.OrgId,
 		NamespaceUID:    namespace.Uid,
@@ -255,30 +623,233 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
 
-	for _, uid := range alertRuleUIDs {
-		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	action := models.AuditActionUpdate
+	if len(before) == 0 {
+		action = models.AuditActionCreate
 	}
+	srv.recordRuleGroupChange(c, action, namespace, ruleGroupConfig.Name, before,
+		srv.getRuleGroupRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig.Name))
 
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+	for _, r := range ruleGroupConfig.Rules {
+		srv.manager.RemoveByRuleUID(c.OrgId, r.GrafanaManagedAlert.UID)
+	}
+	return nil
+}
+
+// getRuleGroupRules returns the rules of the group for the audit log, or nil when they can't be read.
+func (srv RulerSrv) getRuleGroupRules(orgID int64, namespaceUID, ruleGroup string) []*ngmodels.AlertRule {
+	q := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: namespaceUID, RuleGroup: ruleGroup}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		srv.log.Warn("Failed to get the rules of the group for the audit log", "ruleGroup", ruleGroup, "error", err)
+		return nil
+	}
+	return q.Result
+}
+
+// recordRuleGroupChange records the change of a rule group in the audit log, when it's enabled, and publishes it to
+// the event listeners. The rule groups without rules are recorded as nil.
+func (srv RulerSrv) recordRuleGroupChange(c *models.ReqContext, action models.AuditAction, namespace *models.Folder, ruleGroup string, before, after []*ngmodels.AlertRule) {
+	cmd := models.RecordAuditEntryCommand{
+		OrgId:        c.SignedInUser.OrgId,
+		UserId:       c.SignedInUser.UserId,
+		UserLogin:    c.SignedInUser.Login,
+		Action:       action,
+		ResourceType: models.AuditResourceAlertRuleGroup,
+		ResourceId:   namespace.Uid + "/" + ruleGroup,
+		ResourceName: namespace.Title + "/" + ruleGroup,
+	}
+	if len(before) > 0 {
+		cmd.Before = toRuleGroupAuditState(ruleGroup, namespace.Id, before)
+	}
+	if len(after) > 0 {
+		cmd.After = toRuleGroupAuditState(ruleGroup, namespace.Id, after)
+	}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil && !errors.Is(err, bus.ErrHandlerNotFound) {
+		srv.log.Error("Failed to record audit entry", "resourceId", cmd.ResourceId, "error", err)
+	}
+
+	event := &events.AlertRuleGroupChanged{
+		Timestamp:    time.Now(),
+		OrgId:        c.SignedInUser.OrgId,
+		NamespaceUid: namespace.Uid,
+		RuleGroup:    ruleGroup,
+		Action:       string(action),
+		RuleUids:     []string{},
+		UserId:       c.SignedInUser.UserId,
+	}
+	// the deleted rule groups have the UIDs of the deleted rules
+	rules := after
+	if len(rules) == 0 {
+		rules = before
+	}
+	for _, r := range rules {
+		event.RuleUids = append(event.RuleUids, r.UID)
+	}
+	if err := bus.Publish(event); err != nil {
+		srv.log.Error("Failed to publish rule group change", "ruleGroup", ruleGroup, "error", err)
+	}
+}
+
+func toRuleGroupAuditState(ruleGroup string, namespaceID int64, rules []*ngmodels.AlertRule) apimodels.GettableRuleGroupConfig {
+	config := apimodels.GettableRuleGroupConfig{Name: ruleGroup, Rules: make([]apimodels.GettableExtendedRuleNode, 0, len(rules))}
+	for _, r := range rules {
+		config.Interval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
+		config.Rules = append(config.Rules, toGettableExtendedRuleNode(*r, namespaceID))
+	}
+	return config
+}
+
+// checkProvisionedRulesRemoval returns an error response if any of the rules is provisioned.
+func (srv RulerSrv) checkProvisionedRulesRemoval(orgID int64, rules []*ngmodels.AlertRule) response.Response {
+	provenances, err := srv.provenanceStore.GetProvenances(orgID, (&ngmodels.AlertRule{}).ResourceType())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rules")
+	}
+
+	for _, r := range rules {
+		if provenance := provenances[r.UID]; !provenance.CanUpdate(ngmodels.ProvenanceNone) {
+			return ErrResp(http.StatusConflict, ngmodels.ErrProvenanceChangeNotAllowed, "failed to delete provisioned alert rule %s", r.Title)
+		}
+	}
+	return nil
+}
+
+// checkProvisionedRulesUpdate returns an error response if any of the rules is provisioned.
+func (srv RulerSrv) checkProvisionedRulesUpdate(orgID int64, rules []*ngmodels.AlertRule) response.Response {
+	provenances, err := srv.provenanceStore.GetProvenances(orgID, (&ngmodels.AlertRule{}).ResourceType())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rules")
+	}
+
+	for _, r := range rules {
+		if provenance := provenances[r.UID]; !provenance.CanUpdate(ngmodels.ProvenanceNone) {
+			return ErrResp(http.StatusConflict, ngmodels.ErrProvenanceChangeNotAllowed, "failed to update provisioned alert rule %s", r.Title)
+		}
+	}
+	return nil
+}
+
+// checkProvisionedRuleGroupChanges returns an error response if the update of the rule group changes
+// or removes provisioned rules, unchanged provisioned rules are accepted since the group is always posted as a whole.
+func (srv RulerSrv) checkProvisionedRuleGroupChanges(orgID int64, namespaceUID string, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        orgID,
+		NamespaceUID: namespaceUID,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if len(q.Result) == 0 {
+		return nil
+	}
+
+	provenances, err := srv.provenanceStore.GetProvenances(orgID, (&ngmodels.AlertRule{}).ResourceType())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rules")
+	}
+
+	posted := make(map[string]apimodels.PostableExtendedRuleNode, len(ruleGroupConfig.Rules))
+	for _, r := range ruleGroupConfig.Rules {
+		if r.GrafanaManagedAlert != nil && r.GrafanaManagedAlert.UID != "" {
+			posted[r.GrafanaManagedAlert.UID] = r
+		}
+	}
+
+	interval := int64(time.Duration(ruleGroupConfig.Interval).Seconds())
+	for _, existing := range q.Result {
+		if provenance := provenances[existing.UID]; provenance.CanUpdate(ngmodels.ProvenanceNone) {
+			continue
+		}
+
+		r, ok := posted[existing.UID]
+		if !ok {
+			return ErrResp(http.StatusConflict, ngmodels.ErrProvenanceChangeNotAllowed, "failed to delete provisioned alert rule %s", existing.Title)
+		}
+		if interval != existing.IntervalSeconds || isRuleChanged(*existing, r) {
+			return ErrResp(http.StatusConflict, ngmodels.ErrProvenanceChangeNotAllowed, "failed to update provisioned alert rule %s", existing.Title)
+		}
+	}
+	return nil
+}
+
+// isRuleChanged reports whether the posted rule differs from the stored rule.
+func isRuleChanged(existing ngmodels.AlertRule, r apimodels.PostableExtendedRuleNode) bool {
+	rule := r.GrafanaManagedAlert
+	if rule.Title != existing.Title || rule.Condition != existing.Condition ||
+		ngmodels.NoDataState(rule.NoDataState) != existing.NoDataState ||
+		ngmodels.ExecutionErrorState(rule.ExecErrState) != existing.ExecErrState ||
+		int64(time.Duration(rule.EvaluationTimeout).Seconds()) != existing.EvaluationTimeoutSeconds ||
+		rule.IsPaused != existing.IsPaused || rule.Record != existing.Record ||
+		int64(time.Duration(rule.HeartbeatTimeout).Seconds()) != existing.HeartbeatTimeoutSeconds ||
+		rule.CorrelationKey != existing.CorrelationKey || rule.MaxAlertInstances != existing.MaxAlertInstances {
+		return true
+	}
+
+	var apiRuleNode apimodels.ApiRuleNode
+	if r.ApiRuleNode != nil {
+		apiRuleNode = *r.ApiRuleNode
+	}
+	if time.Duration(apiRuleNode.For) != existing.For ||
+		!stringMapEqual(apiRuleNode.Labels, existing.Labels) ||
+		!stringMapEqual(apiRuleNode.Annotations, existing.Annotations) {
+		return true
+	}
+
+	// the stored queries are normalized when they are saved
+	data := make([]ngmodels.AlertQuery, len(rule.Data))
+	copy(data, rule.Data)
+	for i := range data {
+		if err := data[i].PreSave(); err != nil {
+			return true
+		}
+	}
+	postedData, err := json.Marshal(data)
+	if err != nil {
+		return true
+	}
+	existingData, err := json.Marshal(existing.Data)
+	if err != nil {
+		return true
+	}
+	return !bytes.Equal(postedData, existingData)
+}
+
+func stringMapEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
 }
 
 func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            r.Data,
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			NamespaceID:     namespaceID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			ID:                r.ID,
+			OrgID:             r.OrgID,
+			Title:             r.Title,
+			Condition:         r.Condition,
+			Data:              r.Data,
+			Updated:           r.Updated,
+			IntervalSeconds:   r.IntervalSeconds,
+			Version:           r.Version,
+			UID:               r.UID,
+			NamespaceUID:      r.NamespaceUID,
+			NamespaceID:       namespaceID,
+			RuleGroup:         r.RuleGroup,
+			NoDataState:       apimodels.NoDataState(r.NoDataState),
+			ExecErrState:      apimodels.ExecutionErrorState(r.ExecErrState),
+			EvaluationTimeout: model.Duration(time.Duration(r.EvaluationTimeoutSeconds) * time.Second),
+			IsPaused:          r.IsPaused,
+			Record:            r.Record,
+			HeartbeatTimeout:  model.Duration(time.Duration(r.HeartbeatTimeoutSeconds) * time.Second),
+			CorrelationKey:    r.CorrelationKey,
+			MaxAlertInstances: r.MaxAlertInstances,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type RulesSrv struct {
+	log   log.Logger
+	store store.RuleStore
+	ac    accesscontrol.AccessControl
+}
+
+func (srv RulesSrv) RouteGetRulesByDatasource(c *models.ReqContext) response.Response {
+	datasourceUID := c.Query("datasourceUID")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasourceUID is required"), "")
+	}
+
+	q := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	result := apimodels.DatasourceRules{
+		DatasourceUID: datasourceUID,
+		Rules:         make([]apimodels.DatasourceRule, 0),
+	}
+	// folders is nil for the folders the user can't read
+	folders := make(map[string]*models.Folder)
+	for _, rule := range q.Result {
+		if !queriesDatasource(rule, datasourceUID) {
+			continue
+		}
+
+		folder, ok := folders[rule.NamespaceUID]
+		if !ok {
+			f, err := srv.store.GetNamespaceByUID(rule.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser)
+			if err != nil && !errors.Is(err, models.ErrFolderAccessDenied) {
+				return ErrResp(http.StatusInternalServerError, err, "failed to get folder %s", rule.NamespaceUID)
+			}
+			if err == nil && canAccessRules(srv.ac, c, accesscontrol.ActionAlertingRuleRead, rule.NamespaceUID) {
+				folder = f
+			}
+			folders[rule.NamespaceUID] = folder
+		}
+		if folder == nil {
+			continue
+		}
+
+		result.Rules = append(result.Rules, apimodels.DatasourceRule{
+			UID:         rule.UID,
+			Title:       rule.Title,
+			FolderUID:   folder.Uid,
+			FolderTitle: folder.Title,
+			RuleGroup:   rule.RuleGroup,
+			IsPaused:    rule.IsPaused,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// queriesDatasource returns true if a query of the alert rule uses the data source.
+func queriesDatasource(rule *ngmodels.AlertRule, datasourceUID string) bool {
+	for _, q := range rule.Data {
+		if q.DatasourceUID == datasourceUID {
+			return true
+		}
+	}
+	return false
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -13,11 +15,21 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+const (
+	// defaultPreviewIntervalSeconds is the evaluation interval of previewed rules without an interval
+	defaultPreviewIntervalSeconds int64 = 60
+	// maxPreviewEvaluations limits the evaluations needed to preview the for duration of a rule
+	maxPreviewEvaluations = 100
+)
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	Cfg             *setting.Cfg
@@ -87,7 +99,7 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 	}
 
 	if _, err := validateQueriesAndExpressions(cmd.Data, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
-		return ErrResp(http.StatusBadRequest, err, "invalid queries or expressions")
+		return ErrResp(validationErrorStatus(err), err, "invalid queries or expressions")
 	}
 
 	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
@@ -98,3 +110,106 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+// RoutePreviewRule evaluates the rule every interval over its for duration with an in-memory state manager
+// and returns the alert instances as they would be after the last evaluation, nothing is saved.
+func (srv TestingApiSrv) RoutePreviewRule(c *models.ReqContext, body apimodels.PreviewRulePayload) response.Response {
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           body.Title,
+		Condition:       body.Condition,
+		Data:            body.Data,
+		IntervalSeconds: body.IntervalSeconds,
+		NoDataState:     body.NoDataState,
+		ExecErrState:    body.ExecErrState,
+		For:             time.Duration(body.For),
+		Labels:          body.Labels,
+		Annotations:     body.Annotations,
+	}
+	if rule.IntervalSeconds == 0 {
+		rule.IntervalSeconds = defaultPreviewIntervalSeconds
+	}
+	if rule.NoDataState == "" {
+		rule.NoDataState = ngmodels.NoData
+	}
+	if rule.ExecErrState == "" {
+		rule.ExecErrState = ngmodels.AlertingErrState
+	}
+
+	condition := ngmodels.Condition{
+		Condition: rule.Condition,
+		OrgID:     rule.OrgID,
+		Data:      rule.Data,
+	}
+	if err := validateCondition(condition, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(validationErrorStatus(err), err, "invalid condition")
+	}
+
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	// a pending instance fires on the first evaluation after the for duration has passed
+	evaluations := 1
+	if rule.For > 0 {
+		evaluations = int(rule.For/interval) + 2
+	}
+	if evaluations > maxPreviewEvaluations {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("previewing the rule requires %d evaluations, the maximum is %d", evaluations, maxPreviewEvaluations), "for duration is too long for the interval")
+	}
+
+	now := body.Now
+	if now.IsZero() {
+		now = timeNow()
+	}
+
+	// the state manager is private to the request so that the preview does not affect the alerting state and metrics
+	stateManager := state.NewManager(srv.log, metrics.NewMetrics(nil), nil)
+	defer stateManager.Close()
+
+	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	for i := evaluations - 1; i >= 0; i-- {
+		evalTime := now.Add(-time.Duration(i) * interval)
+		results, err := evaluator.ConditionEval(&condition, evalTime, srv.DataService)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to evaluate the rule at %s", evalTime.Format(time.RFC3339))
+		}
+		stateManager.ProcessEvalResults(rule, results)
+	}
+
+	states := stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].CacheId < states[j].CacheId
+	})
+	result := apimodels.PreviewRuleResponse{Instances: make([]apimodels.PreviewAlertInstance, 0, len(states))}
+	for _, s := range states {
+		result.Instances = append(result.Instances, toPreviewAlertInstance(s))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toPreviewAlertInstance(s *state.State) apimodels.PreviewAlertInstance {
+	labels := make(map[string]string, len(s.Labels))
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	// the previewed rule is not saved, it doesn't have a UID nor a folder
+	delete(labels, ngmodels.RuleUIDLabel)
+	delete(labels, ngmodels.NamespaceUIDLabel)
+
+	instance := apimodels.PreviewAlertInstance{
+		Labels:      labels,
+		Annotations: s.Annotations,
+		State:       s.State.String(),
+		StartsAt:    s.StartsAt,
+		Evaluations: make([]apimodels.PreviewEvaluation, 0, len(s.Results)),
+	}
+	if s.Error != nil {
+		instance.Error = s.Error.Error()
+	}
+	for _, r := range s.Results {
+		instance.Evaluations = append(instance.Evaluations, apimodels.PreviewEvaluation{
+			Time:             r.EvaluationTime,
+			State:            r.EvaluationState.String(),
+			EvaluationString: r.EvaluationString,
+		})
+	}
+	return instance
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// reqFolderPermissions is the fallback of the rule actions when access control is disabled:
+// the access to the rules is then given by the permissions of their folder, which are always checked.
+func reqFolderPermissions(*models.ReqContext) bool {
+	return true
+}
+
+func reqEditor(c *models.ReqContext) bool {
+	return c.HasUserRole(models.ROLE_EDITOR)
+}
+
+// canAccessRules returns true if the user is allowed to do the action on the rules of the folder.
+func canAccessRules(ac accesscontrol.AccessControl, c *models.ReqContext, action string, folderUID string) bool {
+	return accesscontrol.HasAccess(ac, c)(reqFolderPermissions, action, accesscontrol.ScopeFoldersPrefix+folderUID)
+}
+
+// authorizeRules returns an error response if the user isn't allowed to do the action on the rules of the folder.
+func authorizeRules(ac accesscontrol.AccessControl, c *models.ReqContext, action string, folderUID string) response.Response {
+	if !canAccessRules(ac, c, action, folderUID) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+	return nil
+}
+
+// authorizeNotifications returns an error response if the user isn't allowed to change the notifications
+// of the Grafana Alertmanager.
+func authorizeNotifications(ac accesscontrol.AccessControl, c *models.ReqContext) response.Response {
+	if !accesscontrol.HasAccess(ac, c)(reqEditor, accesscontrol.ActionAlertingNotificationsWrite) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+	return nil
+}
@@ -99,6 +99,51 @@ func (r *ForkedRuler) RouteGetRulesConfig(ctx *models.ReqContext) response.Respo
 	}
 }
 
+func (r *ForkedRuler) RoutePatchRuleGroupConfig(ctx *models.ReqContext, conf apimodels.PatchableRuleGroupConfig) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RoutePatchRuleGroupConfig(ctx, conf)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RoutePatchRuleGroupConfig(ctx, conf)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
+
+func (r *ForkedRuler) RoutePauseRuleGroup(ctx *models.ReqContext) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RoutePauseRuleGroup(ctx)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RoutePauseRuleGroup(ctx)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
+
+func (r *ForkedRuler) RouteResumeRuleGroup(ctx *models.ReqContext) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RouteResumeRuleGroup(ctx)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RouteResumeRuleGroup(ctx)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
+
 func (r *ForkedRuler) RoutePostNameRulesConfig(ctx *models.ReqContext, conf apimodels.PostableRuleGroupConfig) response.Response {
 	backendType, err := backendType(ctx, r.DatasourceCache)
 	if err != nil {
@@ -119,3 +164,33 @@ func (r *ForkedRuler) RoutePostNameRulesConfig(ctx *models.ReqContext, conf apim
 		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", backendType), "")
 	}
 }
+
+func (r *ForkedRuler) RouteGetNamespaceRulesExport(ctx *models.ReqContext) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RouteGetNamespaceRulesExport(ctx)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RouteGetNamespaceRulesExport(ctx)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
+
+func (r *ForkedRuler) RoutePostNamespaceRulesImport(ctx *models.ReqContext) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RoutePostNamespaceRulesImport(ctx)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RoutePostNamespaceRulesImport(ctx)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
@@ -120,6 +120,15 @@ func (am *ForkedAMSvc) RouteGetSilences(ctx *models.ReqContext) response.Respons
 	return s.RouteGetSilences(ctx)
 }
 
+func (am *ForkedAMSvc) RoutePreviewSilence(ctx *models.ReqContext, body apimodels.PostableSilence) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePreviewSilence(ctx, body)
+}
+
 func (am *ForkedAMSvc) RoutePostAlertingConfig(ctx *models.ReqContext, body apimodels.PostableUserConfig) response.Response {
 	s, err := am.getService(ctx)
 	if err != nil {
@@ -138,6 +147,33 @@ func (am *ForkedAMSvc) RoutePostAlertingConfig(ctx *models.ReqContext, body apim
 	return s.RoutePostAlertingConfig(ctx, body)
 }
 
+func (am *ForkedAMSvc) RoutePostTestReceivers(ctx *models.ReqContext, body apimodels.TestReceiversConfigBodyParams) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePostTestReceivers(ctx, body)
+}
+
+func (am *ForkedAMSvc) RoutePostTestRoutes(ctx *models.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePostTestRoutes(ctx, body)
+}
+
+func (am *ForkedAMSvc) RoutePostTestTemplates(ctx *models.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePostTestTemplates(ctx, body)
+}
+
 func (am *ForkedAMSvc) RoutePostAMAlerts(ctx *models.ReqContext, body apimodels.PostableAlerts) response.Response {
 	s, err := am.getService(ctx)
 	if err != nil {
@@ -146,3 +182,183 @@ func (am *ForkedAMSvc) RoutePostAMAlerts(ctx *models.ReqContext, body apimodels.
 
 	return s.RoutePostAMAlerts(ctx, body)
 }
+
+func (am *ForkedAMSvc) RouteGetNotificationLog(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetNotificationLog(ctx)
+}
+
+func (am *ForkedAMSvc) RouteResendNotification(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteResendNotification(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetMuteTimeIntervals(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetMuteTimeIntervals(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetMuteTimeInterval(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetMuteTimeInterval(ctx)
+}
+
+func (am *ForkedAMSvc) RoutePostMuteTimeInterval(ctx *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePostMuteTimeInterval(ctx, body)
+}
+
+func (am *ForkedAMSvc) RoutePutMuteTimeInterval(ctx *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePutMuteTimeInterval(ctx, body)
+}
+
+func (am *ForkedAMSvc) RouteDeleteMuteTimeInterval(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteDeleteMuteTimeInterval(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetEscalationPolicies(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetEscalationPolicies(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetEscalationPolicy(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetEscalationPolicy(ctx)
+}
+
+func (am *ForkedAMSvc) RoutePostEscalationPolicy(ctx *models.ReqContext, body apimodels.EscalationPolicy) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePostEscalationPolicy(ctx, body)
+}
+
+func (am *ForkedAMSvc) RoutePutEscalationPolicy(ctx *models.ReqContext, body apimodels.EscalationPolicy) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePutEscalationPolicy(ctx, body)
+}
+
+func (am *ForkedAMSvc) RouteDeleteEscalationPolicy(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteDeleteEscalationPolicy(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetEscalations(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetEscalations(ctx)
+}
+
+func (am *ForkedAMSvc) RouteAcknowledgeEscalation(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteAcknowledgeEscalation(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetSilenceExpiryNotification(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetSilenceExpiryNotification(ctx)
+}
+
+func (am *ForkedAMSvc) RoutePutSilenceExpiryNotification(ctx *models.ReqContext, body apimodels.SilenceExpiryNotification) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePutSilenceExpiryNotification(ctx, body)
+}
+
+func (am *ForkedAMSvc) RouteDeleteSilenceExpiryNotification(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteDeleteSilenceExpiryNotification(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetMaintenance(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetMaintenance(ctx)
+}
+
+func (am *ForkedAMSvc) RoutePutMaintenance(ctx *models.ReqContext, body apimodels.Maintenance) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePutMaintenance(ctx, body)
+}
+
+func (am *ForkedAMSvc) RouteDeleteMaintenance(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteDeleteMaintenance(ctx)
+}
@@ -21,17 +21,41 @@ import (
 )
 
 type AlertmanagerApiService interface {
+	RouteAcknowledgeEscalation(*models.ReqContext) response.Response
 	RouteCreateSilence(*models.ReqContext, apimodels.PostableSilence) response.Response
 	RouteDeleteAlertingConfig(*models.ReqContext) response.Response
+	RouteDeleteEscalationPolicy(*models.ReqContext) response.Response
+	RouteDeleteMaintenance(*models.ReqContext) response.Response
+	RouteDeleteMuteTimeInterval(*models.ReqContext) response.Response
 	RouteDeleteSilence(*models.ReqContext) response.Response
+	RouteDeleteSilenceExpiryNotification(*models.ReqContext) response.Response
 	RouteGetAMAlertGroups(*models.ReqContext) response.Response
 	RouteGetAMAlerts(*models.ReqContext) response.Response
 	RouteGetAMStatus(*models.ReqContext) response.Response
 	RouteGetAlertingConfig(*models.ReqContext) response.Response
+	RouteGetEscalationPolicies(*models.ReqContext) response.Response
+	RouteGetEscalationPolicy(*models.ReqContext) response.Response
+	RouteGetEscalations(*models.ReqContext) response.Response
+	RouteGetMaintenance(*models.ReqContext) response.Response
+	RouteGetMuteTimeInterval(*models.ReqContext) response.Response
+	RouteGetMuteTimeIntervals(*models.ReqContext) response.Response
+	RouteGetNotificationLog(*models.ReqContext) response.Response
 	RouteGetSilence(*models.ReqContext) response.Response
+	RouteGetSilenceExpiryNotification(*models.ReqContext) response.Response
 	RouteGetSilences(*models.ReqContext) response.Response
 	RoutePostAMAlerts(*models.ReqContext, apimodels.PostableAlerts) response.Response
 	RoutePostAlertingConfig(*models.ReqContext, apimodels.PostableUserConfig) response.Response
+	RoutePostEscalationPolicy(*models.ReqContext, apimodels.EscalationPolicy) response.Response
+	RoutePostMuteTimeInterval(*models.ReqContext, apimodels.MuteTimeInterval) response.Response
+	RoutePostTestReceivers(*models.ReqContext, apimodels.TestReceiversConfigBodyParams) response.Response
+	RoutePostTestRoutes(*models.ReqContext, apimodels.TestRoutesConfigBodyParams) response.Response
+	RoutePostTestTemplates(*models.ReqContext, apimodels.TestTemplatesConfigBodyParams) response.Response
+	RoutePreviewSilence(*models.ReqContext, apimodels.PostableSilence) response.Response
+	RoutePutEscalationPolicy(*models.ReqContext, apimodels.EscalationPolicy) response.Response
+	RoutePutMaintenance(*models.ReqContext, apimodels.Maintenance) response.Response
+	RoutePutMuteTimeInterval(*models.ReqContext, apimodels.MuteTimeInterval) response.Response
+	RoutePutSilenceExpiryNotification(*models.ReqContext, apimodels.SilenceExpiryNotification) response.Response
+	RouteResendNotification(*models.ReqContext) response.Response
 }
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *metrics.Metrics) {
@@ -46,6 +70,16 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silences/preview"),
+			binding.Bind(apimodels.PostableSilence{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/api/v2/silences/preview",
+				srv.RoutePreviewSilence,
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/alerts"),
 			metrics.Instrument(
@@ -138,5 +172,221 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/receivers/test"),
+			binding.Bind(apimodels.TestReceiversConfigBodyParams{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/receivers/test",
+				srv.RoutePostTestReceivers,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/routes/test"),
+			binding.Bind(apimodels.TestRoutesConfigBodyParams{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/routes/test",
+				srv.RoutePostTestRoutes,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/templates/test"),
+			binding.Bind(apimodels.TestTemplatesConfigBodyParams{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/templates/test",
+				srv.RoutePostTestTemplates,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/notifications"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/notifications",
+				srv.RouteGetNotificationLog,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/notifications/{NotificationId}/resend"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/api/v2/notifications/{NotificationId}/resend",
+				srv.RouteResendNotification,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/mute-timings"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/config/api/v1/mute-timings",
+				srv.RouteGetMuteTimeIntervals,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName}",
+				srv.RouteGetMuteTimeInterval,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/mute-timings"),
+			binding.Bind(apimodels.MuteTimeInterval{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/mute-timings",
+				srv.RoutePostMuteTimeInterval,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName}"),
+			binding.Bind(apimodels.MuteTimeInterval{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName}",
+				srv.RoutePutMuteTimeInterval,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName}",
+				srv.RouteDeleteMuteTimeInterval,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/escalation-policies"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/config/api/v1/escalation-policies",
+				srv.RouteGetEscalationPolicies,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID}",
+				srv.RouteGetEscalationPolicy,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/escalation-policies"),
+			binding.Bind(apimodels.EscalationPolicy{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/escalation-policies",
+				srv.RoutePostEscalationPolicy,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID}"),
+			binding.Bind(apimodels.EscalationPolicy{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID}",
+				srv.RoutePutEscalationPolicy,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID}",
+				srv.RouteDeleteEscalationPolicy,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/escalations"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/escalations",
+				srv.RouteGetEscalations,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/escalations/{EscalationId}/acknowledge"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/api/v2/escalations/{EscalationId}/acknowledge",
+				srv.RouteAcknowledgeEscalation,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification",
+				srv.RouteGetSilenceExpiryNotification,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification"),
+			binding.Bind(apimodels.SilenceExpiryNotification{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification",
+				srv.RoutePutSilenceExpiryNotification,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification",
+				srv.RouteDeleteSilenceExpiryNotification,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/maintenance"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/maintenance",
+				srv.RouteGetMaintenance,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/maintenance"),
+			binding.Bind(apimodels.Maintenance{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/{Recipient}/api/v2/maintenance",
+				srv.RoutePutMaintenance,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/maintenance"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/{Recipient}/api/v2/maintenance",
+				srv.RouteDeleteMaintenance,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
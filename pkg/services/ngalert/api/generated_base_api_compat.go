@@ -0,0 +1,46 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type CompatApiService interface {
+	RouteGetCompatAlerts(*models.ReqContext) response.Response
+	RouteGetCompatRules(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterCompatApiEndpoints(srv CompatApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/prometheus-compat/api/v1/alerts"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus-compat/api/v1/alerts",
+				srv.RouteGetCompatAlerts,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/prometheus-compat/api/v1/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus-compat/api/v1/rules",
+				srv.RouteGetCompatRules,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,80 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type HistoryApiService interface {
+	RouteGetRuleStateHistory(*models.ReqContext) response.Response
+	RouteGetRuleVersion(*models.ReqContext) response.Response
+	RouteGetRuleVersions(*models.ReqContext) response.Response
+	RouteGetRuleVersionsDiff(*models.ReqContext) response.Response
+	RoutePostRuleVersionRestore(*models.ReqContext, apimodels.RuleVersionRestore) response.Response
+}
+
+func (api *API) RegisterHistoryApiEndpoints(srv HistoryApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/rules/{UID}/history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/{UID}/history",
+				srv.RouteGetRuleStateHistory,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/{UID}/versions/{Version}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/{UID}/versions/{Version}",
+				srv.RouteGetRuleVersion,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/{UID}/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/{UID}/versions",
+				srv.RouteGetRuleVersions,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/{UID}/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/{UID}/diff",
+				srv.RouteGetRuleVersionsDiff,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/rules/{UID}/restore"),
+			binding.Bind(apimodels.RuleVersionRestore{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/{UID}/restore",
+				srv.RoutePostRuleVersionRestore,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,216 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type ProvisioningApiService interface {
+	RouteDeleteAlertRule(*models.ReqContext) response.Response
+	RouteDeleteContactpoints(*models.ReqContext) response.Response
+	RouteDeleteMuteTiming(*models.ReqContext) response.Response
+	RouteGetAlertRule(*models.ReqContext) response.Response
+	RouteGetContactpoints(*models.ReqContext) response.Response
+	RouteGetMuteTiming(*models.ReqContext) response.Response
+	RouteGetMuteTimings(*models.ReqContext) response.Response
+	RouteGetPolicyTree(*models.ReqContext) response.Response
+	RouteGetProvisioningExport(*models.ReqContext) response.Response
+	RoutePostAlertRule(*models.ReqContext, apimodels.ProvisionedAlertRule) response.Response
+	RoutePostContactpoints(*models.ReqContext, apimodels.EmbeddedContactPoint) response.Response
+	RoutePostMuteTiming(*models.ReqContext, apimodels.ProvisionedMuteTimeInterval) response.Response
+	RoutePutAlertRule(*models.ReqContext, apimodels.ProvisionedAlertRule) response.Response
+	RoutePutContactpoint(*models.ReqContext, apimodels.EmbeddedContactPoint) response.Response
+	RoutePutMuteTiming(*models.ReqContext, apimodels.ProvisionedMuteTimeInterval) response.Response
+	RoutePutPolicyTree(*models.ReqContext, apimodels.NotificationPolicyTree) response.Response
+}
+
+func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiService, m *metrics.Metrics) {
+	authorize := acmiddleware.Middleware(api.AccessControl)
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningRead),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rules/{UID}",
+				srv.RouteGetAlertRule,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rules"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			middleware.Idempotency(api.Cfg, api.RemoteCache),
+			binding.Bind(apimodels.ProvisionedAlertRule{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rules",
+				srv.RoutePostAlertRule,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			binding.Bind(apimodels.ProvisionedAlertRule{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/alert-rules/{UID}",
+				srv.RoutePutAlertRule,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/alert-rules/{UID}",
+				srv.RouteDeleteAlertRule,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/contact-points"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningRead),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/contact-points",
+				srv.RouteGetContactpoints,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/contact-points"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			binding.Bind(apimodels.EmbeddedContactPoint{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/contact-points",
+				srv.RoutePostContactpoints,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/contact-points/{UID}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			binding.Bind(apimodels.EmbeddedContactPoint{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/contact-points/{UID}",
+				srv.RoutePutContactpoint,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/contact-points/{UID}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/contact-points/{UID}",
+				srv.RouteDeleteContactpoints,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/policies"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningRead),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/policies",
+				srv.RouteGetPolicyTree,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/policies"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			binding.Bind(apimodels.NotificationPolicyTree{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/policies",
+				srv.RoutePutPolicyTree,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningRead),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings",
+				srv.RouteGetMuteTimings,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningRead),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/{name}",
+				srv.RouteGetMuteTiming,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/mute-timings"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			binding.Bind(apimodels.ProvisionedMuteTimeInterval{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/mute-timings",
+				srv.RoutePostMuteTiming,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			binding.Bind(apimodels.ProvisionedMuteTimeInterval{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/mute-timings/{name}",
+				srv.RoutePutMuteTiming,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningWrite),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/mute-timings/{name}",
+				srv.RouteDeleteMuteTiming,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/export"),
+			authorize(middleware.ReqEditorRole, accesscontrol.ActionAlertingProvisioningRead),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/export",
+				srv.RouteGetProvisioningExport,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
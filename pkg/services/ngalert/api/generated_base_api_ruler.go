@@ -24,9 +24,14 @@ type RulerApiService interface {
 	RouteDeleteNamespaceRulesConfig(*models.ReqContext) response.Response
 	RouteDeleteRuleGroupConfig(*models.ReqContext) response.Response
 	RouteGetNamespaceRulesConfig(*models.ReqContext) response.Response
+	RouteGetNamespaceRulesExport(*models.ReqContext) response.Response
 	RouteGetRulegGroupConfig(*models.ReqContext) response.Response
 	RouteGetRulesConfig(*models.ReqContext) response.Response
+	RoutePatchRuleGroupConfig(*models.ReqContext, apimodels.PatchableRuleGroupConfig) response.Response
+	RoutePauseRuleGroup(*models.ReqContext) response.Response
 	RoutePostNameRulesConfig(*models.ReqContext, apimodels.PostableRuleGroupConfig) response.Response
+	RoutePostNamespaceRulesImport(*models.ReqContext) response.Response
+	RouteResumeRuleGroup(*models.ReqContext) response.Response
 }
 
 func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metrics) {
@@ -86,5 +91,51 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 				m,
 			),
 		)
+		group.Patch(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
+			binding.Bind(apimodels.PatchableRuleGroupConfig{}),
+			metrics.Instrument(
+				http.MethodPatch,
+				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}",
+				srv.RoutePatchRuleGroupConfig,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}/pause"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}/pause",
+				srv.RoutePauseRuleGroup,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}/resume"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}/resume",
+				srv.RouteResumeRuleGroup,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/export/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/{Recipient}/api/v1/export/{Namespace}",
+				srv.RouteGetNamespaceRulesExport,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/import/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/{Recipient}/api/v1/import/{Namespace}",
+				srv.RoutePostNamespaceRulesImport,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -22,6 +22,7 @@ import (
 
 type TestingApiService interface {
 	RouteEvalQueries(*models.ReqContext, apimodels.EvalQueriesPayload) response.Response
+	RoutePreviewRule(*models.ReqContext, apimodels.PreviewRulePayload) response.Response
 	RouteTestReceiverConfig(*models.ReqContext, apimodels.ExtendedReceiver) response.Response
 	RouteTestRuleConfig(*models.ReqContext, apimodels.TestRulePayload) response.Response
 }
@@ -48,6 +49,16 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/test"),
+			binding.Bind(apimodels.PreviewRulePayload{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/test",
+				srv.RoutePreviewRule,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{Recipient}"),
 			binding.Bind(apimodels.TestRulePayload{}),
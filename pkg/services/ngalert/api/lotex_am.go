@@ -192,3 +192,99 @@ func (am *LotexAM) RoutePostAMAlerts(ctx *models.ReqContext, alerts apimodels.Po
 		nil,
 	)
 }
+
+func (am *LotexAM) RouteGetNotificationLog(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePreviewSilence(ctx *models.ReqContext, body apimodels.PostableSilence) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteResendNotification(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePostTestReceivers(ctx *models.ReqContext, body apimodels.TestReceiversConfigBodyParams) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePostTestRoutes(ctx *models.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePostTestTemplates(ctx *models.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetMuteTimeIntervals(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetMuteTimeInterval(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePostMuteTimeInterval(ctx *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePutMuteTimeInterval(ctx *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteDeleteMuteTimeInterval(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetEscalationPolicies(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetEscalationPolicy(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePostEscalationPolicy(ctx *models.ReqContext, body apimodels.EscalationPolicy) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePutEscalationPolicy(ctx *models.ReqContext, body apimodels.EscalationPolicy) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteDeleteEscalationPolicy(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetEscalations(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteAcknowledgeEscalation(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetSilenceExpiryNotification(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePutSilenceExpiryNotification(ctx *models.ReqContext, body apimodels.SilenceExpiryNotification) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteDeleteSilenceExpiryNotification(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteGetMaintenance(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RoutePutMaintenance(ctx *models.ReqContext, body apimodels.Maintenance) response.Response {
+	return NotImplementedResp
+}
+
+func (am *LotexAM) RouteDeleteMaintenance(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
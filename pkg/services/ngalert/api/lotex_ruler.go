@@ -149,6 +149,26 @@ func (r *LotexRuler) RoutePostNameRulesConfig(ctx *models.ReqContext, conf apimo
 	return r.withReq(ctx, http.MethodPost, u, bytes.NewBuffer(yml), jsonExtractor(nil), nil)
 }
 
+func (r *LotexRuler) RoutePatchRuleGroupConfig(ctx *models.ReqContext, conf apimodels.PatchableRuleGroupConfig) response.Response {
+	return NotImplementedResp
+}
+
+func (r *LotexRuler) RoutePauseRuleGroup(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (r *LotexRuler) RouteResumeRuleGroup(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (r *LotexRuler) RouteGetNamespaceRulesExport(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+func (r *LotexRuler) RoutePostNamespaceRulesImport(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
 func (r *LotexRuler) getPrefix(ctx *models.ReqContext) (string, error) {
 	ds, err := r.DataProxy.DatasourceCache.GetDatasource(ctx.ParamsInt64("Recipient"), ctx.SignedInUser, ctx.SkipCache)
 	if err != nil {
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// promRuleQueryRefID and promRuleConditionRefID are the ref IDs of the expression query
+	// and of the condition of the imported rules.
+	promRuleQueryRefID     = "A"
+	promRuleConditionRefID = "B"
+	// promRuleQueryRange is the time range the expressions of the imported rules are queried over,
+	// the rules fire for every series returned in this range.
+	promRuleQueryRange = time.Minute
+)
+
+var (
+	// promRuleDatasourceTypes are the types of the data sources the expressions of the Prometheus rule files are written for.
+	promRuleDatasourceTypes = map[string]bool{
+		models.DS_PROMETHEUS: true,
+		"loki":               true,
+	}
+
+	errUnsupportedPromRule = errors.New("the rule cannot be represented in the Prometheus rule format")
+
+	promRuleMathCondition = regexp.MustCompile(`^\s*\$\{?(\w+)\}?\s*(>|<|>=|<=|==|!=)\s*(\S+)\s*$`)
+)
+
+// fromPrometheusRuleGroup converts a group of a Prometheus rule file to a rule group of Grafana managed rules
+// querying their expression from the given data source. The rules get the UID of the existing rules of the group
+// with the same title.
+func fromPrometheusRuleGroup(group apimodels.PrometheusRuleGroup, ds *models.DataSource, existingUIDs map[string]string) (apimodels.PostableRuleGroupConfig, error) {
+	config := apimodels.PostableRuleGroupConfig{
+		Name:     group.Name,
+		Interval: group.Interval,
+		Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+	}
+	if group.Name == "" {
+		return config, errors.New("rule group name is not valid")
+	}
+
+	titles := make(map[string]struct{}, len(group.Rules))
+	for _, r := range group.Rules {
+		if r.Record != "" {
+			return config, fmt.Errorf("recording rule %s is not supported", r.Record)
+		}
+		if r.Alert == "" {
+			return config, errors.New("alert name is missing")
+		}
+		if _, ok := titles[r.Alert]; ok {
+			return config, fmt.Errorf("alert %s is defined more than once", r.Alert)
+		}
+		titles[r.Alert] = struct{}{}
+		if r.Expr == "" {
+			return config, fmt.Errorf("expression of alert %s is missing", r.Alert)
+		}
+		if ds.Type == models.DS_PROMETHEUS {
+			if _, err := parser.ParseExpr(r.Expr); err != nil {
+				return config, fmt.Errorf("invalid expression of alert %s: %w", r.Alert, err)
+			}
+		}
+
+		query, err := json.Marshal(map[string]interface{}{
+			"refId": promRuleQueryRefID,
+			"expr":  r.Expr,
+		})
+		if err != nil {
+			return config, err
+		}
+		// Prometheus fires an alert for every series returned by the expression
+		condition, err := json.Marshal(map[string]interface{}{
+			"refId":      promRuleConditionRefID,
+			"type":       "reduce",
+			"expression": promRuleQueryRefID,
+			"reducer":    "count",
+		})
+		if err != nil {
+			return config, err
+		}
+		timeRange := ngmodels.RelativeTimeRange{From: ngmodels.Duration(promRuleQueryRange)}
+
+		config.Rules = append(config.Rules, apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				For:         r.For,
+				Labels:      r.Labels,
+				Annotations: r.Annotations,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     r.Alert,
+				Condition: promRuleConditionRefID,
+				Data: []ngmodels.AlertQuery{
+					{RefID: promRuleQueryRefID, RelativeTimeRange: timeRange, DatasourceUID: ds.Uid, Model: query},
+					{RefID: promRuleConditionRefID, RelativeTimeRange: timeRange, DatasourceUID: expr.DatasourceUID, Model: condition},
+				},
+				UID: existingUIDs[r.Alert],
+				// Prometheus doesn't fire when the expression returns nothing and keeps the state of the alerts
+				// when the expression fails
+				NoDataState:  apimodels.OK,
+				ExecErrState: apimodels.KeepLastStateErrState,
+			},
+		})
+	}
+	return config, nil
+}
+
+// toPrometheusRuleGroup converts a rule group of Grafana managed rules to a group of a Prometheus rule file.
+// datasourceType returns the type of the data source with the given UID.
+func toPrometheusRuleGroup(name string, rules []*ngmodels.AlertRule, datasourceType func(uid string) (string, error)) (apimodels.PrometheusRuleGroup, error) {
+	group := apimodels.PrometheusRuleGroup{
+		Name:  name,
+		Rules: make([]apimodels.ApiRuleNode, 0, len(rules)),
+	}
+	for _, r := range rules {
+		group.Interval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
+		e, err := toPrometheusExpr(r, datasourceType)
+		if err != nil {
+			return group, fmt.Errorf("%w: %s: %s", errUnsupportedPromRule, r.Title, err)
+		}
+		group.Rules = append(group.Rules, apimodels.ApiRuleNode{
+			Alert:       r.Title,
+			Expr:        e,
+			For:         model.Duration(r.For),
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+		})
+	}
+	return group, nil
+}
+
+// toPrometheusExpr returns the PromQL or LogQL expression equivalent to the condition of the rule.
+// The supported conditions are a count reduction of a Prometheus or Loki query, as created by the import,
+// a last value reduction of such a query and a comparison of this reduction with a number.
+func toPrometheusExpr(rule *ngmodels.AlertRule, datasourceType func(uid string) (string, error)) (string, error) {
+	if rule.IsRecording() {
+		return "", errors.New("recording rules are not supported")
+	}
+	if rule.IsHeartbeat() {
+		return "", errors.New("heartbeat rules are not supported")
+	}
+
+	queries := make(map[string]ngmodels.AlertQuery, len(rule.Data))
+	for _, q := range rule.Data {
+		queries[q.RefID] = q
+	}
+
+	condition, ok := queries[rule.Condition]
+	if !ok {
+		return "", fmt.Errorf("condition %s not found", rule.Condition)
+	}
+	conditionModel, err := expressionModel(condition)
+	if err != nil {
+		return "", err
+	}
+
+	switch conditionModel["type"] {
+	case "reduce":
+		e, reducer, err := reducedExpr(conditionModel, queries, datasourceType)
+		if err != nil {
+			return "", err
+		}
+		switch reducer {
+		case "count":
+			return e, nil
+		case "last":
+			return fmt.Sprintf("(%s) != 0", e), nil
+		}
+		return "", fmt.Errorf("reducer %s is not supported", reducer)
+	case "math":
+		expression, _ := conditionModel["expression"].(string)
+		m := promRuleMathCondition.FindStringSubmatch(expression)
+		if m == nil {
+			return "", fmt.Errorf("math expression %q is not a comparison of a reduced query with a number", expression)
+		}
+		if _, err := strconv.ParseFloat(m[3], 64); err != nil {
+			return "", fmt.Errorf("math expression %q is not a comparison of a reduced query with a number", expression)
+		}
+
+		reduce, ok := queries[m[1]]
+		if !ok {
+			return "", fmt.Errorf("expression %s not found", m[1])
+		}
+		reduceModel, err := expressionModel(reduce)
+		if err != nil {
+			return "", err
+		}
+		if reduceModel["type"] != "reduce" {
+			return "", fmt.Errorf("expression %s is not a reduction", m[1])
+		}
+		e, reducer, err := reducedExpr(reduceModel, queries, datasourceType)
+		if err != nil {
+			return "", err
+		}
+		if reducer != "last" {
+			return "", fmt.Errorf("reducer %s is not supported", reducer)
+		}
+		return fmt.Sprintf("(%s) %s %s", e, m[2], m[3]), nil
+	}
+	return "", fmt.Errorf("condition %s is not a reduce or a math expression", rule.Condition)
+}
+
+// reducedExpr returns the PromQL or LogQL expression of the query reduced by the reduce expression and the reducer.
+func reducedExpr(reduceModel map[string]interface{}, queries map[string]ngmodels.AlertQuery, datasourceType func(uid string) (string, error)) (string, string, error) {
+	refID, _ := reduceModel["expression"].(string)
+	refID = strings.TrimPrefix(refID, "$")
+	reducer, _ := reduceModel["reducer"].(string)
+
+	q, ok := queries[refID]
+	if !ok {
+		return "", "", fmt.Errorf("query %s not found", refID)
+	}
+	if q.DatasourceUID == expr.DatasourceUID {
+		return "", "", fmt.Errorf("%s is not a data source query", refID)
+	}
+	t, err := datasourceType(q.DatasourceUID)
+	if err != nil {
+		return "", "", err
+	}
+	if !promRuleDatasourceTypes[t] {
+		return "", "", fmt.Errorf("query %s is not a Prometheus or Loki query", refID)
+	}
+
+	var queryModel map[string]interface{}
+	if err := json.Unmarshal(q.Model, &queryModel); err != nil {
+		return "", "", err
+	}
+	e, _ := queryModel["expr"].(string)
+	if e == "" {
+		return "", "", fmt.Errorf("query %s has no expression", refID)
+	}
+	return e, reducer, nil
+}
+
+func expressionModel(q ngmodels.AlertQuery) (map[string]interface{}, error) {
+	if q.DatasourceUID != expr.DatasourceUID {
+		return nil, fmt.Errorf("%s is not an expression", q.RefID)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
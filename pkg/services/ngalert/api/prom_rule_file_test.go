@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestPrometheusRuleFile(t *testing.T) {
+	ds := &models.DataSource{Uid: "prom", Type: models.DS_PROMETHEUS}
+	datasourceType := func(uid string) (string, error) {
+		switch uid {
+		case "prom":
+			return models.DS_PROMETHEUS, nil
+		case "testdata":
+			return "testdata", nil
+		}
+		return "", errors.New("data source not found")
+	}
+
+	group := apimodels.PrometheusRuleGroup{
+		Name:     "group",
+		Interval: model.Duration(time.Minute),
+		Rules: []apimodels.ApiRuleNode{
+			{
+				Alert:       "HighErrorRate",
+				Expr:        `rate(errors_total[5m]) > 0.1`,
+				For:         model.Duration(5 * time.Minute),
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "too many errors"},
+			},
+		},
+	}
+
+	t.Run("imported rules are exported unchanged", func(t *testing.T) {
+		config, err := fromPrometheusRuleGroup(group, ds, map[string]string{"HighErrorRate": "uid"})
+		require.NoError(t, err)
+		require.Len(t, config.Rules, 1)
+		rule := config.Rules[0].GrafanaManagedAlert
+		require.Equal(t, "uid", rule.UID)
+		require.Equal(t, apimodels.OK, rule.NoDataState)
+		require.Equal(t, apimodels.KeepLastStateErrState, rule.ExecErrState)
+
+		exported, err := toPrometheusRuleGroup(config.Name, []*ngmodels.AlertRule{{
+			Title:           rule.Title,
+			Condition:       rule.Condition,
+			Data:            rule.Data,
+			IntervalSeconds: 60,
+			For:             time.Duration(config.Rules[0].For),
+			Labels:          config.Rules[0].Labels,
+			Annotations:     config.Rules[0].Annotations,
+		}}, datasourceType)
+		require.NoError(t, err)
+		require.Equal(t, group, exported)
+	})
+
+	t.Run("comparison of the last value is exported", func(t *testing.T) {
+		exported, err := toPrometheusRuleGroup("group", []*ngmodels.AlertRule{{
+			Title:     "HighLatency",
+			Condition: "C",
+			Data: []ngmodels.AlertQuery{
+				{RefID: "A", DatasourceUID: "prom", Model: []byte(`{"expr": "latency_seconds"}`)},
+				{RefID: "B", DatasourceUID: "-100", Model: []byte(`{"type": "reduce", "expression": "A", "reducer": "last"}`)},
+				{RefID: "C", DatasourceUID: "-100", Model: []byte(`{"type": "math", "expression": "$B >= 0.5"}`)},
+			},
+		}}, datasourceType)
+		require.NoError(t, err)
+		require.Equal(t, "(latency_seconds) >= 0.5", exported.Rules[0].Expr)
+	})
+
+	t.Run("rules that cannot be represented are not exported", func(t *testing.T) {
+		for name, data := range map[string][]ngmodels.AlertQuery{
+			"not a Prometheus query": {
+				{RefID: "A", DatasourceUID: "testdata", Model: []byte(`{"scenarioId": "random_walk"}`)},
+				{RefID: "B", DatasourceUID: "-100", Model: []byte(`{"type": "reduce", "expression": "A", "reducer": "count"}`)},
+			},
+			"unsupported reducer": {
+				{RefID: "A", DatasourceUID: "prom", Model: []byte(`{"expr": "up"}`)},
+				{RefID: "B", DatasourceUID: "-100", Model: []byte(`{"type": "reduce", "expression": "A", "reducer": "mean"}`)},
+			},
+			"unsupported math expression": {
+				{RefID: "A", DatasourceUID: "prom", Model: []byte(`{"expr": "up"}`)},
+				{RefID: "C", DatasourceUID: "-100", Model: []byte(`{"type": "math", "expression": "abs($A) > 1"}`)},
+				{RefID: "B", DatasourceUID: "-100", Model: []byte(`{"type": "math", "expression": "$C > 1"}`)},
+			},
+			"query as condition": {
+				{RefID: "B", DatasourceUID: "prom", Model: []byte(`{"expr": "up"}`)},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := toPrometheusRuleGroup("group", []*ngmodels.AlertRule{{Title: "rule", Condition: "B", Data: data}}, datasourceType)
+				require.ErrorIs(t, err, errUnsupportedPromRule)
+			})
+		}
+	})
+
+	t.Run("invalid rules are not imported", func(t *testing.T) {
+		for name, rule := range map[string]apimodels.ApiRuleNode{
+			"recording rule":     {Record: "job:errors:rate5m", Expr: `rate(errors_total[5m])`},
+			"missing alert name": {Expr: `up == 0`},
+			"missing expression": {Alert: "Down"},
+			"invalid expression": {Alert: "Down", Expr: `up ==`},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := fromPrometheusRuleGroup(apimodels.PrometheusRuleGroup{
+					Name:  "group",
+					Rules: []apimodels.ApiRuleNode{rule},
+				}, ds, nil)
+				require.Error(t, err)
+			})
+		}
+	})
+}
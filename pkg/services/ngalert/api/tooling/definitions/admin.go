@@ -0,0 +1,133 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/v1/ngalert/admin_config configuration RouteGetNGalertConfig
+//
+// Get the NGalert configuration of the user's organization, returns 404 if no configuration is present.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableNGalertConfig
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/ngalert/admin_config configuration RoutePostNGalertConfig
+//
+// Creates or updates the NGalert configuration of the user's organization.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: Ack
+//       400: ValidationError
+
+// swagger:route DELETE /api/v1/ngalert/admin_config configuration RouteDeleteNGalertConfig
+//
+// Deletes the NGalert configuration of the user's organization, the alerts are then only sent to the Grafana Alertmanager.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: Ack
+
+// swagger:route GET /api/v1/ngalert/alertmanagers configuration RouteGetAlertmanagers
+//
+// Get the delivery status of the external Alertmanagers the alerts of the user's organization are sent to.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableAlertmanagers
+
+// swagger:parameters RoutePostNGalertConfig
+type NGalertConfig struct {
+	// in:body
+	Body PostableNGalertConfig
+}
+
+// swagger:model
+type PostableNGalertConfig struct {
+	// Alertmanagers are the URLs of the external Alertmanagers, basic auth credentials can be part of the URL.
+	Alertmanagers []string `json:"alertmanagers"`
+	// AlertmanagersChoice is one of "internal", "external" or "both", it defaults to "internal".
+	AlertmanagersChoice string `json:"alertmanagersChoice"`
+	// Enrichment of the labels and annotations of the alerts before they are sent to the Alertmanagers.
+	Enrichment *EnrichmentConfig `json:"enrichment,omitempty"`
+}
+
+// swagger:model
+type GettableNGalertConfig struct {
+	Alertmanagers       []string          `json:"alertmanagers"`
+	AlertmanagersChoice string            `json:"alertmanagersChoice"`
+	Enrichment          *EnrichmentConfig `json:"enrichment,omitempty"`
+}
+
+// EnrichmentConfig is the enrichment of the alerts of an organisation: the static labels and annotations
+// are added first, then the webhook is called and finally the labels are rewritten.
+// swagger:model
+type EnrichmentConfig struct {
+	// Labels and Annotations are added to all the alerts, unless the alerts have them already.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Folders are the labels and annotations added to the alerts of the rules of a folder, by folder UID.
+	// They take precedence over the ones of the organisation.
+	Folders map[string]FolderEnrichment `json:"folders,omitempty"`
+	// Rewrites are applied in order to the labels of the alerts.
+	Rewrites []LabelRewrite     `json:"rewrites,omitempty"`
+	Webhook  *EnrichmentWebhook `json:"webhook,omitempty"`
+}
+
+// swagger:model
+type FolderEnrichment struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// LabelRewrite rewrites the labels of the alerts, the labels starting with "__" are left untouched.
+// swagger:model
+type LabelRewrite struct {
+	// Action is "replace" to set the target label to the replacement when the whole value of the source
+	// label matches the regex, or "drop" to remove the labels whose whole name matches the regex.
+	Action      string `json:"action"`
+	SourceLabel string `json:"sourceLabel,omitempty"`
+	Regex       string `json:"regex"`
+	// TargetLabel defaults to the source label.
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// Replacement can refer to the capture groups of the regex, as in $1. An empty replacement removes the target label.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// EnrichmentWebhook is called with the alerts of each evaluation and returns the labels and annotations to add to them.
+// swagger:model
+type EnrichmentWebhook struct {
+	URL string `json:"url"`
+	// Timeout of the requests, it defaults to 10s.
+	Timeout model.Duration `json:"timeout,omitempty"`
+}
+
+// swagger:model
+type GettableAlertmanagers struct {
+	Status string                 `json:"status"`
+	Data   []ExternalAlertmanager `json:"data"`
+}
+
+// ExternalAlertmanager is the delivery status of an external Alertmanager.
+// swagger:model
+type ExternalAlertmanager struct {
+	URL             string     `json:"url"`
+	Healthy         bool       `json:"healthy"`
+	LastError       string     `json:"lastError,omitempty"`
+	LastHealthCheck *time.Time `json:"lastHealthCheck,omitempty"`
+	LastSent        *time.Time `json:"lastSent,omitempty"`
+	// QueueLength is the number of alerts waiting to be sent to the Alertmanager.
+	QueueLength int `json:"queueLength"`
+}
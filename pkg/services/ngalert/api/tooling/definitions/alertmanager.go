@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -25,6 +29,118 @@ import (
 //       201: Ack
 //       400: ValidationError
 
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/receivers/test alertmanager RoutePostTestReceivers
+//
+// sends a test alert through the integrations of receivers that do not need to be saved,
+// returns 207 if some of the integrations failed
+//
+//     Responses:
+//       200: TestReceiversResult
+//       207: TestReceiversResult
+//       400: ValidationError
+
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/templates/test alertmanager RoutePostTestTemplates
+//
+// renders a template file together with the stored ones against sample alerts or the alerts of a notification attempt
+//
+//     Responses:
+//       200: TestTemplatesResults
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/routes/test alertmanager RoutePostTestRoutes
+//
+// walks the notification policy tree with a label set and returns the matched routes with their effective settings
+//
+//     Responses:
+//       200: TestRoutesResult
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/{Recipient}/config/api/v1/mute-timings alertmanager RouteGetMuteTimeIntervals
+//
+// gets the mute time intervals notification policies can reference
+//
+//     Responses:
+//       200: MuteTimeIntervals
+
+// swagger:route GET /api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName} alertmanager RouteGetMuteTimeInterval
+//
+// gets a mute time interval
+//
+//     Responses:
+//       200: MuteTimeInterval
+//       404: description: Not found.
+
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/mute-timings alertmanager RoutePostMuteTimeInterval
+//
+// creates a mute time interval
+//
+//     Responses:
+//       201: MuteTimeInterval
+//       400: ValidationError
+//       409: description: A mute time interval with this name already exists.
+
+// swagger:route PUT /api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName} alertmanager RoutePutMuteTimeInterval
+//
+// replaces the time intervals of a mute time interval
+//
+//     Responses:
+//       200: MuteTimeInterval
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: The mute time interval is provisioned.
+
+// swagger:route DELETE /api/alertmanager/{Recipient}/config/api/v1/mute-timings/{MuteTimeIntervalName} alertmanager RouteDeleteMuteTimeInterval
+//
+// deletes a mute time interval which is not referenced by notification policies
+//
+//     Responses:
+//       204: description: The mute time interval was deleted.
+//       404: description: Not found.
+//       409: description: The mute time interval is provisioned or referenced by notification policies.
+
+// swagger:route GET /api/alertmanager/{Recipient}/config/api/v1/escalation-policies alertmanager RouteGetEscalationPolicies
+//
+// gets the escalation policies the escalation integrations of the contact points can reference
+//
+//     Responses:
+//       200: EscalationPolicies
+
+// swagger:route GET /api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID} alertmanager RouteGetEscalationPolicy
+//
+// gets an escalation policy
+//
+//     Responses:
+//       200: EscalationPolicy
+//       404: description: Not found.
+
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/escalation-policies alertmanager RoutePostEscalationPolicy
+//
+// creates an escalation policy
+//
+//     Responses:
+//       201: EscalationPolicy
+//       400: ValidationError
+//       409: description: An escalation policy with this UID already exists.
+
+// swagger:route PUT /api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID} alertmanager RoutePutEscalationPolicy
+//
+// replaces the name and the steps of an escalation policy
+//
+//     Responses:
+//       200: EscalationPolicy
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/alertmanager/{Recipient}/config/api/v1/escalation-policies/{EscalationPolicyUID} alertmanager RouteDeleteEscalationPolicy
+//
+// deletes an escalation policy which is not referenced by contact points, together with its escalations
+//
+//     Responses:
+//       204: description: The escalation policy was deleted.
+//       404: description: Not found.
+//       409: description: The escalation policy is referenced by contact points.
+
 // swagger:route GET /api/alertmanager/{Recipient}/config/api/v1/alerts alertmanager RouteGetAlertingConfig
 //
 // gets an Alerting config
@@ -89,6 +205,14 @@ import (
 //       201: GettableSilence
 //       400: ValidationError
 
+// swagger:route POST /api/alertmanager/{Recipient}/api/v2/silences/preview alertmanager RoutePreviewSilence
+//
+// get the firing alerts the matchers of a silence would silence, without creating the silence
+//
+//     Responses:
+//       200: GettableAlerts
+//       400: ValidationError
+
 // swagger:route GET /api/alertmanager/{Recipient}/api/v2/silence/{SilenceId} alertmanager RouteGetSilence
 //
 // get silence
@@ -105,24 +229,206 @@ import (
 //       200: Ack
 //       400: ValidationError
 
-// swagger:parameters RouteCreateSilence
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/notifications alertmanager RouteGetNotificationLog
+//
+// get the attempts to deliver notifications, most recent first
+//
+//     Responses:
+//       200: NotificationLog
+//       400: ValidationError
+
+// swagger:route POST /api/alertmanager/{Recipient}/api/v2/notifications/{NotificationId}/resend alertmanager RouteResendNotification
+//
+// resend the alerts of a notification attempt through the same integration
+//
+//     Responses:
+//       200: Ack
+//       400: ValidationError
+//       404: description: Not found.
+//       502: ValidationError
+
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/escalations alertmanager RouteGetEscalations
+//
+// get the escalations of the alert groups in progress
+//
+//     Responses:
+//       200: Escalations
+
+// swagger:route POST /api/alertmanager/{Recipient}/api/v2/escalations/{EscalationId}/acknowledge alertmanager RouteAcknowledgeEscalation
+//
+// acknowledge an escalation, the contact points of its next steps are not notified
+//
+//     Responses:
+//       200: Ack
+//       404: description: Not found.
+
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification alertmanager RouteGetSilenceExpiryNotification
+//
+// get the notification sent to a contact point before the silence expires
+//
+//     Responses:
+//       200: SilenceExpiryNotification
+//       404: description: Not found.
+
+// swagger:route PUT /api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification alertmanager RoutePutSilenceExpiryNotification
+//
+// notify a contact point before the silence expires, replacing the previous expiry notification of the silence
+//
+//     Responses:
+//       200: SilenceExpiryNotification
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}/expiry-notification alertmanager RouteDeleteSilenceExpiryNotification
+//
+// stop notifying a contact point before the silence expires
+//
+//     Responses:
+//       204: description: The expiry notification was deleted.
+//       404: description: Not found.
+
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/maintenance alertmanager RouteGetMaintenance
+//
+// get the maintenance of the organisation
+//
+//     Responses:
+//       200: Maintenance
+//       404: description: Not found.
+
+// swagger:route PUT /api/alertmanager/{Recipient}/api/v2/maintenance alertmanager RoutePutMaintenance
+//
+// put the organisation in maintenance, replacing its previous maintenance
+//
+//     Responses:
+//       200: Maintenance
+//       400: ValidationError
+
+// swagger:route DELETE /api/alertmanager/{Recipient}/api/v2/maintenance alertmanager RouteDeleteMaintenance
+//
+// end the maintenance of the organisation
+//
+//     Responses:
+//       204: description: The maintenance was deleted.
+//       404: description: Not found.
+
+// swagger:parameters RouteCreateSilence RoutePreviewSilence
 type CreateSilenceParams struct {
 	// in:body
 	Silence PostableSilence
 }
 
-// swagger:parameters RouteGetSilence RouteDeleteSilence
+// swagger:parameters RouteGetSilence RouteDeleteSilence RouteGetSilenceExpiryNotification RoutePutSilenceExpiryNotification RouteDeleteSilenceExpiryNotification
 type GetDeleteSilenceParams struct {
 	// in:path
 	SilenceId string
 }
 
+// swagger:parameters RoutePutSilenceExpiryNotification
+type SilenceExpiryNotificationParams struct {
+	// in:body
+	Body SilenceExpiryNotification
+}
+
+// swagger:parameters RoutePutMaintenance
+type MaintenanceParams struct {
+	// in:body
+	Body Maintenance
+}
+
 // swagger:parameters RouteGetSilences
 type GetSilencesParams struct {
 	// in:query
 	Filter []string `json:"filter"`
 }
 
+// swagger:parameters RouteGetNotificationLog
+type NotificationLogParams struct {
+	// Only return the attempts of this contact point
+	// in:query
+	// required:false
+	Receiver string `json:"receiver"`
+	// Only return the attempts of this integration type, e.g. slack
+	// in:query
+	// required:false
+	Integration string `json:"integration"`
+	// Only return the attempts that failed
+	// in:query
+	// required:false
+	Failed bool `json:"failed"`
+	// Epoch timestamp in milliseconds, only return the attempts at or after this time
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Epoch timestamp in milliseconds, only return the attempts at or before this time
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// Maximum number of attempts to return
+	// in:query
+	// required:false
+	// default:100
+	Limit int64 `json:"limit"`
+}
+
+// swagger:parameters RouteResendNotification
+type ResendNotificationParams struct {
+	// in:path
+	NotificationId int64
+}
+
+// swagger:model
+type NotificationLog struct {
+	Notifications []NotificationAttempt `json:"notifications"`
+}
+
+// NotificationAttempt is a single attempt to deliver a notification through an integration of a contact point.
+// swagger:model
+type NotificationAttempt struct {
+	ID int64 `json:"id"`
+	// Receiver is the name of the contact point
+	Receiver         string `json:"receiver"`
+	IntegrationType  string `json:"integrationType"`
+	IntegrationIndex int    `json:"integrationIndex"`
+	IntegrationUID   string `json:"integrationUid,omitempty"`
+	GroupKey         string `json:"groupKey"`
+	AlertsCount      int    `json:"alertsCount"`
+	Success          bool   `json:"success"`
+	// StatusCode is the HTTP status code of the response if the notification was rejected by the receiving service
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Suppressed is the reason the notification wasn't delivered if it was suppressed, such as "suppressed by maintenance"
+	Suppressed string    `json:"suppressed,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// swagger:parameters RouteAcknowledgeEscalation
+type AcknowledgeEscalationParams struct {
+	// in:path
+	EscalationId int64
+}
+
+// swagger:model
+type Escalations struct {
+	Escalations []Escalation `json:"escalations"`
+}
+
+// Escalation is the escalation of the notifications of an alert group through an escalation policy.
+// swagger:model
+type Escalation struct {
+	ID        int64  `json:"id"`
+	PolicyUID string `json:"policyUid"`
+	GroupKey  string `json:"groupKey"`
+	// Step is the number of the last notified step of the policy, 0 if none was notified yet
+	Step        int `json:"step"`
+	AlertsCount int `json:"alertsCount"`
+	// NextStepAt is when the contact point of the next step is notified unless the escalation is acknowledged
+	NextStepAt     *time.Time `json:"nextStepAt,omitempty"`
+	AcknowledgedBy string     `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	StartedAt      time.Time  `json:"startedAt"`
+}
+
 // swagger:model
 type GettableStatus struct {
 	// cluster
@@ -258,6 +564,200 @@ type PostableAlerts struct {
 	PostableAlerts []amv2.PostableAlert `yaml:"" json:""`
 }
 
+// swagger:parameters RoutePostTestReceivers
+type TestReceiversConfigParams struct {
+	// in:body
+	Body TestReceiversConfigBodyParams
+}
+
+// swagger:model
+type TestReceiversConfigBodyParams struct {
+	// Alert are the labels and annotations added to the ones of the test alert
+	Alert     *TestReceiversConfigAlertParams `json:"alert,omitempty"`
+	Receivers []*PostableApiReceiver          `json:"receivers"`
+}
+
+// swagger:model
+type TestReceiversConfigAlertParams struct {
+	Annotations model.LabelSet `json:"annotations,omitempty"`
+	Labels      model.LabelSet `json:"labels,omitempty"`
+}
+
+// swagger:model
+type TestReceiversResult struct {
+	// Alert are the labels and annotations of the test alert that was sent
+	Alert      TestReceiversConfigAlertParams `json:"alert"`
+	Receivers  []TestReceiverResult           `json:"receivers"`
+	NotifiedAt time.Time                      `json:"notified_at"`
+}
+
+// swagger:model
+type TestReceiverResult struct {
+	Name    string                     `json:"name"`
+	Configs []TestReceiverConfigResult `json:"grafana_managed_receiver_configs"`
+}
+
+// TestReceiverConfigResult is the result of sending the test alert through a single integration.
+// swagger:model
+type TestReceiverConfigResult struct {
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+	// Status is either "ok" or "failed"
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// swagger:parameters RoutePostTestTemplates
+type TestTemplatesConfigParams struct {
+	// in:body
+	Body TestTemplatesConfigBodyParams
+}
+
+// swagger:model
+type TestTemplatesConfigBodyParams struct {
+	// Name is the name of the template file, it replaces the stored template file with the same name
+	Name string `json:"name"`
+	// Template is the content of the template file
+	Template string `json:"template"`
+	// Alerts are the alerts the templates are rendered with, a test alert is used if neither
+	// the alerts nor a notification are given
+	Alerts []amv2.PostableAlert `json:"alerts,omitempty"`
+	// NotificationID is the ID of a notification attempt whose alerts the templates are rendered with
+	NotificationID int64 `json:"notificationId,omitempty"`
+}
+
+// swagger:model
+type TestTemplatesResults struct {
+	Results []TestTemplatesResult      `json:"results"`
+	Errors  []TestTemplatesErrorResult `json:"errors"`
+}
+
+// TestTemplatesResult is the text rendered by a template defined in the tested template file.
+// swagger:model
+type TestTemplatesResult struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// swagger:model
+type TestTemplatesErrorResult struct {
+	// Name is the name of the template that failed to render, it is empty if the template file is invalid
+	Name string `json:"name,omitempty"`
+	// Kind is either "invalid_template" or "execution_error"
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// swagger:parameters RoutePostTestRoutes
+type TestRoutesConfigParams struct {
+	// in:body
+	Body TestRoutesConfigBodyParams
+}
+
+// swagger:model
+type TestRoutesConfigBodyParams struct {
+	// Labels are the labels of the alert that is routed
+	Labels model.LabelSet `json:"labels"`
+}
+
+// swagger:model
+type TestRoutesResult struct {
+	Labels model.LabelSet `json:"labels"`
+	// Routes are the routes the alert is sent through, in the order they are matched
+	Routes []TestRouteResult `json:"routes"`
+}
+
+// TestRouteResult is a matched route with the settings it inherits from its parents.
+// swagger:model
+type TestRouteResult struct {
+	// Key is made of the matchers of the route and of its parents, starting from the root route
+	Key               string         `json:"key"`
+	Receiver          string         `json:"receiver"`
+	GroupBy           []string       `json:"group_by"`
+	GroupWait         model.Duration `json:"group_wait"`
+	GroupInterval     model.Duration `json:"group_interval"`
+	RepeatInterval    model.Duration `json:"repeat_interval"`
+	MuteTimeIntervals []string       `json:"mute_time_intervals"`
+	Continue          bool           `json:"continue"`
+}
+
+// swagger:parameters RouteGetMuteTimeInterval RoutePutMuteTimeInterval RouteDeleteMuteTimeInterval
+type MuteTimeIntervalParams struct {
+	// in:path
+	MuteTimeIntervalName string
+}
+
+// swagger:parameters RoutePostMuteTimeInterval RoutePutMuteTimeInterval
+type MuteTimeIntervalPayload struct {
+	// in:body
+	Body MuteTimeInterval
+}
+
+// swagger:model
+type MuteTimeIntervals []MuteTimeInterval
+
+// swagger:parameters RouteGetEscalationPolicy RoutePutEscalationPolicy RouteDeleteEscalationPolicy
+type EscalationPolicyParams struct {
+	// in:path
+	EscalationPolicyUID string
+}
+
+// swagger:parameters RoutePostEscalationPolicy RoutePutEscalationPolicy
+type EscalationPolicyPayload struct {
+	// in:body
+	Body EscalationPolicy
+}
+
+// EscalationPolicy notifies the contact points of its steps one after the other until the escalation
+// of the notifications of an alert group is acknowledged. The contact points escalate their notifications
+// with an escalation integration referencing the policy.
+// swagger:model
+type EscalationPolicy struct {
+	UID   string           `json:"uid"`
+	Name  string           `json:"name"`
+	Steps []EscalationStep `json:"steps"`
+}
+
+// swagger:model
+type EscalationStep struct {
+	// Receiver is the contact point notified at this step
+	Receiver string `json:"receiver"`
+	// Wait is how long to wait for an acknowledgement before notifying the next step, the last step doesn't need one
+	Wait model.Duration `json:"wait,omitempty"`
+}
+
+// swagger:model
+type EscalationPolicies []EscalationPolicy
+
+// SilenceExpiryNotification notifies a contact point before a silence of the Grafana Alertmanager expires,
+// and again if the silence is extended after the notification.
+// swagger:model
+type SilenceExpiryNotification struct {
+	SilenceID string `json:"silenceId"`
+	// Receiver is the contact point notified
+	Receiver string `json:"receiver"`
+	// NotifyBefore is how long before the end of the silence the contact point is notified
+	NotifyBefore model.Duration `json:"notifyBefore"`
+	// NotifiedAt is the last time the contact point was notified
+	NotifiedAt *time.Time `json:"notifiedAt,omitempty"`
+}
+
+// Maintenance suppresses the notifications of the alerts of the organisation, its rules are still evaluated
+// and the changes of the state of their alerts are still recorded.
+// swagger:model
+type Maintenance struct {
+	// EndsAt is the end of the maintenance, it lasts until it's deleted if it's not set
+	EndsAt *time.Time `json:"endsAt,omitempty"`
+	// Matchers are the label matchers of the alerts the maintenance applies to, such as severity="warning",
+	// it applies to all the alerts of the organisation if there are none
+	Matchers  []string   `json:"matchers,omitempty"`
+	Comment   string     `json:"comment,omitempty"`
+	CreatedBy string     `json:"createdBy,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	// Active is false once the maintenance ended
+	Active bool `json:"active"`
+}
+
 // swagger:parameters RoutePostAlertingConfig
 type BodyAlertingConfig struct {
 	// in:body
@@ -265,7 +765,7 @@ type BodyAlertingConfig struct {
 }
 
 // alertmanager routes
-// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig
+// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RoutePreviewSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RouteGetNotificationLog RouteResendNotification RoutePostTestReceivers RoutePostTestTemplates RoutePostTestRoutes RouteGetMuteTimeIntervals RouteGetMuteTimeInterval RoutePostMuteTimeInterval RoutePutMuteTimeInterval RouteDeleteMuteTimeInterval RouteGetEscalationPolicies RouteGetEscalationPolicy RoutePostEscalationPolicy RoutePutEscalationPolicy RouteDeleteEscalationPolicy RouteGetEscalations RouteAcknowledgeEscalation RouteGetSilenceExpiryNotification RoutePutSilenceExpiryNotification RouteDeleteSilenceExpiryNotification RouteGetMaintenance RoutePutMaintenance RouteDeleteMaintenance
 // ruler routes
 // swagger:parameters RouteGetRulesConfig RoutePostNameRulesConfig RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
 // prom routes
@@ -543,10 +1043,49 @@ func (c *GettableApiAlertingConfig) validate() error {
 
 // Config is the top-level configuration for Alertmanager's config files.
 type Config struct {
-	Global       *config.GlobalConfig  `yaml:"global,omitempty" json:"global,omitempty"`
-	Route        *config.Route         `yaml:"route,omitempty" json:"route,omitempty"`
-	InhibitRules []*config.InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
-	Templates    []string              `yaml:"templates" json:"templates"`
+	Global            *config.GlobalConfig  `yaml:"global,omitempty" json:"global,omitempty"`
+	Route             *config.Route         `yaml:"route,omitempty" json:"route,omitempty"`
+	InhibitRules      []*config.InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
+	MuteTimeIntervals []MuteTimeInterval    `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty"`
+	RateLimits        []RateLimit           `yaml:"rate_limits,omitempty" json:"rate_limits,omitempty"`
+	Templates         []string              `yaml:"templates" json:"templates"`
+}
+
+// MuteTimeInterval is a named set of time intervals for which routes referencing it are muted.
+// swagger:model
+type MuteTimeInterval struct {
+	Name          string                      `yaml:"name" json:"name"`
+	TimeIntervals []timeinterval.TimeInterval `yaml:"time_intervals" json:"time_intervals"`
+}
+
+// RateLimitOverflow is what happens to the notifications over a rate limit.
+type RateLimitOverflow string
+
+const (
+	// RateLimitDrop drops the notifications over the limit, they're sent again by the next notification of
+	// their alert group if their alerts are still firing.
+	RateLimitDrop RateLimitOverflow = "drop"
+	// RateLimitQueue delays the notifications over the limit until the limit allows them, they're dropped
+	// if the notification timeout of their alert group is reached first.
+	RateLimitQueue RateLimitOverflow = "queue"
+)
+
+// RateLimit limits how many notifications are sent to the integrations of a contact point or for the alert
+// groups of a route, to protect the receiving services during alert storms.
+// swagger:model
+type RateLimit struct {
+	Name string `yaml:"name" json:"name"`
+	// Receiver is the contact point whose notifications are limited.
+	Receiver string `yaml:"receiver,omitempty" json:"receiver,omitempty"`
+	// Route is the key of the route whose notifications are limited, the matchers of the routes from the root
+	// route to it, such as {}/{team="ops"}. The notifications of its child routes aren't limited.
+	Route string `yaml:"route,omitempty" json:"route,omitempty"`
+	// MaxNotifications is how many notifications can be sent per interval, each integration of a contact
+	// point sends its own notification.
+	MaxNotifications int            `yaml:"max_notifications" json:"max_notifications"`
+	Interval         model.Duration `yaml:"interval" json:"interval"`
+	// Overflow is either drop, the default, or queue.
+	Overflow RateLimitOverflow `yaml:"overflow,omitempty" json:"overflow,omitempty"`
 }
 
 // Config is the entrypoint for the embedded Alertmanager config with the exception of receivers.
@@ -668,6 +1207,85 @@ func (c *PostableApiAlertingConfig) validate() error {
 		}
 	}
 
+	if err := c.validateMuteTimeIntervals(); err != nil {
+		return err
+	}
+	return c.validateRateLimits(receivers)
+}
+
+// validateMuteTimeIntervals checks that the mute time intervals have unique names
+// and that the routes only reference defined ones.
+// Taken from https://github.com/prometheus/alertmanager/blob/v0.22.2/config/config.go#L464-L477
+func (c *PostableApiAlertingConfig) validateMuteTimeIntervals() error {
+	names := make(map[string]struct{}, len(c.MuteTimeIntervals))
+	for _, mt := range c.MuteTimeIntervals {
+		if mt.Name == "" {
+			return fmt.Errorf("missing name in mute time interval")
+		}
+		if _, ok := names[mt.Name]; ok {
+			return fmt.Errorf("mute time interval %q is not unique", mt.Name)
+		}
+		names[mt.Name] = struct{}{}
+	}
+
+	if c.Route == nil {
+		return nil
+	}
+	if len(c.Route.MuteTimeIntervals) > 0 {
+		return fmt.Errorf("root route must not have any mute time intervals")
+	}
+	for _, name := range AllMuteTimeIntervals(c.Route) {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("undefined mute time interval %q used in route", name)
+		}
+	}
+	return nil
+}
+
+// validateRateLimits checks that the rate limits have unique names, a valid limit and that they
+// limit either a defined contact point or an existing route.
+func (c *PostableApiAlertingConfig) validateRateLimits(receivers map[string]struct{}) error {
+	if len(c.RateLimits) == 0 {
+		return nil
+	}
+
+	routes := make(map[string]struct{})
+	if c.Route != nil {
+		dispatch.NewRoute(c.Route, nil).Walk(func(r *dispatch.Route) {
+			routes[r.Key()] = struct{}{}
+		})
+	}
+
+	names := make(map[string]struct{}, len(c.RateLimits))
+	for _, rl := range c.RateLimits {
+		if rl.Name == "" {
+			return fmt.Errorf("missing name in rate limit")
+		}
+		if _, ok := names[rl.Name]; ok {
+			return fmt.Errorf("rate limit %q is not unique", rl.Name)
+		}
+		names[rl.Name] = struct{}{}
+
+		if rl.MaxNotifications <= 0 || rl.Interval <= 0 {
+			return fmt.Errorf("rate limit %q must allow at least one notification per interval", rl.Name)
+		}
+		if rl.Overflow != "" && rl.Overflow != RateLimitDrop && rl.Overflow != RateLimitQueue {
+			return fmt.Errorf("rate limit %q has an invalid overflow %q, it must be %q or %q", rl.Name, rl.Overflow, RateLimitDrop, RateLimitQueue)
+		}
+
+		switch {
+		case (rl.Receiver == "") == (rl.Route == ""):
+			return fmt.Errorf("rate limit %q must limit either a contact point or a route", rl.Name)
+		case rl.Receiver != "":
+			if _, ok := receivers[rl.Receiver]; !ok {
+				return fmt.Errorf("undefined receiver %q used in rate limit %q", rl.Receiver, rl.Name)
+			}
+		default:
+			if _, ok := routes[rl.Route]; !ok {
+				return fmt.Errorf("undefined route %q used in rate limit %q", rl.Route, rl.Name)
+			}
+		}
+	}
 	return nil
 }
 
@@ -686,6 +1304,20 @@ func (c *PostableApiAlertingConfig) ReceiverType() ReceiverType {
 	return EmptyReceiverType
 }
 
+// AllMuteTimeIntervals will recursively walk a routing tree and return a list of all the
+// referenced mute time interval names.
+func AllMuteTimeIntervals(route *config.Route) (res []string) {
+	if route == nil {
+		return res
+	}
+
+	res = append(res, route.MuteTimeIntervals...)
+	for _, subRoute := range route.Routes {
+		res = append(res, AllMuteTimeIntervals(subRoute)...)
+	}
+	return res
+}
+
 // AllReceivers will recursively walk a routing tree and return a list of all the
 // referenced receiver names.
 func AllReceivers(route *config.Route) (res []string) {
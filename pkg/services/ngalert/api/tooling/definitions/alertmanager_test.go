@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/common/model"
@@ -338,6 +339,260 @@ func Test_ApiAlertingConfig_Marshaling(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			desc: "success graf with mute time intervals",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+					MuteTimeIntervals: []MuteTimeInterval{{Name: "weekends"}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure graf undefined mute time interval",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+					MuteTimeIntervals: []MuteTimeInterval{{Name: "nights"}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf duplicate mute time interval",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+					MuteTimeIntervals: []MuteTimeInterval{{Name: "weekends"}, {Name: "weekends"}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf mute time interval in root route",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver:          "graf",
+						MuteTimeIntervals: []string{"weekends"},
+						Routes: []*config.Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+					MuteTimeIntervals: []MuteTimeInterval{{Name: "weekends"}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "success graf with rate limits",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver: "graf",
+								Match:    map[string]string{"team": "ops"},
+							},
+						},
+					},
+					RateLimits: []RateLimit{
+						{Name: "pager", Receiver: "graf", MaxNotifications: 10, Interval: model.Duration(time.Minute)},
+						{Name: "ops", Route: `{}/{team="ops"}`, MaxNotifications: 1, Interval: model.Duration(time.Minute), Overflow: RateLimitQueue},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure graf rate limit of undefined route",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver: "graf",
+								Match:    map[string]string{"team": "ops"},
+							},
+						},
+					},
+					RateLimits: []RateLimit{{Name: "ops", Route: `{}/{team="dev"}`, MaxNotifications: 1, Interval: model.Duration(time.Minute)}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf rate limit of both a receiver and a route",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver: "graf",
+								Match:    map[string]string{"team": "ops"},
+							},
+						},
+					},
+					RateLimits: []RateLimit{{Name: "ops", Receiver: "graf", Route: `{}/{team="ops"}`, MaxNotifications: 1, Interval: model.Duration(time.Minute)}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf rate limit without notifications",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver: "graf",
+								Match:    map[string]string{"team": "ops"},
+							},
+						},
+					},
+					RateLimits: []RateLimit{{Name: "pager", Receiver: "graf", Interval: model.Duration(time.Minute)}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure graf rate limit with invalid overflow",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &config.Route{
+						Receiver: "graf",
+						Routes: []*config.Route{
+							{
+								Receiver: "graf",
+								Match:    map[string]string{"team": "ops"},
+							},
+						},
+					},
+					RateLimits: []RateLimit{{Name: "pager", Receiver: "graf", MaxNotifications: 1, Interval: model.Duration(time.Minute), Overflow: "block"}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			encoded, err := json.Marshal(tc.input)
@@ -64,6 +64,57 @@ import (
 //     Responses:
 //       202: Ack
 
+// swagger:route POST /api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}/pause ruler RoutePauseRuleGroup
+//
+// Pause the evaluation of all the rules of a rule group, the rules keep their definition and state
+//
+//     Responses:
+//       202: Ack
+
+// swagger:route POST /api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}/resume ruler RouteResumeRuleGroup
+//
+// Resume the evaluation of all the rules of a paused rule group
+//
+//     Responses:
+//       202: Ack
+
+// swagger:route PATCH /api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname} ruler RoutePatchRuleGroupConfig
+//
+// Rename, move to another namespace, reorder the rules and/or change the interval of a rule group
+// in a single transaction, returns 409 if a rule group with the new name already exists in the namespace
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: A rule group with the new name already exists or the rule group is provisioned.
+
+// swagger:route Get /api/ruler/{Recipient}/api/v1/export/{Namespace} ruler RouteGetNamespaceRulesExport
+//
+// Export the rule groups of a namespace as a Prometheus rule file, returns 400 if some of the rules
+// cannot be represented with a PromQL or LogQL expression
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRuleFile
+//       400: ValidationError
+
+// swagger:route POST /api/ruler/{Recipient}/api/v1/import/{Namespace} ruler RoutePostNamespaceRulesImport
+//
+// Import the rule groups of a Prometheus rule file into a namespace, the expressions are queried from the given
+// Prometheus or Loki data source. Existing rule groups with the same name are replaced, the rules keep their UID
+// if they have the same title.
+//
+//     Consumes:
+//     - application/yaml
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
 // swagger:parameters RoutePostNameRulesConfig
 type NamespaceConfig struct {
 	// in:path
@@ -72,13 +123,35 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
-// swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig
+// swagger:parameters RoutePostNamespaceRulesImport
+type NamespaceRulesImport struct {
+	// in:path
+	Namespace string
+	// UID of the Prometheus or Loki data source the expressions of the rules are queried from
+	// in:query
+	// required:true
+	DatasourceUID string `json:"datasourceUid"`
+	// in:body
+	Body PrometheusRuleFile
+}
+
+// swagger:parameters RoutePatchRuleGroupConfig
+type PatchRuleGroupConfigParams struct {
+	// in: path
+	Namespace string
+	// in: path
+	Groupname string
+	// in:body
+	Body PatchableRuleGroupConfig
+}
+
+// swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceRulesExport
 type PathNamespaceConfig struct {
 	// in: path
 	Namespace string
 }
 
-// swagger:parameters RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
+// swagger:parameters RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig RoutePauseRuleGroup RouteResumeRuleGroup
 type PathRouleGroupConfig struct {
 	// in: path
 	Namespace string
@@ -140,6 +213,19 @@ func (c *PostableRuleGroupConfig) validate() error {
 	return nil
 }
 
+// PatchableRuleGroupConfig changes a rule group as a whole, the unset fields are unchanged.
+// swagger:model
+type PatchableRuleGroupConfig struct {
+	// Name renames the rule group
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Namespace moves the rule group to the namespace with this title
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// Interval changes the evaluation interval of all the rules of the group
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// RuleUIDs reorders the rules of the group, it must contain the UID of every rule of the group
+	RuleUIDs []string `yaml:"rule_uids,omitempty" json:"rule_uids,omitempty"`
+}
+
 // swagger:model
 type GettableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
@@ -195,6 +281,19 @@ type ApiRuleNode struct {
 	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
+// PrometheusRuleFile is a rule file in the format of Prometheus, Cortex and Loki.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
 type RuleType int
 
 const (
@@ -279,16 +378,19 @@ func (n *GettableExtendedRuleNode) validate() error {
 type NoDataState string
 
 const (
-	Alerting NoDataState = "Alerting"
-	NoData   NoDataState = "NoData"
-	OK       NoDataState = "OK"
+	Alerting      NoDataState = "Alerting"
+	NoData        NoDataState = "NoData"
+	OK            NoDataState = "OK"
+	KeepLastState NoDataState = "KeepLastState"
 )
 
 // swagger:enum ExecutionErrorState
 type ExecutionErrorState string
 
 const (
-	AlertingErrState ExecutionErrorState = "Alerting"
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
+	OkErrState            ExecutionErrorState = "OK"
 )
 
 // swagger:model
@@ -299,22 +401,42 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// EvaluationTimeout should not be greater than the interval of the rule group, it defaults to 30s
+	// or to the interval of the rule group if it's shorter
+	EvaluationTimeout model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	IsPaused          bool           `json:"is_paused" yaml:"is_paused"`
+	// Record is the metric name the result of the condition is written to, it makes the rule a recording rule
+	Record string `json:"record,omitempty" yaml:"record,omitempty"`
+	// HeartbeatTimeout makes the rule a heartbeat rule, which fires when no heartbeat was received for this long
+	HeartbeatTimeout model.Duration `json:"heartbeat_timeout,omitempty" yaml:"heartbeat_timeout,omitempty"`
+	// CorrelationKey is a template of the labels of the alert instances, such as {{ $labels.cluster }},
+	// the alerts with the same correlation key are notified as a single correlated alert
+	CorrelationKey string `json:"correlation_key,omitempty" yaml:"correlation_key,omitempty"`
+	// MaxAlertInstances is the maximum number of alert instances an evaluation of the rule can produce,
+	// it defaults to max_alert_instances_per_rule of the configuration
+	MaxAlertInstances int64 `json:"max_alert_instances,omitempty" yaml:"max_alert_instances,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ID                int64               `json:"id" yaml:"id"`
+	OrgID             int64               `json:"orgId" yaml:"orgId"`
+	Title             string              `json:"title" yaml:"title"`
+	Condition         string              `json:"condition" yaml:"condition"`
+	Data              []models.AlertQuery `json:"data" yaml:"data"`
+	Updated           time.Time           `json:"updated" yaml:"updated"`
+	IntervalSeconds   int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version           int64               `json:"version" yaml:"version"`
+	UID               string              `json:"uid" yaml:"uid"`
+	NamespaceUID      string              `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID       int64               `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup         string              `json:"rule_group" yaml:"rule_group"`
+	NoDataState       NoDataState         `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState      ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	EvaluationTimeout model.Duration      `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	IsPaused          bool                `json:"is_paused" yaml:"is_paused"`
+	Record            string              `json:"record,omitempty" yaml:"record,omitempty"`
+	HeartbeatTimeout  model.Duration      `json:"heartbeat_timeout,omitempty" yaml:"heartbeat_timeout,omitempty"`
+	CorrelationKey    string              `json:"correlation_key,omitempty" yaml:"correlation_key,omitempty"`
+	MaxAlertInstances int64               `json:"max_alert_instances,omitempty" yaml:"max_alert_instances,omitempty"`
 }
@@ -0,0 +1,24 @@
+package definitions
+
+import "time"
+
+// swagger:route POST /api/v1/rules/{UID}/heartbeat heartbeat RoutePostRuleHeartbeat
+//
+// Send a heartbeat to a heartbeat rule, which fires when it didn't receive a heartbeat for longer than its heartbeat timeout.
+//
+//     Responses:
+//       200: RuleHeartbeat
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:parameters RoutePostRuleHeartbeat
+type RuleHeartbeatParams struct {
+	// in:path
+	UID string
+}
+
+// swagger:model
+type RuleHeartbeat struct {
+	RuleUID    string    `json:"ruleUID"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
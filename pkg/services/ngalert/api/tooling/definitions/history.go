@@ -0,0 +1,199 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/rules/{UID}/history history RouteGetRuleStateHistory
+//
+// Get the state transitions of the instances of an alert rule, most recent first.
+//
+//     Responses:
+//       200: RuleStateHistory
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:parameters RouteGetRuleStateHistory
+type RuleStateHistoryParams struct {
+	// in:path
+	UID string
+	// Only return the transitions into this state
+	// in:query
+	// required:false
+	State string `json:"state"`
+	// Epoch timestamp in milliseconds, only return the transitions at or after this time
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Epoch timestamp in milliseconds, only return the transitions at or before this time
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// Maximum number of transitions to return
+	// in:query
+	// required:false
+	// default:100
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type RuleStateHistory struct {
+	RuleUID     string            `json:"ruleUID"`
+	Transitions []StateTransition `json:"transitions"`
+}
+
+// StateTransition is a single state transition of an alert instance.
+// swagger:model
+type StateTransition struct {
+	Labels        map[string]string `json:"labels"`
+	PreviousState string            `json:"previousState"`
+	State         string            `json:"state"`
+	// Resolved is true if the transition resolved a firing alert
+	Resolved bool `json:"resolved"`
+	// EvaluationString contains the values of the evaluation that caused the transition
+	EvaluationString string    `json:"evaluationString,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// swagger:route GET /api/v1/rules/{UID}/versions history RouteGetRuleVersions
+//
+// Get the versions of an alert rule, most recent first.
+//
+//     Responses:
+//       200: RuleVersions
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/rules/{UID}/versions/{Version} history RouteGetRuleVersion
+//
+// Get a version of an alert rule.
+//
+//     Responses:
+//       200: RuleVersion
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/rules/{UID}/diff history RouteGetRuleVersionsDiff
+//
+// Get the changes of the definition of an alert rule between two of its versions.
+//
+//     Responses:
+//       200: RuleVersionsDiff
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/rules/{UID}/restore history RoutePostRuleVersionRestore
+//
+// Restore the definition of an alert rule to one of its versions. The restore is saved as a new version of the rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersion
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: The rule is provisioned or its restored title conflicts with another rule.
+
+// swagger:parameters RouteGetRuleVersions
+type RuleVersionsParams struct {
+	// in:path
+	UID string
+	// Maximum number of versions to return
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RouteGetRuleVersion
+type RuleVersionParams struct {
+	// in:path
+	UID string
+	// in:path
+	Version int64
+}
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type RuleVersionsDiffParams struct {
+	// in:path
+	UID string
+	// The version the changes are from
+	// in:query
+	// required:true
+	Base int64 `json:"base"`
+	// The version the changes are to
+	// in:query
+	// required:true
+	New int64 `json:"new"`
+}
+
+// swagger:parameters RoutePostRuleVersionRestore
+type RuleVersionRestoreParams struct {
+	// in:path
+	UID string
+	// in:body
+	Body RuleVersionRestore
+}
+
+// swagger:model
+type RuleVersionRestore struct {
+	Version int64 `json:"version"`
+}
+
+// swagger:model
+type RuleVersions struct {
+	RuleUID  string        `json:"ruleUID"`
+	Versions []RuleVersion `json:"versions"`
+}
+
+// RuleVersion is the definition of an alert rule at one of its versions.
+// swagger:model
+type RuleVersion struct {
+	Version       int64 `json:"version"`
+	ParentVersion int64 `json:"parentVersion"`
+	// RestoredFrom is the version this version restored, 0 if the version isn't a restore
+	RestoredFrom             int64               `json:"restoredFrom"`
+	Created                  time.Time           `json:"created"`
+	NamespaceUID             string              `json:"namespaceUID"`
+	RuleGroup                string              `json:"ruleGroup"`
+	Title                    string              `json:"title"`
+	Condition                string              `json:"condition"`
+	Data                     []models.AlertQuery `json:"data"`
+	IntervalSeconds          int64               `json:"intervalSeconds"`
+	NoDataState              NoDataState         `json:"noDataState"`
+	ExecErrState             ExecutionErrorState `json:"execErrState"`
+	EvaluationTimeoutSeconds int64               `json:"evaluationTimeoutSeconds"`
+	Record                   string              `json:"record,omitempty"`
+	HeartbeatTimeoutSeconds  int64               `json:"heartbeatTimeoutSeconds,omitempty"`
+	CorrelationKey           string              `json:"correlationKey,omitempty"`
+	MaxAlertInstances        int64               `json:"maxAlertInstances,omitempty"`
+	For                      model.Duration      `json:"for"`
+	Annotations              map[string]string   `json:"annotations,omitempty"`
+	Labels                   map[string]string   `json:"labels,omitempty"`
+}
+
+// swagger:model
+type RuleVersionsDiff struct {
+	RuleUID string              `json:"ruleUID"`
+	Base    int64               `json:"base"`
+	New     int64               `json:"new"`
+	Changes []RuleVersionChange `json:"changes"`
+}
+
+// RuleVersionChange is the change of a field of the definition of an alert rule between two versions.
+// swagger:model
+type RuleVersionChange struct {
+	// Field is the changed field, the annotations, labels and queries are compared one by one,
+	// for example annotations.summary, labels.severity or data.A
+	Field string `json:"field"`
+	// Base is the value of the field in the base version, absent if the field was added
+	Base interface{} `json:"base,omitempty"`
+	// New is the value of the field in the new version, absent if the field was removed
+	New interface{} `json:"new,omitempty"`
+}
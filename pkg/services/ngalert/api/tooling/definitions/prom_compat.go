@@ -0,0 +1,117 @@
+package definitions
+
+import (
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// swagger:route GET /api/prometheus-compat/api/v1/rules compat RouteGetCompatRules
+//
+// List the Grafana managed rules with their active alerts in the schema of the rules API of Prometheus.
+//
+//     Responses:
+//       200: CompatRulesResponse
+//       400: CompatRulesResponse
+
+// swagger:route GET /api/prometheus-compat/api/v1/alerts compat RouteGetCompatAlerts
+//
+// List the active alerts of the Grafana managed rules in the schema of the alerts API of Prometheus.
+//
+//     Responses:
+//       200: CompatAlertsResponse
+
+// swagger:parameters RouteGetCompatRules
+type CompatRulesParams struct {
+	// Only return the alerting rules (alert) or the recording rules (record).
+	// in:query
+	// required:false
+	Type string `json:"type"`
+	// Only return the rules with one of the names.
+	// in:query
+	// required:false
+	RuleName []string `json:"rule_name[]"`
+	// Only return the rules of one of the rule groups.
+	// in:query
+	// required:false
+	RuleGroup []string `json:"rule_group[]"`
+	// Only return the rules of one of the folders.
+	// in:query
+	// required:false
+	File []string `json:"file[]"`
+}
+
+// swagger:model
+type CompatRulesResponse struct {
+	DiscoveryBase
+	Data CompatRuleDiscovery `json:"data"`
+}
+
+// swagger:model
+type CompatRuleDiscovery struct {
+	RuleGroups []CompatRuleGroup `json:"groups"`
+}
+
+// CompatRuleGroup is a rule group, its file is the title of the folder of its rules.
+// swagger:model
+type CompatRuleGroup struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	// The rules are CompatAlertingRule and CompatRecordingRule, in the order of the rule group.
+	Rules          []interface{} `json:"rules"`
+	Interval       float64       `json:"interval"`
+	EvaluationTime float64       `json:"evaluationTime"`
+	LastEvaluation time.Time     `json:"lastEvaluation"`
+}
+
+// CompatAlertingRule is an alerting rule, its query is the PromQL or LogQL expression of the condition
+// when it has one and the JSON of the queries of the rule otherwise.
+// swagger:model
+type CompatAlertingRule struct {
+	// State can be "pending", "firing", "inactive".
+	State          string         `json:"state"`
+	Name           string         `json:"name"`
+	Query          string         `json:"query"`
+	Duration       float64        `json:"duration"`
+	Labels         labels         `json:"labels"`
+	Annotations    labels         `json:"annotations"`
+	Alerts         []*CompatAlert `json:"alerts"`
+	Health         string         `json:"health"`
+	LastError      string         `json:"lastError,omitempty"`
+	EvaluationTime float64        `json:"evaluationTime"`
+	LastEvaluation time.Time      `json:"lastEvaluation"`
+	Type           v1.RuleType    `json:"type"`
+}
+
+// swagger:model
+type CompatRecordingRule struct {
+	Name           string      `json:"name"`
+	Query          string      `json:"query"`
+	Labels         labels      `json:"labels,omitempty"`
+	Health         string      `json:"health"`
+	LastError      string      `json:"lastError,omitempty"`
+	EvaluationTime float64     `json:"evaluationTime"`
+	LastEvaluation time.Time   `json:"lastEvaluation"`
+	Type           v1.RuleType `json:"type"`
+}
+
+// swagger:model
+type CompatAlertsResponse struct {
+	DiscoveryBase
+	Data CompatAlertDiscovery `json:"data"`
+}
+
+// swagger:model
+type CompatAlertDiscovery struct {
+	Alerts []*CompatAlert `json:"alerts"`
+}
+
+// CompatAlert is a pending or firing alert, its value is the evaluation string of the alert instance.
+// swagger:model
+type CompatAlert struct {
+	Labels      labels     `json:"labels"`
+	Annotations labels     `json:"annotations"`
+	State       string     `json:"state"`
+	ActiveAt    *time.Time `json:"activeAt,omitempty"`
+	Value       string     `json:"value"`
+}
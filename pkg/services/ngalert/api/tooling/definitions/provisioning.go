@@ -0,0 +1,306 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/alert-rules/{UID} provisioning RouteGetAlertRule
+//
+// Get a specific alert rule by UID.
+//
+//     Responses:
+//       200: ProvisionedAlertRule
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/alert-rules provisioning RoutePostAlertRule
+//
+// Create a new alert rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: ProvisionedAlertRule
+//       400: ValidationError
+//       409: description: A rule with the UID exists.
+
+// swagger:route PUT /api/v1/provisioning/alert-rules/{UID} provisioning RoutePutAlertRule
+//
+// Update an existing alert rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedAlertRule
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: The rule is provisioned from another source.
+
+// swagger:route DELETE /api/v1/provisioning/alert-rules/{UID} provisioning RouteDeleteAlertRule
+//
+// Delete a specific alert rule by UID.
+//
+//     Responses:
+//       204: description: The alert rule was deleted successfully.
+//       409: description: The rule is provisioned from another source.
+
+// swagger:route GET /api/v1/provisioning/contact-points provisioning RouteGetContactpoints
+//
+// Get all the contact points.
+//
+//     Responses:
+//       200: ContactPoints
+
+// swagger:route POST /api/v1/provisioning/contact-points provisioning RoutePostContactpoints
+//
+// Create a contact point.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: EmbeddedContactPoint
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/contact-points/{UID} provisioning RoutePutContactpoint
+//
+// Update an existing contact point.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: The contact point is provisioned from another source.
+
+// swagger:route DELETE /api/v1/provisioning/contact-points/{UID} provisioning RouteDeleteContactpoints
+//
+// Delete a contact point.
+//
+//     Responses:
+//       204: description: The contact point was deleted successfully.
+//       409: description: The contact point is provisioned from another source.
+
+// swagger:route GET /api/v1/provisioning/policies provisioning RouteGetPolicyTree
+//
+// Get the notification policy tree.
+//
+//     Responses:
+//       200: NotificationPolicyTree
+
+// swagger:route PUT /api/v1/provisioning/policies provisioning RoutePutPolicyTree
+//
+// Sets the notification policy tree.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       409: description: The policy tree is provisioned from another source.
+
+// swagger:route GET /api/v1/provisioning/mute-timings provisioning RouteGetMuteTimings
+//
+// Get all the mute timings.
+//
+//     Responses:
+//       200: MuteTimings
+
+// swagger:route GET /api/v1/provisioning/mute-timings/{name} provisioning RouteGetMuteTiming
+//
+// Get a mute timing.
+//
+//     Responses:
+//       200: ProvisionedMuteTimeInterval
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/mute-timings provisioning RoutePostMuteTiming
+//
+// Create a new mute timing.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: ProvisionedMuteTimeInterval
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/mute-timings/{name} provisioning RoutePutMuteTiming
+//
+// Replace an existing mute timing.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedMuteTimeInterval
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: The mute timing is provisioned from another source.
+
+// swagger:route DELETE /api/v1/provisioning/mute-timings/{name} provisioning RouteDeleteMuteTiming
+//
+// Delete a mute timing.
+//
+//     Responses:
+//       204: description: The mute timing was deleted successfully.
+//       409: description: The mute timing is provisioned from another source.
+
+// swagger:route GET /api/v1/provisioning/export provisioning RouteGetProvisioningExport
+//
+// Export all provisionable resources as JSON or YAML (format=yaml).
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: ProvisioningExport
+
+// swagger:parameters RouteGetAlertRule RoutePutAlertRule RouteDeleteAlertRule RoutePutContactpoint RouteDeleteContactpoints
+type ProvisioningUIDParam struct {
+	// in:path
+	UID string
+}
+
+// swagger:parameters RouteGetMuteTiming RoutePutMuteTiming RouteDeleteMuteTiming
+type ProvisioningNameParam struct {
+	// in:path
+	Name string `json:"name"`
+}
+
+// swagger:parameters RoutePostAlertRule RoutePutAlertRule
+type ProvisionedAlertRulePayload struct {
+	// in:body
+	Body ProvisionedAlertRule
+}
+
+// swagger:parameters RoutePostContactpoints RoutePutContactpoint
+type ContactPointPayload struct {
+	// in:body
+	Body EmbeddedContactPoint
+}
+
+// swagger:parameters RoutePutPolicyTree
+type PolicyTreePayload struct {
+	// in:body
+	Body NotificationPolicyTree
+}
+
+// swagger:parameters RoutePostMuteTiming RoutePutMuteTiming
+type MuteTimingPayload struct {
+	// in:body
+	Body ProvisionedMuteTimeInterval
+}
+
+// swagger:model
+type ProvisionedAlertRule struct {
+	ID    int64  `json:"id"`
+	UID   string `json:"uid"`
+	OrgID int64  `json:"orgID"`
+	// FolderUID is the UID of the folder (namespace) of the rule
+	FolderUID string              `json:"folderUID"`
+	RuleGroup string              `json:"ruleGroup"`
+	Title     string              `json:"title"`
+	Condition string              `json:"condition"`
+	Data      []models.AlertQuery `json:"data"`
+	// IntervalSeconds defaults to the interval of the rule group
+	IntervalSeconds int64                      `json:"intervalSeconds,omitempty"`
+	Updated         time.Time                  `json:"updated,omitempty"`
+	NoDataState     models.NoDataState         `json:"noDataState"`
+	ExecErrState    models.ExecutionErrorState `json:"execErrState"`
+	// EvaluationTimeout should not be greater than the interval of the rule
+	EvaluationTimeout model.Duration    `json:"evaluationTimeout,omitempty"`
+	For               model.Duration    `json:"for"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	IsPaused          bool              `json:"isPaused"`
+	// Record is the metric name the result of the condition is written to, it makes the rule a recording rule
+	Record string `json:"record,omitempty"`
+	// HeartbeatTimeout makes the rule a heartbeat rule, which fires when no heartbeat was received for this long
+	HeartbeatTimeout model.Duration `json:"heartbeatTimeout,omitempty"`
+	// CorrelationKey is a template of the labels of the alert instances, such as {{ $labels.cluster }},
+	// the alerts with the same correlation key are notified as a single correlated alert
+	CorrelationKey string `json:"correlationKey,omitempty"`
+	// MaxAlertInstances is the maximum number of alert instances an evaluation of the rule can produce,
+	// it defaults to max_alert_instances_per_rule of the configuration
+	MaxAlertInstances int64             `json:"maxAlertInstances,omitempty"`
+	Provenance        models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type ContactPoints []EmbeddedContactPoint
+
+// EmbeddedContactPoint is a single Grafana managed integration of a receiver, the receiver is identified by the name.
+// swagger:model
+type EmbeddedContactPoint struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Settings are the contact point settings without the secure settings
+	Settings              *simplejson.Json `json:"settings"`
+	DisableResolveMessage bool             `json:"disableResolveMessage"`
+	// SecureSettings are only written, existing secure settings are kept unless replaced
+	SecureSettings map[string]string `json:"secureSettings,omitempty"`
+	// SecureFields lists the secure settings which are set
+	SecureFields map[string]bool   `json:"secureFields,omitempty"`
+	Provenance   models.Provenance `json:"provenance,omitempty"`
+}
+
+func (e *EmbeddedContactPoint) ResourceType() string {
+	return "contactPoint"
+}
+
+func (e *EmbeddedContactPoint) ResourceID() string {
+	return e.UID
+}
+
+// swagger:model
+type NotificationPolicyTree struct {
+	Route      *config.Route     `json:"route"`
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+func (t *NotificationPolicyTree) ResourceType() string {
+	return "notificationPolicy"
+}
+
+func (t *NotificationPolicyTree) ResourceID() string {
+	// there is a single policy tree for an organization
+	return "policies"
+}
+
+// swagger:model
+type MuteTimings []ProvisionedMuteTimeInterval
+
+// swagger:model
+type ProvisionedMuteTimeInterval struct {
+	MuteTimeInterval `yaml:",inline"`
+	Provenance       models.Provenance `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+}
+
+func (m *ProvisionedMuteTimeInterval) ResourceType() string {
+	return "muteTimeInterval"
+}
+
+func (m *ProvisionedMuteTimeInterval) ResourceID() string {
+	return m.Name
+}
+
+// swagger:model
+type ProvisioningExport struct {
+	AlertRules    []ProvisionedAlertRule  `json:"alertRules"`
+	ContactPoints ContactPoints           `json:"contactPoints"`
+	Policies      *NotificationPolicyTree `json:"policies,omitempty"`
+	MuteTimings   MuteTimings             `json:"muteTimings"`
+}
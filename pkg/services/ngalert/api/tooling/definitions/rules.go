@@ -0,0 +1,35 @@
+package definitions
+
+// swagger:route GET /api/v1/rules rules RouteGetRulesByDatasource
+//
+// List the Grafana managed alert rules which query a data source, with their folders.
+//
+//     Responses:
+//       200: DatasourceRules
+//       400: ValidationError
+
+// swagger:parameters RouteGetRulesByDatasource
+type DatasourceRulesParams struct {
+	// UID of the data source queried by the rules
+	// in:query
+	// required:true
+	DatasourceUID string `json:"datasourceUID"`
+}
+
+// DatasourceRules are the alert rules which query a data source. The rules of the folders
+// the user can't read are not listed.
+// swagger:model
+type DatasourceRules struct {
+	DatasourceUID string           `json:"datasourceUID"`
+	Rules         []DatasourceRule `json:"rules"`
+}
+
+// swagger:model
+type DatasourceRule struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	FolderUID   string `json:"folderUID"`
+	FolderTitle string `json:"folderTitle"`
+	RuleGroup   string `json:"ruleGroup"`
+	IsPaused    bool   `json:"isPaused"`
+}
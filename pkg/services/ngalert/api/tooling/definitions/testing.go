@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -40,6 +41,20 @@ import (
 //     Responses:
 //       200: TestRuleResponse
 
+// swagger:route Post /api/v1/rule/test testing RoutePreviewRule
+//
+// Preview the alert instances of a Grafana managed rule without saving it
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: PreviewRuleResponse
+//       400: ValidationError
+
 // swagger:route Post /api/v1/eval testing RouteEvalQueries
 //
 // Test rule
@@ -73,6 +88,54 @@ type TestRulePayload struct {
 	GrafanaManagedCondition *models.EvalAlertConditionCommand `json:"grafana_condition,omitempty"`
 }
 
+// swagger:parameters RoutePreviewRule
+type PreviewRuleRequest struct {
+	// in:body
+	Body PreviewRulePayload
+}
+
+// swagger:model
+type PreviewRulePayload struct {
+	Title     string              `json:"title"`
+	Condition string              `json:"condition"`
+	Data      []models.AlertQuery `json:"data"`
+	// For is evaluated by evaluating the rule every interval over the last For duration
+	For model.Duration `json:"for"`
+	// IntervalSeconds is the evaluation interval of the rule, defaults to 60 seconds
+	IntervalSeconds int64                      `json:"intervalSeconds"`
+	NoDataState     models.NoDataState         `json:"noDataState"`
+	ExecErrState    models.ExecutionErrorState `json:"execErrState"`
+	Labels          map[string]string          `json:"labels,omitempty"`
+	Annotations     map[string]string          `json:"annotations,omitempty"`
+	// Now is the time of the last evaluation, defaults to the current time
+	Now time.Time `json:"now"`
+}
+
+// swagger:model
+type PreviewRuleResponse struct {
+	Instances []PreviewAlertInstance `json:"instances"`
+}
+
+// PreviewAlertInstance is an alert instance as it would be after the last evaluation of the rule.
+// swagger:model
+type PreviewAlertInstance struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	State       string            `json:"state"`
+	// StartsAt is when the instance entered its current state
+	StartsAt    time.Time           `json:"startsAt"`
+	Error       string              `json:"error,omitempty"`
+	Evaluations []PreviewEvaluation `json:"evaluations"`
+}
+
+// swagger:model
+type PreviewEvaluation struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"`
+	// EvaluationString contains the values of the evaluation
+	EvaluationString string `json:"evaluationString,omitempty"`
+}
+
 // swagger:parameters RouteEvalQueries
 type EvalQueriesRequest struct {
 	// in:body
@@ -106,6 +169,35 @@ func (p *TestRulePayload) validate() error {
 	return nil
 }
 
+func (p *PreviewRulePayload) UnmarshalJSON(b []byte) error {
+	type plain PreviewRulePayload
+	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
+		return err
+	}
+
+	return p.validate()
+}
+
+func (p *PreviewRulePayload) validate() error {
+	if p.Condition == "" {
+		return fmt.Errorf("missing condition")
+	}
+
+	if len(p.Data) == 0 {
+		return fmt.Errorf("missing data")
+	}
+
+	if p.IntervalSeconds < 0 {
+		return fmt.Errorf("invalid interval: %d", p.IntervalSeconds)
+	}
+
+	if p.For < 0 {
+		return fmt.Errorf("invalid for duration: %s", p.For)
+	}
+
+	return nil
+}
+
 func (p *TestRulePayload) Type() (backend Backend) {
 	if p.Expr != "" {
 		return LoTexRulerBackend
@@ -67,3 +67,41 @@ func TestRulePayloadMarshaling(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewRulePayloadUnmarshaling(t *testing.T) {
+	for _, tc := range []struct {
+		desc  string
+		input string
+		err   bool
+	}{
+		{
+			desc:  "success",
+			input: `{"condition": "B", "data": [{"refId": "A", "model": {}}], "for": "5m", "intervalSeconds": 60}`,
+		},
+		{
+			desc:  "failure missing condition",
+			input: `{"data": [{"refId": "A", "model": {}}]}`,
+			err:   true,
+		},
+		{
+			desc:  "failure missing data",
+			input: `{"condition": "B"}`,
+			err:   true,
+		},
+		{
+			desc:  "failure negative interval",
+			input: `{"condition": "B", "data": [{"refId": "A", "model": {}}], "intervalSeconds": -1}`,
+			err:   true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var out PreviewRulePayload
+			err := json.Unmarshal([]byte(tc.input), &out)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
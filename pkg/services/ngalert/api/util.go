@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
@@ -214,15 +215,45 @@ func validateQueriesAndExpressions(data []ngmodels.AlertQuery, user *models.Sign
 			continue
 		}
 
-		_, err = datasourceCache.GetDatasourceByUID(datasourceUID, user, skipCache)
+		ds, err := datasourceCache.GetDatasourceByUID(datasourceUID, user, skipCache)
 		if err != nil {
 			return nil, fmt.Errorf("invalid query %s: %w: %s", query.RefID, err, datasourceUID)
 		}
+		if err := checkDatasourceQueryAccess(user, ds); err != nil {
+			return nil, fmt.Errorf("invalid query %s: %w: %s", query.RefID, err, datasourceUID)
+		}
 		refIDs[query.RefID] = struct{}{}
 	}
 	return refIDs, nil
 }
 
+// checkDatasourceQueryAccess returns models.ErrDataSourceAccessDenied if the user is not allowed to query the data source.
+func checkDatasourceQueryAccess(user *models.SignedInUser, ds *models.DataSource) error {
+	q := models.DatasourcesPermissionFilterQuery{
+		User:        user,
+		Datasources: []*models.DataSource{ds},
+	}
+	if err := bus.Dispatch(&q); err != nil {
+		if errors.Is(err, bus.ErrHandlerNotFound) {
+			return nil
+		}
+		return err
+	}
+	if len(q.Result) == 0 {
+		return models.ErrDataSourceAccessDenied
+	}
+	return nil
+}
+
+// validationErrorStatus returns the status of the response to a request with an invalid rule:
+// forbidden if the user is not allowed to query one of its data sources, bad request otherwise.
+func validationErrorStatus(err error) int {
+	if errors.Is(err, models.ErrDataSourceAccessDenied) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
 func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand, datasourceCache datasources.CacheService, dataService *tsdb.Service, cfg *setting.Cfg, log log.Logger) response.Response {
 	evalCond := ngmodels.Condition{
 		Condition: cmd.Condition,
@@ -230,7 +261,7 @@ func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand,
 		Data:      cmd.Data,
 	}
 	if err := validateCondition(evalCond, c.SignedInUser, c.SkipCache, datasourceCache); err != nil {
-		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+		return ErrResp(validationErrorStatus(err), err, "invalid condition")
 	}
 
 	now := cmd.Now
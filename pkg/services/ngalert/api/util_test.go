@@ -1,9 +1,15 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
 )
 
 func TestToMacaronPath(t *testing.T) {
@@ -25,3 +31,32 @@ func TestToMacaronPath(t *testing.T) {
 		assert.Equal(t, tc.expectedOutputPath, outputPath)
 	}
 }
+
+func TestCheckDatasourceQueryAccess(t *testing.T) {
+	user := &models.SignedInUser{UserId: 1, OrgId: 1}
+	allowed := &models.DataSource{Uid: "allowed"}
+	denied := &models.DataSource{Uid: "denied"}
+
+	t.Run("access is allowed without data source permissions", func(t *testing.T) {
+		bus.ClearBusHandlers()
+		require.NoError(t, checkDatasourceQueryAccess(user, denied))
+	})
+
+	t.Run("access is given by the data source permissions", func(t *testing.T) {
+		bus.ClearBusHandlers()
+		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandler("test", func(query *models.DatasourcesPermissionFilterQuery) error {
+			for _, ds := range query.Datasources {
+				if ds.Uid == allowed.Uid {
+					query.Result = append(query.Result, ds)
+				}
+			}
+			return nil
+		})
+
+		require.NoError(t, checkDatasourceQueryAccess(user, allowed))
+		err := checkDatasourceQueryAccess(user, denied)
+		require.ErrorIs(t, err, models.ErrDataSourceAccessDenied)
+		require.Equal(t, http.StatusForbidden, validationErrorStatus(fmt.Errorf("invalid query A: %w", err)))
+	})
+}
@@ -0,0 +1,216 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	prommodels "github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// defaultTimeout is the timeout of the webhook requests unless the webhook has another timeout.
+	defaultTimeout = 10 * time.Second
+
+	// internalLabelPrefix is the prefix of the labels used by Grafana itself, they aren't enriched.
+	internalLabelPrefix = "__"
+)
+
+// Enricher enriches the labels and annotations of the alerts of an organisation: the static labels and
+// annotations of the organisation and of the folder of the rule are added first, then the webhook is called
+// and finally the labels are rewritten.
+type Enricher struct {
+	orgID    int64
+	cfg      *ngmodels.EnrichmentConfig
+	rewrites []rewrite
+	client   *http.Client
+}
+
+type rewrite struct {
+	ngmodels.LabelRewrite
+	regex *regexp.Regexp
+}
+
+// New returns the enricher of the configuration, which must be valid.
+func New(orgID int64, cfg *ngmodels.EnrichmentConfig) (*Enricher, error) {
+	e := &Enricher{orgID: orgID, cfg: cfg}
+	for _, r := range cfg.Rewrites {
+		regex, err := regexp.Compile("^(?:" + r.Regex + ")$")
+		if err != nil {
+			return nil, err
+		}
+		e.rewrites = append(e.rewrites, rewrite{LabelRewrite: r, regex: regex})
+	}
+	if cfg.Webhook != nil {
+		timeout := cfg.Webhook.Timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		e.client = &http.Client{Timeout: timeout}
+	}
+	return e, nil
+}
+
+// Enrich returns the enriched alerts of the rule. If the webhook fails the alerts are still enriched with
+// the static labels and the rewrites, and the error is returned alongside them.
+func (e *Enricher) Enrich(ctx context.Context, rule *ngmodels.AlertRule, alerts apimodels.PostableAlerts) (apimodels.PostableAlerts, error) {
+	enriched := apimodels.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(alerts.PostableAlerts))}
+	for _, a := range alerts.PostableAlerts {
+		labels := make(models.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			labels[k] = v
+		}
+		annotations := make(models.LabelSet, len(a.Annotations))
+		for k, v := range a.Annotations {
+			annotations[k] = v
+		}
+
+		if f, ok := e.cfg.Folders[rule.NamespaceUID]; ok {
+			addMissing(labels, f.Labels)
+			addMissing(annotations, f.Annotations)
+		}
+		addMissing(labels, e.cfg.Labels)
+		addMissing(annotations, e.cfg.Annotations)
+
+		a.Labels = labels
+		a.Annotations = annotations
+		enriched.PostableAlerts = append(enriched.PostableAlerts, a)
+	}
+
+	var err error
+	if e.client != nil {
+		err = e.callWebhook(ctx, rule, enriched.PostableAlerts)
+	}
+
+	for i := range enriched.PostableAlerts {
+		e.rewrite(enriched.PostableAlerts[i].Labels)
+	}
+	return enriched, err
+}
+
+// addMissing adds the values to the set unless it has them already.
+func addMissing(set models.LabelSet, values map[string]string) {
+	for k, v := range values {
+		if _, ok := set[k]; !ok {
+			set[k] = v
+		}
+	}
+}
+
+// rewrite applies the rewrites to the labels, the internal labels are left untouched.
+func (e *Enricher) rewrite(labels models.LabelSet) {
+	for _, r := range e.rewrites {
+		switch r.Action {
+		case ngmodels.LabelRewriteReplace:
+			value, ok := labels[r.SourceLabel]
+			if !ok {
+				continue
+			}
+			match := r.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			target := r.TargetLabel
+			if target == "" {
+				target = r.SourceLabel
+			}
+			if strings.HasPrefix(target, internalLabelPrefix) {
+				continue
+			}
+			replacement := string(r.regex.ExpandString(nil, r.Replacement, value, match))
+			if replacement == "" {
+				delete(labels, target)
+				continue
+			}
+			labels[target] = replacement
+		case ngmodels.LabelRewriteDrop:
+			for name := range labels {
+				if !strings.HasPrefix(name, internalLabelPrefix) && r.regex.MatchString(name) {
+					delete(labels, name)
+				}
+			}
+		}
+	}
+}
+
+// WebhookRequest is the body of the requests to the enrichment webhook.
+type WebhookRequest struct {
+	OrgID     int64          `json:"orgId"`
+	RuleUID   string         `json:"ruleUID"`
+	FolderUID string         `json:"folderUID"`
+	Alerts    []WebhookAlert `json:"alerts"`
+}
+
+// WebhookResponse is the body of the responses of the enrichment webhook, it has the labels and annotations
+// to add to the alerts of the request, in the same order.
+type WebhookResponse struct {
+	Alerts []WebhookAlert `json:"alerts"`
+}
+
+// WebhookAlert is the labels and annotations of an alert sent to or returned by the enrichment webhook.
+type WebhookAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// callWebhook sends the alerts to the webhook and sets the labels and annotations it returns, except the
+// internal labels and the invalid label names. The alerts are left unchanged if it fails.
+func (e *Enricher) callWebhook(ctx context.Context, rule *ngmodels.AlertRule, alerts []models.PostableAlert) error {
+	body := WebhookRequest{OrgID: e.orgID, RuleUID: rule.UID, FolderUID: rule.NamespaceUID, Alerts: make([]WebhookAlert, 0, len(alerts))}
+	for _, a := range alerts {
+		body.Alerts = append(body.Alerts, WebhookAlert{Labels: a.Labels, Annotations: a.Annotations})
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.Webhook.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bad response status %s", resp.Status)
+	}
+
+	var enriched WebhookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&enriched); err != nil {
+		return fmt.Errorf("failed to decode the response: %w", err)
+	}
+	if len(enriched.Alerts) != len(alerts) {
+		return fmt.Errorf("the response has %d alerts instead of %d", len(enriched.Alerts), len(alerts))
+	}
+
+	for i, a := range enriched.Alerts {
+		for k, v := range a.Labels {
+			if !strings.HasPrefix(k, internalLabelPrefix) && prommodels.LabelName(k).IsValid() {
+				alerts[i].Labels[k] = v
+			}
+		}
+		for k, v := range a.Annotations {
+			alerts[i].Annotations[k] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func postableAlerts(labels ...models.LabelSet) apimodels.PostableAlerts {
+	alerts := apimodels.PostableAlerts{}
+	for _, l := range labels {
+		alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
+			Alert:       models.Alert{Labels: l},
+			Annotations: models.LabelSet{"summary": "test"},
+		})
+	}
+	return alerts
+}
+
+func TestEnrich(t *testing.T) {
+	rule := &ngmodels.AlertRule{UID: "rule", NamespaceUID: "folder"}
+
+	t.Run("static labels don't override the labels of the alerts and the folder takes precedence", func(t *testing.T) {
+		e, err := New(1, &ngmodels.EnrichmentConfig{
+			Labels:      map[string]string{"team": "ops", "env": "prod", "severity": "low"},
+			Annotations: map[string]string{"runbook": "org", "summary": "org"},
+			Folders: map[string]ngmodels.FolderEnrichment{
+				"folder": {Labels: map[string]string{"team": "db"}, Annotations: map[string]string{"runbook": "folder"}},
+				"other":  {Labels: map[string]string{"team": "web"}},
+			},
+		})
+		require.NoError(t, err)
+
+		original := postableAlerts(models.LabelSet{"alertname": "test", "severity": "high"})
+		alerts, err := e.Enrich(context.Background(), rule, original)
+		require.NoError(t, err)
+		require.Len(t, alerts.PostableAlerts, 1)
+		require.Equal(t, models.LabelSet{"alertname": "test", "severity": "high", "team": "db", "env": "prod"}, alerts.PostableAlerts[0].Labels)
+		require.Equal(t, models.LabelSet{"summary": "test", "runbook": "folder"}, alerts.PostableAlerts[0].Annotations)
+		// the alerts are copied
+		require.Equal(t, models.LabelSet{"alertname": "test", "severity": "high"}, original.PostableAlerts[0].Labels)
+	})
+
+	t.Run("rewrites replace and drop the labels except the internal ones", func(t *testing.T) {
+		e, err := New(1, &ngmodels.EnrichmentConfig{
+			Labels: map[string]string{"instance": "host-1:9100"},
+			Rewrites: []ngmodels.LabelRewrite{
+				{Action: ngmodels.LabelRewriteReplace, SourceLabel: "instance", Regex: "(.*):\\d+", TargetLabel: "host", Replacement: "$1"},
+				{Action: ngmodels.LabelRewriteReplace, SourceLabel: "severity", Regex: "crit|critical", Replacement: "critical"},
+				{Action: ngmodels.LabelRewriteReplace, SourceLabel: "severity", Regex: "none", Replacement: ""},
+				{Action: ngmodels.LabelRewriteDrop, Regex: "instance|pod|__.*"},
+			},
+		})
+		require.NoError(t, err)
+
+		alerts, err := e.Enrich(context.Background(), rule, postableAlerts(
+			models.LabelSet{"severity": "crit", "pod": "pod-1", "__alert_rule_uid__": "rule"},
+			models.LabelSet{"severity": "none"},
+		))
+		require.NoError(t, err)
+		require.Equal(t, models.LabelSet{"host": "host-1", "severity": "critical", "__alert_rule_uid__": "rule"}, alerts.PostableAlerts[0].Labels)
+		require.Equal(t, models.LabelSet{"host": "host-1"}, alerts.PostableAlerts[1].Labels)
+	})
+
+	t.Run("webhook enriches the alerts before the rewrites", func(t *testing.T) {
+		var received WebhookRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			resp := WebhookResponse{Alerts: []WebhookAlert{{
+				Labels:      map[string]string{"owner": "Alice", "__internal__": "ignored"},
+				Annotations: map[string]string{"dashboard": "http://dashboard"},
+			}}}
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		}))
+		t.Cleanup(server.Close)
+
+		e, err := New(1, &ngmodels.EnrichmentConfig{
+			Labels:   map[string]string{"team": "ops"},
+			Rewrites: []ngmodels.LabelRewrite{{Action: ngmodels.LabelRewriteReplace, SourceLabel: "owner", Regex: "Alice", Replacement: "alice"}},
+			Webhook:  &ngmodels.EnrichmentWebhook{URL: server.URL},
+		})
+		require.NoError(t, err)
+
+		alerts, err := e.Enrich(context.Background(), rule, postableAlerts(models.LabelSet{"alertname": "test"}))
+		require.NoError(t, err)
+		require.Equal(t, WebhookRequest{
+			OrgID:     1,
+			RuleUID:   "rule",
+			FolderUID: "folder",
+			Alerts: []WebhookAlert{{
+				Labels:      map[string]string{"alertname": "test", "team": "ops"},
+				Annotations: map[string]string{"summary": "test"},
+			}},
+		}, received)
+		require.Equal(t, models.LabelSet{"alertname": "test", "team": "ops", "owner": "alice"}, alerts.PostableAlerts[0].Labels)
+		require.Equal(t, models.LabelSet{"summary": "test", "dashboard": "http://dashboard"}, alerts.PostableAlerts[0].Annotations)
+	})
+
+	t.Run("failed webhook returns an error with the alerts enriched without it", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		e, err := New(1, &ngmodels.EnrichmentConfig{
+			Labels:  map[string]string{"team": "ops"},
+			Webhook: &ngmodels.EnrichmentWebhook{URL: server.URL},
+		})
+		require.NoError(t, err)
+
+		alerts, err := e.Enrich(context.Background(), rule, postableAlerts(models.LabelSet{"alertname": "test"}))
+		require.Error(t, err)
+		require.Equal(t, models.LabelSet{"alertname": "test", "team": "ops"}, alerts.PostableAlerts[0].Labels)
+	})
+}
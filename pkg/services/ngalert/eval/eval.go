@@ -21,7 +21,8 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 )
 
-const alertingEvaluationTimeout = 30 * time.Second
+// DefaultEvaluationTimeout is how long the evaluation of a condition can take unless another timeout is given.
+const DefaultEvaluationTimeout = 30 * time.Second
 
 type Evaluator struct {
 	Cfg *setting.Cfg
@@ -178,6 +179,8 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 	}
 
 	for refID, res := range execResp.Responses {
+		logQueryStats(ctx, refID, res.Frames)
+
 		// for each frame within each response, the response can contain several data types including time-series data.
 		// For now, we favour simplicity and only care about single scalar values.
 		for _, frame := range res.Frames {
@@ -224,6 +227,24 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 	return result
 }
 
+// logQueryStats logs the executed query and the stats of the query of a data source, if reported in the frames.
+func logQueryStats(ctx AlertExecCtx, refID string, frames data.Frames) {
+	if ctx.Log == nil {
+		return
+	}
+	for _, frame := range frames {
+		if frame.Meta == nil || (frame.Meta.ExecutedQueryString == "" && len(frame.Meta.Stats) == 0) {
+			continue
+		}
+		args := []interface{}{"refID", refID, "executedQuery", frame.Meta.ExecutedQueryString}
+		for _, stat := range frame.Meta.Stats {
+			args = append(args, stat.DisplayName, stat.Value)
+		}
+		ctx.Log.Debug("Query stats", args...)
+		return
+	}
+}
+
 func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (resp *backend.QueryDataResponse, err error) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -423,7 +444,17 @@ func (evalResults Results) AsDataFrame() data.Frame {
 
 // ConditionEval executes conditions and evaluates the result.
 func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+	return e.ConditionEvalWithTimeout(condition, now, 0, nil, dataService)
+}
+
+// ConditionEvalWithTimeout is like ConditionEval but the execution of the conditions is cancelled after the timeout,
+// the result is then an error. A timeout of 0 is the default timeout. The previous states of the alert instances,
+// if any, are used by the threshold expressions.
+func (e *Evaluator) ConditionEvalWithTimeout(condition *models.Condition, now time.Time, timeout time.Duration, previous expr.PreviousStates, dataService *tsdb.Service) (Results, error) {
+	if timeout <= 0 {
+		timeout = DefaultEvaluationTimeout
+	}
+	alertCtx, cancelFn := context.WithTimeout(expr.WithPreviousStates(context.Background(), previous), timeout)
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
@@ -434,9 +465,27 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 	return evalResults, nil
 }
 
+// RecordingEvalWithTimeout executes the condition of a recording rule and returns the frames of the condition,
+// which are written as samples instead of being evaluated to alert states. A timeout of 0 is the default timeout.
+func (e *Evaluator) RecordingEvalWithTimeout(condition *models.Condition, now time.Time, timeout time.Duration, dataService *tsdb.Service) (data.Frames, error) {
+	if timeout <= 0 {
+		timeout = DefaultEvaluationTimeout
+	}
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), timeout)
+	defer cancelFn()
+
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+
+	execResult := executeCondition(alertExecCtx, condition, now, dataService)
+	if execResult.Error != nil {
+		return nil, execResult.Error
+	}
+	return execResult.Results, nil
+}
+
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
 func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), DefaultEvaluationTimeout)
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
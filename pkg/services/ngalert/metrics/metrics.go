@@ -29,13 +29,41 @@ type Metrics struct {
 	*metrics.Alerts
 	AlertState *prometheus.GaugeVec
 	// Registerer is for use by subcomponents which register their own metrics.
-	Registerer           prometheus.Registerer
-	RequestDuration      *prometheus.HistogramVec
-	ActiveConfigurations prometheus.Gauge
-	EvalTotal            *prometheus.CounterVec
-	EvalFailures         *prometheus.CounterVec
-	EvalDuration         *prometheus.SummaryVec
-	GroupRules           *prometheus.GaugeVec
+	Registerer            prometheus.Registerer
+	RequestDuration       *prometheus.HistogramVec
+	ActiveConfigurations  prometheus.Gauge
+	EvalTotal             *prometheus.CounterVec
+	EvalFailures          *prometheus.CounterVec
+	EvalDuration          *prometheus.SummaryVec
+	GroupRules            *prometheus.GaugeVec
+	InstanceLimitExceeded *prometheus.CounterVec
+	InstanceQuotaReached  *prometheus.CounterVec
+
+	RecordingSamplesWritten *prometheus.CounterVec
+	RecordingWriteFailures  *prometheus.CounterVec
+
+	EnrichmentWebhookFailures *prometheus.CounterVec
+
+	MaintenanceSuppressedAlerts *prometheus.CounterVec
+	RateLimitedNotifications    *prometheus.CounterVec
+
+	ExternalAlertmanagerAlertsSent    *prometheus.CounterVec
+	ExternalAlertmanagerErrors        *prometheus.CounterVec
+	ExternalAlertmanagerAlertsDropped *prometheus.CounterVec
+	ExternalAlertmanagerLatency       *prometheus.HistogramVec
+	ExternalAlertmanagerQueueLength   *prometheus.GaugeVec
+	ExternalAlertmanagerUp            *prometheus.GaugeVec
+
+	SchedulerShardInstances    prometheus.Gauge
+	SchedulerShardRebalances   prometheus.Counter
+	SchedulerShardRuleGroups   *prometheus.GaugeVec
+	SchedulerShardEvalTotal    *prometheus.CounterVec
+	SchedulerShardEvalFailures *prometheus.CounterVec
+
+	StatePersistPending       prometheus.Gauge
+	StatePersistWrites        prometheus.Counter
+	StatePersistFailures      prometheus.Counter
+	StatePersistSnapshotBytes prometheus.Gauge
 }
 
 func init() {
@@ -122,6 +150,187 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			},
 			[]string{"user"},
 		),
+		InstanceLimitExceeded: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluation_instance_limit_exceeded_total",
+				Help:      "The total number of rule evaluations that produced more alert instances than the limit.",
+			},
+			[]string{"user"},
+		),
+		InstanceQuotaReached: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluation_instance_quota_reached_total",
+				Help:      "The total number of rule evaluations whose new alert instances were dropped because the alert instance quota was reached.",
+			},
+			[]string{"org"},
+		),
+		RecordingSamplesWritten: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "recording_rule_samples_written_total",
+				Help:      "The total number of samples written by the recording rules.",
+			},
+			[]string{"user"},
+		),
+		RecordingWriteFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "recording_rule_write_failures_total",
+				Help:      "The total number of failures writing the samples of the recording rules.",
+			},
+			[]string{"user"},
+		),
+		EnrichmentWebhookFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "enrichment_webhook_failures_total",
+				Help:      "The total number of failures enriching the alerts with the enrichment webhook.",
+			},
+			[]string{"org"},
+		),
+		MaintenanceSuppressedAlerts: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "maintenance_suppressed_alerts_total",
+				Help:      "The total number of alerts whose notification to a contact point was suppressed by the maintenance of their organisation.",
+			},
+			[]string{"org"},
+		),
+		RateLimitedNotifications: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rate_limited_notifications_total",
+				Help:      "The total number of notifications over a rate limit of the Grafana Alertmanager, by whether they were queued or dropped.",
+			},
+			[]string{"rate_limit", "action"},
+		),
+		ExternalAlertmanagerAlertsSent: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_alerts_sent_total",
+				Help:      "The total number of alerts sent to an external Alertmanager.",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalAlertmanagerErrors: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_errors_total",
+				Help:      "The total number of errors sending alerts to an external Alertmanager.",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalAlertmanagerAlertsDropped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_alerts_dropped_total",
+				Help:      "The total number of alerts dropped because the queue of an external Alertmanager was full.",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalAlertmanagerLatency: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_latency_seconds",
+				Help:      "Histogram of the latency of sending alerts to an external Alertmanager.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalAlertmanagerQueueLength: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_queue_length",
+				Help:      "The number of alerts waiting to be sent to an external Alertmanager.",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalAlertmanagerUp: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_up",
+				Help:      "Whether the last health check of an external Alertmanager succeeded.",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		SchedulerShardInstances: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "scheduler_shard_instances",
+			Help:      "The number of instances the rule groups are sharded across.",
+		}),
+		SchedulerShardRebalances: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "scheduler_shard_rebalances_total",
+			Help:      "The total number of times the rule groups were rebalanced because an instance joined or left.",
+		}),
+		SchedulerShardRuleGroups: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "scheduler_shard_rule_groups",
+				Help:      "The number of rule groups evaluated by the shard.",
+			},
+			[]string{"shard"},
+		),
+		SchedulerShardEvalTotal: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "scheduler_shard_rule_evaluations_total",
+				Help:      "The total number of rule evaluations of the shard.",
+			},
+			[]string{"shard"},
+		),
+		SchedulerShardEvalFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "scheduler_shard_rule_evaluation_failures_total",
+				Help:      "The total number of rule evaluation failures of the shard.",
+			},
+			[]string{"shard"},
+		),
+		StatePersistPending: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "state_persist_pending",
+			Help:      "The number of alert instances waiting to be written to the database.",
+		}),
+		StatePersistWrites: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "state_persist_instances_written_total",
+			Help:      "The total number of alert instances written to the database.",
+		}),
+		StatePersistFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "state_persist_failures_total",
+			Help:      "The total number of failures writing the alert instances or their snapshots to the database.",
+		}),
+		StatePersistSnapshotBytes: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "state_snapshot_bytes",
+			Help:      "The compressed size of the last snapshots of the alert instances.",
+		}),
 	}
 }
 
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AlertmanagersChoice represents which Alertmanagers receive the alerts of an organisation.
+type AlertmanagersChoice string
+
+const (
+	// InternalAlertmanager sends the alerts only to the Grafana Alertmanager.
+	InternalAlertmanager AlertmanagersChoice = "internal"
+	// ExternalAlertmanagers sends the alerts only to the configured external Alertmanagers.
+	ExternalAlertmanagers AlertmanagersChoice = "external"
+	// AllAlertmanagers sends the alerts to both the Grafana Alertmanager and the external Alertmanagers.
+	AllAlertmanagers AlertmanagersChoice = "both"
+)
+
+// IsValid checks that the value is a known choice.
+func (c AlertmanagersChoice) IsValid() bool {
+	return c == InternalAlertmanager || c == ExternalAlertmanagers || c == AllAlertmanagers
+}
+
+// AdminConfiguration represents the ngalert administration configuration of an organisation.
+type AdminConfiguration struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+
+	// Alertmanagers are the URLs of the external Alertmanagers the alerts are sent to.
+	Alertmanagers []string
+	// SendAlertsTo controls which Alertmanagers receive the alerts.
+	SendAlertsTo AlertmanagersChoice
+	// Enrichment of the labels and annotations of the alerts before they are sent, none if it's nil.
+	Enrichment *EnrichmentConfig `xorm:"json"`
+
+	CreatedAt int64 `xorm:"created"`
+	UpdatedAt int64 `xorm:"updated"`
+}
+
+func (ac AdminConfiguration) TableName() string {
+	return "ngalert_configuration"
+}
+
+// AsSHA256 returns a SHA256 hash of the external Alertmanagers and the choice,
+// it's used to find out if the configuration of a sender has changed.
+func (ac *AdminConfiguration) AsSHA256() string {
+	h := sha256.New()
+	_, _ = h.Write([]byte(fmt.Sprintf("%v;%s", ac.Alertmanagers, ac.SendAlertsTo)))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// Validate checks that the choice is known, that the external Alertmanagers are valid URLs
+// that at least one of them is configured when the alerts are sent to external Alertmanagers and that the
+// enrichment is valid.
+func (ac *AdminConfiguration) Validate() error {
+	if !ac.SendAlertsTo.IsValid() {
+		return fmt.Errorf("invalid alertmanagers choice '%s', must be one of '%s', '%s' or '%s'", ac.SendAlertsTo, InternalAlertmanager, ExternalAlertmanagers, AllAlertmanagers)
+	}
+
+	if ac.SendAlertsTo != InternalAlertmanager && len(ac.Alertmanagers) == 0 {
+		return errors.New("at least one external alertmanager is required to send alerts to external alertmanagers")
+	}
+
+	seen := make(map[string]struct{}, len(ac.Alertmanagers))
+	for _, am := range ac.Alertmanagers {
+		u, err := url.Parse(am)
+		if err != nil {
+			return fmt.Errorf("invalid alertmanager url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid alertmanager url '%s': scheme must be http or https", u.Redacted())
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid alertmanager url '%s': host is missing", u.Redacted())
+		}
+		key := strings.TrimSuffix(am, "/")
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("duplicate alertmanager url '%s'", u.Redacted())
+		}
+		seen[key] = struct{}{}
+	}
+
+	if ac.Enrichment != nil {
+		if err := ac.Enrichment.Validate(); err != nil {
+			return fmt.Errorf("invalid enrichment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetOrgAdminConfiguration is the query for retrieving the administration configuration of an organisation.
+type GetOrgAdminConfiguration struct {
+	OrgID int64
+
+	Result *AdminConfiguration
+}
+
+// UpdateAdminConfigurationCmd is the command for creating or updating the administration configuration of an organisation.
+type UpdateAdminConfigurationCmd struct {
+	AdminConfiguration *AdminConfiguration
+}
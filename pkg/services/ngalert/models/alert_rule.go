@@ -19,6 +19,10 @@ var (
 	ErrAlertRuleFailedValidation = errors.New("invalid alert rule")
 	// ErrAlertRuleUniqueConstraintViolation
 	ErrAlertRuleUniqueConstraintViolation = errors.New("a conflicting alert rule is found: rule title under the same organisation and folder should be unique")
+	// ErrRuleGroupAlreadyExists is an error for a rule group renamed or moved to the name of an existing rule group.
+	ErrRuleGroupAlreadyExists = errors.New("a rule group with the same name already exists in the namespace")
+	// ErrAlertRuleVersionNotFound is an error for an unknown version of an alert rule.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
 )
 
 type NoDataState string
@@ -28,11 +32,21 @@ func (noDataState NoDataState) String() string {
 }
 
 const (
-	Alerting NoDataState = "Alerting"
-	NoData   NoDataState = "NoData"
-	OK       NoDataState = "OK"
+	Alerting      NoDataState = "Alerting"
+	NoData        NoDataState = "NoData"
+	OK            NoDataState = "OK"
+	KeepLastState NoDataState = "KeepLastState"
 )
 
+// IsValid returns true if the no data state is known.
+func (noDataState NoDataState) IsValid() bool {
+	switch noDataState {
+	case Alerting, NoData, OK, KeepLastState:
+		return true
+	}
+	return false
+}
+
 type ExecutionErrorState string
 
 func (executionErrorState ExecutionErrorState) String() string {
@@ -40,12 +54,37 @@ func (executionErrorState ExecutionErrorState) String() string {
 }
 
 const (
-	AlertingErrState ExecutionErrorState = "Alerting"
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
+	OkErrState            ExecutionErrorState = "OK"
 )
 
+// IsValid returns true if the execution error state is known.
+func (executionErrorState ExecutionErrorState) IsValid() bool {
+	switch executionErrorState {
+	case AlertingErrState, KeepLastStateErrState, OkErrState:
+		return true
+	}
+	return false
+}
+
 const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
+	// InstanceLimitExceededLabel is the label of the alert instance that fires when an evaluation of a rule
+	// produces more alert instances than the limit.
+	InstanceLimitExceededLabel = "grafana_alert_instance_limit_exceeded"
+
+	// DashboardUIDAnnotation and PanelIDAnnotation are the annotations of the rules linked to a dashboard panel.
+	DashboardUIDAnnotation = "__dashboardUid__"
+	PanelIDAnnotation      = "__panelId__"
+	// OrgIDAnnotation is the annotation of the alerts with the organisation of their rule, the panel of
+	// the rules linked to a dashboard panel is rendered for it and its maintenance applies to them.
+	OrgIDAnnotation = "__orgId__"
+
+	// CorrelationKeyLabel is the label of the alert instances of the rules with a correlation key
+	// with their expanded correlation key.
+	CorrelationKeyLabel = "__correlation_key__"
 )
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -63,11 +102,41 @@ type AlertRule struct {
 	RuleGroup       string
 	NoDataState     NoDataState
 	ExecErrState    ExecutionErrorState
+	// EvaluationTimeoutSeconds is how long the evaluation of the rule can take before it fails,
+	// 0 is the default timeout capped at the interval
+	EvaluationTimeoutSeconds int64
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// IsPaused rules keep their definition and state but are not evaluated
+	IsPaused bool
+	// Record is the metric name the result of the condition is written to, it makes the rule a recording rule
+	// that has no alert instances and sends no notifications
+	Record string
+	// HeartbeatTimeoutSeconds makes the rule a heartbeat rule when it's set: the rule fires when no heartbeat
+	// was received for this long. The heartbeats are sent to the API, or are the evaluations of the condition
+	// of the rule which return data if the rule has queries.
+	HeartbeatTimeoutSeconds int64
+	// CorrelationKey is the template of the correlation key of the alert instances, the alerts with the same
+	// correlation key are notified as a single correlated alert, even if they come from different rules.
+	CorrelationKey string
+	// MaxAlertInstances is the maximum number of alert instances an evaluation of the rule can produce,
+	// 0 is the limit of the configuration
+	MaxAlertInstances int64
+	// RuleGroupIndex is the position of the rule in its rule group, starting at 1
+	RuleGroupIndex int64 `xorm:"rule_group_idx"`
+}
+
+// IsRecording returns true if the rule is a recording rule.
+func (alertRule *AlertRule) IsRecording() bool {
+	return alertRule.Record != ""
+}
+
+// IsHeartbeat returns true if the rule is a heartbeat rule.
+func (alertRule *AlertRule) IsHeartbeat() bool {
+	return alertRule.HeartbeatTimeoutSeconds > 0
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -109,13 +178,18 @@ type AlertRuleVersion struct {
 	RestoredFrom     int64
 	Version          int64
 
-	Created         time.Time
-	Title           string
-	Condition       string
-	Data            []AlertQuery
-	IntervalSeconds int64
-	NoDataState     NoDataState
-	ExecErrState    ExecutionErrorState
+	Created                  time.Time
+	Title                    string
+	Condition                string
+	Data                     []AlertQuery
+	IntervalSeconds          int64
+	NoDataState              NoDataState
+	ExecErrState             ExecutionErrorState
+	EvaluationTimeoutSeconds int64
+	Record                   string
+	HeartbeatTimeoutSeconds  int64
+	CorrelationKey           string
+	MaxAlertInstances        int64
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For         time.Duration
@@ -123,6 +197,35 @@ type AlertRuleVersion struct {
 	Labels      map[string]string
 }
 
+// ListAlertRuleVersionsQuery is the query for listing the versions of an alert rule, most recent first.
+type ListAlertRuleVersionsQuery struct {
+	OrgID   int64
+	RuleUID string
+	// Limit is the maximum number of versions to return, 0 returns all of them
+	Limit int
+
+	Result []*AlertRuleVersion
+}
+
+// GetAlertRuleVersionQuery is the query for retrieving a version of an alert rule.
+type GetAlertRuleVersionQuery struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
+
+	Result *AlertRuleVersion
+}
+
+// RestoreAlertRuleVersionCommand is the command for restoring the definition of an alert rule to one of its versions.
+// The restore is saved as a new version of the rule, the rule stays in its rule group.
+type RestoreAlertRuleVersionCommand struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
+
+	Result *AlertRule
+}
+
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
 type GetAlertRuleByUIDQuery struct {
 	UID   string
@@ -157,6 +260,36 @@ type ListRuleGroupAlertRulesQuery struct {
 	Result []*AlertRule
 }
 
+// SetRuleGroupPausedCommand is the command for pausing or resuming all the rules of a rule group.
+type SetRuleGroupPausedCommand struct {
+	OrgID        int64
+	NamespaceUID string
+	RuleGroup    string
+	IsPaused     bool
+
+	// Result are the UIDs of the updated rules
+	Result []string
+}
+
+// PatchRuleGroupCommand is the command for renaming, moving, reordering or changing the interval
+// of all the rules of a rule group at once.
+type PatchRuleGroupCommand struct {
+	OrgID        int64
+	NamespaceUID string
+	RuleGroup    string
+	// NewNamespaceUID moves the rule group to another namespace if it's set
+	NewNamespaceUID string
+	// NewRuleGroup renames the rule group if it's set
+	NewRuleGroup string
+	// IntervalSeconds changes the interval of the rules if it's greater than 0
+	IntervalSeconds int64
+	// RuleUIDs reorders the rules of the group if it's set, it must contain the UID of every rule of the group
+	RuleUIDs []string
+
+	// Result are the updated rules
+	Result []*AlertRule
+}
+
 // ListOrgRuleGroupsQuery is the query for listing unique rule groups
 type ListOrgRuleGroupsQuery struct {
 	OrgID int64
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// LabelRewriteAction is the action of a label rewrite rule.
+type LabelRewriteAction string
+
+const (
+	// LabelRewriteReplace sets the target label to the replacement when the source label matches the regex.
+	LabelRewriteReplace LabelRewriteAction = "replace"
+	// LabelRewriteDrop removes the labels whose name matches the regex.
+	LabelRewriteDrop LabelRewriteAction = "drop"
+)
+
+// EnrichmentConfig is the enrichment of the labels and annotations of the alerts of an organisation,
+// applied between their evaluation and the Alertmanagers.
+type EnrichmentConfig struct {
+	// Labels and Annotations are added to all the alerts of the organisation, unless the alerts have them already.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Folders are the labels and annotations added to the alerts of the rules of a folder, by folder UID.
+	// They take precedence over the ones of the organisation.
+	Folders map[string]FolderEnrichment `json:"folders,omitempty"`
+	// Rewrites are applied in order to the labels, after the static labels and the webhook.
+	Rewrites []LabelRewrite `json:"rewrites,omitempty"`
+	// Webhook enriches the alerts with an external service, it's not called if it's nil.
+	Webhook *EnrichmentWebhook `json:"webhook,omitempty"`
+}
+
+// FolderEnrichment is the labels and annotations added to the alerts of the rules of a folder.
+type FolderEnrichment struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// LabelRewrite is a rule rewriting the labels of the alerts, similar to a Prometheus relabel config.
+type LabelRewrite struct {
+	Action LabelRewriteAction `json:"action"`
+	// SourceLabel is the label matched by the regex of a replace rule.
+	SourceLabel string `json:"sourceLabel,omitempty"`
+	// Regex must match the whole value of the source label of a replace rule, or the whole name of
+	// the labels removed by a drop rule.
+	Regex string `json:"regex"`
+	// TargetLabel is set to the replacement by a replace rule, it's the source label if it's empty.
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// Replacement can refer to the capture groups of the regex, as in $1. The target label is removed
+	// if the replacement is empty.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// EnrichmentWebhook is an external service enriching the alerts.
+type EnrichmentWebhook struct {
+	URL string `json:"url"`
+	// Timeout of the requests to the webhook, the default timeout is used if it's 0.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// Validate checks that the labels are valid, that the rewrites have a known action and a valid regex
+// and that the webhook has a valid URL.
+func (c *EnrichmentConfig) Validate() error {
+	if err := validateEnrichmentLabels(c.Labels); err != nil {
+		return err
+	}
+	for uid, f := range c.Folders {
+		if uid == "" {
+			return fmt.Errorf("folder UID of the enrichment is required")
+		}
+		if err := validateEnrichmentLabels(f.Labels); err != nil {
+			return fmt.Errorf("folder %s: %w", uid, err)
+		}
+	}
+
+	for i, r := range c.Rewrites {
+		if _, err := regexp.Compile("^(?:" + r.Regex + ")$"); err != nil {
+			return fmt.Errorf("rewrite %d: invalid regex: %w", i+1, err)
+		}
+		switch r.Action {
+		case LabelRewriteReplace:
+			if !model.LabelName(r.SourceLabel).IsValid() {
+				return fmt.Errorf("rewrite %d: invalid source label '%s'", i+1, r.SourceLabel)
+			}
+			if r.TargetLabel != "" && !model.LabelName(r.TargetLabel).IsValid() {
+				return fmt.Errorf("rewrite %d: invalid target label '%s'", i+1, r.TargetLabel)
+			}
+		case LabelRewriteDrop:
+			if r.Regex == "" {
+				return fmt.Errorf("rewrite %d: regex is required to drop labels", i+1)
+			}
+		default:
+			return fmt.Errorf("rewrite %d: invalid action '%s', must be one of '%s' or '%s'", i+1, r.Action, LabelRewriteReplace, LabelRewriteDrop)
+		}
+	}
+
+	if c.Webhook != nil {
+		u, err := url.Parse(c.Webhook.URL)
+		if err != nil {
+			return fmt.Errorf("invalid enrichment webhook url: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid enrichment webhook url '%s': must be an http or https url", u.Redacted())
+		}
+		if c.Webhook.Timeout < 0 {
+			return fmt.Errorf("enrichment webhook timeout must not be negative")
+		}
+	}
+	return nil
+}
+
+func validateEnrichmentLabels(labels map[string]string) error {
+	for name := range labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name '%s'", name)
+		}
+	}
+	return nil
+}
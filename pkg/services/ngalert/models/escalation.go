@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+var (
+	// ErrEscalationPolicyNotFound is an error for an unknown escalation policy.
+	ErrEscalationPolicyNotFound = errors.New("could not find escalation policy")
+	// ErrEscalationPolicyExists is an error for an escalation policy with a UID which is already used.
+	ErrEscalationPolicyExists = errors.New("escalation policy with this UID already exists")
+	// ErrEscalationPolicyFailedGenerateUniqueUID is an error for failure to generate an escalation policy UID.
+	ErrEscalationPolicyFailedGenerateUniqueUID = errors.New("failed to generate escalation policy UID")
+	// ErrEscalationNotFound is an error for an unknown escalation.
+	ErrEscalationNotFound = errors.New("could not find escalation")
+)
+
+// EscalationPolicy notifies the contact points of its steps one after the other,
+// until the escalation of the notification is acknowledged.
+type EscalationPolicy struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	UID   string `xorm:"uid"`
+	Name  string
+	Steps []EscalationStep
+	// Updated is the Unix time of the last change of the policy.
+	Updated int64
+}
+
+func (p EscalationPolicy) TableName() string {
+	return "alert_escalation_policy"
+}
+
+// EscalationStep is a step of an escalation policy: the contact point is notified, then the escalation
+// moves to the next step once Wait elapsed unless it's acknowledged.
+type EscalationStep struct {
+	Receiver string
+	Wait     time.Duration
+}
+
+// Validate returns an error if the escalation policy is invalid.
+func (p EscalationPolicy) Validate() error {
+	if p.Name == "" {
+		return errors.New("escalation policy name is required")
+	}
+	if len(p.Steps) == 0 {
+		return errors.New("escalation policy must have at least one step")
+	}
+	for i, s := range p.Steps {
+		if s.Receiver == "" {
+			return fmt.Errorf("step %d of the escalation policy has no contact point", i+1)
+		}
+		if s.Wait < 0 {
+			return fmt.Errorf("step %d of the escalation policy has a negative wait", i+1)
+		}
+		if s.Wait == 0 && i < len(p.Steps)-1 {
+			return fmt.Errorf("step %d of the escalation policy must wait before the next step", i+1)
+		}
+	}
+	return nil
+}
+
+// Escalation is the escalation of the notifications of an alert group through an escalation policy.
+type Escalation struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	PolicyUID string `xorm:"policy_uid"`
+	GroupKey  string
+	// Alerts are the alerts of the last notification of the group, they are sent to the contact points of the next steps.
+	Alerts []model.Alert
+	// Step is the index of the last notified step, -1 if none was notified yet.
+	Step int
+	// NextStepAt is the Unix time the escalation moves to its next step, 0 if it doesn't.
+	NextStepAt int64
+	// Resolved is true once the alerts of the group are resolved, the notified contact points
+	// are then told and the escalation is removed.
+	Resolved       bool
+	AcknowledgedBy string
+	// AcknowledgedAt is the Unix time the escalation was acknowledged at, 0 if it's not acknowledged.
+	AcknowledgedAt int64
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+func (e Escalation) TableName() string {
+	return "alert_escalation"
+}
+
+// Acknowledged returns true if the escalation was acknowledged.
+func (e Escalation) Acknowledged() bool {
+	return e.AcknowledgedAt != 0
+}
+
+// GetEscalationPolicyQuery is the query for retrieving an escalation policy by its UID.
+type GetEscalationPolicyQuery struct {
+	UID string
+
+	Result *EscalationPolicy
+}
+
+// GetEscalationQuery is the query for retrieving an escalation by its ID, or by the UID of its policy
+// and its group key if the ID isn't set.
+type GetEscalationQuery struct {
+	ID        int64
+	PolicyUID string
+	GroupKey  string
+
+	Result *Escalation
+}
+
+// ListEscalationsQuery is the query for listing the escalations.
+type ListEscalationsQuery struct {
+	// DueBefore only returns the escalations which have a step to process at or before this time.
+	DueBefore time.Time
+
+	Result []*Escalation
+}
+
+// AcknowledgeEscalationCommand is the command for acknowledging an escalation, stopping it at its current step.
+type AcknowledgeEscalationCommand struct {
+	ID    int64
+	Login string
+	At    time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// AlertRuleHeartbeat is the last heartbeat received by a heartbeat rule.
+type AlertRuleHeartbeat struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	OrgID      int64  `xorm:"org_id"`
+	RuleUID    string `xorm:"rule_uid"`
+	ReceivedAt time.Time
+}
+
+func (h AlertRuleHeartbeat) TableName() string {
+	return "alert_rule_heartbeat"
+}
+
+// SaveAlertRuleHeartbeatCommand is the command for saving the last heartbeat of a heartbeat rule.
+type SaveAlertRuleHeartbeatCommand struct {
+	OrgID      int64
+	RuleUID    string
+	ReceivedAt time.Time
+}
+
+// GetAlertRuleHeartbeatQuery is the query for retrieving the last heartbeat of a heartbeat rule.
+// The result is nil if the rule never received a heartbeat.
+type GetAlertRuleHeartbeatQuery struct {
+	OrgID   int64
+	RuleUID string
+
+	Result *AlertRuleHeartbeat
+}
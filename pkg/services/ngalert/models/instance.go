@@ -54,6 +54,44 @@ type SaveAlertInstanceCommand struct {
 	CurrentStateEnd   time.Time
 }
 
+// SaveAlertInstancesCommand is the command for saving several alert instances at once.
+type SaveAlertInstancesCommand struct {
+	Instances []SaveAlertInstanceCommand
+}
+
+// AlertInstanceSnapshot is a compressed snapshot of all the alert instances of an organisation.
+type AlertInstanceSnapshot struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// Data is the gzip compressed JSON of the alert instances.
+	Data []byte
+	// Created is the Unix time the snapshot was taken.
+	Created int64
+}
+
+func (s AlertInstanceSnapshot) TableName() string {
+	return "alert_instance_snapshot"
+}
+
+// SaveAlertInstanceSnapshotCommand is the command for replacing the snapshot of the alert instances of an organisation.
+type SaveAlertInstanceSnapshotCommand struct {
+	OrgID     int64
+	Instances []SaveAlertInstanceCommand
+	Created   time.Time
+
+	// Result is the compressed size of the snapshot.
+	Result int
+}
+
+// GetAlertInstanceSnapshotQuery is the query for retrieving the snapshot of the alert instances of an organisation.
+// The result is empty if the organisation has no snapshot.
+type GetAlertInstanceSnapshotQuery struct {
+	OrgID int64
+
+	Result  []*ListAlertInstancesQueryResult
+	Created time.Time
+}
+
 // GetAlertInstanceQuery is the query for retrieving/deleting an alert definition by ID.
 // nolint:unused
 type GetAlertInstanceQuery struct {
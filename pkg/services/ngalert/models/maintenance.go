@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	// ErrMaintenanceNotFound is an error for an organisation which isn't in maintenance.
+	ErrMaintenanceNotFound = errors.New("could not find maintenance")
+)
+
+// NotificationSuppressedByMaintenance is the reason recorded in the notification log for the notifications
+// which weren't delivered because of a maintenance.
+const NotificationSuppressedByMaintenance = "suppressed by maintenance"
+
+// Maintenance suppresses the notifications of the alerts of an organisation, the rules are still evaluated
+// and the changes of the state of their alerts are still recorded.
+type Maintenance struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// EndsAt is the Unix time the maintenance ends at, 0 if it lasts until it's disabled.
+	EndsAt int64
+	// Matchers are the label matchers of the alerts the maintenance applies to, such as severity="warning".
+	// The maintenance applies to all the alerts of the organisation if there are none.
+	Matchers  []string
+	Comment   string
+	CreatedBy string
+	CreatedAt int64
+}
+
+func (m Maintenance) TableName() string {
+	return "alert_maintenance"
+}
+
+// Validate returns an error if the maintenance is invalid.
+func (m Maintenance) Validate() error {
+	if m.EndsAt < 0 {
+		return errors.New("end of the maintenance must not be negative")
+	}
+	if _, err := m.LabelMatchers(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LabelMatchers returns the parsed matchers of the maintenance.
+func (m Maintenance) LabelMatchers() (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(m.Matchers))
+	for _, s := range m.Matchers {
+		matcher, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q of the maintenance: %w", s, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// IsActive returns true if the maintenance didn't end at the time.
+func (m Maintenance) IsActive(now time.Time) bool {
+	return m.EndsAt == 0 || now.Unix() < m.EndsAt
+}
+
+// Matches returns true if the maintenance is active at the time and applies to the alert with the labels.
+func (m Maintenance) Matches(now time.Time, lset model.LabelSet) bool {
+	if !m.IsActive(now) {
+		return false
+	}
+	matchers, err := m.LabelMatchers()
+	if err != nil {
+		return false
+	}
+	return matchers.Matches(lset)
+}
+
+// GetMaintenanceQuery is the query for retrieving the maintenance of an organisation.
+type GetMaintenanceQuery struct {
+	OrgID int64
+
+	Result *Maintenance
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// NotificationAttempt represents a single attempt to deliver a notification through an integration of a contact point.
+type NotificationAttempt struct {
+	ID int64 `xorm:"pk autoincr 'id'"`
+	// Receiver is the name of the contact point.
+	Receiver         string
+	IntegrationType  string
+	IntegrationIndex int
+	IntegrationUID   string `xorm:"integration_uid"`
+	GroupKey         string
+	// Alerts are the alerts of the notification, they are used to resend it.
+	Alerts []model.Alert
+	// StatusCode is the HTTP status code of the response if the notification was rejected by the receiving service.
+	StatusCode int
+	Error      string
+	// Suppressed is the reason the notification wasn't delivered if it was suppressed, the integration wasn't called then.
+	Suppressed string
+	DurationMs int64
+	CreatedAt  int64
+}
+
+func (a NotificationAttempt) TableName() string {
+	return "alert_notification_log"
+}
+
+// Succeeded returns true if the notification was delivered.
+func (a NotificationAttempt) Succeeded() bool {
+	return a.Error == "" && a.Suppressed == ""
+}
+
+// GetNotificationAttemptQuery is the query for retrieving a notification attempt by its ID.
+type GetNotificationAttemptQuery struct {
+	ID int64
+
+	Result *NotificationAttempt
+}
+
+// ListNotificationAttemptsQuery is the query for listing the notification attempts, the most recent attempts are returned first.
+type ListNotificationAttemptsQuery struct {
+	Receiver        string
+	IntegrationType string
+	// OnlyFailed only returns the attempts that failed to deliver the notification.
+	OnlyFailed bool
+	From       time.Time
+	To         time.Time
+	Limit      int64
+
+	Result []*NotificationAttempt
+}
@@ -0,0 +1,43 @@
+package models
+
+import "errors"
+
+// Provenance is the origin of a provisioned resource.
+type Provenance string
+
+const (
+	// ProvenanceNone is the provenance of resources created and managed in the UI.
+	ProvenanceNone Provenance = ""
+	// ProvenanceAPI is the provenance of resources created through the provisioning API.
+	ProvenanceAPI Provenance = "api"
+	// ProvenanceFile is the provenance of resources provisioned from files.
+	ProvenanceFile Provenance = "file"
+)
+
+var (
+	// ErrProvenanceChangeNotAllowed is an error for changes of provisioned resources from other sources.
+	ErrProvenanceChangeNotAllowed = errors.New("resource is provisioned and cannot be changed from this source")
+)
+
+// CanUpdate reports whether a resource of this provenance can be changed from a source of the given provenance.
+// Resources created in the UI can be taken over by provisioning, but provisioned resources
+// can only be changed by the source they were provisioned from.
+func (p Provenance) CanUpdate(source Provenance) bool {
+	return p == ProvenanceNone || p == source
+}
+
+// Provisionable represents a resource that can be provisioned.
+type Provisionable interface {
+	ResourceType() string
+	ResourceID() string
+}
+
+// ResourceType returns the type of the resource for provisioning.
+func (alertRule *AlertRule) ResourceType() string {
+	return "alertRule"
+}
+
+// ResourceID returns the identifier of the resource for provisioning.
+func (alertRule *AlertRule) ResourceID() string {
+	return alertRule.UID
+}
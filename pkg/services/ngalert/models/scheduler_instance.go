@@ -0,0 +1,13 @@
+package models
+
+// SchedulerInstance is a Grafana instance taking part in the sharded evaluation of the alert rules.
+type SchedulerInstance struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	InstanceID string `xorm:"instance_id"`
+	// LastHeartbeat is the Unix time of the last heartbeat of the instance.
+	LastHeartbeat int64
+}
+
+func (i SchedulerInstance) TableName() string {
+	return "alert_scheduler_instance"
+}
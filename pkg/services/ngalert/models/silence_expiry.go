@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSilenceExpiryNotificationNotFound is an error for a silence without expiry notification.
+var ErrSilenceExpiryNotificationNotFound = errors.New("could not find silence expiry notification")
+
+// SilenceExpiryNotification notifies a contact point that a silence of the Grafana Alertmanager is about to expire.
+type SilenceExpiryNotification struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	SilenceID string `xorm:"silence_id"`
+	Receiver  string
+	// NotifyBefore is how long before the end of the silence the contact point is notified.
+	NotifyBefore time.Duration
+	// NotifiedAt is the Unix time the contact point was last notified at, 0 if it wasn't notified yet.
+	NotifiedAt int64
+}
+
+func (n SilenceExpiryNotification) TableName() string {
+	return "alert_silence_expiry_notification"
+}
+
+// Validate returns an error if the silence expiry notification is invalid.
+func (n SilenceExpiryNotification) Validate() error {
+	if n.Receiver == "" {
+		return errors.New("contact point of the silence expiry notification is required")
+	}
+	if n.NotifyBefore <= 0 {
+		return errors.New("silence expiry notification must be sent before the silence expires")
+	}
+	return nil
+}
+
+// GetSilenceExpiryNotificationQuery is the query for retrieving the expiry notification of a silence.
+type GetSilenceExpiryNotificationQuery struct {
+	SilenceID string
+
+	Result *SilenceExpiryNotification
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertStateHistoryEntry represents a single state transition of an alert instance.
+type AlertStateHistoryEntry struct {
+	ID               int64             `xorm:"pk autoincr 'id'" json:"id"`
+	RuleOrgID        int64             `xorm:"rule_org_id" json:"-"`
+	RuleUID          string            `xorm:"rule_uid" json:"ruleUid"`
+	Labels           InstanceLabels    `json:"labels"`
+	LabelsHash       string            `json:"labelsHash"`
+	PreviousState    InstanceStateType `json:"previousState"`
+	CurrentState     InstanceStateType `json:"currentState"`
+	EvaluationString string            `json:"evaluationString,omitempty"`
+	Error            string            `json:"error,omitempty"`
+	StateChangedAt   time.Time         `json:"stateChangedAt"`
+}
+
+// IsResolved returns true if the transition resolved a firing alert.
+func (e AlertStateHistoryEntry) IsResolved() bool {
+	return e.PreviousState == InstanceStateFiring && e.CurrentState == InstanceStateNormal
+}
+
+// SaveAlertStateHistoryCommand is the command for saving state transitions of alert instances.
+type SaveAlertStateHistoryCommand struct {
+	Entries []AlertStateHistoryEntry
+}
+
+// ListAlertStateHistoryQuery is the query for listing the state transitions of the instances of an alert rule,
+// the most recent transitions are returned first.
+type ListAlertStateHistoryQuery struct {
+	RuleOrgID int64
+	RuleUID   string
+	State     InstanceStateType
+	From      time.Time
+	To        time.Time
+	Limit     int64
+
+	Result []*AlertStateHistoryEntry
+}
+
+// ValidateAlertStateHistoryEntry validates that the state history entry contains an alert rule id,
+// and valid states.
+func ValidateAlertStateHistoryEntry(entry AlertStateHistoryEntry) error {
+	if entry.RuleOrgID == 0 {
+		return fmt.Errorf("alert state history entry is invalid due to missing alert rule organisation")
+	}
+
+	if entry.RuleUID == "" {
+		return fmt.Errorf("alert state history entry is invalid due to missing alert rule uid")
+	}
+
+	if !entry.PreviousState.IsValid() {
+		return fmt.Errorf("alert state history entry is invalid because the previous state '%v' is invalid", entry.PreviousState)
+	}
+
+	if !entry.CurrentState.IsValid() {
+		return fmt.Errorf("alert state history entry is invalid because the state '%v' is invalid", entry.CurrentState)
+	}
+
+	return nil
+}
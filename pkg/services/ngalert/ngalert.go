@@ -14,14 +14,18 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -37,6 +41,8 @@ const (
 	baseIntervalSeconds = 10
 	// default alert definiiton interval
 	defaultIntervalSeconds int64 = 6 * baseIntervalSeconds
+	// how often the state history and the notification log entries older than the configured max age are deleted
+	cleanupInterval = time.Hour
 )
 
 // AlertNG is the service for evaluating the condition of an alert definition.
@@ -49,10 +55,15 @@ type AlertNG struct {
 	DataProxy       *datasourceproxy.DatasourceProxyService `inject:""`
 	QuotaService    *quota.QuotaService                     `inject:""`
 	Metrics         *metrics.Metrics                        `inject:""`
+	RenderService   rendering.Service                       `inject:""`
+	AccessControl   accesscontrol.AccessControl             `inject:""`
+	RemoteCache     *remotecache.RemoteCache                `inject:""`
 	Alertmanager    *notifier.Alertmanager
 	Log             log.Logger
 	schedule        schedule.ScheduleService
 	stateManager    *state.Manager
+	historyStore    store.StateHistoryStore
+	notificationLog store.NotificationLogStore
 }
 
 func init() {
@@ -62,7 +73,6 @@ func init() {
 // Init initializes the AlertingService.
 func (ng *AlertNG) Init() error {
 	ng.Log = log.New("ngalert")
-	ng.stateManager = state.NewManager(ng.Log, ng.Metrics)
 	baseInterval := baseIntervalSeconds * time.Second
 
 	store := &store.DBstore{
@@ -71,12 +81,16 @@ func (ng *AlertNG) Init() error {
 		SQLStore:               ng.SQLStore,
 		Logger:                 ng.Log,
 	}
+	ng.historyStore = store
+	ng.notificationLog = store
+	ng.stateManager = state.NewManager(ng.Log, ng.Metrics, store)
 
 	var err error
 	ng.Alertmanager, err = notifier.New(ng.Cfg, store, ng.Metrics)
 	if err != nil {
 		return err
 	}
+	ng.Alertmanager.ImageProvider = notifier.NewImageProvider(ng.RenderService, ng.Cfg.AlertingNotificationImageRenderLimit)
 
 	schedCfg := schedule.SchedulerCfg{
 		C:             clock.New(),
@@ -88,22 +102,50 @@ func (ng *AlertNG) Init() error {
 		RuleStore:     store,
 		Notifier:      ng.Alertmanager,
 		Metrics:       ng.Metrics,
+
+		AdminConfigStore:        store,
+		AdminConfigPollInterval: ng.Cfg.AdminConfigPollInterval,
+		MaxAlertInstances:       ng.Cfg.AlertingMaxAlertInstancesPerRule,
+		RuleHeartbeatStore:      store,
+
+		StatePersistInterval:  ng.Cfg.AlertingStatePersistInterval,
+		StatePersistBatchSize: ng.Cfg.AlertingStatePersistBatchSize,
+		StateFullSyncInterval: ng.Cfg.AlertingStateFullSyncInterval,
+	}
+	if ng.QuotaService != nil {
+		schedCfg.QuotaChecker = ng.QuotaService
+	}
+	if ng.Cfg.AlertingRecordingRulesRemoteWriteURL != "" {
+		schedCfg.RecordingWriter = recording.NewRemoteWriter(ng.Cfg.AlertingRecordingRulesRemoteWriteURL,
+			ng.Cfg.AlertingRecordingRulesRemoteWriteUser, ng.Cfg.AlertingRecordingRulesRemoteWritePassword,
+			ng.Cfg.AlertingRecordingRulesRemoteWriteTimeout)
+	}
+	if ng.Cfg.AlertingSchedulerSharding {
+		schedCfg.SchedulerInstanceStore = store
+		schedCfg.InstanceID = setting.InstanceName + ":" + ng.Cfg.HTTPPort
+		schedCfg.ShardHeartbeatInterval = ng.Cfg.AlertingSchedulerShardingHeartbeatInterval
 	}
 	ng.schedule = schedule.NewScheduler(schedCfg, ng.DataService, ng.Cfg.AppURL)
 
 	api := api.API{
-		Cfg:             ng.Cfg,
-		DatasourceCache: ng.DatasourceCache,
-		RouteRegister:   ng.RouteRegister,
-		DataService:     ng.DataService,
-		Schedule:        ng.schedule,
-		DataProxy:       ng.DataProxy,
-		QuotaService:    ng.QuotaService,
-		InstanceStore:   store,
-		RuleStore:       store,
-		AlertingStore:   store,
-		Alertmanager:    ng.Alertmanager,
-		StateManager:    ng.stateManager,
+		Cfg:               ng.Cfg,
+		DatasourceCache:   ng.DatasourceCache,
+		RouteRegister:     ng.RouteRegister,
+		DataService:       ng.DataService,
+		Schedule:          ng.schedule,
+		DataProxy:         ng.DataProxy,
+		QuotaService:      ng.QuotaService,
+		InstanceStore:     store,
+		RuleStore:         store,
+		AlertingStore:     store,
+		ProvisioningStore: store,
+		HistoryStore:      store,
+		HeartbeatStore:    store,
+		AdminConfigStore:  store,
+		Alertmanager:      ng.Alertmanager,
+		StateManager:      ng.stateManager,
+		AccessControl:     ng.AccessControl,
+		RemoteCache:       ng.RemoteCache,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics)
 
@@ -122,9 +164,44 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.Alertmanager.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.cleanUp(subCtx, "alert state history", ng.Cfg.AlertingStateHistoryMaxAge, ng.historyStore.DeleteAlertStateHistoryBefore)
+	})
+	children.Go(func() error {
+		return ng.cleanUp(subCtx, "notification log", ng.Cfg.AlertingNotificationLogMaxAge, ng.notificationLog.DeleteNotificationAttemptsBefore)
+	})
 	return children.Wait()
 }
 
+// Shutdown pauses the scheduler, so that no alert rules are evaluated while Grafana shuts down.
+func (ng *AlertNG) Shutdown(_ context.Context) error {
+	return ng.schedule.Pause()
+}
+
+// cleanUp periodically deletes the entries older than maxAge using deleteBefore.
+func (ng *AlertNG) cleanUp(ctx context.Context, what string, maxAge time.Duration, deleteBefore func(time.Time) (int64, error)) error {
+	if maxAge <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		deleted, err := deleteBefore(time.Now().Add(-maxAge))
+		if err != nil {
+			ng.Log.Error("failed to delete old "+what, "err", err)
+		} else if deleted > 0 {
+			ng.Log.Debug("deleted old "+what, "count", deleted)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -22,6 +22,7 @@ import (
 	"github.com/prometheus/alertmanager/provider/mem"
 	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 
@@ -82,6 +83,8 @@ type Alertmanager struct {
 	SQLStore *sqlstore.SQLStore `inject:""`
 	Store    store.AlertingStore
 	Metrics  *metrics.Metrics `inject:""`
+	// ImageProvider provides the panel images of the contact points that include them, nil disables the images.
+	ImageProvider channels.ImageProvider
 
 	notificationLog *nflog.Log
 	marker          types.Marker
@@ -104,6 +107,19 @@ type Alertmanager struct {
 
 	reloadConfigMtx sync.RWMutex
 	config          []byte
+	// integrationsMap are the integrations of the current configuration by receiver name.
+	integrationsMap map[string][]notify.Integration
+	// template is the notification template of the current configuration.
+	template *template.Template
+
+	// escalationsMtx serializes the changes of the escalations, escalationsWakeCh is signalled
+	// when an escalation has a step to notify right away.
+	escalationsMtx    sync.Mutex
+	escalationsWakeCh chan struct{}
+
+	// correlations are the correlations of the alerts with a correlation key by organisation and correlation key.
+	correlationsMtx sync.Mutex
+	correlations    map[string]*correlation
 }
 
 func New(cfg *setting.Cfg, store store.AlertingStore, m *metrics.Metrics) (*Alertmanager, error) {
@@ -116,6 +132,8 @@ func New(cfg *setting.Cfg, store store.AlertingStore, m *metrics.Metrics) (*Aler
 		dispatcherMetrics: dispatch.NewDispatcherMetrics(m.Registerer),
 		Store:             store,
 		Metrics:           m,
+		escalationsWakeCh: make(chan struct{}, 1),
+		correlations:      make(map[string]*correlation),
 	}
 
 	am.gokitLogger = gokit_log.NewLogfmtLogger(logging.NewWrapper(am.logger))
@@ -147,6 +165,18 @@ func New(cfg *setting.Cfg, store store.AlertingStore, m *metrics.Metrics) (*Aler
 		am.wg.Done()
 	}()
 
+	am.wg.Add(1)
+	go func() {
+		am.runEscalations(am.stopc)
+		am.wg.Done()
+	}()
+
+	am.wg.Add(1)
+	go func() {
+		am.runSilenceExpiryNotifications(am.stopc)
+		am.wg.Done()
+	}()
+
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, am.gokitLogger)
 	if err != nil {
@@ -331,11 +361,20 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	am.inhibitor = inhibit.NewInhibitor(am.alerts, cfg.AlertmanagerConfig.InhibitRules, am.marker, am.gokitLogger)
 	am.silencer = silence.NewSilencer(am.silences, am.marker, am.gokitLogger)
 
+	// Build the map of mute time interval names to their time intervals for the routes referencing them.
+	muteTimes := make(map[string][]timeinterval.TimeInterval, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimes[mt.Name] = mt.TimeIntervals
+	}
+
 	inhibitionStage := notify.NewMuteStage(am.inhibitor)
+	timeMuteStage := notify.NewTimeMuteStage(muteTimes)
 	silencingStage := notify.NewMuteStage(am.silencer)
-	for name := range integrationsMap {
-		stage := am.createReceiverStage(name, integrationsMap[name], waitFunc, am.notificationLog)
-		routingStage[name] = notify.MultiStage{silencingStage, inhibitionStage, stage}
+	rateLimiters := newRateLimiters(cfg.AlertmanagerConfig.RateLimits)
+	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+		maintenanceStage := &maintenanceStage{receiver: receiver, store: am.Store, metrics: am.Metrics, logger: am.logger}
+		stage := am.createReceiverStage(receiver.Name, integrationsMap[receiver.Name], waitFunc, am.notificationLog, receiverRateLimiters(rateLimiters, receiver.Name))
+		routingStage[receiver.Name] = notify.MultiStage{silencingStage, inhibitionStage, timeMuteStage, maintenanceStage, stage}
 	}
 
 	am.route = dispatch.NewRoute(cfg.AlertmanagerConfig.Route, nil)
@@ -354,6 +393,8 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	}()
 
 	am.config = rawConfig
+	am.integrationsMap = integrationsMap
+	am.template = tmpl
 	return nil
 }
 
@@ -385,75 +426,96 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 	var integrations []notify.Integration
 
 	for i, r := range receiver.GrafanaManagedReceivers {
-		// secure settings are already encrypted at this point
-		secureSettings := securejsondata.SecureJsonData(make(map[string][]byte, len(r.SecureSettings)))
-
-		for k, v := range r.SecureSettings {
-			d, err := base64.StdEncoding.DecodeString(v)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode secure setting")
-			}
-			secureSettings[k] = d
-		}
-		var (
-			cfg = &channels.NotificationChannelConfig{
-				UID:                   r.UID,
-				Name:                  r.Name,
-				Type:                  r.Type,
-				DisableResolveMessage: r.DisableResolveMessage,
-				Settings:              r.Settings,
-				SecureSettings:        secureSettings,
-			}
-			n   NotificationChannel
-			err error
-		)
-		switch r.Type {
-		case "email":
-			n, err = channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
-		case "pagerduty":
-			n, err = channels.NewPagerdutyNotifier(cfg, tmpl)
-		case "pushover":
-			n, err = channels.NewPushoverNotifier(cfg, tmpl)
-		case "slack":
-			n, err = channels.NewSlackNotifier(cfg, tmpl)
-		case "telegram":
-			n, err = channels.NewTelegramNotifier(cfg, tmpl)
-		case "victorops":
-			n, err = channels.NewVictoropsNotifier(cfg, tmpl)
-		case "teams":
-			n, err = channels.NewTeamsNotifier(cfg, tmpl)
-		case "dingding":
-			n, err = channels.NewDingDingNotifier(cfg, tmpl)
-		case "kafka":
-			n, err = channels.NewKafkaNotifier(cfg, tmpl)
-		case "webhook":
-			n, err = channels.NewWebHookNotifier(cfg, tmpl)
-		case "sensugo":
-			n, err = channels.NewSensuGoNotifier(cfg, tmpl)
-		case "discord":
-			n, err = channels.NewDiscordNotifier(cfg, tmpl)
-		case "googlechat":
-			n, err = channels.NewGoogleChatNotifier(cfg, tmpl)
-		case "line":
-			n, err = channels.NewLineNotifier(cfg, tmpl)
-		case "threema":
-			n, err = channels.NewThreemaNotifier(cfg, tmpl)
-		case "opsgenie":
-			n, err = channels.NewOpsgenieNotifier(cfg, tmpl)
-		case "prometheus-alertmanager":
-			n, err = channels.NewAlertmanagerNotifier(cfg, tmpl)
-		default:
-			return nil, fmt.Errorf("notifier %s is not supported", r.Type)
-		}
+		n, err := am.buildReceiverIntegration(r, tmpl)
 		if err != nil {
 			return nil, err
 		}
-		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
+		recorder := &recordingNotifier{
+			NotificationChannel: n,
+			receiver:            receiver.Name,
+			integrationType:     r.Type,
+			integrationIndex:    i,
+			integrationUID:      r.UID,
+			store:               am.Store,
+			logger:              am.logger,
+		}
+		integrations = append(integrations, notify.NewIntegration(recorder, n, r.Type, i))
 	}
 
 	return integrations, nil
 }
 
+// buildReceiverIntegration builds the notification channel of a Grafana managed receiver config.
+func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaReceiver, tmpl *template.Template) (NotificationChannel, error) {
+	// secure settings are already encrypted at this point
+	secureSettings := securejsondata.SecureJsonData(make(map[string][]byte, len(r.SecureSettings)))
+
+	for k, v := range r.SecureSettings {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secure setting")
+		}
+		secureSettings[k] = d
+	}
+	var (
+		cfg = &channels.NotificationChannelConfig{
+			UID:                   r.UID,
+			Name:                  r.Name,
+			Type:                  r.Type,
+			DisableResolveMessage: r.DisableResolveMessage,
+			Settings:              r.Settings,
+			SecureSettings:        secureSettings,
+			ImageProvider:         am.ImageProvider,
+		}
+		n   NotificationChannel
+		err error
+	)
+	switch r.Type {
+	case "email":
+		n, err = channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
+	case "pagerduty":
+		n, err = channels.NewPagerdutyNotifier(cfg, tmpl)
+	case "pushover":
+		n, err = channels.NewPushoverNotifier(cfg, tmpl)
+	case "slack":
+		n, err = channels.NewSlackNotifier(cfg, tmpl)
+	case "telegram":
+		n, err = channels.NewTelegramNotifier(cfg, tmpl)
+	case "victorops":
+		n, err = channels.NewVictoropsNotifier(cfg, tmpl)
+	case "teams":
+		n, err = channels.NewTeamsNotifier(cfg, tmpl)
+	case "dingding":
+		n, err = channels.NewDingDingNotifier(cfg, tmpl)
+	case "kafka":
+		n, err = channels.NewKafkaNotifier(cfg, tmpl)
+	case "webhook":
+		n, err = channels.NewWebHookNotifier(cfg, tmpl)
+	case "sensugo":
+		n, err = channels.NewSensuGoNotifier(cfg, tmpl)
+	case "discord":
+		n, err = channels.NewDiscordNotifier(cfg, tmpl)
+	case "googlechat":
+		n, err = channels.NewGoogleChatNotifier(cfg, tmpl)
+	case "line":
+		n, err = channels.NewLineNotifier(cfg, tmpl)
+	case "threema":
+		n, err = channels.NewThreemaNotifier(cfg, tmpl)
+	case "opsgenie":
+		n, err = channels.NewOpsgenieNotifier(cfg, tmpl)
+	case "prometheus-alertmanager":
+		n, err = channels.NewAlertmanagerNotifier(cfg, tmpl)
+	case escalationIntegrationType:
+		n, err = am.newEscalationNotifier(cfg)
+	default:
+		return nil, fmt.Errorf("notifier %s is not supported", r.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
 func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error {
 	now := time.Now()
@@ -517,7 +579,7 @@ func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error
 		alerts = append(alerts, alert)
 	}
 
-	if err := am.alerts.Put(alerts...); err != nil {
+	if err := am.alerts.Put(am.correlate(now, alerts)...); err != nil {
 		// Notification sending alert takes precedence over validation errors.
 		return err
 	}
@@ -546,8 +608,8 @@ func (e AlertValidationError) Error() string {
 	return errMsg
 }
 
-// createReceiverStage creates a pipeline of stages for a receiver.
-func (am *Alertmanager) createReceiverStage(name string, integrations []notify.Integration, wait func() time.Duration, notificationLog notify.NotificationLog) notify.Stage {
+// createReceiverStage creates a pipeline of stages for a receiver, the notifications of its integrations are limited by the rate limiters.
+func (am *Alertmanager) createReceiverStage(name string, integrations []notify.Integration, wait func() time.Duration, notificationLog notify.NotificationLog, rateLimiters []*rateLimiter) notify.Stage {
 	var fs notify.FanoutStage
 	for i := range integrations {
 		recv := &nflogpb.Receiver{
@@ -558,6 +620,9 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
 		s = append(s, notify.NewDedupStage(&integrations[i], notificationLog, recv))
+		if len(rateLimiters) > 0 {
+			s = append(s, &rateLimitStage{limiters: rateLimiters, receiver: name, integration: integrations[i].Name(), metrics: am.Metrics, logger: am.logger})
+		}
 		s = append(s, notify.NewRetryStage(integrations[i], name, am.stageMetrics))
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
 
@@ -310,3 +310,82 @@ func TestPutAlert(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewSilence(t *testing.T) {
+	am := setupAMTest(t)
+	require.NoError(t, am.SyncAndApplyConfigFromDatabase())
+
+	now := time.Now()
+	require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{
+		PostableAlerts: []models.PostableAlert{
+			{
+				Alert:    models.Alert{Labels: models.LabelSet{"alertname": "Alert1", "team": "a"}},
+				StartsAt: strfmt.DateTime(now),
+			}, {
+				Alert:    models.Alert{Labels: models.LabelSet{"alertname": "Alert2", "team": "b"}},
+				StartsAt: strfmt.DateTime(now),
+			}, { // Resolved.
+				Alert:    models.Alert{Labels: models.LabelSet{"alertname": "Alert3", "team": "a"}},
+				StartsAt: strfmt.DateTime(now.Add(-2 * time.Hour)),
+				EndsAt:   strfmt.DateTime(now.Add(-time.Hour)),
+			},
+		},
+	}))
+
+	matcher := func(name, value string, isRegex, isEqual bool) *models.Matcher {
+		return &models.Matcher{Name: &name, Value: &value, IsRegex: &isRegex, IsEqual: &isEqual}
+	}
+
+	cases := []struct {
+		title     string
+		matchers  models.Matchers
+		expAlerts []string
+		expError  bool
+	}{
+		{
+			title:     "equal matcher",
+			matchers:  models.Matchers{matcher("team", "a", false, true)},
+			expAlerts: []string{"Alert1"},
+		}, {
+			title:     "regex matcher",
+			matchers:  models.Matchers{matcher("team", "a|b", true, true)},
+			expAlerts: []string{"Alert1", "Alert2"},
+		}, {
+			title:     "not equal matcher",
+			matchers:  models.Matchers{matcher("team", "a", false, false)},
+			expAlerts: []string{"Alert2"},
+		}, {
+			title:     "all matchers must match",
+			matchers:  models.Matchers{matcher("team", "a", false, true), matcher("alertname", "Alert2", false, true)},
+			expAlerts: []string{},
+		}, {
+			title:    "no matchers",
+			matchers: models.Matchers{},
+			expError: true,
+		}, {
+			title:    "invalid regex",
+			matchers: models.Matchers{matcher("team", "(", true, true)},
+			expError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			alerts, err := am.PreviewSilence(&apimodels.PostableSilence{
+				Silence: models.Silence{Matchers: c.matchers},
+			})
+			if c.expError {
+				require.ErrorIs(t, err, ErrPreviewSilenceBadPayload)
+				return
+			}
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(alerts))
+			for _, a := range alerts {
+				names = append(names, a.Labels["alertname"])
+			}
+			sort.Strings(names)
+			require.Equal(t, c.expAlerts, names)
+		})
+	}
+}
@@ -190,6 +190,12 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Element:      alerting.ElementTypeTextArea,
 					PropertyName: "message",
 				},
+				{
+					Label:        "Include image",
+					Description:  "Include a screenshot of the panel the firing alert is linked to, requires the image renderer",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "includeImage",
+				},
 			},
 		},
 		{
@@ -474,6 +480,12 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "text",
 					Placeholder:  `{{ template "slack.default.text" . }}`,
 				},
+				{
+					Label:        "Include image",
+					Description:  "Include a screenshot of the panel the firing alert is linked to, requires the image renderer. Without external image storage the image is uploaded to the recipient with the token",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "includeImage",
+				},
 			},
 		},
 		{
@@ -612,6 +624,10 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 							Value: "PUT",
 							Label: "PUT",
 						},
+						{
+							Value: "PATCH",
+							Label: "PATCH",
+						},
 					},
 					PropertyName: "httpMethod",
 				},
@@ -635,6 +651,35 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					InputType:    alerting.InputTypeText,
 					PropertyName: "maxAlerts",
 				},
+				{
+					Label:        "Custom Headers",
+					Description:  "Headers added to the request, one \"Name: value\" header per line",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "HMAC Secret",
+					Description:  "Secret the payload is signed with, the HMAC-SHA256 signature of the timestamp and the body joined with a dot is sent in the X-Grafana-Alerting-Signature header and the timestamp in the X-Grafana-Alerting-Timestamp header",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "Max Retries",
+					Description:  "Number of times the request is retried when it fails with a 5xx or 429 status or without response. 0 means no retry.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "maxRetries",
+				},
+				{
+					Label:        "Retry Backoff",
+					Description:  "Time to wait before the first retry, it's doubled for every following retry. Default is 1s.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "1s",
+					PropertyName: "retryBackoff",
+				},
 			},
 		},
 		{
@@ -823,5 +868,21 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "escalation",
+			Name:        "Escalation",
+			Description: "Notifies the contact points of an escalation policy one after the other until acknowledged",
+			Heading:     "Escalation settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Escalation policy UID",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "The UID of the escalation policy whose steps are notified",
+					PropertyName: "policyUid",
+					Required:     true,
+				},
+			},
+		},
 	}
 }
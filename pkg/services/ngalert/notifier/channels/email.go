@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/url"
 	"path"
+	"path/filepath"
 
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
@@ -20,11 +21,13 @@ import (
 // alert notifications over email.
 type EmailNotifier struct {
 	old_notifiers.NotifierBase
-	Addresses   []string
-	SingleEmail bool
-	Message     string
-	log         log.Logger
-	tmpl        *template.Template
+	Addresses    []string
+	SingleEmail  bool
+	Message      string
+	IncludeImage bool
+	images       ImageProvider
+	log          log.Logger
+	tmpl         *template.Template
 }
 
 // NewEmailNotifier is the constructor function
@@ -52,11 +55,13 @@ func NewEmailNotifier(model *NotificationChannelConfig, t *template.Template) (*
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		Addresses:   addresses,
-		SingleEmail: singleEmail,
-		Message:     model.Settings.Get("message").MustString(),
-		log:         log.New("alerting.notifier.email"),
-		tmpl:        t,
+		Addresses:    addresses,
+		SingleEmail:  singleEmail,
+		Message:      model.Settings.Get("message").MustString(),
+		IncludeImage: model.Settings.Get("includeImage").MustBool(false),
+		images:       model.ImageProvider,
+		log:          log.New("alerting.notifier.email"),
+		tmpl:         t,
 	}, nil
 }
 
@@ -101,6 +106,18 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		},
 	}
 
+	if en.IncludeImage {
+		// the image is embedded in the email when there is no external image store
+		if img := getImage(ctx, en.images, as, en.log); img != nil {
+			if img.URL != "" {
+				cmd.Data["ImageLink"] = img.URL
+			} else {
+				cmd.EmbeddedFiles = []string{img.Path}
+				cmd.Data["EmbeddedImage"] = filepath.Base(img.Path)
+			}
+		}
+	}
+
 	if tmplErr != nil {
 		en.log.Debug("failed to template email message", "err", tmplErr.Error())
 	}
@@ -105,4 +105,45 @@ func TestEmailNotifier(t *testing.T) {
 			},
 		}, expected)
 	})
+
+	t.Run("includes the image of the panel", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"addresses": "someops@example.com", "includeImage": true}`))
+		require.NoError(t, err)
+		images := &fakeImageProvider{image: &Image{Path: "/tmp/renders/abc.png"}}
+
+		emailNotifier, err := NewEmailNotifier(&NotificationChannelConfig{
+			Name:          "ops",
+			Type:          "email",
+			Settings:      settingsJSON,
+			ImageProvider: images,
+		}, tmpl)
+		require.NoError(t, err)
+
+		var sent *models.SendEmailCommandSync
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+			sent = cmd
+			return nil
+		})
+		alerts := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "AlwaysFiring"}}}}
+
+		_, err = emailNotifier.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+		require.Equal(t, []string{"/tmp/renders/abc.png"}, sent.EmbeddedFiles)
+		require.Equal(t, "abc.png", sent.Data["EmbeddedImage"])
+
+		// the image is linked when it's in the external image store
+		images.image.URL = "https://images.example.com/abc.png"
+		_, err = emailNotifier.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+		require.Empty(t, sent.EmbeddedFiles)
+		require.Equal(t, "https://images.example.com/abc.png", sent.Data["ImageLink"])
+	})
+}
+
+type fakeImageProvider struct {
+	image *Image
+}
+
+func (p *fakeImageProvider) GetImage(_ context.Context, _ *types.Alert) (*Image, error) {
+	return p.image, nil
 }
@@ -0,0 +1,49 @@
+package channels
+
+import (
+	"context"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// Image is a screenshot of the panel an alert is linked to.
+type Image struct {
+	// Path is the path of the image on disk.
+	Path string
+	// URL is the URL of the image in the external image store,
+	// empty if there is no external image store configured.
+	URL string
+}
+
+// ImageProvider provides the screenshots of the panels the alerts are linked to.
+type ImageProvider interface {
+	// GetImage returns the screenshot of the panel the alert is linked to,
+	// or nil if the alert isn't linked to a panel or no image can be taken right now.
+	GetImage(ctx context.Context, alert *types.Alert) (*Image, error)
+}
+
+// getImage returns the screenshot of the panel of the first firing alert linked to a panel,
+// or nil if there is none. The notification is sent without image when the image fails,
+// so errors are only logged.
+func getImage(ctx context.Context, p ImageProvider, as []*types.Alert, logger log.Logger) *Image {
+	if p == nil {
+		return nil
+	}
+	for _, a := range as {
+		if a.Status() != model.AlertFiring {
+			continue
+		}
+		img, err := p.GetImage(ctx, a)
+		if err != nil {
+			logger.Warn("failed to get the image of the alert panel", "alert", a.Name(), "err", err)
+			continue
+		}
+		if img != nil {
+			return img
+		}
+	}
+	return nil
+}
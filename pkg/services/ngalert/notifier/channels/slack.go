@@ -7,9 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -43,12 +46,19 @@ type SlackNotifier struct {
 	MentionGroups  []string
 	MentionChannel string
 	Token          string
+	IncludeImage   bool
+
+	images ImageProvider
 }
 
 var reRecipient *regexp.Regexp = regexp.MustCompile("^((@[a-z0-9][a-zA-Z0-9._-]*)|(#[^ .A-Z]{1,79})|([a-zA-Z0-9]+))$")
 
 var SlackAPIEndpoint = "https://slack.com/api/chat.postMessage"
 
+// SlackFileUploadEndpoint is the endpoint the panel images are uploaded to
+// when there is no external image store.
+var SlackFileUploadEndpoint = "https://slack.com/api/files.upload"
+
 // NewSlackNotifier is the constructor for the Slack notifier
 func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template) (*SlackNotifier, error) {
 	if model.Settings == nil {
@@ -124,10 +134,12 @@ func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		IconEmoji:      model.Settings.Get("icon_emoji").MustString(),
 		IconURL:        model.Settings.Get("icon_url").MustString(),
 		Token:          token,
+		IncludeImage:   model.Settings.Get("includeImage").MustBool(false),
 		Text:           model.Settings.Get("text").MustString(`{{ template "default.message" . }}`),
 		Title:          model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
 		log:            log.New("alerting.notifier.slack"),
 		tmpl:           t,
+		images:         model.ImageProvider,
 	}, nil
 }
 
@@ -151,6 +163,7 @@ type attachment struct {
 	Footer     string              `json:"footer"`
 	FooterIcon string              `json:"footer_icon"`
 	Color      string              `json:"color,omitempty"`
+	ImageURL   string              `json:"image_url,omitempty"`
 	Ts         int64               `json:"ts,omitempty"`
 }
 
@@ -161,6 +174,14 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		return false, fmt.Errorf("build slack message: %w", err)
 	}
 
+	var img *Image
+	if sn.IncludeImage {
+		img = getImage(ctx, sn.images, as, sn.log)
+		if img != nil && img.URL != "" {
+			msg.Attachments[0].ImageURL = img.URL
+		}
+	}
+
 	b, err := json.Marshal(msg)
 	if err != nil {
 		return false, fmt.Errorf("marshal json: %w", err)
@@ -186,9 +207,60 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	if err := sendSlackRequest(request, sn.log); err != nil {
 		return false, err
 	}
+
+	// Without external image store the image can only be uploaded to Slack with the chat API token.
+	// The message is already sent, so a failed upload doesn't fail the notification.
+	if img != nil && img.URL == "" && sn.Token != "" && msg.Channel != "" {
+		if err := sn.uploadImage(ctx, img.Path, msg.Channel); err != nil {
+			sn.log.Warn("failed to upload the image of the alert panel to Slack", "err", err)
+		}
+	}
 	return true, nil
 }
 
+// uploadImage uploads the image to the Slack channel with the files.upload API.
+func (sn *SlackNotifier) uploadImage(ctx context.Context, imagePath string, channel string) error {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			sn.log.Warn("Failed to close image file", "err", err)
+		}
+	}()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if boundary := GetBoundary(); boundary != "" {
+		if err := w.SetBoundary(boundary); err != nil {
+			return err
+		}
+	}
+	if err := writeField(w, "channels", channel); err != nil {
+		return err
+	}
+	fw, err := w.CreateFormFile("file", filepath.Base(imagePath))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fw, f); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, SlackFileUploadEndpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	request.Header.Set("Content-Type", w.FormDataContentType())
+	request.Header.Set("User-Agent", "Grafana")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sn.Token))
+	return sendSlackRequest(request, sn.log)
+}
+
 // sendSlackRequest sends a request to the Slack API.
 // Stubbable by tests.
 var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
@@ -223,7 +295,7 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 
 	if resp.StatusCode/100 != 2 {
 		logger.Warn("Slack API request failed", "url", request.URL.String(), "statusCode", resp.Status, "body", string(body))
-		return fmt.Errorf("request to Slack API failed with status code %d", resp.StatusCode)
+		return &models.HTTPStatusError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("request to Slack API failed with status code %d", resp.StatusCode)}
 	}
 
 	var rslt map[string]interface{}
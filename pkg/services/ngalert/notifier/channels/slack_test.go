@@ -6,6 +6,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/prometheus/alertmanager/notify"
@@ -222,3 +224,65 @@ func TestSlackNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestSlackNotifierImages(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	imagePath := filepath.Join(t.TempDir(), "panel.png")
+	require.NoError(t, os.WriteFile(imagePath, []byte("png"), 0600))
+	images := &fakeImageProvider{image: &Image{Path: imagePath}}
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{"recipient": "#alerts", "token": "1234", "includeImage": true}`))
+	require.NoError(t, err)
+	sn, err := NewSlackNotifier(&NotificationChannelConfig{
+		Name:          "slack_testing",
+		Type:          "slack",
+		Settings:      settingsJSON,
+		ImageProvider: images,
+	}, tmpl)
+	require.NoError(t, err)
+
+	var requests []*http.Request
+	var bodies []string
+	origSendSlackRequest := sendSlackRequest
+	t.Cleanup(func() {
+		sendSlackRequest = origSendSlackRequest
+	})
+	sendSlackRequest = func(request *http.Request, log log.Logger) error {
+		b, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		requests = append(requests, request)
+		bodies = append(bodies, string(b))
+		return nil
+	}
+	alerts := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}}
+
+	t.Run("the image is uploaded to the channel without external image store", func(t *testing.T) {
+		requests, bodies = nil, nil
+		_, err := sn.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+
+		require.Len(t, requests, 2)
+		require.Equal(t, SlackAPIEndpoint, requests[0].URL.String())
+		require.NotContains(t, bodies[0], "image_url")
+		require.Equal(t, SlackFileUploadEndpoint, requests[1].URL.String())
+		require.Equal(t, "Bearer 1234", requests[1].Header.Get("Authorization"))
+		require.Contains(t, bodies[1], `name="channels"`+"\r\n\r\n#alerts")
+		require.Contains(t, bodies[1], `filename="panel.png"`)
+	})
+
+	t.Run("the image is linked when it's in the external image store", func(t *testing.T) {
+		requests, bodies = nil, nil
+		images.image.URL = "https://images.example.com/panel.png"
+		_, err := sn.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		var msg slackMessage
+		require.NoError(t, json.Unmarshal([]byte(bodies[0]), &msg))
+		require.Equal(t, "https://images.example.com/panel.png", msg.Attachments[0].ImageURL)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/prometheus/common/model"
 
@@ -40,6 +41,8 @@ type NotificationChannelConfig struct {
 	DisableResolveMessage bool                          `json:"disableResolveMessage"`
 	Settings              *simplejson.Json              `json:"settings"`
 	SecureSettings        securejsondata.SecureJsonData `json:"secureSettings"`
+	// ImageProvider provides the panel images of the notifiers that include them, nil disables the images.
+	ImageProvider ImageProvider `json:"-"`
 }
 
 // DecryptedValue returns decrypted value from secureSettings
@@ -105,7 +108,7 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 	if resp.StatusCode/100 != 2 {
 		logger.Warn("HTTP request failed", "url", request.URL.String(), "statusCode", resp.Status, "body",
 			string(respBody))
-		return nil, fmt.Errorf("failed to send HTTP request - status code %d", resp.StatusCode)
+		return nil, &models.HTTPStatusError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("failed to send HTTP request - status code %d", resp.StatusCode)}
 	}
 
 	logger.Debug("Sending HTTP request succeeded", "url", request.URL.String(), "statusCode", resp.Status)
@@ -2,7 +2,16 @@ package channels
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
@@ -16,17 +25,29 @@ import (
 	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
 )
 
+const (
+	// WebhookSignatureHeader is the header of the HMAC-SHA256 signature of the webhook payload,
+	// it's the hex encoded signature of the timestamp and of the body joined with a dot.
+	WebhookSignatureHeader = "X-Grafana-Alerting-Signature"
+	// WebhookTimestampHeader is the header of the Unix timestamp the webhook payload was signed at.
+	WebhookTimestampHeader = "X-Grafana-Alerting-Timestamp"
+)
+
 // WebhookNotifier is responsible for sending
 // alert notifications as webhooks.
 type WebhookNotifier struct {
 	old_notifiers.NotifierBase
-	URL        string
-	User       string
-	Password   string
-	HTTPMethod string
-	MaxAlerts  int
-	log        log.Logger
-	tmpl       *template.Template
+	URL          string
+	User         string
+	Password     string
+	HTTPMethod   string
+	MaxAlerts    int
+	Headers      map[string]string
+	HMACSecret   string
+	MaxRetries   int
+	RetryBackoff time.Duration
+	log          log.Logger
+	tmpl         *template.Template
 }
 
 // NewWebHookNotifier is the constructor for
@@ -36,6 +57,22 @@ func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template)
 	if url == "" {
 		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
 	}
+	httpMethod := model.Settings.Get("httpMethod").MustString(http.MethodPost)
+	if httpMethod != http.MethodPost && httpMethod != http.MethodPut && httpMethod != http.MethodPatch {
+		return nil, alerting.ValidationError{Reason: "Invalid HTTP method, it must be POST, PUT or PATCH"}
+	}
+	headers, err := parseWebhookHeaders(model.Settings.Get("headers").MustString())
+	if err != nil {
+		return nil, alerting.ValidationError{Reason: err.Error()}
+	}
+	maxRetries := model.Settings.Get("maxRetries").MustInt(0)
+	if maxRetries < 0 {
+		return nil, alerting.ValidationError{Reason: "Max retries can't be negative"}
+	}
+	retryBackoff, err := time.ParseDuration(model.Settings.Get("retryBackoff").MustString("1s"))
+	if err != nil || retryBackoff <= 0 {
+		return nil, alerting.ValidationError{Reason: "Invalid retry backoff, it must be a positive duration, e.g. 1s"}
+	}
 	return &WebhookNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -44,16 +81,37 @@ func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template)
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		URL:        url,
-		User:       model.Settings.Get("username").MustString(),
-		Password:   model.DecryptedValue("password", model.Settings.Get("password").MustString()),
-		HTTPMethod: model.Settings.Get("httpMethod").MustString("POST"),
-		MaxAlerts:  model.Settings.Get("maxAlerts").MustInt(0),
-		log:        log.New("alerting.notifier.webhook"),
-		tmpl:       t,
+		URL:          url,
+		User:         model.Settings.Get("username").MustString(),
+		Password:     model.DecryptedValue("password", model.Settings.Get("password").MustString()),
+		HTTPMethod:   httpMethod,
+		MaxAlerts:    model.Settings.Get("maxAlerts").MustInt(0),
+		Headers:      headers,
+		HMACSecret:   model.DecryptedValue("hmacSecret", model.Settings.Get("hmacSecret").MustString()),
+		MaxRetries:   maxRetries,
+		RetryBackoff: retryBackoff,
+		log:          log.New("alerting.notifier.webhook"),
+		tmpl:         t,
 	}, nil
 }
 
+// parseWebhookHeaders parses the custom headers of the webhook, one "Name: value" header per line.
+func parseWebhookHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid header %q, headers must be in the Name: value format", line)
+		}
+		headers[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	return headers, nil
+}
+
 // webhookMessage defines the JSON object send to webhook endpoints.
 type webhookMessage struct {
 	*ExtendedData
@@ -110,13 +168,56 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		Password:   wn.Password,
 		Body:       string(body),
 		HttpMethod: wn.HTTPMethod,
+		HttpHeader: make(map[string]string, len(wn.Headers)+2),
+	}
+	for k, v := range wn.Headers {
+		cmd.HttpHeader[k] = v
 	}
 
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
-		return false, err
+	backoff := wn.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if wn.HMACSecret != "" {
+			// The payload is signed again for every attempt so that the timestamp is current.
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			cmd.HttpHeader[WebhookTimestampHeader] = timestamp
+			cmd.HttpHeader[WebhookSignatureHeader] = webhookSignature(wn.HMACSecret, timestamp, body)
+		}
+
+		err = bus.DispatchCtx(ctx, cmd)
+		if err == nil {
+			return true, nil
+		}
+		if attempt >= wn.MaxRetries || !isRetryableWebhookError(err) {
+			return false, err
+		}
+
+		wn.log.Debug("retrying webhook", "attempt", attempt+1, "backoff", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return false, err
+		}
+		backoff *= 2
 	}
+}
+
+// webhookSignature returns the hex encoded HMAC-SHA256 signature of the timestamp and of the body joined with a dot.
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
 
-	return true, nil
+// isRetryableWebhookError returns true if the webhook failed with a server error, because of rate limiting
+// or without response.
+func isRetryableWebhookError(err error) bool {
+	var statusErr *models.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode/100 == 5 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
 }
 
 func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
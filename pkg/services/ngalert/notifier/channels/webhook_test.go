@@ -3,8 +3,11 @@ package channels
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
@@ -224,3 +227,106 @@ func TestWebhookNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookNotifierDelivery(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert1"},
+		},
+	}
+	send := func(t *testing.T, settings string, handler func(webhook *models.SendWebhookSync) error) (bool, error) {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		pn, err := NewWebHookNotifier(&NotificationChannelConfig{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}, tmpl)
+		require.NoError(t, err)
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+			return handler(webhook)
+		})
+		return pn.Notify(notify.WithGroupKey(context.Background(), "alertname"), alert)
+	}
+
+	t.Run("custom headers and signature are sent", func(t *testing.T) {
+		var payload *models.SendWebhookSync
+		ok, err := send(t, `{
+			"url": "http://localhost/test",
+			"httpMethod": "PATCH",
+			"headers": "X-Tenant: team-a\nAuthorization: Bearer token",
+			"hmacSecret": "secret"
+		}`, func(webhook *models.SendWebhookSync) error {
+			payload = webhook
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, http.MethodPatch, payload.HttpMethod)
+		require.Equal(t, "team-a", payload.HttpHeader["X-Tenant"])
+		require.Equal(t, "Bearer token", payload.HttpHeader["Authorization"])
+
+		timestamp := payload.HttpHeader[WebhookTimestampHeader]
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), time.Unix(ts, 0), time.Minute)
+		require.Equal(t, webhookSignature("secret", timestamp, []byte(payload.Body)), payload.HttpHeader[WebhookSignatureHeader])
+	})
+
+	t.Run("server errors are retried with backoff", func(t *testing.T) {
+		attempts := 0
+		ok, err := send(t, `{"url": "http://localhost/test", "maxRetries": 2, "retryBackoff": "1ms"}`, func(webhook *models.SendWebhookSync) error {
+			attempts++
+			if attempts < 3 {
+				return &models.HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		attempts := 0
+		ok, err := send(t, `{"url": "http://localhost/test", "maxRetries": 1, "retryBackoff": "1ms"}`, func(webhook *models.SendWebhookSync) error {
+			attempts++
+			return &models.HTTPStatusError{StatusCode: http.StatusTooManyRequests}
+		})
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		attempts := 0
+		ok, err := send(t, `{"url": "http://localhost/test", "maxRetries": 3, "retryBackoff": "1ms"}`, func(webhook *models.SendWebhookSync) error {
+			attempts++
+			return &models.HTTPStatusError{StatusCode: http.StatusBadRequest}
+		})
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		for _, settings := range []string{
+			`{"url": "http://localhost/test", "httpMethod": "GET"}`,
+			`{"url": "http://localhost/test", "headers": "X-Tenant"}`,
+			`{"url": "http://localhost/test", "maxRetries": -1}`,
+			`{"url": "http://localhost/test", "retryBackoff": "soon"}`,
+		} {
+			settingsJSON, err := simplejson.NewJson([]byte(settings))
+			require.NoError(t, err)
+			_, err = NewWebHookNotifier(&NotificationChannelConfig{Name: "webhook_testing", Type: "webhook", Settings: settingsJSON}, tmpl)
+			require.Error(t, err, settings)
+		}
+	})
+}
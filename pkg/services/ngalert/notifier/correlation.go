@@ -0,0 +1,170 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// CorrelationKeyLabel is the label of the correlated alerts with their correlation key.
+	CorrelationKeyLabel = "correlation_key"
+	// CorrelatedAlertsAnnotation is the annotation of the correlated alerts listing their member alerts.
+	CorrelatedAlertsAnnotation = "correlated_alerts"
+	// CorrelatedAlertName is the name of the correlated alerts whose member alerts don't share their name.
+	CorrelatedAlertName = "CorrelatedAlerts"
+)
+
+// correlation is the set of the alerts with the same correlation key, notified as a single correlated alert.
+type correlation struct {
+	key     string
+	members map[model.Fingerprint]*types.Alert
+	// alert is the last correlated alert put for the members.
+	alert *types.Alert
+}
+
+// correlate replaces the alerts with a correlation key by the correlated alerts of their correlations. The alerts
+// of different organisations are never correlated. A correlated alert changes with its members: if its labels
+// change, the previous correlated alert is resolved so that it isn't left firing.
+func (am *Alertmanager) correlate(now time.Time, alerts []*types.Alert) []*types.Alert {
+	am.correlationsMtx.Lock()
+	defer am.correlationsMtx.Unlock()
+
+	result := make([]*types.Alert, 0, len(alerts))
+	changed := make([]string, 0)
+	for _, a := range alerts {
+		key, ok := a.Labels[ngmodels.CorrelationKeyLabel]
+		if !ok {
+			result = append(result, a)
+			continue
+		}
+		id := string(a.Annotations[ngmodels.OrgIDAnnotation]) + "/" + string(key)
+		c, ok := am.correlations[id]
+		if !ok {
+			c = &correlation{key: string(key), members: make(map[model.Fingerprint]*types.Alert)}
+			am.correlations[id] = c
+		}
+		if !containsString(changed, id) {
+			changed = append(changed, id)
+		}
+		c.members[a.Fingerprint()] = a
+	}
+
+	for _, id := range changed {
+		c := am.correlations[id]
+		alert := c.correlatedAlert(now)
+		if c.alert != nil && c.alert.Fingerprint() != alert.Fingerprint() && c.alert.EndsAt.After(now) {
+			previous := *c.alert
+			previous.EndsAt = now
+			previous.UpdatedAt = now
+			result = append(result, &previous)
+		}
+		c.alert = alert
+		result = append(result, alert)
+	}
+
+	// forget the correlations whose correlated alert is resolved
+	for id, c := range am.correlations {
+		if c.alert != nil && !c.alert.EndsAt.After(now) {
+			delete(am.correlations, id)
+		}
+	}
+	return result
+}
+
+// correlatedAlert returns the correlated alert of the members of the correlation. Its labels and annotations are
+// the ones shared by all its members, it fires as long as any of its members does and lists its members in the
+// correlated_alerts annotation.
+func (c *correlation) correlatedAlert(now time.Time) *types.Alert {
+	var (
+		labels, annotations model.LabelSet
+		startsAt, endsAt    time.Time
+		generatorURL        string
+		timeout             bool
+	)
+	members := make([]string, 0, len(c.members))
+	for _, m := range c.members {
+		if labels == nil {
+			labels, annotations = m.Labels.Clone(), m.Annotations.Clone()
+			startsAt, endsAt, generatorURL = m.StartsAt, m.EndsAt, m.GeneratorURL
+		} else {
+			labels, annotations = intersect(labels, m.Labels), intersect(annotations, m.Annotations)
+			if m.StartsAt.Before(startsAt) {
+				startsAt = m.StartsAt
+			}
+			if m.EndsAt.After(endsAt) {
+				endsAt = m.EndsAt
+			}
+			if m.GeneratorURL != generatorURL {
+				generatorURL = ""
+			}
+		}
+		if m.EndsAt.After(now) {
+			timeout = timeout || m.Timeout
+		}
+		members = append(members, describeMember(now, m))
+	}
+	sort.Strings(members)
+
+	delete(labels, ngmodels.CorrelationKeyLabel)
+	labels[CorrelationKeyLabel] = model.LabelValue(c.key)
+	if _, ok := labels[model.AlertNameLabel]; !ok {
+		labels[model.AlertNameLabel] = CorrelatedAlertName
+	}
+	annotations[CorrelatedAlertsAnnotation] = model.LabelValue(strings.Join(members, "\n"))
+
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels:       labels,
+			Annotations:  annotations,
+			StartsAt:     startsAt,
+			EndsAt:       endsAt,
+			GeneratorURL: generatorURL,
+		},
+		UpdatedAt: now,
+		Timeout:   timeout,
+	}
+}
+
+// describeMember returns the state, name and labels of a member alert, without the labels used internally.
+func describeMember(now time.Time, a *types.Alert) string {
+	state := "firing"
+	if !a.EndsAt.After(now) {
+		state = "resolved"
+	}
+	labels := make([]string, 0, len(a.Labels))
+	for k, v := range a.Labels {
+		if k == model.AlertNameLabel || strings.HasPrefix(string(k), "__") {
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%s=%q", k, v))
+	}
+	sort.Strings(labels)
+	return fmt.Sprintf("[%s] %s {%s}", state, a.Labels[model.AlertNameLabel], strings.Join(labels, ", "))
+}
+
+// intersect returns the labels of a with the same value in b.
+func intersect(a, b model.LabelSet) model.LabelSet {
+	result := make(model.LabelSet, len(a))
+	for k, v := range a {
+		if b[k] == v {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
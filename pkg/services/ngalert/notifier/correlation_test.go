@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestCorrelate(t *testing.T) {
+	now := time.Now()
+	newAlert := func(orgID, name, instance, key string, endsAt time.Time) *types.Alert {
+		a := &types.Alert{Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": model.LabelValue(name), "instance": model.LabelValue(instance), "cluster": "prod"},
+			Annotations: model.LabelSet{ngmodels.OrgIDAnnotation: model.LabelValue(orgID), "summary": model.LabelValue(name + " is down")},
+			StartsAt:    now.Add(-time.Minute),
+			EndsAt:      endsAt,
+		}}
+		if key != "" {
+			a.Labels[ngmodels.CorrelationKeyLabel] = model.LabelValue(key)
+		}
+		return a
+	}
+	firing, resolved := now.Add(time.Hour), now.Add(-time.Second)
+
+	t.Run("the alerts without a correlation key are kept", func(t *testing.T) {
+		am := &Alertmanager{correlations: make(map[string]*correlation)}
+		alerts := []*types.Alert{newAlert("1", "db", "a", "", firing)}
+		require.Equal(t, alerts, am.correlate(now, alerts))
+		require.Empty(t, am.correlations)
+	})
+
+	t.Run("the alerts with the same correlation key are replaced by a correlated alert", func(t *testing.T) {
+		am := &Alertmanager{correlations: make(map[string]*correlation)}
+		result := am.correlate(now, []*types.Alert{
+			newAlert("1", "db", "a", "prod", firing),
+			newAlert("1", "api", "b", "prod", resolved),
+			newAlert("2", "db", "a", "prod", firing),
+		})
+		require.Len(t, result, 2)
+
+		var correlated *types.Alert
+		for _, a := range result {
+			if a.Annotations[ngmodels.OrgIDAnnotation] == "1" {
+				correlated = a
+			}
+		}
+		require.NotNil(t, correlated)
+		require.Equal(t, model.LabelSet{"alertname": CorrelatedAlertName, "cluster": "prod", CorrelationKeyLabel: "prod"}, correlated.Labels)
+		require.Equal(t, model.LabelSet{
+			ngmodels.OrgIDAnnotation:   "1",
+			CorrelatedAlertsAnnotation: "[firing] db {cluster=\"prod\", instance=\"a\"}\n[resolved] api {cluster=\"prod\", instance=\"b\"}",
+		}, correlated.Annotations)
+		require.Equal(t, firing, correlated.EndsAt)
+	})
+
+	t.Run("the previous correlated alert is resolved when its labels change", func(t *testing.T) {
+		am := &Alertmanager{correlations: make(map[string]*correlation)}
+		first := am.correlate(now, []*types.Alert{newAlert("1", "db", "a", "prod", firing)})
+		require.Len(t, first, 1)
+		require.Equal(t, model.LabelValue("db"), first[0].Labels["alertname"])
+
+		result := am.correlate(now, []*types.Alert{newAlert("1", "api", "b", "prod", firing)})
+		require.Len(t, result, 2)
+		require.Equal(t, first[0].Fingerprint(), result[0].Fingerprint())
+		require.Equal(t, now, result[0].EndsAt)
+		require.Equal(t, model.LabelValue(CorrelatedAlertName), result[1].Labels["alertname"])
+	})
+
+	t.Run("the correlation is forgotten once all its alerts are resolved", func(t *testing.T) {
+		am := &Alertmanager{correlations: make(map[string]*correlation)}
+		am.correlate(now, []*types.Alert{newAlert("1", "db", "a", "prod", firing)})
+		require.Len(t, am.correlations, 1)
+		result := am.correlate(now, []*types.Alert{newAlert("1", "db", "a", "prod", resolved)})
+		require.Len(t, result, 1)
+		require.Equal(t, resolved, result[0].EndsAt)
+		require.Empty(t, am.correlations)
+	})
+}
@@ -0,0 +1,246 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+const (
+	// escalationIntegrationType is the type of the integration of the contact points which escalate
+	// their notifications with an escalation policy.
+	escalationIntegrationType = "escalation"
+	// escalationPollInterval is how often the escalations are checked for steps to notify.
+	escalationPollInterval = 10 * time.Second
+)
+
+// escalationNotifier starts the escalation of the notifications of an alert group with an escalation policy.
+// It doesn't send anything itself: the contact points of the steps of the policy are notified by the
+// Alertmanager until the escalation is acknowledged or the alerts are resolved.
+type escalationNotifier struct {
+	old_notifiers.NotifierBase
+	policyUID string
+	am        *Alertmanager
+}
+
+func (am *Alertmanager) newEscalationNotifier(cfg *channels.NotificationChannelConfig) (*escalationNotifier, error) {
+	policyUID := cfg.Settings.Get("policyUid").MustString()
+	if policyUID == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find policyUid property in settings"}
+	}
+	return &escalationNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   cfg.UID,
+			Name:                  cfg.Name,
+			Type:                  cfg.Type,
+			DisableResolveMessage: cfg.DisableResolveMessage,
+			Settings:              cfg.Settings,
+		}),
+		policyUID: policyUID,
+		am:        am,
+	}, nil
+}
+
+func (n *escalationNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return false, errors.New("group key missing")
+	}
+	return false, n.am.escalate(n.policyUID, groupKey, as, !n.GetDisableResolveMessage())
+}
+
+// test checks that the escalation policy exists instead of escalating a test notification, as nothing would
+// ever resolve it.
+func (n *escalationNotifier) test() error {
+	return n.am.Store.GetEscalationPolicy(&ngmodels.GetEscalationPolicyQuery{UID: n.policyUID})
+}
+
+// SendResolved is always true as the escalation of an alert group stops once its alerts are resolved,
+// disabling the resolve message only stops telling the notified contact points about it.
+func (n *escalationNotifier) SendResolved() bool {
+	return true
+}
+
+// escalate starts or updates the escalation of the alert group with the escalation policy. Once the alerts
+// are resolved the escalation is stopped, and the contact points notified so far are told if notifyResolved is true.
+func (am *Alertmanager) escalate(policyUID, groupKey string, as []*types.Alert, notifyResolved bool) error {
+	am.escalationsMtx.Lock()
+	defer am.escalationsMtx.Unlock()
+
+	if err := am.Store.GetEscalationPolicy(&ngmodels.GetEscalationPolicyQuery{UID: policyUID}); err != nil {
+		return fmt.Errorf("failed to get escalation policy %s: %w", policyUID, err)
+	}
+
+	resolved := types.Alerts(as...).Status() == model.AlertResolved
+	now := time.Now()
+
+	q := ngmodels.GetEscalationQuery{PolicyUID: policyUID, GroupKey: groupKey}
+	err := am.Store.GetEscalation(&q)
+	if err != nil && !errors.Is(err, ngmodels.ErrEscalationNotFound) {
+		return err
+	}
+	escalation := q.Result
+	if escalation == nil {
+		if resolved {
+			return nil
+		}
+		escalation = &ngmodels.Escalation{PolicyUID: policyUID, GroupKey: groupKey, Step: -1, NextStepAt: now.Unix()}
+	}
+
+	escalation.Alerts = make([]model.Alert, 0, len(as))
+	for _, a := range as {
+		escalation.Alerts = append(escalation.Alerts, a.Alert)
+	}
+
+	switch {
+	case resolved && (!notifyResolved || escalation.Step < 0):
+		return am.Store.DeleteEscalation(escalation.ID)
+	case resolved:
+		escalation.Resolved = true
+		escalation.NextStepAt = now.Unix()
+	case escalation.Resolved:
+		// the alerts fire again before the resolved notifications were sent, the escalation starts over
+		escalation.Resolved = false
+		escalation.Step = -1
+		escalation.NextStepAt = now.Unix()
+		escalation.AcknowledgedBy = ""
+		escalation.AcknowledgedAt = 0
+	}
+	if err := am.Store.SaveEscalation(escalation); err != nil {
+		return err
+	}
+
+	if escalation.NextStepAt != 0 && escalation.NextStepAt <= now.Unix() {
+		select {
+		case am.escalationsWakeCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// AcknowledgeEscalation stops the escalation at its current step.
+func (am *Alertmanager) AcknowledgeEscalation(id int64, login string) error {
+	am.escalationsMtx.Lock()
+	defer am.escalationsMtx.Unlock()
+
+	return am.Store.AcknowledgeEscalation(&ngmodels.AcknowledgeEscalationCommand{ID: id, Login: login, At: time.Now()})
+}
+
+// runEscalations notifies the due steps of the escalations until stopc is closed.
+func (am *Alertmanager) runEscalations(stopc <-chan struct{}) {
+	ticker := time.NewTicker(escalationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-am.escalationsWakeCh:
+		case <-stopc:
+			return
+		}
+		if err := am.processEscalations(time.Now()); err != nil {
+			am.logger.Error("failed to process escalations", "err", err)
+		}
+	}
+}
+
+// processEscalations notifies the contact point of the next step of the escalations which are due, or tells
+// the notified contact points that the alerts are resolved.
+func (am *Alertmanager) processEscalations(now time.Time) error {
+	// The integrations are taken before locking the escalations: a configuration change holds the configuration
+	// lock while waiting for the notifications in flight, which might be waiting for the escalations lock.
+	am.reloadConfigMtx.RLock()
+	integrationsMap := am.integrationsMap
+	am.reloadConfigMtx.RUnlock()
+
+	am.escalationsMtx.Lock()
+	defer am.escalationsMtx.Unlock()
+
+	q := ngmodels.ListEscalationsQuery{DueBefore: now}
+	if err := am.Store.ListEscalations(&q); err != nil {
+		return err
+	}
+
+	for _, escalation := range q.Result {
+		pq := ngmodels.GetEscalationPolicyQuery{UID: escalation.PolicyUID}
+		if err := am.Store.GetEscalationPolicy(&pq); err != nil {
+			if !errors.Is(err, ngmodels.ErrEscalationPolicyNotFound) {
+				am.logger.Error("failed to get escalation policy", "policy", escalation.PolicyUID, "err", err)
+				continue
+			}
+			am.logger.Warn("escalation policy no longer exists, removing its escalation", "policy", escalation.PolicyUID)
+			if err := am.Store.DeleteEscalation(escalation.ID); err != nil {
+				am.logger.Error("failed to delete escalation", "id", escalation.ID, "err", err)
+			}
+			continue
+		}
+		steps := pq.Result.Steps
+
+		if escalation.Resolved {
+			for i := 0; i <= escalation.Step && i < len(steps); i++ {
+				am.notifyEscalationStep(integrationsMap, steps[i].Receiver, escalation, now, true)
+			}
+			if err := am.Store.DeleteEscalation(escalation.ID); err != nil {
+				am.logger.Error("failed to delete escalation", "id", escalation.ID, "err", err)
+			}
+			continue
+		}
+
+		escalation.NextStepAt = 0
+		if escalation.Step+1 < len(steps) {
+			escalation.Step++
+			step := steps[escalation.Step]
+			am.notifyEscalationStep(integrationsMap, step.Receiver, escalation, now, false)
+			if escalation.Step+1 < len(steps) {
+				escalation.NextStepAt = now.Add(step.Wait).Unix()
+			}
+		}
+		if err := am.Store.SaveEscalation(escalation); err != nil {
+			am.logger.Error("failed to save escalation", "id", escalation.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// notifyEscalationStep sends the alerts of the escalation to the integrations of the contact point, except
+// to the ones escalating their notifications themselves. Only the resolved notifications are sent if resolved is true.
+func (am *Alertmanager) notifyEscalationStep(integrationsMap map[string][]notify.Integration, receiver string, escalation *ngmodels.Escalation, now time.Time, resolved bool) {
+	integrations, ok := integrationsMap[receiver]
+	if !ok {
+		am.logger.Error("contact point of escalation step not found", "policy", escalation.PolicyUID, "receiver", receiver)
+		return
+	}
+
+	alerts := make([]*types.Alert, 0, len(escalation.Alerts))
+	for _, a := range escalation.Alerts {
+		alerts = append(alerts, &types.Alert{Alert: a, UpdatedAt: now})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitFunc())
+	defer cancel()
+	ctx = notify.WithReceiverName(ctx, receiver)
+	ctx = notify.WithGroupKey(ctx, escalation.GroupKey)
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{})
+	ctx = notify.WithNow(ctx, now)
+
+	for i := range integrations {
+		if integrations[i].Name() == escalationIntegrationType || (resolved && !integrations[i].SendResolved()) {
+			continue
+		}
+		if _, err := integrations[i].Notify(ctx, alerts...); err != nil {
+			am.logger.Error("failed to notify escalation step", "policy", escalation.PolicyUID, "receiver", receiver, "integration", integrations[i].Name(), "err", err)
+		}
+	}
+}
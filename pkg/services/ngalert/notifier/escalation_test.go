@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEscalations(t *testing.T) {
+	am := setupAMTest(t)
+	// the escalations are processed by the test only
+	require.NoError(t, am.StopAndWait())
+
+	first, second := &fakeNotificationChannel{}, &fakeNotificationChannel{}
+	am.integrationsMap = map[string][]notify.Integration{
+		"first":  {notify.NewIntegration(first, first, "webhook", 0)},
+		"second": {notify.NewIntegration(second, second, "webhook", 0)},
+	}
+
+	policy := &ngmodels.EscalationPolicy{
+		Name: "on-call",
+		Steps: []ngmodels.EscalationStep{
+			{Receiver: "first", Wait: time.Minute},
+			{Receiver: "second"},
+		},
+	}
+	require.NoError(t, am.Store.CreateEscalationPolicy(policy))
+
+	firing := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}, StartsAt: time.Now()}}}
+	resolved := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}, StartsAt: time.Now(), EndsAt: time.Now().Add(-time.Second)}}}
+
+	getEscalation := func(groupKey string) *ngmodels.Escalation {
+		q := ngmodels.GetEscalationQuery{PolicyUID: policy.UID, GroupKey: groupKey}
+		require.NoError(t, am.Store.GetEscalation(&q))
+		return q.Result
+	}
+
+	t.Run("unknown policy returns an error", func(t *testing.T) {
+		require.ErrorIs(t, am.escalate("unknown", "group", firing, true), ngmodels.ErrEscalationPolicyNotFound)
+	})
+
+	t.Run("unacknowledged escalation notifies the next step after waiting", func(t *testing.T) {
+		first.notified, second.notified = nil, nil
+		now := time.Now()
+		require.NoError(t, am.escalate(policy.UID, "unacknowledged", firing, true))
+
+		require.NoError(t, am.processEscalations(now))
+		require.Len(t, first.notified, 1)
+		require.Len(t, second.notified, 0)
+		require.Equal(t, 0, getEscalation("unacknowledged").Step)
+
+		// the first step waits before the next step is notified
+		require.NoError(t, am.processEscalations(now.Add(30*time.Second)))
+		require.Len(t, second.notified, 0)
+
+		require.NoError(t, am.processEscalations(now.Add(2*time.Minute)))
+		require.Len(t, first.notified, 1)
+		require.Len(t, second.notified, 1)
+		e := getEscalation("unacknowledged")
+		require.Equal(t, 1, e.Step)
+		require.Zero(t, e.NextStepAt)
+
+		// the notified steps are told once the alerts are resolved
+		require.NoError(t, am.escalate(policy.UID, "unacknowledged", resolved, true))
+		require.NoError(t, am.processEscalations(now.Add(3*time.Minute)))
+		require.Len(t, first.notified, 2)
+		require.Len(t, second.notified, 2)
+		require.Equal(t, model.AlertResolved, types.Alerts(first.notified[1]...).Status())
+
+		err := am.Store.GetEscalation(&ngmodels.GetEscalationQuery{PolicyUID: policy.UID, GroupKey: "unacknowledged"})
+		require.ErrorIs(t, err, ngmodels.ErrEscalationNotFound)
+	})
+
+	t.Run("acknowledged escalation stops at its current step", func(t *testing.T) {
+		first.notified, second.notified = nil, nil
+		now := time.Now()
+		require.NoError(t, am.escalate(policy.UID, "acknowledged", firing, true))
+		require.NoError(t, am.processEscalations(now))
+		require.Len(t, first.notified, 1)
+
+		e := getEscalation("acknowledged")
+		require.NoError(t, am.AcknowledgeEscalation(e.ID, "admin"))
+		e = getEscalation("acknowledged")
+		require.True(t, e.Acknowledged())
+		require.Equal(t, "admin", e.AcknowledgedBy)
+
+		require.NoError(t, am.processEscalations(now.Add(2*time.Minute)))
+		require.Len(t, second.notified, 0)
+
+		// a new notification of the group doesn't restart an acknowledged escalation
+		require.NoError(t, am.escalate(policy.UID, "acknowledged", firing, true))
+		require.NoError(t, am.processEscalations(now.Add(4*time.Minute)))
+		require.Len(t, first.notified, 1)
+		require.Len(t, second.notified, 0)
+	})
+
+	t.Run("acknowledging an unknown escalation returns an error", func(t *testing.T) {
+		require.ErrorIs(t, am.AcknowledgeEscalation(-1, "admin"), ngmodels.ErrEscalationNotFound)
+	})
+
+	t.Run("resolved alerts without notified steps end the escalation", func(t *testing.T) {
+		first.notified, second.notified = nil, nil
+		require.NoError(t, am.escalate(policy.UID, "short", firing, true))
+		require.NoError(t, am.escalate(policy.UID, "short", resolved, true))
+		require.NoError(t, am.processEscalations(time.Now()))
+		require.Len(t, first.notified, 0)
+
+		err := am.Store.GetEscalation(&ngmodels.GetEscalationQuery{PolicyUID: policy.UID, GroupKey: "short"})
+		require.ErrorIs(t, err, ngmodels.ErrEscalationNotFound)
+	})
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// imageCacheTTL is for how long the image of a panel is reused, so that a panel is rendered
+	// only once for all the contact points and alert groups its alerts are sent to.
+	imageCacheTTL      = time.Minute
+	imageRenderTimeout = 30 * time.Second
+	imageWidth         = 1000
+	imageHeight        = 500
+)
+
+var (
+	ErrImageRendererNotAvailable = errors.New("no image renderer is available")
+	ErrImageRenderLimitReached   = errors.New("the limit of images rendered for notifications is reached")
+)
+
+// imageProvider takes the screenshots of the panels the alerts are linked to with the image renderer
+// and uploads them to the external image store. At most renderLimit images are rendered per minute,
+// the notifications are sent without image once the limit is reached.
+type imageProvider struct {
+	renderService rendering.Service
+	newUploader   func() (imguploader.ImageUploader, error)
+	limiter       *rate.Limiter
+	logger        log.Logger
+
+	renders singleflight.Group
+	mtx     sync.Mutex
+	cache   map[string]cachedImage
+}
+
+type cachedImage struct {
+	image   *channels.Image
+	expires time.Time
+}
+
+// NewImageProvider returns the provider of the panel images of the notifications rendered by the rendering service,
+// or nil if renderLimit isn't positive and the images are disabled.
+func NewImageProvider(renderService rendering.Service, renderLimit int) channels.ImageProvider {
+	if renderLimit <= 0 {
+		return nil
+	}
+	return &imageProvider{
+		renderService: renderService,
+		newUploader:   imguploader.NewImageUploader,
+		limiter:       rate.NewLimiter(rate.Every(time.Minute/time.Duration(renderLimit)), renderLimit),
+		logger:        log.New("ngalert.notifier.images"),
+		cache:         map[string]cachedImage{},
+	}
+}
+
+func (p *imageProvider) GetImage(ctx context.Context, alert *types.Alert) (*channels.Image, error) {
+	dashboardUID := string(alert.Annotations[ngmodels.DashboardUIDAnnotation])
+	panelID := string(alert.Annotations[ngmodels.PanelIDAnnotation])
+	if dashboardUID == "" || panelID == "" {
+		return nil, nil
+	}
+	orgID, err := strconv.ParseInt(string(alert.Annotations[ngmodels.OrgIDAnnotation]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid organisation of the alert: %w", err)
+	}
+	key := fmt.Sprintf("%d/%s/%s", orgID, dashboardUID, panelID)
+
+	now := time.Now()
+	p.mtx.Lock()
+	cached, ok := p.cache[key]
+	p.mtx.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.image, nil
+	}
+
+	// the alerts of a panel are usually notified at the same time, render it once for all of them
+	img, err, _ := p.renders.Do(key, func() (interface{}, error) {
+		img, err := p.render(ctx, orgID, dashboardUID, panelID)
+		if err != nil {
+			return nil, err
+		}
+
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+		for k, c := range p.cache {
+			if !now.Before(c.expires) {
+				delete(p.cache, k)
+			}
+		}
+		p.cache[key] = cachedImage{image: img, expires: time.Now().Add(imageCacheTTL)}
+		return img, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return img.(*channels.Image), nil
+}
+
+func (p *imageProvider) render(ctx context.Context, orgID int64, dashboardUID, panelID string) (*channels.Image, error) {
+	if !p.renderService.IsAvailable() {
+		return nil, ErrImageRendererNotAvailable
+	}
+	if !p.limiter.Allow() {
+		return nil, ErrImageRenderLimitReached
+	}
+
+	opts := rendering.Opts{
+		Width:           imageWidth,
+		Height:          imageHeight,
+		Timeout:         imageRenderTimeout,
+		OrgID:           orgID,
+		OrgRole:         models.ROLE_ADMIN,
+		Path:            fmt.Sprintf("d-solo/%s/_?orgId=%d&panelId=%s", dashboardUID, orgID, panelID),
+		ConcurrentLimit: setting.AlertingRenderLimit,
+	}
+	start := time.Now()
+	result, err := p.renderService.Render(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the panel: %w", err)
+	}
+	p.logger.Debug("rendered alert panel image", "path", opts.Path, "file", result.FilePath, "took", time.Since(start))
+
+	uploader, err := p.newUploader()
+	if err != nil {
+		return nil, err
+	}
+	url, err := uploader.Upload(ctx, result.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload the image to the external image store: %w", err)
+	}
+	return &channels.Image{Path: result.FilePath, URL: url}, nil
+}
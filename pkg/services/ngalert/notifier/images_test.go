@@ -0,0 +1,109 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+func TestImageProvider(t *testing.T) {
+	panelAlert := func(dashboardUID, panelID string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert"},
+			Annotations: model.LabelSet{
+				"__dashboardUid__": model.LabelValue(dashboardUID),
+				"__panelId__":      model.LabelValue(panelID),
+				"__orgId__":        "2",
+			},
+		}}
+	}
+
+	newProvider := func(renderLimit int) (*imageProvider, *fakeRenderService) {
+		renderer := &fakeRenderService{available: true}
+		p := NewImageProvider(renderer, renderLimit).(*imageProvider)
+		p.newUploader = func() (imguploader.ImageUploader, error) {
+			return fakeImageUploader{url: "https://images.example.com/"}, nil
+		}
+		return p, renderer
+	}
+
+	t.Run("renders and uploads the panel of the alert once", func(t *testing.T) {
+		p, renderer := newProvider(10)
+
+		for i := 0; i < 3; i++ {
+			img, err := p.GetImage(context.Background(), panelAlert("dashboard", "3"))
+			require.NoError(t, err)
+			require.Equal(t, &channels.Image{Path: "image.png", URL: "https://images.example.com/image.png"}, img)
+		}
+		require.Len(t, renderer.renders, 1)
+		require.Equal(t, int64(2), renderer.renders[0].OrgID)
+		require.Equal(t, "d-solo/dashboard/_?orgId=2&panelId=3", renderer.renders[0].Path)
+	})
+
+	t.Run("alerts not linked to a panel have no image", func(t *testing.T) {
+		p, renderer := newProvider(10)
+
+		img, err := p.GetImage(context.Background(), &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert"}}})
+		require.NoError(t, err)
+		require.Nil(t, img)
+		require.Empty(t, renderer.renders)
+	})
+
+	t.Run("no more panels are rendered once the limit is reached", func(t *testing.T) {
+		p, renderer := newProvider(2)
+
+		_, err := p.GetImage(context.Background(), panelAlert("dashboard", "1"))
+		require.NoError(t, err)
+		_, err = p.GetImage(context.Background(), panelAlert("dashboard", "2"))
+		require.NoError(t, err)
+		_, err = p.GetImage(context.Background(), panelAlert("dashboard", "3"))
+		require.ErrorIs(t, err, ErrImageRenderLimitReached)
+		require.Len(t, renderer.renders, 2)
+
+		// the cached images don't count against the limit
+		_, err = p.GetImage(context.Background(), panelAlert("dashboard", "1"))
+		require.NoError(t, err)
+	})
+
+	t.Run("no panel is rendered without image renderer", func(t *testing.T) {
+		p, renderer := newProvider(10)
+		renderer.available = false
+
+		_, err := p.GetImage(context.Background(), panelAlert("dashboard", "1"))
+		require.ErrorIs(t, err, ErrImageRendererNotAvailable)
+	})
+
+	t.Run("images are disabled without render limit", func(t *testing.T) {
+		require.Nil(t, NewImageProvider(&fakeRenderService{}, 0))
+	})
+}
+
+type fakeRenderService struct {
+	rendering.Service
+	available bool
+	renders   []rendering.Opts
+}
+
+func (s *fakeRenderService) IsAvailable() bool {
+	return s.available
+}
+
+func (s *fakeRenderService) Render(_ context.Context, opts rendering.Opts) (*rendering.RenderResult, error) {
+	s.renders = append(s.renders, opts)
+	return &rendering.RenderResult{FilePath: "image.png"}, nil
+}
+
+type fakeImageUploader struct {
+	url string
+}
+
+func (u fakeImageUploader) Upload(_ context.Context, path string) (string, error) {
+	return u.url + path, nil
+}
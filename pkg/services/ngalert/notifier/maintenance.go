@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// maintenanceStore is the part of the store used by the maintenance stage.
+type maintenanceStore interface {
+	store.MaintenanceStore
+	store.NotificationLogStore
+}
+
+// maintenanceStage removes the alerts of the organisations in maintenance which their maintenance applies to
+// before they're notified to the integrations of a contact point. The alerts are kept by the dispatcher, so they're
+// notified by the first flush of their group after the maintenance. The suppressed notifications are recorded
+// in the notification log for each integration of the contact point.
+type maintenanceStage struct {
+	receiver *apimodels.PostableApiReceiver
+	store    maintenanceStore
+	metrics  *metrics.Metrics
+	logger   log.Logger
+}
+
+func (s *maintenanceStage) Exec(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	now, ok := notify.Now(ctx)
+	if !ok {
+		now = time.Now()
+	}
+
+	// the maintenances of the organisations of the alerts, nil for the organisations without one
+	maintenances := make(map[int64]*ngmodels.Maintenance)
+	kept := make([]*types.Alert, 0, len(alerts))
+	suppressed := make([]model.Alert, 0)
+	for _, a := range alerts {
+		orgID, err := strconv.ParseInt(string(a.Annotations[ngmodels.OrgIDAnnotation]), 10, 64)
+		if err != nil {
+			kept = append(kept, a)
+			continue
+		}
+
+		m, ok := maintenances[orgID]
+		if !ok {
+			q := ngmodels.GetMaintenanceQuery{OrgID: orgID}
+			if err := s.store.GetMaintenance(&q); err != nil && !errors.Is(err, ngmodels.ErrMaintenanceNotFound) {
+				// notifying during a maintenance is better than missing a notification
+				s.logger.Error("failed to get maintenance", "org", orgID, "err", err)
+			}
+			m = q.Result
+			maintenances[orgID] = m
+		}
+
+		if m != nil && m.Matches(now, a.Labels) {
+			suppressed = append(suppressed, a.Alert)
+			s.metrics.MaintenanceSuppressedAlerts.WithLabelValues(strconv.FormatInt(orgID, 10)).Inc()
+			continue
+		}
+		kept = append(kept, a)
+	}
+
+	if len(suppressed) > 0 {
+		s.recordSuppressed(ctx, now, suppressed)
+	}
+	return ctx, kept, nil
+}
+
+// recordSuppressed records the suppressed notification of the alerts for each integration of the contact point.
+func (s *maintenanceStage) recordSuppressed(ctx context.Context, now time.Time, alerts []model.Alert) {
+	groupKey, _ := notify.GroupKey(ctx)
+	for i, r := range s.receiver.GrafanaManagedReceivers {
+		attempt := &ngmodels.NotificationAttempt{
+			Receiver:         s.receiver.Name,
+			IntegrationType:  r.Type,
+			IntegrationIndex: i,
+			IntegrationUID:   r.UID,
+			GroupKey:         groupKey,
+			Alerts:           alerts,
+			Suppressed:       ngmodels.NotificationSuppressedByMaintenance,
+			CreatedAt:        now.Unix(),
+		}
+		if err := s.store.SaveNotificationAttempt(attempt); err != nil {
+			s.logger.Error("failed to save notification attempt", "receiver", s.receiver.Name, "integration", r.Type, "err", err)
+		}
+	}
+}
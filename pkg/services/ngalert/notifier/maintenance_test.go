@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestMaintenanceStage(t *testing.T) {
+	am := setupAMTest(t)
+	stage := &maintenanceStage{
+		receiver: &apimodels.PostableApiReceiver{
+			Receiver: config.Receiver{Name: "on-call"},
+			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+				GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{{UID: "webhook-uid", Type: "webhook"}, {UID: "email-uid", Type: "email"}},
+			},
+		},
+		store:   am.Store,
+		metrics: am.Metrics,
+		logger:  log.New("test"),
+	}
+	now := time.Now()
+	ctx := notify.WithNow(notify.WithGroupKey(context.Background(), "test-group"), now)
+	newAlert := func(orgID string, severity string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "test", "severity": model.LabelValue(severity)},
+			Annotations: model.LabelSet{ngmodels.OrgIDAnnotation: model.LabelValue(orgID)},
+		}}
+	}
+	suppressedAttempts := func() []*ngmodels.NotificationAttempt {
+		q := &ngmodels.ListNotificationAttemptsQuery{Receiver: "on-call"}
+		require.NoError(t, am.Store.ListNotificationAttempts(q))
+		attempts := make([]*ngmodels.NotificationAttempt, 0)
+		for _, a := range q.Result {
+			if a.Suppressed != "" {
+				attempts = append(attempts, a)
+			}
+		}
+		return attempts
+	}
+
+	t.Run("the alerts of the organisations without maintenance are kept", func(t *testing.T) {
+		alerts := []*types.Alert{newAlert("1", "critical"), {Alert: model.Alert{Labels: model.LabelSet{"alertname": "no-org"}}}}
+		_, kept, err := stage.Exec(ctx, nil, alerts...)
+		require.NoError(t, err)
+		require.Equal(t, alerts, kept)
+		require.Empty(t, suppressedAttempts())
+	})
+
+	t.Run("the alerts matching the maintenance of their organisation are suppressed for each integration", func(t *testing.T) {
+		require.NoError(t, am.Store.SaveMaintenance(&ngmodels.Maintenance{OrgID: 1, Matchers: []string{`severity="warning"`}}))
+		critical, warning, otherOrg := newAlert("1", "critical"), newAlert("1", "warning"), newAlert("2", "warning")
+		_, kept, err := stage.Exec(ctx, nil, critical, warning, otherOrg)
+		require.NoError(t, err)
+		require.Equal(t, []*types.Alert{critical, otherOrg}, kept)
+
+		attempts := suppressedAttempts()
+		require.Len(t, attempts, 2)
+		for _, a := range attempts {
+			require.False(t, a.Succeeded())
+			require.Equal(t, ngmodels.NotificationSuppressedByMaintenance, a.Suppressed)
+			require.Equal(t, "test-group", a.GroupKey)
+			require.Equal(t, []model.Alert{warning.Alert}, a.Alerts)
+		}
+		require.ElementsMatch(t, []string{"webhook-uid", "email-uid"}, []string{attempts[0].IntegrationUID, attempts[1].IntegrationUID})
+	})
+
+	t.Run("the alerts are kept once the maintenance ended", func(t *testing.T) {
+		require.NoError(t, am.Store.SaveMaintenance(&ngmodels.Maintenance{OrgID: 1, EndsAt: now.Add(-time.Minute).Unix()}))
+		alerts := []*types.Alert{newAlert("1", "warning")}
+		_, kept, err := stage.Exec(ctx, nil, alerts...)
+		require.NoError(t, err)
+		require.Equal(t, alerts, kept)
+	})
+}
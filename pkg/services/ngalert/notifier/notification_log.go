@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+var (
+	ErrNotificationIntegrationNotFound = errors.New("the integration of the notification no longer exists")
+)
+
+// recordingNotifier records every attempt of the wrapped notification channel to deliver a notification
+// in the notification log.
+type recordingNotifier struct {
+	NotificationChannel
+
+	receiver         string
+	integrationType  string
+	integrationIndex int
+	integrationUID   string
+
+	store  store.NotificationLogStore
+	logger log.Logger
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	start := time.Now()
+	retry, err := n.NotificationChannel.Notify(ctx, as...)
+
+	attempt := &ngmodels.NotificationAttempt{
+		Receiver:         n.receiver,
+		IntegrationType:  n.integrationType,
+		IntegrationIndex: n.integrationIndex,
+		IntegrationUID:   n.integrationUID,
+		Alerts:           make([]model.Alert, 0, len(as)),
+		DurationMs:       time.Since(start).Milliseconds(),
+		CreatedAt:        start.Unix(),
+	}
+	attempt.GroupKey, _ = notify.GroupKey(ctx)
+	for _, a := range as {
+		attempt.Alerts = append(attempt.Alerts, a.Alert)
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		var statusErr *models.HTTPStatusError
+		if errors.As(err, &statusErr) {
+			attempt.StatusCode = statusErr.StatusCode
+		}
+	}
+
+	if saveErr := n.store.SaveNotificationAttempt(attempt); saveErr != nil {
+		n.logger.Error("failed to save notification attempt", "receiver", n.receiver, "integration", n.integrationType, "err", saveErr)
+	}
+
+	return retry, err
+}
+
+// ResendNotification sends the alerts of a notification attempt again through the same integration
+// of the current configuration. The new attempt is recorded in the notification log as well.
+func (am *Alertmanager) ResendNotification(ctx context.Context, id int64) error {
+	q := ngmodels.GetNotificationAttemptQuery{ID: id}
+	if err := am.Store.GetNotificationAttempt(&q); err != nil {
+		return err
+	}
+	attempt := q.Result
+
+	am.reloadConfigMtx.RLock()
+	integrations := am.integrationsMap[attempt.Receiver]
+	am.reloadConfigMtx.RUnlock()
+
+	var integration *notify.Integration
+	for i := range integrations {
+		if integrations[i].Index() == attempt.IntegrationIndex && integrations[i].Name() == attempt.IntegrationType {
+			integration = &integrations[i]
+			break
+		}
+	}
+	if integration == nil {
+		return fmt.Errorf("%w: %s[%d] of contact point %s", ErrNotificationIntegrationNotFound, attempt.IntegrationType, attempt.IntegrationIndex, attempt.Receiver)
+	}
+
+	now := time.Now()
+	alerts := make([]*types.Alert, 0, len(attempt.Alerts))
+	for _, a := range attempt.Alerts {
+		alerts = append(alerts, &types.Alert{Alert: a, UpdatedAt: now})
+	}
+
+	ctx = notify.WithReceiverName(ctx, attempt.Receiver)
+	ctx = notify.WithGroupKey(ctx, attempt.GroupKey)
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{})
+	ctx = notify.WithNow(ctx, now)
+
+	_, err := integration.Notify(ctx, alerts...)
+	return err
+}
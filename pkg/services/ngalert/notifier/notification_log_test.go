@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeNotificationChannel struct {
+	err      error
+	notified [][]*types.Alert
+}
+
+func (f *fakeNotificationChannel) Notify(_ context.Context, as ...*types.Alert) (bool, error) {
+	f.notified = append(f.notified, as)
+	return false, f.err
+}
+
+func (f *fakeNotificationChannel) SendResolved() bool {
+	return true
+}
+
+func TestRecordingNotifier(t *testing.T) {
+	am := setupAMTest(t)
+	ctx := notify.WithGroupKey(context.Background(), "test-group")
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+
+	t.Run("successful attempt is recorded", func(t *testing.T) {
+		recorder := &recordingNotifier{
+			NotificationChannel: &fakeNotificationChannel{},
+			receiver:            "ok-receiver",
+			integrationType:     "webhook",
+			store:               am.Store,
+			logger:              log.New("test"),
+		}
+		_, err := recorder.Notify(ctx, alert)
+		require.NoError(t, err)
+
+		q := &ngmodels.ListNotificationAttemptsQuery{Receiver: "ok-receiver"}
+		require.NoError(t, am.Store.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 1)
+		require.True(t, q.Result[0].Succeeded())
+		require.Equal(t, "test-group", q.Result[0].GroupKey)
+		require.Equal(t, []model.Alert{alert.Alert}, q.Result[0].Alerts)
+	})
+
+	t.Run("failed attempt is recorded with its status code", func(t *testing.T) {
+		recorder := &recordingNotifier{
+			NotificationChannel: &fakeNotificationChannel{
+				err: &models.HTTPStatusError{StatusCode: 503, Message: "failed to send HTTP request - status code 503"},
+			},
+			receiver:        "failing-receiver",
+			integrationType: "webhook",
+			store:           am.Store,
+			logger:          log.New("test"),
+		}
+		_, err := recorder.Notify(ctx, alert)
+		require.Error(t, err)
+
+		q := &ngmodels.ListNotificationAttemptsQuery{Receiver: "failing-receiver"}
+		require.NoError(t, am.Store.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 1)
+		require.False(t, q.Result[0].Succeeded())
+		require.Equal(t, 503, q.Result[0].StatusCode)
+		require.Equal(t, "failed to send HTTP request - status code 503", q.Result[0].Error)
+	})
+}
+
+func TestResendNotification(t *testing.T) {
+	am := setupAMTest(t)
+	attempt := &ngmodels.NotificationAttempt{
+		Receiver:        "receiver",
+		IntegrationType: "webhook",
+		GroupKey:        "test-group",
+		Alerts:          []model.Alert{{Labels: model.LabelSet{"alertname": "test"}}},
+		Error:           "failed",
+	}
+	require.NoError(t, am.Store.SaveNotificationAttempt(attempt))
+
+	t.Run("unknown integration returns ErrNotificationIntegrationNotFound", func(t *testing.T) {
+		err := am.ResendNotification(context.Background(), attempt.ID)
+		require.True(t, errors.Is(err, ErrNotificationIntegrationNotFound))
+	})
+
+	t.Run("alerts are sent again through the integration", func(t *testing.T) {
+		channel := &fakeNotificationChannel{}
+		am.integrationsMap = map[string][]notify.Integration{
+			"receiver": {notify.NewIntegration(channel, channel, "webhook", 0)},
+		}
+		require.NoError(t, am.ResendNotification(context.Background(), attempt.ID))
+		require.Len(t, channel.notified, 1)
+		require.Equal(t, attempt.Alerts[0], channel.notified[0][0].Alert)
+	})
+}
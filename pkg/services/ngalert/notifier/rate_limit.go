@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+// rateLimiter limits the notifications of a contact point or of the alert groups of a route.
+type rateLimiter struct {
+	name     string
+	receiver string
+	routeKey string
+	overflow apimodels.RateLimitOverflow
+	limiter  *rate.Limiter
+}
+
+func newRateLimiters(cfg []apimodels.RateLimit) []*rateLimiter {
+	limiters := make([]*rateLimiter, 0, len(cfg))
+	for _, rl := range cfg {
+		overflow := rl.Overflow
+		if overflow == "" {
+			overflow = apimodels.RateLimitDrop
+		}
+		interval := time.Duration(rl.Interval) / time.Duration(rl.MaxNotifications)
+		limiters = append(limiters, &rateLimiter{
+			name:     rl.Name,
+			receiver: rl.Receiver,
+			routeKey: rl.Route,
+			overflow: overflow,
+			limiter:  rate.NewLimiter(rate.Every(interval), rl.MaxNotifications),
+		})
+	}
+	return limiters
+}
+
+// receiverRateLimiters returns the rate limiters which can limit the notifications of the contact point:
+// its own rate limiters and the rate limiters of the routes.
+func receiverRateLimiters(limiters []*rateLimiter, receiver string) []*rateLimiter {
+	result := make([]*rateLimiter, 0)
+	for _, l := range limiters {
+		if l.routeKey != "" || l.receiver == receiver {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// limits returns true if the rate limiter limits the notifications of the alert group.
+// The key of an alert group is the key of its route followed by its labels.
+func (l *rateLimiter) limits(groupKey string) bool {
+	return l.routeKey == "" || strings.HasPrefix(groupKey, l.routeKey+":")
+}
+
+// rateLimitStage drops or delays the notifications to an integration over the rate limits of its contact point
+// and of the route of their alert group. It runs after the deduplication of the notifications, so that only the
+// notifications which would be sent count, and the dropped notifications aren't recorded as sent: they're sent
+// again by the next notification of their alert group if their alerts are still firing.
+type rateLimitStage struct {
+	limiters    []*rateLimiter
+	receiver    string
+	integration string
+	metrics     *metrics.Metrics
+	logger      log.Logger
+}
+
+func (s *rateLimitStage) Exec(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	groupKey, _ := notify.GroupKey(ctx)
+	now := time.Now()
+
+	var (
+		reservations []*rate.Reservation
+		delay        time.Duration
+		delayedBy    *rateLimiter
+		droppedBy    *rateLimiter
+	)
+	for _, l := range s.limiters {
+		if !l.limits(groupKey) {
+			continue
+		}
+		r := l.limiter.ReserveN(now, 1)
+		if !r.OK() {
+			droppedBy = l
+			break
+		}
+		reservations = append(reservations, r)
+		d := r.DelayFrom(now)
+		if d == 0 {
+			continue
+		}
+		if deadline, ok := ctx.Deadline(); l.overflow == apimodels.RateLimitDrop || (ok && now.Add(d).After(deadline)) {
+			droppedBy = l
+			break
+		}
+		if d > delay {
+			delay, delayedBy = d, l
+		}
+	}
+
+	if droppedBy == nil && delayedBy != nil {
+		s.metrics.RateLimitedNotifications.WithLabelValues(delayedBy.name, "queued").Inc()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return ctx, alerts, nil
+		case <-ctx.Done():
+			droppedBy = delayedBy
+		}
+	}
+	if droppedBy == nil {
+		return ctx, alerts, nil
+	}
+
+	// the notification isn't sent, give its reservations back to the other rate limiters
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+	s.metrics.RateLimitedNotifications.WithLabelValues(droppedBy.name, "dropped").Inc()
+	s.logger.Warn("notification dropped by rate limit", "rateLimit", droppedBy.name, "receiver", s.receiver, "integration", s.integration, "groupKey", groupKey)
+	return ctx, nil, nil
+}
@@ -0,0 +1,76 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+func TestRateLimitStage(t *testing.T) {
+	alerts := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}}
+	newStage := func(limits ...apimodels.RateLimit) *rateLimitStage {
+		return &rateLimitStage{
+			limiters:    receiverRateLimiters(newRateLimiters(limits), "pager"),
+			receiver:    "pager",
+			integration: "webhook",
+			metrics:     metrics.NewMetrics(prometheus.NewRegistry()),
+			logger:      log.New("test"),
+		}
+	}
+	exec := func(s *rateLimitStage, groupKey string) []*types.Alert {
+		ctx, cancel := context.WithTimeout(notify.WithGroupKey(context.Background(), groupKey), time.Second)
+		defer cancel()
+		_, kept, err := s.Exec(ctx, nil, alerts...)
+		require.NoError(t, err)
+		return kept
+	}
+
+	t.Run("the notifications over the limit of the contact point are dropped", func(t *testing.T) {
+		s := newStage(
+			apimodels.RateLimit{Name: "pager", Receiver: "pager", MaxNotifications: 2, Interval: model.Duration(time.Hour)},
+			apimodels.RateLimit{Name: "other", Receiver: "other", MaxNotifications: 1, Interval: model.Duration(time.Hour)},
+		)
+		require.Len(t, s.limiters, 1)
+		require.Equal(t, alerts, exec(s, "{}:{}"))
+		require.Equal(t, alerts, exec(s, "{}:{}"))
+		require.Empty(t, exec(s, "{}:{}"))
+		require.Equal(t, float64(1), testutil.ToFloat64(s.metrics.RateLimitedNotifications.WithLabelValues("pager", "dropped")))
+	})
+
+	t.Run("only the alert groups of the route are limited", func(t *testing.T) {
+		s := newStage(apimodels.RateLimit{Name: "ops", Route: `{}/{team="ops"}`, MaxNotifications: 1, Interval: model.Duration(time.Hour)})
+		require.Equal(t, alerts, exec(s, `{}/{team="ops"}:{alertname="test"}`))
+		require.Empty(t, exec(s, `{}/{team="ops"}:{alertname="other"}`))
+		require.Equal(t, alerts, exec(s, `{}/{team="ops"}/{severity="critical"}:{alertname="test"}`))
+		require.Equal(t, alerts, exec(s, `{}:{alertname="test"}`))
+	})
+
+	t.Run("the notifications over the limit are queued until the limit allows them", func(t *testing.T) {
+		s := newStage(apimodels.RateLimit{Name: "pager", Receiver: "pager", MaxNotifications: 10, Interval: model.Duration(time.Second), Overflow: apimodels.RateLimitQueue})
+		for i := 0; i < 10; i++ {
+			require.Equal(t, alerts, exec(s, "{}:{}"))
+		}
+		start := time.Now()
+		require.Equal(t, alerts, exec(s, "{}:{}"))
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		require.Equal(t, float64(1), testutil.ToFloat64(s.metrics.RateLimitedNotifications.WithLabelValues("pager", "queued")))
+	})
+
+	t.Run("the queued notifications are dropped if they can't be sent before the timeout", func(t *testing.T) {
+		s := newStage(apimodels.RateLimit{Name: "pager", Receiver: "pager", MaxNotifications: 1, Interval: model.Duration(time.Hour), Overflow: apimodels.RateLimitQueue})
+		require.Equal(t, alerts, exec(s, "{}:{}"))
+		require.Empty(t, exec(s, "{}:{}"))
+		require.Equal(t, float64(1), testutil.ToFloat64(s.metrics.RateLimitedNotifications.WithLabelValues("pager", "dropped")))
+	})
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	// testReceiversTimeout is how long the integrations have to deliver the test alert.
+	testReceiversTimeout = 15 * time.Second
+
+	// TestReceiverStatusOK and TestReceiverStatusFailed are the statuses of the tested integrations.
+	TestReceiverStatusOK     = "ok"
+	TestReceiverStatusFailed = "failed"
+)
+
+var (
+	ErrInvalidReceiver = errors.New("invalid receiver")
+	ErrNoConfiguration = errors.New("no configuration has been applied yet")
+)
+
+// TestReceivers sends a test alert through the Grafana managed integrations of the given receivers,
+// the receivers do not need to be part of the current configuration. Secure settings must already be encrypted.
+// The labels and annotations of c.Alert are added to the ones of the test alert.
+func (am *Alertmanager) TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*apimodels.TestReceiversResult, error) {
+	am.reloadConfigMtx.RLock()
+	tmpl := am.template
+	am.reloadConfigMtx.RUnlock()
+	if tmpl == nil {
+		return nil, ErrNoConfiguration
+	}
+
+	type testIntegration struct {
+		receiver    int
+		config      int
+		integration notify.Integration
+		notifier    NotificationChannel
+	}
+
+	result := &apimodels.TestReceiversResult{
+		Alert:      newTestAlertParams(c.Alert),
+		Receivers:  make([]apimodels.TestReceiverResult, 0, len(c.Receivers)),
+		NotifiedAt: time.Now(),
+	}
+	var integrations []testIntegration
+	for i, receiver := range c.Receivers {
+		receiverResult := apimodels.TestReceiverResult{
+			Name:    receiver.Name,
+			Configs: make([]apimodels.TestReceiverConfigResult, 0, len(receiver.GrafanaManagedReceivers)),
+		}
+		for j, r := range receiver.GrafanaManagedReceivers {
+			n, err := am.buildReceiverIntegration(r, tmpl)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s[%d] of %s: %s", ErrInvalidReceiver, r.Type, j, receiver.Name, err)
+			}
+			integrations = append(integrations, testIntegration{
+				receiver:    i,
+				config:      j,
+				integration: notify.NewIntegration(n, n, r.Type, j),
+				notifier:    n,
+			})
+			receiverResult.Configs = append(receiverResult.Configs, apimodels.TestReceiverConfigResult{
+				Name: r.Name,
+				UID:  r.UID,
+			})
+		}
+		result.Receivers = append(result.Receivers, receiverResult)
+	}
+
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:      result.Alert.Labels,
+			Annotations: result.Alert.Annotations,
+			StartsAt:    result.NotifiedAt,
+		},
+		UpdatedAt: result.NotifiedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, testReceiversTimeout)
+	defer cancel()
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-%s-%d", alert.Labels.Fingerprint(), "test", result.NotifiedAt.Unix()))
+	ctx = notify.WithGroupLabels(ctx, alert.Labels)
+	ctx = notify.WithNow(ctx, result.NotifiedAt)
+
+	var wg sync.WaitGroup
+	for _, ti := range integrations {
+		wg.Add(1)
+		go func(ti testIntegration) {
+			defer wg.Done()
+			receiverCtx := notify.WithReceiverName(ctx, c.Receivers[ti.receiver].Name)
+			// Every integration writes to a distinct result, no locking is needed.
+			configResult := &result.Receivers[ti.receiver].Configs[ti.config]
+			var err error
+			if en, ok := ti.notifier.(*escalationNotifier); ok {
+				err = en.test()
+			} else {
+				_, err = ti.integration.Notify(receiverCtx, alert)
+			}
+			if err != nil {
+				configResult.Status = TestReceiverStatusFailed
+				configResult.Error = err.Error()
+				return
+			}
+			configResult.Status = TestReceiverStatusOK
+		}(ti)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+func newTestAlertParams(params *apimodels.TestReceiversConfigAlertParams) apimodels.TestReceiversConfigAlertParams {
+	alert := apimodels.TestReceiversConfigAlertParams{
+		Labels: model.LabelSet{
+			model.AlertNameLabel: "TestAlert",
+			"instance":           "Grafana",
+		},
+		Annotations: model.LabelSet{
+			"summary": "Notification test",
+		},
+	}
+	if params == nil {
+		return alert
+	}
+	for k, v := range params.Labels {
+		alert.Labels[k] = v
+	}
+	for k, v := range params.Annotations {
+		alert.Annotations[k] = v
+	}
+	return alert
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	ErrInvalidTestRouteLabels = errors.New("invalid labels")
+)
+
+// TestRoutes walks the notification policy tree of the current configuration with the given labels
+// and returns the routes an alert with these labels would be sent through, in the order they are matched.
+func (am *Alertmanager) TestRoutes(c apimodels.TestRoutesConfigBodyParams) (*apimodels.TestRoutesResult, error) {
+	if err := c.Labels.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTestRouteLabels, err)
+	}
+
+	am.reloadConfigMtx.RLock()
+	routes := am.route.Match(c.Labels)
+	am.reloadConfigMtx.RUnlock()
+
+	result := &apimodels.TestRoutesResult{
+		Labels: c.Labels,
+		Routes: make([]apimodels.TestRouteResult, 0, len(routes)),
+	}
+	for _, r := range routes {
+		result.Routes = append(result.Routes, testRouteResult(r))
+	}
+	return result, nil
+}
+
+func testRouteResult(r *dispatch.Route) apimodels.TestRouteResult {
+	// The labels inherited from the parents are ignored when grouping by all labels.
+	groupBy := []string{"..."}
+	if !r.RouteOpts.GroupByAll {
+		groupBy = make([]string, 0, len(r.RouteOpts.GroupBy))
+		for l := range r.RouteOpts.GroupBy {
+			groupBy = append(groupBy, string(l))
+		}
+		sort.Strings(groupBy)
+	}
+
+	muteTimeIntervals := r.RouteOpts.MuteTimeIntervals
+	if muteTimeIntervals == nil {
+		muteTimeIntervals = []string{}
+	}
+
+	return apimodels.TestRouteResult{
+		Key:               r.Key(),
+		Receiver:          r.RouteOpts.Receiver,
+		GroupBy:           groupBy,
+		GroupWait:         model.Duration(r.RouteOpts.GroupWait),
+		GroupInterval:     model.Duration(r.RouteOpts.GroupInterval),
+		RepeatInterval:    model.Duration(r.RouteOpts.RepeatInterval),
+		MuteTimeIntervals: muteTimeIntervals,
+		Continue:          r.Continue,
+	}
+}
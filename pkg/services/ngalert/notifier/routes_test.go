@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestTestRoutes(t *testing.T) {
+	am := setupAMTest(t)
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "default",
+				"group_by": ["alertname"],
+				"routes": [{
+					"receiver": "team-a",
+					"match": {"team": "a"},
+					"group_wait": "10s",
+					"continue": true
+				}, {
+					"receiver": "team-a-b",
+					"match_re": {"team": "a|b"},
+					"group_by": ["..."],
+					"repeat_interval": "1h"
+				}]
+			},
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": [{"name": "default", "type": "webhook", "settings": {"url": "http://localhost"}}]},
+				{"name": "team-a", "grafana_managed_receiver_configs": [{"name": "team-a", "type": "webhook", "settings": {"url": "http://localhost"}}]},
+				{"name": "team-a-b", "grafana_managed_receiver_configs": [{"name": "team-a-b", "type": "webhook", "settings": {"url": "http://localhost"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+
+	t.Run("matches the routes in order and inherits the settings of the parents", func(t *testing.T) {
+		result, err := am.TestRoutes(apimodels.TestRoutesConfigBodyParams{Labels: model.LabelSet{"team": "a"}})
+		require.NoError(t, err)
+		require.Equal(t, model.LabelSet{"team": "a"}, result.Labels)
+		require.Equal(t, []apimodels.TestRouteResult{
+			{
+				Key:               `{}/{team="a"}`,
+				Receiver:          "team-a",
+				GroupBy:           []string{"alertname"},
+				GroupWait:         model.Duration(10 * time.Second),
+				GroupInterval:     model.Duration(5 * time.Minute),
+				RepeatInterval:    model.Duration(4 * time.Hour),
+				MuteTimeIntervals: []string{},
+				Continue:          true,
+			}, {
+				Key:               `{}/{team=~"^(?:a|b)$"}`,
+				Receiver:          "team-a-b",
+				GroupBy:           []string{"..."},
+				GroupWait:         model.Duration(30 * time.Second),
+				GroupInterval:     model.Duration(5 * time.Minute),
+				RepeatInterval:    model.Duration(time.Hour),
+				MuteTimeIntervals: []string{},
+			},
+		}, result.Routes)
+	})
+
+	t.Run("falls back to the root route", func(t *testing.T) {
+		result, err := am.TestRoutes(apimodels.TestRoutesConfigBodyParams{Labels: model.LabelSet{"team": "c"}})
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		require.Equal(t, "default", result.Routes[0].Receiver)
+		require.Equal(t, "{}", result.Routes[0].Key)
+	})
+
+	t.Run("fails with invalid labels", func(t *testing.T) {
+		_, err := am.TestRoutes(apimodels.TestRoutesConfigBodyParams{Labels: model.LabelSet{"team$": "a"}})
+		require.ErrorIs(t, err, ErrInvalidTestRouteLabels)
+	})
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// silenceExpiryPollInterval is how often the silences are checked for expiry notifications to send.
+	silenceExpiryPollInterval = 30 * time.Second
+	// silenceExpiringAlertName is the name of the alert sent to the contact points when a silence is about to expire.
+	silenceExpiringAlertName = "SilenceExpiring"
+)
+
+// runSilenceExpiryNotifications sends the expiry notifications of the silences which are due until stopc is closed.
+func (am *Alertmanager) runSilenceExpiryNotifications(stopc <-chan struct{}) {
+	ticker := time.NewTicker(silenceExpiryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stopc:
+			return
+		}
+		if err := am.processSilenceExpiryNotifications(time.Now()); err != nil {
+			am.logger.Error("failed to process silence expiry notifications", "err", err)
+		}
+	}
+}
+
+// processSilenceExpiryNotifications notifies the contact points of the silences which expire within their
+// notification period. A silence is notified again if it's extended after the notification, and the expiry
+// notifications of the silences which no longer exist or are expired are removed.
+func (am *Alertmanager) processSilenceExpiryNotifications(now time.Time) error {
+	notifications, err := am.Store.ListSilenceExpiryNotifications()
+	if err != nil {
+		return err
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	am.reloadConfigMtx.RLock()
+	integrationsMap := am.integrationsMap
+	am.reloadConfigMtx.RUnlock()
+
+	for _, n := range notifications {
+		sils, _, err := am.silences.Query(silence.QIDs(n.SilenceID))
+		if err != nil {
+			am.logger.Error("failed to get silence", "id", n.SilenceID, "err", err)
+			continue
+		}
+		if len(sils) == 0 || !sils[0].EndsAt.After(now) {
+			if err := am.Store.DeleteSilenceExpiryNotification(n.SilenceID); err != nil {
+				am.logger.Error("failed to delete silence expiry notification", "id", n.SilenceID, "err", err)
+			}
+			continue
+		}
+
+		sil := sils[0]
+		notifyAt := sil.EndsAt.Add(-n.NotifyBefore)
+		if now.Before(notifyAt) || n.NotifiedAt >= notifyAt.Unix() {
+			continue
+		}
+
+		am.notifySilenceExpiry(integrationsMap, n.Receiver, sil, now)
+		n.NotifiedAt = now.Unix()
+		if err := am.Store.SaveSilenceExpiryNotification(n); err != nil {
+			am.logger.Error("failed to save silence expiry notification", "id", n.SilenceID, "err", err)
+		}
+	}
+	return nil
+}
+
+// notifySilenceExpiry sends an alert about the silence expiring to the integrations of the contact point.
+func (am *Alertmanager) notifySilenceExpiry(integrationsMap map[string][]notify.Integration, receiver string, sil *silencepb.Silence, now time.Time) {
+	integrations, ok := integrationsMap[receiver]
+	if !ok {
+		am.logger.Error("contact point of silence expiry notification not found", "id", sil.Id, "receiver", receiver)
+		return
+	}
+
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{
+				model.AlertNameLabel: silenceExpiringAlertName,
+				"silence_id":         model.LabelValue(sil.Id),
+			},
+			Annotations: model.LabelSet{
+				"summary":    model.LabelValue(fmt.Sprintf("Silence %s expires at %s", sil.Id, sil.EndsAt.Format(time.RFC3339))),
+				"comment":    model.LabelValue(sil.Comment),
+				"created_by": model.LabelValue(sil.CreatedBy),
+				"matchers":   model.LabelValue(silenceMatchersString(sil.Matchers)),
+			},
+			StartsAt: now,
+			EndsAt:   sil.EndsAt,
+		},
+		UpdatedAt: now,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitFunc())
+	defer cancel()
+	ctx = notify.WithReceiverName(ctx, receiver)
+	ctx = notify.WithGroupKey(ctx, "silence-expiry/"+sil.Id)
+	ctx = notify.WithGroupLabels(ctx, alert.Labels)
+	ctx = notify.WithNow(ctx, now)
+
+	for i := range integrations {
+		if integrations[i].Name() == escalationIntegrationType {
+			continue
+		}
+		if _, err := integrations[i].Notify(ctx, alert); err != nil {
+			am.logger.Error("failed to notify silence expiry", "id", sil.Id, "receiver", receiver, "integration", integrations[i].Name(), "err", err)
+		}
+	}
+}
+
+// silenceMatchersString returns the matchers of the silence the way they are written in the UI, e.g. {job="api",env=~"prod.*"}.
+func silenceMatchersString(matchers []*silencepb.Matcher) string {
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		op := "="
+		switch m.Type {
+		case silencepb.Matcher_REGEXP:
+			op = "=~"
+		case silencepb.Matcher_NOT_EQUAL:
+			op = "!="
+		case silencepb.Matcher_NOT_REGEXP:
+			op = "!~"
+		}
+		parts = append(parts, fmt.Sprintf("%s%s%q", m.Name, op, m.Pattern))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
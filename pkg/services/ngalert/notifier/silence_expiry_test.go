@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSilenceExpiryNotifications(t *testing.T) {
+	am := setupAMTest(t)
+	// the expiry notifications are processed by the test only
+	require.NoError(t, am.StopAndWait())
+
+	channel := &fakeNotificationChannel{}
+	am.integrationsMap = map[string][]notify.Integration{
+		"on-call": {notify.NewIntegration(channel, channel, "webhook", 0)},
+	}
+
+	now := time.Now()
+	createSilence := func(endsAt time.Time) string {
+		name, value, isRegex, isEqual := "job", "api", false, true
+		comment, createdBy := "deploy", "admin"
+		startsAt, end := strfmt.DateTime(now.Add(-time.Minute)), strfmt.DateTime(endsAt)
+		id, err := am.CreateSilence(&apimodels.PostableSilence{
+			Silence: models.Silence{
+				Matchers:  models.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex, IsEqual: &isEqual}},
+				StartsAt:  &startsAt,
+				EndsAt:    &end,
+				Comment:   &comment,
+				CreatedBy: &createdBy,
+			},
+		})
+		require.NoError(t, err)
+		return id
+	}
+	getNotification := func(silenceID string) (*ngmodels.SilenceExpiryNotification, error) {
+		q := ngmodels.GetSilenceExpiryNotificationQuery{SilenceID: silenceID}
+		err := am.Store.GetSilenceExpiryNotification(&q)
+		return q.Result, err
+	}
+
+	t.Run("the contact point is notified once before the silence expires", func(t *testing.T) {
+		channel.notified = nil
+		id := createSilence(now.Add(time.Hour))
+		require.NoError(t, am.Store.SaveSilenceExpiryNotification(&ngmodels.SilenceExpiryNotification{SilenceID: id, Receiver: "on-call", NotifyBefore: 15 * time.Minute}))
+
+		require.NoError(t, am.processSilenceExpiryNotifications(now))
+		require.Len(t, channel.notified, 0)
+
+		require.NoError(t, am.processSilenceExpiryNotifications(now.Add(50*time.Minute)))
+		require.Len(t, channel.notified, 1)
+		alert := channel.notified[0][0]
+		require.Equal(t, model.LabelValue(silenceExpiringAlertName), alert.Labels[model.AlertNameLabel])
+		require.Equal(t, model.LabelValue(id), alert.Labels["silence_id"])
+		require.Equal(t, model.LabelValue(`{job="api"}`), alert.Annotations["matchers"])
+
+		require.NoError(t, am.processSilenceExpiryNotifications(now.Add(55*time.Minute)))
+		require.Len(t, channel.notified, 1)
+
+		// the notification is removed once the silence expired
+		require.NoError(t, am.processSilenceExpiryNotifications(now.Add(2*time.Hour)))
+		_, err := getNotification(id)
+		require.ErrorIs(t, err, ngmodels.ErrSilenceExpiryNotificationNotFound)
+	})
+
+	t.Run("an extended silence is notified again", func(t *testing.T) {
+		channel.notified = nil
+		id := createSilence(now.Add(10 * time.Minute))
+		require.NoError(t, am.Store.SaveSilenceExpiryNotification(&ngmodels.SilenceExpiryNotification{SilenceID: id, Receiver: "on-call", NotifyBefore: 15 * time.Minute}))
+		require.NoError(t, am.processSilenceExpiryNotifications(now))
+		require.Len(t, channel.notified, 1)
+
+		sil, err := am.GetSilence(id)
+		require.NoError(t, err)
+		endsAt := strfmt.DateTime(now.Add(time.Hour))
+		sil.EndsAt = &endsAt
+		extendedID, err := am.CreateSilence(&apimodels.PostableSilence{ID: id, Silence: sil.Silence})
+		require.NoError(t, err)
+		require.Equal(t, id, extendedID)
+
+		require.NoError(t, am.processSilenceExpiryNotifications(now.Add(time.Minute)))
+		require.Len(t, channel.notified, 1)
+		require.NoError(t, am.processSilenceExpiryNotifications(now.Add(50*time.Minute)))
+		require.Len(t, channel.notified, 2)
+	})
+
+	t.Run("the notification of a deleted silence is removed", func(t *testing.T) {
+		channel.notified = nil
+		id := createSilence(now.Add(time.Hour))
+		require.NoError(t, am.Store.SaveSilenceExpiryNotification(&ngmodels.SilenceExpiryNotification{SilenceID: id, Receiver: "on-call", NotifyBefore: time.Hour}))
+		require.NoError(t, am.DeleteSilence(id))
+
+		require.NoError(t, am.processSilenceExpiryNotifications(now.Add(time.Minute)))
+		require.Len(t, channel.notified, 0)
+		_, err := getNotification(id)
+		require.ErrorIs(t, err, ngmodels.ErrSilenceExpiryNotificationNotFound)
+	})
+}
@@ -3,19 +3,22 @@ package notifier
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	v2 "github.com/prometheus/alertmanager/api/v2"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/silence"
 )
 
 var (
-	ErrGetSilencesInternal     = fmt.Errorf("unable to retrieve silence(s) due to an internal error")
-	ErrDeleteSilenceInternal   = fmt.Errorf("unable to delete silence due to an internal error")
-	ErrCreateSilenceBadPayload = fmt.Errorf("unable to create silence")
-	ErrListSilencesBadPayload  = fmt.Errorf("unable to list silences")
-	ErrSilenceNotFound         = silence.ErrNotFound
+	ErrGetSilencesInternal      = fmt.Errorf("unable to retrieve silence(s) due to an internal error")
+	ErrDeleteSilenceInternal    = fmt.Errorf("unable to delete silence due to an internal error")
+	ErrCreateSilenceBadPayload  = fmt.Errorf("unable to create silence")
+	ErrListSilencesBadPayload   = fmt.Errorf("unable to list silences")
+	ErrPreviewSilenceBadPayload = fmt.Errorf("unable to preview silence")
+	ErrSilenceNotFound          = silence.ErrNotFound
 )
 
 // ListSilences retrieves a list of stored silences. It supports a set of labels as filters.
@@ -117,3 +120,86 @@ func (am *Alertmanager) DeleteSilence(silenceID string) error {
 
 	return nil
 }
+
+// PreviewSilence returns the firing alerts the matchers of the provided silence would silence, regardless of
+// the start and end time of the silence. The silence is not persisted.
+func (am *Alertmanager) PreviewSilence(ps *apimodels.PostableSilence) (apimodels.GettableAlerts, error) {
+	matchers, err := silenceMatchers(ps)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrPreviewSilenceBadPayload)
+	}
+
+	// Initialize result slice to prevent api returning `null` when there are no alerts.
+	res := apimodels.GettableAlerts{}
+
+	alerts := am.alerts.GetPending()
+	defer alerts.Close()
+
+	am.reloadConfigMtx.RLock()
+	for a := range alerts.Next() {
+		if err = alerts.Err(); err != nil {
+			break
+		}
+
+		if a.Resolved() {
+			continue
+		}
+
+		if !matchers.Matches(a.Labels) {
+			continue
+		}
+
+		routes := am.route.Match(a.Labels)
+		receivers := make([]string, 0, len(routes))
+		for _, r := range routes {
+			receivers = append(receivers, r.RouteOpts.Receiver)
+		}
+
+		res = append(res, v2.AlertToOpenAPIAlert(a, am.marker.Status(a.Fingerprint()), receivers))
+	}
+	am.reloadConfigMtx.RUnlock()
+
+	if err != nil {
+		am.logger.Error("failed to iterate through the alerts", "err", err)
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrGetAlertsInternal)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return *res[i].Fingerprint < *res[j].Fingerprint
+	})
+
+	return res, nil
+}
+
+// silenceMatchers compiles the matchers of the silence the same way the silences are matched against the alerts.
+func silenceMatchers(ps *apimodels.PostableSilence) (labels.Matchers, error) {
+	if len(ps.Matchers) == 0 {
+		return nil, errors.New("at least one matcher required")
+	}
+
+	matchers := make(labels.Matchers, 0, len(ps.Matchers))
+	for _, m := range ps.Matchers {
+		if m.Name == nil || m.Value == nil || m.IsRegex == nil {
+			return nil, errors.New("matcher name, value and isRegex are required")
+		}
+
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		var t labels.MatchType
+		switch {
+		case *m.IsRegex && isEqual:
+			t = labels.MatchRegexp
+		case *m.IsRegex:
+			t = labels.MatchNotRegexp
+		case isEqual:
+			t = labels.MatchEqual
+		default:
+			t = labels.MatchNotEqual
+		}
+
+		matcher, err := labels.NewMatcher(t, *m.Name, *m.Value)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
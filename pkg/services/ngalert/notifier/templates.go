@@ -0,0 +1,215 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	tmpltext "text/template"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	// TestTemplateErrorInvalid and TestTemplateErrorExecution are the kinds of errors of a tested template.
+	TestTemplateErrorInvalid   = "invalid_template"
+	TestTemplateErrorExecution = "execution_error"
+)
+
+var (
+	ErrInvalidTemplateName = errors.New("invalid template file name")
+)
+
+// TestTemplates renders the templates defined in the given template file, together with the stored template files,
+// against the given alerts, the alerts of a notification attempt or a test alert.
+// If the template file doesn't define any template, the file itself is rendered.
+// Invalid templates and rendering failures are reported in the errors of the result.
+func (am *Alertmanager) TestTemplates(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*apimodels.TestTemplatesResults, error) {
+	if c.Name == "" || c.Name != filepath.Base(filepath.Clean(c.Name)) {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidTemplateName, c.Name)
+	}
+
+	alerts, err := am.testTemplateAlerts(c)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := am.storedTemplateFiles()
+	if err != nil {
+		return nil, err
+	}
+	files[c.Name] = c.Template
+
+	result := &apimodels.TestTemplatesResults{
+		Results: []apimodels.TestTemplatesResult{},
+		Errors:  []apimodels.TestTemplatesErrorResult{},
+	}
+	invalid := func(err error) *apimodels.TestTemplatesResults {
+		result.Errors = append(result.Errors, apimodels.TestTemplatesErrorResult{
+			Kind:    TestTemplateErrorInvalid,
+			Message: err.Error(),
+		})
+		return result
+	}
+
+	names, err := definedTemplates(c.Name, c.Template)
+	if err != nil {
+		return invalid(err), nil
+	}
+	tmpl, err := am.templateFromFiles(files)
+	if err != nil {
+		return invalid(err), nil
+	}
+
+	data := channels.ExtendData(notify.GetTemplateData(ctx, tmpl, alerts, am.gokitLogger), am.logger)
+	for _, name := range names {
+		text, err := tmpl.ExecuteTextString(fmt.Sprintf(`{{ template %q . }}`, name), data)
+		if err != nil {
+			result.Errors = append(result.Errors, apimodels.TestTemplatesErrorResult{
+				Name:    name,
+				Kind:    TestTemplateErrorExecution,
+				Message: err.Error(),
+			})
+			continue
+		}
+		result.Results = append(result.Results, apimodels.TestTemplatesResult{Name: name, Text: text})
+	}
+	return result, nil
+}
+
+// testTemplateAlerts returns the alerts a template is tested with.
+func (am *Alertmanager) testTemplateAlerts(c apimodels.TestTemplatesConfigBodyParams) ([]*types.Alert, error) {
+	now := time.Now()
+
+	if c.NotificationID > 0 {
+		q := ngmodels.GetNotificationAttemptQuery{ID: c.NotificationID}
+		if err := am.Store.GetNotificationAttempt(&q); err != nil {
+			return nil, err
+		}
+		alerts := make([]*types.Alert, 0, len(q.Result.Alerts))
+		for _, a := range q.Result.Alerts {
+			alerts = append(alerts, &types.Alert{Alert: a, UpdatedAt: now})
+		}
+		return alerts, nil
+	}
+
+	if len(c.Alerts) == 0 {
+		testAlert := newTestAlertParams(nil)
+		return []*types.Alert{{
+			Alert: model.Alert{
+				Labels:      testAlert.Labels,
+				Annotations: testAlert.Annotations,
+				StartsAt:    now,
+			},
+			UpdatedAt: now,
+		}}, nil
+	}
+
+	alerts := make([]*types.Alert, 0, len(c.Alerts))
+	for _, a := range c.Alerts {
+		alert := &types.Alert{
+			Alert: model.Alert{
+				Labels:       model.LabelSet{},
+				Annotations:  model.LabelSet{},
+				StartsAt:     time.Time(a.StartsAt),
+				EndsAt:       time.Time(a.EndsAt),
+				GeneratorURL: a.GeneratorURL.String(),
+			},
+			UpdatedAt: now,
+		}
+		for k, v := range a.Labels {
+			alert.Labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		for k, v := range a.Annotations {
+			alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if alert.StartsAt.IsZero() {
+			alert.StartsAt = now
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// storedTemplateFiles returns the template files of the stored configuration and the default template.
+func (am *Alertmanager) storedTemplateFiles() (map[string]string, error) {
+	files := map[string]string{}
+	q := &ngmodels.GetLatestAlertmanagerConfigurationQuery{}
+	if err := am.Store.GetLatestAlertmanagerConfiguration(q); err != nil {
+		if !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, err
+		}
+	} else {
+		cfg, err := Load([]byte(q.Result.AlertmanagerConfiguration))
+		if err != nil {
+			return nil, err
+		}
+		for name, content := range cfg.TemplateFiles {
+			files[name] = content
+		}
+	}
+	files["__default__.tmpl"] = channels.DefaultTemplateString
+	return files, nil
+}
+
+// templateFromFiles parses the template files in a temporary directory.
+func (am *Alertmanager) templateFromFiles(files map[string]string) (*template.Template, error) {
+	dir, err := ioutil.TempDir("", "ngalert-templates")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			am.logger.Warn("failed to remove the directory of the tested templates", "dir", dir, "err", err)
+		}
+	}()
+
+	paths, _, err := PersistTemplates(&apimodels.PostableUserConfig{TemplateFiles: files}, dir)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.FromGlobs(paths...)
+	if err != nil {
+		return nil, err
+	}
+	externalURL, err := url.Parse(am.Settings.AppURL)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.ExternalURL = externalURL
+	return tmpl, nil
+}
+
+// definedTemplates returns the sorted names of the templates defined in a template file,
+// or the name of the file if it doesn't define any template.
+func definedTemplates(name, content string) ([]string, error) {
+	t, err := tmpltext.New(name).Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, defined := range t.Templates() {
+		if defined.Name() != name {
+			names = append(names, defined.Name())
+		}
+	}
+	if len(names) == 0 {
+		return []string{name}, nil
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestTestTemplates(t *testing.T) {
+	am := setupAMTest(t)
+
+	t.Run("renders the defined templates with the given alerts", func(t *testing.T) {
+		result, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name: "my.tmpl",
+			Template: `{{ define "my.title" }}{{ .CommonLabels.alertname }} is {{ .Status }}{{ end }}` +
+				`{{ define "my.message" }}{{ template "default.title" . }}{{ end }}`,
+			Alerts: []amv2.PostableAlert{{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "HighCPU"}}}},
+		})
+		require.NoError(t, err)
+		require.Empty(t, result.Errors)
+		require.Equal(t, []apimodels.TestTemplatesResult{
+			{Name: "my.message", Text: "[FIRING:1]  (HighCPU)"},
+			{Name: "my.title", Text: "HighCPU is firing"},
+		}, result.Results)
+	})
+
+	t.Run("renders the template file without defined templates with a test alert", func(t *testing.T) {
+		result, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name:     "plain.tmpl",
+			Template: `{{ range .Alerts }}{{ .Annotations.summary }}{{ end }}`,
+		})
+		require.NoError(t, err)
+		require.Empty(t, result.Errors)
+		require.Equal(t, []apimodels.TestTemplatesResult{{Name: "plain.tmpl", Text: "Notification test"}}, result.Results)
+	})
+
+	t.Run("renders the template with the alerts of a notification attempt", func(t *testing.T) {
+		attempt := &ngmodels.NotificationAttempt{
+			Receiver:        "receiver",
+			IntegrationType: "webhook",
+			Alerts:          []model.Alert{{Labels: model.LabelSet{"alertname": "DiskFull"}}},
+		}
+		require.NoError(t, am.Store.SaveNotificationAttempt(attempt))
+
+		result, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name:           "plain.tmpl",
+			Template:       `{{ .CommonLabels.alertname }}`,
+			NotificationID: attempt.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []apimodels.TestTemplatesResult{{Name: "plain.tmpl", Text: "DiskFull"}}, result.Results)
+
+		_, err = am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name:           "plain.tmpl",
+			NotificationID: attempt.ID + 1,
+		})
+		require.ErrorIs(t, err, store.ErrNotificationAttemptNotFound)
+	})
+
+	t.Run("reports invalid templates", func(t *testing.T) {
+		result, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name:     "invalid.tmpl",
+			Template: `{{ define "my.title" }}{{ .Status }}`,
+		})
+		require.NoError(t, err)
+		require.Empty(t, result.Results)
+		require.Len(t, result.Errors, 1)
+		require.Equal(t, TestTemplateErrorInvalid, result.Errors[0].Kind)
+	})
+
+	t.Run("reports the templates that fail to render", func(t *testing.T) {
+		result, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name:     "my.tmpl",
+			Template: `{{ define "ok" }}ok{{ end }}{{ define "failing" }}{{ template "missing" . }}{{ end }}`,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []apimodels.TestTemplatesResult{{Name: "ok", Text: "ok"}}, result.Results)
+		require.Len(t, result.Errors, 1)
+		require.Equal(t, "failing", result.Errors[0].Name)
+		require.Equal(t, TestTemplateErrorExecution, result.Errors[0].Kind)
+	})
+
+	t.Run("rejects invalid file names", func(t *testing.T) {
+		_, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name: "../my.tmpl",
+		})
+		require.ErrorIs(t, err, ErrInvalidTemplateName)
+	})
+}
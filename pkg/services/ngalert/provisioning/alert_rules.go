@@ -0,0 +1,118 @@
+package provisioning
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// AlertRuleService provisions single alert rules of rule groups.
+type AlertRuleService struct {
+	ruleStore       store.RuleStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
+}
+
+func NewAlertRuleService(ruleStore store.RuleStore, provenanceStore store.ProvisioningStore, log log.Logger) *AlertRuleService {
+	return &AlertRuleService{ruleStore: ruleStore, provenanceStore: provenanceStore, log: log}
+}
+
+// GetAlertRule returns the alert rule with the UID and its provenance.
+func (s *AlertRuleService) GetAlertRule(orgID int64, uid string) (models.AlertRule, models.Provenance, error) {
+	q := models.GetAlertRuleByUIDQuery{OrgID: orgID, UID: uid}
+	if err := s.ruleStore.GetAlertRuleByUID(&q); err != nil {
+		return models.AlertRule{}, models.ProvenanceNone, err
+	}
+
+	provenance, err := s.provenanceStore.GetProvenance(orgID, q.Result)
+	if err != nil {
+		return models.AlertRule{}, models.ProvenanceNone, err
+	}
+	return *q.Result, provenance, nil
+}
+
+// GetAlertRules returns all alert rules of the organization with their provenance.
+func (s *AlertRuleService) GetAlertRules(orgID int64) ([]*models.AlertRule, map[string]models.Provenance, error) {
+	q := models.ListAlertRulesQuery{OrgID: orgID}
+	if err := s.ruleStore.GetOrgAlertRules(&q); err != nil {
+		return nil, nil, err
+	}
+
+	provenances, err := s.provenanceStore.GetProvenances(orgID, (&models.AlertRule{}).ResourceType())
+	if err != nil {
+		return nil, nil, err
+	}
+	return q.Result, provenances, nil
+}
+
+// CreateAlertRule adds the alert rule to its rule group, the rule inherits the interval of the group
+// unless it has one.
+func (s *AlertRuleService) CreateAlertRule(rule models.AlertRule, provenance models.Provenance) (models.AlertRule, error) {
+	if rule.IntervalSeconds == 0 {
+		q := models.ListRuleGroupAlertRulesQuery{OrgID: rule.OrgID, NamespaceUID: rule.NamespaceUID, RuleGroup: rule.RuleGroup}
+		if err := s.ruleStore.GetRuleGroupAlertRules(&q); err != nil {
+			return models.AlertRule{}, err
+		}
+		if len(q.Result) > 0 {
+			rule.IntervalSeconds = q.Result[0].IntervalSeconds
+		}
+	}
+
+	created, err := s.ruleStore.InsertAlertRule(rule)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	if err := s.provenanceStore.SetProvenance(rule.OrgID, created, provenance); err != nil {
+		return models.AlertRule{}, err
+	}
+	return *created, nil
+}
+
+// UpdateAlertRule replaces the alert rule with the UID. The rule can't be moved to another folder or rule group.
+func (s *AlertRuleService) UpdateAlertRule(rule models.AlertRule, provenance models.Provenance) (models.AlertRule, error) {
+	existing, storedProvenance, err := s.GetAlertRule(rule.OrgID, rule.UID)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return models.AlertRule{}, models.ErrProvenanceChangeNotAllowed
+	}
+
+	if rule.NamespaceUID != existing.NamespaceUID || rule.RuleGroup != existing.RuleGroup {
+		return models.AlertRule{}, fmt.Errorf("%w: alert rules can't be moved to another folder or rule group", ErrValidation)
+	}
+	if rule.IntervalSeconds == 0 {
+		rule.IntervalSeconds = existing.IntervalSeconds
+	}
+
+	if err := s.ruleStore.UpsertAlertRules([]store.UpsertRule{{Existing: &existing, New: rule}}); err != nil {
+		return models.AlertRule{}, err
+	}
+
+	if err := s.provenanceStore.SetProvenance(rule.OrgID, &rule, provenance); err != nil {
+		return models.AlertRule{}, err
+	}
+
+	updated, _, err := s.GetAlertRule(rule.OrgID, rule.UID)
+	return updated, err
+}
+
+// DeleteAlertRule removes the alert rule with the UID.
+func (s *AlertRuleService) DeleteAlertRule(orgID int64, uid string, provenance models.Provenance) error {
+	rule := &models.AlertRule{OrgID: orgID, UID: uid}
+	storedProvenance, err := s.provenanceStore.GetProvenance(orgID, rule)
+	if err != nil {
+		return err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return models.ErrProvenanceChangeNotAllowed
+	}
+
+	if err := s.ruleStore.DeleteAlertRuleByUID(orgID, uid); err != nil {
+		return err
+	}
+	return s.provenanceStore.DeleteProvenance(orgID, rule)
+}
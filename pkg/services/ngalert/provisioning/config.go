@@ -0,0 +1,110 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+var (
+	// ErrValidation is an error for invalid provisioned resources.
+	ErrValidation = errors.New("invalid resource")
+	// ErrContactPointNotFound is an error for an unknown contact point.
+	ErrContactPointNotFound = errors.New("contact point not found")
+	// ErrContactPointExists is an error for a contact point with a UID which is already used.
+	ErrContactPointExists = errors.New("contact point with this UID already exists")
+	// ErrContactPointReferenced is an error for deleting a contact point used by notification policies.
+	ErrContactPointReferenced = errors.New("contact point is referenced by a notification policy")
+	// ErrMuteTimingNotFound is an error for an unknown mute timing.
+	ErrMuteTimingNotFound = errors.New("mute timing not found")
+	// ErrMuteTimingExists is an error for a mute timing with a name which is already used.
+	ErrMuteTimingExists = errors.New("mute timing with this name already exists")
+	// ErrMuteTimingReferenced is an error for deleting a mute timing used by notification policies.
+	ErrMuteTimingReferenced = errors.New("mute timing is referenced by a notification policy")
+)
+
+// AMConfigStore is the database interface of the Alertmanager configuration.
+type AMConfigStore interface {
+	GetLatestAlertmanagerConfiguration(*models.GetLatestAlertmanagerConfigurationQuery) error
+}
+
+// AlertmanagerConfigApplier saves and applies a changed Alertmanager configuration.
+type AlertmanagerConfigApplier interface {
+	SaveAndApplyConfig(config *apimodels.PostableUserConfig) error
+}
+
+// ConfigStore reads and updates the Alertmanager configuration the contact points, notification
+// policies and mute timings are part of. Updates are serialized to not lose concurrent changes.
+type ConfigStore struct {
+	mtx   sync.Mutex
+	store AMConfigStore
+	am    AlertmanagerConfigApplier
+}
+
+func NewConfigStore(store AMConfigStore, am AlertmanagerConfigApplier) *ConfigStore {
+	return &ConfigStore{store: store, am: am}
+}
+
+// get returns the latest Alertmanager configuration with decrypted secure settings.
+func (s *ConfigStore) get() (*apimodels.PostableUserConfig, error) {
+	q := models.GetLatestAlertmanagerConfigurationQuery{}
+	if err := s.store.GetLatestAlertmanagerConfiguration(&q); err != nil {
+		return nil, err
+	}
+
+	cfg, err := notifier.Load([]byte(q.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Alertmanager configuration: %w", err)
+	}
+
+	// secure settings are encrypted again when the configuration is saved
+	for _, gr := range cfg.GetGrafanaReceiverMap() {
+		for key := range gr.SecureSettings {
+			value, err := gr.GetDecryptedSecret(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt stored secure setting %s: %w", key, err)
+			}
+			gr.SecureSettings[key] = value
+		}
+	}
+
+	return cfg, nil
+}
+
+// update applies the changes of fn to the latest Alertmanager configuration and saves it.
+func (s *ConfigStore) update(fn func(cfg *apimodels.PostableUserConfig) error) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	cfg, err := s.get()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(cfg); err != nil {
+		return err
+	}
+
+	// the configuration is validated when it's unmarshaled
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &apimodels.PostableUserConfig{}); err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err)
+	}
+
+	if err := cfg.ProcessConfig(); err != nil {
+		return fmt.Errorf("failed to process Alertmanager configuration: %w", err)
+	}
+
+	if err := s.am.SaveAndApplyConfig(cfg); err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err)
+	}
+	return nil
+}
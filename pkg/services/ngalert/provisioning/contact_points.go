@@ -0,0 +1,274 @@
+package provisioning
+
+import (
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ContactPointService provisions the Grafana managed integrations of the Alertmanager receivers.
+type ContactPointService struct {
+	config          *ConfigStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
+}
+
+func NewContactPointService(config *ConfigStore, provenanceStore store.ProvisioningStore, log log.Logger) *ContactPointService {
+	return &ContactPointService{config: config, provenanceStore: provenanceStore, log: log}
+}
+
+// GetContactPoints returns all contact points, secure settings are omitted.
+func (s *ContactPointService) GetContactPoints(orgID int64) ([]apimodels.EmbeddedContactPoint, error) {
+	cfg, err := s.config.get()
+	if err != nil {
+		return nil, err
+	}
+
+	provenances, err := s.provenanceStore.GetProvenances(orgID, (&apimodels.EmbeddedContactPoint{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	contactPoints := make([]apimodels.EmbeddedContactPoint, 0)
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			contactPoints = append(contactPoints, apimodels.EmbeddedContactPoint{
+				UID:                   gr.UID,
+				Name:                  r.Name,
+				Type:                  gr.Type,
+				Settings:              gr.Settings,
+				DisableResolveMessage: gr.DisableResolveMessage,
+				SecureFields:          secureFields(gr.SecureSettings),
+				Provenance:            provenances[gr.UID],
+			})
+		}
+	}
+	return contactPoints, nil
+}
+
+// CreateContactPoint adds the contact point to the receiver of its name, the receiver is created if it doesn't exist.
+func (s *ContactPointService) CreateContactPoint(orgID int64, contactPoint apimodels.EmbeddedContactPoint, provenance models.Provenance) (apimodels.EmbeddedContactPoint, error) {
+	if err := validateContactPoint(contactPoint); err != nil {
+		return apimodels.EmbeddedContactPoint{}, err
+	}
+	if contactPoint.UID == "" {
+		contactPoint.UID = util.GenerateShortUID()
+	}
+
+	err := s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		if _, ok := cfg.GetGrafanaReceiverMap()[contactPoint.UID]; ok {
+			return ErrContactPointExists
+		}
+
+		addIntegration(cfg, contactPoint.Name, &apimodels.PostableGrafanaReceiver{
+			UID:                   contactPoint.UID,
+			Name:                  contactPoint.Name,
+			Type:                  contactPoint.Type,
+			DisableResolveMessage: contactPoint.DisableResolveMessage,
+			Settings:              contactPoint.Settings,
+			SecureSettings:        contactPoint.SecureSettings,
+		})
+		return nil
+	})
+	if err != nil {
+		return apimodels.EmbeddedContactPoint{}, err
+	}
+
+	if err := s.provenanceStore.SetProvenance(orgID, &contactPoint, provenance); err != nil {
+		return apimodels.EmbeddedContactPoint{}, err
+	}
+
+	contactPoint.Provenance = provenance
+	contactPoint.SecureFields = secureFields(contactPoint.SecureSettings)
+	contactPoint.SecureSettings = nil
+	return contactPoint, nil
+}
+
+// UpdateContactPoint replaces the contact point with the UID, secure settings which aren't set are kept.
+// Changing the name moves the contact point to another receiver, references of notification policies
+// are renamed if the previous receiver has no other contact points.
+func (s *ContactPointService) UpdateContactPoint(orgID int64, contactPoint apimodels.EmbeddedContactPoint, provenance models.Provenance) error {
+	if err := validateContactPoint(contactPoint); err != nil {
+		return err
+	}
+
+	storedProvenance, err := s.provenanceStore.GetProvenance(orgID, &contactPoint)
+	if err != nil {
+		return err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return models.ErrProvenanceChangeNotAllowed
+	}
+
+	err = s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		receiver, existing := findIntegration(cfg, contactPoint.UID)
+		if existing == nil {
+			return ErrContactPointNotFound
+		}
+
+		secureSettings := existing.SecureSettings
+		if secureSettings == nil {
+			secureSettings = make(map[string]string, len(contactPoint.SecureSettings))
+		}
+		for key, value := range contactPoint.SecureSettings {
+			secureSettings[key] = value
+		}
+
+		updated := &apimodels.PostableGrafanaReceiver{
+			UID:                   contactPoint.UID,
+			Name:                  contactPoint.Name,
+			Type:                  contactPoint.Type,
+			DisableResolveMessage: contactPoint.DisableResolveMessage,
+			Settings:              contactPoint.Settings,
+			SecureSettings:        secureSettings,
+		}
+
+		if receiver.Name == contactPoint.Name {
+			*existing = *updated
+			return nil
+		}
+
+		removeIntegration(cfg, contactPoint.UID)
+		if findReceiver(cfg, receiver.Name) == nil {
+			renameReceiverReferences(cfg.AlertmanagerConfig.Route, receiver.Name, contactPoint.Name)
+		}
+		addIntegration(cfg, contactPoint.Name, updated)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.provenanceStore.SetProvenance(orgID, &contactPoint, provenance)
+}
+
+// DeleteContactPoint removes the contact point with the UID. The receiver of the contact point is removed
+// with its last contact point, unless notification policies reference it.
+func (s *ContactPointService) DeleteContactPoint(orgID int64, uid string, provenance models.Provenance) error {
+	contactPoint := &apimodels.EmbeddedContactPoint{UID: uid}
+	storedProvenance, err := s.provenanceStore.GetProvenance(orgID, contactPoint)
+	if err != nil {
+		return err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return models.ErrProvenanceChangeNotAllowed
+	}
+
+	err = s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		receiver, existing := findIntegration(cfg, uid)
+		if existing == nil {
+			return ErrContactPointNotFound
+		}
+
+		removeIntegration(cfg, uid)
+		if findReceiver(cfg, receiver.Name) == nil {
+			for _, name := range apimodels.AllReceivers(cfg.AlertmanagerConfig.Route) {
+				if name == receiver.Name {
+					return fmt.Errorf("%w: %s", ErrContactPointReferenced, receiver.Name)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.provenanceStore.DeleteProvenance(orgID, contactPoint)
+}
+
+func validateContactPoint(contactPoint apimodels.EmbeddedContactPoint) error {
+	if contactPoint.Name == "" {
+		return fmt.Errorf("%w: name is empty", ErrValidation)
+	}
+	if contactPoint.Type == "" {
+		return fmt.Errorf("%w: type is empty", ErrValidation)
+	}
+	if contactPoint.Settings == nil {
+		return fmt.Errorf("%w: settings are empty", ErrValidation)
+	}
+	return nil
+}
+
+func secureFields(secureSettings map[string]string) map[string]bool {
+	fields := make(map[string]bool, len(secureSettings))
+	for key, value := range secureSettings {
+		if value != "" {
+			fields[key] = true
+		}
+	}
+	return fields
+}
+
+// findReceiver returns the receiver with the name, or nil if there's no receiver with contact points of the name.
+func findReceiver(cfg *apimodels.PostableUserConfig, name string) *apimodels.PostableApiReceiver {
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// findIntegration returns the contact point with the UID and its receiver.
+func findIntegration(cfg *apimodels.PostableUserConfig, uid string) (*apimodels.PostableApiReceiver, *apimodels.PostableGrafanaReceiver) {
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			if gr.UID == uid {
+				return r, gr
+			}
+		}
+	}
+	return nil, nil
+}
+
+func addIntegration(cfg *apimodels.PostableUserConfig, receiverName string, integration *apimodels.PostableGrafanaReceiver) {
+	if receiver := findReceiver(cfg, receiverName); receiver != nil {
+		receiver.GrafanaManagedReceivers = append(receiver.GrafanaManagedReceivers, integration)
+		return
+	}
+
+	cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &apimodels.PostableApiReceiver{
+		Receiver: config.Receiver{Name: receiverName},
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{integration},
+		},
+	})
+}
+
+// removeIntegration removes the contact point with the UID, receivers without contact points are removed.
+func removeIntegration(cfg *apimodels.PostableUserConfig, uid string) {
+	receivers := make([]*apimodels.PostableApiReceiver, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		integrations := make([]*apimodels.PostableGrafanaReceiver, 0, len(r.GrafanaManagedReceivers))
+		for _, gr := range r.GrafanaManagedReceivers {
+			if gr.UID != uid {
+				integrations = append(integrations, gr)
+			}
+		}
+		if len(integrations) == 0 && len(r.GrafanaManagedReceivers) > 0 {
+			continue
+		}
+		r.GrafanaManagedReceivers = integrations
+		receivers = append(receivers, r)
+	}
+	cfg.AlertmanagerConfig.Receivers = receivers
+}
+
+func renameReceiverReferences(route *config.Route, oldName, newName string) {
+	if route == nil {
+		return
+	}
+	if route.Receiver == oldName {
+		route.Receiver = newName
+	}
+	for _, r := range route.Routes {
+		renameReceiverReferences(r, oldName, newName)
+	}
+}
@@ -0,0 +1,146 @@
+package provisioning
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// MuteTimingService provisions the mute time intervals notification policies can reference.
+type MuteTimingService struct {
+	config          *ConfigStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
+}
+
+func NewMuteTimingService(config *ConfigStore, provenanceStore store.ProvisioningStore, log log.Logger) *MuteTimingService {
+	return &MuteTimingService{config: config, provenanceStore: provenanceStore, log: log}
+}
+
+// GetMuteTimings returns all mute timings of the organization.
+func (s *MuteTimingService) GetMuteTimings(orgID int64) ([]apimodels.ProvisionedMuteTimeInterval, error) {
+	cfg, err := s.config.get()
+	if err != nil {
+		return nil, err
+	}
+
+	provenances, err := s.provenanceStore.GetProvenances(orgID, (&apimodels.ProvisionedMuteTimeInterval{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	muteTimings := make([]apimodels.ProvisionedMuteTimeInterval, 0, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimings = append(muteTimings, apimodels.ProvisionedMuteTimeInterval{
+			MuteTimeInterval: mt,
+			Provenance:       provenances[mt.Name],
+		})
+	}
+	return muteTimings, nil
+}
+
+// GetMuteTiming returns the mute timing with the name.
+func (s *MuteTimingService) GetMuteTiming(orgID int64, name string) (apimodels.ProvisionedMuteTimeInterval, error) {
+	muteTimings, err := s.GetMuteTimings(orgID)
+	if err != nil {
+		return apimodels.ProvisionedMuteTimeInterval{}, err
+	}
+	for _, mt := range muteTimings {
+		if mt.Name == name {
+			return mt, nil
+		}
+	}
+	return apimodels.ProvisionedMuteTimeInterval{}, ErrMuteTimingNotFound
+}
+
+// CreateMuteTiming adds a mute timing, the name must not be used by another mute timing.
+func (s *MuteTimingService) CreateMuteTiming(orgID int64, muteTiming apimodels.ProvisionedMuteTimeInterval, provenance models.Provenance) (apimodels.ProvisionedMuteTimeInterval, error) {
+	if muteTiming.Name == "" {
+		return apimodels.ProvisionedMuteTimeInterval{}, fmt.Errorf("%w: name is empty", ErrValidation)
+	}
+
+	err := s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+			if mt.Name == muteTiming.Name {
+				return ErrMuteTimingExists
+			}
+		}
+		cfg.AlertmanagerConfig.MuteTimeIntervals = append(cfg.AlertmanagerConfig.MuteTimeIntervals, muteTiming.MuteTimeInterval)
+		return nil
+	})
+	if err != nil {
+		return apimodels.ProvisionedMuteTimeInterval{}, err
+	}
+
+	if err := s.provenanceStore.SetProvenance(orgID, &muteTiming, provenance); err != nil {
+		return apimodels.ProvisionedMuteTimeInterval{}, err
+	}
+	muteTiming.Provenance = provenance
+	return muteTiming, nil
+}
+
+// UpdateMuteTiming replaces the time intervals of the mute timing with the name.
+func (s *MuteTimingService) UpdateMuteTiming(orgID int64, muteTiming apimodels.ProvisionedMuteTimeInterval, provenance models.Provenance) (apimodels.ProvisionedMuteTimeInterval, error) {
+	storedProvenance, err := s.provenanceStore.GetProvenance(orgID, &muteTiming)
+	if err != nil {
+		return apimodels.ProvisionedMuteTimeInterval{}, err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return apimodels.ProvisionedMuteTimeInterval{}, models.ErrProvenanceChangeNotAllowed
+	}
+
+	err = s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		for i, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+			if mt.Name == muteTiming.Name {
+				cfg.AlertmanagerConfig.MuteTimeIntervals[i] = muteTiming.MuteTimeInterval
+				return nil
+			}
+		}
+		return ErrMuteTimingNotFound
+	})
+	if err != nil {
+		return apimodels.ProvisionedMuteTimeInterval{}, err
+	}
+
+	if err := s.provenanceStore.SetProvenance(orgID, &muteTiming, provenance); err != nil {
+		return apimodels.ProvisionedMuteTimeInterval{}, err
+	}
+	muteTiming.Provenance = provenance
+	return muteTiming, nil
+}
+
+// DeleteMuteTiming removes the mute timing with the name unless notification policies reference it.
+func (s *MuteTimingService) DeleteMuteTiming(orgID int64, name string, provenance models.Provenance) error {
+	muteTiming := &apimodels.ProvisionedMuteTimeInterval{MuteTimeInterval: apimodels.MuteTimeInterval{Name: name}}
+	storedProvenance, err := s.provenanceStore.GetProvenance(orgID, muteTiming)
+	if err != nil {
+		return err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return models.ErrProvenanceChangeNotAllowed
+	}
+
+	err = s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		for _, referenced := range apimodels.AllMuteTimeIntervals(cfg.AlertmanagerConfig.Route) {
+			if referenced == name {
+				return fmt.Errorf("%w: %s", ErrMuteTimingReferenced, name)
+			}
+		}
+
+		for i, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+			if mt.Name == name {
+				cfg.AlertmanagerConfig.MuteTimeIntervals = append(cfg.AlertmanagerConfig.MuteTimeIntervals[:i], cfg.AlertmanagerConfig.MuteTimeIntervals[i+1:]...)
+				return nil
+			}
+		}
+		return ErrMuteTimingNotFound
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.provenanceStore.DeleteProvenance(orgID, muteTiming)
+}
@@ -0,0 +1,72 @@
+package provisioning
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// NotificationPolicyService provisions the notification policy tree.
+type NotificationPolicyService struct {
+	config          *ConfigStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
+}
+
+func NewNotificationPolicyService(config *ConfigStore, provenanceStore store.ProvisioningStore, log log.Logger) *NotificationPolicyService {
+	return &NotificationPolicyService{config: config, provenanceStore: provenanceStore, log: log}
+}
+
+// GetPolicyTree returns the notification policy tree of the organization.
+func (s *NotificationPolicyService) GetPolicyTree(orgID int64) (apimodels.NotificationPolicyTree, error) {
+	cfg, err := s.config.get()
+	if err != nil {
+		return apimodels.NotificationPolicyTree{}, err
+	}
+
+	tree := apimodels.NotificationPolicyTree{Route: cfg.AlertmanagerConfig.Route}
+	provenance, err := s.provenanceStore.GetProvenance(orgID, &tree)
+	if err != nil {
+		return apimodels.NotificationPolicyTree{}, err
+	}
+	tree.Provenance = provenance
+	return tree, nil
+}
+
+// UpdatePolicyTree replaces the notification policy tree, every receiver of the tree must exist.
+func (s *NotificationPolicyService) UpdatePolicyTree(orgID int64, tree apimodels.NotificationPolicyTree, provenance models.Provenance) error {
+	if tree.Route == nil {
+		return fmt.Errorf("%w: route is empty", ErrValidation)
+	}
+
+	storedProvenance, err := s.provenanceStore.GetProvenance(orgID, &tree)
+	if err != nil {
+		return err
+	}
+	if !storedProvenance.CanUpdate(provenance) {
+		return models.ErrProvenanceChangeNotAllowed
+	}
+
+	err = s.config.update(func(cfg *apimodels.PostableUserConfig) error {
+		muteTimings := make(map[string]struct{}, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+		for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+			muteTimings[mt.Name] = struct{}{}
+		}
+		for _, name := range apimodels.AllMuteTimeIntervals(tree.Route) {
+			if _, ok := muteTimings[name]; !ok {
+				return fmt.Errorf("%w: mute timing %s does not exist", ErrValidation, name)
+			}
+		}
+
+		cfg.AlertmanagerConfig.Route = tree.Route
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.provenanceStore.SetProvenance(orgID, &tree, provenance)
+}
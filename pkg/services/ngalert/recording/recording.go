@@ -0,0 +1,53 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Sample is the value of a series written by a recording rule.
+type Sample struct {
+	Labels data.Labels
+	Value  float64
+}
+
+// Writer writes the samples of the recording rules.
+type Writer interface {
+	// Write writes the samples of the metric at the given time.
+	Write(ctx context.Context, metric string, t time.Time, samples []Sample) error
+}
+
+// FramesToSamples converts the frames of the condition of a recording rule to samples.
+// A number gives a sample with its value and a time series a sample with its last value,
+// the null values are skipped.
+func FramesToSamples(frames data.Frames) ([]Sample, error) {
+	samples := make([]Sample, 0, len(frames))
+	for _, f := range frames {
+		if f == nil {
+			continue
+		}
+		for _, field := range f.Fields {
+			if field.Type().Time() {
+				continue
+			}
+			if !field.Type().Numeric() {
+				return nil, fmt.Errorf("invalid field type of %s: %s", f.RefID, field.Type())
+			}
+			if field.Len() == 0 {
+				continue
+			}
+			v, err := field.NullableFloatAt(field.Len() - 1)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				continue
+			}
+			samples = append(samples, Sample{Labels: field.Labels, Value: *v})
+		}
+	}
+	return samples, nil
+}
@@ -0,0 +1,86 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramesToSamples(t *testing.T) {
+	one, two := 1.0, 2.0
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("", data.Labels{"job": "a"}, []*float64{&one})),
+		data.NewFrame("",
+			data.NewField("", data.Labels{"job": "b"}, []*float64{nil})),
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+			data.NewField("value", data.Labels{"job": "c"}, []float64{one, two})),
+	}
+
+	samples, err := FramesToSamples(frames)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{
+		{Labels: data.Labels{"job": "a"}, Value: 1},
+		{Labels: data.Labels{"job": "c"}, Value: 2},
+	}, samples)
+
+	_, err = FramesToSamples(data.Frames{data.NewFrame("", data.NewField("", nil, []string{"a"}))})
+	require.Error(t, err)
+}
+
+func TestRemoteWriter(t *testing.T) {
+	var (
+		received prompb.WriteRequest
+		headers  http.Header
+		user     string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		user, _, _ = r.BasicAuth()
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, received.Unmarshal(b))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Unix(60, 0)
+	writer := NewRemoteWriter(server.URL, "user", "password", 0)
+	err := writer.Write(context.Background(), "job:errors:rate5m", now, []Sample{
+		{Labels: data.Labels{"job": "a", "env": "prod"}, Value: 0.5},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "snappy", headers.Get("Content-Encoding"))
+	require.Equal(t, "application/x-protobuf", headers.Get("Content-Type"))
+	require.Equal(t, "user", user)
+	require.Equal(t, []prompb.TimeSeries{{
+		Labels: []prompb.Label{
+			{Name: "__name__", Value: "job:errors:rate5m"},
+			{Name: "env", Value: "prod"},
+			{Name: "job", Value: "a"},
+		},
+		Samples: []prompb.Sample{{Value: 0.5, Timestamp: 60000}},
+	}}, received.Timeseries)
+
+	t.Run("failed requests return an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		err := NewRemoteWriter(server.URL, "", "", 0).Write(context.Background(), "metric", now, []Sample{{Value: 1}})
+		require.Error(t, err)
+	})
+}
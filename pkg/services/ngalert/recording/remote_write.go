@@ -0,0 +1,112 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	// defaultTimeout is the timeout of the remote write requests unless another timeout is given.
+	defaultTimeout = 30 * time.Second
+
+	metricNameLabel = "__name__"
+)
+
+// RemoteWriter writes the samples of the recording rules to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewRemoteWriter returns a writer to the remote write endpoint at url, using basic authentication
+// if the user is set. A timeout of 0 is the default timeout.
+func NewRemoteWriter(url, user, password string, timeout time.Duration) *RemoteWriter {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &RemoteWriter{
+		url:      url,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Write sends the samples in a single remote write request, as series of the metric
+// with the labels of the samples.
+func (w *RemoteWriter) Write(ctx context.Context, metric string, t time.Time, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	b, err := encodeWriteRequest(metric, t, samples)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bad response status %s", resp.Status)
+	}
+	return nil
+}
+
+// encodeWriteRequest returns the snappy compressed protobuf write request of the samples.
+func encodeWriteRequest(metric string, t time.Time, samples []Sample) ([]byte, error) {
+	timestamp := t.UnixNano() / int64(time.Millisecond)
+	series := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		labels := make([]prompb.Label, 0, len(s.Labels)+1)
+		labels = append(labels, prompb.Label{Name: metricNameLabel, Value: metric})
+		for name, value := range s.Labels {
+			if name == metricNameLabel {
+				continue
+			}
+			labels = append(labels, prompb.Label{Name: name, Value: value})
+		}
+		// the labels of a series must be sorted by name
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+		series = append(series, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: s.Value, Timestamp: timestamp}},
+		})
+	}
+
+	req := prompb.WriteRequest{Timeseries: series}
+	b, err := req.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, b), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"strconv"
 	"time"
 
 	"github.com/go-openapi/strfmt"
@@ -41,8 +42,14 @@ func FromAlertStateToPostableAlerts(logger log.Logger, firingStates []*state.Sta
 				u.Path = oldPath
 			}
 
+			annotations := make(map[string]string, len(alertState.Annotations)+1)
+			for k, v := range alertState.Annotations {
+				annotations[k] = v
+			}
+			annotations[ngModels.OrgIDAnnotation] = strconv.FormatInt(alertState.OrgID, 10)
+
 			alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
-				Annotations: alertState.Annotations,
+				Annotations: annotations,
 				StartsAt:    strfmt.DateTime(alertState.StartsAt),
 				EndsAt:      strfmt.DateTime(alertState.EndsAt),
 				Alert: models.Alert{
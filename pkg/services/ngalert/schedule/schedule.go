@@ -2,19 +2,26 @@ package schedule
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/ngalert/enrichment"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -29,6 +36,8 @@ type ScheduleService interface {
 	Pause() error
 	Unpause() error
 	WarmStateCache(*state.Manager)
+	SyncAndApplyConfigFromDatabase() error
+	AlertmanagersFor(orgID int64) []sender.AlertmanagerStatus
 
 	// the following are used by tests only used for tests
 	evalApplied(models.AlertRuleKey, time.Time)
@@ -42,6 +51,8 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	evalRunning := false
 	var attempt int64
 	var alertRule *models.AlertRule
+	// instanceLimitExceeded is true when the last evaluation produced more alert instances than the limit
+	var instanceLimitExceeded bool
 	for {
 		select {
 		case ctx := <-evalCh:
@@ -69,7 +80,20 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					OrgID:     alertRule.OrgID,
 					Data:      alertRule.Data,
 				}
-				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+				var (
+					results eval.Results
+					frames  data.Frames
+					err     error
+				)
+				switch {
+				case alertRule.IsRecording():
+					frames, err = sch.evaluator.RecordingEvalWithTimeout(&condition, ctx.now, evaluationTimeout(alertRule), sch.dataService)
+				case alertRule.IsHeartbeat() && len(alertRule.Data) == 0:
+					// the heartbeats are only sent to the API, there's nothing to query
+				default:
+					results, err = sch.evaluator.ConditionEvalWithTimeout(&condition, ctx.now, evaluationTimeout(alertRule),
+						previousStates(stateManager.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)), sch.dataService)
+				}
 				var (
 					end    = timeNow()
 					tenant = fmt.Sprint(alertRule.OrgID)
@@ -78,22 +102,40 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 
 				sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
 				sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
+				if sch.shard != nil {
+					sch.metrics.SchedulerShardEvalTotal.WithLabelValues(sch.shard.instanceID).Inc()
+				}
 				if err != nil {
 					sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
+					if sch.shard != nil {
+						sch.metrics.SchedulerShardEvalFailures.WithLabelValues(sch.shard.instanceID).Inc()
+					}
 					// consider saving alert instance on error
 					sch.log.Error("failed to evaluate alert rule", "title", alertRule.Title,
 						"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
 					return err
 				}
 
+				if alertRule.IsRecording() {
+					return sch.record(alertRule, frames, ctx.now)
+				}
+
+				if alertRule.IsHeartbeat() {
+					results, err = sch.evalHeartbeat(alertRule, results, ctx.now)
+					if err != nil {
+						sch.log.Error("failed to evaluate heartbeat rule", "title", alertRule.Title, "key", key, "error", err)
+						return err
+					}
+				}
+
+				results = sch.limitOrgAlertInstances(alertRule, results, len(stateManager.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)))
+				results, instanceLimitExceeded = sch.limitAlertInstances(alertRule, results, instanceLimitExceeded)
 				processedStates := stateManager.ProcessEvalResults(alertRule, results)
-				sch.saveAlertStates(processedStates)
+				sch.statePersister.save(processedStates)
 				alerts := FromAlertStateToPostableAlerts(sch.log, processedStates, stateManager, sch.appURL)
+				alerts = sch.enrichAlerts(grafanaCtx, alertRule, alerts)
 				sch.log.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts)
-				err = sch.sendAlerts(alerts)
-				if err != nil {
-					sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
-				}
+				sch.sendAlerts(key.OrgID, alerts)
 				return nil
 			}
 
@@ -122,6 +164,193 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	}
 }
 
+// record writes the result of the evaluation of a recording rule, with the labels of the rule added to the labels
+// of the series. The recording rules have no alert instances and send no notifications.
+func (sch *schedule) record(alertRule *models.AlertRule, frames data.Frames, now time.Time) error {
+	if sch.recordingWriter == nil {
+		sch.log.Error("recording rule is not written: no remote write endpoint is configured", "title", alertRule.Title, "key", alertRule.GetKey())
+		return nil
+	}
+
+	samples, err := recording.FramesToSamples(frames)
+	if err != nil {
+		sch.log.Error("invalid result of recording rule", "title", alertRule.Title, "key", alertRule.GetKey(), "error", err)
+		return nil
+	}
+	for i, sample := range samples {
+		labels := sample.Labels.Copy()
+		if labels == nil {
+			labels = data.Labels{}
+		}
+		for k, v := range alertRule.Labels {
+			labels[k] = v
+		}
+		samples[i].Labels = labels
+	}
+
+	tenant := fmt.Sprint(alertRule.OrgID)
+	if err := sch.recordingWriter.Write(context.Background(), alertRule.Record, now, samples); err != nil {
+		sch.metrics.RecordingWriteFailures.WithLabelValues(tenant).Inc()
+		sch.log.Error("failed to write recording rule", "title", alertRule.Title, "key", alertRule.GetKey(), "error", err)
+		return err
+	}
+	sch.metrics.RecordingSamplesWritten.WithLabelValues(tenant).Add(float64(len(samples)))
+	return nil
+}
+
+// evalHeartbeat returns the result of a heartbeat rule: a single alert instance which fires when the last heartbeat
+// of the rule is older than its heartbeat timeout. An evaluation of the condition of the rule which returns data is
+// a heartbeat. The rule waits for a heartbeat from the time it was last updated if it never received one.
+func (sch *schedule) evalHeartbeat(alertRule *models.AlertRule, results eval.Results, now time.Time) (eval.Results, error) {
+	if sch.ruleHeartbeatStore == nil {
+		return nil, errors.New("heartbeat rules are not supported")
+	}
+
+	for _, r := range results {
+		if r.State == eval.Normal || r.State == eval.Alerting {
+			cmd := models.SaveAlertRuleHeartbeatCommand{OrgID: alertRule.OrgID, RuleUID: alertRule.UID, ReceivedAt: now}
+			if err := sch.ruleHeartbeatStore.SaveAlertRuleHeartbeat(&cmd); err != nil {
+				return nil, fmt.Errorf("failed to save heartbeat: %w", err)
+			}
+			break
+		}
+	}
+
+	q := models.GetAlertRuleHeartbeatQuery{OrgID: alertRule.OrgID, RuleUID: alertRule.UID}
+	if err := sch.ruleHeartbeatStore.GetAlertRuleHeartbeat(&q); err != nil {
+		return nil, fmt.Errorf("failed to get last heartbeat: %w", err)
+	}
+	last := alertRule.Updated
+	if q.Result != nil {
+		last = q.Result.ReceivedAt
+	}
+
+	state := eval.Normal
+	if now.Sub(last) > time.Duration(alertRule.HeartbeatTimeoutSeconds)*time.Second {
+		state = eval.Alerting
+	}
+	return eval.Results{{
+		Instance:         data.Labels{},
+		State:            state,
+		EvaluatedAt:      now,
+		EvaluationString: fmt.Sprintf("last heartbeat: %s", last.UTC().Format(time.RFC3339)),
+	}}, nil
+}
+
+// evaluationTimeout returns the evaluation timeout of the alert rule. Unless the rule has its own timeout,
+// the default timeout is used but the evaluation can't take longer than the interval of the rule
+// so that a slow evaluation doesn't make the rule miss its next evaluation.
+func evaluationTimeout(alertRule *models.AlertRule) time.Duration {
+	if alertRule.EvaluationTimeoutSeconds > 0 {
+		return time.Duration(alertRule.EvaluationTimeoutSeconds) * time.Second
+	}
+	interval := time.Duration(alertRule.IntervalSeconds) * time.Second
+	if interval > 0 && interval < eval.DefaultEvaluationTimeout {
+		return interval
+	}
+	return eval.DefaultEvaluationTimeout
+}
+
+// previousStates returns the previous states of the alert instances of a rule for its threshold expressions. An
+// alert instance was firing if it was pending or alerting, and its labels include the labels of the expression
+// result, as the rule labels are added to them.
+func previousStates(states []*state.State) expr.PreviousStates {
+	return func(labels data.Labels) bool {
+		for _, s := range states {
+			if (s.State == eval.Pending || s.State == eval.Alerting) && s.Labels.Contains(labels) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// limitAlertInstances truncates the results of an evaluation that produced more alert instances than the limit of
+// the rule, or the limit of the configuration when the rule has none. The alert instances with the lowest labels
+// are kept, so that the same ones are kept from one evaluation to the next, and an alert instance is added that
+// fires while the limit is exceeded. The returned boolean tells whether the limit was exceeded.
+func (sch *schedule) limitAlertInstances(alertRule *models.AlertRule, results eval.Results, wasExceeded bool) (eval.Results, bool) {
+	maxAlertInstances := sch.maxAlertInstances
+	if alertRule.MaxAlertInstances > 0 {
+		maxAlertInstances = int(alertRule.MaxAlertInstances)
+	}
+	if maxAlertInstances <= 0 || len(results) == 0 {
+		return results, false
+	}
+
+	limitResult := eval.Result{
+		Instance:           data.Labels{models.InstanceLimitExceededLabel: "true"},
+		State:              eval.Normal,
+		EvaluatedAt:        results[0].EvaluatedAt,
+		EvaluationDuration: results[0].EvaluationDuration,
+	}
+	if len(results) <= maxAlertInstances {
+		if wasExceeded {
+			// resolve the alert instance of the exceeded limit
+			return append(results, limitResult), false
+		}
+		return results, false
+	}
+
+	sch.log.Warn("alert rule produced more alert instances than the limit, the extra ones are dropped", "title", alertRule.Title, "key", alertRule.GetKey(), "count", len(results), "limit", maxAlertInstances)
+	sch.metrics.InstanceLimitExceeded.WithLabelValues(fmt.Sprint(alertRule.OrgID)).Inc()
+
+	limited := lowestInstances(results, maxAlertInstances)
+	limitResult.State = eval.Alerting
+	limitResult.EvaluationString = fmt.Sprintf("%d alert instances exceed the limit of %d", len(results), maxAlertInstances)
+	return append(limited, limitResult), true
+}
+
+// limitOrgAlertInstances drops the new alert instances of the results once the organisation of the rule reached
+// its alert instance quota: the rule can't have more alert instances than the existing ones then. The alert
+// instances with the lowest labels are kept, like when the limit of alert instances of a rule is exceeded.
+func (sch *schedule) limitOrgAlertInstances(alertRule *models.AlertRule, results eval.Results, existing int) eval.Results {
+	if sch.quotaChecker == nil || len(results) <= existing {
+		return results
+	}
+
+	remaining, err := sch.quotaChecker.OrgQuotaRemaining(alertRule.OrgID, alertInstanceQuotaTarget)
+	if err != nil {
+		sch.log.Error("failed to get alert instance quota, the alert instances aren't limited", "title", alertRule.Title, "key", alertRule.GetKey(), "error", err)
+		return results
+	}
+	if remaining < 0 || int64(len(results)-existing) <= remaining {
+		return results
+	}
+
+	allowed := existing + int(remaining)
+	sch.log.Warn("alert instance quota of the organisation is reached, the new alert instances are dropped", "title", alertRule.Title, "key", alertRule.GetKey(), "count", len(results), "allowed", allowed)
+	sch.metrics.InstanceQuotaReached.WithLabelValues(fmt.Sprint(alertRule.OrgID)).Inc()
+	return lowestInstances(results, allowed)
+}
+
+// lowestInstances returns the n results with the lowest labels.
+func lowestInstances(results eval.Results, n int) eval.Results {
+	keys := make([]string, len(results))
+	indices := make([]int, len(results))
+	for i, r := range results {
+		keys[i] = r.Instance.String()
+		indices[i] = i
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return keys[indices[i]] < keys[indices[j]]
+	})
+
+	limited := make(eval.Results, 0, n+1)
+	for _, i := range indices[:n] {
+		limited = append(limited, results[i])
+	}
+	return limited
+}
+
+// alertInstanceQuotaTarget is the quota target of the alert instances.
+const alertInstanceQuotaTarget = "alert_instance"
+
+// QuotaChecker returns how many more of a quota target an organisation can have, -1 is unlimited.
+type QuotaChecker interface {
+	OrgQuotaRemaining(orgID int64, target string) (int64, error)
+}
+
 // Notifier handles the delivery of alert notifications to the end user
 type Notifier interface {
 	PutAlerts(alerts apimodels.PostableAlerts) error
@@ -164,6 +393,36 @@ type schedule struct {
 
 	notifier Notifier
 	metrics  *metrics.Metrics
+
+	// Senders help us send alerts to external Alertmanagers.
+	sendersMtx              sync.RWMutex
+	sendersCfgHash          map[int64]string
+	senders                 map[int64]*sender.Sender
+	sendAlertsTo            map[int64]models.AlertmanagersChoice
+	enrichers               map[int64]*enrichment.Enricher
+	adminConfigStore        store.AdminConfigurationStore
+	adminConfigPollInterval time.Duration
+
+	// maxAlertInstances is the maximum number of alert instances of an evaluation of the rules without their own
+	// limit, 0 is no limit.
+	maxAlertInstances int
+
+	// quotaChecker limits the alert instances of the organisations to their quota, they aren't limited if it's nil.
+	quotaChecker QuotaChecker
+
+	// recordingWriter writes the samples of the recording rules, they aren't written if it's nil.
+	recordingWriter recording.Writer
+
+	// ruleHeartbeatStore stores the heartbeats of the heartbeat rules.
+	ruleHeartbeatStore store.AlertRuleHeartbeatStore
+
+	// statePersister writes the states of the alert instances to the database.
+	statePersister *statePersister
+
+	// shard is nil unless the rule groups are sharded across the instances.
+	shard                  *shard
+	schedulerInstanceStore store.SchedulerInstanceStore
+	shardHeartbeatInterval time.Duration
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -179,6 +438,36 @@ type SchedulerCfg struct {
 	InstanceStore   store.InstanceStore
 	Notifier        Notifier
 	Metrics         *metrics.Metrics
+
+	AdminConfigStore        store.AdminConfigurationStore
+	AdminConfigPollInterval time.Duration
+
+	// MaxAlertInstances is the maximum number of alert instances an evaluation of a rule without its own limit
+	// can produce, 0 is no limit.
+	MaxAlertInstances int
+
+	// QuotaChecker limits the alert instances of each organisation to its alert_instance quota.
+	QuotaChecker QuotaChecker
+
+	// RecordingWriter writes the samples of the recording rules.
+	RecordingWriter recording.Writer
+
+	// RuleHeartbeatStore stores the heartbeats of the heartbeat rules, they aren't supported if it's nil.
+	RuleHeartbeatStore store.AlertRuleHeartbeatStore
+
+	// StatePersistInterval is how often the states of the alert instances are written to the database,
+	// they are written after every evaluation if it's 0. StatePersistBatchSize is the maximum number
+	// of states written at once.
+	StatePersistInterval  time.Duration
+	StatePersistBatchSize int
+	// StateFullSyncInterval is how often a snapshot of all the states is written to the database, 0 is never.
+	StateFullSyncInterval time.Duration
+
+	// SchedulerInstanceStore enables the sharding of the rule groups across the instances
+	// identified by InstanceID when it's set.
+	SchedulerInstanceStore store.SchedulerInstanceStore
+	InstanceID             string
+	ShardHeartbeatInterval time.Duration
 }
 
 // NewScheduler returns a new schedule.
@@ -200,6 +489,25 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL string) *s
 		notifier:        cfg.Notifier,
 		metrics:         cfg.Metrics,
 		appURL:          appURL,
+
+		sendersCfgHash:          map[int64]string{},
+		senders:                 map[int64]*sender.Sender{},
+		sendAlertsTo:            map[int64]models.AlertmanagersChoice{},
+		enrichers:               map[int64]*enrichment.Enricher{},
+		adminConfigStore:        cfg.AdminConfigStore,
+		adminConfigPollInterval: cfg.AdminConfigPollInterval,
+		maxAlertInstances:       cfg.MaxAlertInstances,
+		quotaChecker:            cfg.QuotaChecker,
+		recordingWriter:         cfg.RecordingWriter,
+		ruleHeartbeatStore:      cfg.RuleHeartbeatStore,
+
+		schedulerInstanceStore: cfg.SchedulerInstanceStore,
+		shardHeartbeatInterval: cfg.ShardHeartbeatInterval,
+	}
+	sch.statePersister = newStatePersister(cfg.Logger, cfg.C, cfg.InstanceStore, cfg.Metrics,
+		cfg.StatePersistInterval, cfg.StatePersistBatchSize, cfg.StateFullSyncInterval)
+	if cfg.SchedulerInstanceStore != nil {
+		sch.shard = newShard(cfg.InstanceID)
 	}
 	return &sch
 }
@@ -210,6 +518,7 @@ func (sch *schedule) overrideCfg(cfg SchedulerCfg) {
 	sch.heartbeat = alerting.NewTicker(cfg.C.Now(), time.Second*0, cfg.C, int64(cfg.BaseInterval.Seconds()))
 	sch.evalAppliedFunc = cfg.EvalAppliedFunc
 	sch.stopAppliedFunc = cfg.StopAppliedFunc
+	sch.statePersister.clock = cfg.C
 }
 
 func (sch *schedule) evalApplied(alertDefKey models.AlertRuleKey, now time.Time) {
@@ -246,8 +555,155 @@ func (sch *schedule) Unpause() error {
 	return nil
 }
 
+// SyncAndApplyConfigFromDatabase looks for the admin configuration in the database and adjusts the senders of the
+// external Alertmanagers and the enrichment of the alerts accordingly.
+func (sch *schedule) SyncAndApplyConfigFromDatabase() error {
+	sch.log.Debug("start of admin configuration sync")
+	cfgs, err := sch.adminConfigStore.GetAdminConfigurations()
+	if err != nil {
+		return err
+	}
+
+	sch.log.Debug("found admin configurations", "count", len(cfgs))
+
+	orgsFound := make(map[int64]struct{}, len(cfgs))
+	sendersFound := make(map[int64]struct{}, len(cfgs))
+	sch.sendersMtx.Lock()
+	for _, cfg := range cfgs {
+		orgsFound[cfg.OrgID] = struct{}{}
+		sch.sendAlertsTo[cfg.OrgID] = cfg.SendAlertsTo
+
+		delete(sch.enrichers, cfg.OrgID)
+		if cfg.Enrichment != nil {
+			e, err := enrichment.New(cfg.OrgID, cfg.Enrichment)
+			if err != nil {
+				sch.log.Error("failed to apply enrichment configuration", "err", err, "org", cfg.OrgID)
+			} else {
+				sch.enrichers[cfg.OrgID] = e
+			}
+		}
+
+		// The alerts of the organisation aren't sent to external Alertmanagers.
+		if cfg.SendAlertsTo == models.InternalAlertmanager || len(cfg.Alertmanagers) == 0 {
+			continue
+		}
+		sendersFound[cfg.OrgID] = struct{}{}
+
+		existing, ok := sch.senders[cfg.OrgID]
+		if ok {
+			// We have a running sender, check if we need to apply a new config.
+			if sch.sendersCfgHash[cfg.OrgID] == cfg.AsSHA256() {
+				sch.log.Debug("sender configuration is the same as the one running, no-op", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
+				continue
+			}
+
+			sch.log.Debug("applying new configuration to sender", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
+			if err := existing.ApplyConfig(cfg); err != nil {
+				sch.log.Error("failed to apply configuration", "err", err, "org", cfg.OrgID)
+				continue
+			}
+			sch.sendersCfgHash[cfg.OrgID] = cfg.AsSHA256()
+			continue
+		}
+
+		// No sender for this org, start a new one.
+		s := sender.New(cfg.OrgID, sch.metrics)
+		if err := s.ApplyConfig(cfg); err != nil {
+			sch.log.Error("failed to apply configuration", "err", err, "org", cfg.OrgID)
+			continue
+		}
+		sch.senders[cfg.OrgID] = s
+		sch.sendersCfgHash[cfg.OrgID] = cfg.AsSHA256()
+		sch.log.Debug("started sender", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
+	}
+
+	// Stop the senders of the organisations that no longer send alerts to external Alertmanagers.
+	sendersToStop := map[int64]*sender.Sender{}
+	for orgID, s := range sch.senders {
+		if _, exists := sendersFound[orgID]; exists {
+			continue
+		}
+		sendersToStop[orgID] = s
+		delete(sch.senders, orgID)
+		delete(sch.sendersCfgHash, orgID)
+	}
+	for orgID := range sch.sendAlertsTo {
+		if _, exists := orgsFound[orgID]; !exists {
+			delete(sch.sendAlertsTo, orgID)
+			delete(sch.enrichers, orgID)
+		}
+	}
+	sch.sendersMtx.Unlock()
+
+	// We can now stop these senders w/o having to hold a lock.
+	for orgID, s := range sendersToStop {
+		sch.log.Info("stopping sender", "org", orgID)
+		s.Stop()
+		sch.log.Info("stopped sender", "org", orgID)
+	}
+
+	sch.log.Debug("finish of admin configuration sync")
+
+	return nil
+}
+
+// AlertmanagersFor returns the delivery status of the external Alertmanagers of an organisation.
+func (sch *schedule) AlertmanagersFor(orgID int64) []sender.AlertmanagerStatus {
+	sch.sendersMtx.RLock()
+	defer sch.sendersMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return []sender.AlertmanagerStatus{}
+	}
+
+	return s.Alertmanagers()
+}
+
+func (sch *schedule) adminConfigSync(ctx context.Context) error {
+	if sch.adminConfigStore == nil {
+		return nil
+	}
+
+	if err := sch.SyncAndApplyConfigFromDatabase(); err != nil {
+		sch.log.Error("unable to sync admin configuration", "err", err)
+	}
+
+	for {
+		select {
+		case <-time.After(sch.adminConfigPollInterval):
+			if err := sch.SyncAndApplyConfigFromDatabase(); err != nil {
+				sch.log.Error("unable to sync admin configuration", "err", err)
+			}
+		case <-ctx.Done():
+			// Stop sending alerts to all external Alertmanager(s).
+			sch.sendersMtx.Lock()
+			for orgID, s := range sch.senders {
+				delete(sch.senders, orgID) // delete before we stop to make sure we don't accept any more alerts.
+				s.Stop()
+			}
+			sch.sendersMtx.Unlock()
+
+			return nil
+		}
+	}
+}
+
 func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Manager) error {
 	dispatcherGroup, ctx := errgroup.WithContext(grafanaCtx)
+	dispatcherGroup.Go(func() error {
+		return sch.adminConfigSync(ctx)
+	})
+	dispatcherGroup.Go(func() error {
+		return sch.statePersister.run(ctx, stateManager)
+	})
+	if sch.shard != nil {
+		if err := sch.syncShard(); err != nil {
+			sch.log.Error("unable to sync scheduler shard", "err", err)
+		}
+		dispatcherGroup.Go(func() error {
+			return sch.shardSync(ctx)
+		})
+	}
 	for {
 		select {
 		case tick := <-sch.heartbeat.C:
@@ -266,7 +722,17 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 				ruleInfo alertRuleInfo
 			}
 			readyToRun := make([]readyToRunItem, 0)
+			shardRuleGroups := make(map[string]struct{})
 			for _, item := range alertRules {
+				// the alert rules of the rule groups of other instances are not registered,
+				// so the routines of the ones moved to another instance are stopped below
+				if sch.shard != nil {
+					if !sch.shard.owns(item) {
+						continue
+					}
+					shardRuleGroups[fmt.Sprintf("%d/%s/%s", item.OrgID, item.NamespaceUID, item.RuleGroup)] = struct{}{}
+				}
+
 				key := item.GetKey()
 				itemVersion := item.Version
 				newRoutine := !sch.registry.exists(key)
@@ -274,6 +740,10 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 				invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 				if newRoutine && !invalidInterval {
+					if sch.shard != nil {
+						// the rule might have been evaluated by another instance until now
+						sch.warmRuleStateCache(stateManager, item)
+					}
 					dispatcherGroup.Go(func() error {
 						return sch.ruleRoutine(ctx, key, ruleInfo.evalCh, ruleInfo.stopCh, stateManager)
 					})
@@ -317,6 +787,15 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 				}
 				ruleInfo.stopCh <- struct{}{}
 				sch.registry.del(key)
+				sch.statePersister.forget(key)
+				if sch.shard != nil {
+					// the rule might be evaluated by another instance from now on
+					stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+				}
+			}
+
+			if sch.shard != nil {
+				sch.metrics.SchedulerShardRuleGroups.WithLabelValues(sch.shard.instanceID).Set(float64(len(shardRuleGroups)))
 			}
 		case <-grafanaCtx.Done():
 			waitErr := dispatcherGroup.Wait()
@@ -327,7 +806,12 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 			}
 
 			for _, v := range orgIds {
-				sch.saveAlertStates(stateManager.GetAll(v))
+				sch.statePersister.save(stateManager.GetAll(v))
+			}
+			if sch.statePersister.fullSyncInterval > 0 {
+				sch.statePersister.fullSync(stateManager)
+			} else {
+				sch.statePersister.flush()
 			}
 
 			stateManager.Close()
@@ -336,25 +820,55 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 	}
 }
 
-func (sch *schedule) sendAlerts(alerts apimodels.PostableAlerts) error {
-	return sch.notifier.PutAlerts(alerts)
+// enrichAlerts returns the alerts of the rule enriched as configured by its organisation. The alerts are sent
+// without the enrichment of the webhook if it fails.
+func (sch *schedule) enrichAlerts(ctx context.Context, rule *models.AlertRule, alerts apimodels.PostableAlerts) apimodels.PostableAlerts {
+	if len(alerts.PostableAlerts) == 0 {
+		return alerts
+	}
+
+	sch.sendersMtx.RLock()
+	e, ok := sch.enrichers[rule.OrgID]
+	sch.sendersMtx.RUnlock()
+	if !ok {
+		return alerts
+	}
+
+	enriched, err := e.Enrich(ctx, rule, alerts)
+	if err != nil {
+		sch.metrics.EnrichmentWebhookFailures.WithLabelValues(fmt.Sprint(rule.OrgID)).Inc()
+		sch.log.Error("failed to enrich alerts with the webhook", "org", rule.OrgID, "rule", rule.UID, "err", err)
+	}
+	return enriched
 }
 
-func (sch *schedule) saveAlertStates(states []*state.State) {
-	sch.log.Debug("saving alert states", "count", len(states))
-	for _, s := range states {
-		cmd := models.SaveAlertInstanceCommand{
-			RuleOrgID:         s.OrgID,
-			RuleUID:           s.AlertRuleUID,
-			Labels:            models.InstanceLabels(s.Labels),
-			State:             models.InstanceStateType(s.State.String()),
-			LastEvalTime:      s.LastEvaluationTime,
-			CurrentStateSince: s.StartsAt,
-			CurrentStateEnd:   s.EndsAt,
+// sendAlerts sends the alerts to the Alertmanagers chosen by the organisation. When the alerts are only sent to
+// external Alertmanagers and none of them is healthy, they are sent to the Grafana Alertmanager instead.
+func (sch *schedule) sendAlerts(orgID int64, alerts apimodels.PostableAlerts) {
+	if len(alerts.PostableAlerts) == 0 {
+		return
+	}
+
+	sch.sendersMtx.RLock()
+	choice, ok := sch.sendAlertsTo[orgID]
+	if !ok {
+		choice = models.InternalAlertmanager
+	}
+	s := sch.senders[orgID]
+	sch.sendersMtx.RUnlock()
+
+	sendInternally := choice != models.ExternalAlertmanagers || s == nil
+	if s != nil && choice != models.InternalAlertmanager {
+		s.SendAlerts(alerts)
+		if choice == models.ExternalAlertmanagers && !s.Healthy() {
+			sch.log.Warn("no external alertmanager is healthy, sending alerts to the internal alertmanager", "org", orgID, "count", len(alerts.PostableAlerts))
+			sendInternally = true
 		}
-		err := sch.instanceStore.SaveAlertInstance(&cmd)
-		if err != nil {
-			sch.log.Error("failed to save alert state", "uid", s.AlertRuleUID, "orgId", s.OrgID, "labels", s.Labels.String(), "state", s.State.String(), "msg", err.Error())
+	}
+
+	if sendInternally {
+		if err := sch.notifier.PutAlerts(alerts); err != nil {
+			sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
 		}
 	}
 }
@@ -363,6 +877,11 @@ func (sch *schedule) WarmStateCache(st *state.Manager) {
 	sch.log.Info("warming cache for startup")
 	st.ResetCache()
 
+	if sch.shard != nil {
+		// the states of the rules are loaded once this instance starts evaluating them
+		return
+	}
+
 	orgIds, err := sch.instanceStore.FetchOrgIds()
 	if err != nil {
 		sch.log.Error("unable to fetch orgIds", "msg", err.Error())
@@ -391,36 +910,91 @@ func (sch *schedule) WarmStateCache(st *state.Manager) {
 			sch.log.Error("unable to fetch previous state", "msg", err.Error())
 		}
 
-		for _, entry := range cmd.Result {
+		for _, entry := range sch.mergeStateSnapshot(orgId, cmd.Result) {
 			ruleForEntry, ok := ruleByUID[entry.RuleUID]
 			if !ok {
 				sch.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				sch.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &state.State{
-				AlertRuleUID:       entry.RuleUID,
-				OrgID:              entry.RuleOrgID,
-				CacheId:            cacheId,
-				Labels:             lbs,
-				State:              translateInstanceState(entry.CurrentState),
-				Results:            []state.Evaluation{},
-				StartsAt:           entry.CurrentStateSince,
-				EndsAt:             entry.CurrentStateEnd,
-				LastEvaluationTime: entry.LastEvalTime,
-				Annotations:        ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, sch.instanceToState(entry, ruleForEntry))
 		}
 	}
 	st.Put(states)
 }
 
+// mergeStateSnapshot adds the alert instances of the snapshot of the organisation to the stored alert instances,
+// keeping the most recently evaluated of the two when an alert instance is in both.
+func (sch *schedule) mergeStateSnapshot(orgID int64, instances []*models.ListAlertInstancesQueryResult) []*models.ListAlertInstancesQueryResult {
+	query := models.GetAlertInstanceSnapshotQuery{OrgID: orgID}
+	if err := sch.instanceStore.GetAlertInstanceSnapshot(&query); err != nil {
+		sch.log.Error("unable to fetch the snapshot of previous state", "org", orgID, "msg", err.Error())
+		return instances
+	}
+	if len(query.Result) == 0 {
+		return instances
+	}
+
+	instanceKey := func(entry *models.ListAlertInstancesQueryResult) string {
+		labels, _ := entry.Labels.StringKey()
+		return entry.RuleUID + "/" + labels
+	}
+	merged := make(map[string]*models.ListAlertInstancesQueryResult, len(instances)+len(query.Result))
+	for _, entry := range instances {
+		merged[instanceKey(entry)] = entry
+	}
+	for _, entry := range query.Result {
+		key := instanceKey(entry)
+		if stored, ok := merged[key]; !ok || entry.LastEvalTime.After(stored.LastEvalTime) {
+			merged[key] = entry
+		}
+	}
+
+	result := make([]*models.ListAlertInstancesQueryResult, 0, len(merged))
+	for _, entry := range merged {
+		result = append(result, entry)
+	}
+	return result
+}
+
+// warmRuleStateCache loads the states of an alert rule from the database.
+func (sch *schedule) warmRuleStateCache(st *state.Manager, rule *models.AlertRule) {
+	cmd := models.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	}
+	if err := sch.instanceStore.ListAlertInstances(&cmd); err != nil {
+		sch.log.Error("unable to fetch previous state", "msg", err.Error())
+		return
+	}
+
+	states := make([]*state.State, 0, len(cmd.Result))
+	for _, entry := range cmd.Result {
+		states = append(states, sch.instanceToState(entry, rule))
+	}
+	st.Put(states)
+}
+
+func (sch *schedule) instanceToState(entry *models.ListAlertInstancesQueryResult, rule *models.AlertRule) *state.State {
+	lbs := map[string]string(entry.Labels)
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		sch.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &state.State{
+		AlertRuleUID:       entry.RuleUID,
+		OrgID:              entry.RuleOrgID,
+		CacheId:            cacheId,
+		Labels:             lbs,
+		State:              translateInstanceState(entry.CurrentState),
+		Results:            []state.Evaluation{},
+		StartsAt:           entry.CurrentStateSince,
+		EndsAt:             entry.CurrentStateEnd,
+		LastEvaluationTime: entry.LastEvalTime,
+		Annotations:        rule.Annotations,
+	}
+}
+
 func translateInstanceState(state models.InstanceStateType) eval.State {
 	switch {
 	case state == models.InstanceStateFiring:
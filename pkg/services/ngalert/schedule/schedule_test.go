@@ -70,6 +70,19 @@ func TestWarmStateCache(t *testing.T) {
 			EndsAt:             evaluationTime.Add(1 * time.Minute),
 			LastEvaluationTime: evaluationTime,
 			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
+		}, {
+			AlertRuleUID: rule.UID,
+			OrgID:        rule.OrgID,
+			CacheId:      `[["test3","testValue3"]]`,
+			Labels:       data.Labels{"test3": "testValue3"},
+			State:        eval.Alerting,
+			Results: []state.Evaluation{
+				{EvaluationTime: evaluationTime, EvaluationState: eval.Alerting},
+			},
+			StartsAt:           evaluationTime.Add(-1 * time.Minute),
+			EndsAt:             evaluationTime.Add(1 * time.Minute),
+			LastEvaluationTime: evaluationTime,
+			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 		},
 	}
 
@@ -96,6 +109,33 @@ func TestWarmStateCache(t *testing.T) {
 	}
 	_ = dbstore.SaveAlertInstance(saveCmd2)
 
+	// the snapshot has an older state of the second instance and a third one that wasn't written yet
+	snapshotCmd := &models.SaveAlertInstanceSnapshotCommand{
+		OrgID: rule.OrgID,
+		Instances: []models.SaveAlertInstanceCommand{
+			{
+				RuleOrgID:         rule.OrgID,
+				RuleUID:           rule.UID,
+				Labels:            models.InstanceLabels{"test2": "testValue2"},
+				State:             models.InstanceStateNormal,
+				LastEvalTime:      evaluationTime.Add(-1 * time.Minute),
+				CurrentStateSince: evaluationTime.Add(-2 * time.Minute),
+				CurrentStateEnd:   evaluationTime,
+			},
+			{
+				RuleOrgID:         rule.OrgID,
+				RuleUID:           rule.UID,
+				Labels:            models.InstanceLabels{"test3": "testValue3"},
+				State:             models.InstanceStateFiring,
+				LastEvalTime:      evaluationTime,
+				CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
+				CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+			},
+		},
+		Created: evaluationTime,
+	}
+	require.NoError(t, dbstore.SaveAlertInstanceSnapshot(snapshotCmd))
+
 	t.Cleanup(registry.ClearOverrides)
 
 	schedCfg := schedule.SchedulerCfg{
@@ -108,7 +148,7 @@ func TestWarmStateCache(t *testing.T) {
 		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
 	}
 	sched := schedule.NewScheduler(schedCfg, nil, "http://localhost")
-	st := state.NewManager(schedCfg.Logger, nilMetrics)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, nil)
 	sched.WarmStateCache(st)
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -157,7 +197,7 @@ func TestAlertingTicker(t *testing.T) {
 
 	ctx := context.Background()
 
-	st := state.NewManager(schedCfg.Logger, nilMetrics)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, nil)
 	go func() {
 		err := sched.Ticker(ctx, st)
 		require.NoError(t, err)
@@ -223,6 +263,110 @@ func TestAlertingTicker(t *testing.T) {
 	})
 }
 
+func TestShardedAlertingTicker(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, 1)
+	t.Cleanup(registry.ClearOverrides)
+
+	for i := 0; i < 5; i++ {
+		tests.CreateTestAlertRule(t, dbstore, 1)
+	}
+	q := models.ListAlertRulesQuery{}
+	require.NoError(t, dbstore.GetAlertRulesForScheduling(&q))
+	rules := q.Result
+
+	mockedClock := clock.NewMock()
+	type shardEvalAppliedInfo struct {
+		instance string
+		evalAppliedInfo
+	}
+	evalAppliedCh := make(chan shardEvalAppliedInfo, 2*len(rules))
+
+	// both instances are known before the schedulers start so that they agree on the shards from the 1st tick
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("instance-a", mockedClock.Now()))
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("instance-b", mockedClock.Now()))
+
+	cancels := map[string]context.CancelFunc{}
+	stopped := map[string]chan struct{}{}
+	for _, instance := range []string{"instance-a", "instance-b"} {
+		instance := instance
+		schedCfg := schedule.SchedulerCfg{
+			C:            mockedClock,
+			BaseInterval: time.Second,
+			EvalAppliedFunc: func(alertDefKey models.AlertRuleKey, now time.Time) {
+				evalAppliedCh <- shardEvalAppliedInfo{instance: instance, evalAppliedInfo: evalAppliedInfo{alertDefKey: alertDefKey, now: now}}
+			},
+			RuleStore:              dbstore,
+			InstanceStore:          dbstore,
+			Logger:                 log.New("ngalert schedule test", "instance", instance),
+			Metrics:                metrics.NewMetrics(prometheus.NewRegistry()),
+			SchedulerInstanceStore: dbstore,
+			InstanceID:             instance,
+			ShardHeartbeatInterval: time.Second,
+		}
+		sched := schedule.NewScheduler(schedCfg, nil, "http://localhost")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels[instance] = cancel
+		done := make(chan struct{})
+		stopped[instance] = done
+		st := state.NewManager(schedCfg.Logger, nilMetrics, nil)
+		go func() {
+			defer close(done)
+			_ = sched.Ticker(ctx, st)
+		}()
+	}
+	t.Cleanup(func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	})
+	runtime.Gosched()
+
+	// evaluatedBy returns the instance that evaluated each alert rule at the tick
+	evaluatedBy := func(tick time.Time) map[models.AlertRuleKey]string {
+		evaluated := make(map[models.AlertRuleKey]string, len(rules))
+		timeout := time.After(2 * time.Second)
+		for len(evaluated) < len(rules) {
+			select {
+			case info := <-evalAppliedCh:
+				if !info.now.Equal(tick) {
+					continue
+				}
+				_, ok := evaluated[info.alertDefKey]
+				require.False(t, ok, "alert rule %v evaluated more than once", info.alertDefKey)
+				evaluated[info.alertDefKey] = info.instance
+			case <-timeout:
+				t.Fatalf("cycle has expired, evaluated alert rules: %v", evaluated)
+			}
+		}
+		return evaluated
+	}
+
+	var firstTick map[models.AlertRuleKey]string
+	t.Run("every alert rule is evaluated by a single instance", func(t *testing.T) {
+		firstTick = evaluatedBy(advanceClock(t, mockedClock))
+		for _, rule := range rules {
+			require.Contains(t, firstTick, rule.GetKey())
+		}
+	})
+
+	t.Run("every alert rule keeps being evaluated by the same instance", func(t *testing.T) {
+		require.Equal(t, firstTick, evaluatedBy(advanceClock(t, mockedClock)))
+	})
+
+	cancels["instance-b"]()
+	<-stopped["instance-b"]
+
+	t.Run("the alert rules of an instance leaving are moved to the other instances", func(t *testing.T) {
+		// the heartbeat and the evaluation of the next tick race, so the rebalance is only checked on the tick after
+		advanceClock(t, mockedClock)
+		evaluated := evaluatedBy(advanceClock(t, mockedClock))
+		for _, rule := range rules {
+			require.Equal(t, "instance-a", evaluated[rule.GetKey()])
+		}
+	})
+}
+
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
 	timeout := time.After(time.Second)
 
@@ -0,0 +1,409 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestEvaluationTimeout(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rule     *models.AlertRule
+		expected time.Duration
+	}{
+		{
+			desc:     "the timeout of the rule is used",
+			rule:     &models.AlertRule{IntervalSeconds: 60, EvaluationTimeoutSeconds: 20},
+			expected: 20 * time.Second,
+		},
+		{
+			desc:     "the default timeout is used for rules with a longer interval",
+			rule:     &models.AlertRule{IntervalSeconds: 60},
+			expected: eval.DefaultEvaluationTimeout,
+		},
+		{
+			desc:     "the default timeout is capped at the interval",
+			rule:     &models.AlertRule{IntervalSeconds: 10},
+			expected: 10 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, evaluationTimeout(tc.rule))
+		})
+	}
+}
+
+func TestPreviousStates(t *testing.T) {
+	previous := previousStates([]*state.State{
+		{State: eval.Alerting, Labels: data.Labels{"__alert_rule_uid__": "rule", "host": "a"}},
+		{State: eval.Pending, Labels: data.Labels{"__alert_rule_uid__": "rule", "host": "b"}},
+		{State: eval.Normal, Labels: data.Labels{"__alert_rule_uid__": "rule", "host": "c"}},
+	})
+
+	require.True(t, previous(data.Labels{"host": "a"}))
+	require.True(t, previous(data.Labels{"host": "b"}))
+	require.False(t, previous(data.Labels{"host": "c"}))
+	require.False(t, previous(data.Labels{"host": "d"}))
+}
+
+func TestLimitAlertInstances(t *testing.T) {
+	sch := &schedule{
+		log:               log.New("ngalert schedule test"),
+		metrics:           metrics.NewMetrics(prometheus.NewRegistry()),
+		maxAlertInstances: 2,
+	}
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "rule"}
+	results := func(instances ...string) eval.Results {
+		r := make(eval.Results, 0, len(instances))
+		for _, instance := range instances {
+			r = append(r, eval.Result{Instance: data.Labels{"instance": instance}, State: eval.Alerting})
+		}
+		return r
+	}
+	instances := func(results eval.Results) []string {
+		var i []string
+		for _, r := range results {
+			i = append(i, r.Instance["instance"])
+		}
+		return i
+	}
+
+	t.Run("the results are kept as they are under the limit", func(t *testing.T) {
+		limited, exceeded := sch.limitAlertInstances(rule, results("b", "a"), false)
+		require.False(t, exceeded)
+		require.Equal(t, []string{"b", "a"}, instances(limited))
+	})
+
+	t.Run("the alert instances with the lowest labels are kept over the limit", func(t *testing.T) {
+		limited, exceeded := sch.limitAlertInstances(rule, results("c", "a", "b"), false)
+		require.True(t, exceeded)
+		require.Len(t, limited, 3)
+		require.Equal(t, []string{"a", "b"}, instances(limited[:2]))
+		require.Equal(t, data.Labels{models.InstanceLimitExceededLabel: "true"}, limited[2].Instance)
+		require.Equal(t, eval.Alerting, limited[2].State)
+		require.Equal(t, "3 alert instances exceed the limit of 2", limited[2].EvaluationString)
+		require.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.InstanceLimitExceeded.WithLabelValues("1")))
+	})
+
+	t.Run("the alert instance of the exceeded limit is resolved once under the limit", func(t *testing.T) {
+		limited, exceeded := sch.limitAlertInstances(rule, results("a"), true)
+		require.False(t, exceeded)
+		require.Len(t, limited, 2)
+		require.Equal(t, data.Labels{models.InstanceLimitExceededLabel: "true"}, limited[1].Instance)
+		require.Equal(t, eval.Normal, limited[1].State)
+	})
+
+	t.Run("there is no limit when the maximum is 0", func(t *testing.T) {
+		unlimited := &schedule{log: sch.log, metrics: sch.metrics}
+		limited, exceeded := unlimited.limitAlertInstances(rule, results("c", "a", "b"), false)
+		require.False(t, exceeded)
+		require.Len(t, limited, 3)
+	})
+
+	t.Run("the limit of the rule overrides the limit of the configuration", func(t *testing.T) {
+		limitedRule := &models.AlertRule{OrgID: 1, UID: "limited", Title: "limited", MaxAlertInstances: 1}
+		limited, exceeded := sch.limitAlertInstances(limitedRule, results("b", "a"), false)
+		require.True(t, exceeded)
+		require.Equal(t, []string{"a"}, instances(limited[:1]))
+		require.Equal(t, "2 alert instances exceed the limit of 1", limited[1].EvaluationString)
+
+		unlimited := &schedule{log: sch.log, metrics: sch.metrics}
+		limited, exceeded = unlimited.limitAlertInstances(limitedRule, results("b", "a"), false)
+		require.True(t, exceeded)
+		require.Len(t, limited, 2)
+	})
+}
+
+type fakeQuotaChecker struct {
+	remaining int64
+}
+
+func (f *fakeQuotaChecker) OrgQuotaRemaining(orgID int64, target string) (int64, error) {
+	return f.remaining, nil
+}
+
+func TestLimitOrgAlertInstances(t *testing.T) {
+	quota := &fakeQuotaChecker{}
+	sch := &schedule{
+		log:          log.New("ngalert schedule test"),
+		metrics:      metrics.NewMetrics(prometheus.NewRegistry()),
+		quotaChecker: quota,
+	}
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "rule"}
+	results := eval.Results{
+		{Instance: data.Labels{"instance": "c"}, State: eval.Alerting},
+		{Instance: data.Labels{"instance": "a"}, State: eval.Alerting},
+		{Instance: data.Labels{"instance": "b"}, State: eval.Alerting},
+	}
+
+	t.Run("the results are kept as they are under the quota", func(t *testing.T) {
+		quota.remaining = 2
+		require.Len(t, sch.limitOrgAlertInstances(rule, results, 1), 3)
+		quota.remaining = -1
+		require.Len(t, sch.limitOrgAlertInstances(rule, results, 0), 3)
+	})
+
+	t.Run("the results are kept as they are without new alert instances", func(t *testing.T) {
+		quota.remaining = 0
+		require.Len(t, sch.limitOrgAlertInstances(rule, results, 3), 3)
+	})
+
+	t.Run("the new alert instances over the quota are dropped", func(t *testing.T) {
+		quota.remaining = 1
+		limited := sch.limitOrgAlertInstances(rule, results, 1)
+		require.Len(t, limited, 2)
+		require.Equal(t, "a", limited[0].Instance["instance"])
+		require.Equal(t, "b", limited[1].Instance["instance"])
+		require.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.InstanceQuotaReached.WithLabelValues("1")))
+	})
+}
+
+type fakeRecordingWriter struct {
+	metric  string
+	t       time.Time
+	samples []recording.Sample
+	err     error
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, metric string, t time.Time, samples []recording.Sample) error {
+	w.metric, w.t, w.samples = metric, t, samples
+	return w.err
+}
+
+func TestRecord(t *testing.T) {
+	writer := &fakeRecordingWriter{}
+	sch := &schedule{
+		log:             log.New("ngalert schedule test"),
+		metrics:         metrics.NewMetrics(prometheus.NewRegistry()),
+		recordingWriter: writer,
+	}
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "rule", Record: "job:errors:rate5m", Labels: map[string]string{"team": "backend"}}
+	value := 0.5
+	frames := data.Frames{data.NewFrame("", data.NewField("", data.Labels{"job": "a"}, []*float64{&value}))}
+	now := time.Unix(60, 0)
+
+	t.Run("the samples are written with the labels of the rule", func(t *testing.T) {
+		require.NoError(t, sch.record(rule, frames, now))
+		require.Equal(t, "job:errors:rate5m", writer.metric)
+		require.Equal(t, now, writer.t)
+		require.Equal(t, []recording.Sample{{Labels: data.Labels{"job": "a", "team": "backend"}, Value: 0.5}}, writer.samples)
+		require.Equal(t, data.Labels{"job": "a"}, frames[0].Fields[0].Labels)
+		require.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.RecordingSamplesWritten.WithLabelValues("1")))
+	})
+
+	t.Run("failed writes return an error", func(t *testing.T) {
+		writer.err = errors.New("unavailable")
+		require.Error(t, sch.record(rule, frames, now))
+		require.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.RecordingWriteFailures.WithLabelValues("1")))
+	})
+
+	t.Run("nothing is written without writer", func(t *testing.T) {
+		unconfigured := &schedule{log: sch.log, metrics: sch.metrics}
+		require.NoError(t, unconfigured.record(rule, frames, now))
+	})
+}
+
+type fakeRuleHeartbeatStore struct {
+	heartbeats map[string]time.Time
+}
+
+func (s *fakeRuleHeartbeatStore) SaveAlertRuleHeartbeat(cmd *models.SaveAlertRuleHeartbeatCommand) error {
+	s.heartbeats[cmd.RuleUID] = cmd.ReceivedAt
+	return nil
+}
+
+func (s *fakeRuleHeartbeatStore) GetAlertRuleHeartbeat(query *models.GetAlertRuleHeartbeatQuery) error {
+	if t, ok := s.heartbeats[query.RuleUID]; ok {
+		query.Result = &models.AlertRuleHeartbeat{OrgID: query.OrgID, RuleUID: query.RuleUID, ReceivedAt: t}
+	}
+	return nil
+}
+
+func TestEvalHeartbeat(t *testing.T) {
+	heartbeatStore := &fakeRuleHeartbeatStore{heartbeats: map[string]time.Time{}}
+	sch := &schedule{log: log.New("ngalert schedule test"), ruleHeartbeatStore: heartbeatStore}
+	now := time.Unix(3600, 0)
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", HeartbeatTimeoutSeconds: 600, Updated: now.Add(-time.Hour)}
+
+	t.Run("rule without heartbeat waits from its last update", func(t *testing.T) {
+		results, err := sch.evalHeartbeat(rule, nil, now)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, eval.Alerting, results[0].State)
+
+		updated := *rule
+		updated.Updated = now.Add(-time.Minute)
+		results, err = sch.evalHeartbeat(&updated, nil, now)
+		require.NoError(t, err)
+		require.Equal(t, eval.Normal, results[0].State)
+	})
+
+	t.Run("rule fires once its last heartbeat is older than the timeout", func(t *testing.T) {
+		heartbeatStore.heartbeats["rule"] = now.Add(-5 * time.Minute)
+		results, err := sch.evalHeartbeat(rule, nil, now)
+		require.NoError(t, err)
+		require.Equal(t, eval.Normal, results[0].State)
+
+		results, err = sch.evalHeartbeat(rule, nil, now.Add(10*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, eval.Alerting, results[0].State)
+	})
+
+	t.Run("evaluation which returns data is a heartbeat", func(t *testing.T) {
+		later := now.Add(time.Hour)
+		_, err := sch.evalHeartbeat(rule, eval.Results{{State: eval.NoData}}, later)
+		require.NoError(t, err)
+		require.NotEqual(t, later, heartbeatStore.heartbeats["rule"])
+
+		results, err := sch.evalHeartbeat(rule, eval.Results{{State: eval.Alerting}}, later)
+		require.NoError(t, err)
+		require.Equal(t, later, heartbeatStore.heartbeats["rule"])
+		require.Equal(t, eval.Normal, results[0].State)
+	})
+
+	t.Run("heartbeat rules are not supported without store", func(t *testing.T) {
+		unconfigured := &schedule{log: sch.log}
+		_, err := unconfigured.evalHeartbeat(rule, nil, now)
+		require.Error(t, err)
+	})
+}
+
+type fakeAdminConfigStore struct {
+	store.AdminConfigurationStore
+	cfgs []*models.AdminConfiguration
+}
+
+func (s *fakeAdminConfigStore) GetAdminConfigurations() ([]*models.AdminConfiguration, error) {
+	return s.cfgs, nil
+}
+
+func TestEnrichAlerts(t *testing.T) {
+	adminConfigStore := &fakeAdminConfigStore{cfgs: []*models.AdminConfiguration{{
+		OrgID:        1,
+		SendAlertsTo: models.InternalAlertmanager,
+		Enrichment:   &models.EnrichmentConfig{Labels: map[string]string{"team": "ops"}},
+	}}}
+	sch := NewScheduler(SchedulerCfg{
+		C:                clock.NewMock(),
+		Logger:           log.New("ngalert schedule test"),
+		Metrics:          metrics.NewMetrics(prometheus.NewRegistry()),
+		AdminConfigStore: adminConfigStore,
+	}, nil, "")
+	alerts := apimodels.PostableAlerts{PostableAlerts: []amv2.PostableAlert{{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "test"}}}}}
+
+	t.Run("the alerts are enriched as configured by their organisation", func(t *testing.T) {
+		require.NoError(t, sch.SyncAndApplyConfigFromDatabase())
+		enriched := sch.enrichAlerts(context.Background(), &models.AlertRule{OrgID: 1}, alerts)
+		require.Equal(t, amv2.LabelSet{"alertname": "test", "team": "ops"}, enriched.PostableAlerts[0].Labels)
+
+		enriched = sch.enrichAlerts(context.Background(), &models.AlertRule{OrgID: 2}, alerts)
+		require.Equal(t, alerts, enriched)
+	})
+
+	t.Run("the alerts are sent without the enrichment of a failed webhook", func(t *testing.T) {
+		adminConfigStore.cfgs[0].Enrichment.Webhook = &models.EnrichmentWebhook{URL: "http://127.0.0.1:0"}
+		require.NoError(t, sch.SyncAndApplyConfigFromDatabase())
+		enriched := sch.enrichAlerts(context.Background(), &models.AlertRule{OrgID: 1}, alerts)
+		require.Equal(t, amv2.LabelSet{"alertname": "test", "team": "ops"}, enriched.PostableAlerts[0].Labels)
+		require.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.EnrichmentWebhookFailures.WithLabelValues("1")))
+	})
+
+	t.Run("the alerts are no longer enriched once the configuration is removed", func(t *testing.T) {
+		adminConfigStore.cfgs = nil
+		require.NoError(t, sch.SyncAndApplyConfigFromDatabase())
+		require.Equal(t, alerts, sch.enrichAlerts(context.Background(), &models.AlertRule{OrgID: 1}, alerts))
+	})
+}
+
+type fakeInstanceStore struct {
+	store.InstanceStore
+	batches [][]models.SaveAlertInstanceCommand
+}
+
+func (s *fakeInstanceStore) SaveAlertInstances(cmd *models.SaveAlertInstancesCommand) error {
+	s.batches = append(s.batches, cmd.Instances)
+	return nil
+}
+
+func TestStatePersister(t *testing.T) {
+	now := time.Unix(60, 0)
+	newState := func(uid string, labels data.Labels, s eval.State) *state.State {
+		return &state.State{OrgID: 1, AlertRuleUID: uid, Labels: labels, State: s, LastEvaluationTime: now}
+	}
+	states := []*state.State{
+		newState("rule1", data.Labels{"job": "a"}, eval.Normal),
+		newState("rule1", data.Labels{"job": "b"}, eval.Normal),
+		newState("rule2", data.Labels{"job": "a"}, eval.Normal),
+	}
+
+	t.Run("the states are written right away without interval", func(t *testing.T) {
+		instanceStore := &fakeInstanceStore{}
+		p := newStatePersister(log.New("ngalert schedule test"), clock.NewMock(), instanceStore, metrics.NewMetrics(prometheus.NewRegistry()), 0, 2, 0)
+		p.save(states)
+		require.Len(t, instanceStore.batches, 2)
+		require.Len(t, instanceStore.batches[0], 2)
+		require.Len(t, instanceStore.batches[1], 1)
+		require.Equal(t, float64(3), testutil.ToFloat64(p.metrics.StatePersistWrites))
+	})
+
+	t.Run("the latest states are written on flush with interval", func(t *testing.T) {
+		instanceStore := &fakeInstanceStore{}
+		p := newStatePersister(log.New("ngalert schedule test"), clock.NewMock(), instanceStore, metrics.NewMetrics(prometheus.NewRegistry()), time.Minute, 10, 0)
+		p.save(states)
+		p.save([]*state.State{newState("rule1", data.Labels{"job": "a"}, eval.Alerting)})
+		require.Empty(t, instanceStore.batches)
+		require.Equal(t, float64(3), testutil.ToFloat64(p.metrics.StatePersistPending))
+
+		p.forget(models.AlertRuleKey{OrgID: 1, UID: "rule2"})
+		p.flush()
+		require.Len(t, instanceStore.batches, 1)
+		require.ElementsMatch(t, []models.SaveAlertInstanceCommand{
+			{RuleOrgID: 1, RuleUID: "rule1", Labels: models.InstanceLabels{"job": "a"}, State: models.InstanceStateFiring, LastEvalTime: now},
+			{RuleOrgID: 1, RuleUID: "rule1", Labels: models.InstanceLabels{"job": "b"}, State: models.InstanceStateNormal, LastEvalTime: now},
+		}, instanceStore.batches[0])
+		require.Equal(t, float64(0), testutil.ToFloat64(p.metrics.StatePersistPending))
+
+		p.flush()
+		require.Len(t, instanceStore.batches, 1)
+	})
+
+	t.Run("the buffered states are flushed when stopped", func(t *testing.T) {
+		instanceStore := &fakeInstanceStore{}
+		p := newStatePersister(log.New("ngalert schedule test"), clock.NewMock(), instanceStore, metrics.NewMetrics(prometheus.NewRegistry()), time.Minute, 10, 0)
+		p.save(states)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, p.run(ctx, nil))
+		require.Len(t, instanceStore.batches, 1)
+		require.Len(t, instanceStore.batches[0], 3)
+	})
+
+	t.Run("a full batch triggers a flush", func(t *testing.T) {
+		p := newStatePersister(log.New("ngalert schedule test"), clock.NewMock(), &fakeInstanceStore{}, metrics.NewMetrics(prometheus.NewRegistry()), time.Minute, 2, 0)
+		p.save(states[:1])
+		require.Empty(t, p.flushCh)
+		p.save(states[1:])
+		require.Len(t, p.flushCh, 1)
+	})
+}
@@ -0,0 +1,129 @@
+package schedule
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// instanceTimeoutHeartbeats is the number of heartbeats an instance of the sharded scheduler can miss
+// before it's considered gone and its rule groups are moved to the other instances.
+const instanceTimeoutHeartbeats = 4
+
+// shard is the part of the rule groups evaluated by this instance when the scheduler is sharded.
+// The rule groups are assigned to the active instances with rendezvous hashing, so when an instance
+// joins or leaves only the rule groups it gains or loses are moved.
+type shard struct {
+	mtx        sync.RWMutex
+	instanceID string
+	instances  []string
+}
+
+func newShard(instanceID string) *shard {
+	return &shard{instanceID: instanceID, instances: []string{instanceID}}
+}
+
+// setInstances replaces the active instances of the sharded scheduler and returns true if they changed.
+func (s *shard) setInstances(instances []string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	changed := len(instances) != len(s.instances)
+	for i := 0; !changed && i < len(instances); i++ {
+		changed = instances[i] != s.instances[i]
+	}
+	s.instances = instances
+	return changed
+}
+
+// owns returns true if the rule group of the alert rule is evaluated by this instance.
+func (s *shard) owns(rule *models.AlertRule) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return ruleGroupOwner(rule, s.instances) == s.instanceID
+}
+
+// ruleGroupOwner returns the instance that evaluates the rule group of the alert rule,
+// or an empty string if there are no instances.
+func ruleGroupOwner(rule *models.AlertRule, instances []string) string {
+	var (
+		owner string
+		max   uint64
+	)
+	for _, instance := range instances {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(instance))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(strconv.FormatInt(rule.OrgID, 10)))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(rule.NamespaceUID))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(rule.RuleGroup))
+		if sum := mix64(h.Sum64()); owner == "" || sum > max {
+			owner, max = instance, sum
+		}
+	}
+	return owner
+}
+
+// mix64 is the finalizer of MurmurHash3, FNV alone doesn't spread similar inputs
+// well enough across the high bits compared by ruleGroupOwner.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// syncShard sends the heartbeat of this instance and refreshes the instances the rule groups are sharded across.
+func (sch *schedule) syncShard() error {
+	now := sch.clock.Now()
+	if err := sch.schedulerInstanceStore.HeartbeatSchedulerInstance(sch.shard.instanceID, now); err != nil {
+		return err
+	}
+	if _, err := sch.schedulerInstanceStore.DeleteSchedulerInstancesBefore(now.Add(-instanceTimeoutHeartbeats * sch.shardHeartbeatInterval)); err != nil {
+		return err
+	}
+	instances, err := sch.schedulerInstanceStore.GetSchedulerInstances()
+	if err != nil {
+		return err
+	}
+
+	instanceIDs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		instanceIDs = append(instanceIDs, instance.InstanceID)
+	}
+	if sch.shard.setInstances(instanceIDs) {
+		sch.log.Info("scheduler instances changed, rebalancing rule groups", "instance", sch.shard.instanceID, "instances", instanceIDs)
+		sch.metrics.SchedulerShardRebalances.Inc()
+	}
+	sch.metrics.SchedulerShardInstances.Set(float64(len(instanceIDs)))
+	return nil
+}
+
+func (sch *schedule) shardSync(ctx context.Context) error {
+	ticker := sch.clock.Ticker(sch.shardHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := sch.syncShard(); err != nil {
+				sch.log.Error("unable to sync scheduler shard", "err", err)
+			}
+		case <-ctx.Done():
+			// Leave right away so that the other instances take over the rule groups of this one
+			// without waiting for it to time out.
+			if err := sch.schedulerInstanceStore.DeleteSchedulerInstance(sch.shard.instanceID); err != nil {
+				sch.log.Error("unable to leave scheduler shard", "err", err)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRuleGroupOwner(t *testing.T) {
+	rules := make([]*models.AlertRule, 0, 100)
+	for i := 0; i < 100; i++ {
+		rules = append(rules, &models.AlertRule{OrgID: 1, NamespaceUID: "namespace", RuleGroup: fmt.Sprintf("group-%d", i)})
+	}
+
+	instances := []string{"instance-a", "instance-b", "instance-c"}
+	owners := make(map[string]string, len(rules))
+	rulesByInstance := map[string]int{}
+	for _, rule := range rules {
+		owner := ruleGroupOwner(rule, instances)
+		require.Contains(t, instances, owner)
+		owners[rule.RuleGroup] = owner
+		rulesByInstance[owner]++
+	}
+	for _, instance := range instances {
+		require.Greater(t, rulesByInstance[instance], 20, "the rule groups are spread across the instances")
+	}
+
+	t.Run("the rule groups of an instance leaving are the only ones moved", func(t *testing.T) {
+		for _, rule := range rules {
+			owner := ruleGroupOwner(rule, []string{"instance-a", "instance-c"})
+			if owners[rule.RuleGroup] != "instance-b" {
+				require.Equal(t, owners[rule.RuleGroup], owner)
+			} else {
+				require.NotEqual(t, "instance-b", owner)
+			}
+		}
+	})
+
+	t.Run("the rule groups moved to an instance joining come from the other instances", func(t *testing.T) {
+		for _, rule := range rules {
+			owner := ruleGroupOwner(rule, append(instances, "instance-d"))
+			if owner != "instance-d" {
+				require.Equal(t, owners[rule.RuleGroup], owner)
+			}
+		}
+	})
+
+	t.Run("all the rules of a rule group have the same owner", func(t *testing.T) {
+		rule := &models.AlertRule{OrgID: 1, NamespaceUID: "namespace", RuleGroup: "group-1", UID: "another-rule"}
+		require.Equal(t, owners["group-1"], ruleGroupOwner(rule, instances))
+	})
+}
+
+func TestShardSetInstances(t *testing.T) {
+	s := newShard("instance-a")
+	require.False(t, s.setInstances([]string{"instance-a"}))
+	require.True(t, s.setInstances([]string{"instance-a", "instance-b"}))
+	require.False(t, s.setInstances([]string{"instance-a", "instance-b"}))
+	require.True(t, s.setInstances([]string{"instance-a", "instance-c"}))
+}
@@ -0,0 +1,206 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// defaultStatePersistBatchSize is the maximum number of alert instances written at once unless another size is given.
+const defaultStatePersistBatchSize = 100
+
+// statePersister writes the states of the alert instances to the database. The states are written right away
+// unless an interval is set, they are then buffered and written in batches every interval, only the latest state
+// of an alert instance being written. A compressed snapshot of all the states is written every full sync interval
+// if it's set.
+type statePersister struct {
+	log     log.Logger
+	clock   clock.Clock
+	store   store.InstanceStore
+	metrics *metrics.Metrics
+
+	interval         time.Duration
+	batchSize        int
+	fullSyncInterval time.Duration
+
+	mtx     sync.Mutex
+	pending map[string]models.SaveAlertInstanceCommand
+	// flushCh is signalled when a batch of states is pending.
+	flushCh chan struct{}
+}
+
+func newStatePersister(logger log.Logger, c clock.Clock, instanceStore store.InstanceStore, m *metrics.Metrics, interval time.Duration, batchSize int, fullSyncInterval time.Duration) *statePersister {
+	if batchSize <= 0 {
+		batchSize = defaultStatePersistBatchSize
+	}
+	return &statePersister{
+		log:              logger,
+		clock:            c,
+		store:            instanceStore,
+		metrics:          m,
+		interval:         interval,
+		batchSize:        batchSize,
+		fullSyncInterval: fullSyncInterval,
+		pending:          map[string]models.SaveAlertInstanceCommand{},
+		flushCh:          make(chan struct{}, 1),
+	}
+}
+
+// save writes the states, or buffers them until the next flush if the states are written in batches.
+func (p *statePersister) save(states []*state.State) {
+	if p.interval <= 0 {
+		p.write(toSaveAlertInstanceCommands(states))
+		return
+	}
+
+	p.mtx.Lock()
+	for _, cmd := range toSaveAlertInstanceCommands(states) {
+		p.pending[pendingStateKey(cmd)] = cmd
+	}
+	full := len(p.pending) >= p.batchSize
+	p.metrics.StatePersistPending.Set(float64(len(p.pending)))
+	p.mtx.Unlock()
+
+	if full {
+		select {
+		case p.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// forget drops the buffered states of the alert rule, so that the alert instances of a deleted rule aren't written
+// back after being deleted.
+func (p *statePersister) forget(key models.AlertRuleKey) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for k, cmd := range p.pending {
+		if cmd.RuleOrgID == key.OrgID && cmd.RuleUID == key.UID {
+			delete(p.pending, k)
+		}
+	}
+	p.metrics.StatePersistPending.Set(float64(len(p.pending)))
+}
+
+// flush writes the buffered states.
+func (p *statePersister) flush() {
+	p.mtx.Lock()
+	cmds := make([]models.SaveAlertInstanceCommand, 0, len(p.pending))
+	for _, cmd := range p.pending {
+		cmds = append(cmds, cmd)
+	}
+	p.pending = map[string]models.SaveAlertInstanceCommand{}
+	p.metrics.StatePersistPending.Set(0)
+	p.mtx.Unlock()
+
+	p.write(cmds)
+}
+
+// write writes the states in batches, a failed batch is logged and the other batches are still written.
+func (p *statePersister) write(cmds []models.SaveAlertInstanceCommand) {
+	if len(cmds) == 0 {
+		return
+	}
+	p.log.Debug("saving alert states", "count", len(cmds))
+	for start := 0; start < len(cmds); start += p.batchSize {
+		end := start + p.batchSize
+		if end > len(cmds) {
+			end = len(cmds)
+		}
+		if err := p.store.SaveAlertInstances(&models.SaveAlertInstancesCommand{Instances: cmds[start:end]}); err != nil {
+			p.metrics.StatePersistFailures.Inc()
+			p.log.Error("failed to save alert states", "count", end-start, "err", err)
+			continue
+		}
+		p.metrics.StatePersistWrites.Add(float64(end - start))
+	}
+}
+
+// fullSync writes the buffered states and a snapshot of the states of every organisation.
+func (p *statePersister) fullSync(stateManager *state.Manager) {
+	p.flush()
+
+	orgIDs, err := p.store.FetchOrgIds()
+	if err != nil {
+		p.log.Error("unable to fetch orgIds", "msg", err.Error())
+		return
+	}
+
+	now := p.clock.Now()
+	size := 0
+	for _, orgID := range orgIDs {
+		cmd := models.SaveAlertInstanceSnapshotCommand{
+			OrgID:     orgID,
+			Instances: toSaveAlertInstanceCommands(stateManager.GetAll(orgID)),
+			Created:   now,
+		}
+		if err := p.store.SaveAlertInstanceSnapshot(&cmd); err != nil {
+			p.metrics.StatePersistFailures.Inc()
+			p.log.Error("failed to save the snapshot of the alert states", "org", orgID, "err", err)
+			continue
+		}
+		size += cmd.Result
+	}
+	p.metrics.StatePersistSnapshotBytes.Set(float64(size))
+}
+
+// run flushes the buffered states every interval and takes the snapshots every full sync interval
+// until the context is done, the buffered states are then flushed so that they aren't lost on shutdown.
+func (p *statePersister) run(ctx context.Context, stateManager *state.Manager) error {
+	var flushC, fullSyncC <-chan time.Time
+	if p.interval > 0 {
+		ticker := p.clock.Ticker(p.interval)
+		defer ticker.Stop()
+		flushC = ticker.C
+	}
+	if p.fullSyncInterval > 0 {
+		ticker := p.clock.Ticker(p.fullSyncInterval)
+		defer ticker.Stop()
+		fullSyncC = ticker.C
+	}
+
+	for {
+		select {
+		case <-flushC:
+			p.flush()
+		case <-p.flushCh:
+			p.flush()
+		case <-fullSyncC:
+			p.fullSync(stateManager)
+		case <-ctx.Done():
+			p.flush()
+			return nil
+		}
+	}
+}
+
+func toSaveAlertInstanceCommands(states []*state.State) []models.SaveAlertInstanceCommand {
+	cmds := make([]models.SaveAlertInstanceCommand, 0, len(states))
+	for _, s := range states {
+		cmds = append(cmds, models.SaveAlertInstanceCommand{
+			RuleOrgID:         s.OrgID,
+			RuleUID:           s.AlertRuleUID,
+			Labels:            models.InstanceLabels(s.Labels),
+			State:             models.InstanceStateType(s.State.String()),
+			LastEvalTime:      s.LastEvaluationTime,
+			CurrentStateSince: s.StartsAt,
+			CurrentStateEnd:   s.EndsAt,
+		})
+	}
+	return cmds
+}
+
+func pendingStateKey(cmd models.SaveAlertInstanceCommand) string {
+	labels, _ := cmd.Labels.StringKey()
+	return fmt.Sprintf("%d/%s/%s", cmd.RuleOrgID, cmd.RuleUID, labels)
+}
@@ -0,0 +1,424 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// defaultMaxQueueCapacity is the number of alerts queued per Alertmanager,
+	// the oldest alerts are dropped when the queue is full.
+	defaultMaxQueueCapacity = 10000
+	// defaultMaxBatchSize is the maximum number of alerts sent in a single request.
+	defaultMaxBatchSize = 64
+	// defaultTimeout is the timeout of the requests to the Alertmanagers.
+	defaultTimeout = 10 * time.Second
+	// defaultHealthCheckInterval is how often the health of the Alertmanagers is checked,
+	// the alerts that failed to be sent are retried after the next successful health check.
+	defaultHealthCheckInterval = 10 * time.Second
+
+	alertsPath = "/api/v2/alerts"
+	healthPath = "/-/healthy"
+)
+
+// AlertmanagerStatus is the delivery status of an external Alertmanager.
+type AlertmanagerStatus struct {
+	URL             string
+	Healthy         bool
+	LastError       string
+	LastHealthCheck time.Time
+	LastSent        time.Time
+	QueueLength     int
+}
+
+// Sender is responsible for dispatching the alerts of an organisation to its external Alertmanagers.
+// Each Alertmanager has its own queue, so an unavailable Alertmanager doesn't delay the delivery to the others.
+type Sender struct {
+	orgID   int64
+	logger  log.Logger
+	metrics *metrics.Metrics
+	client  *http.Client
+
+	queueCapacity       int
+	batchSize           int
+	healthCheckInterval time.Duration
+
+	mtx     sync.RWMutex
+	targets map[string]*target
+}
+
+// New returns a sender of the alerts of an organisation without any Alertmanager,
+// they are configured by ApplyConfig.
+func New(orgID int64, m *metrics.Metrics) *Sender {
+	return &Sender{
+		orgID:               orgID,
+		logger:              log.New("sender", "org", orgID),
+		metrics:             m,
+		client:              &http.Client{Timeout: defaultTimeout},
+		queueCapacity:       defaultMaxQueueCapacity,
+		batchSize:           defaultMaxBatchSize,
+		healthCheckInterval: defaultHealthCheckInterval,
+		targets:             make(map[string]*target),
+	}
+}
+
+// ApplyConfig syncs the Alertmanagers of the sender with the configuration.
+// Alertmanagers that are kept across configurations keep their queued alerts.
+func (s *Sender) ApplyConfig(cfg *ngmodels.AdminConfiguration) error {
+	urls := make(map[string]*url.URL, len(cfg.Alertmanagers))
+	for _, am := range cfg.Alertmanagers {
+		u, err := url.Parse(am)
+		if err != nil {
+			return fmt.Errorf("invalid alertmanager url: %w", err)
+		}
+		urls[strings.TrimSuffix(am, "/")] = u
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for key, t := range s.targets {
+		if _, ok := urls[key]; ok {
+			continue
+		}
+		t.stop()
+		delete(s.targets, key)
+		s.logger.Info("external alertmanager removed", "alertmanager", t.name)
+	}
+
+	for key, u := range urls {
+		if _, ok := s.targets[key]; ok {
+			continue
+		}
+		t := s.newTarget(u)
+		s.targets[key] = t
+		go t.run()
+		s.logger.Info("external alertmanager added", "alertmanager", t.name)
+	}
+
+	return nil
+}
+
+// SendAlerts queues the alerts for every Alertmanager of the sender.
+func (s *Sender) SendAlerts(alerts apimodels.PostableAlerts) {
+	if len(alerts.PostableAlerts) == 0 {
+		return
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for _, t := range s.targets {
+		t.enqueue(alerts.PostableAlerts)
+	}
+}
+
+// Healthy returns true if at least one of the Alertmanagers passed its last health check.
+func (s *Sender) Healthy() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for _, t := range s.targets {
+		if t.isHealthy() {
+			return true
+		}
+	}
+	return false
+}
+
+// Alertmanagers returns the delivery status of the Alertmanagers of the sender, sorted by URL.
+func (s *Sender) Alertmanagers() []AlertmanagerStatus {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	res := make([]AlertmanagerStatus, 0, len(s.targets))
+	for _, t := range s.targets {
+		res = append(res, t.status())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].URL < res[j].URL
+	})
+	return res
+}
+
+// Stop stops the delivery to all the Alertmanagers, the alerts left in the queues are discarded.
+func (s *Sender) Stop() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key, t := range s.targets {
+		t.stop()
+		delete(s.targets, key)
+	}
+}
+
+func (s *Sender) newTarget(u *url.URL) *target {
+	alertsURL, healthURL := *u, *u
+	alertsURL.Path = path.Join(u.Path, alertsPath)
+	healthURL.Path = path.Join(u.Path, healthPath)
+
+	name := u.Redacted()
+	return &target{
+		name:                name,
+		alertsURL:           alertsURL.String(),
+		healthURL:           healthURL.String(),
+		client:              s.client,
+		logger:              s.logger.New("alertmanager", name),
+		metrics:             s.metrics,
+		labels:              []string{fmt.Sprint(s.orgID), name},
+		queueCapacity:       s.queueCapacity,
+		batchSize:           s.batchSize,
+		healthCheckInterval: s.healthCheckInterval,
+		more:                make(chan struct{}, 1),
+		stopc:               make(chan struct{}),
+		done:                make(chan struct{}),
+	}
+}
+
+// target is a single external Alertmanager with its queue of alerts.
+type target struct {
+	name      string
+	alertsURL string
+	healthURL string
+	client    *http.Client
+	logger    log.Logger
+	metrics   *metrics.Metrics
+	// labels are the values of the org and alertmanager labels of the delivery metrics.
+	labels []string
+
+	queueCapacity       int
+	batchSize           int
+	healthCheckInterval time.Duration
+
+	mtx   sync.Mutex
+	queue []models.PostableAlert
+	// inflight is the number of alerts at the head of the queue that are being sent.
+	inflight        int
+	healthy         bool
+	lastError       string
+	lastHealthCheck time.Time
+	lastSent        time.Time
+
+	more  chan struct{}
+	stopc chan struct{}
+	done  chan struct{}
+}
+
+func (t *target) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.healthCheckInterval)
+	defer ticker.Stop()
+
+	t.checkHealth()
+	for {
+		t.flush()
+
+		select {
+		case <-t.stopc:
+			return
+		case <-t.more:
+		case <-ticker.C:
+			t.checkHealth()
+		}
+	}
+}
+
+func (t *target) stop() {
+	close(t.stopc)
+	<-t.done
+
+	t.metrics.ExternalAlertmanagerAlertsSent.DeleteLabelValues(t.labels...)
+	t.metrics.ExternalAlertmanagerErrors.DeleteLabelValues(t.labels...)
+	t.metrics.ExternalAlertmanagerAlertsDropped.DeleteLabelValues(t.labels...)
+	t.metrics.ExternalAlertmanagerLatency.DeleteLabelValues(t.labels...)
+	t.metrics.ExternalAlertmanagerQueueLength.DeleteLabelValues(t.labels...)
+	t.metrics.ExternalAlertmanagerUp.DeleteLabelValues(t.labels...)
+}
+
+// enqueue adds the alerts to the queue, dropping the oldest queued alerts that aren't being sent if it's full.
+func (t *target) enqueue(alerts []models.PostableAlert) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	dropped := 0
+	if d := len(alerts) - (t.queueCapacity - t.inflight); d > 0 {
+		alerts = alerts[d:]
+		dropped += d
+	}
+	if d := len(t.queue) + len(alerts) - t.queueCapacity; d > 0 {
+		t.queue = append(t.queue[:t.inflight], t.queue[t.inflight+d:]...)
+		dropped += d
+	}
+	if dropped > 0 {
+		t.metrics.ExternalAlertmanagerAlertsDropped.WithLabelValues(t.labels...).Add(float64(dropped))
+		t.logger.Warn("alert queue is full, dropping alerts", "count", dropped)
+	}
+
+	t.queue = append(t.queue, alerts...)
+	t.metrics.ExternalAlertmanagerQueueLength.WithLabelValues(t.labels...).Set(float64(len(t.queue)))
+
+	select {
+	case t.more <- struct{}{}:
+	default:
+	}
+}
+
+// flush sends the queued alerts in batches as long as the Alertmanager is healthy.
+// When a batch fails the Alertmanager is considered unhealthy and the batch stays in the queue
+// until a later health check succeeds.
+func (t *target) flush() {
+	for {
+		select {
+		case <-t.stopc:
+			return
+		default:
+		}
+
+		batch := t.nextBatch()
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := t.send(batch); err != nil {
+			t.metrics.ExternalAlertmanagerErrors.WithLabelValues(t.labels...).Inc()
+			t.logger.Warn("failed to send alerts, they will be retried once the alertmanager is healthy", "count", len(batch), "err", err)
+			t.ack(0, err)
+			return
+		}
+		t.metrics.ExternalAlertmanagerAlertsSent.WithLabelValues(t.labels...).Add(float64(len(batch)))
+		t.ack(len(batch), nil)
+	}
+}
+
+func (t *target) nextBatch() []models.PostableAlert {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !t.healthy || len(t.queue) == 0 {
+		return nil
+	}
+
+	t.inflight = len(t.queue)
+	if t.inflight > t.batchSize {
+		t.inflight = t.batchSize
+	}
+	batch := make([]models.PostableAlert, t.inflight)
+	copy(batch, t.queue[:t.inflight])
+	return batch
+}
+
+// ack removes the sent alerts from the head of the queue, and marks the Alertmanager as unhealthy if sending failed.
+func (t *target) ack(sent int, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.queue = t.queue[sent:]
+	t.inflight = 0
+	t.metrics.ExternalAlertmanagerQueueLength.WithLabelValues(t.labels...).Set(float64(len(t.queue)))
+
+	if err != nil {
+		t.healthy = false
+		t.lastError = err.Error()
+		t.metrics.ExternalAlertmanagerUp.WithLabelValues(t.labels...).Set(0)
+		return
+	}
+	t.lastSent = time.Now()
+}
+
+func (t *target) send(alerts []models.PostableAlert) error {
+	b, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.alertsURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := t.client.Do(req)
+	t.metrics.ExternalAlertmanagerLatency.WithLabelValues(t.labels...).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp)
+}
+
+func (t *target) checkHealth() {
+	err := func() error {
+		resp, err := t.client.Get(t.healthURL)
+		if err != nil {
+			return err
+		}
+		return checkResponse(resp)
+	}()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.lastHealthCheck = time.Now()
+	if err != nil {
+		if t.healthy {
+			t.logger.Warn("alertmanager is unhealthy", "err", err)
+		}
+		t.healthy = false
+		t.lastError = err.Error()
+		t.metrics.ExternalAlertmanagerUp.WithLabelValues(t.labels...).Set(0)
+		return
+	}
+
+	if !t.healthy {
+		t.logger.Info("alertmanager is healthy", "queued", len(t.queue))
+	}
+	t.healthy = true
+	t.lastError = ""
+	t.metrics.ExternalAlertmanagerUp.WithLabelValues(t.labels...).Set(1)
+}
+
+func (t *target) isHealthy() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.healthy
+}
+
+func (t *target) status() AlertmanagerStatus {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return AlertmanagerStatus{
+		URL:             t.name,
+		Healthy:         t.healthy,
+		LastError:       t.lastError,
+		LastHealthCheck: t.lastHealthCheck,
+		LastSent:        t.lastSent,
+		QueueLength:     len(t.queue),
+	}
+}
+
+func checkResponse(resp *http.Response) error {
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bad response status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeAlertmanager struct {
+	mtx     sync.Mutex
+	healthy bool
+	alerts  []models.PostableAlert
+}
+
+func (am *fakeAlertmanager) setHealthy(healthy bool) {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	am.healthy = healthy
+}
+
+func (am *fakeAlertmanager) received() int {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	return len(am.alerts)
+}
+
+func (am *fakeAlertmanager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+
+	if !am.healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.URL.Path {
+	case healthPath:
+		w.WriteHeader(http.StatusOK)
+	case alertsPath:
+		var alerts []models.PostableAlert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		am.alerts = append(am.alerts, alerts...)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestSender(t *testing.T, urls ...string) *Sender {
+	t.Helper()
+
+	s := New(1, metrics.NewMetrics(prometheus.NewRegistry()))
+	s.healthCheckInterval = 10 * time.Millisecond
+	require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: urls, SendAlertsTo: ngmodels.ExternalAlertmanagers}))
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func testAlerts(n int) apimodels.PostableAlerts {
+	alerts := apimodels.PostableAlerts{}
+	for i := 0; i < n; i++ {
+		alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
+			Alert: models.Alert{Labels: models.LabelSet{"alertname": "test"}},
+		})
+	}
+	return alerts
+}
+
+func TestSenderSendsAlertsToAllAlertmanagers(t *testing.T) {
+	am1 := &fakeAlertmanager{healthy: true}
+	am2 := &fakeAlertmanager{healthy: true}
+	srv1 := httptest.NewServer(am1)
+	defer srv1.Close()
+	srv2 := httptest.NewServer(am2)
+	defer srv2.Close()
+
+	s := newTestSender(t, srv1.URL, srv2.URL)
+	require.Eventually(t, s.Healthy, time.Second, 10*time.Millisecond)
+
+	s.SendAlerts(testAlerts(100))
+	require.Eventually(t, func() bool {
+		return am1.received() == 100 && am2.received() == 100
+	}, time.Second, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		for _, status := range s.Alertmanagers() {
+			if !status.Healthy || status.QueueLength != 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSenderRetriesAlertsOnceAlertmanagerIsHealthy(t *testing.T) {
+	am := &fakeAlertmanager{healthy: false}
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+
+	s := newTestSender(t, srv.URL)
+	s.SendAlerts(testAlerts(3))
+
+	require.Eventually(t, func() bool {
+		statuses := s.Alertmanagers()
+		return !statuses[0].LastHealthCheck.IsZero() && statuses[0].LastError != ""
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, s.Healthy())
+	require.Equal(t, 3, s.Alertmanagers()[0].QueueLength)
+
+	am.setHealthy(true)
+	require.Eventually(t, func() bool {
+		return am.received() == 3
+	}, time.Second, 10*time.Millisecond)
+	require.True(t, s.Healthy())
+	require.Eventually(t, func() bool {
+		return s.Alertmanagers()[0].QueueLength == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSenderApplyConfig(t *testing.T) {
+	am := &fakeAlertmanager{healthy: false}
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+
+	s := newTestSender(t, srv.URL, "http://localhost:1")
+	s.SendAlerts(testAlerts(2))
+	require.Len(t, s.Alertmanagers(), 2)
+
+	// The queue of the kept Alertmanager is preserved.
+	require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{srv.URL + "/"}}))
+	statuses := s.Alertmanagers()
+	require.Len(t, statuses, 1)
+	require.Equal(t, srv.URL, statuses[0].URL)
+	require.Equal(t, 2, statuses[0].QueueLength)
+
+	require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1}))
+	require.Empty(t, s.Alertmanagers())
+}
+
+func TestTargetEnqueueDropsOldestAlerts(t *testing.T) {
+	s := New(1, metrics.NewMetrics(prometheus.NewRegistry()))
+	s.queueCapacity = 5
+	u, err := url.Parse("http://localhost:9093")
+	require.NoError(t, err)
+	tgt := s.newTarget(u)
+
+	alerts := testAlerts(4).PostableAlerts
+	for i := range alerts {
+		alerts[i].Labels = models.LabelSet{"n": string(rune('a' + i))}
+	}
+	tgt.enqueue(alerts)
+	// Pretend the first two alerts are being sent.
+	tgt.inflight = 2
+
+	more := testAlerts(3).PostableAlerts
+	for i := range more {
+		more[i].Labels = models.LabelSet{"n": string(rune('x' + i))}
+	}
+	tgt.enqueue(more)
+
+	got := make([]string, 0, len(tgt.queue))
+	for _, a := range tgt.queue {
+		got = append(got, a.Labels["n"])
+	}
+	require.Equal(t, []string{"a", "b", "x", "y", "z"}, got)
+}
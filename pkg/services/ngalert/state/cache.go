@@ -45,6 +45,14 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 	// if duplicate labels exist, alertRule label will take precedence
 	lbs := mergeLabels(ruleLabels, result.Instance)
 	attachRuleLabels(lbs, alertRule)
+	if alertRule.CorrelationKey != "" {
+		key, err := expandTemplate(alertRule.Title, alertRule.CorrelationKey, templateData)
+		if err != nil {
+			c.log.Error("error in expanding correlation key", "value", alertRule.CorrelationKey, "err", err.Error())
+		} else if key != "" {
+			lbs[ngModels.CorrelationKeyLabel] = key
+		}
+	}
 
 	il := ngModels.InstanceLabels(lbs)
 	id, err := il.StringKey()
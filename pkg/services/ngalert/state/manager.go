@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
 type Manager struct {
@@ -16,15 +17,18 @@ type Manager struct {
 	ResendDelay time.Duration
 	Log         log.Logger
 	metrics     *metrics.Metrics
+	// historyStore persists the state transitions, they are not recorded if it's nil
+	historyStore store.StateHistoryStore
 }
 
-func NewManager(logger log.Logger, metrics *metrics.Metrics) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.Metrics, historyStore store.StateHistoryStore) *Manager {
 	manager := &Manager{
-		cache:       newCache(logger, metrics),
-		quit:        make(chan struct{}),
-		ResendDelay: 1 * time.Minute, // TODO: make this configurable
-		Log:         logger,
-		metrics:     metrics,
+		cache:        newCache(logger, metrics),
+		quit:         make(chan struct{}),
+		ResendDelay:  1 * time.Minute, // TODO: make this configurable
+		Log:          logger,
+		metrics:      metrics,
+		historyStore: historyStore,
 	}
 	go manager.recordMetrics()
 	return manager
@@ -59,17 +63,23 @@ func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 func (st *Manager) ProcessEvalResults(alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.Log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
 	var states []*State
+	var transitions []ngModels.AlertStateHistoryEntry
 	for _, result := range results {
-		s := st.setNextState(alertRule, result)
+		s, previous := st.setNextState(alertRule, result)
 		states = append(states, s)
+		if s.State != previous {
+			transitions = append(transitions, toStateHistoryEntry(s, previous, result))
+		}
 	}
+	st.saveStateHistory(alertRule, transitions)
 	st.Log.Debug("returning changed states to scheduler", "count", len(states))
 	return states
 }
 
-//Set the current state based on evaluation results
-func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result) *State {
+//Set the current state based on evaluation results, the state before the evaluation is returned as well
+func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result) (*State, eval.State) {
 	currentState := st.getOrCreate(alertRule, result)
+	previousState := currentState.State
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
 	currentState.EvaluationDuration = result.EvaluationDuration
@@ -94,7 +104,34 @@ func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Resul
 	}
 
 	st.set(currentState)
-	return currentState
+	return currentState, previousState
+}
+
+func (st *Manager) saveStateHistory(alertRule *ngModels.AlertRule, transitions []ngModels.AlertStateHistoryEntry) {
+	if st.historyStore == nil || len(transitions) == 0 {
+		return
+	}
+	st.Log.Debug("saving alert state history", "uid", alertRule.UID, "count", len(transitions))
+	cmd := ngModels.SaveAlertStateHistoryCommand{Entries: transitions}
+	if err := st.historyStore.SaveAlertStateHistory(&cmd); err != nil {
+		st.Log.Error("failed to save alert state history", "uid", alertRule.UID, "orgId", alertRule.OrgID, "count", len(transitions), "msg", err.Error())
+	}
+}
+
+func toStateHistoryEntry(s *State, previous eval.State, result eval.Result) ngModels.AlertStateHistoryEntry {
+	entry := ngModels.AlertStateHistoryEntry{
+		RuleOrgID:        s.OrgID,
+		RuleUID:          s.AlertRuleUID,
+		Labels:           ngModels.InstanceLabels(s.Labels),
+		PreviousState:    ngModels.InstanceStateType(previous.String()),
+		CurrentState:     ngModels.InstanceStateType(s.State.String()),
+		EvaluationString: result.EvaluationString,
+		StateChangedAt:   result.EvaluatedAt,
+	}
+	if s.Error != nil {
+		entry.Error = s.Error.Error()
+	}
+	return entry
 }
 
 func (st *Manager) GetAll(orgID int64) []*State {
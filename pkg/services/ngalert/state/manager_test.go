@@ -1,6 +1,7 @@
 package state_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -25,6 +26,7 @@ func TestProcessEvalResults(t *testing.T) {
 		t.Fatalf("error parsing date format: %s", err.Error())
 	}
 	evaluationDuration := 10 * time.Millisecond
+	evaluationErr := errors.New("context deadline exceeded")
 
 	testCases := []struct {
 		desc           string
@@ -774,6 +776,193 @@ func TestProcessEvalResults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "alerting -> alerting when result is Error and ExecErrState is KeepLastState",
+			alertRule: &models.AlertRule{
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid_2",
+				NamespaceUID:    "test_namespace_uid",
+				Annotations:     map[string]string{"annotation": "test"},
+				Labels:          map[string]string{"label": "test"},
+				IntervalSeconds: 10,
+				ExecErrState:    models.KeepLastStateErrState,
+			},
+			evalResults: []eval.Results{
+				{
+					eval.Result{
+						Instance:           data.Labels{"instance_label": "test"},
+						State:              eval.Alerting,
+						EvaluatedAt:        evaluationTime,
+						EvaluationDuration: evaluationDuration,
+					},
+				},
+				{
+					eval.Result{
+						Instance:           data.Labels{"instance_label": "test"},
+						State:              eval.Error,
+						EvaluatedAt:        evaluationTime.Add(10 * time.Second),
+						EvaluationDuration: evaluationDuration,
+						Error:              evaluationErr,
+					},
+				},
+			},
+			expectedStates: map[string]*state.State{
+				`[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid_2"],["alertname","test_title"],["instance_label","test"],["label","test"]]`: {
+					AlertRuleUID: "test_alert_rule_uid_2",
+					OrgID:        1,
+					CacheId:      `[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid_2"],["alertname","test_title"],["instance_label","test"],["label","test"]]`,
+					Labels: data.Labels{
+						"__alert_rule_namespace_uid__": "test_namespace_uid",
+						"__alert_rule_uid__":           "test_alert_rule_uid_2",
+						"alertname":                    "test_title",
+						"label":                        "test",
+						"instance_label":               "test",
+					},
+					State: eval.Alerting,
+					Results: []state.Evaluation{
+						{
+							EvaluationTime:  evaluationTime,
+							EvaluationState: eval.Alerting,
+						},
+						{
+							EvaluationTime:  evaluationTime.Add(10 * time.Second),
+							EvaluationState: eval.Error,
+						},
+					},
+					StartsAt:           evaluationTime,
+					EndsAt:             evaluationTime.Add(30 * time.Second),
+					LastEvaluationTime: evaluationTime.Add(10 * time.Second),
+					EvaluationDuration: evaluationDuration,
+					Annotations:        map[string]string{"annotation": "test"},
+					Error:              evaluationErr,
+				},
+			},
+		},
+		{
+			desc: "alerting -> normal when result is Error and ExecErrState is OK",
+			alertRule: &models.AlertRule{
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid_2",
+				NamespaceUID:    "test_namespace_uid",
+				Annotations:     map[string]string{"annotation": "test"},
+				Labels:          map[string]string{"label": "test"},
+				IntervalSeconds: 10,
+				ExecErrState:    models.OkErrState,
+			},
+			evalResults: []eval.Results{
+				{
+					eval.Result{
+						Instance:           data.Labels{"instance_label": "test"},
+						State:              eval.Alerting,
+						EvaluatedAt:        evaluationTime,
+						EvaluationDuration: evaluationDuration,
+					},
+				},
+				{
+					eval.Result{
+						Instance:           data.Labels{"instance_label": "test"},
+						State:              eval.Error,
+						EvaluatedAt:        evaluationTime.Add(10 * time.Second),
+						EvaluationDuration: evaluationDuration,
+						Error:              evaluationErr,
+					},
+				},
+			},
+			expectedStates: map[string]*state.State{
+				`[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid_2"],["alertname","test_title"],["instance_label","test"],["label","test"]]`: {
+					AlertRuleUID: "test_alert_rule_uid_2",
+					OrgID:        1,
+					CacheId:      `[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid_2"],["alertname","test_title"],["instance_label","test"],["label","test"]]`,
+					Labels: data.Labels{
+						"__alert_rule_namespace_uid__": "test_namespace_uid",
+						"__alert_rule_uid__":           "test_alert_rule_uid_2",
+						"alertname":                    "test_title",
+						"label":                        "test",
+						"instance_label":               "test",
+					},
+					State: eval.Normal,
+					Results: []state.Evaluation{
+						{
+							EvaluationTime:  evaluationTime,
+							EvaluationState: eval.Alerting,
+						},
+						{
+							EvaluationTime:  evaluationTime.Add(10 * time.Second),
+							EvaluationState: eval.Error,
+						},
+					},
+					StartsAt:           evaluationTime.Add(10 * time.Second),
+					EndsAt:             evaluationTime.Add(10 * time.Second),
+					LastEvaluationTime: evaluationTime.Add(10 * time.Second),
+					EvaluationDuration: evaluationDuration,
+					Annotations:        map[string]string{"annotation": "test"},
+					Error:              evaluationErr,
+				},
+			},
+		},
+		{
+			desc: "alerting -> alerting when result is NoData and NoDataState is KeepLastState",
+			alertRule: &models.AlertRule{
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid_2",
+				NamespaceUID:    "test_namespace_uid",
+				Annotations:     map[string]string{"annotation": "test"},
+				Labels:          map[string]string{"label": "test"},
+				IntervalSeconds: 10,
+				NoDataState:     models.KeepLastState,
+			},
+			evalResults: []eval.Results{
+				{
+					eval.Result{
+						Instance:           data.Labels{"instance_label": "test"},
+						State:              eval.Alerting,
+						EvaluatedAt:        evaluationTime,
+						EvaluationDuration: evaluationDuration,
+					},
+				},
+				{
+					eval.Result{
+						Instance:           data.Labels{"instance_label": "test"},
+						State:              eval.NoData,
+						EvaluatedAt:        evaluationTime.Add(10 * time.Second),
+						EvaluationDuration: evaluationDuration,
+					},
+				},
+			},
+			expectedStates: map[string]*state.State{
+				`[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid_2"],["alertname","test_title"],["instance_label","test"],["label","test"]]`: {
+					AlertRuleUID: "test_alert_rule_uid_2",
+					OrgID:        1,
+					CacheId:      `[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid_2"],["alertname","test_title"],["instance_label","test"],["label","test"]]`,
+					Labels: data.Labels{
+						"__alert_rule_namespace_uid__": "test_namespace_uid",
+						"__alert_rule_uid__":           "test_alert_rule_uid_2",
+						"alertname":                    "test_title",
+						"label":                        "test",
+						"instance_label":               "test",
+					},
+					State: eval.Alerting,
+					Results: []state.Evaluation{
+						{
+							EvaluationTime:  evaluationTime,
+							EvaluationState: eval.Alerting,
+						},
+						{
+							EvaluationTime:  evaluationTime.Add(10 * time.Second),
+							EvaluationState: eval.NoData,
+						},
+					},
+					StartsAt:           evaluationTime,
+					EndsAt:             evaluationTime.Add(30 * time.Second),
+					LastEvaluationTime: evaluationTime.Add(10 * time.Second),
+					EvaluationDuration: evaluationDuration,
+					Annotations:        map[string]string{"annotation": "test"},
+				},
+			},
+		},
 		{
 			desc: "template is correctly expanded",
 			alertRule: &models.AlertRule{
@@ -823,10 +1012,55 @@ func TestProcessEvalResults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "the correlation key of the rule is expanded in a label",
+			alertRule: &models.AlertRule{
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid",
+				NamespaceUID:    "test_namespace_uid",
+				IntervalSeconds: 10,
+				CorrelationKey:  "{{ $labels.cluster }}",
+			},
+			evalResults: []eval.Results{
+				{
+					eval.Result{
+						Instance:           data.Labels{"cluster": "us-central-1"},
+						State:              eval.Normal,
+						EvaluatedAt:        evaluationTime,
+						EvaluationDuration: evaluationDuration,
+					},
+				},
+			},
+			expectedStates: map[string]*state.State{
+				`[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid"],["__correlation_key__","us-central-1"],["alertname","test_title"],["cluster","us-central-1"]]`: {
+					AlertRuleUID: "test_alert_rule_uid",
+					OrgID:        1,
+					CacheId:      `[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid"],["__correlation_key__","us-central-1"],["alertname","test_title"],["cluster","us-central-1"]]`,
+					Labels: data.Labels{
+						"__alert_rule_namespace_uid__": "test_namespace_uid",
+						"__alert_rule_uid__":           "test_alert_rule_uid",
+						"__correlation_key__":          "us-central-1",
+						"alertname":                    "test_title",
+						"cluster":                      "us-central-1",
+					},
+					State: eval.Normal,
+					Results: []state.Evaluation{
+						{
+							EvaluationTime:  evaluationTime,
+							EvaluationState: eval.Normal,
+						},
+					},
+					LastEvaluationTime: evaluationTime,
+					EvaluationDuration: evaluationDuration,
+					Annotations:        map[string]string{},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"), nilMetrics)
+		st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil)
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(tc.alertRule, res)
@@ -839,3 +1073,70 @@ func TestProcessEvalResults(t *testing.T) {
 		})
 	}
 }
+
+type fakeHistoryStore struct {
+	entries []models.AlertStateHistoryEntry
+}
+
+func (f *fakeHistoryStore) SaveAlertStateHistory(cmd *models.SaveAlertStateHistoryCommand) error {
+	f.entries = append(f.entries, cmd.Entries...)
+	return nil
+}
+
+func (f *fakeHistoryStore) ListAlertStateHistory(cmd *models.ListAlertStateHistoryQuery) error {
+	return nil
+}
+
+func (f *fakeHistoryStore) DeleteAlertStateHistoryBefore(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestProcessEvalResultsRecordsStateHistory(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	alertRule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		For:             10 * time.Second,
+		IntervalSeconds: 10,
+	}
+	evalStates := []eval.State{eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.Normal}
+
+	historyStore := &fakeHistoryStore{}
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, historyStore)
+	for i, s := range evalStates {
+		_ = st.ProcessEvalResults(alertRule, eval.Results{
+			eval.Result{
+				Instance:         data.Labels{"instance_label": "test"},
+				State:            s,
+				EvaluatedAt:      evaluationTime.Add(time.Duration(i*10) * time.Second),
+				EvaluationString: "[ var='A' value=1 ]",
+			},
+		})
+	}
+
+	require.Len(t, historyStore.entries, 3)
+	expected := []struct {
+		previous models.InstanceStateType
+		current  models.InstanceStateType
+		at       time.Time
+	}{
+		{models.InstanceStateNormal, models.InstanceStatePending, evaluationTime.Add(10 * time.Second)},
+		{models.InstanceStatePending, models.InstanceStateFiring, evaluationTime.Add(30 * time.Second)},
+		{models.InstanceStateFiring, models.InstanceStateNormal, evaluationTime.Add(40 * time.Second)},
+	}
+	for i, e := range expected {
+		entry := historyStore.entries[i]
+		assert.Equal(t, alertRule.UID, entry.RuleUID)
+		assert.Equal(t, alertRule.OrgID, entry.RuleOrgID)
+		assert.Equal(t, "test", entry.Labels["instance_label"])
+		assert.Equal(t, e.previous, entry.PreviousState)
+		assert.Equal(t, e.current, entry.CurrentState)
+		assert.Equal(t, e.at, entry.StateChangedAt)
+		assert.Equal(t, "[ var='A' value=1 ]", entry.EvaluationString)
+	}
+	assert.True(t, historyStore.entries[2].IsResolved())
+}
@@ -62,12 +62,18 @@ func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result
 }
 
 func (a *State) resultError(alertRule *ngModels.AlertRule, result eval.Result) {
+	if alertRule.ExecErrState == ngModels.OkErrState {
+		a.resultNormal(result)
+		return
+	}
+
 	a.Error = result.Error
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
 	}
 	a.setEndsAt(alertRule, result)
 
+	// the state is kept as it is with KeepLastStateErrState
 	if alertRule.ExecErrState == ngModels.AlertingErrState {
 		a.State = eval.Alerting
 	}
@@ -86,6 +92,7 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 		a.State = eval.NoData
 	case ngModels.OK:
 		a.State = eval.Normal
+	case ngModels.KeepLastState:
 	}
 }
 
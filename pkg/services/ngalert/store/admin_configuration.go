@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+var (
+	// ErrNoAdminConfiguration is an error for when no admin configuration is found.
+	ErrNoAdminConfiguration = fmt.Errorf("no admin configuration available")
+)
+
+// AdminConfigurationStore is the database interface for the ngalert administration configuration of the organisations.
+type AdminConfigurationStore interface {
+	GetAdminConfiguration(query *ngmodels.GetOrgAdminConfiguration) error
+	GetAdminConfigurations() ([]*ngmodels.AdminConfiguration, error)
+	DeleteAdminConfiguration(orgID int64) error
+	UpdateAdminConfiguration(cmd *ngmodels.UpdateAdminConfigurationCmd) error
+}
+
+// GetAdminConfiguration returns the administration configuration of an organisation.
+// It returns ErrNoAdminConfiguration if the organisation has no configuration.
+func (st DBstore) GetAdminConfiguration(query *ngmodels.GetOrgAdminConfiguration) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		adminConfiguration := &ngmodels.AdminConfiguration{}
+		ok, err := sess.Table("ngalert_configuration").Where("org_id = ?", query.OrgID).Get(adminConfiguration)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return ErrNoAdminConfiguration
+		}
+
+		query.Result = adminConfiguration
+		return nil
+	})
+}
+
+// GetAdminConfigurations returns the administration configurations of all the organisations.
+func (st DBstore) GetAdminConfigurations() ([]*ngmodels.AdminConfiguration, error) {
+	var cfg []*ngmodels.AdminConfiguration
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("ngalert_configuration").Find(&cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// DeleteAdminConfiguration deletes the administration configuration of an organisation.
+func (st DBstore) DeleteAdminConfiguration(orgID int64) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM ngalert_configuration WHERE org_id = ?", orgID)
+		return err
+	})
+}
+
+// UpdateAdminConfiguration creates or updates the administration configuration of an organisation.
+func (st DBstore) UpdateAdminConfiguration(cmd *ngmodels.UpdateAdminConfigurationCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := ngmodels.AdminConfiguration{}
+		has, err := sess.Table("ngalert_configuration").Where("org_id = ?", cmd.AdminConfiguration.OrgID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if has {
+			_, err = sess.Table("ngalert_configuration").ID(existing.ID).Cols("alertmanagers", "send_alerts_to", "enrichment", "updated_at").Update(cmd.AdminConfiguration)
+			return err
+		}
+
+		_, err = sess.Table("ngalert_configuration").Insert(cmd.AdminConfiguration)
+		return err
+	})
+}
@@ -0,0 +1,79 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminConfiguration(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	t.Run("no configuration returns ErrNoAdminConfiguration", func(t *testing.T) {
+		q := &models.GetOrgAdminConfiguration{OrgID: 1}
+		require.ErrorIs(t, dbstore.GetAdminConfiguration(q), store.ErrNoAdminConfiguration)
+	})
+
+	t.Run("update creates and then updates the configuration of the organisation", func(t *testing.T) {
+		cmd := &models.UpdateAdminConfigurationCmd{AdminConfiguration: &models.AdminConfiguration{
+			OrgID:         1,
+			Alertmanagers: []string{"http://alertmanager-1:9093"},
+			SendAlertsTo:  models.AllAlertmanagers,
+		}}
+		require.NoError(t, dbstore.UpdateAdminConfiguration(cmd))
+
+		cmd = &models.UpdateAdminConfigurationCmd{AdminConfiguration: &models.AdminConfiguration{
+			OrgID:         1,
+			Alertmanagers: []string{"http://alertmanager-1:9093", "http://alertmanager-2:9093"},
+			SendAlertsTo:  models.ExternalAlertmanagers,
+		}}
+		require.NoError(t, dbstore.UpdateAdminConfiguration(cmd))
+
+		q := &models.GetOrgAdminConfiguration{OrgID: 1}
+		require.NoError(t, dbstore.GetAdminConfiguration(q))
+		require.Equal(t, []string{"http://alertmanager-1:9093", "http://alertmanager-2:9093"}, q.Result.Alertmanagers)
+		require.Equal(t, models.ExternalAlertmanagers, q.Result.SendAlertsTo)
+
+		cfgs, err := dbstore.GetAdminConfigurations()
+		require.NoError(t, err)
+		require.Len(t, cfgs, 1)
+	})
+
+	t.Run("update saves the enrichment of the alerts", func(t *testing.T) {
+		enrichment := &models.EnrichmentConfig{
+			Labels:   map[string]string{"team": "ops"},
+			Folders:  map[string]models.FolderEnrichment{"folder": {Labels: map[string]string{"team": "db"}}},
+			Rewrites: []models.LabelRewrite{{Action: models.LabelRewriteDrop, Regex: "pod"}},
+		}
+		cmd := &models.UpdateAdminConfigurationCmd{AdminConfiguration: &models.AdminConfiguration{
+			OrgID:        1,
+			SendAlertsTo: models.InternalAlertmanager,
+			Enrichment:   enrichment,
+		}}
+		require.NoError(t, dbstore.UpdateAdminConfiguration(cmd))
+
+		q := &models.GetOrgAdminConfiguration{OrgID: 1}
+		require.NoError(t, dbstore.GetAdminConfiguration(q))
+		require.Equal(t, enrichment, q.Result.Enrichment)
+
+		cmd.AdminConfiguration.Enrichment = nil
+		require.NoError(t, dbstore.UpdateAdminConfiguration(cmd))
+		require.NoError(t, dbstore.GetAdminConfiguration(q))
+		require.Nil(t, q.Result.Enrichment)
+	})
+
+	t.Run("delete removes the configuration of the organisation", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteAdminConfiguration(1))
+
+		q := &models.GetOrgAdminConfiguration{OrgID: 1}
+		require.ErrorIs(t, dbstore.GetAdminConfiguration(q), store.ErrNoAdminConfiguration)
+	})
+}
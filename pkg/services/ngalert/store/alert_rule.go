@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"text/template"
 	"time"
 
+	prometheusModel "github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/services/guardian"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -24,6 +27,9 @@ const AlertRuleMaxTitleLength = 190
 // AlertRuleMaxRuleGroupNameLength is the maximum length of the alert rule group name
 const AlertRuleMaxRuleGroupNameLength = 190
 
+// AlertRuleMaxCorrelationKeyLength is the maximum length of the correlation key template of the alert rule
+const AlertRuleMaxCorrelationKeyLength = 190
+
 type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
@@ -49,8 +55,14 @@ type RuleStore interface {
 	GetNamespaceByTitle(string, int64, *models.SignedInUser, bool) (*models.Folder, error)
 	GetNamespaceByUID(string, int64, *models.SignedInUser) (*models.Folder, error)
 	GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
+	InsertAlertRule(ngmodels.AlertRule) (*ngmodels.AlertRule, error)
 	UpsertAlertRules([]UpsertRule) error
 	UpdateRuleGroup(UpdateRuleGroupCmd) error
+	PatchRuleGroup(cmd *ngmodels.PatchRuleGroupCommand) error
+	SetRuleGroupPaused(cmd *ngmodels.SetRuleGroupPausedCommand) error
+	GetAlertRuleVersions(query *ngmodels.ListAlertRuleVersionsQuery) error
+	GetAlertRuleVersion(query *ngmodels.GetAlertRuleVersionQuery) error
+	RestoreAlertRuleVersion(cmd *ngmodels.RestoreAlertRuleVersionCommand) error
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -69,23 +81,32 @@ func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int6
 // DeleteAlertRuleByUID is a handler for deleting an alert rule.
 func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
+		return deleteAlertRuleByUID(sess, orgID, ruleUID)
+	})
+}
 
-		_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
+func deleteAlertRuleByUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
-		}
+	_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
 
-		_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("DELETE FROM alert_rule_heartbeat WHERE org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
 // DeleteNamespaceAlertRules is a handler for deleting namespace alert rules. A list of deleted rule UIDs are returned.
@@ -105,7 +126,10 @@ func (st DBstore) DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([
 			return err
 		}
 
-		if _, err := sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_namespace_uid = ?", orgID, namespaceUID); err != nil {
+		// the versions are deleted by rule since the rules keep the versions from their previous namespaces
+		if _, err := sess.Exec(`DELETE FROM alert_rule_version WHERE rule_org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
 			return err
 		}
 
@@ -115,6 +139,12 @@ func (st DBstore) DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([
 			return err
 		}
 
+		if _, err := sess.Exec(`DELETE FROM alert_rule_heartbeat WHERE org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 	return ruleUIDs, err
@@ -142,7 +172,10 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 			return err
 		}
 
-		if _, err := sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_namespace_uid = ? and rule_group = ?", orgID, namespaceUID, ruleGroup); err != nil {
+		// the versions are deleted by rule since the rules keep the versions from their previous rule groups
+		if _, err := sess.Exec(`DELETE FROM alert_rule_version WHERE rule_org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
 			return err
 		}
 
@@ -152,6 +185,12 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 			return err
 		}
 
+		if _, err := sess.Exec(`DELETE FROM alert_rule_heartbeat WHERE org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -185,141 +224,202 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 // UpsertAlertRules is a handler for creating/updating alert rules.
 func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		newRules := make([]ngmodels.AlertRule, 0, len(rules))
-		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
-		for _, r := range rules {
-			if r.Existing == nil && r.New.UID != "" {
-				// check by UID
-				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
-					return err
+		return st.upsertAlertRules(sess, rules)
+	})
+}
+
+func (st DBstore) upsertAlertRules(sess *sqlstore.DBSession, rules []UpsertRule) error {
+	newRules := make([]ngmodels.AlertRule, 0, len(rules))
+	ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+	for _, r := range rules {
+		if r.Existing == nil && r.New.UID != "" {
+			// check by UID
+			existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
+			if err != nil {
+				if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+					return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 				}
-				r.Existing = existingAlertRule
+				return err
 			}
+			r.Existing = existingAlertRule
+		}
 
-			var parentVersion int64
-			switch r.Existing {
-			case nil: // new rule
-				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
-				}
-				r.New.UID = uid
+		var parentVersion int64
+		switch r.Existing {
+		case nil: // new rule
+			if err := st.prepareNewAlertRule(sess, &r.New); err != nil {
+				return err
+			}
 
-				if r.New.IntervalSeconds == 0 {
-					r.New.IntervalSeconds = st.DefaultIntervalSeconds
-				}
+			newRules = append(newRules, r.New)
+		default:
+			// explicitly set the existing properties if missing
+			// do not rely on xorm
+			if r.New.Title == "" {
+				r.New.Title = r.Existing.Title
+			}
 
-				r.New.Version = 1
+			if r.New.Condition == "" {
+				r.New.Condition = r.Existing.Condition
+			}
 
-				if r.New.NoDataState == "" {
-					// set default no data state
-					r.New.NoDataState = ngmodels.NoData
-				}
+			if len(r.New.Data) == 0 {
+				r.New.Data = r.Existing.Data
+			}
 
-				if r.New.ExecErrState == "" {
-					// set default error state
-					r.New.ExecErrState = ngmodels.AlertingErrState
-				}
+			r.New.ID = r.Existing.ID
+			r.New.OrgID = r.Existing.OrgID
+			r.New.NamespaceUID = r.Existing.NamespaceUID
+			r.New.RuleGroup = r.Existing.RuleGroup
+			r.New.Version = r.Existing.Version + 1
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.ExecErrState == "" {
+				r.New.ExecErrState = r.Existing.ExecErrState
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if r.New.NoDataState == "" {
+				r.New.NoDataState = r.Existing.NoDataState
+			}
 
-				newRules = append(newRules, r.New)
-			default:
-				// explicitly set the existing properties if missing
-				// do not rely on xorm
-				if r.New.Title == "" {
-					r.New.Title = r.Existing.Title
-				}
+			if r.New.RuleGroupIndex == 0 {
+				r.New.RuleGroupIndex = r.Existing.RuleGroupIndex
+			}
 
-				if r.New.Condition == "" {
-					r.New.Condition = r.Existing.Condition
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				if len(r.New.Data) == 0 {
-					r.New.Data = r.Existing.Data
-				}
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
 
-				r.New.ID = r.Existing.ID
-				r.New.OrgID = r.Existing.OrgID
-				r.New.NamespaceUID = r.Existing.NamespaceUID
-				r.New.RuleGroup = r.Existing.RuleGroup
-				r.New.Version = r.Existing.Version + 1
+			// no way to update multiple rules at once
+			if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
+				return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
+			}
 
-				if r.New.ExecErrState == "" {
-					r.New.ExecErrState = r.Existing.ExecErrState
-				}
+			parentVersion = r.Existing.Version
+		}
 
-				if r.New.NoDataState == "" {
-					r.New.NoDataState = r.Existing.NoDataState
-				}
+		ruleVersions = append(ruleVersions, newAlertRuleVersion(r.New, parentVersion))
+	}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+	if len(newRules) > 0 {
+		if _, err := sess.Insert(&newRules); err != nil {
+			return fmt.Errorf("failed to create new rules: %w", err)
+		}
+	}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+	if len(ruleVersions) > 0 {
+		if _, err := sess.Insert(&ruleVersions); err != nil {
+			return fmt.Errorf("failed to create new rule versions: %w", err)
+		}
+	}
 
-				// no way to update multiple rules at once
-				if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
-					return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
-				}
+	return nil
+}
 
-				parentVersion = r.Existing.Version
+// InsertAlertRule is a handler for creating an alert rule. The UID of the rule is generated unless the rule
+// already has one, it returns ngmodels.ErrAlertRuleUniqueConstraintViolation if a rule with the UID exists.
+func (st DBstore) InsertAlertRule(rule ngmodels.AlertRule) (*ngmodels.AlertRule, error) {
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if rule.UID != "" {
+			exists, err := sess.Exist(&ngmodels.AlertRule{OrgID: rule.OrgID, UID: rule.UID})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return ngmodels.ErrAlertRuleUniqueConstraintViolation
 			}
-
-			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
-			})
 		}
 
-		if len(newRules) > 0 {
-			if _, err := sess.Insert(&newRules); err != nil {
-				return fmt.Errorf("failed to create new rules: %w", err)
-			}
+		if err := st.prepareNewAlertRule(sess, &rule); err != nil {
+			return err
 		}
 
-		if len(ruleVersions) > 0 {
-			if _, err := sess.Insert(&ruleVersions); err != nil {
-				return fmt.Errorf("failed to create new rule versions: %w", err)
+		if _, err := sess.Insert(&rule); err != nil {
+			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ngmodels.ErrAlertRuleUniqueConstraintViolation
 			}
+			return fmt.Errorf("failed to create new rule: %w", err)
 		}
 
+		ruleVersion := newAlertRuleVersion(rule, 0)
+		if _, err := sess.Insert(&ruleVersion); err != nil {
+			return fmt.Errorf("failed to create new rule version: %w", err)
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// prepareNewAlertRule sets the UID, version and default values of a new alert rule and validates it.
+func (st DBstore) prepareNewAlertRule(sess *sqlstore.DBSession, rule *ngmodels.AlertRule) error {
+	if rule.UID == "" {
+		uid, err := GenerateNewAlertRuleUID(sess, rule.OrgID, rule.Title)
+		if err != nil {
+			return fmt.Errorf("failed to generate UID for alert rule %q: %w", rule.Title, err)
+		}
+		rule.UID = uid
+	}
+
+	if rule.IntervalSeconds == 0 {
+		rule.IntervalSeconds = st.DefaultIntervalSeconds
+	}
+
+	rule.Version = 1
+
+	if rule.NoDataState == "" {
+		// set default no data state
+		rule.NoDataState = ngmodels.NoData
+	}
+
+	if rule.ExecErrState == "" {
+		// set default error state
+		rule.ExecErrState = ngmodels.AlertingErrState
+	}
+
+	if err := st.validateAlertRule(*rule); err != nil {
+		return err
+	}
+
+	return rule.PreSave(TimeNow)
+}
+
+func newAlertRuleVersion(rule ngmodels.AlertRule, parentVersion int64) ngmodels.AlertRuleVersion {
+	return ngmodels.AlertRuleVersion{
+		RuleOrgID:                rule.OrgID,
+		RuleUID:                  rule.UID,
+		RuleNamespaceUID:         rule.NamespaceUID,
+		RuleGroup:                rule.RuleGroup,
+		ParentVersion:            parentVersion,
+		Version:                  rule.Version,
+		Created:                  rule.Updated,
+		Condition:                rule.Condition,
+		Title:                    rule.Title,
+		Data:                     rule.Data,
+		IntervalSeconds:          rule.IntervalSeconds,
+		NoDataState:              rule.NoDataState,
+		ExecErrState:             rule.ExecErrState,
+		EvaluationTimeoutSeconds: rule.EvaluationTimeoutSeconds,
+		Record:                   rule.Record,
+		HeartbeatTimeoutSeconds:  rule.HeartbeatTimeoutSeconds,
+		CorrelationKey:           rule.CorrelationKey,
+		MaxAlertInstances:        rule.MaxAlertInstances,
+		For:                      rule.For,
+		Annotations:              rule.Annotations,
+		Labels:                   rule.Labels,
+	}
 }
 
 // GetOrgAlertRules is a handler for retrieving alert rules of specific organisation.
 func (st DBstore) GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT * FROM alert_rule WHERE org_id = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? ORDER BY rule_group_idx, id"
 		if err := sess.SQL(q, query.OrgID).Find(&alertRules); err != nil {
 			return err
 		}
@@ -334,7 +434,7 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		// TODO rewrite using group by namespace_uid, rule_group
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? ORDER BY rule_group_idx, id"
 		if err := sess.SQL(q, query.OrgID, query.NamespaceUID).Find(&alertRules); err != nil {
 			return err
 		}
@@ -345,12 +445,11 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 }
 
 // GetRuleGroupAlertRules is a handler for retrieving rule group alert rules of specific organisation.
+// The rules are returned in the order of the rule group.
 func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		alertRules := make([]*ngmodels.AlertRule, 0)
-
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
-		if err := sess.SQL(q, query.OrgID, query.NamespaceUID, query.RuleGroup).Find(&alertRules); err != nil {
+		alertRules, err := getRuleGroupAlertRules(sess, query.OrgID, query.NamespaceUID, query.RuleGroup)
+		if err != nil {
 			return err
 		}
 
@@ -359,6 +458,15 @@ func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRules
 	})
 }
 
+func getRuleGroupAlertRules(sess *sqlstore.DBSession, orgID int64, namespaceUID string, ruleGroup string) ([]*ngmodels.AlertRule, error) {
+	alertRules := make([]*ngmodels.AlertRule, 0)
+	q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ? ORDER BY rule_group_idx, id"
+	if err := sess.SQL(q, orgID, namespaceUID, ruleGroup).Find(&alertRules); err != nil {
+		return nil, err
+	}
+	return alertRules, nil
+}
+
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
 func (st DBstore) GetNamespaceByTitle(namespace string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
 	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
@@ -391,12 +499,12 @@ func (st DBstore) GetNamespaceByUID(UID string, orgID int64, user *models.Signed
 	return folder, nil
 }
 
-// GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
-// that is useful for it's scheduling.
+// GetAlertRulesForScheduling returns alert rule info (identifier, rule group, interval, version state)
+// that is useful for it's scheduling. Paused alert rules are not returned.
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, namespace_uid, rule_group, interval_seconds, version FROM alert_rule WHERE is_paused = " + st.SQLStore.Dialect.BooleanStr(false)
 		if err := sess.SQL(q).Find(&alerts); err != nil {
 			return err
 		}
@@ -428,7 +536,7 @@ var GenerateNewAlertRuleUID = func(sess *sqlstore.DBSession, orgID int64, ruleTi
 
 // validateAlertRule validates the alert rule interval and organisation.
 func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
-	if len(alertRule.Data) == 0 {
+	if len(alertRule.Data) == 0 && !alertRule.IsHeartbeat() {
 		return fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
@@ -440,6 +548,46 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: interval (%v) should be non-zero and divided exactly by scheduler interval: %v", ngmodels.ErrAlertRuleFailedValidation, time.Duration(alertRule.IntervalSeconds)*time.Second, st.BaseInterval)
 	}
 
+	if alertRule.EvaluationTimeoutSeconds < 0 || alertRule.EvaluationTimeoutSeconds > alertRule.IntervalSeconds {
+		return fmt.Errorf("%w: evaluation timeout (%v) should not be negative or greater than the interval: %v", ngmodels.ErrAlertRuleFailedValidation, time.Duration(alertRule.EvaluationTimeoutSeconds)*time.Second, time.Duration(alertRule.IntervalSeconds)*time.Second)
+	}
+
+	if alertRule.Record != "" && !prometheusModel.IsValidMetricName(prometheusModel.LabelValue(alertRule.Record)) {
+		return fmt.Errorf("%w: invalid metric name of the recording rule: %s", ngmodels.ErrAlertRuleFailedValidation, alertRule.Record)
+	}
+
+	if alertRule.HeartbeatTimeoutSeconds < 0 {
+		return fmt.Errorf("%w: heartbeat timeout should not be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.IsHeartbeat() && alertRule.IsRecording() {
+		return fmt.Errorf("%w: a recording rule can't be a heartbeat rule", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.CorrelationKey != "" && alertRule.IsRecording() {
+		return fmt.Errorf("%w: a recording rule has no alerts to correlate", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if len(alertRule.CorrelationKey) > AlertRuleMaxCorrelationKeyLength {
+		return fmt.Errorf("%w: correlation key length should not be greater than %d", ngmodels.ErrAlertRuleFailedValidation, AlertRuleMaxCorrelationKeyLength)
+	}
+
+	if _, err := template.New("correlation_key").Parse("{{- $labels := .Labels -}}" + alertRule.CorrelationKey); err != nil {
+		return fmt.Errorf("%w: invalid correlation key template: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+	}
+
+	if alertRule.MaxAlertInstances < 0 {
+		return fmt.Errorf("%w: maximum number of alert instances should not be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if !alertRule.NoDataState.IsValid() {
+		return fmt.Errorf("%w: unknown no data state: %s", ngmodels.ErrAlertRuleFailedValidation, alertRule.NoDataState)
+	}
+
+	if !alertRule.ExecErrState.IsValid() {
+		return fmt.Errorf("%w: unknown execution error state: %s", ngmodels.ErrAlertRuleFailedValidation, alertRule.ExecErrState)
+	}
+
 	// enfore max name length in SQLite
 	if len(alertRule.Title) > AlertRuleMaxTitleLength {
 		return fmt.Errorf("%w: name length should not be greater than %d", ngmodels.ErrAlertRuleFailedValidation, AlertRuleMaxTitleLength)
@@ -457,19 +605,15 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	return nil
 }
 
-// UpdateRuleGroup creates new rules and updates and/or deletes existing rules
+// UpdateRuleGroup creates new rules and updates and/or deletes existing rules in a single transaction,
+// the rules of the group get the order of the posted rules.
 func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		ruleGroup := cmd.RuleGroupConfig.Name
-		q := &ngmodels.ListRuleGroupAlertRulesQuery{
-			OrgID:        cmd.OrgID,
-			NamespaceUID: cmd.NamespaceUID,
-			RuleGroup:    ruleGroup,
-		}
-		if err := st.GetRuleGroupAlertRules(q); err != nil {
+		existingGroupRules, err := getRuleGroupAlertRules(sess, cmd.OrgID, cmd.NamespaceUID, ruleGroup)
+		if err != nil {
 			return err
 		}
-		existingGroupRules := q.Result
 
 		existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
 		for _, r := range existingGroupRules {
@@ -477,22 +621,29 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 		}
 
 		upsertRules := make([]UpsertRule, 0)
-		for _, r := range cmd.RuleGroupConfig.Rules {
+		for i, r := range cmd.RuleGroupConfig.Rules {
 			if r.GrafanaManagedAlert == nil {
 				continue
 			}
 
 			new := ngmodels.AlertRule{
-				OrgID:           cmd.OrgID,
-				Title:           r.GrafanaManagedAlert.Title,
-				Condition:       r.GrafanaManagedAlert.Condition,
-				Data:            r.GrafanaManagedAlert.Data,
-				UID:             r.GrafanaManagedAlert.UID,
-				IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-				NamespaceUID:    cmd.NamespaceUID,
-				RuleGroup:       ruleGroup,
-				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
-				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				OrgID:                    cmd.OrgID,
+				Title:                    r.GrafanaManagedAlert.Title,
+				Condition:                r.GrafanaManagedAlert.Condition,
+				Data:                     r.GrafanaManagedAlert.Data,
+				UID:                      r.GrafanaManagedAlert.UID,
+				IntervalSeconds:          int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+				NamespaceUID:             cmd.NamespaceUID,
+				RuleGroup:                ruleGroup,
+				NoDataState:              ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+				ExecErrState:             ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				EvaluationTimeoutSeconds: int64(time.Duration(r.GrafanaManagedAlert.EvaluationTimeout).Seconds()),
+				IsPaused:                 r.GrafanaManagedAlert.IsPaused,
+				Record:                   r.GrafanaManagedAlert.Record,
+				HeartbeatTimeoutSeconds:  int64(time.Duration(r.GrafanaManagedAlert.HeartbeatTimeout).Seconds()),
+				CorrelationKey:           r.GrafanaManagedAlert.CorrelationKey,
+				MaxAlertInstances:        r.GrafanaManagedAlert.MaxAlertInstances,
+				RuleGroupIndex:           int64(i + 1),
 			}
 
 			if r.ApiRuleNode != nil {
@@ -513,7 +664,7 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 			upsertRules = append(upsertRules, upsertRule)
 		}
 
-		if err := st.UpsertAlertRules(upsertRules); err != nil {
+		if err := st.upsertAlertRules(sess, upsertRules); err != nil {
 			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
 				return ngmodels.ErrAlertRuleUniqueConstraintViolation
 			}
@@ -523,7 +674,7 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 		// delete instances for rules that will not be removed
 		for _, rule := range existingGroupRules {
 			if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
-				if err := st.DeleteAlertInstancesByRuleUID(cmd.OrgID, rule.UID); err != nil {
+				if _, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", cmd.OrgID, rule.UID); err != nil {
 					return err
 				}
 			}
@@ -531,10 +682,211 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 
 		// delete the remaining rules
 		for ruleUID := range existingGroupRulesUIDs {
-			if err := st.DeleteAlertRuleByUID(cmd.OrgID, ruleUID); err != nil {
+			if err := deleteAlertRuleByUID(sess, cmd.OrgID, ruleUID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PatchRuleGroup renames, moves to another namespace, reorders and/or changes the interval of all the rules
+// of the rule group in a single transaction. It returns ngmodels.ErrRuleGroupNamespaceNotFound if the rule group
+// has no rules and ngmodels.ErrRuleGroupAlreadyExists if the renamed or moved group replaces an existing group.
+func (st DBstore) PatchRuleGroup(cmd *ngmodels.PatchRuleGroupCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		rules, err := getRuleGroupAlertRules(sess, cmd.OrgID, cmd.NamespaceUID, cmd.RuleGroup)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return ngmodels.ErrRuleGroupNamespaceNotFound
+		}
+
+		namespaceUID, ruleGroup := cmd.NamespaceUID, cmd.RuleGroup
+		if cmd.NewNamespaceUID != "" {
+			namespaceUID = cmd.NewNamespaceUID
+		}
+		if cmd.NewRuleGroup != "" {
+			ruleGroup = cmd.NewRuleGroup
+		}
+		if namespaceUID != cmd.NamespaceUID || ruleGroup != cmd.RuleGroup {
+			exists, err := sess.Exist(&ngmodels.AlertRule{OrgID: cmd.OrgID, NamespaceUID: namespaceUID, RuleGroup: ruleGroup})
+			if err != nil {
 				return err
 			}
+			if exists {
+				return ngmodels.ErrRuleGroupAlreadyExists
+			}
+		}
+
+		var order map[string]int64
+		if len(cmd.RuleUIDs) > 0 {
+			order = make(map[string]int64, len(cmd.RuleUIDs))
+			for i, uid := range cmd.RuleUIDs {
+				order[uid] = int64(i + 1)
+			}
+			if len(order) != len(rules) || len(cmd.RuleUIDs) != len(rules) {
+				return fmt.Errorf("%w: the order of the rules should contain every rule of the group once", ngmodels.ErrAlertRuleFailedValidation)
+			}
+		}
+
+		updated := make([]*ngmodels.AlertRule, 0, len(rules))
+		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+		for _, existing := range rules {
+			rule := *existing
+			rule.NamespaceUID = namespaceUID
+			rule.RuleGroup = ruleGroup
+			if cmd.IntervalSeconds > 0 {
+				rule.IntervalSeconds = cmd.IntervalSeconds
+			}
+			if order != nil {
+				idx, ok := order[rule.UID]
+				if !ok {
+					return fmt.Errorf("%w: the order of the rules should contain every rule of the group once", ngmodels.ErrAlertRuleFailedValidation)
+				}
+				rule.RuleGroupIndex = idx
+			}
+			rule.Version = existing.Version + 1
+
+			if err := st.validateAlertRule(rule); err != nil {
+				return err
+			}
+			if err := rule.PreSave(TimeNow); err != nil {
+				return err
+			}
+			if _, err := sess.ID(existing.ID).AllCols().Update(rule); err != nil {
+				if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+					return ngmodels.ErrAlertRuleUniqueConstraintViolation
+				}
+				return fmt.Errorf("failed to update rule %s: %w", rule.Title, err)
+			}
+
+			updated = append(updated, &rule)
+			ruleVersions = append(ruleVersions, newAlertRuleVersion(rule, existing.Version))
+		}
+
+		if _, err := sess.Insert(&ruleVersions); err != nil {
+			return fmt.Errorf("failed to create new rule versions: %w", err)
 		}
+
+		cmd.Result = updated
+		return nil
+	})
+}
+
+// SetRuleGroupPaused pauses or resumes all the rules of the rule group, the definition of the rules is unchanged.
+// It returns ngmodels.ErrRuleGroupNamespaceNotFound if the rule group has no rules.
+func (st DBstore) SetRuleGroupPaused(cmd *ngmodels.SetRuleGroupPausedCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var uids []string
+		if err := sess.Table("alert_rule").Where("org_id = ? AND namespace_uid = ? AND rule_group = ?", cmd.OrgID, cmd.NamespaceUID, cmd.RuleGroup).Cols("uid").Find(&uids); err != nil {
+			return err
+		}
+		if len(uids) == 0 {
+			return ngmodels.ErrRuleGroupNamespaceNotFound
+		}
+
+		if _, err := sess.Exec("UPDATE alert_rule SET is_paused = ? WHERE org_id = ? AND namespace_uid = ? AND rule_group = ?", cmd.IsPaused, cmd.OrgID, cmd.NamespaceUID, cmd.RuleGroup); err != nil {
+			return err
+		}
+
+		cmd.Result = uids
+		return nil
+	})
+}
+
+// GetAlertRuleVersions is a handler for retrieving the versions of an alert rule, most recent first.
+func (st DBstore) GetAlertRuleVersions(query *ngmodels.ListAlertRuleVersionsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		versions := make([]*ngmodels.AlertRuleVersion, 0)
+		q := sess.Where("rule_org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID).Desc("version")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		if err := q.Find(&versions); err != nil {
+			return err
+		}
+
+		query.Result = versions
+		return nil
+	})
+}
+
+// GetAlertRuleVersion is a handler for retrieving a version of an alert rule.
+// It returns ngmodels.ErrAlertRuleVersionNotFound if the rule has no such version.
+func (st DBstore) GetAlertRuleVersion(query *ngmodels.GetAlertRuleVersionQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		version, err := getAlertRuleVersion(sess, query.OrgID, query.RuleUID, query.Version)
+		if err != nil {
+			return err
+		}
+		query.Result = version
+		return nil
+	})
+}
+
+func getAlertRuleVersion(sess *sqlstore.DBSession, orgID int64, ruleUID string, version int64) (*ngmodels.AlertRuleVersion, error) {
+	ruleVersion := ngmodels.AlertRuleVersion{}
+	has, err := sess.Where("rule_org_id = ? AND rule_uid = ? AND version = ?", orgID, ruleUID, version).Get(&ruleVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ngmodels.ErrAlertRuleVersionNotFound
+	}
+	return &ruleVersion, nil
+}
+
+// RestoreAlertRuleVersion is a handler for restoring the definition of an alert rule to one of its versions.
+// The rule keeps its rule group and interval, and the restore is saved as a new version restored from the old one.
+func (st DBstore) RestoreAlertRuleVersion(cmd *ngmodels.RestoreAlertRuleVersionCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing, err := getAlertRuleByUID(sess, cmd.RuleUID, cmd.OrgID)
+		if err != nil {
+			return err
+		}
+		version, err := getAlertRuleVersion(sess, cmd.OrgID, cmd.RuleUID, cmd.Version)
+		if err != nil {
+			return err
+		}
+
+		rule := *existing
+		rule.Title = version.Title
+		rule.Condition = version.Condition
+		rule.Data = version.Data
+		rule.NoDataState = version.NoDataState
+		rule.ExecErrState = version.ExecErrState
+		rule.EvaluationTimeoutSeconds = version.EvaluationTimeoutSeconds
+		rule.Record = version.Record
+		rule.HeartbeatTimeoutSeconds = version.HeartbeatTimeoutSeconds
+		rule.CorrelationKey = version.CorrelationKey
+		rule.MaxAlertInstances = version.MaxAlertInstances
+		rule.For = version.For
+		rule.Annotations = version.Annotations
+		rule.Labels = version.Labels
+		rule.Version = existing.Version + 1
+
+		if err := st.validateAlertRule(rule); err != nil {
+			return err
+		}
+		if err := rule.PreSave(TimeNow); err != nil {
+			return err
+		}
+		if _, err := sess.ID(existing.ID).AllCols().Update(rule); err != nil {
+			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ngmodels.ErrAlertRuleUniqueConstraintViolation
+			}
+			return fmt.Errorf("failed to update rule %s: %w", rule.Title, err)
+		}
+
+		ruleVersion := newAlertRuleVersion(rule, existing.Version)
+		ruleVersion.RestoredFrom = version.Version
+		if _, err := sess.Insert(&ruleVersion); err != nil {
+			return fmt.Errorf("failed to create new rule version: %w", err)
+		}
+
+		cmd.Result = &rule
 		return nil
 	})
 }
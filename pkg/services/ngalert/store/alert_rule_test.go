@@ -0,0 +1,388 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/registry"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRuleGroupPaused(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60)
+
+	isScheduled := func(t *testing.T) bool {
+		t.Helper()
+		q := &models.ListAlertRulesQuery{}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(q))
+		for _, r := range q.Result {
+			if r.UID == alertRule.UID {
+				require.Equal(t, alertRule.NamespaceUID, r.NamespaceUID)
+				require.Equal(t, alertRule.RuleGroup, r.RuleGroup)
+				return true
+			}
+		}
+		return false
+	}
+
+	getRule := func(t *testing.T) *models.AlertRule {
+		t.Helper()
+		q := &models.GetAlertRuleByUIDQuery{OrgID: alertRule.OrgID, UID: alertRule.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(q))
+		return q.Result
+	}
+
+	t.Run("paused rules are not scheduled", func(t *testing.T) {
+		require.True(t, isScheduled(t))
+
+		cmd := &models.SetRuleGroupPausedCommand{
+			OrgID:        alertRule.OrgID,
+			NamespaceUID: alertRule.NamespaceUID,
+			RuleGroup:    alertRule.RuleGroup,
+			IsPaused:     true,
+		}
+		require.NoError(t, dbstore.SetRuleGroupPaused(cmd))
+		require.Equal(t, []string{alertRule.UID}, cmd.Result)
+
+		rule := getRule(t)
+		require.True(t, rule.IsPaused)
+		require.Equal(t, alertRule.Version, rule.Version)
+		require.False(t, isScheduled(t))
+	})
+
+	t.Run("resumed rules are scheduled", func(t *testing.T) {
+		cmd := &models.SetRuleGroupPausedCommand{
+			OrgID:        alertRule.OrgID,
+			NamespaceUID: alertRule.NamespaceUID,
+			RuleGroup:    alertRule.RuleGroup,
+			IsPaused:     false,
+		}
+		require.NoError(t, dbstore.SetRuleGroupPaused(cmd))
+
+		require.False(t, getRule(t).IsPaused)
+		require.True(t, isScheduled(t))
+	})
+
+	t.Run("pausing an unknown rule group fails", func(t *testing.T) {
+		cmd := &models.SetRuleGroupPausedCommand{
+			OrgID:        alertRule.OrgID,
+			NamespaceUID: alertRule.NamespaceUID,
+			RuleGroup:    "unknown",
+			IsPaused:     true,
+		}
+		require.ErrorIs(t, dbstore.SetRuleGroupPaused(cmd), models.ErrRuleGroupNamespaceNotFound)
+	})
+}
+
+func TestAlertRuleEvaluationSettings(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	existing := tests.CreateTestAlertRule(t, dbstore, 60)
+	newRule := func(title string) models.AlertRule {
+		return models.AlertRule{
+			OrgID:           existing.OrgID,
+			Title:           title,
+			Condition:       existing.Condition,
+			Data:            existing.Data,
+			IntervalSeconds: 60,
+			NamespaceUID:    existing.NamespaceUID,
+			RuleGroup:       existing.RuleGroup,
+		}
+	}
+
+	t.Run("the evaluation timeout and the error and no data states are stored", func(t *testing.T) {
+		rule := newRule("with evaluation settings")
+		rule.EvaluationTimeoutSeconds = 30
+		rule.ExecErrState = models.KeepLastStateErrState
+		rule.NoDataState = models.KeepLastState
+		inserted, err := dbstore.InsertAlertRule(rule)
+		require.NoError(t, err)
+
+		q := &models.GetAlertRuleByUIDQuery{OrgID: inserted.OrgID, UID: inserted.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(q))
+		require.Equal(t, int64(30), q.Result.EvaluationTimeoutSeconds)
+		require.Equal(t, models.KeepLastStateErrState, q.Result.ExecErrState)
+		require.Equal(t, models.KeepLastState, q.Result.NoDataState)
+	})
+
+	t.Run("the evaluation timeout can't be greater than the interval", func(t *testing.T) {
+		rule := newRule("with a long evaluation timeout")
+		rule.EvaluationTimeoutSeconds = 120
+		_, err := dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("unknown error and no data states are rejected", func(t *testing.T) {
+		rule := newRule("with an unknown error state")
+		rule.ExecErrState = "Unknown"
+		_, err := dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		rule = newRule("with an unknown no data state")
+		rule.NoDataState = "Unknown"
+		_, err = dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("the metric name of recording rules is stored and validated", func(t *testing.T) {
+		rule := newRule("recording rule")
+		rule.Record = "job:errors:rate5m"
+		inserted, err := dbstore.InsertAlertRule(rule)
+		require.NoError(t, err)
+
+		q := &models.GetAlertRuleByUIDQuery{OrgID: inserted.OrgID, UID: inserted.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(q))
+		require.Equal(t, "job:errors:rate5m", q.Result.Record)
+
+		rule = newRule("recording rule with an invalid metric name")
+		rule.Record = "errors rate"
+		_, err = dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("heartbeat rules don't need queries", func(t *testing.T) {
+		rule := newRule("heartbeat rule")
+		rule.Condition = ""
+		rule.Data = nil
+		rule.HeartbeatTimeoutSeconds = 600
+		inserted, err := dbstore.InsertAlertRule(rule)
+		require.NoError(t, err)
+
+		q := &models.GetAlertRuleByUIDQuery{OrgID: inserted.OrgID, UID: inserted.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(q))
+		require.True(t, q.Result.IsHeartbeat())
+
+		rule = newRule("recording heartbeat rule")
+		rule.HeartbeatTimeoutSeconds = 600
+		rule.Record = "job:errors:rate5m"
+		_, err = dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("the correlation key is stored and validated", func(t *testing.T) {
+		rule := newRule("correlated rule")
+		rule.CorrelationKey = "{{ $labels.cluster }}"
+		inserted, err := dbstore.InsertAlertRule(rule)
+		require.NoError(t, err)
+
+		q := &models.GetAlertRuleByUIDQuery{OrgID: inserted.OrgID, UID: inserted.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(q))
+		require.Equal(t, "{{ $labels.cluster }}", q.Result.CorrelationKey)
+
+		rule = newRule("correlated rule with an invalid template")
+		rule.CorrelationKey = "{{ $labels.cluster"
+		_, err = dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		rule = newRule("correlated recording rule")
+		rule.CorrelationKey = "{{ $labels.cluster }}"
+		rule.Record = "job:errors:rate5m"
+		_, err = dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("the maximum number of alert instances is stored and validated", func(t *testing.T) {
+		rule := newRule("limited rule")
+		rule.MaxAlertInstances = 100
+		inserted, err := dbstore.InsertAlertRule(rule)
+		require.NoError(t, err)
+
+		q := &models.GetAlertRuleByUIDQuery{OrgID: inserted.OrgID, UID: inserted.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(q))
+		require.Equal(t, int64(100), q.Result.MaxAlertInstances)
+
+		rule = newRule("rule with a negative limit")
+		rule.MaxAlertInstances = -1
+		_, err = dbstore.InsertAlertRule(rule)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestAlertRuleVersions(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60)
+	updated := tests.UpdateTestAlertRuleIntervalSeconds(t, dbstore, alertRule, 120)
+	err := dbstore.UpsertAlertRules([]store.UpsertRule{{
+		Existing: updated,
+		New: models.AlertRule{
+			OrgID:           updated.OrgID,
+			UID:             updated.UID,
+			Title:           "renamed",
+			Condition:       updated.Condition,
+			Data:            updated.Data,
+			IntervalSeconds: updated.IntervalSeconds,
+			NamespaceUID:    updated.NamespaceUID,
+			RuleGroup:       updated.RuleGroup,
+			Labels:          map[string]string{"severity": "critical"},
+		},
+	}})
+	require.NoError(t, err)
+
+	t.Run("versions are listed most recent first", func(t *testing.T) {
+		q := &models.ListAlertRuleVersionsQuery{OrgID: alertRule.OrgID, RuleUID: alertRule.UID}
+		require.NoError(t, dbstore.GetAlertRuleVersions(q))
+		require.Len(t, q.Result, 3)
+		for i, v := range q.Result {
+			require.Equal(t, int64(3-i), v.Version)
+		}
+		require.Equal(t, "renamed", q.Result[0].Title)
+
+		q = &models.ListAlertRuleVersionsQuery{OrgID: alertRule.OrgID, RuleUID: alertRule.UID, Limit: 1}
+		require.NoError(t, dbstore.GetAlertRuleVersions(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, int64(3), q.Result[0].Version)
+	})
+
+	t.Run("getting an unknown version fails", func(t *testing.T) {
+		q := &models.GetAlertRuleVersionQuery{OrgID: alertRule.OrgID, RuleUID: alertRule.UID, Version: 10}
+		require.ErrorIs(t, dbstore.GetAlertRuleVersion(q), models.ErrAlertRuleVersionNotFound)
+	})
+
+	t.Run("restoring a version creates a new version with its definition", func(t *testing.T) {
+		cmd := &models.RestoreAlertRuleVersionCommand{OrgID: alertRule.OrgID, RuleUID: alertRule.UID, Version: 1}
+		require.NoError(t, dbstore.RestoreAlertRuleVersion(cmd))
+		require.Equal(t, int64(4), cmd.Result.Version)
+		require.Equal(t, alertRule.Title, cmd.Result.Title)
+		require.Empty(t, cmd.Result.Labels)
+		// the interval is the one of the rule group
+		require.Equal(t, int64(120), cmd.Result.IntervalSeconds)
+
+		q := &models.GetAlertRuleVersionQuery{OrgID: alertRule.OrgID, RuleUID: alertRule.UID, Version: 4}
+		require.NoError(t, dbstore.GetAlertRuleVersion(q))
+		require.Equal(t, int64(1), q.Result.RestoredFrom)
+		require.Equal(t, int64(3), q.Result.ParentVersion)
+		require.Equal(t, alertRule.Title, q.Result.Title)
+	})
+
+	t.Run("restoring an unknown version fails", func(t *testing.T) {
+		cmd := &models.RestoreAlertRuleVersionCommand{OrgID: alertRule.OrgID, RuleUID: alertRule.UID, Version: 10}
+		require.ErrorIs(t, dbstore.RestoreAlertRuleVersion(cmd), models.ErrAlertRuleVersionNotFound)
+	})
+}
+
+func TestPatchRuleGroup(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	existing := tests.CreateTestAlertRule(t, dbstore, 60)
+	saveGroup := func(t *testing.T, name string, titles ...string) []*models.AlertRule {
+		t.Helper()
+		config := apimodels.PostableRuleGroupConfig{Name: name, Interval: model.Duration(time.Minute)}
+		for _, title := range titles {
+			config.Rules = append(config.Rules, apimodels.PostableExtendedRuleNode{
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					Title:     title,
+					Condition: existing.Condition,
+					Data:      existing.Data,
+				},
+			})
+		}
+		require.NoError(t, dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:           existing.OrgID,
+			NamespaceUID:    existing.NamespaceUID,
+			RuleGroupConfig: config,
+		}))
+		return getGroup(t, dbstore, existing.NamespaceUID, name)
+	}
+	titles := func(rules []*models.AlertRule) []string {
+		var titles []string
+		for _, r := range rules {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	rules := saveGroup(t, "group", "first", "second")
+	require.Equal(t, []string{"first", "second"}, titles(rules))
+	saveGroup(t, "other group", "third")
+
+	t.Run("the rules are reordered", func(t *testing.T) {
+		cmd := &models.PatchRuleGroupCommand{
+			OrgID:        existing.OrgID,
+			NamespaceUID: existing.NamespaceUID,
+			RuleGroup:    "group",
+			RuleUIDs:     []string{rules[1].UID, rules[0].UID},
+		}
+		require.NoError(t, dbstore.PatchRuleGroup(cmd))
+		reordered := getGroup(t, dbstore, existing.NamespaceUID, "group")
+		require.Equal(t, []string{"second", "first"}, titles(reordered))
+		require.Equal(t, rules[0].Version+1, reordered[1].Version)
+	})
+
+	t.Run("an invalid order changes nothing", func(t *testing.T) {
+		cmd := &models.PatchRuleGroupCommand{
+			OrgID:           existing.OrgID,
+			NamespaceUID:    existing.NamespaceUID,
+			RuleGroup:       "group",
+			IntervalSeconds: 120,
+			RuleUIDs:        []string{rules[0].UID, rules[0].UID},
+		}
+		require.ErrorIs(t, dbstore.PatchRuleGroup(cmd), models.ErrAlertRuleFailedValidation)
+		for _, r := range getGroup(t, dbstore, existing.NamespaceUID, "group") {
+			require.Equal(t, int64(60), r.IntervalSeconds)
+		}
+	})
+
+	t.Run("the rule group is renamed, moved and gets a new interval", func(t *testing.T) {
+		cmd := &models.PatchRuleGroupCommand{
+			OrgID:           existing.OrgID,
+			NamespaceUID:    existing.NamespaceUID,
+			RuleGroup:       "group",
+			NewNamespaceUID: "another namespace",
+			NewRuleGroup:    "renamed",
+			IntervalSeconds: 120,
+		}
+		require.NoError(t, dbstore.PatchRuleGroup(cmd))
+		require.Len(t, cmd.Result, 2)
+
+		require.Empty(t, getGroup(t, dbstore, existing.NamespaceUID, "group"))
+		moved := getGroup(t, dbstore, "another namespace", "renamed")
+		require.Equal(t, []string{"second", "first"}, titles(moved))
+		for _, r := range moved {
+			require.Equal(t, int64(120), r.IntervalSeconds)
+		}
+	})
+
+	t.Run("a rule group can't replace an existing rule group", func(t *testing.T) {
+		cmd := &models.PatchRuleGroupCommand{
+			OrgID:           existing.OrgID,
+			NamespaceUID:    "another namespace",
+			RuleGroup:       "renamed",
+			NewNamespaceUID: existing.NamespaceUID,
+			NewRuleGroup:    "other group",
+		}
+		require.ErrorIs(t, dbstore.PatchRuleGroup(cmd), models.ErrRuleGroupAlreadyExists)
+	})
+
+	t.Run("patching an unknown rule group fails", func(t *testing.T) {
+		cmd := &models.PatchRuleGroupCommand{
+			OrgID:        existing.OrgID,
+			NamespaceUID: existing.NamespaceUID,
+			RuleGroup:    "unknown",
+			NewRuleGroup: "renamed",
+		}
+		require.ErrorIs(t, dbstore.PatchRuleGroup(cmd), models.ErrRuleGroupNamespaceNotFound)
+	})
+}
+
+func getGroup(t *testing.T, dbstore *store.DBstore, namespaceUID, ruleGroup string) []*models.AlertRule {
+	t.Helper()
+	q := &models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: namespaceUID, RuleGroup: ruleGroup}
+	require.NoError(t, dbstore.GetRuleGroupAlertRules(q))
+	return q.Result
+}
@@ -19,6 +19,10 @@ type AlertingStore interface {
 	GetLatestAlertmanagerConfiguration(*models.GetLatestAlertmanagerConfigurationQuery) error
 	SaveAlertmanagerConfiguration(*models.SaveAlertmanagerConfigurationCmd) error
 	SaveAlertmanagerConfigurationWithCallback(*models.SaveAlertmanagerConfigurationCmd, SaveCallback) error
+	NotificationLogStore
+	EscalationStore
+	SilenceExpiryNotificationStore
+	MaintenanceStore
 }
 
 // DBstore stores the alert definitions and instances in the database.
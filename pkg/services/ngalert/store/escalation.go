@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// EscalationStore is the database interface for the escalation policies and the escalations of the notifications.
+type EscalationStore interface {
+	ListEscalationPolicies() ([]*models.EscalationPolicy, error)
+	GetEscalationPolicy(query *models.GetEscalationPolicyQuery) error
+	CreateEscalationPolicy(policy *models.EscalationPolicy) error
+	UpdateEscalationPolicy(policy *models.EscalationPolicy) error
+	DeleteEscalationPolicy(uid string) error
+
+	GetEscalation(query *models.GetEscalationQuery) error
+	ListEscalations(query *models.ListEscalationsQuery) error
+	SaveEscalation(escalation *models.Escalation) error
+	AcknowledgeEscalation(cmd *models.AcknowledgeEscalationCommand) error
+	DeleteEscalation(id int64) error
+}
+
+// ListEscalationPolicies returns all the escalation policies ordered by name.
+func (st DBstore) ListEscalationPolicies() ([]*models.EscalationPolicy, error) {
+	policies := make([]*models.EscalationPolicy, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.OrderBy("name").Find(&policies)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetEscalationPolicy returns an escalation policy by its UID.
+// It returns models.ErrEscalationPolicyNotFound if there's no such policy.
+func (st DBstore) GetEscalationPolicy(query *models.GetEscalationPolicyQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		policy := &models.EscalationPolicy{}
+		has, err := sess.Where("uid = ?", query.UID).Get(policy)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrEscalationPolicyNotFound
+		}
+
+		query.Result = policy
+		return nil
+	})
+}
+
+// CreateEscalationPolicy adds an escalation policy, generating its UID if it's not set.
+// It returns models.ErrEscalationPolicyExists if the UID is already used.
+func (st DBstore) CreateEscalationPolicy(policy *models.EscalationPolicy) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if policy.UID == "" {
+			uid, err := generateNewEscalationPolicyUID(sess)
+			if err != nil {
+				return err
+			}
+			policy.UID = uid
+		} else {
+			exists, err := sess.Where("uid = ?", policy.UID).Exist(&models.EscalationPolicy{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return models.ErrEscalationPolicyExists
+			}
+		}
+		policy.Updated = TimeNow().Unix()
+		_, err := sess.Insert(policy)
+		return err
+	})
+}
+
+// UpdateEscalationPolicy replaces the name and the steps of an escalation policy.
+// It returns models.ErrEscalationPolicyNotFound if there's no such policy.
+func (st DBstore) UpdateEscalationPolicy(policy *models.EscalationPolicy) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		policy.Updated = TimeNow().Unix()
+		affected, err := sess.Where("uid = ?", policy.UID).Cols("name", "steps", "updated").Update(policy)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrEscalationPolicyNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteEscalationPolicy removes an escalation policy and its escalations.
+// It returns models.ErrEscalationPolicyNotFound if there's no such policy.
+func (st DBstore) DeleteEscalationPolicy(uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_escalation_policy WHERE uid = ?", uid)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrEscalationPolicyNotFound
+		}
+
+		_, err = sess.Exec("DELETE FROM alert_escalation WHERE policy_uid = ?", uid)
+		return err
+	})
+}
+
+// GetEscalation returns an escalation by its ID, or by its policy and group key.
+// It returns models.ErrEscalationNotFound if there's no such escalation.
+func (st DBstore) GetEscalation(query *models.GetEscalationQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		escalation := &models.Escalation{}
+		var (
+			has bool
+			err error
+		)
+		if query.ID != 0 {
+			has, err = sess.ID(query.ID).Get(escalation)
+		} else {
+			has, err = sess.Where("policy_uid = ? AND group_key = ?", query.PolicyUID, query.GroupKey).Get(escalation)
+		}
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrEscalationNotFound
+		}
+
+		query.Result = escalation
+		return nil
+	})
+}
+
+// ListEscalations returns the escalations, the oldest first.
+func (st DBstore) ListEscalations(query *models.ListEscalationsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		escalations := make([]*models.Escalation, 0)
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+		s.WriteString("SELECT * FROM alert_escalation")
+		if !query.DueBefore.IsZero() {
+			s.WriteString(" WHERE next_step_at > 0 AND next_step_at <= ?")
+			params = append(params, query.DueBefore.Unix())
+		}
+		s.WriteString(" ORDER BY id")
+
+		if err := sess.SQL(s.String(), params...).Find(&escalations); err != nil {
+			return err
+		}
+
+		query.Result = escalations
+		return nil
+	})
+}
+
+// SaveEscalation adds the escalation if its ID isn't set, or updates it.
+func (st DBstore) SaveEscalation(escalation *models.Escalation) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		now := TimeNow().Unix()
+		escalation.UpdatedAt = now
+		if escalation.ID == 0 {
+			escalation.CreatedAt = now
+			_, err := sess.Insert(escalation)
+			return err
+		}
+
+		affected, err := sess.ID(escalation.ID).AllCols().Update(escalation)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrEscalationNotFound
+		}
+		return nil
+	})
+}
+
+// AcknowledgeEscalation stops an escalation at its current step, an escalation which is already
+// acknowledged keeps its first acknowledgement.
+// It returns models.ErrEscalationNotFound if there's no such escalation.
+func (st DBstore) AcknowledgeEscalation(cmd *models.AcknowledgeEscalationCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		escalation := &models.Escalation{}
+		has, err := sess.ID(cmd.ID).Get(escalation)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrEscalationNotFound
+		}
+		if escalation.Acknowledged() {
+			return nil
+		}
+
+		_, err = sess.Exec("UPDATE alert_escalation SET acknowledged_by = ?, acknowledged_at = ?, next_step_at = 0, updated_at = ? WHERE id = ? AND resolved = ?",
+			cmd.Login, cmd.At.Unix(), TimeNow().Unix(), cmd.ID, false)
+		return err
+	})
+}
+
+// DeleteEscalation removes an escalation.
+func (st DBstore) DeleteEscalation(id int64) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_escalation WHERE id = ?", id)
+		return err
+	})
+}
+
+func generateNewEscalationPolicyUID(sess *sqlstore.DBSession) (string, error) {
+	for i := 0; i < 3; i++ {
+		uid := util.GenerateShortUID()
+
+		exists, err := sess.Where("uid = ?", uid).Exist(&models.EscalationPolicy{})
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return uid, nil
+		}
+	}
+
+	return "", models.ErrEscalationPolicyFailedGenerateUniqueUID
+}
@@ -0,0 +1,117 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestEscalationPolicies(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	policy := &models.EscalationPolicy{
+		Name: "on-call",
+		Steps: []models.EscalationStep{
+			{Receiver: "primary", Wait: 5 * time.Minute},
+			{Receiver: "secondary"},
+		},
+	}
+	require.NoError(t, dbstore.CreateEscalationPolicy(policy))
+	require.NotEmpty(t, policy.UID)
+
+	t.Run("get returns the policy with its steps", func(t *testing.T) {
+		q := &models.GetEscalationPolicyQuery{UID: policy.UID}
+		require.NoError(t, dbstore.GetEscalationPolicy(q))
+		require.Equal(t, "on-call", q.Result.Name)
+		require.Equal(t, policy.Steps, q.Result.Steps)
+	})
+
+	t.Run("create with a used UID returns ErrEscalationPolicyExists", func(t *testing.T) {
+		err := dbstore.CreateEscalationPolicy(&models.EscalationPolicy{UID: policy.UID, Name: "other"})
+		require.ErrorIs(t, err, models.ErrEscalationPolicyExists)
+	})
+
+	t.Run("update replaces the steps", func(t *testing.T) {
+		update := &models.EscalationPolicy{UID: policy.UID, Name: "on-call", Steps: []models.EscalationStep{{Receiver: "primary"}}}
+		require.NoError(t, dbstore.UpdateEscalationPolicy(update))
+
+		policies, err := dbstore.ListEscalationPolicies()
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		require.Equal(t, update.Steps, policies[0].Steps)
+
+		err = dbstore.UpdateEscalationPolicy(&models.EscalationPolicy{UID: "unknown"})
+		require.ErrorIs(t, err, models.ErrEscalationPolicyNotFound)
+	})
+
+	t.Run("delete removes the policy and its escalations", func(t *testing.T) {
+		escalation := &models.Escalation{PolicyUID: policy.UID, GroupKey: "group", Step: -1}
+		require.NoError(t, dbstore.SaveEscalation(escalation))
+
+		require.NoError(t, dbstore.DeleteEscalationPolicy(policy.UID))
+		err := dbstore.GetEscalationPolicy(&models.GetEscalationPolicyQuery{UID: policy.UID})
+		require.ErrorIs(t, err, models.ErrEscalationPolicyNotFound)
+		err = dbstore.GetEscalation(&models.GetEscalationQuery{ID: escalation.ID})
+		require.ErrorIs(t, err, models.ErrEscalationNotFound)
+
+		require.ErrorIs(t, dbstore.DeleteEscalationPolicy(policy.UID), models.ErrEscalationPolicyNotFound)
+	})
+}
+
+func TestEscalations(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	now := time.Now()
+	due := &models.Escalation{
+		PolicyUID:  "policy",
+		GroupKey:   "due",
+		Alerts:     []model.Alert{{Labels: model.LabelSet{"alertname": "test"}}},
+		Step:       -1,
+		NextStepAt: now.Add(-time.Minute).Unix(),
+	}
+	later := &models.Escalation{PolicyUID: "policy", GroupKey: "later", Step: 0, NextStepAt: now.Add(time.Hour).Unix()}
+	require.NoError(t, dbstore.SaveEscalation(due))
+	require.NoError(t, dbstore.SaveEscalation(later))
+
+	t.Run("get by policy and group key returns the escalation with its alerts", func(t *testing.T) {
+		q := &models.GetEscalationQuery{PolicyUID: "policy", GroupKey: "due"}
+		require.NoError(t, dbstore.GetEscalation(q))
+		require.Equal(t, due.ID, q.Result.ID)
+		require.Equal(t, due.Alerts, q.Result.Alerts)
+	})
+
+	t.Run("list returns the due escalations", func(t *testing.T) {
+		q := &models.ListEscalationsQuery{DueBefore: now}
+		require.NoError(t, dbstore.ListEscalations(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, due.ID, q.Result[0].ID)
+
+		q = &models.ListEscalationsQuery{}
+		require.NoError(t, dbstore.ListEscalations(q))
+		require.Len(t, q.Result, 2)
+	})
+
+	t.Run("acknowledge stops the escalation and keeps the first acknowledgement", func(t *testing.T) {
+		require.NoError(t, dbstore.AcknowledgeEscalation(&models.AcknowledgeEscalationCommand{ID: later.ID, Login: "first", At: now}))
+		require.NoError(t, dbstore.AcknowledgeEscalation(&models.AcknowledgeEscalationCommand{ID: later.ID, Login: "second", At: now.Add(time.Minute)}))
+
+		q := &models.GetEscalationQuery{ID: later.ID}
+		require.NoError(t, dbstore.GetEscalation(q))
+		require.Equal(t, "first", q.Result.AcknowledgedBy)
+		require.Equal(t, now.Unix(), q.Result.AcknowledgedAt)
+		require.Zero(t, q.Result.NextStepAt)
+
+		err := dbstore.AcknowledgeEscalation(&models.AcknowledgeEscalationCommand{ID: 12345, Login: "first", At: now})
+		require.ErrorIs(t, err, models.ErrEscalationNotFound)
+	})
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// AlertRuleHeartbeatStore is the database interface for the heartbeats of the heartbeat rules.
+type AlertRuleHeartbeatStore interface {
+	SaveAlertRuleHeartbeat(cmd *models.SaveAlertRuleHeartbeatCommand) error
+	GetAlertRuleHeartbeat(query *models.GetAlertRuleHeartbeatQuery) error
+}
+
+// SaveAlertRuleHeartbeat saves the last heartbeat of a heartbeat rule, replacing the previous one.
+func (st DBstore) SaveAlertRuleHeartbeat(cmd *models.SaveAlertRuleHeartbeatCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		heartbeat := &models.AlertRuleHeartbeat{OrgID: cmd.OrgID, RuleUID: cmd.RuleUID, ReceivedAt: cmd.ReceivedAt}
+		exists, err := sess.Where("org_id = ? AND rule_uid = ?", cmd.OrgID, cmd.RuleUID).Exist(&models.AlertRuleHeartbeat{})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err := sess.Insert(heartbeat)
+			return err
+		}
+		_, err = sess.Where("org_id = ? AND rule_uid = ?", cmd.OrgID, cmd.RuleUID).Cols("received_at").Update(heartbeat)
+		return err
+	})
+}
+
+// GetAlertRuleHeartbeat returns the last heartbeat of a heartbeat rule, the result is nil if the rule
+// never received a heartbeat.
+func (st DBstore) GetAlertRuleHeartbeat(query *models.GetAlertRuleHeartbeatQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		heartbeat := &models.AlertRuleHeartbeat{}
+		has, err := sess.Where("org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID).Get(heartbeat)
+		if err != nil {
+			return err
+		}
+		if has {
+			query.Result = heartbeat
+		}
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestAlertRuleHeartbeats(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	rule := tests.CreateTestAlertRule(t, dbstore, 60)
+
+	t.Run("rule without heartbeat has no result", func(t *testing.T) {
+		q := &models.GetAlertRuleHeartbeatQuery{OrgID: rule.OrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.GetAlertRuleHeartbeat(q))
+		require.Nil(t, q.Result)
+	})
+
+	t.Run("save replaces the last heartbeat", func(t *testing.T) {
+		first := time.Unix(1000, 0)
+		second := time.Unix(2000, 0)
+		require.NoError(t, dbstore.SaveAlertRuleHeartbeat(&models.SaveAlertRuleHeartbeatCommand{OrgID: rule.OrgID, RuleUID: rule.UID, ReceivedAt: first}))
+		require.NoError(t, dbstore.SaveAlertRuleHeartbeat(&models.SaveAlertRuleHeartbeatCommand{OrgID: rule.OrgID, RuleUID: rule.UID, ReceivedAt: second}))
+
+		q := &models.GetAlertRuleHeartbeatQuery{OrgID: rule.OrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.GetAlertRuleHeartbeat(q))
+		require.NotNil(t, q.Result)
+		require.True(t, second.Equal(q.Result.ReceivedAt))
+	})
+
+	t.Run("deleting the rule deletes its heartbeat", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteAlertRuleByUID(rule.OrgID, rule.UID))
+
+		q := &models.GetAlertRuleHeartbeatQuery{OrgID: rule.OrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.GetAlertRuleHeartbeat(q))
+		require.Nil(t, q.Result)
+	})
+}
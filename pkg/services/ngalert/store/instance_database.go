@@ -1,9 +1,13 @@
 package store
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -13,7 +17,10 @@ type InstanceStore interface {
 	GetAlertInstance(cmd *models.GetAlertInstanceQuery) error
 	ListAlertInstances(cmd *models.ListAlertInstancesQuery) error
 	SaveAlertInstance(cmd *models.SaveAlertInstanceCommand) error
+	SaveAlertInstances(cmd *models.SaveAlertInstancesCommand) error
 	FetchOrgIds() ([]int64, error)
+	SaveAlertInstanceSnapshot(cmd *models.SaveAlertInstanceSnapshotCommand) error
+	GetAlertInstanceSnapshot(query *models.GetAlertInstanceSnapshotQuery) error
 }
 
 // GetAlertInstance is a handler for retrieving an alert instance based on OrgId, AlertDefintionID, and
@@ -85,41 +92,57 @@ func (st DBstore) ListAlertInstances(cmd *models.ListAlertInstancesQuery) error
 // SaveAlertInstance is a handler for saving a new alert instance.
 func (st DBstore) SaveAlertInstance(cmd *models.SaveAlertInstanceCommand) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		labelTupleJSON, labelsHash, err := cmd.Labels.StringAndHash()
-		if err != nil {
-			return err
-		}
-
-		alertInstance := &models.AlertInstance{
-			RuleOrgID:         cmd.RuleOrgID,
-			RuleUID:           cmd.RuleUID,
-			Labels:            cmd.Labels,
-			LabelsHash:        labelsHash,
-			CurrentState:      cmd.State,
-			CurrentStateSince: cmd.CurrentStateSince,
-			CurrentStateEnd:   cmd.CurrentStateEnd,
-			LastEvalTime:      cmd.LastEvalTime,
-		}
-
-		if err := models.ValidateAlertInstance(alertInstance); err != nil {
-			return err
-		}
-
-		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+		return st.upsertAlertInstance(sess, cmd)
+	})
+}
 
-		upsertSQL := st.SQLStore.Dialect.UpsertSQL(
-			"alert_instance",
-			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_state_since", "current_state_end", "last_eval_time"})
-		_, err = sess.SQL(upsertSQL, params...).Query()
-		if err != nil {
-			return err
+// SaveAlertInstances saves the alert instances in a single transaction.
+func (st DBstore) SaveAlertInstances(cmd *models.SaveAlertInstancesCommand) error {
+	if len(cmd.Instances) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for i := range cmd.Instances {
+			if err := st.upsertAlertInstance(sess, &cmd.Instances[i]); err != nil {
+				return err
+			}
 		}
-
 		return nil
 	})
 }
 
+func (st DBstore) upsertAlertInstance(sess *sqlstore.DBSession, cmd *models.SaveAlertInstanceCommand) error {
+	labelTupleJSON, labelsHash, err := cmd.Labels.StringAndHash()
+	if err != nil {
+		return err
+	}
+
+	alertInstance := &models.AlertInstance{
+		RuleOrgID:         cmd.RuleOrgID,
+		RuleUID:           cmd.RuleUID,
+		Labels:            cmd.Labels,
+		LabelsHash:        labelsHash,
+		CurrentState:      cmd.State,
+		CurrentStateSince: cmd.CurrentStateSince,
+		CurrentStateEnd:   cmd.CurrentStateEnd,
+		LastEvalTime:      cmd.LastEvalTime,
+	}
+
+	if err := models.ValidateAlertInstance(alertInstance); err != nil {
+		return err
+	}
+
+	params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+
+	upsertSQL := st.SQLStore.Dialect.UpsertSQL(
+		"alert_instance",
+		[]string{"rule_org_id", "rule_uid", "labels_hash"},
+		[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_state_since", "current_state_end", "last_eval_time"})
+	_, err = sess.SQL(upsertSQL, params...).Query()
+	return err
+}
+
+// FetchOrgIds returns the organisations with alert instances or a snapshot of them.
 func (st DBstore) FetchOrgIds() ([]int64, error) {
 	orgIds := []int64{}
 
@@ -132,7 +155,7 @@ func (st DBstore) FetchOrgIds() ([]int64, error) {
 			params = append(params, p...)
 		}
 
-		addToQuery("SELECT DISTINCT rule_org_id FROM alert_instance")
+		addToQuery("SELECT DISTINCT rule_org_id FROM alert_instance UNION SELECT org_id FROM alert_instance_snapshot")
 
 		if err := sess.SQL(s.String(), params...).Find(&orgIds); err != nil {
 			return err
@@ -142,3 +165,78 @@ func (st DBstore) FetchOrgIds() ([]int64, error) {
 
 	return orgIds, err
 }
+
+// SaveAlertInstanceSnapshot replaces the snapshot of the alert instances of the organisation
+// with a compressed snapshot of the given instances.
+func (st DBstore) SaveAlertInstanceSnapshot(cmd *models.SaveAlertInstanceSnapshotCommand) error {
+	instances := make([]*models.ListAlertInstancesQueryResult, 0, len(cmd.Instances))
+	for _, i := range cmd.Instances {
+		_, labelsHash, err := i.Labels.StringAndHash()
+		if err != nil {
+			return err
+		}
+		instances = append(instances, &models.ListAlertInstancesQueryResult{
+			RuleOrgID:         i.RuleOrgID,
+			RuleUID:           i.RuleUID,
+			Labels:            i.Labels,
+			LabelsHash:        labelsHash,
+			CurrentState:      i.State,
+			CurrentStateSince: i.CurrentStateSince,
+			CurrentStateEnd:   i.CurrentStateEnd,
+			LastEvalTime:      i.LastEvalTime,
+		})
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(instances); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		snapshot := &models.AlertInstanceSnapshot{OrgID: cmd.OrgID, Data: buf.Bytes(), Created: cmd.Created.Unix()}
+		affected, err := sess.Where("org_id = ?", cmd.OrgID).Cols("data", "created").Update(snapshot)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			if _, err := sess.Insert(snapshot); err != nil {
+				return err
+			}
+		}
+		cmd.Result = buf.Len()
+		return nil
+	})
+}
+
+// GetAlertInstanceSnapshot returns the alert instances of the snapshot of the organisation.
+func (st DBstore) GetAlertInstanceSnapshot(query *models.GetAlertInstanceSnapshotQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		snapshot := models.AlertInstanceSnapshot{}
+		has, err := sess.Where("org_id = ?", query.OrgID).Get(&snapshot)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return nil
+		}
+
+		r, err := gzip.NewReader(bytes.NewReader(snapshot.Data))
+		if err != nil {
+			return fmt.Errorf("failed to read the snapshot of the alert instances: %w", err)
+		}
+		defer func() { _ = r.Close() }()
+
+		var instances []*models.ListAlertInstancesQueryResult
+		if err := json.NewDecoder(r).Decode(&instances); err != nil {
+			return fmt.Errorf("failed to read the snapshot of the alert instances: %w", err)
+		}
+
+		query.Result = instances
+		query.Created = time.Unix(snapshot.Created, 0)
+		return nil
+	})
+}
@@ -177,4 +177,70 @@ func TestAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, saveCmdTwo.Labels, listQuery.Result[0].Labels)
 		require.Equal(t, saveCmdTwo.State, listQuery.Result[0].CurrentState)
 	})
+
+	t.Run("can save several instances at once", func(t *testing.T) {
+		alertRule5 := tests.CreateTestAlertRule(t, dbstore, 60)
+
+		cmd := &models.SaveAlertInstancesCommand{
+			Instances: []models.SaveAlertInstanceCommand{
+				{RuleOrgID: alertRule5.OrgID, RuleUID: alertRule5.UID, State: models.InstanceStateFiring, Labels: models.InstanceLabels{"test": "a"}},
+				{RuleOrgID: alertRule5.OrgID, RuleUID: alertRule5.UID, State: models.InstanceStateNormal, Labels: models.InstanceLabels{"test": "b"}},
+			},
+		}
+		require.NoError(t, dbstore.SaveAlertInstances(cmd))
+
+		listQuery := &models.ListAlertInstancesQuery{
+			RuleOrgID: alertRule5.OrgID,
+			RuleUID:   alertRule5.UID,
+		}
+		require.NoError(t, dbstore.ListAlertInstances(listQuery))
+		require.Len(t, listQuery.Result, 2)
+
+		t.Run("nothing is saved if an instance is invalid", func(t *testing.T) {
+			cmd := &models.SaveAlertInstancesCommand{
+				Instances: []models.SaveAlertInstanceCommand{
+					{RuleOrgID: alertRule5.OrgID, RuleUID: alertRule5.UID, State: models.InstanceStateNormal, Labels: models.InstanceLabels{"test": "c"}},
+					{RuleOrgID: alertRule5.OrgID, RuleUID: alertRule5.UID, State: "invalid", Labels: models.InstanceLabels{"test": "d"}},
+				},
+			}
+			require.Error(t, dbstore.SaveAlertInstances(cmd))
+
+			require.NoError(t, dbstore.ListAlertInstances(listQuery))
+			require.Len(t, listQuery.Result, 2)
+		})
+	})
+
+	t.Run("can save and read the snapshot of the instances of an org", func(t *testing.T) {
+		const snapshotOrgID = 1000
+		evalTime := time.Unix(1000, 0).UTC()
+
+		query := &models.GetAlertInstanceSnapshotQuery{OrgID: snapshotOrgID}
+		require.NoError(t, dbstore.GetAlertInstanceSnapshot(query))
+		require.Empty(t, query.Result)
+
+		for i, state := range []models.InstanceStateType{models.InstanceStateNormal, models.InstanceStateFiring} {
+			cmd := &models.SaveAlertInstanceSnapshotCommand{
+				OrgID: snapshotOrgID,
+				Instances: []models.SaveAlertInstanceCommand{
+					{RuleOrgID: snapshotOrgID, RuleUID: "rule", State: state, Labels: models.InstanceLabels{"test": "a"}, LastEvalTime: evalTime},
+				},
+				Created: evalTime.Add(time.Duration(i) * time.Minute),
+			}
+			require.NoError(t, dbstore.SaveAlertInstanceSnapshot(cmd))
+			require.Greater(t, cmd.Result, 0)
+		}
+
+		require.NoError(t, dbstore.GetAlertInstanceSnapshot(query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, "rule", query.Result[0].RuleUID)
+		require.Equal(t, models.InstanceLabels{"test": "a"}, query.Result[0].Labels)
+		require.Equal(t, models.InstanceStateFiring, query.Result[0].CurrentState)
+		require.Equal(t, evalTime, query.Result[0].LastEvalTime)
+		require.Equal(t, evalTime.Add(time.Minute).Unix(), query.Created.Unix())
+
+		orgIDs, err := dbstore.FetchOrgIds()
+		require.NoError(t, err)
+		require.Contains(t, orgIDs, int64(snapshotOrgID))
+		require.Contains(t, orgIDs, orgID)
+	})
 }
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// MaintenanceStore is the database interface for the maintenances of the organisations.
+type MaintenanceStore interface {
+	GetMaintenance(query *models.GetMaintenanceQuery) error
+	SaveMaintenance(m *models.Maintenance) error
+	DeleteMaintenance(orgID int64) error
+}
+
+// GetMaintenance returns the maintenance of an organisation.
+// It returns models.ErrMaintenanceNotFound if the organisation has none.
+func (st DBstore) GetMaintenance(query *models.GetMaintenanceQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		m := &models.Maintenance{}
+		has, err := sess.Where("org_id = ?", query.OrgID).Get(m)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrMaintenanceNotFound
+		}
+
+		query.Result = m
+		return nil
+	})
+}
+
+// SaveMaintenance adds or replaces the maintenance of an organisation.
+func (st DBstore) SaveMaintenance(m *models.Maintenance) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := &models.Maintenance{}
+		has, err := sess.Where("org_id = ?", m.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err := sess.Insert(m)
+			return err
+		}
+
+		m.ID = existing.ID
+		_, err = sess.ID(m.ID).Cols("ends_at", "matchers", "comment", "created_by", "created_at").Update(m)
+		return err
+	})
+}
+
+// DeleteMaintenance removes the maintenance of an organisation.
+// It returns models.ErrMaintenanceNotFound if the organisation has none.
+func (st DBstore) DeleteMaintenance(orgID int64) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_maintenance WHERE org_id = ?", orgID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrMaintenanceNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestMaintenance(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	t.Run("save adds and then replaces the maintenance of the organisation", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveMaintenance(&models.Maintenance{OrgID: 1, EndsAt: 100, Comment: "upgrade", CreatedBy: "admin"}))
+		require.NoError(t, dbstore.SaveMaintenance(&models.Maintenance{OrgID: 1, Matchers: []string{`severity="warning"`}, CreatedBy: "editor"}))
+		require.NoError(t, dbstore.SaveMaintenance(&models.Maintenance{OrgID: 2, CreatedBy: "admin"}))
+
+		q := &models.GetMaintenanceQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetMaintenance(q))
+		require.Zero(t, q.Result.EndsAt)
+		require.Equal(t, []string{`severity="warning"`}, q.Result.Matchers)
+		require.Empty(t, q.Result.Comment)
+		require.Equal(t, "editor", q.Result.CreatedBy)
+	})
+
+	t.Run("delete removes the maintenance of the organisation only", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteMaintenance(1))
+		require.ErrorIs(t, dbstore.GetMaintenance(&models.GetMaintenanceQuery{OrgID: 1}), models.ErrMaintenanceNotFound)
+		require.ErrorIs(t, dbstore.DeleteMaintenance(1), models.ErrMaintenanceNotFound)
+		require.NoError(t, dbstore.GetMaintenance(&models.GetMaintenanceQuery{OrgID: 2}))
+	})
+}
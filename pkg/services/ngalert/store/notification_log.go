@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+var (
+	// ErrNotificationAttemptNotFound is an error for an unknown notification attempt.
+	ErrNotificationAttemptNotFound = fmt.Errorf("could not find notification attempt")
+)
+
+// NotificationLogStore is the database interface for the log of the notification attempts.
+type NotificationLogStore interface {
+	SaveNotificationAttempt(attempt *models.NotificationAttempt) error
+	GetNotificationAttempt(query *models.GetNotificationAttemptQuery) error
+	ListNotificationAttempts(query *models.ListNotificationAttemptsQuery) error
+	DeleteNotificationAttemptsBefore(before time.Time) (int64, error)
+}
+
+// SaveNotificationAttempt adds a notification attempt to the log.
+func (st DBstore) SaveNotificationAttempt(attempt *models.NotificationAttempt) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(attempt)
+		return err
+	})
+}
+
+// GetNotificationAttempt returns a notification attempt by its ID.
+// It returns ErrNotificationAttemptNotFound if there's no such attempt.
+func (st DBstore) GetNotificationAttempt(query *models.GetNotificationAttemptQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		attempt := &models.NotificationAttempt{}
+		has, err := sess.ID(query.ID).Get(attempt)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrNotificationAttemptNotFound
+		}
+
+		query.Result = attempt
+		return nil
+	})
+}
+
+// ListNotificationAttempts is a handler for retrieving the notification attempts based on various filters.
+func (st DBstore) ListNotificationAttempts(query *models.ListNotificationAttemptsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		attempts := make([]*models.NotificationAttempt, 0)
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_notification_log WHERE 1 = 1")
+
+		if query.Receiver != "" {
+			addToQuery(` AND receiver = ?`, query.Receiver)
+		}
+
+		if query.IntegrationType != "" {
+			addToQuery(` AND integration_type = ?`, query.IntegrationType)
+		}
+
+		if query.OnlyFailed {
+			addToQuery(` AND error <> ''`)
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(` AND created_at >= ?`, query.From.Unix())
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(` AND created_at <= ?`, query.To.Unix())
+		}
+
+		addToQuery(` ORDER BY created_at DESC, id DESC`)
+
+		if query.Limit > 0 {
+			addToQuery(st.SQLStore.Dialect.Limit(query.Limit))
+		}
+
+		if err := sess.SQL(s.String(), params...).Find(&attempts); err != nil {
+			return err
+		}
+
+		query.Result = attempts
+		return nil
+	})
+}
+
+// DeleteNotificationAttemptsBefore deletes the notification attempts made before the given time
+// and returns the number of deleted attempts.
+func (st DBstore) DeleteNotificationAttemptsBefore(before time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_notification_log WHERE created_at < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,102 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestNotificationLog(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	now := time.Now()
+	attempts := []*models.NotificationAttempt{
+		{
+			Receiver:        "email-receiver",
+			IntegrationType: "email",
+			GroupKey:        "{}:{alertname=\"test\"}",
+			Alerts:          []model.Alert{{Labels: model.LabelSet{"alertname": "test"}}},
+			DurationMs:      12,
+			CreatedAt:       now.Add(-2 * time.Hour).Unix(),
+		},
+		{
+			Receiver:        "slack-receiver",
+			IntegrationType: "slack",
+			StatusCode:      500,
+			Error:           "request to Slack API failed with status code 500",
+			DurationMs:      34,
+			CreatedAt:       now.Add(-time.Hour).Unix(),
+		},
+		{
+			Receiver:        "slack-receiver",
+			IntegrationType: "slack",
+			DurationMs:      56,
+			CreatedAt:       now.Unix(),
+		},
+	}
+	for _, a := range attempts {
+		require.NoError(t, dbstore.SaveNotificationAttempt(a))
+	}
+
+	t.Run("get returns the attempt with its alerts", func(t *testing.T) {
+		q := &models.GetNotificationAttemptQuery{ID: attempts[0].ID}
+		require.NoError(t, dbstore.GetNotificationAttempt(q))
+		require.Equal(t, "email-receiver", q.Result.Receiver)
+		require.Equal(t, attempts[0].Alerts, q.Result.Alerts)
+		require.True(t, q.Result.Succeeded())
+	})
+
+	t.Run("get unknown attempt returns ErrNotificationAttemptNotFound", func(t *testing.T) {
+		q := &models.GetNotificationAttemptQuery{ID: 12345}
+		require.ErrorIs(t, dbstore.GetNotificationAttempt(q), store.ErrNotificationAttemptNotFound)
+	})
+
+	t.Run("list returns the newest attempts first", func(t *testing.T) {
+		q := &models.ListNotificationAttemptsQuery{}
+		require.NoError(t, dbstore.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, attempts[2].ID, q.Result[0].ID)
+		require.Equal(t, attempts[0].ID, q.Result[2].ID)
+	})
+
+	t.Run("list filters the attempts", func(t *testing.T) {
+		q := &models.ListNotificationAttemptsQuery{Receiver: "slack-receiver"}
+		require.NoError(t, dbstore.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 2)
+
+		q = &models.ListNotificationAttemptsQuery{OnlyFailed: true}
+		require.NoError(t, dbstore.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, 500, q.Result[0].StatusCode)
+
+		q = &models.ListNotificationAttemptsQuery{From: now.Add(-90 * time.Minute), To: now.Add(-30 * time.Minute)}
+		require.NoError(t, dbstore.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, attempts[1].ID, q.Result[0].ID)
+
+		q = &models.ListNotificationAttemptsQuery{Limit: 1}
+		require.NoError(t, dbstore.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, attempts[2].ID, q.Result[0].ID)
+	})
+
+	t.Run("delete removes the attempts older than the given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteNotificationAttemptsBefore(now.Add(-30 * time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		q := &models.ListNotificationAttemptsQuery{}
+		require.NoError(t, dbstore.ListNotificationAttempts(q))
+		require.Len(t, q.Result, 1)
+	})
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type provenanceRecord struct {
+	ID         int64 `xorm:"pk autoincr 'id'"`
+	OrgID      int64 `xorm:"org_id"`
+	RecordKey  string
+	RecordType string
+	Provenance models.Provenance
+}
+
+func (pr provenanceRecord) TableName() string {
+	return "provenance_type"
+}
+
+// ProvisioningStore is the database interface for the provenance of provisioned resources.
+type ProvisioningStore interface {
+	GetProvenance(orgID int64, o models.Provisionable) (models.Provenance, error)
+	GetProvenances(orgID int64, resourceType string) (map[string]models.Provenance, error)
+	SetProvenance(orgID int64, o models.Provisionable, p models.Provenance) error
+	DeleteProvenance(orgID int64, o models.Provisionable) error
+}
+
+// GetProvenance returns the provenance of the resource, it's ProvenanceNone if the resource isn't provisioned.
+func (st DBstore) GetProvenance(orgID int64, o models.Provisionable) (models.Provenance, error) {
+	provenance := models.ProvenanceNone
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		record := provenanceRecord{}
+		has, err := sess.Where("org_id = ? AND record_type = ? AND record_key = ?", orgID, o.ResourceType(), o.ResourceID()).Get(&record)
+		if err != nil {
+			return err
+		}
+		if has {
+			provenance = record.Provenance
+		}
+		return nil
+	})
+	return provenance, err
+}
+
+// GetProvenances returns the provenance of all provisioned resources of the type by resource ID.
+func (st DBstore) GetProvenances(orgID int64, resourceType string) (map[string]models.Provenance, error) {
+	provenances := make(map[string]models.Provenance)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		records := make([]provenanceRecord, 0)
+		if err := sess.Where("org_id = ? AND record_type = ?", orgID, resourceType).Find(&records); err != nil {
+			return err
+		}
+		for _, record := range records {
+			provenances[record.RecordKey] = record.Provenance
+		}
+		return nil
+	})
+	return provenances, err
+}
+
+// SetProvenance sets the provenance of the resource, ProvenanceNone removes the provenance.
+func (st DBstore) SetProvenance(orgID int64, o models.Provisionable, p models.Provenance) error {
+	if p == models.ProvenanceNone {
+		return st.DeleteProvenance(orgID, o)
+	}
+
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		record := provenanceRecord{}
+		has, err := sess.Where("org_id = ? AND record_type = ? AND record_key = ?", orgID, o.ResourceType(), o.ResourceID()).Get(&record)
+		if err != nil {
+			return err
+		}
+
+		record.Provenance = p
+		if has {
+			_, err = sess.ID(record.ID).Cols("provenance").Update(&record)
+			return err
+		}
+
+		record.OrgID = orgID
+		record.RecordType = o.ResourceType()
+		record.RecordKey = o.ResourceID()
+		_, err = sess.Insert(&record)
+		return err
+	})
+}
+
+// DeleteProvenance removes the provenance of the resource.
+func (st DBstore) DeleteProvenance(orgID int64, o models.Provisionable) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM provenance_type WHERE org_id = ? AND record_type = ? AND record_key = ?", orgID, o.ResourceType(), o.ResourceID())
+		return err
+	})
+}
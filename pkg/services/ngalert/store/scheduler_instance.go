@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// SchedulerInstanceStore is the database interface for the instances of the sharded scheduler.
+type SchedulerInstanceStore interface {
+	HeartbeatSchedulerInstance(instanceID string, now time.Time) error
+	GetSchedulerInstances() ([]*models.SchedulerInstance, error)
+	DeleteSchedulerInstance(instanceID string) error
+	DeleteSchedulerInstancesBefore(before time.Time) (int64, error)
+}
+
+// HeartbeatSchedulerInstance records that the instance is alive, adding it if it's not known yet.
+func (st DBstore) HeartbeatSchedulerInstance(instanceID string, now time.Time) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		instance := &models.SchedulerInstance{InstanceID: instanceID, LastHeartbeat: now.Unix()}
+		affected, err := sess.Where("instance_id = ?", instanceID).Cols("last_heartbeat").Update(instance)
+		if err != nil {
+			return err
+		}
+		if affected > 0 {
+			return nil
+		}
+
+		_, err = sess.Insert(instance)
+		return err
+	})
+}
+
+// GetSchedulerInstances returns all the known instances of the sharded scheduler.
+func (st DBstore) GetSchedulerInstances() ([]*models.SchedulerInstance, error) {
+	instances := make([]*models.SchedulerInstance, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.OrderBy("instance_id").Find(&instances)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// DeleteSchedulerInstance removes an instance from the sharded scheduler.
+func (st DBstore) DeleteSchedulerInstance(instanceID string) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_scheduler_instance WHERE instance_id = ?", instanceID)
+		return err
+	})
+}
+
+// DeleteSchedulerInstancesBefore removes the instances whose last heartbeat happened before the given time
+// and returns the number of removed instances.
+func (st DBstore) DeleteSchedulerInstancesBefore(before time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_scheduler_instance WHERE last_heartbeat < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,54 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestSchedulerInstances(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("instance-b", now.Add(-time.Minute)))
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("instance-a", now.Add(-time.Minute)))
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("instance-c", now.Add(-time.Hour)))
+
+	t.Run("a heartbeat of a known instance updates it", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerInstance("instance-a", now))
+
+		instances, err := dbstore.GetSchedulerInstances()
+		require.NoError(t, err)
+		require.Len(t, instances, 3)
+		require.Equal(t, "instance-a", instances[0].InstanceID)
+		require.Equal(t, now.Unix(), instances[0].LastHeartbeat)
+		require.Equal(t, "instance-b", instances[1].InstanceID)
+		require.Equal(t, "instance-c", instances[2].InstanceID)
+	})
+
+	t.Run("can delete the instances that missed their heartbeats", func(t *testing.T) {
+		deleted, err := dbstore.DeleteSchedulerInstancesBefore(now.Add(-2 * time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		instances, err := dbstore.GetSchedulerInstances()
+		require.NoError(t, err)
+		require.Len(t, instances, 2)
+	})
+
+	t.Run("can delete an instance", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSchedulerInstance("instance-b"))
+
+		instances, err := dbstore.GetSchedulerInstances()
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		require.Equal(t, "instance-a", instances[0].InstanceID)
+	})
+}
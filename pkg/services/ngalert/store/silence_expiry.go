@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// SilenceExpiryNotificationStore is the database interface for the expiry notifications of the silences.
+type SilenceExpiryNotificationStore interface {
+	GetSilenceExpiryNotification(query *models.GetSilenceExpiryNotificationQuery) error
+	ListSilenceExpiryNotifications() ([]*models.SilenceExpiryNotification, error)
+	SaveSilenceExpiryNotification(n *models.SilenceExpiryNotification) error
+	DeleteSilenceExpiryNotification(silenceID string) error
+}
+
+// GetSilenceExpiryNotification returns the expiry notification of a silence.
+// It returns models.ErrSilenceExpiryNotificationNotFound if the silence has none.
+func (st DBstore) GetSilenceExpiryNotification(query *models.GetSilenceExpiryNotificationQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		n := &models.SilenceExpiryNotification{}
+		has, err := sess.Where("silence_id = ?", query.SilenceID).Get(n)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrSilenceExpiryNotificationNotFound
+		}
+
+		query.Result = n
+		return nil
+	})
+}
+
+// ListSilenceExpiryNotifications returns the expiry notifications of all the silences.
+func (st DBstore) ListSilenceExpiryNotifications() ([]*models.SilenceExpiryNotification, error) {
+	notifications := make([]*models.SilenceExpiryNotification, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.OrderBy("id").Find(&notifications)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// SaveSilenceExpiryNotification adds or replaces the expiry notification of a silence.
+func (st DBstore) SaveSilenceExpiryNotification(n *models.SilenceExpiryNotification) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := &models.SilenceExpiryNotification{}
+		has, err := sess.Where("silence_id = ?", n.SilenceID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err := sess.Insert(n)
+			return err
+		}
+
+		n.ID = existing.ID
+		_, err = sess.ID(n.ID).Cols("receiver", "notify_before", "notified_at").Update(n)
+		return err
+	})
+}
+
+// DeleteSilenceExpiryNotification removes the expiry notification of a silence.
+// It returns models.ErrSilenceExpiryNotificationNotFound if the silence has none.
+func (st DBstore) DeleteSilenceExpiryNotification(silenceID string) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_silence_expiry_notification WHERE silence_id = ?", silenceID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrSilenceExpiryNotificationNotFound
+		}
+		return nil
+	})
+}
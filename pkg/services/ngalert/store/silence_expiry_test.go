@@ -0,0 +1,41 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestSilenceExpiryNotifications(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	t.Run("save adds and then replaces the expiry notification of the silence", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveSilenceExpiryNotification(&models.SilenceExpiryNotification{SilenceID: "silence", Receiver: "first", NotifyBefore: time.Hour, NotifiedAt: 100}))
+		require.NoError(t, dbstore.SaveSilenceExpiryNotification(&models.SilenceExpiryNotification{SilenceID: "silence", Receiver: "second", NotifyBefore: time.Minute}))
+
+		q := &models.GetSilenceExpiryNotificationQuery{SilenceID: "silence"}
+		require.NoError(t, dbstore.GetSilenceExpiryNotification(q))
+		require.Equal(t, "second", q.Result.Receiver)
+		require.Equal(t, time.Minute, q.Result.NotifyBefore)
+		require.Zero(t, q.Result.NotifiedAt)
+
+		notifications, err := dbstore.ListSilenceExpiryNotifications()
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+	})
+
+	t.Run("delete removes the expiry notification of the silence", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSilenceExpiryNotification("silence"))
+		err := dbstore.GetSilenceExpiryNotification(&models.GetSilenceExpiryNotificationQuery{SilenceID: "silence"})
+		require.ErrorIs(t, err, models.ErrSilenceExpiryNotificationNotFound)
+		require.ErrorIs(t, dbstore.DeleteSilenceExpiryNotification("silence"), models.ErrSilenceExpiryNotificationNotFound)
+	})
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateHistoryStore is the database interface for the state transitions of alert instances.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(cmd *models.SaveAlertStateHistoryCommand) error
+	ListAlertStateHistory(cmd *models.ListAlertStateHistoryQuery) error
+	DeleteAlertStateHistoryBefore(before time.Time) (int64, error)
+}
+
+// SaveAlertStateHistory is a handler for saving state transitions of alert instances.
+func (st DBstore) SaveAlertStateHistory(cmd *models.SaveAlertStateHistoryCommand) error {
+	if len(cmd.Entries) == 0 {
+		return nil
+	}
+
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, entry := range cmd.Entries {
+			if err := models.ValidateAlertStateHistoryEntry(entry); err != nil {
+				return err
+			}
+
+			labelTupleJSON, labelsHash, err := entry.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+
+			params := append(make([]interface{}, 0), entry.RuleOrgID, entry.RuleUID, labelTupleJSON, labelsHash, entry.PreviousState, entry.CurrentState, entry.EvaluationString, entry.Error, entry.StateChangedAt.Unix())
+			if _, err := sess.Exec(append([]interface{}{`INSERT INTO alert_state_history
+				(rule_org_id, rule_uid, labels, labels_hash, previous_state, current_state, evaluation_string, error, state_changed_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`}, params...)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListAlertStateHistory is a handler for retrieving the state transitions of the instances of an alert rule.
+func (st DBstore) ListAlertStateHistory(cmd *models.ListAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		entries := make([]*models.AlertStateHistoryEntry, 0)
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_state_history WHERE rule_org_id = ? AND rule_uid = ?", cmd.RuleOrgID, cmd.RuleUID)
+
+		if cmd.State != "" {
+			addToQuery(` AND current_state = ?`, cmd.State)
+		}
+
+		if !cmd.From.IsZero() {
+			addToQuery(` AND state_changed_at >= ?`, cmd.From.Unix())
+		}
+
+		if !cmd.To.IsZero() {
+			addToQuery(` AND state_changed_at <= ?`, cmd.To.Unix())
+		}
+
+		addToQuery(` ORDER BY state_changed_at DESC, id DESC`)
+
+		if cmd.Limit > 0 {
+			addToQuery(st.SQLStore.Dialect.Limit(cmd.Limit))
+		}
+
+		if err := sess.SQL(s.String(), params...).Find(&entries); err != nil {
+			return err
+		}
+
+		cmd.Result = entries
+		return nil
+	})
+}
+
+// DeleteAlertStateHistoryBefore deletes the state transitions that happened before the given time
+// and returns the number of deleted transitions.
+func (st DBstore) DeleteAlertStateHistoryBefore(before time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE state_changed_at < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
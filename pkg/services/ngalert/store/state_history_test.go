@@ -0,0 +1,89 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertStateHistoryOperations(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60)
+	now := time.Unix(time.Now().Unix(), 0)
+
+	newEntry := func(previous, current models.InstanceStateType, at time.Time) models.AlertStateHistoryEntry {
+		return models.AlertStateHistoryEntry{
+			RuleOrgID:        alertRule.OrgID,
+			RuleUID:          alertRule.UID,
+			Labels:           models.InstanceLabels{"test": "testValue"},
+			PreviousState:    previous,
+			CurrentState:     current,
+			EvaluationString: "[ var='A' value=1 ]",
+			StateChangedAt:   at,
+		}
+	}
+
+	err := dbstore.SaveAlertStateHistory(&models.SaveAlertStateHistoryCommand{
+		Entries: []models.AlertStateHistoryEntry{
+			newEntry(models.InstanceStateNormal, models.InstanceStatePending, now.Add(-3*time.Hour)),
+			newEntry(models.InstanceStatePending, models.InstanceStateFiring, now.Add(-2*time.Hour)),
+			newEntry(models.InstanceStateFiring, models.InstanceStateNormal, now.Add(-1*time.Hour)),
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("can list the state history most recent first", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, models.InstanceStateNormal, q.Result[0].CurrentState)
+		require.Equal(t, models.InstanceStateFiring, q.Result[0].PreviousState)
+		require.Equal(t, now.Add(-1*time.Hour).Unix(), q.Result[0].StateChangedAt.Unix())
+		require.Equal(t, "testValue", q.Result[0].Labels["test"])
+		require.Equal(t, "[ var='A' value=1 ]", q.Result[0].EvaluationString)
+	})
+
+	t.Run("can filter the state history by state, time and limit", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID, State: models.InstanceStateFiring}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 1)
+
+		q = &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID, From: now.Add(-150 * time.Minute)}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 2)
+
+		q = &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID, To: now.Add(-150 * time.Minute)}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 1)
+
+		q = &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID, Limit: 2}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 2)
+	})
+
+	t.Run("fails to save an invalid transition", func(t *testing.T) {
+		err := dbstore.SaveAlertStateHistory(&models.SaveAlertStateHistoryCommand{
+			Entries: []models.AlertStateHistoryEntry{newEntry("Resolved", models.InstanceStateNormal, now)},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("can delete the state history older than a given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteAlertStateHistoryBefore(now.Add(-90 * time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 1)
+	})
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/ossaccesscontrol"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -51,6 +52,7 @@ func overrideAlertNGInRegistry(t *testing.T, cfg *setting.Cfg) ngalert.AlertNG {
 		RouteRegister: routing.NewRouteRegister(),
 		Log:           log.New("ngalert-test"),
 		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
+		AccessControl: &ossaccesscontrol.OSSAccessControlService{Cfg: cfg},
 	}
 
 	// hook for initialising the service after the Cfg is populated
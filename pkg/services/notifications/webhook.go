@@ -13,6 +13,7 @@ import (
 
 	"golang.org/x/net/context/ctxhttp"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -48,8 +49,8 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		webhook.HttpMethod = http.MethodPost
 	}
 
-	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut {
-		return fmt.Errorf("webhook only supports HTTP methods PUT or POST")
+	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut && webhook.HttpMethod != http.MethodPatch {
+		return fmt.Errorf("webhook only supports HTTP methods PUT, POST or PATCH")
 	}
 
 	request, err := http.NewRequest(webhook.HttpMethod, webhook.Url, bytes.NewReader([]byte(webhook.Body)))
@@ -97,5 +98,5 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	}
 
 	ns.log.Debug("Webhook failed", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
-	return fmt.Errorf("Webhook response status %v", resp.Status)
+	return &models.HTTPStatusError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("Webhook response status %v", resp.Status)}
 }
@@ -3,6 +3,8 @@ package oauthtoken
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -17,9 +19,24 @@ var (
 
 // GetCurrentOAuthToken returns the OAuth token, if any, for the authenticated user. Will try to refresh the token if it has expired.
 func GetCurrentOAuthToken(ctx context.Context, user *models.SignedInUser) *oauth2.Token {
+	token, err := getOAuthToken(ctx, user, 0)
+	if err != nil {
+		logger.Error("failed to retrieve OAuth access token", "userId", user.UserId, "username", user.Login, "error", err)
+		return nil
+	}
+	return token
+}
+
+// RefreshOAuthToken refreshes the OAuth token of the user with its refresh token when it expires in less than
+// refreshBefore, and returns it. The token is nil when the user didn't log in with OAuth.
+func RefreshOAuthToken(ctx context.Context, user *models.SignedInUser, refreshBefore time.Duration) (*oauth2.Token, error) {
+	return getOAuthToken(ctx, user, refreshBefore)
+}
+
+func getOAuthToken(ctx context.Context, user *models.SignedInUser, refreshBefore time.Duration) (*oauth2.Token, error) {
 	if user == nil {
 		// No user, therefore no token
-		return nil
+		return nil, nil
 	}
 
 	authInfoQuery := &models.GetAuthInfoQuery{UserId: user.UserId}
@@ -27,23 +44,25 @@ func GetCurrentOAuthToken(ctx context.Context, user *models.SignedInUser) *oauth
 		if errors.Is(err, models.ErrUserNotFound) {
 			// Not necessarily an error.  User may be logged in another way.
 			logger.Debug("no OAuth token for user found", "userId", user.UserId, "username", user.Login)
-		} else {
-			logger.Error("failed to get OAuth token for user", "userId", user.UserId, "username", user.Login, "error", err)
+			return nil, nil
 		}
-		return nil
+		return nil, fmt.Errorf("failed to get OAuth token: %w", err)
 	}
 
 	authProvider := authInfoQuery.Result.AuthModule
+	if authInfoQuery.Result.OAuthAccessToken == "" {
+		// The users logged in with LDAP or provisioned with SCIM have no token.
+		logger.Debug("user didn't log in with OAuth", "userId", user.UserId, "username", user.Login, "authModule", authProvider)
+		return nil, nil
+	}
 	connect, err := social.GetConnector(authProvider)
 	if err != nil {
-		logger.Error("failed to get OAuth connector", "provider", authProvider, "error", err)
-		return nil
+		return nil, fmt.Errorf("failed to get OAuth connector: %w", err)
 	}
 
 	client, err := social.GetOAuthHttpClient(authProvider)
 	if err != nil {
-		logger.Error("failed to get OAuth http client", "provider", authProvider, "error", err)
-		return nil
+		return nil, fmt.Errorf("failed to get OAuth http client: %w", err)
 	}
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
 
@@ -53,11 +72,18 @@ func GetCurrentOAuthToken(ctx context.Context, user *models.SignedInUser) *oauth
 		RefreshToken: authInfoQuery.Result.OAuthRefreshToken,
 		TokenType:    authInfoQuery.Result.OAuthTokenType,
 	}
+	if authInfoQuery.Result.OAuthIdToken != "" {
+		persistedToken = persistedToken.WithExtra(map[string]interface{}{"id_token": authInfoQuery.Result.OAuthIdToken})
+	}
+	sourceToken := persistedToken
+	if expiresBefore(persistedToken, refreshBefore) {
+		// The token source refreshes the tokens which are expired.
+		sourceToken = &oauth2.Token{RefreshToken: persistedToken.RefreshToken, Expiry: time.Now().Add(-time.Second)}
+	}
 	// TokenSource handles refreshing the token if it has expired
-	token, err := connect.TokenSource(ctx, persistedToken).Token()
+	token, err := connect.TokenSource(ctx, sourceToken).Token()
 	if err != nil {
-		logger.Error("failed to retrieve OAuth access token", "provider", authInfoQuery.Result.AuthModule, "userId", user.UserId, "username", user.Login, "error", err)
-		return nil
+		return nil, fmt.Errorf("failed to refresh OAuth token of %s: %w", authProvider, err)
 	}
 
 	// If the tokens are not the same, update the entry in the DB
@@ -69,12 +95,17 @@ func GetCurrentOAuthToken(ctx context.Context, user *models.SignedInUser) *oauth
 			OAuthToken: token,
 		}
 		if err := bus.Dispatch(updateAuthCommand); err != nil {
-			logger.Error("failed to update auth info during token refresh", "userId", user.UserId, "username", user.Login, "error", err)
-			return nil
+			return nil, fmt.Errorf("failed to update auth info during token refresh: %w", err)
 		}
 		logger.Debug("updated OAuth info for user", "userId", user.UserId, "username", user.Login)
 	}
-	return token
+	return token, nil
+}
+
+// expiresBefore returns whether the token can be refreshed, and expires in less than refreshBefore.
+func expiresBefore(token *oauth2.Token, refreshBefore time.Duration) bool {
+	return refreshBefore > 0 && token.RefreshToken != "" && !token.Expiry.IsZero() &&
+		time.Until(token.Expiry) < refreshBefore
 }
 
 // IsOAuthPassThruEnabled returns true if Forward OAuth Identity (oauthPassThru) is enabled for the provided data source.
@@ -75,6 +75,8 @@ type provisioningServiceImpl struct {
 	provisionDatasources    func(string) error
 	provisionPlugins        func(string, plugifaces.Manager) error
 	mutex                   sync.Mutex
+	// stopped is set when Grafana shuts down, so that polling isn't restarted.
+	stopped bool
 }
 
 func (ps *provisioningServiceImpl) Init() error {
@@ -110,6 +112,11 @@ func (ps *provisioningServiceImpl) Run(ctx context.Context) error {
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
+		if ps.stopped {
+			ps.mutex.Unlock()
+			<-ctx.Done()
+			return ctx.Err()
+		}
 		// Using background here because otherwise if root context was canceled the select later on would
 		// non-deterministically take one of the route possibly going into one polling loop before exiting.
 		pollingContext, cancelFun := context.WithCancel(context.Background())
@@ -129,6 +136,16 @@ func (ps *provisioningServiceImpl) Run(ctx context.Context) error {
 	}
 }
 
+// Shutdown stops polling for dashboard changes, so that no dashboards are provisioned while Grafana shuts down.
+func (ps *provisioningServiceImpl) Shutdown(_ context.Context) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.stopped = true
+	ps.cancelPolling()
+	return nil
+}
+
 func (ps *provisioningServiceImpl) ProvisionDatasources() error {
 	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
 	err := ps.provisionDatasources(datasourcePath)
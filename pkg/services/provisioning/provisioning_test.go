@@ -62,6 +62,27 @@ func TestProvisioningServiceImpl(t *testing.T) {
 		// Cancelling the root context and stopping the service
 		serviceTest.cancel()
 	})
+
+	t.Run("Shutdown stops polling until the service is stopped", func(t *testing.T) {
+		serviceTest := setup()
+		err := serviceTest.service.ProvisionDashboards()
+		assert.Nil(t, err)
+		serviceTest.startService()
+		serviceTest.waitForPollChanges()
+
+		err = serviceTest.service.Shutdown(context.Background())
+		assert.Nil(t, err)
+		serviceTest.waitForPollChanges()
+
+		assert.Equal(t, 1, len(serviceTest.mock.Calls.PollChanges), "PollChanges should not have been called again")
+		pollingCtx := serviceTest.mock.Calls.PollChanges[0].(context.Context)
+		assert.Equal(t, context.Canceled, pollingCtx.Err(), "Polling context should have been cancelled")
+
+		// Cancelling the root context and stopping the service
+		serviceTest.cancel()
+		serviceTest.waitForStop()
+		assert.Equal(t, context.Canceled, serviceTest.serviceError, "Service should have returned canceled error")
+	})
 }
 
 type serviceTestStruct struct {
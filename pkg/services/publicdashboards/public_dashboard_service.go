@@ -0,0 +1,179 @@
+package publicdashboards
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var getTime = time.Now
+
+// limiterTTL is how long the rate limiter of a public dashboard is kept after its last request.
+const limiterTTL = 10 * time.Minute
+
+func init() {
+	registry.RegisterService(&PublicDashboardService{})
+}
+
+type Service interface {
+	GetPublicDashboardConfig(ctx context.Context, orgID int64, dashboardUID string) (*models.PublicDashboard, error)
+	SavePublicDashboardConfig(ctx context.Context, cmd *models.SavePublicDashboardCommand) (*models.PublicDashboard, error)
+	GetPublicDashboardByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, error)
+	AllowRequest(accessToken string) bool
+	GetQueryResult(key string) (*backend.QueryDataResponse, bool)
+	SetQueryResult(key string, qdr *backend.QueryDataResponse)
+}
+
+type PublicDashboardService struct {
+	SQLStore *sqlstore.SQLStore `inject:""`
+	Cfg      *setting.Cfg       `inject:""`
+
+	limitersMu   sync.Mutex
+	limiters     *localcache.CacheService
+	queryResults *localcache.CacheService
+}
+
+func (s *PublicDashboardService) Init() error {
+	s.limiters = localcache.New(limiterTTL, limiterTTL)
+	if ttl := s.Cfg.PublicDashboards.QueryCacheTTL; ttl > 0 {
+		s.queryResults = localcache.New(ttl, 2*ttl)
+	}
+	return nil
+}
+
+// GetPublicDashboardConfig returns the public dashboard of a dashboard, enabled or not.
+func (s *PublicDashboardService) GetPublicDashboardConfig(ctx context.Context, orgID int64, dashboardUID string) (*models.PublicDashboard, error) {
+	var publicDashboard models.PublicDashboard
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		exists, err := dbSession.Where("org_id=? AND dashboard_uid=?", orgID, dashboardUID).Get(&publicDashboard)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrPublicDashboardNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &publicDashboard, nil
+}
+
+// SavePublicDashboardConfig creates or updates the public dashboard of a dashboard. The user saving it becomes the
+// user whose data source permissions the queries of the public dashboard run with.
+func (s *PublicDashboardService) SavePublicDashboardConfig(ctx context.Context, cmd *models.SavePublicDashboardCommand) (*models.PublicDashboard, error) {
+	for _, tr := range cmd.TimeRanges {
+		if tr.From == "" || tr.To == "" {
+			return nil, models.ErrPublicDashboardInvalidTimeRange
+		}
+	}
+
+	var publicDashboard models.PublicDashboard
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		exists, err := dbSession.Where("org_id=? AND dashboard_uid=?", cmd.OrgId, cmd.DashboardUid).Get(&publicDashboard)
+		if err != nil {
+			return err
+		}
+
+		now := getTime()
+		publicDashboard.IsEnabled = cmd.IsEnabled
+		publicDashboard.TimeRanges = cmd.TimeRanges
+		publicDashboard.CreatedBy = cmd.UserId
+		publicDashboard.Updated = now
+		if exists {
+			_, err := dbSession.ID(publicDashboard.Id).AllCols().Update(&publicDashboard)
+			return err
+		}
+
+		accessToken, err := util.GetRandomString(32)
+		if err != nil {
+			return err
+		}
+		publicDashboard.OrgId = cmd.OrgId
+		publicDashboard.DashboardUid = cmd.DashboardUid
+		publicDashboard.AccessToken = accessToken
+		publicDashboard.Created = now
+		_, err = dbSession.Insert(&publicDashboard)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &publicDashboard, nil
+}
+
+// GetPublicDashboardByAccessToken returns the enabled public dashboard with the access token.
+func (s *PublicDashboardService) GetPublicDashboardByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, error) {
+	var publicDashboard models.PublicDashboard
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		exists, err := dbSession.Where("access_token=? AND is_enabled=?", accessToken, true).Get(&publicDashboard)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrPublicDashboardNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &publicDashboard, nil
+}
+
+// AllowRequest reports whether a request to the public dashboard with the access token is within the rate limit of
+// the public dashboard. A rate limiter is kept for every access token, so the access token must be the one of a
+// public dashboard.
+func (s *PublicDashboardService) AllowRequest(accessToken string) bool {
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+
+	var limiter *rate.Limiter
+	if cached, ok := s.limiters.Get(accessToken); ok {
+		limiter = cached.(*rate.Limiter)
+	} else {
+		limiter = rate.NewLimiter(rate.Limit(s.Cfg.PublicDashboards.RequestsPerSecond), s.Cfg.PublicDashboards.Burst)
+	}
+	s.limiters.SetDefault(accessToken, limiter)
+	return limiter.Allow()
+}
+
+// GetQueryResult returns the cached result of the queries of a panel of a public dashboard.
+func (s *PublicDashboardService) GetQueryResult(key string) (*backend.QueryDataResponse, bool) {
+	if s.queryResults == nil {
+		return nil, false
+	}
+	if cached, ok := s.queryResults.Get(key); ok {
+		return cached.(*backend.QueryDataResponse), true
+	}
+	return nil, false
+}
+
+// SetQueryResult caches the result of the queries of a panel of a public dashboard, unless one of them failed.
+func (s *PublicDashboardService) SetQueryResult(key string, qdr *backend.QueryDataResponse) {
+	if s.queryResults == nil {
+		return
+	}
+	for _, res := range qdr.Responses {
+		if res.Error != nil {
+			return
+		}
+	}
+	s.queryResults.SetDefault(key, qdr)
+}
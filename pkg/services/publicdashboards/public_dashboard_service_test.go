@@ -0,0 +1,89 @@
+package publicdashboards
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicDashboardService(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.PublicDashboards = setting.PublicDashboards{Enabled: true, QueryCacheTTL: time.Minute, RequestsPerSecond: 1, Burst: 2}
+	service := &PublicDashboardService{SQLStore: sqlStore, Cfg: cfg}
+	require.NoError(t, service.Init())
+
+	t.Run("User can publish a dashboard", func(t *testing.T) {
+		cmd := &models.SavePublicDashboardCommand{
+			IsEnabled:    true,
+			TimeRanges:   []models.PublicDashboardTimeRange{{From: "now-24h", To: "now"}},
+			DashboardUid: "dash",
+			OrgId:        1,
+			UserId:       2,
+		}
+		created, err := service.SavePublicDashboardConfig(context.Background(), cmd)
+		require.NoError(t, err)
+		require.Len(t, created.AccessToken, 32)
+
+		publicDashboard, err := service.GetPublicDashboardByAccessToken(context.Background(), created.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, "dash", publicDashboard.DashboardUid)
+		require.Equal(t, int64(2), publicDashboard.CreatedBy)
+		require.Equal(t, cmd.TimeRanges, publicDashboard.TimeRanges)
+
+		t.Run("and disable it keeping its access token", func(t *testing.T) {
+			cmd := &models.SavePublicDashboardCommand{DashboardUid: "dash", OrgId: 1, UserId: 3}
+			updated, err := service.SavePublicDashboardConfig(context.Background(), cmd)
+			require.NoError(t, err)
+			require.Equal(t, created.AccessToken, updated.AccessToken)
+
+			_, err = service.GetPublicDashboardByAccessToken(context.Background(), created.AccessToken)
+			require.True(t, errors.Is(err, models.ErrPublicDashboardNotFound))
+
+			config, err := service.GetPublicDashboardConfig(context.Background(), 1, "dash")
+			require.NoError(t, err)
+			require.False(t, config.IsEnabled)
+			require.Equal(t, int64(3), config.CreatedBy)
+		})
+	})
+
+	t.Run("Time ranges must have a from and a to", func(t *testing.T) {
+		cmd := &models.SavePublicDashboardCommand{
+			IsEnabled:    true,
+			TimeRanges:   []models.PublicDashboardTimeRange{{From: "now-24h"}},
+			DashboardUid: "other",
+			OrgId:        1,
+		}
+		_, err := service.SavePublicDashboardConfig(context.Background(), cmd)
+		require.True(t, errors.Is(err, models.ErrPublicDashboardInvalidTimeRange))
+	})
+
+	t.Run("Requests over the rate limit of a public dashboard are not allowed", func(t *testing.T) {
+		require.True(t, service.AllowRequest("limited"))
+		require.True(t, service.AllowRequest("limited"))
+		require.False(t, service.AllowRequest("limited"))
+		require.True(t, service.AllowRequest("other"))
+	})
+
+	t.Run("Only the successful query results are cached", func(t *testing.T) {
+		qdr := backend.NewQueryDataResponse()
+		qdr.Responses["A"] = backend.DataResponse{}
+		service.SetQueryResult("ok", qdr)
+		cached, ok := service.GetQueryResult("ok")
+		require.True(t, ok)
+		require.Equal(t, qdr, cached)
+
+		failed := backend.NewQueryDataResponse()
+		failed.Responses["A"] = backend.DataResponse{Error: errors.New("boom")}
+		service.SetQueryResult("failed", failed)
+		_, ok = service.GetQueryResult("failed")
+		require.False(t, ok)
+	})
+}
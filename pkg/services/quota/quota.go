@@ -118,6 +118,60 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 	return false, nil
 }
 
+// OrgQuotaRemaining returns how many more of the target the org can have before it reaches its quota or the
+// global quota, or -1 if it's unlimited. It's used by the background services, which have no request context.
+func (qs *QuotaService) OrgQuotaRemaining(orgID int64, target string) (int64, error) {
+	if !qs.Cfg.Quota.Enabled {
+		return -1, nil
+	}
+
+	scopes, err := qs.getQuotaScopes(target)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := int64(-1)
+	for _, scope := range scopes {
+		var limit, used int64
+		switch scope.Name {
+		case "global":
+			if scope.DefaultLimit < 0 {
+				continue
+			}
+			query := models.GetGlobalQuotaByTargetQuery{Target: scope.Target, Default: scope.DefaultLimit, IsNgAlertEnabled: qs.Cfg.IsNgAlertEnabled()}
+			if err := bus.Dispatch(&query); err != nil {
+				return 0, err
+			}
+			limit, used = scope.DefaultLimit, query.Result.Used
+		case "org":
+			query := models.GetOrgQuotaByTargetQuery{
+				OrgId:            orgID,
+				Target:           scope.Target,
+				Default:          scope.DefaultLimit,
+				IsNgAlertEnabled: qs.Cfg.IsNgAlertEnabled(),
+			}
+			if err := bus.Dispatch(&query); err != nil {
+				return 0, err
+			}
+			if query.Result.Limit < 0 {
+				continue
+			}
+			limit, used = query.Result.Limit, query.Result.Used
+		default:
+			continue
+		}
+
+		left := limit - used
+		if left < 0 {
+			left = 0
+		}
+		if remaining < 0 || left < remaining {
+			remaining = left
+		}
+	}
+	return remaining, nil
+}
+
 func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, error) {
 	scopes := make([]models.QuotaScope, 0)
 	switch target {
@@ -170,6 +224,12 @@ func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, erro
 			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.AlertRule},
 		)
 		return scopes, nil
+	case "alert_instance": // target need to match the respective database name
+		scopes = append(scopes,
+			models.QuotaScope{Name: "global", Target: target, DefaultLimit: qs.Cfg.Quota.Global.AlertInstance},
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.AlertInstance},
+		)
+		return scopes, nil
 	default:
 		return scopes, ErrInvalidQuotaTarget
 	}
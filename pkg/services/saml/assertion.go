@@ -0,0 +1,109 @@
+package saml
+
+import (
+	"github.com/crewjam/saml"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// externalUser returns the user of the assertion, with the roles in its orgs when assertion_attribute_role or
+// assertion_attribute_org is set. The user is identified by the NameID of the assertion, which is the auth id of
+// its logout requests.
+func (s *SAMLService) externalUser(assertion *saml.Assertion) (*models.ExternalUserInfo, error) {
+	cfg := s.Cfg.SAML
+	extUser := &models.ExternalUserInfo{
+		AuthModule: models.AuthModuleSAML,
+		Name:       attributeValue(assertion, cfg.AssertionAttributeName),
+		Login:      attributeValue(assertion, cfg.AssertionAttributeLogin),
+		Email:      attributeValue(assertion, cfg.AssertionAttributeEmail),
+		Groups:     attributeValues(assertion, cfg.AssertionAttributeGroups),
+		OrgRoles:   map[int64]models.RoleType{},
+	}
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		extUser.AuthId = assertion.Subject.NameID.Value
+	}
+	if extUser.Email == "" {
+		return nil, ErrNoEmail
+	}
+	if extUser.Login == "" {
+		extUser.Login = extUser.Email
+	}
+
+	role := models.ROLE_VIEWER
+	if cfg.AssertionAttributeRole != "" {
+		roles := attributeValues(assertion, cfg.AssertionAttributeRole)
+		switch {
+		case containsAny(roles, cfg.RoleValuesAdmin):
+			role = models.ROLE_ADMIN
+		case containsAny(roles, cfg.RoleValuesEditor):
+			role = models.ROLE_EDITOR
+		}
+		if len(cfg.RoleValuesGrafanaAdmin) > 0 {
+			isGrafanaAdmin := containsAny(roles, cfg.RoleValuesGrafanaAdmin)
+			extUser.IsGrafanaAdmin = &isGrafanaAdmin
+		}
+	}
+
+	if cfg.AssertionAttributeOrg != "" {
+		orgs := attributeValues(assertion, cfg.AssertionAttributeOrg)
+		if len(cfg.AllowedOrganizations) > 0 && !containsAny(orgs, cfg.AllowedOrganizations) {
+			return nil, ErrOrgNotAllowed
+		}
+		for _, org := range orgs {
+			for _, orgID := range s.orgMapping[org] {
+				extUser.OrgRoles[orgID] = role
+			}
+		}
+	}
+	// The users without a mapped org get their role in the org they're assigned to, like the OAuth users.
+	if cfg.AssertionAttributeRole != "" && len(extUser.OrgRoles) == 0 {
+		orgID := int64(1)
+		if setting.AutoAssignOrg && setting.AutoAssignOrgId > 0 {
+			orgID = int64(setting.AutoAssignOrgId)
+		}
+		extUser.OrgRoles[orgID] = role
+	}
+
+	return extUser, nil
+}
+
+// attributeValues returns the values of the attribute of the assertion with the name or the friendly name.
+func attributeValues(assertion *saml.Assertion, name string) []string {
+	if name == "" {
+		return nil
+	}
+	var values []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			if attr.Name != name && attr.FriendlyName != name {
+				continue
+			}
+			for _, value := range attr.Values {
+				if value.Value != "" {
+					values = append(values, value.Value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// attributeValue returns the first value of the attribute of the assertion with the name or the friendly name.
+func attributeValue(assertion *saml.Assertion, name string) string {
+	if values := attributeValues(assertion, name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package saml
+
+import (
+	"testing"
+
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestAssertion(attributes map[string][]string) *saml.Assertion {
+	statement := saml.AttributeStatement{}
+	for name, values := range attributes {
+		attr := saml.Attribute{Name: name}
+		for _, value := range values {
+			attr.Values = append(attr.Values, saml.AttributeValue{Value: value})
+		}
+		statement.Attributes = append(statement.Attributes, attr)
+	}
+	return &saml.Assertion{
+		Subject:             &saml.Subject{NameID: &saml.NameID{Value: "jdoe"}},
+		AttributeStatements: []saml.AttributeStatement{statement},
+	}
+}
+
+func TestSAMLService_externalUser(t *testing.T) {
+	newService := func(t *testing.T, cfg setting.SAMLSettings) *SAMLService {
+		cfg.AssertionAttributeName = "displayName"
+		cfg.AssertionAttributeLogin = "login"
+		cfg.AssertionAttributeEmail = "mail"
+		orgMapping, err := parseOrgMapping(cfg.OrgMapping)
+		require.NoError(t, err)
+		return &SAMLService{Cfg: &setting.Cfg{SAML: cfg}, orgMapping: orgMapping}
+	}
+
+	t.Run("Maps the user without roles", func(t *testing.T) {
+		s := newService(t, setting.SAMLSettings{})
+
+		extUser, err := s.externalUser(newTestAssertion(map[string][]string{
+			"displayName": {"John Doe"},
+			"mail":        {"jdoe@example.com"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, &models.ExternalUserInfo{
+			AuthModule: models.AuthModuleSAML,
+			AuthId:     "jdoe",
+			Name:       "John Doe",
+			Login:      "jdoe@example.com",
+			Email:      "jdoe@example.com",
+			OrgRoles:   map[int64]models.RoleType{},
+		}, extUser)
+	})
+
+	t.Run("Requires an email", func(t *testing.T) {
+		s := newService(t, setting.SAMLSettings{})
+
+		_, err := s.externalUser(newTestAssertion(map[string][]string{"login": {"jdoe"}}))
+		require.Equal(t, ErrNoEmail, err)
+	})
+
+	t.Run("Maps the roles in the mapped orgs", func(t *testing.T) {
+		s := newService(t, setting.SAMLSettings{
+			AssertionAttributeRole: "role",
+			AssertionAttributeOrg:  "org",
+			RoleValuesEditor:       []string{"developer"},
+			RoleValuesAdmin:        []string{"operator"},
+			RoleValuesGrafanaAdmin: []string{"superadmin"},
+			OrgMapping:             []string{"Engineering:2", "Engineering:3", "Sales:4"},
+		})
+
+		extUser, err := s.externalUser(newTestAssertion(map[string][]string{
+			"mail": {"jdoe@example.com"},
+			"role": {"developer", "superadmin"},
+			"org":  {"Engineering"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]models.RoleType{2: models.ROLE_EDITOR, 3: models.ROLE_EDITOR}, extUser.OrgRoles)
+		require.NotNil(t, extUser.IsGrafanaAdmin)
+		assert.True(t, *extUser.IsGrafanaAdmin)
+	})
+
+	t.Run("Maps the role in the main org without mapped orgs", func(t *testing.T) {
+		s := newService(t, setting.SAMLSettings{
+			AssertionAttributeRole: "role",
+			RoleValuesAdmin:        []string{"operator"},
+		})
+
+		extUser, err := s.externalUser(newTestAssertion(map[string][]string{
+			"mail": {"jdoe@example.com"},
+			"role": {"operator"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]models.RoleType{1: models.ROLE_ADMIN}, extUser.OrgRoles)
+		assert.Nil(t, extUser.IsGrafanaAdmin)
+	})
+
+	t.Run("Rejects the users outside of the allowed organizations", func(t *testing.T) {
+		s := newService(t, setting.SAMLSettings{
+			AssertionAttributeOrg: "org",
+			AllowedOrganizations:  []string{"Engineering"},
+		})
+
+		_, err := s.externalUser(newTestAssertion(map[string][]string{
+			"mail": {"jdoe@example.com"},
+			"org":  {"Sales"},
+		}))
+		require.Equal(t, ErrOrgNotAllowed, err)
+	})
+}
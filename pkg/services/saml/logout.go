@@ -0,0 +1,259 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// maxMessageSize is the maximum size of the inflated messages sent with the HTTP-Redirect binding.
+const maxMessageSize = 1 << 20
+
+// LogoutRequest is a logout of the user NameID requested by the IdP.
+type LogoutRequest struct {
+	ID         string
+	NameID     string
+	RelayState string
+}
+
+// NewLogoutRequest returns the logout request which ends the session of the user nameID in the IdP. The logout
+// messages are always signed, with rsa-sha256 when signature_algorithm isn't set.
+func (s *SAMLService) NewLogoutRequest(nameID string) (*Message, error) {
+	binding, location := idpLocation(s.sp.GetSLOBindingLocation)
+	if location == "" {
+		return nil, ErrNoSLOLocation
+	}
+	signatureMethod := s.logoutSignatureMethod()
+	if binding == saml.HTTPRedirectBinding {
+		req, err := s.signingSP("").MakeLogoutRequest(location, nameID)
+		if err != nil {
+			return nil, err
+		}
+		return s.redirect(location, "SAMLRequest", req.Element(), "", signatureMethod)
+	}
+
+	req, err := s.signingSP(signatureMethod).MakeLogoutRequest(location, nameID)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{PostForm: req.Post("")}, nil
+}
+
+// NewLogoutResponse returns the response to the logout request of the IdP requestID.
+func (s *SAMLService) NewLogoutResponse(requestID, relayState string) (*Message, error) {
+	binding, location := idpLocation(s.sp.GetSLOBindingLocation)
+	if location == "" {
+		return nil, ErrNoSLOLocation
+	}
+	signatureMethod := s.logoutSignatureMethod()
+	if binding == saml.HTTPRedirectBinding {
+		resp, err := s.signingSP("").MakeLogoutResponse(location, requestID)
+		if err != nil {
+			return nil, err
+		}
+		return s.redirect(location, "SAMLResponse", resp.Element(), relayState, signatureMethod)
+	}
+
+	resp, err := s.signingSP(signatureMethod).MakeLogoutResponse(location, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{PostForm: resp.Post(relayState)}, nil
+}
+
+// ParseLogoutRequest returns the logout request sent by the IdP, which must be signed since it ends all the
+// sessions of the user.
+func (s *SAMLService) ParseLogoutRequest(r *http.Request) (*LogoutRequest, error) {
+	data, relayState, signed, err := s.parseMessage(r, "SAMLRequest")
+	if err != nil {
+		return nil, err
+	}
+	if !signed {
+		return nil, errUnsigned
+	}
+
+	var req saml.LogoutRequest
+	if err := xml.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid logout request: %w", err)
+	}
+	if err := s.validateMessage(req.Issuer, req.Destination, req.IssueInstant); err != nil {
+		return nil, fmt.Errorf("invalid logout request: %w", err)
+	}
+	if req.NameID == nil || req.NameID.Value == "" {
+		return nil, errors.New("invalid logout request: NameID is missing")
+	}
+
+	return &LogoutRequest{ID: req.ID, NameID: req.NameID.Value, RelayState: relayState}, nil
+}
+
+// ParseLogoutResponse validates the response of the IdP to a logout request. The unsigned responses are accepted,
+// since the session of the user already ended when the request was sent.
+func (s *SAMLService) ParseLogoutResponse(r *http.Request) error {
+	data, _, _, err := s.parseMessage(r, "SAMLResponse")
+	if err != nil {
+		return err
+	}
+
+	var resp saml.LogoutResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("invalid logout response: %w", err)
+	}
+	if err := s.validateMessage(resp.Issuer, resp.Destination, resp.IssueInstant); err != nil {
+		return fmt.Errorf("invalid logout response: %w", err)
+	}
+	if resp.Status.StatusCode.Value != saml.StatusSuccess {
+		return fmt.Errorf("logout failed in the IdP with status %q", resp.Status.StatusCode.Value)
+	}
+	return nil
+}
+
+// IsLogoutRequest returns whether the request to the single logout service is a logout request of the IdP, rather
+// than its response to a logout request.
+func IsLogoutRequest(r *http.Request) bool {
+	if r.URL.Query().Get("SAMLRequest") != "" {
+		return true
+	}
+	return r.Method == http.MethodPost && r.PostFormValue("SAMLRequest") != ""
+}
+
+func (s *SAMLService) logoutSignatureMethod() string {
+	if s.sp.SignatureMethod != "" {
+		return s.sp.SignatureMethod
+	}
+	return dsig.RSASHA256SignatureMethod
+}
+
+// parseMessage returns the XML of the param message sent by the IdP with the HTTP-Redirect or HTTP-POST binding and
+// its relay state, and whether it's signed. The signature of the signed messages is verified.
+func (s *SAMLService) parseMessage(r *http.Request, param string) ([]byte, string, bool, error) {
+	query := r.URL.Query()
+	if encoded := query.Get(param); encoded != "" {
+		compressed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxMessageSize))
+		if err != nil {
+			return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+			return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+		}
+
+		signed := query.Get("Signature") != ""
+		if signed {
+			if err := s.verifyQuerySignature(r.URL.RawQuery, param); err != nil {
+				return nil, "", false, err
+			}
+		}
+		return data, query.Get("RelayState"), signed, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, "", false, err
+	}
+	encoded := r.PostForm.Get(param)
+	if encoded == "" {
+		return nil, "", false, fmt.Errorf("%s is missing", param)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+	}
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+	}
+	signed := doc.Root().SelectElement("Signature") != nil
+	if signed {
+		ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: s.idpCerts})
+		ctx.IdAttribute = "ID"
+		validated, err := ctx.Validate(doc.Root())
+		if err != nil {
+			return nil, "", false, fmt.Errorf("invalid signature of %s: %w", param, err)
+		}
+		// The message is read from the validated element, so that only what is signed is used.
+		validatedDoc := etree.NewDocument()
+		validatedDoc.SetRoot(validated)
+		if data, err = validatedDoc.WriteToBytes(); err != nil {
+			return nil, "", false, fmt.Errorf("invalid %s: %w", param, err)
+		}
+	}
+	return data, r.PostForm.Get("RelayState"), signed, nil
+}
+
+// verifyQuerySignature verifies the signature of the query of the param message sent with the HTTP-Redirect
+// binding. The signed query is built from the parameters as they were encoded by the IdP.
+func (s *SAMLService) verifyQuerySignature(rawQuery, param string) error {
+	raw := map[string]string{}
+	for _, part := range strings.Split(rawQuery, "&") {
+		if kv := strings.SplitN(part, "=", 2); len(kv) == 2 {
+			raw[kv[0]] = kv[1]
+		}
+	}
+	signedQuery := param + "=" + raw[param]
+	if relayState, ok := raw["RelayState"]; ok {
+		signedQuery += "&RelayState=" + relayState
+	}
+	signedQuery += "&SigAlg=" + raw["SigAlg"]
+
+	sigAlg, err := url.QueryUnescape(raw["SigAlg"])
+	if err != nil {
+		return fmt.Errorf("invalid SigAlg: %w", err)
+	}
+	hash, ok := signatureHashes[sigAlg]
+	if !ok {
+		return fmt.Errorf("unsupported SigAlg %q", sigAlg)
+	}
+	encodedSignature, err := url.QueryUnescape(raw["Signature"])
+	if err != nil {
+		return fmt.Errorf("invalid Signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return fmt.Errorf("invalid Signature: %w", err)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signedQuery))
+	digest := h.Sum(nil)
+	for _, cert := range s.idpCerts {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid signature of %s", param)
+}
+
+// validateMessage validates the issuer, destination and issue instant of a message sent by the IdP.
+func (s *SAMLService) validateMessage(issuer *saml.Issuer, destination string, issueInstant time.Time) error {
+	if issuer == nil || issuer.Value != s.sp.IDPMetadata.EntityID {
+		return fmt.Errorf("issuer isn't %q", s.sp.IDPMetadata.EntityID)
+	}
+	if destination != "" && destination != s.sp.SloURL.String() {
+		return fmt.Errorf("destination isn't %q", s.sp.SloURL.String())
+	}
+	if issueInstant.Add(saml.MaxIssueDelay).Before(time.Now()) {
+		return fmt.Errorf("expired at %s", issueInstant.Add(saml.MaxIssueDelay))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package saml
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// request returns the request sending the message of the IdP to the single logout service with the HTTP-Redirect
+// binding, signed with signatureMethod when it isn't empty.
+func (idp *testIdP) request(t *testing.T, param string, el *etree.Element, relayState, signatureMethod string) *http.Request {
+	t.Helper()
+	sender := &SAMLService{sp: &saml.ServiceProvider{Key: idp.key}}
+	msg, err := sender.redirect("https://grafana.example.com/saml/slo", param, el, relayState, signatureMethod)
+	require.NoError(t, err)
+	return httptest.NewRequest(http.MethodGet, msg.RedirectURL, nil)
+}
+
+// postRequest returns the request sending the message of the IdP to the single logout service with the HTTP-POST
+// binding, with an enveloped signature. The signed message is changed by tamper when it isn't nil.
+func (idp *testIdP) postRequest(t *testing.T, param string, el *etree.Element, tamper func(*etree.Element)) *http.Request {
+	t.Helper()
+	ctx := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+		Certificate: [][]byte{idp.cert.Raw},
+		PrivateKey:  idp.key,
+	}))
+	ctx.IdAttribute = "ID"
+	signed, err := ctx.SignEnveloped(el)
+	require.NoError(t, err)
+	if tamper != nil {
+		tamper(signed)
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(signed)
+	data, err := doc.WriteToBytes()
+	require.NoError(t, err)
+	form := url.Values{param: {base64.StdEncoding.EncodeToString(data)}}
+	r := httptest.NewRequest(http.MethodPost, "https://grafana.example.com/saml/slo", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func newIdPLogoutRequest(issuer string) *saml.LogoutRequest {
+	return &saml.LogoutRequest{
+		ID:           "id-1",
+		Version:      "2.0",
+		IssueInstant: time.Now(),
+		Destination:  "https://grafana.example.com/saml/slo",
+		Issuer:       &saml.Issuer{Value: issuer},
+		NameID:       &saml.NameID{Value: "jdoe"},
+	}
+}
+
+func TestSAMLService_NewLogoutRequest(t *testing.T) {
+	s, _ := newTestService(t, nil)
+
+	msg, err := s.NewLogoutRequest("jdoe")
+	require.NoError(t, err)
+
+	u, err := url.Parse(msg.RedirectURL)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", u.Host)
+	var req saml.LogoutRequest
+	decodeRedirectMessage(t, u.Query().Get("SAMLRequest"), &req)
+	assert.Equal(t, "jdoe", req.NameID.Value)
+	assert.Equal(t, "https://idp.example.com/slo", req.Destination)
+
+	// The logout requests are signed without a signature algorithm.
+	assert.Equal(t, dsig.RSASHA256SignatureMethod, u.Query().Get("SigAlg"))
+	verifySPSignature(t, s, u)
+}
+
+func TestSAMLService_NewLogoutResponse(t *testing.T) {
+	s, _ := newTestService(t, nil)
+
+	msg, err := s.NewLogoutResponse("id-1", "state")
+	require.NoError(t, err)
+
+	u, err := url.Parse(msg.RedirectURL)
+	require.NoError(t, err)
+	var resp saml.LogoutResponse
+	decodeRedirectMessage(t, u.Query().Get("SAMLResponse"), &resp)
+	assert.Equal(t, "id-1", resp.InResponseTo)
+	assert.Equal(t, saml.StatusSuccess, resp.Status.StatusCode.Value)
+	assert.Equal(t, "state", u.Query().Get("RelayState"))
+	verifySPSignature(t, s, u)
+}
+
+func TestSAMLService_ParseLogoutRequest(t *testing.T) {
+	s, idp := newTestService(t, nil)
+
+	t.Run("Returns the signed requests", func(t *testing.T) {
+		r := idp.request(t, "SAMLRequest", newIdPLogoutRequest(idpEntityID).Element(), "state", dsig.RSASHA256SignatureMethod)
+		require.True(t, IsLogoutRequest(r))
+
+		req, err := s.ParseLogoutRequest(r)
+		require.NoError(t, err)
+		assert.Equal(t, &LogoutRequest{ID: "id-1", NameID: "jdoe", RelayState: "state"}, req)
+	})
+
+	t.Run("Rejects the unsigned requests", func(t *testing.T) {
+		r := idp.request(t, "SAMLRequest", newIdPLogoutRequest(idpEntityID).Element(), "", "")
+
+		_, err := s.ParseLogoutRequest(r)
+		require.Equal(t, errUnsigned, err)
+	})
+
+	t.Run("Rejects the requests signed by another key", func(t *testing.T) {
+		other := &testIdP{}
+		other.key, _ = newTestKeyPair(t, "other.example.com")
+		r := other.request(t, "SAMLRequest", newIdPLogoutRequest(idpEntityID).Element(), "", dsig.RSASHA256SignatureMethod)
+
+		_, err := s.ParseLogoutRequest(r)
+		require.EqualError(t, err, "invalid signature of SAMLRequest")
+	})
+
+	t.Run("Returns the signed requests of the HTTP-POST binding", func(t *testing.T) {
+		r := idp.postRequest(t, "SAMLRequest", newIdPLogoutRequest(idpEntityID).Element(), nil)
+
+		req, err := s.ParseLogoutRequest(r)
+		require.NoError(t, err)
+		assert.Equal(t, &LogoutRequest{ID: "id-1", NameID: "jdoe"}, req)
+	})
+
+	t.Run("Rejects the requests of the HTTP-POST binding changed after being signed", func(t *testing.T) {
+		r := idp.postRequest(t, "SAMLRequest", newIdPLogoutRequest(idpEntityID).Element(), func(el *etree.Element) {
+			el.FindElement("./NameID").SetText("admin")
+		})
+
+		_, err := s.ParseLogoutRequest(r)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid signature of SAMLRequest")
+	})
+
+	t.Run("Rejects the requests of another issuer", func(t *testing.T) {
+		r := idp.request(t, "SAMLRequest", newIdPLogoutRequest("https://other.example.com").Element(), "", dsig.RSASHA256SignatureMethod)
+
+		_, err := s.ParseLogoutRequest(r)
+		require.EqualError(t, err, `invalid logout request: issuer isn't "https://idp.example.com/metadata"`)
+	})
+}
+
+func TestSAMLService_ParseLogoutResponse(t *testing.T) {
+	s, idp := newTestService(t, nil)
+	newResponse := func(status string) *saml.LogoutResponse {
+		return &saml.LogoutResponse{
+			ID:           "id-2",
+			InResponseTo: "id-1",
+			Version:      "2.0",
+			IssueInstant: time.Now(),
+			Destination:  "https://grafana.example.com/saml/slo",
+			Issuer:       &saml.Issuer{Value: idpEntityID},
+			Status:       saml.Status{StatusCode: saml.StatusCode{Value: status}},
+		}
+	}
+
+	r := idp.request(t, "SAMLResponse", newResponse(saml.StatusSuccess).Element(), "", "")
+	require.False(t, IsLogoutRequest(r))
+	require.NoError(t, s.ParseLogoutResponse(r))
+
+	r = idp.request(t, "SAMLResponse", newResponse(saml.StatusRequester).Element(), "", dsig.RSASHA256SignatureMethod)
+	require.EqualError(t, s.ParseLogoutResponse(r), `logout failed in the IdP with status "urn:oasis:names:tc:SAML:2.0:status:Requester"`)
+}
@@ -0,0 +1,535 @@
+// Package saml implements the SAML 2.0 service provider which logs the users in with an identity provider (IdP),
+// and logs them out of the IdP and of Grafana with the SAML single logout.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"   // Hash of the rsa-sha1 signatures.
+	_ "crypto/sha256" // Hash of the rsa-sha256 signatures.
+	_ "crypto/sha512" // Hash of the rsa-sha512 signatures.
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// loginRequestTimeout is how long the user has to log in with the IdP after the login request of Grafana.
+	loginRequestTimeout = 10 * time.Minute
+	// loginRequestCookiePrefix is the prefix of the cookies binding the login requests to the browser of the user,
+	// followed by their relay state.
+	loginRequestCookiePrefix = "saml_request_"
+)
+
+var (
+	ErrNoEmail        = errors.New("SAML assertion doesn't contain an email address")
+	ErrOrgNotAllowed  = errors.New("user isn't a member of an allowed organization")
+	ErrNoSLOLocation  = errors.New("IdP metadata doesn't contain a single logout service")
+	errUnsigned       = errors.New("message isn't signed")
+	errNoRequestID    = errors.New("response isn't a response to a login request from Grafana")
+	errOtherBrowser   = errors.New("response isn't a response to a login request from this browser")
+	errBadRelayState  = errors.New("relay state doesn't match the relay state of the IdP-initiated logins")
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// signatureMethods are the signature methods of the signature_algorithm values.
+var signatureMethods = map[string]string{
+	"rsa-sha1":   dsig.RSASHA1SignatureMethod,
+	"rsa-sha256": dsig.RSASHA256SignatureMethod,
+	"rsa-sha512": dsig.RSASHA512SignatureMethod,
+}
+
+// signatureHashes are the hashes of the signature methods.
+var signatureHashes = map[string]crypto.Hash{
+	dsig.RSASHA1SignatureMethod:   crypto.SHA1,
+	dsig.RSASHA256SignatureMethod: crypto.SHA256,
+	dsig.RSASHA512SignatureMethod: crypto.SHA512,
+}
+
+// Message is a SAML message sent to the IdP through the browser of the user, either with a redirect to RedirectURL
+// or with the HTML form PostForm which posts itself.
+type Message struct {
+	RedirectURL string
+	PostForm    []byte
+}
+
+type SAMLService struct {
+	Cfg         *setting.Cfg             `inject:""`
+	RemoteCache *remotecache.RemoteCache `inject:""`
+
+	log        log.Logger
+	sp         *saml.ServiceProvider
+	idpCerts   []*x509.Certificate
+	orgMapping map[string][]int64
+	// loginRequestsMu serializes the uses of the login requests in the instance
+	loginRequestsMu sync.Mutex
+}
+
+func init() {
+	registry.RegisterService(&SAMLService{})
+}
+
+func (s *SAMLService) Init() error {
+	s.log = log.New("saml.auth")
+	if !s.Cfg.SAML.Enabled {
+		return nil
+	}
+
+	orgMapping, err := parseOrgMapping(s.Cfg.SAML.OrgMapping)
+	if err != nil {
+		return err
+	}
+	sp, err := s.newServiceProvider()
+	if err != nil {
+		return fmt.Errorf("failed to set up SAML: %w", err)
+	}
+	idpCerts, err := idpSigningCerts(sp.IDPMetadata)
+	if err != nil {
+		return fmt.Errorf("failed to set up SAML: %w", err)
+	}
+
+	// The delay is global to the SAML package, there's a single service provider.
+	saml.MaxIssueDelay = s.Cfg.SAML.MaxIssueDelay
+	s.sp, s.idpCerts, s.orgMapping = sp, idpCerts, orgMapping
+	return nil
+}
+
+// IsEnabled returns whether the users can log in with SAML.
+func (s *SAMLService) IsEnabled() bool {
+	return s.sp != nil
+}
+
+// IsSingleLogoutEnabled returns whether the logouts of the SAML users go through the IdP.
+func (s *SAMLService) IsSingleLogoutEnabled() bool {
+	return s.sp != nil && s.Cfg.SAML.SingleLogout
+}
+
+func (s *SAMLService) newServiceProvider() (*saml.ServiceProvider, error) {
+	cfg := s.Cfg.SAML
+	certPEM, err := readValue("certificate", cfg.Certificate, cfg.CertificatePath)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := readValue("private_key", cfg.PrivateKey, cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	idpMetadata, err := s.readIdPMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var signatureMethod string
+	if cfg.SignatureAlgorithm != "" {
+		var ok bool
+		if signatureMethod, ok = signatureMethods[cfg.SignatureAlgorithm]; !ok {
+			return nil, fmt.Errorf("unsupported signature_algorithm %q", cfg.SignatureAlgorithm)
+		}
+	}
+
+	rootURL, err := url.Parse(s.Cfg.AppURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root_url: %w", err)
+	}
+	return &saml.ServiceProvider{
+		Key:                   key,
+		Certificate:           cert,
+		MetadataURL:           *rootURL.ResolveReference(&url.URL{Path: "saml/metadata"}),
+		AcsURL:                *rootURL.ResolveReference(&url.URL{Path: "saml/acs"}),
+		SloURL:                *rootURL.ResolveReference(&url.URL{Path: "saml/slo"}),
+		IDPMetadata:           idpMetadata,
+		MetadataValidDuration: cfg.MetadataValidDuration,
+		AllowIDPInitiated:     cfg.AllowIdPInitiated,
+		SignatureMethod:       signatureMethod,
+	}, nil
+}
+
+// readIdPMetadata reads the IdP metadata from idp_metadata, idp_metadata_path or idp_metadata_url.
+func (s *SAMLService) readIdPMetadata() (*saml.EntityDescriptor, error) {
+	cfg := s.Cfg.SAML
+	if cfg.IdPMetadataURL == "" {
+		data, err := readValue("idp_metadata", cfg.IdPMetadata, cfg.IdPMetadataPath)
+		if err != nil {
+			return nil, err
+		}
+		return parseIdPMetadata(data)
+	}
+	if cfg.IdPMetadata != "" || cfg.IdPMetadataPath != "" {
+		return nil, errors.New("only one of idp_metadata, idp_metadata_path and idp_metadata_url can be set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.IdPMetadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IdP metadata: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to load IdP metadata: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IdP metadata: %w", err)
+	}
+	return parseIdPMetadata(data)
+}
+
+// Metadata returns the SP metadata XML, which registers Grafana in the IdP.
+func (s *SAMLService) Metadata() ([]byte, error) {
+	metadata := s.sp.Metadata()
+	// The IdPs can send the logout messages with both bindings.
+	for i := range metadata.SPSSODescriptors {
+		descriptor := &metadata.SPSSODescriptors[i]
+		descriptor.SingleLogoutServices = append([]saml.Endpoint{{
+			Binding:  saml.HTTPRedirectBinding,
+			Location: s.sp.SloURL.String(),
+		}}, descriptor.SingleLogoutServices...)
+	}
+	return xml.MarshalIndent(metadata, "", "  ")
+}
+
+// NewLoginRequest returns the authentication request which starts the login of a user in the IdP. The request is
+// tracked until the response of the IdP, and bound to the browser of the user with a cookie written to w.
+func (s *SAMLService) NewLoginRequest(w http.ResponseWriter) (*Message, error) {
+	binding, location := idpLocation(s.sp.GetSSOBindingLocation)
+	if location == "" {
+		return nil, errors.New("IdP metadata doesn't contain a single sign-on service")
+	}
+	if binding == saml.HTTPRedirectBinding {
+		req, err := s.signingSP("").MakeAuthenticationRequest(location)
+		if err != nil {
+			return nil, err
+		}
+		relayState, err := s.trackLoginRequest(w, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		return s.redirect(location, "SAMLRequest", req.Element(), relayState, s.sp.SignatureMethod)
+	}
+
+	req, err := s.sp.MakeAuthenticationRequest(location)
+	if err != nil {
+		return nil, err
+	}
+	relayState, err := s.trackLoginRequest(w, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{PostForm: req.Post(relayState)}, nil
+}
+
+// ParseLoginResponse returns the user of the response of the IdP to a login request, or to an IdP-initiated login
+// when allow_idp_initiated is true. A login request can only be used once, by the browser which started it.
+func (s *SAMLService) ParseLoginResponse(w http.ResponseWriter, r *http.Request) (*models.ExternalUserInfo, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	relayState := r.PostForm.Get("RelayState")
+	requestID, ok, err := s.useLoginRequest(w, r, relayState)
+	if err != nil {
+		return nil, err
+	}
+	var possibleRequestIDs []string
+	if ok {
+		possibleRequestIDs = []string{requestID}
+	} else if !s.Cfg.SAML.AllowIdPInitiated {
+		return nil, errNoRequestID
+	} else if s.Cfg.SAML.RelayState != "" && relayState != s.Cfg.SAML.RelayState {
+		return nil, errBadRelayState
+	}
+
+	assertion, err := s.sp.ParseResponse(r, possibleRequestIDs)
+	if err != nil {
+		var invalidErr *saml.InvalidResponseError
+		if errors.As(err, &invalidErr) {
+			s.log.Debug("Invalid SAML response", "error", invalidErr.PrivateErr)
+		}
+		return nil, err
+	}
+	return s.externalUser(assertion)
+}
+
+// signingSP returns a copy of the service provider which signs the messages it makes with signatureMethod, or
+// doesn't sign them when it's empty.
+func (s *SAMLService) signingSP(signatureMethod string) *saml.ServiceProvider {
+	sp := *s.sp
+	sp.SignatureMethod = signatureMethod
+	return &sp
+}
+
+// redirect returns the message sending el as the param of the query of location with the HTTP-Redirect binding,
+// with the signature of the query when signatureMethod isn't empty.
+func (s *SAMLService) redirect(location, param string, el *etree.Element, relayState, signatureMethod string) (*Message, error) {
+	doc := etree.NewDocument()
+	doc.SetRoot(el)
+	buf := &bytes.Buffer{}
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := doc.WriteTo(w); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	query := param + "=" + url.QueryEscape(base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query += "&RelayState=" + url.QueryEscape(relayState)
+	}
+	if signatureMethod != "" {
+		query += "&SigAlg=" + url.QueryEscape(signatureMethod)
+		signature, err := signQuery(s.sp.Key, signatureMethod, query)
+		if err != nil {
+			return nil, err
+		}
+		query += "&Signature=" + url.QueryEscape(signature)
+	}
+
+	separator := "?"
+	if strings.Contains(location, "?") {
+		separator = "&"
+	}
+	return &Message{RedirectURL: location + separator + query}, nil
+}
+
+// trackLoginRequest keeps the id of the login request in the remote cache under a new relay state, which the IdP
+// sends back with its response, and writes the cookie binding the request to the browser of the user.
+func (s *SAMLService) trackLoginRequest(w http.ResponseWriter, requestID string) (string, error) {
+	relayState, err := util.GetRandomString(32)
+	if err != nil {
+		return "", err
+	}
+	if err := s.RemoteCache.Set(loginRequestKey(relayState), requestID, loginRequestTimeout); err != nil {
+		return "", fmt.Errorf("failed to keep SAML login request: %w", err)
+	}
+	http.SetCookie(w, s.loginRequestCookie(relayState, requestID, int(loginRequestTimeout.Seconds())))
+	return relayState, nil
+}
+
+// useLoginRequest returns the id of the login request of the relay state, and whether it's the relay state of a
+// login request. The login request is removed, so that its response can't be replayed, and it fails when the
+// request doesn't have the cookie of the login request.
+func (s *SAMLService) useLoginRequest(w http.ResponseWriter, r *http.Request, relayState string) (string, bool, error) {
+	if relayState == "" {
+		return "", false, nil
+	}
+
+	s.loginRequestsMu.Lock()
+	defer s.loginRequestsMu.Unlock()
+	key := loginRequestKey(relayState)
+	value, err := s.RemoteCache.Get(key)
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get SAML login request: %w", err)
+	}
+	if err := s.RemoteCache.Delete(key); err != nil {
+		return "", false, fmt.Errorf("failed to remove SAML login request: %w", err)
+	}
+	http.SetCookie(w, s.loginRequestCookie(relayState, "", -1))
+
+	requestID, _ := value.(string)
+	cookie, err := r.Cookie(loginRequestCookiePrefix + relayState)
+	if err != nil || requestID == "" || cookie.Value != requestID {
+		return "", false, errOtherBrowser
+	}
+	return requestID, true, nil
+}
+
+// loginRequestCookie returns the cookie binding the login request of the relay state to the browser of the user.
+// The browsers send it with the response posted from the IdP when it's SameSite=None, which they only allow for
+// secure cookies, or when it doesn't have a SameSite attribute.
+func (s *SAMLService) loginRequestCookie(relayState, requestID string, maxAge int) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     loginRequestCookiePrefix + relayState,
+		Value:    requestID,
+		Path:     s.sp.AcsURL.Path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   s.Cfg.CookieSecure,
+	}
+	if s.Cfg.CookieSecure {
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+func loginRequestKey(relayState string) string {
+	return "saml-login-request:" + relayState
+}
+
+// idpLocation returns the location of an IdP service with the HTTP-Redirect binding, or the HTTP-POST binding when
+// the IdP doesn't support it.
+func idpLocation(location func(binding string) string) (string, string) {
+	if l := location(saml.HTTPRedirectBinding); l != "" {
+		return saml.HTTPRedirectBinding, l
+	}
+	return saml.HTTPPostBinding, location(saml.HTTPPostBinding)
+}
+
+// signQuery returns the signature of the query of a message sent with the HTTP-Redirect binding.
+func signQuery(key *rsa.PrivateKey, signatureMethod, query string) (string, error) {
+	hash := signatureHashes[signatureMethod]
+	h := hash.New()
+	h.Write([]byte(query))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, hash, h.Sum(nil))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// readValue returns the base64 decoded value of the setting name, or the content of the file of its _path setting.
+func readValue(name, value, path string) ([]byte, error) {
+	switch {
+	case value != "" && path != "":
+		return nil, fmt.Errorf("only one of %s and %s_path can be set", name, name)
+	case value != "":
+		return base64.StdEncoding.DecodeString(value)
+	case path != "":
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `path` comes from grafana configuration file
+		return ioutil.ReadFile(path)
+	}
+	return nil, fmt.Errorf("%s or %s_path is required", name, name)
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("certificate isn't PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key isn't PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key isn't an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// parseIdPMetadata parses the IdP metadata, which is either an EntityDescriptor or an EntitiesDescriptor containing
+// the EntityDescriptor of the IdP.
+func parseIdPMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("invalid IdP metadata: %w", err)
+	}
+
+	var entity saml.EntityDescriptor
+	if err := xml.Unmarshal(data, &entity); err == nil {
+		return &entity, nil
+	}
+	var entities saml.EntitiesDescriptor
+	if err := xml.Unmarshal(data, &entities); err != nil {
+		return nil, fmt.Errorf("invalid IdP metadata: %w", err)
+	}
+	for i, e := range entities.EntityDescriptors {
+		if len(e.IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+	return nil, errors.New("IdP metadata doesn't contain an IdP")
+}
+
+// idpSigningCerts returns the certificates the IdP signs its messages with.
+func idpSigningCerts(metadata *saml.EntityDescriptor) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, descriptor := range metadata.IDPSSODescriptors {
+		for _, keyDescriptor := range descriptor.KeyDescriptors {
+			if keyDescriptor.Use != "" && keyDescriptor.Use != "signing" || keyDescriptor.KeyInfo.Certificate == "" {
+				continue
+			}
+			der, err := base64.StdEncoding.DecodeString(whitespacePattern.ReplaceAllString(keyDescriptor.KeyInfo.Certificate, ""))
+			if err != nil {
+				return nil, fmt.Errorf("invalid IdP certificate: %w", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IdP certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("IdP metadata doesn't contain a signing certificate")
+	}
+	return certs, nil
+}
+
+// parseOrgMapping parses the Organization:OrgId mappings.
+func parseOrgMapping(mappings []string) (map[string][]int64, error) {
+	orgMapping := map[string][]int64{}
+	for _, mapping := range mappings {
+		i := strings.LastIndex(mapping, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid SAML org_mapping %q", mapping)
+		}
+		orgID, err := strconv.ParseInt(mapping[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SAML org_mapping %q: %w", mapping, err)
+		}
+		orgMapping[mapping[:i]] = append(orgMapping[mapping[:i]], orgID)
+	}
+	return orgMapping, nil
+}
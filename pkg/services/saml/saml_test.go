@@ -0,0 +1,255 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const idpEntityID = "https://idp.example.com/metadata"
+
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestKeyPair(t *testing.T, name string) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert
+}
+
+func newTestConfig(t *testing.T, configure func(*setting.SAMLSettings)) (*setting.Cfg, *testIdP) {
+	t.Helper()
+	spKey, spCert := newTestKeyPair(t, "grafana.example.com")
+	idpKey, idpCert := newTestKeyPair(t, "idp.example.com")
+
+	metadata, err := xml.Marshal(&saml.EntityDescriptor{
+		EntityID: idpEntityID,
+		IDPSSODescriptors: []saml.IDPSSODescriptor{{
+			SSODescriptor: saml.SSODescriptor{
+				RoleDescriptor: saml.RoleDescriptor{
+					KeyDescriptors: []saml.KeyDescriptor{{
+						Use:     "signing",
+						KeyInfo: saml.KeyInfo{Certificate: base64.StdEncoding.EncodeToString(idpCert.Raw)},
+					}},
+				},
+				SingleLogoutServices: []saml.Endpoint{{Binding: saml.HTTPRedirectBinding, Location: "https://idp.example.com/slo"}},
+			},
+			SingleSignOnServices: []saml.Endpoint{{Binding: saml.HTTPRedirectBinding, Location: "https://idp.example.com/sso"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	cfg := setting.SAMLSettings{
+		Enabled:       true,
+		SingleLogout:  true,
+		Certificate:   base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: spCert.Raw})),
+		PrivateKey:    base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(spKey)})),
+		IdPMetadata:   base64.StdEncoding.EncodeToString(metadata),
+		MaxIssueDelay: 90 * time.Second,
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	return &setting.Cfg{AppURL: "https://grafana.example.com/", SAML: cfg}, &testIdP{key: idpKey, cert: idpCert}
+}
+
+func newTestService(t *testing.T, configure func(*setting.SAMLSettings)) (*SAMLService, *testIdP) {
+	t.Helper()
+	cfg, idp := newTestConfig(t, configure)
+	s := &SAMLService{Cfg: cfg, RemoteCache: remotecache.NewFakeStore(t)}
+	require.NoError(t, s.Init())
+	return s, idp
+}
+
+func TestSAMLService_Init(t *testing.T) {
+	t.Run("Is disabled by default", func(t *testing.T) {
+		s := &SAMLService{Cfg: &setting.Cfg{}}
+		require.NoError(t, s.Init())
+		assert.False(t, s.IsEnabled())
+		assert.False(t, s.IsSingleLogoutEnabled())
+	})
+
+	t.Run("Fails with an invalid org mapping", func(t *testing.T) {
+		s := &SAMLService{Cfg: &setting.Cfg{SAML: setting.SAMLSettings{Enabled: true, OrgMapping: []string{"Engineering"}}}}
+		require.EqualError(t, s.Init(), `invalid SAML org_mapping "Engineering"`)
+	})
+
+	t.Run("Fails with an unsupported signature algorithm", func(t *testing.T) {
+		cfg, _ := newTestConfig(t, func(cfg *setting.SAMLSettings) {
+			cfg.SignatureAlgorithm = "dsa-sha1"
+		})
+		s := &SAMLService{Cfg: cfg}
+		require.EqualError(t, s.Init(), `failed to set up SAML: unsupported signature_algorithm "dsa-sha1"`)
+	})
+}
+
+func TestSAMLService_Metadata(t *testing.T) {
+	s, _ := newTestService(t, func(cfg *setting.SAMLSettings) {
+		cfg.SignatureAlgorithm = "rsa-sha256"
+	})
+
+	data, err := s.Metadata()
+	require.NoError(t, err)
+	var metadata saml.EntityDescriptor
+	require.NoError(t, xml.Unmarshal(data, &metadata))
+	descriptor := metadata.SPSSODescriptors[0]
+	assert.Equal(t, "https://grafana.example.com/saml/acs", descriptor.AssertionConsumerServices[0].Location)
+	assert.Equal(t, []string{saml.HTTPRedirectBinding, saml.HTTPPostBinding},
+		[]string{descriptor.SingleLogoutServices[0].Binding, descriptor.SingleLogoutServices[1].Binding})
+	assert.Equal(t, "https://grafana.example.com/saml/slo", descriptor.SingleLogoutServices[0].Location)
+	assert.True(t, *descriptor.AuthnRequestsSigned)
+}
+
+func TestSAMLService_NewLoginRequest(t *testing.T) {
+	s, _ := newTestService(t, func(cfg *setting.SAMLSettings) {
+		cfg.SignatureAlgorithm = "rsa-sha256"
+	})
+
+	w := httptest.NewRecorder()
+	msg, err := s.NewLoginRequest(w)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(msg.RedirectURL, "https://idp.example.com/sso?SAMLRequest="))
+
+	u, err := url.Parse(msg.RedirectURL)
+	require.NoError(t, err)
+	var req saml.AuthnRequest
+	decodeRedirectMessage(t, u.Query().Get("SAMLRequest"), &req)
+	assert.Equal(t, "https://grafana.example.com/saml/acs", req.AssertionConsumerServiceURL)
+	assert.Nil(t, req.Signature)
+
+	relayState := u.Query().Get("RelayState")
+	requestID, err := s.RemoteCache.Get(loginRequestKey(relayState))
+	require.NoError(t, err)
+	assert.Equal(t, req.ID, requestID)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, loginRequestCookiePrefix+relayState, cookies[0].Name)
+	assert.Equal(t, req.ID, cookies[0].Value)
+	assert.Equal(t, "/saml/acs", cookies[0].Path)
+
+	assert.Equal(t, dsig.RSASHA256SignatureMethod, u.Query().Get("SigAlg"))
+	verifySPSignature(t, s, u)
+}
+
+func TestSAMLService_ParseLoginResponse(t *testing.T) {
+	parse := func(s *SAMLService, relayState string, cookies ...*http.Cookie) error {
+		form := url.Values{"SAMLResponse": {""}, "RelayState": {relayState}}
+		r := httptest.NewRequest(http.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range cookies {
+			r.AddCookie(cookie)
+		}
+		_, err := s.ParseLoginResponse(httptest.NewRecorder(), r)
+		return err
+	}
+	// login starts a login request, and returns its relay state and its cookie.
+	login := func(t *testing.T, s *SAMLService) (string, *http.Cookie) {
+		w := httptest.NewRecorder()
+		msg, err := s.NewLoginRequest(w)
+		require.NoError(t, err)
+		u, err := url.Parse(msg.RedirectURL)
+		require.NoError(t, err)
+		return u.Query().Get("RelayState"), w.Result().Cookies()[0]
+	}
+
+	t.Run("Rejects the responses without a login request", func(t *testing.T) {
+		s, _ := newTestService(t, nil)
+
+		assert.Equal(t, errNoRequestID, parse(s, ""))
+		assert.Equal(t, errNoRequestID, parse(s, "forged"))
+	})
+
+	t.Run("Login requests can only be used once", func(t *testing.T) {
+		s, _ := newTestService(t, nil)
+		relayState, cookie := login(t, s)
+
+		err := parse(s, relayState, cookie)
+		assert.Error(t, err)
+		assert.NotEqual(t, errNoRequestID, err)
+		assert.NotEqual(t, errOtherBrowser, err)
+		assert.Equal(t, errNoRequestID, parse(s, relayState, cookie))
+	})
+
+	t.Run("Login requests can only be used by the browser which started them", func(t *testing.T) {
+		s, _ := newTestService(t, nil)
+		relayState, cookie := login(t, s)
+
+		assert.Equal(t, errOtherBrowser, parse(s, relayState))
+		assert.Equal(t, errNoRequestID, parse(s, relayState, cookie))
+
+		relayState, cookie = login(t, s)
+		cookie.Value = "id-forged"
+		assert.Equal(t, errOtherBrowser, parse(s, relayState, cookie))
+	})
+
+	t.Run("Rejects the IdP-initiated logins with another relay state", func(t *testing.T) {
+		s, _ := newTestService(t, func(cfg *setting.SAMLSettings) {
+			cfg.AllowIdPInitiated = true
+			cfg.RelayState = "grafana"
+		})
+
+		assert.Equal(t, errBadRelayState, parse(s, "other"))
+		assert.NotEqual(t, errBadRelayState, parse(s, "grafana"))
+		relayState, cookie := login(t, s)
+		assert.NotEqual(t, errBadRelayState, parse(s, relayState, cookie))
+	})
+}
+
+func decodeRedirectMessage(t *testing.T, encoded string, v interface{}) {
+	t.Helper()
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	require.NoError(t, xml.Unmarshal(data, v))
+}
+
+// verifySPSignature verifies the signature of the query of the message sent by Grafana with the HTTP-Redirect
+// binding.
+func verifySPSignature(t *testing.T, s *SAMLService, u *url.URL) {
+	t.Helper()
+	i := strings.Index(u.RawQuery, "&Signature=")
+	require.Greater(t, i, 0)
+	signature, err := base64.StdEncoding.DecodeString(u.Query().Get("Signature"))
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(u.RawQuery[:i]))
+	require.NoError(t, rsa.VerifyPKCS1v15(&s.sp.Key.PublicKey, crypto.SHA256, digest[:], signature))
+}
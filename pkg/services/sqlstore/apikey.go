@@ -14,13 +14,16 @@ func init() {
 	bus.AddHandler("sql", GetApiKeyByName)
 	bus.AddHandlerCtx("sql", DeleteApiKeyCtx)
 	bus.AddHandler("sql", AddApiKey)
+	bus.AddHandlerCtx("sql", RotateApiKey)
+	bus.AddHandlerCtx("sql", UpdateApiKeyLastUsed)
 }
 
+// GetApiKeys returns the API keys of the org, without the tokens of its service accounts.
 func GetApiKeys(query *models.GetApiKeysQuery) error {
-	sess := x.Limit(100, 0).Where("org_id=? and ( expires IS NULL or expires >= ?)",
+	sess := x.Limit(100, 0).Where("org_id=? and service_account_id IS NULL and ( expires IS NULL or expires >= ?)",
 		query.OrgId, timeNow().Unix()).Asc("name")
 	if query.IncludeExpired {
-		sess = x.Limit(100, 0).Where("org_id=?", query.OrgId).Asc("name")
+		sess = x.Limit(100, 0).Where("org_id=? and service_account_id IS NULL", query.OrgId).Asc("name")
 	}
 
 	query.Result = make([]*models.ApiKey, 0)
@@ -57,21 +60,20 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 		}
 
 		updated := timeNow()
-		var expires *int64 = nil
-		if cmd.SecondsToLive > 0 {
-			v := updated.Add(time.Second * time.Duration(cmd.SecondsToLive)).Unix()
-			expires = &v
-		} else if cmd.SecondsToLive < 0 {
-			return models.ErrInvalidApiKeyExpiration
+		expires, err := apiKeyExpiration(updated, cmd.SecondsToLive)
+		if err != nil {
+			return err
 		}
 		t := models.ApiKey{
-			OrgId:   cmd.OrgId,
-			Name:    cmd.Name,
-			Role:    cmd.Role,
-			Key:     cmd.Key,
-			Created: updated,
-			Updated: updated,
-			Expires: expires,
+			OrgId:            cmd.OrgId,
+			Name:             cmd.Name,
+			Role:             cmd.Role,
+			Key:              cmd.Key,
+			Created:          updated,
+			Updated:          updated,
+			Expires:          expires,
+			ServiceAccountId: cmd.ServiceAccountId,
+			Scopes:           cmd.Scopes,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
@@ -82,6 +84,54 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 	})
 }
 
+// RotateApiKey replaces the key of the API key, and resets its expiration and its last use.
+func RotateApiKey(ctx context.Context, cmd *models.RotateApiKeyCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		var key models.ApiKey
+		exists, err := sess.Where("id=? AND org_id=?", cmd.Id, cmd.OrgId).Get(&key)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrApiKeyNotFound
+		}
+
+		updated := timeNow()
+		expires, err := apiKeyExpiration(updated, cmd.SecondsToLive)
+		if err != nil {
+			return err
+		}
+		key.Key = cmd.Key
+		key.Updated = updated
+		key.Expires = expires
+		key.LastUsedAt = nil
+		if _, err := sess.ID(key.Id).Cols("key", "updated", "expires", "last_used_at").Update(&key); err != nil {
+			return err
+		}
+		cmd.Result = &key
+		return nil
+	})
+}
+
+func UpdateApiKeyLastUsed(ctx context.Context, cmd *models.UpdateApiKeyLastUsedCommand) error {
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE api_key SET last_used_at=? WHERE id=?", timeNow(), cmd.Id)
+		return err
+	})
+}
+
+// apiKeyExpiration returns the expiration of an API key created or rotated at the time, nil for no expiration.
+func apiKeyExpiration(updated time.Time, secondsToLive int64) (*int64, error) {
+	if secondsToLive < 0 {
+		return nil, models.ErrInvalidApiKeyExpiration
+	}
+	if secondsToLive == 0 {
+		return nil, nil
+	}
+	v := updated.Add(time.Second * time.Duration(secondsToLive)).Unix()
+	return &v, nil
+}
+
 func GetApiKeyById(query *models.GetApiKeyByIdQuery) error {
 	var apikey models.ApiKey
 	has, err := x.Id(query.ApiKeyId).Get(&apikey)
@@ -44,6 +44,22 @@ func TestApiKeyDataAccess(t *testing.T) {
 			assert.Nil(t, query.Result.Expires)
 		})
 
+		t.Run("Add a key with scopes", func(t *testing.T) {
+			cmd := models.AddApiKeyCommand{OrgId: 1, Name: "scoped", Key: "asd-scoped", Scopes: []string{"dashboards:read", "ds:query:uid:xyz"}}
+			err := AddApiKey(&cmd)
+			assert.Nil(t, err)
+
+			query := models.GetApiKeyByNameQuery{KeyName: "scoped", OrgId: 1}
+			err = GetApiKeyByName(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"dashboards:read", "ds:query:uid:xyz"}, query.Result.Scopes)
+
+			query = models.GetApiKeyByNameQuery{KeyName: "non-expiring", OrgId: 1}
+			err = GetApiKeyByName(&query)
+			assert.Nil(t, err)
+			assert.Empty(t, query.Result.Scopes)
+		})
+
 		t.Run("Add an expiring key", func(t *testing.T) {
 			// expires in one hour
 			cmd := models.AddApiKeyCommand{OrgId: 1, Name: "expiring-in-an-hour", Key: "asd2", SecondsToLive: 3600}
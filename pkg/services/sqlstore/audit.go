@@ -0,0 +1,69 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// AddAuditEntry stores the entry of the audit log.
+func (ss *SQLStore) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+// SearchAuditEntries returns a page of the entries of the audit log of the org matching the query, the most recent
+// first.
+func (ss *SQLStore) SearchAuditEntries(ctx context.Context, query *models.SearchAuditEntriesQuery) (*models.SearchAuditEntriesResult, error) {
+	result := &models.SearchAuditEntriesResult{Entries: []*models.AuditEntryDTO{}, Page: query.Page, PerPage: query.PerPage}
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		whereConditions := []string{"org_id = ?"}
+		whereParams := []interface{}{query.OrgId}
+		if query.UserId != 0 {
+			whereConditions = append(whereConditions, "user_id = ?")
+			whereParams = append(whereParams, query.UserId)
+		}
+		if query.Action != "" {
+			whereConditions = append(whereConditions, "action = ?")
+			whereParams = append(whereParams, query.Action)
+		}
+		if query.ResourceType != "" {
+			whereConditions = append(whereConditions, "resource_type = ?")
+			whereParams = append(whereParams, query.ResourceType)
+		}
+		if query.ResourceId != "" {
+			whereConditions = append(whereConditions, "resource_id = ?")
+			whereParams = append(whereParams, query.ResourceId)
+		}
+		if !query.From.IsZero() {
+			whereConditions = append(whereConditions, "created >= ?")
+			whereParams = append(whereParams, query.From)
+		}
+		if !query.To.IsZero() {
+			whereConditions = append(whereConditions, "created <= ?")
+			whereParams = append(whereParams, query.To)
+		}
+		where := strings.Join(whereConditions, " AND ")
+
+		entries := make([]*models.AuditEntry, 0)
+		offset := query.PerPage * (query.Page - 1)
+		err := sess.Where(where, whereParams...).Desc("created").Desc("id").Limit(query.PerPage, offset).Find(&entries)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			result.Entries = append(result.Entries, entry.ToDTO())
+		}
+
+		result.TotalCount, err = sess.Where(where, whereParams...).Count(&models.AuditEntry{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
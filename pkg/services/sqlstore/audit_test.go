@@ -0,0 +1,71 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDataAccess(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entries := []*models.AuditEntry{
+		{OrgId: 1, UserId: 1, Action: models.AuditActionCreate, ResourceType: models.AuditResourceDashboard, ResourceId: "a", Created: now.Add(-3 * time.Hour)},
+		{OrgId: 1, UserId: 2, Action: models.AuditActionUpdate, ResourceType: models.AuditResourceDashboard, ResourceId: "a", Created: now.Add(-2 * time.Hour)},
+		{OrgId: 1, UserId: 2, Action: models.AuditActionDelete, ResourceType: models.AuditResourceDataSource, ResourceId: "b", Created: now.Add(-time.Hour)},
+		{OrgId: 2, UserId: 1, Action: models.AuditActionCreate, ResourceType: models.AuditResourceDashboard, ResourceId: "a", Created: now},
+	}
+	for _, entry := range entries {
+		require.NoError(t, ss.AddAuditEntry(ctx, entry))
+	}
+
+	search := func(query models.SearchAuditEntriesQuery) *models.SearchAuditEntriesResult {
+		if query.Page == 0 {
+			query.Page, query.PerPage = 1, 10
+		}
+		result, err := ss.SearchAuditEntries(ctx, &query)
+		require.NoError(t, err)
+		return result
+	}
+	ids := func(result *models.SearchAuditEntriesResult) []int64 {
+		ids := make([]int64, 0, len(result.Entries))
+		for _, entry := range result.Entries {
+			ids = append(ids, entry.Id)
+		}
+		return ids
+	}
+
+	t.Run("Entries of the org are returned, the most recent first", func(t *testing.T) {
+		result := search(models.SearchAuditEntriesQuery{OrgId: 1})
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Equal(t, []int64{entries[2].Id, entries[1].Id, entries[0].Id}, ids(result))
+	})
+
+	t.Run("Entries are paginated", func(t *testing.T) {
+		result := search(models.SearchAuditEntriesQuery{OrgId: 1, Page: 2, PerPage: 2})
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Equal(t, []int64{entries[0].Id}, ids(result))
+	})
+
+	t.Run("Entries can be filtered", func(t *testing.T) {
+		result := search(models.SearchAuditEntriesQuery{OrgId: 1, UserId: 2})
+		require.Equal(t, []int64{entries[2].Id, entries[1].Id}, ids(result))
+
+		result = search(models.SearchAuditEntriesQuery{OrgId: 1, ResourceType: models.AuditResourceDashboard, ResourceId: "a"})
+		require.Equal(t, []int64{entries[1].Id, entries[0].Id}, ids(result))
+
+		result = search(models.SearchAuditEntriesQuery{OrgId: 1, Action: models.AuditActionDelete})
+		require.Equal(t, []int64{entries[2].Id}, ids(result))
+
+		result = search(models.SearchAuditEntriesQuery{OrgId: 1, From: now.Add(-150 * time.Minute), To: now})
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Equal(t, []int64{entries[2].Id, entries[1].Id}, ids(result))
+	})
+}
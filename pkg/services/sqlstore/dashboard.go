@@ -11,6 +11,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
@@ -162,6 +163,19 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		}
 	}
 
+	if !dash.IsFolder {
+		sess.publishAfterCommit(&events.DashboardSaved{
+			Timestamp: dash.Updated,
+			OrgId:     dash.OrgId,
+			Id:        dash.Id,
+			Uid:       dash.Uid,
+			Title:     dash.Title,
+			FolderId:  dash.FolderId,
+			Version:   dash.Version,
+			UserId:    cmd.UserId,
+		})
+	}
+
 	cmd.Result = dash
 
 	return nil
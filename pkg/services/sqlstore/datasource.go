@@ -14,6 +14,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 )
 
@@ -144,7 +145,13 @@ func DeleteDataSource(cmd *models.DeleteDataSourceCommand) error {
 
 	return inTransaction(func(sess *DBSession) error {
 		result, err := sess.Exec(params...)
+		if err != nil {
+			return err
+		}
 		cmd.DeletedDatasourcesCount, _ = result.RowsAffected()
+
+		rawSQL := "DELETE FROM data_source_permission WHERE org_id=? AND data_source_id NOT IN (SELECT id FROM data_source WHERE org_id=?)"
+		_, err = sess.Exec(rawSQL, cmd.OrgID, cmd.OrgID)
 		return err
 	})
 }
@@ -202,6 +209,18 @@ func AddDataSource(cmd *models.AddDataSourceCommand) error {
 		if err := updateIsDefaultFlag(ds, sess); err != nil {
 			return err
 		}
+		if err := addDefaultDataSourcePermissions(sess, ds); err != nil {
+			return err
+		}
+
+		sess.publishAfterCommit(&events.DataSourceCreated{
+			Timestamp: ds.Created,
+			OrgId:     ds.OrgId,
+			Id:        ds.Id,
+			Uid:       ds.Uid,
+			Name:      ds.Name,
+			Type:      ds.Type,
+		})
 
 		cmd.Result = ds
 		return nil
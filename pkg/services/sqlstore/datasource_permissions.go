@@ -0,0 +1,133 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// defaultDataSourcePermissionRoles are the roles given query permission on new data sources, which every
+// viewer and editor had before data source permissions.
+var defaultDataSourcePermissionRoles = []models.RoleType{models.ROLE_VIEWER, models.ROLE_EDITOR}
+
+func addDefaultDataSourcePermissions(sess *DBSession, ds *models.DataSource) error {
+	for _, role := range defaultDataSourcePermissionRoles {
+		role := role
+		permission := models.DataSourcePermission{
+			OrgId:        ds.OrgId,
+			DataSourceId: ds.Id,
+			Role:         &role,
+			Permission:   models.DsPermissionQuery,
+			Created:      ds.Created,
+			Updated:      ds.Updated,
+		}
+		sess.Nullable("user_id", "team_id")
+		if _, err := sess.Insert(&permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDataSourcePermissions returns the permissions of the data source of the org.
+func (ss *SQLStore) GetDataSourcePermissions(ctx context.Context, orgID, dataSourceID int64) ([]*models.DataSourcePermission, error) {
+	permissions := make([]*models.DataSourcePermission, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Where("org_id=? AND data_source_id=?", orgID, dataSourceID).Find(&permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// GetDataSourcePermissionsInfo returns the permissions of the data source of the org with the users and teams
+// they're given to.
+func (ss *SQLStore) GetDataSourcePermissionsInfo(ctx context.Context, orgID, dataSourceID int64) ([]*models.DataSourcePermissionDTO, error) {
+	permissions := make([]*models.DataSourcePermissionDTO, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		rawSQL := `SELECT
+			dsp.id,
+			dsp.data_source_id,
+			dsp.user_id,
+			u.login AS user_login,
+			u.email AS user_email,
+			dsp.team_id,
+			t.name AS team,
+			dsp.role,
+			dsp.permission,
+			dsp.created,
+			dsp.updated
+			FROM data_source_permission AS dsp
+			LEFT OUTER JOIN ` + dialect.Quote("user") + ` AS u ON u.id = dsp.user_id
+			LEFT OUTER JOIN team AS t ON t.id = dsp.team_id
+			WHERE dsp.org_id = ? AND dsp.data_source_id = ?
+			ORDER BY dsp.id ASC`
+		return sess.SQL(rawSQL, orgID, dataSourceID).Find(&permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range permissions {
+		p.PermissionName = p.Permission.String()
+	}
+	return permissions, nil
+}
+
+// UpdateDataSourcePermissions replaces the permissions of the data source with the items of the command.
+func (ss *SQLStore) UpdateDataSourcePermissions(ctx context.Context, cmd *models.UpdateDataSourcePermissionsCommand) error {
+	if cmd.DataSourceId == 0 {
+		return models.ErrDataSourcePermissionDataSourceEmpty
+	}
+
+	seen := make(map[string]bool, len(cmd.Items))
+	for i := range cmd.Items {
+		item := &cmd.Items[i]
+		if err := item.Validate(); err != nil {
+			return err
+		}
+
+		var key string
+		switch {
+		case item.UserId != 0:
+			key = fmt.Sprintf("user-%d", item.UserId)
+		case item.TeamId != 0:
+			key = fmt.Sprintf("team-%d", item.TeamId)
+		default:
+			key = "role-" + string(*item.Role)
+		}
+		if seen[key] {
+			return models.ErrDataSourcePermissionDuplicate
+		}
+		seen[key] = true
+	}
+
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		rawSQL := "DELETE FROM data_source_permission WHERE org_id=? AND data_source_id=?"
+		if _, err := sess.Exec(rawSQL, cmd.OrgId, cmd.DataSourceId); err != nil {
+			return fmt.Errorf("deleting from data_source_permission failed: %w", err)
+		}
+
+		now := timeNow()
+		for _, item := range cmd.Items {
+			permission := models.DataSourcePermission{
+				OrgId:        cmd.OrgId,
+				DataSourceId: cmd.DataSourceId,
+				UserId:       item.UserId,
+				TeamId:       item.TeamId,
+				Role:         item.Role,
+				Permission:   item.Permission,
+				Created:      now,
+				Updated:      now,
+			}
+			sess.Nullable("user_id", "team_id")
+			if _, err := sess.Insert(&permission); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,67 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourcePermissionsDataAccess(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	addCmd := models.AddDataSourceCommand{OrgId: 1, Name: "loki", Type: "loki", Access: models.DS_ACCESS_PROXY}
+	require.NoError(t, AddDataSource(&addCmd))
+	ds := addCmd.Result
+
+	t.Run("New data sources can be queried by viewers and editors", func(t *testing.T) {
+		permissions, err := ss.GetDataSourcePermissions(ctx, 1, ds.Id)
+		require.NoError(t, err)
+		require.Len(t, permissions, 2)
+		for _, p := range permissions {
+			require.NotNil(t, p.Role)
+			require.Equal(t, models.DsPermissionQuery, p.Permission)
+		}
+	})
+
+	t.Run("Permissions are replaced", func(t *testing.T) {
+		editor := models.ROLE_EDITOR
+		cmd := models.UpdateDataSourcePermissionsCommand{OrgId: 1, DataSourceId: ds.Id, Items: []models.DataSourcePermissionItem{
+			{UserId: 2, Permission: models.DsPermissionAdmin},
+			{Role: &editor, Permission: models.DsPermissionRead},
+		}}
+		require.NoError(t, ss.UpdateDataSourcePermissions(ctx, &cmd))
+
+		permissions, err := ss.GetDataSourcePermissionsInfo(ctx, 1, ds.Id)
+		require.NoError(t, err)
+		require.Len(t, permissions, 2)
+		require.Equal(t, int64(2), permissions[0].UserId)
+		require.Equal(t, "Admin", permissions[0].PermissionName)
+		require.Equal(t, models.ROLE_EDITOR, *permissions[1].Role)
+	})
+
+	t.Run("Invalid permissions are rejected", func(t *testing.T) {
+		admin := models.ROLE_ADMIN
+		for _, items := range [][]models.DataSourcePermissionItem{
+			{{UserId: 2, Permission: models.DsPermissionNoAccess}},
+			{{UserId: 2, TeamId: 1, Permission: models.DsPermissionQuery}},
+			{{Role: &admin, Permission: models.DsPermissionQuery}},
+			{{TeamId: 1, Permission: models.DsPermissionQuery}, {TeamId: 1, Permission: models.DsPermissionRead}},
+		} {
+			cmd := models.UpdateDataSourcePermissionsCommand{OrgId: 1, DataSourceId: ds.Id, Items: items}
+			require.Error(t, ss.UpdateDataSourcePermissions(ctx, &cmd))
+		}
+	})
+
+	t.Run("Permissions are deleted with their data source", func(t *testing.T) {
+		require.NoError(t, DeleteDataSource(&models.DeleteDataSourceCommand{ID: ds.Id, OrgID: 1}))
+
+		permissions, err := ss.GetDataSourcePermissions(ctx, 1, ds.Id)
+		require.NoError(t, err)
+		require.Empty(t, permissions)
+	})
+}
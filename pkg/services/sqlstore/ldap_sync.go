@@ -0,0 +1,73 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// CreateLDAPSyncRun creates the run of the LDAP sync.
+func (ss *SQLStore) CreateLDAPSyncRun(ctx context.Context, run *models.LDAPSyncRun) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Insert(run)
+		return err
+	})
+}
+
+// UpdateLDAPSyncRun updates the state, counts and report of the run of the LDAP sync.
+func (ss *SQLStore) UpdateLDAPSyncRun(ctx context.Context, run *models.LDAPSyncRun) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.ID(run.Id).
+			Cols("state", "error", "finished", "users", "synced", "disabled", "enabled", "failed", "report").
+			Update(run)
+		return err
+	})
+}
+
+// GetLDAPSyncRuns returns the last runs of the LDAP sync, the most recent first.
+func (ss *SQLStore) GetLDAPSyncRuns(ctx context.Context, limit int) ([]*models.LDAPSyncRun, error) {
+	runs := make([]*models.LDAPSyncRun, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Desc("id").Limit(limit).Find(&runs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetLDAPSyncRun returns the run of the LDAP sync.
+func (ss *SQLStore) GetLDAPSyncRun(ctx context.Context, id int64) (*models.LDAPSyncRun, error) {
+	var run models.LDAPSyncRun
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		exists, err := sess.ID(id).Get(&run)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrLDAPSyncRunNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// DeleteOldLDAPSyncRuns deletes the runs of the LDAP sync but the last keep ones.
+func (ss *SQLStore) DeleteOldLDAPSyncRuns(ctx context.Context, keep int) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		var oldest []int64
+		if err := sess.Table("ldap_sync_run").Cols("id").Desc("id").Limit(1, keep-1).Find(&oldest); err != nil {
+			return err
+		}
+		if len(oldest) == 0 {
+			return nil
+		}
+		_, err := sess.Exec("DELETE FROM ldap_sync_run WHERE id < ?", oldest[0])
+		return err
+	})
+}
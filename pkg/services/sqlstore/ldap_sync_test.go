@@ -0,0 +1,55 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDAPSyncRunDataAccess(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		run := &models.LDAPSyncRun{TriggeredBy: models.LDAPSyncTriggerSchedule, State: models.LDAPSyncRunning, Started: now, Finished: now}
+		require.NoError(t, ss.CreateLDAPSyncRun(ctx, run))
+		ids = append(ids, run.Id)
+	}
+
+	t.Run("Runs are updated with their report", func(t *testing.T) {
+		run, err := ss.GetLDAPSyncRun(ctx, ids[2])
+		require.NoError(t, err)
+		run.State = models.LDAPSyncSucceeded
+		run.Users = 2
+		run.Disabled = 1
+		run.Report = `[{"userId":2,"login":"jane","action":"disabled"}]`
+		run.Finished = now.Add(time.Second)
+		require.NoError(t, ss.UpdateLDAPSyncRun(ctx, run))
+
+		result, err := ss.GetLDAPSyncRun(ctx, ids[2])
+		require.NoError(t, err)
+		dto := result.ToDTO(true)
+		require.Equal(t, models.LDAPSyncSucceeded, dto.State)
+		require.Equal(t, []models.LDAPSyncUserResult{{UserId: 2, Login: "jane", Action: models.LDAPSyncUserDisabled}}, dto.Report)
+
+		_, err = ss.GetLDAPSyncRun(ctx, ids[2]+1)
+		require.ErrorIs(t, err, models.ErrLDAPSyncRunNotFound)
+	})
+
+	t.Run("The last runs are kept", func(t *testing.T) {
+		require.NoError(t, ss.DeleteOldLDAPSyncRuns(ctx, 2))
+
+		runs, err := ss.GetLDAPSyncRuns(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.Equal(t, ids[2], runs[0].Id)
+		require.Equal(t, ids[1], runs[1].Id)
+	})
+}
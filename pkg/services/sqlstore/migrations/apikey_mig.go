@@ -82,4 +82,16 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add expires to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "expires", Type: DB_BigInt, Nullable: true,
 	}))
+
+	mg.AddMigration("Add service_account_id to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "service_account_id", Type: DB_BigInt, Nullable: true,
+	}))
+
+	mg.AddMigration("Add last_used_at to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "last_used_at", Type: DB_DateTime, Nullable: true,
+	}))
+
+	mg.AddMigration("Add scopes to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "scopes", Type: DB_Text, Nullable: true,
+	}))
 }
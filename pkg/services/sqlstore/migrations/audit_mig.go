@@ -0,0 +1,31 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAuditMigrations(mg *Migrator) {
+	auditEntryV1 := Table{
+		Name: "audit_entry",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource_type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "data_before", Type: DB_MediumText, Nullable: true},
+			{Name: "data_after", Type: DB_MediumText, Nullable: true},
+			{Name: "diff", Type: DB_MediumText, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"org_id", "resource_type", "resource_id"}},
+		},
+	}
+
+	mg.AddMigration("create audit_entry table", NewAddTableMigration(auditEntryV1))
+	mg.AddMigration("add index audit_entry.org_id_created", NewAddIndexMigration(auditEntryV1, auditEntryV1.Indices[0]))
+	mg.AddMigration("add index audit_entry.org_id_resource_type_resource_id", NewAddIndexMigration(auditEntryV1, auditEntryV1.Indices[1]))
+}
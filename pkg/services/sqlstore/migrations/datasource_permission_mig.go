@@ -0,0 +1,34 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDataSourcePermissionMigrations(mg *Migrator) {
+	dataSourcePermissionV1 := Table{
+		Name: "data_source_permission",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: true},
+			{Name: "team_id", Type: DB_BigInt, Nullable: true},
+			{Name: "role", Type: DB_Varchar, Length: 20, Nullable: true},
+			{Name: "permission", Type: DB_SmallInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "data_source_id"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_permission table", NewAddTableMigration(dataSourcePermissionV1))
+	mg.AddMigration("add index data_source_permission.org_id_data_source_id", NewAddIndexMigration(dataSourcePermissionV1, dataSourcePermissionV1.Indices[0]))
+
+	// Viewers and editors could query every data source before data source permissions, which is kept as the
+	// permissions of the existing data sources, and given to the new ones.
+	for _, role := range []string{"Viewer", "Editor"} {
+		mg.AddMigration("add "+role+" query permission to existing data sources", NewRawSQLMigration(`
+INSERT INTO data_source_permission (org_id, data_source_id, role, permission, created, updated)
+SELECT org_id, id, '`+role+`', 1, created, updated FROM data_source`))
+	}
+}
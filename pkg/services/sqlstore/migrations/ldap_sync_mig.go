@@ -0,0 +1,25 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addLDAPSyncMigrations(mg *Migrator) {
+	ldapSyncRunV1 := Table{
+		Name: "ldap_sync_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "triggered_by", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "started", Type: DB_DateTime, Nullable: false},
+			{Name: "finished", Type: DB_DateTime, Nullable: false},
+			{Name: "users", Type: DB_Int, Nullable: false},
+			{Name: "synced", Type: DB_Int, Nullable: false},
+			{Name: "disabled", Type: DB_Int, Nullable: false},
+			{Name: "enabled", Type: DB_Int, Nullable: false},
+			{Name: "failed", Type: DB_Int, Nullable: false},
+			{Name: "report", Type: DB_MediumText, Nullable: true},
+		},
+	}
+
+	mg.AddMigration("create ldap_sync_run table", NewAddTableMigration(ldapSyncRunV1))
+}
@@ -41,6 +41,11 @@ func AddMigrations(mg *Migrator) {
 	ualert.AddTablesMigrations(mg)
 	ualert.AddDashAlertMigration(mg)
 	addLibraryElementsMigrations(mg)
+	addPublicDashboardMigrations(mg)
+	addDataSourcePermissionMigrations(mg)
+	addAuditMigrations(mg)
+	addWebhookMigrations(mg)
+	addLDAPSyncMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
@@ -42,4 +42,8 @@ func addPreferencesMigrations(mg *Migrator) {
 		SQLite("UPDATE preferences SET team_id=0 WHERE team_id IS NULL;").
 		Postgres("UPDATE preferences SET team_id=0 WHERE team_id IS NULL;").
 		Mysql("UPDATE preferences SET team_id=0 WHERE team_id IS NULL;"))
+
+	mg.AddMigration("Add column query_caching_disabled in preferences", NewAddColumnMigration(preferencesV2, &Column{
+		Name: "query_caching_disabled", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addPublicDashboardMigrations(mg *Migrator) {
+	publicDashboardV1 := Table{
+		Name: "public_dashboard",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "is_enabled", Type: DB_Bool, Nullable: false},
+			{Name: "time_ranges", Type: DB_Text, Nullable: true},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create public_dashboard table v1", NewAddTableMigration(publicDashboardV1))
+
+	mg.AddMigration("add unique index public_dashboard.org_id-dashboard_uid", NewAddIndexMigration(publicDashboardV1, publicDashboardV1.Indices[0]))
+	mg.AddMigration("add unique index public_dashboard.access_token", NewAddIndexMigration(publicDashboardV1, publicDashboardV1.Indices[1]))
+}
@@ -200,7 +200,7 @@ func transNoData(s string) (string, error) {
 	case "alerting":
 		return "Alerting", nil
 	case "keep_state":
-		return "Alerting", nil
+		return "KeepLastState", nil
 	}
 	return "", fmt.Errorf("unrecognized No Data setting %v", s)
 }
@@ -210,7 +210,7 @@ func transExecErr(s string) (string, error) {
 	case "", "alerting":
 		return "Alerting", nil
 	case "keep_state":
-		return "Alerting", nil
+		return "KeepLastState", nil
 	}
 	return "", fmt.Errorf("unrecognized Execution Error setting %v", s)
 }
@@ -19,6 +19,36 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Alertmanager configurations
 	AddAlertmanagerConfigMigrations(mg)
+
+	// Create provenance of provisioned resources
+	AddProvisioningMigrations(mg)
+
+	// Create alert_state_history table
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create ngalert_configuration table
+	AddAdminConfigMigrations(mg)
+
+	// Create alert_notification_log table
+	AddNotificationLogMigrations(mg)
+
+	// Create alert_scheduler_instance table
+	AddSchedulerInstanceMigrations(mg)
+
+	// Create alert_instance_snapshot table
+	AddAlertInstanceSnapshotMigrations(mg)
+
+	// Create alert_escalation_policy and alert_escalation tables
+	AddEscalationMigrations(mg)
+
+	// Create alert_rule_heartbeat table
+	AddAlertRuleHeartbeatMigrations(mg)
+
+	// Create alert_silence_expiry_notification table
+	AddSilenceExpiryNotificationMigrations(mg)
+
+	// Create alert_maintenance table
+	AddMaintenanceMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -201,6 +231,27 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 	mg.AddMigration("add index in alert_rule on org_id, namespase_uid and title columns", migrator.NewAddIndexMigration(alertRule, &migrator.Index{
 		Cols: []string{"org_id", "namespace_uid", "title"}, Type: migrator.UniqueIndex,
 	}))
+
+	// paused rules are not scheduled for evaluation
+	mg.AddMigration("add column is_paused to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add evaluation timeout column
+	mg.AddMigration("add column evaluation_timeout_seconds to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "evaluation_timeout_seconds", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	// add recording rule metric name column
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+
+	// add heartbeat timeout column
+	mg.AddMigration("add column heartbeat_timeout_seconds to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "heartbeat_timeout_seconds", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	// add correlation key template column
+	mg.AddMigration("add column correlation_key to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "correlation_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+
+	// add the maximum number of alert instances column
+	mg.AddMigration("add column max_alert_instances to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "max_alert_instances", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	// add the position of the rules in their rule group
+	mg.AddMigration("add column rule_group_idx to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "rule_group_idx", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -244,6 +295,14 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add evaluation timeout column
+	mg.AddMigration("add column evaluation_timeout_seconds to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "evaluation_timeout_seconds", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+	mg.AddMigration("add column heartbeat_timeout_seconds to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "heartbeat_timeout_seconds", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	mg.AddMigration("add column correlation_key to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "correlation_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+	mg.AddMigration("add column max_alert_instances to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "max_alert_instances", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -265,3 +324,240 @@ func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("alert alert_configuration alertmanager_configuration column from TEXT to MEDIUMTEXT if mysql", migrator.NewRawSQLMigration("").
 		Mysql("ALTER TABLE alert_configuration MODIFY alertmanager_configuration MEDIUMTEXT;"))
 }
+
+func AddProvisioningMigrations(mg *migrator.Migrator) {
+	provisioningTable := migrator.Table{
+		Name: "provenance_type",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "record_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "record_type", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "provenance", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"record_type", "record_key", "org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create provenance_type table", migrator.NewAddTableMigration(provisioningTable))
+	mg.AddMigration("add index to uniquify (record_key, record_type, org_id) columns", migrator.NewAddIndexMigration(provisioningTable, provisioningTable.Indices[0]))
+}
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "0"},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "evaluation_string", Type: migrator.DB_Text, Nullable: true},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "state_changed_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"rule_org_id", "rule_uid", "state_changed_at"}, Type: migrator.IndexType},
+			{Cols: []string{"state_changed_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history table on rule_org_id, rule_uid and state_changed_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history table on state_changed_at column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+}
+
+func AddAdminConfigMigrations(mg *migrator.Migrator) {
+	adminConfiguration := migrator.Table{
+		Name: "ngalert_configuration",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "alertmanagers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "send_alerts_to", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "'internal'"},
+			{Name: "created_at", Type: migrator.DB_Int, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create ngalert_configuration table", migrator.NewAddTableMigration(adminConfiguration))
+	mg.AddMigration("add index in ngalert_configuration on org_id column", migrator.NewAddIndexMigration(adminConfiguration, adminConfiguration.Indices[0]))
+
+	// add the enrichment of the alerts
+	mg.AddMigration("add column enrichment to ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{Name: "enrichment", Type: migrator.DB_Text, Nullable: true}))
+}
+
+func AddNotificationLogMigrations(mg *migrator.Migrator) {
+	notificationLog := migrator.Table{
+		Name: "alert_notification_log",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_type", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_Text, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"created_at"}, Type: migrator.IndexType},
+			{Cols: []string{"receiver", "created_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_log table", migrator.NewAddTableMigration(notificationLog))
+	mg.AddMigration("add index in alert_notification_log table on created_at column", migrator.NewAddIndexMigration(notificationLog, notificationLog.Indices[0]))
+	mg.AddMigration("add index in alert_notification_log table on receiver and created_at columns", migrator.NewAddIndexMigration(notificationLog, notificationLog.Indices[1]))
+	mg.AddMigration("alter alert_notification_log table alerts column to mediumtext in mysql", migrator.NewRawSQLMigration("").
+		Mysql("ALTER TABLE alert_notification_log MODIFY alerts MEDIUMTEXT;"))
+
+	// add the reason of the notifications which were suppressed instead of delivered
+	mg.AddMigration("add column suppressed to alert_notification_log", migrator.NewAddColumnMigration(notificationLog, &migrator.Column{Name: "suppressed", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+}
+
+func AddSchedulerInstanceMigrations(mg *migrator.Migrator) {
+	schedulerInstance := migrator.Table{
+		Name: "alert_scheduler_instance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "instance_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "last_heartbeat", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"instance_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduler_instance table", migrator.NewAddTableMigration(schedulerInstance))
+	mg.AddMigration("add unique index in alert_scheduler_instance on instance_id column", migrator.NewAddIndexMigration(schedulerInstance, schedulerInstance.Indices[0]))
+}
+
+func AddAlertInstanceSnapshotMigrations(mg *migrator.Migrator) {
+	snapshot := migrator.Table{
+		Name: "alert_instance_snapshot",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "data", Type: migrator.DB_LongBlob, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_instance_snapshot table", migrator.NewAddTableMigration(snapshot))
+	mg.AddMigration("add unique index in alert_instance_snapshot on org_id column", migrator.NewAddIndexMigration(snapshot, snapshot.Indices[0]))
+}
+
+func AddEscalationMigrations(mg *migrator.Migrator) {
+	policy := migrator.Table{
+		Name: "alert_escalation_policy",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "steps", Type: migrator.DB_Text, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_escalation_policy table", migrator.NewAddTableMigration(policy))
+	mg.AddMigration("add unique index in alert_escalation_policy on uid column", migrator.NewAddIndexMigration(policy, policy.Indices[0]))
+
+	escalation := migrator.Table{
+		Name: "alert_escalation",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "policy_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "step", Type: migrator.DB_Int, Nullable: false},
+			{Name: "next_step_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resolved", Type: migrator.DB_Bool, Nullable: false},
+			{Name: "acknowledged_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "acknowledged_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"policy_uid"}, Type: migrator.IndexType},
+			{Cols: []string{"next_step_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_escalation table", migrator.NewAddTableMigration(escalation))
+	mg.AddMigration("add index in alert_escalation on policy_uid column", migrator.NewAddIndexMigration(escalation, escalation.Indices[0]))
+	mg.AddMigration("add index in alert_escalation on next_step_at column", migrator.NewAddIndexMigration(escalation, escalation.Indices[1]))
+}
+
+func AddAlertRuleHeartbeatMigrations(mg *migrator.Migrator) {
+	heartbeat := migrator.Table{
+		Name: "alert_rule_heartbeat",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "received_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_heartbeat table", migrator.NewAddTableMigration(heartbeat))
+	mg.AddMigration("add unique index in alert_rule_heartbeat on org_id, rule_uid columns", migrator.NewAddIndexMigration(heartbeat, heartbeat.Indices[0]))
+}
+
+func AddSilenceExpiryNotificationMigrations(mg *migrator.Migrator) {
+	notification := migrator.Table{
+		Name: "alert_silence_expiry_notification",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "notify_before", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "notified_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"silence_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_silence_expiry_notification table", migrator.NewAddTableMigration(notification))
+	mg.AddMigration("add unique index in alert_silence_expiry_notification on silence_id column", migrator.NewAddIndexMigration(notification, notification.Indices[0]))
+}
+
+func AddMaintenanceMigrations(mg *migrator.Migrator) {
+	maintenance := migrator.Table{
+		Name: "alert_maintenance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_maintenance table", migrator.NewAddTableMigration(maintenance))
+	mg.AddMigration("add unique index in alert_maintenance on org_id column", migrator.NewAddIndexMigration(maintenance, maintenance.Indices[0]))
+}
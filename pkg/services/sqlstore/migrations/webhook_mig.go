@@ -0,0 +1,56 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addWebhookMigrations(mg *Migrator) {
+	webhookV1 := Table{
+		Name: "webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "url", Type: DB_Text, Nullable: false},
+			{Name: "events", Type: DB_Text, Nullable: false},
+			{Name: "secure_settings", Type: DB_Text, Nullable: true},
+			{Name: "is_enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create webhook table", NewAddTableMigration(webhookV1))
+	mg.AddMigration("add index webhook.org_id", NewAddIndexMigration(webhookV1, webhookV1.Indices[0]))
+
+	webhookDeliveryV1 := Table{
+		Name: "webhook_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "webhook_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "event_type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "next_attempt_at", Type: DB_DateTime, Nullable: false},
+			{Name: "last_status_code", Type: DB_Int, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+			{Name: "last_duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"state", "next_attempt_at"}},
+			{Cols: []string{"org_id", "webhook_id", "created"}},
+		},
+	}
+
+	mg.AddMigration("create webhook_delivery table", NewAddTableMigration(webhookDeliveryV1))
+	mg.AddMigration("add unique index webhook_delivery.uid", NewAddIndexMigration(webhookDeliveryV1, webhookDeliveryV1.Indices[0]))
+	mg.AddMigration("add index webhook_delivery.state_next_attempt_at", NewAddIndexMigration(webhookDeliveryV1, webhookDeliveryV1.Indices[1]))
+	mg.AddMigration("add index webhook_delivery.org_id_webhook_id_created", NewAddIndexMigration(webhookDeliveryV1, webhookDeliveryV1.Indices[2]))
+}
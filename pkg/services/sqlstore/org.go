@@ -25,26 +25,67 @@ func init() {
 	bus.AddHandler("sql", DeleteOrg)
 }
 
+var orgSearchSortColumns = map[string]searchSortColumn{
+	"id":   {column: "id", kind: searchSortInt},
+	"name": {column: "name"},
+}
+
 func SearchOrgs(query *models.SearchOrgsQuery) error {
 	query.Result = make([]*models.OrgDTO, 0)
-	sess := x.Table("org")
-	if query.Query != "" {
-		sess.Where("name LIKE ?", query.Query+"%")
+	query.NextCursor = ""
+
+	paging, err := newSearchPaging(query.Sort, models.SearchSort{Field: "id"}, orgSearchSortColumns, "id", query.Cursor)
+	if err != nil {
+		return err
 	}
-	if query.Name != "" {
-		sess.Where("name=?", query.Name)
+	cursorCondition, cursorParams, err := paging.condition()
+	if err != nil {
+		return err
 	}
 
-	if len(query.Ids) > 0 {
-		sess.In("id", query.Ids)
+	filter := func(sess *xorm.Session) {
+		if query.Query != "" {
+			sess.Where("name LIKE ?", query.Query+"%")
+		}
+		if query.Name != "" {
+			sess.Where("name=?", query.Name)
+		}
+
+		if len(query.Ids) > 0 {
+			sess.In("id", query.Ids)
+		}
+	}
+
+	sess := x.Table("org")
+	filter(sess)
+	if cursorCondition != "" {
+		sess.Where(cursorCondition, cursorParams...)
 	}
 
 	if query.Limit > 0 {
-		sess.Limit(query.Limit, query.Limit*query.Page)
+		// the pages of the orgs start at 0, and one more org than the limit tells if there's a next page
+		sess.Limit(query.Limit+1, paging.offset(query.Page+1, query.Limit))
 	}
 
 	sess.Cols("id", "name")
-	err := sess.Find(&query.Result)
+	sess.OrderBy(paging.orderBy())
+	if err := sess.Find(&query.Result); err != nil {
+		return err
+	}
+
+	if query.Limit > 0 && len(query.Result) > query.Limit {
+		query.Result = query.Result[:query.Limit]
+		last := query.Result[query.Limit-1]
+		var value interface{} = last.Name
+		if paging.sort.Field == "id" {
+			value = last.Id
+		}
+		query.NextCursor = paging.nextCursor(value, last.Id)
+	}
+
+	countSess := x.Table("org")
+	filter(countSess)
+	query.TotalCount, err = countSess.Count(&models.Org{})
 	return err
 }
 
@@ -238,6 +279,9 @@ func DeleteOrg(cmd *models.DeleteOrgCommand) error {
 			"DELETE FROM dashboard WHERE org_id = ?",
 			"DELETE FROM api_key WHERE org_id = ?",
 			"DELETE FROM data_source WHERE org_id = ?",
+			"DELETE FROM data_source_permission WHERE org_id = ?",
+			"DELETE FROM webhook WHERE org_id = ?",
+			"DELETE FROM webhook_delivery WHERE org_id = ?",
 			"DELETE FROM org_user WHERE org_id = ?",
 			"DELETE FROM org WHERE id = ?",
 			"DELETE FROM temp_user WHERE org_id = ?",
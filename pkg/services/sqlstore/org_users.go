@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -52,6 +53,15 @@ func AddOrgUser(cmd *models.AddOrgUserCommand) error {
 			return err
 		}
 
+		sess.publishAfterCommit(&events.OrgUserAdded{
+			Timestamp: entity.Created,
+			OrgId:     entity.OrgId,
+			UserId:    user.Id,
+			Login:     user.Login,
+			Email:     user.Email,
+			Role:      string(entity.Role),
+		})
+
 		var userOrgs []*models.UserOrgDTO
 		sess.Table("org_user")
 		sess.Join("INNER", "org", "org_user.org_id=org.id")
@@ -106,6 +116,10 @@ func GetOrgUsers(query *models.GetOrgUsersQuery) error {
 	whereConditions = append(whereConditions, "org_user.org_id = ?")
 	whereParams = append(whereParams, query.OrgId)
 
+	// The service accounts aren't users of the org.
+	whereConditions = append(whereConditions, "is_service_account = ?")
+	whereParams = append(whereParams, dialect.BooleanStr(false))
+
 	if query.Query != "" {
 		queryWithWildcards := "%" + query.Query + "%"
 		whereConditions = append(whereConditions, "(email "+dialect.LikeStr()+" ? OR name "+dialect.LikeStr()+" ? OR login "+dialect.LikeStr()+" ?)")
@@ -156,6 +170,10 @@ func (ss *SQLStore) SearchOrgUsers(query *models.SearchOrgUsersQuery) error {
 	whereConditions = append(whereConditions, "org_user.org_id = ?")
 	whereParams = append(whereParams, query.OrgID)
 
+	// The service accounts aren't users of the org.
+	whereConditions = append(whereConditions, "is_service_account = ?")
+	whereParams = append(whereParams, dialect.BooleanStr(false))
+
 	if query.Query != "" {
 		queryWithWildcards := "%" + query.Query + "%"
 		whereConditions = append(whereConditions, "(email "+dialect.LikeStr()+" ? OR name "+dialect.LikeStr()+" ? OR login "+dialect.LikeStr()+" ?)")
@@ -189,6 +207,7 @@ func (ss *SQLStore) SearchOrgUsers(query *models.SearchOrgUsersQuery) error {
 	// get total count
 	orgUser := models.OrgUser{}
 	countSess := x.Table("org_user")
+	countSess.Join("INNER", x.Dialect().Quote("user"), fmt.Sprintf("org_user.user_id=%s.id", x.Dialect().Quote("user")))
 
 	if len(whereConditions) > 0 {
 		countSess.Where(strings.Join(whereConditions, " AND "), whereParams...)
@@ -88,14 +88,15 @@ func SavePreferences(cmd *models.SavePreferencesCommand) error {
 
 		if !exists {
 			prefs = models.Preferences{
-				UserId:          cmd.UserId,
-				OrgId:           cmd.OrgId,
-				TeamId:          cmd.TeamId,
-				HomeDashboardId: cmd.HomeDashboardId,
-				Timezone:        cmd.Timezone,
-				Theme:           cmd.Theme,
-				Created:         time.Now(),
-				Updated:         time.Now(),
+				UserId:               cmd.UserId,
+				OrgId:                cmd.OrgId,
+				TeamId:               cmd.TeamId,
+				HomeDashboardId:      cmd.HomeDashboardId,
+				Timezone:             cmd.Timezone,
+				Theme:                cmd.Theme,
+				QueryCachingDisabled: cmd.QueryCachingDisabled,
+				Created:              time.Now(),
+				Updated:              time.Now(),
 			}
 			_, err = sess.Insert(&prefs)
 			return err
@@ -103,6 +104,7 @@ func SavePreferences(cmd *models.SavePreferencesCommand) error {
 		prefs.HomeDashboardId = cmd.HomeDashboardId
 		prefs.Timezone = cmd.Timezone
 		prefs.Theme = cmd.Theme
+		prefs.QueryCachingDisabled = cmd.QueryCachingDisabled
 		prefs.Updated = time.Now()
 		prefs.Version += 1
 		_, err = sess.ID(prefs.Id).AllCols().Update(&prefs)
@@ -113,4 +113,19 @@ func TestPreferencesDataAccess(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), query.Result.HomeDashboardId)
 	})
+	t.Run("SavePreferences should save the query caching setting of the org", func(t *testing.T) {
+		err := SavePreferences(&models.SavePreferencesCommand{OrgId: 3, QueryCachingDisabled: true})
+		require.NoError(t, err)
+
+		query := &models.GetPreferencesQuery{OrgId: 3}
+		err = GetPreferences(query)
+		require.NoError(t, err)
+		require.True(t, query.Result.QueryCachingDisabled)
+
+		err = SavePreferences(&models.SavePreferencesCommand{OrgId: 3})
+		require.NoError(t, err)
+		err = GetPreferences(query)
+		require.NoError(t, err)
+		require.False(t, query.Result.QueryCachingDisabled)
+	})
 }
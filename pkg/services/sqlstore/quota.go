@@ -10,8 +10,9 @@ import (
 )
 
 const (
-	alertRuleTarget = "alert_rule"
-	dashboardTarget = "dashboard"
+	alertRuleTarget     = "alert_rule"
+	alertInstanceTarget = "alert_instance"
+	dashboardTarget     = "dashboard"
 )
 
 func init() {
@@ -28,6 +29,19 @@ type targetCount struct {
 	Count int64
 }
 
+// isNgAlertTarget returns true if the target is a table of the new alerting, which only exists when it's enabled.
+func isNgAlertTarget(target string) bool {
+	return target == alertRuleTarget || target == alertInstanceTarget
+}
+
+// orgIDColumn returns the column of the table of the target which has the org id.
+func orgIDColumn(target string) string {
+	if target == alertInstanceTarget {
+		return "rule_org_id"
+	}
+	return "org_id"
+}
+
 func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 	quota := models.Quota{
 		Target: query.Target,
@@ -41,10 +55,10 @@ func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 	}
 
 	var used int64
-	if query.Target != alertRuleTarget || query.IsNgAlertEnabled {
+	if !isNgAlertTarget(query.Target) || query.IsNgAlertEnabled {
 		// get quota used.
-		rawSQL := fmt.Sprintf("SELECT COUNT(*) AS count FROM %s WHERE org_id=?",
-			dialect.Quote(query.Target))
+		rawSQL := fmt.Sprintf("SELECT COUNT(*) AS count FROM %s WHERE %s=?",
+			dialect.Quote(query.Target), orgIDColumn(query.Target))
 
 		if query.Target == dashboardTarget {
 			rawSQL += fmt.Sprintf(" AND is_folder=%s", dialect.BooleanStr(false))
@@ -94,9 +108,9 @@ func GetOrgQuotas(query *models.GetOrgQuotasQuery) error {
 	result := make([]*models.OrgQuotaDTO, len(quotas))
 	for i, q := range quotas {
 		var used int64
-		if q.Target != alertRuleTarget || query.IsNgAlertEnabled {
+		if !isNgAlertTarget(q.Target) || query.IsNgAlertEnabled {
 			// get quota used.
-			rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where org_id=?", dialect.Quote(q.Target))
+			rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where %s=?", dialect.Quote(q.Target), orgIDColumn(q.Target))
 			resp := make([]*targetCount, 0)
 			if err := x.SQL(rawSQL, q.OrgId).Find(&resp); err != nil {
 				return err
@@ -158,7 +172,7 @@ func GetUserQuotaByTarget(query *models.GetUserQuotaByTargetQuery) error {
 	}
 
 	var used int64
-	if query.Target != alertRuleTarget || query.IsNgAlertEnabled {
+	if !isNgAlertTarget(query.Target) || query.IsNgAlertEnabled {
 		// get quota used.
 		rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where user_id=?", dialect.Quote(query.Target))
 		resp := make([]*targetCount, 0)
@@ -205,7 +219,7 @@ func GetUserQuotas(query *models.GetUserQuotasQuery) error {
 	result := make([]*models.UserQuotaDTO, len(quotas))
 	for i, q := range quotas {
 		var used int64
-		if q.Target != alertRuleTarget || query.IsNgAlertEnabled {
+		if !isNgAlertTarget(q.Target) || query.IsNgAlertEnabled {
 			// get quota used.
 			rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where user_id=?", dialect.Quote(q.Target))
 			resp := make([]*targetCount, 0)
@@ -258,7 +272,7 @@ func UpdateUserQuota(cmd *models.UpdateUserQuotaCmd) error {
 
 func GetGlobalQuotaByTarget(query *models.GetGlobalQuotaByTargetQuery) error {
 	var used int64
-	if query.Target != alertRuleTarget || query.IsNgAlertEnabled {
+	if !isNgAlertTarget(query.Target) || query.IsNgAlertEnabled {
 		// get quota used.
 		rawSQL := fmt.Sprintf("SELECT COUNT(*) AS count FROM %s",
 			dialect.Quote(query.Target))
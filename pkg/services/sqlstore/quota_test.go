@@ -19,23 +19,25 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 	setting.Quota = setting.QuotaSettings{
 		Enabled: true,
 		Org: &setting.OrgQuota{
-			User:       5,
-			Dashboard:  5,
-			DataSource: 5,
-			ApiKey:     5,
-			AlertRule:  5,
+			User:          5,
+			Dashboard:     5,
+			DataSource:    5,
+			ApiKey:        5,
+			AlertRule:     5,
+			AlertInstance: 5,
 		},
 		User: &setting.UserQuota{
 			Org: 5,
 		},
 		Global: &setting.GlobalQuota{
-			Org:        5,
-			User:       5,
-			Dashboard:  5,
-			DataSource: 5,
-			ApiKey:     5,
-			Session:    5,
-			AlertRule:  5,
+			Org:           5,
+			User:          5,
+			Dashboard:     5,
+			DataSource:    5,
+			ApiKey:        5,
+			Session:       5,
+			AlertRule:     5,
+			AlertInstance: 5,
 		},
 	}
 
@@ -105,7 +107,7 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 			err = GetOrgQuotas(&query)
 
 			require.NoError(t, err)
-			require.Len(t, query.Result, 5)
+			require.Len(t, query.Result, 6)
 			for _, res := range query.Result {
 				limit := int64(5) // default quota limit
 				used := int64(0)
@@ -217,6 +219,15 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 		require.Equal(t, int64(0), query.Result.Used)
 	})
 
+	t.Run("Should be able to get zero used global alert instance quota when table does not exist (ngalert is not enabled - default case)", func(t *testing.T) {
+		query := models.GetGlobalQuotaByTargetQuery{Target: "alert_instance", Default: 5}
+		err = GetGlobalQuotaByTarget(&query)
+		require.NoError(t, err)
+
+		require.Equal(t, int64(5), query.Result.Limit)
+		require.Equal(t, int64(0), query.Result.Used)
+	})
+
 	t.Run("Should be able to global dashboard quota", func(t *testing.T) {
 		query := models.GetGlobalQuotaByTargetQuery{Target: dashboardTarget, Default: 5}
 		err = GetGlobalQuotaByTarget(&query)
@@ -0,0 +1,137 @@
+package sqlstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type searchSortKind int
+
+const (
+	searchSortString searchSortKind = iota
+	searchSortInt
+	searchSortTime
+)
+
+// searchSortColumn is the column of a field the results of a search can be sorted by.
+type searchSortColumn struct {
+	column string
+	kind   searchSortKind
+}
+
+// searchCursor is the position of the last result of a page, in the sort of the search. It's encoded as an opaque
+// string for the clients.
+type searchCursor struct {
+	Sort  string `json:"sort"`
+	Value string `json:"value"`
+	Id    int64  `json:"id"`
+}
+
+// searchPaging is the keyset pagination of a search: its results are ordered by the sort column and the id, and the
+// results of the next page are the ones after the cursor in that order. Unlike offsets, the cursors don't get slower
+// for the last pages, and don't skip or repeat results when rows are added or deleted between the pages.
+type searchPaging struct {
+	sort     models.SearchSort
+	column   searchSortColumn
+	idColumn string
+	cursor   *searchCursor
+}
+
+func newSearchPaging(sort, defaultSort models.SearchSort, columns map[string]searchSortColumn, idColumn, cursor string) (*searchPaging, error) {
+	if sort.Field == "" {
+		sort = defaultSort
+	}
+	column, ok := columns[sort.Field]
+	if !ok {
+		return nil, models.ErrInvalidSearchSort
+	}
+	paging := &searchPaging{sort: sort, column: column, idColumn: idColumn}
+
+	if cursor == "" {
+		return paging, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, models.ErrInvalidSearchCursor
+	}
+	paging.cursor = &searchCursor{}
+	if err := json.Unmarshal(data, paging.cursor); err != nil || paging.cursor.Sort != sort.String() {
+		return nil, models.ErrInvalidSearchCursor
+	}
+	return paging, nil
+}
+
+func (p *searchPaging) orderBy() string {
+	direction := "ASC"
+	if p.sort.Descending {
+		direction = "DESC"
+	}
+	if p.column.column == p.idColumn {
+		return p.idColumn + " " + direction
+	}
+	return fmt.Sprintf("%s %s, %s %s", p.column.column, direction, p.idColumn, direction)
+}
+
+// condition returns the condition of the results after the cursor with its params, or an empty string without a
+// cursor.
+func (p *searchPaging) condition() (string, []interface{}, error) {
+	if p.cursor == nil {
+		return "", nil, nil
+	}
+
+	operator := ">"
+	if p.sort.Descending {
+		operator = "<"
+	}
+	if p.column.column == p.idColumn {
+		return fmt.Sprintf("%s %s ?", p.idColumn, operator), []interface{}{p.cursor.Id}, nil
+	}
+
+	var value interface{} = p.cursor.Value
+	switch p.column.kind {
+	case searchSortInt:
+		v, err := strconv.ParseInt(p.cursor.Value, 10, 64)
+		if err != nil {
+			return "", nil, models.ErrInvalidSearchCursor
+		}
+		value = v
+	case searchSortTime:
+		v, err := time.Parse(time.RFC3339Nano, p.cursor.Value)
+		if err != nil {
+			return "", nil, models.ErrInvalidSearchCursor
+		}
+		value = v
+	}
+	condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))",
+		p.column.column, operator, p.column.column, p.idColumn, operator)
+	return condition, []interface{}{value, value, p.cursor.Id}, nil
+}
+
+// offset returns the offset of the page, the pages after a cursor start at it.
+func (p *searchPaging) offset(page, limit int) int {
+	if p.cursor != nil || page < 1 {
+		return 0
+	}
+	return limit * (page - 1)
+}
+
+// nextCursor returns the cursor of the page after the result with the sort value and the id.
+func (p *searchPaging) nextCursor(value interface{}, id int64) string {
+	cursor := searchCursor{Sort: p.sort.String(), Id: id}
+	switch v := value.(type) {
+	case string:
+		cursor.Value = v
+	case int64:
+		cursor.Value = strconv.FormatInt(v, 10)
+	case time.Time:
+		cursor.Value = v.Format(time.RFC3339Nano)
+	}
+	// the cursor only has strings and numbers, which can always be encoded
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,143 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchPaging(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	users := make([]*models.User, 5)
+	for i := range users {
+		user, err := ss.CreateUser(ctx, models.CreateUserCommand{
+			Login: fmt.Sprintf("user%d", i),
+			Email: fmt.Sprintf("user%d@test.com", 4-i),
+		})
+		require.NoError(t, err)
+		// the users with a lower index have been seen more recently, and user3 and user4 at the same time
+		lastSeenAt := now.Add(-time.Duration(i) * time.Hour)
+		if i == 4 {
+			lastSeenAt = now.Add(-3 * time.Hour)
+		}
+		_, err = x.Exec("UPDATE "+dialect.Quote("user")+" SET last_seen_at = ? WHERE id = ?", lastSeenAt, user.Id)
+		require.NoError(t, err)
+		users[i] = user
+	}
+
+	searchUsers := func(t *testing.T, query models.SearchUsersQuery) []string {
+		var logins []string
+		for {
+			require.NoError(t, SearchUsers(&query))
+			for _, user := range query.Result.Users {
+				logins = append(logins, user.Login)
+			}
+			if query.Result.NextCursor == "" {
+				return logins
+			}
+			query.Cursor = query.Result.NextCursor
+		}
+	}
+
+	t.Run("Users are paginated with cursors", func(t *testing.T) {
+		logins := searchUsers(t, models.SearchUsersQuery{Limit: 2})
+		require.Equal(t, []string{"user0", "user1", "user2", "user3", "user4"}, logins)
+
+		logins = searchUsers(t, models.SearchUsersQuery{Limit: 2, Sort: models.SearchSort{Field: "email"}})
+		require.Equal(t, []string{"user4", "user3", "user2", "user1", "user0"}, logins)
+
+		logins = searchUsers(t, models.SearchUsersQuery{Limit: 2, Sort: models.SearchSort{Field: "lastSeenAt", Descending: true}})
+		require.Equal(t, []string{"user0", "user1", "user2", "user4", "user3"}, logins)
+	})
+
+	t.Run("Last page has no cursor", func(t *testing.T) {
+		query := models.SearchUsersQuery{Limit: 5, Page: 1}
+		require.NoError(t, SearchUsers(&query))
+		require.Len(t, query.Result.Users, 5)
+		require.Empty(t, query.Result.NextCursor)
+		require.Equal(t, int64(5), query.Result.TotalCount)
+	})
+
+	t.Run("Cursors are of a sort", func(t *testing.T) {
+		query := models.SearchUsersQuery{Limit: 2}
+		require.NoError(t, SearchUsers(&query))
+
+		query = models.SearchUsersQuery{Limit: 2, Sort: models.SearchSort{Field: "email"}, Cursor: query.Result.NextCursor}
+		require.Equal(t, models.ErrInvalidSearchCursor, SearchUsers(&query))
+
+		query = models.SearchUsersQuery{Limit: 2, Cursor: "invalid"}
+		require.Equal(t, models.ErrInvalidSearchCursor, SearchUsers(&query))
+
+		query = models.SearchUsersQuery{Limit: 2, Sort: models.SearchSort{Field: "password"}}
+		require.Equal(t, models.ErrInvalidSearchSort, SearchUsers(&query))
+	})
+
+	t.Run("Users can be filtered", func(t *testing.T) {
+		require.Equal(t, []string{"user1"}, searchUsers(t, models.SearchUsersQuery{Login: "user1"}))
+		require.Equal(t, []string{"user1"}, searchUsers(t, models.SearchUsersQuery{Email: "user3@test.com"}))
+
+		logins := searchUsers(t, models.SearchUsersQuery{LastSeenAfter: now.Add(-90 * time.Minute)})
+		require.Equal(t, []string{"user0", "user1"}, logins)
+		logins = searchUsers(t, models.SearchUsersQuery{LastSeenBefore: now.Add(-150 * time.Minute)})
+		require.Equal(t, []string{"user3", "user4"}, logins)
+
+		orgID := users[0].OrgId
+		require.NoError(t, AddOrgUser(&models.AddOrgUserCommand{OrgId: orgID, UserId: users[1].Id, Role: models.ROLE_VIEWER}))
+		require.NoError(t, AddOrgUser(&models.AddOrgUserCommand{OrgId: orgID, UserId: users[2].Id, Role: models.ROLE_VIEWER}))
+		logins = searchUsers(t, models.SearchUsersQuery{Role: models.ROLE_VIEWER, RoleOrgId: orgID})
+		require.Equal(t, []string{"user1", "user2"}, logins)
+		logins = searchUsers(t, models.SearchUsersQuery{Role: models.ROLE_ADMIN, RoleOrgId: orgID})
+		require.Equal(t, []string{"user0"}, logins)
+	})
+
+	t.Run("Orgs are paginated with cursors", func(t *testing.T) {
+		query := models.SearchOrgsQuery{Limit: 2, Sort: models.SearchSort{Field: "name", Descending: true}}
+		var names []string
+		for {
+			require.NoError(t, SearchOrgs(&query))
+			require.Equal(t, int64(5), query.TotalCount)
+			for _, org := range query.Result {
+				names = append(names, org.Name)
+			}
+			if query.NextCursor == "" {
+				break
+			}
+			query.Cursor = query.NextCursor
+		}
+		require.Equal(t, []string{"user4@test.com", "user3@test.com", "user2@test.com", "user1@test.com", "user0@test.com"}, names)
+	})
+
+	t.Run("Teams are paginated with cursors", func(t *testing.T) {
+		orgID := users[0].OrgId
+		for i := 0; i < 3; i++ {
+			_, err := ss.CreateTeam(fmt.Sprintf("team%d", i), fmt.Sprintf("team%d@test.com", 2-i), orgID)
+			require.NoError(t, err)
+		}
+		_, err := ss.CreateTeam("other", "", users[1].OrgId)
+		require.NoError(t, err)
+
+		query := models.SearchTeamsQuery{OrgId: orgID, Limit: 2, Page: 1, Sort: models.SearchSort{Field: "email"}}
+		var names []string
+		for {
+			require.NoError(t, SearchTeams(&query))
+			require.Equal(t, int64(3), query.Result.TotalCount)
+			for _, team := range query.Result.Teams {
+				names = append(names, team.Name)
+			}
+			if query.Result.NextCursor == "" {
+				break
+			}
+			query.Cursor = query.Result.NextCursor
+		}
+		require.Equal(t, []string{"team2", "team1", "team0"}, names)
+	})
+}
@@ -0,0 +1,202 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// serviceAccountLogin returns the login of the service account of an org with the name, which is unique as the
+// names of the service accounts of an org are.
+func serviceAccountLogin(orgID int64, name string) string {
+	return fmt.Sprintf("sa-%d-%s", orgID, models.SlugifyTitle(name))
+}
+
+// CreateServiceAccount creates a service account with its role in the org.
+func (ss *SQLStore) CreateServiceAccount(ctx context.Context, cmd *models.CreateServiceAccountCommand) (*models.ServiceAccount, error) {
+	login := serviceAccountLogin(cmd.OrgId, cmd.Name)
+	var serviceAccount *models.ServiceAccount
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		exists, err := sess.Where("login=? OR email=?", login, login).Get(&models.User{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return models.ErrServiceAccountAlreadyExists
+		}
+
+		now := timeNow()
+		user := models.User{
+			Email:            login,
+			Name:             cmd.Name,
+			Login:            login,
+			OrgId:            cmd.OrgId,
+			IsServiceAccount: true,
+			Created:          now,
+			Updated:          now,
+			LastSeenAt:       now.AddDate(-10, 0, 0),
+		}
+		if user.Salt, err = util.GetRandomString(10); err != nil {
+			return err
+		}
+		if user.Rands, err = util.GetRandomString(10); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&user); err != nil {
+			return err
+		}
+
+		orgUser := models.OrgUser{
+			OrgId:   cmd.OrgId,
+			UserId:  user.Id,
+			Role:    cmd.Role,
+			Created: now,
+			Updated: now,
+		}
+		if _, err := sess.Insert(&orgUser); err != nil {
+			return err
+		}
+
+		serviceAccount = &models.ServiceAccount{
+			Id:      user.Id,
+			OrgId:   cmd.OrgId,
+			Name:    user.Name,
+			Login:   user.Login,
+			Role:    cmd.Role,
+			Created: now,
+			Updated: now,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceAccount, nil
+}
+
+const serviceAccountColumns = `u.id AS id, org_user.org_id AS org_id, u.name AS name, u.login AS login,
+	org_user.role AS role, u.is_disabled AS is_disabled, u.created AS created, u.updated AS updated`
+
+// GetServiceAccounts returns the service accounts of the org with the number of their tokens.
+func (ss *SQLStore) GetServiceAccounts(ctx context.Context, orgID int64) ([]*models.ServiceAccountDTO, error) {
+	serviceAccounts := make([]*models.ServiceAccountDTO, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		rawSQL := `SELECT ` + serviceAccountColumns + `,
+			(SELECT COUNT(*) FROM api_key WHERE api_key.service_account_id = u.id) AS tokens
+			FROM ` + dialect.Quote("user") + ` AS u
+			INNER JOIN org_user ON org_user.user_id = u.id AND org_user.org_id = ?
+			WHERE u.is_service_account = ?
+			ORDER BY u.name`
+		return sess.SQL(rawSQL, orgID, dialect.BooleanStr(true)).Find(&serviceAccounts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceAccounts, nil
+}
+
+// GetServiceAccount returns the service account of the org.
+func (ss *SQLStore) GetServiceAccount(ctx context.Context, orgID, id int64) (*models.ServiceAccount, error) {
+	var serviceAccount models.ServiceAccount
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return getServiceAccount(sess, orgID, id, &serviceAccount)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceAccount, nil
+}
+
+func getServiceAccount(sess *DBSession, orgID, id int64, serviceAccount *models.ServiceAccount) error {
+	rawSQL := `SELECT ` + serviceAccountColumns + `
+		FROM ` + dialect.Quote("user") + ` AS u
+		INNER JOIN org_user ON org_user.user_id = u.id AND org_user.org_id = ?
+		WHERE u.id = ? AND u.is_service_account = ?`
+	exists, err := sess.SQL(rawSQL, orgID, id, dialect.BooleanStr(true)).Get(serviceAccount)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+// UpdateServiceAccount updates the name, the role, or the disabled state of the service account.
+func (ss *SQLStore) UpdateServiceAccount(ctx context.Context, cmd *models.UpdateServiceAccountCommand) (*models.ServiceAccount, error) {
+	var serviceAccount models.ServiceAccount
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if err := getServiceAccount(sess, cmd.OrgId, cmd.Id, &serviceAccount); err != nil {
+			return err
+		}
+
+		now := timeNow()
+		user := models.User{Updated: now}
+		cols := []string{"updated"}
+		if cmd.Name != nil && *cmd.Name != serviceAccount.Name {
+			login := serviceAccountLogin(cmd.OrgId, *cmd.Name)
+			exists, err := sess.Where("id<>? AND (login=? OR email=?)", cmd.Id, login, login).Get(&models.User{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return models.ErrServiceAccountAlreadyExists
+			}
+			user.Name, user.Login, user.Email = *cmd.Name, login, login
+			cols = append(cols, "name", "login", "email")
+			serviceAccount.Name, serviceAccount.Login = user.Name, user.Login
+		}
+		if cmd.IsDisabled != nil {
+			user.IsDisabled = *cmd.IsDisabled
+			cols = append(cols, "is_disabled")
+			serviceAccount.IsDisabled = user.IsDisabled
+		}
+		if _, err := sess.ID(cmd.Id).Cols(cols...).Update(&user); err != nil {
+			return err
+		}
+
+		if cmd.Role != nil {
+			rawSQL := "UPDATE org_user SET role=?, updated=? WHERE org_id=? AND user_id=?"
+			if _, err := sess.Exec(rawSQL, string(*cmd.Role), now, cmd.OrgId, cmd.Id); err != nil {
+				return err
+			}
+			serviceAccount.Role = *cmd.Role
+		}
+		serviceAccount.Updated = now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceAccount, nil
+}
+
+// DeleteServiceAccount deletes the service account and its tokens.
+func (ss *SQLStore) DeleteServiceAccount(ctx context.Context, orgID, id int64) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var serviceAccount models.ServiceAccount
+		if err := getServiceAccount(sess, orgID, id, &serviceAccount); err != nil {
+			return err
+		}
+		return deleteUserInTransaction(sess, &models.DeleteUserCommand{UserId: id})
+	})
+}
+
+// GetServiceAccountTokens returns the tokens of the service account, expired or not.
+func (ss *SQLStore) GetServiceAccountTokens(ctx context.Context, orgID, serviceAccountID int64) ([]*models.ApiKey, error) {
+	tokens := make([]*models.ApiKey, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Where("org_id=? AND service_account_id=?", orgID, serviceAccountID).Asc("name").Find(&tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,115 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAccountDataAccess(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	serviceAccount, err := ss.CreateServiceAccount(ctx, &models.CreateServiceAccountCommand{
+		Name: "CI deploy", Role: models.ROLE_EDITOR, OrgId: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "sa-1-ci-deploy", serviceAccount.Login)
+
+	t.Run("Names are unique within an org", func(t *testing.T) {
+		_, err := ss.CreateServiceAccount(ctx, &models.CreateServiceAccountCommand{
+			Name: "CI deploy", Role: models.ROLE_VIEWER, OrgId: 1,
+		})
+		require.Equal(t, models.ErrServiceAccountAlreadyExists, err)
+	})
+
+	t.Run("Service accounts are signed in with their role", func(t *testing.T) {
+		query := models.GetSignedInUserQuery{UserId: serviceAccount.Id, OrgId: 1}
+		require.NoError(t, GetSignedInUser(ctx, &query))
+		require.True(t, query.Result.IsServiceAccount)
+		require.Equal(t, models.ROLE_EDITOR, query.Result.OrgRole)
+	})
+
+	t.Run("Service accounts aren't users of the org", func(t *testing.T) {
+		orgUsers := models.GetOrgUsersQuery{OrgId: 1}
+		require.NoError(t, GetOrgUsers(&orgUsers))
+		require.Empty(t, orgUsers.Result)
+
+		users := models.SearchUsersQuery{}
+		require.NoError(t, SearchUsers(&users))
+		require.Empty(t, users.Result.Users)
+	})
+
+	t.Run("Service accounts can be updated", func(t *testing.T) {
+		name, role, disabled := "CI release", models.ROLE_ADMIN, true
+		updated, err := ss.UpdateServiceAccount(ctx, &models.UpdateServiceAccountCommand{
+			Id: serviceAccount.Id, OrgId: 1, Name: &name, Role: &role, IsDisabled: &disabled,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "sa-1-ci-release", updated.Login)
+
+		found, err := ss.GetServiceAccount(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		require.Equal(t, name, found.Name)
+		require.Equal(t, role, found.Role)
+		require.True(t, found.IsDisabled)
+
+		_, err = ss.GetServiceAccount(ctx, 2, serviceAccount.Id)
+		require.Equal(t, models.ErrServiceAccountNotFound, err)
+	})
+
+	t.Run("Service account tokens aren't API keys of the org", func(t *testing.T) {
+		addCmd := models.AddApiKeyCommand{
+			OrgId: 1, Name: "ci-token", Key: "hashed", Role: models.ROLE_EDITOR, ServiceAccountId: &serviceAccount.Id,
+		}
+		require.NoError(t, AddApiKey(&addCmd))
+
+		apiKeys := models.GetApiKeysQuery{OrgId: 1}
+		require.NoError(t, GetApiKeys(&apiKeys))
+		require.Empty(t, apiKeys.Result)
+
+		tokens, err := ss.GetServiceAccountTokens(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.Nil(t, tokens[0].LastUsedAt)
+
+		serviceAccounts, err := ss.GetServiceAccounts(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, serviceAccounts, 1)
+		require.Equal(t, int64(1), serviceAccounts[0].Tokens)
+
+		t.Run("and their use is tracked", func(t *testing.T) {
+			require.NoError(t, UpdateApiKeyLastUsed(ctx, &models.UpdateApiKeyLastUsedCommand{Id: addCmd.Result.Id}))
+			tokens, err := ss.GetServiceAccountTokens(ctx, 1, serviceAccount.Id)
+			require.NoError(t, err)
+			require.NotNil(t, tokens[0].LastUsedAt)
+		})
+
+		t.Run("and they can be rotated", func(t *testing.T) {
+			rotateCmd := models.RotateApiKeyCommand{Id: addCmd.Result.Id, OrgId: 1, Key: "rotated", SecondsToLive: 60}
+			require.NoError(t, RotateApiKey(ctx, &rotateCmd))
+			require.Equal(t, "rotated", rotateCmd.Result.Key)
+			require.NotNil(t, rotateCmd.Result.Expires)
+			require.Nil(t, rotateCmd.Result.LastUsedAt)
+
+			query := models.GetApiKeyByIdQuery{ApiKeyId: addCmd.Result.Id}
+			require.NoError(t, GetApiKeyById(&query))
+			require.Equal(t, "rotated", query.Result.Key)
+			require.Nil(t, query.Result.LastUsedAt)
+		})
+	})
+
+	t.Run("Deleting a service account deletes its tokens", func(t *testing.T) {
+		require.NoError(t, ss.DeleteServiceAccount(ctx, 1, serviceAccount.Id))
+
+		tokens, err := ss.GetServiceAccountTokens(ctx, 1, serviceAccount.Id)
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+
+		require.Equal(t, models.ErrServiceAccountNotFound, ss.DeleteServiceAccount(ctx, 1, serviceAccount.Id))
+	})
+}
@@ -136,6 +136,7 @@ func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
+			"DELETE FROM data_source_permission WHERE org_id=? and team_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -172,12 +173,26 @@ func isTeamNameTaken(orgId int64, name string, existingId int64, sess *DBSession
 	return false, nil
 }
 
+var teamSearchSortColumns = map[string]searchSortColumn{
+	"name":  {column: "team.name"},
+	"email": {column: "team.email"},
+}
+
 func SearchTeams(query *models.SearchTeamsQuery) error {
 	query.Result = models.SearchTeamQueryResult{
 		Teams: make([]*models.TeamDTO, 0),
 	}
 	queryWithWildcards := "%" + query.Query + "%"
 
+	paging, err := newSearchPaging(query.Sort, models.SearchSort{Field: "name"}, teamSearchSortColumns, "team.id", query.Cursor)
+	if err != nil {
+		return err
+	}
+	cursorCondition, cursorParams, err := paging.condition()
+	if err != nil {
+		return err
+	}
+
 	var sql bytes.Buffer
 	params := make([]interface{}, 0)
 
@@ -195,43 +210,56 @@ func SearchTeams(query *models.SearchTeamsQuery) error {
 		}
 	}
 
-	sql.WriteString(` WHERE team.org_id = ?`)
-	params = append(params, query.OrgId)
+	var where bytes.Buffer
+	whereParams := make([]interface{}, 0)
+	where.WriteString(` WHERE team.org_id = ?`)
+	whereParams = append(whereParams, query.OrgId)
 
 	if query.Query != "" {
-		sql.WriteString(` and team.name ` + dialect.LikeStr() + ` ?`)
-		params = append(params, queryWithWildcards)
+		where.WriteString(` and team.name ` + dialect.LikeStr() + ` ?`)
+		whereParams = append(whereParams, queryWithWildcards)
 	}
 
 	if query.Name != "" {
-		sql.WriteString(` and team.name = ?`)
-		params = append(params, query.Name)
+		where.WriteString(` and team.name = ?`)
+		whereParams = append(whereParams, query.Name)
 	}
 
-	sql.WriteString(` order by team.name asc`)
+	sql.WriteString(where.String())
+	params = append(params, whereParams...)
+	if cursorCondition != "" {
+		sql.WriteString(` and ` + cursorCondition)
+		params = append(params, cursorParams...)
+	}
+
+	sql.WriteString(` order by ` + paging.orderBy())
 
 	if query.Limit != 0 {
-		offset := query.Limit * (query.Page - 1)
-		sql.WriteString(dialect.LimitOffset(int64(query.Limit), int64(offset)))
+		// one more team than the limit tells if there's a next page
+		sql.WriteString(dialect.LimitOffset(int64(query.Limit+1), int64(paging.offset(query.Page, query.Limit))))
 	}
 
 	if err := x.SQL(sql.String(), params...).Find(&query.Result.Teams); err != nil {
 		return err
 	}
 
-	team := models.Team{}
-	countSess := x.Table("team")
-	if query.Query != "" {
-		countSess.Where(`name `+dialect.LikeStr()+` ?`, queryWithWildcards)
+	if query.Limit > 0 && len(query.Result.Teams) > query.Limit {
+		query.Result.Teams = query.Result.Teams[:query.Limit]
+		last := query.Result.Teams[query.Limit-1]
+		value := last.Name
+		if paging.sort.Field == "email" {
+			value = last.Email
+		}
+		query.Result.NextCursor = paging.nextCursor(value, last.Id)
 	}
 
-	if query.Name != "" {
-		countSess.Where("name=?", query.Name)
+	countSQL := `SELECT COUNT(*) FROM team as team`
+	countParams := make([]interface{}, 0)
+	if query.UserIdFilter > 0 {
+		countSQL += ` INNER JOIN team_member ON team.id = team_member.team_id AND team_member.user_id = ?`
+		countParams = append(countParams, query.UserIdFilter)
 	}
-
-	count, err := countSess.Count(&team)
-	query.Result.TotalCount = count
-
+	_, err = x.SQL(countSQL+where.String(), append(countParams, whereParams...)...).Get(&query.Result.TotalCount)
 	return err
 }
 
@@ -178,6 +178,15 @@ func (ss *SQLStore) createUser(ctx context.Context, sess *DBSession, args userCr
 		if _, err = sess.Insert(&orgUser); err != nil {
 			return user, err
 		}
+
+		sess.publishAfterCommit(&events.OrgUserAdded{
+			Timestamp: orgUser.Created,
+			OrgId:     orgUser.OrgId,
+			UserId:    user.Id,
+			Login:     user.Login,
+			Email:     user.Email,
+			Role:      string(orgUser.Role),
+		})
 	}
 
 	return user, nil
@@ -272,6 +281,15 @@ func (ss *SQLStore) CreateUser(ctx context.Context, cmd models.CreateUserCommand
 			if _, err = sess.Insert(&orgUser); err != nil {
 				return err
 			}
+
+			sess.publishAfterCommit(&events.OrgUserAdded{
+				Timestamp: orgUser.Created,
+				OrgId:     orgUser.OrgId,
+				UserId:    user.Id,
+				Login:     user.Login,
+				Email:     user.Email,
+				Role:      string(orgUser.Role),
+			})
 		}
 
 		return nil
@@ -523,6 +541,8 @@ func GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) er
 		u.name           as name,
 		u.help_flags1    as help_flags1,
 		u.last_seen_at   as last_seen_at,
+		u.is_disabled    as is_disabled,
+		u.is_service_account as is_service_account,
 		(SELECT COUNT(*) FROM org_user where org_user.user_id = u.id) as org_count,
 		org.name         as org_name,
 		org_user.role    as org_role,
@@ -570,11 +590,27 @@ func GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) er
 	return err
 }
 
+var userSearchSortColumns = map[string]searchSortColumn{
+	"login":      {column: "u.login"},
+	"email":      {column: "u.email"},
+	"name":       {column: "u.name"},
+	"lastSeenAt": {column: "u.last_seen_at", kind: searchSortTime},
+}
+
 func SearchUsers(query *models.SearchUsersQuery) error {
 	query.Result = models.SearchUserQueryResult{
 		Users: make([]*models.UserSearchHitDTO, 0),
 	}
 
+	paging, err := newSearchPaging(query.Sort, models.SearchSort{Field: "login"}, userSearchSortColumns, "u.id", query.Cursor)
+	if err != nil {
+		return err
+	}
+	cursorCondition, cursorParams, err := paging.condition()
+	if err != nil {
+		return err
+	}
+
 	queryWithWildcards := "%" + query.Query + "%"
 
 	whereConditions := make([]string, 0)
@@ -589,6 +625,9 @@ func SearchUsers(query *models.SearchUsersQuery) error {
 	joinCondition = "user_auth.id=" + joinCondition + dialect.Limit(1) + ")"
 	sess.Join("LEFT", "user_auth", joinCondition)
 
+	whereConditions = append(whereConditions, "u.is_service_account = ?")
+	whereParams = append(whereParams, dialect.BooleanStr(false))
+
 	if query.OrgId > 0 {
 		whereConditions = append(whereConditions, "org_id = ?")
 		whereParams = append(whereParams, query.OrgId)
@@ -609,21 +648,54 @@ func SearchUsers(query *models.SearchUsersQuery) error {
 		whereParams = append(whereParams, query.AuthModule)
 	}
 
-	if len(whereConditions) > 0 {
+	if query.Login != "" {
+		whereConditions = append(whereConditions, "u.login = ?")
+		whereParams = append(whereParams, query.Login)
+	}
+
+	if query.Email != "" {
+		whereConditions = append(whereConditions, "u.email = ?")
+		whereParams = append(whereParams, query.Email)
+	}
+
+	if !query.LastSeenAfter.IsZero() {
+		whereConditions = append(whereConditions, "u.last_seen_at >= ?")
+		whereParams = append(whereParams, query.LastSeenAfter)
+	}
+
+	if !query.LastSeenBefore.IsZero() {
+		whereConditions = append(whereConditions, "u.last_seen_at < ?")
+		whereParams = append(whereParams, query.LastSeenBefore)
+	}
+
+	if query.Role != "" {
+		whereConditions = append(whereConditions, "EXISTS (SELECT 1 FROM org_user WHERE org_user.user_id = u.id AND org_user.org_id = ? AND org_user.role = ?)")
+		whereParams = append(whereParams, query.RoleOrgId, query.Role)
+	}
+
+	if cursorCondition != "" {
+		sess.Where(strings.Join(append(whereConditions, cursorCondition), " AND "), append(whereParams, cursorParams...)...)
+	} else if len(whereConditions) > 0 {
 		sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 	}
 
 	if query.Limit > 0 {
-		offset := query.Limit * (query.Page - 1)
-		sess.Limit(query.Limit, offset)
+		// one more user than the limit tells if there's a next page
+		sess.Limit(query.Limit+1, paging.offset(query.Page, query.Limit))
 	}
 
 	sess.Cols("u.id", "u.email", "u.name", "u.login", "u.is_admin", "u.is_disabled", "u.last_seen_at", "user_auth.auth_module")
-	sess.Asc("u.login", "u.email")
+	sess.OrderBy(paging.orderBy())
 	if err := sess.Find(&query.Result.Users); err != nil {
 		return err
 	}
 
+	if query.Limit > 0 && len(query.Result.Users) > query.Limit {
+		query.Result.Users = query.Result.Users[:query.Limit]
+		last := query.Result.Users[query.Limit-1]
+		query.Result.NextCursor = paging.nextCursor(userSearchSortValue(last, paging.sort.Field), last.Id)
+	}
+
 	// get total
 	user := models.User{}
 	countSess := x.Table("user").Alias("u")
@@ -647,6 +719,19 @@ func SearchUsers(query *models.SearchUsersQuery) error {
 	return err
 }
 
+func userSearchSortValue(user *models.UserSearchHitDTO, field string) interface{} {
+	switch field {
+	case "email":
+		return user.Email
+	case "name":
+		return user.Name
+	case "lastSeenAt":
+		return user.LastSeenAt
+	default:
+		return user.Login
+	}
+}
+
 func DisableUser(cmd *models.DisableUserCommand) error {
 	user := models.User{}
 	sess := x.Table("user")
@@ -711,11 +796,13 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM " + dialect.Quote("user") + " WHERE id = ?",
 		"DELETE FROM org_user WHERE user_id = ?",
 		"DELETE FROM dashboard_acl WHERE user_id = ?",
+		"DELETE FROM data_source_permission WHERE user_id = ?",
 		"DELETE FROM preferences WHERE user_id = ?",
 		"DELETE FROM team_member WHERE user_id = ?",
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM api_key WHERE service_account_id = ?",
 	}
 
 	for _, sql := range deletes {
@@ -180,9 +180,14 @@ func GetAuthInfo(query *models.GetAuthInfoQuery) error {
 	if err != nil {
 		return err
 	}
+	secretIdToken, err := decodeAndDecrypt(userAuth.OAuthIdToken)
+	if err != nil {
+		return err
+	}
 	userAuth.OAuthAccessToken = secretAccessToken
 	userAuth.OAuthRefreshToken = secretRefreshToken
 	userAuth.OAuthTokenType = secretTokenType
+	userAuth.OAuthIdToken = secretIdToken
 
 	query.Result = userAuth
 	return nil
@@ -211,6 +216,14 @@ func SetAuthInfo(cmd *models.SetAuthInfoCommand) error {
 				return err
 			}
 
+			if idToken, ok := cmd.OAuthToken.Extra("id_token").(string); ok && idToken != "" {
+				secretIdToken, err := encryptAndEncode(idToken)
+				if err != nil {
+					return err
+				}
+				authUser.OAuthIdToken = secretIdToken
+			}
+
 			authUser.OAuthAccessToken = secretAccessToken
 			authUser.OAuthRefreshToken = secretRefreshToken
 			authUser.OAuthTokenType = secretTokenType
@@ -245,6 +258,14 @@ func UpdateAuthInfo(cmd *models.UpdateAuthInfoCommand) error {
 				return err
 			}
 
+			if idToken, ok := cmd.OAuthToken.Extra("id_token").(string); ok && idToken != "" {
+				secretIdToken, err := encryptAndEncode(idToken)
+				if err != nil {
+					return err
+				}
+				authUser.OAuthIdToken = secretIdToken
+			}
+
 			authUser.OAuthAccessToken = secretAccessToken
 			authUser.OAuthRefreshToken = secretRefreshToken
 			authUser.OAuthTokenType = secretTokenType
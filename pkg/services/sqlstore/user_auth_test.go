@@ -125,6 +125,7 @@ func TestUserAuth(t *testing.T) {
 				Expiry:       time.Now(),
 				TokenType:    "Bearer",
 			}
+			token = token.WithExtra(map[string]interface{}{"id_token": "testidtoken"})
 
 			// Find a user to set tokens on
 			login := "loginuser0"
@@ -156,6 +157,7 @@ func TestUserAuth(t *testing.T) {
 			require.Equal(t, getAuthQuery.Result.OAuthAccessToken, token.AccessToken)
 			require.Equal(t, getAuthQuery.Result.OAuthRefreshToken, token.RefreshToken)
 			require.Equal(t, getAuthQuery.Result.OAuthTokenType, token.TokenType)
+			require.Equal(t, getAuthQuery.Result.OAuthIdToken, "testidtoken")
 		})
 
 		t.Run("Always return the most recently used auth_module", func(t *testing.T) {
@@ -0,0 +1,260 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// CreateWebhook creates the webhook with its secret encrypted.
+func (ss *SQLStore) CreateWebhook(ctx context.Context, cmd *models.CreateWebhookCommand) (*models.Webhook, error) {
+	now := timeNow()
+	webhook := &models.Webhook{
+		OrgId:          cmd.OrgId,
+		Name:           cmd.Name,
+		Url:            cmd.Url,
+		Events:         cmd.Events,
+		SecureSettings: securejsondata.GetEncryptedJsonData(map[string]string{"secret": cmd.Secret}),
+		IsEnabled:      cmd.IsEnabled == nil || *cmd.IsEnabled,
+		Created:        now,
+		Updated:        now,
+	}
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.UseBool("is_enabled").Insert(webhook)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetWebhooks returns the webhooks of the org.
+func (ss *SQLStore) GetWebhooks(ctx context.Context, orgID int64) ([]*models.Webhook, error) {
+	webhooks := make([]*models.Webhook, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Where("org_id=?", orgID).Asc("name").Asc("id").Find(&webhooks)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook returns the webhook of the org.
+func (ss *SQLStore) GetWebhook(ctx context.Context, orgID, id int64) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return getWebhook(sess, orgID, id, &webhook)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func getWebhook(sess *DBSession, orgID, id int64, webhook *models.Webhook) error {
+	exists, err := sess.Where("org_id=? AND id=?", orgID, id).Get(webhook)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// UpdateWebhook updates the fields of the webhook which are set.
+func (ss *SQLStore) UpdateWebhook(ctx context.Context, cmd *models.UpdateWebhookCommand) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if err := getWebhook(sess, cmd.OrgId, cmd.Id, &webhook); err != nil {
+			return err
+		}
+
+		if cmd.Name != nil {
+			webhook.Name = *cmd.Name
+		}
+		if cmd.Url != nil {
+			webhook.Url = *cmd.Url
+		}
+		if cmd.Events != nil {
+			webhook.Events = cmd.Events
+		}
+		if cmd.Secret != nil {
+			webhook.SecureSettings = securejsondata.GetEncryptedJsonData(map[string]string{"secret": *cmd.Secret})
+		}
+		if cmd.IsEnabled != nil {
+			webhook.IsEnabled = *cmd.IsEnabled
+		}
+		webhook.Updated = timeNow()
+
+		_, err := sess.ID(webhook.Id).AllCols().Update(&webhook)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// DeleteWebhook deletes the webhook and its deliveries.
+func (ss *SQLStore) DeleteWebhook(ctx context.Context, orgID, id int64) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var webhook models.Webhook
+		if err := getWebhook(sess, orgID, id, &webhook); err != nil {
+			return err
+		}
+		if _, err := sess.Exec("DELETE FROM webhook_delivery WHERE webhook_id=?", id); err != nil {
+			return err
+		}
+		_, err := sess.ID(id).Delete(&models.Webhook{})
+		return err
+	})
+}
+
+// AddWebhookDeliveries stores the deliveries.
+func (ss *SQLStore) AddWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		for _, delivery := range deliveries {
+			if _, err := sess.Insert(delivery); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetDueWebhookDeliveries returns the pending deliveries whose next attempt is due, the oldest first.
+func (ss *SQLStore) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	deliveries := make([]*models.WebhookDelivery, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Where("state=? AND next_attempt_at<=?", models.WebhookDeliveryPending, now).
+			Asc("next_attempt_at").Asc("id").Limit(limit).Find(&deliveries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery postpones the next attempt of the delivery to until if it's still due at now, and returns
+// whether it did. Only the instance which claimed a delivery attempts it.
+func (ss *SQLStore) ClaimWebhookDelivery(ctx context.Context, id int64, now, until time.Time) (bool, error) {
+	var claimed bool
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		rawSQL := "UPDATE webhook_delivery SET next_attempt_at=? WHERE id=? AND state=? AND next_attempt_at<=?"
+		result, err := sess.Exec(rawSQL, until, id, models.WebhookDeliveryPending, now)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		claimed = affected == 1
+		return nil
+	})
+	return claimed, err
+}
+
+// UpdateWebhookDelivery stores the state of the delivery and the result of its last attempt.
+func (ss *SQLStore) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.ID(delivery.Id).
+			Cols("state", "attempts", "next_attempt_at", "last_status_code", "last_error", "last_duration_ms", "updated").
+			Update(delivery)
+		return err
+	})
+}
+
+// SearchWebhookDeliveries returns a page of the deliveries of the webhook matching the query, the most recent first.
+func (ss *SQLStore) SearchWebhookDeliveries(ctx context.Context, query *models.SearchWebhookDeliveriesQuery) (*models.SearchWebhookDeliveriesResult, error) {
+	result := &models.SearchWebhookDeliveriesResult{
+		Deliveries: []*models.WebhookDeliveryDTO{},
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		whereConditions := []string{"org_id = ?", "webhook_id = ?"}
+		whereParams := []interface{}{query.OrgId, query.WebhookId}
+		if query.State != "" {
+			whereConditions = append(whereConditions, "state = ?")
+			whereParams = append(whereParams, query.State)
+		}
+		if query.EventType != "" {
+			whereConditions = append(whereConditions, "event_type = ?")
+			whereParams = append(whereParams, query.EventType)
+		}
+		where := strings.Join(whereConditions, " AND ")
+
+		deliveries := make([]*models.WebhookDelivery, 0)
+		offset := query.PerPage * (query.Page - 1)
+		err := sess.Where(where, whereParams...).Desc("created").Desc("id").Limit(query.PerPage, offset).Find(&deliveries)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			result.Deliveries = append(result.Deliveries, delivery.ToDTO())
+		}
+
+		result.TotalCount, err = sess.Where(where, whereParams...).Count(&models.WebhookDelivery{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RedeliverWebhookDelivery makes the delivery of the webhook pending again with its attempts reset, so that it's
+// attempted right away.
+func (ss *SQLStore) RedeliverWebhookDelivery(ctx context.Context, orgID, webhookID, id int64) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		exists, err := sess.Where("org_id=? AND webhook_id=? AND id=?", orgID, webhookID, id).Get(&delivery)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrWebhookDeliveryNotFound
+		}
+
+		now := timeNow()
+		delivery.State = models.WebhookDeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = now
+		delivery.Updated = now
+		_, err = sess.ID(id).Cols("state", "attempts", "next_attempt_at", "updated").Update(&delivery)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// DeleteWebhookDeliveriesBefore deletes the deliveries which succeeded or were dead-lettered before the time, and
+// returns the number of deleted deliveries.
+func (ss *SQLStore) DeleteWebhookDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		result, err := sess.Exec("DELETE FROM webhook_delivery WHERE state<>? AND updated<?", models.WebhookDeliveryPending, before)
+		if err != nil {
+			return err
+		}
+		affected, err = result.RowsAffected()
+		return err
+	})
+	return affected, err
+}
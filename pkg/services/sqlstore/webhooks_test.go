@@ -0,0 +1,106 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDataAccess(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	webhook, err := ss.CreateWebhook(ctx, &models.CreateWebhookCommand{
+		OrgId:  1,
+		Name:   "ci",
+		Url:    "https://ci.example.com/hook",
+		Events: []models.WebhookEventType{models.WebhookEventDashboardSaved},
+		Secret: "secret",
+	})
+	require.NoError(t, err)
+
+	t.Run("Webhooks are read with their events and encrypted secret", func(t *testing.T) {
+		result, err := ss.GetWebhook(ctx, 1, webhook.Id)
+		require.NoError(t, err)
+		require.Equal(t, "ci", result.Name)
+		require.True(t, result.IsEnabled)
+		require.Equal(t, []models.WebhookEventType{models.WebhookEventDashboardSaved}, result.Events)
+		secret, ok := result.SecureSettings.DecryptedValue("secret")
+		require.True(t, ok)
+		require.Equal(t, "secret", secret)
+
+		_, err = ss.GetWebhook(ctx, 2, webhook.Id)
+		require.ErrorIs(t, err, models.ErrWebhookNotFound)
+	})
+
+	t.Run("Only the set fields are updated", func(t *testing.T) {
+		disabled := false
+		result, err := ss.UpdateWebhook(ctx, &models.UpdateWebhookCommand{OrgId: 1, Id: webhook.Id, IsEnabled: &disabled})
+		require.NoError(t, err)
+		require.False(t, result.IsEnabled)
+		require.Equal(t, "https://ci.example.com/hook", result.Url)
+
+		webhooks, err := ss.GetWebhooks(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		require.False(t, webhooks[0].IsEnabled)
+	})
+
+	t.Run("Due deliveries are claimed once", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		deliveries := []*models.WebhookDelivery{
+			{OrgId: 1, WebhookId: webhook.Id, Uid: "a", EventType: models.WebhookEventDashboardSaved, Payload: "{}", State: models.WebhookDeliveryPending, NextAttemptAt: now.Add(-time.Minute), Created: now, Updated: now},
+			{OrgId: 1, WebhookId: webhook.Id, Uid: "b", EventType: models.WebhookEventDashboardSaved, Payload: "{}", State: models.WebhookDeliveryPending, NextAttemptAt: now.Add(time.Hour), Created: now, Updated: now},
+		}
+		require.NoError(t, ss.AddWebhookDeliveries(ctx, deliveries))
+
+		due, err := ss.GetDueWebhookDeliveries(ctx, now, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, "a", due[0].Uid)
+
+		claimed, err := ss.ClaimWebhookDelivery(ctx, due[0].Id, now, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, claimed)
+		claimed, err = ss.ClaimWebhookDelivery(ctx, due[0].Id, now, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		due[0].State = models.WebhookDeliveryDeadLetter
+		due[0].Attempts = 5
+		due[0].LastStatusCode = 500
+		require.NoError(t, ss.UpdateWebhookDelivery(ctx, due[0]))
+
+		result, err := ss.SearchWebhookDeliveries(ctx, &models.SearchWebhookDeliveriesQuery{
+			OrgId: 1, WebhookId: webhook.Id, State: models.WebhookDeliveryDeadLetter, Page: 1, PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		require.Equal(t, 500, result.Deliveries[0].LastStatusCode)
+
+		redelivered, err := ss.RedeliverWebhookDelivery(ctx, 1, webhook.Id, due[0].Id)
+		require.NoError(t, err)
+		require.Equal(t, models.WebhookDeliveryPending, redelivered.State)
+		require.Equal(t, 0, redelivered.Attempts)
+
+		_, err = ss.RedeliverWebhookDelivery(ctx, 2, webhook.Id, due[0].Id)
+		require.ErrorIs(t, err, models.ErrWebhookDeliveryNotFound)
+	})
+
+	t.Run("Deleting a webhook deletes its deliveries", func(t *testing.T) {
+		require.NoError(t, ss.DeleteWebhook(ctx, 1, webhook.Id))
+		_, err := ss.GetWebhook(ctx, 1, webhook.Id)
+		require.ErrorIs(t, err, models.ErrWebhookNotFound)
+
+		result, err := ss.SearchWebhookDeliveries(ctx, &models.SearchWebhookDeliveriesQuery{
+			OrgId: 1, WebhookId: webhook.Id, Page: 1, PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), result.TotalCount)
+	})
+}
@@ -0,0 +1,350 @@
+// Package webhooks delivers the events of the orgs to the webhooks their admins registered.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// SignatureHeader is the header of the HMAC-SHA256 signature of the payload with the secret of the webhook, it's
+	// the hex encoded signature of the timestamp and of the body joined with a dot.
+	SignatureHeader = "X-Grafana-Webhook-Signature"
+	// TimestampHeader is the header of the Unix timestamp the payload was signed at.
+	TimestampHeader = "X-Grafana-Webhook-Timestamp"
+	// EventHeader is the header of the type of the event.
+	EventHeader = "X-Grafana-Webhook-Event"
+	// DeliveryHeader is the header of the UID of the delivery, which is the same for all its attempts.
+	DeliveryHeader = "X-Grafana-Webhook-Delivery"
+
+	pollInterval    = 5 * time.Second
+	cleanupInterval = time.Hour
+	// batchSize is the max number of due deliveries read at once, and maxConcurrentDeliveries the max number of
+	// them attempted at the same time.
+	batchSize               = 100
+	maxConcurrentDeliveries = 10
+	// maxErrorBodySize is the max number of bytes of the body of a failed response kept in the delivery log.
+	maxErrorBodySize = 1024
+)
+
+type WebhookService struct {
+	Cfg      *setting.Cfg       `inject:""`
+	SQLStore *sqlstore.SQLStore `inject:""`
+
+	log    log.Logger
+	client *http.Client
+	wakeup chan struct{}
+}
+
+func init() {
+	registry.RegisterService(&WebhookService{})
+}
+
+func (s *WebhookService) Init() error {
+	s.log = log.New("webhooks")
+	if !s.Cfg.Webhooks.Enabled {
+		return nil
+	}
+
+	s.client = &http.Client{
+		Timeout:   s.Cfg.Webhooks.Timeout,
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+	}
+	s.wakeup = make(chan struct{}, 1)
+
+	bus.AddEventListener(s.dashboardSaved)
+	bus.AddEventListener(s.dataSourceCreated)
+	bus.AddEventListener(s.orgUserAdded)
+	bus.AddEventListener(s.alertRuleGroupChanged)
+	return nil
+}
+
+func (s *WebhookService) IsDisabled() bool {
+	return !s.Cfg.Webhooks.Enabled
+}
+
+// Run attempts the due deliveries when events are published and periodically, and deletes the old deliveries from
+// the delivery log.
+func (s *WebhookService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-s.wakeup:
+		case <-ticker.C:
+		case <-cleanup.C:
+			s.deleteOldDeliveries(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *WebhookService) dashboardSaved(e *events.DashboardSaved) error {
+	return s.publish(models.WebhookEventDashboardSaved, e.OrgId, e.Timestamp, e)
+}
+
+func (s *WebhookService) dataSourceCreated(e *events.DataSourceCreated) error {
+	return s.publish(models.WebhookEventDataSourceCreated, e.OrgId, e.Timestamp, e)
+}
+
+func (s *WebhookService) orgUserAdded(e *events.OrgUserAdded) error {
+	return s.publish(models.WebhookEventUserAdded, e.OrgId, e.Timestamp, e)
+}
+
+func (s *WebhookService) alertRuleGroupChanged(e *events.AlertRuleGroupChanged) error {
+	return s.publish(models.WebhookEventAlertRuleChanged, e.OrgId, e.Timestamp, e)
+}
+
+// eventPayload is the body posted to the webhooks.
+type eventPayload struct {
+	Type      models.WebhookEventType `json:"type"`
+	OrgId     int64                   `json:"orgId"`
+	Timestamp time.Time               `json:"timestamp"`
+	Data      interface{}             `json:"data"`
+}
+
+// publish stores a pending delivery of the event for each of the webhooks of the org subscribed to it.
+func (s *WebhookService) publish(eventType models.WebhookEventType, orgID int64, timestamp time.Time, data interface{}) error {
+	ctx := context.Background()
+	webhooks, err := s.SQLStore.GetWebhooks(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	now := time.Now()
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(eventPayload{Type: eventType, OrgId: orgID, Timestamp: timestamp, Data: data})
+			if err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			OrgId:         orgID,
+			WebhookId:     webhook.Id,
+			Uid:           util.GenerateShortUID(),
+			EventType:     eventType,
+			Payload:       string(payload),
+			State:         models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			Created:       now,
+			Updated:       now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.SQLStore.AddWebhookDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// deliverDue attempts the due deliveries, until there are none left.
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		deliveries, err := s.SQLStore.GetDueWebhookDeliveries(ctx, now, batchSize)
+		if err != nil {
+			s.log.Error("Failed to get the due webhook deliveries", "error", err)
+			return
+		}
+
+		webhooks := newWebhookCache(s.SQLStore)
+		sem := make(chan struct{}, maxConcurrentDeliveries)
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			// the other instances skip the deliveries claimed until their attempt times out
+			claimed, err := s.SQLStore.ClaimWebhookDelivery(ctx, delivery.Id, now, now.Add(2*s.Cfg.Webhooks.Timeout))
+			if err != nil {
+				s.log.Error("Failed to claim webhook delivery", "uid", delivery.Uid, "error", err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			webhook, err := webhooks.get(ctx, delivery.OrgId, delivery.WebhookId)
+			if err != nil {
+				s.log.Error("Failed to get the webhook of the delivery", "uid", delivery.Uid, "error", err)
+				continue
+			}
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(delivery *models.WebhookDelivery) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				s.attempt(ctx, webhook, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+// attempt posts the payload of the delivery to the webhook, and stores the result of the attempt along with the
+// next state of the delivery.
+func (s *WebhookService) attempt(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	start := time.Now()
+	statusCode, err := s.send(ctx, webhook, delivery)
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastDurationMs = now.Sub(start).Milliseconds()
+	delivery.LastError = ""
+	delivery.Updated = now
+	switch {
+	case err == nil:
+		delivery.State = models.WebhookDeliverySucceeded
+	case delivery.Attempts >= s.Cfg.Webhooks.MaxAttempts || !webhook.IsEnabled:
+		s.log.Warn("Webhook delivery failed and is dead-lettered", "webhook", webhook.Id, "uid", delivery.Uid,
+			"attempts", delivery.Attempts, "error", err)
+		delivery.State = models.WebhookDeliveryDeadLetter
+		delivery.LastError = err.Error()
+	default:
+		s.log.Debug("Webhook delivery failed and will be retried", "webhook", webhook.Id, "uid", delivery.Uid,
+			"attempts", delivery.Attempts, "error", err)
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(retryBackoff(s.Cfg.Webhooks.RetryBackoff, delivery.Attempts))
+	}
+
+	// the result is stored even when the service is stopping, so that the delivery isn't attempted again
+	if err := s.SQLStore.UpdateWebhookDelivery(context.Background(), delivery); err != nil {
+		s.log.Error("Failed to update webhook delivery", "uid", delivery.Uid, "error", err)
+	}
+}
+
+// send posts the signed payload of the delivery to the webhook, and returns the status code of the response. It
+// returns an error unless the response has a 2xx status.
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	if !webhook.IsEnabled {
+		return 0, errors.New("the webhook is disabled")
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(DeliveryHeader, delivery.Uid)
+	if secret, ok := webhook.SecureSettings.DecryptedValue("secret"); ok && secret != "" {
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Signature(secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+}
+
+func (s *WebhookService) deleteOldDeliveries(ctx context.Context) {
+	deleted, err := s.SQLStore.DeleteWebhookDeliveriesBefore(ctx, time.Now().Add(-s.Cfg.Webhooks.DeliveryLogRetention))
+	if err != nil {
+		s.log.Error("Failed to delete old webhook deliveries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted old webhook deliveries", "deleted", deleted)
+	}
+}
+
+// Signature returns the hex encoded HMAC-SHA256 signature of the timestamp and of the body joined with a dot.
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryBackoff returns the delay before the next attempt of a delivery which failed its attempts, which doubles
+// after each failed attempt.
+func retryBackoff(backoff time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && backoff < 24*time.Hour; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
+// webhookCache gets the webhooks of a batch of deliveries once.
+type webhookCache struct {
+	store    *sqlstore.SQLStore
+	webhooks map[int64]*models.Webhook
+}
+
+func newWebhookCache(store *sqlstore.SQLStore) *webhookCache {
+	return &webhookCache{store: store, webhooks: map[int64]*models.Webhook{}}
+}
+
+func (c *webhookCache) get(ctx context.Context, orgID, id int64) (*models.Webhook, error) {
+	if webhook, ok := c.webhooks[id]; ok {
+		return webhook, nil
+	}
+	webhook, err := c.store.GetWebhook(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	c.webhooks[id] = webhook
+	return webhook, nil
+}
@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.Webhooks = setting.WebhooksSettings{
+		Enabled:      true,
+		Timeout:      time.Second,
+		MaxAttempts:  2,
+		RetryBackoff: time.Millisecond,
+	}
+	service := &WebhookService{Cfg: cfg, SQLStore: sqlStore}
+	require.NoError(t, service.Init())
+	ctx := context.Background()
+
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	var mtx sync.Mutex
+	var requests []request
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		mtx.Lock()
+		defer mtx.Unlock()
+		requests = append(requests, request{header: r.Header, body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	webhook, err := sqlStore.CreateWebhook(ctx, &models.CreateWebhookCommand{
+		OrgId:  1,
+		Name:   "ci",
+		Url:    server.URL,
+		Events: []models.WebhookEventType{models.WebhookEventDashboardSaved},
+		Secret: "secret",
+	})
+	require.NoError(t, err)
+	deliveries := func(state models.WebhookDeliveryState) []*models.WebhookDeliveryDTO {
+		result, err := sqlStore.SearchWebhookDeliveries(ctx, &models.SearchWebhookDeliveriesQuery{
+			OrgId: 1, WebhookId: webhook.Id, State: state, Page: 1, PerPage: 10,
+		})
+		require.NoError(t, err)
+		return result.Deliveries
+	}
+
+	t.Run("Subscribed events are delivered signed", func(t *testing.T) {
+		timestamp := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, service.dashboardSaved(&events.DashboardSaved{Timestamp: timestamp, OrgId: 1, Uid: "abc", Title: "Home"}))
+		require.NoError(t, service.dashboardSaved(&events.DashboardSaved{Timestamp: timestamp, OrgId: 2, Uid: "def"}))
+		require.NoError(t, service.dataSourceCreated(&events.DataSourceCreated{Timestamp: timestamp, OrgId: 1, Uid: "ds"}))
+		service.deliverDue(ctx)
+
+		require.Len(t, requests, 1)
+		req := requests[0]
+		require.JSONEq(t, `{"type": "dashboard.saved", "orgId": 1, "timestamp": "2021-06-01T12:00:00Z",
+			"data": {"timestamp": "2021-06-01T12:00:00Z", "orgId": 1, "id": 0, "uid": "abc", "title": "Home", "folderId": 0, "version": 0, "userId": 0}}`,
+			string(req.body))
+		require.Equal(t, "dashboard.saved", req.header.Get(EventHeader))
+		require.Equal(t, Signature("secret", req.header.Get(TimestampHeader), req.body), req.header.Get(SignatureHeader))
+
+		succeeded := deliveries(models.WebhookDeliverySucceeded)
+		require.Len(t, succeeded, 1)
+		require.Equal(t, succeeded[0].Uid, req.header.Get(DeliveryHeader))
+		require.Equal(t, 1, succeeded[0].Attempts)
+		require.Equal(t, http.StatusOK, succeeded[0].LastStatusCode)
+	})
+
+	t.Run("Failed deliveries are retried, then dead-lettered", func(t *testing.T) {
+		requests, status = nil, http.StatusServiceUnavailable
+		require.NoError(t, service.dashboardSaved(&events.DashboardSaved{Timestamp: time.Now(), OrgId: 1, Uid: "abc"}))
+
+		service.deliverDue(ctx)
+		pending := deliveries(models.WebhookDeliveryPending)
+		require.Len(t, pending, 1)
+		require.Equal(t, 1, pending[0].Attempts)
+		require.Equal(t, http.StatusServiceUnavailable, pending[0].LastStatusCode)
+		require.Contains(t, pending[0].LastError, "unexpected status 503")
+
+		time.Sleep(10 * time.Millisecond)
+		service.deliverDue(ctx)
+		require.Len(t, requests, 2)
+		require.Equal(t, requests[0].header.Get(DeliveryHeader), requests[1].header.Get(DeliveryHeader))
+		require.Empty(t, deliveries(models.WebhookDeliveryPending))
+		deadLetter := deliveries(models.WebhookDeliveryDeadLetter)
+		require.Len(t, deadLetter, 1)
+		require.Equal(t, 2, deadLetter[0].Attempts)
+
+		status = http.StatusOK
+		_, err := sqlStore.RedeliverWebhookDelivery(ctx, 1, webhook.Id, deadLetter[0].Id)
+		require.NoError(t, err)
+		service.deliverDue(ctx)
+		require.Len(t, requests, 3)
+		require.Empty(t, deliveries(models.WebhookDeliveryDeadLetter))
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, retryBackoff(30*time.Second, 1))
+	require.Equal(t, 60*time.Second, retryBackoff(30*time.Second, 2))
+	require.Equal(t, 120*time.Second, retryBackoff(30*time.Second, 3))
+}
@@ -209,6 +209,11 @@ type Cfg struct {
 	EnableGzip       bool
 	EnforceDomain    bool
 
+	// ShutdownDelay is how long the server waits after becoming unready before it stops accepting requests.
+	ShutdownDelay time.Duration
+	// ShutdownGracePeriod is how long the requests in flight may take to complete when the server shuts down.
+	ShutdownGracePeriod time.Duration
+
 	// build
 	BuildVersion string
 	BuildCommit  string
@@ -279,6 +284,7 @@ type Cfg struct {
 	LoginCookieName              string
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
+	LoginMaxConcurrentSessions   int64
 	TokenRotationIntervalMinutes int
 	SigV4AuthEnabled             bool
 	BasicAuthEnabled             bool
@@ -289,6 +295,9 @@ type Cfg struct {
 	AWSAllowedAuthProviders []string
 	AWSAssumeRoleEnabled    bool
 	AWSListMetricsPageLimit int
+	// AWSLogsMaxConcurrentQueries is the max number of running CloudWatch Logs Insights queries of a data source
+	// in a region, zero means the concurrent queries quota of the account.
+	AWSLogsMaxConcurrentQueries int
 
 	// Azure Cloud settings
 	Azure AzureSettings
@@ -305,6 +314,9 @@ type Cfg struct {
 
 	// OAuth
 	OAuthCookieMaxAge int
+	// OAuthTokenRefreshBeforeExpiry is how long before their expiry the OAuth access tokens of the signed-in users
+	// are refreshed, 0 to only refresh them when they're expired.
+	OAuthTokenRefreshBeforeExpiry time.Duration
 
 	// JWT Auth
 	JWTAuthEnabled       bool
@@ -346,11 +358,98 @@ type Cfg struct {
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
 
+	// AlertingStateHistoryMaxAge is for how long the state transitions of ngalert alert instances are stored,
+	// 0 keeps them forever.
+	AlertingStateHistoryMaxAge time.Duration
+	// AlertingNotificationLogMaxAge is for how long the notification attempts of ngalert are stored,
+	// 0 keeps them forever.
+	AlertingNotificationLogMaxAge time.Duration
+	// AdminConfigPollInterval is how often the ngalert admin configuration, such as the external Alertmanagers
+	// of the organisations, is synced from the database.
+	AdminConfigPollInterval time.Duration
+	// AlertingSchedulerSharding enables the partitioning of the ngalert rule groups across the Grafana instances
+	// sharing the database.
+	AlertingSchedulerSharding bool
+	// AlertingSchedulerShardingHeartbeatInterval is how often an instance announces itself to the other instances
+	// of the sharded scheduler and picks up the instances that joined or left.
+	AlertingSchedulerShardingHeartbeatInterval time.Duration
+	// AlertingMaxAlertInstancesPerRule is the maximum number of alert instances an evaluation of an ngalert rule
+	// without its own limit can produce, the extra ones are dropped. 0 is no limit.
+	AlertingMaxAlertInstancesPerRule int
+	// AlertingNotificationImageRenderLimit is the maximum number of panel images rendered per minute for the
+	// ngalert notifications, the notifications are sent without image once it's reached. 0 disables the images.
+	AlertingNotificationImageRenderLimit int
+	// AlertingRecordingRulesRemoteWriteURL is the Prometheus remote write endpoint the recording rules of ngalert write to,
+	// the recording rules aren't written when it's empty.
+	AlertingRecordingRulesRemoteWriteURL      string
+	AlertingRecordingRulesRemoteWriteUser     string
+	AlertingRecordingRulesRemoteWritePassword string
+	AlertingRecordingRulesRemoteWriteTimeout  time.Duration
+	// AlertingStatePersistInterval is how often the changed states of the ngalert alert instances are written
+	// to the database, 0 writes them after every evaluation.
+	AlertingStatePersistInterval time.Duration
+	// AlertingStatePersistBatchSize is the maximum number of alert instances written to the database at once.
+	AlertingStatePersistBatchSize int
+	// AlertingStateFullSyncInterval is how often a compressed snapshot of all the states of the ngalert alert
+	// instances is written to the database, 0 disables the snapshots.
+	AlertingStateFullSyncInterval time.Duration
+
 	// Sentry config
 	Sentry Sentry
 
+	// Public dashboards config
+	PublicDashboards PublicDashboards
+
+	// Audit log config
+	Audit AuditSettings
+
+	// Rate limiting of the authentication, query and rendering requests
+	RateLimiting RateLimitingSettings
+
+	// gRPC admin API
+	GRPCAdmin GRPCAdminSettings
+
+	// Outbound webhooks of the org events
+	Webhooks WebhooksSettings
+
+	// Priority of the requests under load
+	LoadShedding LoadSheddingSettings
+
+	// Retries of the mutating requests
+	Idempotency IdempotencySettings
+
+	// SCIM provisioning of the users and teams
+	SCIM SCIMSettings
+
+	// SAML authentication
+	SAML SAMLSettings
+
 	// Data sources
 	DataSourceLimit int
+	// DataSourceQueryTimeout is the default timeout of the queries of a data source, zero means no timeout.
+	DataSourceQueryTimeout time.Duration
+	// DataSourceMaxConcurrentQueries is the default max number of queries a data source runs concurrently, zero
+	// means no limit.
+	DataSourceMaxConcurrentQueries int
+	// DataSourcePermissionsEnabled enforces the permissions of the data sources instead of letting every viewer and
+	// editor query every data source.
+	DataSourcePermissionsEnabled bool
+
+	// SQL data sources
+	// SQLDatasourceRowLimit is the max number of rows of the result of a SQL data source query.
+	SQLDatasourceRowLimit int64
+	// SQLDatasourceMaxResultBytes is the max size in bytes of the result of a SQL data source query, zero means
+	// no limit.
+	SQLDatasourceMaxResultBytes int64
+
+	// Query caching
+	// QueryCachingEnabled specifies whether the results of the data source queries are cached in the remote cache.
+	QueryCachingEnabled bool
+	// QueryCachingDefaultTTL is the TTL of the cached results of the data sources without their own TTL, zero means
+	// only the data sources with a TTL are cached.
+	QueryCachingDefaultTTL time.Duration
+	// QueryCachingMaxValueSize is the max size in bytes of a cached result, zero means no limit.
+	QueryCachingMaxValueSize int
 
 	// Snapshots
 	SnapshotPublicMode bool
@@ -367,6 +466,10 @@ type Cfg struct {
 	// LDAP
 	LDAPEnabled     bool
 	LDAPAllowSignup bool
+	// LDAPSyncCron is the cron schedule of the background sync of the LDAP users, with a seconds field, which runs
+	// when LDAPActiveSyncEnabled is true.
+	LDAPSyncCron          string
+	LDAPActiveSyncEnabled bool
 
 	Quota QuotaSettings
 
@@ -539,6 +642,80 @@ func (cfg *Cfg) readAnnotationSettings() {
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
 }
 
+func (cfg *Cfg) readAlertingStateHistorySettings() {
+	alerting := cfg.Raw.Section("alerting")
+	maxAge, err := gtime.ParseDuration(alerting.Key("max_state_history_age").MustString("30d"))
+	if err != nil {
+		maxAge = 0
+	}
+	cfg.AlertingStateHistoryMaxAge = maxAge
+
+	notificationLogMaxAge, err := gtime.ParseDuration(alerting.Key("max_notification_log_age").MustString("7d"))
+	if err != nil {
+		notificationLogMaxAge = 0
+	}
+	cfg.AlertingNotificationLogMaxAge = notificationLogMaxAge
+}
+
+func (cfg *Cfg) readAlertingAdminConfigSettings() {
+	alerting := cfg.Raw.Section("alerting")
+	pollInterval, err := gtime.ParseDuration(alerting.Key("admin_config_poll_interval").MustString("60s"))
+	if err != nil || pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	cfg.AdminConfigPollInterval = pollInterval
+}
+
+func (cfg *Cfg) readAlertingSchedulerShardingSettings() {
+	alerting := cfg.Raw.Section("alerting")
+	cfg.AlertingSchedulerSharding = alerting.Key("scheduler_sharding").MustBool(false)
+	heartbeatInterval, err := gtime.ParseDuration(alerting.Key("scheduler_sharding_heartbeat_interval").MustString("15s"))
+	if err != nil || heartbeatInterval <= 0 {
+		heartbeatInterval = 15 * time.Second
+	}
+	cfg.AlertingSchedulerShardingHeartbeatInterval = heartbeatInterval
+}
+
+func (cfg *Cfg) readAlertingInstanceLimitSettings() {
+	alerting := cfg.Raw.Section("alerting")
+	cfg.AlertingMaxAlertInstancesPerRule = alerting.Key("max_alert_instances_per_rule").MustInt(0)
+}
+
+func (cfg *Cfg) readAlertingNotificationImageSettings() {
+	alerting := cfg.Raw.Section("alerting")
+	cfg.AlertingNotificationImageRenderLimit = alerting.Key("notification_image_render_limit").MustInt(30)
+}
+
+func (cfg *Cfg) readAlertingRecordingRulesSettings() {
+	alerting := cfg.Raw.Section("alerting")
+	cfg.AlertingRecordingRulesRemoteWriteURL = alerting.Key("recording_rules_remote_write_url").MustString("")
+	cfg.AlertingRecordingRulesRemoteWriteUser = alerting.Key("recording_rules_remote_write_basic_auth_user").MustString("")
+	cfg.AlertingRecordingRulesRemoteWritePassword = alerting.Key("recording_rules_remote_write_basic_auth_password").MustString("")
+	timeout, err := gtime.ParseDuration(alerting.Key("recording_rules_remote_write_timeout").MustString("30s"))
+	if err != nil || timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	cfg.AlertingRecordingRulesRemoteWriteTimeout = timeout
+}
+
+func (cfg *Cfg) readAlertingStatePersistenceSettings() {
+	alerting := cfg.Raw.Section("alerting")
+	persistInterval, err := gtime.ParseDuration(alerting.Key("state_persist_interval").MustString("0s"))
+	if err != nil || persistInterval < 0 {
+		persistInterval = 0
+	}
+	cfg.AlertingStatePersistInterval = persistInterval
+	cfg.AlertingStatePersistBatchSize = alerting.Key("state_persist_batch_size").MustInt(100)
+	if cfg.AlertingStatePersistBatchSize <= 0 {
+		cfg.AlertingStatePersistBatchSize = 100
+	}
+	fullSyncInterval, err := gtime.ParseDuration(alerting.Key("state_full_sync_interval").MustString("0s"))
+	if err != nil || fullSyncInterval < 0 {
+		fullSyncInterval = 0
+	}
+	cfg.AlertingStateFullSyncInterval = fullSyncInterval
+}
+
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
@@ -929,12 +1106,20 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.readSmtpSettings()
 	cfg.readQuotaSettings()
 	cfg.readAnnotationSettings()
+	cfg.readAlertingStateHistorySettings()
+	cfg.readAlertingAdminConfigSettings()
+	cfg.readAlertingSchedulerShardingSettings()
+	cfg.readAlertingInstanceLimitSettings()
+	cfg.readAlertingNotificationImageSettings()
+	cfg.readAlertingRecordingRulesSettings()
+	cfg.readAlertingStatePersistenceSettings()
 	cfg.readExpressionsSettings()
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
 
 	cfg.readDataSourcesSettings()
+	cfg.readQueryCachingSettings()
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		log.Warnf("require_email_validation is enabled but smtp is disabled")
@@ -965,6 +1150,21 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 
 	cfg.readDateFormats()
 	cfg.readSentryConfig()
+	cfg.readPublicDashboardsConfig()
+	cfg.readAuditSettings()
+	if err := cfg.readRateLimitingSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readGRPCAdminSettings(); err != nil {
+		return err
+	}
+	cfg.readWebhooksSettings()
+	if err := cfg.readLoadSheddingSettings(); err != nil {
+		return err
+	}
+	cfg.readIdempotencySettings()
+	cfg.readSCIMSettings()
+	cfg.readSAMLSettings()
 
 	if err := cfg.readLiveSettings(iniFile); err != nil {
 		return err
@@ -986,9 +1186,11 @@ func (cfg *Cfg) readLDAPConfig() {
 	ldapSec := cfg.Raw.Section("auth.ldap")
 	LDAPConfigFile = ldapSec.Key("config_file").String()
 	LDAPSyncCron = ldapSec.Key("sync_cron").String()
+	cfg.LDAPSyncCron = LDAPSyncCron
 	LDAPEnabled = ldapSec.Key("enabled").MustBool(false)
 	cfg.LDAPEnabled = LDAPEnabled
 	LDAPActiveSyncEnabled = ldapSec.Key("active_sync_enabled").MustBool(false)
+	cfg.LDAPActiveSyncEnabled = LDAPActiveSyncEnabled
 	LDAPAllowSignup = ldapSec.Key("allow_sign_up").MustBool(true)
 	cfg.LDAPAllowSignup = LDAPAllowSignup
 }
@@ -1004,6 +1206,7 @@ func (cfg *Cfg) handleAWSConfig() {
 		}
 	}
 	cfg.AWSListMetricsPageLimit = awsPluginSec.Key("list_metrics_page_limit").MustInt(500)
+	cfg.AWSLogsMaxConcurrentQueries = awsPluginSec.Key("logs_max_concurrent_queries").MustInt(0)
 	// Also set environment variables that can be used by core plugins
 	err := os.Setenv(awsds.AssumeRoleEnabledEnvVarKeyName, strconv.FormatBool(cfg.AWSAssumeRoleEnabled))
 	if err != nil {
@@ -1181,6 +1384,11 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		return err
 	}
 
+	cfg.LoginMaxConcurrentSessions = auth.Key("login_maximum_concurrent_sessions").MustInt64(0)
+	if cfg.LoginMaxConcurrentSessions < 0 {
+		cfg.LoginMaxConcurrentSessions = 0
+	}
+
 	cfg.ApiKeyMaxSecondsToLive = auth.Key("api_key_max_seconds_to_live").MustInt64(-1)
 
 	cfg.TokenRotationIntervalMinutes = auth.Key("token_rotation_interval_minutes").MustInt(10)
@@ -1192,6 +1400,10 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
 	OAuthAutoLogin = auth.Key("oauth_auto_login").MustBool(false)
 	cfg.OAuthCookieMaxAge = auth.Key("oauth_state_cookie_max_age").MustInt(600)
+	cfg.OAuthTokenRefreshBeforeExpiry, err = gtime.ParseDuration(valueAsString(auth, "oauth_token_refresh_before_expiry", "1m"))
+	if err != nil {
+		return err
+	}
 	SignoutRedirectUrl = valueAsString(auth, "signout_redirect_url", "")
 
 	// SigV4
@@ -1415,6 +1627,8 @@ func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 	}
 
 	cfg.ReadTimeout = server.Key("read_timeout").MustDuration(0)
+	cfg.ShutdownDelay = server.Key("shutdown_delay").MustDuration(0)
+	cfg.ShutdownGracePeriod = server.Key("shutdown_grace_period").MustDuration(30 * time.Second)
 
 	return nil
 }
@@ -1439,6 +1653,28 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	queryTimeout, err := gtime.ParseDuration(datasources.Key("query_timeout").MustString("0s"))
+	if err != nil || queryTimeout < 0 {
+		queryTimeout = 0
+	}
+	cfg.DataSourceQueryTimeout = queryTimeout
+	cfg.DataSourceMaxConcurrentQueries = datasources.Key("max_concurrent_queries").MustInt(0)
+	cfg.DataSourcePermissionsEnabled = datasources.Key("enforce_permissions").MustBool(false)
+
+	sqlDatasources := cfg.Raw.Section("sql_datasources")
+	cfg.SQLDatasourceRowLimit = sqlDatasources.Key("row_limit").MustInt64(1000000)
+	cfg.SQLDatasourceMaxResultBytes = sqlDatasources.Key("max_result_bytes").MustInt64(0)
+}
+
+func (cfg *Cfg) readQueryCachingSettings() {
+	queryCaching := cfg.Raw.Section("query_caching")
+	cfg.QueryCachingEnabled = queryCaching.Key("enabled").MustBool(false)
+	defaultTTL, err := gtime.ParseDuration(queryCaching.Key("default_ttl").MustString("0s"))
+	if err != nil || defaultTTL < 0 {
+		defaultTTL = 0
+	}
+	cfg.QueryCachingDefaultTTL = defaultTTL
+	cfg.QueryCachingMaxValueSize = queryCaching.Key("max_value_size").MustInt(1000000)
 }
 
 func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
@@ -0,0 +1,18 @@
+package setting
+
+type AuditSettings struct {
+	Enabled bool
+	// FilePath is the file the audit entries are appended to as JSON lines, empty to not write them to a file.
+	FilePath string
+	// LokiURL is the push API of the Loki instance the audit entries are sent to, empty to not send them.
+	LokiURL string
+}
+
+func (cfg *Cfg) readAuditSettings() {
+	raw := cfg.Raw.Section("audit")
+	cfg.Audit = AuditSettings{
+		Enabled:  raw.Key("enabled").MustBool(false),
+		FilePath: raw.Key("file_path").MustString(""),
+		LokiURL:  raw.Key("loki_url").MustString(""),
+	}
+}
@@ -1,6 +1,10 @@
 package setting
 
-import "strings"
+import (
+	"os"
+	"strings"
+	"time"
+)
 
 const (
 	AzurePublic       = "AzureCloud"
@@ -13,6 +17,35 @@ type AzureSettings struct {
 	Cloud                   string
 	ManagedIdentityEnabled  bool
 	ManagedIdentityClientId string
+	RemoteTokenCacheEnabled bool
+	TokenAuditLogEnabled    bool
+	TokenExpiryMargin       time.Duration
+	TokenClockSkew          time.Duration
+	TokenMaxRetries         int
+	TokenRetryBackoff       time.Duration
+
+	// Background refresh of cached tokens
+	TokenBackgroundRefreshEnabled bool
+	TokenRefreshLeadTime          time.Duration
+	TokenRefreshJitter            time.Duration
+
+	// Workload Identity
+	WorkloadIdentityEnabled   bool
+	WorkloadIdentityTenantId  string
+	WorkloadIdentityClientId  string
+	WorkloadIdentityTokenFile string
+
+	// Custom clouds (e.g. Azure Stack), keyed by cloud name
+	CustomClouds map[string]AzureCloudSettings
+}
+
+// AzureCloudSettings configures endpoints of a custom Azure cloud
+type AzureCloudSettings struct {
+	AuthorityHost      string
+	ResourceManagerURL string
+	ResourceGraphURL   string
+	LogAnalyticsURL    string
+	AppInsightsURL     string
 }
 
 func (cfg *Cfg) readAzureSettings() {
@@ -25,6 +58,41 @@ func (cfg *Cfg) readAzureSettings() {
 	// Managed Identity
 	cfg.Azure.ManagedIdentityEnabled = azureSection.Key("managed_identity_enabled").MustBool(false)
 	cfg.Azure.ManagedIdentityClientId = azureSection.Key("managed_identity_client_id").String()
+
+	// Workload Identity, defaults are taken from the environment set up by Azure Workload Identity webhook
+	cfg.Azure.WorkloadIdentityEnabled = azureSection.Key("workload_identity_enabled").MustBool(false)
+	cfg.Azure.WorkloadIdentityTenantId = azureSection.Key("workload_identity_tenant_id").MustString(os.Getenv("AZURE_TENANT_ID"))
+	cfg.Azure.WorkloadIdentityClientId = azureSection.Key("workload_identity_client_id").MustString(os.Getenv("AZURE_CLIENT_ID"))
+	cfg.Azure.WorkloadIdentityTokenFile = azureSection.Key("workload_identity_token_file").MustString(os.Getenv("AZURE_FEDERATED_TOKEN_FILE"))
+
+	// Custom clouds
+	cfg.Azure.CustomClouds = map[string]AzureCloudSettings{}
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), "azure.cloud.") {
+			continue
+		}
+
+		cloudName := strings.TrimPrefix(section.Name(), "azure.cloud.")
+		resourceManagerURL := section.Key("resource_manager_url").String()
+		cfg.Azure.CustomClouds[cloudName] = AzureCloudSettings{
+			AuthorityHost:      section.Key("authority_host").String(),
+			ResourceManagerURL: resourceManagerURL,
+			ResourceGraphURL:   section.Key("resource_graph_url").MustString(resourceManagerURL),
+			LogAnalyticsURL:    section.Key("log_analytics_url").String(),
+			AppInsightsURL:     section.Key("app_insights_url").String(),
+		}
+	}
+
+	// Token cache
+	cfg.Azure.RemoteTokenCacheEnabled = azureSection.Key("remote_token_cache_enabled").MustBool(false)
+	cfg.Azure.TokenAuditLogEnabled = azureSection.Key("token_audit_log_enabled").MustBool(false)
+	cfg.Azure.TokenExpiryMargin = azureSection.Key("token_expiry_margin").MustDuration(2 * time.Minute)
+	cfg.Azure.TokenClockSkew = azureSection.Key("token_clock_skew").MustDuration(0)
+	cfg.Azure.TokenMaxRetries = azureSection.Key("token_max_retries").MustInt(3)
+	cfg.Azure.TokenRetryBackoff = azureSection.Key("token_retry_backoff").MustDuration(500 * time.Millisecond)
+	cfg.Azure.TokenBackgroundRefreshEnabled = azureSection.Key("token_background_refresh_enabled").MustBool(true)
+	cfg.Azure.TokenRefreshLeadTime = azureSection.Key("token_refresh_lead_time").MustDuration(5 * time.Minute)
+	cfg.Azure.TokenRefreshJitter = azureSection.Key("token_refresh_jitter").MustDuration(30 * time.Second)
 }
 
 func normalizeAzureCloud(cloudName string) string {
@@ -0,0 +1,24 @@
+package setting
+
+import "errors"
+
+type GRPCAdminSettings struct {
+	Enabled bool
+	// Address is the host and port the gRPC server listens on.
+	Address string
+	// CertFile and KeyFile are the certificate and the key of the TLS server, which is plaintext without them.
+	CertFile string
+	KeyFile  string
+}
+
+func (cfg *Cfg) readGRPCAdminSettings() error {
+	raw := cfg.Raw.Section("grpc_admin")
+	cfg.GRPCAdmin.Enabled = raw.Key("enabled").MustBool(false)
+	cfg.GRPCAdmin.Address = valueAsString(raw, "address", ":3100")
+	cfg.GRPCAdmin.CertFile = valueAsString(raw, "cert_file", "")
+	cfg.GRPCAdmin.KeyFile = valueAsString(raw, "cert_key", "")
+	if (cfg.GRPCAdmin.CertFile == "") != (cfg.GRPCAdmin.KeyFile == "") {
+		return errors.New("grpc_admin cert_file and cert_key must be set together")
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package setting
+
+import "time"
+
+type IdempotencySettings struct {
+	Enabled bool
+	// KeyTTL is how long the response of a request with an idempotency key is kept for its retries.
+	KeyTTL time.Duration
+	// MaxResponseSize is the size in bytes over which a response isn't kept, and the retries of its request are
+	// handled again.
+	MaxResponseSize int
+}
+
+func (cfg *Cfg) readIdempotencySettings() {
+	raw := cfg.Raw.Section("idempotency")
+	cfg.Idempotency = IdempotencySettings{
+		Enabled:         raw.Key("enabled").MustBool(false),
+		KeyTTL:          raw.Key("key_ttl").MustDuration(time.Hour),
+		MaxResponseSize: raw.Key("max_response_size").MustInt(1024 * 1024),
+	}
+}
@@ -0,0 +1,71 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RequestClass is the kind of traffic a request belongs to, which decides its priority when load shedding.
+type RequestClass string
+
+const (
+	// RequestClassInteractive are the requests of the users of the UI, including the anonymous users.
+	RequestClassInteractive RequestClass = "interactive"
+	// RequestClassAPI are the requests authenticated with API keys, service account tokens or basic auth.
+	RequestClassAPI RequestClass = "api"
+	// RequestClassAlerting are the requests evaluating and testing alert rules and notifications.
+	RequestClassAlerting RequestClass = "alerting"
+	// RequestClassRendering are the rendering requests, and the requests of the image renderer.
+	RequestClassRendering RequestClass = "rendering"
+)
+
+type LoadSheddingSettings struct {
+	Enabled bool
+	// MaxConcurrentRequests is the number of requests in flight over which the instance is overloaded, zero disables
+	// the threshold.
+	MaxConcurrentRequests int
+	// CPUThreshold is the percentage of the CPU time of all cores used by the process over which the instance is
+	// overloaded, zero disables the threshold.
+	CPUThreshold float64
+	// LowPriority are the classes of the requests which are queued while the instance is overloaded.
+	LowPriority []RequestClass
+	// MaxQueuedRequests is the number of queued requests over which low priority requests are rejected right away.
+	MaxQueuedRequests int
+	// QueueTimeout is how long a low priority request is queued before it's rejected.
+	QueueTimeout time.Duration
+}
+
+// IsLowPriority returns whether the requests of the class are queued while the instance is overloaded.
+func (s LoadSheddingSettings) IsLowPriority(class RequestClass) bool {
+	for _, c := range s.LowPriority {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *Cfg) readLoadSheddingSettings() error {
+	raw := cfg.Raw.Section("load_shedding")
+	cfg.LoadShedding = LoadSheddingSettings{
+		Enabled:               raw.Key("enabled").MustBool(false),
+		MaxConcurrentRequests: raw.Key("max_concurrent_requests").MustInt(200),
+		CPUThreshold:          raw.Key("cpu_threshold").MustFloat64(90),
+		MaxQueuedRequests:     raw.Key("max_queued_requests").MustInt(100),
+		QueueTimeout:          raw.Key("queue_timeout").MustDuration(5 * time.Second),
+	}
+
+	for _, class := range util.SplitString(raw.Key("low_priority").MustString("api rendering")) {
+		switch RequestClass(class) {
+		case RequestClassInteractive, RequestClassAPI, RequestClassAlerting, RequestClassRendering:
+			cfg.LoadShedding.LowPriority = append(cfg.LoadShedding.LowPriority, RequestClass(class))
+		default:
+			return fmt.Errorf("invalid load_shedding low_priority class %q, expected %s", class,
+				strings.Join([]string{"interactive", "api", "alerting", "rendering"}, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package setting
+
+import "time"
+
+type PublicDashboards struct {
+	Enabled bool
+	// QueryCacheTTL is how long the results of the queries of the public dashboards are cached, zero disables the
+	// cache.
+	QueryCacheTTL time.Duration
+	// RequestsPerSecond and Burst limit the rate of the unauthenticated requests to each public dashboard.
+	RequestsPerSecond int
+	Burst             int
+}
+
+func (cfg *Cfg) readPublicDashboardsConfig() {
+	raw := cfg.Raw.Section("public_dashboards")
+	cfg.PublicDashboards = PublicDashboards{
+		Enabled:           raw.Key("enabled").MustBool(false),
+		QueryCacheTTL:     raw.Key("query_cache_ttl").MustDuration(time.Minute),
+		RequestsPerSecond: raw.Key("requests_per_second_limit").MustInt(10),
+		Burst:             raw.Key("burst_limit").MustInt(20),
+	}
+}
@@ -5,11 +5,12 @@ import (
 )
 
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User          int64 `target:"org_user"`
+	DataSource    int64 `target:"data_source"`
+	Dashboard     int64 `target:"dashboard"`
+	ApiKey        int64 `target:"api_key"`
+	AlertRule     int64 `target:"alert_rule"`
+	AlertInstance int64 `target:"alert_instance"`
 }
 
 type UserQuota struct {
@@ -17,13 +18,19 @@ type UserQuota struct {
 }
 
 type GlobalQuota struct {
-	Org        int64 `target:"org"`
-	User       int64 `target:"user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	Session    int64 `target:"-"`
-	AlertRule  int64 `target:"alert_rule"`
+	Org           int64 `target:"org"`
+	User          int64 `target:"user"`
+	DataSource    int64 `target:"data_source"`
+	Dashboard     int64 `target:"dashboard"`
+	ApiKey        int64 `target:"api_key"`
+	Session       int64 `target:"-"`
+	AlertRule     int64 `target:"alert_rule"`
+	AlertInstance int64 `target:"alert_instance"`
+}
+
+// FolderQuota is the limits of each folder, they can't be overridden per folder.
+type FolderQuota struct {
+	AlertRule int64 `target:"alert_rule"`
 }
 
 func (q *OrgQuota) ToMap() map[string]int64 {
@@ -59,6 +66,7 @@ type QuotaSettings struct {
 	Org     *OrgQuota
 	User    *UserQuota
 	Global  *GlobalQuota
+	Folder  *FolderQuota
 }
 
 func (cfg *Cfg) readQuotaSettings() {
@@ -68,17 +76,24 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	var alertOrgQuota int64
 	var alertGlobalQuota int64
+	var alertInstanceOrgQuota int64 = -1
+	var alertInstanceGlobalQuota int64 = -1
+	var alertFolderQuota int64 = -1
 	if cfg.IsNgAlertEnabled() {
 		alertOrgQuota = quota.Key("org_alert_rule").MustInt64(100)
 		alertGlobalQuota = quota.Key("global_alert_rule").MustInt64(-1)
+		alertInstanceOrgQuota = quota.Key("org_alert_instance").MustInt64(-1)
+		alertInstanceGlobalQuota = quota.Key("global_alert_instance").MustInt64(-1)
+		alertFolderQuota = quota.Key("folder_alert_rule").MustInt64(-1)
 	}
 	// per ORG Limits
 	Quota.Org = &OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  alertOrgQuota,
+		User:          quota.Key("org_user").MustInt64(10),
+		DataSource:    quota.Key("org_data_source").MustInt64(10),
+		Dashboard:     quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:        quota.Key("org_api_key").MustInt64(10),
+		AlertRule:     alertOrgQuota,
+		AlertInstance: alertInstanceOrgQuota,
 	}
 
 	// per User limits
@@ -88,13 +103,19 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// Global Limits
 	Quota.Global = &GlobalQuota{
-		User:       quota.Key("global_user").MustInt64(-1),
-		Org:        quota.Key("global_org").MustInt64(-1),
-		DataSource: quota.Key("global_data_source").MustInt64(-1),
-		Dashboard:  quota.Key("global_dashboard").MustInt64(-1),
-		ApiKey:     quota.Key("global_api_key").MustInt64(-1),
-		Session:    quota.Key("global_session").MustInt64(-1),
-		AlertRule:  alertGlobalQuota,
+		User:          quota.Key("global_user").MustInt64(-1),
+		Org:           quota.Key("global_org").MustInt64(-1),
+		DataSource:    quota.Key("global_data_source").MustInt64(-1),
+		Dashboard:     quota.Key("global_dashboard").MustInt64(-1),
+		ApiKey:        quota.Key("global_api_key").MustInt64(-1),
+		Session:       quota.Key("global_session").MustInt64(-1),
+		AlertRule:     alertGlobalQuota,
+		AlertInstance: alertInstanceGlobalQuota,
+	}
+
+	// per Folder limits
+	Quota.Folder = &FolderQuota{
+		AlertRule: alertFolderQuota,
 	}
 
 	cfg.Quota = Quota
@@ -0,0 +1,66 @@
+package setting
+
+import "fmt"
+
+// RateLimitKey is what the requests are counted by in a rate limit.
+type RateLimitKey string
+
+const (
+	RateLimitByUser RateLimitKey = "user"
+	RateLimitByOrg  RateLimitKey = "org"
+	RateLimitByIP   RateLimitKey = "ip"
+)
+
+// RateLimit is the limit of the requests of each user, org or IP address. The requests of the anonymous users are
+// counted by IP address.
+type RateLimit struct {
+	// RequestsPerMinute is the average rate of the requests, zero disables the limit.
+	RequestsPerMinute int
+	// Burst is the max number of requests at the same time.
+	Burst int
+	By    RateLimitKey
+}
+
+type RateLimitingSettings struct {
+	Enabled bool
+	// Auth is the limit of the login, signup and password reset requests.
+	Auth RateLimit
+	// Query is the limit of the requests to the query API.
+	Query RateLimit
+	// Render is the limit of the requests to the rendering API.
+	Render RateLimit
+	// TrustForwardedHeaders counts the requests by the IP address of the X-Real-IP and X-Forwarded-For headers.
+	TrustForwardedHeaders bool
+}
+
+func (cfg *Cfg) readRateLimitingSettings() error {
+	raw := cfg.Raw.Section("rate_limiting")
+	readLimit := func(name string, requestsPerMinute, burst int, by RateLimitKey) (RateLimit, error) {
+		limit := RateLimit{
+			RequestsPerMinute: raw.Key(name + "_requests_per_minute").MustInt(requestsPerMinute),
+			Burst:             raw.Key(name + "_burst").MustInt(burst),
+			By:                RateLimitKey(raw.Key(name + "_limit_by").MustString(string(by))),
+		}
+		switch limit.By {
+		case RateLimitByUser, RateLimitByOrg, RateLimitByIP:
+		default:
+			return limit, fmt.Errorf("invalid rate_limiting %s_limit_by %q, expected user, org or ip", name, limit.By)
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		return limit, nil
+	}
+
+	var err error
+	cfg.RateLimiting.Enabled = raw.Key("enabled").MustBool(false)
+	cfg.RateLimiting.TrustForwardedHeaders = raw.Key("trust_forwarded_headers").MustBool(false)
+	if cfg.RateLimiting.Auth, err = readLimit("auth", 10, 10, RateLimitByIP); err != nil {
+		return err
+	}
+	if cfg.RateLimiting.Query, err = readLimit("query", 600, 100, RateLimitByUser); err != nil {
+		return err
+	}
+	cfg.RateLimiting.Render, err = readLimit("render", 30, 10, RateLimitByUser)
+	return err
+}
@@ -0,0 +1,87 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type SAMLSettings struct {
+	Enabled bool
+	// SingleLogout is whether the logouts end the session of the user in the IdP, and the logouts requested by the
+	// IdP end the sessions of the user in Grafana.
+	SingleLogout bool
+	// AllowIdPInitiated is whether the users can log in from the IdP, without a request from Grafana.
+	AllowIdPInitiated bool
+	// RelayState is the relay state the IdP sends with the IdP-initiated logins.
+	RelayState  string
+	AllowSignUp bool
+
+	// The SP certificate and private key, base64 encoded or read from a path.
+	Certificate     string
+	CertificatePath string
+	PrivateKey      string
+	PrivateKeyPath  string
+	// SignatureAlgorithm is the algorithm of the signature of the requests sent to the IdP, they aren't signed when
+	// it's empty.
+	SignatureAlgorithm string
+
+	// The IdP metadata, base64 encoded, read from a path or loaded from a URL.
+	IdPMetadata     string
+	IdPMetadataPath string
+	IdPMetadataURL  string
+
+	MaxIssueDelay         time.Duration
+	MetadataValidDuration time.Duration
+
+	AssertionAttributeName   string
+	AssertionAttributeLogin  string
+	AssertionAttributeEmail  string
+	AssertionAttributeGroups string
+	AssertionAttributeRole   string
+	AssertionAttributeOrg    string
+
+	AllowedOrganizations []string
+	// OrgMapping is the list of Organization:OrgId mappings.
+	OrgMapping             []string
+	RoleValuesEditor       []string
+	RoleValuesAdmin        []string
+	RoleValuesGrafanaAdmin []string
+}
+
+func (cfg *Cfg) readSAMLSettings() {
+	raw := cfg.Raw.Section("auth.saml")
+	cfg.SAML = SAMLSettings{
+		Enabled:           raw.Key("enabled").MustBool(false),
+		SingleLogout:      raw.Key("single_logout").MustBool(false),
+		AllowIdPInitiated: raw.Key("allow_idp_initiated").MustBool(false),
+		RelayState:        raw.Key("relay_state").String(),
+		AllowSignUp:       raw.Key("allow_sign_up").MustBool(true),
+
+		Certificate:        raw.Key("certificate").String(),
+		CertificatePath:    raw.Key("certificate_path").String(),
+		PrivateKey:         raw.Key("private_key").String(),
+		PrivateKeyPath:     raw.Key("private_key_path").String(),
+		SignatureAlgorithm: raw.Key("signature_algorithm").String(),
+
+		IdPMetadata:     raw.Key("idp_metadata").String(),
+		IdPMetadataPath: raw.Key("idp_metadata_path").String(),
+		IdPMetadataURL:  raw.Key("idp_metadata_url").String(),
+
+		MaxIssueDelay:         raw.Key("max_issue_delay").MustDuration(90 * time.Second),
+		MetadataValidDuration: raw.Key("metadata_valid_duration").MustDuration(48 * time.Hour),
+
+		AssertionAttributeName:   raw.Key("assertion_attribute_name").MustString("displayName"),
+		AssertionAttributeLogin:  raw.Key("assertion_attribute_login").MustString("mail"),
+		AssertionAttributeEmail:  raw.Key("assertion_attribute_email").MustString("mail"),
+		AssertionAttributeGroups: raw.Key("assertion_attribute_groups").String(),
+		AssertionAttributeRole:   raw.Key("assertion_attribute_role").String(),
+		AssertionAttributeOrg:    raw.Key("assertion_attribute_org").String(),
+
+		AllowedOrganizations:   util.SplitString(raw.Key("allowed_organizations").String()),
+		OrgMapping:             util.SplitString(raw.Key("org_mapping").String()),
+		RoleValuesEditor:       util.SplitString(raw.Key("role_values_editor").String()),
+		RoleValuesAdmin:        util.SplitString(raw.Key("role_values_admin").String()),
+		RoleValuesGrafanaAdmin: util.SplitString(raw.Key("role_values_grafana_admin").String()),
+	}
+}
@@ -0,0 +1,15 @@
+package setting
+
+type SCIMSettings struct {
+	Enabled bool
+	// OrgRole is the role of the users provisioned in the org.
+	OrgRole string
+}
+
+func (cfg *Cfg) readSCIMSettings() {
+	raw := cfg.Raw.Section("auth.scim")
+	cfg.SCIM = SCIMSettings{
+		Enabled: raw.Key("enabled").MustBool(false),
+		OrgRole: raw.Key("org_role").In("Viewer", []string{"Viewer", "Editor", "Admin"}),
+	}
+}
@@ -0,0 +1,29 @@
+package setting
+
+import "time"
+
+type WebhooksSettings struct {
+	Enabled bool
+	// Timeout is the timeout of an attempt to deliver an event.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts after which an undelivered event is dead-lettered.
+	MaxAttempts int
+	// RetryBackoff is the delay before the second attempt, which doubles after each failed attempt.
+	RetryBackoff time.Duration
+	// DeliveryLogRetention is how long the delivered and dead-lettered events are kept in the delivery log.
+	DeliveryLogRetention time.Duration
+}
+
+func (cfg *Cfg) readWebhooksSettings() {
+	raw := cfg.Raw.Section("webhooks")
+	cfg.Webhooks = WebhooksSettings{
+		Enabled:              raw.Key("enabled").MustBool(false),
+		Timeout:              raw.Key("timeout").MustDuration(10 * time.Second),
+		MaxAttempts:          raw.Key("max_attempts").MustInt(5),
+		RetryBackoff:         raw.Key("retry_backoff").MustDuration(30 * time.Second),
+		DeliveryLogRetention: raw.Key("delivery_log_retention").MustDuration(7 * 24 * time.Hour),
+	}
+	if cfg.Webhooks.MaxAttempts < 1 {
+		cfg.Webhooks.MaxAttempts = 1
+	}
+}
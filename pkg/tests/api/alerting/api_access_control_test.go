@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestAlertingAccessControl(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert", "accesscontrol"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+	require.NoError(t, createUser(t, store, models.ROLE_VIEWER, "viewer", "viewer"))
+
+	_, err := createFolder(t, store, 0, "default")
+	require.NoError(t, err)
+
+	ruleGroup := `{
+		"name": "group",
+		"interval": "1m",
+		"rules": [
+			{
+				"grafana_alert": {
+					"title": "rule",
+					"condition": "A",
+					"data": [
+						{
+							"refId": "A",
+							"relativeTimeRange": {"from": 18000, "to": 10800},
+							"datasourceUid": "-100",
+							"model": {"type": "math", "expression": "2 + 3 > 1"}
+						}
+					]
+				}
+			}
+		]
+	}`
+
+	t.Run("editor can change the rules of a folder", func(t *testing.T) {
+		postRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), ruleGroup, http.StatusAccepted)
+	})
+
+	t.Run("viewer can list but not change the rules of a folder", func(t *testing.T) {
+		resp := getRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/ruler/grafana/api/v1/rules", grafanaListedAddr), http.StatusAccepted)
+		require.Contains(t, getBody(t, resp.Body), `"title":"rule"`)
+		getRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/ruler/grafana/api/v1/rules/default/group", grafanaListedAddr), http.StatusAccepted)
+
+		postRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), ruleGroup, http.StatusForbidden)
+		deleteRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/ruler/grafana/api/v1/rules/default/group", grafanaListedAddr), http.StatusForbidden)
+	})
+
+	t.Run("provisioning API requires the provisioning actions", func(t *testing.T) {
+		getRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/v1/provisioning/contact-points", grafanaListedAddr), http.StatusForbidden)
+		getRequest(t, fmt.Sprintf("http://editor:editor@%s/api/v1/provisioning/contact-points", grafanaListedAddr), http.StatusOK)
+	})
+
+	t.Run("viewer can't change the notifications", func(t *testing.T) {
+		postRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/alertmanager/grafana/api/v2/silences", grafanaListedAddr), `{
+			"comment": "silence",
+			"createdBy": "viewer",
+			"matchers": [{"name": "alertname", "value": "rule", "isRegex": false}],
+			"startsAt": "2021-01-01T00:00:00Z",
+			"endsAt": "2031-01-01T00:00:00Z"
+		}`, http.StatusForbidden)
+	})
+}
@@ -0,0 +1,90 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestAdminConfiguration(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_ADMIN, "admin", "admin"))
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+
+	fakeAM := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(fakeAM.Close)
+
+	baseURL := fmt.Sprintf("http://admin:admin@%s/api/v1/ngalert", grafanaListedAddr)
+
+	t.Run("editor can't use the admin configuration API", func(t *testing.T) {
+		getRequest(t, fmt.Sprintf("http://editor:editor@%s/api/v1/ngalert/admin_config", grafanaListedAddr), http.StatusForbidden)
+	})
+
+	t.Run("no configuration returns 404", func(t *testing.T) {
+		getRequest(t, baseURL+"/admin_config", http.StatusNotFound)
+	})
+
+	t.Run("sending alerts to external alertmanagers requires an alertmanager", func(t *testing.T) {
+		postRequest(t, baseURL+"/admin_config", `{"alertmanagersChoice": "external"}`, http.StatusBadRequest)
+		postRequest(t, baseURL+"/admin_config", `{"alertmanagersChoice": "external", "alertmanagers": ["not a url"]}`, http.StatusBadRequest)
+		postRequest(t, baseURL+"/admin_config", `{"alertmanagersChoice": "unknown", "alertmanagers": ["http://localhost:9093"]}`, http.StatusBadRequest)
+	})
+
+	t.Run("save configuration", func(t *testing.T) {
+		postRequest(t, baseURL+"/admin_config", fmt.Sprintf(`{"alertmanagersChoice": "both", "alertmanagers": [%q]}`, fakeAM.URL), http.StatusCreated)
+
+		resp := getRequest(t, baseURL+"/admin_config", http.StatusOK)
+		require.JSONEq(t, fmt.Sprintf(`{"alertmanagersChoice": "both", "alertmanagers": [%q]}`, fakeAM.URL), getBody(t, resp.Body))
+
+		require.Eventually(t, func() bool {
+			var ams apimodels.GettableAlertmanagers
+			resp := getRequest(t, baseURL+"/alertmanagers", http.StatusOK)
+			require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &ams))
+			return len(ams.Data) == 1 && ams.Data[0].URL == fakeAM.URL && ams.Data[0].Healthy
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("save enrichment", func(t *testing.T) {
+		postRequest(t, baseURL+"/admin_config", `{"enrichment": {"rewrites": [{"action": "unknown", "regex": "pod"}]}}`, http.StatusBadRequest)
+		postRequest(t, baseURL+"/admin_config", `{"enrichment": {"labels": {"invalid-name": "value"}}}`, http.StatusBadRequest)
+
+		enrichment := `{
+			"labels": {"team": "ops"},
+			"folders": {"folder": {"annotations": {"runbook": "http://runbook"}}},
+			"rewrites": [{"action": "replace", "sourceLabel": "instance", "regex": "(.*):\\d+", "targetLabel": "host", "replacement": "$1"}],
+			"webhook": {"url": "http://enrichment:8080", "timeout": "5s"}
+		}`
+		postRequest(t, baseURL+"/admin_config", fmt.Sprintf(`{"enrichment": %s}`, enrichment), http.StatusCreated)
+
+		resp := getRequest(t, baseURL+"/admin_config", http.StatusOK)
+		require.JSONEq(t, fmt.Sprintf(`{"alertmanagersChoice": "internal", "alertmanagers": [], "enrichment": %s}`, enrichment), getBody(t, resp.Body))
+	})
+
+	t.Run("delete configuration", func(t *testing.T) {
+		deleteRequest(t, baseURL+"/admin_config", http.StatusOK)
+		getRequest(t, baseURL+"/admin_config", http.StatusNotFound)
+
+		resp := getRequest(t, baseURL+"/alertmanagers", http.StatusOK)
+		require.JSONEq(t, `{"status": "success", "data": []}`, getBody(t, resp.Body))
+	})
+}
@@ -661,6 +661,7 @@ func TestDeleteFolderWithRules(t *testing.T) {
 								],
 								"updated": "2021-05-19T19:47:55Z",
 								"intervalSeconds": 60,
+								"is_paused": false,
 								"version": 1,
 								"uid": "",
 								"namespace_uid": %q,
@@ -1121,6 +1122,7 @@ func TestAlertRuleCRUD(t *testing.T) {
 						  ],
 						  "updated":"2021-02-21T01:10:30Z",
 						  "intervalSeconds":60,
+						  "is_paused":false,
 						  "version":1,
 						  "uid":"uid",
 						  "namespace_uid":"nsuid",
@@ -1156,6 +1158,7 @@ func TestAlertRuleCRUD(t *testing.T) {
 						  ],
 						  "updated":"2021-02-21T01:10:30Z",
 						  "intervalSeconds":60,
+						  "is_paused":false,
 						  "version":1,
 						  "uid":"uid",
 						  "namespace_uid":"nsuid",
@@ -1383,6 +1386,7 @@ func TestAlertRuleCRUD(t *testing.T) {
 		                  ],
 		                  "updated":"2021-02-21T01:10:30Z",
 		                  "intervalSeconds":60,
+		                  "is_paused":false,
 		                  "version":2,
 		                  "uid":"uid",
 		                  "namespace_uid":"nsuid",
@@ -1507,6 +1511,7 @@ func TestAlertRuleCRUD(t *testing.T) {
 					  ],
 					  "updated":"2021-02-21T01:10:30Z",
 					  "intervalSeconds":60,
+					  "is_paused":false,
 					  "version":3,
 					  "uid":"uid",
 					  "namespace_uid":"nsuid",
@@ -1606,6 +1611,7 @@ func TestAlertRuleCRUD(t *testing.T) {
 					  ],
 					  "updated":"2021-02-21T01:10:30Z",
 					  "intervalSeconds":60,
+					  "is_paused":false,
 					  "version":4,
 					  "uid":"uid",
 					  "namespace_uid":"nsuid",
@@ -1819,6 +1825,66 @@ func TestQuota(t *testing.T) {
 	})
 }
 
+func TestFolderQuota(t *testing.T) {
+	// Setup Grafana and its Database
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		EnableQuota:          true,
+		FolderAlertRuleQuota: 2,
+		DisableAnonymous:     true,
+	})
+
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	_, err := createFolder(t, store, 0, "default")
+	require.NoError(t, err)
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "grafana", "password"))
+
+	ruleGroup := func(name string, titles ...string) string {
+		rules := make([]string, 0, len(titles))
+		for _, title := range titles {
+			rules = append(rules, fmt.Sprintf(`{
+				"grafana_alert": {
+					"title": %q,
+					"condition": "A",
+					"data": [{
+						"refId": "A",
+						"relativeTimeRange": {"from": 18000, "to": 10800},
+						"datasourceUid": "-100",
+						"model": {"type": "math", "expression": "2 + 3 > 1"}
+					}]
+				}
+			}`, title))
+		}
+		return fmt.Sprintf(`{"name": %q, "interval": "1m", "rules": [%s]}`, name, strings.Join(rules, ","))
+	}
+	u := fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr)
+
+	t.Run("rules are created up to the folder quota", func(t *testing.T) {
+		postRequest(t, u, ruleGroup("group1", "rule1", "rule2"), http.StatusAccepted)
+	})
+
+	t.Run("rules over the folder quota are rejected", func(t *testing.T) {
+		resp := postRequest(t, u, ruleGroup("group2", "rule3"), http.StatusForbidden)
+		require.JSONEq(t, `{"message":"folder quota reached: the folder can't have more than 2 alert rules"}`, getBody(t, resp.Body))
+	})
+
+	t.Run("the rules of a rule group are replaced", func(t *testing.T) {
+		postRequest(t, u, ruleGroup("group1", "rule3", "rule4"), http.StatusAccepted)
+		postRequest(t, u, ruleGroup("group1", "rule5"), http.StatusAccepted)
+		postRequest(t, u, ruleGroup("group2", "rule6"), http.StatusAccepted)
+	})
+
+	t.Run("the alert instances of the org are counted", func(t *testing.T) {
+		query := models.GetOrgQuotaByTargetQuery{OrgId: 1, Target: "alert_instance", Default: -1, IsNgAlertEnabled: true}
+		require.NoError(t, sqlstore.GetOrgQuotaByTarget(&query))
+		require.Equal(t, int64(-1), query.Result.Limit)
+	})
+}
+
 func TestEval(t *testing.T) {
 	// Setup Grafana and its Database
 	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
@@ -203,6 +203,22 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Include image",
+        "description": "Include a screenshot of the panel the firing alert is linked to, requires the image renderer",
+        "placeholder": "",
+        "propertyName": "includeImage",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
       }
     ]
   },
@@ -970,6 +986,22 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Include image",
+        "description": "Include a screenshot of the panel the firing alert is linked to, requires the image renderer. Without external image storage the image is uploaded to the recipient with the token",
+        "placeholder": "",
+        "propertyName": "includeImage",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
       }
     ]
   },
@@ -1230,6 +1262,10 @@ var expAvailableChannelJsonOutput = `
           {
             "value": "PUT",
             "label": "PUT"
+          },
+          {
+            "value": "PATCH",
+            "label": "PATCH"
           }
         ],
         "showWhen": {
@@ -1287,6 +1323,70 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Custom Headers",
+        "description": "Headers added to the request, one \"Name: value\" header per line",
+        "placeholder": "",
+        "propertyName": "headers",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "HMAC Secret",
+        "description": "Secret the payload is signed with, the HMAC-SHA256 signature of the timestamp and the body joined with a dot is sent in the X-Grafana-Alerting-Signature header and the timestamp in the X-Grafana-Alerting-Timestamp header",
+        "placeholder": "",
+        "propertyName": "hmacSecret",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Max Retries",
+        "description": "Number of times the request is retried when it fails with a 5xx or 429 status or without response. 0 means no retry.",
+        "placeholder": "",
+        "propertyName": "maxRetries",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Retry Backoff",
+        "description": "Time to wait before the first retry, it's doubled for every following retry. Default is 1s.",
+        "placeholder": "1s",
+        "propertyName": "retryBackoff",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
       }
     ]
   },
@@ -1612,6 +1712,31 @@ var expAvailableChannelJsonOutput = `
         "secure": false
       }
     ]
+  },
+  {
+    "type": "escalation",
+    "name": "Escalation",
+    "heading": "Escalation settings",
+    "description": "Notifies the contact points of an escalation policy one after the other until acknowledged",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Escalation policy UID",
+        "description": "The UID of the escalation policy whose steps are notified",
+        "placeholder": "",
+        "propertyName": "policyUid",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
   }
 ]
 `
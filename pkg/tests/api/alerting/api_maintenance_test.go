@@ -0,0 +1,60 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestMaintenance(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+	require.NoError(t, createUser(t, store, models.ROLE_VIEWER, "viewer", "viewer"))
+
+	maintenanceURL := fmt.Sprintf("http://editor:editor@%s/api/alertmanager/grafana/api/v2/maintenance", grafanaListedAddr)
+
+	t.Run("organisation without maintenance returns 404", func(t *testing.T) {
+		getRequest(t, maintenanceURL, http.StatusNotFound)
+	})
+
+	t.Run("invalid maintenances are rejected", func(t *testing.T) {
+		putRequest(t, maintenanceURL, `{"matchers": ["severity=~("]}`, http.StatusBadRequest)
+		putRequest(t, maintenanceURL, `{"endsAt": "2021-01-01T00:00:00Z"}`, http.StatusBadRequest)
+		putRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/alertmanager/grafana/api/v2/maintenance", grafanaListedAddr), `{}`, http.StatusForbidden)
+	})
+
+	t.Run("save and delete maintenance", func(t *testing.T) {
+		resp := putRequest(t, maintenanceURL, `{"endsAt": "2031-01-01T00:00:00Z", "matchers": ["severity=\"warning\""], "comment": "upgrade"}`, http.StatusOK)
+		var saved map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &saved))
+		require.Equal(t, "2031-01-01T00:00:00Z", saved["endsAt"])
+		require.Equal(t, []interface{}{`severity="warning"`}, saved["matchers"])
+		require.Equal(t, "upgrade", saved["comment"])
+		require.Equal(t, "editor", saved["createdBy"])
+		require.Equal(t, true, saved["active"])
+
+		resp = getRequest(t, maintenanceURL, http.StatusOK)
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &got))
+		require.Equal(t, saved, got)
+
+		deleteRequest(t, maintenanceURL, http.StatusNoContent)
+		getRequest(t, maintenanceURL, http.StatusNotFound)
+		deleteRequest(t, maintenanceURL, http.StatusNotFound)
+	})
+}
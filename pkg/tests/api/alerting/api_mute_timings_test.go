@@ -0,0 +1,80 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestMuteTimeIntervals(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		AnonymousUserRole:    models.ROLE_EDITOR,
+	})
+
+	store := testinfra.SetUpDatabase(t, dir)
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+	muteTimingsURL := fmt.Sprintf("http://%s/api/alertmanager/grafana/config/api/v1/mute-timings", grafanaListedAddr)
+	alertConfigURL := fmt.Sprintf("http://%s/api/alertmanager/grafana/config/api/v1/alerts", grafanaListedAddr)
+
+	configWithRoute := func(muteTimeInterval string) string {
+		return fmt.Sprintf(`{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "email",
+			"routes": [{"receiver": "email", "mute_time_intervals": [%q]}]
+		},
+		"mute_time_intervals": [{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}],
+		"receivers": [{
+			"name": "email",
+			"grafana_managed_receiver_configs": [{"name": "email", "type": "email", "settings": {"addresses": "test@example.com"}}]
+		}]
+	}
+}`, muteTimeInterval)
+	}
+
+	t.Run("create, get and update mute time intervals", func(t *testing.T) {
+		postRequest(t, muteTimingsURL, `{"name": "weekends", "time_intervals": [{"weekdays": ["saturday"]}]}`, http.StatusCreated)
+		postRequest(t, muteTimingsURL, `{"name": "weekends", "time_intervals": []}`, http.StatusConflict)
+		postRequest(t, muteTimingsURL, `{"name": "", "time_intervals": []}`, http.StatusBadRequest)
+
+		putRequest(t, muteTimingsURL+"/weekends", `{"time_intervals": [{"weekdays": ["saturday", "sunday"]}]}`, http.StatusOK)
+		putRequest(t, muteTimingsURL+"/unknown", `{"time_intervals": []}`, http.StatusNotFound)
+
+		var muteTiming apimodels.MuteTimeInterval
+		resp := getRequest(t, muteTimingsURL+"/weekends", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &muteTiming))
+		require.Equal(t, "weekends", muteTiming.Name)
+		require.Len(t, muteTiming.TimeIntervals, 1)
+		require.Len(t, muteTiming.TimeIntervals[0].Weekdays, 2)
+
+		var muteTimings apimodels.MuteTimeIntervals
+		resp = getRequest(t, muteTimingsURL, http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &muteTimings))
+		require.Len(t, muteTimings, 1)
+
+		getRequest(t, muteTimingsURL+"/unknown", http.StatusNotFound)
+	})
+
+	t.Run("routes can only reference defined mute time intervals", func(t *testing.T) {
+		resp := postRequest(t, alertConfigURL, configWithRoute("unknown"), http.StatusBadRequest) // nolint
+		require.Contains(t, getBody(t, resp.Body), `undefined mute time interval \"unknown\" used in route`)
+
+		postRequest(t, alertConfigURL, configWithRoute("weekends"), http.StatusAccepted)
+	})
+
+	t.Run("referenced mute time intervals can't be deleted", func(t *testing.T) {
+		deleteRequest(t, muteTimingsURL+"/weekends", http.StatusConflict)
+
+		postRequest(t, muteTimingsURL, `{"name": "nights", "time_intervals": [{"times": [{"start_time": "00:00", "end_time": "06:00"}]}]}`, http.StatusCreated)
+		deleteRequest(t, muteTimingsURL+"/nights", http.StatusNoContent)
+		getRequest(t, muteTimingsURL+"/nights", http.StatusNotFound)
+	})
+}
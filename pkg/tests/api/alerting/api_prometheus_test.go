@@ -258,3 +258,97 @@ func TestPrometheusRules(t *testing.T) {
 		}, 18*time.Second, 2*time.Second)
 	}
 }
+
+func TestPrometheusCompatAPI(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	_, err := createFolder(t, store, 0, "default")
+	require.NoError(t, err)
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "grafana", "password"))
+
+	baseURL := fmt.Sprintf("http://grafana:password@%s/api/prometheus-compat/api/v1", grafanaListedAddr)
+
+	t.Run("unauthenticated requests fail", func(t *testing.T) {
+		getRequest(t, fmt.Sprintf("http://%s/api/prometheus-compat/api/v1/rules", grafanaListedAddr), http.StatusUnauthorized)
+	})
+
+	t.Run("no rules and no alerts", func(t *testing.T) {
+		resp := getRequest(t, baseURL+"/rules", http.StatusOK)
+		require.JSONEq(t, `{"status": "success", "data": {"groups": []}}`, getBody(t, resp.Body))
+		resp = getRequest(t, baseURL+"/alerts", http.StatusOK)
+		require.JSONEq(t, `{"status": "success", "data": {"alerts": []}}`, getBody(t, resp.Body))
+	})
+
+	postRequest(t, fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), `{
+		"name": "arulegroup",
+		"interval": "1m",
+		"rules": [{
+			"for": "10s",
+			"labels": {"label1": "val1"},
+			"annotations": {"annotation1": "val1"},
+			"grafana_alert": {
+				"title": "AlwaysFiring",
+				"condition": "A",
+				"data": [{
+					"refId": "A",
+					"relativeTimeRange": {"from": 18000, "to": 10800},
+					"datasourceUid": "-100",
+					"model": {"type": "math", "expression": "2 + 3 > 1"}
+				}]
+			}
+		}]
+	}`, http.StatusAccepted)
+
+	expectedRules := `
+{
+	"status": "success",
+	"data": {
+		"groups": [{
+			"name": "arulegroup",
+			"file": "default",
+			"rules": [{
+				"state": "inactive",
+				"name": "AlwaysFiring",
+				"query": "[{\"refId\":\"A\",\"queryType\":\"\",\"relativeTimeRange\":{\"from\":18000,\"to\":10800},\"datasourceUid\":\"-100\",\"model\":{\"expression\":\"2 + 3 \\u003e 1\",\"intervalMs\":1000,\"maxDataPoints\":43200,\"type\":\"math\"}}]",
+				"duration": 10,
+				"labels": {"label1": "val1"},
+				"annotations": {"annotation1": "val1"},
+				"alerts": [],
+				"health": "unknown",
+				"evaluationTime": 0,
+				"lastEvaluation": "0001-01-01T00:00:00Z",
+				"type": "alerting"
+			}],
+			"interval": 60,
+			"evaluationTime": 0,
+			"lastEvaluation": "0001-01-01T00:00:00Z"
+		}]
+	}
+}`
+
+	t.Run("the rules are listed in the schema of Prometheus", func(t *testing.T) {
+		resp := getRequest(t, baseURL+"/rules", http.StatusOK)
+		require.JSONEq(t, expectedRules, getBody(t, resp.Body))
+		resp = getRequest(t, baseURL+"/rules?type=alert&rule_group[]=arulegroup&file[]=default", http.StatusOK)
+		require.JSONEq(t, expectedRules, getBody(t, resp.Body))
+	})
+
+	t.Run("the rules are filtered", func(t *testing.T) {
+		resp := getRequest(t, baseURL+"/rules?type=record", http.StatusOK)
+		require.JSONEq(t, `{"status": "success", "data": {"groups": []}}`, getBody(t, resp.Body))
+		resp = getRequest(t, baseURL+"/rules?rule_name[]=other", http.StatusOK)
+		require.JSONEq(t, `{"status": "success", "data": {"groups": []}}`, getBody(t, resp.Body))
+	})
+
+	t.Run("an invalid type is rejected", func(t *testing.T) {
+		resp := getRequest(t, baseURL+"/rules?type=invalid", http.StatusBadRequest)
+		require.JSONEq(t, `{"status": "error", "errorType": "bad_data", "error": "unsupported type \"invalid\""}`, getBody(t, resp.Body))
+	})
+}
@@ -0,0 +1,228 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestProvisioningAPI(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+	require.NoError(t, createUser(t, store, models.ROLE_VIEWER, "viewer", "viewer"))
+
+	folderUID, err := createFolder(t, store, 0, "default")
+	require.NoError(t, err)
+
+	baseURL := fmt.Sprintf("http://editor:editor@%s/api/v1/provisioning", grafanaListedAddr)
+
+	t.Run("viewer can't use the provisioning API", func(t *testing.T) {
+		getRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/v1/provisioning/contact-points", grafanaListedAddr), http.StatusForbidden)
+	})
+
+	var contactPoint apimodels.EmbeddedContactPoint
+	t.Run("create contact point", func(t *testing.T) {
+		resp := postRequest(t, baseURL+"/contact-points", `{
+			"name": "provisioned",
+			"type": "webhook",
+			"settings": {"url": "http://localhost/webhook"},
+			"secureSettings": {"password": "secret"}
+		}`, http.StatusCreated)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &contactPoint))
+
+		assert.NotEmpty(t, contactPoint.UID)
+		assert.Equal(t, ngmodels.ProvenanceAPI, contactPoint.Provenance)
+		assert.Empty(t, contactPoint.SecureSettings)
+		assert.Equal(t, map[string]bool{"password": true}, contactPoint.SecureFields)
+
+		var contactPoints apimodels.ContactPoints
+		resp = getRequest(t, baseURL+"/contact-points", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &contactPoints))
+		require.Len(t, contactPoints, 2)
+		assert.Equal(t, "provisioned", contactPoints[1].Name)
+		assert.Equal(t, ngmodels.ProvenanceAPI, contactPoints[1].Provenance)
+	})
+
+	t.Run("Alertmanager configuration can't change provisioned contact point", func(t *testing.T) {
+		resp := getRequest(t, fmt.Sprintf("http://editor:editor@%s/api/alertmanager/grafana/config/api/v1/alerts", grafanaListedAddr), http.StatusOK)
+		config := strings.Replace(getBody(t, resp.Body), "http://localhost/webhook", "http://localhost/changed", 1)
+		postRequest(t, fmt.Sprintf("http://editor:editor@%s/api/alertmanager/grafana/config/api/v1/alerts", grafanaListedAddr), config, http.StatusConflict)
+
+		resp = getRequest(t, fmt.Sprintf("http://editor:editor@%s/api/alertmanager/grafana/config/api/v1/alerts", grafanaListedAddr), http.StatusOK)
+		unchanged := getBody(t, resp.Body)
+		postRequest(t, fmt.Sprintf("http://editor:editor@%s/api/alertmanager/grafana/config/api/v1/alerts", grafanaListedAddr), unchanged, http.StatusAccepted)
+	})
+
+	t.Run("update contact point keeps secure settings", func(t *testing.T) {
+		putRequest(t, baseURL+"/contact-points/"+contactPoint.UID, `{
+			"name": "provisioned",
+			"type": "webhook",
+			"settings": {"url": "http://localhost/changed"}
+		}`, http.StatusAccepted)
+
+		var contactPoints apimodels.ContactPoints
+		resp := getRequest(t, baseURL+"/contact-points", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &contactPoints))
+		require.Len(t, contactPoints, 2)
+		assert.Equal(t, "http://localhost/changed", contactPoints[1].Settings.Get("url").MustString())
+		assert.Equal(t, map[string]bool{"password": true}, contactPoints[1].SecureFields)
+	})
+
+	t.Run("update unknown contact point fails", func(t *testing.T) {
+		putRequest(t, baseURL+"/contact-points/unknown", `{"name": "unknown", "type": "webhook", "settings": {}}`, http.StatusNotFound)
+	})
+
+	t.Run("mute timings", func(t *testing.T) {
+		postRequest(t, baseURL+"/mute-timings", `{
+			"name": "weekends",
+			"time_intervals": [{"weekdays": ["saturday", "sunday"]}]
+		}`, http.StatusCreated)
+		postRequest(t, baseURL+"/mute-timings", `{"name": "weekends", "time_intervals": []}`, http.StatusConflict)
+
+		var muteTiming apimodels.ProvisionedMuteTimeInterval
+		resp := getRequest(t, baseURL+"/mute-timings/weekends", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &muteTiming))
+		assert.Equal(t, "weekends", muteTiming.Name)
+		assert.Len(t, muteTiming.TimeIntervals, 1)
+		assert.Equal(t, ngmodels.ProvenanceAPI, muteTiming.Provenance)
+
+		getRequest(t, baseURL+"/mute-timings/unknown", http.StatusNotFound)
+	})
+
+	t.Run("notification policies", func(t *testing.T) {
+		putRequest(t, baseURL+"/policies", `{"route": {"receiver": "unknown"}}`, http.StatusBadRequest)
+		putRequest(t, baseURL+"/policies", `{"route": {"receiver": "grafana-default-email", "routes": [{"receiver": "provisioned", "mute_time_intervals": ["unknown"]}]}}`, http.StatusBadRequest)
+		putRequest(t, baseURL+"/policies", `{"route": {"receiver": "grafana-default-email", "routes": [{"receiver": "provisioned", "mute_time_intervals": ["weekends"]}]}}`, http.StatusAccepted)
+
+		var tree apimodels.NotificationPolicyTree
+		resp := getRequest(t, baseURL+"/policies", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &tree))
+		require.Len(t, tree.Route.Routes, 1)
+		assert.Equal(t, "provisioned", tree.Route.Routes[0].Receiver)
+		assert.Equal(t, ngmodels.ProvenanceAPI, tree.Provenance)
+
+		// referenced resources can't be deleted
+		deleteRequest(t, baseURL+"/contact-points/"+contactPoint.UID, http.StatusConflict)
+		deleteRequest(t, baseURL+"/mute-timings/weekends", http.StatusConflict)
+	})
+
+	var rule apimodels.ProvisionedAlertRule
+	t.Run("create alert rule", func(t *testing.T) {
+		resp := postRequest(t, baseURL+"/alert-rules", fmt.Sprintf(`{
+			"uid": "provisioned-rule",
+			"folderUID": "%s",
+			"ruleGroup": "provisioned",
+			"title": "provisioned rule",
+			"condition": "A",
+			"data": [{
+				"refId": "A",
+				"datasourceUid": "-100",
+				"relativeTimeRange": {"from": 600, "to": 0},
+				"model": {"type": "math", "expression": "2 + 3 > 1"}
+			}],
+			"for": "1m"
+		}`, folderUID), http.StatusCreated)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &rule))
+
+		assert.Equal(t, "provisioned-rule", rule.UID)
+		assert.Equal(t, ngmodels.ProvenanceAPI, rule.Provenance)
+		assert.Equal(t, int64(60), rule.IntervalSeconds)
+
+		resp = getRequest(t, baseURL+"/alert-rules/provisioned-rule", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &rule))
+		assert.Equal(t, "provisioned rule", rule.Title)
+		assert.Equal(t, ngmodels.ProvenanceAPI, rule.Provenance)
+	})
+
+	t.Run("ruler API can't change provisioned alert rule", func(t *testing.T) {
+		deleteRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default/provisioned", grafanaListedAddr), http.StatusConflict)
+		deleteRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), http.StatusConflict)
+
+		postRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), `{
+			"name": "provisioned",
+			"interval": "1m",
+			"rules": []
+		}`, http.StatusConflict)
+
+		// posting the group with the unchanged provisioned rule is accepted
+		resp := getRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default/provisioned", grafanaListedAddr), http.StatusAccepted)
+		var group apimodels.RuleGroupConfigResponse
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &group))
+		require.Len(t, group.Rules, 1)
+		postable := apimodels.PostableRuleGroupConfig{Name: group.Name, Interval: group.Interval}
+		for _, r := range group.Rules {
+			postable.Rules = append(postable.Rules, apimodels.PostableExtendedRuleNode{
+				ApiRuleNode: r.ApiRuleNode,
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					UID:          r.GrafanaManagedAlert.UID,
+					Title:        r.GrafanaManagedAlert.Title,
+					Condition:    r.GrafanaManagedAlert.Condition,
+					Data:         r.GrafanaManagedAlert.Data,
+					NoDataState:  r.GrafanaManagedAlert.NoDataState,
+					ExecErrState: r.GrafanaManagedAlert.ExecErrState,
+				},
+			})
+		}
+		body, err := json.Marshal(postable)
+		require.NoError(t, err)
+		postRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), string(body), http.StatusAccepted)
+
+		postable.Rules[0].GrafanaManagedAlert.Title = "changed in the UI"
+		body, err = json.Marshal(postable)
+		require.NoError(t, err)
+		postRequest(t, fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules/default", grafanaListedAddr), string(body), http.StatusConflict)
+	})
+
+	t.Run("update alert rule", func(t *testing.T) {
+		rule.Title = "updated rule"
+		body, err := json.Marshal(rule)
+		require.NoError(t, err)
+
+		resp := putRequest(t, baseURL+"/alert-rules/provisioned-rule", string(body), http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &rule))
+		assert.Equal(t, "updated rule", rule.Title)
+
+		putRequest(t, baseURL+"/alert-rules/unknown", string(body), http.StatusNotFound)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		var export apimodels.ProvisioningExport
+		resp := getRequest(t, baseURL+"/export", http.StatusOK)
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &export))
+		require.Len(t, export.AlertRules, 1)
+		assert.Equal(t, "updated rule", export.AlertRules[0].Title)
+		assert.Len(t, export.ContactPoints, 2)
+		assert.Len(t, export.MuteTimings, 1)
+		require.NotNil(t, export.Policies)
+
+		resp = getRequest(t, baseURL+"/export?format=yaml", http.StatusOK)
+		assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+		yml := getBody(t, resp.Body)
+		assert.Contains(t, yml, "title: updated rule")
+		assert.Contains(t, yml, "name: weekends")
+	})
+
+	t.Run("delete alert rule", func(t *testing.T) {
+		deleteRequest(t, baseURL+"/alert-rules/provisioned-rule", http.StatusNoContent)
+		getRequest(t, baseURL+"/alert-rules/provisioned-rule", http.StatusNotFound)
+	})
+}
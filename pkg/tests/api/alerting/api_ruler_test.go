@@ -103,6 +103,7 @@ func TestAlertRulePermissions(t *testing.T) {
 		                  ],
 						  "updated":"2021-02-21T01:10:30Z",
 						  "intervalSeconds":60,
+						  "is_paused":false,
 						  "version":1,
 						  "uid":"uid",
 						  "namespace_uid":"nsuid",
@@ -153,6 +154,7 @@ func TestAlertRulePermissions(t *testing.T) {
 		                  ],
 						"updated":"2021-02-21T01:10:30Z",
 						"intervalSeconds":60,
+						"is_paused":false,
 						"version":1,
 						"uid":"uid",
 						"namespace_uid":"nsuid",
@@ -225,6 +227,7 @@ func TestAlertRulePermissions(t *testing.T) {
 		                  ],
 						  "updated":"2021-02-21T01:10:30Z",
 						  "intervalSeconds":60,
+						  "is_paused":false,
 						  "version":1,
 						  "uid":"uid",
 						  "namespace_uid":"nsuid",
@@ -299,6 +302,87 @@ func createRule(t *testing.T, grafanaListedAddr string, folder string, user, pas
 	require.JSONEq(t, `{"message":"rule group updated successfully"}`, string(b))
 }
 
+func TestPatchRuleGroup(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	_, err := createFolder(t, store, 0, "folder1")
+	require.NoError(t, err)
+	_, err = createFolder(t, store, 0, "folder2")
+	require.NoError(t, err)
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+
+	createRule(t, grafanaListedAddr, "folder1", "editor", "editor")
+	createRule(t, grafanaListedAddr, "folder2", "editor", "editor")
+	baseURL := fmt.Sprintf("http://editor:editor@%s/api/ruler/grafana/api/v1/rules", grafanaListedAddr)
+
+	t.Run("the rule group is renamed and moved with a new interval", func(t *testing.T) {
+		patchRequest(t, baseURL+"/folder1/arulegroup", `{"name": "renamed", "namespace": "folder2", "interval": "2m"}`, http.StatusAccepted)
+
+		resp := getRequest(t, baseURL+"/folder2/renamed", http.StatusAccepted)
+		body := getBody(t, resp.Body)
+		require.Contains(t, body, `"interval":"2m"`)
+		require.Contains(t, body, `"title":"rule under folder folder1"`)
+		resp = getRequest(t, baseURL+"/folder1", http.StatusAccepted)
+		require.JSONEq(t, `{}`, getBody(t, resp.Body))
+	})
+
+	t.Run("a rule group can't replace an existing rule group", func(t *testing.T) {
+		patchRequest(t, baseURL+"/folder2/renamed", `{"name": "arulegroup"}`, http.StatusConflict)
+	})
+
+	t.Run("the order must contain every rule of the group", func(t *testing.T) {
+		patchRequest(t, baseURL+"/folder2/renamed", `{"rule_uids": ["unknown"]}`, http.StatusBadRequest)
+	})
+
+	t.Run("patching an unknown rule group fails", func(t *testing.T) {
+		patchRequest(t, baseURL+"/folder1/unknown", `{"name": "renamed"}`, http.StatusNotFound)
+	})
+}
+
+func TestRulesByDatasource(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	_, err := createFolder(t, store, 0, "folder1")
+	require.NoError(t, err)
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+
+	createRule(t, grafanaListedAddr, "folder1", "editor", "editor")
+	baseURL := fmt.Sprintf("http://editor:editor@%s/api/v1/rules", grafanaListedAddr)
+
+	t.Run("the rules querying the data source are listed with their folder", func(t *testing.T) {
+		resp := getRequest(t, baseURL+"?datasourceUID=-100", http.StatusOK)
+		var result apimodels.DatasourceRules
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &result))
+		require.Len(t, result.Rules, 1)
+		require.Equal(t, "rule under folder folder1", result.Rules[0].Title)
+		require.Equal(t, "folder1", result.Rules[0].FolderTitle)
+		require.Equal(t, "arulegroup", result.Rules[0].RuleGroup)
+	})
+
+	t.Run("no rules are listed for another data source", func(t *testing.T) {
+		resp := getRequest(t, baseURL+"?datasourceUID=unknown", http.StatusOK)
+		require.JSONEq(t, `{"datasourceUID": "unknown", "rules": []}`, getBody(t, resp.Body))
+	})
+
+	t.Run("the data source is required", func(t *testing.T) {
+		getRequest(t, baseURL, http.StatusBadRequest)
+	})
+}
+
 func TestAlertRuleConflictingTitle(t *testing.T) {
 	// Setup Grafana and its Database
 	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
@@ -0,0 +1,67 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestSilenceExpiryNotification(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "editor", "editor"))
+	require.NoError(t, createUser(t, store, models.ROLE_VIEWER, "viewer", "viewer"))
+
+	baseURL := fmt.Sprintf("http://editor:editor@%s/api/alertmanager/grafana/api/v2", grafanaListedAddr)
+	resp := postRequest(t, baseURL+"/silences", `{
+		"comment": "maintenance",
+		"createdBy": "editor",
+		"matchers": [{"name": "alertname", "value": "rule", "isRegex": false}],
+		"startsAt": "2021-01-01T00:00:00Z",
+		"endsAt": "2031-01-01T00:00:00Z"
+	}`, http.StatusAccepted)
+	var created struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &created))
+	notificationURL := fmt.Sprintf("%s/silence/%s/expiry-notification", baseURL, created.ID)
+
+	t.Run("silence without expiry notification returns 404", func(t *testing.T) {
+		getRequest(t, notificationURL, http.StatusNotFound)
+	})
+
+	t.Run("invalid expiry notifications are rejected", func(t *testing.T) {
+		putRequest(t, notificationURL, `{"receiver": "unknown", "notifyBefore": "30m"}`, http.StatusBadRequest)
+		putRequest(t, notificationURL, `{"receiver": "grafana-default-email", "notifyBefore": "0s"}`, http.StatusBadRequest)
+		putRequest(t, baseURL+"/silence/unknown/expiry-notification", `{"receiver": "grafana-default-email", "notifyBefore": "30m"}`, http.StatusNotFound)
+		putRequest(t, fmt.Sprintf("http://viewer:viewer@%s/api/alertmanager/grafana/api/v2/silence/%s/expiry-notification", grafanaListedAddr, created.ID),
+			`{"receiver": "grafana-default-email", "notifyBefore": "30m"}`, http.StatusForbidden)
+	})
+
+	t.Run("save and delete expiry notification", func(t *testing.T) {
+		resp := putRequest(t, notificationURL, `{"receiver": "grafana-default-email", "notifyBefore": "30m"}`, http.StatusOK)
+		expected := fmt.Sprintf(`{"silenceId": %q, "receiver": "grafana-default-email", "notifyBefore": "30m"}`, created.ID)
+		require.JSONEq(t, expected, getBody(t, resp.Body))
+
+		resp = getRequest(t, notificationURL, http.StatusOK)
+		require.JSONEq(t, expected, getBody(t, resp.Body))
+
+		deleteRequest(t, notificationURL, http.StatusNoContent)
+		getRequest(t, notificationURL, http.StatusNotFound)
+		deleteRequest(t, notificationURL, http.StatusNotFound)
+	})
+}
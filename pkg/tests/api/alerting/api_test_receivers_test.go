@@ -0,0 +1,121 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/tests/testinfra"
+)
+
+func TestTestReceivers(t *testing.T) {
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		AnonymousUserRole:    models.ROLE_EDITOR,
+	})
+
+	store := testinfra.SetUpDatabase(t, dir)
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+	testReceiversURL := fmt.Sprintf("http://%s/api/alertmanager/grafana/config/api/v1/receivers/test", grafanaListedAddr)
+
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(okServer.Close)
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(failingServer.Close)
+
+	t.Run("all integrations succeed", func(t *testing.T) {
+		payload := fmt.Sprintf(`
+{
+	"alert": {
+		"labels": {"team": "alerting"}
+	},
+	"receivers": [{
+		"name": "webhook-receiver",
+		"grafana_managed_receiver_configs": [{
+			"name": "ok",
+			"type": "webhook",
+			"settings": {"url": %q}
+		}]
+	}]
+}`, okServer.URL)
+		resp := postRequest(t, testReceiversURL, payload, http.StatusOK) // nolint
+		var result apimodels.TestReceiversResult
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &result))
+
+		require.Equal(t, "alerting", string(result.Alert.Labels["team"]))
+		require.Equal(t, "TestAlert", string(result.Alert.Labels["alertname"]))
+		require.Len(t, result.Receivers, 1)
+		require.Equal(t, "webhook-receiver", result.Receivers[0].Name)
+		require.Equal(t, []apimodels.TestReceiverConfigResult{{Name: "ok", Status: "ok"}}, result.Receivers[0].Configs)
+	})
+
+	t.Run("some integrations fail", func(t *testing.T) {
+		payload := fmt.Sprintf(`
+{
+	"receivers": [{
+		"name": "webhook-receiver",
+		"grafana_managed_receiver_configs": [{
+			"name": "ok",
+			"type": "webhook",
+			"settings": {"url": %q}
+		}, {
+			"name": "failing",
+			"type": "webhook",
+			"settings": {"url": %q}
+		}]
+	}]
+}`, okServer.URL, failingServer.URL)
+		resp := postRequest(t, testReceiversURL, payload, http.StatusMultiStatus) // nolint
+		var result apimodels.TestReceiversResult
+		require.NoError(t, json.Unmarshal([]byte(getBody(t, resp.Body)), &result))
+
+		configs := result.Receivers[0].Configs
+		require.Len(t, configs, 2)
+		require.Equal(t, "ok", configs[0].Status)
+		require.Equal(t, "failed", configs[1].Status)
+		require.Contains(t, configs[1].Error, "500")
+	})
+
+	t.Run("invalid integration is rejected", func(t *testing.T) {
+		payload := `
+{
+	"receivers": [{
+		"name": "slack-receiver",
+		"grafana_managed_receiver_configs": [{
+			"name": "slack",
+			"type": "slack",
+			"settings": {"recipient": "#unified-alerting-test"}
+		}]
+	}]
+}`
+		resp := postRequest(t, testReceiversURL, payload, http.StatusBadRequest) // nolint
+		require.Contains(t, getBody(t, resp.Body), "token must be specified when using the Slack chat API")
+	})
+
+	t.Run("unknown receiver UID is rejected", func(t *testing.T) {
+		payload := fmt.Sprintf(`
+{
+	"receivers": [{
+		"name": "webhook-receiver",
+		"grafana_managed_receiver_configs": [{
+			"uid": "unknown",
+			"name": "ok",
+			"type": "webhook",
+			"settings": {"url": %q}
+		}]
+	}]
+}`, okServer.URL)
+		resp := postRequest(t, testReceiversURL, payload, http.StatusBadRequest) // nolint
+		require.JSONEq(t, `{"message": "unknown receiver: unknown"}`, getBody(t, resp.Body))
+	})
+}
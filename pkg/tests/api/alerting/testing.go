@@ -68,6 +68,39 @@ func postRequest(t *testing.T, url string, body string, expStatusCode int) *http
 	return resp
 }
 
+func putRequest(t *testing.T, url string, body string, expStatusCode int) *http.Response {
+	t.Helper()
+	return sendRequest(t, http.MethodPut, url, body, expStatusCode)
+}
+
+func patchRequest(t *testing.T, url string, body string, expStatusCode int) *http.Response {
+	t.Helper()
+	return sendRequest(t, http.MethodPatch, url, body, expStatusCode)
+}
+
+func deleteRequest(t *testing.T, url string, expStatusCode int) *http.Response {
+	t.Helper()
+	return sendRequest(t, http.MethodDelete, url, "", expStatusCode)
+}
+
+func sendRequest(t *testing.T, method, url string, body string, expStatusCode int) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, resp.Body.Close())
+	})
+	if expStatusCode != resp.StatusCode {
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		t.Fatal(string(b))
+	}
+	return resp
+}
+
 func getBody(t *testing.T, body io.ReadCloser) string {
 	t.Helper()
 	b, err := ioutil.ReadAll(body)
@@ -226,6 +226,10 @@ func CreateGrafDir(t *testing.T, opts ...GrafanaOpts) (string, string) {
 			require.NoError(t, err)
 			_, err = quotaSection.NewKey("enabled", "true")
 			require.NoError(t, err)
+			if o.FolderAlertRuleQuota != 0 {
+				_, err = quotaSection.NewKey("folder_alert_rule", fmt.Sprint(o.FolderAlertRuleQuota))
+				require.NoError(t, err)
+			}
 		}
 		if o.DisableAnonymous {
 			anonSect, err := cfg.GetSection("auth.anonymous")
@@ -262,6 +266,7 @@ type GrafanaOpts struct {
 	EnableFeatureToggles []string
 	AnonymousUserRole    models.RoleType
 	EnableQuota          bool
+	FolderAlertRuleQuota int64
 	DisableAnonymous     bool
 	CatalogAppEnabled    bool
 	ViewersCanEdit       bool
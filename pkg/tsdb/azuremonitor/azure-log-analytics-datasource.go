@@ -21,6 +21,9 @@ import (
 	"golang.org/x/net/context/ctxhttp"
 )
 
+// defaultLogAnalyticsMaxRows is the maximum number of rows of a query result unless configured otherwise
+const defaultLogAnalyticsMaxRows = 100000
+
 // AzureLogAnalyticsDatasource calls the Azure Log Analytics API's
 type AzureLogAnalyticsDatasource struct{}
 
@@ -34,6 +37,9 @@ type AzureLogAnalyticsQuery struct {
 	Params       url.Values
 	Target       string
 	TimeRange    backend.TimeRange
+
+	// Chunks are queries for parts of the time range when the query is split
+	Chunks []*AzureLogAnalyticsQuery
 }
 
 // executeTimeSeriesQuery does the following:
@@ -105,7 +111,7 @@ func (e *AzureLogAnalyticsDatasource) buildQueries(queries []backend.DataQuery,
 		}
 		params.Add("query", rawQuery)
 
-		azureLogAnalyticsQueries = append(azureLogAnalyticsQueries, &AzureLogAnalyticsQuery{
+		logAnalyticsQuery := &AzureLogAnalyticsQuery{
 			RefID:        query.RefID,
 			ResultFormat: resultFormat,
 			URL:          apiURL,
@@ -113,7 +119,14 @@ func (e *AzureLogAnalyticsDatasource) buildQueries(queries []backend.DataQuery,
 			Params:       params,
 			Target:       params.Encode(),
 			TimeRange:    query.TimeRange,
-		})
+		}
+
+		logAnalyticsQuery.Chunks, err = e.buildChunkQueries(query, dsInfo, azureLogAnalyticsTarget.Query, logAnalyticsQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		azureLogAnalyticsQueries = append(azureLogAnalyticsQueries, logAnalyticsQuery)
 	}
 
 	return azureLogAnalyticsQueries, nil
@@ -135,10 +148,51 @@ func (e *AzureLogAnalyticsDatasource) executeQuery(ctx context.Context, query *A
 		return dataResponse
 	}
 
+	var frame *data.Frame
+	var err error
+	if len(query.Chunks) > 0 {
+		frame, err = e.executeChunkQueries(ctx, query, dsInfo)
+	} else {
+		frame, err = e.queryFrame(ctx, query, dsInfo)
+	}
+	if err != nil {
+		return dataResponseErrorWithExecuted(err)
+	}
+
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return dataResponseErrorWithExecuted(err)
+	}
+
+	err = setAdditionalFrameMeta(frame,
+		query.Params.Get("query"),
+		model.Get("subscriptionId").MustString(),
+		model.Get("azureLogAnalytics").Get("workspace").MustString())
+	if err != nil {
+		frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: "could not add custom metadata: " + err.Error()})
+		azlog.Warn("failed to add custom metadata to azure log analytics response", err)
+	}
+
+	if query.ResultFormat == timeSeries {
+		tsSchema := frame.TimeSeriesSchema()
+		if tsSchema.Type == data.TimeSeriesTypeLong {
+			wideFrame, err := data.LongToWide(frame, nil)
+			if err == nil {
+				frame = wideFrame
+			} else {
+				frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: "could not convert frame to time series, returning raw table: " + err.Error()})
+			}
+		}
+	}
+	dataResponse.Frames = data.Frames{frame}
+	return dataResponse
+}
+
+// queryFrame requests the Log Analytics API and returns the primary result table as data frame
+func (e *AzureLogAnalyticsDatasource) queryFrame(ctx context.Context, query *AzureLogAnalyticsQuery, dsInfo datasourceInfo) (*data.Frame, error) {
 	req, err := e.createRequest(ctx, dsInfo)
 	if err != nil {
-		dataResponse.Error = err
-		return dataResponse
+		return nil, err
 	}
 
 	req.URL.Path = path.Join(req.URL.Path, query.URL)
@@ -153,61 +207,102 @@ func (e *AzureLogAnalyticsDatasource) executeQuery(ctx context.Context, query *A
 
 	defer span.Finish()
 
-	if err := opentracing.GlobalTracer().Inject(
-		span.Context(),
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
-		return dataResponseErrorWithExecuted(err)
+	logResponse, err := e.requestPage(ctx, req, dsInfo)
+	if err != nil {
+		return nil, err
 	}
 
-	azlog.Debug("AzureLogAnalytics", "Request ApiURL", req.URL.String())
-	res, err := ctxhttp.Do(ctx, dsInfo.Services[azureLogAnalytics].HTTPClient, req)
+	t, err := logResponse.GetPrimaryResultTable()
 	if err != nil {
-		return dataResponseErrorWithExecuted(err)
+		return nil, err
 	}
 
-	logResponse, err := e.unmarshalResponse(res)
-	if err != nil {
-		return dataResponseErrorWithExecuted(err)
+	maxRows := getLogAnalyticsMaxRows(dsInfo)
+	truncated := false
+	for nextLink := logResponse.NextLink; nextLink != ""; nextLink = logResponse.NextLink {
+		if len(t.Rows) >= maxRows {
+			truncated = true
+			break
+		}
+
+		req, err := e.createPageRequest(ctx, nextLink, dsInfo)
+		if err != nil {
+			return nil, err
+		}
+		logResponse, err = e.requestPage(ctx, req, dsInfo)
+		if err != nil {
+			return nil, err
+		}
+		page, err := logResponse.GetPrimaryResultTable()
+		if err != nil {
+			return nil, err
+		}
+		t.Rows = append(t.Rows, page.Rows...)
+	}
+	if len(t.Rows) > maxRows {
+		t.Rows = t.Rows[:maxRows]
+		truncated = true
 	}
 
-	t, err := logResponse.GetPrimaryResultTable()
+	frame, err := ResponseTableToFrame(t)
 	if err != nil {
-		return dataResponseErrorWithExecuted(err)
+		return nil, err
 	}
+	if truncated {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Results were limited to %d rows, narrow down the query to see all results", maxRows),
+		})
+	}
+	return frame, nil
+}
 
-	frame, err := ResponseTableToFrame(t)
+// requestPage requests a single page of query results
+func (e *AzureLogAnalyticsDatasource) requestPage(ctx context.Context, req *http.Request, dsInfo datasourceInfo) (AzureLogAnalyticsResponse, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		if err := opentracing.GlobalTracer().Inject(
+			span.Context(),
+			opentracing.HTTPHeaders,
+			opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+			return AzureLogAnalyticsResponse{}, err
+		}
+	}
+
+	azlog.Debug("AzureLogAnalytics", "Request ApiURL", req.URL.String())
+	res, err := ctxhttp.Do(ctx, dsInfo.Services[azureLogAnalytics].HTTPClient, req)
 	if err != nil {
-		return dataResponseErrorWithExecuted(err)
+		return AzureLogAnalyticsResponse{}, err
 	}
 
-	model, err := simplejson.NewJson(query.JSON)
+	return e.unmarshalResponse(res)
+}
+
+// createPageRequest creates the request of the next results page. The link must point to the
+// Log Analytics API, so that the access token isn't sent elsewhere.
+func (e *AzureLogAnalyticsDatasource) createPageRequest(ctx context.Context, nextLink string, dsInfo datasourceInfo) (*http.Request, error) {
+	req, err := e.createRequest(ctx, dsInfo)
 	if err != nil {
-		return dataResponseErrorWithExecuted(err)
+		return nil, err
 	}
 
-	err = setAdditionalFrameMeta(frame,
-		query.Params.Get("query"),
-		model.Get("subscriptionId").MustString(),
-		model.Get("azureLogAnalytics").Get("workspace").MustString())
+	pageURL, err := url.Parse(nextLink)
 	if err != nil {
-		frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: "could not add custom metadata: " + err.Error()})
-		azlog.Warn("failed to add custom metadata to azure log analytics response", err)
+		return nil, fmt.Errorf("invalid link to next page of results: %w", err)
+	}
+	if pageURL.Host != "" && (pageURL.Scheme != req.URL.Scheme || pageURL.Host != req.URL.Host) {
+		return nil, fmt.Errorf("link to next page of results doesn't point to the Log Analytics API: %s", pageURL.Host)
 	}
 
-	if query.ResultFormat == timeSeries {
-		tsSchema := frame.TimeSeriesSchema()
-		if tsSchema.Type == data.TimeSeriesTypeLong {
-			wideFrame, err := data.LongToWide(frame, nil)
-			if err == nil {
-				frame = wideFrame
-			} else {
-				frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: "could not convert frame to time series, returning raw table: " + err.Error()})
-			}
-		}
+	req.URL = req.URL.ResolveReference(pageURL)
+	return req, nil
+}
+
+// getLogAnalyticsMaxRows returns the maximum number of rows of a query result
+func getLogAnalyticsMaxRows(dsInfo datasourceInfo) int {
+	if dsInfo.Settings.LogAnalyticsMaxRows > 0 {
+		return dsInfo.Settings.LogAnalyticsMaxRows
 	}
-	dataResponse.Frames = data.Frames{frame}
-	return dataResponse
+	return defaultLogAnalyticsMaxRows
 }
 
 func (e *AzureLogAnalyticsDatasource) createRequest(ctx context.Context, dsInfo datasourceInfo) (*http.Request, error) {
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -214,3 +217,67 @@ func TestLogAnalyticsCreateRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestLogAnalyticsQueryFramePaging(t *testing.T) {
+	datasource := &AzureLogAnalyticsDatasource{}
+
+	newServer := func(pages int, nextLink func(serverURL string, page int) string) (*httptest.Server, *int) {
+		requests := 0
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			page := 1
+			if p := r.URL.Query().Get("page"); p != "" {
+				_, _ = fmt.Sscanf(p, "%d", &page)
+			}
+			link := ""
+			if page < pages {
+				link = fmt.Sprintf(`,"@odata.nextLink":"%s"`, nextLink(server.URL, page+1))
+			}
+			_, _ = fmt.Fprintf(w, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"Count","type":"long"}],"rows":[[%d],[%d]]}]%s}`,
+				page*2-1, page*2, link)
+		}))
+		return server, &requests
+	}
+	sameServerLink := func(serverURL string, page int) string {
+		return fmt.Sprintf("%s/v1/workspaces/ws/query?page=%d", serverURL, page)
+	}
+	query := &AzureLogAnalyticsQuery{RefID: "A", URL: "v1/workspaces/ws/query", Params: url.Values{"query": {"Perf"}}}
+
+	t.Run("should follow links to next pages", func(t *testing.T) {
+		server, requests := newServer(3, sameServerLink)
+		defer server.Close()
+		dsInfo := datasourceInfo{Services: map[string]datasourceService{azureLogAnalytics: {URL: server.URL, HTTPClient: server.Client()}}}
+
+		frame, err := datasource.queryFrame(context.Background(), query, dsInfo)
+		require.NoError(t, err)
+		assert.Equal(t, 3, *requests)
+		assert.Equal(t, 6, frame.Rows())
+		assert.Empty(t, frame.Meta.Notices)
+	})
+
+	t.Run("should stop at row cap and add notice", func(t *testing.T) {
+		server, requests := newServer(3, sameServerLink)
+		defer server.Close()
+		dsInfo := datasourceInfo{
+			Settings: azureMonitorSettings{LogAnalyticsMaxRows: 3},
+			Services: map[string]datasourceService{azureLogAnalytics: {URL: server.URL, HTTPClient: server.Client()}},
+		}
+
+		frame, err := datasource.queryFrame(context.Background(), query, dsInfo)
+		require.NoError(t, err)
+		assert.Equal(t, 2, *requests)
+		assert.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+	})
+
+	t.Run("should not follow links to other hosts", func(t *testing.T) {
+		server, _ := newServer(2, func(string, int) string { return "https://example.com/query?page=2" })
+		defer server.Close()
+		dsInfo := datasourceInfo{Services: map[string]datasourceService{azureLogAnalytics: {URL: server.URL, HTTPClient: server.Client()}}}
+
+		_, err := datasource.queryFrame(context.Background(), query, dsInfo)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,177 @@
+package azuremonitor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultLogAnalyticsSplitInterval is the length of the chunks long time ranges are split into
+	defaultLogAnalyticsSplitInterval = 24 * time.Hour
+
+	// maxLogAnalyticsChunks limits the number of requests per query, the chunks are enlarged
+	// for time ranges which would exceed the limit
+	maxLogAnalyticsChunks = 100
+
+	// logAnalyticsSplitConcurrency is how many chunks of a query are requested at once
+	logAnalyticsSplitConcurrency = 4
+)
+
+// getLogAnalyticsSplitInterval returns the length of the query chunks or zero when the queries
+// of the datasource aren't split.
+func getLogAnalyticsSplitInterval(dsInfo datasourceInfo) (time.Duration, error) {
+	if !dsInfo.Settings.LogAnalyticsQuerySplitting {
+		return 0, nil
+	}
+
+	if dsInfo.Settings.LogAnalyticsSplitInterval == "" {
+		return defaultLogAnalyticsSplitInterval, nil
+	}
+
+	splitInterval, err := gtime.ParseDuration(dsInfo.Settings.LogAnalyticsSplitInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Log Analytics split interval: %w", err)
+	}
+	return splitInterval, nil
+}
+
+// isSplittableLogAnalyticsQuery reports whether the query can be run for parts of the time range,
+// i.e. the data is filtered by the time range. Rows of the chunks are concatenated, so aggregations
+// should be done in time bins which don't span multiple chunks.
+func isSplittableLogAnalyticsQuery(kql string) bool {
+	return strings.Contains(kql, "$__timeFilter")
+}
+
+// splitTimeRange splits the time range into chunks aligned to the split interval, so that time bins
+// of the interval or its fractions aren't divided between chunks. Returns nil if the time range
+// doesn't need to be split.
+func splitTimeRange(timeRange backend.TimeRange, splitInterval time.Duration) []backend.TimeRange {
+	if splitInterval <= 0 || timeRange.To.Sub(timeRange.From) <= splitInterval {
+		return nil
+	}
+
+	for timeRange.To.Sub(timeRange.From) > splitInterval*maxLogAnalyticsChunks {
+		splitInterval *= 2
+	}
+
+	var chunks []backend.TimeRange
+	for from := timeRange.From; from.Before(timeRange.To); {
+		to := from.Truncate(splitInterval).Add(splitInterval)
+		if !to.Before(timeRange.To) {
+			to = timeRange.To
+		}
+		chunks = append(chunks, backend.TimeRange{From: from, To: to})
+		from = to
+	}
+
+	return chunks
+}
+
+// buildChunkQueries returns queries for chunks of the query time range, or nil if the query isn't split.
+func (e *AzureLogAnalyticsDatasource) buildChunkQueries(query backend.DataQuery, dsInfo datasourceInfo,
+	kql string, parent *AzureLogAnalyticsQuery) ([]*AzureLogAnalyticsQuery, error) {
+	splitInterval, err := getLogAnalyticsSplitInterval(dsInfo)
+	if err != nil {
+		return nil, err
+	}
+	if splitInterval == 0 || !isSplittableLogAnalyticsQuery(kql) {
+		return nil, nil
+	}
+
+	timeRanges := splitTimeRange(query.TimeRange, splitInterval)
+
+	chunks := make([]*AzureLogAnalyticsQuery, 0, len(timeRanges))
+	for i, timeRange := range timeRanges {
+		rawQuery, err := kqlInterpolateChunk(query, timeRange, i == len(timeRanges)-1, dsInfo, kql, "TimeGenerated")
+		if err != nil {
+			return nil, err
+		}
+
+		chunk := *parent
+		chunk.Params = url.Values{"query": {rawQuery}}
+		chunk.Target = chunk.Params.Encode()
+		chunk.TimeRange = timeRange
+		chunks = append(chunks, &chunk)
+	}
+
+	return chunks, nil
+}
+
+// executeChunkQueries runs the chunks of the split query concurrently and merges the resulting frames.
+func (e *AzureLogAnalyticsDatasource) executeChunkQueries(ctx context.Context, query *AzureLogAnalyticsQuery, dsInfo datasourceInfo) (*data.Frame, error) {
+	frames := make([]*data.Frame, len(query.Chunks))
+	limiter := make(chan struct{}, logAnalyticsSplitConcurrency)
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i, chunk := range query.Chunks {
+		i, chunk := i, chunk
+		g.Go(func() error {
+			select {
+			case limiter <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-limiter }()
+
+			frame, err := e.queryFrame(ctx, chunk, dsInfo)
+			if err != nil {
+				return err
+			}
+			frames[i] = frame
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return mergeFrames(frames)
+}
+
+// mergeFrames appends rows of the frames to the first one, all frames must have the same fields.
+func mergeFrames(frames []*data.Frame) (*data.Frame, error) {
+	merged := frames[0]
+	for _, frame := range frames[1:] {
+		if len(frame.Fields) != len(merged.Fields) {
+			return nil, fmt.Errorf("unable to merge query chunks: responses have different columns")
+		}
+
+		for i, field := range frame.Fields {
+			if field.Type() != merged.Fields[i].Type() {
+				return nil, fmt.Errorf("unable to merge query chunks: column %s has different types", field.Name)
+			}
+			for row := 0; row < field.Len(); row++ {
+				merged.Fields[i].Append(field.At(row))
+			}
+		}
+
+		if frame.Meta != nil {
+			for _, notice := range frame.Meta.Notices {
+				if !hasNotice(merged, notice) {
+					merged.AppendNotices(notice)
+				}
+			}
+		}
+	}
+	return merged, nil
+}
+
+func hasNotice(frame *data.Frame, notice data.Notice) bool {
+	if frame.Meta == nil {
+		return false
+	}
+	for _, n := range frame.Meta.Notices {
+		if n == notice {
+			return true
+		}
+	}
+	return false
+}
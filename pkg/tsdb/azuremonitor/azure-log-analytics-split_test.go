@@ -0,0 +1,159 @@
+package azuremonitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitTimeRange(t *testing.T) {
+	from := time.Date(2021, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	t.Run("should not split time range shorter than the split interval", func(t *testing.T) {
+		chunks := splitTimeRange(backend.TimeRange{From: from, To: from.Add(12 * time.Hour)}, 24*time.Hour)
+		assert.Nil(t, chunks)
+	})
+
+	t.Run("should split time range into chunks aligned to the split interval", func(t *testing.T) {
+		chunks := splitTimeRange(backend.TimeRange{From: from, To: from.Add(48 * time.Hour)}, 24*time.Hour)
+		require.Len(t, chunks, 3)
+		assert.Equal(t, backend.TimeRange{From: from, To: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)}, chunks[0])
+		assert.Equal(t, backend.TimeRange{From: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC)}, chunks[1])
+		assert.Equal(t, backend.TimeRange{From: time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC), To: from.Add(48 * time.Hour)}, chunks[2])
+	})
+
+	t.Run("should enlarge chunks of long time ranges", func(t *testing.T) {
+		chunks := splitTimeRange(backend.TimeRange{From: from, To: from.Add(365 * 24 * time.Hour)}, 24*time.Hour)
+		assert.LessOrEqual(t, len(chunks), maxLogAnalyticsChunks+1)
+	})
+}
+
+func TestBuildingSplitAzureLogAnalyticsQueries(t *testing.T) {
+	datasource := &AzureLogAnalyticsDatasource{}
+	from := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	query := backend.DataQuery{
+		RefID:     "A",
+		Interval:  time.Hour,
+		TimeRange: backend.TimeRange{From: from, To: from.Add(36 * time.Hour)},
+		JSON: []byte(`{
+			"queryType": "Azure Log Analytics",
+			"azureLogAnalytics": {
+				"resource": "/subscriptions/aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/ws",
+				"query":    "Perf | where $__timeFilter() | summarize count() by bin(TimeGenerated, $__interval)"
+			}
+		}`),
+	}
+
+	t.Run("should split query when enabled", func(t *testing.T) {
+		dsInfo := datasourceInfo{Settings: azureMonitorSettings{LogAnalyticsQuerySplitting: true}}
+
+		queries, err := datasource.buildQueries([]backend.DataQuery{query}, dsInfo)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		require.Len(t, queries[0].Chunks, 2)
+
+		assert.Equal(t, "Perf | where ['TimeGenerated'] >= datetime('2021-03-01T12:00:00Z') and ['TimeGenerated'] < datetime('2021-03-02T00:00:00Z') | summarize count() by bin(TimeGenerated, 3600000ms)",
+			queries[0].Chunks[0].Params.Get("query"))
+		assert.Equal(t, "Perf | where ['TimeGenerated'] >= datetime('2021-03-02T00:00:00Z') and ['TimeGenerated'] <= datetime('2021-03-03T00:00:00Z') | summarize count() by bin(TimeGenerated, 3600000ms)",
+			queries[0].Chunks[1].Params.Get("query"))
+	})
+
+	t.Run("should not split query when disabled", func(t *testing.T) {
+		queries, err := datasource.buildQueries([]backend.DataQuery{query}, datasourceInfo{})
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		assert.Empty(t, queries[0].Chunks)
+	})
+
+	t.Run("should not split query without time filter", func(t *testing.T) {
+		dsInfo := datasourceInfo{Settings: azureMonitorSettings{LogAnalyticsQuerySplitting: true}}
+		unfiltered := query
+		unfiltered.JSON = []byte(`{"azureLogAnalytics": {"resource": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/ws", "query": "Perf | count"}}`)
+
+		queries, err := datasource.buildQueries([]backend.DataQuery{unfiltered}, dsInfo)
+		require.NoError(t, err)
+		assert.Empty(t, queries[0].Chunks)
+	})
+
+	t.Run("should return error for invalid split interval", func(t *testing.T) {
+		dsInfo := datasourceInfo{Settings: azureMonitorSettings{LogAnalyticsQuerySplitting: true, LogAnalyticsSplitInterval: "invalid"}}
+
+		_, err := datasource.buildQueries([]backend.DataQuery{query}, dsInfo)
+		require.Error(t, err)
+	})
+}
+
+func TestExecutingSplitAzureLogAnalyticsQuery(t *testing.T) {
+	datasource := &AzureLogAnalyticsDatasource{}
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		query := r.URL.Query().Get("query")
+		// The first chunk starts on March 1st, the second chunk on March 2nd
+		day := 1
+		if strings.Contains(query, ">= datetime('2021-03-02") {
+			day = 2
+		}
+		_, _ = fmt.Fprintf(w, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"TimeGenerated","type":"datetime"},{"name":"Count","type":"long"}],
+			"rows":[["2021-03-0%dT12:00:00Z",%d]]}]}`, day, n)
+	}))
+	defer server.Close()
+
+	from := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	query := &AzureLogAnalyticsQuery{
+		RefID:        "A",
+		ResultFormat: "table",
+		JSON:         []byte(`{}`),
+		Chunks: []*AzureLogAnalyticsQuery{
+			{RefID: "A", Params: map[string][]string{"query": {"Perf | where ['TimeGenerated'] >= datetime('2021-03-01T12:00:00Z')"}}},
+			{RefID: "A", Params: map[string][]string{"query": {"Perf | where ['TimeGenerated'] >= datetime('2021-03-02T00:00:00Z')"}}},
+		},
+		TimeRange: backend.TimeRange{From: from, To: from.Add(36 * time.Hour)},
+	}
+	dsInfo := datasourceInfo{
+		Services: map[string]datasourceService{
+			azureLogAnalytics: {URL: server.URL, HTTPClient: server.Client()},
+		},
+	}
+
+	res := datasource.executeQuery(context.Background(), query, dsInfo)
+	require.NoError(t, res.Error)
+	require.Len(t, res.Frames, 1)
+
+	frame := res.Frames[0]
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	require.Equal(t, 2, frame.Rows())
+	// Chunks are merged in order of the time range
+	assert.Equal(t, time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+	assert.Equal(t, time.Date(2021, 3, 2, 12, 0, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+}
+
+func TestMergeFrames(t *testing.T) {
+	t.Run("should append rows of all frames", func(t *testing.T) {
+		frame1 := data.NewFrame("", data.NewField("value", nil, []int64{1, 2}))
+		frame2 := data.NewFrame("", data.NewField("value", nil, []int64{3}))
+
+		merged, err := mergeFrames([]*data.Frame{frame1, frame2})
+		require.NoError(t, err)
+		assert.Equal(t, 3, merged.Rows())
+	})
+
+	t.Run("should fail when columns differ", func(t *testing.T) {
+		frame1 := data.NewFrame("", data.NewField("value", nil, []int64{1}))
+		frame2 := data.NewFrame("", data.NewField("value", nil, []string{"a"}))
+
+		_, err := mergeFrames([]*data.Frame{frame1, frame2})
+		require.Error(t, err)
+	})
+}
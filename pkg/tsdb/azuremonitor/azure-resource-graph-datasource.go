@@ -38,6 +38,10 @@ type AzureResourceGraphQuery struct {
 const argAPIVersion = "2021-03-01"
 const argQueryProviderName = "/providers/Microsoft.ResourceGraph/resources"
 
+// argMaxPages limits how many pages of results are requested per query, Azure Resource Graph
+// returns at most 1000 rows per page
+const argMaxPages = 50
+
 // executeTimeSeriesQuery does the following:
 // 1. builds the AzureMonitor url and querystring for each query
 // 2. executes each query by calling the Azure Monitor API
@@ -121,27 +125,6 @@ func (e *AzureResourceGraphDatasource) executeQuery(ctx context.Context, query *
 		return dataResponse
 	}
 
-	reqBody, err := json.Marshal(map[string]interface{}{
-		"subscriptions": model.Get("subscriptions").MustStringArray(),
-		"query":         query.InterpolatedQuery,
-		"options":       map[string]string{"resultFormat": "table"},
-	})
-
-	if err != nil {
-		dataResponse.Error = err
-		return dataResponse
-	}
-
-	req, err := e.createRequest(ctx, dsInfo, reqBody)
-
-	if err != nil {
-		dataResponse.Error = err
-		return dataResponse
-	}
-
-	req.URL.Path = path.Join(req.URL.Path, argQueryProviderName)
-	req.URL.RawQuery = params.Encode()
-
 	span, ctx := opentracing.StartSpanFromContext(ctx, "azure resource graph query")
 	span.SetTag("interpolated_query", query.InterpolatedQuery)
 	span.SetTag("from", query.TimeRange.From.UnixNano()/int64(time.Millisecond))
@@ -151,37 +134,92 @@ func (e *AzureResourceGraphDatasource) executeQuery(ctx context.Context, query *
 
 	defer span.Finish()
 
-	if err := opentracing.GlobalTracer().Inject(
-		span.Context(),
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
-		return dataResponseErrorWithExecuted(err)
-	}
+	var table AzureResponseTable
+	skipToken := ""
+	truncated := false
+	for page := 0; ; page++ {
+		if page == argMaxPages {
+			truncated = true
+			break
+		}
 
-	azlog.Debug("AzureResourceGraph", "Request ApiURL", req.URL.String())
-	res, err := ctxhttp.Do(ctx, dsInfo.Services[azureResourceGraph].HTTPClient, req)
-	if err != nil {
-		return dataResponseErrorWithExecuted(err)
-	}
+		options := map[string]string{"resultFormat": "table"}
+		if skipToken != "" {
+			options["$skipToken"] = skipToken
+		}
 
-	argResponse, err := e.unmarshalResponse(res)
-	if err != nil {
-		return dataResponseErrorWithExecuted(err)
+		reqBody, err := json.Marshal(map[string]interface{}{
+			"subscriptions": model.Get("subscriptions").MustStringArray(),
+			"query":         query.InterpolatedQuery,
+			"options":       options,
+		})
+		if err != nil {
+			return dataResponseErrorWithExecuted(err)
+		}
+
+		argResponse, err := e.requestPage(ctx, span, dsInfo, params, reqBody)
+		if err != nil {
+			return dataResponseErrorWithExecuted(err)
+		}
+
+		if page == 0 {
+			table = argResponse.Data
+		} else {
+			table.Rows = append(table.Rows, argResponse.Data.Rows...)
+		}
+
+		if argResponse.SkipToken == "" {
+			break
+		}
+		skipToken = argResponse.SkipToken
 	}
 
-	frame, err := ResponseTableToFrame(&argResponse.Data)
+	frame, err := ResponseTableToFrame(&table)
 	if err != nil {
 		return dataResponseErrorWithExecuted(err)
 	}
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
 	}
-	frame.Meta.ExecutedQueryString = req.URL.RawQuery
+	frame.Meta.ExecutedQueryString = query.InterpolatedQuery
+	if truncated {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("results are limited to %d pages, refine the query to get all results", argMaxPages),
+		})
+	}
 
 	dataResponse.Frames = data.Frames{frame}
 	return dataResponse
 }
 
+// requestPage requests a page of the query results
+func (e *AzureResourceGraphDatasource) requestPage(ctx context.Context, span opentracing.Span, dsInfo datasourceInfo,
+	params url.Values, reqBody []byte) (AzureResourceGraphResponse, error) {
+	req, err := e.createRequest(ctx, dsInfo, reqBody)
+	if err != nil {
+		return AzureResourceGraphResponse{}, err
+	}
+
+	req.URL.Path = path.Join(req.URL.Path, argQueryProviderName)
+	req.URL.RawQuery = params.Encode()
+
+	if err := opentracing.GlobalTracer().Inject(
+		span.Context(),
+		opentracing.HTTPHeaders,
+		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		return AzureResourceGraphResponse{}, err
+	}
+
+	azlog.Debug("AzureResourceGraph", "Request ApiURL", req.URL.String())
+	res, err := ctxhttp.Do(ctx, dsInfo.Services[azureResourceGraph].HTTPClient, req)
+	if err != nil {
+		return AzureResourceGraphResponse{}, err
+	}
+
+	return e.unmarshalResponse(res)
+}
+
 func (e *AzureResourceGraphDatasource) createRequest(ctx context.Context, dsInfo datasourceInfo, reqBody []byte) (*http.Request, error) {
 	req, err := http.NewRequest(http.MethodPost, dsInfo.Services[azureResourceGraph].URL, bytes.NewBuffer(reqBody))
 	if err != nil {
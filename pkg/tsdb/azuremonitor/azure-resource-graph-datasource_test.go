@@ -2,8 +2,10 @@ package azuremonitor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -113,3 +116,41 @@ func TestAzureResourceGraphCreateRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestAzureResourceGraphExecuteQuery_Paging(t *testing.T) {
+	var skipTokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Options map[string]string `json:"options"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		skipTokens = append(skipTokens, body.Options["$skipToken"])
+
+		if body.Options["$skipToken"] == "" {
+			_, _ = w.Write([]byte(`{"data":{"columns":[{"name":"name","type":"string"}],"rows":[["vm1"],["vm2"]]},"$skipToken":"page-2"}`))
+		} else {
+			_, _ = w.Write([]byte(`{"data":{"columns":[{"name":"name","type":"string"}],"rows":[["vm3"]]}}`))
+		}
+	}))
+	defer server.Close()
+
+	dsInfo := datasourceInfo{
+		Services: map[string]datasourceService{
+			azureResourceGraph: {URL: server.URL, HTTPClient: server.Client()},
+		},
+	}
+	query := &AzureResourceGraphQuery{
+		RefID:             "A",
+		JSON:              []byte(`{"subscriptions":["sub1"]}`),
+		InterpolatedQuery: "resources | project name",
+	}
+
+	ds := AzureResourceGraphDatasource{}
+	res := ds.executeQuery(context.Background(), query, dsInfo)
+	require.NoError(t, res.Error)
+	require.Len(t, res.Frames, 1)
+
+	assert.Equal(t, []string{"", "page-2"}, skipTokens)
+	assert.Equal(t, 3, res.Frames[0].Rows())
+	assert.Equal(t, "resources | project name", res.Frames[0].Meta.ExecutedQueryString)
+}
@@ -43,6 +43,11 @@ func (e *AzureMonitorDatasource) executeTimeSeriesQuery(ctx context.Context, ori
 	}
 
 	for _, query := range queries {
+		if len(query.Resources) > 0 {
+			result.Responses[query.RefID] = e.executeMultiResourceQuery(ctx, query, dsInfo)
+			continue
+		}
+
 		queryRes, resp, err := e.executeQuery(ctx, query, dsInfo)
 		if err != nil {
 			return nil, err
@@ -88,6 +93,16 @@ func (e *AzureMonitorDatasource) buildQueries(queries []backend.DataQuery, dsInf
 		}
 		azureURL := ub.Build()
 
+		resources := azJSONModel.ResourceURIs
+		for _, resource := range resources {
+			if !strings.HasPrefix(strings.ToLower(resource), "/subscriptions/") {
+				return nil, fmt.Errorf("invalid resource URI %q, expected URI starting with /subscriptions/", resource)
+			}
+		}
+		if len(resources) > 0 {
+			azureURL = ""
+		}
+
 		alias := azJSONModel.Alias
 
 		timeGrain := azJSONModel.TimeGrain
@@ -143,6 +158,8 @@ func (e *AzureMonitorDatasource) buildQueries(queries []backend.DataQuery, dsInf
 			RefID:         query.RefID,
 			Alias:         alias,
 			TimeRange:     query.TimeRange,
+			Resources:     resources,
+			Region:        azJSONModel.Region,
 		})
 	}
 
@@ -0,0 +1,258 @@
+package azuremonitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	azureMonitorBatchAPIVersion = "2023-10-01"
+
+	// maxBatchResources is the number of resources the metrics batch API accepts per request
+	maxBatchResources = 50
+
+	// multiResourceConcurrency is how many requests of a multi-resource query are made at once
+	multiResourceConcurrency = 4
+)
+
+// resourceMetrics is the metrics response for a single resource of a multi-resource query
+type resourceMetrics struct {
+	ResourceID string
+	Response   AzureMonitorResponse
+}
+
+// executeMultiResourceQuery queries the metric of all resources of the query and returns frames
+// labeled by the resource. The metrics batch API is used if the region of the resources is known,
+// otherwise the metrics of every resource are requested separately.
+func (e *AzureMonitorDatasource) executeMultiResourceQuery(ctx context.Context, query *AzureMonitorQuery, dsInfo datasourceInfo) backend.DataResponse {
+	var responses []resourceMetrics
+	var err error
+	if _, ok := dsInfo.Services[azureMonitorBatch]; ok && query.Region != "" {
+		responses, err = e.executeBatchQueries(ctx, query, dsInfo)
+	} else {
+		responses, err = e.executeResourceQueries(ctx, query, dsInfo)
+	}
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	frames := data.Frames{}
+	for _, response := range responses {
+		resourceName := path.Base(response.ResourceID)
+
+		resourceQuery := *query
+		resourceQuery.UrlComponents = map[string]string{"resourceName": resourceName}
+		resourceFrames, err := e.parseResponse(response.Response, &resourceQuery)
+		if err != nil {
+			return backend.DataResponse{Error: err}
+		}
+
+		for _, frame := range resourceFrames {
+			labels := frame.Fields[1].Labels
+			labels["resource"] = response.ResourceID
+			labels["resourceName"] = resourceName
+		}
+		frames = append(frames, resourceFrames...)
+	}
+
+	return backend.DataResponse{Frames: frames}
+}
+
+// executeResourceQueries requests the metrics of every resource of the query from the resource manager.
+func (e *AzureMonitorDatasource) executeResourceQueries(ctx context.Context, query *AzureMonitorQuery, dsInfo datasourceInfo) ([]resourceMetrics, error) {
+	responses := make([]resourceMetrics, len(query.Resources))
+
+	err := runConcurrently(ctx, len(query.Resources), func(ctx context.Context, i int) error {
+		resourceQuery := *query
+		resourceQuery.URL = path.Join(trimSubscriptionsPrefix(query.Resources[i]), "providers/microsoft.insights/metrics")
+
+		dataResponse, response, err := e.executeQuery(ctx, &resourceQuery, dsInfo)
+		if err != nil {
+			return err
+		}
+		if dataResponse.Error != nil {
+			return fmt.Errorf("failed to query metrics of resource %s: %w", query.Resources[i], dataResponse.Error)
+		}
+
+		responses[i] = resourceMetrics{ResourceID: query.Resources[i], Response: response}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// executeBatchQueries requests the metrics of the query resources from the regional metrics batch API.
+// Resources are grouped by subscription since a batch request can't span subscriptions.
+func (e *AzureMonitorDatasource) executeBatchQueries(ctx context.Context, query *AzureMonitorQuery, dsInfo datasourceInfo) ([]resourceMetrics, error) {
+	type batch struct {
+		subscription string
+		resources    []string
+	}
+
+	var batches []*batch
+	for _, resource := range query.Resources {
+		subscription := strings.SplitN(trimSubscriptionsPrefix(resource), "/", 2)[0]
+
+		var current *batch
+		for _, b := range batches {
+			if strings.EqualFold(b.subscription, subscription) && len(b.resources) < maxBatchResources {
+				current = b
+			}
+		}
+		if current == nil {
+			current = &batch{subscription: subscription}
+			batches = append(batches, current)
+		}
+		current.resources = append(current.resources, resource)
+	}
+
+	results := make([][]resourceMetrics, len(batches))
+	err := runConcurrently(ctx, len(batches), func(ctx context.Context, i int) error {
+		result, err := e.executeBatchQuery(ctx, query, batches[i].subscription, batches[i].resources, dsInfo)
+		if err != nil {
+			return err
+		}
+		results[i] = result
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []resourceMetrics
+	for _, result := range results {
+		responses = append(responses, result...)
+	}
+	return responses, nil
+}
+
+func (e *AzureMonitorDatasource) executeBatchQuery(ctx context.Context, query *AzureMonitorQuery, subscription string,
+	resources []string, dsInfo datasourceInfo) ([]resourceMetrics, error) {
+	service := dsInfo.Services[azureMonitorBatch]
+
+	batchURL, err := url.Parse(service.URL)
+	if err != nil {
+		return nil, err
+	}
+	batchURL.Host = query.Region + "." + batchURL.Host
+	batchURL.Path = path.Join("/subscriptions", subscription, "metrics:getBatch")
+	batchURL.RawQuery = batchQueryParams(query).Encode()
+
+	body, err := json.Marshal(map[string][]string{"resourceids": resources})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, batchURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "azuremonitor batch query")
+	span.SetTag("target", query.Target)
+	span.SetTag("resources", len(resources))
+	span.SetTag("datasource_id", dsInfo.DatasourceID)
+	span.SetTag("org_id", dsInfo.OrgID)
+
+	defer span.Finish()
+
+	if err := opentracing.GlobalTracer().Inject(
+		span.Context(),
+		opentracing.HTTPHeaders,
+		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		return nil, err
+	}
+
+	azlog.Debug("AzureMonitor", "Request ApiURL", req.URL.String(), "resources", len(resources))
+	res, err := ctxhttp.Do(ctx, service.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			azlog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		azlog.Debug("Request failed", "status", res.Status, "body", string(resBody))
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	var batchResponse azureMonitorBatchResponse
+	if err := json.Unmarshal(resBody, &batchResponse); err != nil {
+		azlog.Debug("Failed to unmarshal AzureMonitor batch response", "error", err, "status", res.Status, "body", string(resBody))
+		return nil, err
+	}
+
+	responses := make([]resourceMetrics, 0, len(batchResponse.Values))
+	for _, value := range batchResponse.Values {
+		responses = append(responses, resourceMetrics{ResourceID: value.ResourceID, Response: value.AzureMonitorResponse})
+	}
+	return responses, nil
+}
+
+// batchQueryParams converts the resource manager query params to the params of the batch API
+func batchQueryParams(query *AzureMonitorQuery) url.Values {
+	params := url.Values{}
+	params.Set("api-version", azureMonitorBatchAPIVersion)
+	params.Set("starttime", query.TimeRange.From.UTC().Format(time.RFC3339))
+	params.Set("endtime", query.TimeRange.To.UTC().Format(time.RFC3339))
+	for _, name := range []string{"interval", "metricnamespace", "metricnames", "aggregation", "top"} {
+		if value := query.Params.Get(name); value != "" {
+			params.Set(name, value)
+		}
+	}
+	if filter := query.Params.Get("$filter"); filter != "" {
+		params.Set("filter", filter)
+	}
+	return params
+}
+
+// runConcurrently calls fn for indexes 0 to n-1 with at most multiResourceConcurrency calls at once,
+// the first error cancels the remaining calls.
+func runConcurrently(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	limiter := make(chan struct{}, multiResourceConcurrency)
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < n; i++ {
+		i := i
+		g.Go(func() error {
+			select {
+			case limiter <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-limiter }()
+
+			return fn(ctx, i)
+		})
+	}
+	return g.Wait()
+}
+
+func trimSubscriptionsPrefix(resourceID string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(resourceID, "/"), "subscriptions/")
+}
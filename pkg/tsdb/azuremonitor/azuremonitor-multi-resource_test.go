@@ -0,0 +1,185 @@
+package azuremonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testResource1 = "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1"
+	testResource2 = "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-2"
+)
+
+func testMetricsResponse(resourceID string) string {
+	return fmt.Sprintf(`{"namespace":"Microsoft.Compute/virtualMachines","value":[{"id":"%s/providers/Microsoft.Insights/metrics/Percentage CPU",
+		"name":{"value":"Percentage CPU","localizedValue":"Percentage CPU"},"unit":"Percent",
+		"timeseries":[{"data":[{"timeStamp":"2021-03-01T12:00:00Z","average":1.5}]}]}]}`, resourceID)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestAzureMonitorBuildMultiResourceQueries(t *testing.T) {
+	datasource := &AzureMonitorDatasource{}
+	dsInfo := datasourceInfo{Settings: azureMonitorSettings{SubscriptionId: "default-subscription"}}
+
+	buildQuery := func(resourceURIs ...string) backend.DataQuery {
+		model := map[string]interface{}{
+			"azureMonitor": map[string]interface{}{
+				"metricDefinition": "Microsoft.Compute/virtualMachines",
+				"metricNamespace":  "Microsoft.Compute/virtualMachines",
+				"metricName":       "Percentage CPU",
+				"timeGrain":        "PT1M",
+				"aggregation":      "Average",
+				"resourceUris":     resourceURIs,
+				"region":           "westeurope",
+			},
+		}
+		modelJSON, err := json.Marshal(model)
+		require.NoError(t, err)
+
+		from := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+		return backend.DataQuery{
+			RefID:     "A",
+			JSON:      modelJSON,
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		}
+	}
+
+	t.Run("should build query of multiple resources", func(t *testing.T) {
+		queries, err := datasource.buildQueries([]backend.DataQuery{buildQuery(testResource1, testResource2)}, dsInfo)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+
+		assert.Equal(t, []string{testResource1, testResource2}, queries[0].Resources)
+		assert.Equal(t, "westeurope", queries[0].Region)
+		assert.Empty(t, queries[0].URL)
+	})
+
+	t.Run("should fail for invalid resource URI", func(t *testing.T) {
+		_, err := datasource.buildQueries([]backend.DataQuery{buildQuery("resourceGroups/rg")}, dsInfo)
+		require.Error(t, err)
+	})
+}
+
+func TestAzureMonitorExecuteMultiResourceQuery(t *testing.T) {
+	datasource := &AzureMonitorDatasource{}
+	from := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	newQuery := func(region string) *AzureMonitorQuery {
+		return &AzureMonitorQuery{
+			RefID: "A",
+			Params: url.Values{
+				"aggregation":     {"Average"},
+				"interval":        {"PT1M"},
+				"metricnames":     {"Percentage CPU"},
+				"metricnamespace": {"Microsoft.Compute/virtualMachines"},
+				"$filter":         {"LUN eq '*'"},
+			},
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			Resources: []string{testResource1, testResource2},
+			Region:    region,
+		}
+	}
+
+	assertFrames := func(t *testing.T, res backend.DataResponse) {
+		t.Helper()
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+		for i, resource := range []string{testResource1, testResource2} {
+			labels := res.Frames[i].Fields[1].Labels
+			assert.Equal(t, resource, labels["resource"])
+			assert.Equal(t, fmt.Sprintf("vm-%d", i+1), labels["resourceName"])
+			assert.Equal(t, 1.5, *res.Frames[i].Fields[1].At(0).(*float64))
+		}
+	}
+
+	t.Run("should query every resource without region", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resourceID := strings.TrimSuffix(r.URL.Path, "/providers/microsoft.insights/metrics")
+			assert.Contains(t, []string{testResource1, testResource2}, resourceID)
+			_, _ = w.Write([]byte(testMetricsResponse(resourceID)))
+		}))
+		defer server.Close()
+
+		dsInfo := datasourceInfo{
+			Services: map[string]datasourceService{
+				azureMonitor:      {URL: server.URL, HTTPClient: server.Client()},
+				azureMonitorBatch: {URL: server.URL, HTTPClient: server.Client()},
+			},
+		}
+
+		assertFrames(t, datasource.executeMultiResourceQuery(context.Background(), newQuery(""), dsInfo))
+	})
+
+	t.Run("should query batch API in region", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/subscriptions/sub-1/metrics:getBatch", r.URL.Path)
+			assert.Equal(t, azureMonitorBatchAPIVersion, r.URL.Query().Get("api-version"))
+			assert.Equal(t, "2021-03-01T12:00:00Z", r.URL.Query().Get("starttime"))
+			assert.Equal(t, "2021-03-01T13:00:00Z", r.URL.Query().Get("endtime"))
+			assert.Equal(t, "LUN eq '*'", r.URL.Query().Get("filter"))
+
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`{"resourceids":["%s","%s"]}`, testResource1, testResource2), string(body))
+
+			_, _ = fmt.Fprintf(w, `{"values":[%s,%s]}`,
+				strings.Replace(testMetricsResponse(testResource1), "{", fmt.Sprintf(`{"resourceid":"%s",`, testResource1), 1),
+				strings.Replace(testMetricsResponse(testResource2), "{", fmt.Sprintf(`{"resourceid":"%s",`, testResource2), 1))
+		}))
+		defer server.Close()
+
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		// The batch API is served from the regional host, requests are forwarded to the test server
+		var requestedHost string
+		client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requestedHost = req.URL.Host
+			req.URL.Host = serverURL.Host
+			return http.DefaultTransport.RoundTrip(req)
+		})}
+
+		dsInfo := datasourceInfo{
+			Services: map[string]datasourceService{
+				azureMonitorBatch: {URL: "http://metrics.monitor.azure.com", HTTPClient: client},
+			},
+		}
+
+		assertFrames(t, datasource.executeMultiResourceQuery(context.Background(), newQuery("westeurope"), dsInfo))
+		assert.Equal(t, "westeurope.metrics.monitor.azure.com", requestedHost)
+	})
+
+	t.Run("should return error of failed resource query", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		dsInfo := datasourceInfo{
+			Services: map[string]datasourceService{
+				azureMonitor: {URL: server.URL, HTTPClient: server.Client()},
+			},
+		}
+
+		res := datasource.executeMultiResourceQuery(context.Background(), newQuery("westeurope"), dsInfo)
+		require.Error(t, res.Error)
+	})
+}
@@ -6,17 +6,20 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/tokenprovider"
 )
 
 const (
@@ -38,9 +41,10 @@ func init() {
 }
 
 type Service struct {
-	PluginManager        plugins.Manager       `inject:""`
-	Cfg                  *setting.Cfg          `inject:""`
-	BackendPluginManager backendplugin.Manager `inject:""`
+	PluginManager        plugins.Manager          `inject:""`
+	Cfg                  *setting.Cfg             `inject:""`
+	BackendPluginManager backendplugin.Manager    `inject:""`
+	RemoteCache          *remotecache.RemoteCache `inject:""`
 }
 
 type azureMonitorSettings struct {
@@ -55,6 +59,10 @@ type azureMonitorSettings struct {
 	SubscriptionId               string `json:"subscriptionId"`
 	TenantId                     string `json:"tenantId"`
 	AzureAuthType                string `json:"azureAuthType,omitempty"`
+	ClientCertificateFormat      string `json:"clientCertificateFormat,omitempty"`
+	LogAnalyticsQuerySplitting   bool   `json:"logAnalyticsQuerySplitting,omitempty"`
+	LogAnalyticsSplitInterval    string `json:"logAnalyticsSplitInterval,omitempty"`
+	LogAnalyticsMaxRows          int    `json:"logAnalyticsMaxRows,omitempty"`
 }
 
 type datasourceInfo struct {
@@ -67,6 +75,15 @@ type datasourceInfo struct {
 	DecryptedSecureJSONData map[string]string
 	DatasourceID            int64
 	OrgID                   int64
+
+	Cfg *setting.Cfg
+}
+
+// Dispose invalidates cached access tokens when the datasource settings are replaced.
+func (dsInfo datasourceInfo) Dispose() {
+	if dsInfo.Cfg != nil {
+		purgeAccessTokens(dsInfo, dsInfo.Cfg)
+	}
 }
 
 type datasourceService struct {
@@ -74,7 +91,7 @@ type datasourceService struct {
 	HTTPClient *http.Client
 }
 
-func NewInstanceSettings() datasource.InstanceFactoryFunc {
+func NewInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
 	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		jsonData := map[string]interface{}{}
 		err := json.Unmarshal(settings.JSONData, &jsonData)
@@ -98,8 +115,9 @@ func NewInstanceSettings() datasource.InstanceFactoryFunc {
 			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
 			DatasourceID:            settings.ID,
 			Services:                map[string]datasourceService{},
-			Routes:                  routes[azMonitorSettings.CloudName],
+			Routes:                  getRoutes(cfg, azMonitorSettings.CloudName),
 			HTTPCliOpts:             httpCliOpts,
+			Cfg:                     cfg,
 		}
 		return model, nil
 	}
@@ -121,17 +139,26 @@ func newExecutor(im instancemgmt.InstanceManager, cfg *setting.Cfg, executors ma
 			}
 			dsInfo := i.(datasourceInfo)
 			dsInfo.OrgID = req.PluginContext.OrgID
+			if strings.EqualFold(dsInfo.Settings.AzureAuthType, "onbehalfof") {
+				ctx = withUserIdentity(ctx, req)
+			}
 			ds := executors[dst]
 			if _, ok := dsInfo.Services[dst]; !ok {
 				// Create an HTTP Client if it has not been created before
-				route := dsInfo.Routes[dst]
-				client, err := newHTTPClient(ctx, route, dsInfo, cfg)
-				if err != nil {
+				if err := addService(ctx, dsInfo, dst, cfg); err != nil {
 					return nil, err
 				}
-				dsInfo.Services[dst] = datasourceService{
-					URL:        dsInfo.Routes[dst].URL,
-					HTTPClient: client,
+			}
+			for _, service := range additionalServices[dst] {
+				// Additional services are optional, only created if available in the cloud
+				if _, ok := dsInfo.Services[service]; ok {
+					continue
+				}
+				if _, ok := dsInfo.Routes[service]; !ok {
+					continue
+				}
+				if err := addService(ctx, dsInfo, service, cfg); err != nil {
+					return nil, err
 				}
 			}
 			return ds.executeTimeSeriesQuery(ctx, req.Queries, dsInfo)
@@ -140,8 +167,51 @@ func newExecutor(im instancemgmt.InstanceManager, cfg *setting.Cfg, executors ma
 	return mux
 }
 
+// addService creates the HTTP client for the service of the datasource
+func addService(ctx context.Context, dsInfo datasourceInfo, service string, cfg *setting.Cfg) error {
+	route := dsInfo.Routes[service]
+	client, err := newHTTPClient(ctx, route, dsInfo, cfg)
+	if err != nil {
+		return err
+	}
+	dsInfo.Services[service] = datasourceService{
+		URL:        route.URL,
+		HTTPClient: client,
+	}
+	return nil
+}
+
+// newHealthChecker returns handler which validates the datasource credentials by acquiring access tokens,
+// the tokens are cached so that the first query after the datasource is saved doesn't wait for them.
+func newHealthChecker(im instancemgmt.InstanceManager, cfg *setting.Cfg) backend.CheckHealthHandlerFunc {
+	return func(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+		i, err := im.Get(req.PluginContext)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := warmupAccessTokens(ctx, i.(datasourceInfo), cfg); err != nil {
+			return &backend.CheckHealthResult{
+				Status:  backend.HealthStatusError,
+				Message: fmt.Sprintf("Failed to acquire Azure access token: %s", err),
+			}, nil
+		}
+
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusOk,
+			Message: "Successfully acquired Azure access token",
+		}, nil
+	}
+}
+
 func (s *Service) Init() error {
-	im := datasource.NewInstanceManager(NewInstanceSettings())
+	var tokenStore tokenprovider.TokenCacheStore
+	if s.Cfg.Azure.RemoteTokenCacheEnabled {
+		tokenStore = tokenprovider.NewRemoteTokenCacheStore(s.RemoteCache)
+	}
+	tokenprovider.ConfigureTokenCache(s.Cfg, tokenStore)
+
+	im := datasource.NewInstanceManager(NewInstanceSettings(s.Cfg))
 	executors := map[string]azDatasourceExecutor{
 		azureMonitor:       &AzureMonitorDatasource{},
 		appInsights:        &ApplicationInsightsDatasource{},
@@ -150,7 +220,8 @@ func (s *Service) Init() error {
 		azureResourceGraph: &AzureResourceGraphDatasource{},
 	}
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: newExecutor(im, s.Cfg, executors),
+		QueryDataHandler:   newExecutor(im, s.Cfg, executors),
+		CheckHealthHandler: newHealthChecker(im, s.Cfg),
 	})
 
 	if err := s.BackendPluginManager.Register(dsName, factory); err != nil {
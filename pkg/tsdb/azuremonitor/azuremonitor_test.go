@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -39,10 +40,10 @@ func TestNewInstanceSettings(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			factory := NewInstanceSettings()
+			factory := NewInstanceSettings(&setting.Cfg{})
 			instance, err := factory(tt.settings)
 			tt.Err(t, err)
-			if !cmp.Equal(instance, tt.expectedModel, cmpopts.IgnoreFields(datasourceInfo{}, "Services", "HTTPCliOpts")) {
+			if !cmp.Equal(instance, tt.expectedModel, cmpopts.IgnoreFields(datasourceInfo{}, "Services", "HTTPCliOpts", "Cfg")) {
 				t.Errorf("Unexpected instance: %v", cmp.Diff(instance, tt.expectedModel))
 			}
 		})
@@ -125,3 +126,17 @@ func Test_newExecutor(t *testing.T) {
 		})
 	}
 }
+
+func Test_newHealthChecker(t *testing.T) {
+	t.Run("should return error when access token cannot be acquired", func(t *testing.T) {
+		cfg := &setting.Cfg{}
+		im := &fakeInstance{}
+
+		res, err := newHealthChecker(im, cfg)(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+
+		// Datasource isn't configured and managed identity isn't enabled
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, "Failed to acquire Azure access token")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
@@ -27,19 +28,43 @@ const (
 	azureResourceGraph = "Azure Resource Graph"
 )
 
+// Azure services which aren't query types
+const (
+	azureMonitorBatch = "Azure Monitor Metrics Batch"
+)
+
+// additionalServices are the services used by query types besides the service of the query type
+var additionalServices = map[string][]string{
+	azureMonitor: {azureMonitorBatch},
+}
+
+func newTokenAuth(route azRoute, model datasourceInfo, cfg *setting.Cfg) *plugins.JwtTokenAuth {
+	var authorityHost string
+	if cloud, ok := getAzureCloud(cfg, model.Settings.CloudName); ok {
+		authorityHost = cloud.AuthorityHost
+	}
+
+	return &plugins.JwtTokenAuth{
+		Url:    route.URL,
+		Scopes: route.Scopes,
+		Params: map[string]string{
+			"azure_auth_type":      model.Settings.AzureAuthType,
+			"azure_cloud":          cfg.Azure.Cloud,
+			"azure_authority_host": authorityHost,
+			"tenant_id":            model.Settings.TenantId,
+			"client_id":            model.Settings.ClientId,
+			"client_secret":        model.DecryptedSecureJSONData["clientSecret"],
+
+			"client_certificate":          model.DecryptedSecureJSONData["clientCertificate"],
+			"client_certificate_format":   model.Settings.ClientCertificateFormat,
+			"client_certificate_password": model.DecryptedSecureJSONData["clientCertificatePassword"],
+		},
+	}
+}
+
 func httpClientProvider(ctx context.Context, route azRoute, model datasourceInfo, cfg *setting.Cfg) *httpclient.Provider {
 	if len(route.Scopes) > 0 {
-		tokenAuth := &plugins.JwtTokenAuth{
-			Url:    route.URL,
-			Scopes: route.Scopes,
-			Params: map[string]string{
-				"azure_auth_type": model.Settings.AzureAuthType,
-				"azure_cloud":     cfg.Azure.Cloud,
-				"tenant_id":       model.Settings.TenantId,
-				"client_id":       model.Settings.ClientId,
-				"client_secret":   model.DecryptedSecureJSONData["clientSecret"],
-			},
-		}
+		tokenAuth := newTokenAuth(route, model, cfg)
 		tokenProvider := tokenprovider.NewAzureAccessTokenProvider(ctx, cfg, tokenAuth)
 		return httpclient.NewProvider(httpclient.ProviderOptions{
 			Middlewares: []httpclient.Middleware{
@@ -55,3 +80,44 @@ func newHTTPClient(ctx context.Context, route azRoute, model datasourceInfo, cfg
 	model.HTTPCliOpts.Headers = route.Headers
 	return httpClientProvider(ctx, route, model, cfg).New(model.HTTPCliOpts)
 }
+
+// withUserIdentity returns context of the query made on behalf of the signed-in user, the user is
+// identified by Grafana login and authenticates to Azure with the ID token forwarded by Grafana.
+func withUserIdentity(ctx context.Context, req *backend.QueryDataRequest) context.Context {
+	if req.PluginContext.User == nil {
+		return ctx
+	}
+
+	assertion := req.Headers["X-ID-Token"]
+	if assertion == "" {
+		return ctx
+	}
+
+	return tokenprovider.WithUserIdentity(ctx, tokenprovider.UserIdentity{
+		ID:        req.PluginContext.User.Login,
+		Assertion: assertion,
+	})
+}
+
+// purgeAccessTokens invalidates cached access tokens of the datasource credentials.
+func purgeAccessTokens(model datasourceInfo, cfg *setting.Cfg) {
+	for _, route := range model.Routes {
+		if len(route.Scopes) > 0 {
+			tokenAuth := newTokenAuth(route, model, cfg)
+			tokenprovider.NewAzureAccessTokenProvider(context.Background(), cfg, tokenAuth).PurgeAccessTokens()
+		}
+	}
+}
+
+// warmupAccessTokens acquires access tokens of the datasource credentials for all routes.
+func warmupAccessTokens(ctx context.Context, model datasourceInfo, cfg *setting.Cfg) error {
+	for _, route := range model.Routes {
+		if len(route.Scopes) > 0 {
+			tokenAuth := newTokenAuth(route, model, cfg)
+			if err := tokenprovider.NewAzureAccessTokenProvider(ctx, cfg, tokenAuth).WarmupAccessToken(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
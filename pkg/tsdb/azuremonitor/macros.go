@@ -19,6 +19,11 @@ const escapeMultiExpr = `\$__escapeMulti\(('.*')\)`
 type kqlMacroEngine struct {
 	timeRange backend.TimeRange
 	query     backend.DataQuery
+
+	// chunk is the part of the time range the query is run for when the query is split,
+	// the chunk end is exclusive unless it's the end of the whole time range
+	chunk       *backend.TimeRange
+	exclusiveTo bool
 }
 
 //  Macros:
@@ -41,6 +46,21 @@ func KqlInterpolate(query backend.DataQuery, dsInfo datasourceInfo, kql string,
 	return engine.Interpolate(query, dsInfo, kql, defaultTimeFieldForAllDatasources)
 }
 
+// kqlInterpolateChunk interpolates macros of the query run for a chunk of the query time range, the
+// interval is still calculated for the whole time range so that chunks can be merged.
+func kqlInterpolateChunk(query backend.DataQuery, chunk backend.TimeRange, lastChunk bool, dsInfo datasourceInfo, kql string, defaultTimeField string) (string, error) {
+	engine := kqlMacroEngine{chunk: &chunk, exclusiveTo: !lastChunk}
+	return engine.Interpolate(query, dsInfo, kql, defaultTimeField)
+}
+
+// filterRange returns the time range to filter the data by
+func (m *kqlMacroEngine) filterRange() backend.TimeRange {
+	if m.chunk != nil {
+		return *m.chunk
+	}
+	return m.timeRange
+}
+
 func (m *kqlMacroEngine) Interpolate(query backend.DataQuery, dsInfo datasourceInfo, kql string, defaultTimeField string) (string, error) {
 	m.timeRange = query.TimeRange
 	m.query = query
@@ -93,13 +113,18 @@ func (m *kqlMacroEngine) evaluateMacro(name string, defaultTimeField string, arg
 		if len(args) > 0 && args[0] != "" {
 			timeColumn = args[0]
 		}
-		return fmt.Sprintf("['%s'] >= datetime('%s') and ['%s'] <= datetime('%s')", timeColumn,
-			m.timeRange.From.UTC().Format(time.RFC3339), timeColumn,
-			m.timeRange.To.UTC().Format(time.RFC3339)), nil
+		toOperator := "<="
+		if m.exclusiveTo {
+			toOperator = "<"
+		}
+		filterRange := m.filterRange()
+		return fmt.Sprintf("['%s'] >= datetime('%s') and ['%s'] %s datetime('%s')", timeColumn,
+			filterRange.From.UTC().Format(time.RFC3339), timeColumn, toOperator,
+			filterRange.To.UTC().Format(time.RFC3339)), nil
 	case "timeFrom", "__from":
-		return fmt.Sprintf("datetime('%s')", m.timeRange.From.UTC().Format(time.RFC3339)), nil
+		return fmt.Sprintf("datetime('%s')", m.filterRange().From.UTC().Format(time.RFC3339)), nil
 	case "timeTo", "__to":
-		return fmt.Sprintf("datetime('%s')", m.timeRange.To.UTC().Format(time.RFC3339)), nil
+		return fmt.Sprintf("datetime('%s')", m.filterRange().To.UTC().Format(time.RFC3339)), nil
 	case "interval":
 		var it time.Duration
 		if m.query.Interval.Milliseconds() == 0 {
@@ -1,90 +1,138 @@
 package azuremonitor
 
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
 type azRoute struct {
 	URL     string
 	Scopes  []string
 	Headers map[string]string
 }
 
-var azManagement = azRoute{
-	URL:     "https://management.azure.com",
-	Scopes:  []string{"https://management.azure.com/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana"},
-}
+// azureCloud describes the endpoints of an Azure cloud. Services with empty URL
+// aren't available in the cloud.
+type azureCloud struct {
+	// AuthorityHost overrides the Azure AD authority resolved from Grafana Azure settings
+	AuthorityHost      string
+	ResourceManagerURL string
+	ResourceGraphURL   string
+	LogAnalyticsURL    string
+	AppInsightsURL     string
 
-var azUSGovManagement = azRoute{
-	URL:     "https://management.usgovcloudapi.net",
-	Scopes:  []string{"https://management.usgovcloudapi.net/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana"},
+	// MetricsBatchURL is the URL of the metrics batch API without the region prefix
+	MetricsBatchURL string
 }
 
-var azGermanyManagement = azRoute{
-	URL:     "https://management.microsoftazure.de",
-	Scopes:  []string{"https://management.microsoftazure.de/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana"},
-}
+var (
+	// Known Azure clouds identified by the cloud name configured in the datasource
+	azureClouds = map[string]azureCloud{
+		azureMonitorPublic: {
+			ResourceManagerURL: "https://management.azure.com",
+			ResourceGraphURL:   "https://management.azure.com",
+			LogAnalyticsURL:    "https://api.loganalytics.io",
+			AppInsightsURL:     "https://api.applicationinsights.io",
+			MetricsBatchURL:    "https://metrics.monitor.azure.com",
+		},
+		azureMonitorUSGovernment: {
+			ResourceManagerURL: "https://management.usgovcloudapi.net",
+			ResourceGraphURL:   "https://management.usgovcloudapi.net",
+			LogAnalyticsURL:    "https://api.loganalytics.us",
+			MetricsBatchURL:    "https://metrics.monitor.azure.us",
+		},
+		azureMonitorGermany: {
+			ResourceManagerURL: "https://management.microsoftazure.de",
+		},
+		azureMonitorChina: {
+			ResourceManagerURL: "https://management.chinacloudapi.cn",
+			ResourceGraphURL:   "https://management.chinacloudapi.cn",
+			LogAnalyticsURL:    "https://api.loganalytics.azure.cn",
+			AppInsightsURL:     "https://api.applicationinsights.azure.cn",
+			MetricsBatchURL:    "https://metrics.monitor.azure.cn",
+		},
+	}
 
-var azChinaManagement = azRoute{
-	URL:     "https://management.chinacloudapi.cn",
-	Scopes:  []string{"https://management.chinacloudapi.cn/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana"},
-}
+	// The different Azure routes are identified by its cloud (e.g. public or gov)
+	// and the service to query (e.g. Azure Monitor or Azure Log Analytics)
+	routes = buildAllRoutes(azureClouds)
+)
 
-var azAppInsights = azRoute{
-	URL:     "https://api.applicationinsights.io",
-	Scopes:  []string{},
-	Headers: map[string]string{"x-ms-app": "Grafana"},
+func buildAllRoutes(clouds map[string]azureCloud) map[string]map[string]azRoute {
+	result := make(map[string]map[string]azRoute, len(clouds))
+	for name, cloud := range clouds {
+		result[name] = cloud.buildRoutes()
+	}
+	return result
 }
 
-var azChinaAppInsights = azRoute{
-	URL:     "https://api.applicationinsights.azure.cn",
-	Scopes:  []string{},
-	Headers: map[string]string{"x-ms-app": "Grafana"},
-}
+// buildRoutes returns the routes of all services available in the cloud
+func (cloud azureCloud) buildRoutes() map[string]azRoute {
+	result := map[string]azRoute{}
+
+	if cloud.ResourceManagerURL != "" {
+		result[azureMonitor] = newAzRoute(cloud.ResourceManagerURL, true, nil)
+	}
+	if cloud.MetricsBatchURL != "" {
+		result[azureMonitorBatch] = newAzRoute(cloud.MetricsBatchURL, true, nil)
+	}
+	if cloud.ResourceGraphURL != "" {
+		result[azureResourceGraph] = newAzRoute(cloud.ResourceGraphURL, true, nil)
+	}
+	if cloud.LogAnalyticsURL != "" {
+		result[azureLogAnalytics] = newAzRoute(cloud.LogAnalyticsURL, true, map[string]string{"Cache-Control": "public, max-age=60"})
+	}
+	if cloud.AppInsightsURL != "" {
+		// Application Insights authenticates with API key
+		appInsightsRoute := newAzRoute(cloud.AppInsightsURL, false, nil)
+		result[appInsights] = appInsightsRoute
+		result[insightsAnalytics] = appInsightsRoute
+	}
 
-var azLogAnalytics = azRoute{
-	URL:     "https://api.loganalytics.io",
-	Scopes:  []string{"https://api.loganalytics.io/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana", "Cache-Control": "public, max-age=60"},
+	return result
 }
 
-var azChinaLogAnalytics = azRoute{
-	URL:     "https://api.loganalytics.azure.cn",
-	Scopes:  []string{"https://api.loganalytics.azure.cn/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana", "Cache-Control": "public, max-age=60"},
+func newAzRoute(url string, withScopes bool, headers map[string]string) azRoute {
+	scopes := []string{}
+	if withScopes {
+		scopes = append(scopes, strings.TrimSuffix(url, "/")+"/.default")
+	}
+
+	routeHeaders := map[string]string{"x-ms-app": "Grafana"}
+	for name, value := range headers {
+		routeHeaders[name] = value
+	}
+
+	return azRoute{URL: url, Scopes: scopes, Headers: routeHeaders}
 }
 
-var azUSGovLogAnalytics = azRoute{
-	URL:     "https://api.loganalytics.us",
-	Scopes:  []string{"https://api.loganalytics.us/.default"},
-	Headers: map[string]string{"x-ms-app": "Grafana", "Cache-Control": "public, max-age=60"},
+// getAzureCloud returns the cloud with the given name, custom clouds configured
+// in Grafana settings take precedence over the known ones.
+func getAzureCloud(cfg *setting.Cfg, cloudName string) (azureCloud, bool) {
+	if cfg != nil {
+		if custom, ok := cfg.Azure.CustomClouds[cloudName]; ok {
+			return azureCloud{
+				AuthorityHost:      custom.AuthorityHost,
+				ResourceManagerURL: custom.ResourceManagerURL,
+				ResourceGraphURL:   custom.ResourceGraphURL,
+				LogAnalyticsURL:    custom.LogAnalyticsURL,
+				AppInsightsURL:     custom.AppInsightsURL,
+			}, true
+		}
+	}
+
+	cloud, ok := azureClouds[cloudName]
+	return cloud, ok
 }
 
-var (
-	// The different Azure routes are identified by its cloud (e.g. public or gov)
-	// and the service to query (e.g. Azure Monitor or Azure Log Analytics)
-	routes = map[string]map[string]azRoute{
-		azureMonitorPublic: {
-			azureMonitor:       azManagement,
-			azureLogAnalytics:  azLogAnalytics,
-			azureResourceGraph: azManagement,
-			appInsights:        azAppInsights,
-			insightsAnalytics:  azAppInsights,
-		},
-		azureMonitorUSGovernment: {
-			azureMonitor:       azUSGovManagement,
-			azureLogAnalytics:  azUSGovLogAnalytics,
-			azureResourceGraph: azUSGovManagement,
-		},
-		azureMonitorGermany: {
-			azureMonitor: azGermanyManagement,
-		},
-		azureMonitorChina: {
-			azureMonitor:       azChinaManagement,
-			azureLogAnalytics:  azChinaLogAnalytics,
-			azureResourceGraph: azChinaManagement,
-			appInsights:        azChinaAppInsights,
-			insightsAnalytics:  azChinaAppInsights,
-		},
+// getRoutes returns the routes of the cloud with the given name
+func getRoutes(cfg *setting.Cfg, cloudName string) map[string]azRoute {
+	if cfg != nil {
+		if _, ok := cfg.Azure.CustomClouds[cloudName]; ok {
+			cloud, _ := getAzureCloud(cfg, cloudName)
+			return cloud.buildRoutes()
+		}
 	}
-)
+	return routes[cloudName]
+}
@@ -0,0 +1,45 @@
+package azuremonitor
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRoutes(t *testing.T) {
+	t.Run("should return routes of known cloud", func(t *testing.T) {
+		cloudRoutes := getRoutes(&setting.Cfg{}, azureMonitorUSGovernment)
+
+		assert.Equal(t, "https://management.usgovcloudapi.net", cloudRoutes[azureMonitor].URL)
+		assert.Equal(t, []string{"https://management.usgovcloudapi.net/.default"}, cloudRoutes[azureMonitor].Scopes)
+		assert.Equal(t, []string{"https://api.loganalytics.us/.default"}, cloudRoutes[azureLogAnalytics].Scopes)
+		assert.Equal(t, []string{"https://metrics.monitor.azure.us/.default"}, cloudRoutes[azureMonitorBatch].Scopes)
+		assert.NotContains(t, cloudRoutes, appInsights)
+	})
+
+	t.Run("should return routes of custom cloud", func(t *testing.T) {
+		cfg := &setting.Cfg{}
+		cfg.Azure.CustomClouds = map[string]setting.AzureCloudSettings{
+			"azurestack": {
+				AuthorityHost:      "https://login.azurestack.local/",
+				ResourceManagerURL: "https://management.local.azurestack.external",
+			},
+		}
+
+		cloudRoutes := getRoutes(cfg, "azurestack")
+
+		assert.Len(t, cloudRoutes, 1)
+		assert.Equal(t, "https://management.local.azurestack.external", cloudRoutes[azureMonitor].URL)
+		assert.Equal(t, []string{"https://management.local.azurestack.external/.default"}, cloudRoutes[azureMonitor].Scopes)
+		assert.Equal(t, "Grafana", cloudRoutes[azureMonitor].Headers["x-ms-app"])
+
+		cloud, ok := getAzureCloud(cfg, "azurestack")
+		assert.True(t, ok)
+		assert.Equal(t, "https://login.azurestack.local/", cloud.AuthorityHost)
+	})
+
+	t.Run("should return no routes for unknown cloud", func(t *testing.T) {
+		assert.Empty(t, getRoutes(&setting.Cfg{}, "unknown"))
+	})
+}
@@ -1,6 +1,7 @@
 package tokenprovider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -17,12 +18,24 @@ type TokenProvider interface {
 	GetAccessToken() (string, error)
 }
 
+// contextTokenProvider is implemented by providers which acquire tokens on behalf of the
+// request, e.g. to honor its cancellation and correlate the acquisition with the request.
+type contextTokenProvider interface {
+	GetAccessTokenWithContext(ctx context.Context) (string, error)
+}
+
 const authenticationMiddlewareName = "AzureAuthentication"
 
 func AuthMiddleware(tokenProvider TokenProvider) httpclient.Middleware {
 	return httpclient.NamedMiddlewareFunc(authenticationMiddlewareName, func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
 		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
-			token, err := tokenProvider.GetAccessToken()
+			var token string
+			var err error
+			if provider, ok := tokenProvider.(contextTokenProvider); ok {
+				token, err = provider.GetAccessTokenWithContext(req.Context())
+			} else {
+				token, err = tokenProvider.GetAccessToken()
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to retrieve azure access token: %w", err)
 			}
@@ -0,0 +1,109 @@
+package tokenprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// chainedTokenCredential tries the credentials in order until one of them returns a token,
+// the succeeded credential is remembered and used for subsequent requests.
+type chainedTokenCredential struct {
+	credentials []TokenCredential
+
+	mutex       sync.Mutex
+	initialized []TokenCredential
+	selected    TokenCredential
+}
+
+func (c *chainedTokenCredential) GetCacheKey() string {
+	keys := make([]string, len(c.credentials))
+	for i, credential := range c.credentials {
+		keys[i] = credential.GetCacheKey()
+	}
+	return fmt.Sprintf("azure|chain|%s", strings.Join(keys, ";"))
+}
+
+func (c *chainedTokenCredential) Init() error {
+	var errs []string
+	var initialized []TokenCredential
+
+	for _, credential := range c.credentials {
+		if err := credential.Init(); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		initialized = append(initialized, credential)
+	}
+
+	if len(initialized) == 0 {
+		return fmt.Errorf("no credential in the chain could be initialized: %s", strings.Join(errs, "; "))
+	}
+
+	c.mutex.Lock()
+	c.initialized = initialized
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *chainedTokenCredential) GetAccessToken(ctx context.Context, scopes []string) (*AccessToken, error) {
+	c.mutex.Lock()
+	selected := c.selected
+	credentials := c.initialized
+	c.mutex.Unlock()
+
+	if selected != nil {
+		token, err := selected.GetAccessToken(ctx, scopes)
+		if err == nil {
+			return token, nil
+		}
+		logger.Debug("Selected credential failed, trying the whole chain", "credential", selected.GetCacheKey(), "error", err)
+	}
+
+	var errs []string
+	for _, credential := range credentials {
+		token, err := credential.GetAccessToken(ctx, scopes)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		c.mutex.Lock()
+		c.selected = credential
+		c.mutex.Unlock()
+		return token, nil
+	}
+
+	return nil, fmt.Errorf("no credential in the chain returned access token: %s", strings.Join(errs, "; "))
+}
+
+// azureCliCredential authenticates with the account currently logged in Azure CLI, for development only.
+type azureCliCredential struct {
+	credential azcore.TokenCredential
+}
+
+func (c *azureCliCredential) GetCacheKey() string {
+	return "azure|cli"
+}
+
+func (c *azureCliCredential) Init() error {
+	if credential, err := azidentity.NewAzureCLICredential(nil); err != nil {
+		return err
+	} else {
+		c.credential = credential
+		return nil
+	}
+}
+
+func (c *azureCliCredential) GetAccessToken(ctx context.Context, scopes []string) (*AccessToken, error) {
+	accessToken, err := c.credential.GetToken(ctx, azcore.TokenRequestOptions{Scopes: scopes})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessToken{Token: accessToken.Token, ExpiresOn: accessToken.ExpiresOn}, nil
+}
@@ -0,0 +1,74 @@
+package tokenprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainedTokenCredential(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	failingCredential := func(key string) *fakeCredential {
+		return &fakeCredential{
+			key: key,
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				return nil, errors.New("unable to get access token")
+			},
+		}
+	}
+
+	t.Run("should return token of first succeeded credential and remember it", func(t *testing.T) {
+		credential1 := failingCredential("credential-1")
+		credential2 := &fakeCredential{key: "credential-2"}
+		credential3 := &fakeCredential{key: "credential-3"}
+
+		chain := &chainedTokenCredential{credentials: []TokenCredential{credential1, credential2, credential3}}
+		require.NoError(t, chain.Init())
+
+		token, err := chain.GetAccessToken(ctx, scopes)
+		require.NoError(t, err)
+		assert.Equal(t, "credential-2-token-1", token.Token)
+
+		token, err = chain.GetAccessToken(ctx, scopes)
+		require.NoError(t, err)
+		assert.Equal(t, "credential-2-token-2", token.Token)
+
+		assert.Equal(t, 1, credential1.calledTimes)
+		assert.Equal(t, 0, credential3.calledTimes)
+	})
+
+	t.Run("should skip credentials which failed to initialize", func(t *testing.T) {
+		credential1 := &fakeCredential{
+			key:      "credential-1",
+			initFunc: func() error { return errors.New("unable to initialize") },
+		}
+		credential2 := &fakeCredential{key: "credential-2"}
+
+		chain := &chainedTokenCredential{credentials: []TokenCredential{credential1, credential2}}
+		require.NoError(t, chain.Init())
+
+		token, err := chain.GetAccessToken(ctx, scopes)
+		require.NoError(t, err)
+		assert.Equal(t, "credential-2-token-1", token.Token)
+		assert.Equal(t, 0, credential1.calledTimes)
+	})
+
+	t.Run("should return error when all credentials fail", func(t *testing.T) {
+		chain := &chainedTokenCredential{credentials: []TokenCredential{failingCredential("credential-1"), failingCredential("credential-2")}}
+		require.NoError(t, chain.Init())
+
+		_, err := chain.GetAccessToken(ctx, scopes)
+		assert.Error(t, err)
+	})
+
+	t.Run("should have cache key of all credentials", func(t *testing.T) {
+		chain := &chainedTokenCredential{credentials: []TokenCredential{&fakeCredential{key: "credential-1"}, &fakeCredential{key: "credential-2"}}}
+		assert.Equal(t, "azure|chain|credential-1;credential-2", chain.GetCacheKey())
+	})
+}
@@ -0,0 +1,54 @@
+package tokenprovider
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var tokenCacheHits = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "azure_token_cache_hits_total",
+		Help:      "A counter for Azure access tokens served from the cache",
+	},
+)
+
+var tokenCacheMisses = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "azure_token_cache_misses_total",
+		Help:      "A counter for Azure access token requests which had to wait for a token to be acquired",
+	},
+)
+
+var tokenRefreshes = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "azure_token_refreshes_total",
+		Help:      "A counter for Azure access token acquisitions",
+	},
+)
+
+var tokenRefreshFailures = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "azure_token_refresh_failures_total",
+		Help:      "A counter for failed Azure access token acquisitions",
+	},
+)
+
+var tokenAcquisitionDuration = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "azure_token_acquisition_duration_seconds",
+		Help:      "Histogram of Azure access token acquisition latency",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+	},
+)
+
+func init() {
+	prometheus.MustRegister(tokenCacheHits,
+		tokenCacheMisses,
+		tokenRefreshes,
+		tokenRefreshFailures,
+		tokenAcquisitionDuration)
+}
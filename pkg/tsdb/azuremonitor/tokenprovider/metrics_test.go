@@ -0,0 +1,52 @@
+package tokenprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCacheMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should count hits, misses and refreshes", func(t *testing.T) {
+		hits := testutil.ToFloat64(tokenCacheHits)
+		misses := testutil.ToFloat64(tokenCacheMisses)
+		refreshes := testutil.ToFloat64(tokenRefreshes)
+
+		cache := NewConcurrentTokenCache()
+		credential := &fakeCredential{key: "credential-1"}
+
+		_, err := cache.GetAccessToken(ctx, credential, scopes)
+		require.NoError(t, err)
+		_, err = cache.GetAccessToken(ctx, credential, scopes)
+		require.NoError(t, err)
+
+		assert.Equal(t, hits+1, testutil.ToFloat64(tokenCacheHits))
+		assert.Equal(t, misses+1, testutil.ToFloat64(tokenCacheMisses))
+		assert.Equal(t, refreshes+1, testutil.ToFloat64(tokenRefreshes))
+	})
+
+	t.Run("should count refresh failures", func(t *testing.T) {
+		failures := testutil.ToFloat64(tokenRefreshFailures)
+
+		cache := NewConcurrentTokenCache()
+		credential := &fakeCredential{
+			key: "credential-1",
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				return nil, errors.New("unable to get access token")
+			},
+		}
+
+		_, err := cache.GetAccessToken(ctx, credential, scopes)
+		require.Error(t, err)
+
+		assert.Equal(t, failures+1, testutil.ToFloat64(tokenRefreshFailures))
+	})
+}
@@ -0,0 +1,86 @@
+package tokenprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	onBehalfOfGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+type userIdentityKey struct{}
+
+// UserIdentity is the signed-in Grafana user on whose behalf Azure services are queried.
+type UserIdentity struct {
+	// ID identifies the user in Grafana, access tokens are cached per user ID
+	ID string
+
+	// Assertion is the Azure AD token of the user issued to Grafana (e.g. ID token)
+	Assertion string
+}
+
+// WithUserIdentity returns context of the request made on behalf of the given user.
+func WithUserIdentity(ctx context.Context, identity UserIdentity) context.Context {
+	return context.WithValue(ctx, userIdentityKey{}, identity)
+}
+
+func getUserIdentity(ctx context.Context) (UserIdentity, bool) {
+	identity, ok := ctx.Value(userIdentityKey{}).(UserIdentity)
+	return identity, ok && identity.ID != "" && identity.Assertion != ""
+}
+
+// onBehalfOfCredential exchanges the Azure AD token of the signed-in user for an access token using
+// the OAuth on-behalf-of flow, so that Azure RBAC applies to the user instead of the datasource.
+// The credential is bound to a single user, the user assertion is taken from the request context
+// since it changes over time while the cached access tokens stay valid.
+type onBehalfOfCredential struct {
+	authority    string
+	tenantId     string
+	clientId     string
+	clientSecret string
+	userId       string
+
+	httpClient *http.Client
+}
+
+func (c *onBehalfOfCredential) GetCacheKey() string {
+	return fmt.Sprintf("azure|obo|%s|%s|%s|%s|%s", c.authority, c.tenantId, c.clientId, hashSecret(c.clientSecret), c.userId)
+}
+
+func (c *onBehalfOfCredential) Init() error {
+	if c.tenantId == "" || c.clientId == "" || c.clientSecret == "" {
+		return fmt.Errorf("tenant ID, client ID and client secret must be configured for on-behalf-of authentication")
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return nil
+}
+
+func (c *onBehalfOfCredential) GetAccessToken(ctx context.Context, scopes []string) (*AccessToken, error) {
+	identity, ok := getUserIdentity(ctx)
+	if !ok {
+		return nil, fmt.Errorf("identity of the signed-in user not available, on-behalf-of authentication requires Grafana login with Azure AD and forwarding of OAuth identity")
+	}
+	if identity.ID != c.userId {
+		return nil, fmt.Errorf("signed-in user doesn't match the user of the credential")
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.clientId)
+	form.Set("client_secret", c.clientSecret)
+	form.Set("grant_type", onBehalfOfGrantType)
+	form.Set("requested_token_use", "on_behalf_of")
+	form.Set("assertion", identity.Assertion)
+	form.Set("scope", strings.Join(scopes, " "))
+
+	tokenUrl := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(c.authority, "/"), url.PathEscape(c.tenantId))
+
+	return requestAccessToken(ctx, c.httpClient, tokenUrl, form)
+}
@@ -0,0 +1,68 @@
+package tokenprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnBehalfOfCredential_GetAccessToken(t *testing.T) {
+	scopes := []string{"https://management.azure.com/.default"}
+
+	t.Run("should exchange user assertion for access token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/tenant-1/oauth2/v2.0/token", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client-1", r.PostForm.Get("client_id"))
+			assert.Equal(t, "secret-1", r.PostForm.Get("client_secret"))
+			assert.Equal(t, onBehalfOfGrantType, r.PostForm.Get("grant_type"))
+			assert.Equal(t, "on_behalf_of", r.PostForm.Get("requested_token_use"))
+			assert.Equal(t, "id-token-1", r.PostForm.Get("assertion"))
+			assert.Equal(t, "https://management.azure.com/.default", r.PostForm.Get("scope"))
+
+			_, _ = w.Write([]byte(`{"access_token":"access-token-1","expires_in":3599,"token_type":"Bearer"}`))
+		}))
+		defer server.Close()
+
+		credential := &onBehalfOfCredential{authority: server.URL, tenantId: "tenant-1", clientId: "client-1", clientSecret: "secret-1", userId: "user-1"}
+		require.NoError(t, credential.Init())
+
+		ctx := WithUserIdentity(context.Background(), UserIdentity{ID: "user-1", Assertion: "id-token-1"})
+		token, err := credential.GetAccessToken(ctx, scopes)
+		require.NoError(t, err)
+		assert.Equal(t, "access-token-1", token.Token)
+	})
+
+	t.Run("should fail without user identity", func(t *testing.T) {
+		credential := &onBehalfOfCredential{tenantId: "tenant-1", clientId: "client-1", clientSecret: "secret-1", userId: "user-1"}
+		require.NoError(t, credential.Init())
+
+		_, err := credential.GetAccessToken(context.Background(), scopes)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail when user doesn't match the credential", func(t *testing.T) {
+		credential := &onBehalfOfCredential{tenantId: "tenant-1", clientId: "client-1", clientSecret: "secret-1", userId: "user-1"}
+		require.NoError(t, credential.Init())
+
+		ctx := WithUserIdentity(context.Background(), UserIdentity{ID: "user-2", Assertion: "id-token-2"})
+		_, err := credential.GetAccessToken(ctx, scopes)
+		assert.Error(t, err)
+	})
+
+	t.Run("should have different cache keys for different users", func(t *testing.T) {
+		credential1 := &onBehalfOfCredential{tenantId: "tenant-1", clientId: "client-1", clientSecret: "secret-1", userId: "user-1"}
+		credential2 := &onBehalfOfCredential{tenantId: "tenant-1", clientId: "client-1", clientSecret: "secret-1", userId: "user-2"}
+
+		assert.NotEqual(t, credential1.GetCacheKey(), credential2.GetCacheKey())
+	})
+
+	t.Run("should fail init without client secret", func(t *testing.T) {
+		credential := &onBehalfOfCredential{tenantId: "tenant-1", clientId: "client-1", userId: "user-1"}
+		assert.Error(t, credential.Init())
+	})
+}
@@ -0,0 +1,73 @@
+package tokenprovider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+var (
+	auditLogger log.Logger = log.New("tsdb.azuremonitor.tokenaudit")
+
+	// writeAuditRecord makes it possible to test audit logging
+	writeAuditRecord = func(record tokenAuditRecord) { record.write() }
+)
+
+type correlationIdKey struct{}
+
+// WithCorrelationID returns context carrying the ID which correlates token acquisitions
+// with the request that caused them. When not set, the ID of the current trace is used.
+func WithCorrelationID(ctx context.Context, correlationId string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, correlationId)
+}
+
+func getCorrelationID(ctx context.Context) string {
+	if correlationId, ok := ctx.Value(correlationIdKey{}).(string); ok && correlationId != "" {
+		return correlationId
+	}
+
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		if spanContext, ok := span.Context().(jaeger.SpanContext); ok {
+			return spanContext.TraceID().String()
+		}
+	}
+
+	return ""
+}
+
+// tokenAuditRecord describes a single token refresh, the credential is identified by hash of
+// its cache key so that no secrets are written to the log.
+type tokenAuditRecord struct {
+	credentialHash string
+	scopes         []string
+	source         string
+	background     bool
+	correlationId  string
+	duration       time.Duration
+	err            error
+}
+
+func (r tokenAuditRecord) write() {
+	trigger := "request"
+	if r.background {
+		trigger = "background"
+	}
+
+	ctx := []interface{}{
+		"credential", r.credentialHash,
+		"scopes", strings.Join(r.scopes, " "),
+		"trigger", trigger,
+		"correlationId", r.correlationId,
+		"duration", r.duration,
+	}
+
+	if r.err != nil {
+		auditLogger.Warn("Azure access token acquisition failed", append(ctx, "error", r.err)...)
+	} else {
+		auditLogger.Info("Azure access token acquired", append(ctx, "source", r.source)...)
+	}
+}
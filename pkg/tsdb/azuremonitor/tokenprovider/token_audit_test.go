@@ -0,0 +1,90 @@
+package tokenprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestConcurrentTokenCache_AuditLog(t *testing.T) {
+	var records []tokenAuditRecord
+	origWriteAuditRecord := writeAuditRecord
+	writeAuditRecord = func(record tokenAuditRecord) { records = append(records, record) }
+	t.Cleanup(func() { writeAuditRecord = origWriteAuditRecord })
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should write record of successful acquisition", func(t *testing.T) {
+		records = nil
+		cache := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{AuditLog: true})
+		credential := &fakeCredential{key: "credential-1"}
+
+		_, err := cache.GetAccessToken(WithCorrelationID(context.Background(), "request-1"), credential, scopes)
+		require.NoError(t, err)
+
+		// Cached token isn't audited
+		_, err = cache.GetAccessToken(context.Background(), credential, scopes)
+		require.NoError(t, err)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, hashSecret("credential-1"), records[0].credentialHash)
+		assert.Equal(t, scopes, records[0].scopes)
+		assert.Equal(t, "endpoint", records[0].source)
+		assert.Equal(t, "request-1", records[0].correlationId)
+		assert.False(t, records[0].background)
+		assert.NoError(t, records[0].err)
+	})
+
+	t.Run("should write record of failed acquisition", func(t *testing.T) {
+		records = nil
+		cache := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{AuditLog: true})
+		credential := &fakeCredential{key: "credential-1", getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+			return nil, errors.New("unauthorized")
+		}}
+
+		_, err := cache.GetAccessToken(context.Background(), credential, scopes)
+		require.Error(t, err)
+
+		require.Len(t, records, 1)
+		assert.EqualError(t, records[0].err, "unauthorized")
+	})
+
+	t.Run("should not write records when disabled", func(t *testing.T) {
+		records = nil
+		cache := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{})
+		credential := &fakeCredential{key: "credential-1"}
+
+		_, err := cache.GetAccessToken(context.Background(), credential, scopes)
+		require.NoError(t, err)
+
+		assert.Empty(t, records)
+	})
+}
+
+func TestGetCorrelationID(t *testing.T) {
+	t.Run("should return explicit correlation ID", func(t *testing.T) {
+		ctx := WithCorrelationID(context.Background(), "request-1")
+		assert.Equal(t, "request-1", getCorrelationID(ctx))
+	})
+
+	t.Run("should return trace ID of the current span", func(t *testing.T) {
+		tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+		t.Cleanup(func() { _ = closer.Close() })
+
+		span := tracer.StartSpan("query")
+		defer span.Finish()
+		ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+		traceId := span.Context().(jaeger.SpanContext).TraceID().String()
+		assert.Equal(t, traceId, getCorrelationID(ctx))
+	})
+
+	t.Run("should return empty ID without request context", func(t *testing.T) {
+		assert.Equal(t, "", getCorrelationID(context.Background()))
+	})
+}
@@ -2,11 +2,26 @@ package tokenprovider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	logger = log.New("tsdb.azuremonitor.tokenprovider")
+
+	// tokenRequests coalesces token requests of the same identity instance-wide
+	tokenRequests singleflight.Group
 )
 
 type AccessToken struct {
@@ -20,19 +35,120 @@ type TokenCredential interface {
 	GetAccessToken(ctx context.Context, scopes []string) (*AccessToken, error)
 }
 
+// IdentityCredential is implemented by credentials which authenticate as an identity that can be
+// shared by different credentials, e.g. data sources configured with the same service principal.
+// Concurrent token requests for the same identity and scopes are coalesced, so the identity key must
+// include a hash of the secret or certificate, otherwise a credential could get a token acquired
+// with the credential material of another one.
+type IdentityCredential interface {
+	GetIdentityKey() string
+}
+
 type ConcurrentTokenCache interface {
 	GetAccessToken(ctx context.Context, credential TokenCredential, scopes []string) (string, error)
+	// Warmup acquires and caches the token for the credential and scopes if not cached yet.
+	Warmup(ctx context.Context, credential TokenCredential, scopes []string) error
+	Purge(cacheKey string)
 }
 
+// TokenCacheOptions configures optional behaviour of the token cache.
+type TokenCacheOptions struct {
+	// ExpiryMargin is how long before the expiry a cached token stops being used, so that the token
+	// doesn't expire while the request is in flight. For short-lived tokens the margin is limited
+	// to half of the token lifetime. Defaults to 2 minutes.
+	ExpiryMargin time.Duration
+
+	// ClockSkew is the tolerated difference between the clock of Grafana and the clocks of Azure AD
+	// and of Grafana instances sharing tokens through the store, it's added to the expiry margin.
+	ClockSkew time.Duration
+
+	// BackgroundRefresh enables renewal of cached tokens before they expire,
+	// so that callers don't have to wait for the token endpoint.
+	BackgroundRefresh bool
+
+	// RefreshLeadTime is how long before the expiry a token gets renewed in background.
+	// Defaults to 5 minutes.
+	RefreshLeadTime time.Duration
+
+	// RefreshJitter is the upper bound of a random offset subtracted from the refresh time,
+	// which spreads refreshes of tokens with the same expiry. Defaults to 30 seconds.
+	RefreshJitter time.Duration
+
+	// EntryTTL is how long a credential may stay unused before its tokens get evicted.
+	// Zero disables the expiration.
+	EntryTTL time.Duration
+
+	// MaxEntries limits the number of cached credentials, least recently used ones
+	// get evicted first. Zero means unlimited.
+	MaxEntries int
+
+	// MaxRetries is how many times a transient token acquisition failure (e.g. 429 or 5xx
+	// response from the token endpoint) is retried. Zero disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubled on every next attempt.
+	// Defaults to 500 milliseconds.
+	RetryBackoff time.Duration
+
+	// NegativeCacheTTL is how long a failed token acquisition is returned to callers
+	// before a new attempt is made. Zero disables caching of failures.
+	NegativeCacheTTL time.Duration
+
+	// Store shares acquired tokens with other Grafana instances, tokens are looked up
+	// in the store before requesting the token endpoint. Optional.
+	Store TokenCacheStore
+
+	// AuditLog enables logging of every token refresh with the outcome, latency and
+	// correlation ID of the request which caused it.
+	AuditLog bool
+}
+
+const (
+	defaultExpiryMargin    = 2 * time.Minute
+	defaultRefreshLeadTime = 5 * time.Minute
+	defaultRefreshJitter   = 30 * time.Second
+	defaultRetryBackoff    = 500 * time.Millisecond
+
+	// evictionInterval is how often stale entries are looked up when the cache isn't full
+	evictionInterval = time.Minute
+
+	// coalescedRequestTimeout limits coalesced token requests, which don't run on the context of
+	// the caller as they are shared with other callers
+	coalescedRequestTimeout = time.Minute
+)
+
 func NewConcurrentTokenCache() ConcurrentTokenCache {
 	return &tokenCacheImpl{}
 }
 
+func NewConcurrentTokenCacheWithOptions(options TokenCacheOptions) ConcurrentTokenCache {
+	if options.ExpiryMargin <= 0 {
+		options.ExpiryMargin = defaultExpiryMargin
+	}
+	if options.RefreshLeadTime <= 0 {
+		options.RefreshLeadTime = defaultRefreshLeadTime
+	}
+	if options.RefreshJitter <= 0 {
+		options.RefreshJitter = defaultRefreshJitter
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = defaultRetryBackoff
+	}
+	return &tokenCacheImpl{options: options}
+}
+
 type tokenCacheImpl struct {
-	cache sync.Map // of *credentialCacheEntry
+	options TokenCacheOptions
+	cache   sync.Map // of *credentialCacheEntry
+
+	size         int32
+	evicting     uint32
+	lastEviction int64
 }
 type credentialCacheEntry struct {
 	credential TokenCredential
+	options    *TokenCacheOptions
+	lastAccess int64
 
 	credInit  uint32
 	credMutex sync.Mutex
@@ -42,14 +158,40 @@ type credentialCacheEntry struct {
 type scopesCacheEntry struct {
 	credential TokenCredential
 	scopes     []string
-
-	cond        *sync.Cond
-	refreshing  bool
-	accessToken *AccessToken
+	storeKey   string
+	options    *TokenCacheOptions
+
+	mutex        sync.Mutex
+	refreshDone  chan struct{} // set while refreshing, closed once the refresh completes
+	closed       bool
+	accessToken  *AccessToken
+	validUntil   time.Time
+	refreshTimer *time.Timer
+
+	lastError     error
+	lastErrorTime time.Time
 }
 
 func (c *tokenCacheImpl) GetAccessToken(ctx context.Context, credential TokenCredential, scopes []string) (string, error) {
-	return c.getEntryFor(credential).getAccessToken(ctx, scopes)
+	entry := c.getEntryFor(credential)
+	atomic.StoreInt64(&entry.lastAccess, timeNow().UnixNano())
+
+	c.evictIfNeeded()
+
+	return entry.getAccessToken(ctx, scopes)
+}
+
+func (c *tokenCacheImpl) Warmup(ctx context.Context, credential TokenCredential, scopes []string) error {
+	_, err := c.GetAccessToken(ctx, credential, scopes)
+	return err
+}
+
+// Purge removes all cached tokens of the credential with the given cache key.
+func (c *tokenCacheImpl) Purge(cacheKey string) {
+	if entry, ok := c.cache.LoadAndDelete(cacheKey); ok {
+		atomic.AddInt32(&c.size, -1)
+		entry.(*credentialCacheEntry).close()
+	}
 }
 
 func (c *tokenCacheImpl) getEntryFor(credential TokenCredential) *credentialCacheEntry {
@@ -59,14 +201,68 @@ func (c *tokenCacheImpl) getEntryFor(credential TokenCredential) *credentialCach
 	key := credential.GetCacheKey()
 
 	if entry, ok = c.cache.Load(key); !ok {
-		entry, _ = c.cache.LoadOrStore(key, &credentialCacheEntry{
+		var loaded bool
+		entry, loaded = c.cache.LoadOrStore(key, &credentialCacheEntry{
 			credential: credential,
+			options:    &c.options,
 		})
+		if !loaded {
+			atomic.AddInt32(&c.size, 1)
+		}
 	}
 
 	return entry.(*credentialCacheEntry)
 }
 
+func (c *tokenCacheImpl) evictIfNeeded() {
+	if c.options.EntryTTL <= 0 && c.options.MaxEntries <= 0 {
+		return
+	}
+
+	now := timeNow()
+
+	overflow := c.options.MaxEntries > 0 && int(atomic.LoadInt32(&c.size)) > c.options.MaxEntries
+	if !overflow && now.UnixNano()-atomic.LoadInt64(&c.lastEviction) < int64(evictionInterval) {
+		return
+	}
+
+	// Only one caller evicts at a time, others don't wait for it
+	if !atomic.CompareAndSwapUint32(&c.evicting, 0, 1) {
+		return
+	}
+	defer atomic.StoreUint32(&c.evicting, 0)
+
+	atomic.StoreInt64(&c.lastEviction, now.UnixNano())
+	c.evict(now)
+}
+
+func (c *tokenCacheImpl) evict(now time.Time) {
+	type keyedEntry struct {
+		key        interface{}
+		lastAccess int64
+	}
+	var remaining []keyedEntry
+
+	c.cache.Range(func(key, value interface{}) bool {
+		lastAccess := atomic.LoadInt64(&value.(*credentialCacheEntry).lastAccess)
+		if c.options.EntryTTL > 0 && now.Sub(time.Unix(0, lastAccess)) > c.options.EntryTTL {
+			c.Purge(key.(string))
+		} else {
+			remaining = append(remaining, keyedEntry{key: key, lastAccess: lastAccess})
+		}
+		return true
+	})
+
+	if c.options.MaxEntries > 0 && len(remaining) > c.options.MaxEntries {
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].lastAccess < remaining[j].lastAccess
+		})
+		for _, entry := range remaining[:len(remaining)-c.options.MaxEntries] {
+			c.Purge(entry.key.(string))
+		}
+	}
+}
+
 func (c *credentialCacheEntry) getAccessToken(ctx context.Context, scopes []string) (string, error) {
 	err := c.ensureInitialized()
 	if err != nil {
@@ -95,6 +291,14 @@ func (c *credentialCacheEntry) ensureInitialized() error {
 	return nil
 }
 
+// close stops background activity of all scopes of the credential.
+func (c *credentialCacheEntry) close() {
+	c.cache.Range(func(_, value interface{}) bool {
+		value.(*scopesCacheEntry).close()
+		return true
+	})
+}
+
 func (c *credentialCacheEntry) getEntryFor(scopes []string) *scopesCacheEntry {
 	var entry interface{}
 	var ok bool
@@ -105,7 +309,8 @@ func (c *credentialCacheEntry) getEntryFor(scopes []string) *scopesCacheEntry {
 		entry, _ = c.cache.LoadOrStore(key, &scopesCacheEntry{
 			credential: c.credential,
 			scopes:     scopes,
-			cond:       sync.NewCond(&sync.Mutex{}),
+			storeKey:   getStoreKey(c.credential.GetCacheKey(), key),
+			options:    c.options,
 		})
 	}
 
@@ -116,29 +321,57 @@ func (c *scopesCacheEntry) getAccessToken(ctx context.Context) (string, error) {
 	var accessToken *AccessToken
 	var err error
 	shouldRefresh := false
+	waited := false
 
-	c.cond.L.Lock()
 	for {
-		if c.accessToken != nil && c.accessToken.ExpiresOn.After(time.Now().Add(2*time.Minute)) {
+		c.mutex.Lock()
+
+		if c.accessToken != nil && timeNow().Before(c.validUntil) {
 			// Use the cached token since it's available and not expired yet
 			accessToken = c.accessToken
+			c.mutex.Unlock()
+			break
+		}
+
+		if c.lastError != nil && c.options != nil && timeNow().Before(c.lastErrorTime.Add(c.options.NegativeCacheTTL)) {
+			// Return the recent failure instead of requesting the token endpoint again
+			err = c.lastError
+			c.mutex.Unlock()
 			break
 		}
 
-		if !c.refreshing {
+		if c.refreshDone == nil {
 			// Start refreshing the token
-			c.refreshing = true
+			c.refreshDone = make(chan struct{})
 			shouldRefresh = true
+			c.mutex.Unlock()
 			break
 		}
 
-		// Wait for the token to be refreshed
-		c.cond.Wait()
+		refreshDone := c.refreshDone
+		c.mutex.Unlock()
+
+		// Wait for the token to be refreshed or the caller to give up
+		waited = true
+		select {
+		case <-refreshDone:
+		case <-ctx.Done():
+			return "", fmt.Errorf("failed to wait for access token: %w", ctx.Err())
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if shouldRefresh || waited {
+		tokenCacheMisses.Inc()
+	} else {
+		tokenCacheHits.Inc()
 	}
-	c.cond.L.Unlock()
 
 	if shouldRefresh {
-		accessToken, err = c.refreshAccessToken(ctx)
+		accessToken, err = c.refreshAccessToken(ctx, false)
 		if err != nil {
 			return "", err
 		}
@@ -147,31 +380,253 @@ func (c *scopesCacheEntry) getAccessToken(ctx context.Context) (string, error) {
 	return accessToken.Token, nil
 }
 
-func (c *scopesCacheEntry) refreshAccessToken(ctx context.Context) (*AccessToken, error) {
+func (c *scopesCacheEntry) refreshAccessToken(ctx context.Context, background bool) (*AccessToken, error) {
 	var accessToken *AccessToken
+	var validUntil time.Time
+	var err error
+	source := "endpoint"
+	start := timeNow()
 
 	// Safeguarding from panic caused by credential implementation
 	defer func() {
-		c.cond.L.Lock()
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
 
-		c.refreshing = false
+		close(c.refreshDone)
+		c.refreshDone = nil
 
 		if accessToken != nil {
 			c.accessToken = accessToken
+			c.validUntil = validUntil
+			c.lastError = nil
+			c.scheduleRefresh()
+		} else if err != nil && !isContextError(err) {
+			// The cancellation of a caller doesn't tell anything about the token endpoint, it must not
+			// fail the other callers
+			c.lastError = err
+			c.lastErrorTime = timeNow()
+		}
+	}()
+
+	tokenRefreshes.Inc()
+	defer func() {
+		duration := timeNow().Sub(start)
+		tokenAcquisitionDuration.Observe(duration.Seconds())
+		if accessToken == nil {
+			tokenRefreshFailures.Inc()
 		}
 
-		c.cond.Broadcast()
-		c.cond.L.Unlock()
+		if c.options != nil && c.options.AuditLog {
+			writeAuditRecord(tokenAuditRecord{
+				credentialHash: hashSecret(c.credential.GetCacheKey()),
+				scopes:         c.scopes,
+				source:         source,
+				background:     background,
+				correlationId:  getCorrelationID(ctx),
+				duration:       duration,
+				err:            err,
+			})
+		}
 	}()
 
-	token, err := c.credential.GetAccessToken(ctx, c.scopes)
+	store := c.getStore()
+	if store != nil {
+		token, err := store.Get(ctx, c.storeKey)
+		if err != nil {
+			logger.Warn("Failed to read access token from store", "error", err)
+		} else if token != nil && token.ExpiresOn.After(timeNow().Add(c.getExpiryMargin())) && !c.isRefreshDue(token) {
+			// Token acquired by another instance
+			accessToken = token
+			validUntil = token.ExpiresOn.Add(-c.getExpiryMargin())
+			source = "store"
+			return accessToken, nil
+		}
+	}
+
+	var token *AccessToken
+	token, err = c.acquireAccessToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 	accessToken = token
+	validUntil = c.getValidUntil(token, start)
+
+	if store != nil {
+		if err := store.Set(ctx, c.storeKey, accessToken); err != nil {
+			logger.Warn("Failed to write access token to store", "error", err)
+		}
+	}
+
 	return accessToken, nil
 }
 
+// isRefreshDue reports whether the token is about to be renewed by background refresh,
+// such tokens are not taken from the store as the refresh would be scheduled immediately.
+func (c *scopesCacheEntry) isRefreshDue(token *AccessToken) bool {
+	return c.options.BackgroundRefresh && !token.ExpiresOn.After(timeNow().Add(c.options.RefreshLeadTime))
+}
+
+// getExpiryMargin returns how long before the expiry tokens stop being used, including
+// the tolerated clock skew.
+func (c *scopesCacheEntry) getExpiryMargin() time.Duration {
+	if c.options == nil {
+		return defaultExpiryMargin
+	}
+
+	margin := c.options.ExpiryMargin
+	if margin <= 0 {
+		margin = defaultExpiryMargin
+	}
+	if c.options.ClockSkew > 0 {
+		margin += c.options.ClockSkew
+	}
+	return margin
+}
+
+// getValidUntil returns the time until the token acquired at the given time can be used. The margin
+// is limited to half of the token lifetime, otherwise short-lived tokens would be requested on every call.
+func (c *scopesCacheEntry) getValidUntil(token *AccessToken, acquired time.Time) time.Time {
+	margin := c.getExpiryMargin()
+	if lifetime := token.ExpiresOn.Sub(acquired); margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	return token.ExpiresOn.Add(-margin)
+}
+
+// acquireAccessToken requests the token from the credential, retrying transient failures with backoff.
+func (c *scopesCacheEntry) acquireAccessToken(ctx context.Context) (*AccessToken, error) {
+	maxRetries := 0
+	var backoff time.Duration
+	if c.options != nil {
+		maxRetries = c.options.MaxRetries
+		backoff = c.options.RetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.requestAccessToken(ctx)
+		if err == nil || attempt >= maxRetries || !isTransientError(err) {
+			return token, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// requestAccessToken requests the token from the credential, requests of credentials with
+// the same identity are deduplicated across all cache entries. The shared request runs detached
+// from the context of the caller, so that a cancelled caller doesn't fail the other ones.
+func (c *scopesCacheEntry) requestAccessToken(ctx context.Context) (*AccessToken, error) {
+	identity, ok := c.credential.(IdentityCredential)
+	if !ok {
+		return c.credential.GetAccessToken(ctx, c.scopes)
+	}
+
+	key := identity.GetIdentityKey() + "\n" + getKeyForScopes(c.scopes)
+	resultCh := tokenRequests.DoChan(key, func() (interface{}, error) {
+		requestCtx, cancel := context.WithTimeout(context.Background(), coalescedRequestTimeout)
+		defer cancel()
+		return c.credential.GetAccessToken(requestCtx, c.scopes)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*AccessToken), nil
+	}
+}
+
+// isContextError reports whether the token acquisition failed because the context of the caller is done.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// isTransientError reports whether the token acquisition failure may succeed on retry.
+func isTransientError(err error) bool {
+	var authErr *azidentity.AADAuthenticationFailedError
+	if errors.As(err, &authErr) && authErr.Response != nil && authErr.Response.Response != nil {
+		statusCode := authErr.Response.StatusCode
+		return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+	}
+
+	var tempErr interface{ Temporary() bool }
+	if errors.As(err, &tempErr) {
+		return tempErr.Temporary()
+	}
+
+	return false
+}
+
+func (c *scopesCacheEntry) close() {
+	c.mutex.Lock()
+	c.closed = true
+	if c.refreshTimer != nil {
+		c.refreshTimer.Stop()
+	}
+	c.mutex.Unlock()
+
+	if store := c.getStore(); store != nil {
+		if err := store.Delete(context.Background(), c.storeKey); err != nil {
+			logger.Warn("Failed to delete access token from store", "error", err)
+		}
+	}
+}
+
+func (c *scopesCacheEntry) getStore() TokenCacheStore {
+	if c.options == nil {
+		return nil
+	}
+	return c.options.Store
+}
+
+// scheduleRefresh arms the background refresh of the current token, if enabled.
+// Must be called with the lock held.
+func (c *scopesCacheEntry) scheduleRefresh() {
+	if c.options == nil || !c.options.BackgroundRefresh || c.closed {
+		return
+	}
+
+	if c.refreshTimer != nil {
+		c.refreshTimer.Stop()
+	}
+
+	delay := c.accessToken.ExpiresOn.Sub(timeNow()) - c.options.RefreshLeadTime
+	if delay <= 0 {
+		// Token is too short-lived to be renewed ahead, it will be refreshed on demand
+		return
+	}
+	if c.options.RefreshJitter > 0 {
+		delay -= time.Duration(rand.Int63n(int64(c.options.RefreshJitter)))
+		if delay < 0 {
+			delay = 0
+		}
+	}
+
+	c.refreshTimer = time.AfterFunc(delay, c.backgroundRefresh)
+}
+
+func (c *scopesCacheEntry) backgroundRefresh() {
+	c.mutex.Lock()
+	if c.refreshDone != nil || c.closed {
+		// Refresh is already in progress by a caller
+		c.mutex.Unlock()
+		return
+	}
+	c.refreshDone = make(chan struct{})
+	c.mutex.Unlock()
+
+	// Errors are ignored, the token will be refreshed by the next caller once expired
+	_, _ = c.refreshAccessToken(context.Background(), true)
+}
+
 func getKeyForScopes(scopes []string) string {
 	if len(scopes) > 1 {
 		arr := make([]string, len(scopes))
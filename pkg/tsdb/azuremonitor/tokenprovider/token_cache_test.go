@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -291,7 +292,6 @@ func TestScopesCacheEntry_GetAccessToken(t *testing.T) {
 			cacheEntry := &scopesCacheEntry{
 				credential: credential,
 				scopes:     scopes,
-				cond:       sync.NewCond(&sync.Mutex{}),
 			}
 
 			accessToken, err := cacheEntry.getAccessToken(ctx)
@@ -306,7 +306,6 @@ func TestScopesCacheEntry_GetAccessToken(t *testing.T) {
 			cacheEntry := &scopesCacheEntry{
 				credential: credential,
 				scopes:     scopes,
-				cond:       sync.NewCond(&sync.Mutex{}),
 			}
 
 			var err error
@@ -341,7 +340,6 @@ func TestScopesCacheEntry_GetAccessToken(t *testing.T) {
 			cacheEntry := &scopesCacheEntry{
 				credential: credential,
 				scopes:     scopes,
-				cond:       sync.NewCond(&sync.Mutex{}),
 			}
 
 			var accessToken string
@@ -375,7 +373,6 @@ func TestScopesCacheEntry_GetAccessToken(t *testing.T) {
 			cacheEntry := &scopesCacheEntry{
 				credential: credential,
 				scopes:     scopes,
-				cond:       sync.NewCond(&sync.Mutex{}),
 			}
 
 			func() {
@@ -420,7 +417,6 @@ func TestScopesCacheEntry_GetAccessToken(t *testing.T) {
 			cacheEntry := &scopesCacheEntry{
 				credential: credential,
 				scopes:     scopes,
-				cond:       sync.NewCond(&sync.Mutex{}),
 			}
 
 			var accessToken string
@@ -455,3 +451,513 @@ func TestScopesCacheEntry_GetAccessToken(t *testing.T) {
 		})
 	})
 }
+
+func TestScopesCacheEntry_BackgroundRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should renew token before expiry when enabled", func(t *testing.T) {
+		var times int32 = 0
+		credential := &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				n := atomic.AddInt32(&times, 1)
+				fakeAccessToken := &AccessToken{Token: fmt.Sprintf("token-%v", n), ExpiresOn: timeNow().Add(time.Hour)}
+				return fakeAccessToken, nil
+			},
+		}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options: &TokenCacheOptions{
+				BackgroundRefresh: true,
+				RefreshLeadTime:   time.Hour - 50*time.Millisecond,
+			},
+		}
+		defer func() {
+			cacheEntry.mutex.Lock()
+			cacheEntry.options.BackgroundRefresh = false
+			cacheEntry.refreshTimer.Stop()
+			cacheEntry.mutex.Unlock()
+		}()
+
+		accessToken, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", accessToken)
+
+		assert.Eventually(t, func() bool {
+			accessToken, err := cacheEntry.getAccessToken(ctx)
+			return err == nil && accessToken != "token-1"
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should not schedule refresh when disabled", func(t *testing.T) {
+		credential := &fakeCredential{}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{},
+		}
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, cacheEntry.refreshTimer)
+	})
+}
+
+func TestConcurrentTokenCache_Eviction(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should request new token after purge", func(t *testing.T) {
+		cache := NewConcurrentTokenCache()
+		credential := &fakeCredential{key: "credential-1"}
+
+		token, err := cache.GetAccessToken(ctx, credential, scopes)
+		require.NoError(t, err)
+		assert.Equal(t, "credential-1-token-1", token)
+
+		cache.Purge("credential-1")
+
+		token, err = cache.GetAccessToken(ctx, credential, scopes)
+		require.NoError(t, err)
+		assert.Equal(t, "credential-1-token-2", token)
+
+		assert.Equal(t, 2, credential.initCalledTimes)
+	})
+
+	t.Run("should evict entries unused longer than TTL", func(t *testing.T) {
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		t.Cleanup(func() { timeNow = time.Now })
+
+		cache := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{EntryTTL: time.Hour})
+		credential1 := &fakeCredential{key: "credential-1"}
+		credential2 := &fakeCredential{key: "credential-2"}
+
+		_, err := cache.GetAccessToken(ctx, credential1, scopes)
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Hour)
+		_, err = cache.GetAccessToken(ctx, credential2, scopes)
+		require.NoError(t, err)
+
+		_, ok := cache.(*tokenCacheImpl).cache.Load("credential-1")
+		assert.False(t, ok)
+		_, ok = cache.(*tokenCacheImpl).cache.Load("credential-2")
+		assert.True(t, ok)
+	})
+
+	t.Run("should evict least recently used entries above the limit", func(t *testing.T) {
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		t.Cleanup(func() { timeNow = time.Now })
+
+		cache := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{MaxEntries: 2})
+		credential1 := &fakeCredential{key: "credential-1"}
+		credential2 := &fakeCredential{key: "credential-2"}
+		credential3 := &fakeCredential{key: "credential-3"}
+
+		for _, credential := range []*fakeCredential{credential1, credential2, credential1, credential3} {
+			now = now.Add(time.Second)
+			_, err := cache.GetAccessToken(ctx, credential, scopes)
+			require.NoError(t, err)
+		}
+
+		_, ok := cache.(*tokenCacheImpl).cache.Load("credential-2")
+		assert.False(t, ok)
+		_, ok = cache.(*tokenCacheImpl).cache.Load("credential-1")
+		assert.True(t, ok)
+		_, ok = cache.(*tokenCacheImpl).cache.Load("credential-3")
+		assert.True(t, ok)
+	})
+}
+
+type fakeTransientError struct{}
+
+func (e *fakeTransientError) Error() string   { return "service unavailable" }
+func (e *fakeTransientError) Temporary() bool { return true }
+
+func TestScopesCacheEntry_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should retry transient errors", func(t *testing.T) {
+		var times = 0
+		credential := &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				times = times + 1
+				if times < 3 {
+					return nil, &fakeTransientError{}
+				}
+				fakeAccessToken := &AccessToken{Token: fmt.Sprintf("token-%v", times), ExpiresOn: timeNow().Add(time.Hour)}
+				return fakeAccessToken, nil
+			},
+		}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{MaxRetries: 3, RetryBackoff: time.Millisecond},
+		}
+
+		accessToken, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-3", accessToken)
+		assert.Equal(t, 3, credential.calledTimes)
+	})
+
+	t.Run("should not retry permanent errors", func(t *testing.T) {
+		credential := &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				return nil, errors.New("invalid client secret")
+			},
+		}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{MaxRetries: 3, RetryBackoff: time.Millisecond},
+		}
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 1, credential.calledTimes)
+	})
+
+	t.Run("should return cached failure within negative cache TTL", func(t *testing.T) {
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		t.Cleanup(func() { timeNow = time.Now })
+
+		credential := &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				return nil, errors.New("unable to get access token")
+			},
+		}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{NegativeCacheTTL: time.Minute},
+		}
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.Error(t, err)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 1, credential.calledTimes)
+
+		now = now.Add(2 * time.Minute)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 2, credential.calledTimes)
+	})
+
+	t.Run("should not cache failure of cancelled caller", func(t *testing.T) {
+		credential := &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return &AccessToken{Token: "token-1", ExpiresOn: timeNow().Add(time.Hour)}, nil
+			},
+		}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{NegativeCacheTTL: time.Minute},
+		}
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := cacheEntry.getAccessToken(cancelledCtx)
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		accessToken, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", accessToken)
+	})
+}
+
+func TestScopesCacheEntry_ExpiryMargin(t *testing.T) {
+	ctx := context.Background()
+	scopes := []string{"Scope1"}
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	newCredential := func(lifetime time.Duration) *fakeCredential {
+		return &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				return &AccessToken{Token: "token", ExpiresOn: timeNow().Add(lifetime)}, nil
+			},
+		}
+	}
+
+	t.Run("should renew token within the expiry margin", func(t *testing.T) {
+		credential := newCredential(time.Hour)
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{ExpiryMargin: 10 * time.Minute},
+		}
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+
+		now = now.Add(49 * time.Minute)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, credential.calledTimes)
+
+		now = now.Add(2 * time.Minute)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, credential.calledTimes)
+	})
+
+	t.Run("should add clock skew to the expiry margin", func(t *testing.T) {
+		credential := newCredential(time.Hour)
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{ExpiryMargin: 10 * time.Minute, ClockSkew: 5 * time.Minute},
+		}
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+
+		now = now.Add(46 * time.Minute)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, credential.calledTimes)
+	})
+
+	t.Run("should limit the margin of short-lived tokens", func(t *testing.T) {
+		credential := newCredential(4 * time.Minute)
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+			options:    &TokenCacheOptions{ExpiryMargin: 10 * time.Minute},
+		}
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, credential.calledTimes)
+
+		now = now.Add(2 * time.Minute)
+		_, err = cacheEntry.getAccessToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, credential.calledTimes)
+	})
+}
+
+type fakeTokenCacheStore struct {
+	mu     sync.Mutex
+	tokens map[string]*AccessToken
+}
+
+func (s *fakeTokenCacheStore) Get(_ context.Context, key string) (*AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[key], nil
+}
+
+func (s *fakeTokenCacheStore) Set(_ context.Context, key string, token *AccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = token
+	return nil
+}
+
+func (s *fakeTokenCacheStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+func TestConcurrentTokenCache_Store(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should share tokens between caches through store", func(t *testing.T) {
+		store := &fakeTokenCacheStore{tokens: map[string]*AccessToken{}}
+		cache1 := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{Store: store})
+		cache2 := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{Store: store})
+		credential1 := &fakeCredential{key: "credential-1"}
+		credential2 := &fakeCredential{key: "credential-1"}
+
+		token1, err := cache1.GetAccessToken(ctx, credential1, scopes)
+		require.NoError(t, err)
+
+		token2, err := cache2.GetAccessToken(ctx, credential2, scopes)
+		require.NoError(t, err)
+
+		assert.Equal(t, token1, token2)
+		assert.Equal(t, 1, credential1.calledTimes)
+		assert.Equal(t, 0, credential2.calledTimes)
+	})
+
+	t.Run("should delete tokens from store on purge", func(t *testing.T) {
+		store := &fakeTokenCacheStore{tokens: map[string]*AccessToken{}}
+		cache := NewConcurrentTokenCacheWithOptions(TokenCacheOptions{Store: store})
+		credential := &fakeCredential{key: "credential-1"}
+
+		_, err := cache.GetAccessToken(ctx, credential, scopes)
+		require.NoError(t, err)
+		assert.Len(t, store.tokens, 1)
+
+		cache.Purge("credential-1")
+		assert.Len(t, store.tokens, 0)
+	})
+}
+
+func TestScopesCacheEntry_WaitCancellation(t *testing.T) {
+	scopes := []string{"Scope1"}
+
+	t.Run("should return error when context is cancelled while waiting for refresh", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		credential := &fakeCredential{
+			getAccessTokenFunc: func(ctx context.Context, scopes []string) (*AccessToken, error) {
+				close(started)
+				<-release
+				return &AccessToken{Token: "token-1", ExpiresOn: timeNow().Add(time.Hour)}, nil
+			},
+		}
+
+		cacheEntry := &scopesCacheEntry{
+			credential: credential,
+			scopes:     scopes,
+		}
+
+		refreshed := make(chan string)
+		go func() {
+			accessToken, _ := cacheEntry.getAccessToken(context.Background())
+			refreshed <- accessToken
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := cacheEntry.getAccessToken(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		close(release)
+		assert.Equal(t, "token-1", <-refreshed)
+	})
+}
+
+type fakeIdentityCredential struct {
+	fakeCredential
+	identityKey string
+}
+
+func (c *fakeIdentityCredential) GetIdentityKey() string {
+	return c.identityKey
+}
+
+func TestConcurrentTokenCache_CoalesceIdentityRequests(t *testing.T) {
+	ctx := context.Background()
+
+	scopes := []string{"Scope1"}
+
+	t.Run("should request token once for credentials of the same identity", func(t *testing.T) {
+		var calls int32 = 0
+		started := make(chan struct{})
+		release := make(chan struct{})
+		getAccessToken := func(ctx context.Context, scopes []string) (*AccessToken, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return &AccessToken{Token: "token-1", ExpiresOn: timeNow().Add(time.Hour)}, nil
+		}
+
+		cache := NewConcurrentTokenCache()
+		credential1 := &fakeIdentityCredential{fakeCredential: fakeCredential{key: "credential-1", getAccessTokenFunc: getAccessToken}, identityKey: "tenant|client"}
+		credential2 := &fakeIdentityCredential{fakeCredential: fakeCredential{key: "credential-2", getAccessTokenFunc: getAccessToken}, identityKey: "tenant|client"}
+
+		var wg sync.WaitGroup
+		tokens := make([]string, 2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[0], _ = cache.GetAccessToken(ctx, credential1, scopes)
+		}()
+		<-started
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[1], _ = cache.GetAccessToken(ctx, credential2, scopes)
+		}()
+
+		// Give the second request time to join the in-flight one
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []string{"token-1", "token-1"}, tokens)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should not fail other callers when first caller is cancelled", func(t *testing.T) {
+		var calls int32 = 0
+		started := make(chan struct{})
+		release := make(chan struct{})
+		getAccessToken := func(ctx context.Context, scopes []string) (*AccessToken, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+			}
+			return &AccessToken{Token: "token-1", ExpiresOn: timeNow().Add(time.Hour)}, nil
+		}
+
+		cache := NewConcurrentTokenCache()
+		credential1 := &fakeIdentityCredential{fakeCredential: fakeCredential{key: "credential-1", getAccessTokenFunc: getAccessToken}, identityKey: "tenant|client|cancelled"}
+		credential2 := &fakeIdentityCredential{fakeCredential: fakeCredential{key: "credential-2", getAccessTokenFunc: getAccessToken}, identityKey: "tenant|client|cancelled"}
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := cache.GetAccessToken(cancelledCtx, credential1, scopes)
+			errs <- err
+		}()
+		<-started
+
+		token := make(chan string, 1)
+		go func() {
+			t, _ := cache.GetAccessToken(ctx, credential2, scopes)
+			token <- t
+		}()
+
+		// Give the second request time to join the in-flight one
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		assert.True(t, errors.Is(<-errs, context.Canceled))
+
+		close(release)
+		assert.Equal(t, "token-1", <-token)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
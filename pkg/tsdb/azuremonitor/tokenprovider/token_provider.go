@@ -3,8 +3,12 @@ package tokenprovider
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
@@ -13,9 +17,32 @@ import (
 )
 
 var (
-	azureTokenCache = NewConcurrentTokenCache()
+	azureTokenCacheOptions = TokenCacheOptions{
+		EntryTTL:         time.Hour,
+		NegativeCacheTTL: 5 * time.Second,
+	}
+	azureTokenCache = NewConcurrentTokenCacheWithOptions(azureTokenCacheOptions)
 )
 
+// ConfigureTokenCache applies Grafana Azure settings to the token cache, tokens are shared
+// through the given store if not nil. Must be called during initialization, before any
+// tokens are requested.
+func ConfigureTokenCache(cfg *setting.Cfg, store TokenCacheStore) {
+	options := azureTokenCacheOptions
+	options.Store = store
+	options.AuditLog = cfg.Azure.TokenAuditLogEnabled
+	if cfg.Azure.TokenExpiryMargin > 0 {
+		options.ExpiryMargin = cfg.Azure.TokenExpiryMargin
+	}
+	options.ClockSkew = cfg.Azure.TokenClockSkew
+	options.MaxRetries = cfg.Azure.TokenMaxRetries
+	options.RetryBackoff = cfg.Azure.TokenRetryBackoff
+	options.BackgroundRefresh = cfg.Azure.TokenBackgroundRefreshEnabled
+	options.RefreshLeadTime = cfg.Azure.TokenRefreshLeadTime
+	options.RefreshJitter = cfg.Azure.TokenRefreshJitter
+	azureTokenCache = NewConcurrentTokenCacheWithOptions(options)
+}
+
 type azureAccessTokenProvider struct {
 	ctx        context.Context
 	cfg        *setting.Cfg
@@ -32,25 +59,101 @@ func NewAzureAccessTokenProvider(ctx context.Context, cfg *setting.Cfg,
 }
 
 func (provider *azureAccessTokenProvider) GetAccessToken() (string, error) {
+	return provider.GetAccessTokenWithContext(provider.ctx)
+}
+
+// GetAccessTokenWithContext returns the access token on behalf of the request with the given context.
+func (provider *azureAccessTokenProvider) GetAccessTokenWithContext(ctx context.Context) (string, error) {
+	credential, err := provider.getCredential(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	accessToken, err := azureTokenCache.GetAccessToken(ctx, credential, provider.authParams.Scopes)
+	if err != nil {
+		return "", err
+	}
+
+	return accessToken, nil
+}
+
+// WarmupAccessToken acquires the access token ahead of the first request, so that invalid
+// credentials are reported when the datasource is saved.
+func (provider *azureAccessTokenProvider) WarmupAccessToken() error {
+	if provider.isOnBehalfOfCredential() {
+		// Tokens are acquired per user on their requests
+		return nil
+	}
+
+	credential, err := provider.getCredential(provider.ctx)
+	if err != nil {
+		return err
+	}
+
+	return azureTokenCache.Warmup(provider.ctx, credential, provider.authParams.Scopes)
+}
+
+func (provider *azureAccessTokenProvider) getCredential(ctx context.Context) (TokenCredential, error) {
 	var credential TokenCredential
 
 	if provider.isManagedIdentityCredential() {
 		if !provider.cfg.Azure.ManagedIdentityEnabled {
 			err := fmt.Errorf("managed identity authentication is not enabled in Grafana config")
-			return "", err
+			return nil, err
 		} else {
 			credential = provider.getManagedIdentityCredential()
 		}
+	} else if provider.isWorkloadIdentityCredential() {
+		if !provider.cfg.Azure.WorkloadIdentityEnabled {
+			err := fmt.Errorf("workload identity authentication is not enabled in Grafana config")
+			return nil, err
+		} else {
+			credential = provider.getWorkloadIdentityCredential()
+		}
+	} else if provider.isClientCertificateCredential() {
+		credential = provider.getClientCertificateCredential()
+	} else if provider.isChainedCredential() {
+		if credential = provider.getChainedCredential(); credential == nil {
+			err := fmt.Errorf("no credentials configured for chained authentication")
+			return nil, err
+		}
+	} else if provider.isOnBehalfOfCredential() {
+		identity, ok := getUserIdentity(ctx)
+		if !ok {
+			err := fmt.Errorf("on-behalf-of authentication requires identity of the signed-in user")
+			return nil, err
+		}
+		credential = provider.getOnBehalfOfCredential(identity.ID)
 	} else {
 		credential = provider.getClientSecretCredential()
 	}
 
-	accessToken, err := azureTokenCache.GetAccessToken(provider.ctx, credential, provider.authParams.Scopes)
-	if err != nil {
-		return "", err
+	return credential, nil
+}
+
+// PurgeAccessTokens removes cached tokens of the credential configured for the provider,
+// it should be called when the datasource credentials are changed or the datasource deleted.
+func (provider *azureAccessTokenProvider) PurgeAccessTokens() {
+	if provider.isManagedIdentityCredential() || provider.isWorkloadIdentityCredential() {
+		// Managed and workload identities are shared by all datasources, tokens are retained
+		return
 	}
 
-	return accessToken, nil
+	if provider.isOnBehalfOfCredential() {
+		// Tokens of users are keyed by the client secret, so they aren't reused once the secret
+		// is changed and get evicted when unused
+		return
+	}
+
+	if provider.isClientCertificateCredential() {
+		azureTokenCache.Purge(provider.getClientCertificateCredential().GetCacheKey())
+	} else if provider.isChainedCredential() {
+		if credential := provider.getChainedCredential(); credential != nil {
+			azureTokenCache.Purge(credential.GetCacheKey())
+		}
+	} else {
+		azureTokenCache.Purge(provider.getClientSecretCredential().GetCacheKey())
+	}
 }
 
 func (provider *azureAccessTokenProvider) isManagedIdentityCredential() bool {
@@ -66,6 +169,25 @@ func (provider *azureAccessTokenProvider) isManagedIdentityCredential() bool {
 	return authType == "msi" || (authType == "" && clientId == "" && provider.cfg.Azure.ManagedIdentityEnabled)
 }
 
+func (provider *azureAccessTokenProvider) isWorkloadIdentityCredential() bool {
+	authType := strings.ToLower(provider.authParams.Params["azure_auth_type"])
+	return authType == "workloadidentity"
+}
+
+func (provider *azureAccessTokenProvider) getWorkloadIdentityCredential() TokenCredential {
+	authority := provider.resolveAuthorityHost(provider.authParams.Params["azure_cloud"])
+	if authorityHost := os.Getenv("AZURE_AUTHORITY_HOST"); authorityHost != "" {
+		authority = authorityHost
+	}
+
+	return &workloadIdentityCredential{
+		authority: authority,
+		tenantId:  provider.cfg.Azure.WorkloadIdentityTenantId,
+		clientId:  provider.cfg.Azure.WorkloadIdentityClientId,
+		tokenFile: provider.cfg.Azure.WorkloadIdentityTokenFile,
+	}
+}
+
 func (provider *azureAccessTokenProvider) getManagedIdentityCredential() TokenCredential {
 	clientId := provider.cfg.Azure.ManagedIdentityClientId
 
@@ -81,7 +203,72 @@ func (provider *azureAccessTokenProvider) getClientSecretCredential() TokenCrede
 	return &clientSecretCredential{authority: authority, tenantId: tenantId, clientId: clientId, clientSecret: clientSecret}
 }
 
+func (provider *azureAccessTokenProvider) isClientCertificateCredential() bool {
+	authType := strings.ToLower(provider.authParams.Params["azure_auth_type"])
+	return authType == "clientcertificate"
+}
+
+func (provider *azureAccessTokenProvider) getClientCertificateCredential() TokenCredential {
+	authority := provider.resolveAuthorityHost(provider.authParams.Params["azure_cloud"])
+	tenantId := provider.authParams.Params["tenant_id"]
+	clientId := provider.authParams.Params["client_id"]
+	certificate := provider.authParams.Params["client_certificate"]
+	certificateFormat := strings.ToLower(provider.authParams.Params["client_certificate_format"])
+	if certificateFormat == "" {
+		certificateFormat = "pem"
+	}
+	password := provider.authParams.Params["client_certificate_password"]
+
+	return &clientCertificateCredential{authority: authority, tenantId: tenantId, clientId: clientId,
+		certificate: certificate, certificateFormat: certificateFormat, password: password}
+}
+
+func (provider *azureAccessTokenProvider) isChainedCredential() bool {
+	authType := strings.ToLower(provider.authParams.Params["azure_auth_type"])
+	return authType == "chained"
+}
+
+// getChainedCredential returns credential which tries, in order, the configured client secret,
+// the managed identity if enabled and the Azure CLI when Grafana runs in development mode.
+func (provider *azureAccessTokenProvider) getChainedCredential() TokenCredential {
+	var credentials []TokenCredential
+
+	if provider.authParams.Params["client_id"] != "" && provider.authParams.Params["client_secret"] != "" {
+		credentials = append(credentials, provider.getClientSecretCredential())
+	}
+	if provider.cfg.Azure.ManagedIdentityEnabled {
+		credentials = append(credentials, provider.getManagedIdentityCredential())
+	}
+	if provider.cfg.Env == setting.Dev {
+		credentials = append(credentials, &azureCliCredential{})
+	}
+
+	if len(credentials) == 0 {
+		return nil
+	}
+	return &chainedTokenCredential{credentials: credentials}
+}
+
+func (provider *azureAccessTokenProvider) isOnBehalfOfCredential() bool {
+	authType := strings.ToLower(provider.authParams.Params["azure_auth_type"])
+	return authType == "onbehalfof"
+}
+
+func (provider *azureAccessTokenProvider) getOnBehalfOfCredential(userId string) TokenCredential {
+	authority := provider.resolveAuthorityHost(provider.authParams.Params["azure_cloud"])
+	tenantId := provider.authParams.Params["tenant_id"]
+	clientId := provider.authParams.Params["client_id"]
+	clientSecret := provider.authParams.Params["client_secret"]
+
+	return &onBehalfOfCredential{authority: authority, tenantId: tenantId, clientId: clientId, clientSecret: clientSecret, userId: userId}
+}
+
 func (provider *azureAccessTokenProvider) resolveAuthorityHost(cloudName string) string {
+	// Authority explicitly configured for the cloud of the datasource
+	if authorityHost := provider.authParams.Params["azure_authority_host"]; authorityHost != "" {
+		return authorityHost
+	}
+
 	// Known Azure clouds
 	switch cloudName {
 	case setting.AzurePublic:
@@ -140,6 +327,10 @@ func (c *clientSecretCredential) GetCacheKey() string {
 	return fmt.Sprintf("azure|clientsecret|%s|%s|%s|%s", c.authority, c.tenantId, c.clientId, hashSecret(c.clientSecret))
 }
 
+func (c *clientSecretCredential) GetIdentityKey() string {
+	return fmt.Sprintf("%s|%s|%s|%s", c.authority, c.tenantId, c.clientId, hashSecret(c.clientSecret))
+}
+
 func (c *clientSecretCredential) Init() error {
 	options := &azidentity.ClientSecretCredentialOptions{AuthorityHost: c.authority}
 	if credential, err := azidentity.NewClientSecretCredential(c.tenantId, c.clientId, c.clientSecret, options); err != nil {
@@ -159,6 +350,80 @@ func (c *clientSecretCredential) GetAccessToken(ctx context.Context, scopes []st
 	return &AccessToken{Token: accessToken.Token, ExpiresOn: accessToken.ExpiresOn}, nil
 }
 
+type clientCertificateCredential struct {
+	authority         string
+	tenantId          string
+	clientId          string
+	certificate       string
+	certificateFormat string
+	password          string
+	credential        azcore.TokenCredential
+}
+
+func (c *clientCertificateCredential) GetCacheKey() string {
+	return fmt.Sprintf("azure|clientcertificate|%s|%s|%s|%s", c.authority, c.tenantId, c.clientId, hashSecret(c.certificate))
+}
+
+func (c *clientCertificateCredential) GetIdentityKey() string {
+	return fmt.Sprintf("%s|%s|%s|%s", c.authority, c.tenantId, c.clientId, hashSecret(c.certificate+"\n"+c.password))
+}
+
+func (c *clientCertificateCredential) Init() error {
+	certData, err := decodeCertificate(c.certificate, c.certificateFormat)
+	if err != nil {
+		return err
+	}
+
+	// Azure SDK only loads certificates from files, the file is removed as soon as the certificate is parsed
+	certFile, err := ioutil.TempFile("", "azure-cert-*."+c.certificateFormat)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(certFile.Name()); err != nil {
+			logger.Warn("Failed to remove certificate file", "error", err)
+		}
+	}()
+
+	if _, err := certFile.Write(certData); err != nil {
+		_ = certFile.Close()
+		return err
+	}
+	if err := certFile.Close(); err != nil {
+		return err
+	}
+
+	options := &azidentity.ClientCertificateCredentialOptions{AuthorityHost: c.authority, Password: c.password}
+	if credential, err := azidentity.NewClientCertificateCredential(c.tenantId, c.clientId, certFile.Name(), options); err != nil {
+		return err
+	} else {
+		c.credential = credential
+		return nil
+	}
+}
+
+func (c *clientCertificateCredential) GetAccessToken(ctx context.Context, scopes []string) (*AccessToken, error) {
+	accessToken, err := c.credential.GetToken(ctx, azcore.TokenRequestOptions{Scopes: scopes})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessToken{Token: accessToken.Token, ExpiresOn: accessToken.ExpiresOn}, nil
+}
+
+// decodeCertificate returns the certificate file content, PEM certificates are stored as is
+// while PFX certificates are stored base64 encoded.
+func decodeCertificate(certificate string, format string) ([]byte, error) {
+	switch format {
+	case "pem":
+		return []byte(certificate), nil
+	case "pfx":
+		return base64.StdEncoding.DecodeString(certificate)
+	default:
+		return nil, fmt.Errorf("unsupported certificate format '%s', only pem and pfx are supported", format)
+	}
+}
+
 func hashSecret(secret string) string {
 	hash := sha256.New()
 	_, _ = hash.Write([]byte(secret))
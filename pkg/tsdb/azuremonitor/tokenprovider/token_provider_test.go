@@ -2,7 +2,14 @@ package tokenprovider
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
@@ -19,6 +26,13 @@ func (c *tokenCacheFake) GetAccessToken(ctx context.Context, credential TokenCre
 	return "4cb83b87-0ffb-4abd-82f6-48a8c08afc53", nil
 }
 
+func (c *tokenCacheFake) Warmup(ctx context.Context, credential TokenCredential, scopes []string) error {
+	getAccessTokenFunc(credential, scopes)
+	return nil
+}
+
+func (c *tokenCacheFake) Purge(cacheKey string) {}
+
 func TestAzureTokenProvider_isManagedIdentityCredential(t *testing.T) {
 	ctx := context.Background()
 
@@ -159,6 +173,104 @@ func TestAzureTokenProvider_getAccessToken(t *testing.T) {
 		})
 	})
 
+	t.Run("when workload identity enabled", func(t *testing.T) {
+		cfg.Azure.WorkloadIdentityEnabled = true
+
+		t.Run("should resolve workload identity credential if auth type is workload identity", func(t *testing.T) {
+			authParams.Params = map[string]string{
+				"azure_auth_type": "workloadidentity",
+			}
+
+			getAccessTokenFunc = func(credential TokenCredential, scopes []string) {
+				assert.IsType(t, &workloadIdentityCredential{}, credential)
+			}
+
+			_, err := provider.GetAccessToken()
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("when workload identity disabled", func(t *testing.T) {
+		cfg.Azure.WorkloadIdentityEnabled = false
+
+		t.Run("should return error if auth type is workload identity", func(t *testing.T) {
+			authParams.Params = map[string]string{
+				"azure_auth_type": "workloadidentity",
+			}
+
+			getAccessTokenFunc = func(credential TokenCredential, scopes []string) {
+				assert.Fail(t, "token cache not expected to be called")
+			}
+
+			_, err := provider.GetAccessToken()
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("when workload identity enabled", func(t *testing.T) {
+		cfg.Azure.WorkloadIdentityEnabled = true
+
+		t.Run("should resolve workload identity credential if auth type is workload identity", func(t *testing.T) {
+			authParams.Params = map[string]string{
+				"azure_auth_type": "workloadidentity",
+			}
+
+			getAccessTokenFunc = func(credential TokenCredential, scopes []string) {
+				assert.IsType(t, &workloadIdentityCredential{}, credential)
+			}
+
+			_, err := provider.GetAccessToken()
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("when workload identity disabled", func(t *testing.T) {
+		cfg.Azure.WorkloadIdentityEnabled = false
+
+		t.Run("should return error if auth type is workload identity", func(t *testing.T) {
+			authParams.Params = map[string]string{
+				"azure_auth_type": "workloadidentity",
+			}
+
+			getAccessTokenFunc = func(credential TokenCredential, scopes []string) {
+				assert.Fail(t, "token cache not expected to be called")
+			}
+
+			_, err := provider.GetAccessToken()
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("when auth type is on-behalf-of", func(t *testing.T) {
+		authParams.Params = map[string]string{
+			"azure_auth_type": "onbehalfof",
+			"tenant_id":       "tenant-1",
+			"client_id":       "client-1",
+			"client_secret":   "secret-1",
+		}
+
+		t.Run("should resolve on-behalf-of credential of the signed-in user", func(t *testing.T) {
+			userCtx := WithUserIdentity(ctx, UserIdentity{ID: "user-1", Assertion: "id-token"})
+
+			getAccessTokenFunc = func(credential TokenCredential, scopes []string) {
+				require.IsType(t, &onBehalfOfCredential{}, credential)
+				assert.Equal(t, "user-1", credential.(*onBehalfOfCredential).userId)
+			}
+
+			_, err := provider.GetAccessTokenWithContext(userCtx)
+			require.NoError(t, err)
+		})
+
+		t.Run("should return error without signed-in user", func(t *testing.T) {
+			getAccessTokenFunc = func(credential TokenCredential, scopes []string) {
+				assert.Fail(t, "token cache not expected to be called")
+			}
+
+			_, err := provider.GetAccessToken()
+			require.Error(t, err)
+		})
+	})
+
 	t.Run("when managed identities disabled", func(t *testing.T) {
 		cfg.Azure.ManagedIdentityEnabled = false
 
@@ -208,4 +320,102 @@ func TestAzureTokenProvider_getClientSecretCredential(t *testing.T) {
 		assert.Equal(t, "1af7c188-e5b6-4f96-81b8-911761bdd459", credential.clientId)
 		assert.Equal(t, "0416d95e-8af8-472c-aaa3-15c93c46080a", credential.clientSecret)
 	})
+
+	t.Run("should use authority host configured for the cloud", func(t *testing.T) {
+		authParams.Params["azure_authority_host"] = "https://login.azurestack.local/"
+		defer delete(authParams.Params, "azure_authority_host")
+
+		credential := provider.getClientSecretCredential().(*clientSecretCredential)
+
+		assert.Equal(t, "https://login.azurestack.local/", credential.authority)
+	})
+
+	t.Run("should not share identity key with credential of another secret", func(t *testing.T) {
+		credential := provider.getClientSecretCredential().(*clientSecretCredential)
+		otherCredential := *credential
+		otherCredential.clientSecret = "stale-secret"
+
+		assert.NotEqual(t, credential.GetIdentityKey(), otherCredential.GetIdentityKey())
+	})
+}
+
+func TestConfigureTokenCache(t *testing.T) {
+	defaultCache := azureTokenCache
+	t.Cleanup(func() { azureTokenCache = defaultCache })
+
+	t.Run("should apply background refresh settings", func(t *testing.T) {
+		cfg := &setting.Cfg{}
+		cfg.Azure.TokenBackgroundRefreshEnabled = true
+		cfg.Azure.TokenRefreshLeadTime = 10 * time.Minute
+		cfg.Azure.TokenRefreshJitter = time.Minute
+
+		ConfigureTokenCache(cfg, nil)
+
+		options := azureTokenCache.(*tokenCacheImpl).options
+		assert.True(t, options.BackgroundRefresh)
+		assert.Equal(t, 10*time.Minute, options.RefreshLeadTime)
+		assert.Equal(t, time.Minute, options.RefreshJitter)
+	})
+
+	t.Run("should apply retry settings", func(t *testing.T) {
+		cfg := &setting.Cfg{}
+		cfg.Azure.TokenMaxRetries = 5
+		cfg.Azure.TokenRetryBackoff = time.Second
+
+		ConfigureTokenCache(cfg, nil)
+
+		options := azureTokenCache.(*tokenCacheImpl).options
+		assert.Equal(t, 5, options.MaxRetries)
+		assert.Equal(t, time.Second, options.RetryBackoff)
+	})
+}
+
+func TestClientCertificateCredential_Init(t *testing.T) {
+	t.Run("should init credential from PEM certificate", func(t *testing.T) {
+		credential := &clientCertificateCredential{
+			authority:         "https://login.microsoftonline.com/",
+			tenantId:          "7dcf1d1a-4ec0-41f2-ac29-c1538a698bc4",
+			clientId:          "1af7c188-e5b6-4f96-81b8-911761bdd459",
+			certificate:       generateTestCertificate(t),
+			certificateFormat: "pem",
+		}
+
+		err := credential.Init()
+		require.NoError(t, err)
+		assert.NotNil(t, credential.credential)
+	})
+
+	t.Run("should fail for unsupported certificate format", func(t *testing.T) {
+		credential := &clientCertificateCredential{
+			tenantId:          "7dcf1d1a-4ec0-41f2-ac29-c1538a698bc4",
+			clientId:          "1af7c188-e5b6-4f96-81b8-911761bdd459",
+			certificate:       generateTestCertificate(t),
+			certificateFormat: "der",
+		}
+
+		err := credential.Init()
+		assert.Error(t, err)
+	})
+}
+
+func generateTestCertificate(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grafana"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+	return string(certPem) + string(keyPem)
 }
@@ -0,0 +1,93 @@
+package tokenprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/securedata"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+// TokenCacheStore persists access tokens outside of the process, so that tokens
+// can be shared between Grafana instances. When no store is configured the tokens
+// are kept only in memory of the token cache.
+type TokenCacheStore interface {
+	// Get returns the stored token or nil if there is no token for the key.
+	Get(ctx context.Context, key string) (*AccessToken, error)
+	Set(ctx context.Context, key string, token *AccessToken) error
+	Delete(ctx context.Context, key string) error
+}
+
+type remoteTokenCacheStore struct {
+	cache remotecache.CacheStorage
+}
+
+// NewRemoteTokenCacheStore creates a store backed by the Grafana remote cache (database, Redis or
+// memcached). Tokens are encrypted with the Grafana secret key before being written to the cache.
+func NewRemoteTokenCacheStore(cache remotecache.CacheStorage) TokenCacheStore {
+	return &remoteTokenCacheStore{cache: cache}
+}
+
+func (s *remoteTokenCacheStore) Get(_ context.Context, key string) (*AccessToken, error) {
+	value, err := s.cache.Get(key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	encrypted, ok := value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type of cached access token: %T", value)
+	}
+
+	decrypted, err := securedata.SecureData(encrypted).Decrypt()
+	if err != nil {
+		return nil, err
+	}
+
+	var token AccessToken
+	if err := json.Unmarshal(decrypted, &token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (s *remoteTokenCacheStore) Set(_ context.Context, key string, token *AccessToken) error {
+	expire := token.ExpiresOn.Sub(timeNow())
+	if expire <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := securedata.Encrypt(data)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Set(key, []byte(encrypted), expire)
+}
+
+func (s *remoteTokenCacheStore) Delete(_ context.Context, key string) error {
+	err := s.cache.Delete(key)
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil
+	}
+	return err
+}
+
+// getStoreKey returns the key of the token in the store, credential keys are hashed
+// to keep the key length bounded and to not expose the credential details.
+func getStoreKey(credentialKey string, scopesKey string) string {
+	hash := sha256.Sum256([]byte(credentialKey + "\n" + scopesKey))
+	return fmt.Sprintf("azure-token-%x", hash)
+}
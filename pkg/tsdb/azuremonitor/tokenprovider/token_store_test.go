@@ -0,0 +1,46 @@
+package tokenprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteTokenCacheStore(t *testing.T) {
+	ctx := context.Background()
+
+	store := NewRemoteTokenCacheStore(remotecache.NewFakeStore(t))
+	key := getStoreKey("credential-1", "Scope1")
+
+	t.Run("should return nil when token not stored", func(t *testing.T) {
+		token, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Nil(t, token)
+	})
+
+	t.Run("should return stored token", func(t *testing.T) {
+		expected := &AccessToken{Token: "token-1", ExpiresOn: time.Now().Add(time.Hour).Round(time.Second).UTC()}
+
+		err := store.Set(ctx, key, expected)
+		require.NoError(t, err)
+
+		token, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, token)
+		assert.Equal(t, expected.Token, token.Token)
+		assert.True(t, expected.ExpiresOn.Equal(token.ExpiresOn))
+	})
+
+	t.Run("should not return deleted token", func(t *testing.T) {
+		err := store.Delete(ctx, key)
+		require.NoError(t, err)
+
+		token, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Nil(t, token)
+	})
+}
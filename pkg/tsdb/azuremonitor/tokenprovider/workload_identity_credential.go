@@ -0,0 +1,130 @@
+package tokenprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// workloadIdentityCredential exchanges a federated token (e.g. Kubernetes projected service
+// account token) for an Azure AD access token using client credentials flow with client assertion.
+type workloadIdentityCredential struct {
+	authority string
+	tenantId  string
+	clientId  string
+	tokenFile string
+
+	httpClient *http.Client
+}
+
+func (c *workloadIdentityCredential) GetCacheKey() string {
+	return fmt.Sprintf("azure|wi|%s|%s|%s", c.authority, c.tenantId, c.clientId)
+}
+
+func (c *workloadIdentityCredential) GetIdentityKey() string {
+	return fmt.Sprintf("%s|%s|%s", c.authority, c.tenantId, c.clientId)
+}
+
+func (c *workloadIdentityCredential) Init() error {
+	if c.tenantId == "" || c.clientId == "" {
+		return fmt.Errorf("tenant ID and client ID must be configured for workload identity")
+	}
+
+	if _, err := os.Stat(c.tokenFile); err != nil {
+		return fmt.Errorf("federated token file not available: %w", err)
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return nil
+}
+
+func (c *workloadIdentityCredential) GetAccessToken(ctx context.Context, scopes []string) (*AccessToken, error) {
+	// The token file is read on each request since the projected token gets rotated
+	// #nosec G304
+	assertion, err := ioutil.ReadFile(c.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read federated token: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.clientId)
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_assertion_type", clientAssertionType)
+	form.Set("client_assertion", strings.TrimSpace(string(assertion)))
+	form.Set("scope", strings.Join(scopes, " "))
+
+	tokenUrl := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(c.authority, "/"), url.PathEscape(c.tenantId))
+
+	return requestAccessToken(ctx, c.httpClient, tokenUrl, form)
+}
+
+type tokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+}
+
+// tokenRequestError is returned when the token endpoint responded with an error.
+type tokenRequestError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *tokenRequestError) Error() string {
+	return fmt.Sprintf("token request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed on retry.
+func (e *tokenRequestError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+func requestAccessToken(ctx context.Context, client *http.Client, tokenUrl string, form url.Values) (*AccessToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		return nil, &tokenRequestError{StatusCode: res.StatusCode, Body: string(body)}
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	expiresIn, err := token.ExpiresIn.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid token expiration: %w", err)
+	}
+
+	return &AccessToken{Token: token.AccessToken, ExpiresOn: timeNow().Add(time.Duration(expiresIn) * time.Second)}, nil
+}
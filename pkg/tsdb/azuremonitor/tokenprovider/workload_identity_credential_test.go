@@ -0,0 +1,64 @@
+package tokenprovider
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadIdentityCredential_GetAccessToken(t *testing.T) {
+	ctx := context.Background()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	err := ioutil.WriteFile(tokenFile, []byte("federated-token\n"), 0600)
+	require.NoError(t, err)
+
+	t.Run("should exchange federated token for access token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/tenant-1/oauth2/v2.0/token", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client-1", r.PostForm.Get("client_id"))
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, clientAssertionType, r.PostForm.Get("client_assertion_type"))
+			assert.Equal(t, "federated-token", r.PostForm.Get("client_assertion"))
+			assert.Equal(t, "https://management.azure.com/.default", r.PostForm.Get("scope"))
+
+			_, _ = w.Write([]byte(`{"access_token":"access-token-1","expires_in":3599,"token_type":"Bearer"}`))
+		}))
+		defer server.Close()
+
+		credential := &workloadIdentityCredential{authority: server.URL + "/", tenantId: "tenant-1", clientId: "client-1", tokenFile: tokenFile}
+		require.NoError(t, credential.Init())
+
+		token, err := credential.GetAccessToken(ctx, []string{"https://management.azure.com/.default"})
+		require.NoError(t, err)
+		assert.Equal(t, "access-token-1", token.Token)
+		assert.True(t, token.ExpiresOn.After(time.Now().Add(59*time.Minute)))
+	})
+
+	t.Run("should return temporary error when token endpoint is unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		credential := &workloadIdentityCredential{authority: server.URL, tenantId: "tenant-1", clientId: "client-1", tokenFile: tokenFile}
+		require.NoError(t, credential.Init())
+
+		_, err := credential.GetAccessToken(ctx, []string{"https://management.azure.com/.default"})
+		require.Error(t, err)
+		assert.True(t, isTransientError(err))
+	})
+
+	t.Run("should fail init when token file doesn't exist", func(t *testing.T) {
+		credential := &workloadIdentityCredential{tenantId: "tenant-1", clientId: "client-1", tokenFile: filepath.Join(t.TempDir(), "missing")}
+		assert.Error(t, credential.Init())
+	})
+}
@@ -20,6 +20,11 @@ type AzureMonitorQuery struct {
 	RefID         string
 	Alias         string
 	TimeRange     backend.TimeRange
+
+	// Resources are IDs of the resources queried at once, URL isn't used then
+	Resources []string
+	// Region of the resources, multiple resources are queried by the batch API when set
+	Region string
 }
 
 // AzureMonitorResponse is the json response from the Azure Monitor API
@@ -57,14 +62,28 @@ type AzureMonitorResponse struct {
 	Resourceregion string `json:"resourceregion"`
 }
 
+// azureMonitorBatchResponse is the json response from the Azure Monitor metrics batch API
+type azureMonitorBatchResponse struct {
+	Values []struct {
+		AzureMonitorResponse
+		ResourceID string `json:"resourceid"`
+	} `json:"values"`
+}
+
 // AzureLogAnalyticsResponse is the json response object from the Azure Log Analytics API.
 type AzureLogAnalyticsResponse struct {
 	Tables []AzureResponseTable `json:"tables"`
+
+	// NextLink is the URL of the next page when the results are paged
+	NextLink string `json:"@odata.nextLink,omitempty"`
 }
 
 // AzureResourceGraphResponse is the json response object from the Azure Resource Graph Analytics API.
 type AzureResourceGraphResponse struct {
 	Data AzureResponseTable `json:"data"`
+
+	// SkipToken is set when there are more results to be requested
+	SkipToken string `json:"$skipToken,omitempty"`
 }
 
 // AzureResponseTable is the table format for Azure responses
@@ -94,6 +113,10 @@ type azureMonitorJSONQuery struct {
 		TimeGrain           string  `json:"timeGrain"`
 		Top                 string  `json:"top"`
 
+		// Multiple resources queried at once
+		ResourceURIs []string `json:"resourceUris"`
+		Region       string   `json:"region"`
+
 		DimensionFilters []azureMonitorDimensionFilter `json:"dimensionFilters"` // new model
 	} `json:"azureMonitor"`
 	Subscription string `json:"subscription"`
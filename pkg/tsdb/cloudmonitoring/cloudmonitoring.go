@@ -62,6 +62,7 @@ const (
 	jwtAuthentication         string = "jwt"
 	metricQueryType           string = "metrics"
 	sloQueryType              string = "slo"
+	promQLQueryType           string = "promQL"
 	mqlEditorMode             string = "mql"
 	crossSeriesReducerDefault string = "REDUCE_NONE"
 	perSeriesAlignerDefault   string = "ALIGN_MEAN"
@@ -257,6 +258,16 @@ func (e *Executor) buildQueryExecutors(tsdbQuery plugins.DataQuery) ([]cloudMoni
 			params.Add("filter", buildSLOFilterExpression(q.SloQuery))
 			setSloAggParams(&params, &q.SloQuery, durationSeconds, query.IntervalMS)
 			queryInterface = cmtsf
+		case promQLQueryType:
+			queryInterface = &cloudMonitoringPromQLQuery{
+				RefID:       query.RefID,
+				ProjectName: q.PromQLQuery.ProjectName,
+				Expr:        q.PromQLQuery.Expr,
+				Step:        q.PromQLQuery.Step,
+				IntervalMS:  query.IntervalMS,
+				AliasBy:     q.PromQLQuery.AliasBy,
+				timeRange:   *tsdbQuery.TimeRange,
+			}
 		default:
 			panic(fmt.Sprintf("Unrecognized query type %q", q.QueryType))
 		}
@@ -277,7 +288,8 @@ func (e *Executor) buildQueryExecutors(tsdbQuery plugins.DataQuery) ([]cloudMoni
 
 func migrateLegacyQueryModel(query *plugins.DataSubQuery) {
 	mq := query.Model.Get("metricQuery").MustMap()
-	if mq == nil {
+	// PromQL queries don't need a metric query
+	if mq == nil && query.Model.Get("queryType").MustString() != promQLQueryType {
 		migratedModel := simplejson.NewFromAny(map[string]interface{}{
 			"queryType":   metricQueryType,
 			"metricQuery": query.Model.MustMap(),
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/stretchr/testify/assert"
@@ -536,6 +537,33 @@ func TestCloudMonitoring(t *testing.T) {
 			assert.Equal(t, "test-alias", tqueries[0].AliasBy)
 		})
 
+		t.Run("and query type is PromQL", func(t *testing.T) {
+			tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
+				"queryType": promQLQueryType,
+				"promQLQuery": map[string]interface{}{
+					"projectName": "test-proj",
+					"expr":        "rate(up[5m])",
+					"step":        "30s",
+					"aliasBy":     "{{job}}",
+				},
+			})
+
+			qes, err := executor.buildQueryExecutors(tsdbQuery)
+			require.NoError(t, err)
+			require.Len(t, qes, 1)
+			query, ok := qes[0].(*cloudMonitoringPromQLQuery)
+			require.True(t, ok)
+			assert.Equal(t, "A", query.RefID)
+			assert.Equal(t, "test-proj", query.ProjectName)
+			assert.Equal(t, "rate(up[5m])", query.Expr)
+			assert.Equal(t, "30s", query.calculateStep(*tsdbQuery.TimeRange))
+			assert.Equal(t, "{{job}}", query.AliasBy)
+
+			query.Step = ""
+			query.IntervalMS = 60000
+			assert.Equal(t, "60", query.calculateStep(*tsdbQuery.TimeRange))
+		})
+
 		t.Run("and query type is SLOs", func(t *testing.T) {
 			tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
 				"queryType":   sloQueryType,
@@ -957,6 +985,55 @@ func TestCloudMonitoring(t *testing.T) {
 		})
 	})
 
+	t.Run("Parse cloud monitoring response in the PromQL format", func(t *testing.T) {
+		response, err := loadTestFile("./test-data/8-series-response-promql.json")
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(response.PromQLData.Result))
+
+		fromStart := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC).In(time.Local)
+		timeRange := plugins.DataTimeRange{
+			From: fmt.Sprintf("%v", fromStart.Unix()*1000),
+			To:   fmt.Sprintf("%v", fromStart.Add(34*time.Minute).Unix()*1000),
+		}
+
+		t.Run("and the series are named like Prometheus", func(t *testing.T) {
+			//nolint: staticcheck // plugins.DataPlugin deprecated
+			res := &plugins.DataQueryResult{Meta: simplejson.New(), RefID: "A"}
+			query := &cloudMonitoringPromQLQuery{RefID: "A", ProjectName: "test-proj", Expr: "up", timeRange: timeRange}
+			err = query.parseResponse(res, response, "up")
+			require.NoError(t, err)
+			frames, err := res.Dataframes.Decoded()
+			require.NoError(t, err)
+			require.Len(t, frames, 2)
+
+			assert.Equal(t, `up{instance="10.0.0.1:9090", job="prometheus"}`, frames[0].Fields[1].Name)
+			assert.Equal(t, data.Labels{"__name__": "up", "instance": "10.0.0.1:9090", "job": "prometheus"}, frames[0].Fields[1].Labels)
+			assert.Equal(t, "up", frames[0].Meta.ExecutedQueryString)
+			require.Equal(t, 3, frames[0].Fields[0].Len())
+			assert.Equal(t, time.Unix(1521118860, 0).UTC(), frames[0].Fields[0].At(1))
+			assert.Equal(t, 0.0, frames[0].Fields[1].At(1))
+
+			require.Equal(t, 2, frames[1].Fields[0].Len())
+			assert.Equal(t, time.Unix(1521118860, 5e8).UTC(), frames[1].Fields[0].At(1))
+			assert.True(t, math.IsNaN(frames[1].Fields[1].At(1).(float64)))
+			assert.Equal(t, "View in Metrics Explorer", frames[1].Fields[1].Config.Links[0].Title)
+		})
+
+		t.Run("and alias by is expanded", func(t *testing.T) {
+			//nolint: staticcheck // plugins.DataPlugin deprecated
+			res := &plugins.DataQueryResult{Meta: simplejson.New(), RefID: "A"}
+			query := &cloudMonitoringPromQLQuery{
+				RefID: "A", ProjectName: "test-proj", Expr: "up", AliasBy: "{{project}} - {{job}} - {{instance}}", timeRange: timeRange,
+			}
+			err = query.parseResponse(res, response, "up")
+			require.NoError(t, err)
+			frames, err := res.Dataframes.Decoded()
+			require.NoError(t, err)
+			assert.Equal(t, "test-proj - prometheus - 10.0.0.1:9090", frames[0].Fields[1].Name)
+			assert.Equal(t, "test-proj - node - 10.0.0.2:9100", frames[1].Fields[1].Name)
+		})
+	})
+
 	t.Run("when interpolating filter wildcards", func(t *testing.T) {
 		t.Run("and wildcard is used in the beginning and the end of the word", func(t *testing.T) {
 			t.Run("and there's no wildcard in the middle of the word", func(t *testing.T) {
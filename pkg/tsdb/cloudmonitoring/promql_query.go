@@ -0,0 +1,306 @@
+package cloudmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/interval"
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// PromQL queries are run with the Prometheus HTTP API of Google Cloud Managed Service for Prometheus,
+// which queries the metrics of Cloud Monitoring as well.
+// https://cloud.google.com/stackdriver/docs/managed-prometheus/query#api-prometheus
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (promQLQuery cloudMonitoringPromQLQuery) run(ctx context.Context, tsdbQuery plugins.DataQuery,
+	e *Executor) (plugins.DataQueryResult, cloudMonitoringResponse, string, error) {
+	queryResult := plugins.DataQueryResult{Meta: simplejson.New(), RefID: promQLQuery.RefID}
+	projectName := promQLQuery.ProjectName
+	if projectName == "" {
+		defaultProject, err := e.getDefaultProject(ctx)
+		if err != nil {
+			queryResult.Error = err
+			return queryResult, cloudMonitoringResponse{}, "", nil
+		}
+		projectName = defaultProject
+		slog.Info("No project name set on query, using project name from datasource", "projectName", projectName)
+	}
+
+	from, err := tsdbQuery.TimeRange.ParseFrom()
+	if err != nil {
+		queryResult.Error = err
+		return queryResult, cloudMonitoringResponse{}, "", nil
+	}
+	to, err := tsdbQuery.TimeRange.ParseTo()
+	if err != nil {
+		queryResult.Error = err
+		return queryResult, cloudMonitoringResponse{}, "", nil
+	}
+
+	params := url.Values{}
+	params.Add("query", promQLQuery.Expr)
+	params.Add("start", strconv.FormatInt(from.Unix(), 10))
+	params.Add("end", strconv.FormatInt(to.Unix(), 10))
+	params.Add("step", promQLQuery.calculateStep(*tsdbQuery.TimeRange))
+
+	req, err := e.createRequest(ctx, e.dsInfo, path.Join("cloudmonitoring/v1/projects", projectName,
+		"location/global/prometheus/api/v1/query_range"), nil)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult, cloudMonitoringResponse{}, "", nil
+	}
+	req.URL.RawQuery = params.Encode()
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "cloudMonitoring PromQL query")
+	span.SetTag("query", promQLQuery.Expr)
+	span.SetTag("from", tsdbQuery.TimeRange.From)
+	span.SetTag("until", tsdbQuery.TimeRange.To)
+	span.SetTag("datasource_id", e.dsInfo.Id)
+	span.SetTag("org_id", e.dsInfo.OrgId)
+
+	defer span.Finish()
+
+	if err := opentracing.GlobalTracer().Inject(
+		span.Context(),
+		opentracing.HTTPHeaders,
+		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		queryResult.Error = err
+		return queryResult, cloudMonitoringResponse{}, "", nil
+	}
+
+	res, err := ctxhttp.Do(ctx, e.httpClient, req)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult, cloudMonitoringResponse{}, "", nil
+	}
+
+	data, err := unmarshalResponse(res)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult, cloudMonitoringResponse{}, "", nil
+	}
+
+	return queryResult, data, promQLQuery.Expr, nil
+}
+
+// calculateStep returns the step of the query, which is the interval of the query unless it's set.
+func (promQLQuery cloudMonitoringPromQLQuery) calculateStep(timeRange plugins.DataTimeRange) string {
+	if promQLQuery.Step != "" && promQLQuery.Step != "auto" {
+		return promQLQuery.Step
+	}
+	intervalCalculator := interval.NewCalculator(interval.CalculatorOptions{})
+	interval := intervalCalculator.Calculate(timeRange, time.Duration(promQLQuery.IntervalMS)*time.Millisecond)
+	return strconv.FormatFloat(interval.Value.Seconds(), 'f', -1, 64)
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (promQLQuery cloudMonitoringPromQLQuery) parseResponse(queryRes *plugins.DataQueryResult,
+	response cloudMonitoringResponse, executedQueryString string) error {
+	if response.PromQLData.ResultType != "" && response.PromQLData.ResultType != "matrix" {
+		return fmt.Errorf("unsupported PromQL result type %q", response.PromQLData.ResultType)
+	}
+
+	labels := make(map[string]map[string]bool)
+	frames := data.Frames{}
+	for _, series := range response.PromQLData.Result {
+		frame := data.NewFrameOfFieldTypes("", 0, data.FieldTypeTime, data.FieldTypeFloat64)
+		frame.RefID = promQLQuery.RefID
+		frame.Meta = &data.FrameMeta{
+			ExecutedQueryString: executedQueryString,
+		}
+
+		for _, value := range series.Values {
+			t, v, err := parsePromQLSample(value)
+			if err != nil {
+				return err
+			}
+			frame.AppendRow(t, v)
+		}
+
+		seriesLabels := data.Labels{}
+		for key, value := range series.Metric {
+			if _, ok := labels[key]; !ok {
+				labels[key] = map[string]bool{}
+			}
+			labels[key][value] = true
+			seriesLabels[key] = value
+		}
+
+		dataField := frame.Fields[1]
+		dataField.Name = promQLQuery.formatLegend(series.Metric)
+		dataField.Labels = seriesLabels
+		setDisplayNameAsFieldName(dataField)
+
+		frames = append(frames, frame)
+	}
+	if len(response.PromQLData.Result) > 0 {
+		frames = addConfigData(frames, promQLQuery.buildDeepLink(), "")
+	}
+
+	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
+
+	labelsByKey := make(map[string][]string)
+	for key, values := range labels {
+		for value := range values {
+			labelsByKey[key] = append(labelsByKey[key], value)
+		}
+	}
+	queryRes.Meta.Set("labels", labelsByKey)
+
+	return nil
+}
+
+// parsePromQLSample parses a [<unix time in seconds>, "<value>"] pair of a PromQL series.
+func parsePromQLSample(sample []interface{}) (time.Time, float64, error) {
+	if len(sample) != 2 {
+		return time.Time{}, 0, fmt.Errorf("invalid PromQL sample %v", sample)
+	}
+	ts, ok := sample[0].(float64)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("invalid PromQL sample time %v", sample[0])
+	}
+	s, ok := sample[1].(string)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("invalid PromQL sample value %v", sample[1])
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid PromQL sample value %q: %w", s, err)
+	}
+	return time.Unix(0, int64(ts*float64(time.Second))).UTC(), value, nil
+}
+
+// formatLegend returns the name of a series, which is the alias of the query with the {{label}} patterns
+// replaced by the labels of the series, or the series in the Prometheus format when the query has no alias.
+func (promQLQuery cloudMonitoringPromQLQuery) formatLegend(labels map[string]string) string {
+	if promQLQuery.AliasBy == "" {
+		return formatPromQLSeries(labels)
+	}
+
+	result := legendKeyFormat.ReplaceAllFunc([]byte(promQLQuery.AliasBy), func(in []byte) []byte {
+		labelName := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(string(in), "{{"), "}}"))
+		if val, exists := labels[labelName]; exists {
+			return []byte(val)
+		}
+		if labelName == "project" && promQLQuery.ProjectName != "" {
+			return []byte(promQLQuery.ProjectName)
+		}
+		return in
+	})
+	return string(result)
+}
+
+// formatPromQLSeries formats the labels of a series like Prometheus: name{label="value", ...}.
+func formatPromQLSeries(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		if key != "__name__" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, labels[key]))
+	}
+	if len(pairs) == 0 {
+		return labels["__name__"]
+	}
+	return fmt.Sprintf("%s{%s}", labels["__name__"], strings.Join(pairs, ", "))
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (promQLQuery cloudMonitoringPromQLQuery) parseToAnnotations(queryRes *plugins.DataQueryResult,
+	response cloudMonitoringResponse, title string, text string, tags string) error {
+	annotations := make([]map[string]string, 0)
+
+	for _, series := range response.PromQLData.Result {
+		for _, sample := range series.Values {
+			t, v, err := parsePromQLSample(sample)
+			if err != nil {
+				return err
+			}
+			value := strconv.FormatFloat(v, 'f', 6, 64)
+			annotation := make(map[string]string)
+			annotation["time"] = t.Format(time.RFC3339)
+			annotation["title"] = formatAnnotationText(title, value, series.Metric["__name__"], series.Metric, nil)
+			annotation["tags"] = tags
+			annotation["text"] = formatAnnotationText(text, value, series.Metric["__name__"], series.Metric, nil)
+			annotations = append(annotations, annotation)
+		}
+	}
+
+	transformAnnotationToTable(annotations, queryRes)
+	return nil
+}
+
+func (promQLQuery cloudMonitoringPromQLQuery) buildDeepLink() string {
+	u, err := url.Parse("https://console.cloud.google.com/monitoring/metrics-explorer")
+	if err != nil {
+		slog.Error("Failed to generate deep link: unable to parse metrics explorer URL", "projectName", promQLQuery.ProjectName, "query", promQLQuery.RefID)
+		return ""
+	}
+
+	q := u.Query()
+	q.Set("project", promQLQuery.ProjectName)
+	q.Set("Grafana_deeplink", "true")
+
+	pageState := map[string]interface{}{
+		"xyChart": map[string]interface{}{
+			"constantLines": []string{},
+			"dataSets": []map[string]interface{}{
+				{
+					"prometheusQuery": promQLQuery.Expr,
+					"targetAxis":      "Y1",
+					"plotType":        "LINE",
+				},
+			},
+			"timeshiftDuration": "0s",
+			"y1Axis": map[string]string{
+				"label": "y1Axis",
+				"scale": "LINEAR",
+			},
+		},
+		"timeSelection": map[string]string{
+			"timeRange": "custom",
+			"start":     promQLQuery.timeRange.MustGetFrom().Format(time.RFC3339Nano),
+			"end":       promQLQuery.timeRange.MustGetTo().Format(time.RFC3339Nano),
+		},
+	}
+
+	blob, err := json.Marshal(pageState)
+	if err != nil {
+		slog.Error("Failed to generate deep link", "pageState", pageState, "ProjectName", promQLQuery.ProjectName, "query", promQLQuery.RefID)
+		return ""
+	}
+
+	q.Set("pageState", string(blob))
+	u.RawQuery = q.Encode()
+
+	accountChooserURL, err := url.Parse("https://accounts.google.com/AccountChooser")
+	if err != nil {
+		slog.Error("Failed to generate deep link: unable to parse account chooser URL", "ProjectName", promQLQuery.ProjectName, "query", promQLQuery.RefID)
+		return ""
+	}
+	accountChooserQuery := accountChooserURL.Query()
+	accountChooserQuery.Set("continue", u.String())
+	accountChooserURL.RawQuery = accountChooserQuery.Encode()
+
+	return accountChooserURL.String()
+}
+
+func (promQLQuery cloudMonitoringPromQLQuery) getRefID() string {
+	return promQLQuery.RefID
+}
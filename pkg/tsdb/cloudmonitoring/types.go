@@ -44,6 +44,17 @@ type (
 		timeRange   plugins.DataTimeRange
 	}
 
+	// Used to build PromQL queries
+	cloudMonitoringPromQLQuery struct {
+		RefID       string
+		ProjectName string
+		Expr        string
+		Step        string
+		IntervalMS  int64
+		AliasBy     string
+		timeRange   plugins.DataTimeRange
+	}
+
 	metricQuery struct {
 		ProjectName        string
 		MetricType         string
@@ -70,12 +81,20 @@ type (
 		SloId            string
 	}
 
+	promQLQuery struct {
+		ProjectName string
+		Expr        string
+		Step        string
+		AliasBy     string
+	}
+
 	grafanaQuery struct {
 		DatasourceId int
 		RefId        string
 		QueryType    string
 		MetricQuery  metricQuery
 		SloQuery     sloQuery
+		PromQLQuery  promQLQuery
 	}
 
 	cloudMonitoringBucketOptions struct {
@@ -99,9 +118,20 @@ type (
 		TimeSeriesDescriptor timeSeriesDescriptor `json:"timeSeriesDescriptor"`
 		TimeSeriesData       timeSeriesData       `json:"timeSeriesData"`
 		Unit                 string               `json:"unit"`
+		PromQLData           promQLData           `json:"data"`
 	}
 )
 
+// promQLData is the data of a response of the Prometheus HTTP API of Google Cloud Managed Service for Prometheus.
+type promQLData struct {
+	ResultType string `json:"resultType"`
+	Result     []struct {
+		Metric map[string]string `json:"metric"`
+		// Values are the [<unix time in seconds>, "<value>"] pairs of the series.
+		Values [][]interface{} `json:"values"`
+	} `json:"result"`
+}
+
 type timeSeriesDescriptor struct {
 	LabelDescriptors []struct {
 		Key         string `json:"key"`
@@ -0,0 +1,129 @@
+package cloudwatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/oam"
+	"github.com/aws/aws-sdk-go/service/oam/oamiface"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+)
+
+// account is an account whose metrics can be queried by the data source. Accounts other than the account of the
+// data source are the source accounts linked to it when it's a monitoring account of the CloudWatch cross-account
+// observability.
+type account struct {
+	Id                  string `json:"id"`
+	Arn                 string `json:"arn"`
+	Label               string `json:"label"`
+	IsMonitoringAccount bool   `json:"isMonitoringAccount"`
+}
+
+func (e *cloudWatchExecutor) getOAMClient(region string, pluginCtx backend.PluginContext) (oamiface.OAMAPI, error) {
+	sess, err := e.newSession(region, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	return newOAMClient(sess), nil
+}
+
+// listAccounts returns the monitoring account of the region and the source accounts linked to its sink, or no
+// accounts when the account of the data source isn't a monitoring account in the region.
+func (e *cloudWatchExecutor) listAccounts(region string, pluginCtx backend.PluginContext) ([]account, error) {
+	client, err := e.getOAMClient(region, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	sinks, err := client.ListSinks(&oam.ListSinksInput{})
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]account, 0)
+	// a monitoring account has a single sink per region
+	if len(sinks.Items) == 0 {
+		return accounts, nil
+	}
+	sink := sinks.Items[0]
+	sinkARN, err := arn.Parse(aws.StringValue(sink.Arn))
+	if err != nil {
+		return nil, err
+	}
+	accounts = append(accounts, account{
+		Id:                  sinkARN.AccountID,
+		Arn:                 sinkARN.String(),
+		Label:               aws.StringValue(sink.Name),
+		IsMonitoringAccount: true,
+	})
+
+	err = client.ListAttachedLinksPages(&oam.ListAttachedLinksInput{SinkIdentifier: sink.Arn},
+		func(page *oam.ListAttachedLinksOutput, lastPage bool) bool {
+			for _, link := range page.Items {
+				linkARN, err := arn.Parse(aws.StringValue(link.LinkArn))
+				if err != nil {
+					plog.Warn("Ignoring link with invalid ARN", "arn", aws.StringValue(link.LinkArn), "err", err)
+					continue
+				}
+				accounts = append(accounts, account{
+					Id:    linkARN.AccountID,
+					Arn:   linkARN.String(),
+					Label: aws.StringValue(link.Label),
+				})
+			}
+			return !lastPage
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sources := accounts[1:]
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Label < sources[j].Label
+	})
+	return accounts, nil
+}
+
+// newResourceHandler returns the handler of the resources of the data source:
+//  - accounts?region=<region> lists the accounts whose metrics can be queried from the region.
+func (e *cloudWatchExecutor) newResourceHandler() backend.CallResourceHandler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/accounts", e.handleGetAccounts)
+	return httpadapter.New(mux)
+}
+
+func (e *cloudWatchExecutor) handleGetAccounts(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeResourceResponse(rw, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+		return
+	}
+
+	region := req.URL.Query().Get("region")
+	if region == "" {
+		region = defaultRegion
+	}
+	accounts, err := e.listAccounts(region, httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		plog.Error("Failed to list accounts", "region", region, "err", err)
+		writeResourceResponse(rw, http.StatusInternalServerError, map[string]string{"message": "failed to list accounts: " + err.Error()})
+		return
+	}
+	writeResourceResponse(rw, http.StatusOK, accounts)
+}
+
+func writeResourceResponse(rw http.ResponseWriter, status int, body interface{}) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		plog.Error("Failed to marshal response", "err", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if _, err := rw.Write(b); err != nil {
+		plog.Error("Failed to write response", "err", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cloudwatch
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/oam"
+	"github.com/aws/aws-sdk-go/service/oam/oamiface"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resourceResponseSender func(*backend.CallResourceResponse)
+
+func (fn resourceResponseSender) Send(resp *backend.CallResourceResponse) error {
+	fn(resp)
+	return nil
+}
+
+func TestResource_Accounts(t *testing.T) {
+	origNewOAMClient := newOAMClient
+	t.Cleanup(func() {
+		newOAMClient = origNewOAMClient
+	})
+
+	var oamClient fakeOAMClient
+	newOAMClient = func(client.ConfigProvider) oamiface.OAMAPI {
+		return oamClient
+	}
+
+	im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return datasourceInfo{}, nil
+	})
+	handler := newExecutor(nil, im, newTestConfig(), fakeSessionCache{}).newResourceHandler()
+	call := func(method, resourceURL string) *backend.CallResourceResponse {
+		var resp *backend.CallResourceResponse
+		err := handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
+			},
+			Path:   "accounts",
+			Method: method,
+			URL:    resourceURL,
+		}, resourceResponseSender(func(r *backend.CallResourceResponse) {
+			resp = r
+		}))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Lists the monitoring account and its source accounts", func(t *testing.T) {
+		oamClient = fakeOAMClient{
+			sinks: []*oam.ListSinksItem{
+				{Arn: aws.String("arn:aws:oam:us-east-1:111111111111:sink/sink-id"), Name: aws.String("monitoring")},
+			},
+			links: []*oam.ListAttachedLinksItem{
+				{LinkArn: aws.String("arn:aws:oam:us-east-1:333333333333:link/link-id"), Label: aws.String("staging")},
+				{LinkArn: aws.String("invalid")},
+				{LinkArn: aws.String("arn:aws:oam:us-east-1:222222222222:link/link-id"), Label: aws.String("production")},
+			},
+		}
+
+		resp := call(http.MethodGet, "accounts?region=us-east-1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `[
+			{"id": "111111111111", "arn": "arn:aws:oam:us-east-1:111111111111:sink/sink-id", "label": "monitoring", "isMonitoringAccount": true},
+			{"id": "222222222222", "arn": "arn:aws:oam:us-east-1:222222222222:link/link-id", "label": "production", "isMonitoringAccount": false},
+			{"id": "333333333333", "arn": "arn:aws:oam:us-east-1:333333333333:link/link-id", "label": "staging", "isMonitoringAccount": false}
+		]`, string(resp.Body))
+	})
+
+	t.Run("Lists no accounts when the account isn't a monitoring account", func(t *testing.T) {
+		oamClient = fakeOAMClient{}
+
+		resp := call(http.MethodGet, "accounts")
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `[]`, string(resp.Body))
+	})
+
+	t.Run("Rejects other methods", func(t *testing.T) {
+		resp := call(http.MethodPost, "accounts")
+		require.Equal(t, http.StatusMethodNotAllowed, resp.Status)
+	})
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/oam"
+	"github.com/aws/aws-sdk-go/service/oam/oamiface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
@@ -75,8 +77,10 @@ func (s *CloudWatchService) Init() error {
 
 	im := datasource.NewInstanceManager(NewInstanceSettings())
 
+	executor := newExecutor(s.LogsService, im, s.Cfg, awsds.NewSessionCache())
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: newExecutor(s.LogsService, im, s.Cfg, awsds.NewSessionCache()),
+		QueryDataHandler:    executor,
+		CallResourceHandler: executor.newResourceHandler(),
 	})
 
 	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "cloudwatch", factory); err != nil {
@@ -90,12 +94,14 @@ type SessionCache interface {
 }
 
 func newExecutor(logsService *LogsService, im instancemgmt.InstanceManager, cfg *setting.Cfg, sessions SessionCache) *cloudWatchExecutor {
-	return &cloudWatchExecutor{
+	e := &cloudWatchExecutor{
 		logsService: logsService,
 		im:          im,
 		cfg:         cfg,
 		sessions:    sessions,
 	}
+	e.metricDataBatcher = newMetricDataBatcher(metricDataBatchWindow, e.executeRequest)
+	return e
 }
 
 func NewInstanceSettings() datasource.InstanceFactoryFunc {
@@ -160,10 +166,11 @@ type cloudWatchExecutor struct {
 	ec2Client  ec2iface.EC2API
 	rgtaClient resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
 
-	logsService *LogsService
-	im          instancemgmt.InstanceManager
-	cfg         *setting.Cfg
-	sessions    SessionCache
+	logsService       *LogsService
+	im                instancemgmt.InstanceManager
+	cfg               *setting.Cfg
+	sessions          SessionCache
+	metricDataBatcher *metricDataBatcher
 }
 
 func (e *cloudWatchExecutor) newSession(region string, pluginCtx backend.PluginContext) (*session.Session, error) {
@@ -238,14 +245,29 @@ func (e *cloudWatchExecutor) getRGTAClient(region string, pluginCtx backend.Plug
 }
 
 func (e *cloudWatchExecutor) alertQuery(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
-	queryContext backend.DataQuery, model *simplejson.Json) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	queryContext backend.DataQuery, model *simplejson.Json, pluginCtx backend.PluginContext) (*cloudwatchlogs.GetQueryResultsOutput, error) {
 	const maxAttempts = 8
 	const pollPeriod = 1000 * time.Millisecond
 
+	queue, err := e.getQueue(model.Get("region").MustString(defaultRegion), pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := queue.acquire(ctx, logsQueryUser(pluginCtx)); err != nil {
+		return nil, err
+	}
+	defer queue.release()
+
 	startQueryOutput, err := e.executeStartQuery(ctx, logsClient, model, queryContext.TimeRange)
 	if err != nil {
 		return nil, err
 	}
+	terminated := false
+	defer func() {
+		if !terminated {
+			e.stopLogsQuery(logsClient, startQueryOutput.QueryId)
+		}
+	}()
 
 	requestParams := simplejson.NewFromAny(map[string]interface{}{
 		"region":  model.Get("region").MustString(""),
@@ -255,23 +277,25 @@ func (e *cloudWatchExecutor) alertQuery(ctx context.Context, logsClient cloudwat
 	ticker := time.NewTicker(pollPeriod)
 	defer ticker.Stop()
 
-	attemptCount := 1
-	for range ticker.C {
+	for attemptCount := 1; ; attemptCount++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
 		res, err := e.executeGetQueryResults(ctx, logsClient, requestParams)
 		if err != nil {
 			return nil, err
 		}
 		if isTerminated(*res.Status) {
+			terminated = true
 			return res, err
 		}
 		if attemptCount >= maxAttempts {
 			return res, fmt.Errorf("fetching of query results exceeded max number of attempts")
 		}
-
-		attemptCount++
 	}
-
-	return nil, nil
 }
 
 func (e *cloudWatchExecutor) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -341,14 +365,7 @@ func (e *cloudWatchExecutor) executeLogAlertQuery(ctx context.Context, req *back
 			return nil, err
 		}
 
-		result, err := e.executeStartQuery(ctx, logsClient, model, q.TimeRange)
-		if err != nil {
-			return nil, err
-		}
-
-		model.Set("queryId", *result.QueryId)
-
-		getQueryResultsOutput, err := e.alertQuery(ctx, logsClient, q, model)
+		getQueryResultsOutput, err := e.alertQuery(ctx, logsClient, q, model, req.PluginContext)
 		if err != nil {
 			return nil, err
 		}
@@ -424,6 +441,13 @@ var newEC2Client = func(provider client.ConfigProvider) ec2iface.EC2API {
 	return ec2.New(provider)
 }
 
+// OAM client factory.
+//
+// Stubbable by tests.
+var newOAMClient = func(provider client.ConfigProvider) oamiface.OAMAPI {
+	return oam.New(provider)
+}
+
 // RGTA client factory.
 //
 // Stubbable by tests.
@@ -17,10 +17,20 @@ type cloudWatchQuery struct {
 	Period                  int
 	Alias                   string
 	MatchExact              bool
+	AccountId               string
 	UsedExpression          string
 	RequestExceededMaxLimit bool
 }
 
+// allAccounts is the account ID of the queries of a monitoring account returning the metrics of all its source
+// accounts, which is the same as not setting an account ID.
+const allAccounts = "all"
+
+// isSingleAccount returns whether an account ID restricts a query of a monitoring account to one account.
+func isSingleAccount(accountId string) bool {
+	return accountId != "" && accountId != allAccounts
+}
+
 func (q *cloudWatchQuery) isMathExpression() bool {
 	return q.Expression != "" && !q.isUserDefinedSearchExpression()
 }
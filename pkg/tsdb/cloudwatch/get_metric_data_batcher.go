@@ -0,0 +1,207 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+)
+
+const (
+	// maxMetricDataQueries is the max number of queries of a GetMetricData call.
+	maxMetricDataQueries = 500
+	// metricDataBatchWindow is how long the queries of the requests made at the same time, such as the requests
+	// of the panels of a dashboard, are collected before they are sent in a single GetMetricData call.
+	metricDataBatchWindow = 10 * time.Millisecond
+)
+
+// metricDataBatchKey identifies the requests that can share a GetMetricData call: the requests of a data source,
+// which have the same credentials, in the same region and time range.
+type metricDataBatchKey struct {
+	datasourceID int64
+	region       string
+	startTime    int64
+	endTime      int64
+}
+
+func newMetricDataBatchKey(dsInfo *datasourceInfo, region string, input *cloudwatch.GetMetricDataInput) metricDataBatchKey {
+	if region == defaultRegion {
+		region = dsInfo.region
+	}
+	return metricDataBatchKey{
+		datasourceID: dsInfo.datasourceID,
+		region:       region,
+		startTime:    aws.TimeValue(input.StartTime).UnixNano(),
+		endTime:      aws.TimeValue(input.EndTime).UnixNano(),
+	}
+}
+
+// metricDataBatch is a GetMetricData call shared by the requests added to it before it's sent.
+type metricDataBatch struct {
+	client cloudwatchiface.CloudWatchAPI
+	input  *cloudwatch.GetMetricDataInput
+	// ids are the IDs of the queries of the batch.
+	ids map[string]struct{}
+	// requests is the number of requests waiting for the batch, the call is cancelled when none is left.
+	requests int
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	outputs []*cloudwatch.GetMetricDataOutput
+	err     error
+}
+
+// add adds the queries of a request to the batch, and returns the IDs of the queries in the batch mapped to
+// their IDs in the request, or false when they don't fit in the batch. The queries whose IDs are taken by the
+// queries of other requests are renamed, unless they include math expressions, which may refer to their IDs.
+func (b *metricDataBatch) add(input *cloudwatch.GetMetricDataInput) (map[string]string, bool) {
+	if len(b.input.MetricDataQueries)+len(input.MetricDataQueries) > maxMetricDataQueries {
+		return nil, false
+	}
+
+	prefix := ""
+	for _, mdq := range input.MetricDataQueries {
+		if _, ok := b.ids[aws.StringValue(mdq.Id)]; ok {
+			prefix = fmt.Sprintf("r%d_", b.requests)
+			break
+		}
+	}
+	if prefix != "" {
+		for _, mdq := range input.MetricDataQueries {
+			if isMathExpressionQuery(mdq) {
+				return nil, false
+			}
+			if _, ok := b.ids[prefix+aws.StringValue(mdq.Id)]; ok {
+				return nil, false
+			}
+		}
+	}
+
+	ids := make(map[string]string, len(input.MetricDataQueries))
+	for _, mdq := range input.MetricDataQueries {
+		batchMDQ := *mdq
+		batchMDQ.Id = aws.String(prefix + aws.StringValue(mdq.Id))
+		b.input.MetricDataQueries = append(b.input.MetricDataQueries, &batchMDQ)
+		b.ids[*batchMDQ.Id] = struct{}{}
+		ids[*batchMDQ.Id] = aws.StringValue(mdq.Id)
+	}
+	b.requests++
+	return ids, true
+}
+
+// outputsOf returns the outputs of the batch holding the results of the queries of a request, with their IDs in
+// the request.
+func (b *metricDataBatch) outputsOf(ids map[string]string) []*cloudwatch.GetMetricDataOutput {
+	outputs := make([]*cloudwatch.GetMetricDataOutput, 0, len(b.outputs))
+	for _, batchOutput := range b.outputs {
+		output := &cloudwatch.GetMetricDataOutput{Messages: batchOutput.Messages}
+		for _, result := range batchOutput.MetricDataResults {
+			id, ok := ids[aws.StringValue(result.Id)]
+			if !ok {
+				continue
+			}
+			r := *result
+			r.Id = aws.String(id)
+			output.MetricDataResults = append(output.MetricDataResults, &r)
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+func isMathExpressionQuery(mdq *cloudwatch.MetricDataQuery) bool {
+	return mdq.Expression != nil && !strings.Contains(*mdq.Expression, "SEARCH(")
+}
+
+type getMetricDataFunc func(ctx context.Context, client cloudwatchiface.CloudWatchAPI,
+	input *cloudwatch.GetMetricDataInput) ([]*cloudwatch.GetMetricDataOutput, error)
+
+// metricDataBatcher combines the queries of the requests made at the same time into GetMetricData calls of up to
+// maxMetricDataQueries queries, so that a dashboard with many panels makes fewer calls and is throttled less.
+type metricDataBatcher struct {
+	mu            sync.Mutex
+	window        time.Duration
+	getMetricData getMetricDataFunc
+	// batches are the batches that aren't sent yet.
+	batches map[metricDataBatchKey]*metricDataBatch
+}
+
+func newMetricDataBatcher(window time.Duration, getMetricData getMetricDataFunc) *metricDataBatcher {
+	return &metricDataBatcher{
+		window:        window,
+		getMetricData: getMetricData,
+		batches:       make(map[metricDataBatchKey]*metricDataBatch),
+	}
+}
+
+// execute returns the outputs of the GetMetricData calls of a request, made along with the requests with the same
+// key made at the same time.
+func (m *metricDataBatcher) execute(ctx context.Context, key metricDataBatchKey, client cloudwatchiface.CloudWatchAPI,
+	input *cloudwatch.GetMetricDataInput) ([]*cloudwatch.GetMetricDataOutput, error) {
+	if len(input.MetricDataQueries) > maxMetricDataQueries {
+		return m.getMetricData(ctx, client, input)
+	}
+
+	m.mu.Lock()
+	batch, ok := m.batches[key]
+	var ids map[string]string
+	if ok {
+		ids, ok = batch.add(input)
+	}
+	if !ok {
+		batch = m.newBatch(key, client, input)
+		ids, _ = batch.add(input)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-batch.done:
+		if batch.err != nil {
+			return nil, batch.err
+		}
+		return batch.outputsOf(ids), nil
+	case <-ctx.Done():
+		m.mu.Lock()
+		batch.requests--
+		if batch.requests == 0 {
+			batch.cancel()
+		}
+		m.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// newBatch opens a batch for the key, which is sent once the batch window is over. Requests that don't fit in the
+// previous batch of the key are added to the new batch, while the previous one is still sent on time.
+func (m *metricDataBatcher) newBatch(key metricDataBatchKey, client cloudwatchiface.CloudWatchAPI,
+	input *cloudwatch.GetMetricDataInput) *metricDataBatch {
+	batchInput := *input
+	batchInput.MetricDataQueries = make([]*cloudwatch.MetricDataQuery, 0, len(input.MetricDataQueries))
+	batch := &metricDataBatch{
+		client: client,
+		input:  &batchInput,
+		ids:    make(map[string]struct{}),
+		done:   make(chan struct{}),
+	}
+	batch.ctx, batch.cancel = context.WithCancel(context.Background())
+	m.batches[key] = batch
+
+	time.AfterFunc(m.window, func() {
+		m.mu.Lock()
+		if m.batches[key] == batch {
+			delete(m.batches, key)
+		}
+		m.mu.Unlock()
+
+		defer batch.cancel()
+		batch.outputs, batch.err = m.getMetricData(batch.ctx, batch.client, batch.input)
+		close(batch.done)
+	})
+	return batch
+}
@@ -0,0 +1,162 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricDataBatcher(t *testing.T) {
+	// batcherScenario returns a batcher whose calls return a result labeled with the ID of each query.
+	batcherScenario := func(t *testing.T) (*metricDataBatcher, func() []*cloudwatch.GetMetricDataInput) {
+		t.Helper()
+
+		var mu sync.Mutex
+		calls := []*cloudwatch.GetMetricDataInput{}
+		batcher := newMetricDataBatcher(50*time.Millisecond, func(ctx context.Context, client cloudwatchiface.CloudWatchAPI,
+			input *cloudwatch.GetMetricDataInput) ([]*cloudwatch.GetMetricDataOutput, error) {
+			mu.Lock()
+			calls = append(calls, input)
+			mu.Unlock()
+
+			output := &cloudwatch.GetMetricDataOutput{}
+			for _, mdq := range input.MetricDataQueries {
+				output.MetricDataResults = append(output.MetricDataResults, &cloudwatch.MetricDataResult{
+					Id:    mdq.Id,
+					Label: aws.String(aws.StringValue(mdq.Id)),
+				})
+			}
+			return []*cloudwatch.GetMetricDataOutput{output}, nil
+		})
+		return batcher, func() []*cloudwatch.GetMetricDataInput {
+			mu.Lock()
+			defer mu.Unlock()
+			return calls
+		}
+	}
+
+	newInput := func(queries ...*cloudwatch.MetricDataQuery) *cloudwatch.GetMetricDataInput {
+		return &cloudwatch.GetMetricDataInput{
+			StartTime:         aws.Time(time.Unix(1584700643, 0)),
+			EndTime:           aws.Time(time.Unix(1584873443, 0)),
+			MetricDataQueries: queries,
+		}
+	}
+	metricStat := func(id string) *cloudwatch.MetricDataQuery {
+		return &cloudwatch.MetricDataQuery{Id: aws.String(id), MetricStat: &cloudwatch.MetricStat{}}
+	}
+	expression := func(id, expr string) *cloudwatch.MetricDataQuery {
+		return &cloudwatch.MetricDataQuery{Id: aws.String(id), Expression: aws.String(expr)}
+	}
+	// executeAll executes the inputs at the same time, and returns the labels of the results of each input.
+	executeAll := func(t *testing.T, batcher *metricDataBatcher, keys []metricDataBatchKey,
+		inputs ...*cloudwatch.GetMetricDataInput) [][]string {
+		t.Helper()
+
+		labels := make([][]string, len(inputs))
+		var wg sync.WaitGroup
+		for i, input := range inputs {
+			wg.Add(1)
+			go func(i int, input *cloudwatch.GetMetricDataInput) {
+				defer wg.Done()
+				outputs, err := batcher.execute(context.Background(), keys[i], nil, input)
+				assert.NoError(t, err)
+				for _, output := range outputs {
+					for _, result := range output.MetricDataResults {
+						labels[i] = append(labels[i], aws.StringValue(result.Id)+"="+aws.StringValue(result.Label))
+					}
+				}
+			}(i, input)
+		}
+		wg.Wait()
+		return labels
+	}
+	key := metricDataBatchKey{datasourceID: 1, region: "us-east-1"}
+
+	t.Run("Should combine the queries of the requests in a single call", func(t *testing.T) {
+		batcher, calls := batcherScenario(t)
+
+		labels := executeAll(t, batcher, []metricDataBatchKey{key, key},
+			newInput(metricStat("queryA"), expression("queryB", "SEARCH('{AWS/EC2,InstanceId}', 'Average', 300)")),
+			newInput(metricStat("queryA")))
+
+		require.Len(t, calls(), 1)
+		ids := map[string]bool{}
+		for _, mdq := range calls()[0].MetricDataQueries {
+			ids[aws.StringValue(mdq.Id)] = true
+		}
+		assert.Len(t, ids, 3)
+		require.Len(t, labels[0], 2)
+		assert.Regexp(t, `^queryA=(r\d_)?queryA$`, labels[0][0])
+		assert.Regexp(t, `^queryB=(r\d_)?queryB$`, labels[0][1])
+		require.Len(t, labels[1], 1)
+		assert.Regexp(t, `^queryA=(r\d_)?queryA$`, labels[1][0])
+		assert.NotEqual(t, labels[0][0], labels[1][0])
+	})
+
+	t.Run("Should keep the math expressions of the requests with taken IDs apart", func(t *testing.T) {
+		batch := &metricDataBatch{input: newInput(), ids: map[string]struct{}{}}
+
+		ids, ok := batch.add(newInput(metricStat("m1")))
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"m1": "m1"}, ids)
+
+		_, ok = batch.add(newInput(metricStat("m1"), expression("e1", "m1*2")))
+		assert.False(t, ok)
+
+		ids, ok = batch.add(newInput(metricStat("m2"), expression("e2", "m2*2")))
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"m2": "m2", "e2": "e2"}, ids)
+
+		ids, ok = batch.add(newInput(metricStat("m1"), expression("e3", "SEARCH('{AWS/EC2,InstanceId}', 'Average', 300)")))
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"r2_m1": "m1", "r2_e3": "e3"}, ids)
+		assert.Len(t, batch.input.MetricDataQueries, 5)
+	})
+
+	t.Run("Should send no more than 500 queries per call", func(t *testing.T) {
+		batcher, calls := batcherScenario(t)
+
+		inputs := []*cloudwatch.GetMetricDataInput{}
+		for i := 0; i < 3; i++ {
+			queries := []*cloudwatch.MetricDataQuery{}
+			for j := 0; j < 200; j++ {
+				queries = append(queries, metricStat(fmt.Sprintf("q%d_%d", i, j)))
+			}
+			inputs = append(inputs, newInput(queries...))
+		}
+		labels := executeAll(t, batcher, []metricDataBatchKey{key, key, key}, inputs...)
+
+		require.Len(t, calls(), 2)
+		assert.ElementsMatch(t, []int{400, 200}, []int{len(calls()[0].MetricDataQueries), len(calls()[1].MetricDataQueries)})
+		for _, l := range labels {
+			assert.Len(t, l, 200)
+		}
+	})
+
+	t.Run("Should make separate calls for the requests of other regions", func(t *testing.T) {
+		batcher, calls := batcherScenario(t)
+
+		otherKey := metricDataBatchKey{datasourceID: 1, region: "eu-west-1"}
+		executeAll(t, batcher, []metricDataBatchKey{key, otherKey}, newInput(metricStat("a")), newInput(metricStat("a")))
+
+		assert.Len(t, calls(), 2)
+	})
+
+	t.Run("Should return when the context of the request is done", func(t *testing.T) {
+		batcher, _ := batcherScenario(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := batcher.execute(ctx, key, nil, newInput(metricStat("a")))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
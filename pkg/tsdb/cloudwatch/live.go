@@ -153,7 +153,19 @@ func (e *cloudWatchExecutor) sendLiveQueriesToChannel(req *backend.QueryDataRequ
 	}
 }
 
-func (e *cloudWatchExecutor) getQueue(queueKey string, pluginCtx backend.PluginContext) (chan bool, error) {
+// getQueue returns the queue of the Logs Insights queries of the data source in a region. The number of running
+// queries is limited to the concurrent queries quota of the account, or to the logs_max_concurrent_queries
+// setting when lower.
+func (e *cloudWatchExecutor) getQueue(region string, pluginCtx backend.PluginContext) (*logsQueryQueue, error) {
+	dsInfo, err := e.getDSInfo(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	if region == defaultRegion {
+		region = dsInfo.region
+	}
+	queueKey := fmt.Sprintf("%s-%d", region, dsInfo.datasourceID)
+
 	e.logsService.queueLock.Lock()
 	defer e.logsService.queueLock.Unlock()
 
@@ -161,12 +173,23 @@ func (e *cloudWatchExecutor) getQueue(queueKey string, pluginCtx backend.PluginC
 		return queue, nil
 	}
 
-	concurrentQueriesQuota := e.fetchConcurrentQueriesQuota(queueKey, pluginCtx)
+	concurrentQueries := e.fetchConcurrentQueriesQuota(region, pluginCtx)
+	if maxConcurrentQueries := e.cfg.AWSLogsMaxConcurrentQueries; maxConcurrentQueries > 0 && maxConcurrentQueries < concurrentQueries {
+		concurrentQueries = maxConcurrentQueries
+	}
+
+	queue := newLogsQueryQueue(concurrentQueries)
+	e.logsService.queues[queueKey] = queue
 
-	queueChannel := make(chan bool, concurrentQueriesQuota)
-	e.logsService.queues[queueKey] = queueChannel
+	return queue, nil
+}
 
-	return queueChannel, nil
+// logsQueryUser returns the user whose queries a query is queued with.
+func logsQueryUser(pluginCtx backend.PluginContext) string {
+	if pluginCtx.User == nil {
+		return ""
+	}
+	return pluginCtx.User.Login
 }
 
 func (e *cloudWatchExecutor) fetchConcurrentQueriesQuota(region string, pluginCtx backend.PluginContext) int {
@@ -230,19 +253,27 @@ func (e *cloudWatchExecutor) startLiveQuery(ctx context.Context, responseChannel
 		return err
 	}
 
-	queue, err := e.getQueue(fmt.Sprintf("%s-%d", region, dsInfo.datasourceID), pluginCtx)
+	queue, err := e.getQueue(region, pluginCtx)
 	if err != nil {
 		return err
 	}
 
 	// Wait until there are no more active workers than the concurrent queries quota
-	queue <- true
-	defer func() { <-queue }()
+	if err := queue.acquire(ctx, logsQueryUser(pluginCtx)); err != nil {
+		return err
+	}
+	defer queue.release()
 
 	startQueryOutput, err := e.executeStartQuery(ctx, logsClient, model, timeRange)
 	if err != nil {
 		return err
 	}
+	terminated := false
+	defer func() {
+		if !terminated {
+			e.stopLogsQuery(logsClient, startQueryOutput.QueryId)
+		}
+	}()
 
 	queryResultsInput := &cloudwatchlogs.GetQueryResultsInput{
 		QueryId: startQueryOutput.QueryId,
@@ -250,6 +281,10 @@ func (e *cloudWatchExecutor) startLiveQuery(ctx context.Context, responseChannel
 
 	recordsMatched := 0.0
 	return retryer.Retry(func() (retryer.RetrySignal, error) {
+		if err := ctx.Err(); err != nil {
+			return retryer.FuncError, err
+		}
+
 		getQueryResultsOutput, err := logsClient.GetQueryResultsWithContext(ctx, queryResultsInput)
 		if err != nil {
 			return retryer.FuncError, err
@@ -303,6 +338,7 @@ func (e *cloudWatchExecutor) startLiveQuery(ctx context.Context, responseChannel
 		}
 
 		if isTerminated(*getQueryResultsOutput.Status) {
+			terminated = true
 			return retryer.FuncComplete, nil
 		} else if retryNeeded {
 			return retryer.FuncFailure, nil
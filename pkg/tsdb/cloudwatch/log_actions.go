@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -16,6 +17,9 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// stopQueryTimeout is the timeout of the requests stopping the queries that are no longer polled.
+const stopQueryTimeout = 10 * time.Second
+
 func (e *cloudWatchExecutor) executeLogActions(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
@@ -280,6 +284,19 @@ func (e *cloudWatchExecutor) executeStopQuery(ctx context.Context, logsClient cl
 	return response, err
 }
 
+// stopLogsQuery stops a query that is no longer polled before it terminated, so that it doesn't count against the
+// concurrent queries quota until it times out. The context of the query may be done, so the query is stopped
+// with a context of its own.
+func (e *cloudWatchExecutor) stopLogsQuery(logsClient cloudwatchlogsiface.CloudWatchLogsAPI, queryID *string) {
+	ctx, cancel := context.WithTimeout(context.Background(), stopQueryTimeout)
+	defer cancel()
+
+	params := simplejson.NewFromAny(map[string]interface{}{"queryId": aws.StringValue(queryID)})
+	if _, err := e.executeStopQuery(ctx, logsClient, params); err != nil {
+		plog.Warn("Failed to stop query", "queryId", aws.StringValue(queryID), "err", err)
+	}
+}
+
 func (e *cloudWatchExecutor) handleStopQuery(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
 	parameters *simplejson.Json) (*data.Frame, error) {
 	response, err := e.executeStopQuery(ctx, logsClient, parameters)
@@ -11,10 +11,12 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -560,3 +562,74 @@ func TestQuery_GetQueryResults(t *testing.T) {
 	},
 	}, resp)
 }
+
+func TestQuery_AlertQuery(t *testing.T) {
+	origNewCWLogsClient := NewCWLogsClient
+	origNewQuotasClient := newQuotasClient
+	t.Cleanup(func() {
+		NewCWLogsClient = origNewCWLogsClient
+		newQuotasClient = origNewQuotasClient
+	})
+
+	var cli FakeCWLogsClient
+
+	NewCWLogsClient = func(sess *session.Session) cloudwatchlogsiface.CloudWatchLogsAPI {
+		return cli
+	}
+	newQuotasClient = func(sess *session.Session) servicequotasiface.ServiceQuotasAPI {
+		return fakeServiceQuotasClient{concurrentQueries: 10}
+	}
+
+	im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return datasourceInfo{region: "us-east-1", datasourceID: 1}, nil
+	})
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
+	}
+
+	t.Run("Should limit the concurrent queries to the logs_max_concurrent_queries setting", func(t *testing.T) {
+		cfg := newTestConfig()
+		cfg.AWSLogsMaxConcurrentQueries = 2
+		executor := newExecutor(&LogsService{queues: map[string]*logsQueryQueue{}}, im, cfg, fakeSessionCache{})
+
+		queue, err := executor.getQueue("default", pluginCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, queue.limit)
+
+		sameQueue, err := executor.getQueue("us-east-1", pluginCtx)
+		require.NoError(t, err)
+		assert.Same(t, queue, sameQueue)
+
+		cfg.AWSLogsMaxConcurrentQueries = 0
+		executor = newExecutor(&LogsService{queues: map[string]*logsQueryQueue{}}, im, cfg, fakeSessionCache{})
+		queue, err = executor.getQueue("us-east-1", pluginCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 10, queue.limit)
+	})
+
+	t.Run("Should stop the query when the context is done", func(t *testing.T) {
+		stoppedQueries := []string{}
+		cli = FakeCWLogsClient{
+			queryResults: cloudwatchlogs.GetQueryResultsOutput{
+				Status: aws.String("Running"),
+			},
+			stoppedQueries: &stoppedQueries,
+		}
+		executor := newExecutor(&LogsService{queues: map[string]*logsQueryQueue{}}, im, newTestConfig(), fakeSessionCache{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := executor.alertQuery(ctx, cli, backend.DataQuery{
+			TimeRange: backend.TimeRange{From: time.Unix(1584700643, 0), To: time.Unix(1584873443, 0)},
+		}, simplejson.NewFromAny(map[string]interface{}{
+			"region":      "default",
+			"queryString": "fields @message",
+		}), pluginCtx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"abcd-efgh-ijkl-mnop"}, stoppedQueries)
+
+		queue, err := executor.getQueue("us-east-1", pluginCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, queue.running)
+	})
+}
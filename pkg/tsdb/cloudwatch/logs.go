@@ -17,7 +17,7 @@ type LogsService struct {
 	channelMu sync.Mutex
 	// nolint:staticcheck // plugins.DataQueryResult deprecated
 	responseChannels map[string]chan *backend.QueryDataResponse
-	queues           map[string](chan bool)
+	queues           map[string]*logsQueryQueue
 	queueLock        sync.Mutex
 }
 
@@ -25,7 +25,7 @@ type LogsService struct {
 func (s *LogsService) Init() error {
 	// nolint:staticcheck // plugins.DataQueryResult deprecated
 	s.responseChannels = make(map[string]chan *backend.QueryDataResponse)
-	s.queues = make(map[string](chan bool))
+	s.queues = make(map[string]*logsQueryQueue)
 	return nil
 }
 
@@ -0,0 +1,110 @@
+package cloudwatch
+
+import (
+	"context"
+	"sync"
+)
+
+// logsQueryQueue limits the number of running CloudWatch Logs Insights queries of a data source in a region.
+// Queries over the limit wait for a running query to finish, and the waiting queries of the users are started
+// in turn so that a user running many queries doesn't delay the queries of the other users.
+type logsQueryQueue struct {
+	mu      sync.Mutex
+	limit   int
+	running int
+	// waiting are the waiting queries by user, in order of arrival.
+	waiting map[string][]chan struct{}
+	// users are the users with waiting queries, the next query started is the first query of the first user.
+	users []string
+}
+
+func newLogsQueryQueue(limit int) *logsQueryQueue {
+	if limit < 1 {
+		limit = 1
+	}
+	return &logsQueryQueue{
+		limit:   limit,
+		waiting: make(map[string][]chan struct{}),
+	}
+}
+
+// acquire waits until a query of the user can be started, or the context is done. Every successful acquire must
+// be followed by a release once the query is finished.
+func (q *logsQueryQueue) acquire(ctx context.Context, user string) error {
+	q.mu.Lock()
+	if q.running < q.limit && len(q.users) == 0 {
+		q.running++
+		q.mu.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	if _, ok := q.waiting[user]; !ok {
+		q.users = append(q.users, user)
+	}
+	q.waiting[user] = append(q.waiting[user], ready)
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		select {
+		case <-ready:
+			// the query was started while the context was done, hands its slot to the next query
+			q.next()
+		default:
+			q.remove(user, ready)
+		}
+		return ctx.Err()
+	}
+}
+
+// release hands the slot of a finished query to the next waiting query.
+func (q *logsQueryQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.next()
+}
+
+func (q *logsQueryQueue) next() {
+	if len(q.users) == 0 {
+		q.running--
+		return
+	}
+
+	user := q.users[0]
+	waiting := q.waiting[user]
+	q.users = q.users[1:]
+	if len(waiting) == 1 {
+		delete(q.waiting, user)
+	} else {
+		q.waiting[user] = waiting[1:]
+		q.users = append(q.users, user)
+	}
+	close(waiting[0])
+}
+
+func (q *logsQueryQueue) remove(user string, ready chan struct{}) {
+	waiting := q.waiting[user]
+	for i, w := range waiting {
+		if w == ready {
+			waiting = append(waiting[:i], waiting[i+1:]...)
+			break
+		}
+	}
+	if len(waiting) > 0 {
+		q.waiting[user] = waiting
+		return
+	}
+
+	delete(q.waiting, user)
+	for i, u := range q.users {
+		if u == user {
+			q.users = append(q.users[:i], q.users[i+1:]...)
+			break
+		}
+	}
+}
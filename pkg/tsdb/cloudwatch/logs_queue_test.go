@@ -0,0 +1,87 @@
+package cloudwatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsQueryQueue(t *testing.T) {
+	// enqueue queues a query of the user, and sends the user to started once the query is started.
+	enqueue := func(t *testing.T, q *logsQueryQueue, ctx context.Context, user string, started chan<- string) {
+		t.Helper()
+
+		q.mu.Lock()
+		waiting := len(q.waiting[user])
+		q.mu.Unlock()
+		go func() {
+			if err := q.acquire(ctx, user); err == nil {
+				started <- user
+			}
+		}()
+		// the queries are queued one after the other so that their order is known
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return len(q.waiting[user]) > waiting
+		}, time.Second, time.Millisecond)
+	}
+
+	t.Run("Should start no more queries than the limit", func(t *testing.T) {
+		q := newLogsQueryQueue(2)
+		require.NoError(t, q.acquire(context.Background(), "a"))
+		require.NoError(t, q.acquire(context.Background(), "a"))
+
+		started := make(chan string, 1)
+		enqueue(t, q, context.Background(), "b", started)
+		assert.Len(t, started, 0)
+
+		q.release()
+		assert.Equal(t, "b", <-started)
+		assert.Equal(t, 2, q.running)
+
+		q.release()
+		q.release()
+		assert.Equal(t, 0, q.running)
+	})
+
+	t.Run("Should start the waiting queries of the users in turn", func(t *testing.T) {
+		q := newLogsQueryQueue(1)
+		require.NoError(t, q.acquire(context.Background(), "a"))
+
+		started := make(chan string, 5)
+		for _, user := range []string{"a", "a", "a", "b", "c"} {
+			enqueue(t, q, context.Background(), user, started)
+		}
+
+		order := []string{}
+		for i := 0; i < 5; i++ {
+			q.release()
+			order = append(order, <-started)
+		}
+		assert.Equal(t, []string{"a", "b", "c", "a", "a"}, order)
+		assert.Empty(t, q.users)
+		assert.Empty(t, q.waiting)
+	})
+
+	t.Run("Should dequeue the waiting queries whose context is done", func(t *testing.T) {
+		q := newLogsQueryQueue(1)
+		require.NoError(t, q.acquire(context.Background(), "a"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := q.acquire(ctx, "b")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, q.users)
+		assert.Empty(t, q.waiting)
+
+		started := make(chan string, 1)
+		enqueue(t, q, context.Background(), "c", started)
+		q.release()
+		assert.Equal(t, "c", <-started)
+		assert.Equal(t, 1, q.running)
+	})
+}
@@ -38,6 +38,9 @@ func (e *cloudWatchExecutor) buildMetricDataQuery(query *cloudWatchQuery) (*clou
 					})
 			}
 			mdq.MetricStat.Stat = aws.String(query.Stats)
+			if isSingleAccount(query.AccountId) {
+				mdq.AccountId = aws.String(query.AccountId)
+			}
 		}
 	}
 
@@ -87,6 +90,10 @@ func buildSearchExpression(query *cloudWatchQuery, stat string) string {
 		searchTerm = appendSearch(searchTerm, keyFilter)
 	}
 
+	if isSingleAccount(query.AccountId) {
+		searchTerm = appendSearch(searchTerm, fmt.Sprintf(`:aws.AccountId="%s"`, query.AccountId))
+	}
+
 	if query.MatchExact {
 		schema := fmt.Sprintf("%q", query.Namespace)
 		if len(dimensionNames) > 0 {
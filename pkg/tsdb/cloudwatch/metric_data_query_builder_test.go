@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetricDataQueryBuilder_buildSearchExpression(t *testing.T) {
@@ -212,3 +213,61 @@ func TestMetricDataQueryBuilder_buildSearchExpression(t *testing.T) {
 		assert.Contains(t, res, `lb4\"\"`, "Expected escape double quotes")
 	})
 }
+
+func TestMetricDataQueryBuilder_accountId(t *testing.T) {
+	executor := newExecutor(nil, nil, newTestConfig(), fakeSessionCache{})
+
+	t.Run("Metric stat query is restricted to its account", func(t *testing.T) {
+		query := &cloudWatchQuery{
+			Namespace:  "AWS/EC2",
+			MetricName: "CPUUtilization",
+			Dimensions: map[string][]string{
+				"InstanceId": {"i-123"},
+			},
+			Stats:      "Average",
+			Period:     300,
+			MatchExact: true,
+			AccountId:  "123456789012",
+		}
+
+		mdq, err := executor.buildMetricDataQuery(query)
+		require.NoError(t, err)
+		require.NotNil(t, mdq.AccountId)
+		assert.Equal(t, "123456789012", *mdq.AccountId)
+		assert.Nil(t, mdq.Expression)
+	})
+
+	t.Run("Metric stat query of all accounts has no account", func(t *testing.T) {
+		query := &cloudWatchQuery{
+			Namespace:  "AWS/EC2",
+			MetricName: "CPUUtilization",
+			Stats:      "Average",
+			Period:     300,
+			MatchExact: true,
+			AccountId:  allAccounts,
+		}
+
+		mdq, err := executor.buildMetricDataQuery(query)
+		require.NoError(t, err)
+		assert.Nil(t, mdq.AccountId)
+	})
+
+	t.Run("Search expression is filtered by account", func(t *testing.T) {
+		query := &cloudWatchQuery{
+			Namespace:  "AWS/EC2",
+			MetricName: "CPUUtilization",
+			Dimensions: map[string][]string{
+				"InstanceId": {"*"},
+			},
+			Stats:      "Average",
+			Period:     300,
+			MatchExact: true,
+			AccountId:  "123456789012",
+		}
+
+		mdq, err := executor.buildMetricDataQuery(query)
+		require.NoError(t, err)
+		assert.Nil(t, mdq.AccountId)
+		assert.Equal(t, `REMOVE_EMPTY(SEARCH('{"AWS/EC2","InstanceId"} MetricName="CPUUtilization" :aws.AccountId="123456789012"', 'Average', 300))`, *mdq.Expression)
+	})
+}
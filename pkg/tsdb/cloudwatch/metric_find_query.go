@@ -446,6 +446,7 @@ func (e *cloudWatchExecutor) handleGetDimensionValues(ctx context.Context, param
 	metricName := parameters.Get("metricName").MustString()
 	dimensionKey := parameters.Get("dimensionKey").MustString()
 	dimensionsJson := parameters.Get("dimensions").MustMap()
+	accountId := parameters.Get("accountId").MustString()
 
 	var dimensions []*cloudwatch.DimensionFilter
 	for k, v := range dimensionsJson {
@@ -471,6 +472,12 @@ func (e *cloudWatchExecutor) handleGetDimensionValues(ctx context.Context, param
 	if metricName != "" {
 		params.MetricName = aws.String(metricName)
 	}
+	if accountId != "" {
+		params.IncludeLinkedAccounts = aws.Bool(true)
+		if isSingleAccount(accountId) {
+			params.OwningAccount = aws.String(accountId)
+		}
+	}
 	metrics, err := e.listMetrics(region, params, pluginCtx)
 	if err != nil {
 		return nil, err
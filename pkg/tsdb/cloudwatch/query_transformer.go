@@ -47,6 +47,7 @@ func (e *cloudWatchExecutor) transformRequestQueriesToCloudWatchQueries(requestQ
 				Expression: requestQuery.Expression,
 				ReturnData: requestQuery.ReturnData,
 				MatchExact: requestQuery.MatchExact,
+				AccountId:  requestQuery.AccountId,
 			}
 			cloudwatchQueries[id] = query
 		}
@@ -191,10 +192,14 @@ func buildDeepLink(refID string, requestQueries []*requestQuery, executedQueries
 			for dimensionKey, dimensionValues := range requestQuery.Dimensions {
 				metricStat = append(metricStat, dimensionKey, dimensionValues[0])
 			}
-			metricStat = append(metricStat, &metricStatMeta{
+			meta := &metricStatMeta{
 				Stat:   *stat,
 				Period: requestQuery.Period,
-			})
+			}
+			if isSingleAccount(requestQuery.AccountId) {
+				meta.AccountId = requestQuery.AccountId
+			}
+			metricStat = append(metricStat, meta)
 			metricItems = append(metricItems, metricStat)
 		}
 		cloudWatchLinkProps.Metrics = metricItems
@@ -32,11 +32,13 @@ func TestRequestParser(t *testing.T) {
 			"statistics": []interface{}{"Average"},
 			"period":     "600",
 			"hide":       false,
+			"accountId":  "123456789012",
 		})
 
 		res, err := parseRequestQuery(query, "ref1", from, to)
 		require.NoError(t, err)
 		assert.Equal(t, "us-east-1", res.Region)
+		assert.Equal(t, "123456789012", res.AccountId)
 		assert.Equal(t, "ref1", res.RefId)
 		assert.Equal(t, "ec2", res.Namespace)
 		assert.Equal(t, "CPUUtilization", res.MetricName)
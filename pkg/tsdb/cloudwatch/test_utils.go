@@ -12,8 +12,12 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/oam"
+	"github.com/aws/aws-sdk-go/service/oam/oamiface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -23,6 +27,8 @@ type FakeCWLogsClient struct {
 	logGroups      cloudwatchlogs.DescribeLogGroupsOutput
 	logGroupFields cloudwatchlogs.GetLogGroupFieldsOutput
 	queryResults   cloudwatchlogs.GetQueryResultsOutput
+	// stoppedQueries records the IDs of the stopped queries when set.
+	stoppedQueries *[]string
 }
 
 func (m FakeCWLogsClient) GetQueryResultsWithContext(ctx context.Context, input *cloudwatchlogs.GetQueryResultsInput, option ...request.Option) (*cloudwatchlogs.GetQueryResultsOutput, error) {
@@ -36,6 +42,9 @@ func (m FakeCWLogsClient) StartQueryWithContext(ctx context.Context, input *clou
 }
 
 func (m FakeCWLogsClient) StopQueryWithContext(ctx context.Context, input *cloudwatchlogs.StopQueryInput, option ...request.Option) (*cloudwatchlogs.StopQueryOutput, error) {
+	if m.stoppedQueries != nil {
+		*m.stoppedQueries = append(*m.stoppedQueries, aws.StringValue(input.QueryId))
+	}
 	return &cloudwatchlogs.StopQueryOutput{
 		Success: aws.Bool(true),
 	}, nil
@@ -138,6 +147,42 @@ func (c fakeRGTAClient) GetResourcesPages(in *resourcegroupstaggingapi.GetResour
 	return nil
 }
 
+type fakeOAMClient struct {
+	oamiface.OAMAPI
+
+	sinks []*oam.ListSinksItem
+	links []*oam.ListAttachedLinksItem
+}
+
+func (c fakeOAMClient) ListSinks(*oam.ListSinksInput) (*oam.ListSinksOutput, error) {
+	return &oam.ListSinksOutput{
+		Items: c.sinks,
+	}, nil
+}
+
+func (c fakeOAMClient) ListAttachedLinksPages(in *oam.ListAttachedLinksInput,
+	fn func(*oam.ListAttachedLinksOutput, bool) bool) error {
+	for i, link := range c.links {
+		if !fn(&oam.ListAttachedLinksOutput{
+			Items: []*oam.ListAttachedLinksItem{link},
+		}, i+1 == len(c.links)) {
+			break
+		}
+	}
+	return nil
+}
+
+type fakeServiceQuotasClient struct {
+	servicequotasiface.ServiceQuotasAPI
+	concurrentQueries float64
+}
+
+func (c fakeServiceQuotasClient) GetServiceQuota(*servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error) {
+	return &servicequotas.GetServiceQuotaOutput{
+		Quota: &servicequotas.ServiceQuota{Value: aws.Float64(c.concurrentQueries)},
+	}, nil
+}
+
 func chunkSlice(slice []*cloudwatch.Metric, chunkSize int) [][]*cloudwatch.Metric {
 	var chunks [][]*cloudwatch.Metric
 	for {
@@ -38,6 +38,11 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 		return backend.NewQueryDataResponse(), nil
 	}
 
+	dsInfo, err := e.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
 	resultChan := make(chan *responseWrapper, len(req.Queries))
 	eg, ectx := errgroup.WithContext(ctx)
 	for r, q := range requestQueriesByRegion {
@@ -72,7 +77,8 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 				return err
 			}
 
-			mdo, err := e.executeRequest(ectx, client, metricDataInput)
+			mdo, err := e.metricDataBatcher.execute(ectx, newMetricDataBatchKey(dsInfo, region, metricDataInput), client,
+				metricDataInput)
 			if err != nil {
 				return err
 			}
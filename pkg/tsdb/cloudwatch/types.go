@@ -21,6 +21,7 @@ type requestQuery struct {
 	Period             int
 	Alias              string
 	MatchExact         bool
+	AccountId          string
 }
 
 type cloudwatchResponse struct {
@@ -62,6 +63,7 @@ type metricExpression struct {
 }
 
 type metricStatMeta struct {
-	Stat   string `json:"stat"`
-	Period int    `json:"period"`
+	Stat      string `json:"stat"`
+	Period    int    `json:"period"`
+	AccountId string `json:"accountId,omitempty"`
 }
@@ -28,6 +28,11 @@ func dataPluginQueryAdapter(pluginID string, handler backend.QueryDataHandler) p
 			if token := oauthtoken.GetCurrentOAuthToken(ctx, query.User); token != nil {
 				delete(query.Headers, "Authorization")
 				query.Headers["Authorization"] = fmt.Sprintf("%s %s", token.Type(), token.AccessToken)
+
+				delete(query.Headers, "X-ID-Token")
+				if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+					query.Headers["X-ID-Token"] = idToken
+				}
 			}
 		}
 
@@ -98,9 +98,7 @@ var NewClient = func(ctx context.Context, httpClientProvider httpclient.Provider
 		return nil, err
 	}
 
-	clientLog.Info("Creating new client", "version", version.String(), "timeField", timeField, "indices", strings.Join(indices, ", "))
-
-	return &baseClientImpl{
+	c := &baseClientImpl{
 		ctx:                ctx,
 		httpClientProvider: httpClientProvider,
 		ds:                 ds,
@@ -108,7 +106,17 @@ var NewClient = func(ctx context.Context, httpClientProvider httpclient.Provider
 		timeField:          timeField,
 		indices:            indices,
 		timeRange:          timeRange,
-	}, nil
+	}
+
+	if ds.JsonData.Get("resolveIndices").MustBool(false) && supportsResolveIndex(version) {
+		if err := c.resolveIndices(); err != nil {
+			clientLog.Warn("Failed to resolve indices, using index pattern", "indices", strings.Join(indices, ", "), "err", err)
+		}
+	}
+
+	clientLog.Info("Creating new client", "version", version.String(), "timeField", timeField, "indices", strings.Join(c.indices, ", "))
+
+	return c, nil
 }
 
 type baseClientImpl struct {
@@ -0,0 +1,111 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+const (
+	// resolveIndexMinVersion is the first version of Elasticsearch with the _resolve/index API.
+	resolveIndexMinVersion = "7.9.0"
+	// resolvedIndicesCacheTTL is how long the indices of an index expression are cached, so that
+	// the indices created by rollovers are queried soon after their creation.
+	resolvedIndicesCacheTTL = time.Minute
+)
+
+var resolvedIndicesCache = localcache.New(resolvedIndicesCacheTTL, 2*resolvedIndicesCacheTTL)
+
+type resolveIndexResponse struct {
+	Indices []struct {
+		Name string `json:"name"`
+	} `json:"indices"`
+	Aliases []struct {
+		Name    string   `json:"name"`
+		Indices []string `json:"indices"`
+	} `json:"aliases"`
+	DataStreams []struct {
+		Name           string   `json:"name"`
+		BackingIndices []string `json:"backing_indices"`
+	} `json:"data_streams"`
+}
+
+// concreteIndices returns the names of the indices of a _resolve/index response: the matching indices, and the
+// indices behind the matching aliases and data streams.
+func (r *resolveIndexResponse) concreteIndices() []string {
+	names := map[string]struct{}{}
+	for _, index := range r.Indices {
+		names[index.Name] = struct{}{}
+	}
+	for _, alias := range r.Aliases {
+		for _, index := range alias.Indices {
+			names[index] = struct{}{}
+		}
+	}
+	for _, dataStream := range r.DataStreams {
+		for _, index := range dataStream.BackingIndices {
+			names[index] = struct{}{}
+		}
+	}
+
+	indices := make([]string, 0, len(names))
+	for name := range names {
+		indices = append(indices, name)
+	}
+	sort.Strings(indices)
+	return indices
+}
+
+// supportsResolveIndex returns whether the _resolve/index API is available in an Elasticsearch version.
+func supportsResolveIndex(version *semver.Version) bool {
+	minVersion, _ := semver.NewVersion(resolveIndexMinVersion)
+	return !version.LessThan(minVersion)
+}
+
+// resolveIndices resolves the indices of the index pattern, which may be data streams, aliases or wildcards,
+// to the indices holding their documents. Resolved indices are cached per data source version, and the
+// indices of the index pattern are kept when none of them exist.
+func (c *baseClientImpl) resolveIndices() error {
+	expression := strings.Join(c.indices, ",")
+	cacheKey := fmt.Sprintf("%d/%d/%s", c.ds.Id, c.ds.Version, expression)
+	if cached, ok := resolvedIndicesCache.Get(cacheKey); ok {
+		c.indices = cached.([]string)
+		return nil
+	}
+
+	clientRes, err := c.executeRequest(http.MethodGet, "_resolve/index/"+expression, "expand_wildcards=open", nil)
+	if err != nil {
+		return err
+	}
+	res := clientRes.httpResponse
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			clientLog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode == http.StatusNotFound {
+		// none of the indices exist yet
+		resolvedIndicesCache.Set(cacheKey, c.indices, resolvedIndicesCacheTTL)
+		return nil
+	}
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to resolve indices %s: %s", expression, res.Status)
+	}
+
+	var rir resolveIndexResponse
+	if err := json.NewDecoder(res.Body).Decode(&rir); err != nil {
+		return err
+	}
+	if indices := rir.concreteIndices(); len(indices) > 0 {
+		c.indices = indices
+	}
+	resolvedIndicesCache.Set(cacheKey, c.indices, resolvedIndicesCacheTTL)
+	return nil
+}
@@ -0,0 +1,81 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsResolveIndex(t *testing.T) {
+	for version, supported := range map[string]bool{"7.0.0": false, "7.8.1": false, "7.9.0": true, "7.10.2": true} {
+		v, err := semver.NewVersion(version)
+		require.NoError(t, err)
+		assert.Equal(t, supported, supportsResolveIndex(v), version)
+	}
+}
+
+func TestResolveIndices(t *testing.T) {
+	resolveScenario := func(t *testing.T, dsID int64, database string, status int, body string) (Client, []string) {
+		t.Helper()
+
+		paths := []string{}
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(status)
+			_, err := rw.Write([]byte(body))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		ds := &models.DataSource{
+			Id:       dsID,
+			Url:      ts.URL,
+			Database: database,
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"esVersion":      "7.10.0",
+				"timeField":      "@timestamp",
+				"resolveIndices": true,
+			}),
+		}
+		c, err := NewClient(context.Background(), httpclient.NewProvider(), ds, plugins.DataTimeRange{})
+		require.NoError(t, err)
+		return c, paths
+	}
+
+	t.Run("Should query the indices of the data streams, aliases and indices", func(t *testing.T) {
+		c, paths := resolveScenario(t, 1, "logs-*,metrics", http.StatusOK, `{
+			"indices": [{ "name": "logs-2021.05", "attributes": ["open"] }],
+			"aliases": [{ "name": "metrics", "indices": ["metrics-000002", "metrics-000001"] }],
+			"data_streams": [{ "name": "logs-app", "backing_indices": [".ds-logs-app-000001", "logs-2021.05"], "timestamp_field": "@timestamp" }]
+		}`)
+
+		assert.Equal(t, []string{"/_resolve/index/logs-*,metrics?expand_wildcards=open"}, paths)
+		assert.Equal(t, []string{".ds-logs-app-000001", "logs-2021.05", "metrics-000001", "metrics-000002"}, c.(*baseClientImpl).indices)
+
+		c, _ = resolveScenario(t, 1, "logs-*,metrics", http.StatusOK, `{}`)
+		assert.Equal(t, []string{".ds-logs-app-000001", "logs-2021.05", "metrics-000001", "metrics-000002"}, c.(*baseClientImpl).indices)
+	})
+
+	t.Run("Should keep the index pattern when nothing matches", func(t *testing.T) {
+		c, _ := resolveScenario(t, 2, "logs-*", http.StatusOK, `{ "indices": [], "aliases": [], "data_streams": [] }`)
+		assert.Equal(t, []string{"logs-*"}, c.(*baseClientImpl).indices)
+
+		c, _ = resolveScenario(t, 3, "logs", http.StatusNotFound, `{ "error": { "type": "index_not_found_exception" }, "status": 404 }`)
+		assert.Equal(t, []string{"logs"}, c.(*baseClientImpl).indices)
+	})
+
+	t.Run("Should keep the index pattern when the indices can't be resolved", func(t *testing.T) {
+		c, _ := resolveScenario(t, 4, "logs-*", http.StatusForbidden, `{ "status": 403 }`)
+		assert.Equal(t, []string{"logs-*"}, c.(*baseClientImpl).indices)
+	})
+}
@@ -1,6 +1,7 @@
 package es
 
 import (
+	"math"
 	"strings"
 
 	"github.com/Masterminds/semver"
@@ -100,6 +101,26 @@ func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuil
 	return b
 }
 
+// Tags around the matches of the query in the highlights, where the frontend looks for them in the log lines
+const (
+	HighlightPreTag  = "@HIGHLIGHT@"
+	HighlightPostTag = "@/HIGHLIGHT@"
+)
+
+// AddHighlight adds the highlight of the matches of the query in all the fields to the search request
+func (b *SearchRequestBuilder) AddHighlight() *SearchRequestBuilder {
+	b.customProps["highlight"] = map[string]interface{}{
+		"fields": map[string]interface{}{
+			"*": map[string]interface{}{},
+		},
+		"pre_tags":      []string{HighlightPreTag},
+		"post_tags":     []string{HighlightPostTag},
+		"fragment_size": math.MaxInt32,
+	}
+
+	return b
+}
+
 // Query creates and return a query builder
 func (b *SearchRequestBuilder) Query() *QueryBuilder {
 	if b.queryBuilder == nil {
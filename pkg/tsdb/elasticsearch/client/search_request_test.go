@@ -2,6 +2,7 @@ package es
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -144,6 +145,28 @@ func TestSearchRequest(t *testing.T) {
 				})
 			})
 
+			Convey("When adding highlight", func() {
+				b.AddHighlight()
+
+				Convey("When building search request", func() {
+					sr, err := b.Build()
+					So(err, ShouldBeNil)
+
+					Convey("When marshal to JSON should generate correct json", func() {
+						body, err := json.Marshal(sr)
+						So(err, ShouldBeNil)
+						json, err := simplejson.NewJson(body)
+						So(err, ShouldBeNil)
+
+						highlight := json.Get("highlight")
+						So(highlight.GetPath("fields", "*").Interface(), ShouldResemble, map[string]interface{}{})
+						So(highlight.Get("pre_tags").MustStringArray(), ShouldResemble, []string{HighlightPreTag})
+						So(highlight.Get("post_tags").MustStringArray(), ShouldResemble, []string{HighlightPostTag})
+						So(highlight.Get("fragment_size").MustInt(), ShouldEqual, math.MaxInt32)
+					})
+				})
+			})
+
 			Convey("and adding multiple top level aggs", func() {
 				aggBuilder := b.Agg()
 				aggBuilder.Terms("1", "@hostname", nil)
@@ -52,6 +52,8 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 }
 
 var extendedStats = map[string]string{
@@ -84,6 +86,12 @@ var scriptableAggType = map[string]string{
 	"bucket_script":  "bucket_script",
 }
 
+var documentMetricType = map[string]string{
+	"raw_document": "raw_document",
+	"raw_data":     "raw_data",
+	"logs":         "logs",
+}
+
 var pipelineAggWithMultipleBucketPathsType = map[string]string{
 	"bucket_script": "bucket_script",
 }
@@ -95,6 +103,14 @@ func isPipelineAgg(metricType string) bool {
 	return false
 }
 
+// isDocumentMetric returns true if the metric type queries the documents themselves instead of aggregating them
+func isDocumentMetric(metricType string) bool {
+	if _, ok := documentMetricType[metricType]; ok {
+		return true
+	}
+	return false
+}
+
 func isMetricAggregationWithInlineScriptSupport(metricType string) bool {
 	if _, ok := scriptableAggType[metricType]; ok {
 		return true
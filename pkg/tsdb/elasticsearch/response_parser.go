@@ -20,6 +20,9 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -81,7 +81,7 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	}
 
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || !isDocumentMetric(q.Metrics[0].Type) {
 			result.Results[q.RefID] = plugins.DataQueryResult{
 				RefID:       q.RefID,
 				Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
@@ -89,10 +89,27 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 			}
 			return nil
 		}
+	}
+
+	if len(q.Metrics) > 0 && isDocumentMetric(q.Metrics[0].Type) {
 		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+		timeField := e.client.GetTimeField()
+		b.SortDesc(timeField, "boolean")
+		b.AddDocValueField(timeField)
+		if metric.Type != logsType {
+			b.Size(intSetting(metric.Settings, "size", 500))
+			return nil
+		}
+
+		// the logs are queried with the histogram of their count, like in the frontend
+		b.Size(intSetting(metric.Settings, "limit", 500))
+		b.AddHighlight()
+		addDateHistogramAgg(b.Agg(), &BucketAgg{
+			ID:       "2",
+			Type:     dateHistType,
+			Field:    timeField,
+			Settings: simplejson.NewFromAny(map[string]interface{}{"interval": "auto"}),
+		}, from, to)
 		return nil
 	}
 
@@ -103,6 +120,10 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 		bucketAgg.Settings = simplejson.NewFromAny(
 			bucketAgg.generateSettingsForDSL(),
 		)
+		// the date histograms of the query editor without field are on the time field of the data source
+		if bucketAgg.Type == dateHistType && bucketAgg.Field == "" {
+			bucketAgg.Field = e.client.GetTimeField()
+		}
 		switch bucketAgg.Type {
 		case dateHistType:
 			aggBuilder = addDateHistogramAgg(aggBuilder, bucketAgg, from, to)
@@ -193,6 +214,19 @@ func setFloatPath(settings *simplejson.Json, path ...string) {
 	}
 }
 
+// intSetting returns the positive integer of a setting set as a number or as a string, or else defaultValue.
+func intSetting(settings *simplejson.Json, key string, defaultValue int) int {
+	if value, err := settings.Get(key).Int(); err == nil && value > 0 {
+		return value
+	}
+	if stringValue, err := settings.Get(key).String(); err == nil {
+		if value, err := strconv.Atoi(stringValue); err == nil && value > 0 {
+			return value
+		}
+	}
+	return defaultValue
+}
+
 func setIntPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseInt(stringValue, 10, 64); err == nil {
@@ -203,6 +237,13 @@ func setIntPath(settings *simplejson.Json, path ...string) {
 
 // Casts values to float when required by Elastic's query DSL
 func (metricAggregation MetricAgg) generateSettingsForDSL(version *semver.Version) map[string]interface{} {
+	// the query editor leaves the settings it unsets as null
+	for key, value := range metricAggregation.Settings.MustMap() {
+		if value == nil {
+			metricAggregation.Settings.Del(key)
+		}
+	}
+
 	switch metricAggregation.Type {
 	case "moving_avg":
 		setFloatPath(metricAggregation.Settings, "window")
@@ -211,6 +252,9 @@ func (metricAggregation MetricAgg) generateSettingsForDSL(version *semver.Versio
 		setFloatPath(metricAggregation.Settings, "settings", "beta")
 		setFloatPath(metricAggregation.Settings, "settings", "gamma")
 		setFloatPath(metricAggregation.Settings, "settings", "period")
+	case "moving_fn":
+		setFloatPath(metricAggregation.Settings, "window")
+		setFloatPath(metricAggregation.Settings, "shift")
 	case "serial_diff":
 		setFloatPath(metricAggregation.Settings, "lag")
 	}
@@ -273,7 +317,7 @@ func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFro
 
 func addHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	aggBuilder.Histogram(bucketAgg.ID, bucketAgg.Field, func(a *es.HistogramAgg, b es.AggBuilder) {
-		a.Interval = bucketAgg.Settings.Get("interval").MustInt(1000)
+		a.Interval = intSetting(bucketAgg.Settings, "interval", 1000)
 		a.MinDocCount = bucketAgg.Settings.Get("min_doc_count").MustInt(0)
 
 		if missing, err := bucketAgg.Settings.Get("missing").Int(); err == nil {
@@ -304,6 +348,10 @@ func addTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, metrics []*Metr
 
 		if minDocCount, err := bucketAgg.Settings.Get("min_doc_count").Int(); err == nil {
 			a.MinDocCount = &minDocCount
+		} else if minDocCount, err := bucketAgg.Settings.Get("min_doc_count").String(); err == nil {
+			if minDocCount, err := strconv.Atoi(minDocCount); err == nil {
+				a.MinDocCount = &minDocCount
+			}
 		}
 		if missing, err := bucketAgg.Settings.Get("missing").String(); err == nil {
 			a.Missing = &missing
@@ -364,7 +412,7 @@ func addFiltersAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder
 
 func addGeoHashGridAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	aggBuilder.GeoHashGrid(bucketAgg.ID, bucketAgg.Field, func(a *es.GeoHashGridAggregation, b es.AggBuilder) {
-		a.Precision = bucketAgg.Settings.Get("precision").MustInt(3)
+		a.Precision = intSetting(bucketAgg.Settings, "precision", 3)
 		aggBuilder = b
 	})
 
@@ -454,6 +502,10 @@ func (p *timeSeriesQueryParser) parseMetrics(model *simplejson.Json) ([]*MetricA
 			return nil, err
 		}
 
+		// the query editor sets the metric of the pipeline aggregations as their field
+		if metric.PipelineAggregate == "" && isPipelineAgg(metric.Type) {
+			metric.PipelineAggregate = metric.Field
+		}
 		if isPipelineAggWithMultipleBucketPaths(metric.Type) {
 			metric.PipelineVariables = map[string]string{}
 			pvArr := metricJSON.Get("pipelineVariables").MustArray()
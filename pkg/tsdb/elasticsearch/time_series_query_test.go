@@ -379,6 +379,76 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(sr.Size, ShouldEqual, 1337)
 		})
 
+		Convey("With raw data metric size set as string", func() {
+			c := newFakeClient("5.0.0")
+			c.timeField = "timestamp"
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": "1337" }	}]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 1337)
+			So(sr.Sort["timestamp"], ShouldResemble, map[string]string{"order": "desc", "unmapped_type": "boolean"})
+			So(sr.CustomProps["docvalue_fields"], ShouldResemble, []string{"timestamp"})
+			So(sr.Aggs, ShouldBeEmpty)
+		})
+
+		Convey("With raw document metric and bucket aggs", func() {
+			c := newFakeClient("5.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{ "id": "1", "type": "raw_document", "settings": {}	}]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 500)
+			So(sr.Aggs, ShouldBeEmpty)
+		})
+
+		Convey("With logs metric", func() {
+			c := newFakeClient("7.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": "1000" }	}]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 1000)
+			So(sr.Sort["@timestamp"], ShouldResemble, map[string]string{"order": "desc", "unmapped_type": "boolean"})
+			highlight := sr.CustomProps["highlight"].(map[string]interface{})
+			So(highlight["pre_tags"], ShouldResemble, []string{es.HighlightPreTag})
+			So(highlight["post_tags"], ShouldResemble, []string{es.HighlightPostTag})
+
+			So(sr.Aggs, ShouldHaveLength, 1)
+			So(sr.Aggs[0].Key, ShouldEqual, "2")
+			dateHistogramAgg := sr.Aggs[0].Aggregation.Aggregation.(*es.DateHistogramAgg)
+			So(dateHistogramAgg.Field, ShouldEqual, "@timestamp")
+			So(dateHistogramAgg.Interval, ShouldEqual, "$__interval")
+			So(sr.Aggs[0].Aggregation.Aggs, ShouldBeEmpty)
+		})
+
+		Convey("With date histogram agg without field", func() {
+			c := newFakeClient("5.0.0")
+			c.timeField = "timestamp"
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "id": "2", "settings": { "interval": "auto" } }],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			dateHistogramAgg := sr.Aggs[0].Aggregation.Aggregation.(*es.DateHistogramAgg)
+			So(dateHistogramAgg.Field, ShouldEqual, "timestamp")
+		})
+
 		Convey("With date histogram agg", func() {
 			c := newFakeClient("5.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -457,6 +527,76 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(ghGridAgg.Precision, ShouldEqual, 3)
 		})
 
+		Convey("With histogram and geo hash grid aggs with settings set as strings", func() {
+			c := newFakeClient("5.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{
+						"id": "3",
+						"type": "histogram",
+						"field": "bytes",
+						"settings": { "interval": "10", "min_doc_count": 2 }
+					},
+					{
+						"id": "4",
+						"type": "geohash_grid",
+						"field": "@location",
+						"settings": { "precision": "6" }
+					},
+					{
+						"id": "5",
+						"type": "terms",
+						"field": "@host",
+						"settings": { "size": "5", "min_doc_count": "1" }
+					}
+				],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			firstLevel := sr.Aggs[0]
+			So(firstLevel.Aggregation.Aggregation.(*es.HistogramAgg).Interval, ShouldEqual, 10)
+			secondLevel := firstLevel.Aggregation.Aggs[0]
+			So(secondLevel.Aggregation.Aggregation.(*es.GeoHashGridAggregation).Precision, ShouldEqual, 6)
+			termsAgg := secondLevel.Aggregation.Aggs[0].Aggregation.Aggregation.(*es.TermsAggregation)
+			So(termsAgg.Size, ShouldEqual, 5)
+			So(*termsAgg.MinDocCount, ShouldEqual, 1)
+		})
+
+		Convey("With moving function", func() {
+			c := newFakeClient("7.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+				],
+				"metrics": [
+					{ "id": "3", "type": "sum", "field": "@value" },
+					{
+						"id": "2",
+						"type": "moving_fn",
+						"field": "3",
+						"settings": { "script": "MovingFunctions.unweightedAvg(values)", "window": "5", "shift": null }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			firstLevel := sr.Aggs[0]
+			movingFnAgg := firstLevel.Aggregation.Aggs[1]
+			So(movingFnAgg.Key, ShouldEqual, "2")
+			So(movingFnAgg.Aggregation.Type, ShouldEqual, "moving_fn")
+			plAgg := movingFnAgg.Aggregation.Aggregation.(*es.PipelineAggregation)
+			So(plAgg.BucketPath, ShouldEqual, "3")
+			So(plAgg.Settings, ShouldResemble, map[string]interface{}{
+				"script": "MovingFunctions.unweightedAvg(values)",
+				"window": 5.,
+			})
+		})
+
 		Convey("With moving average", func() {
 			c := newFakeClient("5.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -0,0 +1,257 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/registry"
+)
+
+const (
+	// functionsCacheTTL is the time the functions of a data source are cached for, they only change when
+	// Graphite is upgraded.
+	functionsCacheTTL = time.Hour
+	// tagsCacheTTL is the time the tag autocompletions of a data source are cached for.
+	tagsCacheTTL = time.Minute
+	// defaultGraphiteVersion is the version of the data sources without one, like in the frontend.
+	defaultGraphiteVersion = "1.1"
+)
+
+func init() {
+	registry.Register(&registry.Descriptor{
+		Name:         "GraphiteResourceService",
+		InitPriority: registry.Low,
+		Instance:     &ResourceService{},
+	})
+}
+
+// ResourceService serves the functions and tag autocomplete endpoints of the Graphite data sources as resources
+// of the data source, so that the query editor doesn't go through the data source proxy and the responses are
+// cached. The queries of the Graphite data sources are still run by the GraphiteExecutor.
+type ResourceService struct {
+	BackendPluginManager backendplugin.Manager `inject:""`
+	HTTPClientProvider   httpclient.Provider   `inject:""`
+
+	cache *localcache.CacheService
+}
+
+func (s *ResourceService) Init() error {
+	factory := coreplugin.New(backend.ServeOpts{
+		CallResourceHandler: s.resourceHandler(),
+	})
+	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "graphite", factory); err != nil {
+		glog.Error("Failed to register plugin", "error", err)
+	}
+	return nil
+}
+
+func (s *ResourceService) resourceHandler() backend.CallResourceHandler {
+	s.cache = localcache.New(tagsCacheTTL, 2*tagsCacheTTL)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/functions", s.handleResource)
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleResource)
+	mux.HandleFunc("/tags/autoComplete/values", s.handleResource)
+	return httpadapter.New(mux)
+}
+
+// resourceParams are the query parameters forwarded to Graphite by path.
+var resourceParams = map[string][]string{
+	"/functions":                {},
+	"/tags/autoComplete/tags":   {"expr", "tagPrefix", "limit", "from", "until"},
+	"/tags/autoComplete/values": {"expr", "tag", "valuePrefix", "limit", "from", "until"},
+}
+
+func (s *ResourceService) handleResource(rw http.ResponseWriter, req *http.Request) {
+	params, ok := resourceParams[req.URL.Path]
+	if !ok {
+		writeResourceError(rw, http.StatusNotFound, "unknown resource "+req.URL.Path)
+		return
+	}
+	if req.Method != http.MethodGet {
+		writeResourceError(rw, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	pCtx := httpadapter.PluginConfigFromContext(req.Context())
+	settings := pCtx.DataSourceInstanceSettings
+	if settings == nil {
+		writeResourceError(rw, http.StatusBadRequest, "missing data source")
+		return
+	}
+
+	dsQuery := models.GetDataSourceQuery{Id: settings.ID, OrgId: pCtx.OrgID}
+	if err := bus.Dispatch(&dsQuery); err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, "failed to get data source")
+		glog.Error("Failed to get data source", "id", settings.ID, "error", err)
+		return
+	}
+	ds := dsQuery.Result
+
+	// the function index and the tags were added in Graphite 1.1
+	version := graphiteVersion(ds)
+	if !versionAtLeast(version, "1.1") {
+		writeResourceError(rw, http.StatusNotFound, fmt.Sprintf("%s is not supported by Graphite %s", req.URL.Path, version))
+		return
+	}
+
+	query := scopeResourceQuery(params, req.URL.Query())
+	cacheKey := fmt.Sprintf("%d/%d%s?%s", ds.Id, ds.Updated.UnixNano(), req.URL.Path, query.Encode())
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		writeResourceResponse(rw, cached.(*resourceResponse))
+		return
+	}
+
+	resp, err := s.fetchResource(req.Context(), ds, req.URL.Path, query)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+	if resp.status == http.StatusOK && req.URL.Path == "/functions" {
+		resp, err = normalizeFunctions(resp)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+	}
+	if resp.status == http.StatusOK {
+		ttl := tagsCacheTTL
+		if req.URL.Path == "/functions" {
+			ttl = functionsCacheTTL
+		}
+		s.cache.Set(cacheKey, resp, ttl)
+	}
+	writeResourceResponse(rw, resp)
+}
+
+type resourceResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+func (s *ResourceService) fetchResource(ctx context.Context, ds *models.DataSource, path string, query url.Values) (*resourceResponse, error) {
+	transport, err := ds.GetHTTPTransport(s.HTTPClientProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(ds.Url, "/") + path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			glog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceResponse{status: resp.StatusCode, contentType: resp.Header.Get("Content-Type"), body: body}, nil
+}
+
+// scopeResourceQuery returns the parameters of the request forwarded to Graphite, sorted so that the requests
+// with the same parameters share their cached response.
+func scopeResourceQuery(params []string, values url.Values) url.Values {
+	query := url.Values{}
+	for _, name := range params {
+		v := append([]string(nil), values[name]...)
+		sort.Strings(v)
+		for _, value := range v {
+			query.Add(name, value)
+		}
+	}
+	return query
+}
+
+// infiniteDefault matches the infinite default values of the function parameters, which Graphite 1.1.7 writes
+// as invalid JSON: https://github.com/graphite-project/graphite-web/issues/2609
+var infiniteDefault = regexp.MustCompile(`"default": ?(-?)Infinity`)
+
+// normalizeFunctions returns the function index of Graphite as valid JSON, with the infinite default values
+// written as numbers that are parsed as infinite.
+func normalizeFunctions(resp *resourceResponse) (*resourceResponse, error) {
+	body := infiniteDefault.ReplaceAll(resp.body, []byte(`"default": ${1}1e9999`))
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("invalid functions response")
+	}
+	return &resourceResponse{status: resp.status, contentType: "application/json", body: body}, nil
+}
+
+func graphiteVersion(ds *models.DataSource) string {
+	if ds.JsonData == nil {
+		return defaultGraphiteVersion
+	}
+	return ds.JsonData.Get("graphiteVersion").MustString(defaultGraphiteVersion)
+}
+
+// versionAtLeast returns true if the version, like 1.1 or 1.1.x, is the minimum version or later.
+func versionAtLeast(version string, minimum string) bool {
+	v := strings.Split(version, ".")
+	m := strings.Split(minimum, ".")
+	for i := range m {
+		if i >= len(v) {
+			return false
+		}
+		vi, err := strconv.Atoi(v[i])
+		if err != nil {
+			// the x of 1.1.x
+			return true
+		}
+		mi, _ := strconv.Atoi(m[i])
+		if vi != mi {
+			return vi > mi
+		}
+	}
+	return true
+}
+
+func writeResourceResponse(rw http.ResponseWriter, resp *resourceResponse) {
+	if resp.contentType != "" {
+		rw.Header().Set("Content-Type", resp.contentType)
+	}
+	rw.WriteHeader(resp.status)
+	if _, err := rw.Write(resp.body); err != nil {
+		glog.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, msg string) {
+	body, err := json.Marshal(struct {
+		Message string `json:"message"`
+	}{Message: msg})
+	if err != nil {
+		glog.Error("Failed to marshal error", "error", err)
+	}
+	writeResourceResponse(rw, &resourceResponse{status: status, contentType: "application/json", body: body})
+}
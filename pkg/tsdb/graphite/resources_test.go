@@ -0,0 +1,130 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestResources(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch r.URL.Path {
+		case "/functions":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"limit":{"params":[{"name":"n","type":"integer","default": Infinity}]}}`))
+		case "/tags/autoComplete/tags":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`["name","server"]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	updated := time.Now()
+	bus.AddHandler("test", func(q *models.GetDataSourceQuery) error {
+		jsonData := simplejson.NewFromAny(map[string]interface{}{"graphiteVersion": "1.1"})
+		if q.Id == 20 {
+			jsonData = simplejson.NewFromAny(map[string]interface{}{"graphiteVersion": "1.0"})
+		}
+		q.Result = &models.DataSource{Id: q.Id, OrgId: q.OrgId, Url: srv.URL, JsonData: jsonData, Updated: updated}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	s := &ResourceService{HTTPClientProvider: httpclient.NewProvider()}
+	handler := s.resourceHandler()
+	call := func(datasourceID int64, resourceURL string) *backend.CallResourceResponse {
+		var resp *backend.CallResourceResponse
+		u, err := url.Parse(resourceURL)
+		require.NoError(t, err)
+		err = handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: datasourceID, Updated: updated},
+			},
+			Path:   u.Path,
+			Method: http.MethodGet,
+			URL:    resourceURL,
+		}, resourceResponseSender(func(r *backend.CallResourceResponse) {
+			resp = r
+		}))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("the functions are proxied as valid JSON and cached", func(t *testing.T) {
+		resp := call(10, "functions?format=treejson")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Equal(t, `{"limit":{"params":[{"name":"n","type":"integer","default": 1e9999}]}}`, string(resp.Body))
+		require.Len(t, requests, 1)
+		require.Equal(t, "/functions", requests[0].URL.Path)
+		require.Empty(t, requests[0].URL.RawQuery)
+
+		resp = call(10, "functions")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Len(t, requests, 1)
+	})
+
+	t.Run("the tag autocompletions are proxied with their parameters sorted", func(t *testing.T) {
+		resp := call(10, "tags/autoComplete/tags?expr=server%3Dweb&expr=name%3Dcpu&tagPrefix=s&other=1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["name","server"]`, string(resp.Body))
+		require.Len(t, requests, 2)
+		require.Equal(t, "/tags/autoComplete/tags", requests[1].URL.Path)
+		require.Equal(t, url.Values{"expr": {"name=cpu", "server=web"}, "tagPrefix": {"s"}}, requests[1].URL.Query())
+
+		resp = call(10, "tags/autoComplete/tags?expr=name%3Dcpu&expr=server%3Dweb&tagPrefix=s")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Len(t, requests, 2)
+	})
+
+	t.Run("the errors of Graphite aren't cached", func(t *testing.T) {
+		resp := call(10, "tags/autoComplete/values?tag=server")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+		resp = call(10, "tags/autoComplete/values?tag=server")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+		require.Len(t, requests, 4)
+	})
+
+	t.Run("the resources aren't served for the versions of Graphite without them", func(t *testing.T) {
+		resp := call(20, "functions")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+		require.Len(t, requests, 4)
+	})
+
+	t.Run("the other paths aren't served", func(t *testing.T) {
+		resp := call(10, "render")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+		require.Len(t, requests, 4)
+	})
+}
+
+type resourceResponseSender func(*backend.CallResourceResponse)
+
+func (f resourceResponseSender) Send(resp *backend.CallResourceResponse) error {
+	f(resp)
+	return nil
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	require.True(t, versionAtLeast("1.1", "1.1"))
+	require.True(t, versionAtLeast("1.1.x", "1.1"))
+	require.True(t, versionAtLeast("2.0", "1.1"))
+	require.False(t, versionAtLeast("1.0", "1.1"))
+	require.False(t, versionAtLeast("0.9", "1.1"))
+	require.False(t, versionAtLeast("1", "1.1"))
+}
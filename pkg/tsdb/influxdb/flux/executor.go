@@ -13,16 +13,12 @@ import (
 
 const maxPointsEnforceFactor float64 = 10
 
-// executeQuery runs a flux query using the queryModel to interpolate the query and the runner to execute it.
+// executeQuery runs a flux query using the queryModel to bind the macros of the query and the runner to execute it.
 // maxSeries somehow limits the response.
 func executeQuery(ctx context.Context, query queryModel, runner queryRunner, maxSeries int) (dr backend.DataResponse) {
 	dr = backend.DataResponse{}
 
-	flux, err := interpolate(query)
-	if err != nil {
-		dr.Error = err
-		return
-	}
+	flux := buildFlux(query)
 
 	glog.Debug("Executing Flux query", "flux", flux)
 
@@ -66,9 +62,16 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 	return dr
 }
 
+// readDataFrames decodes the records of the result into frames as they are streamed. The result is closed
+// when decoding stops early, so the rest of the response isn't read.
 func readDataFrames(result *api.QueryTableResult, maxPoints int, maxSeries int) (dr backend.DataResponse) {
 	glog.Debug("Reading data frames from query result", "maxPoints", maxPoints, "maxSeries", maxSeries)
 	dr = backend.DataResponse{}
+	defer func() {
+		if err := result.Close(); err != nil {
+			glog.Debug("Failed to close query result", "err", err)
+		}
+	}()
 
 	builder := &frameBuilder{
 		maxPoints: maxPoints,
@@ -161,7 +161,7 @@ func TestAggregateGrouping(t *testing.T) {
 			pointer.Float64(0.667),
 		}),
 	)
-	expectedFrame.Meta = &data.FrameMeta{}
+	expectedFrame.Meta = &data.FrameMeta{ExecutedQueryString: buildFlux(queryModel{MaxDataPoints: 100})}
 
 	diff := cmp.Diff(expectedFrame, dr.Frames[0], data.FrameTestCompareOptions()...)
 	assert.Empty(t, diff)
@@ -194,7 +194,7 @@ func TestNonStandardTimeColumn(t *testing.T) {
 			pointer.Float64(156.304),
 		}),
 	)
-	expectedFrame.Meta = &data.FrameMeta{}
+	expectedFrame.Meta = &data.FrameMeta{ExecutedQueryString: buildFlux(queryModel{MaxDataPoints: 100})}
 
 	diff := cmp.Diff(expectedFrame, dr.Frames[0], data.FrameTestCompareOptions()...)
 	assert.Empty(t, diff)
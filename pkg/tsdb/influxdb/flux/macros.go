@@ -1,39 +1,102 @@
 package flux
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
 
-const variableFilter = `(?m)([a-zA-Z]+)\.([a-zA-Z]+)`
-
-// interpolate processes macros
-func interpolate(query queryModel) (string, error) {
-	flux := query.RawQuery
-
-	variableFilterExp, err := regexp.Compile(variableFilter)
-	matches := variableFilterExp.FindAllStringSubmatch(flux, -1)
-	if matches != nil {
-		timeRange := query.TimeRange
-		from := timeRange.From.UTC().Format(time.RFC3339)
-		to := timeRange.To.UTC().Format(time.RFC3339)
-		for _, match := range matches {
-			switch match[2] {
-			case "timeRangeStart":
-				flux = strings.ReplaceAll(flux, match[0], from)
-			case "timeRangeStop":
-				flux = strings.ReplaceAll(flux, match[0], to)
-			case "windowPeriod":
-				flux = strings.ReplaceAll(flux, match[0], query.Interval.String())
-			case "bucket":
-				flux = strings.ReplaceAll(flux, match[0], "\""+query.Options.Bucket+"\"")
-			case "defaultBucket":
-				flux = strings.ReplaceAll(flux, match[0], "\""+query.Options.DefaultBucket+"\"")
-			case "organization":
-				flux = strings.ReplaceAll(flux, match[0], "\""+query.Options.Organization+"\"")
-			}
+// optionsRecord is the Flux option record the macros and the dashboard variables are bound to, like in the
+// InfluxDB UI: v.timeRangeStart, v.windowPeriod, v.myVariable...
+const optionsRecord = "v"
+
+// fluxIdentifier matches the variable names that can be used as keys of the options record.
+var fluxIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// fluxString escapes the strings, including the Flux string interpolations.
+var fluxString = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`)
+
+// buildFlux returns the Flux query with the macros and the dashboard variables bound to the options record. The
+// values are written as typed Flux literals instead of being interpolated into the query, so they can't change
+// the query.
+func buildFlux(query queryModel) string {
+	properties := []string{
+		"timeRangeStart: " + fluxTime(query.TimeRange.From),
+		"timeRangeStop: " + fluxTime(query.TimeRange.To),
+		"windowPeriod: " + fluxDuration(query.Interval),
+		"bucket: " + fluxStringLiteral(query.Options.Bucket),
+		"defaultBucket: " + fluxStringLiteral(query.Options.DefaultBucket),
+		"organization: " + fluxStringLiteral(query.Options.Organization),
+	}
+
+	names := make([]string, 0, len(query.Variables))
+	for name := range query.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !fluxIdentifier.MatchString(name) || isMacro(name) {
+			glog.Debug("Skipping variable that can't be bound to the Flux options", "name", name)
+			continue
+		}
+		properties = append(properties, name+": "+fluxValue(query.Variables[name]))
+	}
+
+	return fmt.Sprintf("option %s = {%s}\n\n%s", optionsRecord, strings.Join(properties, ", "), query.RawQuery)
+}
+
+func isMacro(name string) bool {
+	switch name {
+	case "timeRangeStart", "timeRangeStop", "windowPeriod", "bucket", "defaultBucket", "organization":
+		return true
+	}
+	return false
+}
+
+func fluxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// fluxDuration returns the duration as a Flux duration literal, like 1m30s, with a precision of a millisecond.
+func fluxDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return "0ms"
+	}
+
+	var sb strings.Builder
+	for _, unit := range []struct {
+		name     string
+		duration time.Duration
+	}{{"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}, {"ms", time.Millisecond}} {
+		if n := d / unit.duration; n > 0 {
+			sb.WriteString(fmt.Sprintf("%d%s", n, unit.name))
+			d -= n * unit.duration
+		}
+	}
+	return sb.String()
+}
+
+func fluxStringLiteral(s string) string {
+	return `"` + fluxString.Replace(s) + `"`
+}
+
+// fluxValue returns the value of a dashboard variable as a Flux literal, the multi-value variables are arrays of
+// strings.
+func fluxValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fluxStringLiteral(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			values = append(values, fluxStringLiteral(fmt.Sprint(e)))
 		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case nil:
+		return `""`
+	default:
+		return fluxStringLiteral(fmt.Sprint(v))
 	}
-	return flux, err
 }
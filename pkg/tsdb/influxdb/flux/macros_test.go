@@ -7,16 +7,12 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 )
 
-func TestInterpolate(t *testing.T) {
-	// Unix sec: 1500376552
-	// Unix ms:  1500376552001
-
+func TestBuildFlux(t *testing.T) {
 	timeRange := backend.TimeRange{
 		From: time.Unix(0, 0),
-		To:   time.Unix(0, 0),
+		To:   time.Unix(1500376552, 1000000),
 	}
 
 	options := queryOptions{
@@ -26,29 +22,50 @@ func TestInterpolate(t *testing.T) {
 	}
 
 	tests := []struct {
-		name   string
-		before string
-		after  string
+		name      string
+		variables map[string]interface{}
+		interval  time.Duration
+		after     string
 	}{
 		{
-			name:   "interpolate flux variables",
-			before: `v.timeRangeStart, something.timeRangeStop, XYZ.bucket, uuUUu.defaultBucket, aBcDefG.organization, window.windowPeriod, a91{}.bucket`,
-			after:  `1970-01-01T00:00:00Z, 1970-01-01T00:00:00Z, "grafana2", "grafana3", "grafana1", 1s, a91{}.bucket`,
+			name:     "bind the macros to the options record",
+			interval: time.Second,
+			after: `option v = {timeRangeStart: 1970-01-01T00:00:00Z, timeRangeStop: 2017-07-18T11:15:52.001Z, ` +
+				`windowPeriod: 1s, bucket: "grafana2", defaultBucket: "grafana3", organization: "grafana1"}`,
+		},
+		{
+			name:     "bind the dashboard variables as typed literals",
+			interval: 90*time.Second + 500*time.Millisecond,
+			variables: map[string]interface{}{
+				"host":          `web") |> drop() //`,
+				"region":        []interface{}{"eu", "us"},
+				"template":      "${v.organization}",
+				"invalid-name":  "skipped",
+				"timeRangeStop": "skipped",
+			},
+			after: `option v = {timeRangeStart: 1970-01-01T00:00:00Z, timeRangeStop: 2017-07-18T11:15:52.001Z, ` +
+				`windowPeriod: 1m30s500ms, bucket: "grafana2", defaultBucket: "grafana3", organization: "grafana1", ` +
+				`host: "web\") |> drop() //", region: ["eu", "us"], template: "\${v.organization}"}`,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			query := queryModel{
-				RawQuery:      tt.before,
+				RawQuery:      `from(bucket: v.bucket) |> range(start: v.timeRangeStart, stop: v.timeRangeStop)`,
 				Options:       options,
+				Variables:     tt.variables,
 				TimeRange:     timeRange,
 				MaxDataPoints: 1,
-				Interval:      1000 * 1000 * 1000,
+				Interval:      tt.interval,
 			}
-			interpolatedQuery, err := interpolate(query)
-			require.NoError(t, err)
-			diff := cmp.Diff(tt.after, interpolatedQuery)
+			diff := cmp.Diff(tt.after+"\n\n"+query.RawQuery, buildFlux(query))
 			assert.Equal(t, "", diff)
 		})
 	}
 }
+
+func TestFluxDuration(t *testing.T) {
+	assert.Equal(t, "0ms", fluxDuration(0))
+	assert.Equal(t, "1ms", fluxDuration(time.Millisecond))
+	assert.Equal(t, "2h5s", fluxDuration(2*time.Hour+5*time.Second))
+}
@@ -21,6 +21,8 @@ type queryOptions struct {
 type queryModel struct {
 	RawQuery string       `json:"query"`
 	Options  queryOptions `json:"options"`
+	// Variables are the values of the dashboard variables, strings or arrays of strings.
+	Variables map[string]interface{} `json:"variables"`
 
 	// Not from JSON
 	TimeRange     backend.TimeRange `json:"-"`
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +39,9 @@ func New(httpClientProvider httpclient.Provider) func(dsInfo *models.DataSource)
 	}
 }
 
+// defaultMaxLines is the line limit of the log queries of the data sources without one.
+const defaultMaxLines = 1000
+
 var (
 	plog         = log.New("tsdb.loki")
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
@@ -51,28 +55,11 @@ func (e *LokiExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		Results: map[string]plugins.DataQueryResult{},
 	}
 
-	tlsConfig, err := dsInfo.GetTLSConfig(e.httpClientProvider)
-	if err != nil {
-		return plugins.DataResponse{}, err
-	}
-
-	transport, err := dsInfo.GetHTTPTransport(e.httpClientProvider)
+	client, err := newClient(dsInfo, e.httpClientProvider)
 	if err != nil {
 		return plugins.DataResponse{}, err
 	}
 
-	client := &client.DefaultClient{
-		Address:  dsInfo.Url,
-		Username: dsInfo.BasicAuthUser,
-		Password: dsInfo.DecryptedBasicAuthPassword(),
-		TLSConfig: config.TLSConfig{
-			InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
-		},
-		Tripperware: func(t http.RoundTripper) http.RoundTripper {
-			return transport
-		},
-	}
-
 	queries, err := e.parseQuery(dsInfo, queryContext)
 	if err != nil {
 		return plugins.DataResponse{}, err
@@ -86,12 +73,14 @@ func (e *LokiExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		//Currently hard coded as not used - applies to log queries
-		limit := 1000
-		//Currently hard coded as not used - applies to queries which produce a stream response
-		interval := time.Second * 1
-
-		value, err := client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+		var value *loghttp.QueryResponse
+		if query.Instant {
+			value, err = client.Query(query.Expr, query.MaxLines, query.End, logproto.BACKWARD, false)
+		} else {
+			//Currently hard coded as not used - applies to queries which produce a stream response
+			interval := time.Second * 1
+			value, err = client.QueryRange(query.Expr, query.MaxLines, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+		}
 		if err != nil {
 			return plugins.DataResponse{}, err
 		}
@@ -100,12 +89,43 @@ func (e *LokiExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		if err != nil {
 			return plugins.DataResponse{}, err
 		}
+		if volume := logsVolumeQuery(query); volume != nil {
+			queryResult, err = addLogsVolume(client, volume, queryResult)
+			if err != nil {
+				return plugins.DataResponse{}, err
+			}
+		}
 		result.Results[query.RefID] = queryResult
 	}
 
 	return result, nil
 }
 
+// newClient returns a client of the Loki API of the data source.
+func newClient(dsInfo *models.DataSource, httpClientProvider httpclient.Provider) (*client.DefaultClient, error) {
+	tlsConfig, err := dsInfo.GetTLSConfig(httpClientProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := dsInfo.GetHTTPTransport(httpClientProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.DefaultClient{
+		Address:  dsInfo.Url,
+		Username: dsInfo.BasicAuthUser,
+		Password: dsInfo.DecryptedBasicAuthPassword(),
+		TLSConfig: config.TLSConfig{
+			InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
+		},
+		Tripperware: func(t http.RoundTripper) http.RoundTripper {
+			return transport
+		},
+	}, nil
+}
+
 //If legend (using of name or pattern instead of time series name) is used, use that name/pattern for formatting
 func formatLegend(metric model.Metric, query *lokiQuery) string {
 	if query.LegendFormat == "" {
@@ -153,6 +173,15 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 		interval := e.intervalCalculator.Calculate(*queryContext.TimeRange, dsInterval)
 		step := time.Duration(int64(interval.Value))
 
+		// the line limit of the query, or else of the data source, like in the frontend
+		maxLines := queryModel.Model.Get("maxLines").MustInt(0)
+		if maxLines <= 0 && dsInfo.JsonData != nil {
+			maxLines, _ = strconv.Atoi(dsInfo.JsonData.Get("maxLines").MustString(""))
+		}
+		if maxLines <= 0 {
+			maxLines = defaultMaxLines
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:         expr,
 			Step:         step,
@@ -160,6 +189,9 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 			Start:        start,
 			End:          end,
 			RefID:        queryModel.RefID,
+			MaxLines:     maxLines,
+			Instant:      queryModel.Model.Get("instant").MustBool(false),
+			LogsVolume:   queryModel.Model.Get("logsVolume").MustBool(false),
 		})
 	}
 
@@ -169,34 +201,72 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (plugins.DataQueryResult, error) {
 	var queryRes plugins.DataQueryResult
-	frames := data.Frames{}
-
-	//We are currently processing only matrix results (for alerting)
-	matrix, ok := value.Data.Result.(loghttp.Matrix)
-	if !ok {
+	var frames data.Frames
+
+	switch result := value.Data.Result.(type) {
+	case loghttp.Matrix:
+		frames = parseMatrix(result, query)
+	case loghttp.Vector:
+		frames = parseVector(result, query)
+	case loghttp.Streams:
+		frames = parseStreams(result)
+	default:
 		return queryRes, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
 	}
+	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
 
+	return queryRes, nil
+}
+
+func parseMatrix(matrix loghttp.Matrix, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
 	for _, v := range matrix {
-		name := formatLegend(v.Metric, query)
-		tags := make(map[string]string, len(v.Metric))
 		timeVector := make([]time.Time, 0, len(v.Values))
 		values := make([]float64, 0, len(v.Values))
-
-		for k, v := range v.Metric {
-			tags[string(k)] = string(v)
-		}
-
 		for _, k := range v.Values {
 			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
 			values = append(values, float64(k.Value))
 		}
+		frames = append(frames, newSeriesFrame(v.Metric, timeVector, values, query))
+	}
+	return frames
+}
 
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+func parseVector(vector loghttp.Vector, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+	for _, v := range vector {
+		timeVector := []time.Time{time.Unix(v.Timestamp.Unix(), 0).UTC()}
+		frames = append(frames, newSeriesFrame(v.Metric, timeVector, []float64{float64(v.Value)}, query))
 	}
-	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
+	return frames
+}
 
-	return queryRes, nil
+func newSeriesFrame(metric model.Metric, timeVector []time.Time, values []float64, query *lokiQuery) *data.Frame {
+	name := formatLegend(metric, query)
+	tags := make(map[string]string, len(metric))
+	for k, v := range metric {
+		tags[string(k)] = string(v)
+	}
+	return data.NewFrame(name,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}))
+}
+
+// parseStreams returns a frame of log lines by stream, with the labels of the stream on its line field.
+func parseStreams(streams loghttp.Streams) data.Frames {
+	frames := data.Frames{}
+	for _, stream := range streams {
+		timeVector := make([]time.Time, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+		for _, entry := range stream.Entries {
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+		}
+		frame := data.NewFrame("",
+			data.NewField("ts", nil, timeVector),
+			data.NewField("line", data.Labels(stream.Labels), lines))
+		frame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeLogs})
+		frames = append(frames, frame)
+	}
+	return frames
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
@@ -97,16 +98,47 @@ func TestLoki(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, time.Second*2, models[0].Step)
 	})
+
+	t.Run("parsing query model with line limits", func(t *testing.T) {
+		jsonModel, err := simplejson.NewJson([]byte(`{"expr": "{job=\"grafana\"}", "refId": "A", "instant": true}`))
+		require.NoError(t, err)
+		timeRange := plugins.NewDataTimeRange("1h", "now")
+		queryContext := plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries: []plugins.DataSubQuery{
+				{Model: jsonModel},
+			},
+		}
+		exe, err := New(httpclient.NewProvider())(dsInfo)
+		require.NoError(t, err)
+		lokiExecutor := exe.(*LokiExecutor)
+		queries, err := lokiExecutor.parseQuery(dsInfo, queryContext)
+		require.NoError(t, err)
+		require.Equal(t, defaultMaxLines, queries[0].MaxLines)
+		require.True(t, queries[0].Instant)
+
+		limitedDsInfo := &models.DataSource{
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"maxLines": "500"}),
+		}
+		queries, err = lokiExecutor.parseQuery(limitedDsInfo, queryContext)
+		require.NoError(t, err)
+		require.Equal(t, 500, queries[0].MaxLines)
+
+		jsonModel.Set("maxLines", 20)
+		queries, err = lokiExecutor.parseQuery(limitedDsInfo, queryContext)
+		require.NoError(t, err)
+		require.Equal(t, 20, queries[0].MaxLines)
+	})
 }
 
 func TestParseResponse(t *testing.T) {
-	t.Run("value is not of type matrix", func(t *testing.T) {
+	t.Run("value is of an unsupported type", func(t *testing.T) {
 		//nolint: staticcheck // plugins.DataPlugin deprecated
 		queryRes := plugins.DataQueryResult{}
 
 		value := loghttp.QueryResponse{
 			Data: loghttp.QueryResponseData{
-				Result: loghttp.Vector{},
+				Result: loghttp.Scalar{},
 			},
 		}
 		res, err := parseResponse(&value, nil)
@@ -155,4 +187,46 @@ func TestParseResponse(t *testing.T) {
 		testValue := decoded[0].Fields[0].At(0)
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
+	t.Run("vector response should be parsed normally", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Vector{
+					p.Sample{Metric: p.Metric{"app": "Application"}, Value: 3, Timestamp: 3000},
+				},
+			},
+		}
+
+		res, err := parseResponse(&value, &lokiQuery{})
+		require.NoError(t, err)
+
+		decoded, _ := res.Dataframes.Decoded()
+		require.Len(t, decoded, 1)
+		require.Equal(t, 1, decoded[0].Rows())
+		require.Equal(t, 3.0, decoded[0].Fields[1].At(0))
+		require.Equal(t, time.Unix(3, 0).UTC(), decoded[0].Fields[0].At(0))
+	})
+
+	t.Run("streams response should be parsed as logs", func(t *testing.T) {
+		ts := time.Unix(3, 15).UTC()
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Streams{
+					loghttp.Stream{
+						Labels:  loghttp.LabelSet{"app": "Application"},
+						Entries: []loghttp.Entry{{Timestamp: ts, Line: "a line"}},
+					},
+				},
+			},
+		}
+
+		res, err := parseResponse(&value, &lokiQuery{})
+		require.NoError(t, err)
+
+		decoded, _ := res.Dataframes.Decoded()
+		require.Len(t, decoded, 1)
+		require.Equal(t, data.VisType(data.VisTypeLogs), decoded[0].Meta.PreferredVisualization)
+		require.Equal(t, ts, decoded[0].Fields[0].At(0))
+		require.Equal(t, "a line", decoded[0].Fields[1].At(0))
+		require.Equal(t, "app=Application", decoded[0].Fields[1].Labels.String())
+	})
 }
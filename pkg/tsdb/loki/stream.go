@@ -0,0 +1,170 @@
+package loki
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/util/unmarshal"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/registry"
+)
+
+const (
+	// tailPathPrefix is the prefix of the paths of the live tailing channels, followed by the query
+	// encoded with base64.RawURLEncoding since the channel paths are restricted to a few characters.
+	tailPathPrefix = "tail/"
+	// tailLimit is the limit of lines Loki sends when the tailing starts.
+	tailLimit = 100
+)
+
+func init() {
+	registry.Register(&registry.Descriptor{
+		Name:         "LokiStreamService",
+		InitPriority: registry.Low,
+		Instance:     &StreamService{},
+	})
+}
+
+// StreamService tails the queries of the Loki data sources from the /loki/api/v1/tail websocket of Loki and
+// publishes the lines to the ds/<uid>/tail/<query> channels of Grafana Live, so that the browser doesn't
+// connect to Loki. The other queries of the Loki data sources are still run by the LokiExecutor.
+type StreamService struct {
+	BackendPluginManager backendplugin.Manager `inject:""`
+	HTTPClientProvider   httpclient.Provider   `inject:""`
+}
+
+func (s *StreamService) Init() error {
+	factory := coreplugin.New(backend.ServeOpts{
+		StreamHandler: s,
+	})
+	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "loki", factory); err != nil {
+		plog.Error("Failed to register plugin", "error", err)
+	}
+	return nil
+}
+
+// tailQuery returns the query of a live tailing channel path.
+func tailQuery(path string) (string, error) {
+	if !strings.HasPrefix(path, tailPathPrefix) {
+		return "", fmt.Errorf("unsupported path: %s", path)
+	}
+	query, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(path, tailPathPrefix))
+	if err != nil || len(query) == 0 {
+		return "", fmt.Errorf("invalid query in path: %s", path)
+	}
+	return string(query), nil
+}
+
+// newTailFrame returns a frame of the lines of a tail response, with the labels of their streams.
+func newTailFrame(streams []loghttp.Stream) *data.Frame {
+	timeVector := []time.Time{}
+	lines := []string{}
+	labels := []string{}
+	for _, stream := range streams {
+		streamLabels := stream.Labels.String()
+		for _, entry := range stream.Entries {
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+			labels = append(labels, streamLabels)
+		}
+	}
+	frame := data.NewFrame("",
+		data.NewField("ts", nil, timeVector),
+		data.NewField("line", nil, lines),
+		data.NewField("labels", nil, labels))
+	frame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeLogs})
+	return frame
+}
+
+func (s *StreamService) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if _, err := tailQuery(req.Path); err != nil {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	initialData, err := backend.NewInitialFrame(newTailFrame(nil), data.IncludeSchemaOnly)
+	if err != nil {
+		return nil, err
+	}
+	return &backend.SubscribeStreamResponse{
+		Status:      backend.SubscribeStreamStatusOK,
+		InitialData: initialData,
+	}, nil
+}
+
+func (s *StreamService) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// RunStream tails the query of the channel until the channel has no subscribers, or Loki closes the websocket.
+func (s *StreamService) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	query, err := tailQuery(req.Path)
+	if err != nil {
+		return err
+	}
+	settings := req.PluginContext.DataSourceInstanceSettings
+	if settings == nil {
+		return fmt.Errorf("missing data source")
+	}
+
+	dsQuery := models.GetDataSourceQuery{Id: settings.ID, OrgId: req.PluginContext.OrgID}
+	if err := bus.Dispatch(&dsQuery); err != nil {
+		return fmt.Errorf("failed to get data source: %w", err)
+	}
+	client, err := newClient(dsQuery.Result, s.HTTPClientProvider)
+	if err != nil {
+		return err
+	}
+
+	plog.Debug("Tailing query", "query", query)
+	conn, err := client.LiveTailQueryConn(query, 0, tailLimit, time.Now(), true)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		// unblocks the reading of the websocket
+		if err := conn.Close(); err != nil {
+			plog.Warn("Failed to close tail websocket", "err", err)
+		}
+	}()
+
+	for {
+		var resp loghttp.TailResponse
+		if err := unmarshal.ReadTailResponseJSON(&resp, conn); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("failed to read tail response: %w", err)
+		}
+		if len(resp.DroppedStreams) > 0 {
+			plog.Debug("Loki dropped tailed entries", "query", query, "dropped", len(resp.DroppedStreams))
+		}
+		if len(resp.Streams) == 0 {
+			continue
+		}
+		if err := sender.SendFrame(newTailFrame(resp.Streams), data.IncludeAll); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package loki
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailQuery(t *testing.T) {
+	t.Run("decodes the query of the path", func(t *testing.T) {
+		query, err := tailQuery("tail/" + base64.RawURLEncoding.EncodeToString([]byte(`{job="grafana"} |= "error"`)))
+		require.NoError(t, err)
+		require.Equal(t, `{job="grafana"} |= "error"`, query)
+	})
+
+	t.Run("rejects the other paths", func(t *testing.T) {
+		_, err := tailQuery("query/e2pvYj0iZ3JhZmFuYSJ9")
+		require.Error(t, err)
+		_, err = tailQuery("tail/")
+		require.Error(t, err)
+		_, err = tailQuery("tail/e2pvYj0iZ3JhZmFuYSJ9==")
+		require.Error(t, err)
+	})
+}
+
+func TestNewTailFrame(t *testing.T) {
+	ts := time.Date(2021, 5, 20, 7, 24, 47, 15, time.UTC)
+	frame := newTailFrame([]loghttp.Stream{
+		{
+			Labels:  loghttp.LabelSet{"job": "grafana"},
+			Entries: []loghttp.Entry{{Timestamp: ts, Line: "first"}, {Timestamp: ts.Add(time.Second), Line: "second"}},
+		},
+		{
+			Labels:  loghttp.LabelSet{"job": "loki"},
+			Entries: []loghttp.Entry{{Timestamp: ts, Line: "third"}},
+		},
+	})
+
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, ts, frame.Fields[0].At(0))
+	require.Equal(t, "second", frame.Fields[1].At(1))
+	require.Equal(t, `{job="grafana"}`, frame.Fields[2].At(0))
+	require.Equal(t, `{job="loki"}`, frame.Fields[2].At(2))
+}
@@ -9,4 +9,7 @@ type lokiQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefID        string
+	MaxLines     int
+	Instant      bool
+	LogsVolume   bool
 }
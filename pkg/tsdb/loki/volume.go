@@ -0,0 +1,76 @@
+package loki
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+const (
+	// logsVolumeLegend is the legend of the series of the log volume queries, named by the level of their lines.
+	logsVolumeLegend = "{{level}}"
+	// logsVolumeUnknownLevel is the name of the series of the lines without level, like the level of the frontend.
+	logsVolumeUnknownLevel = "unknown"
+)
+
+// logsVolumeQuery returns the query of the log volume of a log query with logsVolume set: the count of its lines
+// by level in each step of the query. It returns nil for the other queries, whose volume isn't requested or which
+// aren't log queries.
+func logsVolumeQuery(query *lokiQuery) *lokiQuery {
+	if !query.LogsVolume || query.Instant {
+		return nil
+	}
+	if _, err := logql.ParseLogSelector(query.Expr, false); err != nil {
+		return nil
+	}
+	return &lokiQuery{
+		Expr:         fmt.Sprintf("sum by (level) (count_over_time(%s[%s]))", query.Expr, model.Duration(query.Step)),
+		Step:         query.Step,
+		LegendFormat: logsVolumeLegend,
+		Start:        query.Start,
+		End:          query.End,
+		RefID:        query.RefID,
+		MaxLines:     query.MaxLines,
+	}
+}
+
+// addLogsVolume runs the log volume query of a log query and appends its series to the result of the log query,
+// with the logsVolume custom meta so that Explore shows them as the histogram of the log lines.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func addLogsVolume(client *client.DefaultClient, volume *lokiQuery, queryRes plugins.DataQueryResult) (plugins.DataQueryResult, error) {
+	value, err := client.QueryRange(volume.Expr, volume.MaxLines, volume.Start, volume.End, logproto.BACKWARD, volume.Step, time.Second, false)
+	if err != nil {
+		return queryRes, fmt.Errorf("failed to query the log volume: %w", err)
+	}
+	volumeRes, err := parseResponse(value, volume)
+	if err != nil {
+		return queryRes, err
+	}
+
+	frames, err := queryRes.Dataframes.Decoded()
+	if err != nil {
+		return queryRes, err
+	}
+	volumeFrames, err := volumeRes.Dataframes.Decoded()
+	if err != nil {
+		return queryRes, err
+	}
+	for _, frame := range volumeFrames {
+		if frame.Name == "" {
+			frame.Name = logsVolumeUnknownLevel
+			frame.Fields[1].Config.DisplayNameFromDS = logsVolumeUnknownLevel
+		}
+		frame.SetMeta(&data.FrameMeta{Custom: map[string]interface{}{"logsVolume": true}})
+		frames = append(frames, frame)
+	}
+	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
+
+	return queryRes, nil
+}
@@ -0,0 +1,69 @@
+package loki
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+func TestLogsVolumeQuery(t *testing.T) {
+	t.Run("counts the lines of log queries by level", func(t *testing.T) {
+		query := &lokiQuery{Expr: `{job="grafana"} |= "error"`, Step: 2 * time.Minute, LogsVolume: true, RefID: "A"}
+		volume := logsVolumeQuery(query)
+		require.NotNil(t, volume)
+		require.Equal(t, `sum by (level) (count_over_time({job="grafana"} |= "error"[2m]))`, volume.Expr)
+		require.Equal(t, logsVolumeLegend, volume.LegendFormat)
+		require.Equal(t, "A", volume.RefID)
+	})
+
+	t.Run("skips the queries without logsVolume", func(t *testing.T) {
+		require.Nil(t, logsVolumeQuery(&lokiQuery{Expr: `{job="grafana"}`, Step: time.Minute}))
+	})
+
+	t.Run("skips the metric queries", func(t *testing.T) {
+		require.Nil(t, logsVolumeQuery(&lokiQuery{Expr: `rate({job="grafana"}[5m])`, Step: time.Minute, LogsVolume: true}))
+	})
+
+	t.Run("skips the instant queries", func(t *testing.T) {
+		require.Nil(t, logsVolumeQuery(&lokiQuery{Expr: `{job="grafana"}`, Step: time.Minute, LogsVolume: true, Instant: true}))
+	})
+}
+
+func TestAddLogsVolume(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotQuery = req.URL.Query().Get("query")
+		rw.Header().Set("Content-Type", "application/json")
+		_, err := rw.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"level":"error"},"values":[[60,"3"],[120,"1"]]},
+			{"metric":{},"values":[[60,"5"]]}
+		]}}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	query := &lokiQuery{Expr: `{job="grafana"}`, Step: time.Minute, LogsVolume: true, MaxLines: 1000}
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	queryRes := plugins.DataQueryResult{Dataframes: plugins.NewDecodedDataFrames(data.Frames{data.NewFrame("logs")})}
+	res, err := addLogsVolume(&client.DefaultClient{Address: srv.URL}, logsVolumeQuery(query), queryRes)
+	require.NoError(t, err)
+	require.Equal(t, `sum by (level) (count_over_time({job="grafana"}[1m]))`, gotQuery)
+
+	frames, err := res.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 3)
+	require.Equal(t, "logs", frames[0].Name)
+	require.Nil(t, frames[0].Meta)
+	require.Equal(t, "error", frames[1].Name)
+	require.Equal(t, 2, frames[1].Rows())
+	require.Equal(t, map[string]interface{}{"logsVolume": true}, frames[1].Meta.Custom)
+	require.Equal(t, logsVolumeUnknownLevel, frames[2].Name)
+	require.Equal(t, logsVolumeUnknownLevel, frames[2].Fields[1].Config.DisplayNameFromDS)
+}
@@ -23,28 +23,33 @@ import (
 var logger = log.New("tsdb.mssql")
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
-func NewExecutor(datasource *models.DataSource) (plugins.DataPlugin, error) {
-	cnnstr, err := generateConnectionString(datasource)
-	if err != nil {
-		return nil, err
-	}
-	// TODO: Don't use global
-	if setting.Env == setting.Dev {
-		logger.Debug("getEngine", "connection", cnnstr)
-	}
+func New(cfg *setting.Cfg) func(datasource *models.DataSource) (plugins.DataPlugin, error) {
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	return func(datasource *models.DataSource) (plugins.DataPlugin, error) {
+		cnnstr, err := generateConnectionString(datasource)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Env == setting.Dev {
+			logger.Debug("getEngine", "connection", cnnstr)
+		}
 
-	config := sqleng.DataPluginConfiguration{
-		DriverName:        "mssql",
-		ConnectionString:  cnnstr,
-		Datasource:        datasource,
-		MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
-	}
+		config := sqleng.DataPluginConfiguration{
+			DriverName:        "mssql",
+			ConnectionString:  cnnstr,
+			Datasource:        datasource,
+			MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
+			RowLimit:          cfg.SQLDatasourceRowLimit,
+			MaxResultBytes:    cfg.SQLDatasourceMaxResultBytes,
+			// the driver cancels the queries of the abandoned requests with an attention message
+		}
 
-	queryResultTransformer := mssqlQueryResultTransformer{
-		log: logger,
-	}
+		queryResultTransformer := mssqlQueryResultTransformer{
+			log: logger,
+		}
 
-	return sqleng.NewDataPlugin(config, &queryResultTransformer, newMssqlMacroEngine(), logger)
+		return sqleng.NewDataPlugin(config, &queryResultTransformer, newMssqlMacroEngine(), logger)
+	}
 }
 
 // ParseURL tries to parse an MSSQL URL string into a URL object.
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -51,7 +52,7 @@ func TestMSSQL(t *testing.T) {
 		return x, nil
 	}
 
-	endpoint, err := NewExecutor(&models.DataSource{
+	endpoint, err := New(setting.NewCfg())(&models.DataSource{
 		JsonData:       simplejson.New(),
 		SecureJsonData: securejsondata.SecureJsonData{},
 	})
@@ -786,7 +787,7 @@ func TestMSSQL(t *testing.T) {
 			require.NoError(t, err)
 
 			t.Run("When doing a metric query using stored procedure should return correct result", func(t *testing.T) {
-				endpoint, err := NewExecutor(&models.DataSource{
+				endpoint, err := New(setting.NewCfg())(&models.DataSource{
 					JsonData:       simplejson.New(),
 					SecureJsonData: securejsondata.SecureJsonData{},
 				})
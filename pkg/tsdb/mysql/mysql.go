@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/url"
@@ -33,7 +35,7 @@ func characterEscape(s string, escapeChar string) string {
 }
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
-func New(httpClientProvider httpclient.Provider) func(datasource *models.DataSource) (plugins.DataPlugin, error) {
+func New(cfg *setting.Cfg, httpClientProvider httpclient.Provider) func(datasource *models.DataSource) (plugins.DataPlugin, error) {
 	//nolint: staticcheck // plugins.DataPlugin deprecated
 	return func(datasource *models.DataSource) (plugins.DataPlugin, error) {
 		logger := log.New("tsdb.mysql")
@@ -81,6 +83,9 @@ func New(httpClientProvider httpclient.Provider) func(datasource *models.DataSou
 			Datasource:        datasource,
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+			RowLimit:          cfg.SQLDatasourceRowLimit,
+			MaxResultBytes:    cfg.SQLDatasourceMaxResultBytes,
+			QueryCanceler:     mysqlQueryCanceler{},
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{
@@ -91,6 +96,21 @@ func New(httpClientProvider httpclient.Provider) func(datasource *models.DataSou
 	}
 }
 
+// mysqlQueryCanceler kills the queries of the abandoned requests, which MySQL keeps running after the driver
+// closes their connection.
+type mysqlQueryCanceler struct{}
+
+func (c mysqlQueryCanceler) SessionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+func (c mysqlQueryCanceler) CancelQuery(ctx context.Context, db *sql.DB, sessionID int64) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", sessionID))
+	return err
+}
+
 type mysqlQueryResultTransformer struct {
 	log log.Logger
 }
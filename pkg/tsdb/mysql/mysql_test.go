@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/stretchr/testify/require"
 	"xorm.io/xorm"
@@ -57,7 +58,7 @@ func TestMySQL(t *testing.T) {
 		return sql, nil
 	}
 
-	exe, err := New(httpclient.NewProvider())(&models.DataSource{
+	exe, err := New(setting.NewCfg(), httpclient.NewProvider())(&models.DataSource{
 		JsonData:       simplejson.New(),
 		SecureJsonData: securejsondata.SecureJsonData{},
 	})
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +18,7 @@ import (
 	"net/url"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -23,16 +26,31 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+const (
+	// msResolution is the tsdbResolution setting of the data sources whose timestamps are in milliseconds.
+	msResolution = 2
+	// showQueryVersion is the first tsdbVersion setting with the query of each series in the responses.
+	showQueryVersion = 3
+)
+
 type OpenTsdbExecutor struct {
 	httpClientProvider httpclient.Provider
+	tsdbVersion        int
+	tsdbResolution     int
 }
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func New(httpClientProvider httpclient.Provider) func(*models.DataSource) (plugins.DataPlugin, error) {
 	//nolint: staticcheck // plugins.DataPlugin deprecated
-	return func(*models.DataSource) (plugins.DataPlugin, error) {
+	return func(dsInfo *models.DataSource) (plugins.DataPlugin, error) {
+		jsonData := dsInfo.JsonData
+		if jsonData == nil {
+			jsonData = simplejson.New()
+		}
 		return &OpenTsdbExecutor{
 			httpClientProvider: httpClientProvider,
+			tsdbVersion:        jsonData.Get("tsdbVersion").MustInt(1),
+			tsdbResolution:     jsonData.Get("tsdbResolution").MustInt(1),
 		}, nil
 	}
 }
@@ -41,47 +59,65 @@ var (
 	plog = log.New("tsdb.opentsdb")
 )
 
+// DataQuery runs each query in its own OpenTSDB request, so that the series are returned with the query they
+// belong to like in the frontend, whatever the version of OpenTSDB.
 // nolint:staticcheck // plugins.DataQueryResult deprecated
 func (e *OpenTsdbExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 	queryContext plugins.DataQuery) (plugins.DataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
-	tsdbQuery.Start = queryContext.TimeRange.GetFromAsMsEpoch()
-	tsdbQuery.End = queryContext.TimeRange.GetToAsMsEpoch()
-
-	for _, query := range queryContext.Queries {
-		metric := e.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
-	}
-
-	// TODO: Don't use global variable
-	if setting.Env == setting.Dev {
-		plog.Debug("OpenTsdb request", "params", tsdbQuery)
+	result := plugins.DataResponse{
+		Results: make(map[string]plugins.DataQueryResult),
 	}
 
-	req, err := e.createRequest(dsInfo, tsdbQuery)
+	httpClient, err := dsInfo.GetHTTPClient(e.httpClientProvider)
 	if err != nil {
 		return plugins.DataResponse{}, err
 	}
 
-	httpClient, err := dsInfo.GetHTTPClient(e.httpClientProvider)
-	if err != nil {
-		return plugins.DataResponse{}, err
+	for _, query := range queryContext.Queries {
+		if query.Model.Get("metric").MustString() == "" {
+			continue
+		}
+
+		tsdbQuery := OpenTsdbQuery{
+			Start:        queryContext.TimeRange.GetFromAsMsEpoch(),
+			End:          queryContext.TimeRange.GetToAsMsEpoch(),
+			Queries:      []map[string]interface{}{e.buildMetric(query)},
+			MsResolution: e.tsdbResolution == msResolution,
+			ShowQuery:    e.tsdbVersion >= showQueryVersion,
+			RefID:        query.RefID,
+			Alias:        query.Model.Get("alias").MustString(),
+		}
+
+		// TODO: Don't use global variable
+		if setting.Env == setting.Dev {
+			plog.Debug("OpenTsdb request", "params", tsdbQuery)
+		}
+
+		queryResult, err := e.executeQuery(ctx, httpClient, dsInfo, tsdbQuery)
+		if err != nil {
+			result.Results[query.RefID] = plugins.DataQueryResult{RefID: query.RefID, Error: err}
+			continue
+		}
+		result.Results[query.RefID] = queryResult[query.RefID]
 	}
 
-	res, err := ctxhttp.Do(ctx, httpClient, req)
+	return result, nil
+}
+
+// nolint:staticcheck // plugins.DataQueryResult deprecated
+func (e *OpenTsdbExecutor) executeQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource,
+	tsdbQuery OpenTsdbQuery) (map[string]plugins.DataQueryResult, error) {
+	req, err := e.createRequest(dsInfo, tsdbQuery)
 	if err != nil {
-		return plugins.DataResponse{}, err
+		return nil, err
 	}
 
-	queryResult, err := e.parseResponse(tsdbQuery, res)
+	res, err := ctxhttp.Do(ctx, httpClient, req)
 	if err != nil {
-		return plugins.DataResponse{}, err
+		return nil, err
 	}
 
-	return plugins.DataResponse{
-		Results: queryResult,
-	}, nil
+	return e.parseResponse(tsdbQuery, res)
 }
 
 func (e *OpenTsdbExecutor) createRequest(dsInfo *models.DataSource, data OpenTsdbQuery) (*http.Request, error) {
@@ -114,7 +150,7 @@ func (e *OpenTsdbExecutor) createRequest(dsInfo *models.DataSource, data OpenTsd
 // nolint:staticcheck // plugins.DataQueryResult deprecated
 func (e *OpenTsdbExecutor) parseResponse(query OpenTsdbQuery, res *http.Response) (map[string]plugins.DataQueryResult, error) {
 	queryResults := make(map[string]plugins.DataQueryResult)
-	queryRes := plugins.DataQueryResult{}
+	queryRes := plugins.DataQueryResult{RefID: query.RefID}
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
@@ -128,6 +164,10 @@ func (e *OpenTsdbExecutor) parseResponse(query OpenTsdbQuery, res *http.Response
 
 	if res.StatusCode/100 != 2 {
 		plog.Info("Request failed", "status", res.Status, "body", string(body))
+		var errorResponse OpenTsdbErrorResponse
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+			return nil, fmt.Errorf("request failed, status: %s, error: %s", res.Status, errorResponse.Error.Message)
+		}
 		return nil, fmt.Errorf("request failed, status: %s", res.Status)
 	}
 
@@ -138,47 +178,73 @@ func (e *OpenTsdbExecutor) parseResponse(query OpenTsdbQuery, res *http.Response
 		return nil, err
 	}
 
+	groupByTags := queryGroupByTags(query.Queries)
 	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-
-		for timeString, value := range val.DataPoints {
+		timestamps := make([]int64, 0, len(val.DataPoints))
+		for timeString := range val.DataPoints {
 			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				plog.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			timestamps = append(timestamps, timestamp)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		timeVector := make([]time.Time, 0, len(timestamps))
+		values := make([]float64, 0, len(timestamps))
+		for _, timestamp := range timestamps {
+			if query.MsResolution {
+				timeVector = append(timeVector, time.Unix(0, timestamp*int64(time.Millisecond)).UTC())
+			} else {
+				timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
+			}
+			values = append(values, val.DataPoints[strconv.FormatInt(timestamp, 10)])
+		}
+
+		var labels data.Labels
+		if len(val.Tags) > 0 {
+			labels = data.Labels(val.Tags)
 		}
-		frames = append(frames, data.NewFrame(name,
+		frame := data.NewFrame(metricLabel(val, query.Alias, groupByTags),
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", nil, values)))
+			data.NewField("value", labels, values))
+		frame.RefID = query.RefID
+		frames = append(frames, frame)
 	}
 	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
-	queryResults["A"] = queryRes
+	queryResults[query.RefID] = queryRes
 	return queryResults, nil
 }
 
+// buildMetric returns the OpenTSDB query of the Grafana query, like the frontend does.
 func (e *OpenTsdbExecutor) buildMetric(query plugins.DataSubQuery) map[string]interface{} {
 	metric := make(map[string]interface{})
 
 	// Setting metric and aggregator
 	metric["metric"] = query.Model.Get("metric").MustString()
-	metric["aggregator"] = query.Model.Get("aggregator").MustString()
+	aggregator := query.Model.Get("aggregator").MustString()
+	if aggregator == "" {
+		aggregator = "avg"
+	}
+	metric["aggregator"] = aggregator
 
 	// Setting downsampling options
 	disableDownsampling := query.Model.Get("disableDownsampling").MustBool()
 	if !disableDownsampling {
 		downsampleInterval := query.Model.Get("downsampleInterval").MustString()
 		if downsampleInterval == "" {
-			downsampleInterval = "1m" // default value for blank
+			downsampleInterval = formatInterval(query.IntervalMS)
+		}
+		downsampleAggregator := query.Model.Get("downsampleAggregator").MustString()
+		if downsampleAggregator == "" {
+			downsampleAggregator = "avg"
 		}
-		downsample := downsampleInterval + "-" + query.Model.Get("downsampleAggregator").MustString()
-		if query.Model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + query.Model.Get("downsampleFillPolicy").MustString()
+		downsample := downsampleInterval + "-" + downsampleAggregator
+		fillPolicy := query.Model.Get("downsampleFillPolicy").MustString()
+		if fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -190,34 +256,126 @@ func (e *OpenTsdbExecutor) buildMetric(query plugins.DataSubQuery) map[string]in
 		rateOptions := make(map[string]interface{})
 		rateOptions["counter"] = query.Model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := query.Model.CheckGet("counterMax")
+		counterMax, counterMaxCheck := modelNumber(query.Model, "counterMax")
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := query.Model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := modelNumber(query.Model, "counterResetValue")
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
-			rateOptions["dropResets"] = true
+		if e.tsdbVersion >= 2 {
+			rateOptions["dropResets"] = !counterMaxCheck && (!resetValueCheck || resetValue == 0)
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := query.Model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
-	}
-
-	// Setting filters
+	// Setting filters, or tags without filters
 	filters, filtersCheck := query.Model.CheckGet("filters")
 	if filtersCheck && len(filters.MustArray()) > 0 {
 		metric["filters"] = filters.MustArray()
+	} else {
+		tags, tagsCheck := query.Model.CheckGet("tags")
+		if tagsCheck && len(tags.MustMap()) > 0 {
+			metric["tags"] = tags.MustMap()
+		}
+	}
+
+	if query.Model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
 }
+
+// modelNumber returns the number of the model, which is a string when it's set by the query editor.
+func modelNumber(model *simplejson.Json, key string) (float64, bool) {
+	value, ok := model.CheckGet(key)
+	if !ok {
+		return 0, false
+	}
+	if s, err := value.String(); err == nil {
+		if s == "" {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	f, err := value.Float64()
+	return f, err == nil
+}
+
+// formatInterval returns the interval of the query as an OpenTSDB duration, 1m if it's missing.
+func formatInterval(intervalMS int64) string {
+	switch {
+	case intervalMS <= 0:
+		return "1m"
+	case intervalMS%int64(time.Hour/time.Millisecond) == 0:
+		return fmt.Sprintf("%dh", intervalMS/int64(time.Hour/time.Millisecond))
+	case intervalMS%int64(time.Minute/time.Millisecond) == 0:
+		return fmt.Sprintf("%dm", intervalMS/int64(time.Minute/time.Millisecond))
+	case intervalMS%int64(time.Second/time.Millisecond) == 0:
+		return fmt.Sprintf("%ds", intervalMS/int64(time.Second/time.Millisecond))
+	}
+	return fmt.Sprintf("%dms", intervalMS)
+}
+
+// queryGroupByTags returns the tags the series of the queries are grouped by.
+func queryGroupByTags(queries []map[string]interface{}) map[string]bool {
+	groupByTags := make(map[string]bool)
+	for _, metric := range queries {
+		if filters, ok := metric["filters"].([]interface{}); ok {
+			for _, f := range filters {
+				if filter, ok := f.(map[string]interface{}); ok {
+					if tagk, ok := filter["tagk"].(string); ok {
+						groupByTags[tagk] = true
+					}
+				}
+			}
+			continue
+		}
+		if tags, ok := metric["tags"].(map[string]interface{}); ok {
+			for tagk := range tags {
+				groupByTags[tagk] = true
+			}
+		}
+	}
+	return groupByTags
+}
+
+var aliasTagPattern = regexp.MustCompile(`\$tag_(\w+)|\[\[tag_(\w+)\]\]|\$\{tag_(\w+)\}`)
+
+// metricLabel returns the name of a series: the alias with the $tag_<name> variables replaced, or the metric
+// with the tags the queries are grouped by.
+func metricLabel(series OpenTsdbResponse, alias string, groupByTags map[string]bool) string {
+	if alias != "" {
+		return aliasTagPattern.ReplaceAllStringFunc(alias, func(match string) string {
+			groups := aliasTagPattern.FindStringSubmatch(match)
+			for _, name := range groups[1:] {
+				if value, ok := series.Tags[name]; ok {
+					return value
+				}
+			}
+			return match
+		})
+	}
+
+	keys := make([]string, 0, len(series.Tags))
+	for key := range series.Tags {
+		if groupByTags[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return series.Metric
+	}
+	sort.Strings(keys)
+	tagData := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tagData = append(tagData, key+"="+series.Tags[key])
+	}
+	return series.Metric + "{" + strings.Join(tagData, ", ") + "}"
+}
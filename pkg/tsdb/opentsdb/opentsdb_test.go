@@ -1,8 +1,11 @@
 package opentsdb
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/stretchr/testify/assert"
@@ -34,7 +38,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		query := OpenTsdbQuery{}
+		query := OpenTsdbQuery{RefID: "A"}
 
 		result, err := exec.parseResponse(query, &http.Response{Body: ioutil.NopCloser(strings.NewReader(response))})
 		require.Nil(t, result["A"].Dataframes)
@@ -59,8 +63,9 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			data.NewField("value", nil, []float64{
 				50}),
 		)
+		testFrame.RefID = "A"
 
-		query := OpenTsdbQuery{}
+		query := OpenTsdbQuery{RefID: "A"}
 
 		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
@@ -237,4 +242,92 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric like the frontend", func(t *testing.T) {
+		exec := &OpenTsdbExecutor{tsdbVersion: 2}
+		query := plugins.DataSubQuery{
+			Model:      simplejson.New(),
+			IntervalMS: 30000,
+		}
+
+		query.Model.Set("metric", "cpu.average.percent")
+		query.Model.Set("shouldComputeRate", true)
+		query.Model.Set("isCounter", true)
+		query.Model.Set("counterMax", "")
+		query.Model.Set("counterResetValue", "10")
+		query.Model.Set("explicitTags", true)
+		query.Model.Set("tags", map[string]interface{}{"env": "prod"})
+		query.Model.Set("filters", []interface{}{
+			map[string]interface{}{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true},
+		})
+
+		metric := exec.buildMetric(query)
+
+		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, "30s-avg", metric["downsample"])
+		require.Nil(t, metric["tags"])
+		require.Len(t, metric["filters"], 1)
+		require.True(t, metric["explicitTags"].(bool))
+		require.Equal(t, map[string]interface{}{
+			"counter":    true,
+			"resetValue": float64(10),
+			"dropResets": false,
+		}, metric["rateOptions"])
+	})
+}
+
+func TestOpenTsdbDataQuery(t *testing.T) {
+	requests := make([]OpenTsdbQuery, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query OpenTsdbQuery
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		requests = append(requests, query)
+		if query.Queries[0]["metric"] == "unknown" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"No such name for 'metrics': 'unknown'"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"metric":"cpu","tags":{"host":"web-2","env":"prod"},"dps":{"1405544146000":2,"1405544086000":1}},
+			{"metric":"cpu","tags":{"host":"web-1","env":"prod"},"dps":{"1405544086000":3}}
+		]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	exec := &OpenTsdbExecutor{httpClientProvider: httpclient.NewProvider(), tsdbResolution: msResolution}
+	timeRange := plugins.NewDataTimeRange("1405544000000", "1405544200000")
+	model := func(m map[string]interface{}) *simplejson.Json {
+		return simplejson.NewFromAny(m)
+	}
+
+	resp, err := exec.DataQuery(context.Background(), &models.DataSource{Url: srv.URL}, plugins.DataQuery{
+		TimeRange: &timeRange,
+		Queries: []plugins.DataSubQuery{
+			{RefID: "A", Model: model(map[string]interface{}{"metric": "cpu", "tags": map[string]interface{}{"host": "*"}})},
+			{RefID: "B", Model: model(map[string]interface{}{"metric": "cpu", "alias": "$tag_host in [[tag_env]]"})},
+			{RefID: "C", Model: model(map[string]interface{}{"metric": "unknown"})},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, requests, 3)
+	require.True(t, requests[0].MsResolution)
+	require.Equal(t, int64(1405544000000), requests[0].Start)
+
+	frames, err := resp.Results["A"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	require.Equal(t, "cpu{host=web-2}", frames[0].Name)
+	require.Equal(t, "A", frames[0].RefID)
+	require.Equal(t, data.Labels{"host": "web-2", "env": "prod"}, frames[0].Fields[1].Labels)
+	require.Equal(t, time.Unix(1405544086, 0).UTC(), frames[0].Fields[0].At(0))
+	require.Equal(t, float64(1), frames[0].Fields[1].At(0))
+	require.Equal(t, float64(2), frames[0].Fields[1].At(1))
+
+	frames, err = resp.Results["B"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Equal(t, "web-1 in prod", frames[1].Name)
+
+	require.EqualError(t, resp.Results["C"].Error,
+		"request failed, status: 400 Bad Request, error: No such name for 'metrics': 'unknown'")
 }
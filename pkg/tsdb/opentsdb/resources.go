@@ -0,0 +1,152 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/registry"
+)
+
+func init() {
+	registry.Register(&registry.Descriptor{
+		Name:         "OpenTSDBResourceService",
+		InitPriority: registry.Low,
+		Instance:     &ResourceService{},
+	})
+}
+
+// ResourceService serves the lookup endpoints of the OpenTSDB data sources used by the query editor and the
+// template variables as resources of the data source, so they don't depend on the data source proxy.
+type ResourceService struct {
+	BackendPluginManager backendplugin.Manager `inject:""`
+	HTTPClientProvider   httpclient.Provider   `inject:""`
+}
+
+func (s *ResourceService) Init() error {
+	factory := coreplugin.New(backend.ServeOpts{
+		CallResourceHandler: s.resourceHandler(),
+	})
+	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "opentsdb", factory); err != nil {
+		plog.Error("Failed to register plugin", "error", err)
+	}
+	return nil
+}
+
+// resourceParams are the query parameters forwarded to OpenTSDB by path.
+var resourceParams = map[string][]string{
+	"/api/suggest":        {"type", "q", "max"},
+	"/api/search/lookup":  {"m", "limit", "useMeta"},
+	"/api/aggregators":    {},
+	"/api/config/filters": {},
+}
+
+func (s *ResourceService) resourceHandler() backend.CallResourceHandler {
+	mux := http.NewServeMux()
+	for path := range resourceParams {
+		mux.HandleFunc(path, s.handleResource)
+	}
+	return httpadapter.New(mux)
+}
+
+func (s *ResourceService) handleResource(rw http.ResponseWriter, req *http.Request) {
+	params, ok := resourceParams[req.URL.Path]
+	if !ok {
+		writeResourceError(rw, http.StatusNotFound, "unknown resource "+req.URL.Path)
+		return
+	}
+	if req.Method != http.MethodGet {
+		writeResourceError(rw, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	pCtx := httpadapter.PluginConfigFromContext(req.Context())
+	settings := pCtx.DataSourceInstanceSettings
+	if settings == nil {
+		writeResourceError(rw, http.StatusBadRequest, "missing data source")
+		return
+	}
+
+	dsQuery := models.GetDataSourceQuery{Id: settings.ID, OrgId: pCtx.OrgID}
+	if err := bus.Dispatch(&dsQuery); err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, "failed to get data source")
+		plog.Error("Failed to get data source", "id", settings.ID, "error", err)
+		return
+	}
+
+	query := url.Values{}
+	for _, name := range params {
+		if value := req.URL.Query().Get(name); value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	if err := s.proxyResource(req.Context(), rw, dsQuery.Result, req.URL.Path, query); err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+	}
+}
+
+func (s *ResourceService) proxyResource(ctx context.Context, rw http.ResponseWriter, ds *models.DataSource, path string,
+	query url.Values) error {
+	transport, err := ds.GetHTTPTransport(s.HTTPClientProvider)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(ds.Url, "/") + path)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		rw.Header().Set("Content-Type", contentType)
+	}
+	rw.WriteHeader(resp.StatusCode)
+	if _, err := rw.Write(body); err != nil {
+		plog.Error("Failed to write response", "error", err)
+	}
+	return nil
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, msg string) {
+	// the errors of OpenTSDB have the same format
+	body, err := json.Marshal(OpenTsdbErrorResponse{Error: OpenTsdbError{Code: status, Message: msg}})
+	if err != nil {
+		plog.Error("Failed to marshal error", "error", err)
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if _, err := rw.Write(body); err != nil {
+		plog.Error("Failed to write response", "error", err)
+	}
+}
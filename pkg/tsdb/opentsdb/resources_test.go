@@ -0,0 +1,76 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestResources(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"type":"LOOKUP","metric":"cpu","results":[{"metric":"cpu","tags":{"host":"web-1"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	bus.AddHandler("test", func(q *models.GetDataSourceQuery) error {
+		q.Result = &models.DataSource{Id: q.Id, OrgId: q.OrgId, Url: srv.URL}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	s := &ResourceService{HTTPClientProvider: httpclient.NewProvider()}
+	handler := s.resourceHandler()
+	call := func(resourceURL string) *backend.CallResourceResponse {
+		var resp *backend.CallResourceResponse
+		u, err := url.Parse(resourceURL)
+		require.NoError(t, err)
+		err = handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 10},
+			},
+			Path:   u.Path,
+			Method: http.MethodGet,
+			URL:    resourceURL,
+		}, resourceResponseSender(func(r *backend.CallResourceResponse) {
+			resp = r
+		}))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("the lookups are proxied with their parameters", func(t *testing.T) {
+		resp := call("api/search/lookup?m=cpu%7Bhost%3D*%7D&limit=1000&other=1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"type":"LOOKUP","metric":"cpu","results":[{"metric":"cpu","tags":{"host":"web-1"}}]}`,
+			string(resp.Body))
+		require.Len(t, requests, 1)
+		require.Equal(t, "/api/search/lookup", requests[0].URL.Path)
+		require.Equal(t, url.Values{"m": {"cpu{host=*}"}, "limit": {"1000"}}, requests[0].URL.Query())
+	})
+
+	t.Run("the other paths aren't served", func(t *testing.T) {
+		resp := call("api/query")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+		require.Len(t, requests, 1)
+	})
+}
+
+type resourceResponseSender func(*backend.CallResourceResponse)
+
+func (f resourceResponseSender) Send(resp *backend.CallResourceResponse) error {
+	f(resp)
+	return nil
+}
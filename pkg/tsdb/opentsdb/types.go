@@ -1,12 +1,28 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64                    `json:"start"`
-	End     int64                    `json:"end"`
-	Queries []map[string]interface{} `json:"queries"`
+	Start        int64                    `json:"start"`
+	End          int64                    `json:"end"`
+	Queries      []map[string]interface{} `json:"queries"`
+	MsResolution bool                     `json:"msResolution,omitempty"`
+	ShowQuery    bool                     `json:"showQuery,omitempty"`
+
+	// RefID and Alias are the ones of the Grafana query, they aren't sent to OpenTSDB.
+	RefID string `json:"-"`
+	Alias string `json:"-"`
 }
 
 type OpenTsdbResponse struct {
 	Metric     string             `json:"metric"`
+	Tags       map[string]string  `json:"tags"`
 	DataPoints map[string]float64 `json:"dps"`
 }
+
+type OpenTsdbErrorResponse struct {
+	Error OpenTsdbError `json:"error"`
+}
+
+type OpenTsdbError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
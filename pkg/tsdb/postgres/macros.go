@@ -105,7 +105,7 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange plugins.DataTimeRange, que
 		}
 
 		if m.timescaledb {
-			return fmt.Sprintf("time_bucket('%.3fs',%s)", interval.Seconds(), args[0]), nil
+			return timeBucket(args[0], interval), nil
 		}
 
 		return fmt.Sprintf(
@@ -119,6 +119,27 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange plugins.DataTimeRange, que
 			return tg + " AS \"time\"", nil
 		}
 		return "", err
+	case "__timescaleTimeBucket":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+		}
+		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'`))
+		if err != nil {
+			return "", fmt.Errorf("error parsing interval %v", args[1])
+		}
+		if len(args) == 3 {
+			err := sqleng.SetupFillmode(query, interval, args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return timeBucket(args[0], interval), nil
+	case "__timescaleTimeBucketAlias":
+		tb, err := m.evaluateMacro(timeRange, query, "__timescaleTimeBucket", args)
+		if err == nil {
+			return tb + " AS \"time\"", nil
+		}
+		return "", err
 	case "__unixEpochFilter":
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
@@ -158,3 +179,9 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange plugins.DataTimeRange, que
 		return "", fmt.Errorf("unknown macro %q", name)
 	}
 }
+
+// timeBucket returns the TimescaleDB time_bucket expression of the column, which the planner of TimescaleDB
+// optimizes for the chunks of hypertables unlike the epoch math of $__timeGroup.
+func timeBucket(column string, interval time.Duration) string {
+	return fmt.Sprintf("time_bucket('%.3fs',%s)", interval.Seconds(), column)
+}
@@ -114,6 +114,30 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, "GROUP BY time_bucket('0.020s',time_column)", sql)
 		})
 
+		t.Run("interpolate __timescaleTimeBucket function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timescaleTimeBucket(time_column, '5m')")
+			require.NoError(t, err)
+			require.Equal(t, "GROUP BY time_bucket('300.000s',time_column)", sql)
+
+			sql2, err := engine.Interpolate(query, timeRange, "SELECT $__timescaleTimeBucketAlias(time_column, '5m')")
+			require.NoError(t, err)
+			require.Equal(t, "SELECT time_bucket('300.000s',time_column) AS \"time\"", sql2)
+		})
+
+		t.Run("interpolate __timescaleTimeBucket function with fill value", func(t *testing.T) {
+			query := plugins.DataSubQuery{Model: simplejson.New()}
+			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timescaleTimeBucket(time_column, '5m', NULL)")
+			require.NoError(t, err)
+			require.Equal(t, "GROUP BY time_bucket('300.000s',time_column)", sql)
+			require.True(t, query.Model.Get("fill").MustBool())
+			require.Equal(t, 300.0, query.Model.Get("fillInterval").MustFloat64())
+		})
+
+		t.Run("interpolate __timescaleTimeBucket function without interval", func(t *testing.T) {
+			_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timescaleTimeBucket(time_column)")
+			require.Error(t, err)
+		})
+
 		t.Run("interpolate __unixEpochFilter function", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochFilter(time)")
 			require.NoError(t, err)
@@ -27,9 +27,10 @@ func init() {
 }
 
 type PostgresService struct {
-	Cfg        *setting.Cfg `inject:""`
-	logger     log.Logger
-	tlsManager tlsSettingsProvider
+	Cfg         *setting.Cfg `inject:""`
+	logger      log.Logger
+	tlsManager  tlsSettingsProvider
+	timescaleDB timescaleDBDetector
 }
 
 func (s *PostgresService) Init() error {
@@ -56,13 +57,16 @@ func (s *PostgresService) NewExecutor(datasource *models.DataSource) (plugins.Da
 		ConnectionString:  cnnstr,
 		Datasource:        datasource,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
+		RowLimit:          s.Cfg.SQLDatasourceRowLimit,
+		MaxResultBytes:    s.Cfg.SQLDatasourceMaxResultBytes,
+		// the driver cancels the queries of the abandoned requests with a cancel request, like pg_cancel_backend
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{
 		log: s.logger,
 	}
 
-	timescaledb := datasource.JsonData.Get("timescaledb").MustBool(false)
+	timescaledb := s.timescaleDB.enabled(s.logger, datasource, cnnstr)
 
 	plugin, err := sqleng.NewDataPlugin(config, &queryResultTransformer, newPostgresMacroEngine(timescaledb),
 		s.logger)
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// timescaleDBDetectionTimeout is how long the detection of the TimescaleDB extension of a database may take.
+const timescaleDBDetectionTimeout = 5 * time.Second
+
+// detectTimescaleDB returns whether the TimescaleDB extension is installed in the database, it can be stubbed by tests.
+var detectTimescaleDB = func(ctx context.Context, cnnstr string) (bool, error) {
+	db, err := sql.Open("postgres", cnnstr)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	var extensions int
+	err = db.QueryRowContext(ctx, "SELECT count(*) FROM pg_extension WHERE extname = 'timescaledb'").Scan(&extensions)
+	return extensions > 0, err
+}
+
+type timescaleDBDetection struct {
+	version int
+	enabled bool
+}
+
+// timescaleDBDetector detects the data sources whose databases have the TimescaleDB extension, so that their
+// macros use TimescaleDB functions without the TimescaleDB setting being enabled.
+type timescaleDBDetector struct {
+	mu sync.Mutex
+	// detections are the detections by data source, a data source is detected again when it's updated.
+	detections map[int64]timescaleDBDetection
+}
+
+// enabled returns whether the TimescaleDB macros are used for the data source: the TimescaleDB setting of the data
+// source when it's set, or else whether the extension is installed in its database.
+func (d *timescaleDBDetector) enabled(logger log.Logger, datasource *models.DataSource, cnnstr string) bool {
+	if timescaledb, ok := datasource.JsonData.CheckGet("timescaledb"); ok {
+		return timescaledb.MustBool(false)
+	}
+
+	d.mu.Lock()
+	detection, ok := d.detections[datasource.Id]
+	d.mu.Unlock()
+	if ok && detection.version == datasource.Version {
+		return detection.enabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timescaleDBDetectionTimeout)
+	defer cancel()
+	enabled, err := detectTimescaleDB(ctx, cnnstr)
+	if err != nil {
+		// the detection is retried by the next query
+		logger.Warn("Failed to detect TimescaleDB", "datasource", datasource.Id, "err", err)
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.detections == nil {
+		d.detections = make(map[int64]timescaleDBDetection)
+	}
+	d.detections[datasource.Id] = timescaleDBDetection{version: datasource.Version, enabled: enabled}
+	return enabled
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimescaleDBDetector(t *testing.T) {
+	origDetect := detectTimescaleDB
+	t.Cleanup(func() {
+		detectTimescaleDB = origDetect
+	})
+
+	detections := 0
+	installed := true
+	var detectErr error
+	detectTimescaleDB = func(ctx context.Context, cnnstr string) (bool, error) {
+		detections++
+		return installed, detectErr
+	}
+	logger := log.New("test")
+
+	t.Run("Should use the TimescaleDB setting of the data source when it's set", func(t *testing.T) {
+		detector := &timescaleDBDetector{}
+		detections = 0
+
+		ds := &models.DataSource{Id: 1, JsonData: simplejson.NewFromAny(map[string]interface{}{"timescaledb": false})}
+		assert.False(t, detector.enabled(logger, ds, ""))
+		assert.Equal(t, 0, detections)
+	})
+
+	t.Run("Should detect TimescaleDB once per data source version", func(t *testing.T) {
+		detector := &timescaleDBDetector{}
+		detections = 0
+
+		ds := &models.DataSource{Id: 1, Version: 1, JsonData: simplejson.New()}
+		assert.True(t, detector.enabled(logger, ds, ""))
+		assert.True(t, detector.enabled(logger, ds, ""))
+		assert.Equal(t, 1, detections)
+
+		installed = false
+		ds.Version = 2
+		assert.False(t, detector.enabled(logger, ds, ""))
+		assert.Equal(t, 2, detections)
+		installed = true
+	})
+
+	t.Run("Should detect TimescaleDB again when the detection failed", func(t *testing.T) {
+		detector := &timescaleDBDetector{}
+		detections = 0
+
+		ds := &models.DataSource{Id: 1, Version: 1, JsonData: simplejson.New()}
+		detectErr = errors.New("connection refused")
+		assert.False(t, detector.enabled(logger, ds, ""))
+		detectErr = nil
+		assert.True(t, detector.enabled(logger, ds, ""))
+		assert.Equal(t, 2, detections)
+	})
+}
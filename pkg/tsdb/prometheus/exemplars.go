@@ -0,0 +1,202 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// ExemplarTraceIDDestination is the configuration of the links of the exemplars with a trace ID label.
+type ExemplarTraceIDDestination struct {
+	// Name is the name of the label of the exemplars with the trace ID.
+	Name string `json:"name"`
+	// URL is the URL of the link, with ${__value.raw} replaced by the trace ID.
+	URL string `json:"url"`
+	// DatasourceUID is the data source linked internally by the frontend, it has no link in the frames
+	// of the backend.
+	DatasourceUID string `json:"datasourceUid"`
+}
+
+// exemplarSeries are the exemplars of a series in the response of the exemplars API.
+type exemplarSeries struct {
+	SeriesLabels model.LabelSet `json:"seriesLabels"`
+	Exemplars    []struct {
+		Labels    model.LabelSet    `json:"labels"`
+		Value     model.SampleValue `json:"value"`
+		Timestamp model.Time        `json:"timestamp"`
+	} `json:"exemplars"`
+}
+
+type exemplarsResponse struct {
+	Status    string           `json:"status"`
+	Data      []exemplarSeries `json:"data"`
+	ErrorType apiv1.ErrorType  `json:"errorType"`
+	Error     string           `json:"error"`
+}
+
+// exemplar is an exemplar with the labels of its series.
+type exemplar struct {
+	timestamp time.Time
+	value     float64
+	labels    map[string]string
+}
+
+// queryExemplars returns the exemplars of the series of the query from the /api/v1/query_exemplars endpoint,
+// which requires Prometheus 2.26 or later.
+func (e *PrometheusExecutor) queryExemplars(ctx context.Context, query *PrometheusQuery) ([]exemplar, error) {
+	u := e.apiClient.URL("/api/v1/query_exemplars", nil)
+	q := u.Query()
+	q.Set("query", query.Expr)
+	q.Set("start", formatTime(query.Start))
+	q.Set("end", formatTime(query.End))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := e.apiClient.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result exemplarsResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse exemplars response with status %d: %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, &apiv1.Error{Type: result.ErrorType, Msg: result.Error}
+	}
+
+	exemplars := make([]exemplar, 0)
+	for _, series := range result.Data {
+		for _, ex := range series.Exemplars {
+			labels := make(map[string]string, len(ex.Labels)+len(series.SeriesLabels))
+			for k, v := range ex.Labels {
+				labels[string(k)] = string(v)
+			}
+			for k, v := range series.SeriesLabels {
+				labels[string(k)] = string(v)
+			}
+			exemplars = append(exemplars, exemplar{
+				timestamp: ex.Timestamp.Time().UTC(),
+				value:     float64(ex.Value),
+				labels:    labels,
+			})
+		}
+	}
+	return exemplars, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// sampleExemplars reduces the density of the exemplars like the frontend does: the highest exemplar of each
+// step is kept, and the lower ones only if they're at least 2 standard deviations away from the previous kept one.
+func sampleExemplars(exemplars []exemplar, step time.Duration) []exemplar {
+	if step <= 0 {
+		step = 15 * time.Second
+	}
+	buckets := make(map[int64][]exemplar)
+	values := make([]float64, 0, len(exemplars))
+	for _, ex := range exemplars {
+		bucket := ex.timestamp.UnixNano() / int64(step)
+		buckets[bucket] = append(buckets[bucket], ex)
+		values = append(values, ex.value)
+	}
+	stdDev := standardDeviation(values)
+
+	keys := make([]int64, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	sampled := make([]exemplar, 0, len(keys))
+	for _, k := range keys {
+		bucket := buckets[k]
+		sort.SliceStable(bucket, func(i, j int) bool { return bucket[i].value > bucket[j].value })
+		sampled = append(sampled, bucket[0])
+		prev := bucket[0].value
+		for _, ex := range bucket[1:] {
+			if stdDev > 0 && prev-ex.value >= 2*stdDev {
+				sampled = append(sampled, ex)
+				prev = ex.value
+			}
+		}
+	}
+	return sampled
+}
+
+// standardDeviation returns the sample standard deviation of the values, 0 if there are less than 2 values.
+func standardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// exemplarFrame returns the frame of the exemplars of a query: a row by exemplar with its time, its value and
+// a field by label of the exemplars and of their series, so that each exemplar is linked to the series frame
+// with the same labels. The fields of the trace ID labels have links to their trace.
+func exemplarFrame(exemplars []exemplar, destinations []ExemplarTraceIDDestination) *data.Frame {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, ex := range exemplars {
+		for k := range ex.labels {
+			if !seen[k] {
+				seen[k] = true
+				names = append(names, k)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	timeVector := make([]time.Time, 0, len(exemplars))
+	values := make([]float64, 0, len(exemplars))
+	labelVectors := make([][]string, len(names))
+	for _, ex := range exemplars {
+		timeVector = append(timeVector, ex.timestamp)
+		values = append(values, ex.value)
+		for i, name := range names {
+			labelVectors[i] = append(labelVectors[i], ex.labels[name])
+		}
+	}
+
+	fields := []*data.Field{data.NewField("time", nil, timeVector), data.NewField("value", nil, values)}
+	for i, name := range names {
+		field := data.NewField(name, nil, labelVectors[i])
+		for _, d := range destinations {
+			if d.Name == name && d.URL != "" {
+				if field.Config == nil {
+					field.Config = &data.FieldConfig{}
+				}
+				field.Config.Links = append(field.Config.Links, data.DataLink{Title: "Go to " + d.URL, URL: d.URL, TargetBlank: true})
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	frame := data.NewFrame("exemplar", fields...)
+	frame.Meta = &data.FrameMeta{Custom: map[string]string{"resultType": "exemplar"}}
+	return frame
+}
@@ -2,13 +2,14 @@ package prometheus
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -30,8 +31,11 @@ func init() {
 }
 
 type PrometheusExecutor struct {
-	client             apiv1.API
+	apiClient          api.Client
+	httpClient         *http.Client
 	intervalCalculator interval.Calculator
+	// exemplarTraceIDDestinations are the links of the exemplars with a trace ID label.
+	exemplarTraceIDDestinations []ExemplarTraceIDDestination
 }
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
@@ -52,9 +56,20 @@ func New(provider httpclient.Provider) func(*models.DataSource) (plugins.DataPlu
 			return nil, err
 		}
 
+		var destinations []ExemplarTraceIDDestination
+		if dsInfo.JsonData != nil {
+			if b, err := dsInfo.JsonData.Get("exemplarTraceIdDestinations").Encode(); err == nil {
+				if err := json.Unmarshal(b, &destinations); err != nil {
+					plog.Warn("Invalid exemplar trace ID destinations", "datasource", dsInfo.Name, "err", err)
+				}
+			}
+		}
+
 		return &PrometheusExecutor{
-			intervalCalculator: interval.NewCalculator(interval.CalculatorOptions{MinInterval: time.Second * 1}),
-			client:             apiv1.NewAPI(client),
+			intervalCalculator:          interval.NewCalculator(interval.CalculatorOptions{MinInterval: time.Second * 1}),
+			apiClient:                   client,
+			httpClient:                  &http.Client{Transport: transport},
+			exemplarTraceIDDestinations: destinations,
 		}, nil
 	}
 }
@@ -72,13 +87,7 @@ func (e *PrometheusExecutor) DataQuery(ctx context.Context, dsInfo *models.DataS
 	}
 
 	for _, query := range queries {
-		timeRange := apiv1.Range{
-			Start: query.Start,
-			End:   query.End,
-			Step:  query.Step,
-		}
-
-		plog.Debug("Sending query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
+		plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr)
 
 		span, ctx := opentracing.StartSpanFromContext(ctx, "datasource.prometheus")
 		span.SetTag("expr", query.Expr)
@@ -86,15 +95,24 @@ func (e *PrometheusExecutor) DataQuery(ctx context.Context, dsInfo *models.DataS
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		value, _, err := e.client.QueryRange(ctx, query.Expr, timeRange)
-
+		queryResult, err := e.queryRange(ctx, query)
 		if err != nil {
 			return result, err
 		}
 
-		queryResult, err := parseResponse(value, query)
-		if err != nil {
-			return result, err
+		if query.Exemplar {
+			// the series are returned even if their exemplars can't be, like by the frontend
+			exemplars, err := e.queryExemplars(ctx, query)
+			if err != nil {
+				plog.Warn("Failed to query exemplars", "query", query.Expr, "err", ConvertAPIError(err))
+			} else if len(exemplars) > 0 {
+				frames, err := queryResult.Dataframes.Decoded()
+				if err != nil {
+					return result, err
+				}
+				frames = append(frames, exemplarFrame(sampleExemplars(exemplars, query.Step), e.exemplarTraceIDDestinations))
+				queryResult.Dataframes = plugins.NewDecodedDataFrames(frames)
+			}
 		}
 		result.Results[query.RefId] = queryResult
 	}
@@ -130,6 +148,7 @@ func (e *PrometheusExecutor) parseQuery(dsInfo *models.DataSource, query plugins
 		}
 
 		format := queryModel.Model.Get("legendFormat").MustString("")
+		exemplar := queryModel.Model.Get("exemplar").MustBool(false)
 
 		start, err := query.TimeRange.ParseFrom()
 		if err != nil {
@@ -141,61 +160,26 @@ func (e *PrometheusExecutor) parseQuery(dsInfo *models.DataSource, query plugins
 			return nil, err
 		}
 
-		dsInterval, err := interval.GetIntervalFrom(dsInfo, queryModel.Model, time.Second*15)
+		step, err := e.calculateStep(dsInfo, queryModel, *query.TimeRange, start, end)
 		if err != nil {
 			return nil, err
 		}
 
-		intervalFactor := queryModel.Model.Get("intervalFactor").MustInt64(1)
-		interval := e.intervalCalculator.Calculate(*query.TimeRange, dsInterval)
-		step := time.Duration(int64(interval.Value) * intervalFactor)
-
 		qs = append(qs, &PrometheusQuery{
-			Expr:         expr,
-			Step:         step,
-			LegendFormat: format,
-			Start:        start,
-			End:          end,
-			RefId:        queryModel.RefID,
+			Expr:          expr,
+			Step:          step,
+			LegendFormat:  format,
+			Start:         start,
+			End:           end,
+			RefId:         queryModel.RefID,
+			Exemplar:      exemplar,
+			MaxDataPoints: maxDataPoints(queryModel),
 		})
 	}
 
 	return qs, nil
 }
 
-//nolint: staticcheck // plugins.DataQueryResult deprecated
-func parseResponse(value model.Value, query *PrometheusQuery) (plugins.DataQueryResult, error) {
-	var queryRes plugins.DataQueryResult
-	frames := data.Frames{}
-
-	matrix, ok := value.(model.Matrix)
-	if !ok {
-		return queryRes, fmt.Errorf("unsupported result format: %q", value.Type().String())
-	}
-
-	for _, v := range matrix {
-		name := formatLegend(v.Metric, query)
-		tags := make(map[string]string, len(v.Metric))
-		timeVector := make([]time.Time, 0, len(v.Values))
-		values := make([]float64, 0, len(v.Values))
-
-		for k, v := range v.Metric {
-			tags[string(k)] = string(v)
-		}
-
-		for _, k := range v.Values {
-			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
-			values = append(values, float64(k.Value))
-		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
-	}
-	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
-
-	return queryRes, nil
-}
-
 // IsAPIError returns whether err is or wraps a Prometheus error.
 func IsAPIError(err error) bool {
 	// Check if the right error type is in err's chain.
@@ -2,7 +2,10 @@ package prometheus
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,6 +119,59 @@ func TestPrometheus(t *testing.T) {
 		require.Equal(t, time.Minute*2, models[0].Step)
 	})
 
+	t.Run("parsing query model with a panel interval shorter than the scrape interval", func(t *testing.T) {
+		jsonData, _ := simplejson.NewJson([]byte(`{"timeInterval": "30s"}`))
+		scrapeDsInfo := &models.DataSource{JsonData: jsonData}
+		query := queryContext(`{
+			"expr": "go_goroutines",
+			"intervalMs": 1000,
+			"refId": "A"
+		}`)
+		timeRange := plugins.NewDataTimeRange("10m", "now")
+		query.TimeRange = &timeRange
+		queries, err := executor.parseQuery(scrapeDsInfo, query)
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, queries[0].Step)
+
+		query = queryContext(`{
+			"expr": "go_goroutines",
+			"intervalMs": 1000,
+			"interval": "5s",
+			"refId": "A"
+		}`)
+		query.TimeRange = &timeRange
+		queries, err = executor.parseQuery(scrapeDsInfo, query)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, queries[0].Step)
+	})
+
+	t.Run("parsing query model with max data points", func(t *testing.T) {
+		query := queryContext(`{
+			"expr": "go_goroutines",
+			"intervalMs": 1000,
+			"maxDataPoints": 100,
+			"refId": "A"
+		}`)
+		models, err := executor.parseQuery(dsInfo, query)
+		require.NoError(t, err)
+		require.Equal(t, 1746*time.Second, models[0].Step)
+		require.Equal(t, int64(100), models[0].MaxDataPoints)
+	})
+
+	t.Run("parsing query model returning more points than Prometheus accepts", func(t *testing.T) {
+		query := queryContext(`{
+			"expr": "go_goroutines",
+			"intervalMs": 1000,
+			"interval": "1s",
+			"refId": "A"
+		}`)
+		timeRange := plugins.NewDataTimeRange("720h", "now")
+		query.TimeRange = &timeRange
+		models, err := executor.parseQuery(dsInfo, query)
+		require.NoError(t, err)
+		require.Equal(t, 236*time.Second, models[0].Step)
+	})
+
 	t.Run("runs query with custom params", func(t *testing.T) {
 		query := queryContext(`{
 			"expr": "go_goroutines",
@@ -146,31 +202,20 @@ func TestParseResponse(t *testing.T) {
 	t.Run("value is not of type matrix", func(t *testing.T) {
 		//nolint: staticcheck // plugins.DataQueryResult deprecated
 		queryRes := plugins.DataQueryResult{}
-		value := p.Vector{}
-		res, err := parseResponse(value, nil)
+		body := `{"status":"success","data":{"resultType":"vector","result":[]}}`
+		res, err := parseResponse(strings.NewReader(body), nil)
 
 		require.Equal(t, queryRes, res)
-		require.Error(t, err)
+		require.EqualError(t, err, `unsupported result format: "vector"`)
 	})
 
 	t.Run("response should be parsed normally", func(t *testing.T) {
-		values := []p.SamplePair{
-			{Value: 1, Timestamp: 1000},
-			{Value: 2, Timestamp: 2000},
-			{Value: 3, Timestamp: 3000},
-			{Value: 4, Timestamp: 4000},
-			{Value: 5, Timestamp: 5000},
-		}
-		value := p.Matrix{
-			&p.SampleStream{
-				Metric: p.Metric{"app": "Application", "tag2": "tag2"},
-				Values: values,
-			},
-		}
+		body := `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"app":"Application","tag2":"tag2"},"values":[[1,"1"],[2,"2"],[3,"3"],[4,"4"],[5.5,"5"]]}]}}`
 		query := &PrometheusQuery{
 			LegendFormat: "legend {{app}}",
 		}
-		res, err := parseResponse(value, query)
+		res, err := parseResponse(strings.NewReader(body), query)
 		require.NoError(t, err)
 
 		decoded, _ := res.Dataframes.Decoded()
@@ -183,9 +228,138 @@ func TestParseResponse(t *testing.T) {
 		require.Equal(t, decoded[0].Fields[1].Labels.String(), "app=Application, tag2=tag2")
 		require.Equal(t, decoded[0].Fields[1].Name, "value")
 		require.Equal(t, decoded[0].Fields[1].Config.DisplayNameFromDS, "legend Application")
+		require.Equal(t, 5, decoded[0].Rows())
+		require.Equal(t, time.Unix(5, 0).UTC(), decoded[0].Fields[0].At(4))
+		require.Equal(t, 5.0, decoded[0].Fields[1].At(4))
 
 		// Ensure the timestamps are UTC zoned
 		testValue := decoded[0].Fields[0].At(0)
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
+
+	t.Run("special values are parsed", func(t *testing.T) {
+		body := `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{},"values":[[1,"NaN"],[2,"+Inf"],[3,"-Inf"]]}]}}`
+		res, err := parseResponse(strings.NewReader(body), &PrometheusQuery{})
+		require.NoError(t, err)
+
+		decoded, _ := res.Dataframes.Decoded()
+		require.Len(t, decoded, 1)
+		require.True(t, math.IsNaN(decoded[0].Fields[1].At(0).(float64)))
+		require.True(t, math.IsInf(decoded[0].Fields[1].At(1).(float64), 1))
+		require.True(t, math.IsInf(decoded[0].Fields[1].At(2).(float64), -1))
+	})
+
+	t.Run("errors are returned as Prometheus errors", func(t *testing.T) {
+		body := `{"status":"error","errorType":"bad_data","error":"parse error"}`
+		_, err := parseResponse(strings.NewReader(body), &PrometheusQuery{})
+		require.True(t, IsAPIError(err))
+		require.EqualError(t, ConvertAPIError(err), "parse error: ")
+	})
+
+	t.Run("invalid responses are rejected", func(t *testing.T) {
+		body := `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1,"a"]]}]}}`
+		_, err := parseResponse(strings.NewReader(body), &PrometheusQuery{})
+		require.Error(t, err)
+
+		_, err = parseResponse(strings.NewReader(`{"status":"success","data":{"resultType":"matrix","result":[`), &PrometheusQuery{})
+		require.Error(t, err)
+	})
+}
+
+func TestExemplars(t *testing.T) {
+	var exemplarsRequest *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/query_range":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"job":"api"},"values":[[1600000000,"1"],[1600000015,"2"]]}]}}`))
+		case "/api/v1/query_exemplars":
+			exemplarsRequest = r
+			_, _ = w.Write([]byte(`{"status":"success","data":[{"seriesLabels":{"job":"api"},"exemplars":[
+				{"labels":{"traceID":"abc"},"value":"6","timestamp":1600000001.5},
+				{"labels":{"traceID":"def"},"value":"1","timestamp":1600000002},
+				{"labels":{"traceID":"ghi"},"value":"2","timestamp":1600000020}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	jsonData, _ := simplejson.NewJson([]byte(`{"exemplarTraceIdDestinations":[{"name":"traceID","url":"http://tracing/trace/${__value.raw}"}]}`))
+	// the HTTP transports are cached by data source, the data source of TestPrometheus is left out
+	dsInfo := &models.DataSource{Id: 2, Url: srv.URL, JsonData: jsonData}
+	plug, err := New(httpclient.NewProvider())(dsInfo)
+	require.NoError(t, err)
+	executor := plug.(*PrometheusExecutor)
+
+	query := queryContext(`{"expr": "http_requests_total", "exemplar": true, "refId": "A"}`)
+	timeRange := plugins.NewDataTimeRange("1600000000000", "1600000060000")
+	query.TimeRange = &timeRange
+	res, err := executor.DataQuery(context.Background(), dsInfo, query)
+	require.NoError(t, err)
+	require.NotNil(t, exemplarsRequest)
+	require.Equal(t, "http_requests_total", exemplarsRequest.URL.Query().Get("query"))
+	require.Equal(t, "1600000000", exemplarsRequest.URL.Query().Get("start"))
+
+	frames, err := res.Results[""].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	exemplars := frames[1]
+	require.Equal(t, "exemplar", exemplars.Name)
+	require.Len(t, exemplars.Fields, 4)
+	require.Equal(t, "job", exemplars.Fields[2].Name)
+	require.Equal(t, "traceID", exemplars.Fields[3].Name)
+	require.Equal(t, "http://tracing/trace/${__value.raw}", exemplars.Fields[3].Config.Links[0].URL)
+
+	// the lower exemplar of the first step is left out
+	require.Equal(t, 2, exemplars.Rows())
+	require.Equal(t, "abc", exemplars.Fields[3].At(0))
+	require.Equal(t, 6.0, exemplars.Fields[1].At(0))
+	require.Equal(t, time.Unix(1600000001, 500000000).UTC(), exemplars.Fields[0].At(0))
+	require.Equal(t, "ghi", exemplars.Fields[3].At(1))
+	require.Equal(t, "api", exemplars.Fields[2].At(1))
+}
+
+func TestParseResponseMaxDataPoints(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"app":"Application"},"values":[[1,"1"],[2,"2"],[3,"3"]]}]}}`
+	res, err := parseResponse(strings.NewReader(body), &PrometheusQuery{MaxDataPoints: 2})
+	require.NoError(t, err)
+
+	decoded, err := res.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	require.Equal(t, 2, decoded[0].Rows())
+	require.Equal(t, 3.0, decoded[0].Fields[1].At(1))
+}
+
+func TestQueryRangeGetFallback(t *testing.T) {
+	methods := make([]string, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		require.Equal(t, "up", r.URL.Query().Get("query"))
+		require.Equal(t, "15", r.URL.Query().Get("step"))
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1,"1"]]}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	// the HTTP transports are cached by data source, the data sources of the other tests are left out
+	plug, err := New(httpclient.NewProvider())(&models.DataSource{Id: 3, Url: srv.URL})
+	require.NoError(t, err)
+	executor := plug.(*PrometheusExecutor)
+
+	res, err := executor.queryRange(context.Background(), &PrometheusQuery{
+		Expr: "up", Start: time.Unix(0, 0), End: time.Unix(60, 0), Step: 15 * time.Second,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{http.MethodPost, http.MethodGet}, methods)
+	decoded, err := res.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
 }
@@ -0,0 +1,284 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/registry"
+)
+
+const (
+	// resourceCacheTTL is the time the responses of the metadata endpoints are cached for, the time ranges
+	// of the requests are aligned to it so that the requests of the same time range share their response.
+	resourceCacheTTL = time.Minute
+	// resourceDefaultTimeRange is the time range of the requests without one, Prometheus would otherwise
+	// return the labels of its whole retention.
+	resourceDefaultTimeRange = 6 * time.Hour
+)
+
+func init() {
+	registry.Register(&registry.Descriptor{
+		Name:         "PrometheusResourceService",
+		InitPriority: registry.Low,
+		Instance:     &ResourceService{},
+	})
+}
+
+// ResourceService serves the metadata, label names and label values endpoints of the Prometheus data sources as
+// resources of the data source, so that the requests of the template variables and of the query editor are cached
+// and can be rate limited by data source instead of going through the data source proxy. The queries of the
+// Prometheus data sources are still run by the PrometheusExecutor.
+type ResourceService struct {
+	BackendPluginManager backendplugin.Manager `inject:""`
+	HTTPClientProvider   httpclient.Provider   `inject:""`
+
+	cache      *localcache.CacheService
+	limitersMu sync.Mutex
+	// limiters are the rate limiters of the data sources by ID, with the last update of their settings.
+	limiters map[int64]*resourceLimiter
+}
+
+type resourceLimiter struct {
+	updated time.Time
+	limiter *rate.Limiter
+}
+
+func (s *ResourceService) Init() error {
+	factory := coreplugin.New(backend.ServeOpts{
+		CallResourceHandler: s.resourceHandler(),
+	})
+	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "prometheus", factory); err != nil {
+		plog.Error("Failed to register plugin", "error", err)
+	}
+	return nil
+}
+
+func (s *ResourceService) resourceHandler() backend.CallResourceHandler {
+	s.cache = localcache.New(resourceCacheTTL, 2*resourceCacheTTL)
+	s.limiters = make(map[int64]*resourceLimiter)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/metadata", s.handleResource)
+	mux.HandleFunc("/api/v1/labels", s.handleResource)
+	mux.HandleFunc("/api/v1/label/", s.handleResource)
+	return httpadapter.New(mux)
+}
+
+// resourceParams are the query parameters forwarded to Prometheus by endpoint.
+var resourceParams = map[string][]string{
+	"metadata": {"metric", "limit"},
+	"labels":   {"start", "end", "match[]"},
+	"values":   {"start", "end", "match[]"},
+}
+
+// resourceEndpoint returns the endpoint of a resource path, or an empty string if it's not served.
+func resourceEndpoint(path string) string {
+	switch {
+	case path == "/api/v1/metadata":
+		return "metadata"
+	case path == "/api/v1/labels":
+		return "labels"
+	case strings.HasPrefix(path, "/api/v1/label/") && strings.HasSuffix(path, "/values") &&
+		strings.Count(path, "/") == 5 && len(path) > len("/api/v1/label//values"):
+		return "values"
+	}
+	return ""
+}
+
+func (s *ResourceService) handleResource(rw http.ResponseWriter, req *http.Request) {
+	endpoint := resourceEndpoint(req.URL.Path)
+	if endpoint == "" {
+		writeResourceError(rw, http.StatusNotFound, "not_found", "unknown resource "+req.URL.Path)
+		return
+	}
+	if req.Method != http.MethodGet {
+		writeResourceError(rw, http.StatusMethodNotAllowed, "bad_data", "method not allowed")
+		return
+	}
+
+	pCtx := httpadapter.PluginConfigFromContext(req.Context())
+	settings := pCtx.DataSourceInstanceSettings
+	if settings == nil {
+		writeResourceError(rw, http.StatusBadRequest, "bad_data", "missing data source")
+		return
+	}
+
+	query := scopeResourceQuery(endpoint, req.URL.Query(), time.Now())
+	cacheKey := fmt.Sprintf("%d/%d%s?%s", settings.ID, settings.Updated.UnixNano(), req.URL.Path, query.Encode())
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		writeResourceResponse(rw, cached.(*resourceResponse))
+		return
+	}
+
+	dsQuery := models.GetDataSourceQuery{Id: settings.ID, OrgId: pCtx.OrgID}
+	if err := bus.Dispatch(&dsQuery); err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, "internal", "failed to get data source")
+		plog.Error("Failed to get data source", "id", settings.ID, "error", err)
+		return
+	}
+	ds := dsQuery.Result
+
+	if !s.allow(ds) {
+		writeResourceError(rw, http.StatusTooManyRequests, "too_many_requests", "rate limit of the data source exceeded")
+		return
+	}
+
+	resp, err := s.fetchResource(req.Context(), ds, req.URL.Path, query)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadGateway, "unavailable", err.Error())
+		return
+	}
+	if resp.status == http.StatusOK {
+		s.cache.SetDefault(cacheKey, resp)
+	}
+	writeResourceResponse(rw, resp)
+}
+
+// allow returns true if the request to the data source is within the rate limit of the data source, set by
+// the resourceRequestsPerSecond field of its settings. The cached responses don't count.
+func (s *ResourceService) allow(ds *models.DataSource) bool {
+	if ds.JsonData == nil {
+		return true
+	}
+	limit := ds.JsonData.Get("resourceRequestsPerSecond").MustFloat64(0)
+	if limit <= 0 {
+		return true
+	}
+
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+	l, ok := s.limiters[ds.Id]
+	if !ok || !l.updated.Equal(ds.Updated) {
+		l = &resourceLimiter{
+			updated: ds.Updated,
+			limiter: rate.NewLimiter(rate.Limit(limit), int(math.Max(1, math.Ceil(limit)))),
+		}
+		s.limiters[ds.Id] = l
+	}
+	return l.limiter.Allow()
+}
+
+type resourceResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+func (s *ResourceService) fetchResource(ctx context.Context, ds *models.DataSource, path string, query url.Values) (*resourceResponse, error) {
+	transport, err := ds.GetHTTPTransport(s.HTTPClientProvider, customQueryParametersMiddleware(plog))
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(ds.Url, "/") + path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceResponse{status: resp.StatusCode, contentType: resp.Header.Get("Content-Type"), body: body}, nil
+}
+
+// scopeResourceQuery returns the parameters of the request forwarded to Prometheus. The time range is aligned
+// to the cache TTL, and set to the default time range if missing.
+func scopeResourceQuery(endpoint string, params url.Values, now time.Time) url.Values {
+	query := url.Values{}
+	for _, name := range resourceParams[endpoint] {
+		values := append([]string(nil), params[name]...)
+		sort.Strings(values)
+		for _, v := range values {
+			query.Add(name, v)
+		}
+	}
+	if endpoint == "metadata" {
+		return query
+	}
+
+	ttl := int64(resourceCacheTTL / time.Second)
+	end, ok := parseResourceTime(query.Get("end"))
+	if !ok {
+		end = now.Unix()
+	}
+	start, ok := parseResourceTime(query.Get("start"))
+	if !ok {
+		start = end - int64(resourceDefaultTimeRange/time.Second)
+	}
+	query.Set("start", strconv.FormatInt(start-start%ttl, 10))
+	query.Set("end", strconv.FormatInt(end-end%ttl+ttl, 10))
+	return query
+}
+
+// parseResourceTime parses a Unix timestamp in seconds or an RFC 3339 time.
+func parseResourceTime(v string) (int64, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return int64(f), true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t.Unix(), true
+	}
+	return 0, false
+}
+
+func writeResourceResponse(rw http.ResponseWriter, resp *resourceResponse) {
+	if resp.contentType != "" {
+		rw.Header().Set("Content-Type", resp.contentType)
+	}
+	rw.WriteHeader(resp.status)
+	if _, err := rw.Write(resp.body); err != nil {
+		plog.Error("Failed to write response", "error", err)
+	}
+}
+
+// writeResourceError writes an error in the format of the errors of the Prometheus API.
+func writeResourceError(rw http.ResponseWriter, status int, errorType string, msg string) {
+	body, err := json.Marshal(struct {
+		Status    string          `json:"status"`
+		ErrorType apiv1.ErrorType `json:"errorType"`
+		Error     string          `json:"error"`
+	}{Status: "error", ErrorType: apiv1.ErrorType(errorType), Error: msg})
+	if err != nil {
+		plog.Error("Failed to marshal error", "error", err)
+	}
+	writeResourceResponse(rw, &resourceResponse{status: status, contentType: "application/json", body: body})
+}
@@ -0,0 +1,115 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestResources(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":["job","instance"]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	updated := time.Now()
+	jsonData := simplejson.NewFromAny(map[string]interface{}{"resourceRequestsPerSecond": 1})
+	bus.AddHandler("test", func(q *models.GetDataSourceQuery) error {
+		q.Result = &models.DataSource{Id: q.Id, OrgId: q.OrgId, Url: srv.URL, JsonData: jsonData, Updated: updated}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	s := &ResourceService{HTTPClientProvider: httpclient.NewProvider()}
+	handler := s.resourceHandler()
+	call := func(datasourceID int64, resourceURL string) *backend.CallResourceResponse {
+		var resp *backend.CallResourceResponse
+		u, err := url.Parse(resourceURL)
+		require.NoError(t, err)
+		err = handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: datasourceID, Updated: updated},
+			},
+			Path:   u.Path,
+			Method: http.MethodGet,
+			URL:    resourceURL,
+		}, resourceResponseSender(func(r *backend.CallResourceResponse) {
+			resp = r
+		}))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("the label names are proxied with their time range aligned", func(t *testing.T) {
+		resp := call(10, "api/v1/labels?start=1600000010&end=1600000070&match[]=up&other=1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"status":"success","data":["job","instance"]}`, string(resp.Body))
+		require.Len(t, requests, 1)
+		require.Equal(t, "/api/v1/labels", requests[0].URL.Path)
+		require.Equal(t, url.Values{"start": {"1599999960"}, "end": {"1600000080"}, "match[]": {"up"}}, requests[0].URL.Query())
+	})
+
+	t.Run("the responses are cached and don't count in the rate limit", func(t *testing.T) {
+		resp := call(10, "api/v1/labels?start=1600000015&end=1600000075&match[]=up")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Len(t, requests, 1)
+	})
+
+	t.Run("the requests over the rate limit of the data source are rejected", func(t *testing.T) {
+		resp := call(10, "api/v1/label/job/values?start=1600000000&end=1600000060")
+		require.Equal(t, http.StatusTooManyRequests, resp.Status)
+		require.Len(t, requests, 1)
+
+		resp = call(11, "api/v1/label/job/values")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Len(t, requests, 2)
+		require.Equal(t, "/api/v1/label/job/values", requests[1].URL.Path)
+		start, err := strconv.ParseInt(requests[1].URL.Query().Get("start"), 10, 64)
+		require.NoError(t, err)
+		end, err := strconv.ParseInt(requests[1].URL.Query().Get("end"), 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, int64(resourceDefaultTimeRange/time.Second+resourceCacheTTL/time.Second), end-start)
+	})
+
+	t.Run("the other paths aren't served", func(t *testing.T) {
+		resp := call(12, "api/v1/query")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+		resp = call(12, "api/v1/label/job/other")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+	})
+}
+
+type resourceResponseSender func(*backend.CallResourceResponse)
+
+func (f resourceResponseSender) Send(resp *backend.CallResourceResponse) error {
+	f(resp)
+	return nil
+}
+
+func TestScopeResourceQuery(t *testing.T) {
+	now := time.Unix(1600000030, 0)
+	query := scopeResourceQuery("values", url.Values{}, now)
+	require.Equal(t, url.Values{"start": {"1599978420"}, "end": {"1600000080"}}, query)
+
+	query = scopeResourceQuery("values", url.Values{"start": {"2020-09-13T12:26:40Z"}, "end": {"1600000030.5"}}, now)
+	require.Equal(t, url.Values{"start": {"1599999960"}, "end": {"1600000080"}}, query)
+
+	query = scopeResourceQuery("metadata", url.Values{"metric": {"up"}, "start": {"1600000000"}}, now)
+	require.Equal(t, url.Values{"metric": {"up"}}, query)
+}
@@ -0,0 +1,184 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/plugins"
+	jsoniter "github.com/json-iterator/go"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// queryRange runs a range query and parses its response while it's read, so that neither the body of the
+// response nor its samples are held in memory besides the frames built from them.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func (e *PrometheusExecutor) queryRange(ctx context.Context, query *PrometheusQuery) (plugins.DataQueryResult, error) {
+	args := url.Values{}
+	args.Set("query", query.Expr)
+	args.Set("start", formatTime(query.Start))
+	args.Set("end", formatTime(query.End))
+	args.Set("step", strconv.FormatFloat(query.Step.Seconds(), 'f', -1, 64))
+	u := e.apiClient.URL("/api/v1/query_range", nil)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(args.Encode()))
+	if err != nil {
+		return plugins.DataQueryResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return plugins.DataQueryResult{}, err
+	}
+
+	// Prometheus older than 2.1 only accepts GET requests
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		closeBody(resp)
+		u.RawQuery = args.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return plugins.DataQueryResult{}, err
+		}
+		resp, err = e.httpClient.Do(req)
+		if err != nil {
+			return plugins.DataQueryResult{}, err
+		}
+	}
+	defer closeBody(resp)
+
+	result, err := parseResponse(resp.Body, query)
+	if err != nil && !IsAPIError(err) && resp.StatusCode/100 != 2 {
+		return result, &apiv1.Error{Type: apiv1.ErrBadResponse, Msg: fmt.Sprintf("bad response code %d", resp.StatusCode), Detail: err.Error()}
+	}
+	return result, err
+}
+
+func closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		plog.Warn("Failed to close response body", "err", err)
+	}
+}
+
+// parseResponse parses the response of a range query into a frame by series while it's read.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func parseResponse(r io.Reader, query *PrometheusQuery) (plugins.DataQueryResult, error) {
+	var queryRes plugins.DataQueryResult
+	iter := jsoniter.Parse(jsoniter.ConfigDefault, r, 4096)
+
+	var (
+		status, errorType, errorMsg, resultType string
+		frames                                  data.Frames
+	)
+	for field := iter.ReadObject(); field != "" && iter.Error == nil; field = iter.ReadObject() {
+		switch field {
+		case "status":
+			status = iter.ReadString()
+		case "errorType":
+			errorType = iter.ReadString()
+		case "error":
+			errorMsg = iter.ReadString()
+		case "data":
+			for dataField := iter.ReadObject(); dataField != "" && iter.Error == nil; dataField = iter.ReadObject() {
+				switch {
+				case dataField == "resultType":
+					resultType = iter.ReadString()
+				case dataField == "result" && (resultType == "" || resultType == "matrix"):
+					frames = readMatrix(iter, query)
+				default:
+					iter.Skip()
+				}
+			}
+		default:
+			iter.Skip()
+		}
+	}
+	if iter.Error != nil && iter.Error != io.EOF {
+		return queryRes, fmt.Errorf("failed to parse response: %w", iter.Error)
+	}
+
+	if status == "error" {
+		return queryRes, &apiv1.Error{Type: apiv1.ErrorType(errorType), Msg: errorMsg}
+	}
+	if resultType != "matrix" {
+		return queryRes, fmt.Errorf("unsupported result format: %q", resultType)
+	}
+	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
+	return queryRes, nil
+}
+
+// readMatrix reads the series of a matrix result.
+func readMatrix(iter *jsoniter.Iterator, query *PrometheusQuery) data.Frames {
+	frames := data.Frames{}
+	for iter.ReadArray() {
+		metric := model.Metric{}
+		timeVector := make([]time.Time, 0)
+		values := make([]float64, 0)
+		for field := iter.ReadObject(); field != "" && iter.Error == nil; field = iter.ReadObject() {
+			switch field {
+			case "metric":
+				for name := iter.ReadObject(); name != "" && iter.Error == nil; name = iter.ReadObject() {
+					metric[model.LabelName(name)] = model.LabelValue(iter.ReadString())
+				}
+			case "values":
+				for iter.ReadArray() {
+					timestamp, value := readSample(iter)
+					timeVector = append(timeVector, timestamp)
+					values = append(values, value)
+				}
+			default:
+				iter.Skip()
+			}
+		}
+		if iter.Error != nil {
+			return frames
+		}
+		frames = append(frames, newSeriesFrame(metric, timeVector, values, query))
+	}
+	return frames
+}
+
+// readSample reads a [<unix time>, "<value>"] sample, the time is truncated to the second.
+func readSample(iter *jsoniter.Iterator) (time.Time, float64) {
+	if !iter.ReadArray() {
+		iter.ReportError("readSample", "missing sample time")
+		return time.Time{}, 0
+	}
+	timestamp := iter.ReadFloat64()
+	if !iter.ReadArray() {
+		iter.ReportError("readSample", "missing sample value")
+		return time.Time{}, 0
+	}
+	value, err := strconv.ParseFloat(iter.ReadString(), 64)
+	if err != nil {
+		iter.ReportError("readSample", err.Error())
+	}
+	if iter.ReadArray() {
+		iter.ReportError("readSample", "unexpected sample element")
+	}
+	return time.Unix(int64(timestamp), 0).UTC(), value
+}
+
+func newSeriesFrame(metric model.Metric, timeVector []time.Time, values []float64, query *PrometheusQuery) *data.Frame {
+	name := formatLegend(metric, query)
+	tags := make(map[string]string, len(metric))
+	for k, v := range metric {
+		tags[string(k)] = string(v)
+	}
+
+	// the step returns at most the max data points, Prometheus aligning the time range to the step
+	// is the only reason there could be more
+	if query.MaxDataPoints > 0 && int64(len(values)) > query.MaxDataPoints {
+		timeVector = timeVector[int64(len(timeVector))-query.MaxDataPoints:]
+		values = values[int64(len(values))-query.MaxDataPoints:]
+	}
+	return data.NewFrame(name,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}))
+}
@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/interval"
+)
+
+const (
+	// defaultScrapeInterval is the scrape interval of the data sources without one.
+	defaultScrapeInterval = 15 * time.Second
+	// prometheusMaxPoints is the maximum number of points of a series Prometheus returns, it rejects
+	// the range queries with a step returning more.
+	prometheusMaxPoints = 11000
+)
+
+// calculateStep returns the step of a range query. It doesn't rely on the step calculated by the frontend, so that
+// the queries of the alert rules defined with the API are stepped like the queries of the panels. The step is:
+//
+// - the panel interval, or else the interval of the time range at the default resolution, times the interval factor,
+// - at least the min step of the query, or else the scrape interval of the data source,
+// - large enough for the time range to fit in the max data points of the query and in the points Prometheus accepts.
+func (e *PrometheusExecutor) calculateStep(dsInfo *models.DataSource, query plugins.DataSubQuery, timeRange plugins.DataTimeRange, start, end time.Time) (time.Duration, error) {
+	minStep, err := parseMinStep(dsInfo, query.Model)
+	if err != nil {
+		return 0, err
+	}
+
+	panelInterval := time.Duration(query.Model.Get("intervalMs").MustInt64(query.IntervalMS)) * time.Millisecond
+	if panelInterval <= 0 {
+		panelInterval = e.intervalCalculator.Calculate(timeRange, minStep).Value
+	}
+
+	intervalFactor := query.Model.Get("intervalFactor").MustInt64(1)
+	if intervalFactor < 1 {
+		intervalFactor = 1
+	}
+	step := panelInterval * time.Duration(intervalFactor)
+	if step < minStep {
+		step = minStep
+	}
+
+	timeRangeDuration := end.Sub(start)
+	if maxDataPoints := maxDataPoints(query); maxDataPoints > 0 {
+		if s := safeStep(timeRangeDuration, maxDataPoints); s > step {
+			step = s
+		}
+	}
+	if s := safeStep(timeRangeDuration, prometheusMaxPoints); s > step {
+		step = s
+	}
+	return step, nil
+}
+
+// parseMinStep returns the min step of the query, or else the scrape interval of the data source.
+func parseMinStep(dsInfo *models.DataSource, model *simplejson.Json) (time.Duration, error) {
+	m := simplejson.New()
+	m.Set("interval", model.Get("interval").MustString(""))
+	return interval.GetIntervalFrom(dsInfo, m, defaultScrapeInterval)
+}
+
+// maxDataPoints returns the maximum number of points of the series of the query, 0 if there's no maximum.
+func maxDataPoints(query plugins.DataSubQuery) int64 {
+	return query.Model.Get("maxDataPoints").MustInt64(query.MaxDataPoints)
+}
+
+// safeStep returns the smallest step returning at most maxPoints points for the time range, both ends included,
+// rounded up to the second if it's longer than a second.
+func safeStep(timeRange time.Duration, maxPoints int64) time.Duration {
+	intervals := maxPoints - 1
+	if intervals < 1 {
+		intervals = 1
+	}
+	step := time.Duration((int64(timeRange) + intervals - 1) / intervals)
+	if step > time.Second && step%time.Second != 0 {
+		step = step.Truncate(time.Second) + time.Second
+	}
+	return step
+}
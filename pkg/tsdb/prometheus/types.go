@@ -9,4 +9,8 @@ type PrometheusQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefId        string
+	// Exemplar is true if the exemplars of the series are queried too.
+	Exemplar bool
+	// MaxDataPoints is the maximum number of points of the series, 0 if there's no maximum.
+	MaxDataPoints int64
 }
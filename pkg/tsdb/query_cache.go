@@ -0,0 +1,280 @@
+package tsdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	cacheStatusHit  = "hit"
+	cacheStatusMiss = "miss"
+)
+
+type queryCachingKey struct{}
+
+// WithQueryCaching returns a context allowing the query results to be cached. Only the queries of the dashboards and
+// of Explore are cached, the alert rules and the expressions have to evaluate the current data.
+func WithQueryCaching(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCachingKey{}, true)
+}
+
+func isQueryCachingAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(queryCachingKey{}).(bool)
+	return allowed
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+type handleRequestFunc func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error)
+
+// queryCache caches the results of the data source queries in the remote cache, encrypted with the secret key.
+// The results are keyed on the data source, the queries and the time range truncated to the TTL, so the queries of
+// the same dashboard refreshed by many users within the TTL are only run once.
+type queryCache struct {
+	store        remotecache.CacheStorage
+	defaultTTL   time.Duration
+	maxValueSize int
+	secretKey    string
+	log          log.Logger
+}
+
+// cachedResult is the cached part of a query result. The data frames are Arrow encoded.
+type cachedResult struct {
+	Meta       *simplejson.Json            `json:"meta,omitempty"`
+	Series     plugins.DataTimeSeriesSlice `json:"series,omitempty"`
+	Tables     []plugins.DataTable         `json:"tables,omitempty"`
+	Dataframes [][]byte                    `json:"dataframes,omitempty"`
+}
+
+type cachedResponse struct {
+	CachedAt time.Time               `json:"cachedAt"`
+	Results  map[string]cachedResult `json:"results"`
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) handleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery,
+	next handleRequestFunc) (plugins.DataResponse, error) {
+	if !isQueryCachingAllowed(ctx) || !c.isEnabledForOrg(ctx, ds.OrgId) {
+		return next(ctx, ds, query)
+	}
+
+	ttl := c.ttl(ds)
+	// the results of the data sources forwarding the identity of the user can't be shared
+	if ttl <= 0 || query.Debug || oauthtoken.IsOAuthPassThruEnabled(ds) {
+		return next(ctx, ds, query)
+	}
+
+	key, err := cacheKey(ds, query, ttl)
+	if err != nil {
+		c.log.Warn("Failed to compute the query cache key", "datasource", ds.Name, "error", err)
+		return next(ctx, ds, query)
+	}
+
+	if resp, ok := c.get(key); ok {
+		return resp, nil
+	}
+
+	resp, err := next(ctx, ds, query)
+	if err != nil {
+		return resp, err
+	}
+	c.set(key, resp, ttl)
+	annotateCacheStatus(resp, cacheStatusMiss, time.Time{})
+	return resp, nil
+}
+
+// isEnabledForOrg returns false when the org admins turned the query caching off in the org preferences, or when the
+// preferences can't be read.
+func (c *queryCache) isEnabledForOrg(ctx context.Context, orgID int64) bool {
+	query := models.GetPreferencesQuery{OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		c.log.Warn("Failed to get the org preferences", "orgId", orgID, "error", err)
+		return false
+	}
+	return !query.Result.QueryCachingDisabled
+}
+
+// ttl returns the TTL of the data source from the queryCachingTTL of its JSON data, or the default TTL.
+func (c *queryCache) ttl(ds *models.DataSource) time.Duration {
+	if ds.JsonData == nil {
+		return c.defaultTTL
+	}
+	value, ok := ds.JsonData.CheckGet("queryCachingTTL")
+	if !ok {
+		return c.defaultTTL
+	}
+	ttl, err := gtime.ParseDuration(value.MustString())
+	if err != nil {
+		c.log.Warn("Invalid query caching TTL", "datasource", ds.Name, "ttl", value.Interface())
+		return 0
+	}
+	return ttl
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) get(key string) (plugins.DataResponse, bool) {
+	value, err := c.store.Get(key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			c.log.Warn("Failed to get the cached query result", "error", err)
+		}
+		return plugins.DataResponse{}, false
+	}
+	encrypted, ok := value.([]byte)
+	if !ok {
+		return plugins.DataResponse{}, false
+	}
+	payload, err := util.Decrypt(encrypted, c.secretKey)
+	if err != nil {
+		c.log.Warn("Failed to decrypt the cached query result", "error", err)
+		return plugins.DataResponse{}, false
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(payload, &cached); err != nil {
+		c.log.Warn("Failed to decode the cached query result", "error", err)
+		return plugins.DataResponse{}, false
+	}
+
+	resp := plugins.DataResponse{Results: make(map[string]plugins.DataQueryResult, len(cached.Results))}
+	for refID, result := range cached.Results {
+		queryResult := plugins.DataQueryResult{
+			RefID:  refID,
+			Meta:   result.Meta,
+			Series: result.Series,
+			Tables: result.Tables,
+		}
+		if result.Dataframes != nil {
+			queryResult.Dataframes = plugins.NewEncodedDataFrames(result.Dataframes)
+		}
+		resp.Results[refID] = queryResult
+	}
+	annotateCacheStatus(resp, cacheStatusHit, cached.CachedAt)
+	return resp, true
+}
+
+// set caches the response unless one of its results failed.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) set(key string, resp plugins.DataResponse, ttl time.Duration) {
+	cached := cachedResponse{CachedAt: time.Now(), Results: make(map[string]cachedResult, len(resp.Results))}
+	for refID, result := range resp.Results {
+		if result.Error != nil || result.ErrorString != "" {
+			return
+		}
+		cachedResult := cachedResult{Meta: result.Meta, Series: result.Series, Tables: result.Tables}
+		if result.Dataframes != nil {
+			encoded, err := result.Dataframes.Encoded()
+			if err != nil {
+				c.log.Warn("Failed to encode the data frames of the query result", "error", err)
+				return
+			}
+			cachedResult.Dataframes = encoded
+		}
+		cached.Results[refID] = cachedResult
+	}
+
+	payload, err := json.Marshal(cached)
+	if err != nil {
+		c.log.Warn("Failed to encode the query result", "error", err)
+		return
+	}
+	if c.maxValueSize > 0 && len(payload) > c.maxValueSize {
+		c.log.Debug("Query result too large to be cached", "size", len(payload))
+		return
+	}
+	encrypted, err := util.Encrypt(payload, c.secretKey)
+	if err != nil {
+		c.log.Warn("Failed to encrypt the query result", "error", err)
+		return
+	}
+	if err := c.store.Set(key, encrypted, ttl); err != nil {
+		c.log.Warn("Failed to cache the query result", "error", err)
+	}
+}
+
+// cacheKey returns the key of the cached response of the query. The version of the data source is part of the key
+// so the cached results are dropped when it is updated, and the time range is truncated to the TTL so the
+// queries of relative time ranges use the same key within the TTL.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func cacheKey(ds *models.DataSource, query plugins.DataQuery, ttl time.Duration) (string, error) {
+	type keyQuery struct {
+		RefID         string           `json:"refId"`
+		Model         *simplejson.Json `json:"model"`
+		MaxDataPoints int64            `json:"maxDataPoints"`
+		IntervalMS    int64            `json:"intervalMs"`
+		QueryType     string           `json:"queryType"`
+	}
+	key := struct {
+		From    int64             `json:"from"`
+		To      int64             `json:"to"`
+		Headers map[string]string `json:"headers"`
+		Queries []keyQuery        `json:"queries"`
+	}{Headers: query.Headers}
+
+	if query.TimeRange != nil {
+		from, err := query.TimeRange.ParseFrom()
+		if err != nil {
+			return "", err
+		}
+		to, err := query.TimeRange.ParseTo()
+		if err != nil {
+			return "", err
+		}
+		key.From = from.Truncate(ttl).UnixNano()
+		key.To = to.Truncate(ttl).UnixNano()
+	}
+	for _, q := range query.Queries {
+		key.Queries = append(key.Queries, keyQuery{
+			RefID:         q.RefID,
+			Model:         q.Model,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMS:    q.IntervalMS,
+			QueryType:     q.QueryType,
+		})
+	}
+
+	payload, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("query-cache-%d-%d-%d-%x", ds.OrgId, ds.Id, ds.Version, sha256.Sum256(payload)), nil
+}
+
+// annotateCacheStatus sets the cache status in the meta of the results, and adds a notice to the data frames of
+// the cached results.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func annotateCacheStatus(resp plugins.DataResponse, status string, cachedAt time.Time) {
+	for refID, result := range resp.Results {
+		if result.Meta == nil {
+			result.Meta = simplejson.New()
+		}
+		result.Meta.Set("cacheStatus", status)
+
+		if status == cacheStatusHit && result.Dataframes != nil {
+			frames, err := result.Dataframes.Decoded()
+			if err == nil {
+				for _, frame := range frames {
+					frame.AppendNotices(data.Notice{
+						Severity: data.NoticeSeverityInfo,
+						Text:     fmt.Sprintf("Cached result from %s", cachedAt.UTC().Format(time.RFC3339)),
+					})
+				}
+				result.Dataframes = plugins.NewDecodedDataFrames(frames)
+			}
+		}
+		resp.Results[refID] = result
+	}
+}
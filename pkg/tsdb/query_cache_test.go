@@ -0,0 +1,207 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache(t *testing.T) {
+	ctx := WithQueryCaching(context.Background())
+	now := time.Date(2021, 6, 1, 12, 0, 30, 0, time.UTC)
+
+	disabledOrgs := map[int64]bool{}
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetPreferencesQuery) error {
+		query.Result = &models.Preferences{OrgId: query.OrgId, QueryCachingDisabled: disabledOrgs[query.OrgId]}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	newQuery := func(now time.Time, expr string) plugins.DataQuery {
+		return plugins.DataQuery{
+			TimeRange: &plugins.DataTimeRange{From: "now-1h", To: "now", Now: now},
+			Queries: []plugins.DataSubQuery{
+				{RefID: "A", Model: simplejson.NewFromAny(map[string]interface{}{"expr": expr})},
+			},
+		}
+	}
+
+	setup := func(jsonData map[string]interface{}) (Service, *models.DataSource, *fakeCacheStorage, *int) {
+		svc, exe, _ := createService()
+		store := &fakeCacheStorage{items: map[string]interface{}{}}
+		svc.queryCache = &queryCache{
+			store:      store,
+			defaultTTL: time.Minute,
+			secretKey:  "secret",
+			log:        log.New("test"),
+		}
+		calls := 0
+		exe.HandleQuery("A", func(query plugins.DataQuery) plugins.DataQueryResult {
+			calls++
+			return plugins.DataQueryResult{
+				RefID: "A",
+				Dataframes: plugins.NewDecodedDataFrames(data.Frames{
+					data.NewFrame("series", data.NewField("value", nil, []float64{float64(calls)})),
+				}),
+			}
+		})
+		ds := &models.DataSource{Id: 1, OrgId: 1, Type: "test", JsonData: simplejson.NewFromAny(jsonData)}
+		return svc, ds, store, &calls
+	}
+
+	frameValue := func(t *testing.T, resp plugins.DataResponse) (float64, []data.Notice) {
+		frames, err := resp.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		var notices []data.Notice
+		if frames[0].Meta != nil {
+			notices = frames[0].Meta.Notices
+		}
+		return frames[0].Fields[0].At(0).(float64), notices
+	}
+
+	t.Run("Should return the cached result of the same query in the same time bucket", func(t *testing.T) {
+		svc, ds, store, calls := setup(nil)
+
+		resp, err := svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+		require.NoError(t, err)
+		require.Equal(t, "miss", resp.Results["A"].Meta.Get("cacheStatus").MustString())
+		value, notices := frameValue(t, resp)
+		require.Equal(t, 1.0, value)
+		require.Empty(t, notices)
+		require.Len(t, store.items, 1)
+		for _, item := range store.items {
+			require.NotContains(t, string(item.([]byte)), "series")
+		}
+
+		resp, err = svc.HandleRequest(ctx, ds, newQuery(now.Add(20*time.Second), "up"))
+		require.NoError(t, err)
+		require.Equal(t, 1, *calls)
+		require.Equal(t, "hit", resp.Results["A"].Meta.Get("cacheStatus").MustString())
+		value, notices = frameValue(t, resp)
+		require.Equal(t, 1.0, value)
+		require.Len(t, notices, 1)
+		require.Equal(t, data.NoticeSeverityInfo, notices[0].Severity)
+
+		_, err = svc.HandleRequest(ctx, ds, newQuery(now.Add(time.Minute), "up"))
+		require.NoError(t, err)
+		require.Equal(t, 2, *calls)
+
+		_, err = svc.HandleRequest(ctx, ds, newQuery(now, "down"))
+		require.NoError(t, err)
+		require.Equal(t, 3, *calls)
+
+		ds.Version++
+		_, err = svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+		require.NoError(t, err)
+		require.Equal(t, 4, *calls)
+	})
+
+	t.Run("Should use the TTL of the data source", func(t *testing.T) {
+		svc, ds, store, calls := setup(map[string]interface{}{"queryCachingTTL": "0s"})
+		for i := 0; i < 2; i++ {
+			_, err := svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+			require.NoError(t, err)
+		}
+		require.Equal(t, 2, *calls)
+		require.Empty(t, store.items)
+
+		svc, ds, _, calls = setup(map[string]interface{}{"queryCachingTTL": "1h"})
+		for i := 0; i < 2; i++ {
+			_, err := svc.HandleRequest(ctx, ds, newQuery(now.Add(time.Duration(i)*10*time.Minute), "up"))
+			require.NoError(t, err)
+		}
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("Should not cache the results of the requests not allowing it", func(t *testing.T) {
+		svc, ds, store, calls := setup(nil)
+		for i := 0; i < 2; i++ {
+			resp, err := svc.HandleRequest(context.Background(), ds, newQuery(now, "up"))
+			require.NoError(t, err)
+			require.Nil(t, resp.Results["A"].Meta)
+		}
+		require.Equal(t, 2, *calls)
+		require.Empty(t, store.items)
+	})
+
+	t.Run("Should not cache the results of the data sources forwarding the user identity", func(t *testing.T) {
+		svc, ds, store, calls := setup(map[string]interface{}{"oauthPassThru": true})
+		for i := 0; i < 2; i++ {
+			_, err := svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+			require.NoError(t, err)
+		}
+		require.Equal(t, 2, *calls)
+		require.Empty(t, store.items)
+	})
+
+	t.Run("Should not cache the results of the orgs with the query caching disabled", func(t *testing.T) {
+		svc, ds, store, calls := setup(nil)
+		disabledOrgs[ds.OrgId] = true
+		t.Cleanup(func() { delete(disabledOrgs, ds.OrgId) })
+
+		for i := 0; i < 2; i++ {
+			resp, err := svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+			require.NoError(t, err)
+			require.Nil(t, resp.Results["A"].Meta)
+		}
+		require.Equal(t, 2, *calls)
+		require.Empty(t, store.items)
+
+		ds.OrgId = 2
+		for i := 0; i < 2; i++ {
+			_, err := svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+			require.NoError(t, err)
+		}
+		require.Equal(t, 3, *calls)
+	})
+
+	t.Run("Should not cache the failed results", func(t *testing.T) {
+		svc, ds, store, _ := setup(nil)
+		exe := &fakeExecutor{
+			results: map[string]plugins.DataQueryResult{"A": {RefID: "A", Error: errTest}},
+		}
+		svc.registry["test"] = func(*models.DataSource) (plugins.DataPlugin, error) {
+			return exe, nil
+		}
+
+		resp, err := svc.HandleRequest(ctx, ds, newQuery(now, "up"))
+		require.NoError(t, err)
+		require.Equal(t, errTest, resp.Results["A"].Error)
+		require.Empty(t, store.items)
+	})
+}
+
+var errTest = errors.New("query failed")
+
+type fakeCacheStorage struct {
+	items map[string]interface{}
+}
+
+func (s *fakeCacheStorage) Get(key string) (interface{}, error) {
+	item, ok := s.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return item, nil
+}
+
+func (s *fakeCacheStorage) Set(key string, value interface{}, expire time.Duration) error {
+	s.items[key] = value
+	return nil
+}
+
+func (s *fakeCacheStorage) Delete(key string) error {
+	delete(s.items, key)
+	return nil
+}
@@ -0,0 +1,105 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+var (
+	// ErrDataSourceOverloaded is returned when a data source already runs its max number of concurrent queries.
+	ErrDataSourceOverloaded = errors.New("data source overloaded")
+	// ErrDataSourceQueryTimeout is returned when the query of a data source takes longer than its timeout.
+	ErrDataSourceQueryTimeout = errors.New("data source query timeout")
+)
+
+// queryLimiter enforces the query timeout and the max number of concurrent queries of the data sources. They are
+// set by the queryDispatchTimeout and maxConcurrentQueries of the JSON data of a data source, or by the defaults of
+// the configuration.
+type queryLimiter struct {
+	defaultTimeout       time.Duration
+	defaultMaxConcurrent int
+
+	mu      sync.Mutex
+	running map[int64]int
+}
+
+func newQueryLimiter(defaultTimeout time.Duration, defaultMaxConcurrent int) *queryLimiter {
+	return &queryLimiter{
+		defaultTimeout:       defaultTimeout,
+		defaultMaxConcurrent: defaultMaxConcurrent,
+		running:              map[int64]int{},
+	}
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (l *queryLimiter) handleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery,
+	next handleRequestFunc) (plugins.DataResponse, error) {
+	timeout, maxConcurrent := l.limits(ds)
+
+	if maxConcurrent > 0 {
+		if !l.acquire(ds.Id, maxConcurrent) {
+			return plugins.DataResponse{}, fmt.Errorf("%w: %q already runs %d queries", ErrDataSourceOverloaded,
+				ds.Name, maxConcurrent)
+		}
+		defer l.release(ds.Id)
+	}
+
+	if timeout <= 0 {
+		return next(ctx, ds, query)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := next(ctx, ds, query)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return resp, fmt.Errorf("%w: %q didn't respond within %s", ErrDataSourceQueryTimeout, ds.Name, timeout)
+	}
+	return resp, err
+}
+
+func (l *queryLimiter) limits(ds *models.DataSource) (time.Duration, int) {
+	timeout, maxConcurrent := l.defaultTimeout, l.defaultMaxConcurrent
+	if ds.JsonData == nil {
+		return timeout, maxConcurrent
+	}
+
+	if value, ok := ds.JsonData.CheckGet("queryDispatchTimeout"); ok {
+		if parsed, err := gtime.ParseDuration(value.MustString()); err == nil && parsed >= 0 {
+			timeout = parsed
+		}
+	}
+	if value, ok := ds.JsonData.CheckGet("maxConcurrentQueries"); ok {
+		if parsed, err := value.Int(); err == nil && parsed >= 0 {
+			maxConcurrent = parsed
+		}
+	}
+	return timeout, maxConcurrent
+}
+
+func (l *queryLimiter) acquire(dsID int64, maxConcurrent int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running[dsID] >= maxConcurrent {
+		return false
+	}
+	l.running[dsID]++
+	return true
+}
+
+func (l *queryLimiter) release(dsID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.running[dsID]--
+	if l.running[dsID] <= 0 {
+		delete(l.running, dsID)
+	}
+}
@@ -0,0 +1,80 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	query := plugins.DataQuery{Queries: []plugins.DataSubQuery{{RefID: "A"}}}
+
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	setup := func(limiter *queryLimiter, fn plugins.DataPluginFunc) Service {
+		svc, _, _ := createService()
+		svc.queryLimiter = limiter
+		svc.registry["test"] = func(*models.DataSource) (plugins.DataPlugin, error) {
+			return fn, nil
+		}
+		return svc
+	}
+
+	t.Run("Should reject the queries over the concurrency limit of the data source", func(t *testing.T) {
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		//nolint: staticcheck // plugins.DataPlugin deprecated
+		svc := setup(newQueryLimiter(0, 5), func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			started <- struct{}{}
+			<-unblock
+			return plugins.DataResponse{}, nil
+		})
+		ds := &models.DataSource{Id: 1, Name: "fragile", Type: "test",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"maxConcurrentQueries": 2})}
+
+		errs := make(chan error)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := svc.HandleRequest(context.Background(), ds, query)
+				errs <- err
+			}()
+			<-started
+		}
+
+		_, err := svc.HandleRequest(context.Background(), ds, query)
+		require.True(t, errors.Is(err, ErrDataSourceOverloaded))
+		require.EqualError(t, err, `data source overloaded: "fragile" already runs 2 queries`)
+
+		other := &models.DataSource{Id: 2, Type: "test"}
+		go func() {
+			_, err := svc.HandleRequest(context.Background(), other, query)
+			errs <- err
+		}()
+		<-started
+
+		close(unblock)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, <-errs)
+		}
+		require.Empty(t, svc.queryLimiter.running)
+	})
+
+	t.Run("Should cancel the queries over the timeout of the data source", func(t *testing.T) {
+		//nolint: staticcheck // plugins.DataPlugin deprecated
+		svc := setup(newQueryLimiter(time.Hour, 0), func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			<-ctx.Done()
+			return plugins.DataResponse{}, ctx.Err()
+		})
+		ds := &models.DataSource{Id: 1, Name: "slow", Type: "test",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"queryDispatchTimeout": "10ms"})}
+
+		_, err := svc.HandleRequest(context.Background(), ds, query)
+		require.True(t, errors.Is(err, ErrDataSourceQueryTimeout))
+		require.EqualError(t, err, `data source query timeout: "slow" didn't respond within 10ms`)
+	})
+}
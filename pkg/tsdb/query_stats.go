@@ -0,0 +1,95 @@
+package tsdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// handleRequestWithStats runs the query and attaches its stats to the frames of the results, so the query inspector
+// and the alert evaluations show what was sent to the data source: the executed query, the duration of the query
+// and the outgoing HTTP requests of the data source.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (s *Service) handleRequestWithStats(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	ctx, requestStats := httpclient.WithRequestStats(ctx)
+	start := time.Now()
+	resp, err := s.handleRequest(ctx, ds, query)
+	if err != nil {
+		return resp, err
+	}
+
+	attachQueryStats(resp, queryStats(time.Since(start), requestStats.Snapshot()))
+	return resp, nil
+}
+
+// queryStats returns the stats of a query. The stats of the HTTP requests are those of all the queries of the
+// request, as the data sources may send one request for several queries.
+func queryStats(duration time.Duration, requests httpclient.RequestStatsSnapshot) []data.QueryStat {
+	stats := []data.QueryStat{newQueryStat("Query duration", "ms", float64(duration.Milliseconds()))}
+	if requests.Requests == 0 {
+		return stats
+	}
+
+	stats = append(stats,
+		newQueryStat("Upstream requests", "none", float64(requests.Requests)),
+		newQueryStat("Upstream latency", "ms", float64(requests.Latency.Milliseconds())),
+		newQueryStat("Upstream request size", "decbytes", float64(requests.RequestBytes)),
+		newQueryStat("Upstream response size", "decbytes", float64(requests.ResponseBytes)),
+	)
+	if requests.Failed > 0 {
+		stats = append(stats, newQueryStat("Failed upstream requests", "none", float64(requests.Failed)))
+	}
+	return stats
+}
+
+func newQueryStat(name, unit string, value float64) data.QueryStat {
+	return data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: name, Unit: unit}, Value: value}
+}
+
+// attachQueryStats adds the stats to the meta of the data frames of the results. The executed query of a result is
+// set on all its frames, from the frame or the legacy meta of the result reporting it. The legacy results with
+// time series have the stats in their meta, and they are added to the frames of the series when the results are
+// converted.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func attachQueryStats(resp plugins.DataResponse, stats []data.QueryStat) {
+	for refID, result := range resp.Results {
+		executedQuery := ""
+		if result.Meta != nil {
+			executedQuery = result.Meta.Get(sqleng.MetaKeyExecutedQueryString).MustString()
+		}
+
+		if result.Dataframes == nil {
+			if result.Series != nil {
+				result.Meta = plugins.SetQueryStats(result.Meta, stats)
+				resp.Results[refID] = result
+			}
+			continue
+		}
+
+		frames, err := result.Dataframes.Decoded()
+		if err != nil {
+			continue
+		}
+		for _, frame := range frames {
+			if executedQuery == "" && frame.Meta != nil {
+				executedQuery = frame.Meta.ExecutedQueryString
+			}
+		}
+		for _, frame := range frames {
+			if frame.Meta == nil {
+				frame.Meta = &data.FrameMeta{}
+			}
+			if frame.Meta.ExecutedQueryString == "" {
+				frame.Meta.ExecutedQueryString = executedQuery
+			}
+			frame.Meta.Stats = append(frame.Meta.Stats, stats...)
+		}
+		result.Dataframes = plugins.NewDecodedDataFrames(frames)
+		resp.Results[refID] = result
+	}
+}
@@ -0,0 +1,78 @@
+package tsdb
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := httpclientprovider.New(setting.NewCfg()).New()
+	require.NoError(t, err)
+
+	svc, _, _ := createService()
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	svc.registry["test"] = func(*models.DataSource) (plugins.DataPlugin, error) {
+		return plugins.DataPluginFunc(func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			_, err = ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			first := data.NewFrame("first").SetMeta(&data.FrameMeta{ExecutedQueryString: "SELECT 1"})
+			return plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+				"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{first, data.NewFrame("second")})},
+				"B": {RefID: "B", Meta: simplejson.NewFromAny(map[string]interface{}{"executedQueryString": "SELECT 2"}),
+					Series: plugins.DataTimeSeriesSlice{{Name: "series"}}},
+			}}, nil
+		}), nil
+	}
+
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	resp, err := svc.HandleRequest(context.Background(), &models.DataSource{Type: "test"}, plugins.DataQuery{})
+	require.NoError(t, err)
+	qdr, err := resp.ToBackendDataResponse()
+	require.NoError(t, err)
+
+	stat := func(meta *data.FrameMeta, name string) float64 {
+		for _, s := range meta.Stats {
+			if s.DisplayName == name {
+				return s.Value
+			}
+		}
+		require.Failf(t, "missing stat", "%q", name)
+		return 0
+	}
+
+	frames := qdr.Responses["A"].Frames
+	require.Len(t, frames, 2)
+	for _, frame := range frames {
+		require.Equal(t, "SELECT 1", frame.Meta.ExecutedQueryString)
+		require.Equal(t, float64(1), stat(frame.Meta, "Upstream requests"))
+		require.Equal(t, float64(5), stat(frame.Meta, "Upstream response size"))
+		stat(frame.Meta, "Query duration")
+	}
+
+	frames = qdr.Responses["B"].Frames
+	require.Len(t, frames, 1)
+	require.Equal(t, "SELECT 2", frames[0].Meta.ExecutedQueryString)
+	require.Equal(t, float64(1), stat(frames[0].Meta, "Upstream requests"))
+}
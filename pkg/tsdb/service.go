@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
@@ -49,9 +51,12 @@ type Service struct {
 	PluginManager          plugins.Manager           `inject:""`
 	BackendPluginManager   backendplugin.Manager     `inject:""`
 	HTTPClientProvider     httpclient.Provider       `inject:""`
+	RemoteCache            *remotecache.RemoteCache  `inject:""`
 
 	//nolint: staticcheck // plugins.DataPlugin deprecated
-	registry map[string]func(*models.DataSource) (plugins.DataPlugin, error)
+	registry     map[string]func(*models.DataSource) (plugins.DataPlugin, error)
+	queryCache   *queryCache
+	queryLimiter *queryLimiter
 }
 
 // Init initialises the service.
@@ -60,18 +65,50 @@ func (s *Service) Init() error {
 	s.registry["opentsdb"] = opentsdb.New(s.HTTPClientProvider)
 	s.registry["prometheus"] = prometheus.New(s.HTTPClientProvider)
 	s.registry["influxdb"] = influxdb.New(s.HTTPClientProvider)
-	s.registry["mssql"] = mssql.NewExecutor
+	s.registry["mssql"] = mssql.New(s.Cfg)
 	s.registry["postgres"] = s.PostgresService.NewExecutor
-	s.registry["mysql"] = mysql.New(s.HTTPClientProvider)
+	s.registry["mysql"] = mysql.New(s.Cfg, s.HTTPClientProvider)
 	s.registry["elasticsearch"] = elasticsearch.New(s.HTTPClientProvider)
 	s.registry["stackdriver"] = s.CloudMonitoringService.NewExecutor
 	s.registry["loki"] = loki.New(s.HTTPClientProvider)
-	s.registry["tempo"] = tempo.New(s.HTTPClientProvider)
+	s.registry["tempo"] = tempo.New(s.HTTPClientProvider, s)
+
+	s.queryLimiter = newQueryLimiter(s.Cfg.DataSourceQueryTimeout, s.Cfg.DataSourceMaxConcurrentQueries)
+
+	if s.Cfg.QueryCachingEnabled {
+		s.queryCache = &queryCache{
+			store:        s.RemoteCache,
+			defaultTTL:   s.Cfg.QueryCachingDefaultTTL,
+			maxValueSize: s.Cfg.QueryCachingMaxValueSize,
+			secretKey:    setting.SecretKey,
+			log:          log.New("tsdb.querycache"),
+		}
+	}
 	return nil
 }
 
+// HandleRequest runs the query of the data source, or returns its cached result when query caching is enabled and
+// allowed by the context, see WithQueryCaching.
+// The queries run within the timeout and the concurrency limit of the data source, the cached results don't count
+// against the limit.
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func (s *Service) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	if s.queryCache != nil {
+		return s.queryCache.handleRequest(ctx, ds, query, s.handleLimitedRequest)
+	}
+	return s.handleLimitedRequest(ctx, ds, query)
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (s *Service) handleLimitedRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	if s.queryLimiter != nil {
+		return s.queryLimiter.handleRequest(ctx, ds, query, s.handleRequestWithStats)
+	}
+	return s.handleRequestWithStats(ctx, ds, query)
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (s *Service) handleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
 	if factory, exists := s.registry[ds.Type]; exists {
 		var err error
 		plugin, err := factory(ds)
@@ -0,0 +1,92 @@
+package sqleng
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// frameFromRows returns a frame with the data of the rows, like sqlutil.FrameFromRows, but with at most rowLimit rows
+// whose estimated size is at most byteLimit bytes. When a limit is reached, the remaining rows are dropped and a
+// warning notice is attached to the frame. A byteLimit of zero or less means no limit.
+func frameFromRows(rows *sql.Rows, rowLimit int64, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, converters, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, converters...)
+
+	var i, size int64
+	for rows.Next() {
+		if i == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+			})
+			break
+		}
+
+		r := scanner.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, err
+		}
+
+		if err := sqlutil.Append(frame, r, converters...); err != nil {
+			return nil, err
+		}
+
+		if byteLimit > 0 {
+			size += rowSize(frame, frame.Rows()-1)
+			if size > byteLimit {
+				frame.DeleteRow(frame.Rows() - 1)
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text: fmt.Sprintf("Results have been limited to %v rows because the SQL result size limit of %v bytes was reached",
+						frame.Rows(), byteLimit),
+				})
+				break
+			}
+		}
+
+		i++
+	}
+
+	return frame, rows.Err()
+}
+
+// rowSize returns the estimated size in bytes of the values of a row of the frame.
+func rowSize(frame *data.Frame, rowIdx int) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		v := reflect.ValueOf(field.At(rowIdx))
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.String, reflect.Slice:
+			size += int64(v.Len())
+		case reflect.Invalid:
+		default:
+			size += int64(v.Type().Size())
+		}
+	}
+	return size
+}
@@ -0,0 +1,81 @@
+package sqleng
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestFrameFromRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	// the in-memory database only exists on its connection
+	db.SetMaxOpenConns(1)
+
+	// the columns are scanned as strings, each row holds 11 bytes
+	_, err = db.Exec(`CREATE TABLE metric (name TEXT NOT NULL, value INTEGER NOT NULL);
+		INSERT INTO metric VALUES ('abcdefghij', 1), ('klmnopqrst', 2), ('uvwxyzabcd', 3)`)
+	require.NoError(t, err)
+	query := `SELECT name, value FROM metric ORDER BY value`
+	// sqlite has no scan types before the first row is read
+	stringConverter := func(typeName string) sqlutil.StringConverter {
+		return sqlutil.StringConverter{
+			InputTypeName: typeName,
+			Replacer: &sqlutil.StringFieldReplacer{
+				OutputFieldType: data.FieldTypeNullableString,
+				ReplaceFunc: func(in *string) (interface{}, error) {
+					return in, nil
+				},
+			},
+		}
+	}
+
+	frameFromQuery := func(t *testing.T, rowLimit int64, byteLimit int64) *data.Frame {
+		t.Helper()
+
+		rows, err := db.Query(query)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, rows.Close())
+		})
+
+		frame, err := frameFromRows(rows, rowLimit, byteLimit, sqlutil.ToConverters(
+			stringConverter("TEXT"), stringConverter("INTEGER"))...)
+		require.NoError(t, err)
+		return frame
+	}
+
+	t.Run("Should return all the rows within the limits", func(t *testing.T) {
+		frame := frameFromQuery(t, 10, 0)
+		assert.Equal(t, 3, frame.Rows())
+		assert.Nil(t, frame.Meta)
+	})
+
+	t.Run("Should drop the rows over the row limit", func(t *testing.T) {
+		frame := frameFromQuery(t, 2, 0)
+		assert.Equal(t, 2, frame.Rows())
+		require.NotNil(t, frame.Meta)
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		assert.Contains(t, frame.Meta.Notices[0].Text, "SQL row limit")
+	})
+
+	t.Run("Should drop the rows over the result size limit", func(t *testing.T) {
+		frame := frameFromQuery(t, 10, 25)
+		assert.Equal(t, 2, frame.Rows())
+		require.NotNil(t, frame.Meta)
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		assert.Equal(t, "Results have been limited to 2 rows because the SQL result size limit of 25 bytes was reached",
+			frame.Meta.Notices[0].Text)
+	})
+}
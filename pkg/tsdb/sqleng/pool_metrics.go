@@ -0,0 +1,72 @@
+package sqleng
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	prometheus.MustRegister(newConnectionPoolCollector())
+}
+
+// connectionPoolCollector exposes the connection pool statistics of the engines of the SQL data sources, so that
+// the connections each data source holds to its database can be monitored.
+type connectionPoolCollector struct {
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newConnectionPoolCollector() *connectionPoolCollector {
+	labels := []string{"datasource_id", "datasource_name", "datasource_type"}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("grafana", "datasource_sql", name), help, labels, nil)
+	}
+	return &connectionPoolCollector{
+		maxOpen:           desc("max_open_connections", "Maximum number of open connections to the database of the data source"),
+		open:              desc("open_connections", "Number of open connections to the database of the data source"),
+		inUse:             desc("in_use_connections", "Number of connections to the database of the data source in use"),
+		idle:              desc("idle_connections", "Number of idle connections to the database of the data source"),
+		waitCount:         desc("wait_count_total", "Total number of connections to the database of the data source waited for"),
+		waitDuration:      desc("wait_duration_seconds_total", "Total time waited for connections to the database of the data source"),
+		maxIdleClosed:     desc("max_idle_closed_total", "Total number of connections closed due to the max idle connections of the data source"),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Total number of connections closed due to the max lifetime of the connections of the data source"),
+	}
+}
+
+func (c *connectionPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *connectionPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	for id, engine := range engineCache.cache {
+		ds := engineCache.datasources[id]
+		labels := []string{strconv.FormatInt(id, 10), ds.Name, ds.Type}
+		stats := engine.DB().Stats()
+
+		ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), labels...)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed), labels...)
+		ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), labels...)
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/tsdb/interval"
-	"xorm.io/core"
 	"xorm.io/xorm"
 )
 
@@ -43,17 +42,40 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SQLQueryCanceler cancels the queries of a database on the server. The drivers stop waiting for a query once its
+// context is done, but some databases keep running the queries of the abandoned connections.
+type SQLQueryCanceler interface {
+	// SessionID returns the ID of the session of the connection on the database server.
+	SessionID(ctx context.Context, conn *sql.Conn) (int64, error)
+	// CancelQuery cancels the running query of the session, using another connection of the pool.
+	CancelQuery(ctx context.Context, db *sql.DB, sessionID int64) error
+}
+
 type engineCacheType struct {
 	cache    map[int64]*xorm.Engine
 	versions map[int64]int
+	// connections are the driver names and connection strings of the engines, an engine is only replaced when
+	// the data source connects to another database.
+	connections map[int64]string
+	// datasources are the data sources of the engines, which label their connection pool metrics.
+	datasources map[int64]*models.DataSource
 	sync.Mutex
 }
 
 var engineCache = engineCacheType{
-	cache:    make(map[int64]*xorm.Engine),
-	versions: make(map[int64]int),
+	cache:       make(map[int64]*xorm.Engine),
+	versions:    make(map[int64]int),
+	connections: make(map[int64]string),
+	datasources: make(map[int64]*models.DataSource),
 }
 
+// replacedEngineCloseDelay is how long the engine of a data source is kept open after it's replaced, so that
+// the queries of the requests made before the data source was updated still run.
+var replacedEngineCloseDelay = time.Minute
+
+// cancelQueryTimeout is how long the cancellation of the query of an abandoned request may take.
+var cancelQueryTimeout = 10 * time.Second
+
 var sqlIntervalCalculator = interval.NewCalculator()
 
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
@@ -65,9 +87,12 @@ var NewXormEngine = func(driverName string, connectionString string) (*xorm.Engi
 type dataPlugin struct {
 	macroEngine            SQLMacroEngine
 	queryResultTransformer SqlQueryResultTransformer
+	queryCanceler          SQLQueryCanceler
 	engine                 *xorm.Engine
 	timeColumnNames        []string
 	metricColumnTypes      []string
+	rowLimit               int64
+	maxResultBytes         int64
 	log                    log.Logger
 }
 
@@ -77,6 +102,13 @@ type DataPluginConfiguration struct {
 	ConnectionString  string
 	TimeColumnNames   []string
 	MetricColumnTypes []string
+	// RowLimit is the max number of rows of the result of a query, defaults to 1000000.
+	RowLimit int64
+	// MaxResultBytes is the max size in bytes of the result of a query, zero means no limit.
+	MaxResultBytes int64
+	// QueryCanceler cancels the queries of the abandoned requests on the database server, it's only needed when
+	// the driver doesn't cancel them itself.
+	QueryCanceler SQLQueryCanceler
 }
 
 func (e *dataPlugin) transformQueryError(err error) error {
@@ -100,7 +132,10 @@ func NewDataPlugin(config DataPluginConfiguration, queryResultTransformer SqlQue
 	plugin := dataPlugin{
 		queryResultTransformer: queryResultTransformer,
 		macroEngine:            macroEngine,
+		queryCanceler:          config.QueryCanceler,
 		timeColumnNames:        []string{"time"},
+		rowLimit:               defaultRowLimit,
+		maxResultBytes:         config.MaxResultBytes,
 		log:                    log,
 	}
 
@@ -112,36 +147,64 @@ func NewDataPlugin(config DataPluginConfiguration, queryResultTransformer SqlQue
 		plugin.metricColumnTypes = config.MetricColumnTypes
 	}
 
+	if config.RowLimit > 0 {
+		plugin.rowLimit = config.RowLimit
+	}
+
 	engineCache.Lock()
 	defer engineCache.Unlock()
 
-	if engine, present := engineCache.cache[config.Datasource.Id]; present {
+	connection := config.DriverName + ":" + config.ConnectionString
+	engine, present := engineCache.cache[config.Datasource.Id]
+	if present {
 		if version := engineCache.versions[config.Datasource.Id]; version == config.Datasource.Version {
 			plugin.engine = engine
 			return &plugin, nil
 		}
+
+		if engineCache.connections[config.Datasource.Id] != connection {
+			// the data source connects to another database, the connections of the engine can't be reused
+			replaced := engine
+			time.AfterFunc(replacedEngineCloseDelay, func() {
+				if err := replaced.Close(); err != nil {
+					log.Warn("Failed to close replaced engine", "datasource", config.Datasource.Id, "err", err)
+				}
+			})
+			present = false
+		}
 	}
 
-	engine, err := NewXormEngine(config.DriverName, config.ConnectionString)
-	if err != nil {
-		return nil, err
+	if !present {
+		var err error
+		engine, err = NewXormEngine(config.DriverName, config.ConnectionString)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	maxOpenConns := config.Datasource.JsonData.Get("maxOpenConns").MustInt(0)
-	engine.SetMaxOpenConns(maxOpenConns)
-	maxIdleConns := config.Datasource.JsonData.Get("maxIdleConns").MustInt(2)
-	engine.SetMaxIdleConns(maxIdleConns)
-	connMaxLifetime := config.Datasource.JsonData.Get("connMaxLifetime").MustInt(14400)
-	engine.SetConnMaxLifetime(time.Duration(connMaxLifetime) * time.Second)
+	// the connection pool of an engine that is kept is resized to the settings of the updated data source
+	setConnectionPool(engine, config.Datasource)
 
 	engineCache.versions[config.Datasource.Id] = config.Datasource.Version
 	engineCache.cache[config.Datasource.Id] = engine
+	engineCache.connections[config.Datasource.Id] = connection
+	engineCache.datasources[config.Datasource.Id] = config.Datasource
 	plugin.engine = engine
 
 	return &plugin, nil
 }
 
-const rowLimit = 1000000
+// setConnectionPool applies the connection pool settings of a data source to its engine.
+func setConnectionPool(engine *xorm.Engine, ds *models.DataSource) {
+	maxOpenConns := ds.JsonData.Get("maxOpenConns").MustInt(0)
+	engine.SetMaxOpenConns(maxOpenConns)
+	maxIdleConns := ds.JsonData.Get("maxIdleConns").MustInt(2)
+	engine.SetMaxIdleConns(maxIdleConns)
+	connMaxLifetime := ds.JsonData.Get("connMaxLifetime").MustInt(14400)
+	engine.SetConnMaxLifetime(time.Duration(connMaxLifetime) * time.Second)
+}
+
+const defaultRowLimit = 1000000
 
 // DataQuery queries for data.
 //nolint: staticcheck // plugins.DataPlugin deprecated
@@ -156,7 +219,7 @@ func (e *dataPlugin) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		}
 
 		wg.Add(1)
-		go e.executeQuery(query, &wg, queryContext, ch)
+		go e.executeQuery(ctx, query, &wg, queryContext, ch)
 	}
 
 	wg.Wait()
@@ -174,8 +237,8 @@ func (e *dataPlugin) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 }
 
 //nolint: staticcheck // plugins.DataQueryResult deprecated
-func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup, queryContext plugins.DataQuery,
-	ch chan plugins.DataQueryResult) {
+func (e *dataPlugin) executeQuery(ctx context.Context, query plugins.DataSubQuery, wg *sync.WaitGroup,
+	queryContext plugins.DataQuery, ch chan plugins.DataQueryResult) {
 	defer wg.Done()
 
 	queryResult := plugins.DataQueryResult{
@@ -230,11 +293,21 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 		return
 	}
 
-	session := e.engine.NewSession()
-	defer session.Close()
-	db := session.DB()
+	conn, err := e.engine.DB().Conn(ctx)
+	if err != nil {
+		errAppendDebug("db connection error", e.transformQueryError(err), interpolatedQuery)
+		return
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			e.log.Warn("Failed to close connection", "err", err)
+		}
+	}()
+
+	stopCancel := e.cancelQueryOnDone(ctx, conn)
+	defer stopCancel()
 
-	rows, err := db.Query(interpolatedQuery)
+	rows, err := conn.QueryContext(ctx, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("db query error", e.transformQueryError(err), interpolatedQuery)
 		return
@@ -253,7 +326,7 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := frameFromRows(rows, e.rowLimit, e.maxResultBytes, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
@@ -338,6 +411,42 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 	ch <- queryResult
 }
 
+// cancelQueryOnDone cancels the query of the connection on the database server when the context is done, so that
+// the queries of the abandoned requests don't keep using the database. The returned function must be called
+// before the connection is released.
+func (e *dataPlugin) cancelQueryOnDone(ctx context.Context, conn *sql.Conn) func() {
+	if e.queryCanceler == nil {
+		return func() {}
+	}
+
+	sessionID, err := e.queryCanceler.SessionID(ctx, conn)
+	if err != nil {
+		e.log.Warn("Failed to get the session ID of the connection, its queries can't be cancelled", "err", err)
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			cancelCtx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+			defer cancel()
+			if err := e.queryCanceler.CancelQuery(cancelCtx, e.engine.DB().DB, sessionID); err != nil {
+				e.log.Warn("Failed to cancel query", "sessionID", sessionID, "err", err)
+			}
+		case <-stop:
+		}
+	}()
+
+	return func() {
+		close(stop)
+		// the connection mustn't be handed to another query while its session is cancelled
+		<-stopped
+	}
+}
+
 // Interpolate provides global macros/substitutions for all sql datasources.
 var Interpolate = func(query plugins.DataSubQuery, timeRange plugins.DataTimeRange, sql string) (string, error) {
 	minInterval, err := interval.GetIntervalFrom(query.DataSource, query.Model, time.Second*60)
@@ -356,7 +465,7 @@ var Interpolate = func(query plugins.DataSubQuery, timeRange plugins.DataTimeRan
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func (e *dataPlugin) newProcessCfg(query plugins.DataSubQuery, queryContext plugins.DataQuery,
-	rows *core.Rows, interpolatedQuery string) (*dataQueryModel, error) {
+	rows *sql.Rows, interpolatedQuery string) (*dataQueryModel, error) {
 	columnNames, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -453,7 +562,7 @@ type dataQueryModel struct {
 	columnTypes       []*sql.ColumnType
 	timeIndex         int
 	metricIndex       int
-	rows              *core.Rows
+	rows              *sql.Rows
 	metricPrefix      bool
 	queryContext      plugins.DataQuery
 }
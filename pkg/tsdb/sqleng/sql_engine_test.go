@@ -1,9 +1,11 @@
 package sqleng
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,10 +15,14 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xorcare/pointer"
 	"xorm.io/core"
+	"xorm.io/xorm"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
 func TestSQLEngine(t *testing.T) {
@@ -433,3 +439,124 @@ func (t *testQueryResultTransformer) TransformQueryError(err error) error {
 func (t *testQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	return nil
 }
+
+func TestNewDataPlugin_ConnectionPool(t *testing.T) {
+	origCloseDelay := replacedEngineCloseDelay
+	t.Cleanup(func() {
+		replacedEngineCloseDelay = origCloseDelay
+	})
+	replacedEngineCloseDelay = 0
+
+	newPlugin := func(t *testing.T, version int, connectionString string, maxOpenConns int) *dataPlugin {
+		t.Helper()
+
+		plugin, err := NewDataPlugin(DataPluginConfiguration{
+			DriverName:       "sqlite3",
+			ConnectionString: connectionString,
+			Datasource: &models.DataSource{
+				Id:      9001,
+				Version: version,
+				Name:    "sqlite",
+				Type:    "sqlite",
+				JsonData: simplejson.NewFromAny(map[string]interface{}{
+					"maxOpenConns": maxOpenConns,
+				}),
+			},
+		}, nil, nil, log.New("test"))
+		require.NoError(t, err)
+		return plugin.(*dataPlugin)
+	}
+
+	first := newPlugin(t, 1, "file:pool1?mode=memory", 5)
+	assert.Equal(t, 5, first.engine.DB().Stats().MaxOpenConnections)
+
+	t.Run("Should reuse the engine of the data source", func(t *testing.T) {
+		plugin := newPlugin(t, 1, "file:pool1?mode=memory", 5)
+		assert.Same(t, first.engine, plugin.engine)
+	})
+
+	t.Run("Should resize the connection pool when the data source is updated", func(t *testing.T) {
+		plugin := newPlugin(t, 2, "file:pool1?mode=memory", 10)
+		assert.Same(t, first.engine, plugin.engine)
+		assert.Equal(t, 10, plugin.engine.DB().Stats().MaxOpenConnections)
+	})
+
+	t.Run("Should expose the connection pool metrics of the data source", func(t *testing.T) {
+		err := testutil.CollectAndCompare(newConnectionPoolCollector(), strings.NewReader(`
+			# HELP grafana_datasource_sql_max_open_connections Maximum number of open connections to the database of the data source
+			# TYPE grafana_datasource_sql_max_open_connections gauge
+			grafana_datasource_sql_max_open_connections{datasource_id="9001",datasource_name="sqlite",datasource_type="sqlite"} 10
+		`), "grafana_datasource_sql_max_open_connections")
+		require.NoError(t, err)
+	})
+
+	t.Run("Should replace the engine when the data source connects to another database", func(t *testing.T) {
+		plugin := newPlugin(t, 3, "file:pool2?mode=memory", 10)
+		assert.NotSame(t, first.engine, plugin.engine)
+		assert.Eventually(t, func() bool {
+			return first.engine.Ping() != nil
+		}, time.Second, 10*time.Millisecond)
+		assert.NoError(t, plugin.engine.Ping())
+	})
+}
+
+type fakeQueryCanceler struct {
+	cancelled chan int64
+}
+
+func (c *fakeQueryCanceler) SessionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	return 42, nil
+}
+
+func (c *fakeQueryCanceler) CancelQuery(ctx context.Context, db *sql.DB, sessionID int64) error {
+	c.cancelled <- sessionID
+	return nil
+}
+
+func TestDataPlugin_CancelQueryOnDone(t *testing.T) {
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, engine.Close())
+	})
+
+	newScenario := func(t *testing.T) (*dataPlugin, *fakeQueryCanceler, *sql.Conn) {
+		t.Helper()
+
+		canceler := &fakeQueryCanceler{cancelled: make(chan int64, 1)}
+		plugin := &dataPlugin{engine: engine, queryCanceler: canceler, log: log.New("test")}
+		conn, err := engine.DB().Conn(context.Background())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, conn.Close())
+		})
+		return plugin, canceler, conn
+	}
+
+	t.Run("Should cancel the query of the session when the context is done", func(t *testing.T) {
+		plugin, canceler, conn := newScenario(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := plugin.cancelQueryOnDone(ctx, conn)
+		cancel()
+
+		select {
+		case sessionID := <-canceler.cancelled:
+			assert.Equal(t, int64(42), sessionID)
+		case <-time.After(time.Second):
+			t.Fatal("the query wasn't cancelled")
+		}
+		stop()
+	})
+
+	t.Run("Should not cancel the query once it's done", func(t *testing.T) {
+		plugin, canceler, conn := newScenario(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := plugin.cancelQueryOnDone(ctx, conn)
+		stop()
+		cancel()
+
+		assert.Empty(t, canceler.cancelled)
+	})
+}
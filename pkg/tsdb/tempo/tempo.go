@@ -17,11 +17,14 @@ import (
 
 type tempoExecutor struct {
 	httpClient *http.Client
+	// requestHandler runs the metric queries correlated with the traces.
+	requestHandler plugins.DataRequestHandler
 }
 
 // NewExecutor returns a tempoExecutor.
 //nolint: staticcheck // plugins.DataPlugin deprecated
-func New(httpClientProvider httpclient.Provider) func(*models.DataSource) (plugins.DataPlugin, error) {
+func New(httpClientProvider httpclient.Provider,
+	requestHandler plugins.DataRequestHandler) func(*models.DataSource) (plugins.DataPlugin, error) {
 	//nolint: staticcheck // plugins.DataPlugin deprecated
 	return func(dsInfo *models.DataSource) (plugins.DataPlugin, error) {
 		httpClient, err := dsInfo.GetHTTPClient(httpClientProvider)
@@ -30,7 +33,8 @@ func New(httpClientProvider httpclient.Provider) func(*models.DataSource) (plugi
 		}
 
 		return &tempoExecutor{
-			httpClient: httpClient,
+			httpClient:     httpClient,
+			requestHandler: requestHandler,
 		}, nil
 	}
 }
@@ -86,8 +90,17 @@ func (e *tempoExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource
 	if err != nil {
 		return plugins.DataResponse{}, fmt.Errorf("failed to transform trace %v to data frame: %w", traceID, err)
 	}
+	if frame == nil {
+		return plugins.DataResponse{
+			Results: map[string]plugins.DataQueryResult{
+				refID: queryResult,
+			},
+		}, nil
+	}
 	frame.RefID = refID
 	frames := []*data.Frame{frame}
+	// the trace frame is the first frame, followed by the frames of its metrics
+	frames = append(frames, e.queryTraceMetrics(ctx, dsInfo, queryContext, refID, frame)...)
 	queryResult.Dataframes = plugins.NewDecodedDataFrames(frames)
 
 	return plugins.DataResponse{
@@ -11,7 +11,7 @@ import (
 )
 
 func TestTempo(t *testing.T) {
-	plug, err := New(httpclient.NewProvider())(&models.DataSource{})
+	plug, err := New(httpclient.NewProvider(), nil)(&models.DataSource{})
 	executor := plug.(*tempoExecutor)
 	require.NoError(t, err)
 
@@ -0,0 +1,189 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// tracesToMetricsTimeShift is how long before the start and after the end of a trace its metrics are queried, when
+// the request has no time range.
+const tracesToMetricsTimeShift = 30 * time.Minute
+
+// tracesToMetricsSettings are the metric queries correlated with the traces of a data source, like the RED
+// metrics of the service and operation of a trace. The $__service and $__span variables of the queries are
+// replaced by the service and operation names of the root span of the trace.
+type tracesToMetricsSettings struct {
+	DatasourceUID string                 `json:"datasourceUid"`
+	Queries       []tracesToMetricsQuery `json:"queries"`
+}
+
+type tracesToMetricsQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+func getTracesToMetricsSettings(dsInfo *models.DataSource) (tracesToMetricsSettings, error) {
+	settings := tracesToMetricsSettings{}
+	if dsInfo.JsonData == nil {
+		return settings, nil
+	}
+	if _, ok := dsInfo.JsonData.CheckGet("tracesToMetrics"); !ok {
+		return settings, nil
+	}
+
+	b, err := dsInfo.JsonData.Get("tracesToMetrics").Encode()
+	if err != nil {
+		return settings, err
+	}
+	err = json.Unmarshal(b, &settings)
+	return settings, err
+}
+
+// getDataSourceByUID returns the data source of the organization with the UID, it can be stubbed by tests.
+var getDataSourceByUID = func(uid string, orgID int64) (*models.DataSource, error) {
+	query := &models.GetDataSourceQuery{Uid: uid, OrgId: orgID}
+	if err := bus.Dispatch(query); err != nil {
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+// queryTraceMetrics runs the metric queries correlated with the trace and returns their frames, named after the
+// queries. The queries that fail are reported by notices of the trace frame, which is returned regardless.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (e *tempoExecutor) queryTraceMetrics(ctx context.Context, dsInfo *models.DataSource, queryContext plugins.DataQuery,
+	refID string, traceFrame *data.Frame) []*data.Frame {
+	settings, err := getTracesToMetricsSettings(dsInfo)
+	if err != nil {
+		tlog.Warn("Invalid traces to metrics settings", "datasource", dsInfo.Name, "err", err)
+		return nil
+	}
+	if settings.DatasourceUID == "" || len(settings.Queries) == 0 || e.requestHandler == nil {
+		return nil
+	}
+
+	metricsDS, err := getDataSourceByUID(settings.DatasourceUID, dsInfo.OrgId)
+	if err != nil {
+		traceFrame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Failed to get the metrics data source of the trace: %v", err),
+		})
+		return nil
+	}
+
+	service, span, start, end := rootSpanOf(traceFrame)
+	timeRange := plugins.NewDataTimeRange(
+		strconv.FormatInt(start.Add(-tracesToMetricsTimeShift).UnixNano()/int64(time.Millisecond), 10),
+		strconv.FormatInt(end.Add(tracesToMetricsTimeShift).UnixNano()/int64(time.Millisecond), 10))
+	if queryContext.TimeRange != nil {
+		timeRange = *queryContext.TimeRange
+	}
+	replacer := strings.NewReplacer("$__service", service, "$__span", span)
+
+	metricsQuery := plugins.DataQuery{
+		TimeRange: &timeRange,
+		User:      queryContext.User,
+	}
+	for i, q := range settings.Queries {
+		metricsRefID := fmt.Sprintf("%s-metrics-%d", refID, i)
+		metricsQuery.Queries = append(metricsQuery.Queries, plugins.DataSubQuery{
+			RefID:      metricsRefID,
+			DataSource: metricsDS,
+			Model: simplejson.NewFromAny(map[string]interface{}{
+				"refId": metricsRefID,
+				"expr":  replacer.Replace(q.Query),
+			}),
+		})
+	}
+
+	res, err := e.requestHandler.HandleRequest(ctx, metricsDS, metricsQuery)
+	if err != nil {
+		traceFrame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Failed to query the metrics of the trace: %v", err),
+		})
+		return nil
+	}
+
+	frames := []*data.Frame{}
+	for i, q := range settings.Queries {
+		result := res.Results[metricsQuery.Queries[i].RefID]
+		if result.Error == nil && result.Dataframes == nil {
+			continue
+		}
+
+		var queryFrames data.Frames
+		if result.Error == nil {
+			queryFrames, err = result.Dataframes.Decoded()
+		} else {
+			err = result.Error
+		}
+		if err != nil {
+			traceFrame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Failed to query the %s metrics of the trace: %v", q.Name, err),
+			})
+			continue
+		}
+
+		for _, frame := range queryFrames {
+			frame.Name = q.Name
+			frame.RefID = refID
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+// rootSpanOf returns the service and operation names of the root span of the trace frame, and the time range of
+// the trace.
+func rootSpanOf(frame *data.Frame) (string, string, time.Time, time.Time) {
+	parentSpanIDs := fieldByName(frame, "parentSpanID")
+	serviceNames := fieldByName(frame, "serviceName")
+	operationNames := fieldByName(frame, "operationName")
+	startTimes := fieldByName(frame, "startTime")
+	durations := fieldByName(frame, "duration")
+	if parentSpanIDs == nil || serviceNames == nil || operationNames == nil || startTimes == nil || durations == nil ||
+		frame.Rows() == 0 {
+		return "", "", time.Time{}, time.Time{}
+	}
+
+	root := 0
+	var start, end float64
+	for i := 0; i < frame.Rows(); i++ {
+		if parentSpanIDs.At(i).(string) == "" {
+			root = i
+		}
+		spanStart := startTimes.At(i).(float64)
+		spanEnd := spanStart + durations.At(i).(float64)
+		if i == 0 || spanStart < start {
+			start = spanStart
+		}
+		if spanEnd > end {
+			end = spanEnd
+		}
+	}
+
+	// the start times and durations of the spans are in milliseconds
+	return serviceNames.At(root).(string), operationNames.At(root).(string),
+		time.Unix(0, int64(start*float64(time.Millisecond))), time.Unix(0, int64(end*float64(time.Millisecond)))
+}
+
+func fieldByName(frame *data.Frame, name string) *data.Field {
+	for _, field := range frame.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
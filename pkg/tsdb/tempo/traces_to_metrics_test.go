@@ -0,0 +1,130 @@
+package tempo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRequestHandler struct {
+	query    plugins.DataQuery
+	response plugins.DataResponse
+	err      error
+}
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (h *fakeRequestHandler) HandleRequest(ctx context.Context, ds *models.DataSource,
+	query plugins.DataQuery) (plugins.DataResponse, error) {
+	h.query = query
+	return h.response, h.err
+}
+
+func TestQueryTraceMetrics(t *testing.T) {
+	origGetDataSourceByUID := getDataSourceByUID
+	t.Cleanup(func() {
+		getDataSourceByUID = origGetDataSourceByUID
+	})
+	getDataSourceByUID = func(uid string, orgID int64) (*models.DataSource, error) {
+		if uid != "prometheus" {
+			return nil, errors.New("data source not found")
+		}
+		return &models.DataSource{Uid: uid, OrgId: orgID, Type: "prometheus"}, nil
+	}
+
+	traceFrame := func() *data.Frame {
+		return data.NewFrame("Trace",
+			data.NewField("spanID", nil, []string{"child", "root"}),
+			data.NewField("parentSpanID", nil, []string{"root", ""}),
+			data.NewField("operationName", nil, []string{"SELECT", "HTTP GET /api"}),
+			data.NewField("serviceName", nil, []string{"db", "frontend"}),
+			data.NewField("startTime", nil, []float64{1616072924071, 1616072924070}),
+			data.NewField("duration", nil, []float64{5, 10}),
+		)
+	}
+	dsInfo := &models.DataSource{
+		OrgId: 1,
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"tracesToMetrics": map[string]interface{}{
+				"datasourceUid": "prometheus",
+				"queries": []interface{}{
+					map[string]interface{}{"name": "Request rate", "query": `rate(calls_total{service="$__service",span="$__span"}[5m])`},
+					map[string]interface{}{"name": "Error rate", "query": `rate(errors_total{service="$__service"}[5m])`},
+				},
+			},
+		}),
+	}
+
+	t.Run("Should query the metrics of the root span of the trace", func(t *testing.T) {
+		handler := &fakeRequestHandler{response: plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A-metrics-0": {Dataframes: plugins.NewDecodedDataFrames(data.Frames{
+				data.NewFrame("", data.NewField("Time", nil, []time.Time{}), data.NewField("Value", nil, []float64{})),
+			})},
+			"A-metrics-1": {Error: errors.New("query failed")},
+		}}}
+		executor := &tempoExecutor{requestHandler: handler}
+		trace := traceFrame()
+
+		frames := executor.queryTraceMetrics(context.Background(), dsInfo, plugins.DataQuery{}, "A", trace)
+
+		require.Len(t, handler.query.Queries, 2)
+		assert.Equal(t, `rate(calls_total{service="frontend",span="HTTP GET /api"}[5m])`,
+			handler.query.Queries[0].Model.Get("expr").MustString())
+		assert.Equal(t, `rate(errors_total{service="frontend"}[5m])`, handler.query.Queries[1].Model.Get("expr").MustString())
+		assert.Equal(t, "1616071124070", handler.query.TimeRange.From)
+		assert.Equal(t, "1616074724080", handler.query.TimeRange.To)
+
+		require.Len(t, frames, 1)
+		assert.Equal(t, "Request rate", frames[0].Name)
+		assert.Equal(t, "A", frames[0].RefID)
+		require.NotNil(t, trace.Meta)
+		require.Len(t, trace.Meta.Notices, 1)
+		assert.Equal(t, "Failed to query the Error rate metrics of the trace: query failed", trace.Meta.Notices[0].Text)
+	})
+
+	t.Run("Should query the metrics in the time range of the request", func(t *testing.T) {
+		handler := &fakeRequestHandler{response: plugins.DataResponse{Results: map[string]plugins.DataQueryResult{}}}
+		executor := &tempoExecutor{requestHandler: handler}
+		timeRange := plugins.NewDataTimeRange("now-1h", "now")
+
+		executor.queryTraceMetrics(context.Background(), dsInfo, plugins.DataQuery{TimeRange: &timeRange}, "A", traceFrame())
+
+		assert.Equal(t, "now-1h", handler.query.TimeRange.From)
+		assert.Equal(t, "now", handler.query.TimeRange.To)
+	})
+
+	t.Run("Should not query metrics when none are configured", func(t *testing.T) {
+		handler := &fakeRequestHandler{}
+		executor := &tempoExecutor{requestHandler: handler}
+
+		frames := executor.queryTraceMetrics(context.Background(), &models.DataSource{JsonData: simplejson.New()},
+			plugins.DataQuery{}, "A", traceFrame())
+
+		assert.Empty(t, frames)
+		assert.Empty(t, handler.query.Queries)
+	})
+
+	t.Run("Should report a missing metrics data source", func(t *testing.T) {
+		executor := &tempoExecutor{requestHandler: &fakeRequestHandler{}}
+		trace := traceFrame()
+		ds := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"tracesToMetrics": map[string]interface{}{
+				"datasourceUid": "unknown",
+				"queries":       []interface{}{map[string]interface{}{"name": "Request rate", "query": "up"}},
+			},
+		})}
+
+		frames := executor.queryTraceMetrics(context.Background(), ds, plugins.DataQuery{}, "A", trace)
+
+		assert.Empty(t, frames)
+		require.NotNil(t, trace.Meta)
+		require.Len(t, trace.Meta.Notices, 1)
+	})
+}
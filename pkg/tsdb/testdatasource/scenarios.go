@@ -41,6 +41,7 @@ const (
 	nodeGraphQuery                    queryType = "node_graph"
 	csvFileQueryType                  queryType = "csv_file"
 	csvContentQueryType               queryType = "csv_content"
+	scriptedQuery                     queryType = "scripted"
 )
 
 type queryType string
@@ -202,6 +203,15 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		handler: p.handleCsvContentScenario,
 	})
 
+	p.registerScenario(&Scenario{
+		ID:      string(scriptedQuery),
+		Name:    "Scripted",
+		handler: p.handleScriptedScenario,
+		Description: `Scripted returns the series described by a script of function calls, one per line:
+sine(period, amplitude, offset), randomWalk(seed, start, spread), step(period, values...) and csv("values"),
+with error(probability, seed) and latency(duration) to inject failures and delays.`,
+	})
+
 	p.queryMux.HandleFunc("", p.handleFallbackScenario)
 }
 
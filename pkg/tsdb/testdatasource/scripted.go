@@ -0,0 +1,456 @@
+package testdatasource
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// maxScriptedPoints is the maximum number of points of a scripted series, like the random walk.
+const maxScriptedPoints = 10000
+
+func (p *testDataPlugin) handleScriptedScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := simplejson.NewJson(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		respD := resp.Responses[q.RefID]
+		respD.Frames, respD.Error = runScript(ctx, q, model)
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+// runScript evaluates the script of a query. The latency is waited for before anything else, and an injected
+// error is returned along with the frames, like in the random walk with error scenario.
+func runScript(ctx context.Context, query backend.DataQuery, model *simplejson.Json) (data.Frames, error) {
+	s, err := parseScript(model.Get("script").MustString())
+	if err != nil {
+		return nil, err
+	}
+
+	if s.latency > 0 {
+		timer := time.NewTimer(s.latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	times := scriptTimes(query)
+	frames := make(data.Frames, 0, len(s.series))
+	for i, series := range s.series {
+		fields := series(query, times)
+		fields[1].Name = frameNameForQuery(query, model, i)
+		fields[1].Labels = parseLabels(model)
+		frames = append(frames, data.NewFrame("", fields...))
+	}
+
+	if s.failed(query) {
+		return frames, fmt.Errorf("scripted error")
+	}
+	return frames, nil
+}
+
+// scriptTimes returns the timestamps of the generated series: every interval of the time range, aligned on the
+// interval so the values of the functions of time don't depend on when the query runs.
+func scriptTimes(query backend.DataQuery) []time.Time {
+	interval := query.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	from := query.TimeRange.From.Truncate(interval)
+	if from.Before(query.TimeRange.From) {
+		from = from.Add(interval)
+	}
+
+	times := make([]time.Time, 0)
+	for t := from; !t.After(query.TimeRange.To) && len(times) < maxScriptedPoints; t = t.Add(interval) {
+		times = append(times, t)
+	}
+	return times
+}
+
+// script is a parsed scenario script. Each line of a script is a function call, the series functions add a series
+// to the response and the error and latency functions change how it is returned.
+type script struct {
+	series           []scriptSeries
+	errorProbability float64
+	errorSeed        *int64
+	latency          time.Duration
+}
+
+// scriptSeries returns the time and value fields of a scripted series.
+type scriptSeries func(query backend.DataQuery, times []time.Time) data.Fields
+
+// failed tells whether the error injected by the script happens for the query. With a seed, the outcome only
+// depends on the seed and the start of the time range, so a query on a fixed time range is reproducible.
+func (s *script) failed(query backend.DataQuery) bool {
+	if s.errorProbability <= 0 {
+		return false
+	}
+	if s.errorSeed == nil {
+		return rand.Float64() < s.errorProbability
+	}
+	r := rand.New(rand.NewSource(*s.errorSeed + query.TimeRange.From.UnixNano()))
+	return r.Float64() < s.errorProbability
+}
+
+type scriptFunc func(s *script, call scriptCall) error
+
+var scriptFuncs = map[string]scriptFunc{
+	"sine":       scriptSine,
+	"randomWalk": scriptRandomWalk,
+	"step":       scriptStep,
+	"csv":        scriptCSV,
+	"error":      scriptError,
+	"latency":    scriptLatency,
+}
+
+func parseScript(input string) (*script, error) {
+	calls, err := parseScriptCalls(input)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &script{}
+	for _, call := range calls {
+		fn, ok := scriptFuncs[call.name]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown function %q", call.line, call.name)
+		}
+		if err := fn(s, call); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// sine(period, amplitude, offset) is a sine wave of the absolute time.
+func scriptSine(s *script, call scriptCall) error {
+	if err := call.expectArgs(1, 3); err != nil {
+		return err
+	}
+	period, err := call.duration(0)
+	if err != nil {
+		return err
+	}
+	amplitude, err := call.float(1, 1)
+	if err != nil {
+		return err
+	}
+	offset, err := call.float(2, 0)
+	if err != nil {
+		return err
+	}
+
+	s.series = append(s.series, valueSeries(func(t time.Time) float64 {
+		phase := float64(t.UnixNano()%int64(period)) / float64(period)
+		return offset + amplitude*math.Sin(2*math.Pi*phase)
+	}))
+	return nil
+}
+
+// randomWalk(seed, start, spread) is a random walk, the same for the same seed.
+func scriptRandomWalk(s *script, call scriptCall) error {
+	if err := call.expectArgs(0, 3); err != nil {
+		return err
+	}
+	seed, err := call.int(0, rand.Int63())
+	if err != nil {
+		return err
+	}
+	start, err := call.float(1, 0)
+	if err != nil {
+		return err
+	}
+	spread, err := call.float(2, 1)
+	if err != nil {
+		return err
+	}
+
+	s.series = append(s.series, func(query backend.DataQuery, times []time.Time) data.Fields {
+		r := rand.New(rand.NewSource(seed))
+		walker := start
+		return valueSeries(func(time.Time) float64 {
+			value := walker
+			walker += (r.Float64() - 0.5) * spread
+			return value
+		})(query, times)
+	})
+	return nil
+}
+
+// step(period, values...) cycles through the values, each for a period of the absolute time.
+func scriptStep(s *script, call scriptCall) error {
+	if err := call.expectArgs(2, -1); err != nil {
+		return err
+	}
+	period, err := call.duration(0)
+	if err != nil {
+		return err
+	}
+	values := make([]float64, 0, len(call.args)-1)
+	for i := 1; i < len(call.args); i++ {
+		value, err := call.float(i, 0)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+
+	s.series = append(s.series, valueSeries(func(t time.Time) float64 {
+		return values[(t.UnixNano()/int64(period))%int64(len(values))]
+	}))
+	return nil
+}
+
+// csv("values") spreads the comma separated values evenly over the time range, like the CSV metric values.
+func scriptCSV(s *script, call scriptCall) error {
+	if err := call.expectArgs(1, 1); err != nil {
+		return err
+	}
+	valueField, err := csvLineToField(call.args[0])
+	if err != nil {
+		return call.errorf("%v", err)
+	}
+
+	s.series = append(s.series, func(query backend.DataQuery, _ []time.Time) data.Fields {
+		count := valueField.Len()
+		timeField := data.NewFieldFromFieldType(data.FieldTypeTime, count)
+		timeField.Name = "time"
+		var step time.Duration
+		if count > 1 {
+			step = query.TimeRange.To.Sub(query.TimeRange.From) / time.Duration(count-1)
+		}
+		for i := 0; i < count; i++ {
+			timeField.Set(i, query.TimeRange.From.Add(time.Duration(i)*step))
+		}
+		return data.Fields{timeField, valueField}
+	})
+	return nil
+}
+
+// error(probability, seed) fails the query with the probability.
+func scriptError(s *script, call scriptCall) error {
+	if err := call.expectArgs(1, 2); err != nil {
+		return err
+	}
+	probability, err := call.float(0, 0)
+	if err != nil {
+		return err
+	}
+	if probability < 0 || probability > 1 {
+		return call.errorf("probability must be between 0 and 1")
+	}
+	s.errorProbability = probability
+	if len(call.args) > 1 {
+		seed, err := call.int(1, 0)
+		if err != nil {
+			return err
+		}
+		s.errorSeed = &seed
+	}
+	return nil
+}
+
+// latency(duration) delays the response.
+func scriptLatency(s *script, call scriptCall) error {
+	if err := call.expectArgs(1, 1); err != nil {
+		return err
+	}
+	latency, err := call.duration(0)
+	if err != nil {
+		return err
+	}
+	s.latency = latency
+	return nil
+}
+
+// valueSeries returns a series with a value for each timestamp.
+func valueSeries(value func(t time.Time) float64) scriptSeries {
+	return func(query backend.DataQuery, times []time.Time) data.Fields {
+		values := make([]float64, len(times))
+		for i, t := range times {
+			values[i] = value(t)
+		}
+		return data.Fields{
+			data.NewField("time", nil, append([]time.Time{}, times...)),
+			data.NewField("", nil, values),
+		}
+	}
+}
+
+type scriptCall struct {
+	line int
+	name string
+	args []string
+}
+
+func (c scriptCall) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("line %d: %s: %s", c.line, c.name, fmt.Sprintf(format, a...))
+}
+
+// expectArgs checks the number of arguments, max is -1 for no maximum.
+func (c scriptCall) expectArgs(min, max int) error {
+	if len(c.args) < min || (max >= 0 && len(c.args) > max) {
+		return c.errorf("unexpected number of arguments %d", len(c.args))
+	}
+	return nil
+}
+
+func (c scriptCall) float(i int, def float64) (float64, error) {
+	if i >= len(c.args) {
+		return def, nil
+	}
+	value, err := strconv.ParseFloat(c.args[i], 64)
+	if err != nil {
+		return 0, c.errorf("invalid number %q", c.args[i])
+	}
+	return value, nil
+}
+
+func (c scriptCall) int(i int, def int64) (int64, error) {
+	if i >= len(c.args) {
+		return def, nil
+	}
+	value, err := strconv.ParseInt(c.args[i], 10, 64)
+	if err != nil {
+		return 0, c.errorf("invalid integer %q", c.args[i])
+	}
+	return value, nil
+}
+
+func (c scriptCall) duration(i int) (time.Duration, error) {
+	value, err := gtime.ParseDuration(c.args[i])
+	if err != nil || value <= 0 {
+		return 0, c.errorf("invalid duration %q", c.args[i])
+	}
+	return value, nil
+}
+
+// parseScriptCalls parses the function calls of a script. The calls are separated by new lines or semicolons,
+// the arguments are numbers, durations or double quoted strings, and # starts a comment.
+func parseScriptCalls(input string) ([]scriptCall, error) {
+	calls := make([]scriptCall, 0)
+	line := 1
+	i := 0
+	for i < len(input) {
+		switch c := input[i]; {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ';':
+			i++
+		case c == '#':
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+		default:
+			call := scriptCall{line: line}
+			start := i
+			for i < len(input) && isScriptIdentifier(input[i]) {
+				i++
+			}
+			call.name = input[start:i]
+			if call.name == "" || i >= len(input) || input[i] != '(' {
+				return nil, fmt.Errorf("line %d: expected a function call", line)
+			}
+			i++
+
+			args, n, err := parseScriptArgs(input[i:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %v", line, call.name, err)
+			}
+			call.args = args
+			i += n
+			calls = append(calls, call)
+		}
+	}
+	return calls, nil
+}
+
+// parseScriptArgs parses the arguments of a call up to the closing parenthesis and returns the number of bytes
+// read.
+func parseScriptArgs(input string) ([]string, int, error) {
+	args := make([]string, 0)
+	i := 0
+	for {
+		for i < len(input) && (input[i] == ' ' || input[i] == '\t') {
+			i++
+		}
+		if i >= len(input) {
+			return nil, 0, fmt.Errorf("missing closing parenthesis")
+		}
+		if input[i] == ')' && len(args) == 0 {
+			return args, i + 1, nil
+		}
+
+		var arg string
+		if input[i] == '"' {
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, 0, fmt.Errorf("unterminated string")
+			}
+			unquoted, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid string %s", input[i:end+1])
+			}
+			arg = unquoted
+			i = end + 1
+		} else {
+			end := strings.IndexAny(input[i:], ",)\n")
+			if end < 0 {
+				return nil, 0, fmt.Errorf("missing closing parenthesis")
+			}
+			arg = strings.TrimSpace(input[i : i+end])
+			if arg == "" {
+				return nil, 0, fmt.Errorf("missing argument")
+			}
+			i += end
+		}
+		args = append(args, arg)
+
+		for i < len(input) && (input[i] == ' ' || input[i] == '\t') {
+			i++
+		}
+		if i >= len(input) {
+			return nil, 0, fmt.Errorf("missing closing parenthesis")
+		}
+		switch input[i] {
+		case ',':
+			i++
+		case ')':
+			return args, i + 1, nil
+		default:
+			return nil, 0, fmt.Errorf("unexpected %q", input[i])
+		}
+	}
+}
+
+func isScriptIdentifier(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
@@ -0,0 +1,97 @@
+package testdatasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptedScenario(t *testing.T) {
+	p := &testDataPlugin{}
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	query := func(script string) backend.DataResponse {
+		model := simplejson.New()
+		model.Set("script", script)
+		modelBytes, err := model.MarshalJSON()
+		require.NoError(t, err)
+
+		resp, err := p.handleScriptedScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: from, To: from.Add(time.Minute)},
+				Interval:  15 * time.Second,
+				JSON:      modelBytes,
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("Should return a series per function", func(t *testing.T) {
+		dResp := query("sine(1m, 10, 5)\nstep(30s, 1, 2); randomWalk(42) # comment\ncsv(\"1,null,3\")")
+		require.NoError(t, dResp.Error)
+		require.Len(t, dResp.Frames, 4)
+
+		sine := dResp.Frames[0]
+		require.Equal(t, 5, sine.Rows())
+		require.Equal(t, from, sine.Fields[0].At(0))
+		require.Equal(t, "A-series", sine.Fields[1].Name)
+		require.InDelta(t, 5, sine.Fields[1].At(0), 1e-9)
+		require.InDelta(t, 15, sine.Fields[1].At(1), 1e-9)
+		require.InDelta(t, -5, sine.Fields[1].At(3), 1e-9)
+
+		step := dResp.Frames[1]
+		require.Equal(t, "A-series1", step.Fields[1].Name)
+		for i, expected := range []float64{1, 1, 2, 2, 1} {
+			require.Equal(t, expected, step.Fields[1].At(i))
+		}
+
+		walk := dResp.Frames[2]
+		require.Equal(t, 0.0, walk.Fields[1].At(0))
+		require.Equal(t, walk, query("randomWalk(42)\nrandomWalk(42)\nrandomWalk(42)").Frames[2])
+
+		csv := dResp.Frames[3]
+		require.Equal(t, 3, csv.Rows())
+		require.Equal(t, from.Add(30*time.Second), csv.Fields[0].At(1))
+		require.Nil(t, csv.Fields[1].At(1))
+	})
+
+	t.Run("Should inject errors", func(t *testing.T) {
+		dResp := query("error(1)\nsine(1m)")
+		require.EqualError(t, dResp.Error, "scripted error")
+		require.Len(t, dResp.Frames, 1)
+
+		require.NoError(t, query("error(0, 1)").Error)
+
+		seeded := query("error(0.5, 7)").Error
+		for i := 0; i < 10; i++ {
+			require.Equal(t, seeded, query("error(0.5, 7)").Error)
+		}
+	})
+
+	t.Run("Should inject latency", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, query("latency(20ms)").Error)
+		require.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+	})
+
+	t.Run("Should return the script errors", func(t *testing.T) {
+		for script, expected := range map[string]string{
+			"cosine(1m)":      `line 1: unknown function "cosine"`,
+			"\nsine(x)":       `line 2: sine: invalid duration "x"`,
+			"step(1m)":        "line 1: step: unexpected number of arguments 1",
+			"error(2)":        "line 1: error: probability must be between 0 and 1",
+			"sine(1m":         "line 1: sine: missing closing parenthesis",
+			`csv("1,2)`:       "line 1: csv: unterminated string",
+			"sine":            "line 1: expected a function call",
+			"latency(1s) 1m)": "line 1: expected a function call",
+		} {
+			require.EqualError(t, query(script).Error, expected, script)
+		}
+	})
+}
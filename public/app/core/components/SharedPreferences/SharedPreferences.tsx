@@ -11,6 +11,7 @@ import {
   RadioButtonGroup,
   Select,
   stylesFactory,
+  Switch,
   TimeZonePicker,
   Tooltip,
 } from '@grafana/ui';
@@ -29,6 +30,7 @@ export interface State {
   homeDashboardId: number;
   theme: string;
   timezone: string;
+  queryCachingDisabled: boolean;
   dashboards: DashboardSearchHit[];
 }
 
@@ -49,6 +51,7 @@ export class SharedPreferences extends PureComponent<Props, State> {
       homeDashboardId: 0,
       theme: '',
       timezone: '',
+      queryCachingDisabled: false,
       dashboards: [],
     };
   }
@@ -84,13 +87,14 @@ export class SharedPreferences extends PureComponent<Props, State> {
       homeDashboardId: prefs.homeDashboardId,
       theme: prefs.theme,
       timezone: prefs.timezone,
+      queryCachingDisabled: Boolean(prefs.queryCachingDisabled),
       dashboards: [defaultDashboardHit, ...dashboards],
     });
   }
 
   onSubmitForm = async () => {
-    const { homeDashboardId, theme, timezone } = this.state;
-    await this.service.update({ homeDashboardId, theme, timezone });
+    const { homeDashboardId, theme, timezone, queryCachingDisabled } = this.state;
+    await this.service.update({ homeDashboardId, theme, timezone, queryCachingDisabled });
     window.location.reload();
   };
 
@@ -105,6 +109,10 @@ export class SharedPreferences extends PureComponent<Props, State> {
     this.setState({ timezone: timezone });
   };
 
+  onQueryCachingChanged = () => {
+    this.setState({ queryCachingDisabled: !this.state.queryCachingDisabled });
+  };
+
   onHomeDashboardChanged = (dashboardId: number) => {
     this.setState({ homeDashboardId: dashboardId });
   };
@@ -117,7 +125,8 @@ export class SharedPreferences extends PureComponent<Props, State> {
   };
 
   render() {
-    const { theme, timezone, homeDashboardId, dashboards } = this.state;
+    const { resourceUri } = this.props;
+    const { theme, timezone, homeDashboardId, queryCachingDisabled, dashboards } = this.state;
     const styles = getStyles();
 
     return (
@@ -159,6 +168,15 @@ export class SharedPreferences extends PureComponent<Props, State> {
               <Field label="Timezone" aria-label={selectors.components.TimeZonePicker.container}>
                 <TimeZonePicker includeInternal={true} value={timezone} onChange={this.onTimeZoneChanged} />
               </Field>
+
+              {resourceUri === 'org' && (
+                <Field
+                  label="Query caching"
+                  description="Cache the query results of the dashboards and of Explore, when the query caching is enabled on the server."
+                >
+                  <Switch value={!queryCachingDisabled} onChange={this.onQueryCachingChanged} />
+                </Field>
+              )}
               <div className="gf-form-button-row">
                 <Button variant="primary" aria-label="User preferences save button">
                   Save
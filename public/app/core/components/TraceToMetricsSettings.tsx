@@ -0,0 +1,109 @@
+import { css } from '@emotion/css';
+import {
+  DataSourceJsonData,
+  DataSourcePluginOptionsEditorProps,
+  GrafanaTheme,
+  updateDatasourcePluginJsonDataOption,
+} from '@grafana/data';
+import { DataSourcePicker } from '@grafana/runtime';
+import { Button, IconButton, InlineField, InlineFieldRow, Input, useStyles } from '@grafana/ui';
+import React from 'react';
+
+export interface TraceToMetricQuery {
+  name?: string;
+  query?: string;
+}
+
+export interface TraceToMetricsOptions {
+  datasourceUid?: string;
+  queries?: TraceToMetricQuery[];
+}
+
+export interface TraceToMetricsData extends DataSourceJsonData {
+  tracesToMetrics?: TraceToMetricsOptions;
+}
+
+interface Props extends DataSourcePluginOptionsEditorProps<TraceToMetricsData> {}
+
+export function TraceToMetricsSettings({ options, onOptionsChange }: Props) {
+  const styles = useStyles(getStyles);
+  const queries = options.jsonData.tracesToMetrics?.queries ?? [];
+
+  const updateQueries = (queries: TraceToMetricQuery[]) =>
+    updateDatasourcePluginJsonDataOption({ onOptionsChange, options }, 'tracesToMetrics', {
+      datasourceUid: options.jsonData.tracesToMetrics?.datasourceUid,
+      queries,
+    });
+
+  return (
+    <div className={css({ width: '100%' })}>
+      <h3 className="page-heading">Trace to metrics</h3>
+
+      <div className={styles.infoText}>
+        Trace to metrics runs the queries with the trace, for example the RED metrics of its service. $__service and
+        $__span are replaced by the service and operation of the root span of the trace.
+      </div>
+
+      <InlineFieldRow>
+        <InlineField tooltip="The data source the metrics are queried from" label="Data source" labelWidth={26}>
+          <DataSourcePicker
+            pluginId="prometheus"
+            current={options.jsonData.tracesToMetrics?.datasourceUid}
+            noDefault={true}
+            width={40}
+            onChange={(ds) =>
+              updateDatasourcePluginJsonDataOption({ onOptionsChange, options }, 'tracesToMetrics', {
+                datasourceUid: ds.uid,
+                queries: options.jsonData.tracesToMetrics?.queries,
+              })
+            }
+          />
+        </InlineField>
+      </InlineFieldRow>
+
+      {queries.map((query, index) => (
+        <InlineFieldRow key={index}>
+          <InlineField label="Query" labelWidth={26}>
+            <Input
+              placeholder="Name"
+              width={20}
+              value={query.name}
+              onChange={(event) =>
+                updateQueries(queries.map((q, i) => (i === index ? { ...q, name: event.currentTarget.value } : q)))
+              }
+            />
+          </InlineField>
+          <InlineField grow>
+            <Input
+              placeholder='sum(rate(traces_spanmetrics_calls_total{service="$__service"}[5m]))'
+              value={query.query}
+              onChange={(event) =>
+                updateQueries(queries.map((q, i) => (i === index ? { ...q, query: event.currentTarget.value } : q)))
+              }
+            />
+          </InlineField>
+          <IconButton
+            name="trash-alt"
+            title="Remove query"
+            className={styles.removeButton}
+            onClick={() => updateQueries(queries.filter((_, i) => i !== index))}
+          />
+        </InlineFieldRow>
+      ))}
+
+      <Button variant="secondary" icon="plus" type="button" onClick={() => updateQueries([...queries, {}])}>
+        Add query
+      </Button>
+    </div>
+  );
+}
+
+const getStyles = (theme: GrafanaTheme) => ({
+  infoText: css`
+    padding-bottom: ${theme.spacing.md};
+    color: ${theme.colors.textSemiWeak};
+  `,
+  removeButton: css`
+    margin: ${theme.spacing.sm};
+  `,
+});
@@ -16,6 +16,7 @@ import {
   logSeriesToLogsModel,
   filterLogLevels,
   LIMIT_LABEL,
+  LogLevelColor,
 } from './logs_model';
 
 describe('dedupLogRows()', () => {
@@ -718,6 +719,47 @@ describe('dataFrameToLogsModel', () => {
     const logsModel = dataFrameToLogsModel(series, 1);
     expect(logsModel.rows[0].uid).toBe('0');
   });
+
+  it('should use the log volume returned by the data source as histogram', () => {
+    const series: DataFrame[] = [
+      toDataFrame({
+        fields: [
+          {
+            name: 'ts',
+            type: FieldType.time,
+            values: ['1970-01-01T00:00:01Z'],
+          },
+          {
+            name: 'line',
+            type: FieldType.string,
+            values: ['WARN boooo 1'],
+          },
+        ],
+      }),
+      toDataFrame({
+        name: 'error',
+        meta: { custom: { logsVolume: true } },
+        fields: [
+          {
+            name: 'time',
+            type: FieldType.time,
+            values: [0, 60000],
+          },
+          {
+            name: 'value',
+            type: FieldType.number,
+            values: [3, 5],
+          },
+        ],
+      }),
+    ];
+    const logsModel = dataFrameToLogsModel(series, 1);
+    expect(logsModel.rows).toHaveLength(1);
+    expect(logsModel.series).toHaveLength(1);
+    expect(logsModel.series![0].name).toBe('error');
+    expect(logsModel.series![0].fields[1].values.toArray()).toEqual([3, 5]);
+    expect(logsModel.series![0].fields[1].config.custom.fillColor).toBe(LogLevelColor[LogLevel.error]);
+  });
 });
 
 describe('logSeriesToLogsModel', () => {
@@ -9,6 +9,7 @@ import {
   dateTimeFormat,
   dateTimeFormatTimeAgo,
   FieldCache,
+  FieldConfig,
   FieldType,
   FieldWithIndex,
   findCommonLabels,
@@ -138,30 +139,62 @@ export function makeDataFramesForLogs(sortedRows: LogRowModel[], bucketSize: num
     const fieldCache = new FieldCache(data);
 
     const valueField = fieldCache.getFirstFieldOfType(FieldType.number)!;
-
-    data.fields[valueField.index].config.min = 0;
-    data.fields[valueField.index].config.decimals = 0;
-
-    data.fields[valueField.index].config.custom = {
-      drawStyle: DrawStyle.Bars,
-      barAlignment: BarAlignment.Center,
-      barWidthFactor: 0.9,
-      barMaxWidth: 5,
-      lineColor: series.color,
-      pointColor: series.color,
-      fillColor: series.color,
-      lineWidth: 0,
-      fillOpacity: 100,
-      stacking: {
-        mode: StackingMode.Normal,
-        group: 'A',
-      },
-    };
+    setHistogramFieldConfig(data.fields[valueField.index].config, series.color);
 
     return data;
   });
 }
 
+/**
+ * Returns the series of the log volume queries run by the data source along with the log queries, with the log
+ * volume as the number of lines by level in each interval, styled like the series computed from the log rows.
+ */
+export function makeDataFramesForLogsVolume(logsVolumeSeries: DataFrame[]): DataFrame[] {
+  return logsVolumeSeries.map((series) => {
+    const color = LogLevelColor[getLogLevelFromKey(series.name ?? '')];
+    return {
+      ...series,
+      fields: series.fields.map((field) => {
+        if (field.type !== FieldType.number) {
+          return field;
+        }
+        const config = { ...field.config };
+        setHistogramFieldConfig(config, color);
+        return { ...field, config };
+      }),
+    };
+  });
+}
+
+function setHistogramFieldConfig(config: FieldConfig, color: string) {
+  config.min = 0;
+  config.decimals = 0;
+
+  config.custom = {
+    drawStyle: DrawStyle.Bars,
+    barAlignment: BarAlignment.Center,
+    barWidthFactor: 0.9,
+    barMaxWidth: 5,
+    lineColor: color,
+    pointColor: color,
+    fillColor: color,
+    lineWidth: 0,
+    fillOpacity: 100,
+    stacking: {
+      mode: StackingMode.Normal,
+      group: 'A',
+    },
+  };
+}
+
+/**
+ * Returns true if the frame is a series of a log volume query, which the data sources mark with the logsVolume
+ * custom meta.
+ */
+export function isLogsVolumeData(series: DataFrame) {
+  return Boolean(series.meta?.custom?.logsVolume);
+}
+
 function isLogsData(series: DataFrame) {
   return series.fields.some((f) => f.type === FieldType.time) && series.fields.some((f) => f.type === FieldType.string);
 }
@@ -178,12 +211,15 @@ export function dataFrameToLogsModel(
   absoluteRange?: AbsoluteTimeRange,
   queries?: DataQuery[]
 ): LogsModel {
-  const { logSeries } = separateLogsAndMetrics(dataFrame);
+  const { logSeries, logsVolumeSeries } = separateLogsAndMetrics(dataFrame);
   const logsModel = logSeriesToLogsModel(logSeries);
 
   if (logsModel) {
-    // Create histogram metrics from logs using the interval as bucket size for the line count
-    if (intervalMs && logsModel.rows.length > 0) {
+    if (logsVolumeSeries.length > 0) {
+      // The data source counted the lines in the whole time range, rather than only the returned rows
+      logsModel.series = makeDataFramesForLogsVolume(logsVolumeSeries);
+    } else if (intervalMs && logsModel.rows.length > 0) {
+      // Create histogram metrics from logs using the interval as bucket size for the line count
       const sortedRows = logsModel.rows.sort(sortInAscendingOrder);
       const { visibleRange, bucketSize, visibleRangeMs, requestedRangeMs } = getSeriesProperties(
         sortedRows,
@@ -260,8 +296,14 @@ export function getSeriesProperties(
 function separateLogsAndMetrics(dataFrames: DataFrame[]) {
   const metricSeries: DataFrame[] = [];
   const logSeries: DataFrame[] = [];
+  const logsVolumeSeries: DataFrame[] = [];
 
   for (const dataFrame of dataFrames) {
+    if (isLogsVolumeData(dataFrame)) {
+      logsVolumeSeries.push(dataFrame);
+      continue;
+    }
+
     // We want to show meta stats even if no result was returned. That's why we are pushing also data frames with no fields.
     if (isLogsData(dataFrame) || !dataFrame.fields.length) {
       logSeries.push(dataFrame);
@@ -273,7 +315,7 @@ function separateLogsAndMetrics(dataFrames: DataFrame[]) {
     }
   }
 
-  return { logSeries, metricSeries };
+  return { logSeries, metricSeries, logsVolumeSeries };
 }
 
 interface LogFields {
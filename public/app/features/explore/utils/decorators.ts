@@ -12,7 +12,7 @@ import { config } from '@grafana/runtime';
 import { groupBy } from 'lodash';
 import { Observable, of } from 'rxjs';
 import { map, mergeMap } from 'rxjs/operators';
-import { dataFrameToLogsModel } from '../../../core/logs_model';
+import { dataFrameToLogsModel, isLogsVolumeData } from '../../../core/logs_model';
 import { refreshIntervalToSortOrder } from '../../../core/utils/explore';
 import { ExplorePanelData } from '../../../types';
 import { preProcessPanelData } from '../../query/state/runRequest';
@@ -30,6 +30,12 @@ export const decorateWithFrameTypeMetadata = (data: PanelData): ExplorePanelData
   const nodeGraphFrames: DataFrame[] = [];
 
   for (const frame of data.series) {
+    // The log volume is shown as the histogram of the logs
+    if (isLogsVolumeData(frame)) {
+      logsFrames.push(frame);
+      continue;
+    }
+
     switch (frame.meta?.preferredVisualisationType) {
       case 'logs':
         logsFrames.push(frame);
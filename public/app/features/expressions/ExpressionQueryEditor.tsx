@@ -6,6 +6,7 @@ import { Resample } from './components/Resample';
 import { Reduce } from './components/Reduce';
 import { Math } from './components/Math';
 import { ClassicConditions } from './components/ClassicConditions';
+import { Threshold } from './components/Threshold';
 import { getDefaults } from './utils/expressionTypes';
 import { ExpressionQuery, ExpressionQueryType, gelTypes } from './types';
 
@@ -35,6 +36,9 @@ export class ExpressionQueryEditor extends PureComponent<Props> {
 
       case ExpressionQueryType.classic:
         return <ClassicConditions onChange={onChange} query={query} refIds={refIds} />;
+
+      case ExpressionQueryType.threshold:
+        return <Threshold refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
     }
   }
 
@@ -0,0 +1,52 @@
+import React, { ChangeEvent, FC } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery, thresholdEvaluatorTypes } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Threshold: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const evaluator = thresholdEvaluatorTypes.find((o) => o.value === query.evaluator);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectEvaluator = (value: SelectableValue<string>) => {
+    onChange({ ...query, evaluator: value.value });
+  };
+
+  const onEnterLevelChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, enterLevel: parseFloat(event.target.value) });
+  };
+
+  const onExitLevelChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, exitLevel: parseFloat(event.target.value) });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+        <InlineField label="Evaluator">
+          <Select options={thresholdEvaluatorTypes} value={evaluator} onChange={onSelectEvaluator} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Enter alerting" labelWidth={labelWidth} tooltip="The level a number must cross to fire">
+          <Input type="number" onChange={onEnterLevelChange} value={query.enterLevel} width={15} />
+        </InlineField>
+        <InlineField label="Exit alerting" tooltip="The level a number must cross back to stop firing">
+          <Input type="number" onChange={onExitLevelChange} value={query.exitLevel} width={15} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
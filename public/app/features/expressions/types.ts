@@ -6,6 +6,7 @@ export enum ExpressionQueryType {
   reduce = 'reduce',
   resample = 'resample',
   classic = 'classic_conditions',
+  threshold = 'threshold',
 }
 
 export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
@@ -13,6 +14,7 @@ export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
   { value: ExpressionQueryType.reduce, label: 'Reduce' },
   { value: ExpressionQueryType.resample, label: 'Resample' },
   { value: ExpressionQueryType.classic, label: 'Classic condition' },
+  { value: ExpressionQueryType.threshold, label: 'Threshold' },
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -21,6 +23,12 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: 'p50', label: '50th percentile', description: 'Get the median of the values' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile of the values' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile of the values' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile of the values' },
 ];
 
 export const downsamplingTypes: Array<SelectableValue<string>> = [
@@ -28,12 +36,20 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: 'p50', label: '50th percentile', description: 'Fill with the median of the values' },
+  { value: 'p95', label: '95th percentile', description: 'Fill with the 95th percentile of the values' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'Interpolate between the last and next known values' },
+];
+
+export const thresholdEvaluatorTypes: Array<SelectableValue<string>> = [
+  { value: 'gt', label: 'Is above', description: 'Fire when the number is above the level' },
+  { value: 'lt', label: 'Is below', description: 'Fire when the number is below the level' },
 ];
 
 /**
@@ -48,6 +64,9 @@ export interface ExpressionQuery extends DataQuery {
   downsampler?: string;
   upsampler?: string;
   conditions?: ClassicCondition[];
+  evaluator?: string;
+  enterLevel?: number;
+  exitLevel?: number;
 }
 export interface ClassicCondition {
   evaluator: {
@@ -29,6 +29,22 @@ export const getDefaults = (query: ExpressionQuery) => {
       }
       break;
 
+    case ExpressionQueryType.threshold:
+      if (!query.evaluator) {
+        query.evaluator = 'gt';
+      }
+
+      if (query.enterLevel === undefined) {
+        query.enterLevel = 0;
+      }
+
+      if (query.exitLevel === undefined) {
+        query.exitLevel = query.enterLevel;
+      }
+
+      query.reducer = undefined;
+      break;
+
     default:
       query.reducer = undefined;
   }
@@ -0,0 +1,80 @@
+import React from 'react';
+import { SelectableValue } from '@grafana/data';
+import { Input, TextArea } from '@grafana/ui';
+import { Project, AliasBy, QueryEditorRow } from '.';
+import { PromQLQuery } from '../types';
+import CloudMonitoringDatasource from '../datasource';
+import { INPUT_WIDTH } from '../constants';
+
+export interface Props {
+  variableOptionGroup: SelectableValue<string>;
+  onChange: (query: PromQLQuery) => void;
+  onRunQuery: () => void;
+  query: PromQLQuery;
+  datasource: CloudMonitoringDatasource;
+}
+
+export const defaultQuery: (dataSource: CloudMonitoringDatasource) => PromQLQuery = (dataSource) => ({
+  projectName: dataSource.getDefaultProject(),
+  expr: '',
+  step: '',
+  aliasBy: '',
+});
+
+export function PromQLQueryEditor({
+  query,
+  datasource,
+  onChange,
+  onRunQuery,
+  variableOptionGroup,
+}: React.PropsWithChildren<Props>) {
+  const onKeyDown = (event: any) => {
+    if (event.key === 'Enter' && (event.shiftKey || event.ctrlKey)) {
+      event.preventDefault();
+      onRunQuery();
+    }
+  };
+
+  return (
+    <>
+      <Project
+        templateVariableOptions={variableOptionGroup.options}
+        projectName={query.projectName}
+        datasource={datasource}
+        onChange={(projectName) => {
+          onChange({ ...query, projectName });
+          onRunQuery();
+        }}
+      />
+      <TextArea
+        name="Query"
+        className="slate-query-field"
+        value={query.expr}
+        rows={10}
+        placeholder="Enter a PromQL query (Run with Shift+Enter)"
+        onBlur={onRunQuery}
+        onChange={(e) => onChange({ ...query, expr: e.currentTarget.value })}
+        onKeyDown={onKeyDown}
+      />
+      <QueryEditorRow
+        label="Step"
+        tooltip="Time between the points of the series, such as 1m. Defaults to the interval of the panel."
+      >
+        <Input
+          width={INPUT_WIDTH}
+          value={query.step}
+          placeholder="auto"
+          onChange={(e) => onChange({ ...query, step: e.currentTarget.value })}
+          onBlur={onRunQuery}
+        />
+      </QueryEditorRow>
+      <AliasBy
+        value={query.aliasBy}
+        onChange={(aliasBy) => {
+          onChange({ ...query, aliasBy });
+          onRunQuery();
+        }}
+      />
+    </>
+  );
+}
@@ -2,11 +2,12 @@ import React, { PureComponent } from 'react';
 import { css } from '@emotion/css';
 import { ExploreQueryFieldProps } from '@grafana/data';
 import { Button, Select } from '@grafana/ui';
-import { MetricQueryEditor, SLOQueryEditor, QueryEditorRow } from './';
-import { CloudMonitoringQuery, MetricQuery, QueryType, SLOQuery, EditorMode } from '../types';
+import { MetricQueryEditor, SLOQueryEditor, PromQLQueryEditor, QueryEditorRow } from './';
+import { CloudMonitoringQuery, MetricQuery, QueryType, SLOQuery, EditorMode, PromQLQuery } from '../types';
 import { SELECT_WIDTH, QUERY_TYPES } from '../constants';
 import { defaultQuery } from './MetricQueryEditor';
 import { defaultQuery as defaultSLOQuery } from './SLO/SLOQueryEditor';
+import { defaultQuery as defaultPromQLQuery } from './PromQLQueryEditor';
 import { toOption } from '../functions';
 import CloudMonitoringDatasource from '../datasource';
 
@@ -42,6 +43,7 @@ export class QueryEditor extends PureComponent<Props> {
     const { datasource, query, onRunQuery, onChange } = this.props;
     const metricQuery = { ...defaultQuery(datasource), ...query.metricQuery };
     const sloQuery = { ...defaultSLOQuery(datasource), ...query.sloQuery };
+    const promQLQuery = { ...defaultPromQLQuery(datasource), ...query.promQLQuery };
     const queryType = query.queryType || QueryType.METRICS;
     const meta = this.props.data?.series.length ? this.props.data?.series[0].meta : {};
     const customMetaData = meta?.custom ?? {};
@@ -56,7 +58,7 @@ export class QueryEditor extends PureComponent<Props> {
         <QueryEditorRow
           label="Query type"
           fillComponent={
-            query.queryType !== QueryType.SLO && (
+            queryType === QueryType.METRICS && (
               <Button
                 variant="secondary"
                 className={css`
@@ -80,7 +82,7 @@ export class QueryEditor extends PureComponent<Props> {
             value={queryType}
             options={QUERY_TYPES}
             onChange={({ value }) => {
-              onChange({ ...query, sloQuery, queryType: value! });
+              onChange({ ...query, sloQuery, promQLQuery, queryType: value! });
               onRunQuery();
             }}
           />
@@ -110,6 +112,16 @@ export class QueryEditor extends PureComponent<Props> {
             query={sloQuery}
           ></SLOQueryEditor>
         )}
+
+        {queryType === QueryType.PROMQL && (
+          <PromQLQueryEditor
+            variableOptionGroup={variableOptionGroup}
+            onChange={(promQLQuery: PromQLQuery) => this.props.onChange({ ...this.props.query, promQLQuery })}
+            onRunQuery={onRunQuery}
+            datasource={datasource}
+            query={promQLQuery}
+          ></PromQLQueryEditor>
+        )}
       </>
     );
   }
@@ -263,4 +263,5 @@ export const SELECTORS = [
 export const QUERY_TYPES = [
   { label: 'Metrics', value: QueryType.METRICS },
   { label: 'Service Level Objectives (SLO)', value: QueryType.SLO },
+  { label: 'PromQL', value: QueryType.PROMQL },
 ];
@@ -104,7 +104,7 @@ export default class CloudMonitoringDatasource extends DataSourceWithBackend<
   }
 
   applyTemplateVariables(
-    { metricQuery, refId, queryType, sloQuery }: CloudMonitoringQuery,
+    { metricQuery, refId, queryType, sloQuery, promQLQuery }: CloudMonitoringQuery,
     scopedVars: ScopedVars
   ): Record<string, any> {
     return {
@@ -125,6 +125,13 @@ export default class CloudMonitoringDatasource extends DataSourceWithBackend<
         editorMode: metricQuery.editorMode,
       },
       sloQuery: sloQuery && this.interpolateProps(sloQuery, scopedVars),
+      promQLQuery: promQLQuery && {
+        ...this.interpolateProps(promQLQuery, scopedVars),
+        projectName: this.templateSrv.replace(
+          promQLQuery.projectName ? promQLQuery.projectName : this.getDefaultProject(),
+          scopedVars
+        ),
+      },
     };
   }
 
@@ -333,6 +340,10 @@ export default class CloudMonitoringDatasource extends DataSourceWithBackend<
       return !!selectorName && !!serviceId && !!sloId && !!projectName;
     }
 
+    if (query.queryType && query.queryType === QueryType.PROMQL) {
+      return !!query.promQLQuery?.expr;
+    }
+
     if (query.queryType && query.queryType === QueryType.METRICS && query.metricQuery.editorMode === EditorMode.MQL) {
       return !!query.metricQuery.projectName && !!query.metricQuery.query;
     }
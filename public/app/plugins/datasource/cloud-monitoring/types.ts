@@ -56,6 +56,7 @@ export interface VariableQueryData {
 export enum QueryType {
   METRICS = 'metrics',
   SLO = 'slo',
+  PROMQL = 'promQL',
 }
 
 export enum EditorMode {
@@ -136,11 +137,19 @@ export interface SLOQuery extends BaseQuery {
   goal?: number;
 }
 
+export interface PromQLQuery {
+  projectName: string;
+  expr: string;
+  step?: string;
+  aliasBy?: string;
+}
+
 export interface CloudMonitoringQuery extends DataQuery {
   datasourceId?: number; // Should not be necessary anymore
   queryType: QueryType;
   metricQuery: MetricQuery;
   sloQuery?: SLOQuery;
+  promQLQuery?: PromQLQuery;
   intervalMs: number;
   type: string;
 }
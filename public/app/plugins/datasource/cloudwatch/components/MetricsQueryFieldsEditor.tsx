@@ -14,6 +14,7 @@ export type Props = {
 
 interface State {
   regions: SelectableStrings;
+  accounts: SelectableStrings;
   namespaces: SelectableStrings;
   metricNames: SelectableStrings;
   variableOptionGroup: SelectableValue<string>;
@@ -30,6 +31,7 @@ export function MetricsQueryFieldsEditor({
 
   const [state, setState] = useState<State>({
     regions: [],
+    accounts: [],
     namespaces: [],
     metricNames: [],
     variableOptionGroup: {},
@@ -54,6 +56,22 @@ export function MetricsQueryFieldsEditor({
     );
   }, [datasource]);
 
+  // The accounts are only listed when the data source is a monitoring account in the region of the query.
+  useEffect(() => {
+    datasource
+      .getAccounts(query.region)
+      .then((accounts) =>
+        accounts.length
+          ? [
+              { label: 'All', value: 'all', description: 'All the accounts' },
+              ...accounts.map(({ id, label }) => ({ label: label || id, value: id, description: id })),
+            ]
+          : []
+      )
+      .catch(() => [])
+      .then((accounts) => setState((prevState) => ({ ...prevState, accounts })));
+  }, [datasource, query.region]);
+
   const loadMetricNames = async () => {
     const { namespace, region } = query;
     return datasource.metricFindQuery(`metrics(${namespace},${region})`).then(appendTemplateVariables);
@@ -80,12 +98,19 @@ export function MetricsQueryFieldsEditor({
       {}
     );
     return datasource
-      .getDimensionValues(query.region, query.namespace, metricsQuery.metricName, newKey, newDimensions)
+      .getDimensionValues(
+        query.region,
+        query.namespace,
+        metricsQuery.metricName,
+        newKey,
+        newDimensions,
+        metricsQuery.accountId
+      )
       .then((values) => (values.length ? [{ value: '*', text: '*', label: '*' }, ...values] : values))
       .then(appendTemplateVariables);
   };
 
-  const { regions, namespaces, variableOptionGroup } = state;
+  const { regions, accounts, namespaces, variableOptionGroup } = state;
   return (
     <>
       <QueryInlineField label="Region">
@@ -98,6 +123,17 @@ export function MetricsQueryFieldsEditor({
         />
       </QueryInlineField>
 
+      {accounts.length > 0 && (
+        <QueryInlineField label="Account">
+          <Segment
+            value={metricsQuery.accountId ?? 'all'}
+            options={[...accounts, variableOptionGroup]}
+            allowCustomValue
+            onChange={({ value: accountId }) => onQueryChange({ ...metricsQuery, accountId })}
+          />
+        </QueryInlineField>
+      )}
+
       {query.expression?.length === 0 && (
         <>
           <QueryInlineField label="Namespace">
@@ -45,6 +45,7 @@ import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { ThrottlingErrorMessage } from './components/ThrottlingErrorMessage';
 import memoizedDebounce from './memoizedDebounce';
 import {
+  Account,
   CloudWatchJsonData,
   CloudWatchLogsQuery,
   CloudWatchLogsQueryStatus,
@@ -271,6 +272,9 @@ export class CloudWatchDatasource extends DataSourceWithBackend<CloudWatchQuery,
           item.period = String(this.getPeriod(item, options)); // use string format for period in graph query, and alerting
           item.id = this.templateSrv.replace(item.id, options.scopedVars);
           item.expression = this.templateSrv.replace(item.expression, options.scopedVars);
+          if (item.accountId) {
+            item.accountId = this.replace(item.accountId, options.scopedVars, true, 'account');
+          }
 
           // valid ExtendedStatistics is like p90.00, check the pattern
           const hasInvalidStatistics = item.statistics.some((s) => {
@@ -700,7 +704,8 @@ export class CloudWatchDatasource extends DataSourceWithBackend<CloudWatchQuery,
     namespace: string,
     metricName: string,
     dimensionKey: string,
-    filterDimensions: {}
+    filterDimensions: {},
+    accountId?: string
   ) {
     if (!namespace || !metricName) {
       return [];
@@ -712,11 +717,20 @@ export class CloudWatchDatasource extends DataSourceWithBackend<CloudWatchQuery,
       metricName: this.templateSrv.replace(metricName.trim()),
       dimensionKey: this.templateSrv.replace(dimensionKey),
       dimensions: this.convertDimensionFormat(filterDimensions, {}),
+      accountId: accountId ? this.templateSrv.replace(accountId) : undefined,
     });
 
     return values;
   }
 
+  /**
+   * Returns the monitoring account and its source accounts when the data source is the monitoring account of the
+   * cross-account observability in the region, otherwise no accounts.
+   */
+  getAccounts(region?: string): Promise<Account[]> {
+    return this.getResource('accounts', { region: this.templateSrv.replace(this.getActualRegion(region)) });
+  }
+
   getEbsVolumeIds(region: string, instanceId: string) {
     return this.doMetricQueryRequest('ebs_volume_ids', {
       region: this.templateSrv.replace(this.getActualRegion(region)),
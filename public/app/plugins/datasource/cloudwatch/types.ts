@@ -15,6 +15,10 @@ export interface CloudWatchMetricsQuery extends DataQuery {
   period: string;
   alias: string;
   matchExact: boolean;
+  /**
+   * The account of the metrics in a monitoring account of the cross-account observability, 'all' for all the accounts.
+   */
+  accountId?: string;
 }
 
 export type LogAction =
@@ -57,6 +61,13 @@ export interface AnnotationQuery extends CloudWatchMetricsQuery {
 
 export type SelectableStrings = Array<SelectableValue<string>>;
 
+export interface Account {
+  id: string;
+  arn: string;
+  label: string;
+  isMonitoringAccount: boolean;
+}
+
 export interface CloudWatchJsonData extends AwsAuthDataSourceJsonData {
   timeField?: string;
   database?: string;
@@ -23,6 +23,18 @@ describe('ElasticDetails', () => {
     expect(wrapper.find('input[aria-label="Max concurrent Shard Requests input"]').length).toBe(0);
   });
 
+  it('should render "Resolve indices" if version high enough', () => {
+    const options = createDefaultConfigOptions();
+    options.jsonData.esVersion = '7.10.0';
+    const wrapper = mount(<ElasticDetails onChange={() => {}} value={options} />);
+    expect(wrapper.find({ label: 'Resolve indices' }).length).toBeGreaterThan(0);
+  });
+
+  it('should not render "Resolve indices" if version is low', () => {
+    const wrapper = mount(<ElasticDetails onChange={() => {}} value={createDefaultConfigOptions()} />);
+    expect(wrapper.find({ label: 'Resolve indices' }).length).toBe(0);
+  });
+
   it('should change database on interval change when not set explicitly', () => {
     const onChangeMock = jest.fn();
     const wrapper = mount(<ElasticDetails onChange={onChangeMock} value={createDefaultConfigOptions()} />);
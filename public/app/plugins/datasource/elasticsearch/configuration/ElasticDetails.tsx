@@ -21,6 +21,7 @@ const esVersions = [
   { label: '6.0+', value: '6.0.0' },
   { label: '7.0+', value: '7.0.0' },
   { label: '7.7+', value: '7.7.0' },
+  { label: '7.10+', value: '7.10.0' },
 ];
 
 type Props = {
@@ -150,6 +151,17 @@ export const ElasticDetails = ({ value, onChange }: Props) => {
             onChange={jsonDataSwitchChangeHandler('xpack', value, onChange)}
           />
         </div>
+        {gte(value.jsonData.esVersion, '7.10.0') && (
+          <div className="gf-form-inline">
+            <Switch
+              label="Resolve indices"
+              labelClass="width-13"
+              checked={value.jsonData.resolveIndices || false}
+              onChange={jsonDataSwitchChangeHandler('resolveIndices', value, onChange)}
+              tooltip="Resolve the data streams, aliases and wildcards of the index name to the indices holding their documents, so that the queries only hit existing indices."
+            />
+          </div>
+        )}
       </div>
     </>
   );
@@ -17,6 +17,7 @@ export interface ElasticsearchOptions extends DataSourceJsonData {
   interval?: Interval;
   timeInterval: string;
   maxConcurrentShardRequests?: number;
+  resolveIndices?: boolean;
   logMessageField?: string;
   logLevelField?: string;
   dataLinks?: DataLinkConfig[];
@@ -20,7 +20,7 @@ import {
   ScopedVars,
 } from '@grafana/data';
 import { forkJoin, Observable, of } from 'rxjs';
-import { DataSourceWithBackend, getTemplateSrv, TemplateSrv } from '@grafana/runtime';
+import { DataSourceWithBackend, getTemplateSrv, HealthStatus, TemplateSrv } from '@grafana/runtime';
 import InsightsAnalyticsDatasource from './insights_analytics/insights_analytics_datasource';
 import { migrateMetricsDimensionFilters } from './query_ctrl';
 import { map } from 'rxjs/operators';
@@ -158,6 +158,7 @@ export default class Datasource extends DataSourceApi<AzureMonitorQuery, AzureDa
   async testDatasource(): Promise<DatasourceValidationResult> {
     const promises: Array<Promise<DatasourceValidationResult>> = [];
 
+    promises.push(this.testCredentials());
     promises.push(this.azureMonitorDatasource.testDatasource());
     promises.push(this.azureLogAnalyticsDatasource.testDatasource());
 
@@ -184,6 +185,18 @@ export default class Datasource extends DataSourceApi<AzureMonitorQuery, AzureDa
     });
   }
 
+  /**
+   * Acquires access tokens in the backend, so invalid credentials are reported
+   * and the tokens are cached before the first query
+   */
+  async testCredentials(): Promise<DatasourceValidationResult> {
+    const result = await this.azureResourceGraphDatasource.callHealthCheck();
+    return {
+      status: result?.status === HealthStatus.OK ? 'success' : 'error',
+      message: result?.message ?? 'Failed to validate credentials.',
+    };
+  }
+
   /* Azure Monitor REST API methods */
   getResourceGroups(subscriptionId: string) {
     return this.azureMonitorDatasource.getResourceGroups(this.replaceTemplateVariable(subscriptionId));
@@ -27,6 +27,7 @@ describe('graphiteDatasource', () => {
     jest.clearAllMocks();
 
     const instanceSettings = {
+      id: 1,
       url: '/api/datasources/proxy/1',
       name: 'graphiteProd',
       jsonData: {
@@ -267,6 +268,14 @@ describe('graphiteDatasource', () => {
     const INVALID_JSON =
       '{"testFunction":{"name":"function","description":"description","module":"graphite.render.functions","group":"Transform","params":[{"name":"param","type":"intOrInf","required":true,"default":Infinity}]}}';
 
+    it('should fetch the functions from the backend with server access', async () => {
+      fetchMock.mockImplementation(() => {
+        return of(createFetchResponse({}));
+      });
+      await ctx.ds.getFuncDefs();
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/functions');
+    });
+
     it('should parse the response with an invalid JSON', async () => {
       fetchMock.mockImplementation(() => {
         return of(createFetchResponse(INVALID_JSON));
@@ -404,7 +413,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
       expect(requestOptions.params.expr).toEqual([]);
       expect(results).not.toBe(null);
     });
@@ -414,7 +423,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
       expect(requestOptions.params.expr).toEqual(['server=backend_01']);
       expect(results).not.toBe(null);
     });
@@ -424,7 +433,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
       expect(requestOptions.params.expr).toEqual(['server=backend_01']);
       expect(results).not.toBe(null);
     });
@@ -434,7 +443,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual([]);
       expect(results).not.toBe(null);
@@ -445,7 +454,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual(['server=~backend*']);
       expect(results).not.toBe(null);
@@ -456,7 +465,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual([]);
       expect(results).not.toBe(null);
@@ -467,7 +476,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual(['server=~backend*']);
       expect(results).not.toBe(null);
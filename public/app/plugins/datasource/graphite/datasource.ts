@@ -581,7 +581,7 @@ export class GraphiteDatasource extends DataSourceApi<
       httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
       httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
     }
-    return this.doGraphiteRequest(httpOptions).pipe(mapToTags()).toPromise();
+    return this.doGraphiteResourceRequest(httpOptions).pipe(mapToTags()).toPromise();
   }
 
   getTagValuesAutoComplete(expressions: any[], tag: any, valuePrefix: any, optionalOptions: any) {
@@ -608,7 +608,7 @@ export class GraphiteDatasource extends DataSourceApi<
       httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
       httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
     }
-    return this.doGraphiteRequest(httpOptions).pipe(mapToTags()).toPromise();
+    return this.doGraphiteResourceRequest(httpOptions).pipe(mapToTags()).toPromise();
   }
 
   getVersion(optionalOptions: any) {
@@ -664,7 +664,7 @@ export class GraphiteDatasource extends DataSourceApi<
       url: '/functions',
     };
 
-    return this.doGraphiteRequest(httpOptions)
+    return this.doGraphiteResourceRequest(httpOptions)
       .pipe(
         map((results: any) => {
           if (results.status !== 200 || typeof results.data !== 'object') {
@@ -737,6 +737,26 @@ export class GraphiteDatasource extends DataSourceApi<
       );
   }
 
+  // With server access the functions and the tag autocompletions are served by the backend, which caches them
+  doGraphiteResourceRequest(options: { method?: string; url: string; params?: any; requestId?: any }) {
+    if (!this.url.startsWith('/api/datasources/proxy/')) {
+      return this.doGraphiteRequest(options);
+    }
+
+    return getBackendSrv()
+      .fetch({
+        ...options,
+        url: `/api/datasources/${this.id}/resources${options.url}`,
+        method: 'GET',
+        hideFromInspector: true,
+      })
+      .pipe(
+        catchError((err: any) => {
+          return throwError(reduceError(err));
+        })
+      );
+  }
+
   buildGraphiteParams(options: any, scopedVars?: ScopedVars): string[] {
     const graphiteOptions = ['from', 'until', 'rawData', 'format', 'maxDataPoints', 'cacheTimeout'];
     const cleanOptions = [],
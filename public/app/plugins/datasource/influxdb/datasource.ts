@@ -105,15 +105,30 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
   }
 
   /**
-   * Only applied on flux queries
+   * Only applied on flux queries. The query text is sent as it is, the variables are used as fields of the `v`
+   * record so that their values can't change the query.
    */
   applyTemplateVariables(query: InfluxQuery, scopedVars: ScopedVars): Record<string, any> {
     return {
       ...query,
-      query: this.templateSrv.replace(query.query ?? '', scopedVars), // The raw query text
+      variables: this.getFluxVariables(scopedVars),
     };
   }
 
+  /**
+   * The values of the dashboard variables, bound by the backend to the `v` option record of flux queries
+   */
+  getFluxVariables(scopedVars: ScopedVars): Record<string, string | string[]> {
+    const variables: Record<string, string | string[]> = {};
+    for (const variable of this.templateSrv.getVariables()) {
+      this.templateSrv.replace(`\${${variable.name}}`, scopedVars, (value: string | string[]) => {
+        variables[variable.name] = value;
+        return '';
+      });
+    }
+    return variables;
+  }
+
   /**
    * The unchanged pre 7.1 query implementation
    */
@@ -276,7 +291,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
           policy: this.templateSrv.replace(query.policy ?? '', scopedVars, 'regex'),
         };
 
-        if (query.rawQuery) {
+        if (query.rawQuery && !this.isFlux) {
           expandedQuery.query = this.templateSrv.replace(query.query ?? '', scopedVars, 'regex');
         }
 
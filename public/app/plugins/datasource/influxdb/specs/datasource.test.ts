@@ -1,4 +1,5 @@
 import InfluxDatasource from '../datasource';
+import { InfluxVersion } from '../types';
 
 import { TemplateSrvStub } from 'test/specs/helpers';
 import { backendSrv } from 'app/core/services/backend_srv'; // will use the version in __mocks__
@@ -173,4 +174,29 @@ describe('InfluxDataSource', () => {
       });
     });
   });
+
+  describe('InfluxDataSource in Flux mode', () => {
+    const host = '") |> drop(columns: ["_value"]) //';
+    const fluxTemplateSrv: any = {
+      getVariables: () => [{ name: 'host' }],
+      replace: (text: string, scopedVars: any, format?: any) => {
+        if (typeof format === 'function') {
+          return format(host);
+        }
+        return text.replace('$host', host);
+      },
+    };
+    const fluxDs = new InfluxDatasource(
+      { url: 'url', name: 'influxDb', jsonData: { version: InfluxVersion.Flux } } as any,
+      fluxTemplateSrv
+    );
+
+    it('should send the variables apart from the query text', () => {
+      const query = 'from(bucket: v.defaultBucket) |> filter(fn: (r) => r.host == "$host" or r.host == v.host)';
+      const applied = fluxDs.applyTemplateVariables({ refId: 'A', query }, {});
+
+      expect(applied.query).toBe(query);
+      expect(applied.variables).toEqual({ host });
+    });
+  });
 });
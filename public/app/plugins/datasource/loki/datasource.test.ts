@@ -3,7 +3,7 @@ import { take } from 'rxjs/operators';
 import { AnnotationQueryRequest, CoreApp, DataFrame, dateTime, FieldCache, TimeSeries } from '@grafana/data';
 import { BackendSrvRequest, FetchResponse } from '@grafana/runtime';
 
-import LokiDatasource from './datasource';
+import LokiDatasource, { encodeTailQuery } from './datasource';
 import { LokiQuery, LokiResponse, LokiResultType } from './types';
 import { getQueryOptions } from 'test/helpers/getQueryOptions';
 import { TemplateSrv } from 'app/features/templating/template_srv';
@@ -680,6 +680,14 @@ describe('LokiDatasource', () => {
       });
     });
   });
+
+  describe('encodeTailQuery', () => {
+    it('encodes the query with the unpadded base64url encoding', () => {
+      expect(encodeTailQuery('{job="grafana"}')).toBe('e2pvYj0iZ3JhZmFuYSJ9');
+      expect(encodeTailQuery('{job="ü"} |~ "?"')).toBe('e2pvYj0iw7wifSB8fiAiPyI');
+      expect(encodeTailQuery('{app="~"} |~ "??>"')).toBe('e2FwcD0ifiJ9IHx-ICI_Pz4i');
+    });
+  });
 });
 
 function createLokiDSForTests(
@@ -19,12 +19,20 @@ import {
   dateMath,
   DateTime,
   FieldCache,
+  LiveChannelScope,
   LoadingState,
   LogRowModel,
   QueryResultMeta,
   ScopedVars,
 } from '@grafana/data';
-import { getTemplateSrv, TemplateSrv, BackendSrvRequest, FetchError, getBackendSrv } from '@grafana/runtime';
+import {
+  getTemplateSrv,
+  TemplateSrv,
+  BackendSrvRequest,
+  FetchError,
+  getBackendSrv,
+  getGrafanaLiveSrv,
+} from '@grafana/runtime';
 import { addLabelToQuery } from 'app/plugins/datasource/prometheus/add_label_to_query';
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { convertToWebSocketUrl } from 'app/core/utils/explore';
@@ -57,6 +65,8 @@ const NS_IN_MS = 1000000;
 
 const RANGE_QUERY_ENDPOINT = `${LOKI_ENDPOINT}/query_range`;
 const INSTANT_QUERY_ENDPOINT = `${LOKI_ENDPOINT}/query`;
+// Grafana Live limits the length of the channels, the longer queries are tailed from the browser
+const MAX_LIVE_CHANNEL_LENGTH = 160;
 
 const DEFAULT_QUERY_PARAMS: Partial<LokiRangeQueryRequest> = {
   direction: 'BACKWARD',
@@ -256,6 +266,26 @@ export class LokiDatasource extends DataSourceApi<LokiQuery, LokiOptions> {
    * labels per row.
    */
   runLiveQuery = (target: LokiQuery, maxDataPoints: number): Observable<DataQueryResponse> => {
+    // With server access the query is tailed by the backend and published to a Grafana Live channel
+    const tailPath = `tail/${encodeTailQuery(target.expr)}`;
+    if (
+      this.instanceSettings.url?.startsWith('/api/datasources/proxy/') &&
+      `${LiveChannelScope.DataSource}/${this.instanceSettings.uid}/${tailPath}`.length <= MAX_LIVE_CHANNEL_LENGTH
+    ) {
+      return getGrafanaLiveSrv()
+        .getDataStream({
+          key: `loki-${target.refId}`,
+          addr: { scope: LiveChannelScope.DataSource, namespace: this.instanceSettings.uid, path: tailPath },
+          buffer: { maxLength: maxDataPoints },
+        })
+        .pipe(
+          map((response) => ({
+            ...response,
+            data: response.data.map((frame: DataFrame) => ({ ...frame, refId: target.refId })),
+          }))
+        );
+    }
+
     const liveTarget = this.createLiveTarget(target, maxDataPoints);
 
     return this.streams.getStream(liveTarget).pipe(
@@ -649,4 +679,14 @@ function isMetricsQuery(query: string): boolean {
   });
 }
 
+/**
+ * Encodes a query for the path of a tailing channel, with the unpadded base64url encoding of its UTF-8 bytes.
+ */
+export function encodeTailQuery(query: string): string {
+  return btoa(unescape(encodeURIComponent(query)))
+    .replace(/\+/g, '-')
+    .replace(/\//g, '_')
+    .replace(/=+$/, '');
+}
+
 export default LokiDatasource;
@@ -286,6 +286,15 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     relativeUrl: string,
     params?: { type?: string; q?: string; max?: number; m?: any; limit?: number }
   ): Observable<FetchResponse> {
+    // With server access the lookups are served by the backend
+    if (this.url.startsWith('/api/datasources/proxy/')) {
+      return getBackendSrv().fetch({
+        method: 'GET',
+        url: `/api/datasources/${this.id}/resources${relativeUrl}`,
+        params: params,
+      });
+    }
+
     const options = {
       method: 'GET',
       url: this.url + relativeUrl,
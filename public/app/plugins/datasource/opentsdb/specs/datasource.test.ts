@@ -20,12 +20,12 @@ const metricFindQueryData = [
 ];
 
 describe('opentsdb', () => {
-  function getTestcontext({ data = metricFindQueryData }: { data?: any } = {}) {
+  function getTestcontext({ data = metricFindQueryData, url = '' }: { data?: any; url?: string } = {}) {
     jest.clearAllMocks();
     const fetchMock = jest.spyOn(backendSrv, 'fetch');
     fetchMock.mockImplementation(() => of(createFetchResponse(data)));
 
-    const instanceSettings = { url: '', jsonData: { tsdbVersion: 1 } };
+    const instanceSettings = { id: 1, url, jsonData: { tsdbVersion: 1 } };
     const replace = jest.fn((value) => value);
     const templateSrv: any = {
       replace,
@@ -49,6 +49,16 @@ describe('opentsdb', () => {
       expect(results).not.toBe(null);
     });
 
+    it('metrics() should query the backend with server access', async () => {
+      const { ds, fetchMock } = getTestcontext({ url: '/api/datasources/proxy/1' });
+
+      await ds.metricFindQuery('metrics(pew)');
+
+      expect(fetchMock).toHaveBeenCalledTimes(1);
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/api/suggest');
+      expect(fetchMock.mock.calls[0][0].params?.q).toBe('pew');
+    });
+
     it('tag_names(cpu) should generate lookup query', async () => {
       const { ds, fetchMock } = getTestcontext();
 
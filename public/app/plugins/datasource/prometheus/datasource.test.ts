@@ -219,6 +219,30 @@ describe('PrometheusDatasource', () => {
     });
   });
 
+  describe('Metadata requests with server access', () => {
+    const promDs = new PrometheusDatasource(
+      { ...instanceSettings, id: 5, url: '/api/datasources/proxy/5' },
+      templateSrvStub as any,
+      timeSrvStub as any
+    );
+
+    it('uses the resources of the data source for the metadata endpoints', () => {
+      promDs.metadataRequest('/api/v1/label/job/values', { start: '60', end: '180' });
+      expect(fetchMock.mock.calls.length).toBe(1);
+      expect(fetchMock.mock.calls[0][0]).toMatchObject({
+        url: '/api/datasources/5/resources/api/v1/label/job/values',
+        params: { start: '60', end: '180' },
+        method: 'GET',
+      });
+    });
+
+    it('uses the proxy for the other endpoints', () => {
+      promDs.metadataRequest('/api/v1/series', { 'match[]': 'up' });
+      expect(fetchMock.mock.calls.length).toBe(1);
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/proxy/5/api/v1/series');
+    });
+  });
+
   describe('When using adhoc filters', () => {
     const DEFAULT_QUERY_EXPRESSION = 'metric{job="foo"} - metric';
     const target = { expr: DEFAULT_QUERY_EXPRESSION };
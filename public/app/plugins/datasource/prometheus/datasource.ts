@@ -45,6 +45,7 @@ import PrometheusMetricFindQuery from './metric_find_query';
 export const ANNOTATION_QUERY_STEP_DEFAULT = '60s';
 const EXEMPLARS_NOT_AVAILABLE = 'Exemplars for this data source are not available.';
 const GET_AND_POST_METADATA_ENDPOINTS = ['api/v1/query', 'api/v1/query_range', 'api/v1/series', 'api/v1/labels'];
+const RESOURCE_METADATA_ENDPOINT = /^\/api\/v1\/(metadata|labels|label\/[^/]+\/values)$/;
 
 export class PrometheusDatasource extends DataSourceApi<PromQuery, PromOptions> {
   type: string;
@@ -155,6 +156,18 @@ export class PrometheusDatasource extends DataSourceApi<PromQuery, PromOptions>
 
   // Use this for tab completion features, wont publish response to other components
   async metadataRequest<T = any>(url: string, params = {}) {
+    // With server access the metadata endpoints are served by the backend, which caches and rate limits them
+    if (this.url.startsWith('/api/datasources/proxy/') && RESOURCE_METADATA_ENDPOINT.test(url)) {
+      return await getBackendSrv()
+        .fetch<T>({
+          url: `/api/datasources/${this.id}/resources${url}`,
+          params,
+          method: 'GET',
+          hideFromInspector: true,
+        })
+        .toPromise();
+    }
+
     // If URL includes endpoint that supports POST and GET method, try to use configured method. This might fail as POST is supported only in v2.10+.
     if (GET_AND_POST_METADATA_ENDPOINTS.some((endpoint) => url.includes(endpoint))) {
       try {
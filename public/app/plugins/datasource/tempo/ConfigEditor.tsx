@@ -1,6 +1,7 @@
 import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
 import { DataSourceHttpSettings } from '@grafana/ui';
 import { TraceToLogsSettings } from 'app/core/components/TraceToLogsSettings';
+import { TraceToMetricsSettings } from 'app/core/components/TraceToMetricsSettings';
 import React from 'react';
 
 export type Props = DataSourcePluginOptionsEditorProps;
@@ -16,6 +17,8 @@ export const ConfigEditor: React.FC<Props> = ({ options, onOptionsChange }) => {
       />
 
       <TraceToLogsSettings options={options} onOptionsChange={onOptionsChange} />
+
+      <TraceToMetricsSettings options={options} onOptionsChange={onOptionsChange} />
     </>
   );
 };
@@ -0,0 +1,25 @@
+import React, { ChangeEvent } from 'react';
+import { InlineField, TextArea } from '@grafana/ui';
+import { EditorProps } from '../QueryEditor';
+
+export const ScriptEditor = ({ onChange, query }: EditorProps) => {
+  const onScript = (e: ChangeEvent<HTMLTextAreaElement>) => {
+    onChange({ ...query, script: e.currentTarget.value });
+  };
+
+  return (
+    <InlineField
+      label="Script"
+      labelWidth={14}
+      tooltip="One function per line: sine(period, amplitude, offset), randomWalk(seed, start, spread), step(period, values...), csv(&quot;values&quot;), error(probability, seed), latency(duration)"
+    >
+      <TextArea
+        width="100%"
+        rows={10}
+        onBlur={onScript}
+        placeholder={'sine(1h, 10)\nrandomWalk(42)\nerror(0.1, 7)'}
+        defaultValue={query.script ?? ''}
+      />
+    </InlineField>
+  );
+};
@@ -20,6 +20,7 @@ export interface TestDataQuery extends DataQuery {
   nodes?: NodesQuery;
   csvFileName?: string;
   csvContent?: string;
+  script?: string;
 }
 
 export interface NodesQuery {
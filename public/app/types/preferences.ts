@@ -4,4 +4,5 @@ export interface UserPreferencesDTO {
   timezone: TimeZone;
   homeDashboardId: number;
   theme: string;
+  queryCachingDisabled?: boolean;
 }